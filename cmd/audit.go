@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/utils"
+)
+
+// auditSession 当前命令的审计信息，在 PersistentPreRun 中开始，命令结束或退出时写入
+var auditSession struct {
+	started    bool
+	startTime  time.Time
+	command    string
+	args       []string
+	projectDir string
+}
+
+// osExit 进程退出函数，测试时可替换
+var osExit = os.Exit
+
+// startAudit 记录命令开始执行
+func startAudit(cmd *cobra.Command, args []string) {
+	auditSession.started = true
+	auditSession.startTime = time.Now()
+	auditSession.command = cmd.CommandPath()
+	auditSession.args = utils.SanitizeAuditArgs(os.Args[1:])
+	auditSession.projectDir = "."
+}
+
+// setAuditProjectDir 命令创建了新项目时，审计记录写入新项目
+func setAuditProjectDir(projectDir string) {
+	auditSession.projectDir = projectDir
+}
+
+// finishAudit 写入审计记录，只在项目目录中记录，写入失败时仅记录警告
+func finishAudit(exitCode int) {
+	if !auditSession.started {
+		return
+	}
+	auditSession.started = false
+
+	path := utils.AuditLogPath(auditSession.projectDir)
+	if _, err := os.Stat(filepath.Dir(path)); err != nil {
+		return
+	}
+
+	project, err := filepath.Abs(auditSession.projectDir)
+	if err != nil {
+		project = auditSession.projectDir
+	}
+	host, _ := os.Hostname()
+
+	result := utils.AuditResultSuccess
+	if exitCode != 0 {
+		result = utils.AuditResultFailure
+	}
+	record := &utils.AuditRecord{
+		Time:     auditSession.startTime,
+		RunID:    utils.RunID(),
+		User:     utils.CurrentUsername(),
+		Host:     host,
+		Project:  project,
+		Command:  auditSession.command,
+		Args:     auditSession.args,
+		Result:   result,
+		ExitCode: exitCode,
+		Duration: time.Since(auditSession.startTime),
+	}
+	if err := utils.AppendAuditRecord(path, record); err != nil {
+		utils.GetGlobalLogger().Warnf("写入审计日志失败: %v", err)
+	}
+}
+
+// exit 释放迁移锁、更新后台运行记录、写入审计记录后退出进程，命令失败时代替 os.Exit 使用
+func exit(code int) {
+	releaseMigrationLock()
+	finishDetachedRun(code)
+	finishAudit(code)
+	osExit(code)
+}
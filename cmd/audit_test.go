@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/utils"
+)
+
+// executeForAudit 以给定参数执行根命令，exit() 通过 panic 返回退出码
+func executeForAudit(t *testing.T, args ...string) (exitCode int) {
+	t.Helper()
+
+	originalArgs := os.Args
+	os.Args = append([]string{"ora2pg-admin"}, args...)
+	osExit = func(code int) { panic(code) }
+	defer func() {
+		os.Args = originalArgs
+		osExit = os.Exit
+		if r := recover(); r != nil {
+			code, ok := r.(int)
+			require.True(t, ok, "unexpected panic: %v", r)
+			exitCode = code
+		}
+	}()
+
+	rootCmd.SetArgs(args)
+	require.NoError(t, rootCmd.Execute())
+	return 0
+}
+
+// readAuditRecords 读取项目的审计记录
+func readAuditRecords(t *testing.T, projectDir string) []utils.AuditRecord {
+	t.Helper()
+
+	file, err := os.Open(utils.AuditLogPath(projectDir))
+	require.NoError(t, err)
+	defer file.Close()
+
+	var records []utils.AuditRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record utils.AuditRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestCommandsWriteAuditRecords(t *testing.T) {
+	projectDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, ".ora2pg-admin"), 0755))
+	originalDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(projectDir))
+	defer os.Chdir(originalDir)
+	defer func() { historyLimit, validateSample = 20, 100 }()
+
+	assert.Equal(t, 0, executeForAudit(t, "历史", "--limit", "5"))
+	assert.Equal(t, 1, executeForAudit(t, "校验", "--sample", "0"))
+
+	records := readAuditRecords(t, projectDir)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, "ora2pg-admin 历史", records[0].Command)
+	assert.Equal(t, []string{"历史", "--limit", "5"}, records[0].Args)
+	assert.Equal(t, utils.AuditResultSuccess, records[0].Result)
+	assert.Equal(t, 0, records[0].ExitCode)
+	assert.Equal(t, utils.RunID(), records[0].RunID)
+	assert.NotEmpty(t, records[0].User)
+
+	assert.Equal(t, "ora2pg-admin 校验", records[1].Command)
+	assert.Equal(t, utils.AuditResultFailure, records[1].Result)
+	assert.Equal(t, 1, records[1].ExitCode)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -11,12 +12,23 @@ import (
 	"github.com/spf13/cobra"
 	"ora2pg-admin/internal/config"
 	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/service"
 	"ora2pg-admin/internal/utils"
 )
 
 var (
 	checkVerbose bool
 	checkConfig  string
+	checkOutput  string
+	checkNoProbe bool
+	// checkConnHistory 显示连接响应时间的历史趋势，不执行连接测试
+	checkConnHistory bool
+	checkConnLimit   int
+	// checkNetwork 连接测试后测量网络延迟，checkBandwidth 同时估算带宽
+	checkNetwork        bool
+	checkNetworkSamples int
+	checkBandwidth      bool
+	checkBandwidthSize  int
 )
 
 // checkCmd 检查命令
@@ -28,6 +40,7 @@ var checkCmd = &cobra.Command{
 此命令提供多种检查功能，帮助您诊断和解决迁移环境中的问题：
 • 环境检查：验证Oracle客户端、ora2pg工具等环境配置
 • 连接测试：测试Oracle和PostgreSQL数据库连接
+• 就绪度评估：评估源库的迁移复杂度
 
 使用子命令指定具体的检查类型。`,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -64,6 +77,16 @@ var checkConnCmd = &cobra.Command{
 • 网络连通性和响应时间
 • 数据库权限和访问性
 
+Oracle连接因端口不通或服务名/SID不被识别而失败时，会自动探测少量常见端口、
+按SID重新登录一次，给出纠错建议（仅供参考，不会修改配置；--no-probe 跳过探测）。
+
+每次测试的响应时间记录在 .ora2pg-admin/connection_history.jsonl 中，--history 显示最近
+--limit 次的响应时间趋势，并标记连接失败和明显变慢的记录，用于迁移窗口期的健康监控。
+
+--network 在连接测试后多次建立TCP连接，统计到两端数据库延迟的最小/平均/最大值和抖动，
+判断是否跨地域，并给出并行度、批大小和超时的建议；--bandwidth 再通过查询传输测试数据估算带宽，
+适合跨地域迁移前评估网络是否会成为瓶颈。
+
 需要先配置数据库连接信息才能进行连接测试。`,
 	Run: runCheckConn,
 }
@@ -76,174 +99,115 @@ func init() {
 	// 添加命令参数
 	checkCmd.PersistentFlags().BoolVarP(&checkVerbose, "verbose", "v", false, "显示详细检查信息")
 	checkCmd.PersistentFlags().StringVarP(&checkConfig, "config", "c", "", "指定配置文件路径")
+	checkCmd.PersistentFlags().StringVarP(&checkOutput, "output", "o", checkOutputText, "输出格式 (text, json)")
+	checkConnCmd.Flags().BoolVar(&checkNoProbe, "no-probe", false, "Oracle连接失败时不自动探测端口和SID/服务名")
+	checkConnCmd.Flags().BoolVar(&checkConnHistory, "history", false, "显示历次连接测试的响应时间趋势，不执行连接测试")
+	checkConnCmd.Flags().IntVar(&checkConnLimit, "limit", 20, "--history 显示的最近记录数（0表示全部）")
+	checkConnCmd.Flags().BoolVar(&checkNetwork, "network", false, "多次建立TCP连接测量到两端数据库的延迟和抖动，给出并行度和超时建议")
+	checkConnCmd.Flags().IntVar(&checkNetworkSamples, "samples", oracle.DefaultLatencySamples, "--network 测量延迟的次数")
+	checkConnCmd.Flags().BoolVar(&checkBandwidth, "bandwidth", false, "同时通过查询传输测试数据估算带宽（包含 --network，会占用少量数据库资源）")
+	checkConnCmd.Flags().IntVar(&checkBandwidthSize, "bandwidth-size", 8, "--bandwidth 传输的测试数据量（MB）")
 }
 
 // runCheckEnv 执行环境检查
 func runCheckEnv(cmd *cobra.Command, args []string) {
 	logger := utils.GetGlobalLogger()
-	
-	fmt.Println("🔍 环境检查")
-	fmt.Println()
 
-	// 1. 检查Oracle客户端
-	fmt.Println("📋 Oracle客户端检查")
-	fmt.Println("─────────────────────")
-	
+	if err := prepareCheckOutput(); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	// 1. 收集检查结果
+	report := newCheckReport()
 	detector := oracle.NewClientDetector()
-	statusReport := detector.CheckClientStatus()
-	
-	fmt.Print(statusReport.GetStatusSummary())
-	
-	if statusReport.Status != "COMPATIBLE" {
-		fmt.Println()
-		if statusReport.Status == "NOT_INSTALLED" {
-			guide := detector.GetInstallationGuide()
-			fmt.Println("📥 安装指导:")
-			fmt.Printf("下载地址: %s\n", guide.DownloadURL)
-			fmt.Println("安装步骤:")
-			for i, instruction := range guide.Instructions {
-				fmt.Printf("  %d. %s\n", i+1, instruction)
-			}
+	// 配置指定了客户端路径时以配置为准
+	var projectConfig *config.ProjectConfig
+	if configPath := getConfigPath(); configPath != "" {
+		manager := config.NewManager()
+		if err := manager.LoadConfig(configPath); err == nil {
+			projectConfig = manager.GetConfig()
+			detector.UseConfig(&projectConfig.OracleClient)
 		}
 	}
+	statusReport := detector.CheckClientStatus()
 
-	// 2. 检查ora2pg工具
-	fmt.Println()
-	fmt.Println("📋 ora2pg工具检查")
-	fmt.Println("─────────────────────")
-	
-	if checkOra2pgTool() {
-		fmt.Println("✅ ora2pg工具: 已安装并可用")
-		if checkVerbose {
-			if version := getOra2pgVersion(); version != "" {
-				fmt.Printf("   版本: %s\n", version)
-			}
+	collectOracleClientCheck(report.Section("Oracle客户端检查"), detector, statusReport)
+	collectOra2pgCheck(report.Section("ora2pg工具检查"))
+	if projectConfig != nil && len(projectConfig.Tools.Constraints()) > 0 {
+		collectToolVersionCheck(report.Section("工具版本检查"), projectConfig)
+	}
+	collectSystemEnvironment(report.Section("系统环境检查"))
+	collectProjectEnvironment(report.Section("项目环境检查"))
+
+	// 2. 按格式输出
+	if isJSONOutput() {
+		if err := report.RenderJSON(); err != nil {
+			fmt.Printf("%s\n", utils.FormatError(err))
+			exit(1)
 		}
 	} else {
-		fmt.Println("❌ ora2pg工具: 未找到")
+		fmt.Println("🔍 环境检查")
 		fmt.Println()
-		fmt.Println("💡 解决建议:")
-		fmt.Println("  1. 确认ora2pg已正确安装")
-		fmt.Println("  2. 将ora2pg添加到PATH环境变量")
-		fmt.Println("  3. 检查Perl环境是否正确配置")
-	}
+		report.RenderText()
 
-	// 3. 检查系统环境
-	fmt.Println()
-	fmt.Println("📋 系统环境检查")
-	fmt.Println("─────────────────────")
-	
-	checkSystemEnvironment()
-
-	// 4. 检查项目环境
-	fmt.Println()
-	fmt.Println("📋 项目环境检查")
-	fmt.Println("─────────────────────")
-	
-	checkProjectEnvironment()
+		fmt.Println()
+		fmt.Println("📊 检查总结")
+		fmt.Println("─────────────────────")
+		provideSummaryAndSuggestions(report)
+	}
 
-	// 5. 总结和建议
-	fmt.Println()
-	fmt.Println("📊 检查总结")
-	fmt.Println("─────────────────────")
-	
-	provideSummaryAndSuggestions(statusReport)
-	
 	logger.Info("环境检查完成")
+	exitOnFailedChecks(report)
 }
 
 // runCheckConn 执行连接测试
 func runCheckConn(cmd *cobra.Command, args []string) {
 	logger := utils.GetGlobalLogger()
-	
-	fmt.Println("🔗 数据库连接测试")
-	fmt.Println()
 
-	// 1. 加载配置文件
-	configPath := getConfigPath()
-	if configPath == "" {
-		fmt.Printf("%s\n", utils.FormatError(
-			utils.ConfigErrors.FileNotFound("配置文件未找到")))
-		fmt.Println()
-		fmt.Println("💡 请先使用以下命令初始化项目:")
-		fmt.Println("   ora2pg-admin 初始化 [项目名称]")
-		fmt.Println("   ora2pg-admin 配置 数据库")
-		return
+	if err := prepareCheckOutput(); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
 	}
 
-	manager := config.NewManager()
-	if err := manager.LoadConfig(configPath); err != nil {
-		fmt.Printf("%s\n", utils.FormatError(err))
+	if checkConnHistory {
+		if err := showConnectionHistory(); err != nil {
+			fmt.Printf("%s\n", utils.FormatError(err))
+			exit(1)
+		}
 		return
 	}
 
-	cfg := manager.GetConfig()
+	// 1. 收集检查结果
+	report := newCheckReport()
+	collectConnectionChecks(report)
 
-	// 2. 测试Oracle连接
-	fmt.Println("📋 Oracle数据库连接测试")
-	fmt.Println("─────────────────────────")
-	
-	tester := oracle.NewConnectionTester()
-	oracleResult := tester.TestOracleConnection(&cfg.Oracle)
-	
-	fmt.Printf("状态: %s\n", oracleResult.Message)
-	if oracleResult.Success {
-		fmt.Printf("响应时间: %v\n", oracleResult.ResponseTime)
-		if oracleResult.Details != "" {
-			fmt.Printf("详情: %s\n", oracleResult.Details)
-		}
-	} else {
-		if oracleResult.Error != "" {
-			fmt.Printf("错误: %s\n", oracleResult.Error)
-		}
-		if oracleResult.Details != "" && checkVerbose {
-			fmt.Printf("详细信息: %s\n", oracleResult.Details)
-		}
-		
-		// 提供诊断信息
-		fmt.Println()
-		fmt.Println("🔍 连接诊断:")
-		diagnostics := tester.GetConnectionDiagnostics(&cfg.Oracle)
-		for _, diag := range diagnostics {
-			fmt.Println(diag)
+	// 2. 按格式输出
+	if isJSONOutput() {
+		if err := report.RenderJSON(); err != nil {
+			fmt.Printf("%s\n", utils.FormatError(err))
+			exit(1)
 		}
+		logger.Info("连接测试完成")
+		exitOnFailedChecks(report)
+		return
 	}
 
-	// 3. 测试PostgreSQL连接
+	fmt.Println("🔗 数据库连接测试")
 	fmt.Println()
-	fmt.Println("📋 PostgreSQL数据库连接测试")
-	fmt.Println("──────────────────────────")
-	
-	pgResult := tester.TestPostgreSQLConnection(&cfg.PostgreSQL)
-	
-	fmt.Printf("状态: %s\n", pgResult.Message)
-	if pgResult.Success {
-		fmt.Printf("响应时间: %v\n", pgResult.ResponseTime)
-		if pgResult.Details != "" {
-			fmt.Printf("详情: %s\n", pgResult.Details)
-		}
-	} else {
-		if pgResult.Error != "" {
-			fmt.Printf("错误: %s\n", pgResult.Error)
-		}
-		if pgResult.Details != "" && checkVerbose {
-			fmt.Printf("详细信息: %s\n", pgResult.Details)
-		}
-		
-		fmt.Println()
-		fmt.Println("💡 解决建议:")
-		fmt.Println("  1. 检查PostgreSQL服务是否运行")
-		fmt.Println("  2. 验证主机名和端口是否正确")
-		fmt.Println("  3. 确认用户名和密码是否正确")
-		fmt.Println("  4. 检查防火墙设置")
+	report.RenderText()
+
+	// 配置文件缺失时无需输出总结
+	if report.Find("config") != nil {
+		exit(1)
 	}
 
-	// 4. 连接测试总结
+	// 3. 连接测试总结
 	fmt.Println()
 	fmt.Println("📊 连接测试总结")
 	fmt.Println("─────────────────")
 	
-	if oracleResult.Success && pgResult.Success {
+	if report.Count(checkStatusFail) == 0 {
 		fmt.Println("✅ 所有数据库连接测试通过")
 		fmt.Println("🚀 您可以开始执行数据库迁移了")
 		fmt.Println()
@@ -263,6 +227,212 @@ func runCheckConn(cmd *cobra.Command, args []string) {
 	}
 	
 	logger.Info("连接测试完成")
+	exitOnFailedChecks(report)
+}
+
+// collectConnectionChecks 收集数据库连接测试结果
+func collectConnectionChecks(report *CheckReport) {
+	// 加载配置文件
+	configPath := getConfigPath()
+	if configPath == "" {
+		report.Section("配置文件检查").Add("config", checkStatusFail, "配置文件未找到", "",
+			"ora2pg-admin 初始化 [项目名称]",
+			"ora2pg-admin 配置 数据库")
+		return
+	}
+
+	manager := config.NewManager()
+	if err := manager.LoadConfig(configPath); err != nil {
+		report.Section("配置文件检查").Add("config", checkStatusFail, "配置文件加载失败", err.Error())
+		return
+	}
+
+	cfg := manager.GetConfig()
+	tester := oracle.NewConnectionTester()
+	tester.SetClientConfig(&cfg.OracleClient)
+
+	// 测试Oracle连接；连接配置指向导出文件时无需尝试连接，每次测试的响应时间记录到连接测试历史
+	var samples []oracle.ConnectionSample
+	var oracleResult *oracle.ConnectionResult
+	field, value, isDumpFile := config.DumpFileReference(&cfg.Oracle)
+	if !isDumpFile {
+		oracleResult = tester.TestOracleConnection(&cfg.Oracle)
+		samples = append(samples, oracle.NewConnectionSample(oracle.ConnectionTargetOracle, oracleEndpoint(&cfg.Oracle), oracleResult))
+	}
+
+	oracleConnected := false
+	oracleSection := report.Section("Oracle数据库连接测试")
+	if isDumpFile {
+		oracleSection.Add("oracle_connection", checkStatusFail, "❌ Oracle连接配置指向了导出文件，而不是在线数据库",
+			fmt.Sprintf("%s: %s", field, value), config.DumpFileSuggestions...)
+	} else if oracleResult.Success {
+		oracleConnected = true
+		oracleSection.Add("oracle_connection", checkStatusPass, oracleResult.Message,
+			formatConnectionDetails(oracleResult))
+	} else {
+		// 附带连接诊断信息
+		var diagnostics []string
+		for _, diag := range tester.GetConnectionDiagnostics(&cfg.Oracle, oracleResult) {
+			if strings.TrimSpace(diag) != "" {
+				diagnostics = append(diagnostics, diag)
+			}
+		}
+		details := formatConnectionDetails(oracleResult)
+		if len(diagnostics) > 0 {
+			details = strings.TrimSpace(details + "\n🔍 连接诊断:\n" + strings.Join(diagnostics, "\n"))
+		}
+		var suggestions []string
+		if !checkNoProbe {
+			utils.GetGlobalLogger().Info("Oracle连接失败，开始自动探测连接参数")
+			probe := oracle.NewConnectionProber(tester).Probe(context.Background(), &cfg.Oracle, oracleResult)
+			var probeDetails string
+			probeDetails, suggestions = formatProbeReport(probe)
+			if probeDetails != "" {
+				details = strings.TrimSpace(details + "\n" + probeDetails)
+			}
+		}
+		oracleSection.Add("oracle_connection", checkStatusFail, oracleResult.Message, details, suggestions...)
+	}
+
+	// 测试PostgreSQL连接
+	pgSection := report.Section("PostgreSQL数据库连接测试")
+	pgResult := tester.TestPostgreSQLConnection(&cfg.PostgreSQL)
+	samples = append(samples, oracle.NewConnectionSample(oracle.ConnectionTargetPostgreSQL, postgresEndpoint(&cfg.PostgreSQL), pgResult))
+	saveConnectionSamples(samples)
+	if pgResult.Success {
+		pgSection.Add("postgresql_connection", checkStatusPass, pgResult.Message,
+			formatConnectionDetails(pgResult))
+		collectPrivilegeCheck(pgSection, cfg)
+	} else {
+		pgSection.Add("postgresql_connection", checkStatusFail, pgResult.Message,
+			formatConnectionDetails(pgResult),
+			"检查PostgreSQL服务是否运行",
+			"验证主机名和端口是否正确",
+			"确认用户名和密码是否正确",
+			"检查防火墙设置")
+	}
+
+	// 配置了多个目标库时分别测试其他目标
+	collectTargetConnectionChecks(report, tester, cfg)
+
+	if checkNetwork || checkBandwidth {
+		collectNetworkChecks(report, cfg, oracleResult, pgResult)
+	}
+
+	// 两端都能连接时对比时区和日期格式
+	if oracleConnected && pgResult.Success {
+		collectTimeZoneChecks(report, cfg)
+	}
+}
+
+// formatConnectionDetails 格式化连接测试详情
+func formatConnectionDetails(result *oracle.ConnectionResult) string {
+	var lines []string
+	if result.Success {
+		lines = append(lines, fmt.Sprintf("响应时间: %v", result.ResponseTime))
+		if result.Details != "" {
+			lines = append(lines, fmt.Sprintf("详情: %s", result.Details))
+		}
+	} else {
+		if result.Error != "" {
+			lines = append(lines, fmt.Sprintf("错误: %s", result.Error))
+		}
+		if result.Details != "" && (checkVerbose || isJSONOutput()) {
+			lines = append(lines, fmt.Sprintf("详细信息: %s", result.Details))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatProbeReport 格式化连接自诊断结果，返回探测过程和纠错建议
+func formatProbeReport(report *oracle.ProbeReport) (string, []string) {
+	if report == nil || len(report.Attempts) == 0 {
+		return "", nil
+	}
+	lines := []string{"🔎 连接自诊断（自动探测，仅供参考，未修改配置）:"}
+	for _, attempt := range report.Attempts {
+		lines = append(lines, "  - "+attempt)
+	}
+	if len(report.Suggestions) == 0 {
+		lines = append(lines, "  未探测到可用的替代连接参数")
+	}
+
+	suggestions := make([]string, len(report.Suggestions))
+	for i, suggestion := range report.Suggestions {
+		suggestions[i] = "[自动探测] " + suggestion.Message
+	}
+	return strings.Join(lines, "\n"), suggestions
+}
+
+// collectOracleClientCheck 收集Oracle客户端检查结果
+func collectOracleClientCheck(section *checkSection, detector *oracle.ClientDetector, statusReport *oracle.ClientStatusReport) {
+	status := checkStatusFail
+	switch statusReport.Status {
+	case "COMPATIBLE":
+		status = checkStatusPass
+	case "INCOMPATIBLE", "UNKNOWN_VERSION":
+		status = checkStatusWarn
+	}
+
+	// 客户端详细信息
+	var details []string
+	if home := detector.ConfiguredHome(); home != "" {
+		details = append(details, fmt.Sprintf("来源: 配置文件指定 (oracle_client.home: %s)", home))
+	} else {
+		details = append(details, "来源: 自动检测")
+	}
+	if statusReport.ClientInfo.Installed {
+		info := statusReport.ClientInfo
+		if info.Version != "" {
+			details = append(details, fmt.Sprintf("版本: %s", info.Version))
+		}
+		if info.Home != "" {
+			details = append(details, fmt.Sprintf("安装路径: %s", info.Home))
+		}
+		if info.InstantClient {
+			details = append(details, "类型: Instant Client")
+		} else {
+			details = append(details, "类型: 完整客户端")
+		}
+		details = append(details, fmt.Sprintf("架构: %s", info.Architecture))
+	}
+
+	// 未安装时附带安装指导
+	if statusReport.Status == "NOT_INSTALLED" {
+		guide := detector.GetInstallationGuide()
+		details = append(details, "📥 安装指导:", fmt.Sprintf("下载地址: %s", guide.DownloadURL), "安装步骤:")
+		details = append(details, guide.Instructions...)
+	}
+
+	section.Add("oracle_client", status, statusReport.Message, strings.Join(details, "\n"),
+		statusReport.Recommendations...)
+}
+
+// collectOra2pgCheck 收集ora2pg工具检查结果
+func collectOra2pgCheck(section *checkSection) {
+	if !checkOra2pgTool() {
+		section.Add("ora2pg", checkStatusFail, "ora2pg工具: 未找到", "",
+			"确认ora2pg已正确安装",
+			"将ora2pg添加到PATH环境变量",
+			"检查Perl环境是否正确配置")
+		return
+	}
+
+	raw, version := getOra2pgVersion()
+	details := ""
+	if (checkVerbose || isJSONOutput()) && raw != "" {
+		details = fmt.Sprintf("版本: %s", raw)
+	}
+
+	// 旧版本与最新版本的指令差异由适配层自动处理，提示用户哪些配置不会生效
+	if adjustments := service.NewOra2pgCompat(version).Adjustments(); len(adjustments) > 0 {
+		details = strings.TrimSpace(fmt.Sprintf("版本: %s\n兼容适配: %s", raw, strings.Join(adjustments, "；")))
+		section.Add("ora2pg", checkStatusWarn, fmt.Sprintf("ora2pg工具: 已安装（%s，部分指令将自动适配）", version), details,
+			"不支持的指令会在生成的ora2pg.conf中注释掉，对应配置不会生效",
+			"建议升级到最新版本的ora2pg")
+		return
+	}
+	section.Add("ora2pg", checkStatusPass, "ora2pg工具: 已安装并可用", details)
 }
 
 // checkOra2pgTool 检查ora2pg工具
@@ -272,145 +442,128 @@ func checkOra2pgTool() bool {
 	return err == nil
 }
 
-// getOra2pgVersion 获取ora2pg版本
-func getOra2pgVersion() string {
-	cmd := exec.Command("ora2pg", "--version")
-	output, err := cmd.Output()
-	if err != nil {
-		return ""
-	}
-
-	// 解析版本信息
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "ora2pg") && strings.Contains(line, "v") {
-			return line
-		}
+// getOra2pgVersion 获取ora2pg版本行和解析出的版本号，无法获取时返回空字符串
+func getOra2pgVersion() (string, service.Ora2pgVersion) {
+	raw, version, err := service.DetectOra2pgVersion(context.Background(), nil)
+	if err != nil && raw == "" {
+		return "", service.Ora2pgVersion{}
 	}
-
-	return strings.TrimSpace(string(output))
+	return raw, version
 }
 
-// checkSystemEnvironment 检查系统环境
-func checkSystemEnvironment() {
+// collectSystemEnvironment 收集系统环境检查结果
+func collectSystemEnvironment(section *checkSection) {
 	// 检查ORACLE_HOME环境变量
 	if oracleHome := os.Getenv("ORACLE_HOME"); oracleHome != "" {
-		fmt.Printf("✅ ORACLE_HOME: %s\n", oracleHome)
+		section.Add("env_oracle_home", checkStatusPass, fmt.Sprintf("ORACLE_HOME: %s", oracleHome), "")
 	} else {
-		fmt.Println("⚠️ ORACLE_HOME: 未设置")
+		section.Add("env_oracle_home", checkStatusWarn, "ORACLE_HOME: 未设置", "")
 	}
 
 	// 检查PATH环境变量
 	if path := os.Getenv("PATH"); path != "" {
-		fmt.Println("✅ PATH: 已设置")
-		if checkVerbose {
-			fmt.Printf("   内容: %s\n", path)
+		details := ""
+		if checkVerbose || isJSONOutput() {
+			details = fmt.Sprintf("内容: %s", path)
 		}
+		section.Add("env_path", checkStatusPass, "PATH: 已设置", details)
 	} else {
-		fmt.Println("❌ PATH: 未设置")
+		section.Add("env_path", checkStatusFail, "PATH: 未设置", "")
 	}
 
 	// 检查LD_LIBRARY_PATH (Linux/macOS)
 	if runtime.GOOS != "windows" {
 		if ldPath := os.Getenv("LD_LIBRARY_PATH"); ldPath != "" {
-			fmt.Printf("✅ LD_LIBRARY_PATH: %s\n", ldPath)
+			section.Add("env_ld_library_path", checkStatusPass, fmt.Sprintf("LD_LIBRARY_PATH: %s", ldPath), "")
 		} else {
-			fmt.Println("⚠️ LD_LIBRARY_PATH: 未设置")
+			section.Add("env_ld_library_path", checkStatusWarn, "LD_LIBRARY_PATH: 未设置", "")
 		}
 	}
 
 	// 检查当前工作目录
 	if wd, err := os.Getwd(); err == nil {
-		fmt.Printf("✅ 工作目录: %s\n", wd)
+		section.Add("working_dir", checkStatusPass, fmt.Sprintf("工作目录: %s", wd), "")
 	} else {
-		fmt.Printf("❌ 工作目录: 获取失败 (%v)\n", err)
+		section.Add("working_dir", checkStatusFail, fmt.Sprintf("工作目录: 获取失败 (%v)", err), "")
 	}
 }
 
-// checkProjectEnvironment 检查项目环境
-func checkProjectEnvironment() {
+// collectProjectEnvironment 收集项目环境检查结果
+func collectProjectEnvironment(section *checkSection) {
 	fileUtils := utils.NewFileUtils()
 
 	// 检查是否在项目目录中
-	if fileUtils.DirExists(".ora2pg-admin") {
-		fmt.Println("✅ 项目环境: 已初始化")
-
-		// 检查配置文件
-		configPath := filepath.Join(".ora2pg-admin", "config.yaml")
-		if fileUtils.FileExists(configPath) {
-			fmt.Println("✅ 配置文件: 存在")
-
-			// 验证配置文件
-			manager := config.NewManager()
-			if err := manager.LoadConfig(configPath); err == nil {
-				validator := config.NewValidator()
-				result := validator.ValidateConfig(manager.GetConfig())
-				if result.Valid {
-					fmt.Println("✅ 配置验证: 通过")
-				} else {
-					fmt.Printf("⚠️ 配置验证: 发现 %d 个问题\n", len(result.Errors))
-					if checkVerbose {
-						for i, err := range result.Errors {
-							fmt.Printf("   %d. %s\n", i+1, err.Error())
-						}
+	if !fileUtils.DirExists(".ora2pg-admin") {
+		section.Add("project", checkStatusFail, "项目环境: 未初始化", "",
+			"ora2pg-admin 初始化 [项目名称]")
+		return
+	}
+	section.Add("project", checkStatusPass, "项目环境: 已初始化", "")
+
+	// 检查配置文件
+	configPath := config.ProjectConfigPath(".")
+	if fileUtils.FileExists(configPath) {
+		section.Add("config_file", checkStatusPass, "配置文件: 存在", "")
+
+		// 验证配置文件
+		manager := config.NewManager()
+		if err := manager.LoadConfig(configPath); err == nil {
+			validator := newConfigValidator(section)
+			result := validator.ValidateConfig(manager.GetConfig())
+			if result.Valid {
+				section.Add("config_validation", checkStatusPass, "配置验证: 通过", "")
+			} else {
+				var details []string
+				if checkVerbose || isJSONOutput() {
+					for i, err := range result.Errors {
+						details = append(details, fmt.Sprintf("%d. %s", i+1, err.Error()))
 					}
 				}
-			} else {
-				fmt.Printf("❌ 配置文件: 解析失败 (%v)\n", err)
+				section.Add("config_validation", checkStatusWarn,
+					fmt.Sprintf("配置验证: 发现 %d 个问题", len(result.Errors)), strings.Join(details, "\n"))
 			}
+			collectConsistencyWarnings(section, result)
 		} else {
-			fmt.Println("❌ 配置文件: 不存在")
+			section.Add("config_validation", checkStatusFail, fmt.Sprintf("配置文件: 解析失败 (%v)", err), "")
 		}
+	} else {
+		section.Add("config_file", checkStatusFail, "配置文件: 不存在", "")
+	}
 
-		// 检查输出目录
-		if fileUtils.DirExists("output") {
-			fmt.Println("✅ 输出目录: 存在")
-		} else {
-			fmt.Println("⚠️ 输出目录: 不存在")
-		}
+	// 检查输出目录
+	if fileUtils.DirExists("output") {
+		section.Add("output_dir", checkStatusPass, "输出目录: 存在", "")
+	} else {
+		section.Add("output_dir", checkStatusWarn, "输出目录: 不存在", "")
+	}
 
-		// 检查日志目录
-		if fileUtils.DirExists("logs") {
-			fmt.Println("✅ 日志目录: 存在")
-		} else {
-			fmt.Println("⚠️ 日志目录: 不存在")
-		}
+	// 检查日志目录
+	if fileUtils.DirExists("logs") {
+		section.Add("logs_dir", checkStatusPass, "日志目录: 存在", "")
 	} else {
-		fmt.Println("❌ 项目环境: 未初始化")
-		fmt.Println()
-		fmt.Println("💡 请使用以下命令初始化项目:")
-		fmt.Println("   ora2pg-admin 初始化 [项目名称]")
+		section.Add("logs_dir", checkStatusWarn, "日志目录: 不存在", "")
 	}
 }
 
 // provideSummaryAndSuggestions 提供总结和建议
-func provideSummaryAndSuggestions(statusReport *oracle.ClientStatusReport) {
+func provideSummaryAndSuggestions(report *CheckReport) {
 	issues := []string{}
 	suggestions := []string{}
 
 	// 检查Oracle客户端状态
-	switch statusReport.Status {
-	case "NOT_INSTALLED":
-		issues = append(issues, "Oracle客户端未安装")
-		suggestions = append(suggestions, "安装Oracle Instant Client")
-	case "INCOMPATIBLE":
-		issues = append(issues, "Oracle客户端版本可能不兼容")
-		suggestions = append(suggestions, "升级到支持的Oracle版本")
-	case "UNKNOWN_VERSION":
-		issues = append(issues, "无法确定Oracle客户端版本")
-		suggestions = append(suggestions, "检查Oracle客户端安装完整性")
+	if result := report.Find("oracle_client"); result != nil && result.Status != checkStatusPass {
+		issues = append(issues, result.Message)
+		suggestions = append(suggestions, "安装或升级到支持的Oracle客户端版本")
 	}
 
 	// 检查ora2pg工具
-	if !checkOra2pgTool() {
+	if result := report.Find("ora2pg"); result != nil && result.Status == checkStatusFail {
 		issues = append(issues, "ora2pg工具未找到")
 		suggestions = append(suggestions, "安装ora2pg工具并添加到PATH")
 	}
 
 	// 检查项目环境
-	fileUtils := utils.NewFileUtils()
-	if !fileUtils.DirExists(".ora2pg-admin") {
+	if result := report.Find("project"); result != nil && result.Status == checkStatusFail {
 		issues = append(issues, "项目未初始化")
 		suggestions = append(suggestions, "使用 'ora2pg-admin 初始化' 创建项目")
 	}
@@ -453,7 +606,7 @@ func getConfigPath() string {
 
 	// 2. 检查当前目录的项目配置
 	fileUtils := utils.NewFileUtils()
-	projectConfig := filepath.Join(".ora2pg-admin", "config.yaml")
+	projectConfig := config.ProjectConfigPath(".")
 	if fileUtils.FileExists(projectConfig) {
 		return projectConfig
 	}
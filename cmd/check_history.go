@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/utils"
+)
+
+// connectionHistoryTargets 连接测试历史中展示的数据库及其名称
+var connectionHistoryTargets = []struct {
+	target string
+	title  string
+}{
+	{oracle.ConnectionTargetOracle, "Oracle数据库"},
+	{oracle.ConnectionTargetPostgreSQL, "PostgreSQL数据库"},
+}
+
+// oracleEndpoint Oracle连接的地址，用于区分连接配置变化前后的记录
+func oracleEndpoint(cfg *config.OracleConfig) string {
+	address := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	if cfg.Service != "" {
+		return address + "/" + cfg.Service
+	}
+	return address + ":" + cfg.SID
+}
+
+// postgresEndpoint PostgreSQL连接的地址
+func postgresEndpoint(cfg *config.PostgreConfig) string {
+	return net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)) + "/" + cfg.Database
+}
+
+// saveConnectionSamples 记录本次连接测试的响应时间，记录失败不影响测试结果
+func saveConnectionSamples(samples []oracle.ConnectionSample) {
+	if err := oracle.AppendConnectionSamples(oracle.DefaultConnectionHistoryPath, samples...); err != nil {
+		utils.GetGlobalLogger().Warnf("记录连接测试历史失败: %v", err)
+	}
+}
+
+// showConnectionHistory 显示最近几次连接测试的响应时间趋势
+func showConnectionHistory() error {
+	trends := make(map[string]*oracle.ConnectionTrend, len(connectionHistoryTargets))
+	for _, item := range connectionHistoryTargets {
+		samples, err := oracle.LoadConnectionSamples(oracle.DefaultConnectionHistoryPath, item.target, checkConnLimit)
+		if err != nil {
+			return err
+		}
+		trends[item.target] = oracle.AnalyzeConnectionTrend(samples)
+	}
+
+	if isJSONOutput() {
+		data, err := json.MarshalIndent(trends, "", "  ")
+		if err != nil {
+			return fmt.Errorf("序列化连接测试历史失败: %v", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Println("📈 数据库连接响应时间趋势")
+	for _, item := range connectionHistoryTargets {
+		fmt.Println()
+		fmt.Println(item.title)
+		fmt.Println("─────────────────")
+		printConnectionTrend(trends[item.target])
+	}
+	return nil
+}
+
+// printConnectionTrend 显示单个数据库的趋势图、统计和逐次记录
+func printConnectionTrend(trend *oracle.ConnectionTrend) {
+	if len(trend.Samples) == 0 {
+		fmt.Println("暂无记录，执行 'ora2pg-admin 检查 连接' 后会自动记录响应时间")
+		return
+	}
+
+	fmt.Printf("趋势: %s（最近 %d 次，从左到右由旧到新）\n", trend.Sparkline(), len(trend.Samples))
+	if trend.Failures < len(trend.Samples) {
+		fmt.Printf("响应时间: 最小 %v / 中位数 %v / 平均 %v / 最大 %v\n",
+			roundMillis(trend.Min), roundMillis(trend.Median), roundMillis(trend.Average), roundMillis(trend.Max))
+	}
+	if trend.Threshold > 0 {
+		fmt.Printf("异常阈值: %v（中位数的2倍，且至少慢50ms）\n", roundMillis(trend.Threshold))
+	}
+
+	endpoint := ""
+	for i, sample := range trend.Samples {
+		if sample.Endpoint != endpoint {
+			endpoint = sample.Endpoint
+			fmt.Printf("  📍 %s\n", endpoint)
+		}
+		timestamp := sample.Time.Local().Format("2006-01-02 15:04:05")
+		switch {
+		case !sample.Success:
+			fmt.Printf("  ❌ %s  连接失败: %s\n", timestamp, sample.Error)
+		case trend.Anomalies[i]:
+			fmt.Printf("  ⚠️ %s  %v  响应明显变慢\n", timestamp, roundMillis(sample.ResponseTime))
+		default:
+			fmt.Printf("  ✅ %s  %v\n", timestamp, roundMillis(sample.ResponseTime))
+		}
+	}
+
+	if anomalies := trend.AnomalyCount(); anomalies > 0 {
+		fmt.Printf("⚠️ %d 次异常（其中连接失败 %d 次），请检查网络和数据库负载\n", anomalies, trend.Failures)
+	}
+}
+
+// roundMillis 响应时间保留到毫秒
+func roundMillis(d time.Duration) time.Duration {
+	return d.Round(time.Millisecond)
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/postgres"
+)
+
+// privilegeCheckTimeout 目标库权限查询的超时时间
+const privilegeCheckTimeout = 30 * time.Second
+
+// collectPrivilegeCheck 检查迁移账号在目标库的权限，识别托管数据库的限制，PostgreSQL连接测试通过后执行
+func collectPrivilegeCheck(section *checkSection, cfg *config.ProjectConfig) {
+	ctx, cancel := context.WithTimeout(context.Background(), privilegeCheckTimeout)
+	defer cancel()
+
+	privileges, err := postgres.InspectPrivileges(ctx, postgres.NewPSQLRunner(&cfg.PostgreSQL))
+	if err != nil {
+		section.Add("postgresql_privileges", checkStatusWarn, "⚠️  无法查询目标库账号权限，跳过托管数据库检查", err.Error())
+		return
+	}
+	addPrivilegeCheck(section, &cfg.PostgreSQL, privileges)
+}
+
+// addPrivilegeCheck 按账号权限和托管数据库模式给出检查结果
+func addPrivilegeCheck(section *checkSection, pgConfig *config.PostgreConfig, privileges *postgres.TargetPrivileges) {
+	if privileges.Superuser {
+		section.Add("postgresql_privileges", checkStatusPass,
+			fmt.Sprintf("✅ 迁移账号 %s 为超级用户", pgConfig.Username), "")
+		return
+	}
+
+	target := "目标库"
+	if privileges.Managed() {
+		target = privileges.Provider + " 托管数据库"
+	}
+	details := "受限的操作:\n• " + strings.Join(privileges.Restrictions(), "\n• ")
+	suggestions := managedSuggestions(privileges)
+
+	if !pgConfig.Managed {
+		section.Add("postgresql_privileges", checkStatusWarn,
+			fmt.Sprintf("⚠️  迁移账号在%s中不是超级用户，但未启用托管数据库模式", target), details,
+			append([]string{"在 .ora2pg-admin/config.yaml 的 postgresql 中设置 managed: true，迁移时自动跳过需要超级用户的操作"}, suggestions...)...)
+		return
+	}
+
+	status, message := checkStatusPass, fmt.Sprintf("✅ 已启用托管数据库模式（%s）", target)
+	if !privileges.CreateSchema || (privileges.AdminRole != "" && !privileges.AdminMember) {
+		status, message = checkStatusWarn, fmt.Sprintf("⚠️  已启用托管数据库模式，但迁移账号在%s中权限不足", target)
+	}
+	section.Add("postgresql_privileges", status, message, details, suggestions...)
+}
+
+// managedSuggestions 云环境适配建议
+func managedSuggestions(privileges *postgres.TargetPrivileges) []string {
+	var suggestions []string
+	if privileges.AdminRole != "" && !privileges.AdminMember {
+		suggestions = append(suggestions, fmt.Sprintf("使用托管服务的管理账号授予角色: GRANT %s TO <迁移账号>;", privileges.AdminRole))
+	}
+	if !privileges.CreateSchema {
+		suggestions = append(suggestions, "授予创建Schema的权限: GRANT CREATE ON DATABASE <数据库> TO <迁移账号>;")
+	}
+	if !privileges.CreateRole {
+		suggestions = append(suggestions, "迁移 GRANT 类型前授予 CREATEROLE，或从迁移类型中去掉 GRANT 并由DBA手工创建角色")
+	}
+	suggestions = append(suggestions, "需要的扩展请由控制台或管理账号预先安装（CREATE EXTENSION IF NOT EXISTS ...），可放在迁移前脚本中")
+	return suggestions
+}
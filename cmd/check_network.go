@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/postgres"
+)
+
+// networkCheckTimeout 网络质量测量（含带宽测试）的超时时间
+const networkCheckTimeout = 5 * time.Minute
+
+// 两端延迟相差超过 networkPlacementFactor 倍且至少 networkPlacementDelta 时，提示迁移机的部署位置
+const (
+	networkPlacementFactor = 2
+	networkPlacementDelta  = 20 * time.Millisecond
+)
+
+// networkTarget 测量网络质量的一端数据库
+type networkTarget struct {
+	check    string
+	name     string
+	host     string
+	port     int
+	result   *oracle.ConnectionResult
+	transfer func(ctx context.Context, size int64) error
+}
+
+// collectNetworkChecks 测量到源库和目标库的网络延迟，指定 --bandwidth 且能登录时估算带宽
+func collectNetworkChecks(report *CheckReport, cfg *config.ProjectConfig, oracleResult, pgResult *oracle.ConnectionResult) {
+	section := report.Section("网络质量测量")
+
+	ctx, cancel := context.WithTimeout(context.Background(), networkCheckTimeout)
+	defer cancel()
+
+	var targets []networkTarget
+	// 连接配置指向导出文件时 oracleResult 为 nil
+	if oracleResult != nil && cfg.Oracle.Host != "" {
+		targets = append(targets, networkTarget{
+			check: "oracle_network", name: "Oracle", host: cfg.Oracle.Host, port: cfg.Oracle.Port, result: oracleResult,
+			transfer: oracle.NewSQLPlusRunner(&cfg.Oracle, &cfg.OracleClient).TransferTest,
+		})
+	}
+	targets = append(targets, networkTarget{
+		check: "postgresql_network", name: "PostgreSQL", host: cfg.PostgreSQL.Host, port: cfg.PostgreSQL.Port, result: pgResult,
+		transfer: postgres.NewPSQLRunner(&cfg.PostgreSQL).TransferTest,
+	})
+
+	measured := make(map[string]*oracle.NetworkQuality)
+	for _, target := range targets {
+		quality, err := oracle.MeasureLatency(ctx, target.host, target.port, checkNetworkSamples)
+		if err != nil {
+			section.Add(target.check, checkStatusWarn, fmt.Sprintf("⚠️  无法测量到%s的网络延迟", target.name), err.Error())
+			continue
+		}
+		if checkBandwidth && target.result.Success && quality.Reachable() {
+			size := int64(checkBandwidthSize) << 20
+			quality.BandwidthBytes = size
+			quality.Bandwidth, err = oracle.MeasureBandwidth(ctx, size, target.transfer)
+			if err != nil {
+				quality.BandwidthError = err.Error()
+			}
+		}
+		target.result.Network = quality
+		addNetworkCheck(section, target.check, target.name, quality, &cfg.Migration)
+		measured[target.name] = quality
+	}
+
+	if ora, pg := measured["Oracle"], measured["PostgreSQL"]; ora != nil && pg != nil {
+		addNetworkPlacementCheck(section, ora, pg)
+	}
+}
+
+// addNetworkCheck 添加一端的网络质量检查项
+func addNetworkCheck(section *checkSection, check, name string, quality *oracle.NetworkQuality, migration *config.MigrationConfig) {
+	if !quality.Reachable() {
+		section.Add(check, checkStatusFail, fmt.Sprintf("❌ 到%s的 %d 次TCP连接全部失败", name, len(quality.Samples)),
+			fmt.Sprintf("地址: %s", quality.Address),
+			"检查防火墙和安全组是否放行该端口",
+			"确认数据库监听正在运行")
+		return
+	}
+
+	details := []string{
+		fmt.Sprintf("地址: %s", quality.Address),
+		fmt.Sprintf("延迟: 最小 %v / 平均 %v / 最大 %v，抖动 %v（%d 次，失败 %d 次）",
+			roundLatency(quality.Min), roundLatency(quality.Average), roundLatency(quality.Max),
+			roundLatency(quality.Jitter), len(quality.Samples), quality.Failures),
+	}
+	switch {
+	case quality.BandwidthError != "":
+		details = append(details, fmt.Sprintf("带宽: 测量失败: %s", quality.BandwidthError))
+	case quality.Bandwidth > 0:
+		details = append(details, fmt.Sprintf("带宽: 约 %s（传输 %d MB）", formatBandwidth(quality.Bandwidth), quality.BandwidthBytes>>20))
+	}
+
+	status, icon := checkStatusPass, "✅"
+	if quality.Degraded() {
+		status, icon = checkStatusWarn, "⚠️ "
+	}
+	section.Add(check, status,
+		fmt.Sprintf("%s 到%s的平均延迟 %v（%s）", icon, name, roundLatency(quality.Average), quality.Level()),
+		strings.Join(details, "\n"), quality.Advice(migration)...)
+}
+
+// addNetworkPlacementCheck 两端延迟相差悬殊时提示把迁移机部署在靠近延迟较高的一端
+func addNetworkPlacementCheck(section *checkSection, ora, pg *oracle.NetworkQuality) {
+	if !ora.Reachable() || !pg.Reachable() {
+		return
+	}
+	far, near := "Oracle", "PostgreSQL"
+	high, low := ora.Average, pg.Average
+	if pg.Average > ora.Average {
+		far, near = near, far
+		high, low = low, high
+	}
+	if high < low*networkPlacementFactor || high-low < networkPlacementDelta {
+		return
+	}
+	section.Add("network_placement", checkStatusWarn,
+		fmt.Sprintf("⚠️  到%s的延迟 (%v) 明显高于到%s (%v)", far, roundLatency(high), near, roundLatency(low)),
+		"ora2pg 从源库读取数据后写入目标库，数据在两段网络上各传输一次",
+		fmt.Sprintf("把迁移机部署在靠近%s的一侧，可减少高延迟链路上的往返次数", far))
+}
+
+// roundLatency 延迟保留到0.1毫秒
+func roundLatency(d time.Duration) time.Duration {
+	return d.Round(100 * time.Microsecond)
+}
+
+// formatBandwidth 格式化带宽（字节/秒）
+func formatBandwidth(bytesPerSecond float64) string {
+	if bytesPerSecond >= 1<<20 {
+		return fmt.Sprintf("%.1f MB/s", bytesPerSecond/(1<<20))
+	}
+	return fmt.Sprintf("%.0f KB/s", bytesPerSecond/(1<<10))
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+)
+
+func TestAddNetworkCheck(t *testing.T) {
+	ms := time.Millisecond
+	migration := &config.MigrationConfig{ParallelJobs: 4, BatchSize: 10000}
+
+	report := newCheckReport()
+	section := report.Section("网络")
+	addNetworkCheck(section, "oracle_network", "Oracle", &oracle.NetworkQuality{
+		Address: "db:1521", Samples: []time.Duration{ms, 2 * ms}, Min: ms, Average: 1500 * time.Microsecond, Max: 2 * ms, Jitter: ms,
+		Bandwidth: 50 << 20, BandwidthBytes: 8 << 20,
+	}, migration)
+	addNetworkCheck(section, "postgresql_network", "PostgreSQL", &oracle.NetworkQuality{
+		Address: "pg:5432", Samples: []time.Duration{60 * ms, 0}, Failures: 1, Min: 60 * ms, Average: 60 * ms, Max: 60 * ms,
+		BandwidthError: "psql执行失败",
+	}, migration)
+
+	ora := report.Find("oracle_network")
+	require.NotNil(t, ora)
+	assert.Equal(t, checkStatusPass, ora.Status)
+	assert.Contains(t, ora.Message, "1.5ms（同机房）")
+	assert.Contains(t, ora.Details, "带宽: 约 50.0 MB/s（传输 8 MB）")
+
+	pg := report.Find("postgresql_network")
+	require.NotNil(t, pg)
+	assert.Equal(t, checkStatusWarn, pg.Status)
+	assert.Contains(t, pg.Message, "跨地域")
+	assert.Contains(t, pg.Details, "失败 1 次")
+	assert.Contains(t, pg.Details, "带宽: 测量失败: psql执行失败")
+	assert.NotEmpty(t, pg.Suggestions)
+
+	// 全部连接失败
+	report = newCheckReport()
+	addNetworkCheck(report.Section("网络"), "oracle_network", "Oracle", &oracle.NetworkQuality{
+		Address: "db:1521", Samples: []time.Duration{0, 0}, Failures: 2,
+	}, migration)
+	assert.Equal(t, checkStatusFail, report.Find("oracle_network").Status)
+}
+
+func TestAddNetworkPlacementCheck(t *testing.T) {
+	ms := time.Millisecond
+	near := &oracle.NetworkQuality{Samples: []time.Duration{2 * ms}, Average: 2 * ms}
+	far := &oracle.NetworkQuality{Samples: []time.Duration{80 * ms}, Average: 80 * ms}
+
+	report := newCheckReport()
+	addNetworkPlacementCheck(report.Section("网络"), far, near)
+	placement := report.Find("network_placement")
+	require.NotNil(t, placement)
+	assert.Contains(t, placement.Message, "到Oracle的延迟 (80ms) 明显高于到PostgreSQL (2ms)")
+	assert.Contains(t, placement.Suggestions[0], "靠近Oracle")
+
+	// 两端延迟接近时不提示
+	report = newCheckReport()
+	addNetworkPlacementCheck(report.Section("网络"), near, &oracle.NetworkQuality{Samples: []time.Duration{15 * ms}, Average: 15 * ms})
+	assert.Nil(t, report.Find("network_placement"))
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+// readinessTimeout 就绪度评估查询源库的超时时间
+const readinessTimeout = 5 * time.Minute
+
+var readinessLargeRows int64
+
+// checkReadinessCmd 迁移就绪度评分命令
+var checkReadinessCmd = &cobra.Command{
+	Use:   "就绪度",
+	Short: "评估源库的迁移就绪度",
+	Long: `查询源库的对象数量、难迁移类型、PL/SQL代码、统计信息、表名和大表，
+按评分模型给出 0-100 的迁移就绪度评分（越高越容易迁移）和各维度说明。
+
+评分模型（权重）：
+• 难迁移类型 25%：含 LONG、LONG RAW、BFILE、XMLTYPE、自定义对象类型列或嵌套表的表占比
+• PL/SQL代码 20%：包、存储过程、函数、触发器、类型占全部对象的比例
+• 对象规模   15%：对象总数
+• 统计信息   15%：统计信息缺失或过期的表占比
+• 大表数量   15%：行数达到阈值的表数量
+• 命名合规   10%：保留字、超长或需要加引号的表名占比
+
+示例：
+  ora2pg-admin 检查 就绪度
+  ora2pg-admin 检查 就绪度 --large-rows 5000000 --output json`,
+	Run: runCheckReadiness,
+}
+
+func init() {
+	checkCmd.AddCommand(checkReadinessCmd)
+
+	checkReadinessCmd.Flags().Int64Var(&readinessLargeRows, "large-rows", oracle.DefaultLargeTableRows, "行数达到该值的表视为大表")
+}
+
+// runCheckReadiness 评估并输出迁移就绪度
+func runCheckReadiness(cmd *cobra.Command, args []string) {
+	if err := prepareCheckOutput(); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	manager, _, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	cfg := manager.GetConfig()
+	schema := cfg.Oracle.Schema
+	if schema == "" {
+		schema = cfg.Oracle.Username
+	}
+
+	if !isJSONOutput() {
+		fmt.Printf("🔍 正在评估 %s 的迁移就绪度...\n", schema)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), readinessTimeout)
+	defer cancel()
+
+	runner := oracle.NewSQLPlusRunner(&cfg.Oracle, &cfg.OracleClient)
+	profile, err := oracle.NewInspector(runner, schema).Profile(ctx, readinessLargeRows)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	// 统计信息查询失败时该维度不参与评分
+	stats, err := oracle.NewStatsCollector(runner, schema).CheckFreshness(ctx, oracle.DefaultStatsMaxAge)
+	if err != nil {
+		utils.GetGlobalLogger().Warnf("查询统计信息失败，统计信息维度不参与评分: %v", err)
+		stats = nil
+	}
+	report := service.ScoreReadiness(profile, stats)
+
+	if isJSONOutput() {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Printf("%s\n", utils.FormatError(err))
+			exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+	printReadinessReport(report)
+}
+
+// printReadinessReport 以文本形式输出就绪度报告
+func printReadinessReport(report *service.ReadinessReport) {
+	fmt.Println()
+	fmt.Printf("📊 迁移就绪度: %d 分（%s）\n", report.Score, report.Level)
+	fmt.Printf("   %s\n", report.Complexity)
+	fmt.Printf("   预计需要人工复核的对象: %d 个\n", report.ManualReview)
+	fmt.Println("─────────────────────")
+
+	for _, dimension := range report.Dimensions {
+		if !dimension.Available {
+			fmt.Printf("➖ %s（权重 %d%%）: 未评估\n", dimension.Name, dimension.Weight)
+			fmt.Printf("   %s\n", dimension.Summary)
+			continue
+		}
+		fmt.Printf("%s %s（权重 %d%%）: %d 分\n", readinessIcon(dimension.Score), dimension.Name, dimension.Weight, dimension.Score)
+		fmt.Printf("   %s\n", dimension.Summary)
+		for _, suggestion := range dimension.Suggestions {
+			fmt.Printf("   💡 %s\n", suggestion)
+		}
+	}
+}
+
+// readinessIcon 维度得分对应的图标
+func readinessIcon(score int) string {
+	switch {
+	case score >= 80:
+		return "✅"
+	case score >= 50:
+		return "⚠️ "
+	default:
+		return "❌"
+	}
+}
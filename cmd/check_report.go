@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// 检查项状态
+const (
+	checkStatusPass = "pass"
+	checkStatusWarn = "warn"
+	checkStatusFail = "fail"
+)
+
+// 检查结果输出格式
+const (
+	checkOutputText = "text"
+	checkOutputJSON = "json"
+)
+
+// CheckResult 单个检查项的结果
+type CheckResult struct {
+	Check       string   `json:"check"`
+	Status      string   `json:"status"`
+	Message     string   `json:"message"`
+	Details     string   `json:"details,omitempty"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// checkSection 按标题分组的检查项，仅用于文本展示
+type checkSection struct {
+	title   string
+	results []*CheckResult
+}
+
+// CheckReport 检查报告：先收集结果，再按输出格式渲染
+type CheckReport struct {
+	sections []*checkSection
+}
+
+// newCheckReport 创建检查报告
+func newCheckReport() *CheckReport {
+	return &CheckReport{}
+}
+
+// Section 开始新的检查分组
+func (r *CheckReport) Section(title string) *checkSection {
+	section := &checkSection{title: title}
+	r.sections = append(r.sections, section)
+	return section
+}
+
+// Add 添加检查项
+func (s *checkSection) Add(check, status, message, details string, suggestions ...string) *CheckResult {
+	result := &CheckResult{
+		Check:       check,
+		Status:      status,
+		Message:     message,
+		Details:     details,
+		Suggestions: suggestions,
+	}
+	s.results = append(s.results, result)
+	return result
+}
+
+// Results 返回所有检查项
+func (r *CheckReport) Results() []*CheckResult {
+	results := make([]*CheckResult, 0)
+	for _, section := range r.sections {
+		results = append(results, section.results...)
+	}
+	return results
+}
+
+// Count 统计指定状态的检查项数量
+func (r *CheckReport) Count(status string) int {
+	count := 0
+	for _, result := range r.Results() {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Find 按名称查找检查项
+func (r *CheckReport) Find(check string) *CheckResult {
+	for _, result := range r.Results() {
+		if result.Check == check {
+			return result
+		}
+	}
+	return nil
+}
+
+// RenderJSON 以JSON数组输出所有检查项
+func (r *CheckReport) RenderJSON() error {
+	data, err := json.MarshalIndent(r.Results(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化检查结果失败: %v", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// RenderText 以人类可读的文本输出所有检查项
+func (r *CheckReport) RenderText() {
+	for i, section := range r.sections {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("📋 %s\n", section.title)
+		fmt.Println("─────────────────────")
+
+		for _, result := range section.results {
+			fmt.Printf("%s %s\n", checkStatusIcon(result.Status), result.Message)
+			if result.Details != "" {
+				for _, line := range strings.Split(result.Details, "\n") {
+					fmt.Printf("   %s\n", line)
+				}
+			}
+			if len(result.Suggestions) > 0 {
+				fmt.Println()
+				fmt.Println("💡 解决建议:")
+				for j, suggestion := range result.Suggestions {
+					fmt.Printf("  %d. %s\n", j+1, suggestion)
+				}
+			}
+		}
+	}
+}
+
+// exitOnFailedChecks 存在失败的检查项时以退出码1结束，文本和JSON输出一致，便于在脚本和CI中判断
+func exitOnFailedChecks(report *CheckReport) {
+	if report.Count(checkStatusFail) > 0 {
+		exit(1)
+	}
+}
+
+// checkStatusIcon 获取状态图标
+func checkStatusIcon(status string) string {
+	switch status {
+	case checkStatusPass:
+		return "✅"
+	case checkStatusWarn:
+		return "⚠️"
+	default:
+		return "❌"
+	}
+}
+
+// isJSONOutput 是否以JSON格式输出检查结果
+func isJSONOutput() bool {
+	return strings.EqualFold(checkOutput, checkOutputJSON)
+}
+
+// prepareCheckOutput 校验输出格式，JSON模式下把日志改到stderr以免混入结果
+func prepareCheckOutput() error {
+	switch strings.ToLower(checkOutput) {
+	case checkOutputText:
+	case checkOutputJSON:
+		if utils.GetGlobalLogger().WritesToStdout() {
+			utils.GetGlobalLogger().SetOutput("stderr")
+		}
+	default:
+		return utils.ConfigErrors.InvalidValue("output", checkOutput)
+	}
+	return nil
+}
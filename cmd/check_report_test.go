@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/oracle"
+)
+
+// captureStdout 执行 fn 并返回其写入标准输出的内容
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	original := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = original }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		done <- string(data)
+	}()
+	fn()
+	writer.Close()
+	return <-done
+}
+
+// newSampleCheckReport 创建包含各种状态检查项的报告
+func newSampleCheckReport() *CheckReport {
+	report := newCheckReport()
+	oracleSection := report.Section("Oracle连接测试")
+	oracleSection.Add("oracle_connection", checkStatusPass, "Oracle连接成功", "响应时间: 20ms")
+	oracleSection.Add("oracle_network", checkStatusWarn, "到Oracle的平均延迟 45ms（跨地域）", "", "提高 migration.parallel_tables")
+	report.Section("PostgreSQL连接测试").Add("postgresql_connection", checkStatusFail, "PostgreSQL连接失败",
+		"错误: connection refused\n详细信息: 127.0.0.1:5432", "确认数据库服务已启动", "检查防火墙设置")
+	return report
+}
+
+func TestCheckReportJSONShape(t *testing.T) {
+	data, err := json.Marshal(newSampleCheckReport().Results())
+	require.NoError(t, err)
+
+	var decoded []map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Len(t, decoded, 3)
+
+	assert.Equal(t, map[string]interface{}{
+		"check":   "oracle_connection",
+		"status":  "pass",
+		"message": "Oracle连接成功",
+		"details": "响应时间: 20ms",
+	}, decoded[0])
+	// 空的 details 和 suggestions 不输出
+	assert.NotContains(t, decoded[1], "details")
+	assert.Equal(t, []interface{}{"确认数据库服务已启动", "检查防火墙设置"}, decoded[2]["suggestions"])
+	assert.Equal(t, "fail", decoded[2]["status"])
+
+	// 没有检查项时输出空数组而不是 null
+	data, err = json.Marshal(newCheckReport().Results())
+	require.NoError(t, err)
+	assert.Equal(t, "[]", string(data))
+}
+
+func TestCheckReportCountAndFind(t *testing.T) {
+	report := newSampleCheckReport()
+	assert.Equal(t, 1, report.Count(checkStatusPass))
+	assert.Equal(t, 1, report.Count(checkStatusWarn))
+	assert.Equal(t, 1, report.Count(checkStatusFail))
+
+	found := report.Find("postgresql_connection")
+	require.NotNil(t, found)
+	assert.Equal(t, "PostgreSQL连接失败", found.Message)
+	assert.Nil(t, report.Find("missing"))
+}
+
+func TestCheckStatusMapping(t *testing.T) {
+	assert.Equal(t, "✅", checkStatusIcon(checkStatusPass))
+	assert.Equal(t, "⚠️", checkStatusIcon(checkStatusWarn))
+	assert.Equal(t, "❌", checkStatusIcon(checkStatusFail))
+
+	// Oracle客户端状态映射为检查项状态
+	tests := map[string]string{
+		"COMPATIBLE":      checkStatusPass,
+		"INCOMPATIBLE":    checkStatusWarn,
+		"UNKNOWN_VERSION": checkStatusWarn,
+		"NOT_INSTALLED":   checkStatusFail,
+		"ERROR":           checkStatusFail,
+	}
+	for clientStatus, want := range tests {
+		report := newCheckReport()
+		collectOracleClientCheck(report.Section("Oracle客户端检查"), oracle.NewClientDetector(),
+			&oracle.ClientStatusReport{Status: clientStatus, Message: clientStatus})
+		result := report.Find("oracle_client")
+		require.NotNil(t, result, clientStatus)
+		assert.Equal(t, want, result.Status, clientStatus)
+	}
+}
+
+func TestCheckReportRenderText(t *testing.T) {
+	output := captureStdout(t, newSampleCheckReport().RenderText)
+
+	assert.Contains(t, output, "📋 Oracle连接测试\n")
+	assert.Contains(t, output, "✅ Oracle连接成功\n   响应时间: 20ms\n")
+	assert.Contains(t, output, "⚠️ 到Oracle的平均延迟 45ms（跨地域）\n")
+	// 多行详情逐行缩进，建议按序号列出
+	assert.Contains(t, output, "❌ PostgreSQL连接失败\n   错误: connection refused\n   详细信息: 127.0.0.1:5432\n")
+	assert.Contains(t, output, "💡 解决建议:\n  1. 确认数据库服务已启动\n  2. 检查防火墙设置\n")
+	// 分组之间空一行
+	assert.Contains(t, output, "\n\n📋 PostgreSQL连接测试\n")
+}
+
+func TestCheckConnJSONExitsOnFailure(t *testing.T) {
+	defer func() { checkOutput, checkConfig = checkOutputText, "" }()
+
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = executeForAudit(t, "检查", "连接", "-o", "json", "-c", missing)
+	})
+	assert.Equal(t, 1, exitCode)
+
+	var results []CheckResult
+	require.NoError(t, json.Unmarshal([]byte(output), &results))
+	require.Len(t, results, 1)
+	assert.Equal(t, "config", results[0].Check)
+	assert.Equal(t, checkStatusFail, results[0].Status)
+}
+
+func TestCheckEnvJSONExitsOnFailure(t *testing.T) {
+	defer func() { checkOutput, checkConfig = checkOutputText, "" }()
+	t.Chdir(t.TempDir())
+
+	// 未初始化的目录中项目环境检查失败
+	var exitCode int
+	output := captureStdout(t, func() {
+		exitCode = executeForAudit(t, "检查", "环境", "-o", "json")
+	})
+	assert.Equal(t, 1, exitCode)
+
+	var results []CheckResult
+	require.NoError(t, json.Unmarshal([]byte(output), &results))
+	project := findCheckResult(results, "project")
+	require.NotNil(t, project)
+	assert.Equal(t, checkStatusFail, project.Status)
+}
+
+// findCheckResult 按名称查找解码后的检查项
+func findCheckResult(results []CheckResult, check string) *CheckResult {
+	for i := range results {
+		if results[i].Check == check {
+			return &results[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+)
+
+// collectTargetConnectionChecks 分别测试主目标以外的各目标库连接
+func collectTargetConnectionChecks(report *CheckReport, tester *oracle.ConnectionTester, cfg *config.ProjectConfig) {
+	targets := cfg.PostgreSQLTargets()
+	if len(targets) < 2 {
+		return
+	}
+	var samples []oracle.ConnectionSample
+	for _, target := range targets[1:] {
+		section := report.Section(fmt.Sprintf("PostgreSQL目标 %s 连接测试", target.Name))
+		result := tester.TestPostgreSQLConnection(&target)
+		samples = append(samples, oracle.NewConnectionSample(oracle.ConnectionTargetPostgreSQL, postgresEndpoint(&target), result))
+		if result.Success {
+			section.Add("postgresql_connection_"+target.Name, checkStatusPass, result.Message, formatConnectionDetails(result))
+		} else {
+			section.Add("postgresql_connection_"+target.Name, checkStatusFail, result.Message, formatConnectionDetails(result),
+				"验证该目标的主机名、端口和数据库名",
+				"未配置的连接项沿用主目标，确认账号在该目标上同样有效")
+		}
+	}
+	saveConnectionSamples(samples)
+}
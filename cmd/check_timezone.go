@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/postgres"
+)
+
+// timeZoneCheckTimeout 时区查询的超时时间
+const timeZoneCheckTimeout = time.Minute
+
+// collectTimeZoneChecks 查询两端的时区和日期格式设置并对比，连接测试都通过后执行
+func collectTimeZoneChecks(report *CheckReport, cfg *config.ProjectConfig) {
+	section := report.Section("时区与日期格式检查")
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeZoneCheckTimeout)
+	defer cancel()
+
+	oraSettings, err := oracle.QueryTimeZoneSettings(ctx, oracle.NewSQLPlusRunner(&cfg.Oracle, &cfg.OracleClient))
+	if err != nil {
+		section.Add("timezone", checkStatusWarn, "⚠️  无法查询Oracle时区设置，跳过时区检查", err.Error(),
+			"确认sqlplus可用: ora2pg-admin 检查 环境")
+		return
+	}
+	pgSettings, err := postgres.QueryTimeZoneSettings(ctx, postgres.NewPSQLRunner(&cfg.PostgreSQL))
+	if err != nil {
+		section.Add("timezone", checkStatusWarn, "⚠️  无法查询PostgreSQL时区设置，跳过时区检查", err.Error(),
+			"确认psql可用: ora2pg-admin 检查 环境")
+		return
+	}
+	addTimeZoneChecks(section, cfg, oraSettings, pgSettings)
+}
+
+// addTimeZoneChecks 对比两端设置：SYSDATE使用的服务器时区、迁移会话时区和日期格式
+func addTimeZoneChecks(section *checkSection, cfg *config.ProjectConfig, ora *oracle.TimeZoneSettings, pg *postgres.TimeZoneSettings) {
+	pgZone := fmt.Sprintf("%s (%s)", pg.TimeZone, formatZoneOffset(pg.OffsetSeconds))
+	database := cfg.PostgreSQL.Database
+
+	// SYSDATE、SYSTIMESTAMP按Oracle服务器操作系统时区取值，迁移后的默认值按PostgreSQL时区取值
+	if offset, ok := oracle.OffsetSeconds(ora.SystemOffset); !ok || offset == pg.OffsetSeconds {
+		section.Add("timezone_sysdate", checkStatusPass, "✅ Oracle服务器时区与PostgreSQL时区一致",
+			fmt.Sprintf("Oracle服务器: %s\nPostgreSQL: %s", ora.SystemOffset, pgZone))
+	} else {
+		section.Add("timezone_sysdate", checkStatusWarn,
+			fmt.Sprintf("⚠️  Oracle服务器时区 (%s) 与PostgreSQL时区 %s 相差 %s", ora.SystemOffset, pgZone, formatZoneDiff(offset-pg.OffsetSeconds)),
+			"SYSDATE、SYSTIMESTAMP按Oracle服务器时区取值；迁移后 DEFAULT SYSDATE 等默认值按PostgreSQL时区取值，新写入的时间会出现偏差",
+			fmt.Sprintf("在目标库设置与Oracle服务器一致的时区: ALTER DATABASE %s SET timezone = '<时区名称，如 Asia/Shanghai>';", postgres.QuoteIdentifier(database)),
+			"设置后重新连接的会话生效，可用 SHOW timezone 确认")
+	}
+
+	// TIMESTAMP WITH (LOCAL) TIME ZONE 导出时按Oracle会话时区转换，导入 timestamptz 时按PostgreSQL会话时区解释
+	if configured := strings.TrimSpace(cfg.Migration.TimeZone); configured != "" {
+		section.Add("timezone_session", checkStatusPass,
+			fmt.Sprintf("✅ 迁移会话统一使用 migration.time_zone: %s", configured),
+			fmt.Sprintf("ORA_INITIAL_COMMAND %s\nPG_INITIAL_COMMAND %s",
+				cfg.Migration.OracleTimeZoneCommand(), cfg.Migration.PostgresTimeZoneCommand()))
+	} else if offset, ok := oracle.OffsetSeconds(ora.SessionOffset); !ok || offset == pg.OffsetSeconds {
+		section.Add("timezone_session", checkStatusPass, "✅ Oracle会话时区与PostgreSQL会话时区一致",
+			fmt.Sprintf("Oracle会话: %s (%s)，数据库时区: %s\nPostgreSQL: %s", ora.SessionTimeZone, ora.SessionOffset, ora.DBTimeZone, pgZone))
+	} else {
+		section.Add("timezone_session", checkStatusWarn,
+			fmt.Sprintf("⚠️  Oracle会话时区 %s (%s) 与PostgreSQL会话时区 %s 不一致", ora.SessionTimeZone, ora.SessionOffset, pgZone),
+			fmt.Sprintf("ora2pg导出 TIMESTAMP WITH LOCAL TIME ZONE 时按Oracle会话时区转换，导入 timestamptz 时按PostgreSQL会话时区解释，迁移后的时间可能相差 %s\nOracle会话时区由客户端的 ORA_SDTZ 环境变量或操作系统时区决定，数据库时区: %s",
+				formatZoneDiff(offset-pg.OffsetSeconds), ora.DBTimeZone),
+			fmt.Sprintf("在 .ora2pg-admin/config.yaml 的 migration 中设置 time_zone: \"%s\"", suggestedTimeZone(ora)),
+			"生成的ora2pg配置会加入 ORA_INITIAL_COMMAND 和 PG_INITIAL_COMMAND，统一两端迁移会话的时区")
+	}
+
+	// 非ISO的DateStyle会改变日期的输出格式，DMY/MDY还影响有歧义的日期字符串的解析
+	dateDetails := fmt.Sprintf("PostgreSQL DateStyle: %s\nOracle NLS_DATE_FORMAT: %s，NLS_TIMESTAMP_FORMAT: %s", pg.DateStyle, ora.DateFormat, ora.TimestampFormat)
+	if pg.ISODateStyle() {
+		section.Add("date_style", checkStatusPass, "✅ PostgreSQL日期格式为ISO", dateDetails)
+	} else {
+		section.Add("date_style", checkStatusWarn, fmt.Sprintf("⚠️  PostgreSQL日期格式为 %s，不是ISO", pg.DateStyle),
+			dateDetails+"\n依赖日期字符串隐式转换的SQL和数据校验结果可能与Oracle不一致",
+			fmt.Sprintf("ALTER DATABASE %s SET DateStyle = 'ISO, YMD';", postgres.QuoteIdentifier(database)))
+	}
+}
+
+// suggestedTimeZone 建议的迁移会话时区：Oracle会话时区是地区名称时直接使用，否则使用偏移量
+func suggestedTimeZone(ora *oracle.TimeZoneSettings) string {
+	if _, isOffset := oracle.OffsetSeconds(ora.SessionTimeZone); !isOffset && ora.SessionTimeZone != "" {
+		return ora.SessionTimeZone
+	}
+	return ora.SessionOffset
+}
+
+// formatZoneOffset 把偏移秒数格式化为 +08:00 形式
+func formatZoneOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d:%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// formatZoneDiff 两个时区的差值，如 8小时、5小时30分钟
+func formatZoneDiff(seconds int) string {
+	if seconds < 0 {
+		seconds = -seconds
+	}
+	diff := fmt.Sprintf("%d小时", seconds/3600)
+	if minutes := seconds % 3600 / 60; minutes > 0 {
+		diff += fmt.Sprintf("%d分钟", minutes)
+	}
+	return diff
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/postgres"
+)
+
+func TestAddTimeZoneChecks(t *testing.T) {
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("时区检查")
+	cfg := manager.GetConfig()
+	ora := &oracle.TimeZoneSettings{
+		DBTimeZone:      "+00:00",
+		SessionTimeZone: "Asia/Shanghai",
+		SessionOffset:   "+08:00",
+		SystemOffset:    "+08:00",
+		DateFormat:      "DD-MON-RR",
+	}
+
+	// 两端一致
+	report := newCheckReport()
+	addTimeZoneChecks(report.Section("时区"), cfg, ora, &postgres.TimeZoneSettings{TimeZone: "Asia/Shanghai", OffsetSeconds: 28800, DateStyle: "ISO, MDY"})
+	assert.Equal(t, 0, report.Count(checkStatusWarn))
+	assert.Len(t, report.Results(), 3)
+
+	// 时区不同时提示相差的时长并给出配置建议
+	report = newCheckReport()
+	addTimeZoneChecks(report.Section("时区"), cfg, ora, &postgres.TimeZoneSettings{TimeZone: "UTC", OffsetSeconds: 0, DateStyle: "SQL, DMY"})
+	sysdate := report.Find("timezone_sysdate")
+	require.NotNil(t, sysdate)
+	assert.Equal(t, checkStatusWarn, sysdate.Status)
+	assert.Contains(t, sysdate.Message, "8小时")
+	session := report.Find("timezone_session")
+	require.NotNil(t, session)
+	assert.Equal(t, checkStatusWarn, session.Status)
+	assert.Contains(t, session.Suggestions, `在 .ora2pg-admin/config.yaml 的 migration 中设置 time_zone: "Asia/Shanghai"`)
+	dateStyle := report.Find("date_style")
+	require.NotNil(t, dateStyle)
+	assert.Equal(t, checkStatusWarn, dateStyle.Status)
+	assert.Contains(t, dateStyle.Suggestions[0], "SET DateStyle = 'ISO, YMD'")
+
+	// 配置了迁移会话时区后不再提示会话时区差异
+	cfg.Migration.TimeZone = "Asia/Shanghai"
+	report = newCheckReport()
+	addTimeZoneChecks(report.Section("时区"), cfg, ora, &postgres.TimeZoneSettings{TimeZone: "UTC", OffsetSeconds: 0, DateStyle: "ISO, MDY"})
+	assert.Equal(t, checkStatusPass, report.Find("timezone_session").Status)
+	assert.Equal(t, checkStatusWarn, report.Find("timezone_sysdate").Status)
+}
+
+func TestFormatZoneOffset(t *testing.T) {
+	assert.Equal(t, "+08:00", formatZoneOffset(28800))
+	assert.Equal(t, "-05:30", formatZoneOffset(-19800))
+	assert.Equal(t, "5小时30分钟", formatZoneDiff(-19800))
+}
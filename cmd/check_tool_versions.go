@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/service"
+)
+
+// collectToolVersionCheck 校验 tools 中声明的ora2pg、sqlplus、psql期望版本
+func collectToolVersionCheck(section *checkSection, cfg *config.ProjectConfig) {
+	for _, check := range service.CheckToolVersions(context.Background(), cfg) {
+		id := "tool_version_" + check.Tool
+		details := ""
+		if check.Output != "" && (checkVerbose || isJSONOutput()) {
+			details = fmt.Sprintf("版本输出: %s", check.Output)
+		}
+		switch check.Status {
+		case service.ToolVersionMatched:
+			section.Add(id, checkStatusPass, fmt.Sprintf("%s: %s（期望 %s）", check.Tool, check.Version, check.Expected), details)
+		case service.ToolVersionMismatched:
+			section.Add(id, checkStatusWarn, fmt.Sprintf("%s: 版本 %s 不符合期望的 %s", check.Tool, check.Version, check.Expected), details,
+				fmt.Sprintf("安装经过验证的 %s 版本，避免环境差异导致迁移结果不一致", check.Tool),
+				"确认新版本无影响后，更新配置文件中的 tools."+check.Tool)
+		default:
+			section.Add(id, checkStatusWarn, fmt.Sprintf("%s: 无法确认版本（期望 %s）", check.Tool, check.Expected), check.Error,
+				fmt.Sprintf("确认 %s 已安装且可以执行", check.Tool))
+		}
+	}
+	if cfg.Tools.Enforce {
+		section.Add("tool_version_enforce", checkStatusPass, "迁移前强制校验: 已启用（tools.enforce）",
+			"版本不一致时迁移不会开始，可使用 --skip-version-check 跳过")
+	}
+}
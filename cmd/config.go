@@ -1,9 +1,9 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -69,10 +69,61 @@ var configOptionsCmd = &cobra.Command{
 	Run: runConfigOptions,
 }
 
+// configSaveTemplateCmd 另存为模板命令
+var configSaveTemplateCmd = &cobra.Command{
+	Use:   "另存为模板 <名称>",
+	Short: "将当前配置保存为团队共享模板",
+	Long: `将当前项目配置保存为命名模板，供新项目初始化时复用。
+
+保存时会去除数据库主机、用户名、密码等环境相关信息，
+模板保存在用户目录 ~/.ora2pg-admin/templates 下。
+
+示例:
+  ora2pg-admin 配置 另存为模板 团队标准
+  ora2pg-admin 初始化 --template=团队标准 新项目`,
+	Args: cobra.ExactArgs(1),
+	Run:  runConfigSaveTemplate,
+}
+
+// configEncryptCmd 加密配置命令
+var configEncryptCmd = &cobra.Command{
+	Use:   "加密",
+	Short: "使用主密码加密配置中的数据库密码",
+	Long: `使用主密码加密 config.yaml 中的Oracle和PostgreSQL密码。
+
+加密后的字段以 enc: 开头，此后保存配置时会自动保持加密。
+读取配置时从环境变量 ORA2PG_ADMIN_MASTER_PASSWORD 获取主密码，
+未设置时交互输入。请妥善保管主密码，遗失后无法恢复密码。
+
+示例:
+  ora2pg-admin 配置 加密
+  ORA2PG_ADMIN_MASTER_PASSWORD=xxx ora2pg-admin 迁移 全部`,
+	Run: runConfigEncrypt,
+}
+
+// configDecryptCmd 解密配置命令
+var configDecryptCmd = &cobra.Command{
+	Use:   "解密",
+	Short: "将加密的数据库密码还原为明文",
+	Long: `使用主密码解密 config.yaml 中的敏感字段，并以明文重新保存。
+
+示例:
+  ora2pg-admin 配置 解密`,
+	Run: runConfigDecrypt,
+}
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configDbCmd)
 	configCmd.AddCommand(configOptionsCmd)
+	configCmd.AddCommand(configSaveTemplateCmd)
+	configCmd.AddCommand(configEncryptCmd)
+	configCmd.AddCommand(configDecryptCmd)
+
+	// 加载加密配置时交互输入主密码
+	config.SetMasterPasswordPrompt(func() (string, error) {
+		return promptMasterPassword("主密码")
+	})
 
 	// 添加命令参数
 	configCmd.PersistentFlags().StringVarP(&configFile, "file", "f", "", "指定配置文件路径")
@@ -91,48 +142,38 @@ func runConfigDb(cmd *cobra.Command, args []string) {
 	manager, err := loadOrCreateConfig()
 	if err != nil {
 		fmt.Printf("%s\n", utils.FormatError(err))
-		os.Exit(1)
+		exit(1)
 	}
 
 	cfg := manager.GetConfig()
 
-	// 2. 配置Oracle数据库
-	fmt.Println("📊 Oracle数据库配置")
-	fmt.Println("─────────────────────")
-	
-	if err := configureOracle(&cfg.Oracle); err != nil {
+	// 2. 配置Oracle和PostgreSQL数据库，可跨数据库返回上一步修改
+	printWizardHint()
+	steps := append(oracleWizardSteps(&cfg.Oracle), postgresWizardSteps(&cfg.PostgreSQL)...)
+	if err := runWizard(steps); err != nil {
 		fmt.Printf("%s\n", utils.FormatError(err))
-		os.Exit(1)
+		exit(1)
 	}
+	fmt.Println("✅ 数据库配置完成")
 
-	// 3. 配置PostgreSQL数据库
-	fmt.Println()
-	fmt.Println("🐘 PostgreSQL数据库配置")
-	fmt.Println("──────────────────────")
-	
-	if err := configurePostgreSQL(&cfg.PostgreSQL); err != nil {
-		fmt.Printf("%s\n", utils.FormatError(err))
-		os.Exit(1)
-	}
-
-	// 4. 测试连接
+	// 3. 测试连接
 	fmt.Println()
 	fmt.Println("🔗 连接测试")
 	fmt.Println("─────────")
 	
 	testConnections(cfg)
 
-	// 5. 保存配置
+	// 4. 保存配置
 	fmt.Println()
 	fmt.Println("💾 保存配置")
 	fmt.Println("─────────")
 	
 	if err := saveConfiguration(manager); err != nil {
 		fmt.Printf("%s\n", utils.FormatError(err))
-		os.Exit(1)
+		exit(1)
 	}
 
-	// 6. 显示配置摘要
+	// 5. 显示配置摘要
 	showConfigurationSummary(cfg)
 	
 	logger.Info("数据库配置完成")
@@ -149,7 +190,7 @@ func runConfigOptions(cmd *cobra.Command, args []string) {
 	manager, err := loadOrCreateConfig()
 	if err != nil {
 		fmt.Printf("%s\n", utils.FormatError(err))
-		os.Exit(1)
+		exit(1)
 	}
 
 	cfg := manager.GetConfig()
@@ -160,7 +201,7 @@ func runConfigOptions(cmd *cobra.Command, args []string) {
 	
 	if err := configureMigrationTypes(&cfg.Migration); err != nil {
 		fmt.Printf("%s\n", utils.FormatError(err))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 3. 配置性能参数
@@ -170,7 +211,7 @@ func runConfigOptions(cmd *cobra.Command, args []string) {
 	
 	if err := configurePerformanceSettings(&cfg.Migration); err != nil {
 		fmt.Printf("%s\n", utils.FormatError(err))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 4. 配置高级选项
@@ -180,7 +221,14 @@ func runConfigOptions(cmd *cobra.Command, args []string) {
 	
 	if err := configureAdvancedOptions(&cfg.Migration); err != nil {
 		fmt.Printf("%s\n", utils.FormatError(err))
-		os.Exit(1)
+		exit(1)
+	}
+
+	if utils.AskYesNo("是否为ora2pg添加自定义环境变量（如 TNS_ADMIN、NLS_DATE_FORMAT）") {
+		if err := configureEnvironment(cfg); err != nil {
+			fmt.Printf("%s\n", utils.FormatError(err))
+			exit(1)
+		}
 	}
 
 	// 5. 预览配置
@@ -194,12 +242,12 @@ func runConfigOptions(cmd *cobra.Command, args []string) {
 	if confirmConfiguration() {
 		if err := saveConfiguration(manager); err != nil {
 			fmt.Printf("%s\n", utils.FormatError(err))
-			os.Exit(1)
+			exit(1)
 		}
 
 		// 生成ora2pg配置文件
 		if err := generateOra2pgConfig(cfg); err != nil {
-			logger.Warnf("生成ora2pg配置文件时出现警告: %v", err)
+			fmt.Printf("⚠️ 生成ora2pg配置文件时出现警告\n%s\n", utils.FormatError(err))
 		}
 
 		fmt.Println()
@@ -215,6 +263,165 @@ func runConfigOptions(cmd *cobra.Command, args []string) {
 	logger.Info("迁移选项配置完成")
 }
 
+// runConfigSaveTemplate 执行另存为模板
+func runConfigSaveTemplate(cmd *cobra.Command, args []string) {
+	logger := utils.GetGlobalLogger()
+	name := strings.TrimSpace(args[0])
+
+	configPath := getConfigFilePath()
+	fileUtils := utils.NewFileUtils()
+	if !fileUtils.FileExists(configPath) {
+		fmt.Printf("%s\n", utils.FormatError(utils.ConfigErrors.FileNotFound(configPath)))
+		exit(1)
+	}
+
+	manager := config.NewManager()
+	if err := manager.LoadConfig(configPath); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(configLoadError(err)))
+		exit(1)
+	}
+
+	templatePath, err := config.SaveUserTemplate(name, manager.GetConfig())
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	fmt.Printf("✅ 已保存模板 %s: %s\n", name, templatePath)
+	fmt.Println("   已去除数据库主机、用户名和密码信息")
+
+	if templates, err := config.ListUserTemplates(); err == nil && len(templates) > 0 {
+		fmt.Printf("📋 可用的自定义模板: %s\n", strings.Join(templates, ", "))
+	}
+	fmt.Printf("💡 使用方法: ora2pg-admin 初始化 --template=%s [项目名称]\n", name)
+
+	logger.Infof("配置已另存为模板: %s", name)
+}
+
+// runConfigEncrypt 执行配置加密
+func runConfigEncrypt(cmd *cobra.Command, args []string) {
+	logger := utils.GetGlobalLogger()
+
+	manager, configPath, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	if manager.IsEncrypted() {
+		fmt.Println("ℹ️ 配置已加密，将使用当前主密码重新加密")
+	} else if os.Getenv(config.MasterPasswordEnv) == "" {
+		// 首次加密需要确认主密码，防止输错后无法解密
+		password, err := promptMasterPassword("设置主密码")
+		if err != nil {
+			fmt.Printf("%s\n", utils.FormatError(err))
+			exit(1)
+		}
+		confirm, err := promptMasterPassword("确认主密码")
+		if err != nil {
+			fmt.Printf("%s\n", utils.FormatError(err))
+			exit(1)
+		}
+		if password != confirm {
+			fmt.Printf("%s\n", utils.FormatError(utils.NewError(utils.ErrorTypeUser, "MASTER_PASSWORD_MISMATCH").
+				Message("两次输入的主密码不一致").
+				Build()))
+			exit(1)
+		}
+		manager.SetMasterPassword(password)
+	}
+
+	manager.SetEncryption(true)
+	if err := manager.SaveConfig(configPath); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(configLoadError(err)))
+		exit(1)
+	}
+
+	fmt.Printf("🔒 已加密配置中的数据库密码: %s\n", configPath)
+	fmt.Printf("💡 非交互运行时请设置环境变量 %s 提供主密码\n", config.MasterPasswordEnv)
+	logger.Info("配置敏感字段已加密")
+}
+
+// runConfigDecrypt 执行配置解密
+func runConfigDecrypt(cmd *cobra.Command, args []string) {
+	logger := utils.GetGlobalLogger()
+
+	manager, configPath, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	if !manager.IsEncrypted() {
+		fmt.Println("ℹ️ 配置未加密，无需解密")
+		return
+	}
+
+	manager.SetEncryption(false)
+	if err := manager.SaveConfig(configPath); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	fmt.Printf("🔓 已解密配置中的数据库密码: %s\n", configPath)
+	fmt.Println("⚠️ 密码已以明文保存，请注意文件权限")
+	logger.Info("配置敏感字段已解密")
+}
+
+// loadExistingConfig 加载已存在的项目配置
+func loadExistingConfig() (*config.Manager, string, error) {
+	configPath := getConfigFilePath()
+	if !utils.NewFileUtils().FileExists(configPath) {
+		return nil, "", utils.ConfigErrors.FileNotFound(configPath)
+	}
+
+	manager := config.NewManager()
+	if err := manager.LoadConfig(configPath); err != nil {
+		return nil, "", configLoadError(err)
+	}
+	return manager, configPath, nil
+}
+
+// configLoadError 将配置加载错误转换为带建议的错误，区分主密码问题
+func configLoadError(err error) error {
+	switch {
+	case errors.Is(err, config.ErrMasterPasswordRequired):
+		return utils.NewError(utils.ErrorTypeConfig, "MASTER_PASSWORD_REQUIRED").
+			Message("配置已加密，需要主密码").
+			Cause(err).
+			Suggestion(fmt.Sprintf("设置环境变量 %s 或在终端中交互输入主密码", config.MasterPasswordEnv)).
+			Build()
+	case errors.Is(err, config.ErrInvalidMasterPassword):
+		return utils.NewError(utils.ErrorTypeConfig, "INVALID_MASTER_PASSWORD").
+			Message("主密码错误，无法解密配置").
+			Cause(err).
+			Suggestion("确认主密码是否正确").
+			Suggestion("如主密码遗失，请使用 '配置 数据库' 重新输入数据库密码").
+			Build()
+	case strings.HasPrefix(utils.GetErrorCode(err), "CONFIG_INCLUDE_"),
+		utils.GetErrorCode(err) == "CONFIG_ENV_OVERRIDE_INVALID":
+		// include 和环境变量覆盖的错误已带有引用链和建议
+		return err
+	default:
+		return utils.ConfigErrors.ParseFailed(err)
+	}
+}
+
+// promptMasterPassword 交互输入主密码
+func promptMasterPassword(label string) (string, error) {
+	prompt := promptui.Prompt{
+		Label:    label,
+		Mask:     '*',
+		Validate: validateRequired,
+	}
+	password, err := prompt.Run()
+	if err != nil {
+		return "", utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
+			Message("用户取消了输入").Build()
+	}
+	return password, nil
+}
+
 // loadOrCreateConfig 加载或创建配置
 func loadOrCreateConfig() (*config.Manager, error) {
 	manager := config.NewManager()
@@ -227,16 +434,16 @@ func loadOrCreateConfig() (*config.Manager, error) {
 	if fileUtils.FileExists(configPath) {
 		// 创建备份
 		if configBackup {
-			backupPath := configPath + ".backup"
-			if err := fileUtils.CopyFile(configPath, backupPath); err != nil {
-				return nil, utils.FileErrors.CreateFailed(backupPath, err)
+			backup, err := config.BackupConfig(configPath, configBackupKeep)
+			if err != nil {
+				return nil, err
 			}
-			fmt.Printf("📋 已创建配置备份: %s\n", backupPath)
+			fmt.Printf("📋 已创建配置备份: %s\n", backup.Path)
 		}
 
 		// 加载现有配置
 		if err := manager.LoadConfig(configPath); err != nil {
-			return nil, utils.ConfigErrors.ParseFailed(err)
+			return nil, configLoadError(err)
 		}
 		fmt.Printf("📂 已加载现有配置: %s\n", configPath)
 	} else {
@@ -263,222 +470,322 @@ func getConfigFilePath() string {
 	}
 
 	// 默认使用项目目录的配置文件
-	return filepath.Join(".ora2pg-admin", "config.yaml")
+	return config.ProjectConfigPath(".")
 }
 
-// configureOracle 配置Oracle数据库
-func configureOracle(oracleConfig *config.OracleConfig) error {
-	// 显示当前配置
-	if oracleConfig.Host != "" {
-		fmt.Printf("当前配置: %s:%d/%s (用户: %s)\n",
-			oracleConfig.Host, oracleConfig.Port,
-			getOracleIdentifier(oracleConfig), oracleConfig.Username)
-		fmt.Println()
-	}
-
-	// 配置主机
-	hostPrompt := promptui.Prompt{
-		Label:    "Oracle主机地址",
-		Default:  oracleConfig.Host,
-		Validate: validateHost,
-	}
-	host, err := hostPrompt.Run()
-	if err != nil {
-		return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
-			Message("用户取消了输入").Build()
-	}
-	oracleConfig.Host = strings.TrimSpace(host)
+// oracleWizardSteps Oracle数据库配置的向导步骤
+func oracleWizardSteps(oracleConfig *config.OracleConfig) []wizardStep {
+	steps := []wizardStep{
+		{run: func() error {
+			host, err := wizardPrompt(promptui.Prompt{
+				Label:    "Oracle主机地址",
+				Default:  oracleConfig.Host,
+				Validate: validateOracleHost,
+			})
+			if err != nil {
+				return err
+			}
+			oracleConfig.Host = host
+			return nil
+		}},
+		{run: func() error {
+			useTCPS, err := wizardConfirm("是否使用 TCPS（SSL）加密连接", oracleConfig.UseTCPS)
+			if err != nil {
+				return err
+			}
+			// 切换协议时把默认端口改为对应协议的常用端口
+			if useTCPS && !oracleConfig.UseTCPS && oracleConfig.Port == config.DefaultOraclePort {
+				oracleConfig.Port = config.DefaultOracleTCPSPort
+			} else if !useTCPS && oracleConfig.UseTCPS && oracleConfig.Port == config.DefaultOracleTCPSPort {
+				oracleConfig.Port = config.DefaultOraclePort
+			}
+			oracleConfig.UseTCPS = useTCPS
+			return nil
+		}},
+		{run: func() error {
+			return promptPort("Oracle端口", &oracleConfig.Port, func(port int) *config.ValidationWarning {
+				return config.AdviseOraclePort(port, oracleConfig.UseTCPS)
+			})
+		}},
+		{run: func() error {
+			// 选择SID或Service Name，光标停在当前使用的类型上
+			cursor := 0
+			if oracleConfig.Service != "" {
+				cursor = 1
+			}
+			typeIndex, err := wizardSelect("选择Oracle连接类型", []string{
+				"SID - 系统标识符",
+				"Service Name - 服务名称",
+			}, cursor)
+			if err != nil {
+				return err
+			}
 
-	// 配置端口
-	portPrompt := promptui.Prompt{
-		Label:    "Oracle端口",
-		Default:  strconv.Itoa(oracleConfig.Port),
-		Validate: validatePort,
-	}
-	portStr, err := portPrompt.Run()
-	if err != nil {
-		return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
-			Message("用户取消了输入").Build()
-	}
-	if port, err := strconv.Atoi(strings.TrimSpace(portStr)); err == nil {
-		oracleConfig.Port = port
-	}
+			if typeIndex == 0 {
+				// 配置SID
+				sid, err := wizardPrompt(promptui.Prompt{
+					Label:    "Oracle SID",
+					Default:  oracleConfig.SID,
+					Validate: validateOracleIdentifier,
+				})
+				if err != nil {
+					return err
+				}
+				oracleConfig.SID = sid
+				oracleConfig.Service = "" // 清空Service Name
+			} else {
+				// 配置Service Name
+				service, err := wizardPrompt(promptui.Prompt{
+					Label:    "Oracle Service Name",
+					Default:  oracleConfig.Service,
+					Validate: validateOracleIdentifier,
+				})
+				if err != nil {
+					return err
+				}
+				oracleConfig.Service = service
+				oracleConfig.SID = "" // 清空SID
+			}
+			return nil
+		}},
+	}
+
+	// 配置 TCPS 的 wallet 和证书验证（仅启用 TCPS 时）
+	steps = append(steps, oracleTLSSteps(oracleConfig)...)
+
+	steps = append(steps, []wizardStep{
+		{run: func() error {
+			username, err := wizardPromptWithAdvice(promptui.Prompt{
+				Label:    "Oracle用户名",
+				Default:  oracleConfig.Username,
+				Validate: validateRequired,
+			}, config.AdviseOracleUsername)
+			if err != nil {
+				return err
+			}
+			oracleConfig.Username = username
+			return nil
+		}},
+		{run: func() error {
+			return wizardPassword("Oracle密码", &oracleConfig.Password)
+		}},
+	}...)
 
-	// 选择SID或Service Name
-	typePrompt := promptui.Select{
-		Label: "选择Oracle连接类型",
-		Items: []string{
-			"SID - 系统标识符",
-			"Service Name - 服务名称",
-		},
-	}
-	typeIndex, _, err := typePrompt.Run()
-	if err != nil {
-		return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
-			Message("用户取消了选择").Build()
-	}
+	// 配置只读测试账号（可选）
+	steps = append(steps, testAccountSteps("Oracle", &oracleConfig.TestUsername, &oracleConfig.TestPassword)...)
 
-	if typeIndex == 0 {
-		// 配置SID
-		sidPrompt := promptui.Prompt{
-			Label:    "Oracle SID",
-			Default:  oracleConfig.SID,
-			Validate: validateRequired,
-		}
-		sid, err := sidPrompt.Run()
+	// 配置Schema（可选）
+	steps = append(steps, wizardStep{run: func() error {
+		schema, err := wizardPrompt(promptui.Prompt{
+			Label:   "Oracle Schema（可选，直接回车跳过）",
+			Default: oracleConfig.Schema,
+		})
 		if err != nil {
-			return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
-				Message("用户取消了输入").Build()
+			return err
 		}
-		oracleConfig.SID = strings.TrimSpace(sid)
-		oracleConfig.Service = "" // 清空Service Name
-	} else {
-		// 配置Service Name
-		servicePrompt := promptui.Prompt{
-			Label:    "Oracle Service Name",
-			Default:  oracleConfig.Service,
-			Validate: validateRequired,
-		}
-		service, err := servicePrompt.Run()
-		if err != nil {
-			return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
-				Message("用户取消了输入").Build()
+		oracleConfig.Schema = schema
+		return nil
+	}})
+
+	return wizardSection(func() {
+		fmt.Println("📊 Oracle数据库配置")
+		fmt.Println("─────────────────────")
+
+		// 显示当前配置
+		if oracleConfig.Host != "" {
+			fmt.Printf("当前配置: %s:%d/%s (用户: %s)\n",
+				oracleConfig.Host, oracleConfig.Port,
+				getOracleIdentifier(oracleConfig), oracleConfig.Username)
+			fmt.Println()
 		}
-		oracleConfig.Service = strings.TrimSpace(service)
-		oracleConfig.SID = "" // 清空SID
-	}
+	}, steps)
+}
 
-	// 配置用户名
-	userPrompt := promptui.Prompt{
-		Label:    "Oracle用户名",
-		Default:  oracleConfig.Username,
-		Validate: validateRequired,
-	}
-	username, err := userPrompt.Run()
-	if err != nil {
-		return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
-			Message("用户取消了输入").Build()
+// oracleTLSSteps TCPS 连接的 wallet 目录和证书验证级别的向导步骤
+func oracleTLSSteps(oracleConfig *config.OracleConfig) []wizardStep {
+	skip := func() bool { return !oracleConfig.UseTCPS }
+	return []wizardStep{
+		{skip: skip, run: func() error {
+			walletDir, err := wizardPrompt(promptui.Prompt{
+				Label:    "Oracle wallet 目录（包含 cwallet.sso 和服务器CA证书）",
+				Default:  oracleConfig.WalletDir,
+				Validate: validateRequired,
+			})
+			if err != nil {
+				return err
+			}
+			oracleConfig.WalletDir = strings.TrimSpace(walletDir)
+			return nil
+		}},
+		{skip: skip, run: func() error {
+			cursor := 0
+			if oracleConfig.SSLVerifyLevel() == config.OracleSSLVerifyCA {
+				cursor = 1
+			}
+			index, err := wizardSelect("服务器证书验证级别", []string{
+				"full - 校验证书链和服务器DN（推荐）",
+				"ca - 只校验证书链，不校验DN",
+			}, cursor)
+			if err != nil {
+				return err
+			}
+			oracleConfig.SSLVerify = config.OracleSSLVerifyFull
+			if index == 1 {
+				oracleConfig.SSLVerify = config.OracleSSLVerifyCA
+				oracleConfig.SSLServerCertDN = ""
+			}
+			return nil
+		}},
+		{skip: func() bool { return skip() || oracleConfig.SSLVerifyLevel() == config.OracleSSLVerifyCA }, run: func() error {
+			dn, err := wizardPrompt(promptui.Prompt{
+				Label:   "服务器证书DN（可选，直接回车按主机名校验）",
+				Default: oracleConfig.SSLServerCertDN,
+			})
+			if err != nil {
+				return err
+			}
+			oracleConfig.SSLServerCertDN = strings.TrimSpace(dn)
+			return nil
+		}},
 	}
-	oracleConfig.Username = strings.TrimSpace(username)
+}
 
-	// 配置密码
-	passwordPrompt := promptui.Prompt{
-		Label: "Oracle密码",
-		Mask:  '*',
-		Validate: validateRequired,
-	}
-	password, err := passwordPrompt.Run()
-	if err != nil {
-		return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
-			Message("用户取消了输入").Build()
+// postgresWizardSteps PostgreSQL数据库配置的向导步骤
+func postgresWizardSteps(pgConfig *config.PostgreConfig) []wizardStep {
+	steps := []wizardStep{
+		{run: func() error {
+			host, err := wizardPrompt(promptui.Prompt{
+				Label:    "PostgreSQL主机地址",
+				Default:  pgConfig.Host,
+				Validate: validateHost,
+			})
+			if err != nil {
+				return err
+			}
+			pgConfig.Host = host
+			return nil
+		}},
+		{run: func() error {
+			return promptPort("PostgreSQL端口", &pgConfig.Port, config.AdvisePostgresPort)
+		}},
+		{run: func() error {
+			database, err := wizardPrompt(promptui.Prompt{
+				Label:    "PostgreSQL数据库名",
+				Default:  pgConfig.Database,
+				Validate: validateRequired,
+			})
+			if err != nil {
+				return err
+			}
+			pgConfig.Database = database
+			return nil
+		}},
+		{run: func() error {
+			username, err := wizardPromptWithAdvice(promptui.Prompt{
+				Label:    "PostgreSQL用户名",
+				Default:  pgConfig.Username,
+				Validate: validateRequired,
+			}, config.AdvisePostgresUsername)
+			if err != nil {
+				return err
+			}
+			pgConfig.Username = username
+			return nil
+		}},
+		{run: func() error {
+			return wizardPassword("PostgreSQL密码", &pgConfig.Password)
+		}},
 	}
-	oracleConfig.Password = strings.TrimSpace(password)
 
-	// 配置Schema（可选）
-	schemaPrompt := promptui.Prompt{
-		Label:   "Oracle Schema（可选，直接回车跳过）",
-		Default: oracleConfig.Schema,
-	}
-	schema, err := schemaPrompt.Run()
-	if err == nil {
-		oracleConfig.Schema = strings.TrimSpace(schema)
-	}
+	// 配置只读测试账号（可选）
+	steps = append(steps, testAccountSteps("PostgreSQL", &pgConfig.TestUsername, &pgConfig.TestPassword)...)
 
-	fmt.Println("✅ Oracle配置完成")
-	return nil
-}
+	// 配置Schema
+	steps = append(steps, wizardStep{run: func() error {
+		schema, err := wizardPrompt(promptui.Prompt{
+			Label:    "PostgreSQL Schema",
+			Default:  pgConfig.Schema,
+			Validate: validateRequired,
+		})
+		if err != nil {
+			return err
+		}
+		pgConfig.Schema = schema
+		return nil
+	}})
 
-// configurePostgreSQL 配置PostgreSQL数据库
-func configurePostgreSQL(pgConfig *config.PostgreConfig) error {
-	// 显示当前配置
-	if pgConfig.Host != "" {
-		fmt.Printf("当前配置: %s:%d/%s (用户: %s)\n",
-			pgConfig.Host, pgConfig.Port, pgConfig.Database, pgConfig.Username)
+	return wizardSection(func() {
 		fmt.Println()
-	}
-
-	// 配置主机
-	hostPrompt := promptui.Prompt{
-		Label:    "PostgreSQL主机地址",
-		Default:  pgConfig.Host,
-		Validate: validateHost,
-	}
-	host, err := hostPrompt.Run()
-	if err != nil {
-		return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
-			Message("用户取消了输入").Build()
-	}
-	pgConfig.Host = strings.TrimSpace(host)
+		fmt.Println("🐘 PostgreSQL数据库配置")
+		fmt.Println("──────────────────────")
+
+		// 显示当前配置
+		if pgConfig.Host != "" {
+			fmt.Printf("当前配置: %s:%d/%s (用户: %s)\n",
+				pgConfig.Host, pgConfig.Port, pgConfig.Database, pgConfig.Username)
+			fmt.Println()
+		}
+	}, steps)
+}
 
-	// 配置端口
-	portPrompt := promptui.Prompt{
-		Label:    "PostgreSQL端口",
-		Default:  strconv.Itoa(pgConfig.Port),
+// promptPort 向导中输入端口，advise 检查端口是否合理（如误填了另一种数据库的端口）
+func promptPort(label string, port *int, advise func(port int) *config.ValidationWarning) error {
+	portStr, err := wizardPromptWithAdvice(promptui.Prompt{
+		Label:    label,
+		Default:  strconv.Itoa(*port),
 		Validate: validatePort,
-	}
-	portStr, err := portPrompt.Run()
+	}, func(input string) *config.ValidationWarning {
+		value, _ := strconv.Atoi(input)
+		return advise(value)
+	})
 	if err != nil {
-		return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
-			Message("用户取消了输入").Build()
+		return err
 	}
-	if port, err := strconv.Atoi(strings.TrimSpace(portStr)); err == nil {
-		pgConfig.Port = port
+	if value, err := strconv.Atoi(portStr); err == nil {
+		*port = value
 	}
+	return nil
+}
 
-	// 配置数据库名
-	dbPrompt := promptui.Prompt{
-		Label:    "PostgreSQL数据库名",
-		Default:  pgConfig.Database,
-		Validate: validateRequired,
-	}
-	database, err := dbPrompt.Run()
-	if err != nil {
-		return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
-			Message("用户取消了输入").Build()
-	}
-	pgConfig.Database = strings.TrimSpace(database)
-
-	// 配置用户名
-	userPrompt := promptui.Prompt{
-		Label:    "PostgreSQL用户名",
-		Default:  pgConfig.Username,
-		Validate: validateRequired,
-	}
-	username, err := userPrompt.Run()
-	if err != nil {
-		return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
-			Message("用户取消了输入").Build()
-	}
-	pgConfig.Username = strings.TrimSpace(username)
-
-	// 配置密码
-	passwordPrompt := promptui.Prompt{
-		Label: "PostgreSQL密码",
-		Mask:  '*',
-		Validate: validateRequired,
-	}
-	password, err := passwordPrompt.Run()
-	if err != nil {
-		return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
-			Message("用户取消了输入").Build()
-	}
-	pgConfig.Password = strings.TrimSpace(password)
-
-	// 配置Schema
-	schemaPrompt := promptui.Prompt{
-		Label:   "PostgreSQL Schema",
-		Default: pgConfig.Schema,
-		Validate: validateRequired,
-	}
-	schema, err := schemaPrompt.Run()
-	if err != nil {
-		return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
-			Message("用户取消了输入").Build()
+// testAccountSteps 连接测试使用的只读账号的向导步骤，未启用时清除
+//
+// 选择不启用时暂存已有账号，返回上一步重新启用时恢复为默认值。
+func testAccountSteps(label string, username, password *string) []wizardStep {
+	enabled := *username != ""
+	var savedUsername, savedPassword string
+	skip := func() bool { return !enabled }
+
+	return []wizardStep{
+		{run: func() error {
+			answer, err := wizardConfirm(fmt.Sprintf("是否为%s连接测试使用独立的只读账号", label), enabled)
+			if err != nil {
+				return err
+			}
+			enabled = answer
+			if !enabled && *username != "" {
+				savedUsername, savedPassword = *username, *password
+				*username, *password = "", ""
+			} else if enabled && *username == "" {
+				*username, *password = savedUsername, savedPassword
+			}
+			return nil
+		}},
+		{skip: skip, run: func() error {
+			testUsername, err := wizardPrompt(promptui.Prompt{
+				Label:    label + "测试账号用户名",
+				Default:  *username,
+				Validate: validateRequired,
+			})
+			if err != nil {
+				return err
+			}
+			*username = testUsername
+			return nil
+		}},
+		{skip: skip, run: func() error {
+			return wizardPassword(label+"测试账号密码", password)
+		}},
 	}
-	pgConfig.Schema = strings.TrimSpace(schema)
-
-	fmt.Println("✅ PostgreSQL配置完成")
-	return nil
 }
 
 // 验证函数
@@ -490,6 +797,25 @@ func validateHost(input string) error {
 	return nil
 }
 
+// validateOracleHost 验证Oracle主机地址，识别误填的导出文件路径
+func validateOracleHost(input string) error {
+	if config.IsDumpFilePath(input) {
+		return errDumpFileInput
+	}
+	return validateHost(input)
+}
+
+// validateOracleIdentifier 验证SID或Service Name，识别误填的导出文件路径
+func validateOracleIdentifier(input string) error {
+	if config.IsDumpFilePath(input) {
+		return errDumpFileInput
+	}
+	return validateRequired(input)
+}
+
+// errDumpFileInput 连接信息中填写了导出文件时的提示
+var errDumpFileInput = fmt.Errorf("这是expdp导出文件，需要在线Oracle连接：请先用 impdp 导入Oracle实例，再填写该实例的地址")
+
 func validatePort(input string) error {
 	input = strings.TrimSpace(input)
 	if input == "" {
@@ -523,6 +849,7 @@ func getOracleIdentifier(oracleConfig *config.OracleConfig) string {
 // testConnections 测试数据库连接
 func testConnections(cfg *config.ProjectConfig) {
 	tester := oracle.NewConnectionTester()
+	tester.SetClientConfig(&cfg.OracleClient)
 
 	// 测试Oracle连接
 	fmt.Print("🔍 测试Oracle连接... ")
@@ -568,6 +895,9 @@ func showConfigurationSummary(cfg *config.ProjectConfig) {
 	fmt.Println("📊 配置摘要")
 	fmt.Println("─────────")
 	fmt.Printf("Oracle:     %s:%d/%s\n", cfg.Oracle.Host, cfg.Oracle.Port, getOracleIdentifier(&cfg.Oracle))
+	if cfg.Oracle.UseTCPS {
+		fmt.Printf("            TCPS 加密（wallet: %s，证书验证: %s）\n", cfg.Oracle.WalletDir, cfg.Oracle.SSLVerifyLevel())
+	}
 	fmt.Printf("PostgreSQL: %s:%d/%s\n", cfg.PostgreSQL.Host, cfg.PostgreSQL.Port, cfg.PostgreSQL.Database)
 	fmt.Println()
 	fmt.Println("🚀 下一步操作:")
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+var configBackupKeep int
+
+// configBackupCmd 配置备份命令
+var configBackupCmd = &cobra.Command{
+	Use:   "备份",
+	Short: "查看和创建配置备份",
+	Long: `管理配置文件的历史备份。
+
+每次运行配置向导前会把当前配置复制为带时间戳的备份，保存在配置文件所在目录的
+config-backups 下，如 .ora2pg-admin/config-backups/config-20240102-150405.yaml。
+默认保留最新的 10 份，超出时自动删除最老的备份（--backup-keep 调整，0 表示不清理）。
+
+示例：
+  ora2pg-admin 配置 备份 列表
+  ora2pg-admin 配置 备份 创建
+  ora2pg-admin 配置 恢复 1`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// configBackupListCmd 列出配置备份
+var configBackupListCmd = &cobra.Command{
+	Use:   "列表",
+	Short: "列出配置备份，最新的在前",
+	Run:   runConfigBackupList,
+}
+
+// configBackupCreateCmd 手动创建配置备份
+var configBackupCreateCmd = &cobra.Command{
+	Use:   "创建",
+	Short: "立即备份当前配置",
+	Run:   runConfigBackupCreate,
+}
+
+// configRestoreCmd 恢复配置备份命令
+var configRestoreCmd = &cobra.Command{
+	Use:   "恢复 <备份>",
+	Short: "将配置回滚到历史备份",
+	Long: `用历史备份覆盖当前配置文件，覆盖前会先备份当前配置，恢复错了可以再恢复回来。
+
+<备份> 可以是 '配置 备份 列表' 中的序号（1 为最新）或备份文件名。
+恢复前需要确认，使用 --yes 跳过确认。
+
+示例：
+  ora2pg-admin 配置 恢复 2
+  ora2pg-admin 配置 恢复 config-20240102-150405.yaml`,
+	Args: cobra.ExactArgs(1),
+	Run:  runConfigRestore,
+}
+
+func init() {
+	configCmd.AddCommand(configBackupCmd)
+	configBackupCmd.AddCommand(configBackupListCmd)
+	configBackupCmd.AddCommand(configBackupCreateCmd)
+	configCmd.AddCommand(configRestoreCmd)
+
+	configCmd.PersistentFlags().IntVar(&configBackupKeep, "backup-keep", config.DefaultConfigBackupKeep, "保留的配置备份数量，超出时删除最老的备份（0表示不清理）")
+}
+
+// runConfigBackupList 列出配置备份
+func runConfigBackupList(cmd *cobra.Command, args []string) {
+	configPath := getConfigFilePath()
+	backups, err := config.ListConfigBackups(configPath)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	fmt.Printf("🗂️ 配置备份: %s\n", config.ConfigBackupDir(configPath))
+	fmt.Println()
+	if len(backups) == 0 {
+		fmt.Println("💡 还没有配置备份，运行配置向导或 'ora2pg-admin 配置 备份 创建' 时会自动创建")
+		return
+	}
+	for i, backup := range backups {
+		fmt.Printf("  %2d. %s  %s  %.1f KB\n", i+1, backup.Time.Format("2006-01-02 15:04:05"), backup.Name, float64(backup.Size)/1024)
+	}
+	fmt.Println()
+	fmt.Println("💡 使用 'ora2pg-admin 配置 恢复 <序号>' 回滚到指定备份")
+}
+
+// runConfigBackupCreate 手动创建配置备份
+func runConfigBackupCreate(cmd *cobra.Command, args []string) {
+	configPath := getConfigFilePath()
+	if !utils.NewFileUtils().FileExists(configPath) {
+		fmt.Printf("%s\n", utils.FormatError(utils.ConfigErrors.FileNotFound(configPath)))
+		exit(1)
+	}
+	backup, err := config.BackupConfig(configPath, configBackupKeep)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	fmt.Printf("📋 已创建配置备份: %s\n", backup.Path)
+}
+
+// runConfigRestore 恢复配置备份
+func runConfigRestore(cmd *cobra.Command, args []string) {
+	configPath := getConfigFilePath()
+	backup, err := config.FindConfigBackup(configPath, args[0])
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	fmt.Printf("🔄 将用备份 %s（%s）覆盖 %s\n", backup.Name, backup.Time.Format("2006-01-02 15:04:05"), configPath)
+	if !utils.ConfirmDangerous("确认恢复配置备份", "恢复配置备份 "+backup.Path) {
+		fmt.Println("❌ 已取消恢复")
+		return
+	}
+
+	current, err := config.RestoreConfigBackup(configPath, backup, configBackupKeep)
+	if current != nil {
+		fmt.Printf("📋 已备份当前配置: %s\n", current.Path)
+	}
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	fmt.Printf("✅ 已恢复配置: %s\n", configPath)
+
+	// 恢复的内容仍需通过当前的校验规则
+	manager := config.NewManager()
+	if err := manager.LoadConfig(configPath); err != nil {
+		fmt.Printf("⚠️ 恢复的配置无法加载:\n%s\n", utils.FormatError(configLoadError(err)))
+		return
+	}
+	validator := config.NewValidator()
+	if result := validator.ValidateConfig(manager.GetConfig()); !result.Valid {
+		fmt.Printf("⚠️ 恢复的配置未通过校验，请检查:\n%s\n", validator.GetValidationSummary(result))
+	}
+}
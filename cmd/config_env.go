@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+var configEnvAll bool
+
+// configEnvCmd 查看环境变量覆盖
+var configEnvCmd = &cobra.Command{
+	Use:   "环境变量",
+	Short: "查看覆盖配置项的环境变量",
+	Long: `查看当前生效的配置覆盖环境变量，加载优先级为：环境变量 > 配置文件 > 默认值。
+
+环境变量名为 ORA2PG_ADMIN_ 加配置路径，路径转大写、用下划线连接，如：
+  ORA2PG_ADMIN_ORACLE_HOST             覆盖 oracle.host
+  ORA2PG_ADMIN_MIGRATION_PARALLEL_JOBS 覆盖 migration.parallel_jobs
+  ORA2PG_ADMIN_MIGRATION_TYPES         覆盖 migration.types（逗号分隔）
+
+值为空的环境变量视为未设置；map 和对象列表不能通过环境变量覆盖。覆盖的值只在本次运行中生效，
+保存配置时仍写入配置文件中的原值。
+
+配置值中的 {{...}} 模板在覆盖之后展开（覆盖值也可以是模板），同时列出展开前后的值。
+
+示例：
+  ora2pg-admin 配置 环境变量
+  ora2pg-admin 配置 环境变量 --all`,
+	Run: runConfigEnv,
+}
+
+func init() {
+	configCmd.AddCommand(configEnvCmd)
+
+	configEnvCmd.Flags().BoolVar(&configEnvAll, "all", false, "列出全部可覆盖的配置项")
+}
+
+// runConfigEnv 显示环境变量覆盖
+func runConfigEnv(cmd *cobra.Command, args []string) {
+	fmt.Println("🌱 配置覆盖环境变量")
+	fmt.Println()
+
+	if configEnvAll {
+		for _, field := range config.EnvOverrideFields() {
+			fmt.Printf("  %-60s %-45s %s\n", field.Env, field.Path, field.Type)
+		}
+		fmt.Println()
+	}
+
+	manager, configPath, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	overrides := manager.EnvOverrides()
+	if len(overrides) == 0 {
+		fmt.Printf("💡 没有生效的环境变量覆盖，配置全部来自 %s\n", configPath)
+	} else {
+		fmt.Printf("生效的覆盖（%d 项，优先于 %s）：\n", len(overrides), configPath)
+		for i := range overrides {
+			override := &overrides[i]
+			fmt.Printf("  %s → %s = %s\n", override.Env, override.Path, override.DisplayValue())
+		}
+	}
+
+	if interpolations := manager.Interpolations(); len(interpolations) > 0 {
+		fmt.Println()
+		fmt.Printf("展开的模板（%d 项）：\n", len(interpolations))
+		for i := range interpolations {
+			interpolation := &interpolations[i]
+			fmt.Printf("  %s: %s → %s\n", interpolation.Path, interpolation.Raw(), interpolation.Expanded())
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+var (
+	configFragmentsUse   []string
+	configFragmentsClear bool
+)
+
+// fragmentSourceNames 片段来源的显示名称
+var fragmentSourceNames = map[string]string{
+	config.FragmentSourceBuiltin: "内置",
+	config.FragmentSourceUser:    "用户级",
+	config.FragmentSourceProject: "项目级",
+}
+
+// configFragmentsCmd 管理ora2pg配置片段
+var configFragmentsCmd = &cobra.Command{
+	Use:   "片段 [片段名...]",
+	Short: "查看和组合ora2pg配置片段",
+	Long: `把ora2pg配置拆成可复用的片段，按迁移场景组合，生成ora2pg配置时按 migration.fragments 的顺序合并。
+
+内置片段：performance（性能优化）、lob（LOB处理）、structure-first（结构优先）、
+data-first（数据优先）、minimal-downtime（最小停机）。
+自定义片段放在项目的 .ora2pg-admin/fragments/<名称>.conf 或 ~/.ora2pg-admin/fragments/<名称>.conf，
+同名时项目级优先于用户级，用户级优先于内置片段。
+
+合并规则：片段中的指令覆盖主模板和前面片段中的同名指令；EXCLUDE、MODIFY_TYPE 等列表指令合并去重；
+连接、迁移类型、并行度等由项目配置生成的指令不能在片段中设置。
+
+不指定片段名时列出可用的片段和当前的组合，指定片段名时显示片段内容；--use 设置组合并重新生成ora2pg配置。
+
+示例：
+  ora2pg-admin 配置 片段
+  ora2pg-admin 配置 片段 performance lob
+  ora2pg-admin 配置 片段 --use performance,lob`,
+	Run: runConfigFragments,
+}
+
+func init() {
+	configCmd.AddCommand(configFragmentsCmd)
+
+	configFragmentsCmd.Flags().StringSliceVar(&configFragmentsUse, "use", nil, "按顺序组合的片段（逗号分隔），保存到 migration.fragments")
+	configFragmentsCmd.Flags().BoolVar(&configFragmentsClear, "clear", false, "不再使用配置片段")
+}
+
+// runConfigFragments 查看或设置配置片段的组合
+func runConfigFragments(cmd *cobra.Command, args []string) {
+	fmt.Println("🧩 ora2pg配置片段")
+	fmt.Println()
+
+	manager, _, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	cfg := manager.GetConfig()
+
+	switch {
+	case configFragmentsClear || len(configFragmentsUse) > 0:
+		saveFragments(manager, configFragmentsUse)
+	case len(args) > 0:
+		showFragments(args)
+	default:
+		listFragments(cfg)
+	}
+}
+
+// listFragments 列出可用的片段和当前组合的合并结果
+func listFragments(cfg *config.ProjectConfig) {
+	fragments, err := config.ListOra2pgFragments(".")
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	used := make(map[string]int, len(cfg.Migration.Fragments))
+	for i, name := range cfg.Migration.Fragments {
+		used[name] = i + 1
+	}
+
+	fmt.Println("可用的片段：")
+	for _, fragment := range fragments {
+		mark := "  "
+		if used[fragment.Name] > 0 {
+			mark = fmt.Sprintf("%d.", used[fragment.Name])
+		}
+		fmt.Printf("  %s %-18s [%s] %s\n", mark, fragment.Name, fragmentSourceNames[fragment.Source], fragment.Description)
+	}
+	fmt.Println()
+
+	if len(cfg.Migration.Fragments) == 0 {
+		fmt.Println("💡 当前未使用配置片段，可使用 --use 按场景组合，如 --use performance,lob")
+		return
+	}
+	fmt.Printf("📋 当前组合: %s\n", strings.Join(cfg.Migration.Fragments, " → "))
+	loaded, err := cfg.Migration.LoadFragments(".")
+	var directives []config.MergedDirective
+	if err == nil {
+		_, directives, err = config.MergeOra2pgFragments("", loaded)
+	}
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(utils.NewError(utils.ErrorTypeConfig, "FRAGMENT_INVALID").
+			Message("ora2pg配置片段无效").
+			Details(err.Error()).
+			Build()))
+		exit(1)
+	}
+	for _, directive := range directives {
+		overridden := ""
+		if len(directive.Overridden) > 0 {
+			overridden = fmt.Sprintf("，覆盖 %s", strings.Join(directive.Overridden, ", "))
+		}
+		fmt.Printf("   %s %s（%s%s）\n", directive.Name, directive.Value, directive.Fragment, overridden)
+	}
+	fmt.Println("💡 片段中的指令同时覆盖主模板中的同名设置，生成的 ora2pg.conf 中被覆盖的行会改为注释")
+}
+
+// showFragments 显示片段的来源和内容
+func showFragments(names []string) {
+	for i, name := range names {
+		fragment, err := config.ResolveOra2pgFragment(".", name)
+		if err == nil {
+			_, err = fragment.Directives()
+		}
+		if err != nil {
+			fmt.Printf("%s\n", utils.FormatError(utils.NewError(utils.ErrorTypeConfig, "FRAGMENT_INVALID").
+				Message(fmt.Sprintf("无法读取配置片段 %s", name)).
+				Details(err.Error()).
+				Build()))
+			exit(1)
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("📄 %s [%s]\n", fragment.Name, fragmentSourceNames[fragment.Source])
+		if fragment.Description != "" {
+			fmt.Printf("   %s\n", fragment.Description)
+		}
+		if fragment.Path != "" {
+			fmt.Printf("   文件: %s\n", fragment.Path)
+		}
+		fmt.Println("─────────────────")
+		fmt.Print(fragment.Content)
+	}
+}
+
+// saveFragments 保存片段组合并重新生成ora2pg配置
+func saveFragments(manager *config.Manager, names []string) {
+	var fragments []string
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			fragments = append(fragments, name)
+		}
+	}
+	cfg := manager.GetConfig()
+	cfg.Migration.Fragments = fragments
+	if _, err := cfg.Migration.LoadFragments("."); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(utils.NewError(utils.ErrorTypeConfig, "FRAGMENT_NOT_FOUND").
+			Message("无法加载ora2pg配置片段").
+			Details(err.Error()).
+			Suggestion("运行 'ora2pg-admin 配置 片段' 查看可用的片段").
+			Build()))
+		exit(1)
+	}
+
+	if err := saveConfiguration(manager); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	if len(fragments) == 0 {
+		fmt.Println("✅ 已清除配置片段")
+	} else {
+		fmt.Printf("✅ 已保存片段组合: %s\n", strings.Join(fragments, " → "))
+	}
+	if err := generateOra2pgConfig(cfg); err != nil {
+		fmt.Printf("⚠️ 生成ora2pg配置文件时出现警告\n%s\n", utils.FormatError(err))
+	}
+}
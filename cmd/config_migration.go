@@ -2,11 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"runtime"
 	"strconv"
 	"strings"
 
 	"github.com/manifoldco/promptui"
 	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/service"
 	"ora2pg-admin/internal/utils"
 )
 
@@ -16,6 +18,7 @@ func configureMigrationTypes(migrationConfig *config.MigrationConfig) error {
 	availableTypes := []string{
 		"TABLE - 表结构和数据",
 		"VIEW - 视图",
+		"MVIEW - 物化视图",
 		"SEQUENCE - 序列",
 		"INDEX - 索引",
 		"TRIGGER - 触发器",
@@ -24,6 +27,8 @@ func configureMigrationTypes(migrationConfig *config.MigrationConfig) error {
 		"PACKAGE - 包",
 		"TYPE - 自定义类型",
 		"GRANT - 权限",
+		"DBLINK - 数据库链接",
+		"SYNONYM - 同义词",
 		"TABLESPACE - 表空间",
 		"PARTITION - 分区",
 	}
@@ -31,6 +36,7 @@ func configureMigrationTypes(migrationConfig *config.MigrationConfig) error {
 	typeMap := map[string]string{
 		"TABLE - 表结构和数据":    "TABLE",
 		"VIEW - 视图":        "VIEW",
+		"MVIEW - 物化视图":    "MVIEW",
 		"SEQUENCE - 序列":    "SEQUENCE",
 		"INDEX - 索引":       "INDEX",
 		"TRIGGER - 触发器":    "TRIGGER",
@@ -39,6 +45,8 @@ func configureMigrationTypes(migrationConfig *config.MigrationConfig) error {
 		"PACKAGE - 包":       "PACKAGE",
 		"TYPE - 自定义类型":     "TYPE",
 		"GRANT - 权限":       "GRANT",
+		"DBLINK - 数据库链接":  "DBLINK",
+		"SYNONYM - 同义词":   "SYNONYM",
 		"TABLESPACE - 表空间": "TABLESPACE",
 		"PARTITION - 分区":   "PARTITION",
 	}
@@ -134,51 +142,251 @@ func configureMigrationTypes(migrationConfig *config.MigrationConfig) error {
 
 // configurePerformanceSettings 配置性能参数
 func configurePerformanceSettings(migrationConfig *config.MigrationConfig) error {
-	// 配置并行作业数
-	jobsPrompt := promptui.Prompt{
-		Label:    "并行作业数（建议设置为CPU核心数）",
-		Default:  strconv.Itoa(migrationConfig.ParallelJobs),
-		Validate: validatePositiveInt,
+	printWizardHint()
+	if err := runWizard(performanceWizardSteps(migrationConfig)); err != nil {
+		return err
 	}
-	jobsStr, err := jobsPrompt.Run()
-	if err != nil {
-		return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
-			Message("用户取消了输入").Build()
+
+	fmt.Println("✅ 性能参数配置完成")
+	return nil
+}
+
+// performanceWizardSteps 性能参数的向导步骤，并行作业数和批处理大小超出建议范围时给出软提示
+func performanceWizardSteps(migrationConfig *config.MigrationConfig) []wizardStep {
+	return []wizardStep{
+		{run: func() error {
+			jobsStr, err := wizardPromptWithAdvice(promptui.Prompt{
+				Label:    fmt.Sprintf("并行作业数（建议不超过CPU核心数，本机 %d 核）", runtime.NumCPU()),
+				Default:  strconv.Itoa(migrationConfig.ParallelJobs),
+				Validate: validatePositiveInt,
+			}, func(input string) *config.ValidationWarning {
+				jobs, _ := strconv.Atoi(input)
+				return config.AdviseParallelJobs(jobs, runtime.NumCPU())
+			})
+			if err != nil {
+				return err
+			}
+			migrationConfig.ParallelJobs, _ = strconv.Atoi(jobsStr)
+			return nil
+		}},
+		{run: func() error {
+			batchStr, err := wizardPromptWithAdvice(promptui.Prompt{
+				Label:    "批处理大小（每次处理的记录数）",
+				Default:  strconv.Itoa(migrationConfig.BatchSize),
+				Validate: validatePositiveInt,
+			}, func(input string) *config.ValidationWarning {
+				batch, _ := strconv.Atoi(input)
+				return config.AdviseBatchSize(batch)
+			})
+			if err != nil {
+				return err
+			}
+			migrationConfig.BatchSize, _ = strconv.Atoi(batchStr)
+			return nil
+		}},
+		{run: func() error {
+			output, err := wizardPrompt(promptui.Prompt{
+				Label:    "输出目录",
+				Default:  migrationConfig.OutputDir,
+				Validate: validateRequired,
+			})
+			if err != nil {
+				return err
+			}
+			migrationConfig.OutputDir = output
+			return nil
+		}},
+		{run: func() error {
+			// 配置大表分片并行导出
+			answer, err := wizardConfirm("是否为超大表配置分片并行导出", len(migrationConfig.LargeTables) > 0)
+			if err != nil || !answer {
+				return err
+			}
+			return configureLargeTables(migrationConfig)
+		}},
 	}
-	if jobs, err := strconv.Atoi(strings.TrimSpace(jobsStr)); err == nil {
-		migrationConfig.ParallelJobs = jobs
+}
+
+// configureLargeTables 标记大表并设置分片数
+func configureLargeTables(migrationConfig *config.MigrationConfig) error {
+	const (
+		addItem    = "添加大表"
+		removeItem = "移除大表"
+		doneItem   = "DONE - 完成配置"
+	)
+
+	for {
+		fmt.Println("\n当前大表:")
+		if len(migrationConfig.LargeTables) == 0 {
+			fmt.Println("  (无)")
+		}
+		for _, table := range migrationConfig.LargeTables {
+			column := table.SplitColumn
+			if column == "" {
+				column = "数值主键"
+			}
+			fmt.Printf("  • %s: %d 个分片，分片列 %s\n", table.Name, table.Shards, column)
+		}
+		fmt.Println()
+
+		actionPrompt := promptui.Select{
+			Label: "选择操作",
+			Items: []string{addItem, removeItem, doneItem},
+		}
+		_, action, err := actionPrompt.Run()
+		if err != nil {
+			return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
+				Message("用户取消了选择").Build()
+		}
+
+		switch action {
+		case addItem:
+			table, err := promptLargeTable()
+			if err != nil {
+				return err
+			}
+			migrationConfig.LargeTables = append(migrationConfig.LargeTables, table)
+		case removeItem:
+			if len(migrationConfig.LargeTables) == 0 {
+				continue
+			}
+			names := make([]string, len(migrationConfig.LargeTables))
+			for i, table := range migrationConfig.LargeTables {
+				names[i] = table.Name
+			}
+			removePrompt := promptui.Select{Label: "选择要移除的表", Items: names}
+			index, _, err := removePrompt.Run()
+			if err != nil {
+				continue
+			}
+			migrationConfig.LargeTables = append(migrationConfig.LargeTables[:index], migrationConfig.LargeTables[index+1:]...)
+		default:
+			if len(migrationConfig.LargeTables) > 0 && migrationConfig.OracleCopies() > migrationConfig.ParallelJobs {
+				fmt.Printf("💡 分片数 %d 大于并行作业数 %d，写入PostgreSQL可能成为瓶颈\n",
+					migrationConfig.OracleCopies(), migrationConfig.ParallelJobs)
+			}
+			return nil
+		}
 	}
+}
+
+// promptLargeTable 输入单张大表的分片配置
+func promptLargeTable() (config.LargeTableConfig, error) {
+	cancelled := utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
+		Message("用户取消了输入").Build()
 
-	// 配置批处理大小
-	batchPrompt := promptui.Prompt{
-		Label:    "批处理大小（每次处理的记录数）",
-		Default:  strconv.Itoa(migrationConfig.BatchSize),
-		Validate: validatePositiveInt,
+	namePrompt := promptui.Prompt{
+		Label:    "表名（如 ORDERS 或 SALES.ORDERS）",
+		Validate: validateRequired,
 	}
-	batchStr, err := batchPrompt.Run()
+	name, err := namePrompt.Run()
 	if err != nil {
-		return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
-			Message("用户取消了输入").Build()
+		return config.LargeTableConfig{}, cancelled
 	}
-	if batch, err := strconv.Atoi(strings.TrimSpace(batchStr)); err == nil {
-		migrationConfig.BatchSize = batch
+
+	shardsPrompt := promptui.Prompt{
+		Label:   fmt.Sprintf("分片数（2-%d）", config.MaxTableShards),
+		Default: "4",
+		Validate: func(input string) error {
+			value, err := strconv.Atoi(strings.TrimSpace(input))
+			if err != nil || value < 2 || value > config.MaxTableShards {
+				return fmt.Errorf("分片数必须在 2-%d 之间", config.MaxTableShards)
+			}
+			return nil
+		},
 	}
+	shardsStr, err := shardsPrompt.Run()
+	if err != nil {
+		return config.LargeTableConfig{}, cancelled
+	}
+	shards, _ := strconv.Atoi(strings.TrimSpace(shardsStr))
 
-	// 配置输出目录
-	outputPrompt := promptui.Prompt{
-		Label:   "输出目录",
-		Default: migrationConfig.OutputDir,
-		Validate: validateRequired,
+	columnPrompt := promptui.Prompt{
+		Label: "分片列（留空使用数值主键，无主键表填 ROWID）",
 	}
-	output, err := outputPrompt.Run()
+	column, err := columnPrompt.Run()
 	if err != nil {
-		return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
-			Message("用户取消了输入").Build()
+		return config.LargeTableConfig{}, cancelled
 	}
-	migrationConfig.OutputDir = strings.TrimSpace(output)
 
-	fmt.Println("✅ 性能参数配置完成")
-	return nil
+	return config.LargeTableConfig{
+		Name:        strings.ToUpper(strings.TrimSpace(name)),
+		Shards:      shards,
+		SplitColumn: strings.ToUpper(strings.TrimSpace(column)),
+	}, nil
+}
+
+// configureEnvironment 添加或移除执行ora2pg时的自定义环境变量
+func configureEnvironment(cfg *config.ProjectConfig) error {
+	const (
+		setItem    = "添加或修改变量"
+		removeItem = "移除变量"
+		doneItem   = "DONE - 完成配置"
+	)
+	cancelled := utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
+		Message("用户取消了输入").Build()
+
+	for {
+		fmt.Println("\n当前自定义环境变量:")
+		if len(cfg.Environment) == 0 {
+			fmt.Println("  (无)")
+		}
+		for _, name := range cfg.EnvironmentNames() {
+			fmt.Printf("  • %s=%s\n", name, cfg.Environment[name])
+		}
+		fmt.Println()
+
+		actionPrompt := promptui.Select{
+			Label: "选择操作",
+			Items: []string{setItem, removeItem, doneItem},
+		}
+		_, action, err := actionPrompt.Run()
+		if err != nil {
+			return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
+				Message("用户取消了选择").Build()
+		}
+
+		switch action {
+		case setItem:
+			namePrompt := promptui.Prompt{
+				Label: "变量名（如 TNS_ADMIN）",
+				Validate: func(input string) error {
+					return config.ValidateEnvName(strings.TrimSpace(input))
+				},
+			}
+			name, err := namePrompt.Run()
+			if err != nil {
+				return cancelled
+			}
+			name = strings.TrimSpace(name)
+
+			valuePrompt := promptui.Prompt{
+				Label:   "变量值（可用 $VAR 引用已有变量）",
+				Default: cfg.Environment[name],
+			}
+			value, err := valuePrompt.Run()
+			if err != nil {
+				return cancelled
+			}
+			if cfg.Environment == nil {
+				cfg.Environment = make(map[string]string)
+			}
+			cfg.Environment[name] = strings.TrimSpace(value)
+		case removeItem:
+			names := cfg.EnvironmentNames()
+			if len(names) == 0 {
+				continue
+			}
+			removePrompt := promptui.Select{Label: "选择要移除的变量", Items: names}
+			index, _, err := removePrompt.Run()
+			if err != nil {
+				continue
+			}
+			delete(cfg.Environment, names[index])
+		default:
+			return nil
+		}
+	}
 }
 
 // configureAdvancedOptions 配置高级选项
@@ -207,10 +415,67 @@ func configureAdvancedOptions(migrationConfig *config.MigrationConfig) error {
 	}
 	migrationConfig.LogLevel = logLevel
 
+	// 配置ora2pg行为开关
+	if utils.AskYesNo("是否调整ora2pg行为开关（如 TRUNCATE_TABLE、FILE_PER_TABLE）") {
+		if err := configureOra2pgSwitches(migrationConfig); err != nil {
+			return err
+		}
+	}
+
 	fmt.Println("✅ 高级选项配置完成")
 	return nil
 }
 
+// configureOra2pgSwitches 逐个切换ora2pg布尔开关，只保存与默认值不同的项
+func configureOra2pgSwitches(migrationConfig *config.MigrationConfig) error {
+	current := make(map[string]bool)
+	for _, value := range config.ResolveOra2pgSwitches(migrationConfig.Options) {
+		current[value.Name] = value.Enabled
+	}
+
+	const doneItem = "DONE - 完成选择"
+	for {
+		items := make([]string, 0, len(config.Ora2pgSwitches)+1)
+		for _, sw := range config.Ora2pgSwitches {
+			mark := "[ ]"
+			if current[sw.Name] {
+				mark = "[✓]"
+			}
+			items = append(items, fmt.Sprintf("%s %s - %s", mark, sw.Name, sw.Description))
+		}
+		items = append(items, doneItem)
+
+		prompt := promptui.Select{
+			Label: "选择要切换的开关",
+			Items: items,
+			Size:  len(items),
+		}
+		index, result, err := prompt.Run()
+		if err != nil {
+			return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
+				Message("用户取消了选择").Build()
+		}
+		if result == doneItem {
+			break
+		}
+
+		name := config.Ora2pgSwitches[index].Name
+		current[name] = !current[name]
+	}
+
+	options := make(map[string]bool)
+	for _, sw := range config.Ora2pgSwitches {
+		if current[sw.Name] != sw.Default {
+			options[sw.Name] = current[sw.Name]
+		}
+	}
+	if len(options) == 0 {
+		options = nil
+	}
+	migrationConfig.Options = options
+	return nil
+}
+
 // previewMigrationConfig 预览迁移配置
 func previewMigrationConfig(migrationConfig *config.MigrationConfig) {
 	fmt.Printf("迁移类型: %s\n", strings.Join(migrationConfig.Types, ", "))
@@ -218,43 +483,58 @@ func previewMigrationConfig(migrationConfig *config.MigrationConfig) {
 	fmt.Printf("批处理大小: %d\n", migrationConfig.BatchSize)
 	fmt.Printf("输出目录: %s\n", migrationConfig.OutputDir)
 	fmt.Printf("日志级别: %s\n", migrationConfig.LogLevel)
+	for _, table := range migrationConfig.LargeTables {
+		fmt.Printf("大表分片: %s × %d\n", table.Name, table.Shards)
+	}
+	if migrationConfig.MaxConnections > 0 {
+		fmt.Printf("连接数上限: %d（%s）\n", migrationConfig.MaxConnections, migrationConfig.ConnectionPlan().Summary())
+	}
+	if split := migrationConfig.LogSplit; split.Enabled() {
+		var limits []string
+		if split.MaxSizeMB > 0 {
+			limits = append(limits, fmt.Sprintf("%dMB", split.MaxSizeMB))
+		}
+		if split.Interval != "" {
+			limits = append(limits, split.Interval)
+		}
+		fmt.Printf("日志分段: 每 %s\n", strings.Join(limits, " 或 "))
+	}
+	if naming := &migrationConfig.NamingConvention; naming.Enabled() {
+		fmt.Printf("命名规则: %s\n", naming.Describe())
+		for _, example := range naming.Examples(migrationConfig.PreserveCase()) {
+			note := ""
+			if example.Reserved {
+				note = "（保留字，将自动加引号）"
+			}
+			fmt.Printf("  %-14s → %s%s\n", example.Source, example.Target, note)
+		}
+	}
+	if migrationConfig.TimeZone != "" {
+		fmt.Printf("会话时区: %s\n", migrationConfig.TimeZone)
+	}
+	if len(migrationConfig.AllowTables) > 0 {
+		fmt.Printf("迁移的表: %d 张（%s）\n", len(migrationConfig.AllowTables), strings.Join(migrationConfig.AllowTables, ", "))
+	}
+	if len(migrationConfig.AllowPatterns) > 0 {
+		fmt.Printf("选择的表（正则）: %s\n", strings.Join(migrationConfig.AllowPatterns, ", "))
+	}
+	if len(migrationConfig.ExcludePatterns) > 0 {
+		fmt.Printf("排除的表（正则）: %s\n", strings.Join(migrationConfig.ExcludePatterns, ", "))
+	}
+	if changed := config.ChangedOra2pgSwitches(migrationConfig.Options); len(changed) > 0 {
+		fmt.Printf("ora2pg开关: %s\n", strings.Join(changed, ", "))
+	}
 }
 
 // confirmConfiguration 确认配置
 func confirmConfiguration() bool {
-	prompt := promptui.Prompt{
-		Label:     "确认保存配置",
-		IsConfirm: true,
-	}
-	
-	_, err := prompt.Run()
-	return err == nil
+	return utils.Confirm("确认保存配置")
 }
 
-// generateOra2pgConfig 生成ora2pg配置文件
+// generateOra2pgConfig 生成ora2pg配置文件，并调用ora2pg校验能否解析
 func generateOra2pgConfig(cfg *config.ProjectConfig) error {
-	templateEngine := config.NewTemplateEngine("templates")
-	
-	// 检查模板目录是否存在
-	fileUtils := utils.NewFileUtils()
-	if !fileUtils.DirExists("templates") {
-		// 尝试使用可执行文件目录的模板
-		if execPath, err := fileUtils.GetExecutablePath(); err == nil {
-			templateDir := fileUtils.JoinPath(execPath, "templates")
-			if fileUtils.DirExists(templateDir) {
-				templateEngine.SetTemplateDir(templateDir)
-			} else {
-				return utils.NewError(utils.ErrorTypeFile, "TEMPLATE_NOT_FOUND").
-					Message("未找到ora2pg配置模板").
-					Suggestion("请确认templates目录存在").
-					Build()
-			}
-		}
-	}
-
-	// 生成ora2pg配置文件
-	outputPath := fileUtils.JoinPath(cfg.Migration.OutputDir, "ora2pg.conf")
-	if err := templateEngine.GenerateOra2pgConfig(cfg, outputPath); err != nil {
+	outputPath := utils.NewFileUtils().JoinPath(cfg.Migration.OutputDir, "ora2pg.conf")
+	if err := service.NewOra2pgService().GenerateConfigFile(cfg, outputPath, true); err != nil {
 		return err
 	}
 
@@ -0,0 +1,480 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/utils"
+)
+
+// tableListTimeout 查询源库表名的超时时间
+const tableListTimeout = 2 * time.Minute
+
+var (
+	configTablesSchemas  []string
+	configTablesPageSize int
+	configTablesClear    bool
+)
+
+// configTablesCmd 选择迁移的表
+var configTablesCmd = &cobra.Command{
+	Use:   "表",
+	Short: "交互式选择要迁移的表",
+	Long: `连接源库列出表，勾选要迁移的表，结果保存到 migration.allow_tables 并生成 ora2pg 的 ALLOW 指令。
+
+表按模式分组、分页显示，可按名称筛选，对筛选结果全选或反选；选择分组标题切换该模式下筛选出的全部表。
+默认列出配置的 Oracle 模式，--schema 可追加其他模式，其他模式的表保存为 模式.表名。
+不选择任何表或使用 --clear 时迁移模式下的全部表。
+
+示例：
+  ora2pg-admin 配置 表
+  ora2pg-admin 配置 表 --schema HR --page-size 50
+  ora2pg-admin 配置 表 --clear`,
+	Run: runConfigTables,
+}
+
+func init() {
+	configCmd.AddCommand(configTablesCmd)
+
+	configTablesCmd.Flags().StringArrayVar(&configTablesSchemas, "schema", nil, "额外列出的Oracle模式，可重复指定")
+	configTablesCmd.Flags().IntVar(&configTablesPageSize, "page-size", 20, "每页显示的表数量")
+	configTablesCmd.Flags().BoolVar(&configTablesClear, "clear", false, "清除已选择的表，迁移模式下的全部表")
+}
+
+// runConfigTables 选择要迁移的表
+func runConfigTables(cmd *cobra.Command, args []string) {
+	fmt.Println("📋 迁移表选择")
+	fmt.Println()
+
+	manager, _, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	cfg := manager.GetConfig()
+
+	if configTablesClear {
+		cfg.Migration.AllowTables = nil
+		saveAllowTables(manager)
+		return
+	}
+
+	if !hasMigrationType(cfg.Migration.Types, "TABLE") {
+		fmt.Println("💡 当前迁移类型不含 TABLE，选择的表仍会限制 COPY、INDEX 等类型导出的表")
+	}
+
+	schemas := tableSelectorSchemas(cfg)
+	fmt.Printf("🔍 正在查询 %s 下的表...\n", strings.Join(schemas, ", "))
+	tables, err := listSourceTables(cfg, schemas)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	if len(tables) == 0 {
+		fmt.Printf("💡 %s 下没有表\n", strings.Join(schemas, ", "))
+		return
+	}
+
+	selector := newTableSelector(schemas[0], tables, configTablesPageSize)
+	if missing := selector.Preselect(cfg.Migration.AllowTables); len(missing) > 0 {
+		fmt.Printf("⚠️ 以下已选择的表在源库中不存在，将从列表中移除: %s\n", strings.Join(missing, ", "))
+	}
+
+	if err := runTableSelector(selector); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	selected := selector.Selected()
+	if len(selected) == 0 {
+		fmt.Println("💡 未选择任何表，将迁移模式下的全部表")
+	} else {
+		fmt.Printf("已选择 %d 张表: %s\n", len(selected), strings.Join(selected, ", "))
+	}
+	if !confirmConfiguration() {
+		fmt.Println("❌ 已取消，配置未修改")
+		return
+	}
+	cfg.Migration.AllowTables = selected
+	saveAllowTables(manager)
+}
+
+// saveAllowTables 保存选择的表并重新生成ora2pg配置文件
+func saveAllowTables(manager *config.Manager) {
+	if err := saveConfiguration(manager); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	if err := generateOra2pgConfig(manager.GetConfig()); err != nil {
+		fmt.Printf("⚠️ 生成ora2pg配置文件时出现警告\n%s\n", utils.FormatError(err))
+	}
+}
+
+// hasMigrationType 迁移类型列表中是否包含指定类型
+func hasMigrationType(types []string, migrationType string) bool {
+	for _, t := range types {
+		if strings.EqualFold(strings.TrimSpace(t), migrationType) {
+			return true
+		}
+	}
+	return false
+}
+
+// tableSelectorSchemas 列出表的模式，第一个为配置的模式（未配置时为登录用户）
+func tableSelectorSchemas(cfg *config.ProjectConfig) []string {
+	primary := cfg.Oracle.Schema
+	if primary == "" {
+		primary = cfg.Oracle.Username
+	}
+	schemas := []string{strings.ToUpper(strings.TrimSpace(primary))}
+	for _, schema := range configTablesSchemas {
+		schema = strings.ToUpper(strings.TrimSpace(schema))
+		if schema != "" && !containsString(schemas, schema) {
+			schemas = append(schemas, schema)
+		}
+	}
+	return schemas
+}
+
+// containsString 列表中是否包含字符串
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// listSourceTables 按模式查询源库表名
+func listSourceTables(cfg *config.ProjectConfig, schemas []string) (map[string][]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), tableListTimeout)
+	defer cancel()
+
+	runner := oracle.NewSQLPlusRunner(&cfg.Oracle, &cfg.OracleClient)
+	tables := make(map[string][]string, len(schemas))
+	for _, schema := range schemas {
+		names, err := oracle.NewInspector(runner, schema).TableNames(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tables[schema] = names
+	}
+	return tables, nil
+}
+
+// tableChoice 选择器中的一张表
+type tableChoice struct {
+	Schema   string
+	Name     string
+	Selected bool
+}
+
+// tableSelector 按模式分组、分页和筛选的多选表列表
+type tableSelector struct {
+	primary  string
+	tables   []tableChoice
+	filter   string
+	page     int
+	pageSize int
+}
+
+// newTableSelector 创建表选择器，primary 模式的表保存时不带模式前缀
+func newTableSelector(primary string, tables map[string][]string, pageSize int) *tableSelector {
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	s := &tableSelector{primary: primary, pageSize: pageSize}
+	for schema, names := range tables {
+		for _, name := range names {
+			s.tables = append(s.tables, tableChoice{Schema: schema, Name: name})
+		}
+	}
+	sort.Slice(s.tables, func(i, j int) bool {
+		a, b := s.tables[i], s.tables[j]
+		if (a.Schema == primary) != (b.Schema == primary) {
+			return a.Schema == primary
+		}
+		if a.Schema != b.Schema {
+			return a.Schema < b.Schema
+		}
+		return a.Name < b.Name
+	})
+	return s
+}
+
+// key 表保存到 allow_tables 中的名称
+func (s *tableSelector) key(table tableChoice) string {
+	if table.Schema == s.primary {
+		return table.Name
+	}
+	return table.Schema + "." + table.Name
+}
+
+// Preselect 勾选已配置的表，返回源库中不存在的表
+func (s *tableSelector) Preselect(names []string) []string {
+	index := make(map[string]int, len(s.tables))
+	for i, table := range s.tables {
+		index[strings.ToUpper(s.key(table))] = i
+	}
+	var missing []string
+	for _, name := range names {
+		if i, ok := index[strings.ToUpper(name)]; ok {
+			s.tables[i].Selected = true
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// SetFilter 按名称筛选（不区分大小写的子串匹配），并回到第一页
+func (s *tableSelector) SetFilter(filter string) {
+	s.filter = strings.ToUpper(strings.TrimSpace(filter))
+	s.page = 0
+}
+
+// visible 符合筛选条件的表的下标
+func (s *tableSelector) visible() []int {
+	var indexes []int
+	for i, table := range s.tables {
+		if s.filter == "" || strings.Contains(strings.ToUpper(s.key(table)), s.filter) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// Pages 筛选结果的页数
+func (s *tableSelector) Pages() int {
+	count := len(s.visible())
+	if count == 0 {
+		return 1
+	}
+	return (count + s.pageSize - 1) / s.pageSize
+}
+
+// NextPage 翻到下一页
+func (s *tableSelector) NextPage() {
+	if s.page < s.Pages()-1 {
+		s.page++
+	}
+}
+
+// PrevPage 翻到上一页
+func (s *tableSelector) PrevPage() {
+	if s.page > 0 {
+		s.page--
+	}
+}
+
+// pageItems 当前页的表的下标
+func (s *tableSelector) pageItems() []int {
+	indexes := s.visible()
+	start := s.page * s.pageSize
+	if start >= len(indexes) {
+		return nil
+	}
+	end := start + s.pageSize
+	if end > len(indexes) {
+		end = len(indexes)
+	}
+	return indexes[start:end]
+}
+
+// Toggle 切换一张表的选择状态
+func (s *tableSelector) Toggle(index int) {
+	s.tables[index].Selected = !s.tables[index].Selected
+}
+
+// ToggleSchema 切换模式下筛选出的全部表：存在未选择的表时全选，否则全部取消
+func (s *tableSelector) ToggleSchema(schema string) {
+	var indexes []int
+	for _, i := range s.visible() {
+		if s.tables[i].Schema == schema {
+			indexes = append(indexes, i)
+		}
+	}
+	s.setAll(indexes, !s.allSelected(indexes))
+}
+
+// SelectAll 全选筛选结果，已全部选择时全部取消
+func (s *tableSelector) SelectAll() {
+	indexes := s.visible()
+	s.setAll(indexes, !s.allSelected(indexes))
+}
+
+// Invert 反选筛选结果
+func (s *tableSelector) Invert() {
+	for _, i := range s.visible() {
+		s.Toggle(i)
+	}
+}
+
+// allSelected 下标对应的表是否都已选择
+func (s *tableSelector) allSelected(indexes []int) bool {
+	for _, i := range indexes {
+		if !s.tables[i].Selected {
+			return false
+		}
+	}
+	return true
+}
+
+// setAll 设置下标对应的表的选择状态
+func (s *tableSelector) setAll(indexes []int, selected bool) {
+	for _, i := range indexes {
+		s.tables[i].Selected = selected
+	}
+}
+
+// Selected 已选择的表，按列表顺序
+func (s *tableSelector) Selected() []string {
+	var names []string
+	for _, table := range s.tables {
+		if table.Selected {
+			names = append(names, s.key(table))
+		}
+	}
+	return names
+}
+
+// Status 选择器状态行：已选数量、筛选条件和页码
+func (s *tableSelector) Status() string {
+	status := fmt.Sprintf("已选 %d/%d 张表", len(s.Selected()), len(s.tables))
+	if s.filter != "" {
+		status += fmt.Sprintf("，筛选 %q 匹配 %d 张", s.filter, len(s.visible()))
+	}
+	return status + fmt.Sprintf("，第 %d/%d 页", s.page+1, s.Pages())
+}
+
+// 选择器菜单项的动作
+const (
+	tableActionToggle = iota
+	tableActionSchema
+	tableActionFilter
+	tableActionSelectAll
+	tableActionInvert
+	tableActionPrev
+	tableActionNext
+	tableActionDone
+)
+
+// tableMenuItem 选择器菜单中的一项
+type tableMenuItem struct {
+	Label  string
+	action int
+	index  int
+	schema string
+}
+
+// Menu 当前页的菜单：按模式分组的表，之后是筛选、全选、反选、翻页和完成
+func (s *tableSelector) Menu() []tableMenuItem {
+	var items []tableMenuItem
+	schema := ""
+	for _, i := range s.pageItems() {
+		table := s.tables[i]
+		if table.Schema != schema {
+			schema = table.Schema
+			items = append(items, tableMenuItem{Label: fmt.Sprintf("── %s ──", schema), action: tableActionSchema, schema: schema})
+		}
+		mark := "[ ]"
+		if table.Selected {
+			mark = "[✓]"
+		}
+		items = append(items, tableMenuItem{Label: fmt.Sprintf("%s %s", mark, table.Name), action: tableActionToggle, index: i})
+	}
+
+	filterLabel := "🔍 按名称筛选"
+	if s.filter != "" {
+		filterLabel = fmt.Sprintf("🔍 修改筛选（当前: %s）", s.filter)
+	}
+	items = append(items,
+		tableMenuItem{Label: filterLabel, action: tableActionFilter},
+		tableMenuItem{Label: "☑️ 全选/取消全选筛选结果", action: tableActionSelectAll},
+		tableMenuItem{Label: "🔄 反选筛选结果", action: tableActionInvert},
+	)
+	if s.page > 0 {
+		items = append(items, tableMenuItem{Label: "⬅️ 上一页", action: tableActionPrev})
+	}
+	if s.page < s.Pages()-1 {
+		items = append(items, tableMenuItem{Label: "➡️ 下一页", action: tableActionNext})
+	}
+	return append(items, tableMenuItem{Label: "DONE - 完成选择", action: tableActionDone})
+}
+
+// Apply 执行菜单项，选择完成时返回 true
+func (s *tableSelector) Apply(item tableMenuItem) bool {
+	switch item.action {
+	case tableActionToggle:
+		s.Toggle(item.index)
+	case tableActionSchema:
+		s.ToggleSchema(item.schema)
+	case tableActionSelectAll:
+		s.SelectAll()
+	case tableActionInvert:
+		s.Invert()
+	case tableActionPrev:
+		s.PrevPage()
+	case tableActionNext:
+		s.NextPage()
+	case tableActionDone:
+		return true
+	}
+	return false
+}
+
+// runTableSelector 交互式选择表，直到选择完成
+func runTableSelector(selector *tableSelector) error {
+	cursor := 0
+	for {
+		fmt.Println()
+		fmt.Println(selector.Status())
+
+		items := selector.Menu()
+		labels := make([]string, len(items))
+		for i, item := range items {
+			labels[i] = item.Label
+		}
+		if cursor >= len(items) {
+			cursor = len(items) - 1
+		}
+		prompt := promptui.Select{
+			Label:     "选择要迁移的表",
+			Items:     labels,
+			Size:      len(labels),
+			CursorPos: cursor,
+		}
+		index, _, err := prompt.Run()
+		if err != nil {
+			return utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
+				Message("用户取消了选择").Build()
+		}
+
+		item := items[index]
+		cursor = index
+		if item.action == tableActionFilter {
+			filterPrompt := promptui.Prompt{Label: "表名包含（留空显示全部）", Default: selector.filter}
+			filter, err := filterPrompt.Run()
+			if err != nil {
+				continue
+			}
+			selector.SetFilter(filter)
+			cursor = 0
+			continue
+		}
+		if item.action == tableActionPrev || item.action == tableActionNext {
+			cursor = 0
+		}
+		if selector.Apply(item) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// menuLabels 菜单项的文本
+func menuLabels(items []tableMenuItem) []string {
+	labels := make([]string, len(items))
+	for i, item := range items {
+		labels[i] = item.Label
+	}
+	return labels
+}
+
+func TestTableSelector(t *testing.T) {
+	selector := newTableSelector("APP", map[string][]string{
+		"HR":  {"EMPLOYEES"},
+		"APP": {"ORDERS", "CUSTOMERS", "ORDER_ITEMS"},
+	}, 2)
+
+	missing := selector.Preselect([]string{"orders", "HR.EMPLOYEES", "GONE"})
+	assert.Equal(t, []string{"GONE"}, missing)
+	assert.Equal(t, []string{"ORDERS", "HR.EMPLOYEES"}, selector.Selected())
+
+	// 配置的模式排在前面，分页显示并带分组标题
+	assert.Equal(t, 2, selector.Pages())
+	assert.Equal(t, []string{
+		"── APP ──", "[ ] CUSTOMERS", "[✓] ORDERS",
+		"🔍 按名称筛选", "☑️ 全选/取消全选筛选结果", "🔄 反选筛选结果", "➡️ 下一页", "DONE - 完成选择",
+	}, menuLabels(selector.Menu()))
+
+	selector.NextPage()
+	items := selector.Menu()
+	assert.Equal(t, []string{"── APP ──", "[ ] ORDER_ITEMS", "── HR ──", "[✓] EMPLOYEES"}, menuLabels(items)[:4])
+	assert.Contains(t, menuLabels(items), "⬅️ 上一页")
+	assert.NotContains(t, menuLabels(items), "➡️ 下一页")
+
+	// 选择分组标题切换该模式下的全部表
+	assert.False(t, selector.Apply(items[2]))
+	assert.Equal(t, []string{"ORDERS"}, selector.Selected())
+
+	// 筛选后回到第一页，全选和反选只作用于筛选结果
+	selector.SetFilter("order")
+	assert.Equal(t, 1, selector.Pages())
+	assert.Contains(t, selector.Status(), `筛选 "ORDER" 匹配 2 张`)
+	selector.SelectAll()
+	assert.Equal(t, []string{"ORDERS", "ORDER_ITEMS"}, selector.Selected())
+	selector.SelectAll()
+	assert.Empty(t, selector.Selected())
+	selector.Invert()
+	assert.Equal(t, []string{"ORDERS", "ORDER_ITEMS"}, selector.Selected())
+
+	selector.SetFilter("")
+	selector.Invert()
+	assert.Equal(t, []string{"CUSTOMERS", "HR.EMPLOYEES"}, selector.Selected())
+	assert.Equal(t, "已选 2/4 张表，第 1/2 页", selector.Status())
+
+	menu := selector.Menu()
+	assert.True(t, selector.Apply(menu[len(menu)-1]))
+}
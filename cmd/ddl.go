@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+// ddlSnapshotMaxFailures 导出结果中最多列出的失败对象数
+const ddlSnapshotMaxFailures = 10
+
+var (
+	ddlSnapshotSchema            string
+	ddlSnapshotTypes             []string
+	ddlSnapshotOutput            string
+	ddlSnapshotBatchSize         int
+	ddlSnapshotSegmentAttributes bool
+	ddlSnapshotTimeout           time.Duration
+)
+
+// ddlCmd DDL命令
+var ddlCmd = &cobra.Command{
+	Use:   "DDL",
+	Short: "导出源库对象的DDL",
+	Long:  `通过 DBMS_METADATA 导出源库Schema中对象的DDL，用于迁移前的结构审查和归档。`,
+}
+
+// ddlSnapshotCmd 导出Schema的DDL快照
+var ddlSnapshotCmd = &cobra.Command{
+	Use:   "快照",
+	Short: "导出Schema所有对象的DDL快照",
+	Long: `通过 DBMS_METADATA.GET_DDL 导出Schema中对象的DDL，按对象类型分文件保存，作为迁移前后的结构基线。
+
+快照目录默认为 snapshots/ddl-<SCHEMA>-<时间>：
+• 每种对象类型一个文件（如 TABLE.sql、PACKAGE.sql），对象按名称排序
+• manifest.json 记录导出时间、各类型对象数量和导出失败的对象
+• 文件中不含导出时间，两个时间点的快照可以直接用 diff -r 对比
+
+默认去掉表空间、存储参数等段属性，减少不同环境之间的无关差异；需要保留时指定 --segment-attributes。
+导出其他用户的Schema需要 SELECT_CATALOG_ROLE 角色。
+
+示例：
+  ora2pg-admin DDL 快照
+  ora2pg-admin DDL 快照 --types TABLE,VIEW,PACKAGE
+  ora2pg-admin DDL 快照 --schema HR --output baseline/hr`,
+	Run: runDDLSnapshot,
+}
+
+func init() {
+	rootCmd.AddCommand(ddlCmd)
+	ddlCmd.AddCommand(ddlSnapshotCmd)
+
+	ddlSnapshotCmd.Flags().StringVar(&ddlSnapshotSchema, "schema", "", "导出的Schema（默认使用 oracle.schema，未配置时为连接用户）")
+	ddlSnapshotCmd.Flags().StringSliceVar(&ddlSnapshotTypes, "types", nil, "只导出指定的对象类型，逗号分隔（如 TABLE,VIEW）")
+	ddlSnapshotCmd.Flags().StringVar(&ddlSnapshotOutput, "output", "", "快照目录（默认 snapshots/ddl-<SCHEMA>-<时间>）")
+	ddlSnapshotCmd.Flags().IntVar(&ddlSnapshotBatchSize, "batch-size", service.DefaultDDLBatchSize, "每个sqlplus会话导出的对象数")
+	ddlSnapshotCmd.Flags().BoolVar(&ddlSnapshotSegmentAttributes, "segment-attributes", false, "保留表空间、存储参数等段属性")
+	ddlSnapshotCmd.Flags().DurationVar(&ddlSnapshotTimeout, "timeout", time.Hour, "导出超时时间")
+}
+
+// runDDLSnapshot 导出DDL快照
+func runDDLSnapshot(cmd *cobra.Command, args []string) {
+	types, err := oracle.ParseDDLObjectTypes(ddlSnapshotTypes)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	if ddlSnapshotBatchSize <= 0 {
+		fmt.Printf("%s\n", utils.FormatError(utils.ValidationErrors.InvalidFormat("batch-size", fmt.Sprint(ddlSnapshotBatchSize))))
+		exit(1)
+	}
+
+	manager, _, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	cfg := manager.GetConfig()
+	schema := ddlSnapshotSchema
+	if schema == "" {
+		schema = cfg.Oracle.Schema
+	}
+	if schema == "" {
+		schema = cfg.Oracle.Username
+	}
+	schema = strings.ToUpper(schema)
+
+	dir := ddlSnapshotOutput
+	if dir == "" {
+		dir = filepath.Join(service.DefaultDDLSnapshotDir, service.DDLSnapshotDirName(schema, time.Now()))
+	}
+
+	fmt.Printf("📸 正在导出 %s 的DDL快照...\n", schema)
+	ctx, cancel := context.WithTimeout(context.Background(), ddlSnapshotTimeout)
+	defer cancel()
+
+	runner := oracle.NewSQLPlusRunner(&cfg.Oracle, &cfg.OracleClient)
+	exporter := oracle.NewDDLExporter(runner, schema, oracle.DDLOptions{SegmentAttributes: ddlSnapshotSegmentAttributes})
+	snapshot, err := service.CreateDDLSnapshot(ctx, exporter, dir, service.DDLSnapshotOptions{
+		Types:     types,
+		BatchSize: ddlSnapshotBatchSize,
+		OnProgress: func(exported, total int) {
+			fmt.Printf("   已处理 %d/%d 个对象\n", exported, total)
+		},
+	})
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	printDDLSnapshot(snapshot)
+}
+
+// printDDLSnapshot 输出快照结果
+func printDDLSnapshot(snapshot *service.DDLSnapshot) {
+	fmt.Println()
+	fmt.Printf("✅ 已导出 %d 个对象的DDL到 %s\n", snapshot.Total(), snapshot.Dir)
+
+	types := make([]string, 0, len(snapshot.Objects))
+	for objectType := range snapshot.Objects {
+		types = append(types, objectType)
+	}
+	sort.Strings(types)
+	for _, objectType := range types {
+		fmt.Printf("   %-18s %d\n", objectType, snapshot.Objects[objectType])
+	}
+
+	if len(snapshot.Failures) > 0 {
+		fmt.Println()
+		fmt.Printf("⚠️ %d 个对象导出失败（详见 %s）:\n", len(snapshot.Failures), filepath.Join(snapshot.Dir, service.DDLSnapshotManifestFile))
+		for i, failure := range snapshot.Failures {
+			if i == ddlSnapshotMaxFailures {
+				fmt.Printf("   ... 其余 %d 个\n", len(snapshot.Failures)-ddlSnapshotMaxFailures)
+				break
+			}
+			fmt.Printf("   • %s %s: %s\n", failure.Type, failure.Name, failure.Error)
+		}
+	}
+
+	fmt.Println()
+	fmt.Println("💡 对比两次快照: diff -r <快照目录1> <快照目录2>")
+}
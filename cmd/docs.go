@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+var (
+	docsOutput    string
+	docsShowHosts bool
+)
+
+// docsCmd 项目文档命令
+var docsCmd = &cobra.Command{
+	Use:   "文档",
+	Short: "生成迁移项目文档",
+	Long:  `根据项目配置生成供团队评审和执行的迁移文档。`,
+}
+
+// docsGenerateCmd 生成迁移操作手册
+var docsGenerateCmd = &cobra.Command{
+	Use:   "生成",
+	Short: "根据当前配置生成迁移操作手册",
+	Long: `根据当前项目配置生成Markdown格式的迁移操作手册，包含：
+• 项目信息
+• 数据库连接概况（主机名和用户名脱敏，不包含密码）
+• 迁移范围：配置的迁移类型、表筛选、大表分片、分批和增量规则、业务模块
+• 执行步骤：按是否使用模块、分批、增量同步等生成对应的命令
+• 回滚方案和迁移前、中、后的检查清单
+
+手册默认保存到 docs/migration-runbook.md，配置变化后重新生成即可。
+
+示例：
+  ora2pg-admin 文档 生成
+  ora2pg-admin 文档 生成 --output 上线手册.md
+  ora2pg-admin 文档 生成 --show-hosts`,
+	Run: runDocsGenerate,
+}
+
+func init() {
+	rootCmd.AddCommand(docsCmd)
+	docsCmd.AddCommand(docsGenerateCmd)
+
+	docsGenerateCmd.Flags().StringVarP(&docsOutput, "output", "o", service.DefaultRunbookPath, "手册保存路径")
+	docsGenerateCmd.Flags().BoolVar(&docsShowHosts, "show-hosts", false, "显示完整的主机名和用户名（密码始终不显示）")
+}
+
+// runDocsGenerate 生成迁移操作手册
+func runDocsGenerate(cmd *cobra.Command, args []string) {
+	fmt.Println("📘 生成迁移操作手册")
+	fmt.Println()
+
+	manager, configPath, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	content := service.GenerateRunbook(manager.GetConfig(), service.RunbookOptions{
+		ConfigFile:  configPath,
+		GeneratedAt: time.Now(),
+		ShowHosts:   docsShowHosts,
+	})
+
+	if err := utils.NewFileUtils().WriteFile(docsOutput, []byte(content), 0644); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(utils.FileErrors.CreateFailed(docsOutput, err)))
+		exit(1)
+	}
+
+	fmt.Printf("✅ 操作手册已保存: %s\n", docsOutput)
+	if docsShowHosts {
+		fmt.Println("⚠️ 手册中包含完整的主机名和用户名，分享前请确认范围")
+	}
+	fmt.Println("💡 配置变化后重新执行 'ora2pg-admin 文档 生成' 更新手册")
+}
@@ -18,12 +18,46 @@ var helpCmd = &cobra.Command{
 		fmt.Println("  初始化 [项目名]     创建新的迁移项目")
 		fmt.Println("  配置 数据库         配置Oracle和PostgreSQL连接")
 		fmt.Println("  配置 选项           配置迁移选项和参数")
+		fmt.Println("  配置 表             选择要迁移的表")
+		fmt.Println("  配置 片段           查看和组合ora2pg配置片段")
+		fmt.Println("  配置 环境变量       查看覆盖配置项的环境变量")
+		fmt.Println("  配置 备份 列表      查看配置的历史备份")
+		fmt.Println("  配置 恢复 <备份>    将配置回滚到历史备份")
 		fmt.Println("  检查 环境           检查Oracle客户端等环境")
 		fmt.Println("  检查 连接           测试数据库连接")
+		fmt.Println("  检查 连接 --history 查看连接响应时间趋势")
+		fmt.Println("  检查 连接 --network 测量到数据库的网络延迟（--bandwidth 估算带宽）")
+		fmt.Println("  检查 就绪度         评估源库的迁移就绪度")
+		fmt.Println("  DDL 快照            导出源库Schema的DDL快照")
 		fmt.Println("  迁移 结构           迁移数据库结构")
 		fmt.Println("  迁移 数据           迁移数据内容")
+		fmt.Println("  迁移 数据 --incremental  按水位增量同步新数据")
 		fmt.Println("  迁移 全部           完整迁移流程")
+		fmt.Println("  迁移 全部 --targets a,b  配置了多个目标库时只迁移到指定目标")
+		fmt.Println("  迁移 计划           预览迁移执行顺序")
+		fmt.Println("  迁移 队列 <文件>    按顺序/按时执行多个迁移任务")
+		fmt.Println("  迁移 重试           只重新执行上次失败的迁移类型")
+		fmt.Println("  迁移 模块 [模块名]  按业务模块迁移，不指定模块时查看各模块进度")
+		fmt.Println("  迁移 分批           按计划分批迁移数据，每批完成后暂停验证")
+		fmt.Println("  迁移 基准           空跑测量迁移性能并外推生产库耗时")
+		fmt.Println("  迁移 预检           迁移前执行检查清单")
+		fmt.Println("  迁移 预览           浏览生成的SQL，按类型过滤和高亮")
+		fmt.Println("  迁移 差异           对比生成的SQL与目标库现状，预览应用后的变化")
+		fmt.Println("  迁移 状态 [运行ID]  查看 --detach 后台运行的迁移（日志/停止 <运行ID>）")
+		fmt.Println("  迁移 监控           从进度文件查看正在执行的迁移进度（-f 持续刷新）")
+		fmt.Println("  校验               抽样比对源库和目标库数据")
+		fmt.Println("  校验 约束           对比源库和目标库的约束定义")
+		fmt.Println("  校验 断言           在目标库检查配置的迁移后断言")
+		fmt.Println("  校验 语法           导入前试执行生成的SQL，报告语法问题")
 		fmt.Println("  状态               查看当前项目状态")
+		fmt.Println("  历史               查看迁移历史记录")
+		fmt.Println("  历史 指标           导出 Prometheus 格式的迁移指标")
+		fmt.Println("  历史 性能           分析迁移耗时分布和最慢的表")
+		fmt.Println("  进度               生成项目整体迁移完成度快照")
+		fmt.Println("  文档 生成           根据配置生成迁移操作手册")
+		fmt.Println("  日志 跟踪 <类型>    实时跟踪某迁移类型的ora2pg日志")
+		fmt.Println("  通知 预览           预览/测试迁移结果通知")
+		fmt.Println("  项目 导出/导入      在团队间共享迁移项目")
 		fmt.Println("  版本               显示版本信息")
 		fmt.Println("  帮助               显示此帮助信息")
 		fmt.Println()
@@ -31,8 +65,10 @@ var helpCmd = &cobra.Command{
 		fmt.Println("  --config, -c       指定配置文件路径")
 		fmt.Println("  --verbose, -v      显示详细输出")
 		fmt.Println("  --quiet, -q        静默模式")
-		fmt.Println("  --dry-run          预览模式，不执行实际操作")
+		fmt.Println("  --dry-run          预演模式，迁移只生成SQL并输出差异报告")
 		fmt.Println("  --log-file         指定日志文件路径")
+		fmt.Println("  --yes, -y          自动确认所有确认提示（别名 --assume-yes）")
+		fmt.Println("  --no-sanitize-logs 关闭日志脱敏（仅限安全环境调试）")
 		fmt.Println()
 		fmt.Println("💡 典型使用流程:")
 		fmt.Println("  1. ora2pg-admin 初始化 我的迁移项目")
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+var (
+	historyTags          []string
+	historyLimit         int
+	historyMetricsOutput string
+)
+
+// historyCmd 查看迁移历史
+var historyCmd = &cobra.Command{
+	Use:   "历史",
+	Short: "查看迁移历史记录",
+	Long: `查看当前项目的迁移运行历史，包括每次运行的状态、耗时、标签和备注。
+
+示例：
+  ora2pg-admin 历史
+  ora2pg-admin 历史 --tag ticket=JIRA-123
+  ora2pg-admin 历史 --tag owner=zhang --limit 5`,
+	Run: runHistory,
+}
+
+// historyMetricsCmd 导出迁移指标
+var historyMetricsCmd = &cobra.Command{
+	Use:   "指标",
+	Short: "导出 Prometheus 格式的迁移指标",
+	Long: `根据迁移历史生成 Prometheus 文本格式的指标，包括迁移成功/失败次数、各类型耗时、迁移行数和最后一次迁移时间。
+
+默认输出到标准输出；指定 --output 时写入文件（供 node_exporter 的 textfile collector 读取，文件需以 .prom 结尾）。
+在配置中设置 metrics.textfile 后，每次迁移结束会自动更新该文件。
+
+示例：
+  ora2pg-admin 历史 指标
+  ora2pg-admin 历史 指标 --output /var/lib/node_exporter/textfile/ora2pg.prom`,
+	Run: runHistoryMetrics,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().StringArrayVar(&historyTags, "tag", nil, "按标签过滤，格式 key=value，可重复指定（需同时匹配）")
+	historyCmd.Flags().IntVar(&historyLimit, "limit", 20, "最多显示的记录数（0表示全部）")
+
+	historyCmd.AddCommand(historyMetricsCmd)
+	historyMetricsCmd.Flags().StringVarP(&historyMetricsOutput, "output", "o", "", "写入的指标文件（默认输出到标准输出）")
+}
+
+// runHistory 显示迁移历史
+func runHistory(cmd *cobra.Command, args []string) {
+	fmt.Println("📜 迁移历史")
+	fmt.Println("─────────────")
+
+	tags, err := service.ParseMetadataTags(historyTags)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	records, err := service.LoadHistory(service.DefaultHistoryPath, tags)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	if len(records) == 0 {
+		fmt.Println("暂无匹配的迁移记录")
+		return
+	}
+
+	// 只显示最近的记录，最新的在前
+	total := len(records)
+	if historyLimit > 0 && total > historyLimit {
+		records = records[total-historyLimit:]
+	}
+	for i := len(records) - 1; i >= 0; i-- {
+		printHistoryRecord(records[i])
+	}
+
+	fmt.Printf("共 %d 条记录", total)
+	if len(records) < total {
+		fmt.Printf("，显示最近 %d 条（可通过 --limit 调整）", len(records))
+	}
+	fmt.Println()
+}
+
+// printHistoryRecord 显示单条迁移历史
+func printHistoryRecord(record *service.HistoryRecord) {
+	icon := "✅"
+	switch record.Status {
+	case service.StatusFailed:
+		icon = "❌"
+	case service.StatusCancelled:
+		icon = "⚠️"
+	}
+
+	fmt.Printf("%s %s  %s (耗时: %v)\n", icon, record.StartTime.Format("2006-01-02 15:04:05"),
+		record.Task, record.Duration.Truncate(time.Second))
+	if record.RunID != "" {
+		fmt.Printf("   运行ID: %s\n", record.RunID)
+	}
+	if tags := record.Tags(); len(tags) > 0 {
+		fmt.Printf("   标签: %s\n", strings.Join(tags, ", "))
+	}
+	if note := record.Note(); note != "" {
+		fmt.Printf("   备注: %s\n", note)
+	}
+
+	completed := 0
+	var failed, warned, skipped []string
+	for _, result := range record.Results {
+		switch result.Status {
+		case service.StatusSkipped:
+			completed++
+			skipped = append(skipped, string(result.Type))
+		case service.StatusCompleted:
+			completed++
+			if result.ErrorCount > 0 || result.WarningCount > 0 {
+				warned = append(warned, string(result.Type))
+			}
+		case service.StatusFailed:
+			failed = append(failed, string(result.Type))
+		}
+	}
+	fmt.Printf("   结果: %d/%d 成功", completed, len(record.Results))
+	if len(warned) > 0 {
+		fmt.Printf("，有警告: %s", strings.Join(warned, ", "))
+	}
+	if len(skipped) > 0 {
+		fmt.Printf("，跳过（无对象）: %s", strings.Join(skipped, ", "))
+	}
+	if len(failed) > 0 {
+		fmt.Printf("，失败: %s", strings.Join(failed, ", "))
+	}
+	fmt.Println()
+	for _, result := range record.Results {
+		switch len(result.LogFiles) {
+		case 0:
+		case 1:
+			fmt.Printf("   %s 日志: %s\n", result.Type, result.LogFiles[0])
+		default:
+			fmt.Printf("   %s 日志: %d 个分段 %s ... %s\n", result.Type, len(result.LogFiles),
+				result.LogFiles[0], result.LogFiles[len(result.LogFiles)-1])
+		}
+	}
+	fmt.Println()
+}
+
+// runHistoryMetrics 输出迁移指标
+func runHistoryMetrics(cmd *cobra.Command, args []string) {
+	manager, _, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	records, err := service.LoadHistory(service.DefaultHistoryPath, nil)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	metrics := service.RenderMetrics(manager.GetConfig().Project.Name, records)
+
+	if historyMetricsOutput == "" {
+		fmt.Print(metrics)
+		return
+	}
+	if err := service.WriteMetricsFile(historyMetricsOutput, metrics); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	fmt.Printf("✅ 迁移指标已写入: %s\n", historyMetricsOutput)
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+// migrationProfileTopTables 迁移结束时显示的最慢表数量
+const migrationProfileTopTables = 5
+
+var (
+	historyProfileTop    int
+	historyProfileOutput string
+)
+
+// historyProfileCmd 迁移耗时的性能分析
+var historyProfileCmd = &cobra.Command{
+	Use:   "性能 [运行ID]",
+	Short: "分析迁移耗时分布，找出最慢的迁移类型和表",
+	Long: `根据迁移历史分析一次迁移的耗时分布，默认分析最近一次迁移：
+  • 各迁移类型的耗时和占比
+  • 耗时最长的表（从ora2pg输出的表级进度和速度计算）
+  • 数据迁移的平均每行耗时
+  • 根据耗时分布给出的优化建议，如"INDEX 阶段占 60%，考虑迁移数据后再建索引"
+
+迁移结束时会自动显示简要的分析结果。迁移历史中每个类型最多保存耗时最长的 20 张表。
+
+示例：
+  ora2pg-admin 历史 性能
+  ora2pg-admin 历史 性能 --top 20
+  ora2pg-admin 历史 性能 20261014-093000-1a2b3c -o json`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runHistoryProfile,
+}
+
+func init() {
+	historyCmd.AddCommand(historyProfileCmd)
+
+	historyProfileCmd.Flags().IntVar(&historyProfileTop, "top", service.DefaultProfileTopTables, "显示耗时最长的表数量")
+	historyProfileCmd.Flags().StringVarP(&historyProfileOutput, "output", "o", checkOutputText, "输出格式 (text, json)")
+}
+
+// runHistoryProfile 显示指定或最近一次迁移的耗时分析
+func runHistoryProfile(cmd *cobra.Command, args []string) {
+	jsonOutput := strings.EqualFold(historyProfileOutput, checkOutputJSON)
+	if !jsonOutput && !strings.EqualFold(historyProfileOutput, checkOutputText) {
+		fmt.Printf("%s\n", utils.FormatError(utils.ConfigErrors.InvalidValue("output", historyProfileOutput)))
+		exit(1)
+	}
+	if historyProfileTop < 1 {
+		fmt.Printf("%s\n", utils.FormatError(utils.ConfigErrors.InvalidValue("top", fmt.Sprint(historyProfileTop))))
+		exit(1)
+	}
+
+	records, err := service.LoadHistory(service.DefaultHistoryPath, nil)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	record, err := findHistoryRecord(records, args)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	profile := service.BuildMigrationProfile(record.Results, historyProfileTop)
+	if jsonOutput {
+		data, _ := json.MarshalIndent(profile, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Println("⏱️ 迁移耗时分析")
+	fmt.Println("─────────────")
+	fmt.Printf("%s  %s（运行ID %s）\n", record.StartTime.Format("2006-01-02 15:04:05"), record.Task, record.RunID)
+	printMigrationProfile(profile)
+}
+
+// findHistoryRecord 按运行ID查找迁移历史，未指定时为最近一次迁移
+func findHistoryRecord(records []*service.HistoryRecord, args []string) (*service.HistoryRecord, error) {
+	if len(records) == 0 {
+		return nil, utils.NewError(utils.ErrorTypeUser, "HISTORY_EMPTY").
+			Message("没有迁移历史").
+			Details(service.DefaultHistoryPath).
+			Suggestion("先执行 'ora2pg-admin 迁移 全部' 等迁移命令").
+			Build()
+	}
+	if len(args) == 0 {
+		return records[len(records)-1], nil
+	}
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].RunID == args[0] {
+			return records[i], nil
+		}
+	}
+	return nil, utils.NewError(utils.ErrorTypeUser, "HISTORY_RUN_NOT_FOUND").
+		Message("迁移历史中没有该运行ID").
+		Details(args[0]).
+		Suggestion("运行 'ora2pg-admin 历史' 查看各次迁移的运行ID").
+		Build()
+}
+
+// showMigrationProfile 迁移结束时显示耗时分布和最慢的表，只执行了一个类型且没有表级耗时时不显示
+func showMigrationProfile(record *service.HistoryRecord) {
+	if record == nil {
+		return
+	}
+	profile := service.BuildMigrationProfile(record.Results, migrationProfileTopTables)
+	if profile.Duration <= 0 || (len(profile.Types) < 2 && profile.TableCount == 0) {
+		return
+	}
+	fmt.Println()
+	fmt.Println("⏱️ 耗时分析:")
+	printMigrationProfile(profile)
+	fmt.Println("💡 运行 'ora2pg-admin 历史 性能' 可查看更多表的耗时")
+}
+
+// printMigrationProfile 显示耗时分析报告
+func printMigrationProfile(profile *service.MigrationProfile) {
+	const barWidth = 20
+	fmt.Printf("   总耗时 %v", profile.Duration.Round(time.Second))
+	if profile.TotalRows > 0 {
+		fmt.Printf("，共 %d 行", profile.TotalRows)
+		if profile.TimePerRow > 0 {
+			fmt.Printf("，平均每行 %s", service.FormatTimePerRow(profile.TimePerRow))
+		}
+	}
+	fmt.Println()
+
+	fmt.Println("   各迁移类型:")
+	for _, item := range profile.Types {
+		filled := int(item.Share*barWidth + 0.5)
+		fmt.Printf("     %-10s %s%s %5s  %v", item.Type, strings.Repeat("█", filled), strings.Repeat("░", barWidth-filled),
+			service.FormatShare(item.Share), item.Duration.Round(time.Second))
+		if item.Rows > 0 {
+			fmt.Printf("  %d 行", item.Rows)
+		}
+		fmt.Println()
+	}
+
+	if len(profile.SlowestTables) > 0 {
+		fmt.Printf("   最慢的 %d 张表（共 %d 张）:\n", len(profile.SlowestTables), profile.TableCount)
+		for i, table := range profile.SlowestTables {
+			fmt.Printf("     %2d. %-30s %-6s %10v %5s  %d 行", i+1, table.Table, table.Type, table.Duration.Round(100*time.Millisecond),
+				service.FormatShare(table.Share), table.Rows)
+			if table.RowsPerSecond > 0 {
+				fmt.Printf("  %.0f 行/秒", table.RowsPerSecond)
+			}
+			fmt.Println()
+		}
+	}
+
+	if len(profile.Suggestions) > 0 {
+		fmt.Println("   优化建议:")
+		for _, suggestion := range profile.Suggestions {
+			fmt.Printf("     • %s\n", suggestion)
+		}
+	}
+}
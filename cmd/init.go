@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -16,7 +15,8 @@ import (
 var (
 	initForce       bool
 	initTemplate    string
-	initDescription string
+	initDescription  string
+	initConfigFormat string
 )
 
 // initCmd 初始化命令
@@ -30,7 +30,8 @@ var initCmd = &cobra.Command{
 
 示例:
   ora2pg-admin 初始化 我的迁移项目
-  ora2pg-admin 初始化 --template=basic --description="生产环境迁移" 生产迁移`,
+  ora2pg-admin 初始化 --template=basic --description="生产环境迁移" 生产迁移
+  ora2pg-admin 初始化 --config-format json 我的迁移项目`,
 	Args: cobra.MaximumNArgs(1),
 	Run:  runInit,
 }
@@ -40,8 +41,9 @@ func init() {
 
 	// 添加命令参数
 	initCmd.Flags().BoolVarP(&initForce, "force", "f", false, "强制覆盖已存在的项目")
-	initCmd.Flags().StringVarP(&initTemplate, "template", "t", "", "项目模板 (basic, advanced, custom)")
+	initCmd.Flags().StringVarP(&initTemplate, "template", "t", "", "项目模板 (basic, advanced, custom 或自定义模板名称)")
 	initCmd.Flags().StringVarP(&initDescription, "description", "d", "", "项目描述")
+	initCmd.Flags().StringVar(&initConfigFormat, "config-format", string(config.FormatYAML), "配置文件格式 (yaml, json)")
 }
 
 // runInit 执行初始化命令
@@ -56,39 +58,47 @@ func runInit(cmd *cobra.Command, args []string) {
 	projectName, err := getProjectName(args)
 	if err != nil {
 		fmt.Printf("%s\n", utils.FormatError(err))
-		os.Exit(1)
+		exit(1)
+	}
+
+	configFormat, err := config.ParseFormat(initConfigFormat)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
 	}
 
 	// 2. 检查项目是否已存在
 	if err := checkProjectExists(projectName, fileUtils); err != nil {
 		fmt.Printf("%s\n", utils.FormatError(err))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 3. 收集项目信息
 	projectInfo, err := collectProjectInfo(projectName)
 	if err != nil {
 		fmt.Printf("%s\n", utils.FormatError(err))
-		os.Exit(1)
+		exit(1)
 	}
+	projectInfo.ConfigFormat = configFormat
 
 	// 4. 创建项目目录结构
 	fmt.Println("📁 创建项目目录结构...")
 	if err := createProjectStructure(projectName, fileUtils); err != nil {
 		fmt.Printf("%s\n", utils.FormatError(err))
-		os.Exit(1)
+		exit(1)
 	}
+	setAuditProjectDir(getProjectDir(projectName))
 
 	// 5. 生成配置文件
 	fmt.Println("⚙️ 生成项目配置文件...")
 	if err := generateProjectConfig(projectName, projectInfo, fileUtils); err != nil {
 		fmt.Printf("%s\n", utils.FormatError(err))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 6. 创建示例文件
 	fmt.Println("📄 创建示例文件...")
-	if err := createExampleFiles(projectName, fileUtils); err != nil {
+	if err := createExampleFiles(projectName, projectInfo, fileUtils); err != nil {
 		logger.Warnf("创建示例文件时出现警告: %v", err)
 	}
 
@@ -152,13 +162,8 @@ func checkProjectExists(projectName string, fileUtils *utils.FileUtils) error {
 		}
 		
 		// 如果使用了 --force 参数，询问确认
-		prompt := promptui.Prompt{
-			Label:     fmt.Sprintf("项目目录 %s 已存在，是否覆盖", projectDir),
-			IsConfirm: true,
-		}
-		
-		_, err := prompt.Run()
-		if err != nil {
+		if !utils.ConfirmDangerous(fmt.Sprintf("项目目录 %s 已存在，是否覆盖", projectDir),
+			fmt.Sprintf("删除已存在的项目目录 %s", projectDir)) {
 			return utils.NewError(utils.ErrorTypeUser, "OPERATION_CANCELLED").
 				Message("用户取消了覆盖操作").
 				Build()
@@ -180,6 +185,8 @@ type ProjectInfo struct {
 	Template    string
 	Author      string
 	Email       string
+	// ConfigFormat 项目配置文件的格式
+	ConfigFormat config.Format
 }
 
 // collectProjectInfo 收集项目信息
@@ -203,17 +210,31 @@ func collectProjectInfo(projectName string) (*ProjectInfo, error) {
 	}
 
 	// 获取项目模板
+	userTemplates, err := config.ListUserTemplates()
+	if err != nil {
+		utils.GetGlobalLogger().Warnf("读取用户模板失败: %v", err)
+	}
+
 	if initTemplate != "" {
+		if err := checkTemplateAvailable(initTemplate, userTemplates); err != nil {
+			return nil, err
+		}
 		info.Template = initTemplate
 	} else {
-		templates := []string{"basic", "advanced", "custom"}
+		templates := append([]string{}, config.BuiltinTemplates...)
+		items := []string{
+			"basic - 基础模板（推荐新手使用）",
+			"advanced - 高级模板（包含更多配置选项）",
+			"custom - 自定义模板（手动配置所有选项）",
+		}
+		for _, name := range userTemplates {
+			templates = append(templates, name)
+			items = append(items, fmt.Sprintf("%s - 团队模板", name))
+		}
+
 		prompt := promptui.Select{
 			Label: "选择项目模板",
-			Items: []string{
-				"basic - 基础模板（推荐新手使用）",
-				"advanced - 高级模板（包含更多配置选项）",
-				"custom - 自定义模板（手动配置所有选项）",
-			},
+			Items: items,
 		}
 		
 		index, _, err := prompt.Run()
@@ -247,6 +268,26 @@ func collectProjectInfo(projectName string) (*ProjectInfo, error) {
 	return info, nil
 }
 
+// checkTemplateAvailable 检查模板是否为内置模板或已保存的用户模板
+func checkTemplateAvailable(name string, userTemplates []string) error {
+	if config.IsBuiltinTemplate(name) {
+		return nil
+	}
+	for _, t := range userTemplates {
+		if t == name {
+			return nil
+		}
+	}
+
+	available := append([]string{}, config.BuiltinTemplates...)
+	available = append(available, userTemplates...)
+	return utils.NewError(utils.ErrorTypeUser, "TEMPLATE_NOT_FOUND").
+		Message(fmt.Sprintf("项目模板不存在: %s", name)).
+		Details(fmt.Sprintf("可用模板: %s", strings.Join(available, ", "))).
+		Suggestion("使用 'ora2pg-admin 配置 另存为模板 <名称>' 保存自定义模板").
+		Build()
+}
+
 // getProjectDir 获取项目目录路径
 func getProjectDir(projectName string) string {
 	// 将项目名称转换为合法的目录名
@@ -266,6 +307,8 @@ func createProjectStructure(projectName string, fileUtils *utils.FileUtils) erro
 		filepath.Join(projectDir, "logs"),
 		filepath.Join(projectDir, "output"),
 		filepath.Join(projectDir, "scripts"),
+		filepath.Join(projectDir, config.DefaultPreScriptsDir),
+		filepath.Join(projectDir, config.DefaultPostScriptsDir),
 		filepath.Join(projectDir, "backup"),
 		filepath.Join(projectDir, "docs"),
 	}
@@ -284,7 +327,7 @@ func createProjectStructure(projectName string, fileUtils *utils.FileUtils) erro
 // generateProjectConfig 生成项目配置文件
 func generateProjectConfig(projectName string, projectInfo *ProjectInfo, fileUtils *utils.FileUtils) error {
 	projectDir := getProjectDir(projectName)
-	configPath := filepath.Join(projectDir, ".ora2pg-admin", "config.yaml")
+	configPath := filepath.Join(projectDir, config.ProjectConfigDir, projectInfo.ConfigFormat.FileName())
 
 	// 创建配置管理器
 	manager := config.NewManager()
@@ -309,6 +352,16 @@ func generateProjectConfig(projectName string, projectInfo *ProjectInfo, fileUti
 		cfg.Migration.ParallelJobs = 4
 	case "custom":
 		// 自定义模板：保持默认配置，用户后续自行配置
+	default:
+		// 用户模板：套用团队共享的迁移设置，连接信息保持默认
+		tmpl, err := config.LoadUserTemplate(projectInfo.Template)
+		if err != nil {
+			return utils.NewError(utils.ErrorTypeUser, "TEMPLATE_NOT_FOUND").
+				Message(fmt.Sprintf("加载项目模板失败: %s", projectInfo.Template)).
+				Cause(err).
+				Build()
+		}
+		config.ApplyUserTemplate(cfg, tmpl)
 	}
 
 	// 保存配置文件
@@ -321,12 +374,12 @@ func generateProjectConfig(projectName string, projectInfo *ProjectInfo, fileUti
 }
 
 // createExampleFiles 创建示例文件
-func createExampleFiles(projectName string, fileUtils *utils.FileUtils) error {
+func createExampleFiles(projectName string, projectInfo *ProjectInfo, fileUtils *utils.FileUtils) error {
 	projectDir := getProjectDir(projectName)
 
 	// 创建README文件
 	readmePath := filepath.Join(projectDir, "README.md")
-	readmeContent := generateReadmeContent(projectName)
+	readmeContent := generateReadmeContent(projectName, projectInfo.ConfigFormat.FileName())
 	if err := fileUtils.WriteFile(readmePath, []byte(readmeContent), 0644); err != nil {
 		return err
 	}
@@ -352,7 +405,7 @@ func createExampleFiles(projectName string, fileUtils *utils.FileUtils) error {
 }
 
 // generateReadmeContent 生成README内容
-func generateReadmeContent(projectName string) string {
+func generateReadmeContent(projectName, configFile string) string {
 	return fmt.Sprintf(`# %s
 
 这是一个使用 ora2pg-admin 创建的Oracle到PostgreSQL数据库迁移项目。
@@ -363,6 +416,8 @@ func generateReadmeContent(projectName string) string {
 - logs/ - 日志文件目录
 - output/ - 迁移输出文件目录
 - scripts/ - 自定义SQL脚本目录
+  - scripts/pre/ - 迁移前自动执行的脚本（按文件名排序，默认在目标库执行，*.oracle.sql 在源库执行）
+  - scripts/post/ - 迁移后自动执行的脚本
 - backup/ - 备份文件目录
 - docs/ - 项目文档目录
 
@@ -388,9 +443,16 @@ func generateReadmeContent(projectName string) string {
    ora2pg-admin 迁移 全部
    `+"`"+`
 
+## 操作手册
+
+根据当前配置生成迁移操作手册（docs/migration-runbook.md），配置变化后重新生成：
+`+"`"+`bash
+ora2pg-admin 文档 生成
+`+"`"+`
+
 ## 配置文件
 
-主要配置文件位于 .ora2pg-admin/config.yaml，包含：
+主要配置文件位于 .ora2pg-admin/%s，包含：
 - Oracle数据库连接配置
 - PostgreSQL数据库连接配置
 - 迁移选项和参数设置
@@ -410,7 +472,7 @@ ora2pg-admin 帮助
 
 ---
 *此项目由 ora2pg-admin 自动生成*
-`, projectName)
+`, projectName, configFile)
 }
 
 // generateGitignoreContent 生成.gitignore内容
@@ -494,7 +556,7 @@ func showSuccessMessage(projectName string, projectInfo *ProjectInfo) {
 	fmt.Println()
 	fmt.Println("💡 提示:")
 	fmt.Println("  • 使用 'ora2pg-admin 帮助' 查看所有可用命令")
-	fmt.Println("  • 配置文件位于 .ora2pg-admin/config.yaml")
+	fmt.Printf("  • 配置文件位于 .ora2pg-admin/%s\n", projectInfo.ConfigFormat.FileName())
 	fmt.Println("  • 查看 README.md 了解更多信息")
 
 	fmt.Println()
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+// 跟踪日志时出错行的颜色
+const (
+	logColorReset = "\033[0m"
+	logColorError = "\033[1;31m"
+)
+
+var (
+	logsFollowLines   int
+	logsFollowGrep    string
+	logsFollowNoColor bool
+)
+
+// logsCmd 日志命令
+var logsCmd = &cobra.Command{
+	Use:   "日志",
+	Short: "查看ora2pg日志",
+	Long:  `查看迁移过程中ora2pg按迁移类型写入 logs 目录的日志。`,
+}
+
+// logsFollowCmd 实时跟踪某迁移类型的日志
+var logsFollowCmd = &cobra.Command{
+	Use:   "跟踪 <类型>",
+	Short: "实时跟踪某迁移类型正在写入的ora2pg日志",
+	Long: `类似 tail -f，持续显示某迁移类型最新的ora2pg日志，适合在另一个终端监控后台运行的迁移。
+
+- 日志文件还未创建时等待其出现
+- 配置了 migration.log_split 时，切换到新的分段后自动跟随；开始新一次运行时同样跟随新的日志
+- 日志被截断或改名轮转后从新文件开头继续读取
+- 包含 ERROR 或 FATAL 的行高亮显示（输出不是终端、指定 --no-color 或设置 NO_COLOR 时不使用颜色）
+
+按 Ctrl+C 退出。
+
+示例：
+  ora2pg-admin 日志 跟踪 COPY
+  ora2pg-admin 日志 跟踪 TABLE --lines 50
+  ora2pg-admin 日志 跟踪 COPY --grep ORDERS`,
+	Args: cobra.ExactArgs(1),
+	Run:  runLogsFollow,
+}
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+	logsCmd.AddCommand(logsFollowCmd)
+
+	logsFollowCmd.Flags().IntVarP(&logsFollowLines, "lines", "n", 10, "开始时显示已有日志的最后几行")
+	logsFollowCmd.Flags().StringVar(&logsFollowGrep, "grep", "", "只显示包含该关键字的行（不区分大小写）")
+	logsFollowCmd.Flags().BoolVar(&logsFollowNoColor, "no-color", false, "不使用终端颜色")
+}
+
+// runLogsFollow 跟踪迁移类型的ora2pg日志直到用户中断
+func runLogsFollow(cmd *cobra.Command, args []string) {
+	migrationType := service.MigrationType(strings.ToUpper(strings.TrimSpace(args[0])))
+	if err := service.NewOra2pgService().ValidateMigrationType(migrationType); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	if logsFollowLines < 0 {
+		fmt.Printf("%s\n", utils.FormatError(utils.ValidationErrors.InvalidFormat("lines", fmt.Sprint(logsFollowLines))))
+		exit(1)
+	}
+	if !checkProjectDirectory() {
+		fmt.Printf("%s\n", utils.FormatError(utils.NewError(utils.ErrorTypeConfig, "PROJECT_NOT_INITIALIZED").
+			Message("项目未初始化").
+			Suggestion("请在项目根目录下运行，或先使用 'ora2pg-admin 初始化 [项目名称]' 创建项目").
+			Build()))
+		exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	printer := &logLinePrinter{out: os.Stdout, color: logsColorEnabled()}
+	follower := service.NewLogFollower(service.DefaultLogDir, migrationType, service.LogFollowOptions{
+		Lines:  logsFollowLines,
+		Filter: logsFollowGrep,
+		OnWaiting: func() {
+			fmt.Printf("⏳ 等待 %s 的日志出现（%s/ora2pg-%s-*.log），按 Ctrl+C 退出...\n",
+				migrationType, service.DefaultLogDir, migrationType)
+		},
+		OnFile:      func(path string) { fmt.Printf("📄 跟踪日志: %s\n", path) },
+		LineHandler: printer.Print,
+	})
+	if err := follower.Follow(ctx); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	fmt.Printf("\n👋 已停止跟踪，共显示 %d 行", printer.lines)
+	if printer.errors > 0 {
+		fmt.Printf("，其中 %d 行包含错误", printer.errors)
+	}
+	fmt.Println()
+}
+
+// logsColorEnabled 是否使用终端颜色：标准输出是终端，且未指定 --no-color 或 NO_COLOR 环境变量
+func logsColorEnabled() bool {
+	if logsFollowNoColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// logLinePrinter 显示跟踪到的日志行，高亮出错行并计数
+type logLinePrinter struct {
+	out    io.Writer
+	color  bool
+	lines  int
+	errors int
+}
+
+// Print 显示一行日志
+func (p *logLinePrinter) Print(line string) {
+	p.lines++
+	if !isErrorLogLine(line) {
+		fmt.Fprintln(p.out, line)
+		return
+	}
+	p.errors++
+	if p.color {
+		fmt.Fprintf(p.out, "%s%s%s\n", logColorError, line, logColorReset)
+	} else {
+		fmt.Fprintln(p.out, line)
+	}
+}
+
+// isErrorLogLine 日志行是否包含 ERROR 或 FATAL
+func isErrorLogLine(line string) bool {
+	upper := strings.ToUpper(line)
+	return strings.Contains(upper, "ERROR") || strings.Contains(upper, "FATAL")
+}
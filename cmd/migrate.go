@@ -5,24 +5,82 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
-	"path/filepath"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
 	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/postgres"
 	"ora2pg-admin/internal/service"
 	"ora2pg-admin/internal/utils"
 )
 
 var (
-	migrateTimeout   time.Duration
-	migrateParallel  int
-	migrateResume    bool
-	migrateValidate  bool
-	migrateBackup    bool
+	migrateTimeout            time.Duration
+	migrateParallel           int
+	migrateResume             bool
+	migrateValidate           bool
+	migrateBackup             bool
+	migrateSyslog             string
+	migrateMonitor            bool
+	migrateGatherStats        bool
+	migrateGatherStatsTimeout time.Duration
+	migrateArchive            bool
+	migrateArchiveClean       bool
+	migrateSkipEmptyTypes     bool
+	migrateSkipVersionCheck   bool
+	migrateCheckSQL           bool
+	migrateTags               []string
+	migrateNote               string
+	migrateOrder              string
+	migrateAnalyze            bool
+	migrateAnalyzeScope       string
+	migrateAnalyzeTimeout     time.Duration
+	migrateCheckConf          bool
+	migrateForce              bool
+	migrateSchedule           string
+	migrateIncremental        bool
+	migrateIdempotent         bool
+	migratePartialExitCode    int
 )
 
+const (
+	// exitCodeMigrationFailed 全部迁移类型失败时的退出码
+	exitCodeMigrationFailed = 1
+	// defaultPartialFailureExitCode 部分迁移类型失败时的默认退出码
+	defaultPartialFailureExitCode = 2
+)
+
+// allMigrationTypes 完整迁移执行的迁移类型（按执行顺序）
+var allMigrationTypes = []service.MigrationType{
+	// 第一阶段：基础结构
+	service.MigrationTypeTable,
+	service.MigrationTypeView,
+	service.MigrationTypeSequence,
+	// 第二阶段：数据内容
+	service.MigrationTypeCopy,
+	// 物化视图在数据导入后创建，创建时即填充数据
+	service.MigrationTypeMView,
+	// 第三阶段：索引和约束
+	service.MigrationTypeIndex,
+	// 第四阶段：程序对象
+	service.MigrationTypeTrigger,
+	service.MigrationTypeFunction,
+	service.MigrationTypeProcedure,
+	// 第五阶段：权限
+	service.MigrationTypeGrant,
+}
+
+// taskMigrationPhases 结构/数据子命令执行的迁移阶段，只执行配置的类型中属于这些阶段的部分
+var taskMigrationPhases = map[string][]service.MigrationPhase{
+	service.TaskTypeStructure: service.StructurePhases,
+	service.TaskTypeData:      service.DataPhases,
+}
+
 // migrateCmd 迁移命令
 var migrateCmd = &cobra.Command{
 	Use:   "迁移",
@@ -70,7 +128,10 @@ var migrateDataCmd = &cobra.Command{
 • 数据插入（INSERT）
 • 数据验证和完整性检查
 
-建议在结构迁移完成后执行数据迁移。`,
+建议在结构迁移完成后执行数据迁移。
+
+配置了 migration.incremental 时，数据迁移会记录各表增量列的水位；
+切换前使用 --incremental 只同步上次水位之后新增或变更的数据。`,
 	Run: runMigrateData,
 }
 
@@ -101,33 +162,58 @@ func init() {
 	migrateCmd.PersistentFlags().DurationVar(&migrateTimeout, "timeout", 2*time.Hour, "迁移超时时间")
 	migrateCmd.PersistentFlags().IntVar(&migrateParallel, "parallel", 0, "并行作业数（0表示使用配置文件设置）")
 	migrateCmd.PersistentFlags().BoolVar(&migrateResume, "resume", false, "恢复中断的迁移")
+	migrateCmd.PersistentFlags().BoolVar(&migrateIdempotent, "idempotent", false, "幂等模式：清理上次的输出，结构先删后建（DROP_IF_EXISTS），数据先清空再导入（TRUNCATE_TABLE）")
 	migrateCmd.PersistentFlags().BoolVar(&migrateValidate, "validate", true, "迁移后验证结果")
 	migrateCmd.PersistentFlags().BoolVar(&migrateBackup, "backup", true, "迁移前创建备份")
+	migrateCmd.PersistentFlags().StringVar(&migrateSyslog, "syslog", "", "实时转发ora2pg输出到syslog (如 udp://127.0.0.1:514)")
+	migrateCmd.PersistentFlags().BoolVar(&migrateMonitor, "monitor", false, "监控ora2pg进程的CPU和内存使用")
+	migrateCmd.PersistentFlags().BoolVar(&migrateGatherStats, "gather-stats", false, "迁移前在源库收集Oracle统计信息（会产生负载）")
+	migrateCmd.PersistentFlags().DurationVar(&migrateGatherStatsTimeout, "gather-stats-timeout", time.Hour, "统计信息收集超时时间")
+	migrateCmd.PersistentFlags().BoolVar(&migrateArchive, "archive", false, "迁移完成后将输出目录打包归档到backup目录")
+	migrateCmd.PersistentFlags().BoolVar(&migrateArchiveClean, "archive-clean", false, "归档成功后清理输出目录中的原始文件（需同时指定 --archive）")
+	migrateCmd.PersistentFlags().BoolVar(&migrateSkipEmptyTypes, "skip-empty-types", true, "执行每个类型前检查源库，没有对应对象的类型（如没有触发器时的 TRIGGER）跳过而不是失败")
+	migrateCmd.PersistentFlags().StringArrayVar(&migrateTags, "tag", nil, "迁移标签，格式 key=value，可重复指定（如 --tag ticket=JIRA-123）")
+	migrateCmd.PersistentFlags().StringVar(&migrateNote, "note", "", "迁移备注，随运行记录保存到历史")
+	migrateCmd.PersistentFlags().BoolVar(&migrateAnalyze, "analyze", false, "迁移完成后在目标库执行ANALYZE更新统计信息")
+	migrateCmd.PersistentFlags().BoolVar(&migrateCheckConf, "check-conf", false, "迁移前调用ora2pg校验生成的配置文件（ora2pg不可用时跳过）")
+	migrateCmd.PersistentFlags().StringVar(&migrateAnalyzeScope, "analyze-scope", string(postgres.AnalyzeScopeMigrated), "ANALYZE范围: migrated（本次迁移的表）、schema（目标模式）、database（整库）")
+	migrateCmd.PersistentFlags().DurationVar(&migrateAnalyzeTimeout, "analyze-timeout", time.Hour, "ANALYZE超时时间")
+	migrateCmd.PersistentFlags().StringVar(&migrateSchedule, "schedule", "", "延迟到指定时间开始执行（如 02:00 或 \"2024-01-02 02:00\"），等待期间可按 Ctrl+C 取消")
+	migrateDataCmd.Flags().BoolVar(&migrateIncremental, "incremental", false, "增量同步：只导出上次水位之后的数据（需配置 migration.incremental）")
+	migrateCmd.PersistentFlags().IntVar(&migratePartialExitCode, "partial-failure-exit-code", defaultPartialFailureExitCode, "部分迁移类型失败时的退出码（0-255，全部成功为0，全部失败为1）")
+	migrateCmd.PersistentFlags().BoolVar(&migrateForce, "force", false, "已有迁移锁时强制获取（确认没有其他迁移在运行时使用）")
+	migrateCmd.PersistentFlags().BoolVar(&migrateCheckSQL, "check-sql", false, "导出结构后试执行生成的SQL（在事务中执行后回滚），报告语法问题（同 migration.sql_check.enabled）")
+	migrateCmd.PersistentFlags().BoolVar(&migrateSkipVersionCheck, "skip-version-check", false, "跳过迁移前的工具版本校验（tools 中声明的期望版本）")
+	migrateCmd.PersistentFlags().StringVar(&migrateOrder, "order", "", "手动指定执行顺序，逗号分隔（如 TABLE,SEQUENCE,COPY），需满足依赖关系")
 }
 
 // runMigrateStructure 执行结构迁移
 func runMigrateStructure(cmd *cobra.Command, args []string) {
 	logger := utils.GetGlobalLogger()
 	
+	detachIfRequested()
+
 	fmt.Println("🏗️ 数据库结构迁移")
 	fmt.Println()
 
-	// 1. 加载配置和初始化服务
+	// 1. 等待调度时间
+	if err := waitForSchedule(); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	// 加载配置和初始化服务
 	migrationService, err := initializeMigrationService()
 	if err != nil {
 		fmt.Printf("%s\n", utils.FormatError(err))
-		os.Exit(1)
+		exit(1)
 	}
 
-	// 2. 定义结构迁移类型
-	structureTypes := []service.MigrationType{
-		service.MigrationTypeTable,
-		service.MigrationTypeView,
-		service.MigrationTypeSequence,
-		service.MigrationTypeIndex,
-		service.MigrationTypeTrigger,
-		service.MigrationTypeFunction,
-		service.MigrationTypeProcedure,
+	// 2. 从配置的迁移类型中选出结构类型
+	structureTypes, err := resolveTaskMigrationTypes(migrationService, service.TaskTypeStructure)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
 	}
 
 	// 3. 执行迁移
@@ -137,96 +223,121 @@ func runMigrateStructure(cmd *cobra.Command, args []string) {
 	results, err := executeMigrationWithProgress(ctx, migrationService, structureTypes, "结构迁移")
 	if err != nil {
 		fmt.Printf("%s\n", utils.FormatError(err))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 4. 显示结果
-	showMigrationResults(results, "结构迁移")
+	showMigrationResults(results, "结构迁移", migrationService.GetState().Metadata)
 	
 	logger.Info("结构迁移完成")
+	exitWithMigrationResults(results)
 }
 
 // runMigrateData 执行数据迁移
 func runMigrateData(cmd *cobra.Command, args []string) {
 	logger := utils.GetGlobalLogger()
 	
+	detachIfRequested()
+
 	fmt.Println("📊 数据内容迁移")
 	fmt.Println()
 
-	// 1. 加载配置和初始化服务
+	// 1. 等待调度时间
+	if err := waitForSchedule(); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	// 加载配置和初始化服务
 	migrationService, err := initializeMigrationService()
 	if err != nil {
 		fmt.Printf("%s\n", utils.FormatError(err))
-		os.Exit(1)
+		exit(1)
+	}
+
+	// 2. 从配置的迁移类型中选出数据类型
+	dataTypes, err := resolveTaskMigrationTypes(migrationService, service.TaskTypeData)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
 	}
 
-	// 2. 定义数据迁移类型
-	dataTypes := []service.MigrationType{
-		service.MigrationTypeCopy,
-		service.MigrationTypeInsert,
+	taskName := "数据迁移"
+	if migrateIncremental {
+		if err := enableIncrementalSync(migrationService); err != nil {
+			fmt.Printf("%s\n", utils.FormatError(err))
+			exit(1)
+		}
+		taskName = "增量同步"
 	}
 
 	// 3. 执行迁移
 	ctx, cancel := createMigrationContext()
 	defer cancel()
 
-	results, err := executeMigrationWithProgress(ctx, migrationService, dataTypes, "数据迁移")
+	// 增量同步本来就在源库写入期间执行，不检查写入状态
+	if !migrateIncremental {
+		warnSourceWrites(ctx, migrationService.GetConfig())
+	}
+
+	results, err := executeMigrationWithProgress(ctx, migrationService, dataTypes, taskName)
 	if err != nil {
 		fmt.Printf("%s\n", utils.FormatError(err))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 4. 显示结果
-	showMigrationResults(results, "数据迁移")
+	showMigrationResults(results, taskName, migrationService.GetState().Metadata)
+	showWatermarks(migrationService.GetState().Watermarks)
 	
 	logger.Info("数据迁移完成")
+	exitWithMigrationResults(results)
 }
 
 // runMigrateAll 执行完整迁移
 func runMigrateAll(cmd *cobra.Command, args []string) {
 	logger := utils.GetGlobalLogger()
 	
+	detachIfRequested()
+
 	fmt.Println("🚀 完整数据库迁移")
 	fmt.Println()
 
-	// 1. 加载配置和初始化服务
+	// 1. 等待调度时间
+	if err := waitForSchedule(); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	// 加载配置和初始化服务
 	migrationService, err := initializeMigrationService()
 	if err != nil {
 		fmt.Printf("%s\n", utils.FormatError(err))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 2. 定义完整迁移类型（按执行顺序）
-	allTypes := []service.MigrationType{
-		// 第一阶段：基础结构
-		service.MigrationTypeTable,
-		service.MigrationTypeView,
-		service.MigrationTypeSequence,
-		// 第二阶段：数据内容
-		service.MigrationTypeCopy,
-		// 第三阶段：索引和约束
-		service.MigrationTypeIndex,
-		// 第四阶段：程序对象
-		service.MigrationTypeTrigger,
-		service.MigrationTypeFunction,
-		service.MigrationTypeProcedure,
-		// 第五阶段：权限
-		service.MigrationTypeGrant,
-	}
+	allTypes := allMigrationTypes
 
 	// 3. 执行迁移
 	ctx, cancel := createMigrationContext()
 	defer cancel()
 
+	warnSourceWrites(ctx, migrationService.GetConfig())
+
 	results, err := executeMigrationWithProgress(ctx, migrationService, allTypes, "完整迁移")
 	if err != nil {
 		fmt.Printf("%s\n", utils.FormatError(err))
-		os.Exit(1)
+		exit(1)
 	}
 
 	// 4. 显示结果
-	showMigrationResults(results, "完整迁移")
+	showMigrationResults(results, "完整迁移", migrationService.GetState().Metadata)
 	
+	if !migrateAnalyze {
+		fmt.Println("💡 建议使用 --analyze 在迁移后更新PostgreSQL统计信息，避免查询计划不佳")
+	}
+
 	// 5. 执行验证（如果启用）
 	if migrateValidate {
 		fmt.Println()
@@ -236,10 +347,16 @@ func runMigrateAll(cmd *cobra.Command, args []string) {
 	}
 	
 	logger.Info("完整迁移完成")
+	exitWithMigrationResults(results)
 }
 
 // initializeMigrationService 初始化迁移服务
 func initializeMigrationService() (*service.MigrationService, error) {
+	return initializeMigrationServiceWith(nil)
+}
+
+// initializeMigrationServiceWith 初始化迁移服务，watched 为正在监听的配置管理器时使用其当前配置，为空时从配置文件加载
+func initializeMigrationServiceWith(watched *config.Manager) (*service.MigrationService, error) {
 	// 检查项目环境
 	fileUtils := utils.NewFileUtils()
 	if !fileUtils.DirExists(".ora2pg-admin") {
@@ -249,50 +366,333 @@ func initializeMigrationService() (*service.MigrationService, error) {
 			Build()
 	}
 
+	// 同一项目同时只允许一个迁移运行
+	if err := acquireMigrationLock(auditSession.command); err != nil {
+		return nil, err
+	}
+
 	// 加载配置
-	configPath := filepath.Join(".ora2pg-admin", "config.yaml")
-	manager := config.NewManager()
-	if err := manager.LoadConfig(configPath); err != nil {
-		return nil, utils.ConfigErrors.ParseFailed(err)
+	manager := watched
+	if manager == nil {
+		manager = config.NewManager()
+		if err := manager.LoadConfig(config.ProjectConfigPath(".")); err != nil {
+			return nil, configLoadError(err)
+		}
+	}
+	cfg := manager.GetConfig()
+	if err := config.CheckDumpFileReference(&cfg.Oracle); err != nil {
+		return nil, err
 	}
 
 	// 创建迁移服务
-	migrationService := service.NewMigrationService(manager.GetConfig())
+	migrationService := service.NewMigrationService(cfg)
 
 	// 应用命令行参数
-	if migrateParallel > 0 {
+	if migratePartialExitCode < 0 || migratePartialExitCode > 255 {
+		return nil, utils.NewError(utils.ErrorTypeValidation, "INVALID_EXIT_CODE").
+			Message("无效的部分失败退出码").
+			Details(fmt.Sprintf("--partial-failure-exit-code=%d", migratePartialExitCode)).
+			Suggestion("退出码需在 0-255 之间").
+			Build()
+	}
+	if migrateAnalyze {
+		if _, err := postgres.ParseAnalyzeScope(migrateAnalyzeScope); err != nil {
+			return nil, err
+		}
+	}
+	metadata, err := service.ParseMetadataTags(migrateTags)
+	if err != nil {
+		return nil, err
+	}
+	if note := strings.TrimSpace(migrateNote); note != "" {
+		metadata[service.MetadataNoteKey] = note
+	}
+	if migrateIdempotent && migrateResume {
+		// 续传跳过已完成的表，幂等模式会清空目标表，两者一起使用会丢失已完成表的数据
+		return nil, utils.NewError(utils.ErrorTypeUser, "IDEMPOTENT_RESUME_CONFLICT").
+			Message("--idempotent 不能与 --resume 同时使用").
+			Details("幂等模式会重建对象并清空目标表，续传跳过的已完成表将没有数据").
+			Suggestion("去掉 --resume 以幂等模式重新执行整个迁移，或去掉 --idempotent 继续上次的迁移").
+			Build()
+	}
+	runEnv, err := applyRunEnvironment(migrationService, metadata)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyDryRun(migrationService, metadata); err != nil {
+		return nil, err
+	}
+	migrationService.SetMetadata(metadata)
+	if runEnv == nil && migrateParallel > 0 {
 		migrationService.SetParallelJobs(migrateParallel)
 	}
+	migrationService.SetResume(migrateResume)
+	if migrateIdempotent {
+		migrationService.SetIdempotent(true)
+		fmt.Println("♻️ 幂等模式：清理上次的输出，结构先删后建，数据先清空再导入")
+	}
+	migrationService.SetValidateConfig(migrateCheckConf)
+	migrationService.SetSkipEmptyTypes(migrateSkipEmptyTypes)
+	migrationService.SetSkipVersionCheck(migrateSkipVersionCheck)
+	if migrateCheckSQL {
+		migrationService.SetSQLCheck(true)
+	}
+	if migrateMonitor {
+		migrationService.EnableResourceMonitor(0)
+	}
+	// 交互模式下接近超时时询问是否延长，非交互时只记录预警日志
+	if utils.IsInteractive() {
+		migrationService.SetTimeoutWarningHandler(promptTimeoutExtension)
+	}
+
+	// 输出转发失败不影响迁移，仅提示
+	if migrateSyslog != "" {
+		if sink, err := newSyslogSink(migrateSyslog); err != nil {
+			fmt.Printf("⚠️ syslog转发未启用: %s\n", utils.FormatError(err))
+		} else {
+			migrationService.AddOutputSink(sink)
+			fmt.Printf("📡 ora2pg输出将实时转发到: %s\n", sink.Name())
+		}
+	}
 
 	return migrationService, nil
 }
 
+// enableIncrementalSync 检查增量同步的前提并切换到增量模式
+func enableIncrementalSync(migrationService *service.MigrationService) error {
+	if !migrationService.GetConfig().Migration.Incremental.Enabled() {
+		return utils.NewError(utils.ErrorTypeConfig, "INCREMENTAL_NOT_CONFIGURED").
+			Message("未配置增量同步的表").
+			Suggestion("在配置文件的 migration.incremental.tables 中为需要增量同步的表指定增量列").
+			Build()
+	}
+	if migrateResume {
+		// 续传时已完成的表只导出到上次的水位，推进水位会漏掉之间的数据
+		return utils.NewError(utils.ErrorTypeUser, "INCREMENTAL_RESUME_UNSUPPORTED").
+			Message("增量同步不支持 --resume").
+			Details("增量同步失败时不会推进水位，直接重新执行即可从上次的水位继续").
+			Suggestion("去掉 --resume 重新执行 'ora2pg-admin 迁移 数据 --incremental'").
+			Build()
+	}
+	if migrateIdempotent {
+		// 增量同步在目标表已有数据的基础上追加，幂等模式会先清空目标表
+		return utils.NewError(utils.ErrorTypeUser, "INCREMENTAL_IDEMPOTENT_UNSUPPORTED").
+			Message("增量同步不支持 --idempotent").
+			Details("增量同步只导出上次水位之后的数据并追加到目标表，清空目标表会丢失之前同步的数据").
+			Suggestion("去掉 --idempotent；需要重新全量导出时执行 'ora2pg-admin 迁移 数据 --idempotent'").
+			Build()
+	}
+	migrationService.SetIncremental(true)
+	fmt.Println("🔁 增量同步：只导出上次水位之后的数据")
+	return nil
+}
+
+// showWatermarks 显示本次更新的增量水位
+func showWatermarks(watermarks map[string]*service.TableWatermark) {
+	if len(watermarks) == 0 {
+		return
+	}
+	tables := make([]string, 0, len(watermarks))
+	for table := range watermarks {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	fmt.Println()
+	fmt.Println("📌 增量水位:")
+	for _, table := range tables {
+		watermark := watermarks[table]
+		value := watermark.Value
+		if value == "" {
+			value = "（无数据）"
+		}
+		fmt.Printf("   %s.%s = %s\n", table, watermark.Column, value)
+	}
+}
+
+// resolveTaskMigrationTypes 确定子命令执行的迁移类型：结构/数据按配置的类型过滤，全部使用完整流程
+func resolveTaskMigrationTypes(migrationService *service.MigrationService, taskType string) ([]service.MigrationType, error) {
+	phases, filtered := taskMigrationPhases[taskType]
+	if !filtered {
+		return allMigrationTypes, nil
+	}
+
+	types, unsupported := migrationService.ConfiguredTypesForPhases(phases...)
+	for _, name := range unsupported {
+		fmt.Printf("⚠️ 迁移类型 %s 暂不支持单独执行，已跳过\n", name)
+	}
+	if len(types) == 0 {
+		configured := strings.Join(migrationService.GetConfig().Migration.Types, ", ")
+		if configured == "" {
+			configured = "（空）"
+		}
+		return nil, utils.NewError(utils.ErrorTypeConfig, "NO_MIGRATION_TYPES").
+			Message(fmt.Sprintf("配置的迁移类型中没有可执行的%s类型", taskType)).
+			Details(fmt.Sprintf("当前配置的迁移类型: %s", configured)).
+			Suggestion("运行 'ora2pg-admin 配置 选项' 选择迁移类型，数据迁移需要 COPY 或 INSERT").
+			Build()
+	}
+	fmt.Printf("📋 按配置执行的%s类型: %s\n", taskType, service.FormatMigrationOrder(types))
+	return types, nil
+}
+
+// promptTimeoutExtension 迁移类型接近超时时询问是否延长，返回延长的时间，不延长时返回0
+func promptTimeoutExtension(migrationType service.MigrationType, elapsed, timeout time.Duration) time.Duration {
+	fmt.Printf("\n⏰ 迁移类型 %s 已运行 %v，接近超时阈值 %v\n", migrationType, elapsed.Round(time.Second), timeout)
+	if !utils.AskYesNo("是否延长超时时间") {
+		return 0
+	}
+
+	prompt := promptui.Prompt{
+		Label:    "延长时间（如 30m、1h）",
+		Default:  "30m",
+		Validate: validatePositiveDuration,
+	}
+	input, err := prompt.Run()
+	if err != nil {
+		return 0
+	}
+	extension, _ := time.ParseDuration(strings.TrimSpace(input))
+	fmt.Printf("⏱️ 已将 %s 的超时延长 %v（整体超时 --timeout 仍然有效）\n", migrationType, extension)
+	return extension
+}
+
+// validatePositiveDuration 验证正的时长
+func validatePositiveDuration(input string) error {
+	duration, err := time.ParseDuration(strings.TrimSpace(input))
+	if err != nil {
+		return fmt.Errorf("必须是时长，如 30m、1h")
+	}
+	if duration <= 0 {
+		return fmt.Errorf("必须大于0")
+	}
+	return nil
+}
+
+// newSyslogSink 根据 "udp://host:514" 形式的地址创建syslog转发
+func newSyslogSink(target string) (*service.SyslogSink, error) {
+	network, address, err := service.ParseSyslogTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	return service.NewSyslogSink(network, address, "ora2pg-admin")
+}
+
 // createMigrationContext 创建迁移上下文
 func createMigrationContext() (context.Context, context.CancelFunc) {
-	ctx, cancel := context.WithTimeout(context.Background(), migrateTimeout)
+	interruptCtx, interruptCancel := createInterruptContext()
+	ctx, cancel := context.WithTimeout(interruptCtx, migrateTimeout)
+	return ctx, func() {
+		cancel()
+		interruptCancel()
+	}
+}
+
+// createInterruptContext 创建收到中断信号时取消的上下文
+func createInterruptContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
 
 	// 设置信号处理，支持优雅中断
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
-		<-sigChan
-		fmt.Println("\n⚠️ 收到中断信号，正在停止迁移...")
-		cancel()
+		defer signal.Stop(sigChan)
+		select {
+		case <-sigChan:
+			fmt.Println("\n⚠️ 收到中断信号，正在停止迁移...")
+			cancel()
+		case <-ctx.Done():
+		}
 	}()
 
 	return ctx, cancel
 }
 
+// waitForSchedule 指定了 --schedule 时等待到调度时间，等待期间可中断
+func waitForSchedule() error {
+	if migrateSchedule == "" {
+		return nil
+	}
+	ctx, cancel := createInterruptContext()
+	defer cancel()
+	_, err := waitForScheduleContext(ctx)
+	return err
+}
+
+// waitForScheduleContext 等待到 --schedule 指定的时间，返回实际开始时间
+func waitForScheduleContext(ctx context.Context) (time.Time, error) {
+	now := time.Now()
+	if migrateSchedule == "" {
+		return now, nil
+	}
+	at, err := service.ParseScheduleTime(migrateSchedule, now)
+	if err != nil {
+		return now, err
+	}
+
+	fmt.Printf("⏰ 已调度，将于 %s 开始执行（%v 后），按 Ctrl+C 取消\n",
+		at.Format("2006-01-02 15:04:05"), at.Sub(now).Truncate(time.Second))
+	utils.GetGlobalLogger().Infof("迁移已调度: %s", at.Format(time.RFC3339))
+	if err := service.NewScheduler().WaitUntil(ctx, at); err != nil {
+		return now, utils.NewError(utils.ErrorTypeMigration, "SCHEDULE_CANCELLED").
+			Message("已取消调度的迁移").
+			Cause(err).
+			Build()
+	}
+	fmt.Println("⏰ 到达调度时间，开始执行")
+	fmt.Println()
+	return at, nil
+}
+
 // executeMigrationWithProgress 执行迁移并显示进度
 func executeMigrationWithProgress(ctx context.Context, migrationService *service.MigrationService,
 	migrationTypes []service.MigrationType, taskName string) ([]*service.ExecutionResult, error) {
 
+	defer migrationService.CloseOutputSinks()
+
+	// 手动顺序覆盖默认顺序
+	migrationTypes, err := resolveMigrationOrder(migrationTypes)
+	if err != nil {
+		return nil, err
+	}
+	if migrateOrder != "" {
+		fmt.Printf("🔀 使用手动执行顺序: %s\n", service.FormatMigrationOrder(migrationTypes))
+	}
+	printMigrationTypeNotes(migrationTypes)
+
+	if migrateGatherStats {
+		gatherOracleStats(ctx, migrationService.GetConfig())
+	}
+	estimateProgressScale(ctx, migrationService, migrationTypes)
+
+	// 配置了多个目标库时并行向各目标迁移
+	if multiTargetEnabled(migrationService) {
+		return executeMultiTargetMigration(ctx, migrationService, migrationTypes, taskName)
+	}
+
 	fmt.Printf("📋 开始执行%s，共 %d 个步骤\n", taskName, len(migrationTypes))
+	fmt.Printf("🔖 运行ID: %s（目标库连接 application_name=%s）\n", utils.RunID(), config.ApplicationName())
 	fmt.Println()
+	utils.GetGlobalLogger().Infof("开始%s，运行ID: %s", taskName, utils.RunID())
 
-	// 创建进度跟踪器
+	// 创建进度跟踪器，配置了 webhook 时同时推送进度
+	notifier := service.NewNotifier(migrationService.GetConfig())
+	progressWebhook := notifier.StartProgressWebhook(taskName, len(migrationTypes))
+	// 配置了群机器人时发送迁移开始消息，失败不影响迁移
+	showChatBotErrors(notifier.NotifyChatBotsStarted(taskName, migrationTypes))
 	progressTracker := service.NewProgressTracker()
+	// 进度定期写入状态文件，监控端重启后可从文件恢复进度显示
+	progressTracker.SetStatePath(service.DefaultProgressStatePath)
+	updateHandler := progressWebhook.HandleUpdate
+	if recordProgress := detachedProgressHandler(taskName, len(migrationTypes)); recordProgress != nil {
+		// 后台运行时同时记录进度，供 '迁移 状态' 查看
+		updateHandler = func(update service.ProgressUpdate) {
+			progressWebhook.HandleUpdate(update)
+			recordProgress(update)
+		}
+	}
+	progressTracker.SetUpdateHandler(updateHandler)
 	progressTracker.Start(taskName, len(migrationTypes))
 
 	// 执行迁移
@@ -300,17 +700,341 @@ func executeMigrationWithProgress(ctx context.Context, migrationService *service
 
 	// 停止进度跟踪
 	progressTracker.Stop()
+	progressWebhook.Finish(migrationService.RunStatus(err))
+
+	showConstraintResult(migrationService)
+	showScriptResults(migrationService)
+	showAssertionResults(migrationService)
+	showSQLCheckReport(migrationService)
+	showMViewRefreshPlan(migrationService)
+	exportMigrationErrors(migrationService.ErrorReport(taskName, migrationTypes, err))
+
+	// 记录迁移历史，失败时仅提示
+	record, historyErr := migrationService.RecordHistory(taskName, migrationTypes, err)
+	if historyErr != nil {
+		fmt.Printf("⚠️ 记录迁移历史失败:\n%s\n", utils.FormatError(historyErr))
+	}
+
+	// 更新指标文件，失败时仅提示
+	if historyErr == nil {
+		if _, metricsErr := migrationService.ExportMetrics(); metricsErr != nil {
+			fmt.Printf("⚠️ 更新迁移指标失败:\n%s\n", utils.FormatError(metricsErr))
+		}
+	}
+
+	// 发送结果通知，失败不影响迁移结果
+	if notifyErr := notifier.NotifyMigrationFinished(record); notifyErr != nil {
+		fmt.Printf("⚠️ 发送迁移通知失败:\n%s\n", utils.FormatError(notifyErr))
+	}
+	showChatBotErrors(notifier.NotifyChatBotsFinished(record))
+
+	if migrateMonitor {
+		if !migrationService.ResourceMonitorSupported() {
+			fmt.Println("⚠️ 当前平台不支持资源监控，已跳过资源统计")
+		} else if stats := migrationService.GetResourceStats(); stats != nil {
+			fmt.Printf("📈 资源使用: %s\n", stats.Summary())
+		}
+	}
+	showThroughputChart(migrationService, progressTracker)
+	showMigrationProfile(record)
+
+	// 预演只生成SQL，对比目标库现状，不分析、不上传
+	if dryRun && err == nil {
+		showDryRunDiff(ctx, migrationService)
+	}
+
+	if migrateAnalyze && err == nil && !dryRun {
+		analyzeTargetDatabase(ctx, migrationService, migrationTypes)
+	}
+
+	// 先上传再归档，归档清理输出目录不影响上传
+	if migrateUpload && err == nil && !dryRun && migrationService.GetConfig().Migration.RemoteStorage.Enabled() {
+		uploadMigrationOutput(ctx, migrationService, results)
+	}
+
+	if migrateArchive && err == nil {
+		archiveMigrationOutput(migrationService, results)
+	}
 
 	return results, err
 }
 
+// showConstraintResult 显示数据迁移后重建外键的结果
+func showConstraintResult(migrationService *service.MigrationService) {
+	result, err := migrationService.ConstraintResult()
+	if err != nil {
+		fmt.Printf("❌ 恢复目标库约束失败，约束记录保留在 %s，修复后重新执行数据迁移会自动恢复:\n%s\n",
+			service.DefaultDeferredConstraintsPath, utils.FormatError(err))
+		return
+	}
+	if result == nil {
+		return
+	}
+
+	fmt.Printf("🔗 已重建 %d 个外键，重新启用 %d 张表的触发器（耗时 %v）\n",
+		len(result.Validated)+len(result.Violations), len(result.TriggerTables), result.Duration.Round(time.Second))
+	if len(result.Violations) > 0 {
+		fmt.Printf("⚠️ %d 个外键与现有数据冲突，已保留为 NOT VALID（仍约束新数据），请修复数据后执行 VALIDATE CONSTRAINT:\n", len(result.Violations))
+		for _, violation := range result.Violations {
+			fmt.Printf("   • %s.%s: %s\n", violation.Table, violation.Name, violation.Reason)
+			if violation.Detail != "" {
+				fmt.Printf("     %s\n", violation.Detail)
+			}
+		}
+	}
+	if len(result.Failed) > 0 {
+		fmt.Printf("❌ %d 个外键无法重建，定义保留在 %s:\n", len(result.Failed), service.DefaultDeferredConstraintsPath)
+		for _, failure := range result.Failed {
+			fmt.Printf("   • %s.%s: %s\n", failure.Table, failure.Name, failure.Reason)
+		}
+	}
+}
+
+// showMViewRefreshPlan 显示物化视图刷新脚本和刷新策略的转换建议
+func showMViewRefreshPlan(migrationService *service.MigrationService) {
+	plan := migrationService.MViewRefreshPlan()
+	if plan == nil {
+		return
+	}
+	if plan.ScriptPath != "" {
+		fmt.Printf("🔄 已按依赖顺序生成 %d 个物化视图的刷新脚本，数据迁移完成后执行: %s\n", len(plan.Views), plan.ScriptPath)
+	}
+	for _, item := range plan.Views {
+		if len(item.Notes) == 0 {
+			continue
+		}
+		fmt.Printf("   • %s（%s）\n", item.Name, item.Source)
+		for _, note := range item.Notes {
+			fmt.Printf("     %s\n", note)
+		}
+	}
+}
+
+// showScriptResults 显示迁移前后脚本的执行结果
+func showScriptResults(migrationService *service.MigrationService) {
+	results := migrationService.ScriptResults()
+	if len(results) == 0 {
+		return
+	}
+
+	fmt.Println("📜 迁移前后脚本:")
+	for _, result := range results {
+		icon := "✅"
+		switch result.Status {
+		case service.StatusFailed:
+			icon = "❌"
+		case service.StatusPending, service.StatusCancelled:
+			icon = "⏭️"
+		}
+		fmt.Printf("   %s [%s] %s → %s", icon, result.Phase, result.Path, result.Target)
+		if result.Status == service.StatusCompleted {
+			fmt.Printf("（%v）", result.Duration.Round(time.Millisecond))
+		} else if result.Status != service.StatusFailed {
+			fmt.Print("（未执行）")
+		}
+		fmt.Println()
+		if result.Error != "" {
+			fmt.Printf("     %s\n", result.Error)
+		}
+	}
+}
+
+// archiveMigrationOutput 归档迁移输出，存在失败项时保留原始文件便于排查
+func archiveMigrationOutput(migrationService *service.MigrationService, results []*service.ExecutionResult) {
+	clean := migrateArchiveClean
+	for _, result := range results {
+		if !result.Status.Succeeded() {
+			if clean {
+				fmt.Println("⚠️ 存在未成功的迁移类型，保留输出目录中的原始文件")
+			}
+			clean = false
+			break
+		}
+	}
+
+	fmt.Println("📦 正在归档迁移输出...")
+	archivePath, count, err := migrationService.ArchiveOutput(clean)
+	if err != nil {
+		fmt.Printf("⚠️ 归档迁移输出失败:\n%s\n", utils.FormatError(err))
+		return
+	}
+	fmt.Printf("✅ 已归档 %d 个文件: %s\n", count, archivePath)
+	if clean {
+		fmt.Println("🧹 已清理输出目录中的原始文件")
+	}
+}
+
+// gatherOracleStats 迁移前收集源库统计信息，失败时仅提示，不中断迁移
+func gatherOracleStats(ctx context.Context, cfg *config.ProjectConfig) {
+	logger := utils.GetGlobalLogger()
+
+	schema := cfg.Oracle.Schema
+	if schema == "" {
+		schema = cfg.Oracle.Username
+	}
+
+	fmt.Println("📊 收集Oracle统计信息")
+	fmt.Println("─────────────────────")
+	fmt.Printf("⚠️ 将在源库执行 DBMS_STATS.GATHER_SCHEMA_STATS(%s)，这会在源库产生明显负载\n", strings.ToUpper(schema))
+	fmt.Printf("   大库可能耗时较长，超时时间: %v\n", migrateGatherStatsTimeout)
+
+	runner := oracle.NewSQLPlusRunner(&cfg.Oracle, &cfg.OracleClient)
+	collector := oracle.NewStatsCollector(runner, schema)
+
+	statsCtx, cancel := context.WithTimeout(ctx, migrateGatherStatsTimeout)
+	defer cancel()
+
+	// 收集期间定期显示已用时间
+	startTime := time.Now()
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				fmt.Printf("\r⏳ 正在收集统计信息，已用时: %v", time.Since(startTime).Truncate(time.Second))
+			}
+		}
+	}()
+	err := collector.Gather(statsCtx)
+	close(done)
+	fmt.Print("\r")
+
+	if err == nil {
+		fmt.Printf("✅ 统计信息收集完成，耗时: %v\n", time.Since(startTime).Truncate(time.Second))
+		fmt.Println()
+		logger.Infof("Oracle统计信息收集完成: %s", schema)
+		return
+	}
+
+	if statsCtx.Err() == context.DeadlineExceeded {
+		fmt.Printf("⚠️ 统计信息收集超过 %v 未完成，继续迁移（可通过 --gather-stats-timeout 调整）\n", migrateGatherStatsTimeout)
+	} else {
+		fmt.Printf("⚠️ 统计信息收集未完成，继续迁移:\n%s\n", utils.FormatError(err))
+	}
+	logger.Warnf("Oracle统计信息收集失败: %v", err)
+
+	// 无法收集时至少检测统计新鲜度，提示估算可能不准
+	if ctx.Err() == nil && utils.GetErrorCode(err) == "ORACLE_STATS_PERMISSION" {
+		checkCtx, checkCancel := context.WithTimeout(ctx, 2*time.Minute)
+		defer checkCancel()
+		if freshness, checkErr := collector.CheckFreshness(checkCtx, oracle.DefaultStatsMaxAge); checkErr == nil {
+			if freshness.NeedsGather() {
+				fmt.Printf("⚠️ %s，ora2pg的行数和成本估算可能不准确\n", freshness.Summary())
+			} else {
+				fmt.Printf("✅ 现有统计信息较新: %s\n", freshness.Summary())
+			}
+		} else {
+			logger.Debugf("检测统计信息新鲜度失败: %v", checkErr)
+		}
+	}
+	fmt.Println()
+}
+
+// analyzeTargetDatabase 迁移后在目标库执行ANALYZE，失败时仅提示，不影响迁移结果
+func analyzeTargetDatabase(ctx context.Context, migrationService *service.MigrationService,
+	migrationTypes []service.MigrationType) {
+	logger := utils.GetGlobalLogger()
+	cfg := migrationService.GetConfig()
+	scope, _ := postgres.ParseAnalyzeScope(migrateAnalyzeScope)
+
+	fmt.Println()
+	fmt.Println("📈 更新PostgreSQL统计信息")
+	fmt.Println("─────────────────────────")
+
+	analyzeCtx, cancel := context.WithTimeout(ctx, migrateAnalyzeTimeout)
+	defer cancel()
+	analyzer := postgres.NewAnalyzer(postgres.NewPSQLRunner(&cfg.PostgreSQL), cfg.PostgreSQL.Schema)
+
+	var tables []string
+	switch scope {
+	case postgres.AnalyzeScopeMigrated:
+		hasData := false
+		for _, migrationType := range migrationTypes {
+			if migrationType == service.MigrationTypeCopy || migrationType == service.MigrationTypeInsert {
+				hasData = true
+			}
+		}
+		if !hasData {
+			fmt.Println("ℹ️ 本次未迁移数据，跳过ANALYZE")
+			return
+		}
+
+		for _, table := range migrationService.DataTables() {
+			tables = append(tables, cfg.Migration.TargetTableName(table))
+		}
+		if len(tables) == 0 {
+			fmt.Println("⚠️ 未能从ora2pg输出识别本次迁移的表，改为分析目标模式下的全部表")
+			scope = postgres.AnalyzeScopeSchema
+		}
+	}
+
+	if scope == postgres.AnalyzeScopeSchema {
+		schemaTables, err := analyzer.SchemaTables(analyzeCtx)
+		if err != nil {
+			fmt.Printf("⚠️ 获取目标模式的表失败，跳过ANALYZE:\n%s\n", utils.FormatError(err))
+			logger.Warnf("获取目标模式的表失败: %v", err)
+			return
+		}
+		tables = schemaTables
+	}
+
+	var result *postgres.AnalyzeResult
+	var err error
+	if scope == postgres.AnalyzeScopeDatabase {
+		fmt.Printf("⏳ 正在对数据库 %s 执行ANALYZE，大库可能耗时较长（超时时间: %v）\n",
+			cfg.PostgreSQL.Database, migrateAnalyzeTimeout)
+		result, err = analyzer.AnalyzeDatabase(analyzeCtx)
+	} else {
+		fmt.Printf("共 %d 张表，超时时间: %v\n", len(tables), migrateAnalyzeTimeout)
+		result, err = analyzer.AnalyzeTables(analyzeCtx, tables, func(index, total int, table string) {
+			fmt.Printf("\r⏳ ANALYZE [%d/%d] %-40s", index, total, table)
+		})
+		fmt.Print("\r")
+	}
+
+	if result != nil {
+		for _, skip := range result.Skipped {
+			fmt.Printf("⚠️ 跳过 %s: %s\n", skip.Table, skip.Reason)
+		}
+	}
+	if err != nil {
+		if analyzeCtx.Err() == context.DeadlineExceeded {
+			fmt.Printf("⚠️ ANALYZE超过 %v 未完成（可通过 --analyze-timeout 调整），请稍后手动执行\n", migrateAnalyzeTimeout)
+		} else {
+			fmt.Printf("⚠️ ANALYZE未完成:\n%s\n", utils.FormatError(err))
+		}
+		logger.Warnf("目标库ANALYZE失败: %v", err)
+		return
+	}
+
+	if scope == postgres.AnalyzeScopeDatabase {
+		fmt.Printf("✅ 数据库ANALYZE完成，耗时: %v\n", result.Duration.Truncate(time.Second))
+	} else {
+		fmt.Printf("✅ 已分析 %d 张表，耗时: %v\n", len(result.Analyzed), result.Duration.Truncate(time.Second))
+	}
+	logger.Infof("目标库ANALYZE完成，范围: %s", scope)
+}
+
 // showMigrationResults 显示迁移结果
-func showMigrationResults(results []*service.ExecutionResult, taskName string) {
+func showMigrationResults(results []*service.ExecutionResult, taskName string, metadata map[string]string) {
 	fmt.Println()
 	fmt.Printf("📊 %s结果摘要\n", taskName)
 	fmt.Println("─────────────────")
 
+	if tags := service.FormatMetadataTags(metadata); len(tags) > 0 {
+		fmt.Printf("🏷️ 标签: %s\n", strings.Join(tags, ", "))
+	}
+	if note := metadata[service.MetadataNoteKey]; note != "" {
+		fmt.Printf("📝 备注: %s\n", note)
+	}
+
 	successful := 0
+	withWarnings := 0
+	skipped := 0
 	failed := 0
 	totalDuration := time.Duration(0)
 
@@ -319,13 +1043,21 @@ func showMigrationResults(results []*service.ExecutionResult, taskName string) {
 
 		switch result.Status {
 		case service.StatusCompleted:
-			fmt.Printf("✅ 成功")
+			if result.HasWarnings() {
+				fmt.Printf("⚠️ 成功（有警告）")
+				withWarnings++
+			} else {
+				fmt.Printf("✅ 成功")
+			}
 			successful++
 		case service.StatusFailed:
 			fmt.Printf("❌ 失败")
 			failed++
 		case service.StatusCancelled:
 			fmt.Printf("⚠️ 已取消")
+		case service.StatusSkipped:
+			fmt.Printf("⏭️ 跳过（无对象）")
+			skipped++
 		default:
 			fmt.Printf("❓ 未知状态")
 		}
@@ -336,18 +1068,58 @@ func showMigrationResults(results []*service.ExecutionResult, taskName string) {
 		if result.Error != nil {
 			fmt.Printf("   错误: %s\n", result.Error.Error())
 		}
+		if result.Status == service.StatusSkipped && result.Progress != nil {
+			fmt.Printf("   原因: %s\n", result.Progress.Message)
+		}
+		if result.ErrorCount > 0 || result.WarningCount > 0 {
+			fmt.Printf("   输出: %d 个错误，%d 个警告\n", result.ErrorCount, result.WarningCount)
+		}
+		if result.Resources != nil {
+			fmt.Printf("   资源: %s\n", result.Resources.Summary())
+		}
 	}
 
 	fmt.Println()
-	fmt.Printf("总计: %d 成功, %d 失败, 总耗时: %v\n", successful, failed, totalDuration)
+	fmt.Printf("总计: %d 成功", successful)
+	if withWarnings > 0 {
+		fmt.Printf("（其中 %d 有警告）", withWarnings)
+	}
+	if skipped > 0 {
+		fmt.Printf(", %d 跳过", skipped)
+	}
+	fmt.Printf(", %d 失败, 总耗时: %v\n", failed, totalDuration)
 
-	if failed == 0 {
+	if failed == 0 && withWarnings > 0 {
+		fmt.Printf("✅ %s完成，但有 %d 个类型输出了警告，请检查日志确认\n", taskName, withWarnings)
+	} else if failed == 0 {
 		fmt.Printf("🎉 %s全部完成！\n", taskName)
 	} else {
 		fmt.Printf("⚠️ %s部分失败，请检查错误信息\n", taskName)
 	}
 }
 
+// migrationExitCode 根据执行摘要确定退出码：全部成功为0，全部失败为1，部分失败为 --partial-failure-exit-code
+func migrationExitCode(results []*service.ExecutionResult) int {
+	summary := service.NewOra2pgService().GetExecutionSummary(results)
+	successful := summary["successful"].(int) + summary["skipped"].(int)
+	switch {
+	case successful == summary["total_executions"].(int):
+		return 0
+	case successful == 0:
+		return exitCodeMigrationFailed
+	default:
+		return migratePartialExitCode
+	}
+}
+
+// exitWithMigrationResults 存在失败或取消的迁移类型时按结果设置退出码
+func exitWithMigrationResults(results []*service.ExecutionResult) {
+	if code := migrationExitCode(results); code != 0 {
+		utils.GetGlobalLogger().Infof("迁移存在未成功的类型，退出码: %d", code)
+		exit(code)
+	}
+}
+
 // validateMigrationResults 验证迁移结果
 func validateMigrationResults(results []*service.ExecutionResult) {
 	fmt.Println("正在验证迁移结果...")
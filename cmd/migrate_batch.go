@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+var (
+	batchPlanOnly bool
+	batchReplan   bool
+	batchNumbers  []int
+	batchNoPause  bool
+)
+
+// migrateBatchCmd 分批迁移数据命令
+var migrateBatchCmd = &cobra.Command{
+	Use:   "分批",
+	Short: "按计划分批迁移数据，每批完成后暂停验证",
+	Long: `按 migration.batching 中的规则把数据迁移拆成多个批次依次执行，适合灰度迁移和大库分批上线。
+
+分批规则示例：
+  migration:
+    batching:
+      table_buckets: 3          # 未切片的表按行数分成3批，小表在前
+      slices:                   # 大表按范围切片，每个范围一批
+        - name: ORDERS
+          column: CREATED_AT
+          type: timestamp
+          boundaries: ["2023-01-01", "2024-01-01"]
+
+首次执行时生成分批计划并保存，之后按计划继续；分批规则或表筛选变化后需要指定 --replan 重新生成计划。
+每个批次只迁移数据，请先执行 '迁移 结构' 创建目标表。批次完成后暂停，确认验证无误再继续下一批次，
+--yes 或 --no-pause 时不暂停。某个批次失败时停止，修复后重新执行本命令从失败的批次继续，已完成的批次会被跳过。
+
+示例：
+  ora2pg-admin 迁移 分批 --plan
+  ora2pg-admin 迁移 分批
+  ora2pg-admin 迁移 分批 --batch 3`,
+	Args: cobra.NoArgs,
+	Run:  runMigrateBatch,
+}
+
+func init() {
+	migrateCmd.AddCommand(migrateBatchCmd)
+
+	migrateBatchCmd.Flags().BoolVar(&batchPlanOnly, "plan", false, "只生成或显示分批计划和各批次进度，不执行迁移")
+	migrateBatchCmd.Flags().BoolVar(&batchReplan, "replan", false, "按当前配置重新生成分批计划，已有批次的进度不再沿用")
+	migrateBatchCmd.Flags().IntSliceVar(&batchNumbers, "batch", nil, "只执行指定序号的批次（可重复指定），已完成的批次也重新执行")
+	migrateBatchCmd.Flags().BoolVar(&batchNoPause, "no-pause", false, "批次之间不暂停确认")
+}
+
+// runMigrateBatch 按分批计划执行数据迁移
+func runMigrateBatch(cmd *cobra.Command, args []string) {
+	if !batchPlanOnly {
+		detachIfRequested()
+	}
+
+	fmt.Println("🧱 分批迁移数据")
+	fmt.Println()
+
+	manager, _, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	cfg := manager.GetConfig()
+	plan, err := loadOrCreateBatchPlan(cfg)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	progress, err := service.LoadBatchProgress(service.DefaultHistoryPath, plan)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	printBatchPlan(plan, progress)
+	if batchPlanOnly {
+		return
+	}
+
+	pending, err := selectBatches(progress)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	if len(pending) == 0 {
+		fmt.Println()
+		fmt.Println("✅ 全部批次已完成，无需执行")
+		return
+	}
+
+	if err := waitForSchedule(); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	ctx, cancel := createInterruptContext()
+	defer cancel()
+
+	outcomes := make([]batchOutcome, 0, len(pending))
+	paused := false
+	for i, item := range pending {
+		if ctx.Err() != nil {
+			break
+		}
+		outcome := runBatch(ctx, plan, item.Batch, batchIndex(plan, item.Batch))
+		outcomes = append(outcomes, outcome)
+		if !outcome.succeeded() {
+			break
+		}
+		if i < len(pending)-1 && !batchNoPause {
+			fmt.Println()
+			if !utils.Confirm(fmt.Sprintf("批次 %d 已完成，验证无误后继续下一批次？", batchIndex(plan, item.Batch))) {
+				paused = true
+				break
+			}
+		}
+	}
+	if !printBatchSummary(plan, pending, outcomes, paused) {
+		exit(1)
+	}
+}
+
+// loadOrCreateBatchPlan 加载已保存的分批计划，没有计划或指定 --replan 时按当前配置生成
+func loadOrCreateBatchPlan(cfg *config.ProjectConfig) (*service.BatchPlan, error) {
+	if !cfg.Migration.Batching.Enabled() {
+		return nil, utils.NewError(utils.ErrorTypeConfig, "BATCHING_NOT_CONFIGURED").
+			Message("未配置分批规则").
+			Suggestion("在配置文件的 migration.batching 中设置 table_buckets 或 slices").
+			Build()
+	}
+
+	plan, err := service.LoadBatchPlan(service.DefaultBatchPlanPath)
+	if err != nil && !batchReplan {
+		return nil, err
+	}
+	if plan != nil && !batchReplan {
+		if plan.ConfigHash != service.BatchConfigFingerprint(&cfg.Migration) {
+			return nil, utils.NewError(utils.ErrorTypeConfig, "BATCH_PLAN_OUTDATED").
+				Message("分批规则或表筛选配置在生成计划后发生了变化").
+				Details(fmt.Sprintf("计划 %s 生成于 %s", plan.ID, plan.CreatedAt.Format("2006-01-02 15:04:05"))).
+				Suggestion("恢复原来的配置后继续，或指定 --replan 按当前配置重新生成计划（已有批次的进度不再沿用）").
+				Build()
+		}
+		return plan, nil
+	}
+
+	if plan != nil {
+		fmt.Printf("⚠️ 重新生成分批计划，计划 %s 中各批次的进度不再沿用\n", plan.ID)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	plan, err = service.PlanBatches(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Batches) == 0 {
+		return nil, utils.NewError(utils.ErrorTypeConfig, "BATCH_PLAN_EMPTY").
+			Message("按当前配置没有需要迁移的表").
+			Suggestion("检查表筛选配置，或运行 'ora2pg-admin 检查 连接' 确认源库中有表").
+			Build()
+	}
+	if err := service.SaveBatchPlan(service.DefaultBatchPlanPath, plan); err != nil {
+		return nil, err
+	}
+	fmt.Printf("📝 已生成分批计划 %s: %s\n", plan.ID, service.DefaultBatchPlanPath)
+	return plan, nil
+}
+
+// printBatchPlan 显示分批计划和各批次的进度
+func printBatchPlan(plan *service.BatchPlan, progress []*service.BatchProgress) {
+	fmt.Printf("📋 分批计划 %s（%d 个批次）\n", plan.ID, len(plan.Batches))
+	fmt.Println("─────────────────")
+	completed := 0
+	for i, item := range progress {
+		batch := item.Batch
+		rows := ""
+		if batch.Rows > 0 {
+			rows = fmt.Sprintf("，约 %d 行", batch.Rows)
+		}
+		switch {
+		case item.Completed():
+			completed++
+			fmt.Printf("✅ %d. %s%s: 已完成（%s）\n", i+1, batch.Name, rows, item.LastCompleted.EndTime.Format("2006-01-02 15:04:05"))
+		case item.Last != nil:
+			fmt.Printf("❌ %d. %s%s: %s（%s）\n", i+1, batch.Name, rows, item.Last.Status, item.Last.StartTime.Format("2006-01-02 15:04:05"))
+		default:
+			fmt.Printf("⬜ %d. %s%s: 未迁移\n", i+1, batch.Name, rows)
+		}
+		fmt.Printf("      %s\n", batch.Describe())
+	}
+	fmt.Printf("进度: %d/%d 个批次已完成\n", completed, len(progress))
+}
+
+// selectBatches 确定本次执行的批次：指定 --batch 时为这些批次，否则为未完成的批次
+func selectBatches(progress []*service.BatchProgress) ([]*service.BatchProgress, error) {
+	if len(batchNumbers) == 0 {
+		var pending []*service.BatchProgress
+		for _, item := range progress {
+			if !item.Completed() {
+				pending = append(pending, item)
+			}
+		}
+		return pending, nil
+	}
+
+	selected := make(map[int]bool, len(batchNumbers))
+	for _, number := range batchNumbers {
+		if number < 1 || number > len(progress) {
+			return nil, utils.ValidationErrors.OutOfRange("--batch", 1, len(progress))
+		}
+		selected[number] = true
+	}
+	var pending []*service.BatchProgress
+	for i, item := range progress {
+		if selected[i+1] {
+			pending = append(pending, item)
+		}
+	}
+	return pending, nil
+}
+
+// batchIndex 批次在计划中的序号，从1开始
+func batchIndex(plan *service.BatchPlan, batch *service.MigrationBatch) int {
+	for i, item := range plan.Batches {
+		if item.ID == batch.ID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// batchOutcome 单个批次的执行结果
+type batchOutcome struct {
+	batch    *service.MigrationBatch
+	results  []*service.ExecutionResult
+	err      error
+	duration time.Duration
+}
+
+// succeeded 批次的全部迁移类型是否成功
+func (o batchOutcome) succeeded() bool {
+	return o.err == nil && migrationExitCode(o.results) == 0
+}
+
+// runBatch 执行单个批次的数据迁移，每个批次使用独立的迁移服务和超时
+func runBatch(ctx context.Context, plan *service.BatchPlan, batch *service.MigrationBatch, index int) batchOutcome {
+	startTime := time.Now()
+	outcome := batchOutcome{batch: batch}
+	taskName := fmt.Sprintf("批次 %d/%d %s", index, len(plan.Batches), batch.Name)
+
+	fmt.Println()
+	fmt.Printf("▶️ %s\n", taskName)
+	fmt.Printf("   %s\n", batch.Describe())
+	fmt.Println("─────────────────")
+
+	migrationService, err := initializeMigrationService()
+	var migrationTypes []service.MigrationType
+	if err == nil {
+		migrationService.SetBatch(plan, batch)
+		migrationTypes, err = resolveTaskMigrationTypes(migrationService, service.TaskTypeData)
+	}
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		outcome.err = err
+		outcome.duration = time.Since(startTime)
+		return outcome
+	}
+
+	batchCtx, batchCancel := context.WithTimeout(ctx, migrateTimeout)
+	defer batchCancel()
+
+	outcome.results, outcome.err = executeMigrationWithProgress(batchCtx, migrationService, migrationTypes, taskName)
+	outcome.duration = time.Since(startTime)
+	if outcome.err != nil {
+		fmt.Printf("%s\n", utils.FormatError(outcome.err))
+		return outcome
+	}
+	showMigrationResults(outcome.results, taskName, migrationService.GetState().Metadata)
+	return outcome
+}
+
+// printBatchSummary 显示本次执行的各批次结果，全部成功时返回true；暂停不算失败
+func printBatchSummary(plan *service.BatchPlan, pending []*service.BatchProgress, outcomes []batchOutcome, paused bool) bool {
+	fmt.Println()
+	fmt.Println("📊 分批迁移结果")
+	fmt.Println("─────────────────")
+
+	success := true
+	for i, item := range pending {
+		index := batchIndex(plan, item.Batch)
+		if i >= len(outcomes) {
+			fmt.Printf("⏭️ %d. %s: 未执行\n", index, item.Batch.Name)
+			continue
+		}
+		outcome := outcomes[i]
+		duration := outcome.duration.Truncate(time.Second)
+		if outcome.succeeded() {
+			fmt.Printf("✅ %d. %s: 完成（耗时 %v）\n", index, item.Batch.Name, duration)
+			continue
+		}
+		success = false
+		fmt.Printf("❌ %d. %s: 失败（耗时 %v）\n", index, item.Batch.Name, duration)
+	}
+
+	switch {
+	case !success:
+		fmt.Println("💡 修复后重新执行 'ora2pg-admin 迁移 分批'，从失败的批次继续，已完成的批次会被跳过")
+	case paused:
+		fmt.Println("⏸️ 已暂停，验证完成后重新执行 'ora2pg-admin 迁移 分批' 继续后续批次")
+	case len(outcomes) < len(pending):
+		// 被中断时未执行完全部批次
+		success = false
+		fmt.Println("💡 迁移被中断，重新执行 'ora2pg-admin 迁移 分批' 继续后续批次")
+	}
+	return success
+}
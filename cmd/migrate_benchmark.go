@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+var (
+	benchmarkOutputDir  string
+	benchmarkKeepOutput bool
+	benchmarkDataOnly   bool
+	benchmarkProdRows   int64
+	benchmarkProdFactor float64
+	benchmarkOutput     string
+	benchmarkSave       string
+)
+
+// migrateBenchmarkCmd 迁移性能基准测试
+var migrateBenchmarkCmd = &cobra.Command{
+	Use:   "基准",
+	Short: "在测试环境测量迁移性能并外推生产库耗时",
+	Long: `对源库执行完整的迁移流程，但只把结果导出到临时目录，不连接目标库，
+记录各迁移类型的耗时、导出行数和吞吐，生成性能基准报告。
+
+基准测试不会修改目标库、检查点和迁移历史，也不执行迁移前后脚本和增量同步。
+指定生产库的数据量后，按数据量线性外推生产库的预计耗时：
+• --prod-rows：生产库的总行数，与样本行数相比得到倍数
+• --prod-factor：直接指定生产库数据量是测试库的多少倍
+
+外推只放大数据类型（COPY/INSERT）扣除固定开销后的耗时，结构类型按实测耗时计算，
+并按外推倍数、样本耗时、统计信息完整性给出置信度和误差范围。外推不包含目标库导入的耗时，
+规划生产窗口时请以范围上限为准并预留余量。
+
+示例：
+  ora2pg-admin 迁移 基准
+  ora2pg-admin 迁移 基准 --data-only --prod-rows 500000000
+  ora2pg-admin 迁移 基准 --prod-factor 20 --output json --save reports/benchmark.json`,
+	Args: cobra.NoArgs,
+	Run:  runMigrateBenchmark,
+}
+
+func init() {
+	migrateCmd.AddCommand(migrateBenchmarkCmd)
+
+	migrateBenchmarkCmd.Flags().StringVar(&benchmarkOutputDir, "output-dir", "", "基准测试的输出目录（默认为系统临时目录）")
+	migrateBenchmarkCmd.Flags().BoolVar(&benchmarkKeepOutput, "keep-output", false, "保留自动创建的临时目录中导出的文件")
+	migrateBenchmarkCmd.Flags().BoolVar(&benchmarkDataOnly, "data-only", false, "只测试配置的数据类型（COPY/INSERT）")
+	migrateBenchmarkCmd.Flags().Int64Var(&benchmarkProdRows, "prod-rows", 0, "生产库的总行数，用于外推生产库耗时")
+	migrateBenchmarkCmd.Flags().Float64Var(&benchmarkProdFactor, "prod-factor", 0, "生产库数据量相对测试库的倍数，用于外推生产库耗时")
+	migrateBenchmarkCmd.Flags().StringVarP(&benchmarkOutput, "output", "o", checkOutputText, "输出格式 (text, json)")
+	migrateBenchmarkCmd.Flags().StringVar(&benchmarkSave, "save", "", "报告保存路径（默认为 reports/benchmark-<时间>.json）")
+	migrateBenchmarkCmd.MarkFlagsMutuallyExclusive("prod-rows", "prod-factor")
+}
+
+// runMigrateBenchmark 执行迁移性能基准测试
+func runMigrateBenchmark(cmd *cobra.Command, args []string) {
+	logger := utils.GetGlobalLogger()
+
+	jsonOutput := false
+	switch strings.ToLower(benchmarkOutput) {
+	case checkOutputText:
+	case checkOutputJSON:
+		jsonOutput = true
+		if logger.WritesToStdout() {
+			logger.SetOutput("stderr")
+		}
+	default:
+		fmt.Printf("%s\n", utils.FormatError(utils.ConfigErrors.InvalidValue("output", benchmarkOutput)))
+		exit(1)
+	}
+	if benchmarkProdRows < 0 || benchmarkProdFactor < 0 {
+		fmt.Printf("%s\n", utils.FormatError(utils.NewError(utils.ErrorTypeValidation, "INVALID_BENCHMARK_SCALE").
+			Message("生产库数据量不能为负数").
+			Details(fmt.Sprintf("--prod-rows=%d --prod-factor=%g", benchmarkProdRows, benchmarkProdFactor)).
+			Build()))
+		exit(1)
+	}
+
+	migrationService, err := initializeMigrationService()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	outputDir, err := prepareBenchmarkService(migrationService)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	// 只删除自动创建的临时目录，不删除 --output-dir 指定的目录
+	keepOutput := benchmarkKeepOutput || benchmarkOutputDir != ""
+	if !keepOutput {
+		defer os.RemoveAll(outputDir)
+	}
+
+	types := allMigrationTypes
+	if benchmarkDataOnly {
+		if types, err = resolveTaskMigrationTypes(migrationService, service.TaskTypeData); err != nil {
+			fmt.Printf("%s\n", utils.FormatError(err))
+			exit(1)
+		}
+	}
+	if types, err = resolveMigrationOrder(types); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	ctx, cancel := createMigrationContext()
+	defer cancel()
+
+	if !jsonOutput {
+		fmt.Println("⏱️ 迁移性能基准测试")
+		fmt.Println()
+		fmt.Printf("📁 只导出到: %s（不连接目标库）\n", outputDir)
+	}
+	if migrateGatherStats {
+		gatherOracleStats(ctx, migrationService.GetConfig())
+	}
+	scaleCtx, scaleCancel := context.WithTimeout(ctx, progressScaleTimeout)
+	if scale, err := service.EstimateMigrationScale(scaleCtx, migrationService.GetConfig()); err != nil {
+		logger.Warnf("统计源库规模失败，外推只使用导出行数: %v", err)
+	} else {
+		migrationService.SetProgressScale(scale)
+	}
+	scaleCancel()
+
+	report, runErr := service.RunBenchmark(ctx, migrationService, types, func(stage *service.BenchmarkStage) {
+		if !jsonOutput {
+			fmt.Printf("   %s %s: %v\n", benchmarkStageIcon(stage), stage.Type, stage.Duration.Round(time.Millisecond))
+		}
+	})
+	if runErr != nil && len(report.Stages) == 0 {
+		fmt.Printf("%s\n", utils.FormatError(runErr))
+		exit(1)
+	}
+
+	if factor, err := benchmarkFactor(report); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+	} else if factor > 0 {
+		report.Estimate = report.Extrapolate(factor)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(fmt.Errorf("序列化基准报告失败: %v", err)))
+		exit(1)
+	}
+	if jsonOutput {
+		fmt.Println(string(data))
+	} else {
+		fmt.Println()
+		fmt.Println("📊 性能基准报告")
+		fmt.Println("─────────────────")
+		fmt.Print(report.FormatText())
+	}
+
+	savePath := benchmarkSave
+	if savePath == "" {
+		savePath = filepath.Join("reports", "benchmark-"+report.StartTime.Format("20060102-150405")+".json")
+	}
+	if err := utils.NewFileUtils().EnsureDir(filepath.Dir(savePath)); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(utils.FileErrors.CreateFailed(savePath, err)))
+		exit(1)
+	}
+	if err := os.WriteFile(savePath, append(data, '\n'), 0644); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(utils.FileErrors.WriteFailed(savePath, err)))
+		exit(1)
+	}
+	if !jsonOutput {
+		fmt.Printf("\n💾 报告已保存: %s\n", savePath)
+		if keepOutput {
+			fmt.Printf("📁 导出的文件保留在: %s\n", outputDir)
+		}
+	}
+
+	if runErr != nil {
+		fmt.Printf("%s\n", utils.FormatError(runErr))
+		exit(1)
+	}
+	logger.Info("迁移性能基准测试完成")
+}
+
+// prepareBenchmarkService 把迁移服务切换为只导出到文件，检查点等运行记录写入基准测试目录，返回输出目录
+func prepareBenchmarkService(migrationService *service.MigrationService) (string, error) {
+	outputDir := benchmarkOutputDir
+	if outputDir == "" {
+		dir, err := os.MkdirTemp("", "ora2pg-benchmark-")
+		if err != nil {
+			return "", utils.FileErrors.CreateFailed(os.TempDir(), err)
+		}
+		outputDir = dir
+	} else if err := utils.NewFileUtils().EnsureDir(outputDir); err != nil {
+		return "", utils.FileErrors.CreateFailed(outputDir, err)
+	}
+
+	// 只修改内存中的配置，不保存到配置文件
+	cfg := migrationService.GetConfig()
+	cfg.Migration.OutputDir = outputDir
+	cfg.Migration.Scripts.Disabled = true
+	cfg.Migration.Incremental = config.IncrementalConfig{}
+
+	migrationService.SetFileOnly(true)
+	migrationService.SetResume(false)
+	migrationService.SetCheckpointPath(filepath.Join(outputDir, "checkpoint.json"))
+	migrationService.SetHistoryPath(filepath.Join(outputDir, "history.json"))
+	migrationService.SetWatermarkPath(filepath.Join(outputDir, "watermarks.json"))
+	return outputDir, nil
+}
+
+// benchmarkFactor 按 --prod-rows 或 --prod-factor 确定外推倍数，都未指定时返回0
+func benchmarkFactor(report *service.BenchmarkReport) (float64, error) {
+	if benchmarkProdFactor > 0 {
+		return benchmarkProdFactor, nil
+	}
+	if benchmarkProdRows > 0 {
+		return report.FactorForRows(benchmarkProdRows)
+	}
+	return 0, nil
+}
+
+// benchmarkStageIcon 基准测试中单个类型的结果图标
+func benchmarkStageIcon(stage *service.BenchmarkStage) string {
+	if stage.Status == service.StatusCompleted {
+		return "✅"
+	}
+	return "❌"
+}
@@ -0,0 +1,377 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+// detachedRun 当前进程是后台运行的迁移时的运行记录，退出时写入退出码
+var detachedRun *service.DetachedRun
+
+var (
+	migrateDetach       bool
+	runStatusOutput     string
+	runOutputFollow     bool
+	runStopWait         time.Duration
+	runStopKill         bool
+	runOutputPollPeriod = 500 * time.Millisecond
+)
+
+// migrateStatusCmd 查看后台运行的迁移
+var migrateStatusCmd = &cobra.Command{
+	Use:   "状态 [运行ID]",
+	Short: "查看后台运行的迁移状态",
+	Long: `查看通过 --detach 在后台运行的迁移。不指定运行ID时列出全部后台运行，按开始时间倒序；
+运行ID可以只输入唯一的前缀。
+
+状态说明：
+• running：正在运行
+• completed：已完成，退出码为0
+• failed：已结束，退出码非0（含被停止的运行）
+• lost：记录为运行中但进程已不存在，如被强制杀死或机器重启
+
+示例：
+  ora2pg-admin 迁移 状态
+  ora2pg-admin 迁移 状态 20240102-020000-a1b2c3
+  ora2pg-admin 迁移 状态 20240102-020000-a1b2c3 -o json`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runMigrateStatus,
+}
+
+// migrateLogsCmd 查看后台运行的输出
+var migrateLogsCmd = &cobra.Command{
+	Use:   "日志 <运行ID>",
+	Short: "查看后台运行的迁移输出",
+	Long: `输出后台运行迁移的命令行输出（即前台运行时打印到终端的内容）。
+指定 --follow 时持续输出新内容，直到该运行结束或按 Ctrl+C。
+
+示例：
+  ora2pg-admin 迁移 日志 20240102-020000-a1b2c3
+  ora2pg-admin 迁移 日志 20240102-020000-a1b2c3 -f`,
+	Args: cobra.ExactArgs(1),
+	Run:  runMigrateLogs,
+}
+
+// migrateStopCmd 停止后台运行的迁移
+var migrateStopCmd = &cobra.Command{
+	Use:   "停止 <运行ID>",
+	Short: "停止后台运行的迁移",
+	Long: `停止后台运行的迁移。Unix 下发送 SIGTERM，迁移会像按 Ctrl+C 一样停止ora2pg、
+保存检查点并记录历史，之后可使用 --resume 继续；--kill 发送 SIGKILL 立即终止。
+Windows 下总是立即终止进程。
+
+停止后等待进程退出，最长 --wait 指定的时间。
+
+示例：
+  ora2pg-admin 迁移 停止 20240102-020000-a1b2c3
+  ora2pg-admin 迁移 停止 20240102-020000-a1b2c3 --kill`,
+	Args: cobra.ExactArgs(1),
+	Run:  runMigrateStop,
+}
+
+func init() {
+	migrateCmd.AddCommand(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateLogsCmd)
+	migrateCmd.AddCommand(migrateStopCmd)
+
+	migrateCmd.PersistentFlags().BoolVar(&migrateDetach, "detach", false, "在后台运行迁移，打印运行ID后立即返回（支持 结构/数据/全部/重试/队列）")
+	migrateStatusCmd.Flags().StringVarP(&runStatusOutput, "output", "o", checkOutputText, "输出格式 (text, json)")
+	migrateLogsCmd.Flags().BoolVarP(&runOutputFollow, "follow", "f", false, "持续输出新内容，直到运行结束")
+	migrateStopCmd.Flags().DurationVar(&runStopWait, "wait", 30*time.Second, "等待进程退出的最长时间（0表示不等待）")
+	migrateStopCmd.Flags().BoolVar(&runStopKill, "kill", false, "立即强制终止进程")
+}
+
+// detachIfRequested 指定了 --detach 时在后台重新执行当前命令并退出；当前进程是后台运行的迁移时记录运行信息
+func detachIfRequested() {
+	if run, ok := service.DetachedRunFromEnv(); ok {
+		detachedRun = run
+		// 迁移脚本等子进程不再视为后台运行的迁移
+		os.Unsetenv(service.DetachedEnv)
+		return
+	}
+	if !migrateDetach {
+		return
+	}
+
+	if !checkProjectDirectory() {
+		fmt.Printf("%s\n", utils.FormatError(utils.NewError(utils.ErrorTypeConfig, "PROJECT_NOT_INITIALIZED").
+			Message("项目未初始化").
+			Suggestion("请先使用 'ora2pg-admin 初始化 [项目名称]' 创建项目").
+			Build()))
+		exit(1)
+	}
+
+	// 已有迁移在运行时直接报错，不启动后台进程；后台进程启动后自行获取锁
+	if err := acquireMigrationLock(auditSession.command); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	releaseMigrationLock()
+
+	executable, err := os.Executable()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(utils.NewError(utils.ErrorTypeSystem, "DETACH_START_FAILED").
+			Message("无法确定当前程序路径").
+			Details(err.Error()).
+			Cause(err).
+			Build()))
+		exit(1)
+	}
+
+	run, err := service.StartDetached(service.DefaultRunsDir, executable, detachedArgs(os.Args[1:]), auditSession.command)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	fmt.Printf("🚀 迁移已在后台启动（PID %d）\n", run.PID)
+	fmt.Printf("🔖 运行ID: %s\n", run.RunID)
+	fmt.Println()
+	fmt.Printf("  查看状态: ora2pg-admin 迁移 状态 %s\n", run.RunID)
+	fmt.Printf("  查看输出: ora2pg-admin 迁移 日志 %s -f\n", run.RunID)
+	fmt.Printf("  停止迁移: ora2pg-admin 迁移 停止 %s\n", run.RunID)
+	exit(0)
+}
+
+// detachedArgs 去掉 --detach 后的命令行参数，后台进程以前台方式执行同一命令
+func detachedArgs(args []string) []string {
+	result := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "--detach" || strings.HasPrefix(arg, "--detach=") {
+			continue
+		}
+		result = append(result, arg)
+	}
+	return result
+}
+
+// finishDetachedRun 后台运行的迁移结束时记录退出码，失败时仅记录警告
+func finishDetachedRun(exitCode int) {
+	if detachedRun == nil {
+		return
+	}
+	if err := detachedRun.Finish(exitCode); err != nil {
+		utils.GetGlobalLogger().Warnf("更新后台运行记录失败: %v", err)
+	}
+	detachedRun = nil
+}
+
+// detachedProgressHandler 后台运行时把进度记录到运行记录，前台运行时返回 nil
+func detachedProgressHandler(taskName string, totalSteps int) func(service.ProgressUpdate) {
+	if detachedRun == nil {
+		return nil
+	}
+	return detachedRun.ProgressHandler(taskName, totalSteps)
+}
+
+// runMigrateStatus 查看后台运行的迁移
+func runMigrateStatus(cmd *cobra.Command, args []string) {
+	jsonOutput := false
+	switch strings.ToLower(runStatusOutput) {
+	case checkOutputText:
+	case checkOutputJSON:
+		jsonOutput = true
+	default:
+		fmt.Printf("%s\n", utils.FormatError(utils.ConfigErrors.InvalidValue("output", runStatusOutput)))
+		exit(1)
+	}
+
+	var runs []*service.DetachedRun
+	if len(args) == 1 {
+		run, err := service.LoadDetachedRun(service.DefaultRunsDir, args[0])
+		if err != nil {
+			fmt.Printf("%s\n", utils.FormatError(err))
+			exit(1)
+		}
+		runs = append(runs, run)
+	} else {
+		list, err := service.ListDetachedRuns(service.DefaultRunsDir)
+		if err != nil {
+			fmt.Printf("%s\n", utils.FormatError(err))
+			exit(1)
+		}
+		runs = list
+	}
+
+	if jsonOutput {
+		// 输出检测后的实际状态，如进程已不存在时的 lost
+		for _, run := range runs {
+			run.Status = run.State()
+		}
+		var value interface{} = runs
+		if len(args) == 1 {
+			value = runs[0]
+		}
+		data, _ := json.MarshalIndent(value, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	if len(runs) == 0 {
+		fmt.Println("📭 没有后台运行的迁移")
+		fmt.Println("💡 使用 --detach 在后台运行迁移，如: ora2pg-admin 迁移 全部 --detach")
+		return
+	}
+	if len(args) == 1 {
+		showDetachedRun(runs[0])
+		return
+	}
+
+	fmt.Println("🗂️ 后台运行的迁移")
+	fmt.Println()
+	for _, run := range runs {
+		line := fmt.Sprintf("%s %-24s %-10s %s  %s", runStateIcon(run), run.RunID, run.State(),
+			run.StartedAt.Format("2006-01-02 15:04:05"), run.Command)
+		if run.Alive() && run.Progress != nil {
+			line += fmt.Sprintf("  [%.1f%% %s]", run.Progress.Percentage, run.Progress.Message)
+		}
+		fmt.Println(line)
+	}
+}
+
+// showDetachedRun 显示单个后台运行的详情
+func showDetachedRun(run *service.DetachedRun) {
+	state := run.State()
+	fmt.Printf("%s 运行ID: %s\n", runStateIcon(run), run.RunID)
+	fmt.Printf("   状态: %s\n", state)
+	fmt.Printf("   命令: %s\n", run.Command)
+	fmt.Printf("   参数: %s\n", strings.Join(run.Args, " "))
+	fmt.Printf("   进程: PID %d @ %s\n", run.PID, run.Host)
+	fmt.Printf("   开始: %s\n", run.StartedAt.Format("2006-01-02 15:04:05"))
+	if run.EndedAt != nil {
+		fmt.Printf("   结束: %s（耗时 %s）\n", run.EndedAt.Format("2006-01-02 15:04:05"),
+			run.EndedAt.Sub(run.StartedAt).Round(time.Second))
+	} else if state == service.RunStateRunning {
+		fmt.Printf("   已运行: %s\n", time.Since(run.StartedAt).Round(time.Second))
+	}
+	if run.ExitCode != nil {
+		fmt.Printf("   退出码: %d\n", *run.ExitCode)
+	}
+	if run.StopRequested {
+		fmt.Println("   已通过停止命令请求终止")
+	}
+	if run.Progress != nil {
+		fmt.Printf("   进度: %s %.1f%%（步骤 %d/%d）%s\n", run.Progress.Task, run.Progress.Percentage,
+			run.Progress.Step, run.Progress.TotalSteps, run.Progress.Message)
+		fmt.Printf("   进度更新于: %s\n", run.Progress.UpdatedAt.Format("2006-01-02 15:04:05"))
+	}
+	fmt.Printf("   输出日志: %s\n", run.LogFile)
+	if state == service.RunStateLost {
+		fmt.Println()
+		fmt.Println("💡 进程已不存在但未记录结束状态，可能被强制终止；查看输出日志确认，必要时使用 --resume 重新执行")
+	}
+}
+
+// runStateIcon 运行状态的图标
+func runStateIcon(run *service.DetachedRun) string {
+	switch run.State() {
+	case service.RunStateRunning:
+		return "⏳"
+	case service.RunStateCompleted:
+		return "✅"
+	case service.RunStateLost:
+		return "❓"
+	default:
+		if run.StopRequested {
+			return "⏹️"
+		}
+		return "❌"
+	}
+}
+
+// runMigrateLogs 输出后台运行的命令行输出
+func runMigrateLogs(cmd *cobra.Command, args []string) {
+	run, err := service.LoadDetachedRun(service.DefaultRunsDir, args[0])
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	file, err := os.Open(run.LogFile)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(utils.FileErrors.ReadFailed(run.LogFile, err)))
+		exit(1)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(os.Stdout, file); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(utils.FileErrors.ReadFailed(run.LogFile, err)))
+		exit(1)
+	}
+	if !runOutputFollow {
+		return
+	}
+
+	ctx, cancel := createInterruptContext()
+	defer cancel()
+	followDetachedLog(ctx, run, file)
+}
+
+// followDetachedLog 持续输出新内容，直到运行结束或上下文取消
+func followDetachedLog(ctx context.Context, run *service.DetachedRun, file *os.File) {
+	ticker := time.NewTicker(runOutputPollPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		io.Copy(os.Stdout, file)
+
+		latest, err := service.LoadDetachedRun(service.DefaultRunsDir, run.RunID)
+		if err != nil || !latest.Alive() {
+			// 输出结束前最后写入的内容
+			io.Copy(os.Stdout, file)
+			if err == nil {
+				fmt.Printf("\n📌 后台迁移已结束，状态: %s\n", latest.State())
+			}
+			return
+		}
+	}
+}
+
+// runMigrateStop 停止后台运行的迁移
+func runMigrateStop(cmd *cobra.Command, args []string) {
+	run, err := service.LoadDetachedRun(service.DefaultRunsDir, args[0])
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	if err := run.Stop(runStopKill); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	if runStopKill {
+		fmt.Printf("⏹️ 已强制终止后台迁移 %s（PID %d）\n", run.RunID, run.PID)
+	} else {
+		fmt.Printf("⏹️ 已请求停止后台迁移 %s（PID %d）\n", run.RunID, run.PID)
+	}
+	if runStopWait <= 0 {
+		return
+	}
+
+	fmt.Printf("⏳ 等待进程退出（最长 %s）...\n", runStopWait)
+	deadline := time.Now().Add(runStopWait)
+	for time.Now().Before(deadline) {
+		latest, err := service.LoadDetachedRun(service.DefaultRunsDir, run.RunID)
+		if err != nil || !latest.Alive() {
+			fmt.Println("✅ 后台迁移已停止")
+			return
+		}
+		time.Sleep(runOutputPollPeriod)
+	}
+	fmt.Printf("%s\n", utils.FormatError(utils.NewError(utils.ErrorTypeSystem, "RUN_STOP_TIMEOUT").
+		Message(fmt.Sprintf("后台迁移在 %s 内没有退出", runStopWait)).
+		Suggestion(fmt.Sprintf("稍后运行 'ora2pg-admin 迁移 状态 %s' 查看，或使用 --kill 强制终止", run.RunID)).
+		Build()))
+	exit(1)
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+// dryRunPlanFile 预演结束后保存差异报告的文件，位于输出目录
+const dryRunPlanFile = "dry-run-plan.json"
+
+// metadataDryRunKey 预演在迁移元数据中的键
+const metadataDryRunKey = "dry_run"
+
+var (
+	migrateDiffOutput string
+	migrateDiffAll    bool
+)
+
+// migrateDiffCmd 对比生成的SQL与目标库现状
+var migrateDiffCmd = &cobra.Command{
+	Use:   "差异 [文件或目录...]",
+	Short: "对比生成的SQL与目标库现状，预览应用后的变化",
+	Long: `解析ora2pg生成的SQL，与目标库中已有的对象对比，报告应用后将新增、修改和无变化的对象，
+类似数据库迁移工具的 plan。未指定路径时对比输出目录下的全部 .sql 文件。
+
+表比较列名和类型（类型按PostgreSQL的写法规范化，如 varchar(100) 与 character varying(100) 相同），
+其他对象只比较名称：已存在且为 CREATE OR REPLACE 时视为修改，否则视为无变化。
+同时统计 COPY、INSERT 导入的数据行数，以及先删除再重建、导入前清空的表。
+
+在迁移命令上加 --dry-run 时只生成SQL不写入目标库，结束后自动输出该报告。
+
+示例：
+  ora2pg-admin 迁移 差异
+  ora2pg-admin 迁移 差异 output/TABLE_output.sql --all
+  ora2pg-admin 迁移 结构 --dry-run
+  ora2pg-admin 迁移 差异 -o json`,
+	Run: runMigrateDiff,
+}
+
+func init() {
+	migrateCmd.AddCommand(migrateDiffCmd)
+
+	migrateDiffCmd.Flags().StringVarP(&migrateDiffOutput, "output", "o", checkOutputText, "输出格式 (text, json)")
+	migrateDiffCmd.Flags().BoolVar(&migrateDiffAll, "all", false, "同时列出无变化的对象")
+}
+
+// runMigrateDiff 对比生成的SQL与目标库现状
+func runMigrateDiff(cmd *cobra.Command, args []string) {
+	jsonOutput := strings.EqualFold(migrateDiffOutput, checkOutputJSON)
+	if !jsonOutput && !strings.EqualFold(migrateDiffOutput, checkOutputText) {
+		fmt.Printf("%s\n", utils.FormatError(utils.ConfigErrors.InvalidValue("output", migrateDiffOutput)))
+		exit(1)
+	}
+
+	manager, _, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	cfg := manager.GetConfig()
+	paths := args
+	if len(paths) == 0 {
+		paths = []string{cfg.Migration.OutputDir}
+	}
+	files, err := service.FindSQLFiles(paths)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	if len(files) == 0 {
+		fmt.Printf("💡 %s 中没有SQL文件，请先执行迁移（可加 --dry-run 只生成SQL）\n", strings.Join(paths, ", "))
+		return
+	}
+
+	report, err := service.PlanTargetChanges(context.Background(), cfg, files)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	if jsonOutput {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Printf("%s\n", utils.FormatError(err))
+			exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+	printTargetDiff(report, migrateDiffAll)
+}
+
+// applyDryRun 预演模式只导出到文件，不向目标库写入
+func applyDryRun(migrationService *service.MigrationService, metadata map[string]string) error {
+	if !dryRun {
+		return nil
+	}
+	if migrateIncremental {
+		// 增量同步导出后会推进水位，预演没有导入数据，之后的正式同步会漏掉这部分数据
+		return utils.NewError(utils.ErrorTypeUser, "DRY_RUN_INCREMENTAL_UNSUPPORTED").
+			Message("增量同步不支持 --dry-run").
+			Details("增量同步完成后会推进水位，预演没有导入的数据在之后的同步中不会再导出").
+			Suggestion("去掉 --incremental 预演全量迁移，或去掉 --dry-run 正式同步").
+			Build()
+	}
+	migrationService.SetFileOnly(true)
+	metadata[metadataDryRunKey] = "true"
+	fmt.Println("🧪 预演模式：只生成SQL，不写入目标库，结束后对比目标库现状")
+	return nil
+}
+
+// showDryRunDiff 预演结束后对比生成的SQL与目标库现状并保存报告，失败时仅提示
+func showDryRunDiff(ctx context.Context, migrationService *service.MigrationService) {
+	cfg := migrationService.GetConfig()
+	files, err := service.FindSQLFiles([]string{cfg.Migration.OutputDir})
+	if err != nil || len(files) == 0 {
+		if err != nil {
+			fmt.Printf("⚠️ 生成差异报告失败:\n%s\n", utils.FormatError(err))
+		}
+		return
+	}
+
+	fmt.Println()
+	report, err := service.PlanTargetChanges(ctx, cfg, files)
+	if err != nil {
+		fmt.Printf("⚠️ 生成差异报告失败:\n%s\n", utils.FormatError(err))
+		return
+	}
+	printTargetDiff(report, false)
+
+	path := filepath.Join(cfg.Migration.OutputDir, dryRunPlanFile)
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err == nil {
+		err = os.WriteFile(path, data, 0644)
+	}
+	if err != nil {
+		fmt.Printf("⚠️ 保存差异报告失败: %v\n", err)
+		return
+	}
+	fmt.Printf("📝 差异报告已保存: %s\n", path)
+}
+
+// printTargetDiff 显示差异报告，all 为 true 时同时列出无变化的对象
+func printTargetDiff(report *service.TargetDiffReport, all bool) {
+	fmt.Printf("🧭 目标模式 %s 的变化预览（%d 个文件，%d 条语句）\n", report.Schema, report.Files, report.Statements)
+	fmt.Printf("   %s\n", report.Summary())
+	for _, line := range report.KindSummaries() {
+		fmt.Printf("   • %s\n", line)
+	}
+
+	sections := []struct {
+		action string
+		title  string
+	}{
+		{service.TargetDiffCreate, "➕ 新增"},
+		{service.TargetDiffModify, "✏️ 修改"},
+		{service.TargetDiffUnchanged, "➖ 无变化"},
+	}
+	for _, section := range sections {
+		if section.action == service.TargetDiffUnchanged && !all {
+			continue
+		}
+		var objects []service.TargetObjectDiff
+		for _, object := range report.Objects {
+			if object.Action == section.action {
+				objects = append(objects, object)
+			}
+		}
+		if len(objects) == 0 {
+			continue
+		}
+		fmt.Println()
+		fmt.Printf("%s（%d）:\n", section.title, len(objects))
+		for _, object := range objects {
+			fmt.Printf("   %s %s  %s:%d\n", service.TargetKindLabel(object.Kind), object.Name, object.File, object.Line)
+			for _, change := range object.Changes {
+				fmt.Printf("     - %s\n", change)
+			}
+			if object.Recreate {
+				fmt.Println("     ⚠️ 先删除再重建，现有数据会丢失")
+			}
+		}
+	}
+
+	if len(report.Data) > 0 {
+		var rows int64
+		for _, load := range report.Data {
+			rows += load.Rows
+		}
+		fmt.Println()
+		fmt.Printf("📦 数据: 将向 %d 张表导入 %d 行\n", len(report.Data), rows)
+		for _, load := range report.Data {
+			switch {
+			case !load.Exists:
+				fmt.Printf("   ⚠️ 目标库中没有表 %s，生成的SQL中也没有建表语句，导入会失败\n", load.Table)
+			case load.Truncate:
+				fmt.Printf("   ⚠️ 导入 %s 前会清空表中现有的数据\n", load.Table)
+			}
+		}
+	}
+
+	if len(report.TargetOnly) > 0 {
+		shown := report.TargetOnly
+		if len(shown) > 10 {
+			shown = shown[:10]
+		}
+		fmt.Println()
+		fmt.Printf("ℹ️ 目标模式中另有 %d 张表不在生成的SQL中（不受影响）: %s", len(report.TargetOnly), strings.Join(shown, ", "))
+		if len(report.TargetOnly) > len(shown) {
+			fmt.Print(" ...")
+		}
+		fmt.Println()
+	}
+	if !report.HasChanges() {
+		fmt.Println()
+		fmt.Println("✅ 应用生成的SQL不会改变目标库中的对象")
+	}
+}
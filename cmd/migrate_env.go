@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+// metadataEnvKey 运行环境在迁移元数据中的键
+const metadataEnvKey = "env"
+
+var (
+	migrateEnv            string
+	migrateAllowDangerous bool
+)
+
+func init() {
+	migrateCmd.PersistentFlags().StringVar(&migrateEnv, "env", "", "运行环境 (test, prod)，按配置文件 run_environments 应用对应的迁移策略，默认使用 run_environments.default")
+	migrateCmd.PersistentFlags().BoolVar(&migrateAllowDangerous, "allow-dangerous", false, "非交互运行时确认在生产环境执行危险操作（--idempotent、--archive-clean、defer_constraints）")
+}
+
+// applyRunEnvironment 按运行环境策略检查目标库、调整并行度、备份输出并确认危险操作
+//
+// 未指定 --env 且未配置 run_environments.default 时返回nil，行为与之前相同。
+func applyRunEnvironment(migrationService *service.MigrationService, metadata map[string]string) (*config.RunEnvironment, error) {
+	cfg := migrationService.GetConfig()
+	environments := &cfg.RunEnvironments
+	env, err := environments.Resolve(migrateEnv)
+	if err != nil {
+		return nil, utils.NewError(utils.ErrorTypeValidation, "RUN_ENV_INVALID").
+			Message("无效的运行环境").
+			Cause(err).
+			Suggestion("使用 --env test 或 --env prod，或修改配置文件中的 run_environments.default").
+			Build()
+	}
+	if env == nil {
+		return nil, nil
+	}
+
+	fmt.Printf("🏷️ 运行环境: %s\n", env.DisplayName())
+	for _, line := range env.Summary() {
+		fmt.Printf("   • %s\n", line)
+	}
+
+	// 防止以测试环境的参数连接生产库，或反之
+	if err := environments.CheckTarget(env, cfg.PostgreSQL.Host); err != nil {
+		return nil, utils.NewError(utils.ErrorTypeValidation, "RUN_ENV_TARGET_MISMATCH").
+			Message("目标库与运行环境不匹配").
+			Cause(err).
+			Suggestion("确认 --env 与配置文件中的 postgresql.host 是否正确，或修改 run_environments 中的 postgresql_hosts").
+			Build()
+	}
+
+	jobs, capped, err := env.ParallelJobsFor(cfg.Migration.ParallelJobs, migrateParallel)
+	if err != nil {
+		return nil, utils.NewError(utils.ErrorTypeValidation, "RUN_ENV_PARALLEL_EXCEEDED").
+			Message("并行作业数超过运行环境的上限").
+			Cause(err).
+			Suggestion(fmt.Sprintf("降低 --parallel，或修改 run_environments.%s.max_parallel_jobs", env.Name)).
+			Build()
+	}
+	if capped {
+		fmt.Printf("⚙️ 并行作业数已降为 %s 环境的上限 %d\n", env.Name, jobs)
+	}
+	migrationService.SetParallelJobs(jobs)
+
+	backup := env.Backup
+	if migrateCmd.PersistentFlags().Changed("backup") {
+		if env.Backup && !migrateBackup && env.IsProd() {
+			return nil, utils.NewError(utils.ErrorTypeUser, "RUN_ENV_BACKUP_REQUIRED").
+				Message("生产环境不能关闭迁移前备份").
+				Details("--backup=false 与 prod 环境策略冲突").
+				Suggestion("去掉 --backup=false；确实不需要备份时请使用 --env test").
+				Build()
+		}
+		backup = migrateBackup
+	}
+
+	if env.ConfirmDangerous {
+		if err := confirmRunEnvironment(env, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	// 幂等模式会自行归档并清理上次的输出
+	if backup && !migrateIdempotent {
+		if err := backupPreviousOutput(migrationService); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, ok := metadata[metadataEnvKey]; !ok {
+		metadata[metadataEnvKey] = env.Name
+	}
+	return env, nil
+}
+
+// confirmRunEnvironment 迁移开始前确认目标库，并单独确认本次启用的危险操作
+func confirmRunEnvironment(env *config.RunEnvironment, cfg *config.ProjectConfig) error {
+	target := fmt.Sprintf("%s:%d/%s", cfg.PostgreSQL.Host, cfg.PostgreSQL.Port, cfg.PostgreSQL.Database)
+	if !utils.ConfirmDangerous(fmt.Sprintf("即将在%s环境迁移到目标库 %s，确认继续", env.DisplayName(), target), env.Name+" 环境迁移: "+target) {
+		return runEnvironmentCancelled()
+	}
+
+	operations := dangerousOperations(cfg)
+	if len(operations) == 0 {
+		return nil
+	}
+	fmt.Printf("⚠️ 本次迁移包含危险操作: %s\n", strings.Join(operations, "、"))
+	if utils.IsInteractive() {
+		if !utils.AskYesNo(fmt.Sprintf("确认在%s环境执行以上危险操作", env.DisplayName())) {
+			return runEnvironmentCancelled()
+		}
+	} else if !migrateAllowDangerous {
+		return utils.NewError(utils.ErrorTypeUser, "RUN_ENV_DANGEROUS_NOT_CONFIRMED").
+			Message(fmt.Sprintf("%s环境的危险操作未确认", env.DisplayName())).
+			Details(strings.Join(operations, "\n")).
+			Suggestion("在终端中交互运行以确认，或加 --allow-dangerous 明确允许").
+			Build()
+	}
+	utils.GetGlobalLogger().Warnf("已确认在 %s 环境执行危险操作: %s", env.Name, strings.Join(operations, ", "))
+	return nil
+}
+
+// dangerousOperations 本次迁移启用的会删除或覆盖目标库对象、清理输出文件的操作
+func dangerousOperations(cfg *config.ProjectConfig) []string {
+	var operations []string
+	if migrateIdempotent {
+		operations = append(operations, "--idempotent（删除重建对象、清空目标表）")
+	}
+	if migrateArchive && migrateArchiveClean {
+		operations = append(operations, "--archive-clean（归档后清理输出目录）")
+	}
+	if cfg.Migration.DeferConstraints {
+		operations = append(operations, "defer_constraints（迁移期间删除目标库外键、禁用触发器）")
+	}
+	return operations
+}
+
+// runEnvironmentCancelled 用户取消迁移的错误
+func runEnvironmentCancelled() error {
+	return utils.NewError(utils.ErrorTypeUser, "RUN_ENV_CANCELLED").
+		Message("用户取消了迁移").
+		Build()
+}
+
+// backupPreviousOutput 迁移前归档输出目录中上次的输出，目录为空时跳过
+func backupPreviousOutput(migrationService *service.MigrationService) error {
+	entries, err := os.ReadDir(migrationService.GetConfig().Migration.OutputDir)
+	if err != nil || len(entries) == 0 {
+		return nil
+	}
+	archivePath, count, err := migrationService.ArchiveOutput(false)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("💾 已备份上次的输出 %d 个文件: %s\n", count, archivePath)
+	return nil
+}
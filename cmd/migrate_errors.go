@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+var migrateExportErrors string
+
+func init() {
+	migrateCmd.PersistentFlags().StringVar(&migrateExportErrors, "export-errors", "", "迁移结束后把错误导出为结构化JSON文件，供工单系统导入（如 errors.json）")
+}
+
+// exportMigrationErrors 指定了 --export-errors 时写入错误报告，没有错误时也写入空列表，失败时仅提示
+func exportMigrationErrors(report *service.ErrorReport) {
+	if migrateExportErrors == "" {
+		return
+	}
+	if err := service.WriteErrorReport(migrateExportErrors, report); err != nil {
+		fmt.Printf("⚠️ 导出迁移错误失败:\n%s\n", utils.FormatError(err))
+		return
+	}
+	if len(report.Errors) == 0 {
+		fmt.Printf("📤 本次迁移没有错误，已写入空的错误报告: %s\n", migrateExportErrors)
+		return
+	}
+	occurrences := 0
+	for _, item := range report.Errors {
+		occurrences += item.Occurrences
+	}
+	fmt.Printf("📤 已导出 %d 类错误（共出现 %d 次）到: %s\n", len(report.Errors), occurrences, migrateExportErrors)
+}
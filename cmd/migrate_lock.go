@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+// migrationLock 当前进程持有的迁移锁，命令结束或调用 exit 时释放
+var migrationLock *service.MigrationLock
+
+// acquireMigrationLock 获取项目的迁移锁，已有迁移在运行时返回错误；同一进程内重复调用（如任务队列）只获取一次
+func acquireMigrationLock(command string) error {
+	if migrationLock != nil {
+		return nil
+	}
+	lock, err := service.AcquireMigrationLock(service.DefaultLockPath, command, migrateForce)
+	if err != nil {
+		return err
+	}
+	if lock.Replaced != nil {
+		if lock.Forced {
+			fmt.Printf("⚠️ 已强制获取迁移锁，替换了 %s\n", lock.Replaced)
+		} else {
+			fmt.Printf("🧹 清理了陈旧的迁移锁（%s，进程已退出）\n", lock.Replaced)
+		}
+	}
+	migrationLock = lock
+	return nil
+}
+
+// releaseMigrationLock 释放当前进程持有的迁移锁，失败时仅记录警告
+func releaseMigrationLock() {
+	if migrationLock == nil {
+		return
+	}
+	if err := migrationLock.Release(); err != nil {
+		utils.GetGlobalLogger().Warnf("释放迁移锁失败: %v", err)
+	}
+	migrationLock = nil
+}
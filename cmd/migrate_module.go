@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+var (
+	moduleTask     string
+	moduleWithDeps bool
+)
+
+// migrateModuleCmd 按业务模块迁移命令
+var migrateModuleCmd = &cobra.Command{
+	Use:   "模块 [模块名...]",
+	Short: "只迁移配置中指定业务模块的对象",
+	Long: `按 migration.groups 中定义的业务模块迁移，每个模块只迁移其中列出的对象，便于分模块迁移验证、分阶段上线。
+
+模块配置示例：
+  migration:
+    groups:
+      用户: [USERS, USER_ROLES]
+      订单: [ORDERS, ORDER_ITEMS]
+    group_dependencies:
+      订单: [用户]      # 订单模块依赖用户模块
+
+多个模块按依赖排序后依次执行，每个模块内按迁移类型的依赖顺序执行；某个模块失败时不再执行后续模块。
+依赖的模块不在本次计划中时需要已成功迁移过，指定 --with-deps 时一并迁移依赖的模块。
+每个模块的检查点单独保存，--resume 只续传该模块。不指定模块名时显示各模块的迁移进度。
+
+示例：
+  ora2pg-admin 迁移 模块
+  ora2pg-admin 迁移 模块 订单 --with-deps
+  ora2pg-admin 迁移 模块 用户 订单 --task 结构`,
+	Run: runMigrateModule,
+}
+
+func init() {
+	migrateCmd.AddCommand(migrateModuleCmd)
+
+	migrateModuleCmd.Flags().StringVar(&moduleTask, "task", service.TaskTypeAll, "每个模块执行的任务：结构、数据、全部（或 structure、data、all）")
+	migrateModuleCmd.Flags().BoolVar(&moduleWithDeps, "with-deps", false, "同时迁移依赖的模块（含间接依赖）")
+}
+
+// runMigrateModule 按模块执行迁移，未指定模块时显示模块进度
+func runMigrateModule(cmd *cobra.Command, args []string) {
+	if len(args) == 0 {
+		showModuleProgress()
+		return
+	}
+
+	detachIfRequested()
+
+	fmt.Println("🧩 按模块迁移")
+	fmt.Println()
+
+	taskType, err := service.NormalizeTaskType(moduleTask)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	manager, _, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	migration := &manager.GetConfig().Migration
+	plan, err := planModules(migration, args)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	fmt.Printf("📋 执行顺序: %s（每个模块执行%s）\n", strings.Join(plan, " → "), taskDisplayNames[taskType])
+	for _, module := range plan {
+		fmt.Printf("   • %s: %s\n", module, strings.Join(migration.Groups[module], ", "))
+	}
+
+	if err := waitForSchedule(); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	ctx, cancel := createInterruptContext()
+	defer cancel()
+
+	outcomes := make([]moduleOutcome, 0, len(plan))
+	for _, module := range plan {
+		if ctx.Err() != nil {
+			break
+		}
+		outcome := runModule(ctx, module, taskType)
+		outcomes = append(outcomes, outcome)
+		if !outcome.succeeded() {
+			break
+		}
+	}
+	if !printModuleSummary(plan, outcomes) {
+		exit(1)
+	}
+}
+
+// planModules 确定模块的执行顺序，并确认不在计划中的依赖模块已经成功迁移
+func planModules(migration *config.MigrationConfig, modules []string) ([]string, error) {
+	if len(migration.Groups) == 0 {
+		return nil, utils.NewError(utils.ErrorTypeConfig, "MODULES_NOT_CONFIGURED").
+			Message("未配置业务模块").
+			Suggestion("在配置文件的 migration.groups 中定义模块名及其包含的对象").
+			Build()
+	}
+	plan, external, err := migration.GroupPlan(modules, moduleWithDeps)
+	if err != nil {
+		return nil, utils.NewError(utils.ErrorTypeConfig, "MODULE_PLAN_INVALID").
+			Message("无法确定模块的执行顺序").
+			Details(err.Error()).
+			Suggestion("检查 migration.groups 和 migration.group_dependencies").
+			Build()
+	}
+	if len(external) == 0 {
+		return plan, nil
+	}
+
+	progress, err := service.LoadModuleProgress(service.DefaultHistoryPath, migration)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, module := range progress {
+		for _, dependency := range external {
+			if module.Name == dependency && !module.Completed() {
+				missing = append(missing, dependency)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return nil, utils.NewError(utils.ErrorTypeUser, "MODULE_DEPENDENCY_NOT_MIGRATED").
+			Message("依赖的模块尚未成功迁移").
+			Details(fmt.Sprintf("未迁移的依赖模块: %s", strings.Join(missing, ", "))).
+			Suggestion("先迁移这些模块，或指定 --with-deps 一并迁移").
+			Build()
+	}
+	fmt.Printf("🔗 依赖的模块已迁移: %s\n", strings.Join(external, ", "))
+	return plan, nil
+}
+
+// moduleOutcome 单个模块的执行结果
+type moduleOutcome struct {
+	module   string
+	results  []*service.ExecutionResult
+	err      error
+	duration time.Duration
+}
+
+// succeeded 模块的全部迁移类型是否成功
+func (o moduleOutcome) succeeded() bool {
+	return o.err == nil && migrationExitCode(o.results) == 0
+}
+
+// runModule 执行单个模块的迁移，每个模块使用独立的迁移服务和超时
+func runModule(ctx context.Context, module, taskType string) moduleOutcome {
+	startTime := time.Now()
+	outcome := moduleOutcome{module: module}
+	taskName := fmt.Sprintf("模块 %s %s", module, taskDisplayNames[taskType])
+
+	fmt.Println()
+	fmt.Printf("▶️ %s\n", taskName)
+	fmt.Println("─────────────────")
+
+	migrationService, err := initializeMigrationService()
+	if err == nil {
+		err = migrationService.SetModule(module)
+	}
+	var migrationTypes []service.MigrationType
+	if err == nil {
+		migrationTypes, err = resolveTaskMigrationTypes(migrationService, taskType)
+	}
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		outcome.err = err
+		outcome.duration = time.Since(startTime)
+		return outcome
+	}
+
+	moduleCtx, moduleCancel := context.WithTimeout(ctx, migrateTimeout)
+	defer moduleCancel()
+
+	outcome.results, outcome.err = executeMigrationWithProgress(moduleCtx, migrationService, migrationTypes, taskName)
+	outcome.duration = time.Since(startTime)
+	if outcome.err != nil {
+		fmt.Printf("%s\n", utils.FormatError(outcome.err))
+		return outcome
+	}
+	showMigrationResults(outcome.results, taskName, migrationService.GetState().Metadata)
+	return outcome
+}
+
+// printModuleSummary 显示各模块的执行结果，全部成功时返回true
+func printModuleSummary(plan []string, outcomes []moduleOutcome) bool {
+	fmt.Println()
+	fmt.Println("📊 模块迁移结果")
+	fmt.Println("─────────────────")
+
+	success := len(outcomes) == len(plan)
+	for i, module := range plan {
+		if i >= len(outcomes) {
+			fmt.Printf("⏭️ %d. %s: 未执行\n", i+1, module)
+			continue
+		}
+		outcome := outcomes[i]
+		duration := outcome.duration.Truncate(time.Second)
+		if outcome.succeeded() {
+			fmt.Printf("✅ %d. %s: 完成（耗时 %v）\n", i+1, module, duration)
+			continue
+		}
+		success = false
+		fmt.Printf("❌ %d. %s: 失败（耗时 %v）\n", i+1, module, duration)
+	}
+	if !success {
+		fmt.Println("💡 修复后使用 'ora2pg-admin 迁移 重试' 重试失败模块的失败类型，或重新执行未完成的模块")
+	}
+	return success
+}
+
+// showModuleProgress 显示各模块在迁移历史中的进度
+func showModuleProgress() {
+	fmt.Println("🧩 模块迁移进度")
+	fmt.Println("─────────────────")
+
+	manager, _, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	migration := &manager.GetConfig().Migration
+	if len(migration.Groups) == 0 {
+		fmt.Println("未配置业务模块，可在配置文件的 migration.groups 中定义")
+		return
+	}
+	progress, err := service.LoadModuleProgress(service.DefaultHistoryPath, migration)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	completed := 0
+	for _, module := range progress {
+		deps := ""
+		if len(module.Dependencies) > 0 {
+			deps = "，依赖 " + strings.Join(module.Dependencies, ", ")
+		}
+		switch {
+		case module.Last == nil:
+			fmt.Printf("⬜ %s（%d 个对象%s）: 未迁移\n", module.Name, module.Objects, deps)
+			continue
+		case module.Last.Status == service.StatusCompleted:
+			fmt.Printf("✅ %s（%d 个对象%s）: 已完成\n", module.Name, module.Objects, deps)
+		case module.Last.Status == service.StatusCancelled:
+			fmt.Printf("⚠️ %s（%d 个对象%s）: 最近一次已取消\n", module.Name, module.Objects, deps)
+		default:
+			fmt.Printf("❌ %s（%d 个对象%s）: 最近一次失败\n", module.Name, module.Objects, deps)
+		}
+		fmt.Printf("   最近运行: %s %s（运行ID %s，耗时 %v，共 %d 次）\n", module.Last.StartTime.Format("2006-01-02 15:04:05"),
+			module.Last.Task, module.Last.RunID, module.Last.Duration.Truncate(time.Second), module.Runs)
+		if module.Completed() {
+			completed++
+		}
+	}
+	fmt.Println()
+	fmt.Printf("总计: %d/%d 个模块已成功迁移\n", completed, len(progress))
+}
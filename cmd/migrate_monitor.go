@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+var (
+	monitorFollow     bool
+	monitorOutput     string
+	monitorPollPeriod = time.Second
+)
+
+// migrateMonitorCmd 从进度状态文件查看正在执行的迁移进度
+var migrateMonitorCmd = &cobra.Command{
+	Use:   "监控",
+	Short: "查看正在执行的迁移进度",
+	Long: `读取迁移进程定期写入的进度状态文件（.ora2pg-admin/progress.json），显示当前或最近一次迁移的进度。
+
+进度只从文件读取，不依赖迁移进程的内存状态：监控端可随时退出、重启，重新执行即可恢复显示；
+前台和 --detach 后台运行的迁移都会写入该文件。指定 --follow 时持续刷新，直到迁移结束或按 Ctrl+C。
+
+示例：
+  ora2pg-admin 迁移 监控
+  ora2pg-admin 迁移 监控 -f
+  ora2pg-admin 迁移 监控 -o json`,
+	Args: cobra.NoArgs,
+	Run:  runMigrateMonitor,
+}
+
+func init() {
+	migrateCmd.AddCommand(migrateMonitorCmd)
+
+	migrateMonitorCmd.Flags().BoolVarP(&monitorFollow, "follow", "f", false, "持续刷新进度，直到迁移结束")
+	migrateMonitorCmd.Flags().StringVarP(&monitorOutput, "output", "o", checkOutputText, "输出格式 (text, json)")
+}
+
+// runMigrateMonitor 显示进度状态文件中的迁移进度
+func runMigrateMonitor(cmd *cobra.Command, args []string) {
+	jsonOutput := strings.EqualFold(monitorOutput, checkOutputJSON)
+	if !jsonOutput && !strings.EqualFold(monitorOutput, checkOutputText) {
+		fmt.Printf("%s\n", utils.FormatError(utils.ConfigErrors.InvalidValue("output", monitorOutput)))
+		exit(1)
+	}
+
+	state, err := service.LoadProgressState(service.DefaultProgressStatePath)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	if jsonOutput {
+		data, _ := json.MarshalIndent(state, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	showProgressState(state)
+	if !monitorFollow || state.State() != service.RunStateRunning {
+		return
+	}
+
+	ctx, cancel := createInterruptContext()
+	defer cancel()
+	followProgressState(ctx, state)
+}
+
+// showProgressState 显示迁移进度详情
+func showProgressState(state *service.ProgressState) {
+	fmt.Printf("%s %s\n", progressStateIcon(state.State()), state.Task)
+	fmt.Printf("   运行ID: %s（PID %d @ %s）\n", state.RunID, state.PID, state.Host)
+	fmt.Printf("   状态: %s\n", state.State())
+	elapsedLabel := "耗时"
+	if state.Running {
+		elapsedLabel = "已运行"
+	}
+	fmt.Printf("   开始: %s，%s %v\n", state.StartedAt.Format("2006-01-02 15:04:05"), elapsedLabel, state.Elapsed().Round(time.Second))
+	fmt.Printf("   %s\n", progressStateLine(state))
+	if migration := state.Migration; migration != nil && len(migration.Types) > 0 {
+		var types []string
+		for _, progress := range migration.Types {
+			types = append(types, fmt.Sprintf("%s %s", progress.Type, typeProgressIcon(progress.Status)))
+		}
+		fmt.Printf("   已执行: %s\n", strings.Join(types, "  "))
+	}
+	if state.Resources != "" {
+		fmt.Printf("   资源: %s\n", state.Resources)
+	}
+	fmt.Printf("   进度更新于: %s\n", state.UpdatedAt.Format("2006-01-02 15:04:05"))
+	if state.State() == service.RunStateLost {
+		fmt.Println()
+		fmt.Println("💡 迁移进程已不存在但未记录结束状态，可能被强制终止；必要时使用 --resume 重新执行")
+	}
+}
+
+// progressStateLine 进度条和当前步骤，如 "[2/4] 执行 COPY 迁移 [███░░░] 45.0%"
+func progressStateLine(state *service.ProgressState) string {
+	const barWidth = 30
+	filled := int(state.Percentage / 100 * barWidth)
+	if filled > barWidth {
+		filled = barWidth
+	}
+	line := fmt.Sprintf("[%d/%d] %s", state.Step, state.TotalSteps, state.Message)
+	if state.Details != "" {
+		line += " - " + state.Details
+	}
+	line += fmt.Sprintf(" [%s%s] %.1f%%", strings.Repeat("█", filled), strings.Repeat("░", barWidth-filled), state.Percentage)
+	if remaining := state.EstimatedRemaining(); remaining > 0 {
+		line += fmt.Sprintf("，预计剩余 %v", remaining.Round(time.Second))
+	}
+	return line
+}
+
+// followProgressState 持续刷新进度，直到迁移结束、进程不存在或上下文取消
+func followProgressState(ctx context.Context, state *service.ProgressState) {
+	fmt.Println()
+	ticker := time.NewTicker(monitorPollPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Println()
+			return
+		case <-ticker.C:
+		}
+
+		latest, err := service.LoadProgressState(service.DefaultProgressStatePath)
+		if err != nil {
+			// 迁移进程正在替换文件等情况下读取失败，下次重试
+			continue
+		}
+		if latest.RunID != state.RunID {
+			fmt.Printf("\n📌 开始了新的迁移 %s\n", latest.RunID)
+		}
+		state = latest
+		fmt.Printf("\r🔄 %s", progressStateLine(state))
+		if current := state.State(); current != service.RunStateRunning {
+			fmt.Printf("\n📌 迁移已结束，状态: %s（耗时 %v）\n", current, state.Elapsed().Round(time.Second))
+			return
+		}
+	}
+}
+
+// progressStateIcon 迁移状态的图标
+func progressStateIcon(state string) string {
+	switch state {
+	case service.RunStateRunning:
+		return "⏳"
+	case service.RunStateCompleted:
+		return "✅"
+	case service.RunStateLost:
+		return "❓"
+	default:
+		return "❌"
+	}
+}
+
+// typeProgressIcon 迁移类型执行状态的图标
+func typeProgressIcon(status service.ExecutionStatus) string {
+	switch {
+	case status.Succeeded():
+		return "✅"
+	case status == service.StatusFailed:
+		return "❌"
+	default:
+		return "⏭️"
+	}
+}
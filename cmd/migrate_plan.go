@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+var migratePlanAdjust bool
+
+// migratePlanCmd 迁移计划命令
+var migratePlanCmd = &cobra.Command{
+	Use:   "计划",
+	Short: "预览迁移类型的执行顺序",
+	Long: `按依赖关系排序配置中的迁移类型，展示最终的执行顺序。
+
+可以通过 --order 手动指定顺序，或使用 --adjust 交互式上移/下移调整，
+手动顺序会校验依赖关系（如 COPY 不能排在 TABLE 之前）。
+
+示例：
+  ora2pg-admin 迁移 计划
+  ora2pg-admin 迁移 计划 --order TABLE,SEQUENCE,COPY,INDEX
+  ora2pg-admin 迁移 计划 --adjust`,
+	Run: runMigratePlan,
+}
+
+func init() {
+	migrateCmd.AddCommand(migratePlanCmd)
+
+	migratePlanCmd.Flags().BoolVar(&migratePlanAdjust, "adjust", false, "交互式调整执行顺序")
+}
+
+// runMigratePlan 显示迁移执行计划
+func runMigratePlan(cmd *cobra.Command, args []string) {
+	fmt.Println("🗺️ 迁移执行计划")
+	fmt.Println()
+
+	manager, _, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	// 只保留ora2pg-admin可执行的类型
+	ora2pgService := service.NewOra2pgService()
+	var candidates []service.MigrationType
+	for _, name := range manager.GetConfig().Migration.Types {
+		migrationType := service.MigrationType(strings.ToUpper(strings.TrimSpace(name)))
+		if err := ora2pgService.ValidateMigrationType(migrationType); err != nil {
+			fmt.Printf("⚠️ 迁移类型 %s 暂不支持单独执行，已从计划中排除\n", name)
+			continue
+		}
+		candidates = append(candidates, migrationType)
+	}
+	if len(candidates) == 0 {
+		fmt.Printf("%s\n", utils.FormatError(utils.ValidationErrors.Required("迁移类型")))
+		exit(1)
+	}
+
+	suggested := service.OrderMigrationTypes(candidates)
+	plan, err := resolveMigrationOrder(suggested)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	if migratePlanAdjust {
+		if plan, err = adjustMigrationOrder(plan); err != nil {
+			fmt.Printf("%s\n", utils.FormatError(err))
+			exit(1)
+		}
+	}
+
+	printMigrationPlan(plan)
+	printMigrationTypeNotes(plan)
+
+	if service.FormatMigrationOrder(plan) != service.FormatMigrationOrder(suggested) {
+		fmt.Printf("建议顺序: %s\n", service.FormatMigrationOrder(suggested))
+		fmt.Printf("💡 执行时使用该顺序: --order %s\n", service.FormatMigrationOrder(plan))
+	} else {
+		fmt.Println("✅ 当前为建议顺序")
+	}
+}
+
+// resolveMigrationOrder 应用 --order 参数，未指定时使用默认顺序
+func resolveMigrationOrder(defaultTypes []service.MigrationType) ([]service.MigrationType, error) {
+	if strings.TrimSpace(migrateOrder) == "" {
+		return defaultTypes, nil
+	}
+	order, err := service.ParseMigrationOrder(migrateOrder)
+	if err != nil {
+		return nil, err
+	}
+	return service.ApplyMigrationOrder(defaultTypes, order)
+}
+
+// printMigrationPlan 以流程图形式显示执行顺序和依赖
+func printMigrationPlan(plan []service.MigrationType) {
+	included := make(map[service.MigrationType]bool, len(plan))
+	for _, t := range plan {
+		included[t] = true
+	}
+
+	for i, t := range plan {
+		if i > 0 {
+			fmt.Println("    │")
+			fmt.Println("    ▼")
+		}
+		line := fmt.Sprintf(" %2d. %-10s", i+1, t)
+		var dependencies []string
+		for _, dependency := range service.MigrationTypeDependencies(t) {
+			if included[dependency] {
+				dependencies = append(dependencies, string(dependency))
+			}
+		}
+		if len(dependencies) > 0 {
+			line += "  ← 依赖: " + strings.Join(dependencies, ", ")
+		}
+		fmt.Println(line)
+	}
+	fmt.Println()
+}
+
+// printMigrationTypeNotes 显示需要人工处理的迁移类型的转换说明和限制
+func printMigrationTypeNotes(types []service.MigrationType) {
+	for _, t := range types {
+		notes := service.MigrationTypeNotes(t)
+		if len(notes) == 0 {
+			continue
+		}
+		fmt.Printf("⚠️ %s 的转换说明和限制:\n", t)
+		for _, note := range notes {
+			fmt.Printf("   • %s\n", note)
+		}
+		fmt.Println()
+	}
+}
+
+// adjustMigrationOrder 交互式上移/下移调整执行顺序，完成时校验依赖关系
+func adjustMigrationOrder(plan []service.MigrationType) ([]service.MigrationType, error) {
+	order := make([]service.MigrationType, len(plan))
+	copy(order, plan)
+
+	const doneItem = "DONE - 完成调整"
+	for {
+		items := make([]string, 0, len(order)+1)
+		for i, t := range order {
+			items = append(items, fmt.Sprintf("%2d. %s", i+1, t))
+		}
+		items = append(items, doneItem)
+
+		selectPrompt := promptui.Select{
+			Label: "选择要移动的类型",
+			Items: items,
+			Size:  len(items),
+		}
+		index, result, err := selectPrompt.Run()
+		if err != nil {
+			return nil, utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
+				Message("用户取消了选择").Build()
+		}
+
+		if result == doneItem {
+			if err := service.ValidateMigrationOrder(order); err != nil {
+				fmt.Printf("%s\n", utils.FormatError(err))
+				continue
+			}
+			return order, nil
+		}
+
+		movePrompt := promptui.Select{
+			Label: fmt.Sprintf("移动 %s", order[index]),
+			Items: []string{"上移", "下移"},
+		}
+		_, direction, err := movePrompt.Run()
+		if err != nil {
+			continue
+		}
+
+		target := index - 1
+		if direction == "下移" {
+			target = index + 1
+		}
+		if target < 0 || target >= len(order) {
+			continue
+		}
+		order[index], order[target] = order[target], order[index]
+
+		if err := service.ValidateMigrationOrder(order); err != nil {
+			fmt.Printf("⚠️ 当前顺序不满足依赖关系，完成前需要调整:\n%s\n", utils.FormatError(err))
+		}
+	}
+}
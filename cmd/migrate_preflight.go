@@ -0,0 +1,510 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/postgres"
+	"ora2pg-admin/internal/utils"
+)
+
+// preflightTimeout 预检中数据库查询的总超时时间
+const preflightTimeout = 3 * time.Minute
+
+// preflightConflictLimit 冲突对象最多列出的数量
+const preflightConflictLimit = 10
+
+var (
+	migratePreflightJSON      bool
+	migratePreflightMinFreeGB int
+)
+
+// migratePreflightCmd 迁移预检命令
+var migratePreflightCmd = &cobra.Command{
+	Use:   "预检",
+	Short: "正式迁移前执行检查清单",
+	Long: `正式迁移前并行执行一系列检查，输出带通过状态的清单：
+
+• 环境：Oracle客户端和ora2pg工具
+• 配置：配置文件验证
+• 连接：Oracle和PostgreSQL连接
+• 权限：源库导出权限、目标库建表权限
+• 目标模式：是否存在、是否为空
+• 磁盘空间：输出目录所在磁盘的可用空间
+• 目标库容量：连接数能否支撑并行度，内存、语句超时、临时文件和WAL参数
+• 统计信息：源库表统计信息是否新鲜
+• 源库写入状态：源库是否只读、是否有未提交的写事务
+• 冲突对象：目标模式中与源库同名的表
+
+没有失败项时才建议继续迁移；存在失败项时命令以非零状态退出，可用于脚本判断。
+
+示例：
+  ora2pg-admin 迁移 预检
+  ora2pg-admin 迁移 预检 --json
+  ora2pg-admin 迁移 预检 --min-free-gb 50`,
+	Run: runMigratePreflight,
+}
+
+func init() {
+	migrateCmd.AddCommand(migratePreflightCmd)
+
+	migratePreflightCmd.Flags().BoolVar(&migratePreflightJSON, "json", false, "以JSON格式输出检查结果")
+	migratePreflightCmd.Flags().IntVar(&migratePreflightMinFreeGB, "min-free-gb", 10, "输出目录所在磁盘至少需要的可用空间（GB）")
+}
+
+// preflightCheck 预检中的一个检查项，Run 只向自己的分组写入结果，各检查项可并行执行
+type preflightCheck struct {
+	Title string
+	Run   func(ctx context.Context, env *preflightEnv, section *checkSection)
+}
+
+// preflightEnv 预检共享的配置和查询结果，同一查询在多个检查项之间只执行一次
+type preflightEnv struct {
+	cfg          *config.ProjectConfig
+	minFreeSpace uint64
+
+	oracleConnection func() *oracle.ConnectionResult
+	pgConnection     func() *oracle.ConnectionResult
+	oracleTables     func() ([]string, error)
+	targetSchema     func() (*postgres.SchemaState, error)
+	targetCapacity   func() (*postgres.Capacity, error)
+	sourceDataSize   func() (*oracle.DataSize, error)
+}
+
+// newPreflightEnv 创建预检环境，数据库查询在首次使用时执行
+func newPreflightEnv(ctx context.Context, cfg *config.ProjectConfig, minFreeSpace uint64) *preflightEnv {
+	tester := oracle.NewConnectionTester()
+	tester.SetClientConfig(&cfg.OracleClient)
+
+	env := &preflightEnv{cfg: cfg, minFreeSpace: minFreeSpace}
+	env.oracleConnection = sync.OnceValue(func() *oracle.ConnectionResult {
+		return tester.TestOracleConnection(&cfg.Oracle)
+	})
+	env.pgConnection = sync.OnceValue(func() *oracle.ConnectionResult {
+		return tester.TestPostgreSQLConnection(&cfg.PostgreSQL)
+	})
+	env.oracleTables = sync.OnceValues(func() ([]string, error) {
+		return oracle.NewInspector(env.sqlplusRunner(), env.oracleSchema()).TableNames(ctx)
+	})
+	env.targetSchema = sync.OnceValues(func() (*postgres.SchemaState, error) {
+		return postgres.InspectSchema(ctx, postgres.NewPSQLRunner(&cfg.PostgreSQL), cfg.PostgreSQL.Schema)
+	})
+	env.targetCapacity = sync.OnceValues(func() (*postgres.Capacity, error) {
+		return postgres.InspectCapacity(ctx, postgres.NewPSQLRunner(&cfg.PostgreSQL))
+	})
+	env.sourceDataSize = sync.OnceValues(func() (*oracle.DataSize, error) {
+		return oracle.NewInspector(env.sqlplusRunner(), env.oracleSchema()).DataSize(ctx)
+	})
+	return env
+}
+
+// sqlplusRunner 源库sqlplus执行器
+func (env *preflightEnv) sqlplusRunner() *oracle.SQLPlusRunner {
+	return oracle.NewSQLPlusRunner(&env.cfg.Oracle, &env.cfg.OracleClient)
+}
+
+// oracleSchema 源库Schema，未配置时为连接用户
+func (env *preflightEnv) oracleSchema() string {
+	if env.cfg.Oracle.Schema != "" {
+		return env.cfg.Oracle.Schema
+	}
+	return env.cfg.Oracle.Username
+}
+
+// oracleReady 源库是否可以查询，不可查询时记录跳过原因
+func (env *preflightEnv) oracleReady(section *checkSection, check string) bool {
+	if _, _, found := config.DumpFileReference(&env.cfg.Oracle); found {
+		section.Add(check, checkStatusFail, "未检查: Oracle连接配置指向了导出文件", "")
+		return false
+	}
+	if !env.oracleConnection().Success {
+		section.Add(check, checkStatusFail, "未检查: Oracle连接失败", "")
+		return false
+	}
+	return true
+}
+
+// pgReady 目标库是否可以查询，不可查询时记录跳过原因
+func (env *preflightEnv) pgReady(section *checkSection, check string) bool {
+	if !env.pgConnection().Success {
+		section.Add(check, checkStatusFail, "未检查: PostgreSQL连接失败", "")
+		return false
+	}
+	return true
+}
+
+// preflightChecks 预检清单，按此顺序输出
+var preflightChecks = []preflightCheck{
+	{Title: "环境", Run: preflightEnvironment},
+	{Title: "配置验证", Run: preflightConfig},
+	{Title: "数据库连接", Run: preflightConnections},
+	{Title: "权限", Run: preflightPrivileges},
+	{Title: "目标模式状态", Run: preflightTargetSchema},
+	{Title: "磁盘空间", Run: preflightDiskSpace},
+	{Title: "目标库容量", Run: preflightTargetCapacity},
+	{Title: "统计信息新鲜度", Run: preflightStats},
+	{Title: "源库写入状态", Run: preflightSourceWrites},
+	{Title: "冲突对象", Run: preflightConflicts},
+}
+
+// runPreflightChecks 并行执行检查项，结果按清单顺序汇总到报告中
+func runPreflightChecks(ctx context.Context, env *preflightEnv, checks []preflightCheck) *CheckReport {
+	report := newCheckReport()
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		section := report.Section(check.Title)
+		wg.Add(1)
+		go func(check preflightCheck) {
+			defer wg.Done()
+			check.Run(ctx, env, section)
+		}(check)
+	}
+	wg.Wait()
+	return report
+}
+
+// preflightSummary 预检结果的JSON输出
+type preflightSummary struct {
+	Ready    bool           `json:"ready"`
+	Passed   int            `json:"passed"`
+	Warnings int            `json:"warnings"`
+	Failed   int            `json:"failed"`
+	Checks   []*CheckResult `json:"checks"`
+}
+
+// runMigratePreflight 执行迁移预检
+func runMigratePreflight(cmd *cobra.Command, args []string) {
+	logger := utils.GetGlobalLogger()
+
+	if migratePreflightJSON {
+		checkOutput = checkOutputJSON
+	}
+	if err := prepareCheckOutput(); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	if migratePreflightMinFreeGB < 0 {
+		fmt.Printf("%s\n", utils.FormatError(utils.ConfigErrors.InvalidValue("min-free-gb", fmt.Sprint(migratePreflightMinFreeGB))))
+		exit(1)
+	}
+
+	manager, _, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	if !isJSONOutput() {
+		fmt.Println("🛫 迁移预检")
+		fmt.Println("⏳ 正在并行执行检查...")
+		fmt.Println()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), preflightTimeout)
+	defer cancel()
+	env := newPreflightEnv(ctx, manager.GetConfig(), uint64(migratePreflightMinFreeGB)<<30)
+	report := runPreflightChecks(ctx, env, preflightChecks)
+
+	failed := report.Count(checkStatusFail)
+	logger.Infof("迁移预检完成: %d 项失败，%d 项警告", failed, report.Count(checkStatusWarn))
+
+	if isJSONOutput() {
+		summary := preflightSummary{
+			Ready:    failed == 0,
+			Passed:   report.Count(checkStatusPass),
+			Warnings: report.Count(checkStatusWarn),
+			Failed:   failed,
+			Checks:   report.Results(),
+		}
+		data, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			fmt.Printf("%s\n", utils.FormatError(fmt.Errorf("序列化检查结果失败: %v", err)))
+			exit(1)
+		}
+		fmt.Println(string(data))
+	} else {
+		report.RenderText()
+		printPreflightSummary(report)
+	}
+
+	if failed > 0 {
+		exit(1)
+	}
+}
+
+// printPreflightSummary 输出清单总结和是否建议继续
+func printPreflightSummary(report *CheckReport) {
+	fmt.Println()
+	fmt.Println("📊 预检总结")
+	fmt.Println("─────────────────────")
+	fmt.Printf("✅ 通过: %d  ⚠️ 警告: %d  ❌ 失败: %d\n",
+		report.Count(checkStatusPass), report.Count(checkStatusWarn), report.Count(checkStatusFail))
+
+	switch {
+	case report.Count(checkStatusFail) > 0:
+		fmt.Println("❌ 存在未通过的检查项，不建议开始迁移，请先解决上述问题后重新预检")
+	case report.Count(checkStatusWarn) > 0:
+		fmt.Println("⚠️ 检查项均已通过，但存在警告，请确认警告项不影响本次迁移后再继续")
+	default:
+		fmt.Println("🚀 所有检查项均已通过，可以开始迁移: ora2pg-admin 迁移 全部")
+	}
+}
+
+// preflightEnvironment 检查Oracle客户端和ora2pg工具
+func preflightEnvironment(ctx context.Context, env *preflightEnv, section *checkSection) {
+	detector := oracle.NewClientDetector()
+	detector.UseConfig(&env.cfg.OracleClient)
+	collectOracleClientCheck(section, detector, detector.CheckClientStatus())
+	collectOra2pgCheck(section)
+}
+
+// preflightConfig 验证配置文件，预检中配置问题视为失败
+func preflightConfig(ctx context.Context, env *preflightEnv, section *checkSection) {
+	result := newConfigValidator(section).ValidateConfig(env.cfg)
+	if result.Valid {
+		section.Add("config_validation", checkStatusPass, "配置验证通过", "")
+	} else {
+		details := make([]string, len(result.Errors))
+		for i, err := range result.Errors {
+			details[i] = fmt.Sprintf("%d. %s", i+1, err.Error())
+		}
+		section.Add("config_validation", checkStatusFail,
+			fmt.Sprintf("配置验证发现 %d 个问题", len(result.Errors)), strings.Join(details, "\n"),
+			"运行 'ora2pg-admin 配置 数据库' 修正配置")
+	}
+	collectConsistencyWarnings(section, result)
+}
+
+// newConfigValidator 创建配置验证器，并加载用户级和项目级的自定义校验规则
+func newConfigValidator(section *checkSection) *config.Validator {
+	validator := config.NewValidator()
+	rules, err := config.LoadDefaultCustomRules(".")
+	if err != nil {
+		section.Add("custom_rules", checkStatusFail, "自定义校验规则: 加载失败", err.Error(),
+			fmt.Sprintf("按提示修正 %s 后重试", config.ValidationRulesFileName))
+		return validator
+	}
+	if len(rules) > 0 {
+		validator.AddCustomRules(rules...)
+		section.Add("custom_rules", checkStatusPass, fmt.Sprintf("自定义校验规则: 已加载 %d 条", len(rules)), "")
+	}
+	return validator
+}
+
+// collectConsistencyWarnings 把配置一致性警告记为一项警告检查
+func collectConsistencyWarnings(section *checkSection, result *config.ValidationResult) {
+	if len(result.Warnings) == 0 {
+		return
+	}
+	details := make([]string, len(result.Warnings))
+	for i, warning := range result.Warnings {
+		details[i] = fmt.Sprintf("%d. %s", i+1, warning.String())
+	}
+	section.Add("config_consistency", checkStatusWarn,
+		fmt.Sprintf("配置中有 %d 处需要注意的设置", len(result.Warnings)), strings.Join(details, "\n"),
+		"按提示调整配置，或确认这是预期的行为")
+}
+
+// preflightConnections 测试源库和目标库连接
+func preflightConnections(ctx context.Context, env *preflightEnv, section *checkSection) {
+	if field, value, found := config.DumpFileReference(&env.cfg.Oracle); found {
+		section.Add("oracle_connection", checkStatusFail, "Oracle连接配置指向了导出文件，而不是在线数据库",
+			fmt.Sprintf("%s: %s", field, value), config.DumpFileSuggestions...)
+	} else if result := env.oracleConnection(); result.Success {
+		section.Add("oracle_connection", checkStatusPass, result.Message, formatConnectionDetails(result))
+	} else {
+		section.Add("oracle_connection", checkStatusFail, result.Message, formatConnectionDetails(result),
+			"运行 'ora2pg-admin 检查 连接' 查看连接诊断")
+	}
+
+	if result := env.pgConnection(); result.Success {
+		section.Add("postgresql_connection", checkStatusPass, result.Message, formatConnectionDetails(result))
+	} else {
+		section.Add("postgresql_connection", checkStatusFail, result.Message, formatConnectionDetails(result),
+			"运行 'ora2pg-admin 检查 连接' 查看连接诊断")
+	}
+}
+
+// preflightPrivileges 检查源库导出权限和目标库建表权限
+func preflightPrivileges(ctx context.Context, env *preflightEnv, section *checkSection) {
+	if env.oracleReady(section, "oracle_privileges") {
+		privileges, err := oracle.CheckMigrationPrivileges(ctx, env.sqlplusRunner(), env.cfg.Oracle.Username, env.oracleSchema())
+		switch {
+		case err != nil:
+			section.Add("oracle_privileges", checkStatusFail, "Oracle权限查询失败", err.Error())
+		case !privileges.Sufficient():
+			section.Add("oracle_privileges", checkStatusFail,
+				fmt.Sprintf("Oracle账号缺少权限: %s", strings.Join(privileges.Missing, ", ")), "",
+				fmt.Sprintf("请DBA授予 %s，或使用 %s 用户连接", strings.Join(privileges.Missing, ", "), strings.ToUpper(env.oracleSchema())))
+		case len(privileges.Recommended) > 0:
+			section.Add("oracle_privileges", checkStatusWarn,
+				fmt.Sprintf("Oracle账号建议补充权限: %s", strings.Join(privileges.Recommended, ", ")),
+				"缺少时部分对象的定义和注释可能导出不完整")
+		default:
+			section.Add("oracle_privileges", checkStatusPass, "Oracle账号权限足够", "")
+		}
+	}
+
+	if env.pgReady(section, "postgresql_privileges") {
+		state, err := env.targetSchema()
+		switch {
+		case err != nil:
+			section.Add("postgresql_privileges", checkStatusFail, "PostgreSQL权限查询失败", err.Error())
+		case !state.CanCreate && state.Exists:
+			section.Add("postgresql_privileges", checkStatusFail,
+				fmt.Sprintf("PostgreSQL账号没有模式 %s 的 CREATE 权限", state.Schema), "",
+				fmt.Sprintf("GRANT CREATE ON SCHEMA %s TO %s;", state.Schema, env.cfg.PostgreSQL.Username))
+		case !state.CanCreate:
+			section.Add("postgresql_privileges", checkStatusFail,
+				fmt.Sprintf("模式 %s 不存在，且PostgreSQL账号没有创建模式的权限", state.Schema), "",
+				fmt.Sprintf("由DBA预先创建模式: CREATE SCHEMA %s AUTHORIZATION %s;", state.Schema, env.cfg.PostgreSQL.Username))
+		default:
+			section.Add("postgresql_privileges", checkStatusPass, "PostgreSQL账号权限足够", "")
+		}
+	}
+}
+
+// preflightTargetSchema 检查目标模式是否存在、是否已有对象
+func preflightTargetSchema(ctx context.Context, env *preflightEnv, section *checkSection) {
+	if !env.pgReady(section, "target_schema") {
+		return
+	}
+
+	state, err := env.targetSchema()
+	switch {
+	case err != nil:
+		section.Add("target_schema", checkStatusFail, "目标模式状态查询失败", err.Error())
+	case !state.Exists:
+		section.Add("target_schema", checkStatusWarn, fmt.Sprintf("目标模式 %s 不存在，导入时需要创建", state.Schema), "")
+	case !state.Empty():
+		section.Add("target_schema", checkStatusWarn,
+			fmt.Sprintf("目标模式 %s 已有 %d 个表、视图或序列", state.Schema, len(state.Relations)), "",
+			"确认是否为上次迁移遗留的对象，重新迁移前建议清空目标模式")
+	default:
+		section.Add("target_schema", checkStatusPass, fmt.Sprintf("目标模式 %s 存在且为空", state.Schema), "")
+	}
+}
+
+// preflightDiskSpace 检查输出目录所在磁盘的可用空间
+func preflightDiskSpace(ctx context.Context, env *preflightEnv, section *checkSection) {
+	outputDir := env.cfg.Migration.OutputDir
+	if outputDir == "" {
+		outputDir = "output"
+	}
+
+	// 输出目录尚未创建时检查其最近的已存在的上级目录
+	path := outputDir
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+		path = parent
+	}
+
+	free, err := utils.DiskFreeSpace(path)
+	if err != nil {
+		section.Add("disk_space", checkStatusWarn, fmt.Sprintf("无法获取 %s 所在磁盘的可用空间", outputDir), err.Error())
+		return
+	}
+
+	message := fmt.Sprintf("输出目录 %s 所在磁盘可用 %s", outputDir, formatGigabytes(free))
+	if free < env.minFreeSpace {
+		section.Add("disk_space", checkStatusFail, message,
+			fmt.Sprintf("至少需要 %s", formatGigabytes(env.minFreeSpace)),
+			"清理磁盘或把 migration.output_dir 改到空间充足的磁盘",
+			"可通过 --min-free-gb 调整需要的可用空间")
+		return
+	}
+	section.Add("disk_space", checkStatusPass, message, "")
+}
+
+// formatGigabytes 以GB显示字节数
+func formatGigabytes(bytes uint64) string {
+	return fmt.Sprintf("%.1fGB", float64(bytes)/float64(1<<30))
+}
+
+// preflightStats 检查源库表统计信息是否新鲜
+func preflightStats(ctx context.Context, env *preflightEnv, section *checkSection) {
+	if !env.oracleReady(section, "oracle_stats") {
+		return
+	}
+
+	collector := oracle.NewStatsCollector(env.sqlplusRunner(), env.oracleSchema())
+	freshness, err := collector.CheckFreshness(ctx, oracle.DefaultStatsMaxAge)
+	switch {
+	case err != nil:
+		section.Add("oracle_stats", checkStatusWarn, "统计信息新鲜度检测失败", err.Error())
+	case freshness.NeedsGather():
+		section.Add("oracle_stats", checkStatusWarn, freshness.Summary(),
+			"ora2pg的行数和成本估算可能不准确",
+			"迁移时加 --gather-stats 先收集统计信息，或由DBA手动收集")
+	default:
+		section.Add("oracle_stats", checkStatusPass, freshness.Summary(), "")
+	}
+}
+
+// preflightConflicts 检查目标模式中是否已有与源库同名的表
+func preflightConflicts(ctx context.Context, env *preflightEnv, section *checkSection) {
+	oracleReady := env.oracleReady(section, "conflicting_objects")
+	if !oracleReady || !env.pgReady(section, "conflicting_objects") {
+		return
+	}
+
+	tables, err := env.oracleTables()
+	if err != nil {
+		section.Add("conflicting_objects", checkStatusFail, "源库表名查询失败", err.Error())
+		return
+	}
+	state, err := env.targetSchema()
+	if err != nil {
+		section.Add("conflicting_objects", checkStatusFail, "目标模式状态查询失败", err.Error())
+		return
+	}
+
+	conflicts := conflictingObjects(tables, state.Relations)
+	if len(conflicts) == 0 {
+		section.Add("conflicting_objects", checkStatusPass, "目标模式中没有与源库同名的对象", "")
+		return
+	}
+
+	listed := conflicts
+	if len(listed) > preflightConflictLimit {
+		listed = listed[:preflightConflictLimit]
+	}
+	details := strings.Join(listed, ", ")
+	if len(conflicts) > len(listed) {
+		details += fmt.Sprintf(" 等 %d 个", len(conflicts))
+	}
+	section.Add("conflicting_objects", checkStatusFail,
+		fmt.Sprintf("目标模式 %s 中有 %d 个与源库同名的对象", state.Schema, len(conflicts)), details,
+		"导入时会因对象已存在而失败，请确认后删除这些对象或改用新的目标模式")
+}
+
+// conflictingObjects 目标库中与源库表同名的对象（ora2pg默认把名称转为小写，按不区分大小写比较）
+func conflictingObjects(sourceTables, targetRelations []string) []string {
+	source := make(map[string]bool, len(sourceTables))
+	for _, table := range sourceTables {
+		source[strings.ToLower(table)] = true
+	}
+
+	var conflicts []string
+	for _, relation := range targetRelations {
+		if source[strings.ToLower(relation)] {
+			conflicts = append(conflicts, relation)
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/postgres"
+)
+
+// 目标库容量预检的阈值
+const (
+	// capacityConnectionUsage 迁移连接加上现有连接占 max_connections 的比例超过该值时警告
+	capacityConnectionUsage = 0.9
+	// capacityMinMaintenanceWorkMem 创建索引和约束时 maintenance_work_mem 的建议下限
+	capacityMinMaintenanceWorkMem = 64 << 20
+	// capacityMinWorkMem work_mem 的建议下限，与PostgreSQL默认值相同
+	capacityMinWorkMem = 4 << 20
+	// capacityWalRatio 预计数据量超过 max_wal_size 的倍数时警告检查点过于频繁
+	capacityWalRatio = 10
+)
+
+// preflightTargetCapacity 检查目标库的连接数、内存、超时和WAL参数能否承载迁移
+func preflightTargetCapacity(ctx context.Context, env *preflightEnv, section *checkSection) {
+	if !env.pgReady(section, "pg_capacity") {
+		return
+	}
+	capacity, err := env.targetCapacity()
+	if err != nil {
+		section.Add("pg_capacity", checkStatusFail, "目标库容量参数查询失败", err.Error())
+		return
+	}
+
+	// 数据量估算只是参考，源库不可查询时不单独记为失败
+	var size *oracle.DataSize
+	var sizeErr error
+	if _, _, found := config.DumpFileReference(&env.cfg.Oracle); found {
+		sizeErr = fmt.Errorf("Oracle连接配置指向了导出文件")
+	} else if !env.oracleConnection().Success {
+		sizeErr = fmt.Errorf("Oracle连接失败")
+	} else {
+		size, sizeErr = env.sourceDataSize()
+	}
+
+	collectCapacityChecks(section, capacity, env.cfg.Migration.ConnectionPlan(), env.cfg.PostgreSQL.Username, size, sizeErr)
+}
+
+// collectCapacityChecks 按阈值评估目标库容量，size 为源库的数据量估算，获取失败时为 nil
+func collectCapacityChecks(section *checkSection, capacity *postgres.Capacity, plan config.ConnectionPlan,
+	username string, size *oracle.DataSize, sizeErr error) {
+	collectConnectionCapacity(section, capacity, plan)
+
+	switch {
+	case capacity.MaintenanceWorkMem < capacityMinMaintenanceWorkMem:
+		section.Add("pg_memory", checkStatusWarn,
+			fmt.Sprintf("maintenance_work_mem 为 %s，导入后创建索引和约束会较慢", formatMegabytes(capacity.MaintenanceWorkMem)),
+			fmt.Sprintf("建议至少 %s", formatMegabytes(capacityMinMaintenanceWorkMem)),
+			fmt.Sprintf("ALTER ROLE %s SET maintenance_work_mem = '1GB';", username))
+	case capacity.WorkMem < capacityMinWorkMem:
+		section.Add("pg_memory", checkStatusWarn,
+			fmt.Sprintf("work_mem 为 %s，低于默认值 %s", formatMegabytes(capacity.WorkMem), formatMegabytes(capacityMinWorkMem)), "",
+			fmt.Sprintf("ALTER ROLE %s SET work_mem = '16MB';", username))
+	default:
+		section.Add("pg_memory", checkStatusPass,
+			fmt.Sprintf("shared_buffers %s，work_mem %s，maintenance_work_mem %s", formatMegabytes(capacity.SharedBuffers),
+				formatMegabytes(capacity.WorkMem), formatMegabytes(capacity.MaintenanceWorkMem)), "")
+	}
+
+	if capacity.StatementTimeout > 0 {
+		section.Add("pg_statement_timeout", checkStatusWarn,
+			fmt.Sprintf("statement_timeout 为 %dms，耗时较长的 COPY 和建索引语句会被取消", capacity.StatementTimeout), "",
+			fmt.Sprintf("迁移期间取消语句超时: ALTER ROLE %s SET statement_timeout = 0;", username))
+	} else {
+		section.Add("pg_statement_timeout", checkStatusPass, "未设置语句超时", "")
+	}
+
+	if size == nil {
+		section.Add("pg_data_volume", checkStatusWarn, "无法估算迁移数据量，未检查临时文件和WAL参数", sizeErr.Error(),
+			"确认源库连接后重新预检")
+		return
+	}
+	collectVolumeCapacity(section, capacity, size)
+}
+
+// collectConnectionCapacity 检查目标库剩余连接数能否支撑配置的并行度
+func collectConnectionCapacity(section *checkSection, capacity *postgres.Capacity, plan config.ConnectionPlan) {
+	needed := plan.PostgresConnections()
+	available := capacity.AvailableConnections()
+	details := fmt.Sprintf("max_connections %d（保留 %d），当前 %d 个连接，用户限制 %s，数据库限制 %s；%s",
+		capacity.MaxConnections, capacity.ReservedConnections, capacity.ActiveConnections,
+		formatConnLimit(capacity.RoleConnLimit), formatConnLimit(capacity.DatabaseConnLimit), plan.Summary())
+
+	switch {
+	case available < needed:
+		section.Add("pg_connections", checkStatusFail,
+			fmt.Sprintf("迁移需要 %d 个PostgreSQL连接，目标库只剩 %d 个可用", needed, available), details,
+			"降低 migration.parallel_tables 或 migration.parallel_jobs",
+			"或设置 migration.max_connections 限制迁移使用的连接数",
+			"或请DBA调大 max_connections、用户或数据库的连接数限制")
+	case float64(capacity.ActiveConnections+needed) > float64(capacity.MaxConnections)*capacityConnectionUsage:
+		section.Add("pg_connections", checkStatusWarn,
+			fmt.Sprintf("迁移后目标库连接数将达到 %d/%d，可能影响其他应用", capacity.ActiveConnections+needed, capacity.MaxConnections),
+			details, "在业务低峰期迁移，或降低迁移并行度")
+	default:
+		section.Add("pg_connections", checkStatusPass,
+			fmt.Sprintf("迁移需要 %d 个PostgreSQL连接，目标库剩余 %d 个", needed, available), details)
+	}
+}
+
+// collectVolumeCapacity 结合预计数据量检查临时文件和WAL参数
+func collectVolumeCapacity(section *checkSection, capacity *postgres.Capacity, size *oracle.DataSize) {
+	message := fmt.Sprintf("预计迁移 %d 张表、%d 行，约 %s；目标库当前 %s（表空间 %s）", size.Tables, size.Rows,
+		formatGigabytes(uint64(size.Bytes)), formatGigabytes(uint64(capacity.DatabaseSize)), capacity.Tablespace)
+	if size.Unanalyzed > 0 {
+		section.Add("pg_data_volume", checkStatusWarn, message,
+			fmt.Sprintf("%d 张表没有统计信息，未计入估算", size.Unanalyzed),
+			"迁移时加 --gather-stats 先收集统计信息")
+	} else {
+		section.Add("pg_data_volume", checkStatusPass, message,
+			"按源库统计信息估算，不含索引；请确认目标表空间所在磁盘有足够空间")
+	}
+
+	if capacity.TempFileLimit >= 0 && capacity.TempFileLimit < size.Bytes {
+		section.Add("pg_temp_file_limit", checkStatusWarn,
+			fmt.Sprintf("temp_file_limit 为 %s，小于预计数据量，大表建索引时可能因临时文件超限失败", formatMegabytes(capacity.TempFileLimit)), "",
+			"迁移期间由DBA把 temp_file_limit 设为 -1 或调大")
+	}
+
+	if capacity.MaxWalSize > 0 && size.Bytes > capacity.MaxWalSize*capacityWalRatio {
+		section.Add("pg_wal", checkStatusWarn,
+			fmt.Sprintf("预计数据量超过 max_wal_size（%s）的 %d 倍，导入期间检查点会很频繁", formatMegabytes(capacity.MaxWalSize), capacityWalRatio), "",
+			"迁移期间由DBA调大 max_wal_size，例如 ALTER SYSTEM SET max_wal_size = '16GB';")
+	}
+}
+
+// formatMegabytes 以MB显示字节数，不足1MB时以kB显示
+func formatMegabytes(bytes int64) string {
+	if bytes < 1<<20 {
+		return fmt.Sprintf("%dkB", bytes>>10)
+	}
+	return fmt.Sprintf("%dMB", bytes>>20)
+}
+
+// formatConnLimit 显示连接数限制，-1 表示不限制
+func formatConnLimit(limit int) string {
+	if limit < 0 {
+		return "无"
+	}
+	return fmt.Sprint(limit)
+}
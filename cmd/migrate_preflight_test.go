@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/postgres"
+)
+
+func TestRunPreflightChecksParallelAndOrdered(t *testing.T) {
+	// 三个检查项都等待同一个屏障，串行执行时会超时
+	var barrier sync.WaitGroup
+	barrier.Add(3)
+	check := func(name, status string) preflightCheck {
+		return preflightCheck{Title: name, Run: func(ctx context.Context, env *preflightEnv, section *checkSection) {
+			barrier.Done()
+			waited := make(chan struct{})
+			go func() { barrier.Wait(); close(waited) }()
+			select {
+			case <-waited:
+				section.Add(name, status, name, "")
+			case <-time.After(5 * time.Second):
+				section.Add(name, checkStatusFail, "未并行执行", "")
+			}
+		}}
+	}
+
+	report := runPreflightChecks(context.Background(), &preflightEnv{}, []preflightCheck{
+		check("first", checkStatusPass),
+		check("second", checkStatusWarn),
+		check("third", checkStatusPass),
+	})
+
+	results := report.Results()
+	require.Len(t, results, 3)
+	assert.Equal(t, []string{"first", "second", "third"}, []string{results[0].Check, results[1].Check, results[2].Check})
+	assert.Equal(t, 0, report.Count(checkStatusFail))
+	assert.Equal(t, 1, report.Count(checkStatusWarn))
+}
+
+func TestConflictingObjects(t *testing.T) {
+	conflicts := conflictingObjects([]string{"EMP", "DEPT", "BONUS"}, []string{"dept", "emp_seq", "bonus"})
+	assert.Equal(t, []string{"dept", "bonus"}, conflicts)
+	assert.Empty(t, conflictingObjects([]string{"EMP"}, nil))
+}
+
+func TestPreflightDiskSpace(t *testing.T) {
+	cfg := defaultPreflightConfig()
+	cfg.Migration.OutputDir = t.TempDir() + "/not/created/yet"
+
+	report := newCheckReport()
+	preflightDiskSpace(context.Background(), &preflightEnv{cfg: cfg}, report.Section("磁盘空间"))
+	require.NotNil(t, report.Find("disk_space"))
+	assert.Equal(t, checkStatusPass, report.Find("disk_space").Status)
+
+	report = newCheckReport()
+	preflightDiskSpace(context.Background(), &preflightEnv{cfg: cfg, minFreeSpace: 1 << 62}, report.Section("磁盘空间"))
+	assert.Equal(t, checkStatusFail, report.Find("disk_space").Status)
+}
+
+func TestPreflightConfigAndDumpFile(t *testing.T) {
+	cfg := defaultPreflightConfig()
+	cfg.Oracle.Host = "/backup/export.dmp"
+	cfg.Migration.Types = append(cfg.Migration.Types, "COPY", "INSERT")
+
+	report := newCheckReport()
+	env := &preflightEnv{cfg: cfg}
+	preflightConfig(context.Background(), env, report.Section("配置验证"))
+	preflightStats(context.Background(), env, report.Section("统计信息新鲜度"))
+
+	assert.Equal(t, checkStatusFail, report.Find("config_validation").Status)
+	assert.Equal(t, checkStatusWarn, report.Find("config_consistency").Status)
+	assert.Contains(t, report.Find("config_consistency").Details, "COPY 和 INSERT")
+	// 连接配置指向导出文件时不尝试查询源库
+	assert.Equal(t, checkStatusFail, report.Find("oracle_stats").Status)
+	assert.Contains(t, report.Find("oracle_stats").Message, "导出文件")
+}
+
+// defaultPreflightConfig 默认项目配置
+func defaultPreflightConfig() *config.ProjectConfig {
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("preflight")
+	return manager.GetConfig()
+}
+
+func TestCollectCapacityChecks(t *testing.T) {
+	capacity := &postgres.Capacity{
+		MaxConnections: 100, ReservedConnections: 3, ActiveConnections: 20,
+		RoleConnLimit: -1, DatabaseConnLimit: -1,
+		WorkMem: 4 << 20, MaintenanceWorkMem: 1 << 30, MaxWalSize: 1 << 30, TempFileLimit: -1,
+		Tablespace: "pg_default",
+	}
+	plan := config.ConnectionPlan{ParallelTables: 4, OracleCopies: 1, ParallelJobs: 2}
+	size := &oracle.DataSize{Tables: 10, Rows: 1000, Bytes: 2 << 30}
+
+	report := newCheckReport()
+	collectCapacityChecks(report.Section("目标库容量"), capacity, plan, "app", size, nil)
+	assert.Equal(t, 0, report.Count(checkStatusWarn)+report.Count(checkStatusFail))
+	assert.Equal(t, checkStatusPass, report.Find("pg_connections").Status)
+	assert.Nil(t, report.Find("pg_wal"))
+
+	// 用户连接数限制不足以支撑并行度，语句超时和临时文件上限会中断长语句
+	capacity.RoleConnLimit = 5
+	capacity.StatementTimeout = 60000
+	capacity.TempFileLimit = 1 << 30
+	capacity.MaintenanceWorkMem = 16 << 20
+	capacity.MaxWalSize = 128 << 20
+	size.Unanalyzed = 2
+	report = newCheckReport()
+	collectCapacityChecks(report.Section("目标库容量"), capacity, plan, "app", size, nil)
+	assert.Equal(t, checkStatusFail, report.Find("pg_connections").Status)
+	assert.Contains(t, report.Find("pg_connections").Message, "需要 9 个")
+	for _, check := range []string{"pg_memory", "pg_statement_timeout", "pg_temp_file_limit", "pg_wal", "pg_data_volume"} {
+		require.NotNil(t, report.Find(check), check)
+		assert.Equal(t, checkStatusWarn, report.Find(check).Status, check)
+	}
+
+	// 连接数够用但接近上限，源库不可查询时只提示无法估算数据量
+	capacity.RoleConnLimit = -1
+	capacity.ActiveConnections = 85
+	report = newCheckReport()
+	collectCapacityChecks(report.Section("目标库容量"), capacity, plan, "app", nil, errors.New("Oracle连接失败"))
+	assert.Equal(t, checkStatusWarn, report.Find("pg_connections").Status)
+	assert.Equal(t, checkStatusWarn, report.Find("pg_data_volume").Status)
+	assert.Nil(t, report.Find("pg_temp_file_limit"))
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+var (
+	previewTypes    []string
+	previewKeywords []string
+	previewPageSize int
+	previewLines    int
+	previewNoColor  bool
+	previewSummary  bool
+)
+
+// previewInput 分页时读取翻页命令的输入，nil 表示标准输入
+var previewInput io.Reader
+
+// migratePreviewCmd SQL预览命令
+var migratePreviewCmd = &cobra.Command{
+	Use:   "预览 [文件或目录...]",
+	Short: "浏览ora2pg生成的SQL，支持按语句类型过滤和高亮",
+	Long: `逐条浏览ora2pg生成的SQL，便于导入目标库前审查。未指定路径时浏览输出目录下的全部 .sql 文件。
+
+文件按流式读取，大文件不会整体载入内存；每条语句只显示前若干行，COPY 数据显示行数。
+--type 按语句类型过滤（CREATE 匹配所有建对象语句，CREATE TABLE 只匹配建表语句），
+--keyword 只显示包含关注词的语句并高亮这些词。在终端中按页浏览，回车翻页，输入 q 退出。
+
+示例：
+  ora2pg-admin 迁移 预览
+  ora2pg-admin 迁移 预览 --type "CREATE TABLE" --type "ALTER TABLE"
+  ora2pg-admin 迁移 预览 output/TABLE_output.sql --keyword CASCADE
+  ora2pg-admin 迁移 预览 --summary`,
+	Run: runMigratePreview,
+}
+
+func init() {
+	migrateCmd.AddCommand(migratePreviewCmd)
+
+	migratePreviewCmd.Flags().StringArrayVar(&previewTypes, "type", nil, "只显示指定类型的语句，可重复指定（如 CREATE TABLE、INSERT、COPY）")
+	migratePreviewCmd.Flags().StringArrayVar(&previewKeywords, "keyword", nil, "只显示包含该词的语句并高亮，可重复指定")
+	migratePreviewCmd.Flags().IntVar(&previewPageSize, "page-size", 10, "每页显示的语句数（0表示不分页）")
+	migratePreviewCmd.Flags().IntVar(&previewLines, "lines", 30, "每条语句最多显示的行数（0表示全部显示）")
+	migratePreviewCmd.Flags().BoolVar(&previewNoColor, "no-color", false, "不使用终端颜色")
+	migratePreviewCmd.Flags().BoolVar(&previewSummary, "summary", false, "只统计各类型语句的数量")
+}
+
+// runMigratePreview 浏览生成的SQL
+func runMigratePreview(cmd *cobra.Command, args []string) {
+	fmt.Println("🔎 SQL预览")
+	fmt.Println()
+
+	paths := args
+	if len(paths) == 0 {
+		manager, _, err := loadExistingConfig()
+		if err != nil {
+			fmt.Printf("%s\n", utils.FormatError(err))
+			exit(1)
+		}
+		paths = []string{manager.GetConfig().Migration.OutputDir}
+	}
+
+	files, err := service.FindSQLFiles(paths)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	if len(files) == 0 {
+		fmt.Printf("💡 %s 中没有SQL文件，请先执行迁移\n", strings.Join(paths, ", "))
+		return
+	}
+
+	filter := service.SQLPreviewFilter{Kinds: previewTypes, Keywords: previewKeywords}
+	printer := &sqlPreviewPrinter{
+		out:      os.Stdout,
+		lines:    previewLines,
+		color:    previewColorEnabled(),
+		keywords: previewKeywords,
+	}
+	var pager *sqlPager
+	if !previewSummary && previewPageSize > 0 && utils.IsInteractive() {
+		input := previewInput
+		if input == nil {
+			input = os.Stdin
+		}
+		pager = newSQLPager(input, os.Stdout, previewPageSize)
+	}
+
+	visit := func(stmt service.SQLStatement) bool {
+		if previewSummary {
+			return true
+		}
+		if !pager.Next() {
+			return false
+		}
+		printer.Print(stmt)
+		return true
+	}
+	summary, err := service.PreviewSQLFiles(files, filter, 0, visit)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	printSQLPreviewSummary(os.Stdout, summary)
+}
+
+// previewColorEnabled 是否使用终端颜色：标准输出是终端，且未指定 --no-color 或 NO_COLOR 环境变量
+func previewColorEnabled() bool {
+	if previewNoColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// sqlPreviewPrinter 显示单条语句
+type sqlPreviewPrinter struct {
+	out      io.Writer
+	lines    int
+	color    bool
+	keywords []string
+}
+
+// Print 显示语句的位置、类型和文本，超过行数上限的部分省略
+func (p *sqlPreviewPrinter) Print(stmt service.SQLStatement) {
+	header := fmt.Sprintf("── %s:%d  %s", stmt.File, stmt.Line, stmt.Kind)
+	if stmt.Rows > 0 {
+		header += fmt.Sprintf("（%d 行数据）", stmt.Rows)
+	}
+	fmt.Fprintln(p.out, header)
+
+	text := stmt.Text
+	shown := strings.Count(text, "\n") + 1
+	if p.lines > 0 && shown > p.lines {
+		text = strings.Join(strings.SplitN(text, "\n", p.lines+1)[:p.lines], "\n")
+		shown = p.lines
+	}
+	if p.color {
+		text = service.HighlightSQL(text, p.keywords)
+	}
+	fmt.Fprintln(p.out, text)
+	if omitted := stmt.Lines - shown; omitted > 0 {
+		fmt.Fprintf(p.out, "   … 省略 %d 行（共 %d 行）\n", omitted, stmt.Lines)
+	} else if stmt.Truncated {
+		fmt.Fprintf(p.out, "   … 语句过长，只显示前 %d KB\n", service.DefaultSQLPreviewBytes/1024)
+	}
+	fmt.Fprintln(p.out)
+}
+
+// sqlPager 按页显示语句，每页显示完后等待用户翻页
+type sqlPager struct {
+	in       *bufio.Reader
+	out      io.Writer
+	pageSize int
+	shown    int
+	page     int
+}
+
+// newSQLPager 创建分页器
+func newSQLPager(in io.Reader, out io.Writer, pageSize int) *sqlPager {
+	return &sqlPager{in: bufio.NewReader(in), out: out, pageSize: pageSize, page: 1}
+}
+
+// Next 显示下一条语句前调用，当前页已满时等待翻页，用户退出时返回 false；nil 表示不分页
+func (p *sqlPager) Next() bool {
+	if p == nil {
+		return true
+	}
+	if p.shown == p.pageSize {
+		fmt.Fprintf(p.out, "-- 第 %d 页，回车继续，输入 q 退出 -- ", p.page)
+		answer, err := p.in.ReadString('\n')
+		fmt.Fprintln(p.out)
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer == "q" || answer == "quit" || (err != nil && answer == "") {
+			return false
+		}
+		p.shown = 0
+		p.page++
+	}
+	p.shown++
+	return true
+}
+
+// printSQLPreviewSummary 显示扫描的文件数和各类型语句的数量
+func printSQLPreviewSummary(out io.Writer, summary *service.SQLPreviewSummary) {
+	if summary.Stopped {
+		fmt.Fprintln(out, "⏹️ 已退出浏览，以下为退出前的统计")
+	}
+	fmt.Fprintf(out, "📊 扫描 %d 个文件、%d 条语句，匹配 %d 条\n", summary.Files, summary.Statements, summary.Matched)
+	for _, kind := range summary.KindCounts() {
+		fmt.Fprintf(out, "  • %-24s %d\n", kind, summary.Kinds[kind])
+	}
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"ora2pg-admin/internal/service"
+)
+
+func TestSQLPager(t *testing.T) {
+	var out bytes.Buffer
+	pager := newSQLPager(strings.NewReader("\nq\n"), &out, 2)
+
+	shown := 0
+	for pager.Next() {
+		shown++
+	}
+	// 第一页2条，回车后第二页2条，输入 q 退出
+	assert.Equal(t, 4, shown)
+	assert.Contains(t, out.String(), "第 1 页")
+	assert.Contains(t, out.String(), "第 2 页")
+
+	// 输入结束时退出
+	pager = newSQLPager(strings.NewReader(""), &out, 1)
+	assert.True(t, pager.Next())
+	assert.False(t, pager.Next())
+
+	// 不分页
+	var none *sqlPager
+	assert.True(t, none.Next())
+}
+
+func TestSQLPreviewPrinter(t *testing.T) {
+	var out bytes.Buffer
+	printer := &sqlPreviewPrinter{out: &out, lines: 2}
+	printer.Print(service.SQLStatement{
+		File:  "output/COPY_output.sql",
+		Line:  7,
+		Kind:  "COPY",
+		Text:  "COPY t FROM STDIN;\n1\n2\n\\.",
+		Lines: 4,
+		Rows:  2,
+	})
+	assert.Equal(t, "── output/COPY_output.sql:7  COPY（2 行数据）\nCOPY t FROM STDIN;\n1\n   … 省略 2 行（共 4 行）\n\n", out.String())
+}
@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+// queueTaskMetadataKey 队列任务名称在迁移历史元数据中的键
+const queueTaskMetadataKey = "queue_task"
+
+// taskDisplayNames 各任务类型的显示名称，与单独执行子命令时一致
+var taskDisplayNames = map[string]string{
+	service.TaskTypeStructure: "结构迁移",
+	service.TaskTypeData:      "数据迁移",
+	service.TaskTypeAll:       "完整迁移",
+}
+
+// migrateQueueCmd 迁移任务队列命令
+var migrateQueueCmd = &cobra.Command{
+	Use:   "队列 <任务文件>",
+	Short: "按优先级和顺序执行任务文件中的多个迁移任务",
+	Long: `从YAML任务文件读取多个迁移任务，按优先级和顺序执行，可为任务指定最早开始时间。
+
+任务文件示例：
+  continue_on_error: false   # 任务失败后是否继续执行后续任务
+  tasks:
+    - name: 夜间结构迁移
+      type: 结构               # 结构、数据、全部（或 structure、data、all）
+      at: "01:00"              # 可选，到达该时间前等待
+    - name: 夜间数据迁移
+      type: 数据
+      tags:
+        ticket: JIRA-123
+    - name: 紧急补数
+      type: 数据
+      priority: 紧急           # 紧急、高、普通、低（或 urgent、high、normal、low），默认普通
+
+等待中的任务按优先级从高到低执行，同一优先级按文件中的顺序；正在执行的任务不会被抢占。
+每个任务结束后重新读取任务文件，新增的任务和优先级的调整对等待中的任务生效，
+可在队列运行期间向文件中加入紧急任务插队（任务按名称识别，名称不能重复）。
+指定 --reload-tasks=false 时只使用开始时读取的任务。
+
+只写时分的时间按任务顺序递增（如 23:00 之后的 01:00 表示次日凌晨），
+到达任务时已过开始时间则立即执行。每个任务使用独立的迁移服务，命令行参数对所有任务生效。
+
+队列运行期间监听项目配置文件，修改后自动重新加载并校验：正在执行的任务不受影响，
+从下一个任务开始使用新配置；校验失败时继续使用原配置。指定 --watch-config=false 时
+每个任务开始时直接读取配置文件。
+
+示例：
+  ora2pg-admin 迁移 队列 tasks.yaml
+  ora2pg-admin 迁移 队列 tasks.yaml --schedule 02:00`,
+	Args: cobra.ExactArgs(1),
+	Run:  runMigrateQueue,
+}
+
+var (
+	queueWatchConfig bool
+	queueReloadTasks bool
+)
+
+func init() {
+	migrateCmd.AddCommand(migrateQueueCmd)
+
+	migrateQueueCmd.Flags().BoolVar(&queueWatchConfig, "watch-config", true, "监听配置文件，修改并校验通过后从下一个任务开始生效")
+	migrateQueueCmd.Flags().BoolVar(&queueReloadTasks, "reload-tasks", true, "每个任务结束后重新读取任务文件，新增任务和优先级调整对等待中的任务生效")
+}
+
+// runMigrateQueue 执行迁移任务队列
+func runMigrateQueue(cmd *cobra.Command, args []string) {
+	detachIfRequested()
+
+	fmt.Println("🗂️ 迁移任务队列")
+	fmt.Println()
+
+	if !checkProjectDirectory() {
+		fmt.Printf("%s\n", utils.FormatError(utils.NewError(utils.ErrorTypeConfig, "PROJECT_NOT_INITIALIZED").
+			Message("项目未初始化").
+			Suggestion("请先使用 'ora2pg-admin 初始化 [项目名称]' 创建项目").
+			Build()))
+		exit(1)
+	}
+
+	// 整个队列共用一个可中断的上下文，超时按任务单独计算
+	ctx, cancel := createInterruptContext()
+	defer cancel()
+
+	if _, err := service.LoadTaskQueue(args[0], time.Now()); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	start, err := waitForScheduleContext(ctx)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	// 按实际开始时间重新解析任务时间
+	queue, err := service.LoadTaskQueue(args[0], start)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	printTaskQueue(queue)
+
+	var watched *config.Manager
+	if queueWatchConfig {
+		manager, watcher, err := watchProjectConfig()
+		if err != nil {
+			fmt.Printf("%s\n", utils.FormatError(err))
+			exit(1)
+		}
+		defer watcher.Close()
+		watched = manager
+	}
+
+	scheduler := service.NewScheduler()
+	if queueReloadTasks {
+		scheduler.SetReloader(func() (*service.TaskQueue, error) {
+			return service.LoadTaskQueue(args[0], start)
+		})
+	}
+	results := scheduler.RunQueue(ctx, queue, func(ctx context.Context, task *service.ScheduledTask) error {
+		return runQueueTask(ctx, task, watched)
+	})
+	if !printTaskQueueSummary(results) {
+		exit(1)
+	}
+}
+
+// watchProjectConfig 加载项目配置并监听配置文件的修改
+func watchProjectConfig() (*config.Manager, *config.ConfigWatcher, error) {
+	manager := config.NewManager()
+	if err := manager.LoadConfig(config.ProjectConfigPath(".")); err != nil {
+		return nil, nil, configLoadError(err)
+	}
+	watcher, err := manager.Watch(printConfigReload)
+	if err != nil {
+		return nil, nil, utils.NewError(utils.ErrorTypeConfig, "CONFIG_WATCH_FAILED").
+			Message("无法监听配置文件").
+			Details(err.Error()).
+			Cause(err).
+			Suggestion("指定 --watch-config=false，每个任务开始时直接读取配置文件").
+			Build()
+	}
+	fmt.Printf("👀 正在监听配置文件: %s（修改后从下一个任务开始生效）\n", manager.GetConfigPath())
+	return manager, watcher, nil
+}
+
+// printConfigReload 显示配置文件重载结果
+func printConfigReload(event *config.ReloadEvent) {
+	fmt.Println()
+	if event.Reloaded() {
+		fmt.Println("🔄 配置文件已修改并重新加载，从下一个任务开始生效")
+		if event.Validation != nil {
+			for _, warning := range event.Validation.Warnings {
+				fmt.Printf("   ⚠️ %s\n", warning.String())
+			}
+		}
+		return
+	}
+	fmt.Printf("⚠️ 配置文件已修改，但%v，继续使用原配置\n", event.Err)
+	if event.Validation != nil {
+		for _, validationErr := range event.Validation.Errors {
+			fmt.Printf("   ❌ %s\n", validationErr.Error())
+		}
+	}
+}
+
+// runQueueTask 执行队列中的单个任务，每个任务创建独立的迁移服务，结束时释放输出转发等资源
+//
+// watched 为正在监听的配置管理器时使用其当前配置，为空时从配置文件加载。
+func runQueueTask(ctx context.Context, task *service.ScheduledTask, watched *config.Manager) error {
+	taskName := taskDisplayNames[task.Type]
+
+	fmt.Println()
+	fmt.Printf("▶️ %s（%s）\n", task.Name, taskName)
+	fmt.Println("─────────────────")
+
+	migrationService, err := initializeMigrationServiceWith(watched)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		return err
+	}
+
+	// 任务自带的标签和备注覆盖命令行参数
+	metadata := make(map[string]string)
+	for key, value := range migrationService.GetState().Metadata {
+		metadata[key] = value
+	}
+	for key, value := range task.Tags {
+		metadata[key] = value
+	}
+	if task.Note != "" {
+		metadata[service.MetadataNoteKey] = task.Note
+	}
+	metadata[queueTaskMetadataKey] = task.Name
+	migrationService.SetMetadata(metadata)
+
+	migrationTypes, err := resolveTaskMigrationTypes(migrationService, task.Type)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		return err
+	}
+
+	taskCtx, taskCancel := context.WithTimeout(ctx, migrateTimeout)
+	defer taskCancel()
+
+	results, err := executeMigrationWithProgress(taskCtx, migrationService, migrationTypes, taskName)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		return err
+	}
+	showMigrationResults(results, taskName, migrationService.GetState().Metadata)
+	return nil
+}
+
+// printTaskQueue 显示任务队列计划，按执行顺序排列
+func printTaskQueue(queue *service.TaskQueue) {
+	fmt.Printf("📋 共 %d 个任务", len(queue.Tasks))
+	if queue.ContinueOnError {
+		fmt.Print("（任务失败后继续执行）")
+	}
+	fmt.Println()
+	for i, task := range queue.ExecutionOrder() {
+		when := "上一任务结束后立即执行"
+		if !task.StartAt.IsZero() {
+			when = "不早于 " + task.StartAt.Format("2006-01-02 15:04:05")
+		}
+		priority := ""
+		if task.Priority != service.TaskPriorityNormal {
+			priority = fmt.Sprintf("[%s] ", task.Priority)
+		}
+		fmt.Printf("  %d. %s%s（%s）%s\n", i+1, priority, task.Name, taskDisplayNames[task.Type], when)
+	}
+}
+
+// printTaskQueueSummary 显示队列执行结果，全部成功时返回true
+func printTaskQueueSummary(results []*service.TaskResult) bool {
+	fmt.Println()
+	fmt.Println("📊 任务队列结果")
+	fmt.Println("─────────────────")
+
+	success := true
+	for i, result := range results {
+		switch {
+		case result.Skipped:
+			success = false
+			fmt.Printf("⏭️ %d. %s: 已跳过\n", i+1, result.Task.Name)
+		case result.Err != nil:
+			success = false
+			fmt.Printf("❌ %d. %s: 失败（耗时 %v）\n", i+1, result.Task.Name, result.EndTime.Sub(result.StartTime).Truncate(time.Second))
+		default:
+			fmt.Printf("✅ %d. %s: 完成（耗时 %v）\n", i+1, result.Task.Name, result.EndTime.Sub(result.StartTime).Truncate(time.Second))
+		}
+	}
+	return success
+}
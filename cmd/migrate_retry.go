@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+var migrateRetryAllowConfigChange bool
+
+// migrateRetryCmd 重试上次迁移中失败的类型
+var migrateRetryCmd = &cobra.Command{
+	Use:   "重试",
+	Short: "只重新执行上次迁移中失败的类型",
+	Long: `读取最近一次迁移的历史记录，只重新执行其中状态为 FAILED 的迁移类型，不重跑已成功的类型。
+
+与 --resume 的区别：
+• 重试：重新执行失败的类型；因取消等原因没有执行到的类型不在重试范围内
+• --resume：按检查点跳过已完成的类型和表，继续执行中断的迁移
+
+重试前会比较当前配置与上次迁移时的配置指纹（源库、目标库、迁移选项，不含密码），
+配置变化时拒绝重试，避免用不同的配置补跑一部分类型；确认需要时指定 --allow-config-change。
+重试结果会记录到迁移历史，仍有失败时可再次执行本命令。可同时指定 --resume，跳过失败类型中已完成的表。
+
+示例：
+  ora2pg-admin 迁移 重试
+  ora2pg-admin 迁移 重试 --resume`,
+	Args: cobra.NoArgs,
+	Run:  runMigrateRetry,
+}
+
+func init() {
+	migrateCmd.AddCommand(migrateRetryCmd)
+
+	migrateRetryCmd.Flags().BoolVar(&migrateRetryAllowConfigChange, "allow-config-change", false, "配置与上次迁移时不同也继续重试")
+}
+
+// runMigrateRetry 重试上次迁移中失败的类型
+func runMigrateRetry(cmd *cobra.Command, args []string) {
+	logger := utils.GetGlobalLogger()
+
+	detachIfRequested()
+
+	fmt.Println("🔁 重试失败的迁移类型")
+	fmt.Println()
+
+	if err := waitForSchedule(); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	migrationService, err := initializeMigrationService()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	plan, err := service.PlanRetry(service.DefaultHistoryPath, migrationService.GetConfig())
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	record := plan.Record
+	fmt.Printf("📜 上次迁移: %s（运行ID %s，%s）\n", record.Task, record.RunID, record.StartTime.Format("2006-01-02 15:04:05"))
+	if len(plan.Failed) == 0 {
+		fmt.Println("✅ 上次迁移没有失败的类型，无需重试")
+		if record.Status == service.StatusCancelled {
+			fmt.Println("💡 上次迁移被取消，未执行的类型可使用 --resume 续传")
+		}
+		return
+	}
+
+	fmt.Printf("❌ 失败的类型（%d 个）:\n", len(plan.Failed))
+	for _, result := range plan.Failed {
+		if result.Error != "" {
+			fmt.Printf("   • %s: %s\n", result.Type, result.Error)
+		} else {
+			fmt.Printf("   • %s\n", result.Type)
+		}
+	}
+	fmt.Println()
+
+	// 分批迁移的批次由 '迁移 分批' 按计划重新执行，保证切片范围的清理和批次进度一致
+	if batch := record.Metadata[service.BatchMetadataKey]; batch != "" {
+		fmt.Printf("%s\n", utils.FormatError(utils.NewError(utils.ErrorTypeUser, "RETRY_BATCH_RUN").
+			Message(fmt.Sprintf("上次迁移是分批迁移的批次 %s", batch)).
+			Suggestion("执行 'ora2pg-admin 迁移 分批' 从失败的批次继续，已完成的批次会被跳过").
+			Suggestion("只重新执行该批次时指定 --batch 和批次序号").
+			Build()))
+		exit(1)
+	}
+
+	switch {
+	case plan.ConfigChanged && !migrateRetryAllowConfigChange:
+		fmt.Printf("%s\n", utils.FormatError(utils.NewError(utils.ErrorTypeConfig, "RETRY_CONFIG_CHANGED").
+			Message("配置与上次迁移时不同，已拒绝重试").
+			Details(fmt.Sprintf("运行ID %s 的配置指纹与当前配置不一致", record.RunID)).
+			Suggestion("恢复上次迁移时的配置后重试，或重新执行完整的迁移命令").
+			Suggestion("确认配置变化不影响已成功的类型时，指定 --allow-config-change").
+			Build()))
+		exit(1)
+	case plan.ConfigChanged:
+		fmt.Println("⚠️ 配置与上次迁移时不同，按 --allow-config-change 继续重试")
+	case plan.FingerprintMissing:
+		fmt.Println("⚠️ 上次迁移的记录中没有配置指纹，无法确认配置是否变化")
+	}
+
+	// 上次是按模块迁移时，重试同样只迁移该模块的对象
+	if module := record.Metadata[service.ModuleMetadataKey]; module != "" {
+		if err := migrationService.SetModule(module); err != nil {
+			fmt.Printf("%s\n", utils.FormatError(err))
+			exit(1)
+		}
+		fmt.Printf("🧩 上次迁移的模块: %s\n", module)
+	}
+	migrationService.SetRetry(record)
+	ctx, cancel := createMigrationContext()
+	defer cancel()
+
+	taskName := "重试失败类型"
+	results, err := executeMigrationWithProgress(ctx, migrationService, plan.Types(), taskName)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	showMigrationResults(results, taskName, migrationService.GetState().Metadata)
+
+	logger.Info("重试失败类型完成")
+	exitWithMigrationResults(results)
+}
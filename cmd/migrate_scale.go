@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+// progressScaleTimeout 估算迁移规模的超时时间，超时后进度按类型等权计算
+const progressScaleTimeout = 30 * time.Second
+
+var migrateEstimateProgress bool
+
+func init() {
+	migrateCmd.PersistentFlags().BoolVar(&migrateEstimateProgress, "estimate-progress", true, "迁移前从源库统计各类型的规模，按工作量加权计算进度")
+}
+
+// estimateProgressScale 估算源库规模，使总进度按各类型的对象数量和数据量加权；无法估算时按类型等权
+func estimateProgressScale(ctx context.Context, migrationService *service.MigrationService, migrationTypes []service.MigrationType) {
+	if !migrateEstimateProgress || len(migrationTypes) < 2 {
+		return
+	}
+
+	scaleCtx, cancel := context.WithTimeout(ctx, progressScaleTimeout)
+	defer cancel()
+	scale, err := service.EstimateMigrationScale(scaleCtx, migrationService.GetConfig())
+	if err != nil {
+		utils.GetGlobalLogger().Warnf("估算迁移规模失败，进度按类型等权计算: %v", err)
+		fmt.Println("⚠️ 无法估算各类型的规模，进度按类型等权计算")
+		return
+	}
+
+	migrationService.SetProgressScale(scale)
+	fmt.Printf("📏 进度按源库规模加权: %d 个对象，约 %d 行数据\n", scale.Objects.Total(), scale.Data.Rows)
+	if scale.Data.Unanalyzed > 0 {
+		fmt.Printf("   %d 张表没有统计信息，数据量可能被低估（可使用 --gather-stats 收集）\n", scale.Data.Unanalyzed)
+	}
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/utils"
+)
+
+// sourceWriteCheckTimeout 迁移数据前检查源库写入状态的超时时间
+const sourceWriteCheckTimeout = 30 * time.Second
+
+var migrateCheckSourceWrites bool
+
+func init() {
+	migrateCmd.PersistentFlags().BoolVar(&migrateCheckSourceWrites, "check-source-writes", true, "迁移数据前检查源库是否只读、是否有未提交的写事务（只警告不中止）")
+}
+
+// preflightSourceWrites 检查源库是否处于只读或静默状态
+func preflightSourceWrites(ctx context.Context, env *preflightEnv, section *checkSection) {
+	if !env.oracleReady(section, "source_writes") {
+		return
+	}
+
+	activity, err := oracle.NewInspector(env.sqlplusRunner(), env.oracleSchema()).WriteActivity(ctx)
+	switch {
+	case err != nil:
+		section.Add("source_writes", checkStatusWarn, "无法检查源库是否仍在写入", err.Error(),
+			"需要 V$DATABASE、V$TRANSACTION、V$SESSION 的查询权限（如 SELECT_CATALOG_ROLE）")
+	case activity.Writing():
+		section.Add("source_writes", checkStatusWarn, "源库仍在接受写入，迁移数据可能不一致",
+			strings.Join(activity.Sessions(), "; "), oracle.ReadOnlySuggestions(env.oracleSchema())...)
+	case activity.ReadOnly():
+		section.Add("source_writes", checkStatusPass, activity.Summary(), "")
+	default:
+		section.Add("source_writes", checkStatusPass, activity.Summary(), "正式切换迁移时，建议将源库置为只读，避免迁移期间的新数据丢失")
+	}
+}
+
+// warnSourceWrites 迁移数据前检查源库是否仍在接受写入，只提示不中止迁移
+func warnSourceWrites(ctx context.Context, cfg *config.ProjectConfig) {
+	if !migrateCheckSourceWrites {
+		return
+	}
+	schema := cfg.Oracle.Schema
+	if schema == "" {
+		schema = cfg.Oracle.Username
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, sourceWriteCheckTimeout)
+	defer cancel()
+	runner := oracle.NewSQLPlusRunner(&cfg.Oracle, &cfg.OracleClient)
+	activity, err := oracle.NewInspector(runner, schema).WriteActivity(checkCtx)
+	if err != nil {
+		utils.GetGlobalLogger().Warnf("检查源库写入状态失败: %v", err)
+		fmt.Println("⚠️ 无法检查源库是否仍在写入，请确认迁移期间源库已停止写入（--check-source-writes=false 跳过检查）")
+		return
+	}
+	if !activity.Writing() {
+		fmt.Printf("🔒 %s\n", activity.Summary())
+		return
+	}
+
+	utils.GetGlobalLogger().Warnf("%s: %s", activity.Summary(), strings.Join(activity.Sessions(), "; "))
+	fmt.Println("⚠️ 源库仍在接受写入，迁移数据可能不一致")
+	for _, session := range activity.Sessions() {
+		fmt.Printf("   • %s\n", session)
+	}
+	fmt.Println("💡 迁移期间的新数据不会被迁移，正式切换前建议将源库置为只读：")
+	for _, suggestion := range oracle.ReadOnlySuggestions(schema) {
+		fmt.Printf("   %s\n", suggestion)
+	}
+}
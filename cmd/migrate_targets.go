@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+var migrateTargets []string
+
+func init() {
+	migrateCmd.PersistentFlags().StringSliceVar(&migrateTargets, "targets", nil, "配置了多个目标库时只迁移指定名称的目标（逗号分隔），默认并行迁移全部目标")
+}
+
+// multiTargetEnabled 是否按多目标方式迁移：配置了多个目标，或用 --targets 指定了目标
+func multiTargetEnabled(migrationService *service.MigrationService) bool {
+	return migrationService.GetConfig().HasMultipleTargets() || len(migrateTargets) > 0
+}
+
+// executeMultiTargetMigration 并行向多个目标库迁移，各目标独立执行，部分目标失败不影响其他目标
+//
+// 返回全部目标的执行结果，连接失败或无法开始执行的目标记为一个失败的结果，用于确定退出码。
+func executeMultiTargetMigration(ctx context.Context, migrationService *service.MigrationService,
+	migrationTypes []service.MigrationType, taskName string) ([]*service.ExecutionResult, error) {
+
+	cfg := migrationService.GetConfig()
+	targets, err := cfg.SelectTargets(migrateTargets)
+	if err != nil {
+		return nil, utils.NewError(utils.ErrorTypeUser, "TARGET_NOT_FOUND").
+			Message("--targets 指定的目标不存在").
+			Details(err.Error()).
+			Suggestion("在配置文件的 postgresql.targets 中为目标指定名称，主目标未命名时为 primary").
+			Build()
+	}
+
+	runs := migrationService.NewTargetRuns(targets)
+	fmt.Printf("🎯 并行迁移到 %d 个目标:\n", len(runs))
+	for _, run := range runs {
+		fmt.Printf("   • %s（%s），输出目录 %s\n", run.Name, run.Endpoint, run.Service.GetConfig().Migration.OutputDir)
+	}
+
+	// 预演不连接目标库，其余情况先分别测试各目标的连接，连接失败的目标不执行
+	if !dryRun {
+		tester := oracle.NewConnectionTester()
+		tester.SetClientConfig(&cfg.OracleClient)
+		for _, run := range runs {
+			if run.CheckConnection(tester) {
+				fmt.Printf("   ✅ %s 连接正常\n", run.Name)
+			} else {
+				fmt.Printf("   ❌ %s 连接失败，跳过该目标\n", run.Name)
+			}
+		}
+	}
+	if (migrateUpload && cfg.Migration.RemoteStorage.Enabled()) || migrateArchive {
+		fmt.Println("💡 多目标迁移不上传、不归档输出，需要时用 --targets 逐个目标执行")
+	}
+
+	fmt.Printf("📋 开始执行%s，共 %d 个目标、每个目标 %d 个步骤\n", taskName, len(runs), len(migrationTypes))
+	fmt.Printf("🔖 运行ID: %s\n", utils.RunID())
+	fmt.Println()
+	utils.GetGlobalLogger().Infof("开始%s，目标数量: %d，运行ID: %s", taskName, len(runs), utils.RunID())
+
+	progressTracker := service.NewProgressTracker()
+	progressTracker.SetStatePath(service.DefaultProgressStatePath)
+	if recordProgress := detachedProgressHandler(taskName, len(runs)); recordProgress != nil {
+		// 后台运行时同时记录进度，供 '迁移 状态' 查看
+		progressTracker.SetUpdateHandler(recordProgress)
+	}
+	progressTracker.Start(fmt.Sprintf("%s（%d 个目标）", taskName, len(runs)), len(runs))
+	service.ExecuteTargets(ctx, runs, migrationTypes, progressTracker)
+	progressTracker.Stop()
+
+	notifier := service.NewNotifier(cfg)
+	// 各目标的错误合并到一个报告中，按目标名称区分
+	errorReport := service.BuildErrorReport(taskName, migrationTypes, nil, nil)
+	errorReport.Project = cfg.Project.Name
+	var results []*service.ExecutionResult
+	for _, run := range runs {
+		fmt.Println()
+		fmt.Printf("── 目标 %s ──\n", run.Name)
+		showConstraintResult(run.Service)
+		showScriptResults(run.Service)
+		showAssertionResults(run.Service)
+		showSQLCheckReport(run.Service)
+		showMViewRefreshPlan(run.Service)
+		errorReport.AddTarget(run.Name, run.Service.ErrorReport(taskName, migrationTypes, run.Err))
+		if migrateMonitor {
+			if stats := run.Service.GetResourceStats(); stats != nil && run.Service.ResourceMonitorSupported() {
+				fmt.Printf("📈 资源使用: %s\n", stats.Summary())
+			}
+		}
+
+		// 每个目标记录一条迁移历史，元数据中带有目标名称
+		if len(run.Results) > 0 {
+			record, historyErr := run.Service.RecordHistory(taskName, migrationTypes, run.Err)
+			if historyErr != nil {
+				fmt.Printf("⚠️ 记录迁移历史失败:\n%s\n", utils.FormatError(historyErr))
+			} else if notifyErr := notifier.NotifyMigrationFinished(record); notifyErr != nil {
+				fmt.Printf("⚠️ 发送迁移通知失败:\n%s\n", utils.FormatError(notifyErr))
+			}
+		}
+
+		if run.Succeeded() {
+			if dryRun {
+				showDryRunDiff(ctx, run.Service)
+			} else if migrateAnalyze {
+				analyzeTargetDatabase(ctx, run.Service, migrationTypes)
+			}
+		}
+
+		results = append(results, run.Results...)
+		// 连接失败或断言等导致目标失败、但各类型都成功时补一个失败的结果，保证退出码非0
+		if run.Err != nil && run.Failed() == 0 {
+			now := time.Now()
+			results = append(results, &service.ExecutionResult{Status: service.StatusFailed, StartTime: now, EndTime: now, Error: run.Err})
+		}
+	}
+	if _, metricsErr := migrationService.ExportMetrics(); metricsErr != nil {
+		fmt.Printf("⚠️ 更新迁移指标失败:\n%s\n", utils.FormatError(metricsErr))
+	}
+
+	showTargetRuns(runs)
+	exportMigrationErrors(errorReport)
+	return results, nil
+}
+
+// showTargetRuns 显示各目标的迁移结果
+func showTargetRuns(runs []*service.TargetRun) {
+	fmt.Println()
+	fmt.Println("🎯 各目标结果")
+	fmt.Println("─────────────────")
+	var failed []string
+	for _, run := range runs {
+		icon := "✅"
+		if !run.Succeeded() {
+			icon = "❌"
+			failed = append(failed, run.Name)
+		}
+		fmt.Printf("%s %s（%s）: %d 个类型", icon, run.Name, run.Endpoint, len(run.Results))
+		if n := run.Failed(); n > 0 {
+			fmt.Printf("，%d 个未成功", n)
+		}
+		fmt.Printf("，耗时 %v\n", run.Duration.Round(time.Second))
+		if run.Err != nil {
+			fmt.Printf("%s\n", utils.FormatError(run.Err))
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Printf("⚠️ %d/%d 个目标未全部成功: %s；修复后可用 --targets %s 只重新迁移这些目标\n",
+			len(failed), len(runs), strings.Join(failed, ", "), strings.Join(failed, ","))
+	} else {
+		fmt.Printf("🎉 %d 个目标全部迁移成功\n", len(runs))
+	}
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"ora2pg-admin/internal/service"
+)
+
+func TestMigrationExitCode(t *testing.T) {
+	completed := &service.ExecutionResult{Status: service.StatusCompleted}
+	failed := &service.ExecutionResult{Status: service.StatusFailed}
+	cancelled := &service.ExecutionResult{Status: service.StatusCancelled}
+
+	assert.Equal(t, 0, migrationExitCode(nil))
+	assert.Equal(t, 0, migrationExitCode([]*service.ExecutionResult{completed, completed}))
+	assert.Equal(t, exitCodeMigrationFailed, migrationExitCode([]*service.ExecutionResult{failed, cancelled}))
+	assert.Equal(t, defaultPartialFailureExitCode, migrationExitCode([]*service.ExecutionResult{completed, failed}))
+	assert.Equal(t, defaultPartialFailureExitCode, migrationExitCode([]*service.ExecutionResult{completed, cancelled}))
+
+	// 部分失败的退出码可配置，0 表示部分失败也视为成功
+	original := migratePartialExitCode
+	defer func() { migratePartialExitCode = original }()
+	migratePartialExitCode = 0
+	assert.Equal(t, 0, migrationExitCode([]*service.ExecutionResult{completed, failed}))
+	migratePartialExitCode = 3
+	assert.Equal(t, 3, migrationExitCode([]*service.ExecutionResult{completed, failed}))
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ora2pg-admin/internal/service"
+)
+
+// throughputChartFile 吞吐率图表文件，位于输出目录
+const throughputChartFile = "throughput.svg"
+
+// 终端中吞吐率图表的尺寸
+const (
+	throughputChartWidth  = 60
+	throughputChartHeight = 8
+)
+
+// showThroughputChart 显示迁移过程中吞吐率随时间的变化和卡顿时段，并保存SVG图表；没有迁移数据行时不显示
+func showThroughputChart(migrationService *service.MigrationService, progressTracker *service.ProgressTracker) {
+	report := progressTracker.ThroughputReport()
+	if report == nil {
+		return
+	}
+
+	fmt.Println()
+	fmt.Printf("📊 吞吐率: %s\n", report.Summary())
+	for _, line := range report.Chart(throughputChartWidth, throughputChartHeight) {
+		fmt.Printf("   %s\n", line)
+	}
+	if len(report.Stalls) > 0 {
+		fmt.Printf("🐢 %d 个吞吐明显下降的时段:\n", len(report.Stalls))
+		for _, stall := range report.Stalls {
+			fmt.Printf("   • %v - %v 平均 %.0f 行/秒：%s\n", stall.Start.Round(time.Second), stall.End.Round(time.Second),
+				stall.RowsPerSecond, stall.Hint())
+		}
+	}
+	if !report.HasResource() {
+		fmt.Println("💡 加 --monitor 同时采集CPU和内存，可判断卡顿时段受CPU还是IO限制")
+	}
+
+	path := filepath.Join(migrationService.GetConfig().Migration.OutputDir, throughputChartFile)
+	if err := os.WriteFile(path, []byte(report.SVG()), 0644); err != nil {
+		fmt.Printf("⚠️ 保存吞吐率图表失败: %v\n", err)
+		return
+	}
+	fmt.Printf("📝 吞吐率图表已保存: %s\n", path)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+var migrateUpload bool
+
+func init() {
+	migrateCmd.PersistentFlags().BoolVar(&migrateUpload, "upload", true, "配置了 migration.remote_storage 时，迁移成功后把输出目录上传到远程存储（--upload=false 跳过）")
+}
+
+// uploadMigrationOutput 上传迁移输出到远程存储，失败时仅提示，本地输出保留
+//
+// local_files 为 delete 时，只有全部迁移类型成功且全部文件上传成功才删除本地文件。
+func uploadMigrationOutput(ctx context.Context, migrationService *service.MigrationService, results []*service.ExecutionResult) {
+	cleanLocal := true
+	for _, result := range results {
+		if !result.Status.Succeeded() {
+			cleanLocal = false
+			break
+		}
+	}
+	storage := &migrationService.GetConfig().Migration.RemoteStorage
+	if storage.DeleteLocal() && !cleanLocal {
+		fmt.Println("⚠️ 存在未成功的迁移类型，上传后保留本地输出文件")
+	}
+
+	fmt.Printf("⬆️ 正在上传迁移输出到远程存储（%s）...\n", storage.StorageType())
+	// 每完成约 10% 显示一次进度
+	nextPercent := 10
+	report, err := migrationService.UploadOutput(ctx, cleanLocal, func(progress service.RemoteUploadProgress) {
+		percent := 100
+		if progress.TotalFiles > 0 {
+			percent = progress.Files * 100 / progress.TotalFiles
+		}
+		if percent >= nextPercent && percent < 100 {
+			fmt.Printf("   已上传 %s\n", progress)
+			nextPercent = percent/10*10 + 10
+		}
+	})
+	if err != nil {
+		fmt.Printf("⚠️ 上传迁移输出失败:\n%s\n", utils.FormatError(err))
+		return
+	}
+	fmt.Printf("✅ 已上传 %d 个文件到 %s（%s，耗时 %v）\n", report.Files, report.Target,
+		report.Size(), report.Duration.Round(time.Millisecond))
+	if report.Deleted > 0 {
+		fmt.Printf("🧹 已删除已上传的本地文件 %d 个\n", report.Deleted)
+	}
+}
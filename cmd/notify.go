@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+var (
+	notifyFormat   string
+	notifySend     bool
+	notifySendBots bool
+)
+
+// notifyCmd 迁移结果通知命令
+var notifyCmd = &cobra.Command{
+	Use:   "通知",
+	Short: "迁移结果通知相关操作",
+	Long:  "预览和测试迁移结果通知，通知配置位于配置文件的 notifications 部分。",
+}
+
+// notifyPreviewCmd 预览通知内容
+var notifyPreviewCmd = &cobra.Command{
+	Use:   "预览",
+	Short: "用最近一次迁移记录渲染通知内容",
+	Long: `用最近一次迁移历史渲染通知邮件的主题和正文，便于调试自定义模板；没有历史记录时使用示例数据。
+自定义模板渲染失败时会显示原因和回退后的内置模板内容。配置了群机器人时同时显示机器人消息的正文。
+
+示例：
+  ora2pg-admin 通知 预览
+  ora2pg-admin 通知 预览 --format html
+  ora2pg-admin 通知 预览 --send
+  ora2pg-admin 通知 预览 --send-bots`,
+	Run: runNotifyPreview,
+}
+
+func init() {
+	rootCmd.AddCommand(notifyCmd)
+	notifyCmd.AddCommand(notifyPreviewCmd)
+
+	notifyPreviewCmd.Flags().StringVar(&notifyFormat, "format", "", "正文格式 (text, html)，默认使用配置")
+	notifyPreviewCmd.Flags().BoolVar(&notifySend, "send", false, "按配置实际发送一封测试邮件")
+	notifyPreviewCmd.Flags().BoolVar(&notifySendBots, "send-bots", false, "按配置向订阅了该结果的群机器人实际发送消息")
+}
+
+// runNotifyPreview 渲染并显示通知内容
+func runNotifyPreview(cmd *cobra.Command, args []string) {
+	manager, _, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	cfg := manager.GetConfig()
+
+	emailConfig := cfg.Notifications.Email
+	switch strings.ToLower(notifyFormat) {
+	case "":
+	case config.NotificationFormatText, config.NotificationFormatHTML:
+		emailConfig.Format = notifyFormat
+	default:
+		fmt.Printf("%s\n", utils.FormatError(utils.ConfigErrors.InvalidValue("format", notifyFormat)))
+		exit(1)
+	}
+
+	records, err := service.LoadHistory(service.DefaultHistoryPath, nil)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	record := sampleNotificationRecord()
+	if len(records) > 0 {
+		record = records[len(records)-1]
+	} else {
+		fmt.Println("💡 暂无迁移历史，使用示例数据预览")
+	}
+
+	message, err := service.RenderNotification(&emailConfig, service.NewNotificationData(cfg, record))
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	if message.FallbackReason != "" {
+		fmt.Printf("⚠️ 自定义模板渲染失败，已使用内置模板: %s\n", message.FallbackReason)
+	}
+
+	fmt.Printf("📧 主题: %s\n", message.Subject)
+	fmt.Println("─────────────────")
+	fmt.Println(message.Body)
+
+	if len(cfg.Notifications.Bots) > 0 {
+		botMessage := service.NewChatBotFinishMessage(service.NewNotificationData(cfg, record)).Sanitized(utils.GetGlobalLogger())
+		fmt.Println()
+		fmt.Printf("🤖 群机器人消息（%d 个机器人）: %s\n", len(cfg.Notifications.Bots), botMessage.Title)
+		fmt.Println("─────────────────")
+		fmt.Println(botMessage.Markdown())
+	}
+
+	if notifySendBots {
+		sendPreviewChatBots(cfg, record)
+	}
+	if !notifySend {
+		return
+	}
+	if !emailConfig.Enabled {
+		fmt.Println("❌ 未启用邮件通知，请在配置中设置 notifications.email.enabled: true")
+		exit(1)
+	}
+	cfg.Notifications.Email = emailConfig
+	if err := service.NewNotifier(cfg).SendEmail(message); err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	fmt.Printf("✅ 测试邮件已发送给: %s\n", strings.Join(emailConfig.To, ", "))
+}
+
+// sendPreviewChatBots 向群机器人发送预览的结果消息
+func sendPreviewChatBots(cfg *config.ProjectConfig, record *service.HistoryRecord) {
+	if len(cfg.Notifications.Bots) == 0 {
+		fmt.Println("❌ 未配置群机器人，请在配置中添加 notifications.bots")
+		exit(1)
+	}
+	errs := service.NewNotifier(cfg).NotifyChatBotsFinished(record)
+	showChatBotErrors(errs)
+	if len(errs) > 0 {
+		exit(1)
+	}
+	fmt.Println("✅ 已向订阅了该结果的群机器人发送消息")
+}
+
+// showChatBotErrors 显示群机器人发送失败的原因，失败不影响迁移结果
+func showChatBotErrors(errs []error) {
+	for _, err := range errs {
+		fmt.Printf("⚠️ 发送群机器人消息失败:\n%s\n", utils.FormatError(err))
+	}
+}
+
+// sampleNotificationRecord 没有历史记录时用于预览的示例数据
+func sampleNotificationRecord() *service.HistoryRecord {
+	start := time.Now().Add(-30 * time.Minute)
+	return &service.HistoryRecord{
+		RunID:     utils.RunID(),
+		Task:      "完整迁移",
+		Status:    service.StatusFailed,
+		StartTime: start,
+		EndTime:   start.Add(25 * time.Minute),
+		Duration:  25 * time.Minute,
+		Metadata:  map[string]string{"ticket": "示例-1", service.MetadataNoteKey: "示例数据"},
+		Results: []service.HistoryTypeResult{
+			{Type: service.MigrationTypeTable, Status: service.StatusCompleted, Duration: 3 * time.Minute},
+			{Type: service.MigrationTypeCopy, Status: service.StatusCompleted, Duration: 20 * time.Minute, WarningCount: 2},
+			{Type: service.MigrationTypeIndex, Status: service.StatusFailed, Duration: 2 * time.Minute, Error: "ERROR: relation \"emp\" does not exist"},
+		},
+	}
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+// progressInventoryTimeout 统计源库对象数量的超时时间
+const progressInventoryTimeout = 2 * time.Minute
+
+var (
+	progressOutput  string
+	progressSave    string
+	progressOffline bool
+	progressTags    []string
+)
+
+// progressCmd 项目整体迁移进度快照
+var progressCmd = &cobra.Command{
+	Use:   "进度",
+	Short: "生成项目整体迁移完成度快照",
+	Long: `汇总迁移历史中多次运行的结果，结合源库对象数量，按迁移类型计算已迁移对象占总对象的百分比。
+
+迁移类型在任意一次运行中成功即视为该类型的对象全部迁移；数据迁移尚未成功时，
+按检查点中已完成的表计算部分进度。源库无法连接或使用 --offline 时只按类型统计完成度。
+
+示例：
+  ora2pg-admin 进度
+  ora2pg-admin 进度 --output json --save reports/progress-week12.json
+  ora2pg-admin 进度 --tag phase=1 --offline`,
+	Run: runProgress,
+}
+
+func init() {
+	rootCmd.AddCommand(progressCmd)
+
+	progressCmd.Flags().StringVarP(&progressOutput, "output", "o", checkOutputText, "输出格式 (text, json)")
+	progressCmd.Flags().StringVar(&progressSave, "save", "", "同时把快照保存到指定文件")
+	progressCmd.Flags().BoolVar(&progressOffline, "offline", false, "不连接源库统计对象数量")
+	progressCmd.Flags().StringArrayVar(&progressTags, "tag", nil, "只汇总带指定标签的运行记录，格式 key=value，可重复指定")
+}
+
+// runProgress 生成并输出迁移进度快照
+func runProgress(cmd *cobra.Command, args []string) {
+	jsonOutput := false
+	switch strings.ToLower(progressOutput) {
+	case checkOutputText:
+	case checkOutputJSON:
+		jsonOutput = true
+		if utils.GetGlobalLogger().WritesToStdout() {
+			utils.GetGlobalLogger().SetOutput("stderr")
+		}
+	default:
+		fmt.Printf("%s\n", utils.FormatError(utils.ConfigErrors.InvalidValue("output", progressOutput)))
+		exit(1)
+	}
+
+	manager, _, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	cfg := manager.GetConfig()
+
+	tags, err := service.ParseMetadataTags(progressTags)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	records, err := service.LoadHistory(service.DefaultHistoryPath, tags)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	// 检查点只用于计算数据迁移的部分进度，不存在时忽略
+	checkpoint, err := service.LoadCheckpoint(service.DefaultCheckpointPath)
+	if err != nil && utils.GetErrorCode(err) != "CHECKPOINT_NOT_FOUND" {
+		utils.GetGlobalLogger().Warnf("读取迁移检查点失败，不计算部分进度: %v", err)
+	}
+
+	schema := cfg.Oracle.Schema
+	if schema == "" {
+		schema = cfg.Oracle.Username
+	}
+	inspection, inventoryErr := inspectSourceSchema(cfg, schema)
+	var inventory oracle.ObjectInventory
+	if inspection != nil {
+		inventory = inspection.Objects
+	}
+
+	types := make([]service.MigrationType, 0, len(cfg.Migration.Types))
+	for _, migrationType := range cfg.Migration.Types {
+		types = append(types, service.MigrationType(strings.ToUpper(migrationType)))
+	}
+	snapshot := service.BuildProgressSnapshot(types, records, inventory, checkpoint)
+	snapshot.Project = cfg.Project.Name
+	snapshot.Schema = strings.ToUpper(schema)
+	snapshot.InventoryError = inventoryErr
+	if inspection != nil {
+		snapshot.Charset = inspection.Charset
+	}
+
+	var content string
+	if jsonOutput {
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			fmt.Printf("%s\n", utils.FormatError(fmt.Errorf("序列化进度快照失败: %v", err)))
+			exit(1)
+		}
+		content = string(data) + "\n"
+	} else {
+		content = snapshot.FormatText()
+		fmt.Println("📈 迁移进度快照")
+		fmt.Println("─────────────────")
+	}
+	fmt.Print(content)
+
+	if progressSave != "" {
+		if err := utils.NewFileUtils().EnsureDir(filepath.Dir(progressSave)); err != nil {
+			fmt.Printf("%s\n", utils.FormatError(utils.FileErrors.CreateFailed(progressSave, err)))
+			exit(1)
+		}
+		if err := os.WriteFile(progressSave, []byte(content), 0644); err != nil {
+			fmt.Printf("%s\n", utils.FormatError(utils.FileErrors.WriteFailed(progressSave, err)))
+			exit(1)
+		}
+		if !jsonOutput {
+			fmt.Printf("\n💾 快照已保存: %s\n", progressSave)
+		}
+	}
+}
+
+// inspectSourceSchema 统计源库对象数量和字符集，失败时返回原因供快照说明
+func inspectSourceSchema(cfg *config.ProjectConfig, schema string) (*oracle.SchemaInspection, string) {
+	if progressOffline {
+		return nil, "已跳过源库对象统计（--offline），完成度按迁移类型计算"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), progressInventoryTimeout)
+	defer cancel()
+
+	inspector := oracle.NewInspector(oracle.NewSQLPlusRunner(&cfg.Oracle, &cfg.OracleClient), schema)
+	inspection, err := inspector.Inspect(ctx)
+	if err != nil {
+		utils.GetGlobalLogger().Debugf("统计源库对象数量失败: %v", err)
+		return nil, fmt.Sprintf("无法统计源库对象数量，完成度按迁移类型计算: %v", err)
+	}
+	return inspection, ""
+}
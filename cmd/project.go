@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+var projectImportForce bool
+
+// projectCmd 项目管理命令
+var projectCmd = &cobra.Command{
+	Use:   "项目",
+	Short: "导出和导入迁移项目",
+	Long: `在团队成员间共享完整的迁移项目设置。
+
+导出内容包括项目配置、scripts/、templates/、docs/ 等自定义文件，
+不包括 logs/、output/、backup/ 等运行产物，配置中的密码会被清除
+（环境变量引用保持不变）。`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// projectExportCmd 导出项目
+var projectExportCmd = &cobra.Command{
+	Use:   "导出 <文件.tar.gz>",
+	Short: "将当前项目导出为项目包",
+	Args:  cobra.ExactArgs(1),
+	Run:   runProjectExport,
+}
+
+// projectImportCmd 导入项目
+var projectImportCmd = &cobra.Command{
+	Use:   "导入 <文件.tar.gz> [目标目录]",
+	Short: "从项目包恢复项目到新目录",
+	Long: `从项目包恢复迁移项目。未指定目标目录时使用项目包文件名作为目录名。
+
+目标目录已存在且不为空时默认拒绝导入，使用 --force 覆盖同名文件；
+覆盖已有项目时会保留其本地配置中的密码。`,
+	Args: cobra.RangeArgs(1, 2),
+	Run:  runProjectImport,
+}
+
+func init() {
+	rootCmd.AddCommand(projectCmd)
+	projectCmd.AddCommand(projectExportCmd)
+	projectCmd.AddCommand(projectImportCmd)
+
+	projectImportCmd.Flags().BoolVar(&projectImportForce, "force", false, "目标目录不为空时覆盖同名文件")
+}
+
+// runProjectExport 导出项目
+func runProjectExport(cmd *cobra.Command, args []string) {
+	fmt.Println("📦 导出迁移项目")
+	fmt.Println()
+
+	if !checkProjectDirectory() {
+		fmt.Printf("%s\n", utils.FormatError(utils.NewError(utils.ErrorTypeConfig, "PROJECT_NOT_INITIALIZED").
+			Message("项目未初始化").
+			Suggestion("请在项目目录中执行导出").
+			Build()))
+		exit(1)
+	}
+
+	manifest, err := service.ExportProject(".", args[0], version)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	fmt.Printf("✅ 已导出 %d 个文件: %s\n", len(manifest.Files), args[0])
+	for _, file := range manifest.Files {
+		fmt.Printf("  • %s\n", file)
+	}
+	fmt.Println()
+	fmt.Println("🔒 配置中的密码已清除，导入后请使用 'ora2pg-admin 配置 数据库' 重新填写")
+}
+
+// runProjectImport 导入项目
+func runProjectImport(cmd *cobra.Command, args []string) {
+	fmt.Println("📥 导入迁移项目")
+	fmt.Println()
+
+	archivePath := args[0]
+	targetDir := filepath.Base(archivePath)
+	for _, ext := range []string{".tar.gz", ".tgz"} {
+		targetDir = strings.TrimSuffix(targetDir, ext)
+	}
+	if len(args) > 1 {
+		targetDir = args[1]
+	}
+
+	manifest, err := service.ImportProject(archivePath, targetDir, projectImportForce)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	setAuditProjectDir(targetDir)
+
+	fmt.Printf("✅ 已导入项目 %s 到: %s\n", manifest.ProjectName, targetDir)
+	fmt.Printf("   导出时间: %s，导出工具版本: %s\n", manifest.ExportedAt.Format("2006-01-02 15:04:05"), manifest.ToolVersion)
+	fmt.Printf("   共 %d 个文件\n", len(manifest.Files))
+	fmt.Println()
+	fmt.Println("💡 后续步骤:")
+	fmt.Printf("  1. cd %s\n", targetDir)
+	fmt.Println("  2. ora2pg-admin 配置 数据库    # 填写数据库密码")
+	fmt.Println("  3. ora2pg-admin 检查 连接")
+}
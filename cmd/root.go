@@ -16,6 +16,9 @@ var (
 	quiet   bool
 	dryRun  bool
 	logFile string
+	yes     bool
+
+	noSanitizeLogs bool
 )
 
 // 版本信息
@@ -44,6 +47,15 @@ var rootCmd = &cobra.Command{
 		// 如果没有提供子命令，显示帮助信息
 		cmd.Help()
 	},
+	// 统一记录审计日志，命令失败通过 exit() 退出时同样会写入
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		startAudit(cmd, args)
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		releaseMigrationLock()
+		finishDetachedRun(0)
+		finishAudit(0)
+	},
 }
 
 // Execute 添加所有子命令到根命令并设置适当的标志
@@ -66,8 +78,11 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "配置文件路径 (默认为二进制文件同目录下的 .ora2pg-admin.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "显示详细输出")
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "静默模式")
-	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "预览模式，不执行实际操作")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "预演模式，迁移只生成SQL不写入目标库，并对比目标库现状")
 	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "指定日志文件路径")
+	rootCmd.PersistentFlags().BoolVarP(&yes, "yes", "y", false, "自动确认所有确认提示（用于脚本等非交互场景）")
+	rootCmd.PersistentFlags().BoolVar(&yes, "assume-yes", false, "同 --yes")
+	rootCmd.PersistentFlags().BoolVar(&noSanitizeLogs, "no-sanitize-logs", false, "关闭日志脱敏（仅限安全环境调试，会明文记录密码）")
 
 	// 将标志绑定到viper
 	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
@@ -78,8 +93,7 @@ func init() {
 
 // initConfig 读取配置文件和环境变量
 func initConfig() {
-	// 初始化日志系统
-	initLogger()
+	utils.SetAssumeYes(yes)
 
 	if cfgFile != "" {
 		// 使用命令行指定的配置文件
@@ -105,20 +119,31 @@ func initConfig() {
 	viper.AutomaticEnv() // 读取匹配的环境变量
 
 	// 如果找到配置文件，则读取它
+	viper.SetDefault("log.sanitize_secrets", true)
+	viper.SetDefault("log.output", "stdout")
 	if err := viper.ReadInConfig(); err == nil {
 		if verbose {
 			logrus.Infof("使用配置文件: %s", viper.ConfigFileUsed())
 		}
 	}
+
+	// 初始化日志系统（脱敏设置可来自配置文件）
+	initLogger()
 }
 
 // initLogger 初始化日志系统
 func initLogger() {
 	// 创建日志配置
 	logConfig := &utils.LogConfig{
-		Format:     "text",
-		Output:     "stdout",
+		Format: "text",
+		// 配置文件 log.output 可设为 syslog（Windows 上为事件日志）
+		Output:     viper.GetString("log.output"),
 		TimeFormat: "2006-01-02 15:04:05",
+		// 默认脱敏，可通过 --no-sanitize-logs 或配置文件 log.sanitize_secrets 关闭
+		DisableSanitize:  noSanitizeLogs || !viper.GetBool("log.sanitize_secrets"),
+		SanitizePatterns: viper.GetStringSlice("log.sanitize_patterns"),
+		SyslogFacility:   viper.GetString("log.syslog_facility"),
+		SyslogTag:        viper.GetString("log.syslog_tag"),
 	}
 
 	// 根据参数设置日志级别
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+var (
+	validateSample    int
+	validateTables    []string
+	validateTolerance float64
+	validateMaxDiffs  int
+)
+
+// validateCmd 迁移数据校验命令
+var validateCmd = &cobra.Command{
+	Use:   "校验",
+	Short: "抽样比对源库和目标库的数据内容",
+	Long: `从Oracle随机抽取若干行，按主键到PostgreSQL查询同一行，逐列比对规范化后的值。
+
+比对时 DATE/TIMESTAMP 统一格式化（带时区的类型转换为UTC），CHAR 忽略尾部空格，
+Oracle 空字符串视为 NULL，数值按十进制比较并允许 --tolerance 相对容差。
+LOB、RAW 等类型不参与比对，没有主键的表会被跳过。
+
+示例：
+  ora2pg-admin 校验 --sample 100
+  ora2pg-admin 校验 --sample 500 --table EMP --table DEPT`,
+	Run: runValidate,
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().IntVar(&validateSample, "sample", 100, "每张表随机抽取的行数")
+	validateCmd.Flags().StringArrayVar(&validateTables, "table", nil, "要校验的Oracle表名，可重复指定（默认为源模式下全部表）")
+	validateCmd.Flags().Float64Var(&validateTolerance, "tolerance", service.DefaultSampleTolerance, "数值比对的相对容差")
+	validateCmd.Flags().IntVar(&validateMaxDiffs, "max-diffs", 20, "每张表最多显示的不一致详情数（0表示全部）")
+}
+
+// runValidate 执行抽样校验
+func runValidate(cmd *cobra.Command, args []string) {
+	fmt.Println("🔬 数据抽样校验")
+	fmt.Println("─────────────────")
+
+	if validateSample <= 0 {
+		fmt.Printf("%s\n", utils.FormatError(utils.ValidationErrors.InvalidFormat("sample", fmt.Sprint(validateSample))))
+		exit(1)
+	}
+
+	manager, _, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	ctx := context.Background()
+	validator := service.NewSampleValidator(manager.GetConfig())
+	validator.SetTolerance(validateTolerance)
+
+	tables := validateTables
+	if len(tables) == 0 {
+		if tables, err = validator.SchemaTables(ctx); err != nil {
+			fmt.Printf("%s\n", utils.FormatError(err))
+			exit(1)
+		}
+	}
+	if len(tables) == 0 {
+		fmt.Println("源模式下没有可校验的表")
+		return
+	}
+	// 表结构在少量sqlplus会话中批量查询，失败时逐表查询
+	if err := validator.PrefetchColumns(ctx, tables); err != nil {
+		utils.GetGlobalLogger().Debugf("批量查询表结构失败，改为逐表查询: %v", err)
+	}
+	fmt.Printf("共 %d 张表，每张表抽取 %d 行\n", len(tables), validateSample)
+	fmt.Println("⚠️ 抽样使用 ORDER BY DBMS_RANDOM.VALUE，大表会在源库产生全表扫描")
+	fmt.Println()
+
+	passed, mismatched, skipped := 0, 0, 0
+	for _, table := range tables {
+		result, err := validator.ValidateTable(ctx, table, validateSample)
+		if err != nil {
+			skipped++
+			fmt.Printf("⚠️ %s: 已跳过\n%s\n", strings.ToUpper(table), utils.FormatError(err))
+			continue
+		}
+
+		if len(result.Mismatches) == 0 {
+			passed++
+			fmt.Printf("✅ %s: 抽样 %d 行全部一致\n", result.Table, result.Sampled)
+		} else {
+			mismatched++
+			fmt.Printf("❌ %s: 抽样 %d 行，%d 行一致，发现 %d 处不一致\n",
+				result.Table, result.Sampled, result.Matched, len(result.Mismatches))
+			printSampleMismatches(result.Mismatches)
+		}
+		if len(result.SkippedColumns) > 0 {
+			fmt.Printf("   未比对的列: %s\n", strings.Join(result.SkippedColumns, ", "))
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("总计: %d 张一致, %d 张不一致, %d 张跳过\n", passed, mismatched, skipped)
+	if mismatched > 0 {
+		exit(1)
+	}
+}
+
+// printSampleMismatches 显示不一致的行详情
+func printSampleMismatches(mismatches []service.SampleMismatch) {
+	shown := mismatches
+	if validateMaxDiffs > 0 && len(shown) > validateMaxDiffs {
+		shown = shown[:validateMaxDiffs]
+	}
+	for _, mismatch := range shown {
+		if mismatch.Column == "" {
+			fmt.Printf("   • [%s] PostgreSQL中%s\n", mismatch.Key, mismatch.Postgres)
+			continue
+		}
+		fmt.Printf("   • [%s] %s: Oracle=%s, PostgreSQL=%s\n",
+			mismatch.Key, mismatch.Column, mismatch.Oracle, mismatch.Postgres)
+	}
+	if len(shown) < len(mismatches) {
+		fmt.Printf("   ... 另有 %d 处不一致（可通过 --max-diffs 调整）\n", len(mismatches)-len(shown))
+	}
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+var validateAssertionOutput string
+
+// validateAssertionsCmd 迁移后断言检查命令
+var validateAssertionsCmd = &cobra.Command{
+	Use:   "断言",
+	Short: "在目标库检查配置的迁移后断言",
+	Long: `在PostgreSQL中执行配置文件 migration.assertions 中的断言规则，报告每条断言的值和是否满足期望。
+
+迁移数据（迁移 数据、迁移 全部）后会自动执行同样的检查，此命令用于修复数据后重新检查。
+断言的值为表中满足条件的行数，或自定义查询返回的单个数值，如"订单表行数应 > 0"、"用户表 email 为 NULL 的行数应 = 0"。
+
+示例：
+  ora2pg-admin 校验 断言
+  ora2pg-admin 校验 断言 -o json`,
+	Run: runValidateAssertions,
+}
+
+func init() {
+	validateCmd.AddCommand(validateAssertionsCmd)
+
+	validateAssertionsCmd.Flags().StringVarP(&validateAssertionOutput, "output", "o", checkOutputText, "输出格式 (text, json)")
+}
+
+// runValidateAssertions 执行断言检查，配置为 fail 的断言未满足时以失败退出
+func runValidateAssertions(cmd *cobra.Command, args []string) {
+	jsonOutput := strings.EqualFold(validateAssertionOutput, checkOutputJSON)
+	if !jsonOutput && !strings.EqualFold(validateAssertionOutput, checkOutputText) {
+		fmt.Printf("%s\n", utils.FormatError(utils.ConfigErrors.InvalidValue("output", validateAssertionOutput)))
+		exit(1)
+	}
+
+	manager, _, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	cfg := manager.GetConfig()
+	if len(cfg.Migration.Assertions) == 0 {
+		if jsonOutput {
+			fmt.Println("[]")
+			return
+		}
+		fmt.Println("💡 未配置迁移后断言，可在配置文件的 migration.assertions 中添加断言规则")
+		return
+	}
+
+	results := service.NewAssertionRunner(cfg).Run(context.Background(), cfg.Migration.Assertions)
+	if jsonOutput {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			fmt.Printf("%s\n", utils.FormatError(err))
+			exit(1)
+		}
+		fmt.Println(string(data))
+	} else {
+		fmt.Println("🧪 迁移后断言检查")
+		fmt.Println("─────────────────")
+		printAssertionResults(results)
+	}
+	for _, result := range results {
+		if result.Fatal() {
+			exit(1)
+		}
+	}
+}
+
+// showAssertionResults 显示迁移后断言的检查结果
+func showAssertionResults(migrationService *service.MigrationService) {
+	results := migrationService.AssertionResults()
+	if len(results) == 0 {
+		return
+	}
+	fmt.Println("🧪 迁移后断言:")
+	printAssertionResults(results)
+}
+
+// printAssertionResults 逐条显示断言结果和汇总
+func printAssertionResults(results []*service.AssertionResult) {
+	passed, warned, failed := 0, 0, 0
+	for _, result := range results {
+		icon := "✅"
+		switch {
+		case result.Passed():
+			passed++
+		case result.Fatal():
+			icon = "❌"
+			failed++
+		default:
+			icon = "⚠️"
+			warned++
+		}
+		fmt.Printf("   %s %s", icon, result.Name)
+		if result.Value != nil {
+			fmt.Printf(": 值为 %s，期望 %s", service.FormatAssertionValue(*result.Value), result.Expect)
+		}
+		fmt.Println()
+		if result.Error != "" {
+			fmt.Printf("     查询失败: %s\n", result.Error)
+			fmt.Printf("     %s\n", result.Query)
+		}
+	}
+	fmt.Printf("   共 %d 条：%d 条通过，%d 条警告，%d 条失败\n", len(results), passed, warned, failed)
+}
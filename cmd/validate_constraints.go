@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+var (
+	validateConstraintTables []string
+	validateConstraintData   bool
+	validateConstraintOutput string
+	validateConstraintExtra  bool
+)
+
+// validateConstraintsCmd 约束完整性校验命令
+var validateConstraintsCmd = &cobra.Command{
+	Use:   "约束",
+	Short: "对比源库和目标库的约束定义",
+	Long: `查询两端数据字典，逐表对比主键、唯一约束、外键、检查约束和非空约束，报告缺失或定义不一致的约束。
+
+约束名在迁移中通常会变化，两端按表、约束类型和列匹配，不比较约束名；表名、列名按命名规则转换后比较。
+外键还比较被引用的表、列和 ON DELETE 规则，检查约束比较规范化后的条件。
+
+使用 --check-data 时，对缺失、不一致或 NOT VALID 的约束在PostgreSQL中统计违反约束的数据，
+用于判断能否直接补建约束。Oracle的检查条件无法可靠转换，只检查PostgreSQL中已存在的检查约束。
+
+示例：
+  ora2pg-admin 校验 约束
+  ora2pg-admin 校验 约束 --table ORDERS --check-data
+  ora2pg-admin 校验 约束 -o json`,
+	Run: runValidateConstraints,
+}
+
+func init() {
+	validateCmd.AddCommand(validateConstraintsCmd)
+
+	validateConstraintsCmd.Flags().StringArrayVar(&validateConstraintTables, "table", nil, "要校验的Oracle表名，可重复指定（默认为源模式下全部表）")
+	validateConstraintsCmd.Flags().BoolVar(&validateConstraintData, "check-data", false, "在PostgreSQL中检查数据是否满足缺失或未验证的约束")
+	validateConstraintsCmd.Flags().BoolVar(&validateConstraintExtra, "show-extra", false, "同时显示只存在于PostgreSQL中的约束")
+	validateConstraintsCmd.Flags().StringVarP(&validateConstraintOutput, "output", "o", checkOutputText, "输出格式 (text, json)")
+}
+
+// runValidateConstraints 执行约束校验
+func runValidateConstraints(cmd *cobra.Command, args []string) {
+	jsonOutput := strings.EqualFold(validateConstraintOutput, checkOutputJSON)
+	if !jsonOutput && !strings.EqualFold(validateConstraintOutput, checkOutputText) {
+		fmt.Printf("%s\n", utils.FormatError(utils.ConfigErrors.InvalidValue("output", validateConstraintOutput)))
+		exit(1)
+	}
+
+	if !jsonOutput {
+		fmt.Println("🔗 约束完整性校验")
+		fmt.Println("─────────────────")
+	}
+
+	manager, _, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	validator := service.NewConstraintValidator(manager.GetConfig())
+	report, err := validator.Validate(context.Background(), validateConstraintTables, validateConstraintData)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Printf("%s\n", utils.FormatError(err))
+			exit(1)
+		}
+		fmt.Println(string(data))
+	} else {
+		printConstraintReport(report)
+	}
+	if report.HasProblems() {
+		exit(1)
+	}
+}
+
+// printConstraintReport 显示约束对比报告
+func printConstraintReport(report *service.ConstraintReport) {
+	if len(report.Tables) == 0 {
+		fmt.Println("源模式下没有定义约束的表")
+		return
+	}
+	fmt.Printf("Oracle模式 %s → PostgreSQL模式 %s，共 %d 张表\n\n", report.OracleSchema, report.PostgresSchema, len(report.Tables))
+
+	passed, failed := 0, 0
+	for _, table := range report.Tables {
+		if table.TableMissing {
+			failed++
+			fmt.Printf("❌ %s: PostgreSQL中没有表 %s\n", table.Table, table.Target)
+			continue
+		}
+
+		if problems := table.Problems(); problems > 0 {
+			failed++
+			fmt.Printf("❌ %s: %d 个约束一致，%d 处需要处理\n", table.Table, table.Matched, problems)
+		} else {
+			passed++
+			fmt.Printf("✅ %s: %d 个约束一致\n", table.Table, table.Matched)
+		}
+		for _, diff := range table.Diffs {
+			printConstraintDiff(diff)
+		}
+		if table.CheckError != "" {
+			fmt.Printf("   ⚠️ 数据检查失败: %s\n", table.CheckError)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("总计: %d 张一致, %d 张有问题；缺失 %d, 不一致 %d, 未验证 %d, 仅目标库 %d\n", passed, failed,
+		report.Count(service.ConstraintDiffMissing), report.Count(service.ConstraintDiffMismatch),
+		report.Count(service.ConstraintDiffNotValidated), report.Count(service.ConstraintDiffExtra))
+}
+
+// printConstraintDiff 显示一处约束差异
+func printConstraintDiff(diff service.ConstraintDiff) {
+	switch diff.Kind {
+	case service.ConstraintDiffMissing:
+		fmt.Printf("   • 缺失: %s\n", service.DescribeConstraint(diff.Oracle))
+	case service.ConstraintDiffMismatch:
+		fmt.Printf("   • 不一致: %s\n     %s\n", service.DescribeConstraint(diff.Oracle), diff.Detail)
+	case service.ConstraintDiffNotValidated:
+		fmt.Printf("   • 未验证: %s 为 NOT VALID\n", service.DescribeConstraint(diff.Postgres))
+	case service.ConstraintDiffExtra:
+		if !validateConstraintExtra && diff.Violations <= 0 {
+			return
+		}
+		fmt.Printf("   • 仅目标库: %s\n", service.DescribeConstraint(diff.Postgres))
+	}
+	if diff.Kind == service.ConstraintDiffMissing && diff.Detail != "" {
+		fmt.Printf("     %s\n", diff.Detail)
+	}
+	switch {
+	case diff.Violations > 0:
+		fmt.Printf("     ❌ PostgreSQL中有 %d 处数据违反该约束\n", diff.Violations)
+	case diff.Violations == 0:
+		fmt.Println("     ✅ 现有数据满足该约束")
+	}
+}
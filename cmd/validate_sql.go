@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"ora2pg-admin/internal/service"
+	"ora2pg-admin/internal/utils"
+)
+
+// sqlCheckShownIssues 迁移结束时最多显示的语法错误数
+const sqlCheckShownIssues = 10
+
+var (
+	validateSQLJobs     int
+	validateSQLDatabase string
+	validateSQLRuntime  bool
+	validateSQLOutput   string
+)
+
+// validateSQLCmd 生成SQL的语法预校验命令
+var validateSQLCmd = &cobra.Command{
+	Use:   "语法 [SQL文件或目录...]",
+	Short: "导入前在PostgreSQL中试执行生成的SQL，报告语法问题",
+	Long: `在PostgreSQL中试执行ora2pg生成的SQL，报告有问题的文件、行号和语句，默认校验输出目录中的全部SQL文件。
+
+每个文件在一个psql会话的事务中执行，出错的语句单独回滚后继续执行，最后回滚整个事务，不会留下对象。
+函数和存储过程的PL/pgSQL函数体同样会检查（check_function_bodies）。
+
+问题分为两类：
+  • 语法错误（SQLSTATE 42601 等）：语句无法解析，通常是转换问题，需要修改SQL或转换规则
+  • 运行时错误：语法正确但执行失败，如依赖的表不存在，与校验库的状态有关，默认只按错误代码统计
+
+psql元命令、事务控制语句（BEGIN/COMMIT 等）和数据语句（INSERT/COPY）不执行。
+建议在 migration.sql_check.database 中配置单独的空库作为校验库，避免校验时锁住目标库的对象。
+存在语法错误时以失败退出。
+
+示例：
+  ora2pg-admin 校验 语法
+  ora2pg-admin 校验 语法 output/PROCEDURE_output.sql --runtime
+  ora2pg-admin 校验 语法 --database sqlcheck --jobs 8 -o json`,
+	Run: runValidateSQL,
+}
+
+func init() {
+	validateCmd.AddCommand(validateSQLCmd)
+
+	validateSQLCmd.Flags().IntVar(&validateSQLJobs, "jobs", 0, "并行校验的文件数（0表示使用配置文件设置）")
+	validateSQLCmd.Flags().StringVar(&validateSQLDatabase, "database", "", "执行校验的数据库（默认为 migration.sql_check.database 或目标库）")
+	validateSQLCmd.Flags().BoolVar(&validateSQLRuntime, "runtime", false, "逐条显示运行时错误")
+	validateSQLCmd.Flags().StringVarP(&validateSQLOutput, "output", "o", checkOutputText, "输出格式 (text, json)")
+}
+
+// runValidateSQL 校验SQL文件，存在语法错误时以失败退出
+func runValidateSQL(cmd *cobra.Command, args []string) {
+	jsonOutput := strings.EqualFold(validateSQLOutput, checkOutputJSON)
+	if !jsonOutput && !strings.EqualFold(validateSQLOutput, checkOutputText) {
+		fmt.Printf("%s\n", utils.FormatError(utils.ConfigErrors.InvalidValue("output", validateSQLOutput)))
+		exit(1)
+	}
+	if validateSQLJobs < 0 {
+		fmt.Printf("%s\n", utils.FormatError(utils.ConfigErrors.InvalidValue("jobs", fmt.Sprint(validateSQLJobs))))
+		exit(1)
+	}
+
+	manager, _, err := loadExistingConfig()
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	cfg := manager.GetConfig()
+	paths := args
+	if len(paths) == 0 {
+		paths = []string{cfg.Migration.OutputDir}
+	}
+	files, err := service.FindSQLFiles(paths)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+	if len(files) == 0 {
+		fmt.Printf("💡 %s 中没有SQL文件，请先执行迁移\n", strings.Join(paths, ", "))
+		return
+	}
+
+	if validateSQLDatabase != "" {
+		cfg.Migration.SQLCheck.Database = validateSQLDatabase
+	}
+	checker := service.NewSQLSyntaxChecker(cfg)
+	checker.SetJobs(validateSQLJobs)
+	if !jsonOutput {
+		fmt.Println("🧪 SQL语法预校验")
+		fmt.Println("─────────────────")
+		fmt.Printf("共 %d 个文件，正在校验...\n", len(files))
+	}
+	report, err := checker.Check(context.Background(), files)
+	if err != nil {
+		fmt.Printf("%s\n", utils.FormatError(err))
+		exit(1)
+	}
+
+	if jsonOutput {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		printSQLCheckReport(report, 0, validateSQLRuntime)
+	}
+	if report.SyntaxErrors > 0 {
+		exit(1)
+	}
+}
+
+// showSQLCheckReport 迁移结束时显示SQL语法预校验结果
+func showSQLCheckReport(migrationService *service.MigrationService) {
+	report := migrationService.SQLCheckReport()
+	if report == nil {
+		return
+	}
+	fmt.Println("🧪 SQL语法预校验:")
+	printSQLCheckReport(report, sqlCheckShownIssues, false)
+	if report.SyntaxErrors > sqlCheckShownIssues || report.RuntimeErrors > 0 {
+		fmt.Println("💡 运行 'ora2pg-admin 校验 语法 --runtime' 查看全部问题")
+	}
+}
+
+// printSQLCheckReport 显示校验结果，limit 为最多显示的语法错误数（0表示全部），showRuntime 时逐条显示运行时错误
+func printSQLCheckReport(report *service.SQLCheckReport, limit int, showRuntime bool) {
+	syntax := report.Issues(service.SQLCheckSyntax)
+	shown := syntax
+	if limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+	for _, issue := range shown {
+		printSQLCheckIssue("❌", issue)
+	}
+	if len(shown) < len(syntax) {
+		fmt.Printf("   ... 另有 %d 个语法错误\n", len(syntax)-len(shown))
+	}
+	if showRuntime {
+		for _, issue := range report.Issues(service.SQLCheckRuntime) {
+			printSQLCheckIssue("⚠️", issue)
+		}
+	}
+	for _, file := range report.Files {
+		if file.Error != "" {
+			fmt.Printf("   ⚠️ %s 校验未完成: %s\n", file.File, file.Error)
+		}
+	}
+
+	icon := "✅"
+	if report.SyntaxErrors > 0 {
+		icon = "❌"
+	} else if report.RuntimeErrors > 0 || report.FailedFiles > 0 {
+		icon = "⚠️"
+	}
+	fmt.Printf("   %s 校验库 %s：%d 个文件，%d 条语句（跳过 %d 条），语法错误 %d 个，运行时错误 %d 个，耗时 %v\n",
+		icon, report.Database, len(report.Files), report.Statements, report.Skipped,
+		report.SyntaxErrors, report.RuntimeErrors, report.Duration.Round(time.Millisecond))
+	if report.RuntimeErrors > 0 {
+		fmt.Printf("   运行时错误与校验库的状态有关（如依赖的对象不存在），不一定是转换问题: %s\n",
+			strings.Join(report.RuntimeErrorCodes(), "，"))
+	}
+}
+
+// printSQLCheckIssue 显示一个问题的位置、错误和语句
+func printSQLCheckIssue(icon string, issue service.SQLCheckIssue) {
+	code := ""
+	if issue.Code != "" {
+		code = issue.Code + " "
+	}
+	fmt.Printf("   %s %s:%d [%s] %s%s\n", icon, issue.File, issue.Line, issue.Kind, code, issue.Message)
+	fmt.Printf("      %s\n", issue.Statement)
+}
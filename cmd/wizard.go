@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/manifoldco/promptui"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// 向导中每一步都可输入的导航命令
+const (
+	wizardBackCommand = ":back"
+	wizardQuitCommand = ":quit"
+)
+
+// wizardBackItem 选择列表中返回上一步的选项
+const wizardBackItem = "« 返回上一步"
+
+// errWizardBack 步骤要求返回上一步
+var errWizardBack = errors.New("返回上一步")
+
+// wizardInput 向导提示的输入，nil 表示标准输入
+var wizardInput io.ReadCloser
+
+// wizardStep 配置向导中的一步
+type wizardStep struct {
+	// run 提问并把输入写入配置，返回 errWizardBack 表示回到上一步
+	run func() error
+	// skip 为 true 时跳过该步，nil 表示总是执行
+	skip func() bool
+}
+
+// runWizard 依次执行向导步骤
+//
+// 每一步的输入直接写入配置并作为再次进入该步时的默认值，返回上一步时不会丢失已填写的内容；
+// 返回时跳过未执行过的步骤。输入 :quit 或按 Ctrl+C 时返回错误，由调用方放弃保存。
+func runWizard(steps []wizardStep) error {
+	var history []int
+	for i := 0; i < len(steps); {
+		step := steps[i]
+		if step.skip != nil && step.skip() {
+			i++
+			continue
+		}
+
+		err := step.run()
+		if errors.Is(err, errWizardBack) {
+			if len(history) == 0 {
+				fmt.Println("⚠️  已经是第一步")
+				continue
+			}
+			i = history[len(history)-1]
+			history = history[:len(history)-1]
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		history = append(history, i)
+		i++
+	}
+	return nil
+}
+
+// wizardSection 在一组步骤的第一步前显示标题，返回该组时会再次显示
+func wizardSection(header func(), steps []wizardStep) []wizardStep {
+	if len(steps) == 0 {
+		return steps
+	}
+	first := steps[0].run
+	steps[0].run = func() error {
+		header()
+		return first()
+	}
+	return steps
+}
+
+// printWizardHint 显示向导导航命令的说明
+func printWizardHint() {
+	fmt.Printf("💡 任意一步输入 %s 返回上一步修改，输入 %s 退出向导（不保存）\n", wizardBackCommand, wizardQuitCommand)
+	fmt.Println()
+}
+
+// wizardPrompt 执行向导中的输入提示，返回去掉首尾空白的输入
+//
+// 导航命令不经过该步的校验；输入 :back 返回 errWizardBack，:quit 或取消输入返回错误。
+func wizardPrompt(prompt promptui.Prompt) (string, error) {
+	validate := prompt.Validate
+	prompt.Validate = func(input string) error {
+		if isWizardCommand(input) || validate == nil {
+			return nil
+		}
+		return validate(input)
+	}
+	if prompt.Stdin == nil {
+		prompt.Stdin = wizardInput
+	}
+
+	value, err := prompt.Run()
+	if err != nil {
+		return "", utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
+			Message("用户取消了输入").Build()
+	}
+	value = strings.TrimSpace(value)
+	switch value {
+	case wizardBackCommand:
+		return "", errWizardBack
+	case wizardQuitCommand:
+		return "", wizardQuitError()
+	}
+	return value, nil
+}
+
+// wizardAdvice 输入的合理性检查，返回不合理但允许继续的软提示，nil 表示没有建议
+//
+// 格式错误等必须修改的问题由 Validate 校验，输入通过 Validate 后才会检查合理性。
+type wizardAdvice func(input string) *config.ValidationWarning
+
+// wizardLabel 带软提示的提示标签，输入时随校验实时更新
+type wizardLabel struct {
+	Text string
+	Hint string
+}
+
+// wizardAdviceTemplates 有软提示时标签后以黄色显示提示，提交后只保留标签
+var wizardAdviceTemplates = &promptui.PromptTemplates{
+	Prompt:  `{{ "?" | blue }} {{ .Text | bold }}{{ ":" | bold }} `,
+	Valid:   `{{ if .Hint }}{{ "!" | yellow }}{{ else }}{{ "✔" | green }}{{ end }} {{ .Text | bold }}{{ if .Hint }} {{ .Hint | yellow }}{{ end }}{{ ":" | bold }} `,
+	Invalid: `{{ "✗" | red }} {{ .Text | bold }}{{ ":" | bold }} `,
+	Success: `{{ .Text | faint }}{{ ":" | faint }} `,
+}
+
+// wizardPromptWithAdvice 执行带合理性检查的输入提示
+//
+// 输入时在标签后实时显示软提示；提交的值有软提示时显示警告和建议，仍然接受该值，需要修改时可输入 :back 返回。
+func wizardPromptWithAdvice(prompt promptui.Prompt, advise wizardAdvice) (string, error) {
+	label := &wizardLabel{Text: fmt.Sprint(prompt.Label)}
+	validate := prompt.Validate
+	prompt.Label = label
+	prompt.Templates = wizardAdviceTemplates
+	prompt.Validate = func(input string) error {
+		label.Hint = ""
+		if validate != nil {
+			if err := validate(input); err != nil {
+				return err
+			}
+		}
+		if warning := advise(strings.TrimSpace(input)); warning != nil {
+			label.Hint = "（" + warning.Message + "）"
+		}
+		return nil
+	}
+
+	value, err := wizardPrompt(prompt)
+	if err != nil {
+		return "", err
+	}
+	if warning := advise(value); warning != nil {
+		printWizardAdvice(warning)
+	}
+	return value, nil
+}
+
+// printWizardAdvice 显示软提示和建议
+func printWizardAdvice(warning *config.ValidationWarning) {
+	fmt.Printf("⚠️  %s\n", warning.Message)
+	if warning.Suggestion != "" {
+		fmt.Printf("   💡 %s\n", warning.Suggestion)
+	}
+	fmt.Printf("   该值可以继续使用；需要修改时输入 %s 返回\n", wizardBackCommand)
+}
+
+// wizardSelect 执行向导中的选择提示，列表末尾附加返回上一步的选项，返回所选项的下标
+func wizardSelect(label string, items []string, cursor int) (int, error) {
+	prompt := promptui.Select{
+		Label:     label,
+		Items:     append(append([]string(nil), items...), wizardBackItem),
+		CursorPos: cursor,
+		Stdin:     wizardInput,
+	}
+	index, _, err := prompt.Run()
+	if err != nil {
+		return 0, utils.NewError(utils.ErrorTypeUser, "INPUT_CANCELLED").
+			Message("用户取消了选择").Build()
+	}
+	if index == len(items) {
+		return 0, errWizardBack
+	}
+	return index, nil
+}
+
+// wizardConfirm 执行向导中的是/否提问，直接回车使用 defaultYes
+//
+// 不使用 promptui 的确认模式，以便同样支持导航命令。
+func wizardConfirm(label string, defaultYes bool) (bool, error) {
+	suffix := " [y/N]"
+	if defaultYes {
+		suffix = " [Y/n]"
+	}
+	answer, err := wizardPrompt(promptui.Prompt{
+		Label:    label + suffix,
+		Validate: validateYesNo,
+	})
+	if err != nil {
+		return false, err
+	}
+	if answer == "" {
+		return defaultYes, nil
+	}
+	return parseYesNo(answer), nil
+}
+
+// wizardPassword 执行向导中的密码输入，已有密码时直接回车保留原密码
+func wizardPassword(label string, current *string) error {
+	prompt := promptui.Prompt{
+		Label:    label,
+		Mask:     '*',
+		Validate: validateRequired,
+	}
+	if *current != "" {
+		prompt.Label = label + "（直接回车保留当前密码）"
+		prompt.Validate = nil
+	}
+	password, err := wizardPrompt(prompt)
+	if err != nil {
+		return err
+	}
+	if password != "" {
+		*current = password
+	}
+	return nil
+}
+
+// wizardQuitError 用户退出向导
+func wizardQuitError() error {
+	return utils.NewError(utils.ErrorTypeUser, "WIZARD_QUIT").
+		Message("已退出配置向导").
+		Details("本次输入的内容未保存").
+		Build()
+}
+
+// isWizardCommand 输入是否为导航命令
+func isWizardCommand(input string) bool {
+	input = strings.TrimSpace(input)
+	return input == wizardBackCommand || input == wizardQuitCommand
+}
+
+// validateYesNo 验证是/否回答，允许为空
+func validateYesNo(input string) error {
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "", "y", "yes", "n", "no":
+		return nil
+	}
+	return fmt.Errorf("请输入 y 或 n")
+}
+
+// parseYesNo 回答是否为"是"
+func parseYesNo(input string) bool {
+	answer := strings.ToLower(strings.TrimSpace(input))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/manifoldco/promptui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// setWizardInput 让向导提示读取给定的输入
+func setWizardInput(t *testing.T, input string) {
+	t.Helper()
+	wizardInput = io.NopCloser(strings.NewReader(input))
+	t.Cleanup(func() { wizardInput = nil })
+}
+
+func TestRunWizardNavigation(t *testing.T) {
+	// 预期依次进入的步骤和该步的结果
+	script := []struct {
+		name    string
+		err     error
+		enabled bool
+	}{
+		{name: "host"},
+		{name: "port", err: errWizardBack},
+		{name: "host"},
+		{name: "port"},
+		{name: "account", enabled: true},
+		{name: "username", err: errWizardBack},
+		{name: "account"},
+		// 返回时跳过未执行的步骤：从 schema 回到 account，而不是已跳过的 username
+		{name: "schema", err: errWizardBack},
+		{name: "account"},
+		{name: "schema"},
+	}
+	var visited []string
+	enabled := false
+	step := func(name string) wizardStep {
+		return wizardStep{run: func() error {
+			require.Less(t, len(visited), len(script), "步骤 %s 多执行了一次", name)
+			expected := script[len(visited)]
+			visited = append(visited, name)
+			if name == "account" {
+				enabled = expected.enabled
+			}
+			return expected.err
+		}}
+	}
+	username := step("username")
+	username.skip = func() bool { return !enabled }
+
+	require.NoError(t, runWizard([]wizardStep{step("host"), step("port"), step("account"), username, step("schema")}))
+	expected := make([]string, 0, len(script))
+	for _, item := range script {
+		expected = append(expected, item.name)
+	}
+	assert.Equal(t, expected, visited)
+}
+
+func TestRunWizardFirstStepAndQuit(t *testing.T) {
+	calls := 0
+	quit := wizardQuitError()
+	err := runWizard([]wizardStep{
+		{run: func() error {
+			calls++
+			if calls == 1 {
+				// 第一步返回上一步时停留在第一步
+				return errWizardBack
+			}
+			return nil
+		}},
+		{run: func() error { return quit }},
+	})
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, "WIZARD_QUIT", utils.GetErrorCode(err))
+}
+
+func TestWizardPrompt(t *testing.T) {
+	// 导航命令不经过该步的校验
+	setWizardInput(t, ":back\n")
+	_, err := wizardPrompt(promptui.Prompt{Label: "端口", Validate: validatePort})
+	assert.True(t, errors.Is(err, errWizardBack))
+
+	setWizardInput(t, " :quit \n")
+	_, err = wizardPrompt(promptui.Prompt{Label: "主机", Validate: validateHost})
+	assert.Equal(t, "WIZARD_QUIT", utils.GetErrorCode(err))
+
+	setWizardInput(t, " db.example.com \n")
+	value, err := wizardPrompt(promptui.Prompt{Label: "主机", Validate: validateHost})
+	require.NoError(t, err)
+	assert.Equal(t, "db.example.com", value)
+
+	// 输入结束视为取消
+	setWizardInput(t, "")
+	_, err = wizardPrompt(promptui.Prompt{Label: "主机", Validate: validateHost})
+	assert.Equal(t, "INPUT_CANCELLED", utils.GetErrorCode(err))
+}
+
+func TestWizardPasswordKeepsCurrent(t *testing.T) {
+	password := "secret"
+	setWizardInput(t, "\n")
+	require.NoError(t, wizardPassword("Oracle密码", &password))
+	assert.Equal(t, "secret", password)
+
+	setWizardInput(t, "changed\n")
+	require.NoError(t, wizardPassword("Oracle密码", &password))
+	assert.Equal(t, "changed", password)
+}
+
+func TestWizardConfirm(t *testing.T) {
+	setWizardInput(t, "\n")
+	answer, err := wizardConfirm("是否使用只读账号", true)
+	require.NoError(t, err)
+	assert.True(t, answer)
+
+	setWizardInput(t, "n\n")
+	answer, err = wizardConfirm("是否使用只读账号", true)
+	require.NoError(t, err)
+	assert.False(t, answer)
+}
+
+func TestTestAccountStepsRestoresOnReenable(t *testing.T) {
+	username, password := "reader", "pw"
+	steps := testAccountSteps("Oracle", &username, &password)
+
+	// 选择不启用时清除账号，跳过后续步骤
+	setWizardInput(t, "n\n")
+	require.NoError(t, steps[0].run())
+	assert.Empty(t, username)
+	assert.Empty(t, password)
+	assert.True(t, steps[1].skip())
+
+	// 返回上一步重新启用时恢复原账号
+	setWizardInput(t, "y\n")
+	require.NoError(t, steps[0].run())
+	assert.Equal(t, "reader", username)
+	assert.Equal(t, "pw", password)
+	assert.False(t, steps[1].skip())
+}
+
+func TestWizardPromptWithAdvice(t *testing.T) {
+	advise := func(input string) *config.ValidationWarning {
+		jobs, _ := strconv.Atoi(input)
+		return config.AdviseParallelJobs(jobs, 0)
+	}
+
+	// 软提示不阻止输入
+	setWizardInput(t, "64\n")
+	value, err := wizardPromptWithAdvice(promptui.Prompt{Label: "并行作业数", Validate: validatePositiveInt}, advise)
+	require.NoError(t, err)
+	assert.Equal(t, "64", value)
+
+	// 格式错误仍然必须修改，输入结束视为取消
+	setWizardInput(t, "abc\n")
+	_, err = wizardPromptWithAdvice(promptui.Prompt{Label: "并行作业数", Validate: validatePositiveInt}, advise)
+	assert.Equal(t, "INPUT_CANCELLED", utils.GetErrorCode(err))
+
+	// 导航命令不做合理性检查
+	setWizardInput(t, ":back\n")
+	_, err = wizardPromptWithAdvice(promptui.Prompt{Label: "并行作业数", Validate: validatePositiveInt}, advise)
+	assert.True(t, errors.Is(err, errWizardBack))
+}
+
+func TestPerformanceWizardSteps(t *testing.T) {
+	migrationConfig := &config.MigrationConfig{ParallelJobs: 4, BatchSize: 1000, OutputDir: "output"}
+	steps := performanceWizardSteps(migrationConfig)
+	require.Len(t, steps, 4)
+
+	setWizardInput(t, "64\n")
+	require.NoError(t, steps[0].run())
+	assert.Equal(t, 64, migrationConfig.ParallelJobs)
+
+	setWizardInput(t, "50\n")
+	require.NoError(t, steps[1].run())
+	assert.Equal(t, 50, migrationConfig.BatchSize)
+
+	setWizardInput(t, "n\n")
+	require.NoError(t, steps[3].run())
+	assert.Empty(t, migrationConfig.LargeTables)
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 配置的建议范围，超出时只给出警告，不视为错误
+const (
+	// MaxRecommendedParallelJobs 并行作业数的建议上限
+	MaxRecommendedParallelJobs = 32
+	// MinRecommendedBatchSize、MaxRecommendedBatchSize 批处理大小的建议范围
+	MinRecommendedBatchSize = 100
+	MaxRecommendedBatchSize = 100000
+)
+
+// DefaultPostgresPort PostgreSQL的默认端口
+const DefaultPostgresPort = 5432
+
+// oracleAdminUsers Oracle的管理账号，不建议用于迁移
+var oracleAdminUsers = []string{"SYS", "SYSTEM"}
+
+// AdviseParallelJobs 检查并行作业数是否合理，cpus 为本机CPU核心数，0表示不按核心数检查
+func AdviseParallelJobs(jobs, cpus int) *ValidationWarning {
+	switch {
+	case jobs > MaxRecommendedParallelJobs:
+		return &ValidationWarning{
+			Field:      "migration.parallel_jobs",
+			Message:    fmt.Sprintf("并行作业数 %d 超过建议上限 %d，可能压垮数据库", jobs, MaxRecommendedParallelJobs),
+			Suggestion: "每个作业占用源库和目标库各一个连接，建议先用较小的值迁移一批表，观察数据库负载后再调整",
+		}
+	case cpus > 0 && jobs > cpus*2:
+		return &ValidationWarning{
+			Field:      "migration.parallel_jobs",
+			Message:    fmt.Sprintf("并行作业数 %d 超过本机CPU核心数 %d 的2倍，ora2pg进程会互相争抢CPU", jobs, cpus),
+			Suggestion: fmt.Sprintf("建议不超过 %d", cpus*2),
+		}
+	}
+	return nil
+}
+
+// AdviseBatchSize 检查批处理大小是否合理
+func AdviseBatchSize(size int) *ValidationWarning {
+	switch {
+	case size > 0 && size < MinRecommendedBatchSize:
+		return &ValidationWarning{
+			Field:      "migration.batch_size",
+			Message:    fmt.Sprintf("批处理大小 %d 过小，频繁提交会明显降低导入速度", size),
+			Suggestion: fmt.Sprintf("一般设置为 %d 以上，如 10000", MinRecommendedBatchSize),
+		}
+	case size > MaxRecommendedBatchSize:
+		return &ValidationWarning{
+			Field:      "migration.batch_size",
+			Message:    fmt.Sprintf("批处理大小 %d 过大，每批数据都要缓存在内存中，含LOB的表可能耗尽内存", size),
+			Suggestion: fmt.Sprintf("建议不超过 %d", MaxRecommendedBatchSize),
+		}
+	}
+	return nil
+}
+
+// AdviseOraclePort 检查Oracle端口：启用 TCPS 却使用明文端口，或误填了PostgreSQL端口
+func AdviseOraclePort(port int, useTCPS bool) *ValidationWarning {
+	switch {
+	case useTCPS && port == DefaultOraclePort:
+		return &ValidationWarning{
+			Field:      "oracle.port",
+			Message:    fmt.Sprintf("启用了 TCPS，但端口为 %d（通常是明文 TCP 端口）", DefaultOraclePort),
+			Suggestion: fmt.Sprintf("TCPS 监听通常使用 %d 端口，请与DBA确认", DefaultOracleTCPSPort),
+		}
+	case port == DefaultPostgresPort:
+		return &ValidationWarning{
+			Field:      "oracle.port",
+			Message:    fmt.Sprintf("%d 是PostgreSQL的默认端口", port),
+			Suggestion: fmt.Sprintf("Oracle监听默认使用 %d（TCPS 为 %d），请确认没有填反", DefaultOraclePort, DefaultOracleTCPSPort),
+		}
+	}
+	return nil
+}
+
+// AdvisePostgresPort 检查PostgreSQL端口，识别误填的Oracle端口
+func AdvisePostgresPort(port int) *ValidationWarning {
+	if port != DefaultOraclePort && port != DefaultOracleTCPSPort {
+		return nil
+	}
+	return &ValidationWarning{
+		Field:      "postgresql.port",
+		Message:    fmt.Sprintf("%d 是Oracle监听的默认端口", port),
+		Suggestion: fmt.Sprintf("PostgreSQL默认使用 %d，请确认没有填反", DefaultPostgresPort),
+	}
+}
+
+// AdviseOracleUsername 检查Oracle迁移账号，不建议使用管理账号（默认配置即为 SYSTEM，只在配置向导中提示）
+func AdviseOracleUsername(username string) *ValidationWarning {
+	for _, admin := range oracleAdminUsers {
+		if strings.EqualFold(strings.TrimSpace(username), admin) {
+			return &ValidationWarning{
+				Field:      "oracle.username",
+				Message:    fmt.Sprintf("使用管理账号 %s 迁移，误操作的影响范围大", admin),
+				Suggestion: "使用只有查询权限的专用迁移账号，并在 oracle.schema 中指定要迁移的模式",
+			}
+		}
+	}
+	return nil
+}
+
+// AdvisePostgresUsername 检查PostgreSQL迁移账号，不建议使用超级用户（只在配置向导中提示）
+func AdvisePostgresUsername(username string) *ValidationWarning {
+	if strings.TrimSpace(username) != "postgres" {
+		return nil
+	}
+	return &ValidationWarning{
+		Field:      "postgresql.username",
+		Message:    "postgres 是超级用户，迁移创建的对象属主也会是超级用户",
+		Suggestion: "使用目标数据库的属主或专用迁移账号",
+	}
+}
+
+// addAdvice 添加合理性检查的警告，nil 表示没有建议
+func (vr *ValidationResult) addAdvice(warning *ValidationWarning) {
+	if warning != nil {
+		vr.Warnings = append(vr.Warnings, *warning)
+	}
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// allowTablePattern 允许迁移的表名，可带模式前缀，如 ORDERS、HR.EMPLOYEES
+var allowTablePattern = regexp.MustCompile(`^[A-Za-z0-9_$#]+(\.[A-Za-z0-9_$#]+)?$`)
+
+// AllowDirective 生成的ora2pg配置中 ALLOW 指令的值，未限定表时为空
+func (m *MigrationConfig) AllowDirective() string {
+	return strings.Join(m.AllowTables, " ")
+}
+
+// validateAllowTables 验证允许迁移的表名
+func (v *Validator) validateAllowTables(migration *MigrationConfig, result *ValidationResult) {
+	seen := make(map[string]bool, len(migration.AllowTables))
+	for i, table := range migration.AllowTables {
+		field := fmt.Sprintf("migration.allow_tables[%d]", i)
+		if !allowTablePattern.MatchString(table) {
+			result.AddError(field, fmt.Sprintf("无效的表名 %q，只能包含字母、数字、_、$、#，可用 模式.表名 指定其他模式的表", table))
+			continue
+		}
+		key := strings.ToUpper(table)
+		if seen[key] {
+			result.AddError(field, fmt.Sprintf("表 %s 重复", table))
+		}
+		seen[key] = true
+	}
+}
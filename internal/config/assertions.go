@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 断言不满足时的处理策略
+const (
+	AssertionOnFailureWarn = "warn"
+	AssertionOnFailureFail = "fail"
+)
+
+// assertionOperators 期望中支持的比较运算符，较长的在前以便按前缀匹配
+var assertionOperators = []string{">=", "<=", "!=", "<>", "=", ">", "<"}
+
+// AssertionConfig 迁移后在目标库执行的断言规则
+//
+// 断言的值为 table 中满足 condition 的行数，或 sql 查询返回的单个数值，与 expect 比较：
+//
+//	table: orders                          # 订单表行数 > 0（未指定 expect 时）
+//	table: users, column: email, condition: "IS NULL", expect: "= 0"
+//	table: orders, condition: "amount < 0", expect: "= 0"
+//	sql: "SELECT max(id) - count(*) FROM orders", expect: "< 100"
+type AssertionConfig struct {
+	// Name 断言名称，显示在报告中，默认根据表和条件生成
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Table 目标库中的表，按命名规则转换大小写，不带模式名时使用 postgresql.schema
+	Table string `yaml:"table,omitempty" json:"table,omitempty"`
+	// Column 与 condition 组合为对该列的条件，如 column: email、condition: "IS NULL"
+	Column string `yaml:"column,omitempty" json:"column,omitempty"`
+	// Condition 统计行数的过滤条件；指定了 column 时为该列之后的部分，否则为完整的 WHERE 条件
+	Condition string `yaml:"condition,omitempty" json:"condition,omitempty"`
+	// SQL 自定义查询，返回单个数值，与 table 二选一
+	SQL string `yaml:"sql,omitempty" json:"sql,omitempty"`
+	// Expect 期望，如 "> 0"、"= 0"、">= 1000"；只统计表行数时默认为 "> 0"
+	Expect string `yaml:"expect,omitempty" json:"expect,omitempty"`
+	// OnFailure 断言不满足或查询失败时的处理：warn（默认）只警告，fail 使迁移以失败退出
+	OnFailure string `yaml:"on_failure,omitempty" json:"on_failure,omitempty"`
+}
+
+// AssertionExpectation 解析后的期望
+type AssertionExpectation struct {
+	Operator string
+	Value    float64
+}
+
+// Matches 断言的值是否满足期望
+func (e AssertionExpectation) Matches(value float64) bool {
+	switch e.Operator {
+	case "=":
+		return value == e.Value
+	case "!=", "<>":
+		return value != e.Value
+	case ">":
+		return value > e.Value
+	case ">=":
+		return value >= e.Value
+	case "<":
+		return value < e.Value
+	case "<=":
+		return value <= e.Value
+	}
+	return false
+}
+
+// String 期望的文本形式，如 "> 0"
+func (e AssertionExpectation) String() string {
+	return e.Operator + " " + strconv.FormatFloat(e.Value, 'f', -1, 64)
+}
+
+// ParseAssertionExpectation 解析期望，格式为比较运算符加数值，如 ">= 1000"
+func ParseAssertionExpectation(expect string) (AssertionExpectation, error) {
+	text := strings.TrimSpace(expect)
+	for _, operator := range assertionOperators {
+		rest, ok := strings.CutPrefix(text, operator)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(rest), 64)
+		if err != nil {
+			break
+		}
+		return AssertionExpectation{Operator: operator, Value: value}, nil
+	}
+	return AssertionExpectation{}, fmt.Errorf("期望 %q 无效，格式为比较运算符（=、!=、>、>=、<、<=）加数值，如 \"> 0\"", expect)
+}
+
+// Expectation 断言的期望，只统计表行数且未指定时为 "> 0"
+func (a *AssertionConfig) Expectation() (AssertionExpectation, error) {
+	if strings.TrimSpace(a.Expect) == "" && a.SQL == "" && a.Condition == "" {
+		return AssertionExpectation{Operator: ">", Value: 0}, nil
+	}
+	return ParseAssertionExpectation(a.Expect)
+}
+
+// DisplayName 断言名称，未配置时根据表和条件生成，如 "users.email IS NULL"
+func (a *AssertionConfig) DisplayName() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	if a.SQL != "" {
+		return strings.TrimSpace(a.SQL)
+	}
+	subject := a.Table
+	if a.Column != "" {
+		subject += "." + a.Column
+	}
+	if a.Condition == "" {
+		return subject + " 行数"
+	}
+	if a.Column != "" {
+		return subject + " " + strings.TrimSpace(a.Condition)
+	}
+	return subject + " WHERE " + strings.TrimSpace(a.Condition)
+}
+
+// FailOnViolation 断言不满足时是否使迁移失败
+func (a *AssertionConfig) FailOnViolation() bool {
+	return a.OnFailure == AssertionOnFailureFail
+}
+
+// validateAssertions 验证迁移后断言规则
+func (v *Validator) validateAssertions(migration *MigrationConfig, result *ValidationResult) {
+	for i, assertion := range migration.Assertions {
+		prefix := fmt.Sprintf("migration.assertions[%d]", i)
+		hasTable := strings.TrimSpace(assertion.Table) != ""
+		hasSQL := strings.TrimSpace(assertion.SQL) != ""
+		switch {
+		case hasTable && hasSQL:
+			result.AddError(prefix, "table 和 sql 只能指定一个")
+		case !hasTable && !hasSQL:
+			result.AddError(prefix, "需要指定 table 或 sql")
+		case hasSQL && (assertion.Column != "" || assertion.Condition != ""):
+			result.AddError(prefix, "使用 sql 时不能再指定 column 或 condition")
+		}
+		if assertion.Column != "" && strings.TrimSpace(assertion.Condition) == "" {
+			result.AddError(prefix+".condition", "指定了 column 时需要同时指定对该列的条件，如 \"IS NULL\"")
+		}
+		if _, err := assertion.Expectation(); err != nil {
+			result.AddError(prefix+".expect", err.Error())
+		}
+		switch assertion.OnFailure {
+		case "", AssertionOnFailureWarn, AssertionOnFailureFail:
+		default:
+			result.AddError(prefix+".on_failure",
+				fmt.Sprintf("无效的处理策略 %q，可选: %s, %s", assertion.OnFailure, AssertionOnFailureWarn, AssertionOnFailureFail))
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// ConfigBackupDirName 配置备份目录名，位于配置文件所在目录下
+const ConfigBackupDirName = "config-backups"
+
+// DefaultConfigBackupKeep 默认保留的配置备份数量，超出时删除最老的备份
+const DefaultConfigBackupKeep = 10
+
+// configBackupTimeFormat 备份文件名中的时间格式
+const configBackupTimeFormat = "20060102-150405"
+
+// ConfigBackup 带时间戳的配置备份，文件名为 <配置文件名>-<时间>[-序号].<扩展名>，如 config-20240102-150405.yaml
+type ConfigBackup struct {
+	Name string
+	Path string
+	Time time.Time
+	Size int64
+
+	seq int // 同一秒内的多个备份按序号区分
+}
+
+// ConfigBackupDir 配置文件的备份目录
+func ConfigBackupDir(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), ConfigBackupDirName)
+}
+
+// configBackupStem 备份文件名的前缀和扩展名
+func configBackupStem(configPath string) (string, string) {
+	base := filepath.Base(configPath)
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext), ext
+}
+
+// BackupConfig 将配置文件原样复制为带时间戳的备份，保留最新的 keep 份，keep 为0时不清理
+func BackupConfig(configPath string, keep int) (*ConfigBackup, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, utils.FileErrors.ReadFailed(configPath, err)
+	}
+	dir := ConfigBackupDir(configPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, utils.FileErrors.CreateFailed(dir, err)
+	}
+
+	existing, err := ListConfigBackups(configPath)
+	if err != nil {
+		return nil, err
+	}
+	// 同一秒内的序号接在已有的最大序号之后，已清理的最老备份的文件名不会被重用
+	now := time.Now()
+	backup := &ConfigBackup{Time: now.Truncate(time.Second), Size: int64(len(data)), seq: 1}
+	for _, item := range existing {
+		if item.Time.Equal(backup.Time) {
+			backup.seq = max(backup.seq, item.seq+1)
+		}
+	}
+	stem, ext := configBackupStem(configPath)
+	backup.Name = fmt.Sprintf("%s-%s%s", stem, now.Format(configBackupTimeFormat), ext)
+	if backup.seq > 1 {
+		backup.Name = fmt.Sprintf("%s-%s-%d%s", stem, now.Format(configBackupTimeFormat), backup.seq, ext)
+	}
+	backup.Path = filepath.Join(dir, backup.Name)
+	// 备份中可能有明文密码，只允许当前用户读取
+	if err := os.WriteFile(backup.Path, data, 0600); err != nil {
+		return nil, utils.FileErrors.CreateFailed(backup.Path, err)
+	}
+
+	if err := pruneConfigBackups(configPath, keep); err != nil {
+		return backup, err
+	}
+	return backup, nil
+}
+
+// pruneConfigBackups 删除超出保留数量的最老备份
+func pruneConfigBackups(configPath string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	backups, err := ListConfigBackups(configPath)
+	if err != nil {
+		return err
+	}
+	for _, backup := range backups[min(keep, len(backups)):] {
+		if err := os.Remove(backup.Path); err != nil && !os.IsNotExist(err) {
+			return utils.FileErrors.WriteFailed(backup.Path, err)
+		}
+	}
+	return nil
+}
+
+// ListConfigBackups 列出配置文件的全部备份，最新的在前；没有备份目录时返回空列表
+func ListConfigBackups(configPath string) ([]ConfigBackup, error) {
+	dir := ConfigBackupDir(configPath)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, utils.FileErrors.ReadFailed(dir, err)
+	}
+
+	stem, ext := configBackupStem(configPath)
+	var backups []ConfigBackup
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		backupTime, seq, ok := parseConfigBackupName(entry.Name(), stem, ext)
+		if !ok {
+			continue
+		}
+		backup := ConfigBackup{Name: entry.Name(), Path: filepath.Join(dir, entry.Name()), Time: backupTime, seq: seq}
+		if info, err := entry.Info(); err == nil {
+			backup.Size = info.Size()
+		}
+		backups = append(backups, backup)
+	}
+	slices.SortFunc(backups, func(a, b ConfigBackup) int {
+		if c := b.Time.Compare(a.Time); c != 0 {
+			return c
+		}
+		return b.seq - a.seq
+	})
+	return backups, nil
+}
+
+// parseConfigBackupName 从备份文件名解析备份时间和序号
+func parseConfigBackupName(name, stem, ext string) (time.Time, int, bool) {
+	rest, ok := strings.CutPrefix(name, stem+"-")
+	if !ok {
+		return time.Time{}, 0, false
+	}
+	if rest, ok = strings.CutSuffix(rest, ext); !ok || len(rest) < len(configBackupTimeFormat) {
+		return time.Time{}, 0, false
+	}
+	backupTime, err := time.ParseInLocation(configBackupTimeFormat, rest[:len(configBackupTimeFormat)], time.Local)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	seq := 1
+	if suffix := rest[len(configBackupTimeFormat):]; suffix != "" {
+		number, ok := strings.CutPrefix(suffix, "-")
+		if !ok {
+			return time.Time{}, 0, false
+		}
+		if seq, err = strconv.Atoi(number); err != nil || seq < 2 {
+			return time.Time{}, 0, false
+		}
+	}
+	return backupTime, seq, true
+}
+
+// FindConfigBackup 按序号（1 为最新）或文件名查找备份
+func FindConfigBackup(configPath, ref string) (*ConfigBackup, error) {
+	backups, err := ListConfigBackups(configPath)
+	if err != nil {
+		return nil, err
+	}
+	ref = strings.TrimSpace(ref)
+	if index, err := strconv.Atoi(ref); err == nil && index >= 1 && index <= len(backups) {
+		return &backups[index-1], nil
+	}
+	for i := range backups {
+		if backups[i].Name == filepath.Base(ref) {
+			return &backups[i], nil
+		}
+	}
+	return nil, utils.NewError(utils.ErrorTypeConfig, "CONFIG_BACKUP_NOT_FOUND").
+		Message(fmt.Sprintf("未找到配置备份: %s", ref)).
+		Details(fmt.Sprintf("备份目录 %s 中共有 %d 个备份", ConfigBackupDir(configPath), len(backups))).
+		Suggestion("使用 'ora2pg-admin 配置 备份 列表' 查看备份的序号和文件名").
+		Build()
+}
+
+// RestoreConfigBackup 用备份覆盖配置文件，覆盖前先备份当前配置，返回当前配置的备份（配置文件不存在时为nil）
+//
+// 备份无法解析时不恢复。只检查备份本身的格式，include 的公共配置按恢复后的位置解析。
+func RestoreConfigBackup(configPath string, backup *ConfigBackup, keep int) (*ConfigBackup, error) {
+	data, err := os.ReadFile(backup.Path)
+	if err != nil {
+		return nil, utils.FileErrors.ReadFailed(backup.Path, err)
+	}
+	if _, err := unmarshalConfigMap(backup.Path, data); err != nil {
+		return nil, utils.NewError(utils.ErrorTypeConfig, "CONFIG_BACKUP_INVALID").
+			Message(fmt.Sprintf("配置备份无法解析: %s", backup.Name)).
+			Cause(err).
+			Suggestion("选择其他备份恢复").
+			Build()
+	}
+
+	// 先读取要恢复的备份再备份当前配置，避免清理旧备份时删除要恢复的文件
+	var current *ConfigBackup
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(configPath); err == nil {
+		mode = info.Mode().Perm()
+		if current, err = BackupConfig(configPath, keep); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.WriteFile(configPath, data, mode); err != nil {
+		return current, utils.FileErrors.WriteFailed(configPath, err)
+	}
+	return current, nil
+}
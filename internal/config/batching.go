@@ -0,0 +1,162 @@
+package config
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// 切片列的类型
+const (
+	SliceNumber    = "number"    // 数值列，如 ID
+	SliceTimestamp = "timestamp" // DATE 或 TIMESTAMP 列，如 CREATED_AT
+)
+
+// MaxTableBuckets 按规模分桶的最大批次数
+const MaxTableBuckets = 100
+
+// SliceTimestampFormat 规范化后的时间切分点格式
+const SliceTimestampFormat = "2006-01-02 15:04:05"
+
+// sliceTimestampLayouts 时间切分点支持的写法
+var sliceTimestampLayouts = []string{SliceTimestampFormat, "2006-01-02"}
+
+// TableSliceConfig 按时间或ID范围切片导出的大表
+type TableSliceConfig struct {
+	Name   string `yaml:"name" json:"name"`
+	Column string `yaml:"column" json:"column"`
+	// Type 切片列类型（number、timestamp），默认 number
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+	// Boundaries 升序的切分点，N 个切分点得到 N+1 个切片，第一个切片包含切片列为空的行
+	Boundaries []string `yaml:"boundaries" json:"boundaries"`
+}
+
+// BatchingConfig 分批迁移数据的规则，供 '迁移 分批' 使用
+//
+// 未切片的表按统计信息中的行数分成 TableBuckets 个批次，小表的批次在前；切片的大表每个范围作为一个批次，排在最后。
+type BatchingConfig struct {
+	// TableBuckets 未切片的表分成的批次数，0或1表示这些表作为一个批次
+	TableBuckets int `yaml:"table_buckets,omitempty" json:"table_buckets,omitempty"`
+	// Slices 按范围切片的大表
+	Slices []TableSliceConfig `yaml:"slices,omitempty" json:"slices,omitempty"`
+}
+
+// Enabled 是否配置了分批规则
+func (c *BatchingConfig) Enabled() bool {
+	return c.TableBuckets > 1 || len(c.Slices) > 0
+}
+
+// SlicedTables 切片的表，大写
+func (c *BatchingConfig) SlicedTables() []string {
+	tables := make([]string, 0, len(c.Slices))
+	for _, slice := range c.Slices {
+		tables = append(tables, slice.TableName())
+	}
+	return tables
+}
+
+// TableName 大写的表名
+func (s *TableSliceConfig) TableName() string {
+	return strings.ToUpper(strings.TrimSpace(s.Name))
+}
+
+// ColumnName 大写的切片列名
+func (s *TableSliceConfig) ColumnName() string {
+	return strings.ToUpper(strings.TrimSpace(s.Column))
+}
+
+// ColumnType 小写的切片列类型，未配置时为 number
+func (s *TableSliceConfig) ColumnType() string {
+	if kind := strings.ToLower(strings.TrimSpace(s.Type)); kind != "" {
+		return kind
+	}
+	return SliceNumber
+}
+
+// NormalizedBoundaries 规范化后的切分点：时间统一为 SliceTimestampFormat，数值去掉空白
+func (s *TableSliceConfig) NormalizedBoundaries() ([]string, error) {
+	boundaries := make([]string, len(s.Boundaries))
+	for i, boundary := range s.Boundaries {
+		value, err := normalizeSliceBoundary(s.ColumnType(), boundary)
+		if err != nil {
+			return nil, err
+		}
+		boundaries[i] = value
+	}
+	return boundaries, nil
+}
+
+// normalizeSliceBoundary 规范化一个切分点
+func normalizeSliceBoundary(kind, boundary string) (string, error) {
+	value := strings.TrimSpace(boundary)
+	if kind == SliceTimestamp {
+		for _, layout := range sliceTimestampLayouts {
+			if parsed, err := time.Parse(layout, value); err == nil {
+				return parsed.Format(SliceTimestampFormat), nil
+			}
+		}
+		return "", fmt.Errorf("无效的时间切分点 %q，请使用 2024-01-01 或 2024-01-01 08:00:00 格式", boundary)
+	}
+	if _, ok := new(big.Float).SetString(value); !ok {
+		return "", fmt.Errorf("无效的数值切分点 %q", boundary)
+	}
+	return value, nil
+}
+
+// compareSliceBoundaries 比较两个规范化后的切分点
+func compareSliceBoundaries(kind, a, b string) int {
+	if kind == SliceTimestamp {
+		return strings.Compare(a, b)
+	}
+	x, _ := new(big.Float).SetString(a)
+	y, _ := new(big.Float).SetString(b)
+	return x.Cmp(y)
+}
+
+// validateBatching 验证分批规则
+func (v *Validator) validateBatching(migration *MigrationConfig, result *ValidationResult) {
+	batching := &migration.Batching
+	if batching.TableBuckets < 0 || batching.TableBuckets > MaxTableBuckets {
+		result.AddError("migration.batching.table_buckets", fmt.Sprintf("分桶批次数必须在 0-%d 之间", MaxTableBuckets))
+	}
+
+	seen := make(map[string]bool)
+	for i, slice := range batching.Slices {
+		field := fmt.Sprintf("migration.batching.slices[%d]", i)
+		name := slice.TableName()
+		switch {
+		case name == "":
+			result.AddError(field, "切片的表名不能为空")
+		case !oracleIdentifierPattern.MatchString(name):
+			result.AddError(field, fmt.Sprintf("无效的表名: %s", slice.Name))
+		case seen[name]:
+			result.AddError(field, fmt.Sprintf("表 %s 重复配置切片", slice.Name))
+		}
+		seen[name] = true
+
+		if !oracleNamePattern.MatchString(slice.ColumnName()) {
+			result.AddError(field+".column", fmt.Sprintf("无效的切片列: %q", slice.Column))
+		}
+		kind := slice.ColumnType()
+		if kind != SliceNumber && kind != SliceTimestamp {
+			result.AddError(field+".type", fmt.Sprintf("无效的切片列类型 %s，只支持 number、timestamp", slice.Type))
+			continue
+		}
+		if len(slice.Boundaries) == 0 {
+			result.AddError(field+".boundaries", "至少需要一个切分点")
+			continue
+		}
+		boundaries, err := slice.NormalizedBoundaries()
+		if err != nil {
+			result.AddError(field+".boundaries", err.Error())
+			continue
+		}
+		for j := 1; j < len(boundaries); j++ {
+			if compareSliceBoundaries(kind, boundaries[j-1], boundaries[j]) >= 0 {
+				result.AddError(field+".boundaries", fmt.Sprintf("切分点必须严格升序: %s 不大于 %s", slice.Boundaries[j], slice.Boundaries[j-1]))
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+)
+
+// ConnectionPlan 按连接数上限计算出的并行度和预计占用的连接数
+//
+// 本工具逐个执行迁移类型，同一时间只运行一个ora2pg主进程。导出数据时主进程按 PARALLEL_TABLES
+// 派生表级子进程，每个子进程用 ORACLE_COPIES 个连接读取Oracle、用 JOBS 个进程写入PostgreSQL，因此：
+//
+//	Oracle连接     = PARALLEL_TABLES × ORACLE_COPIES + 1
+//	PostgreSQL连接 = PARALLEL_TABLES × JOBS + 1
+//
+// 其中 +1 为主进程自身的连接。
+type ConnectionPlan struct {
+	// MaxConnections 每个数据库允许的最大连接数，0表示不限制
+	MaxConnections int
+	ParallelTables int
+	OracleCopies   int
+	ParallelJobs   int
+	// Adjusted 配置的并行度超过连接上限，已按上限降低
+	Adjusted bool
+}
+
+// OracleConnections 预计占用的Oracle连接数
+func (p ConnectionPlan) OracleConnections() int {
+	return p.ParallelTables*p.OracleCopies + 1
+}
+
+// PostgresConnections 预计占用的PostgreSQL连接数
+func (p ConnectionPlan) PostgresConnections() int {
+	return p.ParallelTables*p.ParallelJobs + 1
+}
+
+// Summary 并行度和连接数摘要
+func (p ConnectionPlan) Summary() string {
+	return fmt.Sprintf("PARALLEL_TABLES=%d ORACLE_COPIES=%d JOBS=%d，预计Oracle连接 %d、PostgreSQL连接 %d",
+		p.ParallelTables, p.OracleCopies, p.ParallelJobs, p.OracleConnections(), p.PostgresConnections())
+}
+
+// ConnectionPlan 计算不超过 MaxConnections 的并行度
+//
+// 超过上限时先减少同时导出的表数，仍超过时再降低每张表的分片数和写入作业数，
+// 尽量保留大表分片带来的表内并行。
+func (m *MigrationConfig) ConnectionPlan() ConnectionPlan {
+	plan := ConnectionPlan{
+		MaxConnections: m.MaxConnections,
+		ParallelTables: max(m.ParallelTables, 1),
+		OracleCopies:   m.OracleCopies(),
+		ParallelJobs:   max(m.ParallelJobs, 1),
+	}
+	if m.MaxConnections <= 0 {
+		return plan
+	}
+
+	// 扣除主进程的连接后，表级子进程可用的连接数
+	budget := max(m.MaxConnections-1, 1)
+	perTable := max(plan.OracleCopies, plan.ParallelJobs)
+	if plan.ParallelTables*perTable > budget {
+		plan.ParallelTables = max(budget/perTable, 1)
+		plan.Adjusted = true
+	}
+	if limit := budget / plan.ParallelTables; plan.OracleCopies > limit {
+		plan.OracleCopies = max(limit, 1)
+		plan.Adjusted = true
+	}
+	if limit := budget / plan.ParallelTables; plan.ParallelJobs > limit {
+		plan.ParallelJobs = max(limit, 1)
+		plan.Adjusted = true
+	}
+	return plan
+}
+
+// validateMaxConnections 验证连接数上限，至少需要主进程和一个工作连接
+func (v *Validator) validateMaxConnections(migration *MigrationConfig, result *ValidationResult) {
+	if migration.MaxConnections < 0 || migration.MaxConnections == 1 {
+		result.AddError("migration.max_connections", "最大连接数必须为0（不限制）或不小于2")
+	}
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConsistencyRule 跨字段的一致性检查规则，发现冗余或矛盾的配置时返回警告
+type ConsistencyRule struct {
+	Name  string
+	Check func(migration *MigrationConfig) []ValidationWarning
+}
+
+// consistencyRules 内置的一致性检查规则，按顺序执行
+var consistencyRules = []ConsistencyRule{
+	{Name: "duplicate-types", Check: checkDuplicateTypes},
+	{Name: "copy-insert", Check: checkCopyAndInsert},
+	{Name: "data-options-without-data", Check: checkDataOptionsWithoutData},
+	{Name: "truncate-incremental", Check: checkTruncateWithIncremental},
+	{Name: "drop-fkey-defer-constraints", Check: checkDropFkeyWithDeferConstraints},
+	{Name: "case-conflict", Check: checkCaseConflict},
+}
+
+// validateConsistency 执行一致性检查规则，结果只作为警告，不影响配置是否有效
+func (v *Validator) validateConsistency(migration *MigrationConfig, result *ValidationResult) {
+	for _, rule := range consistencyRules {
+		for _, warning := range rule.Check(migration) {
+			result.AddWarning(warning.Field, warning.Message, warning.Suggestion)
+		}
+	}
+}
+
+// migrationTypeSet 配置的迁移类型（大写）集合
+func migrationTypeSet(migration *MigrationConfig) map[string]bool {
+	types := make(map[string]bool, len(migration.Types))
+	for _, t := range migration.Types {
+		types[strings.ToUpper(strings.TrimSpace(t))] = true
+	}
+	return types
+}
+
+// switchEnabled 开关在配置中是否显式开启
+func switchEnabled(options map[string]bool, name string) bool {
+	for key, enabled := range options {
+		if strings.EqualFold(strings.TrimSpace(key), name) {
+			return enabled
+		}
+	}
+	return false
+}
+
+// checkDuplicateTypes 同一迁移类型配置了多次
+func checkDuplicateTypes(migration *MigrationConfig) []ValidationWarning {
+	counts := make(map[string]int)
+	var order []string
+	for _, t := range migration.Types {
+		name := strings.ToUpper(strings.TrimSpace(t))
+		if counts[name] == 0 {
+			order = append(order, name)
+		}
+		counts[name]++
+	}
+
+	var warnings []ValidationWarning
+	for _, name := range order {
+		if counts[name] > 1 {
+			warnings = append(warnings, ValidationWarning{
+				Field:      "migration.types",
+				Message:    fmt.Sprintf("迁移类型 %s 重复配置了 %d 次，只会执行一次", name, counts[name]),
+				Suggestion: fmt.Sprintf("删除多余的 %s", name),
+			})
+		}
+	}
+	return warnings
+}
+
+// checkCopyAndInsert 同时配置了 COPY 和 INSERT，数据会导入两次
+func checkCopyAndInsert(migration *MigrationConfig) []ValidationWarning {
+	types := migrationTypeSet(migration)
+	if !types["COPY"] || !types["INSERT"] {
+		return nil
+	}
+	return []ValidationWarning{{
+		Field:      "migration.types",
+		Message:    "同时配置了 COPY 和 INSERT，两者都导出表数据，数据会导入两次，可能产生重复行或主键冲突",
+		Suggestion: "只保留一种数据迁移方式，通常使用速度更快的 COPY",
+	}}
+}
+
+// checkDataOptionsWithoutData 配置了只对数据迁移生效的选项，但迁移类型中没有 COPY 或 INSERT
+func checkDataOptionsWithoutData(migration *MigrationConfig) []ValidationWarning {
+	types := migrationTypeSet(migration)
+	if len(types) == 0 || types["COPY"] || types["INSERT"] {
+		return nil
+	}
+
+	var fields []string
+	if migration.DeferConstraints {
+		fields = append(fields, "migration.defer_constraints")
+	}
+	if len(migration.LargeTables) > 0 {
+		fields = append(fields, "migration.large_tables")
+	}
+	if migration.Incremental.Enabled() {
+		fields = append(fields, "migration.incremental")
+	}
+	for _, name := range []string{"TRUNCATE_TABLE", "DROP_FKEY", "DROP_INDEXES"} {
+		if switchEnabled(migration.Options, name) {
+			fields = append(fields, "migration.options."+name)
+		}
+	}
+
+	warnings := make([]ValidationWarning, 0, len(fields))
+	for _, field := range fields {
+		warnings = append(warnings, ValidationWarning{
+			Field:      field,
+			Message:    "只对数据迁移生效，但迁移类型中没有 COPY 或 INSERT，该配置不会起作用",
+			Suggestion: "在 migration.types 中加入 COPY，或删除该配置",
+		})
+	}
+	return warnings
+}
+
+// checkTruncateWithIncremental 增量同步时 TRUNCATE_TABLE 会被强制关闭
+func checkTruncateWithIncremental(migration *MigrationConfig) []ValidationWarning {
+	if !migration.Incremental.Enabled() || !switchEnabled(migration.Options, "TRUNCATE_TABLE") {
+		return nil
+	}
+	return []ValidationWarning{{
+		Field:      "migration.options.TRUNCATE_TABLE",
+		Message:    "开启了 TRUNCATE_TABLE，同时配置了增量同步；增量同步时会关闭该开关，避免清空已同步的数据",
+		Suggestion: "首次全量迁移需要清空目标表时保留该开关，否则删除以免误解",
+	}}
+}
+
+// checkDropFkeyWithDeferConstraints DROP_FKEY 与 defer_constraints 都会在导入前删除外键
+func checkDropFkeyWithDeferConstraints(migration *MigrationConfig) []ValidationWarning {
+	if !migration.DeferConstraints || !switchEnabled(migration.Options, "DROP_FKEY") {
+		return nil
+	}
+	return []ValidationWarning{{
+		Field:      "migration.options.DROP_FKEY",
+		Message:    "DROP_FKEY 与 migration.defer_constraints 都会在导入数据前删除外键，功能重复",
+		Suggestion: "保留 defer_constraints（完成后会校验约束并生成结果报告），关闭 DROP_FKEY",
+	}}
+}
+
+// checkCaseConflict 命名规则要求转为小写，但开启了 PRESERVE_CASE
+func checkCaseConflict(migration *MigrationConfig) []ValidationWarning {
+	if migration.NamingConvention.normalizedCase() != NamingCaseLower || !switchEnabled(migration.Options, "PRESERVE_CASE") {
+		return nil
+	}
+	return []ValidationWarning{{
+		Field:      "migration.naming_convention.case",
+		Message:    "命名规则为 lower，但开启了 PRESERVE_CASE 开关，实际会保留Oracle中的大小写",
+		Suggestion: "删除 PRESERVE_CASE 开关，或把命名规则改为 preserve",
+	}}
+}
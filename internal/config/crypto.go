@@ -0,0 +1,214 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EncryptedPrefix 加密字段的标记前缀
+const EncryptedPrefix = "enc:"
+
+// MasterPasswordEnv 提供主密码的环境变量
+const MasterPasswordEnv = "ORA2PG_ADMIN_MASTER_PASSWORD"
+
+// 密钥派生参数：PBKDF2-SHA256，每个字段独立随机盐
+const (
+	kdfIterations = 210000
+	kdfSaltSize   = 16
+	kdfKeySize    = 32
+)
+
+var (
+	// ErrMasterPasswordRequired 配置包含加密字段但未提供主密码
+	ErrMasterPasswordRequired = errors.New("配置包含加密字段，需要主密码")
+	// ErrInvalidMasterPassword 主密码错误或密文被篡改
+	ErrInvalidMasterPassword = errors.New("主密码错误或加密数据已损坏")
+)
+
+// masterPasswordPrompt 交互输入主密码的回调，由命令层设置
+var masterPasswordPrompt func() (string, error)
+
+// SetMasterPasswordPrompt 设置交互输入主密码的回调
+func SetMasterPasswordPrompt(prompt func() (string, error)) {
+	masterPasswordPrompt = prompt
+}
+
+// IsEncrypted 判断字段值是否已加密
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, EncryptedPrefix)
+}
+
+// EncryptValue 使用主密码加密字段值，格式为 enc:base64(盐|随机数|密文)
+func EncryptValue(plaintext, masterPassword string) (string, error) {
+	salt := make([]byte, kdfSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("生成随机盐失败: %v", err)
+	}
+
+	gcm, err := newGCM(masterPassword, salt)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %v", err)
+	}
+
+	data := append(salt, nonce...)
+	data = gcm.Seal(data, nonce, []byte(plaintext), nil)
+	return EncryptedPrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// DecryptValue 使用主密码解密字段值，未加密的值原样返回
+func DecryptValue(value, masterPassword string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, EncryptedPrefix))
+	if err != nil || len(data) < kdfSaltSize {
+		return "", ErrInvalidMasterPassword
+	}
+
+	salt, data := data[:kdfSaltSize], data[kdfSaltSize:]
+	gcm, err := newGCM(masterPassword, salt)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", ErrInvalidMasterPassword
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrInvalidMasterPassword
+	}
+	return string(plaintext), nil
+}
+
+// newGCM 由主密码和盐派生AES-256-GCM
+func newGCM(masterPassword string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, masterPassword, salt, kdfIterations, kdfKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("派生加密密钥失败: %v", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("创建加密器失败: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// secretFields 返回配置中需要加密的敏感字段
+func secretFields(cfg *ProjectConfig) []*string {
+	fields := []*string{
+		&cfg.Oracle.Password,
+		&cfg.Oracle.TestPassword,
+		&cfg.PostgreSQL.Password,
+		&cfg.PostgreSQL.TestPassword,
+		&cfg.Notifications.Email.Password,
+		&cfg.Notifications.Webhook.Token,
+		&cfg.Migration.RemoteStorage.S3.SecretAccessKey,
+	}
+	for i := range cfg.Notifications.Bots {
+		fields = append(fields, &cfg.Notifications.Bots[i].Secret)
+	}
+	for i := range cfg.PostgreSQL.Targets {
+		fields = append(fields, &cfg.PostgreSQL.Targets[i].Password, &cfg.PostgreSQL.Targets[i].TestPassword)
+	}
+	return fields
+}
+
+// hasEncryptedFields 配置中是否存在加密字段
+func hasEncryptedFields(cfg *ProjectConfig) bool {
+	for _, field := range secretFields(cfg) {
+		if IsEncrypted(*field) {
+			return true
+		}
+	}
+	return false
+}
+
+// isEnvReference 是否为 ${VAR} 形式的环境变量引用
+func isEnvReference(value string) bool {
+	return strings.HasPrefix(value, "${") && strings.HasSuffix(value, "}")
+}
+
+// resolveMasterPassword 依次从已设置的值、环境变量、交互输入获取主密码
+func (m *Manager) resolveMasterPassword() (string, error) {
+	if m.masterPassword != "" {
+		return m.masterPassword, nil
+	}
+	if value := os.Getenv(MasterPasswordEnv); value != "" {
+		m.masterPassword = value
+		return value, nil
+	}
+	if masterPasswordPrompt != nil {
+		value, err := masterPasswordPrompt()
+		if err != nil {
+			return "", fmt.Errorf("读取主密码失败: %v", err)
+		}
+		if value != "" {
+			m.masterPassword = value
+			return value, nil
+		}
+	}
+	return "", ErrMasterPasswordRequired
+}
+
+// decryptSecrets 解密已加载配置中的敏感字段
+func (m *Manager) decryptSecrets() error {
+	if !hasEncryptedFields(m.config) {
+		return nil
+	}
+	m.encryptSecrets = true
+
+	masterPassword, err := m.resolveMasterPassword()
+	if err != nil {
+		return err
+	}
+	for _, field := range secretFields(m.config) {
+		value, err := DecryptValue(*field, masterPassword)
+		if err != nil {
+			// 主密码错误时清除，避免后续保存时使用
+			m.masterPassword = ""
+			return err
+		}
+		*field = value
+	}
+	return nil
+}
+
+// encryptedCopy 返回敏感字段加密后的配置副本，内存中的配置保持明文
+func (m *Manager) encryptedCopy() (*ProjectConfig, error) {
+	masterPassword, err := m.resolveMasterPassword()
+	if err != nil {
+		return nil, err
+	}
+
+	copied := *m.config
+	copied.PostgreSQL.Targets = append([]PostgreConfig(nil), m.config.PostgreSQL.Targets...)
+	copied.Notifications.Bots = append([]ChatBotConfig(nil), m.config.Notifications.Bots...)
+	for _, field := range secretFields(&copied) {
+		// 空值和环境变量引用本身不含密码，保持原样
+		if *field == "" || IsEncrypted(*field) || isEnvReference(*field) {
+			continue
+		}
+		value, err := EncryptValue(*field, masterPassword)
+		if err != nil {
+			return nil, err
+		}
+		*field = value
+	}
+	return &copied, nil
+}
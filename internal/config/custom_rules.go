@@ -0,0 +1,321 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationRulesFileName 自定义校验规则文件名，可放在用户级目录（~/.ora2pg-admin）和项目的 .ora2pg-admin 目录
+const ValidationRulesFileName = "validation-rules.yaml"
+
+// 自定义规则的级别
+const (
+	RuleLevelError   = "error"
+	RuleLevelWarning = "warning"
+)
+
+// customRuleRoots 规则字段路径允许的顶层配置节
+var customRuleRoots = []string{"project", "oracle", "postgresql", "migration", "oracle_client", "notifications", "metrics", "environment", "tools"}
+
+// CustomRule 组织自定义的字段约束，字段路径使用配置文件中的键名，如 migration.parallel_jobs、migration.options.DROP_FKEY
+type CustomRule struct {
+	Name  string `yaml:"name,omitempty"`
+	Field string `yaml:"field"`
+	// Required 字段必须配置（非空）
+	Required bool `yaml:"required,omitempty"`
+	// Forbidden 字段不允许配置（布尔值为 false 视为未配置）
+	Forbidden bool `yaml:"forbidden,omitempty"`
+	// Min/Max 数值的范围
+	Min *float64 `yaml:"min,omitempty"`
+	Max *float64 `yaml:"max,omitempty"`
+	// Pattern 值（列表时为每个元素）必须匹配的正则表达式
+	Pattern string `yaml:"pattern,omitempty"`
+	// Enum 值（列表时为每个元素）只能是其中之一，不区分大小写
+	Enum []string `yaml:"enum,omitempty"`
+	// Contains 列表必须包含的元素，不区分大小写
+	Contains []string `yaml:"contains,omitempty"`
+	// Message 违反规则时的提示，为空时按约束生成
+	Message string `yaml:"message,omitempty"`
+	// Level 违反规则时报告为错误（error，默认）还是警告（warning）
+	Level string `yaml:"level,omitempty"`
+	// Source 规则所在的文件
+	Source string `yaml:"-"`
+
+	pattern  *regexp.Regexp
+	compiled bool
+}
+
+// customRuleFile 自定义规则文件的格式
+type customRuleFile struct {
+	Rules []CustomRule `yaml:"rules"`
+}
+
+// LoadCustomRules 加载并检查自定义校验规则文件
+func LoadCustomRules(path string) ([]CustomRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取自定义校验规则失败: %v", err)
+	}
+	var file customRuleFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("解析自定义校验规则 %s 失败: %v", path, err)
+	}
+	for i := range file.Rules {
+		rule := &file.Rules[i]
+		rule.Source = path
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("%s 第 %d 条规则%s: %v", path, i+1, rule.label(), err)
+		}
+	}
+	return file.Rules, nil
+}
+
+// LoadDefaultCustomRules 依次加载用户级和项目级的自定义校验规则，文件不存在时跳过
+func LoadDefaultCustomRules(projectDir string) ([]CustomRule, error) {
+	var paths []string
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".ora2pg-admin", ValidationRulesFileName))
+	}
+	paths = append(paths, filepath.Join(projectDir, ".ora2pg-admin", ValidationRulesFileName))
+
+	var rules []CustomRule
+	for _, path := range paths {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		loaded, err := LoadCustomRules(path)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, loaded...)
+	}
+	return rules, nil
+}
+
+// label 规则名称，用于错误信息
+func (r *CustomRule) label() string {
+	if r.Name != "" {
+		return "（" + r.Name + "）"
+	}
+	return ""
+}
+
+// compile 检查规则定义并编译正则表达式
+func (r *CustomRule) compile() error {
+	r.Field = strings.TrimSpace(r.Field)
+	if r.Field == "" {
+		return fmt.Errorf("缺少 field")
+	}
+	root, _, _ := strings.Cut(r.Field, ".")
+	known := false
+	for _, name := range customRuleRoots {
+		known = known || name == root
+	}
+	if !known {
+		return fmt.Errorf("字段 %s 不在配置中，顶层只能是 %s", r.Field, strings.Join(customRuleRoots, "、"))
+	}
+	if !r.Required && !r.Forbidden && r.Min == nil && r.Max == nil && r.Pattern == "" && len(r.Enum) == 0 && len(r.Contains) == 0 {
+		return fmt.Errorf("至少需要一种约束（required、forbidden、min、max、pattern、enum、contains）")
+	}
+	if r.Required && r.Forbidden {
+		return fmt.Errorf("required 与 forbidden 不能同时使用")
+	}
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		return fmt.Errorf("min 不能大于 max")
+	}
+	switch strings.ToLower(strings.TrimSpace(r.Level)) {
+	case "", RuleLevelError:
+		r.Level = RuleLevelError
+	case RuleLevelWarning:
+		r.Level = RuleLevelWarning
+	default:
+		return fmt.Errorf("level 只能是 %s 或 %s", RuleLevelError, RuleLevelWarning)
+	}
+	if r.Pattern != "" {
+		pattern, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("正则表达式无效: %v", err)
+		}
+		r.pattern = pattern
+	}
+	r.compiled = true
+	return nil
+}
+
+// AddCustomRules 在内置规则之后追加自定义规则
+func (v *Validator) AddCustomRules(rules ...CustomRule) {
+	v.customRules = append(v.customRules, rules...)
+}
+
+// validateCustomRules 执行自定义规则，字段值从配置的YAML表示中按路径读取
+func (v *Validator) validateCustomRules(config *ProjectConfig, result *ValidationResult) {
+	if len(v.customRules) == 0 {
+		return
+	}
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		result.AddError("validation_rules", fmt.Sprintf("无法读取配置执行自定义规则: %v", err))
+		return
+	}
+	var tree map[string]interface{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		result.AddError("validation_rules", fmt.Sprintf("无法读取配置执行自定义规则: %v", err))
+		return
+	}
+
+	for i := range v.customRules {
+		rule := &v.customRules[i]
+		if !rule.compiled {
+			if err := rule.compile(); err != nil {
+				result.AddError(rule.Field, fmt.Sprintf("自定义规则无效: %v", err))
+				continue
+			}
+		}
+		message := rule.check(lookupConfigPath(tree, rule.Field))
+		if message == "" {
+			continue
+		}
+		if rule.Message != "" {
+			message = rule.Message
+		}
+		if rule.Level == RuleLevelWarning {
+			result.AddWarning(rule.Field, message, "")
+		} else {
+			result.AddError(rule.Field, message)
+		}
+	}
+}
+
+// lookupConfigPath 按点分隔的路径读取值，映射的键不区分大小写，不存在时返回 nil
+func lookupConfigPath(tree map[string]interface{}, path string) interface{} {
+	var current interface{} = tree
+	for _, key := range strings.Split(path, ".") {
+		node, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value, exists := node[key]
+		if !exists {
+			for name, v := range node {
+				if strings.EqualFold(name, key) {
+					value, exists = v, true
+					break
+				}
+			}
+		}
+		if !exists {
+			return nil
+		}
+		current = value
+	}
+	return current
+}
+
+// isEmptyValue 值是否视为未配置
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// check 检查值是否满足规则，返回违反规则的说明，满足时返回空字符串
+func (r *CustomRule) check(value interface{}) string {
+	if r.Forbidden {
+		if enabled, ok := value.(bool); (ok && enabled) || (!ok && !isEmptyValue(value)) {
+			return "按配置规范不允许配置该字段"
+		}
+		return ""
+	}
+	if isEmptyValue(value) {
+		if r.Required {
+			return "按配置规范必须配置该字段"
+		}
+		return ""
+	}
+
+	if r.Min != nil || r.Max != nil {
+		number, ok := numericValue(value)
+		if !ok {
+			return fmt.Sprintf("值 %v 不是数字", value)
+		}
+		if r.Min != nil && number < *r.Min {
+			return fmt.Sprintf("值 %v 小于配置规范的下限 %v", value, *r.Min)
+		}
+		if r.Max != nil && number > *r.Max {
+			return fmt.Sprintf("值 %v 超过配置规范的上限 %v", value, *r.Max)
+		}
+	}
+
+	items, isList := value.([]interface{})
+	if !isList {
+		items = []interface{}{value}
+	}
+	for _, item := range items {
+		text := fmt.Sprint(item)
+		if r.pattern != nil && !r.pattern.MatchString(text) {
+			return fmt.Sprintf("值 %s 不符合配置规范的格式 %s", text, r.Pattern)
+		}
+		if len(r.Enum) > 0 && !containsFold(r.Enum, text) {
+			return fmt.Sprintf("值 %s 不在配置规范允许的范围内（%s）", text, strings.Join(r.Enum, "、"))
+		}
+	}
+
+	if len(r.Contains) > 0 {
+		present := make([]string, len(items))
+		for i, item := range items {
+			present[i] = fmt.Sprint(item)
+		}
+		var missing []string
+		for _, required := range r.Contains {
+			if !containsFold(present, required) {
+				missing = append(missing, required)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Sprintf("按配置规范必须包含 %s", strings.Join(missing, "、"))
+		}
+	}
+	return ""
+}
+
+// numericValue 把YAML中的数字或数字字符串转换为浮点数
+func numericValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+// containsFold 列表中是否有与 value 相同（不区分大小写）的元素
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), strings.TrimSpace(value)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// dumpFileExtensions expdp/exp 导出文件的常见扩展名
+var dumpFileExtensions = []string{".dmp", ".dpdmp"}
+
+// DumpFileSuggestions 连接配置指向导出文件时的处理建议
+var DumpFileSuggestions = []string{
+	"ora2pg-admin 通过 ora2pg 连接在线Oracle数据库迁移，无法直接读取 expdp/exp 导出文件",
+	"先用 impdp 把导出文件导入一个临时Oracle实例（如 Docker 中的 Oracle Free/XE），例如: impdp system/密码 directory=DATA_PUMP_DIR dumpfile=export.dmp schemas=HR",
+	"导入完成后运行 'ora2pg-admin 配置 数据库'，把连接指向该实例",
+}
+
+// IsDumpFilePath 判断输入是否是导出文件路径，而不是主机名、SID或服务名
+func IsDumpFilePath(value string) bool {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return false
+	}
+
+	lower := strings.ToLower(value)
+	for _, ext := range dumpFileExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+
+	// 主机名和服务名不含路径分隔符，指向已存在的文件时视为导出文件
+	if strings.ContainsAny(value, `/\`) {
+		if info, err := os.Stat(value); err == nil && info.Mode().IsRegular() {
+			return true
+		}
+	}
+	return false
+}
+
+// DumpFileReference 查找Oracle连接配置中填写了导出文件路径的字段，返回字段名和值
+func DumpFileReference(oracle *OracleConfig) (string, string, bool) {
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"oracle.host", oracle.Host},
+		{"oracle.sid", oracle.SID},
+		{"oracle.service", oracle.Service},
+	}
+	for _, field := range fields {
+		if IsDumpFilePath(field.value) {
+			return field.name, strings.TrimSpace(field.value), true
+		}
+	}
+	return "", "", false
+}
+
+// CheckDumpFileReference Oracle连接配置指向导出文件时返回错误
+func CheckDumpFileReference(oracle *OracleConfig) error {
+	field, value, found := DumpFileReference(oracle)
+	if !found {
+		return nil
+	}
+
+	builder := utils.NewError(utils.ErrorTypeConfig, "ORACLE_DUMP_FILE_CONFIGURED").
+		Message("Oracle连接配置指向了导出文件，而不是在线数据库").
+		Details(fmt.Sprintf("%s: %s", field, value))
+	for _, suggestion := range DumpFileSuggestions {
+		builder = builder.Suggestion(suggestion)
+	}
+	return builder.Build()
+}
@@ -0,0 +1,261 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// EnvOverridePrefix 覆盖配置项的环境变量前缀
+//
+// 环境变量名为前缀加配置路径：路径各段取配置文件中的字段名，转大写后用下划线连接，
+// 如 oracle.host → ORA2PG_ADMIN_ORACLE_HOST，migration.parallel_jobs → ORA2PG_ADMIN_MIGRATION_PARALLEL_JOBS。
+// 加载优先级为 环境变量 > 配置文件 > 默认值。与配置文件中 ${VAR} 占位符的区别：占位符只用于密码等敏感字段，
+// 由配置文件决定从哪个变量取值；覆盖变量可作用于任意标量配置项，不需要修改配置文件。两者同时存在时覆盖变量优先，
+// 覆盖的值按原样使用，不再展开 ${VAR}，也不解密。
+const EnvOverridePrefix = "ORA2PG_ADMIN_"
+
+// 可覆盖配置项的值类型
+const (
+	EnvOverrideString = "string"
+	EnvOverrideInt    = "int"
+	EnvOverrideBool   = "bool"
+	EnvOverrideFloat  = "float"
+	EnvOverrideList   = "list" // 逗号分隔的字符串列表
+)
+
+// envOverrideTypeNames 值类型的说明，用于错误信息
+var envOverrideTypeNames = map[string]string{
+	EnvOverrideInt:   "整数",
+	EnvOverrideBool:  "布尔值（true/false/1/0）",
+	EnvOverrideFloat: "数字",
+}
+
+// EnvOverrideField 可被环境变量覆盖的配置项
+type EnvOverrideField struct {
+	Env  string // 环境变量名，如 ORA2PG_ADMIN_ORACLE_HOST
+	Path string // 配置路径，如 oracle.host
+	Type string // 值类型
+
+	index []int
+	// noInterpolate 运行时才渲染的模板字段（标记 interpolate:"-"），加载配置时不展开
+	noInterpolate bool
+}
+
+// EnvOverride 加载配置时生效的环境变量覆盖
+type EnvOverride struct {
+	EnvOverrideField
+	// Secret 是否为敏感字段，显示时不应输出值
+	Secret bool
+
+	fileValue reflect.Value // 配置文件中的值，保存配置时写回
+	envValue  reflect.Value
+}
+
+// EnvOverrideFields 按配置结构顺序列出全部可被环境变量覆盖的配置项
+//
+// 只支持字符串、整数、布尔、浮点数和字符串列表；map 和对象列表（如 large_tables）不能通过环境变量覆盖。
+func EnvOverrideFields() []EnvOverrideField {
+	var fields []EnvOverrideField
+	collectEnvOverrideFields(reflect.TypeOf(ProjectConfig{}), "", nil, &fields)
+	return fields
+}
+
+// collectEnvOverrideFields 递归收集结构体中的标量字段
+func collectEnvOverrideFields(structType reflect.Type, prefix string, index []int, fields *[]EnvOverrideField) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if !field.IsExported() || name == "" || name == "-" {
+			continue
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Struct && fieldType != reflect.TypeOf(time.Time{}) {
+			collectEnvOverrideFields(fieldType, path, fieldIndex, fields)
+			continue
+		}
+		valueType := envOverrideType(fieldType)
+		if valueType == "" {
+			continue
+		}
+		*fields = append(*fields, EnvOverrideField{
+			Env:   EnvOverridePrefix + strings.ToUpper(strings.ReplaceAll(path, ".", "_")),
+			Path:  path,
+			Type:  valueType,
+			index: fieldIndex,
+
+			noInterpolate: field.Tag.Get("interpolate") == "-",
+		})
+	}
+}
+
+// envOverrideType 字段的覆盖值类型，不支持的类型返回空
+func envOverrideType(fieldType reflect.Type) string {
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	switch fieldType.Kind() {
+	case reflect.String:
+		return EnvOverrideString
+	case reflect.Bool:
+		return EnvOverrideBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// time.Duration 在配置中以字符串保存，这里不会出现
+		return EnvOverrideInt
+	case reflect.Float32, reflect.Float64:
+		return EnvOverrideFloat
+	case reflect.Slice:
+		if fieldType.Elem().Kind() == reflect.String {
+			return EnvOverrideList
+		}
+	}
+	return ""
+}
+
+// applyEnvOverrides 用环境变量覆盖已加载的配置，值为空的环境变量视为未设置；在展开 ${VAR} 之前执行，保存时写回占位符
+func (m *Manager) applyEnvOverrides() error {
+	m.envOverrides = nil
+	secrets := make(map[uintptr]bool)
+	for _, field := range secretFields(m.config) {
+		secrets[reflect.ValueOf(field).Pointer()] = true
+	}
+
+	root := reflect.ValueOf(m.config).Elem()
+	for _, field := range EnvOverrideFields() {
+		value := os.Getenv(field.Env)
+		if value == "" {
+			continue
+		}
+		target := root.FieldByIndex(field.index)
+		converted, err := convertEnvOverride(target.Type(), field.Type, value)
+		if err != nil {
+			return utils.NewError(utils.ErrorTypeConfig, "CONFIG_ENV_OVERRIDE_INVALID").
+				Message(fmt.Sprintf("环境变量 %s 的值无效", field.Env)).
+				Details(fmt.Sprintf("%s 需要%s，实际为 %q", field.Path, envOverrideTypeNames[field.Type], value)).
+				Cause(err).
+				Suggestion(fmt.Sprintf("修改或取消设置环境变量 %s", field.Env)).
+				Build()
+		}
+
+		override := EnvOverride{
+			EnvOverrideField: field,
+			Secret:           secrets[target.Addr().Pointer()],
+			fileValue:        reflect.New(target.Type()).Elem(),
+			envValue:         converted,
+		}
+		override.fileValue.Set(target)
+		target.Set(converted)
+		m.envOverrides = append(m.envOverrides, override)
+	}
+	return nil
+}
+
+// convertEnvOverride 把环境变量的值转换为字段类型
+func convertEnvOverride(fieldType reflect.Type, valueType, value string) (reflect.Value, error) {
+	if fieldType.Kind() == reflect.Ptr {
+		elem, err := convertEnvOverride(fieldType.Elem(), valueType, value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		pointer := reflect.New(fieldType.Elem())
+		pointer.Elem().Set(elem)
+		return pointer, nil
+	}
+
+	converted := reflect.New(fieldType).Elem()
+	value = strings.TrimSpace(value)
+	switch valueType {
+	case EnvOverrideString:
+		converted.SetString(value)
+	case EnvOverrideBool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		converted.SetBool(parsed)
+	case EnvOverrideInt:
+		parsed, err := strconv.ParseInt(value, 10, fieldType.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		converted.SetInt(parsed)
+	case EnvOverrideFloat:
+		parsed, err := strconv.ParseFloat(value, fieldType.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		converted.SetFloat(parsed)
+	case EnvOverrideList:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		converted.Set(reflect.ValueOf(items))
+	}
+	return converted, nil
+}
+
+// isEnvOverridden 字段是否被环境变量覆盖
+func (m *Manager) isEnvOverridden(field *string) bool {
+	root := reflect.ValueOf(m.config).Elem()
+	for _, override := range m.envOverrides {
+		if root.FieldByIndex(override.index).Addr().Interface() == field {
+			return true
+		}
+	}
+	return false
+}
+
+// EnvOverrides 加载配置时生效的环境变量覆盖
+func (m *Manager) EnvOverrides() []EnvOverride {
+	return m.envOverrides
+}
+
+// withoutEnvOverrides 保存配置前把被覆盖的字段恢复为配置文件中的值，返回重新应用覆盖的函数
+//
+// 加载后又被修改过的字段（如通过配置向导）不再等于覆盖值，按修改后的值保存。
+func (m *Manager) withoutEnvOverrides() func() {
+	root := reflect.ValueOf(m.config).Elem()
+	var restored []EnvOverride
+	for _, override := range m.envOverrides {
+		target := root.FieldByIndex(override.index)
+		if !reflect.DeepEqual(target.Interface(), override.envValue.Interface()) {
+			continue
+		}
+		target.Set(override.fileValue)
+		restored = append(restored, override)
+	}
+	return func() {
+		for _, override := range restored {
+			root.FieldByIndex(override.index).Set(override.envValue)
+		}
+	}
+}
+
+// DisplayValue 用于显示的覆盖值，敏感字段不显示
+func (o *EnvOverride) DisplayValue() string {
+	if o.Secret {
+		return "已设置（不显示）"
+	}
+	value := o.envValue
+	if value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if items, ok := value.Interface().([]string); ok {
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprint(value.Interface())
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// envNamePattern 环境变量名：字母或下划线开头，只包含字母、数字和下划线
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateEnvName 验证环境变量名
+func ValidateEnvName(name string) error {
+	if !envNamePattern.MatchString(name) {
+		return fmt.Errorf("环境变量名 %q 无效，只能包含字母、数字和下划线，且不能以数字开头", name)
+	}
+	return nil
+}
+
+// MergeEnvironment 把配置的环境变量合并到 base 中并返回 base
+//
+// 合并顺序为 系统环境 → base（工具的默认值）→ 配置，后者覆盖前者。值中的 $VAR、${VAR}
+// 按 base 和系统环境展开，如 LD_LIBRARY_PATH: /opt/lib:$LD_LIBRARY_PATH 会追加在默认值之前。
+func MergeEnvironment(base, custom map[string]string) map[string]string {
+	if base == nil {
+		base = make(map[string]string, len(custom))
+	}
+	lookup := func(name string) string {
+		if value, exists := base[name]; exists {
+			return value
+		}
+		return os.Getenv(name)
+	}
+
+	// 先按原值展开再写入，变量之间互相引用时不依赖 map 的遍历顺序
+	expanded := make(map[string]string, len(custom))
+	for name, value := range custom {
+		expanded[name] = os.Expand(value, lookup)
+	}
+	for name, value := range expanded {
+		base[name] = value
+	}
+	return base
+}
+
+// EnvironmentNames 按名称排序的自定义环境变量名
+func (c *ProjectConfig) EnvironmentNames() []string {
+	names := make([]string, 0, len(c.Environment))
+	for name := range c.Environment {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateEnvironment 验证自定义环境变量
+func (v *Validator) validateEnvironment(environment map[string]string, result *ValidationResult) {
+	for name, value := range environment {
+		field := "environment." + name
+		if err := ValidateEnvName(name); err != nil {
+			result.AddError(field, err.Error())
+		}
+		if strings.ContainsAny(value, "\x00\n") {
+			result.AddError(field, "环境变量的值不能包含换行或空字符")
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// Format 配置文件的序列化格式
+type Format string
+
+const (
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+// ProjectConfigDir 项目配置所在的目录
+const ProjectConfigDir = ".ora2pg-admin"
+
+// projectConfigNames 项目配置文件的候选名称，按查找顺序排列
+var projectConfigNames = []string{"config.yaml", "config.yml", "config.json"}
+
+// ParseFormat 解析格式名称，如 --config-format 的值
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "json":
+		return FormatJSON, nil
+	}
+	return "", utils.NewError(utils.ErrorTypeValidation, "CONFIG_FORMAT_UNSUPPORTED").
+		Message(fmt.Sprintf("不支持的配置文件格式: %s", name)).
+		Suggestion("可选格式: yaml, json").
+		Build()
+}
+
+// FormatFromPath 按文件扩展名确定配置格式：.yaml、.yml 为YAML，.json 为JSON
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".json":
+		return FormatJSON, nil
+	}
+	return "", utils.NewError(utils.ErrorTypeConfig, "CONFIG_FORMAT_UNSUPPORTED").
+		Message(fmt.Sprintf("无法根据扩展名确定配置文件格式: %s", path)).
+		Suggestion("配置文件的扩展名应为 .yaml、.yml 或 .json").
+		Build()
+}
+
+// FileName 该格式的项目配置文件名
+func (f Format) FileName() string {
+	if f == FormatJSON {
+		return "config.json"
+	}
+	return "config.yaml"
+}
+
+// ProjectConfigPath 项目目录中已存在的配置文件路径，都不存在时返回默认的 config.yaml
+func ProjectConfigPath(projectDir string) string {
+	for _, name := range projectConfigNames {
+		path := filepath.Join(projectDir, ProjectConfigDir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(projectDir, ProjectConfigDir, FormatYAML.FileName())
+}
+
+// unmarshalConfigMap 按文件格式把配置内容解析为映射，空内容返回空映射
+func unmarshalConfigMap(path string, data []byte) (map[string]interface{}, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{}
+	if format == FormatJSON {
+		if len(bytes.TrimSpace(data)) == 0 {
+			return values, nil
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&values); err != nil {
+			return nil, formatMismatchError(path, format, data, err)
+		}
+		if values == nil {
+			return map[string]interface{}{}, nil
+		}
+		return normalizeJSONValue(values).(map[string]interface{}), nil
+	}
+
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+	}
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	return values, nil
+}
+
+// formatMismatchError 解析失败的错误，内容是另一种格式时提示修改扩展名
+func formatMismatchError(path string, format Format, data []byte, cause error) error {
+	var values map[string]interface{}
+	trimmed := bytes.TrimSpace(data)
+	if format == FormatJSON && !bytes.HasPrefix(trimmed, []byte("{")) && yaml.Unmarshal(trimmed, &values) == nil && values != nil {
+		return utils.NewError(utils.ErrorTypeConfig, "CONFIG_FORMAT_MISMATCH").
+			Message(fmt.Sprintf("配置文件 %s 的扩展名为 .json，但内容是YAML格式", path)).
+			Details(cause.Error()).
+			Cause(cause).
+			Suggestion("把文件扩展名改为 .yaml，或把内容转换为JSON").
+			Build()
+	}
+	return fmt.Errorf("解析配置文件 %s 失败: %v", path, cause)
+}
+
+// normalizeJSONValue 把JSON数字转换为整数或浮点数，使其与YAML解析出的值一致
+func normalizeJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return int(n)
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeJSONValue(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeJSONValue(item)
+		}
+		return v
+	}
+	return value
+}
+
+// decodeConfigMap 把合并后的映射解码到配置结构体，两种格式都使用同一套字段名
+func decodeConfigMap(values map[string]interface{}, cfg *ProjectConfig) error {
+	data, err := yaml.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("解析配置文件失败: %v", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("解析配置文件失败: %v", err)
+	}
+	return nil
+}
+
+// marshalConfig 按文件格式序列化配置，JSON使用两个空格缩进
+func marshalConfig(path string, cfg *ProjectConfig) ([]byte, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if format == FormatYAML {
+		return yaml.Marshal(cfg)
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
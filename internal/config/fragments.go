@@ -0,0 +1,491 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// FragmentsDirName 配置片段目录名，可放在用户级目录（~/.ora2pg-admin）和项目的 .ora2pg-admin 目录
+const FragmentsDirName = "fragments"
+
+// fragmentExt 配置片段文件扩展名
+const fragmentExt = ".conf"
+
+// 配置片段的来源，同名时项目级优先于用户级，用户级优先于内置
+const (
+	FragmentSourceBuiltin = "builtin"
+	FragmentSourceUser    = "user"
+	FragmentSourceProject = "project"
+)
+
+// fragmentDescriptionPrefixes 片段开头注释中说明的写法
+var fragmentDescriptionPrefixes = []string{"说明:", "说明：", "description:"}
+
+// fragmentNamePattern 片段名称
+var fragmentNamePattern = regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)
+
+// directiveNamePattern ora2pg指令名
+var directiveNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// fragmentManagedDirectives 由项目配置生成的指令及对应的配置项，片段中不允许设置，避免与项目配置不一致
+var fragmentManagedDirectives = map[string]string{
+	"ORACLE_DSN":      "oracle",
+	"ORACLE_USER":     "oracle.username",
+	"ORACLE_PWD":      "oracle.password",
+	"SCHEMA":          "oracle.schema",
+	"PG_DSN":          "postgresql",
+	"PG_USER":         "postgresql.username",
+	"PG_PWD":          "postgresql.password",
+	"PG_SCHEMA":       "postgresql.schema",
+	"TYPE":            "migration.types",
+	"OUTPUT_DIR":      "migration.output_dir",
+	"JOBS":            "migration.parallel_jobs",
+	"PARALLEL_TABLES": "migration.parallel_tables",
+	"ORACLE_COPIES":   "migration.large_tables",
+	"DEFINED_PK":      "migration.large_tables",
+	"ALLOW":           "migration.allow_tables",
+	"REPLACE_TABLES":  "migration.naming_convention",
+	"WHERE":           "migration.incremental 和 migration.batching",
+}
+
+// fragmentListDirectives 值为列表的指令及其分隔符，多个来源的值合并去重，其他指令后面的覆盖前面的
+var fragmentListDirectives = map[string]string{
+	"EXCLUDE":      " ",
+	"REPLACE_COLS": " ",
+	"MODIFY_TYPE":  ",",
+	"DATA_TYPE":    ",",
+}
+
+// builtinFragments 内置的常用配置片段
+var builtinFragments = []Ora2pgFragment{
+	{
+		Name:        "performance",
+		Description: "性能优化：加大每批读取的行数，导入时删除索引并关闭同步提交",
+		Content: `DATA_LIMIT 20000
+DROP_INDEXES 1
+SYNCHRONOUS_COMMIT 0
+`,
+	},
+	{
+		Name:        "lob",
+		Description: "LOB处理：读取大字段时不截断，含LOB的表减少每批读取的行数",
+		Content: `LONGREADLEN 10485760
+LONGTRUNCOK 0
+BLOB_LIMIT 500
+NO_BLOB_EXPORT 0
+`,
+	},
+	{
+		Name:        "structure-first",
+		Description: "结构优先：索引、约束和外键输出到单独的文件，便于导入数据后再创建",
+		Content: `FILE_PER_INDEX 1
+FILE_PER_CONSTRAINT 1
+FILE_PER_FKEYS 1
+`,
+	},
+	{
+		Name:        "data-first",
+		Description: "数据优先：导入前删除外键和索引、禁用触发器，导入后重建",
+		Content: `DROP_FKEY 1
+DROP_INDEXES 1
+DISABLE_TRIGGERS 1
+`,
+	},
+	{
+		Name:        "minimal-downtime",
+		Description: "最小停机：在性能优化的基础上延迟外键检查，减少切换窗口内的导入时间",
+		Content: `DATA_LIMIT 50000
+DROP_INDEXES 1
+DROP_FKEY 1
+SYNCHRONOUS_COMMIT 0
+DEFER_FKEY 1
+`,
+	},
+}
+
+// Ora2pgFragment 可复用的ora2pg配置片段
+//
+// 片段为ora2pg配置格式的指令（NAME value 或 NAME=value），可使用与主模板相同的模板变量，如 {{.ParallelJobs}}；
+// 开头的 "# 说明: ..." 注释作为片段说明。
+type Ora2pgFragment struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Source      string `json:"source"`
+	Path        string `json:"path,omitempty"`
+	Content     string `json:"-"`
+}
+
+// FragmentDirective 片段中的一条指令
+type FragmentDirective struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// MergedDirective 合并后由片段设置的指令
+type MergedDirective struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// Fragment 最终生效的片段，列表指令为最后一个设置该指令的片段
+	Fragment string `json:"fragment"`
+	// Overridden 被覆盖的来源："模板" 或片段名
+	Overridden []string `json:"overridden,omitempty"`
+}
+
+// FragmentSearchDirs 查找配置片段的目录，按优先级从高到低：项目级、用户级
+func FragmentSearchDirs(projectDir string) []string {
+	dirs := []string{filepath.Join(projectDir, ".ora2pg-admin", FragmentsDirName)}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".ora2pg-admin", FragmentsDirName))
+	}
+	return dirs
+}
+
+// BuiltinFragments 内置的配置片段
+func BuiltinFragments() []Ora2pgFragment {
+	fragments := make([]Ora2pgFragment, len(builtinFragments))
+	for i, fragment := range builtinFragments {
+		fragment.Source = FragmentSourceBuiltin
+		fragments[i] = fragment
+	}
+	return fragments
+}
+
+// ResolveOra2pgFragment 按名称查找配置片段：项目级、用户级片段目录中的 <名称>.conf，最后是内置片段
+func ResolveOra2pgFragment(projectDir, name string) (*Ora2pgFragment, error) {
+	name = strings.TrimSpace(name)
+	if !fragmentNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("无效的片段名称 %q，只能包含字母、数字、下划线和连字符", name)
+	}
+	sources := []string{FragmentSourceProject, FragmentSourceUser}
+	for i, dir := range FragmentSearchDirs(projectDir) {
+		path := filepath.Join(dir, name+fragmentExt)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取配置片段 %s 失败: %v", path, err)
+		}
+		content := string(data)
+		return &Ora2pgFragment{
+			Name:        name,
+			Description: fragmentDescription(content),
+			Source:      sources[i],
+			Path:        path,
+			Content:     content,
+		}, nil
+	}
+	for _, fragment := range BuiltinFragments() {
+		if fragment.Name == name {
+			return &fragment, nil
+		}
+	}
+	return nil, fmt.Errorf("未找到配置片段 %s（内置片段: %s）", name, strings.Join(builtinFragmentNames(), ", "))
+}
+
+// ListOra2pgFragments 可用的配置片段，同名的片段只列出生效的一个，按名称排序
+func ListOra2pgFragments(projectDir string) ([]Ora2pgFragment, error) {
+	found := make(map[string]bool)
+	var fragments []Ora2pgFragment
+	add := func(name string) error {
+		if found[name] {
+			return nil
+		}
+		fragment, err := ResolveOra2pgFragment(projectDir, name)
+		if err != nil {
+			return err
+		}
+		found[name] = true
+		fragments = append(fragments, *fragment)
+		return nil
+	}
+
+	for _, dir := range FragmentSearchDirs(projectDir) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := strings.TrimSuffix(entry.Name(), fragmentExt)
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), fragmentExt) || !fragmentNamePattern.MatchString(name) {
+				continue
+			}
+			if err := add(name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, name := range builtinFragmentNames() {
+		if err := add(name); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(fragments, func(i, j int) bool { return fragments[i].Name < fragments[j].Name })
+	return fragments, nil
+}
+
+// builtinFragmentNames 内置片段的名称
+func builtinFragmentNames() []string {
+	names := make([]string, 0, len(builtinFragments))
+	for _, fragment := range builtinFragments {
+		names = append(names, fragment.Name)
+	}
+	return names
+}
+
+// fragmentDescription 片段开头注释中的说明
+func fragmentDescription(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			return ""
+		}
+		comment := strings.TrimSpace(strings.TrimPrefix(line, "#"))
+		for _, prefix := range fragmentDescriptionPrefixes {
+			if len(comment) >= len(prefix) && strings.EqualFold(comment[:len(prefix)], prefix) {
+				return strings.TrimSpace(comment[len(prefix):])
+			}
+		}
+	}
+	return ""
+}
+
+// parseOra2pgDirective 解析一行ora2pg配置，注释和空行返回false；兼容 NAME value 和 NAME=value 写法
+func parseOra2pgDirective(line string) (string, string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+	end := strings.IndexAny(line, " \t=")
+	if end < 0 {
+		return strings.ToUpper(line), "", true
+	}
+	name := strings.ToUpper(line[:end])
+	value := strings.TrimSpace(line[end:])
+	if line[end] == '=' {
+		value = strings.TrimSpace(line[end+1:])
+	}
+	return name, value, true
+}
+
+// Directives 解析片段中的指令；同一片段中重复设置非列表指令、设置由项目配置生成的指令时返回错误
+func (f *Ora2pgFragment) Directives() ([]FragmentDirective, error) {
+	var directives []FragmentDirective
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(f.Content))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		name, value, ok := parseOra2pgDirective(scanner.Text())
+		if !ok {
+			continue
+		}
+		if !directiveNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("片段 %s 第 %d 行不是有效的ora2pg指令: %s", f.Name, lineNo, strings.TrimSpace(scanner.Text()))
+		}
+		if field, managed := fragmentManagedDirectives[name]; managed {
+			return nil, fmt.Errorf("片段 %s 第 %d 行: 指令 %s 由 %s 生成，不能在片段中设置", f.Name, lineNo, name, field)
+		}
+		if _, list := fragmentListDirectives[name]; seen[name] && !list {
+			return nil, fmt.Errorf("片段 %s 第 %d 行: 重复设置指令 %s", f.Name, lineNo, name)
+		}
+		seen[name] = true
+		directives = append(directives, FragmentDirective{Name: name, Value: value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取片段 %s 失败: %v", f.Name, err)
+	}
+	return directives, nil
+}
+
+// Render 用模板数据渲染片段中的模板变量
+func (f *Ora2pgFragment) Render(data interface{}) (*Ora2pgFragment, error) {
+	tmpl, err := template.New(f.Name).Option("missingkey=error").Parse(f.Content)
+	if err != nil {
+		return nil, fmt.Errorf("解析片段 %s 失败: %v", f.Name, err)
+	}
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, data); err != nil {
+		return nil, fmt.Errorf("渲染片段 %s 失败: %v", f.Name, err)
+	}
+	rendered := *f
+	rendered.Content = builder.String()
+	return &rendered, nil
+}
+
+// MergeOra2pgFragments 把配置片段按顺序合并到ora2pg配置
+//
+// 片段中的指令覆盖主模板和前面片段中的同名指令，列表指令（EXCLUDE、MODIFY_TYPE 等）的值合并去重；
+// 主模板中被覆盖的行改为注释，片段设置的指令按生效的片段分组追加在文件末尾。
+func MergeOra2pgFragments(base string, fragments []*Ora2pgFragment) (string, []MergedDirective, error) {
+	baseValues := make(map[string]string)
+	for _, line := range strings.Split(base, "\n") {
+		if name, value, ok := parseOra2pgDirective(line); ok {
+			baseValues[name] = value
+		}
+	}
+
+	merged := make(map[string]*MergedDirective)
+	var order []string
+	for _, fragment := range fragments {
+		directives, err := fragment.Directives()
+		if err != nil {
+			return "", nil, err
+		}
+		for _, directive := range directives {
+			separator, list := fragmentListDirectives[directive.Name]
+			current, exists := merged[directive.Name]
+			if !exists {
+				current = &MergedDirective{Name: directive.Name}
+				if value, inBase := baseValues[directive.Name]; inBase {
+					if list {
+						current.Value = value
+					} else {
+						current.Overridden = append(current.Overridden, "模板")
+					}
+				}
+				merged[directive.Name] = current
+				order = append(order, directive.Name)
+			} else if !list && current.Fragment != fragment.Name {
+				current.Overridden = append(current.Overridden, current.Fragment)
+			}
+			if list {
+				current.Value = mergeDirectiveList(current.Value, directive.Value, separator)
+			} else {
+				current.Value = directive.Value
+			}
+			current.Fragment = fragment.Name
+		}
+	}
+	if len(merged) == 0 {
+		return base, nil, nil
+	}
+
+	var builder strings.Builder
+	lines := strings.Split(strings.TrimRight(base, "\n"), "\n")
+	for _, line := range lines {
+		if name, _, ok := parseOra2pgDirective(line); ok && merged[name] != nil {
+			builder.WriteString(fmt.Sprintf("# %s 由配置片段 %s 设置，见文件末尾\n", name, merged[name].Fragment))
+			continue
+		}
+		builder.WriteString(line)
+		builder.WriteString("\n")
+	}
+
+	names := make([]string, 0, len(fragments))
+	for _, fragment := range fragments {
+		names = append(names, fragment.Name)
+	}
+	builder.WriteString("\n#------------------------------------------------------------------------------\n")
+	builder.WriteString(fmt.Sprintf("# 配置片段（migration.fragments: %s）\n", strings.Join(names, ", ")))
+	builder.WriteString("#------------------------------------------------------------------------------\n")
+
+	result := make([]MergedDirective, 0, len(order))
+	for _, fragment := range fragments {
+		header := false
+		for _, name := range order {
+			directive := merged[name]
+			if directive.Fragment != fragment.Name {
+				continue
+			}
+			if !header {
+				header = true
+				builder.WriteString("\n# " + fragment.Name)
+				if fragment.Description != "" {
+					builder.WriteString(": " + fragment.Description)
+				}
+				builder.WriteString("\n")
+			}
+			builder.WriteString(strings.TrimSpace(directive.Name+" "+directive.Value) + "\n")
+			result = append(result, *directive)
+		}
+	}
+	return builder.String(), result, nil
+}
+
+// mergeDirectiveList 合并列表指令的值并去重，保持先后顺序
+func mergeDirectiveList(current, value, separator string) string {
+	split := func(s string) []string {
+		if separator == " " {
+			return strings.Fields(s)
+		}
+		var items []string
+		for _, item := range strings.Split(s, separator) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+	seen := make(map[string]bool)
+	var items []string
+	for _, item := range append(split(current), split(value)...) {
+		if !seen[item] {
+			seen[item] = true
+			items = append(items, item)
+		}
+	}
+	return strings.Join(items, separator)
+}
+
+// LoadFragments 按配置顺序加载 migration.fragments 中的片段
+func (m *MigrationConfig) LoadFragments(projectDir string) ([]*Ora2pgFragment, error) {
+	fragments := make([]*Ora2pgFragment, 0, len(m.Fragments))
+	for _, name := range m.Fragments {
+		fragment, err := ResolveOra2pgFragment(projectDir, name)
+		if err != nil {
+			return nil, err
+		}
+		fragments = append(fragments, fragment)
+	}
+	return fragments, nil
+}
+
+// validateFragments 验证配置片段：片段存在且格式正确，不重复引用，并提示覆盖了 migration.options 的片段
+func (v *Validator) validateFragments(migration *MigrationConfig, result *ValidationResult) {
+	seen := make(map[string]bool)
+	options := make(map[string]bool, len(migration.Options))
+	for name := range migration.Options {
+		options[strings.ToUpper(strings.TrimSpace(name))] = true
+	}
+
+	for i, name := range migration.Fragments {
+		field := fmt.Sprintf("migration.fragments[%d]", i)
+		name = strings.TrimSpace(name)
+		if seen[name] {
+			result.AddError(field, fmt.Sprintf("重复引用配置片段 %s", name))
+			continue
+		}
+		seen[name] = true
+
+		fragment, err := ResolveOra2pgFragment(".", name)
+		if err != nil {
+			result.AddError(field, err.Error())
+			continue
+		}
+		if _, err := template.New(fragment.Name).Parse(fragment.Content); err != nil {
+			result.AddError(field, fmt.Sprintf("片段 %s 的模板语法错误: %v", fragment.Name, err))
+			continue
+		}
+		directives, err := fragment.Directives()
+		if err != nil {
+			result.AddError(field, err.Error())
+			continue
+		}
+		for _, directive := range directives {
+			if options[directive.Name] {
+				result.AddWarning(field, fmt.Sprintf("片段 %s 覆盖了 migration.options 中的 %s", fragment.Name, directive.Name),
+					"确认以片段为准，或从 migration.options 中去掉该开关")
+			}
+		}
+	}
+}
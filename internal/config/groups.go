@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GroupNames 配置的模块名，按名称排序
+func (m *MigrationConfig) GroupNames() []string {
+	names := make([]string, 0, len(m.Groups))
+	for name := range m.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ForGroup 只迁移指定模块对象的迁移配置
+//
+// 模块的对象替换 allow_tables 和 allow_patterns，exclude_patterns 仍然生效。
+func (m *MigrationConfig) ForGroup(name string) (MigrationConfig, error) {
+	objects, exists := m.Groups[name]
+	if !exists {
+		return MigrationConfig{}, fmt.Errorf("没有名为 %s 的模块，已配置的模块: %s", name, strings.Join(m.GroupNames(), ", "))
+	}
+	scoped := *m
+	scoped.AllowTables = append([]string(nil), objects...)
+	scoped.AllowPatterns = nil
+	return scoped, nil
+}
+
+// GroupPlan 按模块间的依赖确定执行顺序，依赖的模块排在前面
+//
+// withDependencies 为 true 时把依赖的模块（含间接依赖）一并加入计划；否则只排列 names 中的模块，
+// 并返回计划中模块直接依赖但不在计划中的模块，由调用方确认这些模块已经迁移。
+func (m *MigrationConfig) GroupPlan(names []string, withDependencies bool) (plan []string, external []string, err error) {
+	requested := make(map[string]bool, len(names))
+	for _, name := range names {
+		if _, exists := m.Groups[name]; !exists {
+			return nil, nil, fmt.Errorf("没有名为 %s 的模块，已配置的模块: %s", name, strings.Join(m.GroupNames(), ", "))
+		}
+		requested[name] = true
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("模块依赖存在循环: %s -> %s", strings.Join(path, " -> "), name)
+		}
+		if _, exists := m.Groups[name]; !exists {
+			return fmt.Errorf("模块 %s 依赖的模块 %s 不存在", path[len(path)-1], name)
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dependency := range m.GroupDependencies[name] {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		if withDependencies || requested[name] {
+			plan = append(plan, name)
+		}
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	planned := make(map[string]bool, len(plan))
+	for _, name := range plan {
+		planned[name] = true
+	}
+	seen := make(map[string]bool)
+	for _, name := range plan {
+		for _, dependency := range m.GroupDependencies[name] {
+			if !planned[dependency] && !seen[dependency] {
+				seen[dependency] = true
+				external = append(external, dependency)
+			}
+		}
+	}
+	return plan, external, nil
+}
+
+// validateGroups 验证模块划分和模块间的依赖
+func (v *Validator) validateGroups(migration *MigrationConfig, result *ValidationResult) {
+	owners := make(map[string]string)
+	for _, name := range migration.GroupNames() {
+		field := fmt.Sprintf("migration.groups.%s", name)
+		if strings.TrimSpace(name) == "" || strings.ContainsAny(name, `/\`) {
+			result.AddError("migration.groups", fmt.Sprintf("无效的模块名 %q，不能为空或包含路径分隔符", name))
+			continue
+		}
+		objects := migration.Groups[name]
+		if len(objects) == 0 {
+			result.AddError(field, "模块中至少需要一个对象")
+		}
+		seen := make(map[string]bool, len(objects))
+		for i, object := range objects {
+			if !allowTablePattern.MatchString(object) {
+				result.AddError(fmt.Sprintf("%s[%d]", field, i), fmt.Sprintf("无效的对象名 %q，只能包含字母、数字、_、$、#，可用 模式.名称 指定其他模式的对象", object))
+				continue
+			}
+			key := strings.ToUpper(object)
+			if seen[key] {
+				result.AddError(fmt.Sprintf("%s[%d]", field, i), fmt.Sprintf("对象 %s 重复", object))
+				continue
+			}
+			seen[key] = true
+			if owner, exists := owners[key]; exists {
+				result.AddWarning(fmt.Sprintf("%s[%d]", field, i), fmt.Sprintf("对象 %s 同时属于模块 %s 和 %s，会被迁移两次", object, owner, name),
+					"每个对象只放在一个模块中")
+				continue
+			}
+			owners[key] = name
+		}
+	}
+
+	names := make([]string, 0, len(migration.GroupDependencies))
+	for name := range migration.GroupDependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	valid := true
+	for _, name := range names {
+		field := fmt.Sprintf("migration.group_dependencies.%s", name)
+		if _, exists := migration.Groups[name]; !exists {
+			result.AddError(field, fmt.Sprintf("模块 %s 不存在", name))
+			valid = false
+			continue
+		}
+		for _, dependency := range migration.GroupDependencies[name] {
+			if dependency == name {
+				result.AddError(field, "模块不能依赖自身")
+				valid = false
+			} else if _, exists := migration.Groups[dependency]; !exists {
+				result.AddError(field, fmt.Sprintf("依赖的模块 %s 不存在", dependency))
+				valid = false
+			}
+		}
+	}
+	if valid {
+		if _, _, err := migration.GroupPlan(migration.GroupNames(), true); err != nil {
+			result.AddError("migration.group_dependencies", err.Error())
+		}
+	}
+}
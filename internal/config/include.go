@@ -0,0 +1,258 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// includeKey 配置文件中引用公共配置的指令
+const includeKey = "include"
+
+// includedConfig 递归合并 include 后的配置
+type includedConfig struct {
+	// merged 本文件覆盖 include 文件后的完整配置
+	merged map[string]interface{}
+	// base 仅由 include 文件合并出的配置，保存时只写入与其不同的字段
+	base map[string]interface{}
+	// includes 本文件中原样书写的 include 路径
+	includes []string
+}
+
+// loadIncludedConfig 读取配置文件并按顺序合并 include 的文件
+//
+// include 路径相对于书写它的文件所在目录；多个 include 按顺序合并，后面的覆盖前面的，本文件覆盖所有 include。
+// 映射逐层深合并，列表和标量整体替换。被 include 的文件可以继续 include，出现循环时返回错误。
+func loadIncludedConfig(path string) (*includedConfig, error) {
+	raw, err := readConfigMap(path)
+	if err != nil {
+		return nil, err
+	}
+	includes, err := parseIncludes(path, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	absPath, _ := filepath.Abs(path)
+	base := map[string]interface{}{}
+	for _, include := range includes {
+		included, err := resolveInclude(resolveIncludePath(path, include), []string{absPath})
+		if err != nil {
+			return nil, err
+		}
+		base = mergeYAMLMaps(base, included)
+	}
+	return &includedConfig{
+		merged:   mergeYAMLMaps(base, raw),
+		base:     base,
+		includes: includes,
+	}, nil
+}
+
+// resolveInclude 递归加载被 include 的文件，stack 为当前的引用链，用于检测循环
+func resolveInclude(path string, stack []string) (map[string]interface{}, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+	for i, parent := range stack {
+		if parent == absPath {
+			chain := append(append([]string{}, stack[i:]...), absPath)
+			return nil, utils.NewError(utils.ErrorTypeConfig, "CONFIG_INCLUDE_CYCLE").
+				Message("配置文件存在循环 include").
+				Details(strings.Join(chain, " -> ")).
+				Suggestion("删除引用链中任意一处 include 以打破循环").
+				Build()
+		}
+	}
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, utils.NewError(utils.ErrorTypeConfig, "CONFIG_INCLUDE_NOT_FOUND").
+			Message(fmt.Sprintf("include 的配置文件不存在: %s", path)).
+			Details(fmt.Sprintf("引用自: %s", stack[len(stack)-1])).
+			Suggestion("include 的相对路径相对于书写它的配置文件所在目录").
+			Build()
+	}
+
+	raw, err := readConfigMap(path)
+	if err != nil {
+		return nil, err
+	}
+	includes, err := parseIncludes(path, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := map[string]interface{}{}
+	for _, include := range includes {
+		included, err := resolveInclude(resolveIncludePath(path, include), append(stack, absPath))
+		if err != nil {
+			return nil, err
+		}
+		merged = mergeYAMLMaps(merged, included)
+	}
+	return mergeYAMLMaps(merged, raw), nil
+}
+
+// readConfigMap 按扩展名读取YAML或JSON配置文件为映射，空文件返回空映射
+func readConfigMap(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+	return unmarshalConfigMap(path, data)
+}
+
+// parseIncludes 取出并删除 include 指令，支持单个路径或路径列表
+func parseIncludes(path string, values map[string]interface{}) ([]string, error) {
+	value, ok := values[includeKey]
+	if !ok {
+		return nil, nil
+	}
+	delete(values, includeKey)
+
+	invalid := fmt.Errorf("配置文件 %s 的 include 必须是路径或路径列表", path)
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []interface{}:
+		includes := make([]string, 0, len(v))
+		for _, item := range v {
+			include, ok := item.(string)
+			if !ok || strings.TrimSpace(include) == "" {
+				return nil, invalid
+			}
+			includes = append(includes, include)
+		}
+		return includes, nil
+	default:
+		return nil, invalid
+	}
+}
+
+// resolveIncludePath 相对路径按书写它的文件所在目录解析
+func resolveIncludePath(from, include string) string {
+	include = os.ExpandEnv(strings.TrimSpace(include))
+	if filepath.IsAbs(include) {
+		return include
+	}
+	return filepath.Join(filepath.Dir(from), include)
+}
+
+// mergeYAMLMaps 深合并两个映射，override 中的值优先；映射递归合并，其他类型整体替换
+func mergeYAMLMaps(base, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(override))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range override {
+		baseMap, baseIsMap := merged[key].(map[string]interface{})
+		overrideMap, overrideIsMap := value.(map[string]interface{})
+		if baseIsMap && overrideIsMap {
+			merged[key] = mergeYAMLMaps(baseMap, overrideMap)
+		} else {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// pruneIncludedFields 删除映射节点中与 base 相同的字段，保留原有字段顺序；base 中有而节点中没有的键以 null 覆盖
+func pruneIncludedFields(node *yaml.Node, base map[string]interface{}) error {
+	present := make(map[string]bool, len(node.Content)/2)
+	kept := make([]*yaml.Node, 0, len(node.Content))
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		present[key.Value] = true
+
+		baseValue, exists := base[key.Value]
+		if !exists {
+			kept = append(kept, key, value)
+			continue
+		}
+		if baseMap, ok := baseValue.(map[string]interface{}); ok && value.Kind == yaml.MappingNode {
+			if err := pruneIncludedFields(value, baseMap); err != nil {
+				return err
+			}
+			if len(value.Content) > 0 {
+				kept = append(kept, key, value)
+			}
+			continue
+		}
+
+		var decoded interface{}
+		if err := value.Decode(&decoded); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(baseValue, decoded) {
+			kept = append(kept, key, value)
+		}
+	}
+
+	for _, key := range sortedKeys(base) {
+		if !present[key] {
+			kept = append(kept,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"})
+		}
+	}
+	node.Content = kept
+	return nil
+}
+
+// sortedKeys 按名称排序的映射键
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// marshalWithIncludes 序列化配置，只保留与 include 基础配置不同的字段，并把 include 指令写在最前面
+//
+// JSON格式的字段按名称排序输出。
+func marshalWithIncludes(path string, cfg *ProjectConfig, included *includedConfig) ([]byte, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var document yaml.Node
+	if err := document.Encode(cfg); err != nil {
+		return nil, err
+	}
+	if err := pruneIncludedFields(&document, included.base); err != nil {
+		return nil, err
+	}
+
+	var includeNode yaml.Node
+	if err := includeNode.Encode(included.includes); err != nil {
+		return nil, err
+	}
+	document.Content = append([]*yaml.Node{{Kind: yaml.ScalarNode, Value: includeKey}, &includeNode}, document.Content...)
+	if format == FormatYAML {
+		return yaml.Marshal(&document)
+	}
+
+	values := map[string]interface{}{}
+	if err := document.Decode(&values); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
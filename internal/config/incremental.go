@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 增量列的类型
+const (
+	IncrementalTimestamp = "timestamp" // DATE 或 TIMESTAMP 列，如 UPDATED_AT
+	IncrementalSequence  = "sequence"  // 单调递增的数值列，如序列生成的 ID
+)
+
+// 增量同步时未配置增量列（或增量列不可用）的表的处理方式
+const (
+	IncrementalOthersSkip = "skip" // 不导出
+	IncrementalOthersFull = "full" // 重新全量导出
+)
+
+// oracleNamePattern 不带Schema前缀的Oracle表名或列名
+var oracleNamePattern = regexp.MustCompile(`^[A-Za-z][\w$#]*$`)
+
+// IncrementalTableConfig 一张表的增量列
+type IncrementalTableConfig struct {
+	Name   string `yaml:"name" json:"name"`
+	Column string `yaml:"column" json:"column"`
+	// Type 增量列类型（timestamp、sequence），默认 timestamp
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+}
+
+// IncrementalConfig 增量同步配置
+//
+// 数据迁移时记录各表增量列的最大值作为水位，之后的增量同步只导出大于上次水位的行。
+type IncrementalConfig struct {
+	Tables []IncrementalTableConfig `yaml:"tables,omitempty" json:"tables,omitempty"`
+	// Others 增量同步时其他表的处理方式（skip、full），默认 skip
+	Others string `yaml:"others,omitempty" json:"others,omitempty"`
+}
+
+// Enabled 是否配置了增量同步
+func (c *IncrementalConfig) Enabled() bool {
+	return len(c.Tables) > 0
+}
+
+// OthersFull 增量同步时是否全量导出未配置增量列的表
+func (c *IncrementalConfig) OthersFull() bool {
+	return strings.EqualFold(strings.TrimSpace(c.Others), IncrementalOthersFull)
+}
+
+// TableName 大写的表名
+func (t *IncrementalTableConfig) TableName() string {
+	return strings.ToUpper(strings.TrimSpace(t.Name))
+}
+
+// ColumnName 大写的增量列名
+func (t *IncrementalTableConfig) ColumnName() string {
+	return strings.ToUpper(strings.TrimSpace(t.Column))
+}
+
+// ColumnType 小写的增量列类型，未配置时为 timestamp
+func (t *IncrementalTableConfig) ColumnType() string {
+	if kind := strings.ToLower(strings.TrimSpace(t.Type)); kind != "" {
+		return kind
+	}
+	return IncrementalTimestamp
+}
+
+// validateIncremental 验证增量同步配置
+func (v *Validator) validateIncremental(migration *MigrationConfig, result *ValidationResult) {
+	incremental := &migration.Incremental
+	switch strings.ToLower(strings.TrimSpace(incremental.Others)) {
+	case "", IncrementalOthersSkip, IncrementalOthersFull:
+	default:
+		result.AddError("migration.incremental.others", "其他表的处理方式只支持 skip、full")
+	}
+
+	seen := make(map[string]bool)
+	for i, table := range incremental.Tables {
+		field := fmt.Sprintf("migration.incremental.tables[%d]", i)
+		name := table.TableName()
+		switch {
+		case name == "":
+			result.AddError(field, "增量表名称不能为空")
+		case !oracleNamePattern.MatchString(name):
+			result.AddError(field, fmt.Sprintf("无效的表名: %s（不需要Schema前缀）", table.Name))
+		case seen[name]:
+			result.AddError(field, fmt.Sprintf("增量表 %s 重复配置", table.Name))
+		}
+		seen[name] = true
+
+		if !oracleNamePattern.MatchString(table.ColumnName()) {
+			result.AddError(field, fmt.Sprintf("表 %s 的增量列无效: %q", table.Name, table.Column))
+		}
+		switch table.ColumnType() {
+		case IncrementalTimestamp, IncrementalSequence:
+		default:
+			result.AddError(field, fmt.Sprintf("表 %s 的增量列类型只支持 timestamp、sequence", table.Name))
+		}
+	}
+}
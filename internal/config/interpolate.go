@@ -0,0 +1,316 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// interpolationNow 插值使用的当前时间，测试时可替换
+var interpolationNow = time.Now
+
+// templateFuncs 配置模板中可用的函数
+var templateFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// TemplateData 配置值模板插值时可引用的变量
+//
+// 配置字段使用Go结构中的字段名引用，如 {{.Project.Name}}、{{.Oracle.Schema}}；
+// 内置变量为 {{.Date}}（20060102）、{{.Time}}（150405）、{{.Now}}（可调用 Format）、{{.Host}}（主机名）和 {{.Env.NAME}}（环境变量）。
+type TemplateData struct {
+	*ProjectConfig
+	Date string
+	Time string
+	Now  time.Time
+	Host string
+	Env  map[string]string
+}
+
+// Interpolation 加载配置时展开的模板字段
+type Interpolation struct {
+	Path string // 配置路径，如 migration.output_dir
+
+	index    []int
+	raw      reflect.Value // 配置文件中的模板，保存配置时写回
+	expanded reflect.Value
+}
+
+// Raw 配置文件中的模板文本，列表中的各项用逗号连接
+func (i *Interpolation) Raw() string {
+	return displayTemplateValue(i.raw)
+}
+
+// Expanded 展开后的值，列表中的各项用逗号连接
+func (i *Interpolation) Expanded() string {
+	return displayTemplateValue(i.expanded)
+}
+
+// displayTemplateValue 字符串或字符串列表的显示文本
+func displayTemplateValue(value reflect.Value) string {
+	if items, ok := value.Interface().([]string); ok {
+		return strings.Join(items, ", ")
+	}
+	return value.String()
+}
+
+// templateField 待展开的模板字段
+type templateField struct {
+	EnvOverrideField
+	target reflect.Value
+	state  int // 0 未处理，1 展开中，2 已展开
+}
+
+// interpolate 展开配置值中的 {{...}} 模板，在 ${VAR} 替换和环境变量覆盖之后、校验之前执行
+//
+// 只处理字符串和字符串列表字段，密码等敏感字段和标记 interpolate:"-" 的运行时模板字段不展开；引用的字段本身也是模板时先展开被引用的字段，
+// 出现循环引用时返回错误。保存配置时写回模板原文。
+func (m *Manager) interpolate() error {
+	m.interpolations = nil
+	secrets := make(map[uintptr]bool)
+	for _, field := range secretFields(m.config) {
+		secrets[reflect.ValueOf(field).Pointer()] = true
+	}
+
+	root := reflect.ValueOf(m.config).Elem()
+	pending := make(map[string]*templateField)
+	var fields []*templateField
+	for _, field := range EnvOverrideFields() {
+		target := root.FieldByIndex(field.index)
+		if field.noInterpolate || secrets[target.Addr().Pointer()] || !hasTemplate(target) {
+			continue
+		}
+		tf := &templateField{EnvOverrideField: field, target: target}
+		pending[fmt.Sprint(field.index)] = tf
+		fields = append(fields, tf)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	now := interpolationNow()
+	host, _ := os.Hostname()
+	env := make(map[string]string)
+	for _, item := range os.Environ() {
+		if name, value, ok := strings.Cut(item, "="); ok {
+			env[name] = value
+		}
+	}
+	data := &TemplateData{
+		ProjectConfig: m.config,
+		Date:          now.Format("20060102"),
+		Time:          now.Format("150405"),
+		Now:           now,
+		Host:          host,
+		Env:           env,
+	}
+
+	for _, field := range fields {
+		if err := m.expandTemplateField(field, pending, data, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandTemplateField 先展开字段引用的其他模板字段，再展开字段本身；chain 为正在展开的引用链
+func (m *Manager) expandTemplateField(field *templateField, pending map[string]*templateField, data *TemplateData, chain []string) error {
+	switch field.state {
+	case 2:
+		return nil
+	case 1:
+		start := 0
+		for i, path := range chain {
+			if path == field.Path {
+				start = i
+				break
+			}
+		}
+		return utils.NewError(utils.ErrorTypeConfig, "CONFIG_TEMPLATE_CYCLE").
+			Message("配置模板存在循环引用").
+			Details(strings.Join(append(chain[start:], field.Path), " → ")).
+			Suggestion("修改其中一个字段，不再引用链上的其他字段").
+			Build()
+	}
+	field.state = 1
+	chain = append(chain, field.Path)
+
+	raw := reflect.New(field.target.Type()).Elem()
+	raw.Set(field.target)
+	texts := templateTexts(raw)
+	templates := make([]*template.Template, len(texts))
+	for i, text := range texts {
+		tmpl, err := template.New(field.Path).Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+		if err != nil {
+			return templateError(field.Path, text, err)
+		}
+		templates[i] = tmpl
+		for _, reference := range templateReferences(tmpl.Tree.Root) {
+			index, ok := configFieldIndex(reference)
+			if !ok {
+				continue
+			}
+			if dependency := pending[fmt.Sprint(index)]; dependency != nil {
+				if err := m.expandTemplateField(dependency, pending, data, chain); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	expanded := make([]string, len(texts))
+	for i, tmpl := range templates {
+		var output bytes.Buffer
+		if err := tmpl.Execute(&output, data); err != nil {
+			return templateError(field.Path, texts[i], err)
+		}
+		expanded[i] = output.String()
+	}
+	if field.target.Kind() == reflect.String {
+		field.target.SetString(expanded[0])
+	} else {
+		field.target.Set(reflect.ValueOf(expanded))
+	}
+	field.state = 2
+
+	value := reflect.New(field.target.Type()).Elem()
+	value.Set(field.target)
+	m.interpolations = append(m.interpolations, Interpolation{
+		Path:     field.Path,
+		index:    field.index,
+		raw:      raw,
+		expanded: value,
+	})
+	return nil
+}
+
+// hasTemplate 字符串或字符串列表中是否包含 {{
+func hasTemplate(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String:
+		return strings.Contains(value.String(), "{{")
+	case reflect.Slice:
+		if items, ok := value.Interface().([]string); ok {
+			for _, item := range items {
+				if strings.Contains(item, "{{") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// templateTexts 字段中的模板文本，字符串列表每项一个
+func templateTexts(value reflect.Value) []string {
+	if value.Kind() == reflect.String {
+		return []string{value.String()}
+	}
+	return append([]string(nil), value.Interface().([]string)...)
+}
+
+// templateReferences 模板中引用的字段路径，如 [Project Name]
+func templateReferences(node parse.Node) [][]string {
+	var references [][]string
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n != nil {
+				for _, child := range n.Nodes {
+					walk(child)
+				}
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n != nil {
+				for _, command := range n.Cmds {
+					walk(command)
+				}
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.FieldNode:
+			references = append(references, n.Ident)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		}
+	}
+	walk(node)
+	return references
+}
+
+// configFieldIndex 按Go字段名查找配置字段的索引，不是配置字段（如 Date、Env）时返回false
+func configFieldIndex(idents []string) ([]int, bool) {
+	fieldType := reflect.TypeOf(ProjectConfig{})
+	var index []int
+	for _, ident := range idents {
+		if fieldType.Kind() != reflect.Struct {
+			break
+		}
+		field, ok := fieldType.FieldByName(ident)
+		if !ok {
+			return nil, false
+		}
+		index = append(index, field.Index...)
+		fieldType = field.Type
+	}
+	return index, len(index) > 0
+}
+
+// templateError 构建模板解析或展开失败的错误
+func templateError(path, text string, err error) error {
+	return utils.NewError(utils.ErrorTypeConfig, "CONFIG_TEMPLATE_INVALID").
+		Message(fmt.Sprintf("配置项 %s 的模板无效", path)).
+		Details(fmt.Sprintf("%q: %v", text, err)).
+		Cause(err).
+		Suggestion("配置字段使用Go字段名引用（如 {{.Project.Name}}），内置变量为 .Date、.Time、.Now、.Host、.Env.NAME").
+		Build()
+}
+
+// withoutInterpolation 保存配置前把展开的字段恢复为模板原文，返回重新展开的函数
+//
+// 加载后又被修改过的字段不再等于展开值，按修改后的值保存。
+func (m *Manager) withoutInterpolation() func() {
+	root := reflect.ValueOf(m.config).Elem()
+	var restored []Interpolation
+	for _, interpolation := range m.interpolations {
+		target := root.FieldByIndex(interpolation.index)
+		if !reflect.DeepEqual(target.Interface(), interpolation.expanded.Interface()) {
+			continue
+		}
+		target.Set(interpolation.raw)
+		restored = append(restored, interpolation)
+	}
+	return func() {
+		for _, interpolation := range restored {
+			root.FieldByIndex(interpolation.index).Set(interpolation.expanded)
+		}
+	}
+}
+
+// Interpolations 加载配置时展开的模板字段，按展开顺序排列
+func (m *Manager) Interpolations() []Interpolation {
+	return m.interpolations
+}
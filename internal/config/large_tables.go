@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxTableShards 单张表允许的最大分片数
+const MaxTableShards = 64
+
+// SplitColumnRowID 无主键表按ROWID范围分片时使用的分片列
+const SplitColumnRowID = "ROWID"
+
+// LargeTableConfig 大表分片并行导出配置
+type LargeTableConfig struct {
+	Name   string `yaml:"name" json:"name"`
+	Shards int    `yaml:"shards" json:"shards"`
+	// SplitColumn 分片使用的数值列，为空时由ora2pg使用数值主键；无主键表填 ROWID
+	SplitColumn string `yaml:"split_column,omitempty" json:"split_column,omitempty"`
+}
+
+// oracleIdentifierPattern Oracle表名和列名（允许 SCHEMA.TABLE 形式）
+var oracleIdentifierPattern = regexp.MustCompile(`^[A-Za-z][\w$#]*(\.[A-Za-z][\w$#]*)?$`)
+
+// OracleCopies 返回生成 ORACLE_COPIES 的值，即所有大表中最大的分片数，未配置时为1
+//
+// ora2pg的分片数是全局设置，对所有存在数值主键或 DEFINED_PK 的表生效。
+func (m *MigrationConfig) OracleCopies() int {
+	copies := 1
+	for _, table := range m.LargeTables {
+		if table.Shards > copies {
+			copies = table.Shards
+		}
+	}
+	return copies
+}
+
+// DefinedPK 返回 DEFINED_PK 指令的 TABLE:COLUMN 列表，只包含显式指定分片列的表
+func (m *MigrationConfig) DefinedPK() []string {
+	var defined []string
+	for _, table := range m.LargeTables {
+		if column := strings.TrimSpace(table.SplitColumn); column != "" {
+			defined = append(defined, strings.ToUpper(strings.TrimSpace(table.Name))+":"+strings.ToUpper(column))
+		}
+	}
+	return defined
+}
+
+// UsesParallelExport 是否启用了表间或表内并行导出
+func (m *MigrationConfig) UsesParallelExport() bool {
+	return m.OracleCopies() > 1 || m.ParallelTables > 1
+}
+
+// ExportProcesses 估算数据导出的ora2pg进程总数（PARALLEL_TABLES × ORACLE_COPIES × JOBS）
+func (m *MigrationConfig) ExportProcesses() int {
+	tables := m.ParallelTables
+	if tables < 1 {
+		tables = 1
+	}
+	jobs := m.ParallelJobs
+	if jobs < 1 {
+		jobs = 1
+	}
+	return tables * m.OracleCopies() * jobs
+}
+
+// validateLargeTables 验证大表分片配置
+func (v *Validator) validateLargeTables(migration *MigrationConfig, result *ValidationResult) {
+	if migration.ParallelTables < 0 {
+		result.AddError("migration.parallel_tables", "并行导出表数不能为负数")
+	} else if migration.ParallelTables > 32 {
+		result.AddError("migration.parallel_tables", "并行导出表数不建议超过32")
+	}
+
+	seen := make(map[string]bool)
+	for i, table := range migration.LargeTables {
+		field := fmt.Sprintf("migration.large_tables[%d]", i)
+		name := strings.ToUpper(strings.TrimSpace(table.Name))
+		switch {
+		case name == "":
+			result.AddError(field, "大表名称不能为空")
+		case !oracleIdentifierPattern.MatchString(name):
+			result.AddError(field, fmt.Sprintf("无效的表名: %s", table.Name))
+		case seen[name]:
+			result.AddError(field, fmt.Sprintf("大表 %s 重复配置", table.Name))
+		}
+		seen[name] = true
+
+		if table.Shards < 2 || table.Shards > MaxTableShards {
+			result.AddError(field, fmt.Sprintf("表 %s 的分片数必须在 2-%d 之间", table.Name, MaxTableShards))
+		}
+
+		column := strings.TrimSpace(table.SplitColumn)
+		if column != "" && !strings.EqualFold(column, SplitColumnRowID) && !oracleIdentifierPattern.MatchString(column) {
+			result.AddError(field, fmt.Sprintf("无效的分片列: %s", table.SplitColumn))
+		}
+	}
+}
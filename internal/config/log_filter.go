@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ora2pg输出数据行的过滤级别
+const (
+	// LogFilterOff 不过滤，默认值
+	LogFilterOff = "off"
+	// LogFilterMask 数据行替换为占位行，INSERT 的 VALUES 和错误详情中的键值替换为 ***
+	LogFilterMask = "mask"
+	// LogFilterDrop 丢弃数据行，只保留进度和状态信息；包含数据的错误行仍按 mask 脱敏后保留
+	LogFilterDrop = "drop"
+)
+
+// LogFilterConfig ora2pg输出中数据行的过滤配置
+type LogFilterConfig struct {
+	// Level 过滤级别：off、mask、drop，为空表示 off
+	Level string `yaml:"level,omitempty" json:"level,omitempty"`
+	// DataPatterns 额外识别为数据行的正则，匹配的整行按级别替换或丢弃
+	DataPatterns []string `yaml:"data_patterns,omitempty" json:"data_patterns,omitempty"`
+}
+
+// EffectiveLevel 生效的过滤级别，未配置时为 off
+func (c *LogFilterConfig) EffectiveLevel() string {
+	level := strings.ToLower(strings.TrimSpace(c.Level))
+	if level == "" {
+		return LogFilterOff
+	}
+	return level
+}
+
+// Enabled 是否过滤数据行
+func (c *LogFilterConfig) Enabled() bool {
+	return c.EffectiveLevel() != LogFilterOff
+}
+
+// validateLogFilter 验证数据行过滤配置
+func (v *Validator) validateLogFilter(migration *MigrationConfig, result *ValidationResult) {
+	filter := &migration.LogFilter
+	switch filter.EffectiveLevel() {
+	case LogFilterOff, LogFilterMask, LogFilterDrop:
+	default:
+		result.AddError("migration.log_filter.level", fmt.Sprintf("无效的过滤级别 %s，可选值: off、mask、drop", filter.Level))
+	}
+	for i, pattern := range filter.DataPatterns {
+		field := fmt.Sprintf("migration.log_filter.data_patterns[%d]", i)
+		if strings.TrimSpace(pattern) == "" {
+			result.AddError(field, "数据行正则不能为空")
+			continue
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			result.AddError(field, fmt.Sprintf("无效的正则表达式 %s: %v", pattern, err))
+		}
+	}
+	if len(filter.DataPatterns) > 0 && !filter.Enabled() {
+		result.AddWarning("migration.log_filter.data_patterns", "过滤级别为 off，data_patterns 不会生效", "将 migration.log_filter.level 设为 mask 或 drop")
+	}
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// minLogSplitInterval 按时间切割ora2pg日志的最小间隔，避免产生大量碎片文件
+const minLogSplitInterval = time.Minute
+
+// LogSplitConfig ora2pg日志（-l 输出）的分段切割配置，大小和时间任一达到阈值即切换到下一段
+type LogSplitConfig struct {
+	// MaxSizeMB 单个分段的最大大小（MB），0表示不按大小切割
+	MaxSizeMB int `yaml:"max_size_mb,omitempty" json:"max_size_mb,omitempty"`
+	// Interval 单个分段的最长时间，如 30m、1h，为空表示不按时间切割
+	Interval string `yaml:"interval,omitempty" json:"interval,omitempty"`
+}
+
+// Enabled 是否切割ora2pg日志
+func (c *LogSplitConfig) Enabled() bool {
+	return c.MaxSizeMB > 0 || strings.TrimSpace(c.Interval) != ""
+}
+
+// MaxSize 单个分段的最大字节数，0表示不按大小切割
+func (c *LogSplitConfig) MaxSize() int64 {
+	return int64(c.MaxSizeMB) * 1024 * 1024
+}
+
+// SplitInterval 单个分段的最长时间，未配置或无效时返回0
+func (c *LogSplitConfig) SplitInterval() time.Duration {
+	interval, err := time.ParseDuration(strings.TrimSpace(c.Interval))
+	if err != nil {
+		return 0
+	}
+	return interval
+}
+
+// validateLogSplit 验证ora2pg日志切割配置
+func (v *Validator) validateLogSplit(migration *MigrationConfig, result *ValidationResult) {
+	split := &migration.LogSplit
+	if split.MaxSizeMB < 0 {
+		result.AddError("migration.log_split.max_size_mb", "日志分段大小不能为负数")
+	}
+	if interval := strings.TrimSpace(split.Interval); interval != "" {
+		duration, err := time.ParseDuration(interval)
+		if err != nil {
+			result.AddError("migration.log_split.interval", fmt.Sprintf("无效的切割间隔 %s，请使用 30m、1h 等格式", interval))
+		} else if duration < minLogSplitInterval {
+			result.AddError("migration.log_split.interval", fmt.Sprintf("切割间隔不能小于 %v", minLogSplitInterval))
+		}
+	}
+}
@@ -2,14 +2,19 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
-	"gopkg.in/yaml.v3"
+
+	"ora2pg-admin/internal/utils"
 )
 
 // ProjectConfig 项目配置结构
@@ -19,6 +24,14 @@ type ProjectConfig struct {
 	PostgreSQL PostgreConfig  `yaml:"postgresql" json:"postgresql"`
 	Migration  MigrationConfig `yaml:"migration" json:"migration"`
 	OracleClient OracleClientConfig `yaml:"oracle_client" json:"oracle_client"`
+	Notifications NotificationConfig `yaml:"notifications,omitempty" json:"notifications,omitempty"`
+	Metrics      MetricsConfig      `yaml:"metrics,omitempty" json:"metrics,omitempty"`
+	// RunEnvironments 测试和生产环境的迁移策略，迁移时用 --env 选择
+	RunEnvironments RunEnvironmentsConfig `yaml:"run_environments,omitempty" json:"run_environments,omitempty"`
+	// Environment 执行ora2pg时额外设置的环境变量，如 TNS_ADMIN、NLS_DATE_FORMAT，优先于工具的默认值
+	Environment map[string]string `yaml:"environment,omitempty" json:"environment,omitempty"`
+	// Tools 期望的ora2pg、sqlplus、psql版本，检查环境和迁移前校验
+	Tools ToolVersionsConfig `yaml:"tools,omitempty" json:"tools,omitempty"`
 }
 
 // ProjectInfo 项目基本信息
@@ -39,6 +52,34 @@ type OracleConfig struct {
 	Username string `yaml:"username" json:"username"`
 	Password string `yaml:"password" json:"password"`
 	Schema   string `yaml:"schema" json:"schema"`
+	// TestUsername/TestPassword 连接测试使用的只读账号，未配置时使用迁移账号
+	TestUsername string `yaml:"test_username,omitempty" json:"test_username,omitempty"`
+	TestPassword string `yaml:"test_password,omitempty" json:"test_password,omitempty"`
+	// UseTCPS 使用 TCPS（SSL/TLS）加密连接，监听端口通常为 2484
+	UseTCPS bool `yaml:"use_tcps,omitempty" json:"use_tcps,omitempty"`
+	// WalletDir 存放信任证书（及客户端证书）的 Oracle wallet 目录
+	WalletDir string `yaml:"wallet_dir,omitempty" json:"wallet_dir,omitempty"`
+	// TNSAdmin 含 sqlnet.ora 的目录，作为 TNS_ADMIN 环境变量；未配置时使用 wallet 目录
+	TNSAdmin string `yaml:"tns_admin,omitempty" json:"tns_admin,omitempty"`
+	// SSLVerify 服务器证书验证级别：full（校验证书链和DN，默认）、ca（只校验证书链）
+	SSLVerify string `yaml:"ssl_verify,omitempty" json:"ssl_verify,omitempty"`
+	// SSLServerCertDN 期望的服务器证书DN，未配置时按主机名校验
+	SSLServerCertDN string `yaml:"ssl_server_cert_dn,omitempty" json:"ssl_server_cert_dn,omitempty"`
+}
+
+// HasTestAccount 是否配置了独立的测试账号
+func (c *OracleConfig) HasTestAccount() bool {
+	return c.TestUsername != ""
+}
+
+// ForConnectionTest 返回连接测试使用的配置，配置了测试账号时替换凭据
+func (c *OracleConfig) ForConnectionTest() *OracleConfig {
+	testConfig := *c
+	if c.HasTestAccount() {
+		testConfig.Username = c.TestUsername
+		testConfig.Password = c.TestPassword
+	}
+	return &testConfig
 }
 
 // PostgreConfig PostgreSQL数据库配置
@@ -49,6 +90,56 @@ type PostgreConfig struct {
 	Username string `yaml:"username" json:"username"`
 	Password string `yaml:"password" json:"password"`
 	Schema   string `yaml:"schema" json:"schema"`
+	// TestUsername/TestPassword 连接测试使用的只读账号，未配置时使用迁移账号
+	TestUsername string `yaml:"test_username,omitempty" json:"test_username,omitempty"`
+	TestPassword string `yaml:"test_password,omitempty" json:"test_password,omitempty"`
+	// Managed 目标库为云托管数据库（如 AWS RDS、阿里云RDS），迁移账号没有超级用户权限
+	Managed bool `yaml:"managed,omitempty" json:"managed,omitempty"`
+	// Name 目标名称，配置了多个目标时用于区分各目标的输出和结果，主目标默认为 primary
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Targets 同时迁移的其他目标库，未配置的连接项沿用主目标
+	Targets []PostgreConfig `yaml:"targets,omitempty" json:"targets,omitempty"`
+}
+
+// ApplicationNamePrefix 目标库连接的 application_name 前缀
+const ApplicationNamePrefix = "ora2pg-admin"
+
+// ApplicationName 目标库连接的 application_name，带上本次运行ID，便于在 pg_stat_activity 中识别迁移连接
+func ApplicationName() string {
+	return ApplicationNamePrefix + "/" + utils.RunID()
+}
+
+// DBIDSN 构建ora2pg（DBD::Pg）使用的关键字格式DSN
+func (c *PostgreConfig) DBIDSN() string {
+	return fmt.Sprintf("dbi:Pg:dbname=%s;host=%s;port=%d;application_name=%s",
+		c.Database, c.Host, c.Port, ApplicationName())
+}
+
+// ConnectionURI 构建psql使用的URI格式连接串，用户名、密码和参数按URI规则转义
+func (c *PostgreConfig) ConnectionURI() string {
+	uri := url.URL{
+		Scheme:   "postgresql",
+		User:     url.UserPassword(c.Username, c.Password),
+		Host:     net.JoinHostPort(c.Host, strconv.Itoa(c.Port)),
+		Path:     "/" + c.Database,
+		RawQuery: url.Values{"application_name": {ApplicationName()}}.Encode(),
+	}
+	return uri.String()
+}
+
+// HasTestAccount 是否配置了独立的测试账号
+func (c *PostgreConfig) HasTestAccount() bool {
+	return c.TestUsername != ""
+}
+
+// ForConnectionTest 返回连接测试使用的配置，配置了测试账号时替换凭据
+func (c *PostgreConfig) ForConnectionTest() *PostgreConfig {
+	testConfig := *c
+	if c.HasTestAccount() {
+		testConfig.Username = c.TestUsername
+		testConfig.Password = c.TestPassword
+	}
+	return &testConfig
 }
 
 // MigrationConfig 迁移配置
@@ -58,6 +149,60 @@ type MigrationConfig struct {
 	BatchSize    int      `yaml:"batch_size" json:"batch_size"`
 	OutputDir    string   `yaml:"output_dir" json:"output_dir"`
 	LogLevel     string   `yaml:"log_level" json:"log_level"`
+	// PostProcessScript 生成SQL后、导入前执行的后处理脚本，参数为本次生成的SQL文件
+	PostProcessScript string           `yaml:"post_process_script,omitempty" json:"post_process_script,omitempty"`
+	SQLReplacements   []SQLReplacement `yaml:"sql_replacements,omitempty" json:"sql_replacements,omitempty"`
+	// OutputEncoding ora2pg输出SQL的编码（如 gbk），生成后统一转换为UTF-8；为空时只检测
+	OutputEncoding string `yaml:"output_encoding,omitempty" json:"output_encoding,omitempty"`
+	// Options ora2pg布尔开关，可用键见 Ora2pgSwitches，未配置的使用默认值
+	Options map[string]bool `yaml:"options,omitempty" json:"options,omitempty"`
+	// Fragments 按顺序合并到ora2pg配置的配置片段：内置片段名，或 .ora2pg-admin/fragments 下的片段文件名（不含扩展名）
+	Fragments []string `yaml:"fragments,omitempty" json:"fragments,omitempty"`
+	// ParallelTables 同时导出的表数量（PARALLEL_TABLES），0或1表示逐表导出
+	ParallelTables int                `yaml:"parallel_tables,omitempty" json:"parallel_tables,omitempty"`
+	LargeTables    []LargeTableConfig `yaml:"large_tables,omitempty" json:"large_tables,omitempty"`
+	// MaxConnections 迁移期间每个数据库最多占用的连接数，据此降低 PARALLEL_TABLES、ORACLE_COPIES 和 JOBS，0表示不限制
+	MaxConnections int `yaml:"max_connections,omitempty" json:"max_connections,omitempty"`
+	// DeferConstraints 数据迁移前删除目标库外键、禁用用户触发器，完成后重建外键并验证现有数据
+	DeferConstraints bool `yaml:"defer_constraints,omitempty" json:"defer_constraints,omitempty"`
+	// LogSplit 单个迁移类型的ora2pg日志过大或运行过久时切割为多个分段
+	LogSplit LogSplitConfig `yaml:"log_split,omitempty" json:"log_split,omitempty"`
+	// LogFilter 过滤ora2pg输出中的实际数据行（INSERT的VALUES、COPY数据行），避免个人隐私写入日志
+	LogFilter LogFilterConfig `yaml:"log_filter,omitempty" json:"log_filter,omitempty"`
+	// NamingConvention 目标库表名的转换规则（大小写、驼峰转下划线、前缀/后缀）
+	NamingConvention NamingConvention `yaml:"naming_convention,omitempty" json:"naming_convention,omitempty"`
+	// TimeZone 迁移期间ora2pg的Oracle和PostgreSQL会话使用的时区，如 Asia/Shanghai、+08:00，为空时使用各自的默认值
+	TimeZone string `yaml:"time_zone,omitempty" json:"time_zone,omitempty"`
+	// Incremental 全量迁移后按时间戳或序列列增量同步新数据
+	Incremental IncrementalConfig `yaml:"incremental,omitempty" json:"incremental,omitempty"`
+	// Batching 分批迁移数据的规则：未切片的表按规模分桶，大表按时间或ID范围切片
+	Batching BatchingConfig `yaml:"batching,omitempty" json:"batching,omitempty"`
+	// AllowTables 只迁移这些表（ALLOW），其他模式的表写作 模式.表名，为空时迁移模式下全部表
+	AllowTables []string `yaml:"allow_tables,omitempty" json:"allow_tables,omitempty"`
+	// AllowPatterns 按正则表达式（不区分大小写）选择迁移的表，迁移前从源库列出表后与 allow_tables 合并生成 ALLOW
+	AllowPatterns []string `yaml:"allow_patterns,omitempty" json:"allow_patterns,omitempty"`
+	// ExcludePatterns 按正则表达式排除的表，优先于 allow_tables 和 allow_patterns
+	ExcludePatterns []string `yaml:"exclude_patterns,omitempty" json:"exclude_patterns,omitempty"`
+	// ProgressRules 自定义的ora2pg输出解析规则，补充内置规则无法识别的输出格式
+	ProgressRules []ProgressRuleConfig `yaml:"progress_rules,omitempty" json:"progress_rules,omitempty"`
+	// Scripts 迁移前后在源库或目标库执行的SQL脚本，默认为 scripts/pre、scripts/post
+	Scripts MigrationScriptsConfig `yaml:"scripts,omitempty" json:"scripts,omitempty"`
+	// Groups 按业务模块划分的迁移对象，模块名 → 对象名（写法同 allow_tables），用于 '迁移 模块' 分模块迁移
+	Groups map[string][]string `yaml:"groups,omitempty" json:"groups,omitempty"`
+	// GroupDependencies 模块间的依赖，模块名 → 需要先迁移的模块
+	GroupDependencies map[string][]string `yaml:"group_dependencies,omitempty" json:"group_dependencies,omitempty"`
+	// RemoteStorage 迁移完成后把输出目录上传到 SFTP 或 S3 兼容对象存储归档
+	RemoteStorage RemoteStorageConfig `yaml:"remote_storage,omitempty" json:"remote_storage,omitempty"`
+	// Assertions 迁移数据后在目标库检查的断言规则，如订单表行数应大于0
+	Assertions []AssertionConfig `yaml:"assertions,omitempty" json:"assertions,omitempty"`
+	// SQLCheck 导入前对生成的SQL做语法预校验
+	SQLCheck SQLCheckConfig `yaml:"sql_check,omitempty" json:"sql_check,omitempty"`
+}
+
+// SQLReplacement 对生成SQL的正则替换规则
+type SQLReplacement struct {
+	Pattern     string `yaml:"pattern" json:"pattern"`
+	Replacement string `yaml:"replacement" json:"replacement"`
 }
 
 // OracleClientConfig Oracle客户端配置
@@ -68,8 +213,18 @@ type OracleClientConfig struct {
 
 // Manager 配置管理器
 type Manager struct {
-	config     *ProjectConfig
-	configPath string
+	config         *ProjectConfig
+	configPath     string
+	masterPassword string
+	encryptSecrets bool
+	// included 配置文件使用了 include 时的合并信息，保存时据此只写入本文件覆盖的字段
+	included *includedConfig
+	// envOverrides 加载时生效的环境变量覆盖，保存时不写入配置文件
+	envOverrides []EnvOverride
+	// interpolations 加载时展开的模板字段，保存时写回模板原文
+	interpolations []Interpolation
+	// mu 保护监听配置文件时被替换的 config
+	mu sync.RWMutex
 }
 
 // NewManager 创建新的配置管理器
@@ -89,20 +244,39 @@ func (m *Manager) LoadConfig(configPath string) error {
 		return fmt.Errorf("配置文件不存在: %s", configPath)
 	}
 
-	// 读取配置文件
-	data, err := os.ReadFile(configPath)
+	// 读取配置文件并合并 include 的公共配置
+	included, err := loadIncludedConfig(configPath)
 	if err != nil {
-		return fmt.Errorf("读取配置文件失败: %v", err)
+		return err
+	}
+	m.included = nil
+	if len(included.includes) > 0 {
+		m.included = included
+	}
+
+	// 解析YAML或JSON配置
+	if err := decodeConfigMap(included.merged, m.config); err != nil {
+		return err
 	}
 
-	// 解析YAML配置
-	if err := yaml.Unmarshal(data, m.config); err != nil {
-		return fmt.Errorf("解析配置文件失败: %v", err)
+	// 解密敏感字段（未加密的旧配置直接跳过）
+	if err := m.decryptSecrets(); err != nil {
+		return err
+	}
+
+	// 应用 ORA2PG_ADMIN_ 开头的环境变量覆盖，优先于配置文件
+	if err := m.applyEnvOverrides(); err != nil {
+		return err
 	}
 
 	// 处理环境变量替换
 	m.processEnvVars()
 
+	// 展开 {{...}} 模板，可引用其他配置字段和覆盖后的值
+	if err := m.interpolate(); err != nil {
+		return err
+	}
+
 	logrus.Infof("成功加载配置文件: %s", configPath)
 	return nil
 }
@@ -122,8 +296,29 @@ func (m *Manager) SaveConfig(configPath string) error {
 	// 更新时间戳
 	m.config.Project.Updated = time.Now()
 
-	// 序列化为YAML
-	data, err := yaml.Marshal(m.config)
+	// 模板展开的值和环境变量覆盖的值只在本次运行生效，写入配置文件中的原值；
+	// 先写回模板再恢复覆盖前的值，覆盖值本身是模板时才能与记录的覆盖值对应
+	defer m.withoutInterpolation()()
+	defer m.withoutEnvOverrides()()
+
+	// 启用加密时只把敏感字段的密文写入文件
+	output := m.config
+	if m.encryptSecrets {
+		encrypted, err := m.encryptedCopy()
+		if err != nil {
+			return err
+		}
+		output = encrypted
+	}
+
+	// 按扩展名序列化为YAML或JSON，使用 include 时只写入本文件覆盖的字段
+	var data []byte
+	var err error
+	if m.included != nil {
+		data, err = marshalWithIncludes(m.configPath, output, m.included)
+	} else {
+		data, err = marshalConfig(m.configPath, output)
+	}
 	if err != nil {
 		return fmt.Errorf("序列化配置失败: %v", err)
 	}
@@ -139,42 +334,66 @@ func (m *Manager) SaveConfig(configPath string) error {
 
 // GetConfig 获取配置
 func (m *Manager) GetConfig() *ProjectConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.config
 }
 
 // SetConfig 设置配置
 func (m *Manager) SetConfig(config *ProjectConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.config = config
 }
 
+// SetMasterPassword 设置加解密敏感字段使用的主密码
+func (m *Manager) SetMasterPassword(password string) {
+	m.masterPassword = password
+}
+
+// SetEncryption 设置保存时是否加密敏感字段
+func (m *Manager) SetEncryption(enabled bool) {
+	m.encryptSecrets = enabled
+}
+
+// IsEncrypted 配置文件是否启用了敏感字段加密
+func (m *Manager) IsEncrypted() bool {
+	return m.encryptSecrets
+}
+
 // processEnvVars 处理环境变量替换
 func (m *Manager) processEnvVars() {
-	// Oracle密码
-	if strings.HasPrefix(m.config.Oracle.Password, "${") && strings.HasSuffix(m.config.Oracle.Password, "}") {
-		envVar := strings.TrimSuffix(strings.TrimPrefix(m.config.Oracle.Password, "${"), "}")
-		if value := os.Getenv(envVar); value != "" {
-			m.config.Oracle.Password = value
+	for _, field := range secretFields(m.config) {
+		// 环境变量覆盖的值按原样使用
+		if m.isEnvOverridden(field) {
+			continue
 		}
+		*field = expandEnvReference(*field)
 	}
+}
 
-	// PostgreSQL密码
-	if strings.HasPrefix(m.config.PostgreSQL.Password, "${") && strings.HasSuffix(m.config.PostgreSQL.Password, "}") {
-		envVar := strings.TrimSuffix(strings.TrimPrefix(m.config.PostgreSQL.Password, "${"), "}")
-		if value := os.Getenv(envVar); value != "" {
-			m.config.PostgreSQL.Password = value
-		}
+// expandEnvReference 将 ${VAR} 形式的值替换为环境变量的值，未设置时保持原样
+func expandEnvReference(value string) string {
+	if !isEnvReference(value) {
+		return value
+	}
+	envVar := strings.TrimSuffix(strings.TrimPrefix(value, "${"), "}")
+	if envValue := os.Getenv(envVar); envValue != "" {
+		return envValue
 	}
+	return value
 }
 
 // CreateDefaultConfig 创建默认配置
 func (m *Manager) CreateDefaultConfig(projectName string) {
+	now := time.Now()
 	m.config = &ProjectConfig{
 		Project: ProjectInfo{
 			Name:        projectName,
 			Version:     "1.0.0",
 			Description: "Oracle到PostgreSQL数据库迁移项目",
-			Created:     time.Now(),
-			Updated:     time.Now(),
+			Created:     now,
+			Updated:     now,
 		},
 		Oracle: OracleConfig{
 			Host:     "localhost",
@@ -1,14 +1,20 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/utils"
 )
 
 func TestNewManager(t *testing.T) {
@@ -240,3 +246,2345 @@ func TestConfigDeepCopy(t *testing.T) {
 		t.Log("GetConfig返回的是深拷贝")
 	}
 }
+
+func TestUserTemplateSaveAndApply(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	manager := NewManager()
+	manager.CreateDefaultConfig("源项目")
+	source := manager.GetConfig()
+	source.Oracle.Host = "ora.prod.internal"
+	source.Oracle.Username = "scott"
+	source.Oracle.Password = "tiger"
+	source.PostgreSQL.Host = "pg.prod.internal"
+	source.PostgreSQL.Password = "secret"
+	source.Migration.Types = []string{"TABLE", "COPY"}
+	source.Migration.ParallelJobs = 8
+
+	path, err := SaveUserTemplate("团队标准", source)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "ora.prod.internal")
+	assert.NotContains(t, string(content), "tiger")
+	assert.NotContains(t, string(content), "secret")
+
+	names, err := ListUserTemplates()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"团队标准"}, names)
+
+	tmpl, err := LoadUserTemplate("团队标准")
+	require.NoError(t, err)
+
+	target := NewManager()
+	target.CreateDefaultConfig("新项目")
+	cfg := target.GetConfig()
+	ApplyUserTemplate(cfg, tmpl)
+	assert.Equal(t, "localhost", cfg.Oracle.Host)
+	assert.Equal(t, []string{"TABLE", "COPY"}, cfg.Migration.Types)
+	assert.Equal(t, 8, cfg.Migration.ParallelJobs)
+
+	// 模板不存在或名称非法
+	_, err = LoadUserTemplate("不存在")
+	assert.Error(t, err)
+	_, err = SaveUserTemplate("basic", source)
+	assert.Error(t, err)
+}
+
+func TestConfigEncryption(t *testing.T) {
+	t.Setenv(MasterPasswordEnv, "")
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+
+	manager := NewManager()
+	manager.CreateDefaultConfig("加密项目")
+	manager.GetConfig().Oracle.Password = "tiger"
+	manager.SetMasterPassword("master-pass")
+	manager.SetEncryption(true)
+	require.NoError(t, manager.SaveConfig(configPath))
+
+	// 文件中只保存密文，环境变量引用保持原样，内存中仍为明文
+	content, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "tiger")
+	assert.Contains(t, string(content), EncryptedPrefix)
+	assert.Contains(t, string(content), "${PG_PASSWORD}")
+	assert.Equal(t, "tiger", manager.GetConfig().Oracle.Password)
+
+	// 通过环境变量提供主密码
+	t.Setenv(MasterPasswordEnv, "master-pass")
+	loaded := NewManager()
+	require.NoError(t, loaded.LoadConfig(configPath))
+	assert.Equal(t, "tiger", loaded.GetConfig().Oracle.Password)
+	assert.True(t, loaded.IsEncrypted())
+
+	// 主密码错误
+	t.Setenv(MasterPasswordEnv, "wrong")
+	err = NewManager().LoadConfig(configPath)
+	assert.ErrorIs(t, err, ErrInvalidMasterPassword)
+
+	// 未提供主密码
+	t.Setenv(MasterPasswordEnv, "")
+	err = NewManager().LoadConfig(configPath)
+	assert.ErrorIs(t, err, ErrMasterPasswordRequired)
+
+	// 解密后保存为明文，未加密的配置仍可正常加载
+	loaded.SetEncryption(false)
+	require.NoError(t, loaded.SaveConfig(configPath))
+	plain := NewManager()
+	require.NoError(t, plain.LoadConfig(configPath))
+	assert.Equal(t, "tiger", plain.GetConfig().Oracle.Password)
+	assert.False(t, plain.IsEncrypted())
+}
+
+func TestConfigEncryptionKeepsBotSecretsPlain(t *testing.T) {
+	t.Setenv(MasterPasswordEnv, "")
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+
+	manager := NewManager()
+	manager.CreateDefaultConfig("加密项目")
+	manager.GetConfig().Notifications.Bots = []ChatBotConfig{
+		{Platform: "dingtalk", URL: "https://oapi.dingtalk.com/robot/send?access_token=${DINGTALK_TOKEN}", Secret: "SEC-plain"},
+	}
+	manager.SetMasterPassword("master-pass")
+	manager.SetEncryption(true)
+	require.NoError(t, manager.SaveConfig(configPath))
+
+	// 文件中的加签密钥为密文，内存中仍为明文，保存后发送消息时签名不受影响
+	content, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "SEC-plain")
+	assert.Equal(t, "SEC-plain", manager.GetConfig().Notifications.Bots[0].Secret)
+}
+
+func TestOra2pgSwitches(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("开关项目")
+	cfg := manager.GetConfig()
+	cfg.Migration.Options = map[string]bool{
+		"truncate_table":   true,
+		"DISABLE_TRIGGERS": false,
+	}
+
+	validator := NewValidator()
+	assert.True(t, validator.ValidateConfig(cfg).Valid)
+
+	values := make(map[string]bool)
+	for _, value := range ResolveOra2pgSwitches(cfg.Migration.Options) {
+		values[value.Name] = value.Enabled
+	}
+	assert.True(t, values["TRUNCATE_TABLE"])
+	assert.False(t, values["DISABLE_TRIGGERS"])
+	assert.True(t, values["STOP_ON_ERROR"])
+	assert.False(t, values["FILE_PER_TABLE"])
+	assert.Equal(t, []string{"DISABLE_TRIGGERS=关闭", "TRUNCATE_TABLE=开启"}, ChangedOra2pgSwitches(cfg.Migration.Options))
+
+	// 模板生成对应指令
+	outputPath := filepath.Join(t.TempDir(), "ora2pg.conf")
+	require.NoError(t, NewTemplateEngine(filepath.Join("..", "..", "templates")).GenerateOra2pgConfig(cfg, outputPath))
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "TRUNCATE_TABLE=1")
+	assert.Contains(t, string(content), "DISABLE_TRIGGERS=0")
+	assert.Contains(t, string(content), "FILE_PER_TABLE=0")
+
+	// 非法键名
+	cfg.Migration.Options["NOT_A_SWITCH"] = true
+	result := validator.ValidateConfig(cfg)
+	assert.False(t, result.Valid)
+	assert.Contains(t, result.Errors[0].Message, "NOT_A_SWITCH")
+}
+
+func TestConnectionTestAccount(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("测试账号项目")
+	cfg := manager.GetConfig()
+	cfg.Oracle.Username = "migrator"
+	cfg.Oracle.Password = "secret"
+
+	// 未配置测试账号时使用迁移账号
+	assert.False(t, cfg.Oracle.HasTestAccount())
+	assert.Equal(t, "migrator", cfg.Oracle.ForConnectionTest().Username)
+	assert.Equal(t, "secret", cfg.Oracle.ForConnectionTest().Password)
+
+	// 配置测试账号后连接测试使用测试账号，原配置不变
+	cfg.Oracle.TestUsername = "readonly"
+	cfg.Oracle.TestPassword = "${TEST_ORACLE_RO_PASSWORD}"
+	cfg.PostgreSQL.TestUsername = "pg_readonly"
+	cfg.PostgreSQL.TestPassword = "ro"
+
+	t.Setenv("TEST_ORACLE_RO_PASSWORD", "ro-secret")
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, manager.SaveConfig(configPath))
+	loaded := NewManager()
+	require.NoError(t, loaded.LoadConfig(configPath))
+
+	oracleTest := loaded.GetConfig().Oracle.ForConnectionTest()
+	assert.Equal(t, "readonly", oracleTest.Username)
+	assert.Equal(t, "ro-secret", oracleTest.Password)
+	assert.Equal(t, "migrator", loaded.GetConfig().Oracle.Username)
+	assert.Equal(t, "pg_readonly", loaded.GetConfig().PostgreSQL.ForConnectionTest().Username)
+
+	// 用户名和密码需同时配置
+	validator := NewValidator()
+	assert.True(t, validator.ValidateConfig(cfg).Valid)
+	cfg.Oracle.TestPassword = ""
+	cfg.PostgreSQL.TestUsername = ""
+	result := validator.ValidateConfig(cfg)
+	assert.False(t, result.Valid)
+	fields := make([]string, 0, len(result.Errors))
+	for _, e := range result.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Contains(t, fields, "oracle.test_password")
+	assert.Contains(t, fields, "postgresql.test_username")
+}
+
+func TestLargeTableSharding(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("大表项目")
+	cfg := manager.GetConfig()
+
+	// 未配置时不启用并行导出
+	assert.Equal(t, 1, cfg.Migration.OracleCopies())
+	assert.False(t, cfg.Migration.UsesParallelExport())
+
+	cfg.Migration.LargeTables = []LargeTableConfig{
+		{Name: "ORDERS", Shards: 8},
+		{Name: "audit_log", Shards: 4, SplitColumn: "rowid"},
+	}
+	cfg.Migration.ParallelTables = 2
+
+	validator := NewValidator()
+	assert.True(t, validator.ValidateConfig(cfg).Valid)
+	assert.Equal(t, 8, cfg.Migration.OracleCopies())
+	assert.Equal(t, []string{"AUDIT_LOG:ROWID"}, cfg.Migration.DefinedPK())
+	assert.True(t, cfg.Migration.UsesParallelExport())
+	assert.Equal(t, 2*8*cfg.Migration.ParallelJobs, cfg.Migration.ExportProcesses())
+
+	// 模板生成分片指令
+	outputPath := filepath.Join(t.TempDir(), "ora2pg.conf")
+	require.NoError(t, NewTemplateEngine(filepath.Join("..", "..", "templates")).GenerateOra2pgConfig(cfg, outputPath))
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "ORACLE_COPIES=8")
+	assert.Contains(t, string(content), "DEFINED_PK AUDIT_LOG:ROWID")
+	assert.Contains(t, string(content), "PARALLEL_TABLES=2")
+
+	// 非法配置
+	cfg.Migration.LargeTables = append(cfg.Migration.LargeTables,
+		LargeTableConfig{Name: "orders", Shards: 4},
+		LargeTableConfig{Name: "EVENTS", Shards: 1},
+		LargeTableConfig{Name: "ITEMS", Shards: 2, SplitColumn: "bad column"},
+	)
+	result := validator.ValidateConfig(cfg)
+	assert.False(t, result.Valid)
+	assert.Len(t, result.Errors, 3)
+}
+
+func TestConnectionPlan(t *testing.T) {
+	migration := &MigrationConfig{
+		ParallelJobs:   4,
+		ParallelTables: 4,
+		LargeTables:    []LargeTableConfig{{Name: "ORDERS", Shards: 8}},
+	}
+
+	// 未限制时保持配置
+	plan := migration.ConnectionPlan()
+	assert.False(t, plan.Adjusted)
+	assert.Equal(t, 4*8+1, plan.OracleConnections())
+	assert.Equal(t, 4*4+1, plan.PostgresConnections())
+
+	// 先减少并行表数，保留分片
+	migration.MaxConnections = 20
+	plan = migration.ConnectionPlan()
+	assert.True(t, plan.Adjusted)
+	assert.Equal(t, 2, plan.ParallelTables)
+	assert.Equal(t, 8, plan.OracleCopies)
+	assert.Equal(t, 4, plan.ParallelJobs)
+	assert.LessOrEqual(t, plan.OracleConnections(), 20)
+
+	// 上限很小时再降低分片数和作业数
+	migration.MaxConnections = 5
+	plan = migration.ConnectionPlan()
+	assert.Equal(t, 1, plan.ParallelTables)
+	assert.Equal(t, 4, plan.OracleCopies)
+	assert.Equal(t, 4, plan.ParallelJobs)
+	assert.Equal(t, 5, plan.OracleConnections())
+
+	// 生成的ora2pg.conf使用调整后的并行度
+	manager := NewManager()
+	manager.CreateDefaultConfig("连接限流")
+	cfg := manager.GetConfig()
+	cfg.Migration.ParallelJobs = 4
+	cfg.Migration.ParallelTables = 4
+	cfg.Migration.LargeTables = migration.LargeTables
+	cfg.Migration.MaxConnections = 3
+	outputPath := filepath.Join(t.TempDir(), "ora2pg.conf")
+	require.NoError(t, NewTemplateEngine(filepath.Join("..", "..", "templates")).GenerateOra2pgConfig(cfg, outputPath))
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "ORACLE_COPIES=2")
+	assert.Contains(t, string(content), "JOBS=2")
+	assert.NotContains(t, string(content), "PARALLEL_TABLES=")
+
+	cfg.Migration.MaxConnections = 1
+	assert.False(t, NewValidator().ValidateConfig(cfg).Valid)
+}
+
+func TestPostgresApplicationName(t *testing.T) {
+	appName := ApplicationName()
+	assert.True(t, strings.HasPrefix(appName, ApplicationNamePrefix+"/"))
+	assert.Equal(t, appName, ApplicationNamePrefix+"/"+utils.RunID())
+	assert.LessOrEqual(t, len(appName), 63) // NAMEDATALEN-1
+
+	pgConfig := &PostgreConfig{
+		Host:     "pg.example.com",
+		Port:     5432,
+		Database: "app",
+		Username: "mig@user",
+		Password: "p@ss/w:rd",
+	}
+
+	assert.Equal(t, "dbi:Pg:dbname=app;host=pg.example.com;port=5432;application_name="+appName, pgConfig.DBIDSN())
+
+	uri, err := url.Parse(pgConfig.ConnectionURI())
+	require.NoError(t, err)
+	assert.Equal(t, "pg.example.com:5432", uri.Host)
+	assert.Equal(t, "/app", uri.Path)
+	assert.Equal(t, "mig@user", uri.User.Username())
+	password, _ := uri.User.Password()
+	assert.Equal(t, "p@ss/w:rd", password)
+	assert.Equal(t, appName, uri.Query().Get("application_name"))
+}
+
+func TestNotificationConfig(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("通知项目")
+	cfg := manager.GetConfig()
+	validator := NewValidator()
+
+	// 未启用时不校验
+	cfg.Notifications.Email.SMTPHost = ""
+	assert.True(t, validator.ValidateConfig(cfg).Valid)
+
+	cfg.Notifications.Email = EmailNotificationConfig{
+		Enabled:  true,
+		SMTPHost: "smtp.example.com",
+		SMTPPort: 587,
+		From:     "迁移机器人 <dba@example.com>",
+		To:       []string{"team@example.com"},
+		Events:   []string{"failure"},
+		Format:   "HTML",
+	}
+	assert.True(t, validator.ValidateConfig(cfg).Valid)
+	assert.True(t, cfg.Notifications.Email.IsHTML())
+	assert.True(t, cfg.Notifications.Email.NotifyOn(NotificationEventFailure))
+	assert.False(t, cfg.Notifications.Email.NotifyOn(NotificationEventSuccess))
+
+	cfg.Notifications.Email.SMTPPort = 0
+	cfg.Notifications.Email.To = []string{"not-an-address"}
+	cfg.Notifications.Email.Events = []string{"started"}
+	cfg.Notifications.Email.Format = "markdown"
+	result := validator.ValidateConfig(cfg)
+	assert.False(t, result.Valid)
+	assert.Len(t, result.Errors, 4)
+
+	// 邮件密码与数据库密码一样参与加密
+	assert.Contains(t, secretFields(cfg), &cfg.Notifications.Email.Password)
+}
+
+func TestWebhookNotificationConfig(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("进度推送")
+	cfg := manager.GetConfig()
+	validator := NewValidator()
+
+	// 未配置时使用默认的节流和重试参数
+	webhook := &cfg.Notifications.Webhook
+	assert.Equal(t, float64(DefaultWebhookPercentStep), webhook.Step())
+	assert.Equal(t, DefaultWebhookMinInterval, webhook.Interval())
+	assert.Equal(t, DefaultWebhookRetries, webhook.RetryCount())
+	assert.Equal(t, DefaultWebhookTimeout, webhook.RequestTimeout())
+
+	noRetry := 0
+	*webhook = WebhookNotificationConfig{
+		Enabled:     true,
+		URL:         "https://dashboard.example.com/hooks/ora2pg",
+		PercentStep: 5,
+		MinInterval: "1m",
+		Retries:     &noRetry,
+		Timeout:     "3s",
+	}
+	assert.True(t, validator.ValidateConfig(cfg).Valid)
+	assert.Equal(t, 5.0, webhook.Step())
+	assert.Equal(t, time.Minute, webhook.Interval())
+	assert.Equal(t, 0, webhook.RetryCount())
+	assert.Equal(t, 3*time.Second, webhook.RequestTimeout())
+
+	tooMany := 20
+	webhook.URL = "ftp://dashboard.example.com"
+	webhook.PercentStep = 150
+	webhook.Retries = &tooMany
+	webhook.MinInterval = "soon"
+	webhook.Timeout = "-1s"
+	result := validator.ValidateConfig(cfg)
+	assert.False(t, result.Valid)
+	assert.Len(t, result.Errors, 5)
+
+	// 令牌与密码一样参与加密，共享模板中会被清除
+	assert.Contains(t, secretFields(cfg), &cfg.Notifications.Webhook.Token)
+}
+
+func TestMetricsConfig(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("指标项目")
+	cfg := manager.GetConfig()
+	validator := NewValidator()
+
+	// 默认不导出
+	assert.False(t, cfg.Metrics.Enabled())
+	assert.True(t, validator.ValidateConfig(cfg).Valid)
+
+	cfg.Metrics.Textfile = "/var/lib/node_exporter/textfile/ora2pg.prom"
+	assert.True(t, cfg.Metrics.Enabled())
+	assert.True(t, validator.ValidateConfig(cfg).Valid)
+
+	// textfile collector 只读取 .prom 文件
+	cfg.Metrics.Textfile = "metrics.txt"
+	result := validator.ValidateConfig(cfg)
+	assert.False(t, result.Valid)
+	assert.Len(t, result.Errors, 1)
+}
+
+func TestLogSplitConfig(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("日志项目")
+	cfg := manager.GetConfig()
+	validator := NewValidator()
+
+	// 默认不切割
+	assert.False(t, cfg.Migration.LogSplit.Enabled())
+
+	cfg.Migration.LogSplit = LogSplitConfig{MaxSizeMB: 200, Interval: "1h"}
+	assert.True(t, validator.ValidateConfig(cfg).Valid)
+	assert.True(t, cfg.Migration.LogSplit.Enabled())
+	assert.Equal(t, int64(200*1024*1024), cfg.Migration.LogSplit.MaxSize())
+	assert.Equal(t, time.Hour, cfg.Migration.LogSplit.SplitInterval())
+
+	cfg.Migration.LogSplit = LogSplitConfig{MaxSizeMB: -1, Interval: "10s"}
+	result := validator.ValidateConfig(cfg)
+	assert.False(t, result.Valid)
+	assert.Len(t, result.Errors, 2)
+
+	cfg.Migration.LogSplit = LogSplitConfig{Interval: "hourly"}
+	assert.False(t, validator.ValidateConfig(cfg).Valid)
+	assert.Zero(t, cfg.Migration.LogSplit.SplitInterval())
+}
+
+func TestNamingConvention(t *testing.T) {
+	for name, expected := range map[string]string{
+		"OrderHistory": "order_history",
+		"HTTPLog":      "http_log",
+		"ORDER_ITEMS":  "order_items",
+		"Order2Items":  "order2_items",
+		"EMPLOYEES":    "employees",
+	} {
+		assert.Equal(t, expected, toSnakeCase(name), name)
+	}
+
+	// 规则组合：驼峰转下划线后加前缀和后缀
+	naming := NamingConvention{SnakeCase: true, Prefix: "t_", Suffix: "_tab"}
+	assert.Equal(t, "t_order_history_tab", naming.ConvertTable("OrderHistory", false))
+	assert.Equal(t, "驼峰转下划线，前缀 t_，后缀 _tab", naming.Describe())
+	assert.True(t, naming.RenamesTables())
+
+	// 保留大小写时只加前缀
+	naming = NamingConvention{Case: "preserve", Prefix: "t_"}
+	assert.Equal(t, "t_OrderHistory", naming.ConvertTable("OrderHistory", true))
+
+	// 保留字和冲突
+	naming = NamingConvention{SnakeCase: true}
+	mappings, err := naming.TableMappings([]string{"USER", "OrderItems"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []NameMapping{{Source: "USER", Target: "user", Reserved: true}, {Source: "OrderItems", Target: "order_items"}}, mappings)
+	_, err = naming.TableMappings([]string{"ORDER_ITEMS", "OrderItems"}, false)
+	assert.ErrorContains(t, err, "order_items")
+	naming = NamingConvention{Prefix: strings.Repeat("p", 60)}
+	_, err = naming.TableMappings([]string{"EMPLOYEES"}, false)
+	assert.ErrorContains(t, err, "63")
+
+	// 预览示例包含保留字提示
+	naming = NamingConvention{SnakeCase: true}
+	examples := naming.Examples(false)
+	require.Len(t, examples, 4)
+	assert.Equal(t, NameMapping{Source: "OrderHistory", Target: "order_history"}, examples[2])
+	assert.Equal(t, NameMapping{Source: "USER", Target: "user", Reserved: true}, examples[3])
+
+	// 目标表名去掉模式前缀并应用规则
+	migration := MigrationConfig{NamingConvention: NamingConvention{Prefix: "t_"}}
+	assert.Equal(t, "t_employees", migration.TargetTableName("HR.EMPLOYEES"))
+	migration.Options = map[string]bool{"PRESERVE_CASE": true}
+	assert.True(t, migration.PreserveCase())
+	assert.Equal(t, "t_EMPLOYEES", migration.TargetTableName("HR.EMPLOYEES"))
+}
+
+func TestNamingConventionValidation(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("命名项目")
+	cfg := manager.GetConfig()
+	validator := NewValidator()
+
+	cfg.Migration.NamingConvention = NamingConvention{Case: "lower", SnakeCase: true, Prefix: "t_"}
+	assert.True(t, validator.ValidateConfig(cfg).Valid)
+
+	cfg.Migration.NamingConvention = NamingConvention{Case: "upper", Prefix: "1t", Suffix: "-x"}
+	result := validator.ValidateConfig(cfg)
+	assert.False(t, result.Valid)
+	assert.Len(t, result.Errors, 3)
+
+	cfg.Migration.NamingConvention = NamingConvention{Case: "preserve", SnakeCase: true}
+	cfg.Migration.Options = map[string]bool{"PRESERVE_CASE": false}
+	result = validator.ValidateConfig(cfg)
+	assert.False(t, result.Valid)
+	assert.Len(t, result.Errors, 2)
+}
+
+func TestNamingConventionTemplate(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("命名项目")
+	cfg := manager.GetConfig()
+	cfg.Migration.NamingConvention = NamingConvention{SnakeCase: true, Prefix: "t_"}
+
+	// 需要逐表改名但没有源库表名时不生成配置
+	engine := NewTemplateEngine(filepath.Join("..", "..", "templates"))
+	outputPath := filepath.Join(t.TempDir(), "ora2pg.conf")
+	assert.Error(t, engine.GenerateOra2pgConfig(cfg, outputPath))
+
+	engine.SetSourceTables([]string{"EMPLOYEES", "OrderItems"})
+	require.NoError(t, engine.GenerateOra2pgConfig(cfg, outputPath))
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "REPLACE_TABLES EMPLOYEES:t_employees OrderItems:t_order_items")
+	assert.Contains(t, string(content), "USE_RESERVED_WORDS=0")
+
+	// 转换后为保留字时自动加引号，与默认名称相同的表不写入 REPLACE_TABLES
+	cfg.Migration.NamingConvention = NamingConvention{SnakeCase: true}
+	engine.SetSourceTables([]string{"USER", "ORDERS"})
+	require.NoError(t, engine.GenerateOra2pgConfig(cfg, outputPath))
+	content, err = os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "REPLACE_TABLES")
+	assert.Contains(t, string(content), "USE_RESERVED_WORDS=1")
+
+	engine.SetSourceTables([]string{"UserAccounts", "Order"})
+	require.NoError(t, engine.GenerateOra2pgConfig(cfg, outputPath))
+	content, err = os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "REPLACE_TABLES UserAccounts:user_accounts\n")
+	assert.Contains(t, string(content), "USE_RESERVED_WORDS=1")
+
+	// 冲突时返回错误
+	engine.SetSourceTables([]string{"ORDER_ITEMS", "OrderItems"})
+	err = engine.GenerateOra2pgConfig(cfg, outputPath)
+	assert.Equal(t, "NAMING_CONVENTION_CONFLICT", utils.GetErrorCode(err))
+
+	// 只保留大小写时不需要源库表名
+	cfg.Migration.NamingConvention = NamingConvention{Case: "preserve"}
+	require.NoError(t, NewTemplateEngine(filepath.Join("..", "..", "templates")).GenerateOra2pgConfig(cfg, outputPath))
+	content, err = os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "PRESERVE_CASE=1")
+}
+
+func TestDumpFileReference(t *testing.T) {
+	dir := t.TempDir()
+	dumpPath := filepath.Join(dir, "hr_full")
+	require.NoError(t, os.WriteFile(dumpPath, []byte("dump"), 0644))
+
+	assert.True(t, IsDumpFilePath("EXPORT.DMP"))
+	assert.True(t, IsDumpFilePath(" /backup/hr_%U.dmp "))
+	assert.True(t, IsDumpFilePath(dumpPath))
+	assert.False(t, IsDumpFilePath("db.example.com"))
+	assert.False(t, IsDumpFilePath("ORCLPDB1"))
+	assert.False(t, IsDumpFilePath(filepath.Join(dir, "missing")))
+	assert.False(t, IsDumpFilePath(""))
+
+	manager := NewManager()
+	manager.CreateDefaultConfig("导出文件项目")
+	cfg := manager.GetConfig()
+	cfg.Oracle.Host = "db.example.com"
+	cfg.Oracle.Username = "hr"
+	cfg.Oracle.Password = "hr"
+	assert.NoError(t, CheckDumpFileReference(&cfg.Oracle))
+
+	// 导出文件名本身也符合域名格式，只报告导出文件错误
+	cfg.Oracle.Host = "expdp_hr.dmp"
+	field, value, found := DumpFileReference(&cfg.Oracle)
+	assert.True(t, found)
+	assert.Equal(t, "oracle.host", field)
+	assert.Equal(t, "expdp_hr.dmp", value)
+
+	result := NewValidator().ValidateConfig(cfg)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "oracle.host", result.Errors[0].Field)
+	assert.Contains(t, result.Errors[0].Message, "impdp")
+
+	cfg.Oracle.Host = "db.example.com"
+	cfg.Oracle.SID = ""
+	cfg.Oracle.Service = dumpPath
+	err := CheckDumpFileReference(&cfg.Oracle)
+	require.Error(t, err)
+	assert.Equal(t, "ORACLE_DUMP_FILE_CONFIGURED", utils.GetErrorCode(err))
+	assert.Contains(t, err.Error(), "oracle.service")
+}
+
+func TestConfigInclude(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "shared"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "project"), 0755))
+
+	// 公共配置再 include 更底层的默认值，路径相对于公共配置所在目录
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shared", "defaults.yaml"), []byte(`
+migration:
+  batch_size: 500
+  log_level: DEBUG
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "shared", "common.yaml"), []byte(`
+include: defaults.yaml
+oracle:
+  host: ora.example.com
+  port: 1521
+migration:
+  types: [TABLE, COPY]
+  parallel_jobs: 8
+  batch_size: 2000
+  options:
+    TRUNCATE_TABLE: true
+    FILE_PER_TABLE: true
+`), 0644))
+	configPath := filepath.Join(dir, "project", "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+include:
+  - ../shared/common.yaml
+project:
+  name: 子项目
+oracle:
+  sid: ORCL
+migration:
+  types: [TABLE]
+  parallel_jobs: 2
+  options:
+    FILE_PER_TABLE: false
+`), 0644))
+
+	manager := NewManager()
+	require.NoError(t, manager.LoadConfig(configPath))
+	cfg := manager.GetConfig()
+
+	// 映射深合并，本文件优先，列表整体替换
+	assert.Equal(t, "子项目", cfg.Project.Name)
+	assert.Equal(t, "ora.example.com", cfg.Oracle.Host)
+	assert.Equal(t, 1521, cfg.Oracle.Port)
+	assert.Equal(t, "ORCL", cfg.Oracle.SID)
+	assert.Equal(t, []string{"TABLE"}, cfg.Migration.Types)
+	assert.Equal(t, 2, cfg.Migration.ParallelJobs)
+	assert.Equal(t, 2000, cfg.Migration.BatchSize)
+	assert.Equal(t, "DEBUG", cfg.Migration.LogLevel)
+	assert.Equal(t, map[string]bool{"TRUNCATE_TABLE": true, "FILE_PER_TABLE": false}, cfg.Migration.Options)
+
+	// 保存时保留 include，只写入本文件覆盖的字段
+	cfg.Oracle.Port = 1522
+	require.NoError(t, manager.SaveConfig(""))
+	content, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "include:\n    - ../shared/common.yaml\n"), string(content))
+	assert.NotContains(t, string(content), "ora.example.com")
+	assert.Contains(t, string(content), "port: 1522")
+
+	reloaded := NewManager()
+	require.NoError(t, reloaded.LoadConfig(configPath))
+	assert.Equal(t, "ora.example.com", reloaded.GetConfig().Oracle.Host)
+	assert.Equal(t, 1522, reloaded.GetConfig().Oracle.Port)
+	assert.Equal(t, 2000, reloaded.GetConfig().Migration.BatchSize)
+	assert.Equal(t, cfg.Migration.Options, reloaded.GetConfig().Migration.Options)
+}
+
+func TestConfigIncludeErrors(t *testing.T) {
+	dir := t.TempDir()
+	aPath := filepath.Join(dir, "a.yaml")
+	require.NoError(t, os.WriteFile(aPath, []byte("include: b.yaml\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.yaml"), []byte("include: [a.yaml]\n"), 0644))
+
+	err := NewManager().LoadConfig(aPath)
+	require.Error(t, err)
+	assert.Equal(t, "CONFIG_INCLUDE_CYCLE", utils.GetErrorCode(err))
+	assert.Contains(t, err.Error(), "循环")
+
+	missingPath := filepath.Join(dir, "missing.yaml")
+	require.NoError(t, os.WriteFile(missingPath, []byte("include: nowhere.yaml\n"), 0644))
+	err = NewManager().LoadConfig(missingPath)
+	assert.Equal(t, "CONFIG_INCLUDE_NOT_FOUND", utils.GetErrorCode(err))
+
+	invalidPath := filepath.Join(dir, "invalid.yaml")
+	require.NoError(t, os.WriteFile(invalidPath, []byte("include:\n  path: a.yaml\n"), 0644))
+	assert.Error(t, NewManager().LoadConfig(invalidPath))
+}
+
+func TestMigrationTimeZone(t *testing.T) {
+	migration := &MigrationConfig{}
+	assert.Empty(t, migration.OracleTimeZoneCommand())
+	assert.Empty(t, migration.PostgresTimeZoneCommand())
+
+	migration.TimeZone = "Asia/Shanghai"
+	assert.Equal(t, "ALTER SESSION SET TIME_ZONE = 'Asia/Shanghai'", migration.OracleTimeZoneCommand())
+	assert.Equal(t, "SET TIME ZONE 'Asia/Shanghai'", migration.PostgresTimeZoneCommand())
+
+	// 偏移量使用 INTERVAL，避免被按POSIX规则解释为相反的时区
+	migration.TimeZone = "+08:00"
+	assert.Equal(t, "ALTER SESSION SET TIME_ZONE = '+08:00'", migration.OracleTimeZoneCommand())
+	assert.Equal(t, "SET TIME ZONE INTERVAL '+08:00' HOUR TO MINUTE", migration.PostgresTimeZoneCommand())
+
+	validator := NewValidator()
+	for _, value := range []string{"UTC", "Asia/Shanghai", "America/Argentina/Buenos_Aires", "-05:30"} {
+		result := &ValidationResult{Valid: true}
+		validator.validateTimeZone(&MigrationConfig{TimeZone: value}, result)
+		assert.True(t, result.Valid, value)
+	}
+	for _, value := range []string{"Asia/Shanghai'; DROP TABLE x", "+25:00", "8"} {
+		result := &ValidationResult{Valid: true}
+		validator.validateTimeZone(&MigrationConfig{TimeZone: value}, result)
+		assert.False(t, result.Valid, value)
+	}
+
+	// 配置后生成的ora2pg配置统一两端会话时区
+	manager := NewManager()
+	manager.CreateDefaultConfig("时区项目")
+	cfg := manager.GetConfig()
+	engine := NewTemplateEngine(filepath.Join("..", "..", "templates"))
+	outputPath := filepath.Join(t.TempDir(), "ora2pg.conf")
+	require.NoError(t, engine.GenerateOra2pgConfig(cfg, outputPath))
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "INITIAL_COMMAND")
+
+	cfg.Migration.TimeZone = "+08:00"
+	require.NoError(t, engine.GenerateOra2pgConfig(cfg, outputPath))
+	content, err = os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "ORA_INITIAL_COMMAND ALTER SESSION SET TIME_ZONE = '+08:00'\n")
+	assert.Contains(t, string(content), "PG_INITIAL_COMMAND SET TIME ZONE INTERVAL '+08:00' HOUR TO MINUTE\n")
+}
+
+func TestIncrementalConfig(t *testing.T) {
+	incremental := &IncrementalConfig{}
+	assert.False(t, incremental.Enabled())
+	assert.False(t, incremental.OthersFull())
+
+	table := IncrementalTableConfig{Name: " orders ", Column: "updated_at"}
+	assert.Equal(t, "ORDERS", table.TableName())
+	assert.Equal(t, "UPDATED_AT", table.ColumnName())
+	assert.Equal(t, IncrementalTimestamp, table.ColumnType())
+
+	validator := NewValidator()
+	result := &ValidationResult{Valid: true}
+	validator.validateIncremental(&MigrationConfig{Incremental: IncrementalConfig{
+		Tables: []IncrementalTableConfig{table, {Name: "EVENTS", Column: "ID", Type: "Sequence"}},
+		Others: "FULL",
+	}}, result)
+	assert.True(t, result.Valid)
+
+	for _, invalid := range []IncrementalConfig{
+		{Tables: []IncrementalTableConfig{{Name: "APP.ORDERS", Column: "ID"}}},
+		{Tables: []IncrementalTableConfig{{Name: "ORDERS", Column: "ID"}, {Name: "orders", Column: "ID"}}},
+		{Tables: []IncrementalTableConfig{{Name: "ORDERS", Column: "ID] OR 1=1"}}},
+		{Tables: []IncrementalTableConfig{{Name: "ORDERS", Column: "ID", Type: "rowid"}}},
+		{Tables: []IncrementalTableConfig{{Name: "ORDERS", Column: "ID"}}, Others: "truncate"},
+	} {
+		result := &ValidationResult{Valid: true}
+		validator.validateIncremental(&MigrationConfig{Incremental: invalid}, result)
+		assert.False(t, result.Valid, "%+v", invalid)
+	}
+}
+
+func TestEnvironmentConfig(t *testing.T) {
+	assert.NoError(t, ValidateEnvName("TNS_ADMIN"))
+	assert.NoError(t, ValidateEnvName("_X1"))
+	for _, name := range []string{"", "1ABC", "NLS-LANG", "A B", "A=B"} {
+		assert.Error(t, ValidateEnvName(name), name)
+	}
+
+	validator := NewValidator()
+	result := &ValidationResult{Valid: true}
+	validator.validateEnvironment(map[string]string{"TNS_ADMIN": "/etc/oracle", "EMPTY": ""}, result)
+	assert.True(t, result.Valid)
+
+	result = &ValidationResult{Valid: true}
+	validator.validateEnvironment(map[string]string{"BAD-NAME": "x", "MULTI": "a\nb"}, result)
+	assert.False(t, result.Valid)
+	assert.Len(t, result.Errors, 2)
+
+	// 配置覆盖默认值，$VAR 先引用默认值再引用系统环境
+	t.Setenv("ORA2PG_ADMIN_TEST_HOME", "/home/oracle")
+	merged := MergeEnvironment(map[string]string{"NLS_LANG": "AMERICAN_AMERICA.UTF8", "ORACLE_HOME": "/opt/oracle"}, map[string]string{
+		"NLS_LANG":  "SIMPLIFIED CHINESE_CHINA.AL32UTF8",
+		"TNS_ADMIN": "$ORACLE_HOME/network/admin",
+		"WALLET":    "${ORA2PG_ADMIN_TEST_HOME}/wallet",
+	})
+	assert.Equal(t, "SIMPLIFIED CHINESE_CHINA.AL32UTF8", merged["NLS_LANG"])
+	assert.Equal(t, "/opt/oracle/network/admin", merged["TNS_ADMIN"])
+	assert.Equal(t, "/home/oracle/wallet", merged["WALLET"])
+	assert.Equal(t, "/opt/oracle", merged["ORACLE_HOME"])
+
+	cfg := &ProjectConfig{Environment: map[string]string{"B": "2", "A": "1"}}
+	assert.Equal(t, []string{"A", "B"}, cfg.EnvironmentNames())
+}
+
+func TestAllowTables(t *testing.T) {
+	validator := NewValidator()
+	result := &ValidationResult{Valid: true}
+	validator.validateAllowTables(&MigrationConfig{AllowTables: []string{"ORDERS", "HR.EMPLOYEES", "T$1#"}}, result)
+	assert.True(t, result.Valid)
+
+	for _, tables := range [][]string{{"ORDERS; DROP"}, {""}, {"A.B.C"}, {"ORDERS", "orders"}} {
+		result := &ValidationResult{Valid: true}
+		validator.validateAllowTables(&MigrationConfig{AllowTables: tables}, result)
+		assert.False(t, result.Valid, tables)
+	}
+
+	// 选择了表时生成 ALLOW 指令
+	manager := NewManager()
+	manager.CreateDefaultConfig("表选择项目")
+	cfg := manager.GetConfig()
+	engine := NewTemplateEngine(filepath.Join("..", "..", "templates"))
+	outputPath := filepath.Join(t.TempDir(), "ora2pg.conf")
+	require.NoError(t, engine.GenerateOra2pgConfig(cfg, outputPath))
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "\nALLOW")
+
+	cfg.Migration.AllowTables = []string{"ORDERS", "HR.EMPLOYEES"}
+	require.NoError(t, engine.GenerateOra2pgConfig(cfg, outputPath))
+	content, err = os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "\nALLOW ORDERS HR.EMPLOYEES\n")
+}
+
+func TestProgressRulesConfig(t *testing.T) {
+	validator := NewValidator()
+	result := &ValidationResult{Valid: true}
+	validator.validateProgressRules(&MigrationConfig{ProgressRules: []ProgressRuleConfig{
+		{Name: "dump", Pattern: `Dumping (\w+) \((\d+)/(\d+)\)`, Step: "导出 $1", Completed: 2, Total: 3},
+	}}, result)
+	assert.True(t, result.Valid)
+
+	invalid := [][]ProgressRuleConfig{
+		{{Name: "", Pattern: `x`, Message: "m"}},
+		{{Name: "a", Pattern: `(`, Message: "m"}},
+		{{Name: "a", Pattern: `(\d+)`, Rows: 2}},
+		{{Name: "a", Pattern: `x`}},
+		{{Name: "a", Pattern: `x`, Message: "m"}, {Name: "a", Pattern: `y`, Message: "m"}},
+	}
+	for _, rules := range invalid {
+		result := &ValidationResult{Valid: true}
+		validator.validateProgressRules(&MigrationConfig{ProgressRules: rules}, result)
+		assert.False(t, result.Valid, rules)
+	}
+}
+
+func TestJSONConfigRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	t.Setenv("ORA2PG_TEST_PG_PASSWORD", "from-env")
+
+	manager := NewManager()
+	manager.CreateDefaultConfig("JSON项目")
+	cfg := manager.GetConfig()
+	cfg.Oracle.Port = 1522
+	cfg.PostgreSQL.Password = "${ORA2PG_TEST_PG_PASSWORD}"
+	cfg.Migration.ParallelJobs = 6
+	cfg.Migration.AllowTables = []string{"ORDERS", "HR.EMPLOYEES"}
+	cfg.Migration.LargeTables = []LargeTableConfig{{Name: "ORDERS", Shards: 4}}
+	require.NoError(t, manager.SaveConfig(configPath))
+
+	content, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.True(t, json.Valid(content), string(content))
+	assert.Contains(t, string(content), `"parallel_jobs": 6`)
+	// 环境变量引用原样保存
+	assert.Contains(t, string(content), `"${ORA2PG_TEST_PG_PASSWORD}"`)
+
+	loaded := NewManager()
+	require.NoError(t, loaded.LoadConfig(configPath))
+	loadedCfg := loaded.GetConfig()
+	assert.Equal(t, "JSON项目", loadedCfg.Project.Name)
+	assert.Equal(t, 1522, loadedCfg.Oracle.Port)
+	assert.Equal(t, 6, loadedCfg.Migration.ParallelJobs)
+	assert.Equal(t, []string{"ORDERS", "HR.EMPLOYEES"}, loadedCfg.Migration.AllowTables)
+	assert.Equal(t, []LargeTableConfig{{Name: "ORDERS", Shards: 4}}, loadedCfg.Migration.LargeTables)
+	assert.True(t, cfg.Project.Created.Equal(loadedCfg.Project.Created))
+	assert.Equal(t, "from-env", loadedCfg.PostgreSQL.Password)
+
+	// 另存为YAML后内容一致
+	yamlPath := filepath.Join(dir, "config.yaml")
+	require.NoError(t, WriteConfigFile(yamlPath, cfg))
+	fromYAML, err := ReadConfigFile(yamlPath)
+	require.NoError(t, err)
+	fromJSON, err := ReadConfigFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, fromYAML.Migration, fromJSON.Migration)
+}
+
+func TestJSONConfigIncludesYAML(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "common.yaml"), []byte("migration:\n  parallel_jobs: 3\n  batch_size: 5000\n"), 0644))
+	configPath := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"include": "common.yaml", "migration": {"batch_size": 20000}}`), 0644))
+
+	manager := NewManager()
+	require.NoError(t, manager.LoadConfig(configPath))
+	assert.Equal(t, 3, manager.GetConfig().Migration.ParallelJobs)
+	assert.Equal(t, 20000, manager.GetConfig().Migration.BatchSize)
+
+	require.NoError(t, manager.SaveConfig(""))
+	var saved map[string]interface{}
+	content, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(content, &saved))
+	assert.Equal(t, []interface{}{"common.yaml"}, saved["include"])
+	migration := saved["migration"].(map[string]interface{})
+	assert.Equal(t, float64(20000), migration["batch_size"])
+	assert.NotContains(t, migration, "parallel_jobs")
+}
+
+func TestConfigFormatErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	// 扩展名为 .json 但内容是YAML
+	mismatched := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(mismatched, []byte("oracle:\n  host: db\n"), 0644))
+	err := NewManager().LoadConfig(mismatched)
+	assert.Equal(t, "CONFIG_FORMAT_MISMATCH", utils.GetErrorCode(err))
+
+	broken := filepath.Join(dir, "broken.json")
+	require.NoError(t, os.WriteFile(broken, []byte(`{"oracle": {"host": "db",}}`), 0644))
+	err = NewManager().LoadConfig(broken)
+	require.Error(t, err)
+	assert.NotEqual(t, "CONFIG_FORMAT_MISMATCH", utils.GetErrorCode(err))
+
+	unknown := filepath.Join(dir, "config.toml")
+	require.NoError(t, os.WriteFile(unknown, []byte("x = 1\n"), 0644))
+	err = NewManager().LoadConfig(unknown)
+	assert.Equal(t, "CONFIG_FORMAT_UNSUPPORTED", utils.GetErrorCode(err))
+
+	_, err = ParseFormat("toml")
+	assert.Equal(t, "CONFIG_FORMAT_UNSUPPORTED", utils.GetErrorCode(err))
+	format, err := ParseFormat("JSON")
+	require.NoError(t, err)
+	assert.Equal(t, "config.json", format.FileName())
+}
+
+func TestProjectConfigPath(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, filepath.Join(dir, ProjectConfigDir, "config.yaml"), ProjectConfigPath(dir))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ProjectConfigDir), 0755))
+	jsonPath := filepath.Join(dir, ProjectConfigDir, "config.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte("{}"), 0644))
+	assert.Equal(t, jsonPath, ProjectConfigPath(dir))
+}
+
+func TestMigrationScriptsConfig(t *testing.T) {
+	scripts := &MigrationScriptsConfig{}
+	assert.Equal(t, filepath.Join("scripts", "pre"), scripts.PreScriptsDir())
+	assert.Equal(t, filepath.Join("scripts", "post"), scripts.PostScriptsDir())
+	assert.Equal(t, ScriptTargetPostgreSQL, scripts.DefaultTarget())
+	assert.False(t, scripts.ContinueOnError())
+
+	validator := NewValidator()
+	result := &ValidationResult{Valid: true}
+	validator.validateMigrationScripts(&MigrationConfig{Scripts: MigrationScriptsConfig{Target: ScriptTargetOracle, OnError: ScriptOnErrorContinue}}, result)
+	assert.True(t, result.Valid)
+
+	for _, invalid := range []MigrationScriptsConfig{{Target: "mysql"}, {OnError: "ignore"}} {
+		result := &ValidationResult{Valid: true}
+		validator.validateMigrationScripts(&MigrationConfig{Scripts: invalid}, result)
+		assert.False(t, result.Valid, invalid)
+	}
+}
+
+func TestValidateConsistency(t *testing.T) {
+	validator := NewValidator()
+	warnings := func(migration *MigrationConfig) []string {
+		result := &ValidationResult{Valid: true}
+		validator.validateConsistency(migration, result)
+		assert.True(t, result.Valid)
+		var fields []string
+		for _, warning := range result.Warnings {
+			fields = append(fields, warning.Field)
+		}
+		return fields
+	}
+
+	assert.Empty(t, warnings(&MigrationConfig{Types: []string{"TABLE", "COPY", "INDEX"}, DeferConstraints: true}))
+	assert.Equal(t, []string{"migration.types", "migration.types"},
+		warnings(&MigrationConfig{Types: []string{"TABLE", "copy", "COPY", "INSERT"}}))
+
+	// 没有数据迁移类型时，数据相关选项不起作用
+	assert.Equal(t, []string{"migration.defer_constraints", "migration.large_tables", "migration.options.TRUNCATE_TABLE"},
+		warnings(&MigrationConfig{
+			Types:            []string{"TABLE"},
+			DeferConstraints: true,
+			LargeTables:      []LargeTableConfig{{Name: "ORDERS", Shards: 4}},
+			Options:          map[string]bool{"truncate_table": true, "DROP_INDEXES": false},
+		}))
+
+	incremental := IncrementalConfig{Tables: []IncrementalTableConfig{{Name: "ORDERS", Column: "UPDATED_AT"}}}
+	assert.Equal(t, []string{"migration.options.TRUNCATE_TABLE"},
+		warnings(&MigrationConfig{Types: []string{"COPY"}, Incremental: incremental, Options: map[string]bool{"TRUNCATE_TABLE": true}}))
+	assert.Equal(t, []string{"migration.options.DROP_FKEY"},
+		warnings(&MigrationConfig{Types: []string{"COPY"}, DeferConstraints: true, Options: map[string]bool{"DROP_FKEY": true}}))
+	assert.Equal(t, []string{"migration.naming_convention.case"},
+		warnings(&MigrationConfig{Types: []string{"TABLE"}, NamingConvention: NamingConvention{Case: "lower"}, Options: map[string]bool{"PRESERVE_CASE": true}}))
+
+	// 警告不影响验证结果，但会出现在摘要中
+	manager := NewManager()
+	manager.CreateDefaultConfig("consistency")
+	cfg := manager.GetConfig()
+	cfg.Migration.Types = append(cfg.Migration.Types, "COPY", "INSERT")
+	result := validator.ValidateConfig(cfg)
+	assert.Len(t, result.Warnings, 1)
+	assert.Contains(t, validator.GetValidationSummary(result), "COPY 和 INSERT")
+}
+
+func TestCustomValidationRules(t *testing.T) {
+	dir := t.TempDir()
+	rulesPath := filepath.Join(dir, ValidationRulesFileName)
+	require.NoError(t, os.WriteFile(rulesPath, []byte(`rules:
+  - name: 并行度上限
+    field: migration.parallel_jobs
+    max: 8
+    message: 并行作业数不能超过8
+  - field: oracle.schema
+    required: true
+  - field: migration.types
+    contains: [TABLE, COPY]
+  - field: migration.options.DROP_FKEY
+    forbidden: true
+  - field: postgresql.host
+    pattern: '^pg-'
+    level: warning
+`), 0644))
+
+	rules, err := LoadCustomRules(rulesPath)
+	require.NoError(t, err)
+	require.Len(t, rules, 5)
+	assert.Equal(t, rulesPath, rules[0].Source)
+
+	manager := NewManager()
+	manager.CreateDefaultConfig("rules")
+	cfg := manager.GetConfig()
+	cfg.Migration.ParallelJobs = 16
+	cfg.Oracle.Schema = ""
+	cfg.Migration.Types = []string{"TABLE", "VIEW"}
+	cfg.Migration.Options = map[string]bool{"DROP_FKEY": true}
+	cfg.PostgreSQL.Host = "db01"
+
+	builtin := NewValidator().ValidateConfig(cfg)
+	validator := NewValidator()
+	validator.AddCustomRules(rules...)
+	result := validator.ValidateConfig(cfg)
+	assert.False(t, result.Valid)
+
+	custom := make(map[string]string)
+	for _, e := range result.Errors[len(builtin.Errors):] {
+		custom[e.Field] = e.Message
+	}
+	assert.Equal(t, map[string]string{
+		"migration.parallel_jobs":     "并行作业数不能超过8",
+		"oracle.schema":               "按配置规范必须配置该字段",
+		"migration.types":             "按配置规范必须包含 COPY",
+		"migration.options.DROP_FKEY": "按配置规范不允许配置该字段",
+	}, custom)
+	require.Len(t, result.Warnings, len(builtin.Warnings)+1)
+	assert.Equal(t, "postgresql.host", result.Warnings[len(result.Warnings)-1].Field)
+
+	// 满足规范时不产生额外的问题
+	cfg.Migration.ParallelJobs = 4
+	cfg.Oracle.Schema = "HR"
+	cfg.Migration.Types = []string{"table", "copy"}
+	cfg.Migration.Options = map[string]bool{"DROP_FKEY": false}
+	cfg.PostgreSQL.Host = "pg-01"
+	builtin = NewValidator().ValidateConfig(cfg)
+	result = validator.ValidateConfig(cfg)
+	assert.Equal(t, builtin.Errors, result.Errors)
+	assert.Equal(t, builtin.Warnings, result.Warnings)
+
+	// 代码中构造的规则同样生效
+	validator = NewValidator()
+	validator.AddCustomRules(CustomRule{Field: "migration.output_dir", Enum: []string{"output"}})
+	cfg.Migration.OutputDir = "dump"
+	result = validator.ValidateConfig(cfg)
+	require.NotEmpty(t, result.Errors)
+	assert.Equal(t, "migration.output_dir", result.Errors[len(result.Errors)-1].Field)
+}
+
+func TestCustomValidationRulesErrors(t *testing.T) {
+	dir := t.TempDir()
+	for content, expected := range map[string]string{
+		"rules:\n  - field: migration.parallel_jobs\n":                    "至少需要一种约束",
+		"rules:\n  - field: unknown.key\n    required: true\n":           "不在配置中",
+		"rules:\n  - field: oracle.host\n    pattern: '('\n":             "正则表达式无效",
+		"rules:\n  - field: oracle.host\n    required: true\n    level: x": "level 只能是",
+		"rules:\n  - field: migration.parallel_jobs\n    min: 9\n    max: 1": "min 不能大于 max",
+		"rules: [":                                                       "解析自定义校验规则",
+	} {
+		path := filepath.Join(dir, ValidationRulesFileName)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		_, err := LoadCustomRules(path)
+		if assert.Error(t, err, content) {
+			assert.Contains(t, err.Error(), expected)
+		}
+	}
+
+	// 用户级和项目级规则都不存在时不加载任何规则
+	t.Setenv("HOME", dir)
+	t.Setenv("USERPROFILE", dir)
+	project := t.TempDir()
+	rules, err := LoadDefaultCustomRules(project)
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".ora2pg-admin"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".ora2pg-admin", ValidationRulesFileName),
+		[]byte("rules:\n  - field: oracle.schema\n    required: true\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(project, ".ora2pg-admin"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, ".ora2pg-admin", ValidationRulesFileName),
+		[]byte("rules:\n  - field: migration.parallel_jobs\n    max: 8\n"), 0644))
+	rules, err = LoadDefaultCustomRules(project)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, "oracle.schema", rules[0].Field)
+	assert.Equal(t, "migration.parallel_jobs", rules[1].Field)
+}
+
+func TestWatchConfigReload(t *testing.T) {
+	project := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	configPath := filepath.Join(project, ProjectConfigDir, "config.yaml")
+
+	writer := NewManager()
+	writer.CreateDefaultConfig("监听项目")
+	require.NoError(t, writer.SaveConfig(configPath))
+
+	manager := NewManager()
+	require.NoError(t, manager.LoadConfig(configPath))
+	original := manager.GetConfig()
+
+	events := make(chan *ReloadEvent, 4)
+	watcher, err := manager.Watch(func(event *ReloadEvent) { events <- event })
+	require.NoError(t, err)
+	defer watcher.Close()
+
+	waitEvent := func() *ReloadEvent {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("没有收到配置重载事件")
+			return nil
+		}
+	}
+
+	// 连续多次保存只重载一次，已取得的旧配置不受影响
+	writer.GetConfig().Migration.ParallelJobs = 6
+	require.NoError(t, writer.SaveConfig(""))
+	writer.GetConfig().Migration.ParallelJobs = 8
+	require.NoError(t, writer.SaveConfig(""))
+	event := waitEvent()
+	require.True(t, event.Reloaded(), "%v", event.Err)
+	assert.Equal(t, 8, manager.GetConfig().Migration.ParallelJobs)
+	assert.Equal(t, 8, event.Config.Migration.ParallelJobs)
+	assert.Equal(t, 4, original.Migration.ParallelJobs)
+	select {
+	case extra := <-events:
+		t.Fatalf("防抖后仍收到多余的重载事件: %+v", extra)
+	case <-time.After(2 * ConfigReloadDebounce):
+	}
+
+	// 校验失败或无法解析时保留原配置
+	writer.GetConfig().Oracle.Host = ""
+	require.NoError(t, writer.SaveConfig(""))
+	event = waitEvent()
+	assert.False(t, event.Reloaded())
+	require.NotNil(t, event.Validation)
+	assert.NotEmpty(t, event.Validation.Errors)
+	assert.Equal(t, 8, manager.GetConfig().Migration.ParallelJobs)
+	assert.NotEmpty(t, manager.GetConfig().Oracle.Host)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("project: ["), 0644))
+	event = waitEvent()
+	assert.False(t, event.Reloaded())
+	assert.Nil(t, event.Validation)
+	assert.Same(t, manager.GetConfig(), event.Config)
+
+	require.NoError(t, watcher.Close())
+	require.NoError(t, watcher.Close())
+}
+
+func TestTableFilterPatterns(t *testing.T) {
+	migration := &MigrationConfig{
+		AllowTables:     []string{"tmp_keep", "HR.EMPLOYEES", "HR.TMP_LOG", "app.Orders"},
+		AllowPatterns:   []string{"^ORD", "_HIST$"},
+		ExcludePatterns: []string{"^tmp_", "_BAK$"},
+	}
+	assert.True(t, migration.HasTablePatterns())
+	filter, err := migration.TableFilter()
+	require.NoError(t, err)
+
+	// exclude 优先于 allow_tables 和 allow_patterns，匹配不区分大小写
+	result := filter.Filter("app", []string{"ORDERS", "ORDER_ITEMS", "ORDERS_BAK", "SALES_HIST", "TMP_KEEP", "CUSTOMERS"})
+	assert.Equal(t, []string{"HR.EMPLOYEES", "ORDERS", "ORDER_ITEMS", "SALES_HIST"}, result.Selected)
+	assert.Equal(t, []string{"ORDERS_BAK", "TMP_KEEP"}, result.Excluded)
+	assert.False(t, result.All)
+
+	// 只有排除规则时选中其余全部表，没有排除任何表时不需要 ALLOW
+	excludeOnly := &MigrationConfig{ExcludePatterns: []string{"^TMP_", "_BAK$"}}
+	filter, err = excludeOnly.TableFilter()
+	require.NoError(t, err)
+	result = filter.Filter("APP", []string{"ORDERS", "TMP_A", "LOG_BAK"})
+	assert.Equal(t, []string{"ORDERS"}, result.Selected)
+	assert.False(t, result.All)
+	assert.True(t, filter.Filter("APP", []string{"ORDERS", "CUSTOMERS"}).All)
+
+	// 大量表只需各匹配一次合并后的表达式
+	tables := make([]string, 20000)
+	for i := range tables {
+		tables[i] = fmt.Sprintf("T_%05d", i)
+		if i%4 == 0 {
+			tables[i] += "_BAK"
+		}
+	}
+	result = filter.Filter("APP", tables)
+	assert.Len(t, result.Selected, 15000)
+	assert.Len(t, result.Excluded, 5000)
+
+	_, err = (&MigrationConfig{AllowPatterns: []string{"("}}).TableFilter()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "migration.allow_patterns[0]")
+}
+
+func TestValidateTablePatterns(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("表筛选")
+	cfg := manager.GetConfig()
+	cfg.Migration.AllowTables = []string{"ORDERS", "TMP_ORDERS"}
+	cfg.Migration.ExcludePatterns = []string{"^TMP_"}
+
+	result := NewValidator().ValidateConfig(cfg)
+	assert.True(t, result.Valid)
+	require.NotEmpty(t, result.Warnings)
+	assert.Equal(t, "migration.allow_tables[1]", result.Warnings[0].Field)
+
+	cfg.Migration.AllowPatterns = []string{"[A-Z", " "}
+	result = NewValidator().ValidateConfig(cfg)
+	assert.False(t, result.Valid)
+	fields := make([]string, 0, len(result.Errors))
+	for _, validationErr := range result.Errors {
+		fields = append(fields, validationErr.Field)
+	}
+	assert.Equal(t, []string{"migration.allow_patterns[0]", "migration.allow_patterns[1]"}, fields)
+}
+
+func TestOracleTCPSConnection(t *testing.T) {
+	oracle := &OracleConfig{Host: "db", Port: 1521, Service: "ORCLPDB"}
+	assert.Equal(t, "db:1521/ORCLPDB", oracle.ConnectDescriptor())
+	assert.Equal(t, "dbi:Oracle:host=db;service_name=ORCLPDB;port=1521", oracle.DBIDSN())
+	assert.Nil(t, oracle.ConnectionEnvironment())
+
+	oracle = &OracleConfig{Host: "db", Port: 2484, Service: "ORCLPDB", UseTCPS: true,
+		WalletDir: "/opt/wallet", SSLServerCertDN: "CN=db,O=Example"}
+	descriptor := "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCPS)(HOST=db)(PORT=2484))(CONNECT_DATA=(SERVICE_NAME=ORCLPDB))" +
+		"(SECURITY=(SSL_SERVER_DN_MATCH=ON)(SSL_SERVER_CERT_DN=\"CN=db,O=Example\")(MY_WALLET_DIRECTORY=/opt/wallet)))"
+	assert.Equal(t, descriptor, oracle.ConnectDescriptor())
+	assert.Equal(t, "dbi:Oracle:"+descriptor, oracle.DBIDSN())
+	assert.Equal(t, map[string]string{"TNS_ADMIN": "/opt/wallet"}, oracle.ConnectionEnvironment())
+
+	// 只校验证书链时不校验DN，SID 连接使用 CONNECT_DATA=(SID=...)
+	oracle = &OracleConfig{Host: "db", Port: 2484, SID: "ORCL", UseTCPS: true, TNSAdmin: "/etc/tns", SSLVerify: "ca"}
+	assert.Equal(t, "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCPS)(HOST=db)(PORT=2484))(CONNECT_DATA=(SID=ORCL))"+
+		"(SECURITY=(SSL_SERVER_DN_MATCH=OFF)))", oracle.ConnectDescriptor())
+	assert.Equal(t, map[string]string{"TNS_ADMIN": "/etc/tns"}, oracle.ConnectionEnvironment())
+}
+
+func TestValidateOracleTCPS(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("TCPS")
+	cfg := manager.GetConfig()
+	cfg.Oracle.UseTCPS = true
+
+	fields := func(items []ValidationError) []string {
+		result := make([]string, 0, len(items))
+		for _, item := range items {
+			result = append(result, item.Field)
+		}
+		return result
+	}
+	warningFields := func(items []ValidationWarning) []string {
+		result := make([]string, 0, len(items))
+		for _, item := range items {
+			result = append(result, item.Field)
+		}
+		return result
+	}
+
+	// 没有 wallet，端口仍为明文端口
+	result := NewValidator().ValidateConfig(cfg)
+	assert.False(t, result.Valid)
+	assert.Contains(t, fields(result.Errors), "oracle.wallet_dir")
+	assert.Contains(t, warningFields(result.Warnings), "oracle.port")
+
+	wallet := t.TempDir()
+	cfg.Oracle.WalletDir = wallet
+	cfg.Oracle.Port = DefaultOracleTCPSPort
+	result = NewValidator().ValidateConfig(cfg)
+	assert.True(t, result.Valid)
+	assert.Contains(t, warningFields(result.Warnings), "oracle.wallet_dir")
+
+	require.NoError(t, os.WriteFile(filepath.Join(wallet, "cwallet.sso"), []byte("wallet"), 0600))
+	cfg.Oracle.SSLVerify = "strict"
+	result = NewValidator().ValidateConfig(cfg)
+	assert.Equal(t, []string{"oracle.ssl_verify"}, fields(result.Errors))
+	assert.NotContains(t, warningFields(result.Warnings), "oracle.wallet_dir")
+}
+
+func TestMigrationGroupPlan(t *testing.T) {
+	migration := &MigrationConfig{
+		Groups: map[string][]string{
+			"用户": {"USERS"},
+			"订单": {"ORDERS", "ORDER_ITEMS"},
+			"库存": {"STOCK"},
+			"报表": {"REPORTS"},
+		},
+		GroupDependencies: map[string][]string{
+			"订单": {"用户", "库存"},
+			"报表": {"订单"},
+		},
+	}
+
+	// 依赖排在前面，含间接依赖
+	plan, external, err := migration.GroupPlan([]string{"报表"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"用户", "库存", "订单", "报表"}, plan)
+	assert.Empty(t, external)
+
+	// 不带依赖时只排列指定的模块，并返回计划外的直接依赖
+	plan, external, err = migration.GroupPlan([]string{"报表", "用户"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"用户", "报表"}, plan)
+	assert.Equal(t, []string{"订单"}, external)
+
+	_, _, err = migration.GroupPlan([]string{"财务"}, false)
+	assert.Error(t, err)
+
+	// 只迁移模块中的对象，排除规则仍然生效
+	migration.AllowTables = []string{"OTHER"}
+	migration.AllowPatterns = []string{"^ORD"}
+	migration.ExcludePatterns = []string{"_BAK$"}
+	scoped, err := migration.ForGroup("订单")
+	require.NoError(t, err)
+	assert.Equal(t, "ORDERS ORDER_ITEMS", scoped.AllowDirective())
+	assert.Empty(t, scoped.AllowPatterns)
+	assert.Equal(t, []string{"_BAK$"}, scoped.ExcludePatterns)
+	assert.Equal(t, []string{"OTHER"}, migration.AllowTables)
+
+	// 循环依赖
+	migration.GroupDependencies["用户"] = []string{"报表"}
+	_, _, err = migration.GroupPlan([]string{"订单"}, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "循环")
+}
+
+func TestValidateGroups(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("模块")
+	cfg := manager.GetConfig()
+
+	fields := func(items []ValidationError) []string {
+		result := make([]string, 0, len(items))
+		for _, item := range items {
+			result = append(result, item.Field)
+		}
+		return result
+	}
+
+	cfg.Migration.Groups = map[string][]string{
+		"用户": {"USERS", "SHARED"},
+		"订单": {"ORDERS", "shared"},
+	}
+	cfg.Migration.GroupDependencies = map[string][]string{"订单": {"用户"}}
+	result := NewValidator().ValidateConfig(cfg)
+	assert.True(t, result.Valid)
+	require.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0].Message, "同时属于模块")
+
+	cfg.Migration.Groups["订单"] = []string{"ORDERS", "bad name"}
+	cfg.Migration.Groups["空"] = nil
+	cfg.Migration.GroupDependencies["财务"] = []string{"用户"}
+	result = NewValidator().ValidateConfig(cfg)
+	assert.ElementsMatch(t, []string{"migration.groups.订单[1]", "migration.groups.空", "migration.group_dependencies.财务"}, fields(result.Errors))
+
+	// 循环依赖
+	delete(cfg.Migration.Groups, "空")
+	delete(cfg.Migration.GroupDependencies, "财务")
+	cfg.Migration.Groups["订单"] = []string{"ORDERS"}
+	cfg.Migration.GroupDependencies["用户"] = []string{"订单"}
+	result = NewValidator().ValidateConfig(cfg)
+	assert.Equal(t, []string{"migration.group_dependencies"}, fields(result.Errors))
+}
+
+func TestValidateLogFilter(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("数据行过滤")
+	cfg := manager.GetConfig()
+
+	assert.Equal(t, LogFilterOff, cfg.Migration.LogFilter.EffectiveLevel())
+	assert.False(t, cfg.Migration.LogFilter.Enabled())
+
+	cfg.Migration.LogFilter = LogFilterConfig{Level: "Mask", DataPatterns: []string{`^ROW:`}}
+	result := NewValidator().ValidateConfig(cfg)
+	assert.True(t, result.Valid)
+	assert.True(t, cfg.Migration.LogFilter.Enabled())
+
+	cfg.Migration.LogFilter = LogFilterConfig{Level: "hide", DataPatterns: []string{"(", " "}}
+	result = NewValidator().ValidateConfig(cfg)
+	require.Len(t, result.Errors, 3)
+	assert.Equal(t, "migration.log_filter.level", result.Errors[0].Field)
+	assert.Equal(t, "migration.log_filter.data_patterns[0]", result.Errors[1].Field)
+	assert.Equal(t, "migration.log_filter.data_patterns[1]", result.Errors[2].Field)
+
+	// 未启用时自定义正则不生效
+	cfg.Migration.LogFilter = LogFilterConfig{DataPatterns: []string{`^ROW:`}}
+	result = NewValidator().ValidateConfig(cfg)
+	assert.True(t, result.Valid)
+	require.Len(t, result.Warnings, 1)
+	assert.Equal(t, "migration.log_filter.data_patterns", result.Warnings[0].Field)
+}
+
+func TestValidateBatching(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("分批迁移")
+	cfg := manager.GetConfig()
+	assert.False(t, cfg.Migration.Batching.Enabled())
+
+	cfg.Migration.Batching = BatchingConfig{
+		TableBuckets: 4,
+		Slices: []TableSliceConfig{
+			{Name: "orders", Column: "id", Boundaries: []string{"1000", "2000.5"}},
+			{Name: "APP.LOGS", Column: "CREATED_AT", Type: "Timestamp", Boundaries: []string{"2023-01-01", "2024-01-01 12:00:00"}},
+		},
+	}
+	result := NewValidator().ValidateConfig(cfg)
+	assert.True(t, result.Valid)
+	assert.True(t, cfg.Migration.Batching.Enabled())
+	assert.Equal(t, []string{"ORDERS", "APP.LOGS"}, cfg.Migration.Batching.SlicedTables())
+	boundaries, err := cfg.Migration.Batching.Slices[1].NormalizedBoundaries()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2023-01-01 00:00:00", "2024-01-01 12:00:00"}, boundaries)
+
+	cfg.Migration.Batching = BatchingConfig{
+		TableBuckets: 101,
+		Slices: []TableSliceConfig{
+			{Name: "ORDERS", Column: "ID", Boundaries: []string{"2000", "1000"}},
+			{Name: "orders", Column: "ID;", Boundaries: []string{"1"}},
+			{Name: "LOGS", Column: "CREATED_AT", Type: "date", Boundaries: []string{"2024-01-01"}},
+			{Name: "EVENTS", Column: "AT", Type: "timestamp", Boundaries: []string{"2024/01/01"}},
+			{Name: "ITEMS", Column: "ID"},
+		},
+	}
+	result = NewValidator().ValidateConfig(cfg)
+	require.Len(t, result.Errors, 7)
+	assert.Equal(t, "migration.batching.table_buckets", result.Errors[0].Field)
+	assert.Equal(t, "migration.batching.slices[0].boundaries", result.Errors[1].Field)
+	assert.Equal(t, "migration.batching.slices[1]", result.Errors[2].Field)
+	assert.Equal(t, "migration.batching.slices[1].column", result.Errors[3].Field)
+	assert.Equal(t, "migration.batching.slices[2].type", result.Errors[4].Field)
+	assert.Equal(t, "migration.batching.slices[3].boundaries", result.Errors[5].Field)
+	assert.Equal(t, "migration.batching.slices[4].boundaries", result.Errors[6].Field)
+}
+
+func TestMergeOra2pgFragments(t *testing.T) {
+	base := "# 开关\nDROP_INDEXES=0\nEXCLUDE AUDIT\nUSE_COPY=1\n"
+	fragments := []*Ora2pgFragment{
+		{Name: "performance", Description: "性能优化", Content: "# 说明: 性能优化\nDATA_LIMIT 20000\nDROP_INDEXES 1\nEXCLUDE TMP_A\n"},
+		{Name: "custom", Content: "DATA_LIMIT=5000\nEXCLUDE TMP_A TMP_B\nMODIFY_TYPE ORDERS:NOTE:text\n"},
+	}
+
+	content, directives, err := MergeOra2pgFragments(base, fragments)
+	require.NoError(t, err)
+	assert.Contains(t, content, "# DROP_INDEXES 由配置片段 performance 设置，见文件末尾\n")
+	assert.Contains(t, content, "# EXCLUDE 由配置片段 custom 设置，见文件末尾\n")
+	assert.Contains(t, content, "USE_COPY=1\n")
+	assert.Contains(t, content, "# 配置片段（migration.fragments: performance, custom）\n")
+	assert.Contains(t, content, "\n# performance: 性能优化\nDROP_INDEXES 1\n")
+	assert.Contains(t, content, "\n# custom\nDATA_LIMIT 5000\nEXCLUDE AUDIT TMP_A TMP_B\nMODIFY_TYPE ORDERS:NOTE:text\n")
+	assert.NotContains(t, content, "DATA_LIMIT 20000")
+
+	require.Len(t, directives, 4)
+	assert.Equal(t, MergedDirective{Name: "DROP_INDEXES", Value: "1", Fragment: "performance", Overridden: []string{"模板"}}, directives[0])
+	assert.Equal(t, MergedDirective{Name: "DATA_LIMIT", Value: "5000", Fragment: "custom", Overridden: []string{"performance"}}, directives[1])
+	assert.Equal(t, "EXCLUDE", directives[2].Name)
+	assert.Empty(t, directives[2].Overridden)
+
+	// 没有片段时原样返回
+	content, directives, err = MergeOra2pgFragments(base, nil)
+	require.NoError(t, err)
+	assert.Equal(t, base, content)
+	assert.Empty(t, directives)
+
+	// 由项目配置生成的指令和重复的指令
+	_, _, err = MergeOra2pgFragments(base, []*Ora2pgFragment{{Name: "bad", Content: "PG_PWD secret\n"}})
+	assert.ErrorContains(t, err, "postgresql.password")
+	_, _, err = MergeOra2pgFragments(base, []*Ora2pgFragment{{Name: "bad", Content: "DATA_LIMIT 1\ndata_limit 2\n"}})
+	assert.ErrorContains(t, err, "重复设置指令 DATA_LIMIT")
+	_, _, err = MergeOra2pgFragments(base, []*Ora2pgFragment{{Name: "bad", Content: "-- not a directive\n"}})
+	assert.ErrorContains(t, err, "第 1 行")
+}
+
+func TestResolveOra2pgFragment(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	project := t.TempDir()
+
+	fragment, err := ResolveOra2pgFragment(project, "lob")
+	require.NoError(t, err)
+	assert.Equal(t, FragmentSourceBuiltin, fragment.Source)
+	assert.NotEmpty(t, fragment.Description)
+
+	// 用户级和项目级片段覆盖内置片段，项目级优先
+	userDir := filepath.Join(home, ".ora2pg-admin", FragmentsDirName)
+	projectDir := filepath.Join(project, ".ora2pg-admin", FragmentsDirName)
+	require.NoError(t, os.MkdirAll(userDir, 0755))
+	require.NoError(t, os.MkdirAll(projectDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(userDir, "lob.conf"), []byte("LONGREADLEN 1024\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(userDir, "公司规范.conf"), []byte("# 说明: 公司规范\nDATA_LIMIT 1000\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(projectDir, "公司规范.conf"), []byte("# 说明：项目规范\nDATA_LIMIT {{.BatchSize}}\n"), 0644))
+
+	fragment, err = ResolveOra2pgFragment(project, "lob")
+	require.NoError(t, err)
+	assert.Equal(t, FragmentSourceUser, fragment.Source)
+	fragment, err = ResolveOra2pgFragment(project, "公司规范")
+	require.NoError(t, err)
+	assert.Equal(t, FragmentSourceProject, fragment.Source)
+	assert.Equal(t, "项目规范", fragment.Description)
+	rendered, err := fragment.Render(map[string]interface{}{"BatchSize": 500})
+	require.NoError(t, err)
+	assert.Equal(t, "# 说明：项目规范\nDATA_LIMIT 500\n", rendered.Content)
+	_, err = fragment.Render(map[string]interface{}{})
+	assert.Error(t, err)
+
+	_, err = ResolveOra2pgFragment(project, "../secret")
+	assert.ErrorContains(t, err, "无效的片段名称")
+	_, err = ResolveOra2pgFragment(project, "missing")
+	assert.ErrorContains(t, err, "未找到配置片段 missing")
+
+	fragments, err := ListOra2pgFragments(project)
+	require.NoError(t, err)
+	require.Len(t, fragments, len(builtinFragments)+1)
+	sources := make(map[string]string)
+	for _, fragment := range fragments {
+		sources[fragment.Name] = fragment.Source
+	}
+	assert.Equal(t, FragmentSourceUser, sources["lob"])
+	assert.Equal(t, FragmentSourceProject, sources["公司规范"])
+	assert.Equal(t, FragmentSourceBuiltin, sources["performance"])
+}
+
+func TestOra2pgConfigWithFragments(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	manager := NewManager()
+	manager.CreateDefaultConfig("配置片段")
+	cfg := manager.GetConfig()
+	cfg.Migration.Options = map[string]bool{"DROP_INDEXES": false}
+	cfg.Migration.Fragments = []string{"performance", "lob"}
+
+	result := NewValidator().ValidateConfig(cfg)
+	assert.True(t, result.Valid)
+	require.Len(t, result.Warnings, 1)
+	assert.Equal(t, "migration.fragments[0]", result.Warnings[0].Field)
+	assert.Contains(t, result.Warnings[0].Message, "DROP_INDEXES")
+
+	outputPath := filepath.Join(t.TempDir(), "ora2pg.conf")
+	require.NoError(t, NewTemplateEngine(filepath.Join("..", "..", "templates")).GenerateOra2pgConfig(cfg, outputPath))
+	content, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "DROP_INDEXES=0")
+	assert.Contains(t, string(content), "# DROP_INDEXES 由配置片段 performance 设置")
+	assert.Contains(t, string(content), "DROP_INDEXES 1\n")
+	assert.Contains(t, string(content), "LONGREADLEN 10485760\n")
+
+	cfg.Migration.Fragments = []string{"lob", "lob", "missing"}
+	result = NewValidator().ValidateConfig(cfg)
+	require.Len(t, result.Errors, 2)
+	assert.Equal(t, "migration.fragments[1]", result.Errors[0].Field)
+	assert.Equal(t, "migration.fragments[2]", result.Errors[1].Field)
+	err = NewTemplateEngine(filepath.Join("..", "..", "templates")).GenerateOra2pgConfig(cfg, outputPath)
+	assert.Equal(t, "FRAGMENT_NOT_FOUND", utils.GetErrorCode(err))
+}
+
+func TestChatBotConfig(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("群机器人")
+	cfg := manager.GetConfig()
+	validator := NewValidator()
+
+	cfg.Notifications.Bots = []ChatBotConfig{
+		{Platform: "DingTalk", URL: "https://oapi.dingtalk.com/robot/send?access_token=abc", Secret: "SEC1",
+			Events: []string{"start", "failure"}, Mentions: []string{"13800000000"}},
+		{Name: "开发群", Platform: ChatBotWeCom, URL: "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=k"},
+	}
+	assert.True(t, validator.ValidateConfig(cfg).Valid)
+	bot := &cfg.Notifications.Bots[0]
+	assert.Equal(t, "dingtalk", bot.DisplayName())
+	assert.Equal(t, "开发群", cfg.Notifications.Bots[1].DisplayName())
+	assert.True(t, bot.NotifyOn(NotificationEventStart))
+	assert.False(t, bot.NotifyOn(NotificationEventSuccess))
+	assert.True(t, cfg.Notifications.Bots[1].NotifyOn(NotificationEventSuccess))
+	assert.Equal(t, DefaultWebhookRetries, bot.RetryCount())
+	assert.Equal(t, DefaultWebhookTimeout, bot.RequestTimeout())
+
+	// 加签密钥参与加密，共享模板中去掉整个机器人配置，去除密码时不影响原配置
+	assert.Contains(t, secretFields(cfg), &bot.Secret)
+	assert.Empty(t, SanitizeForSharing(cfg).Notifications.Bots)
+	stripped := StripSecrets(cfg)
+	assert.Empty(t, stripped.Notifications.Bots[0].Secret)
+	assert.Equal(t, "SEC1", bot.Secret)
+
+	tooMany := 11
+	cfg.Notifications.Bots = append(cfg.Notifications.Bots,
+		ChatBotConfig{Platform: "teams", URL: "https://example.com"},
+		ChatBotConfig{Platform: ChatBotSlack, URL: "hooks.slack.com", Secret: "x", Events: []string{"progress"}, Retries: &tooMany, Timeout: "soon"})
+	result := validator.ValidateConfig(cfg)
+	assert.False(t, result.Valid)
+	require.Len(t, result.Errors, 6)
+	assert.Equal(t, "notifications.bots[2].platform", result.Errors[0].Field)
+	assert.Equal(t, "notifications.bots[3].secret", result.Errors[1].Field)
+}
+
+func TestEnvOverrideFields(t *testing.T) {
+	fields := make(map[string]EnvOverrideField)
+	for _, field := range EnvOverrideFields() {
+		_, duplicated := fields[field.Env]
+		assert.False(t, duplicated, "环境变量名重复: %s", field.Env)
+		fields[field.Env] = field
+	}
+
+	assert.Equal(t, "oracle.host", fields["ORA2PG_ADMIN_ORACLE_HOST"].Path)
+	assert.Equal(t, EnvOverrideInt, fields["ORA2PG_ADMIN_MIGRATION_PARALLEL_JOBS"].Type)
+	assert.Equal(t, EnvOverrideList, fields["ORA2PG_ADMIN_MIGRATION_TYPES"].Type)
+	assert.Equal(t, EnvOverrideBool, fields["ORA2PG_ADMIN_MIGRATION_DEFER_CONSTRAINTS"].Type)
+	assert.Equal(t, EnvOverrideInt, fields["ORA2PG_ADMIN_NOTIFICATIONS_WEBHOOK_RETRIES"].Type)
+	assert.Equal(t, EnvOverrideFloat, fields["ORA2PG_ADMIN_NOTIFICATIONS_WEBHOOK_PERCENT_STEP"].Type)
+	// map、对象列表和时间不能覆盖，工具自身使用的环境变量不与配置项冲突
+	for _, name := range []string{"ORA2PG_ADMIN_ENVIRONMENT", "ORA2PG_ADMIN_MIGRATION_LARGE_TABLES",
+		"ORA2PG_ADMIN_PROJECT_CREATED", MasterPasswordEnv, utils.RunIDEnv} {
+		assert.NotContains(t, fields, name)
+	}
+}
+
+func TestEnvOverridePriority(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	manager := NewManager()
+	manager.CreateDefaultConfig("覆盖")
+	cfg := manager.GetConfig()
+	cfg.Oracle.Host = "file-host"
+	cfg.Oracle.Port = 1521
+	cfg.Oracle.Password = "${TEST_OVERRIDE_ORACLE_PASSWORD}"
+	cfg.PostgreSQL.Password = "${TEST_OVERRIDE_PG_PASSWORD}"
+	require.NoError(t, manager.SaveConfig(configPath))
+
+	t.Setenv("ORA2PG_ADMIN_ORACLE_HOST", "env-host")
+	t.Setenv("ORA2PG_ADMIN_ORACLE_PORT", " 1522 ")
+	t.Setenv("ORA2PG_ADMIN_ORACLE_USE_TCPS", "true")
+	t.Setenv("ORA2PG_ADMIN_MIGRATION_TYPES", "TABLE, COPY,")
+	t.Setenv("ORA2PG_ADMIN_NOTIFICATIONS_WEBHOOK_RETRIES", "0")
+	t.Setenv("ORA2PG_ADMIN_MIGRATION_OUTPUT_DIR", "")
+	// 占位符和覆盖变量同时存在时覆盖变量优先，且覆盖的值不再展开
+	t.Setenv("TEST_OVERRIDE_ORACLE_PASSWORD", "from-placeholder")
+	t.Setenv("TEST_OVERRIDE_PG_PASSWORD", "pg-placeholder")
+	t.Setenv("ORA2PG_ADMIN_ORACLE_PASSWORD", "${TEST_OVERRIDE_PG_PASSWORD}")
+
+	loaded := NewManager()
+	require.NoError(t, loaded.LoadConfig(configPath))
+	cfg = loaded.GetConfig()
+	assert.Equal(t, "env-host", cfg.Oracle.Host)
+	assert.Equal(t, 1522, cfg.Oracle.Port)
+	assert.True(t, cfg.Oracle.UseTCPS)
+	assert.Equal(t, []string{"TABLE", "COPY"}, cfg.Migration.Types)
+	require.NotNil(t, cfg.Notifications.Webhook.Retries)
+	assert.Equal(t, 0, cfg.Notifications.Webhook.RetryCount())
+	assert.Equal(t, "${TEST_OVERRIDE_PG_PASSWORD}", cfg.Oracle.Password)
+	assert.Equal(t, "pg-placeholder", cfg.PostgreSQL.Password)
+	// 空值视为未设置，使用配置文件中的值；配置文件中没有的项使用默认值
+	assert.Equal(t, "output", cfg.Migration.OutputDir)
+	assert.Equal(t, DefaultWebhookTimeout, cfg.Notifications.Webhook.RequestTimeout())
+
+	overrides := loaded.EnvOverrides()
+	require.Len(t, overrides, 6)
+	assert.Equal(t, "oracle.host", overrides[0].Path)
+	assert.Equal(t, "env-host", overrides[0].DisplayValue())
+	for _, override := range overrides {
+		if override.Path == "oracle.password" {
+			assert.True(t, override.Secret)
+			assert.NotContains(t, override.DisplayValue(), "TEST_OVERRIDE")
+		}
+		if override.Path == "migration.types" {
+			assert.Equal(t, "TABLE, COPY", override.DisplayValue())
+		}
+	}
+
+	// 保存时写入配置文件中的原值，加载后修改过的项按修改后的值保存
+	cfg.Oracle.Port = 1600
+	require.NoError(t, loaded.SaveConfig(""))
+	assert.Equal(t, "env-host", cfg.Oracle.Host)
+	saved, err := ReadConfigFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "file-host", saved.Oracle.Host)
+	assert.Equal(t, 1600, saved.Oracle.Port)
+	assert.False(t, saved.Oracle.UseTCPS)
+	assert.Nil(t, saved.Notifications.Webhook.Retries)
+	assert.Equal(t, "${TEST_OVERRIDE_ORACLE_PASSWORD}", saved.Oracle.Password)
+
+	// 无法转换类型时加载失败
+	t.Setenv("ORA2PG_ADMIN_MIGRATION_PARALLEL_JOBS", "many")
+	err = NewManager().LoadConfig(configPath)
+	require.Error(t, err)
+	assert.Equal(t, "CONFIG_ENV_OVERRIDE_INVALID", utils.GetErrorCode(err))
+	assert.Contains(t, utils.FormatError(err), "migration.parallel_jobs 需要整数")
+}
+
+func TestRunEnvironments(t *testing.T) {
+	environments := RunEnvironmentsConfig{}
+	env, err := environments.Resolve("")
+	require.NoError(t, err)
+	assert.Nil(t, env)
+	_, err = environments.Resolve("staging")
+	assert.Error(t, err)
+
+	// 生产环境默认限制并行度、备份并确认危险操作
+	prod, err := environments.Resolve(" PROD ")
+	require.NoError(t, err)
+	assert.Equal(t, RunEnvProd, prod.Name)
+	assert.Equal(t, "prod（生产）", prod.DisplayName())
+	assert.Equal(t, DefaultProdMaxParallelJobs, prod.MaxParallelJobs)
+	assert.True(t, prod.Backup)
+	assert.True(t, prod.ConfirmDangerous)
+	test, err := environments.Resolve(RunEnvTest)
+	require.NoError(t, err)
+	assert.Zero(t, test.MaxParallelJobs)
+	assert.False(t, test.Backup)
+	assert.False(t, test.ConfirmDangerous)
+
+	// 命令行参数超过上限时报错，配置文件的值超过上限时降到上限
+	jobs, capped, err := prod.ParallelJobsFor(8, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 4, jobs)
+	assert.True(t, capped)
+	jobs, capped, err = prod.ParallelJobsFor(8, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, jobs)
+	assert.False(t, capped)
+	_, _, err = prod.ParallelJobsFor(2, 8)
+	assert.Error(t, err)
+	jobs, _, err = test.ParallelJobsFor(8, 16)
+	require.NoError(t, err)
+	assert.Equal(t, 16, jobs)
+
+	// 未指定 --env 时使用 default，策略中的值覆盖内置默认值
+	limit := 0
+	environments = RunEnvironmentsConfig{
+		Default: RunEnvProd,
+		Test:    RunEnvironmentPolicy{ParallelJobs: 8, PostgreSQLHosts: []string{"pg-test"}},
+		Prod:    RunEnvironmentPolicy{ParallelJobs: 6, MaxParallelJobs: &limit, PostgreSQLHosts: []string{"PG-PROD"}},
+	}
+	prod, err = environments.Resolve("")
+	require.NoError(t, err)
+	assert.Equal(t, RunEnvProd, prod.Name)
+	assert.Zero(t, prod.MaxParallelJobs)
+	jobs, _, err = prod.ParallelJobsFor(2, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 6, jobs)
+
+	// 目标库主机必须属于当前环境
+	assert.NoError(t, environments.CheckTarget(prod, "pg-prod"))
+	assert.ErrorContains(t, environments.CheckTarget(prod, "pg-test"), "属于 test 环境")
+	assert.ErrorContains(t, environments.CheckTarget(prod, "pg-other"), "不在 prod 环境")
+	test, err = environments.Resolve(RunEnvTest)
+	require.NoError(t, err)
+	assert.ErrorContains(t, environments.CheckTarget(test, "pg-prod"), "属于 prod 环境")
+
+	// 生产环境不能关闭备份和确认，两个环境的目标库不能重叠
+	disabled := false
+	manager := NewManager()
+	manager.CreateDefaultConfig("测试项目")
+	config := manager.GetConfig()
+	config.RunEnvironments = environments
+	validator := NewValidator()
+	result := validator.ValidateConfig(config)
+	assert.True(t, result.Valid, "%v", result.Errors)
+
+	config.RunEnvironments = RunEnvironmentsConfig{
+		Default: "staging",
+		Test:    RunEnvironmentPolicy{PostgreSQLHosts: []string{"pg-prod"}},
+		Prod:    RunEnvironmentPolicy{ParallelJobs: 8, Backup: &disabled, ConfirmDangerous: &disabled, PostgreSQLHosts: []string{"pg-prod"}},
+	}
+	result = validator.ValidateConfig(config)
+	assert.False(t, result.Valid)
+	var fields []string
+	for _, validationError := range result.Errors {
+		fields = append(fields, validationError.Field)
+	}
+	assert.Subset(t, fields, []string{
+		"run_environments.default",
+		"run_environments.prod.backup",
+		"run_environments.prod.confirm_dangerous",
+		"run_environments.prod.parallel_jobs",
+		"run_environments.test.postgresql_hosts",
+	})
+}
+
+func TestConfigBackups(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	backups, err := ListConfigBackups(configPath)
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+
+	// 同一秒内的多个备份按序号命名，超出保留数量时删除最老的
+	var created []*ConfigBackup
+	for i := 1; i <= 4; i++ {
+		require.NoError(t, os.WriteFile(configPath, []byte(fmt.Sprintf("project:\n  name: v%d\n", i)), 0644))
+		backup, err := BackupConfig(configPath, 3)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(filepath.Dir(configPath), ConfigBackupDirName, backup.Name), backup.Path)
+		assert.True(t, strings.HasPrefix(backup.Name, "config-"))
+		assert.True(t, strings.HasSuffix(backup.Name, ".yaml"))
+		created = append(created, backup)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(ConfigBackupDir(configPath), "notes.txt"), []byte("x"), 0644))
+
+	backups, err = ListConfigBackups(configPath)
+	require.NoError(t, err)
+	require.Len(t, backups, 3)
+	assert.Equal(t, created[3].Name, backups[0].Name)
+	assert.Equal(t, created[1].Name, backups[2].Name)
+	assert.NoFileExists(t, created[0].Path)
+	info, err := os.Stat(backups[0].Path)
+	require.NoError(t, err)
+	assert.Equal(t, backups[0].Size, info.Size())
+
+	// 按序号或文件名查找
+	found, err := FindConfigBackup(configPath, "3")
+	require.NoError(t, err)
+	assert.Equal(t, created[1].Name, found.Name)
+	found, err = FindConfigBackup(configPath, created[2].Path)
+	require.NoError(t, err)
+	assert.Equal(t, created[2].Name, found.Name)
+	_, err = FindConfigBackup(configPath, "9")
+	require.Error(t, err)
+	assert.Equal(t, "CONFIG_BACKUP_NOT_FOUND", utils.GetErrorCode(err))
+
+	// 恢复前备份当前配置，并且不会清理掉正在恢复的最老备份
+	oldest, err := FindConfigBackup(configPath, "3")
+	require.NoError(t, err)
+	current, err := RestoreConfigBackup(configPath, oldest, 3)
+	require.NoError(t, err)
+	require.NotNil(t, current)
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "name: v2")
+	data, err = os.ReadFile(current.Path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "name: v4")
+	backups, err = ListConfigBackups(configPath)
+	require.NoError(t, err)
+	require.Len(t, backups, 3)
+	assert.Equal(t, current.Name, backups[0].Name)
+
+	// 无法解析的备份不恢复
+	broken := filepath.Join(ConfigBackupDir(configPath), "config-20200101-000000.yaml")
+	require.NoError(t, os.WriteFile(broken, []byte("project: [unclosed"), 0600))
+	found, err = FindConfigBackup(configPath, filepath.Base(broken))
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local), found.Time)
+	_, err = RestoreConfigBackup(configPath, found, 0)
+	require.Error(t, err)
+	assert.Equal(t, "CONFIG_BACKUP_INVALID", utils.GetErrorCode(err))
+	data, err = os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "name: v2")
+}
+
+func TestValidateRemoteStorage(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("远程存储")
+	cfg := manager.GetConfig()
+
+	storage := &cfg.Migration.RemoteStorage
+	assert.False(t, storage.Enabled())
+	assert.Equal(t, DefaultRemoteConcurrency, storage.Workers())
+	assert.Equal(t, DefaultRemoteRetries, storage.RetryCount())
+	assert.Equal(t, DefaultRemoteTimeout, storage.UploadTimeout())
+	assert.False(t, storage.DeleteLocal())
+
+	cfg.Migration.RemoteStorage = RemoteStorageConfig{
+		Type: "S3", Path: "archive/hr", LocalFiles: "delete",
+		S3: S3StorageConfig{Endpoint: "https://s3.amazonaws.com", Bucket: "backups", AccessKeyID: "AKID", SecretAccessKey: "secret"},
+	}
+	result := NewValidator().ValidateConfig(cfg)
+	assert.True(t, result.Valid, "%v", result.Errors)
+	assert.Equal(t, RemoteStorageS3, storage.StorageType())
+	assert.True(t, storage.DeleteLocal())
+	assert.Equal(t, DefaultS3Region, storage.S3.SigningRegion())
+
+	retries := 20
+	cfg.Migration.RemoteStorage = RemoteStorageConfig{
+		Type: "s3", Path: "../escape", Exclude: []string{"["}, Retries: &retries, Timeout: "soon",
+		S3: S3StorageConfig{Endpoint: "s3.amazonaws.com"},
+	}
+	result = NewValidator().ValidateConfig(cfg)
+	var fields []string
+	for _, e := range result.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{
+		"migration.remote_storage.s3.endpoint",
+		"migration.remote_storage.s3.bucket",
+		"migration.remote_storage.s3",
+		"migration.remote_storage.path",
+		"migration.remote_storage.exclude[0]",
+		"migration.remote_storage.retries",
+		"migration.remote_storage.timeout",
+	}, fields)
+
+	cfg.Migration.RemoteStorage = RemoteStorageConfig{Type: "sftp", SFTP: SFTPStorageConfig{Host: "backup.local"}, LocalFiles: "move"}
+	result = NewValidator().ValidateConfig(cfg)
+	require.Len(t, result.Errors, 2)
+	assert.Equal(t, "migration.remote_storage.sftp.username", result.Errors[0].Field)
+	assert.Equal(t, "migration.remote_storage.local_files", result.Errors[1].Field)
+	assert.Equal(t, DefaultSFTPPort, cfg.Migration.RemoteStorage.SFTP.SFTPPort())
+
+	// 分享配置时清除存储凭据
+	cfg.Migration.RemoteStorage.S3.SecretAccessKey = "secret"
+	shared := SanitizeForSharing(cfg)
+	assert.Empty(t, shared.Migration.RemoteStorage.SFTP.Host)
+	assert.Empty(t, shared.Migration.RemoteStorage.S3.SecretAccessKey)
+}
+
+func TestPostgreSQLTargets(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	content := `project:
+  name: multi
+postgresql:
+  - name: dev
+    host: pg-dev
+    port: 5432
+    database: app
+    username: app
+    password: secret
+    schema: hr
+  - name: staging
+    host: pg-staging
+  - name: perf
+    host: pg-perf
+    port: 6432
+    password: other
+migration:
+  output_dir: output
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+
+	// postgresql 写成列表时第一项为主目标，其他目标未配置的连接项沿用主目标
+	manager := NewManager()
+	require.NoError(t, manager.LoadConfig(configPath))
+	cfg := manager.GetConfig()
+	assert.True(t, cfg.HasMultipleTargets())
+	assert.Equal(t, "pg-dev", cfg.PostgreSQL.Host)
+	targets := cfg.PostgreSQLTargets()
+	require.Len(t, targets, 3)
+	assert.Equal(t, "dev", targets[0].Name)
+	assert.Empty(t, targets[0].Targets)
+	assert.Equal(t, PostgreConfig{Name: "staging", Host: "pg-staging", Port: 5432, Database: "app", Username: "app", Password: "secret", Schema: "hr"}, targets[1])
+	assert.Equal(t, 6432, targets[2].Port)
+	assert.Equal(t, "other", targets[2].Password)
+	for _, e := range NewValidator().ValidateConfig(cfg).Errors {
+		assert.False(t, strings.HasPrefix(e.Field, "postgresql.targets"), e.Field)
+	}
+
+	selected, err := cfg.SelectTargets([]string{"PERF", "dev"})
+	require.NoError(t, err)
+	require.Len(t, selected, 2)
+	assert.Equal(t, "perf", selected[0].Name)
+	_, err = cfg.SelectTargets([]string{"prod"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dev, staging, perf")
+
+	staging := cfg.ForTarget(targets[1])
+	assert.Equal(t, "pg-staging", staging.PostgreSQL.Host)
+	assert.Equal(t, filepath.Join("output", "staging"), staging.Migration.OutputDir)
+	assert.Equal(t, "output", cfg.Migration.OutputDir)
+
+	// 保存为 targets 形式，其他目标的密码同样加密
+	manager.SetMasterPassword("master-pass")
+	manager.SetEncryption(true)
+	require.NoError(t, manager.SaveConfig(configPath))
+	saved, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(saved), "targets:")
+	assert.NotContains(t, string(saved), "other")
+	assert.Equal(t, "other", cfg.PostgreSQL.Targets[1].Password)
+
+	t.Setenv(MasterPasswordEnv, "master-pass")
+	loaded := NewManager()
+	require.NoError(t, loaded.LoadConfig(configPath))
+	require.Len(t, loaded.GetConfig().PostgreSQL.Targets, 2)
+	assert.Equal(t, "other", loaded.GetConfig().PostgreSQL.Targets[1].Password)
+	assert.Empty(t, StripSecrets(cfg).PostgreSQL.Targets[1].Password)
+	assert.Empty(t, SanitizeForSharing(cfg).PostgreSQL.Targets[0].Host)
+	assert.Equal(t, "pg-staging", cfg.PostgreSQL.Targets[0].Host)
+}
+
+func TestValidatePostgreSQLTargets(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("多目标")
+	cfg := manager.GetConfig()
+
+	// 未配置其他目标时主目标名称为 primary
+	assert.False(t, cfg.HasMultipleTargets())
+	assert.Equal(t, DefaultTargetName, cfg.PostgreSQLTargets()[0].Name)
+
+	cfg.PostgreSQL.Targets = []PostgreConfig{
+		{Host: "pg2"},
+		{Name: "bad name"},
+		{Name: "PRIMARY"},
+		{Name: "nested", Targets: []PostgreConfig{{Name: "inner"}}},
+		{Name: "port", Port: 70000},
+	}
+	result := NewValidator().ValidateConfig(cfg)
+	var fields []string
+	for _, e := range result.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{
+		"postgresql.targets[0].name",
+		"postgresql.targets[1].name",
+		"postgresql.targets[2].name",
+		"postgresql.targets[3].targets",
+		"postgresql.targets[4].port",
+	}, fields)
+}
+
+func TestAssertionExpectation(t *testing.T) {
+	expectation, err := ParseAssertionExpectation(">= 1000")
+	require.NoError(t, err)
+	assert.Equal(t, AssertionExpectation{Operator: ">=", Value: 1000}, expectation)
+	assert.True(t, expectation.Matches(1000))
+	assert.False(t, expectation.Matches(999))
+
+	expectation, err = ParseAssertionExpectation("<>0")
+	require.NoError(t, err)
+	assert.True(t, expectation.Matches(-1))
+	assert.Equal(t, "<> 0", expectation.String())
+
+	for _, expect := range []string{"", "0", "> abc", "~ 1"} {
+		_, err := ParseAssertionExpectation(expect)
+		assert.Error(t, err, expect)
+	}
+
+	// 只统计表行数时默认期望大于0
+	expectation, err = (&AssertionConfig{Table: "orders"}).Expectation()
+	require.NoError(t, err)
+	assert.Equal(t, "> 0", expectation.String())
+	_, err = (&AssertionConfig{Table: "orders", Condition: "amount < 0"}).Expectation()
+	assert.Error(t, err)
+}
+
+func TestValidateAssertions(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("断言")
+	cfg := manager.GetConfig()
+	cfg.Migration.Assertions = []AssertionConfig{
+		{Table: "orders"},
+		{Table: "users", Column: "email", Condition: "IS NULL", Expect: "= 0", OnFailure: AssertionOnFailureFail},
+		{SQL: "SELECT 1", Expect: "= 1"},
+	}
+	assert.True(t, NewValidator().ValidateConfig(cfg).Valid)
+
+	cfg.Migration.Assertions = []AssertionConfig{
+		{},
+		{Table: "orders", SQL: "SELECT 1", Expect: "= 1"},
+		{SQL: "SELECT 1", Condition: "x > 1", Expect: "= 1"},
+		{Table: "users", Column: "email", Expect: "= 0"},
+		{Table: "orders", Expect: "lots"},
+		{Table: "orders", OnFailure: "abort"},
+	}
+	result := NewValidator().ValidateConfig(cfg)
+	var fields []string
+	for _, e := range result.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{
+		"migration.assertions[0]",
+		"migration.assertions[1]",
+		"migration.assertions[2]",
+		"migration.assertions[3].condition",
+		"migration.assertions[4].expect",
+		"migration.assertions[5].on_failure",
+	}, fields)
+}
+
+func TestConfigAdvice(t *testing.T) {
+	assert.Nil(t, AdviseParallelJobs(8, 8))
+	assert.Contains(t, AdviseParallelJobs(64, 0).Message, "超过建议上限 32")
+	assert.Contains(t, AdviseParallelJobs(20, 8).Message, "超过本机CPU核心数 8 的2倍")
+	assert.Nil(t, AdviseParallelJobs(20, 0))
+
+	assert.Nil(t, AdviseBatchSize(1000))
+	assert.Contains(t, AdviseBatchSize(10).Message, "过小")
+	assert.Contains(t, AdviseBatchSize(500000).Message, "过大")
+
+	assert.Nil(t, AdviseOraclePort(DefaultOraclePort, false))
+	assert.Contains(t, AdviseOraclePort(DefaultOraclePort, true).Message, "启用了 TCPS")
+	assert.Contains(t, AdviseOraclePort(DefaultPostgresPort, false).Message, "PostgreSQL的默认端口")
+	assert.Nil(t, AdvisePostgresPort(DefaultPostgresPort))
+	assert.Equal(t, "postgresql.port", AdvisePostgresPort(DefaultOraclePort).Field)
+
+	assert.NotNil(t, AdviseOracleUsername(" system "))
+	assert.Nil(t, AdviseOracleUsername("MIGRATOR"))
+	assert.NotNil(t, AdvisePostgresUsername("postgres"))
+	assert.Nil(t, AdvisePostgresUsername("app_owner"))
+}
+
+func TestValidateConfigAdvice(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("合理性")
+	cfg := manager.GetConfig()
+
+	// 超出建议范围只警告，不影响验证结果
+	cfg.Migration.ParallelJobs = 64
+	cfg.Migration.BatchSize = 10
+	cfg.PostgreSQL.Port = DefaultOraclePort
+	result := NewValidator().ValidateConfig(cfg)
+	assert.True(t, result.Valid)
+	var fields []string
+	for _, warning := range result.Warnings {
+		fields = append(fields, warning.Field)
+	}
+	assert.Contains(t, fields, "migration.parallel_jobs")
+	assert.Contains(t, fields, "migration.batch_size")
+	assert.Contains(t, fields, "postgresql.port")
+
+	cfg.Migration.ParallelJobs = 0
+	assert.False(t, NewValidator().ValidateConfig(cfg).Valid)
+}
+
+func TestToolVersionConstraint(t *testing.T) {
+	version, ok := ParseToolVersion("Version 19.3.0.0.0")
+	require.True(t, ok)
+	assert.Equal(t, "19.3.0.0.0", version.String())
+	version, ok = ParseToolVersion("Ora2Pg v24.1")
+	require.True(t, ok)
+	assert.Equal(t, "24.1", version.String())
+	_, ok = ParseToolVersion("unknown")
+	assert.False(t, ok)
+
+	match := func(constraint, version string) bool {
+		t.Helper()
+		parsed, err := ParseVersionConstraint(constraint)
+		require.NoError(t, err, constraint)
+		v, ok := ParseToolVersion(version)
+		require.True(t, ok)
+		return parsed.Match(v)
+	}
+	// 不带比较符时按前缀匹配
+	assert.True(t, match("24.1", "24.1"))
+	assert.True(t, match("24.1", "24.1.3"))
+	assert.True(t, match("24.0", "24"))
+	assert.False(t, match("24.1", "24.10"))
+	assert.True(t, match("19.x", "19.3.0.0.0"))
+	assert.False(t, match("=19.*", "21.3"))
+	// 多个条件同时满足，缺少的段按0比较
+	assert.True(t, match(">=23.2, <25", "24.1"))
+	assert.False(t, match(">=23.2, <25", "25.0"))
+	assert.True(t, match(">= 16 < 17", "16.2"))
+	assert.False(t, match(">23.2", "23.2.0"))
+	assert.True(t, match("!=23.1", "23.2"))
+
+	for _, invalid := range []string{"", "latest", ">=24.x", "24.1-beta", ">="} {
+		_, err := ParseVersionConstraint(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestValidateToolVersions(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("工具版本")
+	cfg := manager.GetConfig()
+
+	cfg.Tools = ToolVersionsConfig{Ora2pg: " 24.1 ", PSQL: ">=16"}
+	assert.True(t, NewValidator().ValidateConfig(cfg).Valid)
+	assert.Equal(t, []ToolConstraint{{"ora2pg", "24.1"}, {"psql", ">=16"}}, cfg.Tools.Constraints())
+
+	cfg.Tools.SQLPlus = "newest"
+	result := NewValidator().ValidateConfig(cfg)
+	assert.False(t, result.Valid)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "tools.sqlplus", result.Errors[0].Field)
+
+	cfg.Tools = ToolVersionsConfig{Enforce: true}
+	result = NewValidator().ValidateConfig(cfg)
+	assert.True(t, result.Valid)
+	var fields []string
+	for _, warning := range result.Warnings {
+		fields = append(fields, warning.Field)
+	}
+	assert.Contains(t, fields, "tools.enforce")
+}
+
+func TestValidateSQLCheck(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("语法校验")
+	cfg := manager.GetConfig()
+
+	assert.Equal(t, DefaultSQLCheckJobs, cfg.Migration.SQLCheck.ParallelJobs())
+	cfg.Migration.SQLCheck = SQLCheckConfig{Enabled: true, Database: "sqlcheck", Jobs: 8}
+	assert.True(t, NewValidator().ValidateConfig(cfg).Valid)
+	assert.Equal(t, 8, cfg.Migration.SQLCheck.ParallelJobs())
+
+	cfg.Migration.SQLCheck = SQLCheckConfig{Database: " sqlcheck", Jobs: -1}
+	result := NewValidator().ValidateConfig(cfg)
+	assert.False(t, result.Valid)
+	var fields []string
+	for _, e := range result.Errors {
+		fields = append(fields, e.Field)
+	}
+	assert.Equal(t, []string{"migration.sql_check.jobs", "migration.sql_check.database"}, fields)
+}
+
+func TestValidateMigrationTypes(t *testing.T) {
+	manager := NewManager()
+	manager.CreateDefaultConfig("迁移类型")
+	cfg := manager.GetConfig()
+
+	cfg.Migration.Types = []string{"TABLE", "mview", "DBLINK", "Synonym"}
+	assert.True(t, NewValidator().ValidateConfig(cfg).Valid)
+
+	cfg.Migration.Types = []string{"TABLE", "DB_LINK"}
+	result := NewValidator().ValidateConfig(cfg)
+	assert.False(t, result.Valid)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "migration.types", result.Errors[0].Field)
+	assert.Contains(t, result.Errors[0].Message, "DB_LINK")
+}
+
+func TestConfigInterpolation(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	manager := NewManager()
+	manager.CreateDefaultConfig("hr")
+	cfg := manager.GetConfig()
+	cfg.Migration.OutputDir = "output/{{.Project.Name}}/{{.Date}}"
+	// 引用的字段本身是模板时先展开被引用的字段
+	cfg.Oracle.Schema = "{{upper .PostgreSQL.Schema}}"
+	cfg.PostgreSQL.Schema = "app_{{.Project.Name}}"
+	cfg.Migration.Types = []string{"TABLE", "{{.Env.TEST_INTERPOLATION_TYPE}}"}
+	// 敏感字段不展开
+	cfg.Oracle.Password = "p{{w}}d"
+	require.NoError(t, manager.SaveConfig(configPath))
+
+	previous := interpolationNow
+	interpolationNow = func() time.Time { return time.Date(2026, 10, 14, 9, 30, 0, 0, time.Local) }
+	defer func() { interpolationNow = previous }()
+	t.Setenv("TEST_INTERPOLATION_TYPE", "COPY")
+	t.Setenv("ORA2PG_ADMIN_MIGRATION_LOG_LEVEL", "{{if eq .Project.Name \"hr\"}}DEBUG{{end}}")
+
+	loaded := NewManager()
+	require.NoError(t, loaded.LoadConfig(configPath))
+	cfg = loaded.GetConfig()
+	assert.Equal(t, "output/hr/20261014", cfg.Migration.OutputDir)
+	assert.Equal(t, "APP_HR", cfg.Oracle.Schema)
+	assert.Equal(t, "app_hr", cfg.PostgreSQL.Schema)
+	assert.Equal(t, []string{"TABLE", "COPY"}, cfg.Migration.Types)
+	assert.Equal(t, "p{{w}}d", cfg.Oracle.Password)
+	// 覆盖值同样展开
+	assert.Equal(t, "DEBUG", cfg.Migration.LogLevel)
+
+	interpolations := loaded.Interpolations()
+	require.Len(t, interpolations, 5)
+	assert.Equal(t, "postgresql.schema", interpolations[0].Path)
+	assert.Equal(t, "oracle.schema", interpolations[1].Path)
+	for _, interpolation := range interpolations {
+		if interpolation.Path == "migration.types" {
+			assert.Equal(t, "TABLE, {{.Env.TEST_INTERPOLATION_TYPE}}", interpolation.Raw())
+			assert.Equal(t, "TABLE, COPY", interpolation.Expanded())
+		}
+	}
+
+	// 保存时写回模板原文，加载后修改过的字段按修改后的值保存
+	cfg.PostgreSQL.Schema = "public"
+	require.NoError(t, loaded.SaveConfig(""))
+	assert.Equal(t, "output/hr/20261014", cfg.Migration.OutputDir)
+	saved, err := ReadConfigFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "output/{{.Project.Name}}/{{.Date}}", saved.Migration.OutputDir)
+	assert.Equal(t, "{{upper .PostgreSQL.Schema}}", saved.Oracle.Schema)
+	assert.Equal(t, "public", saved.PostgreSQL.Schema)
+	assert.Equal(t, "INFO", saved.Migration.LogLevel)
+	assert.Equal(t, []string{"TABLE", "{{.Env.TEST_INTERPOLATION_TYPE}}"}, saved.Migration.Types)
+}
+
+func TestConfigInterpolationSkipsNotificationTemplates(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	manager := NewManager()
+	manager.CreateDefaultConfig("hr")
+	email := &manager.GetConfig().Notifications.Email
+	// 用户指南中的邮件通知示例，字段在发送时才有值
+	email.Subject = "{{.Project}} {{.Task}}{{.StatusText}}（{{.Succeeded}}/{{.Total}}）"
+	email.Link = "https://ci.example.com/runs/{{.RunID}}"
+	require.NoError(t, manager.SaveConfig(configPath))
+
+	loaded := NewManager()
+	require.NoError(t, loaded.LoadConfig(configPath))
+	assert.Equal(t, email.Subject, loaded.GetConfig().Notifications.Email.Subject)
+	assert.Equal(t, email.Link, loaded.GetConfig().Notifications.Email.Link)
+	assert.Empty(t, loaded.Interpolations())
+}
+
+func TestConfigInterpolationErrors(t *testing.T) {
+	load := func(mutate func(cfg *ProjectConfig)) error {
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		manager := NewManager()
+		manager.CreateDefaultConfig("hr")
+		mutate(manager.GetConfig())
+		require.NoError(t, manager.SaveConfig(configPath))
+		return NewManager().LoadConfig(configPath)
+	}
+
+	err := load(func(cfg *ProjectConfig) {
+		cfg.Oracle.Host = "{{.PostgreSQL.Host}}"
+		cfg.PostgreSQL.Host = "db-{{.Migration.OutputDir}}"
+		cfg.Migration.OutputDir = "{{.Oracle.Host}}"
+	})
+	assert.Equal(t, "CONFIG_TEMPLATE_CYCLE", utils.GetErrorCode(err))
+	assert.Contains(t, utils.FormatError(err), "oracle.host → postgresql.host → migration.output_dir → oracle.host")
+
+	err = load(func(cfg *ProjectConfig) { cfg.Migration.OutputDir = "{{.Project.Name}}/{{.Project.Name}}{{.Migration.OutputDir}}" })
+	assert.Equal(t, "CONFIG_TEMPLATE_CYCLE", utils.GetErrorCode(err))
+
+	// 未知字段、未设置的环境变量和语法错误
+	for _, text := range []string{"{{.Project.Owner}}", "{{.Env.TEST_INTERPOLATION_MISSING}}", "{{.Project.Name"} {
+		err = load(func(cfg *ProjectConfig) { cfg.Migration.OutputDir = text })
+		assert.Equal(t, "CONFIG_TEMPLATE_INVALID", utils.GetErrorCode(err), text)
+		assert.Contains(t, utils.FormatError(err), "migration.output_dir", text)
+	}
+}
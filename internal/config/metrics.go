@@ -0,0 +1,27 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// MetricsConfig 迁移指标导出配置
+type MetricsConfig struct {
+	// Textfile Prometheus textfile collector 读取的指标文件（相对于项目根目录），为空时不导出
+	Textfile string `yaml:"textfile,omitempty" json:"textfile,omitempty"`
+}
+
+// Enabled 是否导出指标文件
+func (c *MetricsConfig) Enabled() bool {
+	return strings.TrimSpace(c.Textfile) != ""
+}
+
+// validateMetrics 验证指标导出配置，textfile collector 只读取 .prom 扩展名的文件
+func (v *Validator) validateMetrics(metrics *MetricsConfig, result *ValidationResult) {
+	if !metrics.Enabled() {
+		return
+	}
+	if filepath.Ext(strings.TrimSpace(metrics.Textfile)) != ".prom" {
+		result.AddError("metrics.textfile", "指标文件必须以 .prom 为扩展名，否则 textfile collector 不会读取")
+	}
+}
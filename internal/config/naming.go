@@ -0,0 +1,218 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// 目标库对象名的大小写规则
+const (
+	NamingCaseLower    = "lower"    // 转为小写（ora2pg默认行为）
+	NamingCasePreserve = "preserve" // 保留Oracle中的大小写（PRESERVE_CASE）
+)
+
+// maxPostgresIdentifierLength PostgreSQL标识符的最大字节数，超出部分会被截断
+const maxPostgresIdentifierLength = 63
+
+// namingAffixPattern 前缀和后缀只能包含字母、数字和下划线
+var namingAffixPattern = regexp.MustCompile(`^[A-Za-z0-9_]*$`)
+
+// namingExampleTables 预览命名规则时使用的示例表名
+var namingExampleTables = []string{"EMPLOYEES", "ORDER_ITEMS", "OrderHistory", "USER"}
+
+// postgresReservedWords PostgreSQL保留关键字，不加引号不能用作表名
+var postgresReservedWords = map[string]bool{}
+
+func init() {
+	for _, word := range strings.Fields(`all analyse analyze and any array as asc asymmetric authorization
+binary both case cast check collate collation column concurrently constraint create cross
+current_catalog current_date current_role current_schema current_time current_timestamp current_user
+default deferrable desc distinct do else end except false fetch for foreign freeze from full
+grant group having ilike in initially inner intersect into is isnull join lateral leading left like
+limit localtime localtimestamp natural not notnull null offset on only or order outer overlaps
+placing primary references returning right select session_user similar some symmetric system_user
+table tablesample then to trailing true union unique user using variadic verbose when where window with`) {
+		postgresReservedWords[word] = true
+	}
+}
+
+// IsPostgresReservedWord 名称是否为PostgreSQL保留关键字（不区分大小写）
+func IsPostgresReservedWord(name string) bool {
+	return postgresReservedWords[strings.ToLower(name)]
+}
+
+// NamingConvention 迁移到PostgreSQL时表名的转换规则
+//
+// 按顺序应用：驼峰转下划线（同时转为小写）→ 大小写 → 前缀、后缀。前缀、后缀和驼峰转换
+// 通过 REPLACE_TABLES 逐表生成，需要在生成ora2pg配置时查询源库表名；列名只受大小写规则影响。
+type NamingConvention struct {
+	// Case 大小写规则（lower、preserve），默认 lower
+	Case string `yaml:"case,omitempty" json:"case,omitempty"`
+	// SnakeCase 把 OrderHistory 这样的驼峰名称转为 order_history
+	SnakeCase bool `yaml:"snake_case,omitempty" json:"snake_case,omitempty"`
+	// Prefix 表名前缀，如 t_
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+	// Suffix 表名后缀，如 _tab
+	Suffix string `yaml:"suffix,omitempty" json:"suffix,omitempty"`
+}
+
+// NameMapping 一张表转换前后的名称
+type NameMapping struct {
+	Source string
+	Target string
+	// Reserved 转换后的名称是PostgreSQL保留字，需要加引号
+	Reserved bool
+}
+
+// Enabled 是否配置了命名规则
+func (n *NamingConvention) Enabled() bool {
+	return n.normalizedCase() != "" || n.RenamesTables()
+}
+
+// PreserveCase 是否保留Oracle中的大小写
+func (n *NamingConvention) PreserveCase() bool {
+	return n.normalizedCase() == NamingCasePreserve
+}
+
+// RenamesTables 是否需要逐表生成 REPLACE_TABLES
+func (n *NamingConvention) RenamesTables() bool {
+	return n.SnakeCase || n.Prefix != "" || n.Suffix != ""
+}
+
+// normalizedCase 小写形式的大小写规则
+func (n *NamingConvention) normalizedCase() string {
+	return strings.ToLower(strings.TrimSpace(n.Case))
+}
+
+// ConvertTable 按规则转换表名，preserveCase 为最终是否保留大小写（命名规则或 PRESERVE_CASE 开关）
+func (n *NamingConvention) ConvertTable(name string, preserveCase bool) string {
+	if n.SnakeCase {
+		name = toSnakeCase(name)
+	} else if !preserveCase {
+		name = strings.ToLower(name)
+	}
+	return n.Prefix + name + n.Suffix
+}
+
+// TableMappings 计算各表转换后的名称
+//
+// 转换后名称重复或超过PostgreSQL标识符长度时返回错误，避免ora2pg生成互相覆盖或被截断的表。
+func (n *NamingConvention) TableMappings(tables []string, preserveCase bool) ([]NameMapping, error) {
+	mappings := make([]NameMapping, 0, len(tables))
+	sources := make(map[string]string, len(tables))
+	for _, table := range tables {
+		target := n.ConvertTable(table, preserveCase)
+		if len(target) > maxPostgresIdentifierLength {
+			return nil, fmt.Errorf("表 %s 转换后的名称 %s 超过 %d 字节，PostgreSQL会截断该名称", table, target, maxPostgresIdentifierLength)
+		}
+		// 不加引号的名称在PostgreSQL中不区分大小写，按小写判断冲突
+		key := target
+		if !preserveCase {
+			key = strings.ToLower(target)
+		}
+		if other, exists := sources[key]; exists {
+			return nil, fmt.Errorf("表 %s 和 %s 转换后的名称都是 %s", other, table, target)
+		}
+		sources[key] = table
+		mappings = append(mappings, NameMapping{Source: table, Target: target, Reserved: IsPostgresReservedWord(target)})
+	}
+	return mappings, nil
+}
+
+// Examples 用示例表名展示转换前后的对照
+func (n *NamingConvention) Examples(preserveCase bool) []NameMapping {
+	mappings := make([]NameMapping, 0, len(namingExampleTables))
+	for _, table := range namingExampleTables {
+		target := n.ConvertTable(table, preserveCase)
+		mappings = append(mappings, NameMapping{Source: table, Target: target, Reserved: IsPostgresReservedWord(target)})
+	}
+	return mappings
+}
+
+// Describe 命名规则的简短说明
+func (n *NamingConvention) Describe() string {
+	parts := []string{"小写"}
+	if n.PreserveCase() {
+		parts[0] = "保留大小写"
+	}
+	if n.SnakeCase {
+		parts[0] = "驼峰转下划线"
+	}
+	if n.Prefix != "" {
+		parts = append(parts, "前缀 "+n.Prefix)
+	}
+	if n.Suffix != "" {
+		parts = append(parts, "后缀 "+n.Suffix)
+	}
+	return strings.Join(parts, "，")
+}
+
+// toSnakeCase 驼峰名称转为小写下划线形式，如 OrderHistory → order_history、HTTPLog → http_log；
+// 已是全大写或下划线分隔的名称只转为小写
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var builder strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && runes[i-1] != '_' {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				builder.WriteRune('_')
+			}
+		}
+		builder.WriteRune(unicode.ToLower(r))
+	}
+	return builder.String()
+}
+
+// PreserveCase 目标库是否保留Oracle对象名的大小写（命名规则 case: preserve 或 PRESERVE_CASE 开关）
+func (m *MigrationConfig) PreserveCase() bool {
+	if m.NamingConvention.PreserveCase() {
+		return true
+	}
+	for _, sw := range ResolveOra2pgSwitches(m.Options) {
+		if sw.Name == "PRESERVE_CASE" {
+			return sw.Enabled
+		}
+	}
+	return false
+}
+
+// TargetTableName 把ora2pg输出或源库中的Oracle表名转换为目标库表名，去掉模式前缀并应用命名规则
+func (m *MigrationConfig) TargetTableName(oracleName string) string {
+	name := strings.TrimSpace(oracleName)
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+	return m.NamingConvention.ConvertTable(name, m.PreserveCase())
+}
+
+// validateNamingConvention 验证命名规则
+func (v *Validator) validateNamingConvention(migration *MigrationConfig, result *ValidationResult) {
+	naming := &migration.NamingConvention
+	switch naming.normalizedCase() {
+	case "", NamingCaseLower, NamingCasePreserve:
+	default:
+		result.AddError("migration.naming_convention.case", "大小写规则只支持 lower、preserve")
+	}
+	if naming.SnakeCase && naming.PreserveCase() {
+		result.AddError("migration.naming_convention.snake_case", "驼峰转下划线会把名称转为小写，不能与 case: preserve 同时使用")
+	}
+	if naming.normalizedCase() != "" {
+		for name, enabled := range migration.Options {
+			if strings.EqualFold(strings.TrimSpace(name), "PRESERVE_CASE") && enabled != naming.PreserveCase() {
+				result.AddError("migration.naming_convention.case", "与 migration.options 中的 PRESERVE_CASE 冲突，请只保留一处配置")
+			}
+		}
+	}
+	if !namingAffixPattern.MatchString(naming.Prefix) {
+		result.AddError("migration.naming_convention.prefix", "表名前缀只能包含字母、数字和下划线")
+	} else if naming.Prefix != "" && unicode.IsDigit(rune(naming.Prefix[0])) {
+		result.AddError("migration.naming_convention.prefix", "表名前缀不能以数字开头")
+	}
+	if !namingAffixPattern.MatchString(naming.Suffix) {
+		result.AddError("migration.naming_convention.suffix", "表名后缀只能包含字母、数字和下划线")
+	}
+}
@@ -0,0 +1,303 @@
+package config
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// 通知内容格式
+const (
+	NotificationFormatText = "text"
+	NotificationFormatHTML = "html"
+)
+
+// 触发通知的迁移结果
+const (
+	NotificationEventSuccess = "success"
+	NotificationEventFailure = "failure" // 包括失败和取消
+	NotificationEventStart   = "start"   // 迁移开始，仅群机器人支持
+)
+
+// 群机器人平台
+const (
+	ChatBotDingTalk = "dingtalk"
+	ChatBotWeCom    = "wecom"
+	ChatBotFeishu   = "feishu"
+	ChatBotSlack    = "slack"
+)
+
+// ChatBotMentionAll 群机器人 mentions 中表示@所有人的值
+const ChatBotMentionAll = "all"
+
+// 进度 webhook 的默认值
+const (
+	DefaultWebhookPercentStep = 10
+	DefaultWebhookMinInterval = 30 * time.Second
+	DefaultWebhookRetries     = 3
+	DefaultWebhookTimeout     = 10 * time.Second
+)
+
+// NotificationConfig 迁移结果通知配置
+type NotificationConfig struct {
+	Email   EmailNotificationConfig   `yaml:"email,omitempty" json:"email,omitempty"`
+	Webhook WebhookNotificationConfig `yaml:"webhook,omitempty" json:"webhook,omitempty"`
+	Bots    []ChatBotConfig           `yaml:"bots,omitempty" json:"bots,omitempty"`
+}
+
+// ChatBotConfig 群机器人配置：迁移开始和结束时向钉钉、企业微信、飞书或 Slack 群发送卡片消息
+type ChatBotConfig struct {
+	// Name 日志和错误信息中显示的名称，默认为平台名
+	Name     string `yaml:"name,omitempty" json:"name,omitempty"`
+	Platform string `yaml:"platform" json:"platform"`
+	// URL 机器人的 webhook 地址，其中的 access_token、key 等同样是凭据，建议以环境变量引用
+	URL string `yaml:"url" json:"url"`
+	// Secret 钉钉、飞书机器人的加签密钥，可加密保存；企业微信和 Slack 不支持加签
+	Secret string `yaml:"secret,omitempty" json:"secret,omitempty"`
+	// Events 发送消息的事件（start、success、failure），为空时都发送
+	Events []string `yaml:"events,omitempty" json:"events,omitempty"`
+	// Mentions 迁移失败时@的负责人，all 表示所有人：钉钉为手机号，企业微信为手机号或成员ID，飞书为 open_id，Slack 为成员ID
+	Mentions []string `yaml:"mentions,omitempty" json:"mentions,omitempty"`
+	// Retries 发送失败时的重试次数，默认 3
+	Retries *int `yaml:"retries,omitempty" json:"retries,omitempty"`
+	// Timeout 单次请求的超时时间，默认 10s
+	Timeout string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// DisplayName 机器人的显示名称
+func (c *ChatBotConfig) DisplayName() string {
+	if name := strings.TrimSpace(c.Name); name != "" {
+		return name
+	}
+	return strings.ToLower(strings.TrimSpace(c.Platform))
+}
+
+// PlatformName 小写的平台名
+func (c *ChatBotConfig) PlatformName() string {
+	return strings.ToLower(strings.TrimSpace(c.Platform))
+}
+
+// NotifyOn 是否需要在指定事件发送消息
+func (c *ChatBotConfig) NotifyOn(event string) bool {
+	return eventConfigured(c.Events, event)
+}
+
+// RetryCount 发送失败时的重试次数
+func (c *ChatBotConfig) RetryCount() int {
+	if c.Retries == nil {
+		return DefaultWebhookRetries
+	}
+	return *c.Retries
+}
+
+// RequestTimeout 单次请求的超时时间，未配置或无效时使用默认值
+func (c *ChatBotConfig) RequestTimeout() time.Duration {
+	return parseDurationOrDefault(c.Timeout, DefaultWebhookTimeout)
+}
+
+// WebhookNotificationConfig 迁移进度 webhook 配置：迁移过程中把当前进度以JSON POST到指定地址
+type WebhookNotificationConfig struct {
+	Enabled bool   `yaml:"enabled" json:"enabled"`
+	URL     string `yaml:"url" json:"url"`
+	// Token 以 Authorization: Bearer 请求头发送，可加密保存
+	Token string `yaml:"token,omitempty" json:"token,omitempty"`
+	// PercentStep 进度每增加多少个百分点推送一次，默认 10；迁移类型完成时总会推送
+	PercentStep float64 `yaml:"percent_step,omitempty" json:"percent_step,omitempty"`
+	// MinInterval 两次进度推送的最小间隔，如 30s，默认 30s
+	MinInterval string `yaml:"min_interval,omitempty" json:"min_interval,omitempty"`
+	// Retries 推送失败时的重试次数，默认 3
+	Retries *int `yaml:"retries,omitempty" json:"retries,omitempty"`
+	// Timeout 单次请求的超时时间，默认 10s
+	Timeout string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// Step 推送进度的百分点间隔
+func (c *WebhookNotificationConfig) Step() float64 {
+	if c.PercentStep <= 0 {
+		return DefaultWebhookPercentStep
+	}
+	return c.PercentStep
+}
+
+// Interval 两次进度推送的最小间隔，未配置或无效时使用默认值
+func (c *WebhookNotificationConfig) Interval() time.Duration {
+	return parseDurationOrDefault(c.MinInterval, DefaultWebhookMinInterval)
+}
+
+// RetryCount 推送失败时的重试次数
+func (c *WebhookNotificationConfig) RetryCount() int {
+	if c.Retries == nil {
+		return DefaultWebhookRetries
+	}
+	return *c.Retries
+}
+
+// RequestTimeout 单次请求的超时时间，未配置或无效时使用默认值
+func (c *WebhookNotificationConfig) RequestTimeout() time.Duration {
+	return parseDurationOrDefault(c.Timeout, DefaultWebhookTimeout)
+}
+
+// parseDurationOrDefault 解析时长，为空、无效或不为正时返回默认值
+func parseDurationOrDefault(value string, defaultValue time.Duration) time.Duration {
+	duration, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || duration <= 0 {
+		return defaultValue
+	}
+	return duration
+}
+
+// EmailNotificationConfig 邮件通知配置
+type EmailNotificationConfig struct {
+	Enabled  bool     `yaml:"enabled" json:"enabled"`
+	SMTPHost string   `yaml:"smtp_host" json:"smtp_host"`
+	SMTPPort int      `yaml:"smtp_port" json:"smtp_port"`
+	Username string   `yaml:"username,omitempty" json:"username,omitempty"`
+	Password string   `yaml:"password,omitempty" json:"password,omitempty"`
+	From     string   `yaml:"from" json:"from"`
+	To       []string `yaml:"to" json:"to"`
+	// Events 触发通知的结果（success、failure），为空时成功和失败都通知
+	Events []string `yaml:"events,omitempty" json:"events,omitempty"`
+	// Format 邮件正文格式（text、html），默认 text
+	Format string `yaml:"format,omitempty" json:"format,omitempty"`
+	// Subject 邮件主题模板，为空时使用内置主题；发送时渲染，加载配置时不展开
+	Subject string `yaml:"subject,omitempty" json:"subject,omitempty" interpolate:"-"`
+	// Template 自定义正文模板文件（相对于项目根目录），为空时使用内置模板
+	Template string `yaml:"template,omitempty" json:"template,omitempty"`
+	// Link 报告链接模板，可引用运行ID，如 https://ci.example.com/runs/{{.RunID}}
+	Link string `yaml:"link,omitempty" json:"link,omitempty" interpolate:"-"`
+}
+
+// NotifyOn 是否需要通知指定结果
+func (c *EmailNotificationConfig) NotifyOn(event string) bool {
+	return eventConfigured(c.Events, event)
+}
+
+// eventConfigured 事件是否在配置的列表中，列表为空时所有事件都通知
+func eventConfigured(events []string, event string) bool {
+	if len(events) == 0 {
+		return true
+	}
+	for _, configured := range events {
+		if strings.EqualFold(strings.TrimSpace(configured), event) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsHTML 是否以HTML格式发送
+func (c *EmailNotificationConfig) IsHTML() bool {
+	return strings.EqualFold(strings.TrimSpace(c.Format), NotificationFormatHTML)
+}
+
+// validateNotifications 验证通知配置，未启用时不检查
+func (v *Validator) validateNotifications(notifications *NotificationConfig, result *ValidationResult) {
+	validateWebhook(&notifications.Webhook, result)
+	validateChatBots(notifications.Bots, result)
+
+	email := &notifications.Email
+	if !email.Enabled {
+		return
+	}
+
+	if strings.TrimSpace(email.SMTPHost) == "" {
+		result.AddError("notifications.email.smtp_host", "启用邮件通知时必须指定SMTP服务器")
+	}
+	if email.SMTPPort <= 0 || email.SMTPPort > 65535 {
+		result.AddError("notifications.email.smtp_port", "SMTP端口必须在 1-65535 之间")
+	}
+	if _, err := mail.ParseAddress(email.From); err != nil {
+		result.AddError("notifications.email.from", fmt.Sprintf("无效的发件人地址: %s", email.From))
+	}
+	if len(email.To) == 0 {
+		result.AddError("notifications.email.to", "至少需要一个收件人")
+	}
+	for i, to := range email.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			result.AddError(fmt.Sprintf("notifications.email.to[%d]", i), fmt.Sprintf("无效的收件人地址: %s", to))
+		}
+	}
+	for i, event := range email.Events {
+		switch strings.ToLower(strings.TrimSpace(event)) {
+		case NotificationEventSuccess, NotificationEventFailure:
+		default:
+			result.AddError(fmt.Sprintf("notifications.email.events[%d]", i), "通知事件只支持 success、failure")
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(email.Format)) {
+	case "", NotificationFormatText, NotificationFormatHTML:
+	default:
+		result.AddError("notifications.email.format", "邮件格式只支持 text、html")
+	}
+}
+
+// validateWebhook 验证进度 webhook 配置，未启用时不检查
+func validateWebhook(webhook *WebhookNotificationConfig, result *ValidationResult) {
+	if !webhook.Enabled {
+		return
+	}
+
+	if parsed, err := url.Parse(strings.TrimSpace(webhook.URL)); err != nil || parsed.Host == "" ||
+		(parsed.Scheme != "http" && parsed.Scheme != "https") {
+		result.AddError("notifications.webhook.url", fmt.Sprintf("无效的 webhook 地址: %s，需要 http:// 或 https:// 开头", webhook.URL))
+	}
+	if webhook.PercentStep < 0 || webhook.PercentStep > 100 {
+		result.AddError("notifications.webhook.percent_step", "推送间隔百分点必须在 0-100 之间")
+	}
+	if webhook.Retries != nil && (*webhook.Retries < 0 || *webhook.Retries > 10) {
+		result.AddError("notifications.webhook.retries", "重试次数必须在 0-10 之间")
+	}
+	durations := []struct{ field, value string }{
+		{"min_interval", webhook.MinInterval},
+		{"timeout", webhook.Timeout},
+	}
+	for _, item := range durations {
+		value := strings.TrimSpace(item.value)
+		if value == "" {
+			continue
+		}
+		if duration, err := time.ParseDuration(value); err != nil || duration <= 0 {
+			result.AddError("notifications.webhook."+item.field, fmt.Sprintf("无效的时长 %s，请使用 30s、1m 等格式", value))
+		}
+	}
+}
+
+// validateChatBots 验证群机器人配置
+func validateChatBots(bots []ChatBotConfig, result *ValidationResult) {
+	for i := range bots {
+		bot := &bots[i]
+		field := fmt.Sprintf("notifications.bots[%d]", i)
+
+		platform := bot.PlatformName()
+		switch platform {
+		case ChatBotDingTalk, ChatBotFeishu:
+		case ChatBotWeCom, ChatBotSlack:
+			if bot.Secret != "" {
+				result.AddError(field+".secret", fmt.Sprintf("%s 机器人不支持加签，请去掉 secret", platform))
+			}
+		default:
+			result.AddError(field+".platform", fmt.Sprintf("不支持的机器人平台: %s，支持 dingtalk、wecom、feishu、slack", bot.Platform))
+		}
+		if parsed, err := url.Parse(strings.TrimSpace(bot.URL)); err != nil || parsed.Host == "" ||
+			(parsed.Scheme != "http" && parsed.Scheme != "https") {
+			result.AddError(field+".url", "无效的机器人 webhook 地址，需要 http:// 或 https:// 开头")
+		}
+		for j, event := range bot.Events {
+			switch strings.ToLower(strings.TrimSpace(event)) {
+			case NotificationEventStart, NotificationEventSuccess, NotificationEventFailure:
+			default:
+				result.AddError(fmt.Sprintf("%s.events[%d]", field, j), "机器人通知事件只支持 start、success、failure")
+			}
+		}
+		if bot.Retries != nil && (*bot.Retries < 0 || *bot.Retries > 10) {
+			result.AddError(field+".retries", "重试次数必须在 0-10 之间")
+		}
+		if timeout := strings.TrimSpace(bot.Timeout); timeout != "" {
+			if duration, err := time.ParseDuration(timeout); err != nil || duration <= 0 {
+				result.AddError(field+".timeout", fmt.Sprintf("无效的时长 %s，请使用 10s、1m 等格式", timeout))
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// Ora2pgSwitch ora2pg常用布尔开关
+type Ora2pgSwitch struct {
+	Name        string
+	Description string
+	Default     bool
+}
+
+// Ora2pgSwitches 支持通过 migration.options 配置的ora2pg开关
+//
+// Default 为未配置时生成的值，除 DISABLE_TRIGGERS 外均与ora2pg自身默认值一致。
+var Ora2pgSwitches = []Ora2pgSwitch{
+	{Name: "DISABLE_TRIGGERS", Description: "导入数据期间禁用目标表触发器", Default: true},
+	{Name: "DISABLE_SEQUENCE", Description: "导入数据后不重置序列的当前值", Default: false},
+	{Name: "TRUNCATE_TABLE", Description: "导入数据前清空目标表", Default: false},
+	{Name: "DROP_FKEY", Description: "导入数据前删除外键，导入后重建", Default: false},
+	{Name: "DROP_INDEXES", Description: "导入数据前删除非主键索引，导入后重建", Default: false},
+	{Name: "NO_LOB_LOCATOR", Description: "不使用LOB定位器读取LOB（提速但占用更多内存）", Default: false},
+	{Name: "FILE_PER_TABLE", Description: "每张表的数据输出到单独的文件", Default: false},
+	{Name: "FILE_PER_INDEX", Description: "索引定义输出到单独的文件", Default: false},
+	{Name: "FILE_PER_CONSTRAINT", Description: "约束定义输出到单独的文件", Default: false},
+	{Name: "FILE_PER_FKEYS", Description: "外键定义输出到单独的文件", Default: false},
+	{Name: "PRESERVE_CASE", Description: "保留Oracle对象名的大小写", Default: false},
+	{Name: "USE_RESERVED_WORDS", Description: "自动为PostgreSQL保留字对象名加引号", Default: false},
+	{Name: "DISABLE_UNLOGGED", Description: "不把NOLOGGING表转换为UNLOGGED表", Default: false},
+	{Name: "DISABLE_COMMENT", Description: "不导出表和列的注释", Default: false},
+	{Name: "EXPORT_INVALID", Description: "同时导出状态为INVALID的对象", Default: false},
+	{Name: "STOP_ON_ERROR", Description: "导入出错时立即停止", Default: true},
+}
+
+// Ora2pgSwitchValue 开关的生效值
+type Ora2pgSwitchValue struct {
+	Name        string
+	Description string
+	Enabled     bool
+}
+
+// LookupOra2pgSwitch 按名称（不区分大小写）查找开关
+func LookupOra2pgSwitch(name string) (Ora2pgSwitch, bool) {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	for _, sw := range Ora2pgSwitches {
+		if sw.Name == name {
+			return sw, true
+		}
+	}
+	return Ora2pgSwitch{}, false
+}
+
+// ResolveOra2pgSwitches 合并配置与默认值，返回所有开关的生效值
+func ResolveOra2pgSwitches(options map[string]bool) []Ora2pgSwitchValue {
+	configured := make(map[string]bool, len(options))
+	for name, enabled := range options {
+		configured[strings.ToUpper(strings.TrimSpace(name))] = enabled
+	}
+
+	values := make([]Ora2pgSwitchValue, 0, len(Ora2pgSwitches))
+	for _, sw := range Ora2pgSwitches {
+		enabled := sw.Default
+		if value, exists := configured[sw.Name]; exists {
+			enabled = value
+		}
+		values = append(values, Ora2pgSwitchValue{Name: sw.Name, Description: sw.Description, Enabled: enabled})
+	}
+	return values
+}
+
+// ChangedOra2pgSwitches 返回与默认值不同的开关名称，用于展示
+func ChangedOra2pgSwitches(options map[string]bool) []string {
+	var changed []string
+	for _, value := range ResolveOra2pgSwitches(options) {
+		if sw, _ := LookupOra2pgSwitch(value.Name); value.Enabled != sw.Default {
+			state := "关闭"
+			if value.Enabled {
+				state = "开启"
+			}
+			changed = append(changed, value.Name+"="+state)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
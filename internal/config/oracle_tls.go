@@ -0,0 +1,154 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultOracleTCPSPort Oracle TCPS 监听的常用端口
+const DefaultOracleTCPSPort = 2484
+
+// DefaultOraclePort Oracle TCP 监听的默认端口
+const DefaultOraclePort = 1521
+
+// 服务器证书验证级别
+const (
+	// OracleSSLVerifyFull 校验证书链，并校验服务器证书DN与主机名或 ssl_server_cert_dn 一致（默认）
+	OracleSSLVerifyFull = "full"
+	// OracleSSLVerifyCA 只校验证书链是否由 wallet 中的CA签发，不校验DN，用于证书DN与连接地址不一致的场景
+	OracleSSLVerifyCA = "ca"
+)
+
+// walletFiles Oracle wallet 目录中的文件，自动登录 wallet 为 cwallet.sso
+var walletFiles = []string{"cwallet.sso", "ewallet.p12"}
+
+// SSLVerifyLevel 证书验证级别，未配置时为 full
+func (c *OracleConfig) SSLVerifyLevel() string {
+	if c.SSLVerify == "" {
+		return OracleSSLVerifyFull
+	}
+	return strings.ToLower(c.SSLVerify)
+}
+
+// ConnectDescriptor sqlplus、tnsping 使用的连接串
+//
+// TCP 连接使用 主机:端口/名称 的EZConnect方式；TCPS 连接使用完整的连接描述符，
+// 其中指定 wallet 目录和证书DN校验，不依赖 tnsnames.ora。
+func (c *OracleConfig) ConnectDescriptor() string {
+	name := c.Service
+	if name == "" {
+		name = c.SID
+	}
+	if !c.UseTCPS {
+		return fmt.Sprintf("%s:%d/%s", c.Host, c.Port, name)
+	}
+
+	connectData := fmt.Sprintf("(SERVICE_NAME=%s)", c.Service)
+	if c.Service == "" {
+		connectData = fmt.Sprintf("(SID=%s)", c.SID)
+	}
+	return fmt.Sprintf("(DESCRIPTION=(ADDRESS=(PROTOCOL=TCPS)(HOST=%s)(PORT=%d))(CONNECT_DATA=%s)%s)",
+		c.Host, c.Port, connectData, c.securityClause())
+}
+
+// securityClause TCPS 连接描述符的 SECURITY 部分
+func (c *OracleConfig) securityClause() string {
+	var security strings.Builder
+	if c.SSLVerifyLevel() == OracleSSLVerifyCA {
+		security.WriteString("(SSL_SERVER_DN_MATCH=OFF)")
+	} else {
+		security.WriteString("(SSL_SERVER_DN_MATCH=ON)")
+		if c.SSLServerCertDN != "" {
+			fmt.Fprintf(&security, "(SSL_SERVER_CERT_DN=\"%s\")", c.SSLServerCertDN)
+		}
+	}
+	if c.WalletDir != "" {
+		fmt.Fprintf(&security, "(MY_WALLET_DIRECTORY=%s)", c.WalletDir)
+	}
+	return "(SECURITY=" + security.String() + ")"
+}
+
+// DBIDSN ora2pg 使用的 DBD::Oracle 数据源，TCPS 连接使用完整的连接描述符
+func (c *OracleConfig) DBIDSN() string {
+	if c.UseTCPS {
+		return "dbi:Oracle:" + c.ConnectDescriptor()
+	}
+	if c.Service != "" {
+		return fmt.Sprintf("dbi:Oracle:host=%s;service_name=%s;port=%d", c.Host, c.Service, c.Port)
+	}
+	return fmt.Sprintf("dbi:Oracle:host=%s;sid=%s;port=%d", c.Host, c.SID, c.Port)
+}
+
+// TNSAdminDir TCPS 连接使用的 TNS_ADMIN 目录，配置了 tns_admin 时使用该目录，否则使用 wallet 目录
+func (c *OracleConfig) TNSAdminDir() string {
+	if !c.UseTCPS {
+		return ""
+	}
+	if c.TNSAdmin != "" {
+		return c.TNSAdmin
+	}
+	return c.WalletDir
+}
+
+// ConnectionEnvironment 连接源库额外需要的环境变量，TCPS 连接时设置 TNS_ADMIN
+//
+// 客户端从 TNS_ADMIN 中的 sqlnet.ora 读取 wallet 位置和加密套件等设置，
+// 云数据库下载的 wallet 压缩包中已包含 sqlnet.ora，解压后可直接作为 TNS_ADMIN。
+func (c *OracleConfig) ConnectionEnvironment() map[string]string {
+	dir := c.TNSAdminDir()
+	if dir == "" {
+		return nil
+	}
+	return map[string]string{"TNS_ADMIN": dir}
+}
+
+// validateOracleTLS 验证 TCPS 连接配置
+func (v *Validator) validateOracleTLS(oracle *OracleConfig, result *ValidationResult) {
+	if !oracle.UseTCPS {
+		if oracle.WalletDir != "" || oracle.SSLServerCertDN != "" {
+			result.AddWarning("oracle.use_tcps", "配置了 wallet 或证书DN，但未启用 TCPS，连接仍为明文",
+				"需要加密连接时设置 use_tcps: true")
+		}
+		return
+	}
+
+	switch oracle.SSLVerifyLevel() {
+	case OracleSSLVerifyFull, OracleSSLVerifyCA:
+	default:
+		result.AddError("oracle.ssl_verify", fmt.Sprintf("无效的证书验证级别 %q，可选 full、ca", oracle.SSLVerify))
+	}
+	if oracle.SSLVerifyLevel() == OracleSSLVerifyCA && oracle.SSLServerCertDN != "" {
+		result.AddWarning("oracle.ssl_server_cert_dn", "证书验证级别为 ca 时不校验服务器证书DN，ssl_server_cert_dn 不会生效",
+			"需要校验DN时设置 ssl_verify: full")
+	}
+
+	if oracle.WalletDir == "" && oracle.TNSAdmin == "" {
+		result.AddError("oracle.wallet_dir", "启用 TCPS 时必须配置 wallet_dir（存放信任证书的 wallet 目录）或 tns_admin")
+	}
+	if oracle.WalletDir != "" {
+		v.validateWalletDir(oracle.WalletDir, result)
+	}
+	if oracle.TNSAdmin != "" {
+		if info, err := os.Stat(oracle.TNSAdmin); err != nil || !info.IsDir() {
+			result.AddError("oracle.tns_admin", fmt.Sprintf("TNS_ADMIN 目录 %s 不存在", oracle.TNSAdmin))
+		}
+	}
+}
+
+// validateWalletDir 检查 wallet 目录是否存在且包含 wallet 文件
+func (v *Validator) validateWalletDir(dir string, result *ValidationResult) {
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		result.AddError("oracle.wallet_dir", fmt.Sprintf("wallet 目录 %s 不存在", dir))
+		return
+	}
+	for _, name := range walletFiles {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return
+		}
+	}
+	result.AddWarning("oracle.wallet_dir", fmt.Sprintf("wallet 目录 %s 中没有 %s", dir, strings.Join(walletFiles, " 或 ")),
+		"使用 orapki wallet create -auto_login 创建 wallet 并导入服务器CA证书")
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ProgressRuleConfig 自定义的ora2pg输出解析规则，匹配行后按捕获组更新进度
+//
+// Step、Message 中可用 $1、${name} 引用捕获组；Completed、Total、Rows、TotalRows 为捕获组序号，0表示不更新。
+type ProgressRuleConfig struct {
+	Name    string `yaml:"name" json:"name"`
+	Pattern string `yaml:"pattern" json:"pattern"`
+	// Priority 优先级，越大越先匹配；内置规则为 100-300，兜底的状态行规则为 -100
+	Priority  int    `yaml:"priority,omitempty" json:"priority,omitempty"`
+	Step      string `yaml:"step,omitempty" json:"step,omitempty"`
+	Message   string `yaml:"message,omitempty" json:"message,omitempty"`
+	Completed int    `yaml:"completed,omitempty" json:"completed,omitempty"`
+	Total     int    `yaml:"total,omitempty" json:"total,omitempty"`
+	Rows      int    `yaml:"rows,omitempty" json:"rows,omitempty"`
+	TotalRows int    `yaml:"total_rows,omitempty" json:"total_rows,omitempty"`
+}
+
+// Groups 规则引用的捕获组序号
+func (r *ProgressRuleConfig) Groups() map[string]int {
+	return map[string]int{
+		"completed":  r.Completed,
+		"total":      r.Total,
+		"rows":       r.Rows,
+		"total_rows": r.TotalRows,
+	}
+}
+
+// validateProgressRules 验证自定义输出解析规则
+func (v *Validator) validateProgressRules(migration *MigrationConfig, result *ValidationResult) {
+	names := make(map[string]bool, len(migration.ProgressRules))
+	for i := range migration.ProgressRules {
+		rule := &migration.ProgressRules[i]
+		field := fmt.Sprintf("migration.progress_rules[%d]", i)
+
+		name := strings.TrimSpace(rule.Name)
+		if name == "" {
+			result.AddError(field+".name", "规则名称不能为空")
+		} else if names[name] {
+			result.AddError(field+".name", fmt.Sprintf("规则名称 %s 重复", name))
+		}
+		names[name] = true
+
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil || rule.Pattern == "" {
+			result.AddError(field+".pattern", fmt.Sprintf("无效的正则表达式 %q", rule.Pattern))
+			continue
+		}
+		for key, group := range rule.Groups() {
+			if group < 0 || group > re.NumSubexp() {
+				result.AddError(field+"."+key, fmt.Sprintf("捕获组 %d 不存在，正则表达式共有 %d 个捕获组", group, re.NumSubexp()))
+			}
+		}
+		if rule.Step == "" && rule.Message == "" && rule.Completed == 0 && rule.Total == 0 && rule.Rows == 0 && rule.TotalRows == 0 {
+			result.AddError(field, "规则未指定要更新的进度字段（step、message、completed、total、rows、total_rows）")
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// 远程存储类型
+const (
+	RemoteStorageSFTP = "sftp"
+	RemoteStorageS3   = "s3"
+)
+
+// 上传后本地输出的处理方式
+const (
+	RemoteLocalKeep   = "keep"   // 保留本地文件
+	RemoteLocalDelete = "delete" // 全部上传成功且迁移全部成功后删除已上传的本地文件
+)
+
+// 远程存储上传的默认值
+const (
+	DefaultRemoteConcurrency = 4
+	DefaultRemoteRetries     = 3
+	DefaultRemoteTimeout     = 10 * time.Minute
+	DefaultSFTPPort          = 22
+	DefaultS3Region          = "us-east-1"
+)
+
+// RemoteStorageConfig 迁移完成后把输出目录上传到远程存储归档，type 为空时不上传
+type RemoteStorageConfig struct {
+	// Type 存储类型：sftp、s3（S3 兼容的对象存储，如 MinIO、OSS、COS）
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+	// Path 远程目录或对象键前缀，文件上传到 <path>/<运行ID>/ 下
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// Exclude 不上传的文件（相对于输出目录，支持通配符），含数据库密码的 ora2pg*.conf 总是不上传
+	Exclude []string `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+	// Concurrency 并发上传数，默认 4；SFTP 为同时打开的会话数
+	Concurrency int `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
+	// Retries 上传失败时的重试次数，默认 3
+	Retries *int `yaml:"retries,omitempty" json:"retries,omitempty"`
+	// Timeout 单个文件（SFTP 为一批文件）的上传超时时间，默认 10m
+	Timeout string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	// LocalFiles 上传后本地文件的处理：keep（默认）保留，delete 删除已上传的文件
+	LocalFiles string `yaml:"local_files,omitempty" json:"local_files,omitempty"`
+
+	SFTP SFTPStorageConfig `yaml:"sftp,omitempty" json:"sftp,omitempty"`
+	S3   S3StorageConfig   `yaml:"s3,omitempty" json:"s3,omitempty"`
+}
+
+// SFTPStorageConfig SFTP 服务器，通过系统的 sftp 命令以批处理模式上传，只支持密钥或 ssh-agent 认证
+type SFTPStorageConfig struct {
+	Host     string `yaml:"host,omitempty" json:"host,omitempty"`
+	Port     int    `yaml:"port,omitempty" json:"port,omitempty"`
+	Username string `yaml:"username,omitempty" json:"username,omitempty"`
+	// IdentityFile 私钥文件，为空时使用 ssh 的默认密钥和 ssh-agent
+	IdentityFile string `yaml:"identity_file,omitempty" json:"identity_file,omitempty"`
+	// KnownHostsFile 校验服务器主机密钥的 known_hosts 文件，为空时使用 ~/.ssh/known_hosts
+	KnownHostsFile string `yaml:"known_hosts_file,omitempty" json:"known_hosts_file,omitempty"`
+}
+
+// S3StorageConfig S3 兼容的对象存储，使用 AWS Signature V4 签名
+type S3StorageConfig struct {
+	// Endpoint 服务地址，如 https://s3.amazonaws.com、http://minio.local:9000
+	Endpoint string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+	// Region 签名使用的区域，默认 us-east-1
+	Region string `yaml:"region,omitempty" json:"region,omitempty"`
+	Bucket string `yaml:"bucket,omitempty" json:"bucket,omitempty"`
+	// AccessKeyID 访问密钥ID，建议以环境变量引用
+	AccessKeyID string `yaml:"access_key_id,omitempty" json:"access_key_id,omitempty"`
+	// SecretAccessKey 访问密钥，可加密保存或以环境变量引用
+	SecretAccessKey string `yaml:"secret_access_key,omitempty" json:"secret_access_key,omitempty"`
+	// PathStyle 使用 <endpoint>/<bucket>/<key> 形式的地址（MinIO 等需要），默认使用 <bucket>.<endpoint>/<key>
+	PathStyle bool `yaml:"path_style,omitempty" json:"path_style,omitempty"`
+}
+
+// Enabled 是否配置了远程存储
+func (c *RemoteStorageConfig) Enabled() bool {
+	return c.StorageType() != ""
+}
+
+// StorageType 小写的存储类型
+func (c *RemoteStorageConfig) StorageType() string {
+	return strings.ToLower(strings.TrimSpace(c.Type))
+}
+
+// Workers 并发上传数
+func (c *RemoteStorageConfig) Workers() int {
+	if c.Concurrency <= 0 {
+		return DefaultRemoteConcurrency
+	}
+	return c.Concurrency
+}
+
+// RetryCount 上传失败时的重试次数
+func (c *RemoteStorageConfig) RetryCount() int {
+	if c.Retries == nil {
+		return DefaultRemoteRetries
+	}
+	return *c.Retries
+}
+
+// UploadTimeout 单次上传的超时时间，未配置或无效时使用默认值
+func (c *RemoteStorageConfig) UploadTimeout() time.Duration {
+	return parseDurationOrDefault(c.Timeout, DefaultRemoteTimeout)
+}
+
+// DeleteLocal 上传后是否删除本地文件
+func (c *RemoteStorageConfig) DeleteLocal() bool {
+	return strings.EqualFold(strings.TrimSpace(c.LocalFiles), RemoteLocalDelete)
+}
+
+// SFTPPort SFTP 端口
+func (c *SFTPStorageConfig) SFTPPort() int {
+	if c.Port <= 0 {
+		return DefaultSFTPPort
+	}
+	return c.Port
+}
+
+// SigningRegion 签名使用的区域
+func (c *S3StorageConfig) SigningRegion() string {
+	if region := strings.TrimSpace(c.Region); region != "" {
+		return region
+	}
+	return DefaultS3Region
+}
+
+// validateRemoteStorage 验证远程存储配置，未配置时不检查
+func (v *Validator) validateRemoteStorage(storage *RemoteStorageConfig, result *ValidationResult) {
+	if !storage.Enabled() {
+		return
+	}
+	const prefix = "migration.remote_storage"
+
+	switch storage.StorageType() {
+	case RemoteStorageSFTP:
+		sftp := &storage.SFTP
+		if strings.TrimSpace(sftp.Host) == "" {
+			result.AddError(prefix+".sftp.host", "使用 SFTP 时必须指定服务器地址")
+		}
+		if strings.TrimSpace(sftp.Username) == "" {
+			result.AddError(prefix+".sftp.username", "使用 SFTP 时必须指定用户名")
+		}
+		if sftp.Port < 0 || sftp.Port > 65535 {
+			result.AddError(prefix+".sftp.port", "端口必须在 1-65535 之间")
+		}
+	case RemoteStorageS3:
+		s3 := &storage.S3
+		if parsed, err := url.Parse(strings.TrimSpace(s3.Endpoint)); err != nil || parsed.Host == "" ||
+			(parsed.Scheme != "http" && parsed.Scheme != "https") {
+			result.AddError(prefix+".s3.endpoint", fmt.Sprintf("无效的服务地址: %s，需要 http:// 或 https:// 开头", s3.Endpoint))
+		}
+		if strings.TrimSpace(s3.Bucket) == "" {
+			result.AddError(prefix+".s3.bucket", "使用 S3 时必须指定存储桶")
+		}
+		if strings.TrimSpace(s3.AccessKeyID) == "" || strings.TrimSpace(s3.SecretAccessKey) == "" {
+			result.AddError(prefix+".s3", "使用 S3 时必须指定 access_key_id 和 secret_access_key")
+		}
+	default:
+		result.AddError(prefix+".type", fmt.Sprintf("不支持的远程存储类型: %s，支持 sftp、s3", storage.Type))
+	}
+
+	if strings.Contains(storage.Path, "..") {
+		result.AddError(prefix+".path", "远程路径不能包含 ..")
+	}
+	for i, pattern := range storage.Exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			result.AddError(fmt.Sprintf("%s.exclude[%d]", prefix, i), fmt.Sprintf("无效的通配符: %s", pattern))
+		}
+	}
+	if storage.Concurrency < 0 || storage.Concurrency > 32 {
+		result.AddError(prefix+".concurrency", "并发上传数必须在 0-32 之间")
+	}
+	if storage.Retries != nil && (*storage.Retries < 0 || *storage.Retries > 10) {
+		result.AddError(prefix+".retries", "重试次数必须在 0-10 之间")
+	}
+	if value := strings.TrimSpace(storage.Timeout); value != "" {
+		if duration, err := time.ParseDuration(value); err != nil || duration <= 0 {
+			result.AddError(prefix+".timeout", fmt.Sprintf("无效的超时时间: %s（如 10m）", storage.Timeout))
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(storage.LocalFiles)) {
+	case "", RemoteLocalKeep, RemoteLocalDelete:
+	default:
+		result.AddError(prefix+".local_files", "本地文件处理方式只支持 keep、delete")
+	}
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// 迁移运行环境
+const (
+	RunEnvTest = "test"
+	RunEnvProd = "prod"
+)
+
+// DefaultProdMaxParallelJobs 生产环境默认的并行作业数上限
+const DefaultProdMaxParallelJobs = 4
+
+// runEnvironmentNames 运行环境的中文名称
+var runEnvironmentNames = map[string]string{
+	RunEnvTest: "测试",
+	RunEnvProd: "生产",
+}
+
+// RunEnvironmentsConfig 测试和生产环境的迁移策略，迁移时用 --env 选择
+type RunEnvironmentsConfig struct {
+	// Default 未指定 --env 时使用的环境（test、prod），为空时不应用环境策略
+	Default string               `yaml:"default,omitempty" json:"default,omitempty"`
+	Test    RunEnvironmentPolicy `yaml:"test,omitempty" json:"test,omitempty"`
+	Prod    RunEnvironmentPolicy `yaml:"prod,omitempty" json:"prod,omitempty"`
+}
+
+// RunEnvironmentPolicy 一个运行环境的迁移策略，未配置的项使用该环境的内置默认值
+type RunEnvironmentPolicy struct {
+	// ParallelJobs 该环境使用的并行作业数，0 表示使用 migration.parallel_jobs
+	ParallelJobs int `yaml:"parallel_jobs,omitempty" json:"parallel_jobs,omitempty"`
+	// MaxParallelJobs 并行作业数上限，生产环境默认 4，0 表示不限制
+	MaxParallelJobs *int `yaml:"max_parallel_jobs,omitempty" json:"max_parallel_jobs,omitempty"`
+	// Backup 迁移前把上次的输出目录归档到 backup 目录，生产环境必须开启
+	Backup *bool `yaml:"backup,omitempty" json:"backup,omitempty"`
+	// ConfirmDangerous 迁移开始和执行危险操作前额外确认，生产环境必须开启
+	ConfirmDangerous *bool `yaml:"confirm_dangerous,omitempty" json:"confirm_dangerous,omitempty"`
+	// PostgreSQLHosts 属于该环境的目标库主机，用于防止以错误的环境连接目标库
+	PostgreSQLHosts []string `yaml:"postgresql_hosts,omitempty" json:"postgresql_hosts,omitempty"`
+}
+
+// RunEnvironment 合并内置默认值后生效的环境策略
+type RunEnvironment struct {
+	Name             string
+	ParallelJobs     int
+	MaxParallelJobs  int
+	Backup           bool
+	ConfirmDangerous bool
+	PostgreSQLHosts  []string
+}
+
+// IsRunEnvironment 是否为支持的运行环境
+func IsRunEnvironment(name string) bool {
+	_, ok := runEnvironmentNames[name]
+	return ok
+}
+
+// policy 指定环境的策略配置
+func (c *RunEnvironmentsConfig) policy(name string) *RunEnvironmentPolicy {
+	if name == RunEnvProd {
+		return &c.Prod
+	}
+	return &c.Test
+}
+
+// Resolve 获取环境的生效策略；name 为空时使用 default，两者都为空时返回nil表示不应用环境策略
+func (c *RunEnvironmentsConfig) Resolve(name string) (*RunEnvironment, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = strings.ToLower(strings.TrimSpace(c.Default))
+	}
+	if name == "" {
+		return nil, nil
+	}
+	if !IsRunEnvironment(name) {
+		return nil, fmt.Errorf("不支持的运行环境: %s，支持 test、prod", name)
+	}
+
+	policy := c.policy(name)
+	env := &RunEnvironment{
+		Name:            name,
+		ParallelJobs:    policy.ParallelJobs,
+		PostgreSQLHosts: policy.PostgreSQLHosts,
+	}
+	// 生产环境默认保守：限制并行度，迁移前备份，危险操作额外确认
+	if name == RunEnvProd {
+		env.MaxParallelJobs = DefaultProdMaxParallelJobs
+		env.Backup = true
+		env.ConfirmDangerous = true
+	}
+	if policy.MaxParallelJobs != nil {
+		env.MaxParallelJobs = *policy.MaxParallelJobs
+	}
+	if policy.Backup != nil {
+		env.Backup = *policy.Backup
+	}
+	if policy.ConfirmDangerous != nil {
+		env.ConfirmDangerous = *policy.ConfirmDangerous
+	}
+	return env, nil
+}
+
+// DisplayName 带中文名称的环境名，如 prod（生产）
+func (e *RunEnvironment) DisplayName() string {
+	return fmt.Sprintf("%s（%s）", e.Name, runEnvironmentNames[e.Name])
+}
+
+// IsProd 是否为生产环境
+func (e *RunEnvironment) IsProd() bool {
+	return e.Name == RunEnvProd
+}
+
+// Summary 策略说明，每项一行
+func (e *RunEnvironment) Summary() []string {
+	var lines []string
+	switch {
+	case e.ParallelJobs > 0 && e.MaxParallelJobs > 0:
+		lines = append(lines, fmt.Sprintf("并行作业数 %d，上限 %d", e.ParallelJobs, e.MaxParallelJobs))
+	case e.ParallelJobs > 0:
+		lines = append(lines, fmt.Sprintf("并行作业数 %d", e.ParallelJobs))
+	case e.MaxParallelJobs > 0:
+		lines = append(lines, fmt.Sprintf("并行作业数上限 %d", e.MaxParallelJobs))
+	default:
+		lines = append(lines, "并行作业数使用配置，不限制上限")
+	}
+	if e.Backup {
+		lines = append(lines, "迁移前归档上次的输出目录")
+	}
+	if e.ConfirmDangerous {
+		lines = append(lines, "迁移开始和危险操作前额外确认")
+	}
+	if len(e.PostgreSQLHosts) > 0 {
+		lines = append(lines, fmt.Sprintf("目标库限定为 %s", strings.Join(e.PostgreSQLHosts, ", ")))
+	}
+	return lines
+}
+
+// ParallelJobsFor 计算生效的并行作业数：命令行参数 > 环境策略 > 配置文件
+//
+// 命令行指定的值超过上限时返回错误，避免在生产环境误用测试参数；策略或配置文件的值超过上限时降到上限，capped 为 true。
+func (e *RunEnvironment) ParallelJobsFor(configured, flag int) (jobs int, capped bool, err error) {
+	jobs = configured
+	if e.ParallelJobs > 0 {
+		jobs = e.ParallelJobs
+	}
+	if flag > 0 {
+		if e.MaxParallelJobs > 0 && flag > e.MaxParallelJobs {
+			return 0, false, fmt.Errorf("--parallel=%d 超过 %s 环境的并行作业数上限 %d", flag, e.Name, e.MaxParallelJobs)
+		}
+		return flag, false, nil
+	}
+	if e.MaxParallelJobs > 0 && jobs > e.MaxParallelJobs {
+		return e.MaxParallelJobs, true, nil
+	}
+	return jobs, false, nil
+}
+
+// CheckTarget 检查目标库主机是否属于指定环境：属于另一个环境或不在本环境列表中时返回错误
+func (c *RunEnvironmentsConfig) CheckTarget(env *RunEnvironment, host string) error {
+	host = strings.ToLower(strings.TrimSpace(host))
+	for _, other := range []string{RunEnvTest, RunEnvProd} {
+		if other == env.Name {
+			continue
+		}
+		if containsHost(c.policy(other).PostgreSQLHosts, host) {
+			return fmt.Errorf("目标库 %s 属于 %s 环境，不能以 %s 环境运行", host, other, env.Name)
+		}
+	}
+	if len(env.PostgreSQLHosts) > 0 && !containsHost(env.PostgreSQLHosts, host) {
+		return fmt.Errorf("目标库 %s 不在 %s 环境的 postgresql_hosts 中", host, env.Name)
+	}
+	return nil
+}
+
+// containsHost 主机列表中是否包含主机，不区分大小写
+func containsHost(hosts []string, host string) bool {
+	return slices.ContainsFunc(hosts, func(item string) bool {
+		return strings.EqualFold(strings.TrimSpace(item), host)
+	})
+}
+
+// validateRunEnvironments 验证运行环境策略：生产环境不能关闭备份和危险操作确认，两个环境的目标库不能重叠
+func (v *Validator) validateRunEnvironments(environments *RunEnvironmentsConfig, result *ValidationResult) {
+	if name := strings.ToLower(strings.TrimSpace(environments.Default)); name != "" && !IsRunEnvironment(name) {
+		result.AddError("run_environments.default", fmt.Sprintf("不支持的运行环境: %s，支持 test、prod", environments.Default))
+	}
+
+	for _, name := range []string{RunEnvTest, RunEnvProd} {
+		policy := environments.policy(name)
+		field := "run_environments." + name
+		if policy.ParallelJobs < 0 {
+			result.AddError(field+".parallel_jobs", "并行作业数不能为负数")
+		}
+		if policy.MaxParallelJobs != nil {
+			if *policy.MaxParallelJobs < 0 {
+				result.AddError(field+".max_parallel_jobs", "并行作业数上限不能为负数")
+			} else if *policy.MaxParallelJobs > 0 && policy.ParallelJobs > *policy.MaxParallelJobs {
+				result.AddError(field+".parallel_jobs", fmt.Sprintf("并行作业数 %d 超过上限 %d", policy.ParallelJobs, *policy.MaxParallelJobs))
+			}
+		}
+		if name != RunEnvProd {
+			continue
+		}
+		if policy.Backup != nil && !*policy.Backup {
+			result.AddError(field+".backup", "生产环境必须在迁移前备份")
+		}
+		if policy.ConfirmDangerous != nil && !*policy.ConfirmDangerous {
+			result.AddError(field+".confirm_dangerous", "生产环境必须确认危险操作")
+		}
+		if policy.MaxParallelJobs == nil && policy.ParallelJobs > DefaultProdMaxParallelJobs {
+			result.AddError(field+".parallel_jobs", fmt.Sprintf("并行作业数 %d 超过生产环境默认上限 %d，需要时请同时设置 max_parallel_jobs",
+				policy.ParallelJobs, DefaultProdMaxParallelJobs))
+		}
+	}
+
+	for _, host := range environments.Test.PostgreSQLHosts {
+		if containsHost(environments.Prod.PostgreSQLHosts, strings.ToLower(strings.TrimSpace(host))) {
+			result.AddError("run_environments.test.postgresql_hosts", fmt.Sprintf("目标库 %s 同时属于测试和生产环境", host))
+		}
+	}
+}
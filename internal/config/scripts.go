@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// 迁移前后脚本的目标库
+const (
+	ScriptTargetPostgreSQL = "postgresql"
+	ScriptTargetOracle     = "oracle"
+)
+
+// 迁移前后脚本失败时的处理策略
+const (
+	ScriptOnErrorAbort    = "abort"
+	ScriptOnErrorContinue = "continue"
+)
+
+// 迁移前后脚本的默认目录（相对于项目根目录）
+var (
+	DefaultPreScriptsDir  = filepath.Join("scripts", "pre")
+	DefaultPostScriptsDir = filepath.Join("scripts", "post")
+)
+
+// MigrationScriptsConfig 迁移前后自动执行的SQL脚本
+//
+// 目录下的 *.sql 按文件名排序执行；文件名以 .oracle.sql 或 .postgresql.sql 结尾时在对应的库执行，否则在 Target 指定的库执行。
+type MigrationScriptsConfig struct {
+	// Disabled 不执行迁移前后脚本
+	Disabled bool `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+	// PreDir、PostDir 迁移前、后执行的脚本目录，默认为 scripts/pre、scripts/post
+	PreDir  string `yaml:"pre_dir,omitempty" json:"pre_dir,omitempty"`
+	PostDir string `yaml:"post_dir,omitempty" json:"post_dir,omitempty"`
+	// Target 文件名未指定目标库的脚本在哪个库执行：postgresql（默认）或 oracle
+	Target string `yaml:"target,omitempty" json:"target,omitempty"`
+	// OnError 脚本失败时的策略：abort（默认）中止迁移，continue 记录失败后继续
+	OnError string `yaml:"on_error,omitempty" json:"on_error,omitempty"`
+}
+
+// PreScriptsDir 迁移前脚本目录
+func (c *MigrationScriptsConfig) PreScriptsDir() string {
+	if c.PreDir != "" {
+		return c.PreDir
+	}
+	return DefaultPreScriptsDir
+}
+
+// PostScriptsDir 迁移后脚本目录
+func (c *MigrationScriptsConfig) PostScriptsDir() string {
+	if c.PostDir != "" {
+		return c.PostDir
+	}
+	return DefaultPostScriptsDir
+}
+
+// DefaultTarget 文件名未指定目标库时脚本执行的库
+func (c *MigrationScriptsConfig) DefaultTarget() string {
+	if c.Target != "" {
+		return c.Target
+	}
+	return ScriptTargetPostgreSQL
+}
+
+// ContinueOnError 脚本失败后是否继续执行
+func (c *MigrationScriptsConfig) ContinueOnError() bool {
+	return c.OnError == ScriptOnErrorContinue
+}
+
+// validateMigrationScripts 验证迁移前后脚本配置
+func (v *Validator) validateMigrationScripts(migration *MigrationConfig, result *ValidationResult) {
+	scripts := &migration.Scripts
+	switch scripts.Target {
+	case "", ScriptTargetPostgreSQL, ScriptTargetOracle:
+	default:
+		result.AddError("migration.scripts.target",
+			fmt.Sprintf("无效的目标库 %q，可选: %s, %s", scripts.Target, ScriptTargetPostgreSQL, ScriptTargetOracle))
+	}
+	switch scripts.OnError {
+	case "", ScriptOnErrorAbort, ScriptOnErrorContinue:
+	default:
+		result.AddError("migration.scripts.on_error",
+			fmt.Sprintf("无效的失败策略 %q，可选: %s, %s", scripts.OnError, ScriptOnErrorAbort, ScriptOnErrorContinue))
+	}
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultSQLCheckJobs 并行校验的SQL文件数默认值
+const DefaultSQLCheckJobs = 4
+
+// SQLCheckConfig 导入前对ora2pg生成的SQL做语法预校验
+//
+// 语句在目标库（或 database 指定的校验库）的事务中执行后回滚，不会留下对象。
+type SQLCheckConfig struct {
+	// Enabled 迁移结构后自动校验本次生成的SQL，也可用 --check-sql 临时开启
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	// Database 执行校验的数据库，为空时使用 postgresql.database；建议使用单独的空库，避免校验时锁住目标库的对象
+	Database string `yaml:"database,omitempty" json:"database,omitempty"`
+	// Jobs 并行校验的文件数，每个文件使用一个psql会话，0表示默认值
+	Jobs int `yaml:"jobs,omitempty" json:"jobs,omitempty"`
+}
+
+// ParallelJobs 并行校验的文件数
+func (c *SQLCheckConfig) ParallelJobs() int {
+	if c.Jobs > 0 {
+		return c.Jobs
+	}
+	return DefaultSQLCheckJobs
+}
+
+// validateSQLCheck 验证SQL语法预校验配置
+func (v *Validator) validateSQLCheck(migration *MigrationConfig, result *ValidationResult) {
+	check := &migration.SQLCheck
+	if check.Jobs < 0 {
+		result.AddError("migration.sql_check.jobs", fmt.Sprintf("并行校验的文件数不能为负数: %d", check.Jobs))
+	}
+	if database := check.Database; database != "" && strings.TrimSpace(database) != database {
+		result.AddError("migration.sql_check.database", fmt.Sprintf("校验库名称前后不能有空白: %q", database))
+	}
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TableFilter 按 allow_tables 和正则表达式筛选迁移的表
+//
+// exclude_patterns 优先级最高，匹配的表即使在 allow_tables 或 allow_patterns 中也会被排除；
+// 没有 allow_tables 和 allow_patterns 时选中全部未被排除的表。
+type TableFilter struct {
+	// exact allow_tables 中的表名（大写，含模式前缀时为 模式.表名）
+	exact map[string]bool
+	// allow、exclude 合并为一个表达式，每张表只需匹配一次
+	allow   *regexp.Regexp
+	exclude *regexp.Regexp
+}
+
+// TableFilterResult 筛选结果
+type TableFilterResult struct {
+	// Selected 选中的表，按名称排序；其他模式的表为 模式.表名
+	Selected []string
+	// Excluded 被 exclude_patterns 排除的表
+	Excluded []string
+	// All 是否选中了源库的全部表，此时不需要生成 ALLOW
+	All bool
+}
+
+// HasTablePatterns 是否配置了按正则表达式筛选表
+func (m *MigrationConfig) HasTablePatterns() bool {
+	return len(m.AllowPatterns) > 0 || len(m.ExcludePatterns) > 0
+}
+
+// TableFilter 编译表筛选规则，正则表达式无效时返回错误
+func (m *MigrationConfig) TableFilter() (*TableFilter, error) {
+	filter := &TableFilter{exact: make(map[string]bool, len(m.AllowTables))}
+	for _, table := range m.AllowTables {
+		filter.exact[strings.ToUpper(strings.TrimSpace(table))] = true
+	}
+	var err error
+	if filter.allow, err = compileTablePatterns("migration.allow_patterns", m.AllowPatterns); err != nil {
+		return nil, err
+	}
+	if filter.exclude, err = compileTablePatterns("migration.exclude_patterns", m.ExcludePatterns); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// compileTablePatterns 把多个表名正则表达式合并为一个不区分大小写的表达式，没有表达式时返回 nil
+func compileTablePatterns(field string, patterns []string) (*regexp.Regexp, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	parts := make([]string, 0, len(patterns))
+	for i, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			return nil, fmt.Errorf("%s[%d] 不能为空", field, i)
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("%s[%d] 正则表达式 %q 无效: %v", field, i, pattern, err)
+		}
+		parts = append(parts, "(?:"+pattern+")")
+	}
+	return regexp.MustCompile("(?i)" + strings.Join(parts, "|")), nil
+}
+
+// Excluded 表是否被 exclude_patterns 排除，只匹配表名部分
+func (f *TableFilter) Excluded(table string) bool {
+	if f.exclude == nil {
+		return false
+	}
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		table = table[i+1:]
+	}
+	return f.exclude.MatchString(table)
+}
+
+// Filter 从模式 schema 的表中筛选迁移的表，allow_tables 中其他模式的表未被排除时同样保留
+func (f *TableFilter) Filter(schema string, tables []string) *TableFilterResult {
+	schema = strings.ToUpper(schema)
+	result := &TableFilterResult{}
+	allowAll := len(f.exact) == 0 && f.allow == nil
+
+	for _, table := range tables {
+		name := strings.ToUpper(table)
+		if f.Excluded(name) {
+			result.Excluded = append(result.Excluded, name)
+			continue
+		}
+		if allowAll || f.exact[name] || f.exact[schema+"."+name] || (f.allow != nil && f.allow.MatchString(name)) {
+			result.Selected = append(result.Selected, name)
+		}
+	}
+	result.All = len(result.Selected) == len(tables)
+
+	for table := range f.exact {
+		prefix, _, qualified := strings.Cut(table, ".")
+		if !qualified || prefix == schema || f.Excluded(table) {
+			continue
+		}
+		result.Selected = append(result.Selected, table)
+		result.All = false
+	}
+	sort.Strings(result.Selected)
+	sort.Strings(result.Excluded)
+	return result
+}
+
+// validateTablePatterns 验证表筛选的正则表达式，提示被 exclude_patterns 排除的 allow_tables
+func (v *Validator) validateTablePatterns(migration *MigrationConfig, result *ValidationResult) {
+	valid := true
+	for _, group := range []struct {
+		field    string
+		patterns []string
+	}{
+		{"migration.allow_patterns", migration.AllowPatterns},
+		{"migration.exclude_patterns", migration.ExcludePatterns},
+	} {
+		for i, pattern := range group.patterns {
+			field := fmt.Sprintf("%s[%d]", group.field, i)
+			if strings.TrimSpace(pattern) == "" {
+				result.AddError(field, "正则表达式不能为空")
+				valid = false
+			} else if _, err := regexp.Compile(pattern); err != nil {
+				result.AddError(field, fmt.Sprintf("无效的正则表达式 %q: %v", pattern, err))
+				valid = false
+			}
+		}
+	}
+	if !valid || len(migration.ExcludePatterns) == 0 {
+		return
+	}
+
+	filter, err := migration.TableFilter()
+	if err != nil {
+		return
+	}
+	for i, table := range migration.AllowTables {
+		if filter.Excluded(strings.ToUpper(table)) {
+			result.AddWarning(fmt.Sprintf("migration.allow_tables[%d]", i),
+				fmt.Sprintf("表 %s 同时匹配 exclude_patterns，将被排除", table),
+				"exclude_patterns 优先于 allow_tables，需要迁移该表时调整排除规则")
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultTargetName 配置了多个目标时，未命名的主目标的名称
+const DefaultTargetName = "primary"
+
+// targetNamePattern 目标名称用作输出子目录名，只允许字母、数字、下划线和连字符
+var targetNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// UnmarshalYAML 支持把 postgresql 写成列表：第一项为主目标，其余各项为同时迁移的其他目标
+func (c *PostgreConfig) UnmarshalYAML(node *yaml.Node) error {
+	type plain PostgreConfig
+	if node.Kind != yaml.SequenceNode {
+		value := plain(*c)
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		*c = PostgreConfig(value)
+		return nil
+	}
+
+	if len(node.Content) == 0 {
+		return fmt.Errorf("postgresql 列表中至少需要一个目标")
+	}
+	primary := plain(*c)
+	if err := node.Content[0].Decode(&primary); err != nil {
+		return err
+	}
+	for _, item := range node.Content[1:] {
+		var target plain
+		if err := item.Decode(&target); err != nil {
+			return err
+		}
+		primary.Targets = append(primary.Targets, PostgreConfig(target))
+	}
+	*c = PostgreConfig(primary)
+	return nil
+}
+
+// HasMultipleTargets 是否配置了多个目标库
+func (c *ProjectConfig) HasMultipleTargets() bool {
+	return len(c.PostgreSQL.Targets) > 0
+}
+
+// PostgreSQLTargets 全部目标库，主目标在前；其他目标未配置的连接项沿用主目标
+func (c *ProjectConfig) PostgreSQLTargets() []PostgreConfig {
+	primary := c.PostgreSQL
+	primary.Targets = nil
+	if primary.Name == "" {
+		primary.Name = DefaultTargetName
+	}
+
+	targets := []PostgreConfig{primary}
+	for _, target := range c.PostgreSQL.Targets {
+		inheritString(&target.Host, primary.Host)
+		if target.Port == 0 {
+			target.Port = primary.Port
+		}
+		inheritString(&target.Database, primary.Database)
+		inheritString(&target.Username, primary.Username)
+		inheritString(&target.Password, primary.Password)
+		inheritString(&target.Schema, primary.Schema)
+		inheritString(&target.TestUsername, primary.TestUsername)
+		inheritString(&target.TestPassword, primary.TestPassword)
+		target.Targets = nil
+		targets = append(targets, target)
+	}
+	return targets
+}
+
+// SelectTargets 按名称选出目标库，names 为空时返回全部目标
+func (c *ProjectConfig) SelectTargets(names []string) ([]PostgreConfig, error) {
+	targets := c.PostgreSQLTargets()
+	if len(names) == 0 {
+		return targets, nil
+	}
+
+	selected := make([]PostgreConfig, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, target := range targets {
+			if strings.EqualFold(target.Name, strings.TrimSpace(name)) {
+				selected = append(selected, target)
+				found = true
+				break
+			}
+		}
+		if !found {
+			available := make([]string, len(targets))
+			for i, target := range targets {
+				available[i] = target.Name
+			}
+			return nil, fmt.Errorf("未配置目标 %s，可用的目标: %s", name, strings.Join(available, ", "))
+		}
+	}
+	return selected, nil
+}
+
+// ForTarget 复制配置并替换为指定的目标库，输出目录为原输出目录下以目标名称命名的子目录
+func (c *ProjectConfig) ForTarget(target PostgreConfig) *ProjectConfig {
+	copied := *c
+	target.Targets = nil
+	copied.PostgreSQL = target
+	copied.Migration.OutputDir = filepath.Join(c.Migration.OutputDir, target.Name)
+	return &copied
+}
+
+// inheritString 未配置的项沿用主目标的值
+func inheritString(value *string, primary string) {
+	if strings.TrimSpace(*value) == "" {
+		*value = primary
+	}
+}
+
+// validatePostgreSQLTargets 验证其他目标库，未配置时不检查
+func (v *Validator) validatePostgreSQLTargets(cfg *ProjectConfig, result *ValidationResult) {
+	if !cfg.HasMultipleTargets() {
+		return
+	}
+
+	primaryName := cfg.PostgreSQL.Name
+	if primaryName == "" {
+		primaryName = DefaultTargetName
+	} else if !targetNamePattern.MatchString(primaryName) {
+		result.AddError("postgresql.name", fmt.Sprintf("目标名称 %s 无效，只能包含字母、数字、下划线和连字符", primaryName))
+	}
+	names := map[string]bool{strings.ToLower(primaryName): true}
+
+	targets := cfg.PostgreSQLTargets()
+	for i, target := range cfg.PostgreSQL.Targets {
+		prefix := fmt.Sprintf("postgresql.targets[%d]", i)
+		switch {
+		case target.Name == "":
+			result.AddError(prefix+".name", "配置了多个目标时必须为每个目标指定名称")
+		case !targetNamePattern.MatchString(target.Name):
+			result.AddError(prefix+".name", fmt.Sprintf("目标名称 %s 无效，只能包含字母、数字、下划线和连字符", target.Name))
+		case names[strings.ToLower(target.Name)]:
+			result.AddError(prefix+".name", fmt.Sprintf("目标名称 %s 重复", target.Name))
+		}
+		names[strings.ToLower(target.Name)] = true
+
+		if len(target.Targets) > 0 {
+			result.AddError(prefix+".targets", "目标中不能再嵌套 targets")
+		}
+		// 合并主目标的连接项后再检查
+		merged := targets[i+1]
+		if !v.isValidHost(merged.Host) {
+			result.AddError(prefix+".host", fmt.Sprintf("PostgreSQL主机地址格式无效: %s", merged.Host))
+		}
+		if merged.Port <= 0 || merged.Port > 65535 {
+			result.AddError(prefix+".port", "PostgreSQL端口必须在1-65535范围内")
+		}
+	}
+}
@@ -3,16 +3,22 @@ package config
 import (
 	"bytes"
 	"fmt"
+	htmltemplate "html/template"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/template"
 
 	"github.com/sirupsen/logrus"
+
+	"ora2pg-admin/internal/utils"
 )
 
 // TemplateEngine 模板引擎
 type TemplateEngine struct {
 	templateDir string
+	// sourceTables 源库表名，命名规则需要逐表生成 REPLACE_TABLES 时使用
+	sourceTables []string
 }
 
 // NewTemplateEngine 创建新的模板引擎
@@ -43,8 +49,14 @@ func (te *TemplateEngine) GenerateOra2pgConfig(config *ProjectConfig, outputPath
 		return fmt.Errorf("解析模板失败: %v", err)
 	}
 
+	// 按命名规则计算表名转换
+	renames, reserved, err := te.tableRenames(config)
+	if err != nil {
+		return err
+	}
+
 	// 准备模板数据
-	templateData := te.prepareOra2pgTemplateData(config)
+	templateData := te.prepareOra2pgTemplateData(config, renames, reserved)
 
 	// 执行模板
 	var buf bytes.Buffer
@@ -52,6 +64,12 @@ func (te *TemplateEngine) GenerateOra2pgConfig(config *ProjectConfig, outputPath
 		return fmt.Errorf("执行模板失败: %v", err)
 	}
 
+	// 按顺序合并配置片段
+	content, err := te.mergeFragments(config, buf.String(), templateData)
+	if err != nil {
+		return err
+	}
+
 	// 确保输出目录存在
 	outputDir := filepath.Dir(outputPath)
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
@@ -59,7 +77,7 @@ func (te *TemplateEngine) GenerateOra2pgConfig(config *ProjectConfig, outputPath
 	}
 
 	// 写入配置文件
-	if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+	if err := os.WriteFile(outputPath, []byte(content), 0644); err != nil {
 		return fmt.Errorf("写入ora2pg配置文件失败: %v", err)
 	}
 
@@ -67,6 +85,38 @@ func (te *TemplateEngine) GenerateOra2pgConfig(config *ProjectConfig, outputPath
 	return nil
 }
 
+// mergeFragments 用模板数据渲染 migration.fragments 中的片段并合并到生成的配置，没有片段时原样返回
+func (te *TemplateEngine) mergeFragments(config *ProjectConfig, content string, templateData map[string]interface{}) (string, error) {
+	if len(config.Migration.Fragments) == 0 {
+		return content, nil
+	}
+	fragments, err := config.Migration.LoadFragments(".")
+	if err != nil {
+		return "", utils.NewError(utils.ErrorTypeConfig, "FRAGMENT_NOT_FOUND").
+			Message("无法加载ora2pg配置片段").
+			Details(err.Error()).
+			Suggestion("运行 'ora2pg-admin 配置 片段' 查看可用的片段").
+			Build()
+	}
+	for i, fragment := range fragments {
+		if fragments[i], err = fragment.Render(templateData); err != nil {
+			return "", utils.NewError(utils.ErrorTypeConfig, "FRAGMENT_INVALID").
+				Message("ora2pg配置片段无效").
+				Details(err.Error()).
+				Build()
+		}
+	}
+	merged, directives, err := MergeOra2pgFragments(content, fragments)
+	if err != nil {
+		return "", utils.NewError(utils.ErrorTypeConfig, "FRAGMENT_INVALID").
+			Message("ora2pg配置片段无效").
+			Details(err.Error()).
+			Build()
+	}
+	logrus.Infof("已合并配置片段 %s（%d 条指令）", strings.Join(config.Migration.Fragments, ", "), len(directives))
+	return merged, nil
+}
+
 // GenerateProjectConfig 生成项目配置文件
 func (te *TemplateEngine) GenerateProjectConfig(projectName, outputPath string) error {
 	templatePath := filepath.Join(te.templateDir, "project.yaml.tmpl")
@@ -115,21 +165,53 @@ func (te *TemplateEngine) GenerateProjectConfig(projectName, outputPath string)
 	return nil
 }
 
+// SetSourceTables 设置源库表名，命名规则包含前缀、后缀或驼峰转换时必须设置
+func (te *TemplateEngine) SetSourceTables(tables []string) {
+	te.sourceTables = tables
+}
+
+// tableRenames 需要通过 REPLACE_TABLES 改名的表（只包含与ora2pg默认名称不同的表），以及转换后是否有表名为保留字
+func (te *TemplateEngine) tableRenames(config *ProjectConfig) ([]NameMapping, bool, error) {
+	naming := &config.Migration.NamingConvention
+	if !naming.RenamesTables() {
+		return nil, false, nil
+	}
+	if te.sourceTables == nil {
+		return nil, false, fmt.Errorf("命名规则包含前缀、后缀或驼峰转换，生成ora2pg配置前需要查询源库表名")
+	}
+
+	preserveCase := config.Migration.PreserveCase()
+	mappings, err := naming.TableMappings(te.sourceTables, preserveCase)
+	if err != nil {
+		return nil, false, utils.NewError(utils.ErrorTypeConfig, "NAMING_CONVENTION_CONFLICT").
+			Message("命名规则无法应用到源库表").
+			Details(err.Error()).
+			Cause(err).
+			Suggestion("调整 migration.naming_convention 的前缀、后缀或驼峰转换设置，或排除冲突的表").
+			Build()
+	}
+	renames := make([]NameMapping, 0, len(mappings))
+	reserved := false
+	for _, mapping := range mappings {
+		reserved = reserved || mapping.Reserved
+		defaultName := mapping.Source
+		if !preserveCase {
+			defaultName = strings.ToLower(defaultName)
+		}
+		if mapping.Target != defaultName {
+			renames = append(renames, mapping)
+		}
+	}
+	return renames, reserved, nil
+}
+
 // prepareOra2pgTemplateData 准备ora2pg模板数据
-func (te *TemplateEngine) prepareOra2pgTemplateData(config *ProjectConfig) map[string]interface{} {
+func (te *TemplateEngine) prepareOra2pgTemplateData(config *ProjectConfig, renames []NameMapping, reserved bool) map[string]interface{} {
 	// 构建Oracle DSN
-	var oracleDSN string
-	if config.Oracle.Service != "" {
-		oracleDSN = fmt.Sprintf("dbi:Oracle:host=%s;service_name=%s;port=%d",
-			config.Oracle.Host, config.Oracle.Service, config.Oracle.Port)
-	} else {
-		oracleDSN = fmt.Sprintf("dbi:Oracle:host=%s;sid=%s;port=%d",
-			config.Oracle.Host, config.Oracle.SID, config.Oracle.Port)
-	}
+	oracleDSN := config.Oracle.DBIDSN()
 
 	// 构建PostgreSQL DSN
-	postgreDSN := fmt.Sprintf("dbi:Pg:dbname=%s;host=%s;port=%d",
-		config.PostgreSQL.Database, config.PostgreSQL.Host, config.PostgreSQL.Port)
+	postgreDSN := config.PostgreSQL.DBIDSN()
 
 	// 构建迁移类型字符串
 	migrationTypes := ""
@@ -140,6 +222,24 @@ func (te *TemplateEngine) prepareOra2pgTemplateData(config *ProjectConfig) map[s
 		migrationTypes += t
 	}
 
+	// 按连接数上限调整后的并行度
+	plan := config.Migration.ConnectionPlan()
+
+	// 命名规则：case: preserve 开启 PRESERVE_CASE；转换后出现保留字时开启 USE_RESERVED_WORDS 为其加引号
+	switches := ResolveOra2pgSwitches(config.Migration.Options)
+	replaceTables := make([]string, 0, len(renames))
+	for _, rename := range renames {
+		replaceTables = append(replaceTables, rename.Source+":"+rename.Target)
+	}
+	for i := range switches {
+		switch switches[i].Name {
+		case "PRESERVE_CASE":
+			switches[i].Enabled = switches[i].Enabled || config.Migration.NamingConvention.PreserveCase()
+		case "USE_RESERVED_WORDS":
+			switches[i].Enabled = switches[i].Enabled || reserved
+		}
+	}
+
 	return map[string]interface{}{
 		"OracleDSN":      oracleDSN,
 		"OracleUser":     config.Oracle.Username,
@@ -150,11 +250,22 @@ func (te *TemplateEngine) prepareOra2pgTemplateData(config *ProjectConfig) map[s
 		"PostgrePassword": config.PostgreSQL.Password,
 		"PostgreSchema":  config.PostgreSQL.Schema,
 		"MigrationTypes": migrationTypes,
-		"ParallelJobs":   config.Migration.ParallelJobs,
+		"ParallelJobs":   plan.ParallelJobs,
 		"BatchSize":      config.Migration.BatchSize,
 		"OutputDir":      config.Migration.OutputDir,
 		"LogLevel":       config.Migration.LogLevel,
 		"ProjectName":    config.Project.Name,
+		"Switches":       switches,
+		"OracleCopies":   plan.OracleCopies,
+		"DefinedPK":      strings.Join(config.Migration.DefinedPK(), " "),
+		"ParallelTables": plan.ParallelTables,
+		"LargeTables":    config.Migration.LargeTables,
+		"NamingRule":     config.Migration.NamingConvention.Describe(),
+		"ReplaceTables":  strings.Join(replaceTables, " "),
+		"TimeZone":       strings.TrimSpace(config.Migration.TimeZone),
+		"OraTimeZone":    config.Migration.OracleTimeZoneCommand(),
+		"PgTimeZone":     config.Migration.PostgresTimeZoneCommand(),
+		"AllowTables":    config.Migration.AllowDirective(),
 	}
 }
 
@@ -183,6 +294,42 @@ func (te *TemplateEngine) ValidateTemplate(templateName string) error {
 	return nil
 }
 
+// ReadTemplate 读取模板目录下的模板文件内容
+func (te *TemplateEngine) ReadTemplate(templateName string) (string, error) {
+	templatePath := filepath.Join(te.templateDir, templateName)
+	content, err := os.ReadFile(templatePath)
+	if err != nil {
+		return "", fmt.Errorf("读取模板文件失败: %v", err)
+	}
+	return string(content), nil
+}
+
+// RenderText 渲染纯文本模板
+func (te *TemplateEngine) RenderText(name, content string, data interface{}) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("解析模板失败: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("执行模板失败: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// RenderHTML 渲染HTML模板，数据中的特殊字符会被转义
+func (te *TemplateEngine) RenderHTML(name, content string, data interface{}) (string, error) {
+	tmpl, err := htmltemplate.New(name).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("解析模板失败: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("执行模板失败: %v", err)
+	}
+	return buf.String(), nil
+}
+
 // GetTemplateDir 获取模板目录
 func (te *TemplateEngine) GetTemplateDir() string {
 	return te.templateDir
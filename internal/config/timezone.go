@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// timeZoneOffsetPattern 以偏移量表示的时区，如 +08:00
+	timeZoneOffsetPattern = regexp.MustCompile(`^[+-](0\d|1[0-4]):[0-5]\d$`)
+	// timeZoneNamePattern 时区名称，如 Asia/Shanghai、UTC
+	timeZoneNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_+\-]*(/[A-Za-z0-9_+\-]+)*$`)
+)
+
+// OracleTimeZoneCommand 迁移期间ora2pg的Oracle会话设置时区的语句，未配置时为空
+func (m *MigrationConfig) OracleTimeZoneCommand() string {
+	timeZone := strings.TrimSpace(m.TimeZone)
+	if timeZone == "" {
+		return ""
+	}
+	return fmt.Sprintf("ALTER SESSION SET TIME_ZONE = '%s'", timeZone)
+}
+
+// PostgresTimeZoneCommand 迁移期间ora2pg的PostgreSQL会话设置时区的语句，未配置时为空
+//
+// 偏移量使用 INTERVAL 形式，避免 '+08:00' 被按POSIX规则解释为西八区。
+func (m *MigrationConfig) PostgresTimeZoneCommand() string {
+	timeZone := strings.TrimSpace(m.TimeZone)
+	switch {
+	case timeZone == "":
+		return ""
+	case timeZoneOffsetPattern.MatchString(timeZone):
+		return fmt.Sprintf("SET TIME ZONE INTERVAL '%s' HOUR TO MINUTE", timeZone)
+	default:
+		return fmt.Sprintf("SET TIME ZONE '%s'", timeZone)
+	}
+}
+
+// validateTimeZone 验证迁移会话时区
+func (v *Validator) validateTimeZone(migration *MigrationConfig, result *ValidationResult) {
+	timeZone := strings.TrimSpace(migration.TimeZone)
+	if timeZone == "" || timeZoneOffsetPattern.MatchString(timeZone) || timeZoneNamePattern.MatchString(timeZone) {
+		return
+	}
+	result.AddError("migration.time_zone", fmt.Sprintf("无效的时区 %s，请使用 Asia/Shanghai、UTC 或 +08:00 等格式", timeZone))
+}
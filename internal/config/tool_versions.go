@@ -0,0 +1,219 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ToolVersionsConfig 期望的外部工具版本，用于固定经过验证的迁移环境
+//
+// 每项为版本约束，如 "24.1"（24.1.x）、">=23.2, <25"、"19.x"，为空表示不限制该工具。
+type ToolVersionsConfig struct {
+	Ora2pg  string `yaml:"ora2pg,omitempty" json:"ora2pg,omitempty"`
+	SQLPlus string `yaml:"sqlplus,omitempty" json:"sqlplus,omitempty"`
+	PSQL    string `yaml:"psql,omitempty" json:"psql,omitempty"`
+	// Enforce 迁移开始前强制校验，版本不匹配或无法识别时终止迁移；为 false 时只记录警告
+	Enforce bool `yaml:"enforce,omitempty" json:"enforce,omitempty"`
+}
+
+// ToolConstraint 一个工具的版本约束
+type ToolConstraint struct {
+	Tool       string
+	Constraint string
+}
+
+// Constraints 已配置的工具版本约束，按 ora2pg、sqlplus、psql 的顺序排列
+func (c *ToolVersionsConfig) Constraints() []ToolConstraint {
+	var constraints []ToolConstraint
+	for _, item := range []ToolConstraint{{"ora2pg", c.Ora2pg}, {"sqlplus", c.SQLPlus}, {"psql", c.PSQL}} {
+		if item.Constraint = strings.TrimSpace(item.Constraint); item.Constraint != "" {
+			constraints = append(constraints, item)
+		}
+	}
+	return constraints
+}
+
+var (
+	// toolVersionPattern 版本号，如 24.1、19.3.0.0.0、16
+	toolVersionPattern = regexp.MustCompile(`\d+(?:\.\d+)*`)
+	// toolVersionOutputPattern 版本输出中独立的版本号，跳过 "Ora2Pg" 这类名称中的数字
+	toolVersionOutputPattern = regexp.MustCompile(`(?:^|[^\w.])[vV]?(\d+(?:\.\d+)*)`)
+)
+
+// ToolVersion 外部工具的版本号，各段为数字，如 [24 1]
+type ToolVersion []int
+
+// ParseToolVersion 从工具的版本输出中提取第一个版本号
+func ParseToolVersion(text string) (ToolVersion, bool) {
+	match := toolVersionOutputPattern.FindStringSubmatch(text)
+	if match == nil {
+		return nil, false
+	}
+	var version ToolVersion
+	for _, part := range strings.Split(match[1], ".") {
+		number, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, false
+		}
+		version = append(version, number)
+	}
+	return version, true
+}
+
+// Compare 比较版本号，缺少的段按0处理：小于 other 返回-1，相等返回0，大于返回1
+func (v ToolVersion) Compare(other ToolVersion) int {
+	for i := 0; i < len(v) || i < len(other); i++ {
+		a, b := 0, 0
+		if i < len(v) {
+			a = v[i]
+		}
+		if i < len(other) {
+			b = other[i]
+		}
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	}
+	return 0
+}
+
+// hasPrefix 版本号的前几段是否与 prefix 相同，如 24.1.3 以 24.1 开头
+func (v ToolVersion) hasPrefix(prefix ToolVersion) bool {
+	if len(prefix) > len(v) {
+		return ToolVersion(prefix[:len(v)]).Compare(v) == 0 && prefix[len(v):].isZero()
+	}
+	for i, number := range prefix {
+		if v[i] != number {
+			return false
+		}
+	}
+	return true
+}
+
+// isZero 各段是否都为0
+func (v ToolVersion) isZero() bool {
+	for _, number := range v {
+		if number != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// String 版本号文本，如 24.1
+func (v ToolVersion) String() string {
+	parts := make([]string, len(v))
+	for i, number := range v {
+		parts[i] = strconv.Itoa(number)
+	}
+	return strings.Join(parts, ".")
+}
+
+// versionOperators 版本约束支持的比较符，较长的放在前面以便优先匹配
+var versionOperators = []string{">=", "<=", "!=", ">", "<", "="}
+
+// versionTerm 单个比较条件，op 为空或 "=" 时按前缀匹配
+type versionTerm struct {
+	op      string
+	version ToolVersion
+}
+
+// VersionConstraint 工具版本约束，多个条件需同时满足
+type VersionConstraint struct {
+	raw   string
+	terms []versionTerm
+}
+
+// ParseVersionConstraint 解析版本约束，条件之间用逗号或空格分隔
+//
+// 不带比较符或使用 "=" 时按前缀匹配（"24.1" 匹配 24.1、24.1.3），末尾的 ".x"、".*" 等同于省略该段。
+func ParseVersionConstraint(text string) (*VersionConstraint, error) {
+	constraint := &VersionConstraint{raw: strings.TrimSpace(text)}
+	fields := strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		op := ""
+		for _, candidate := range versionOperators {
+			if strings.HasPrefix(field, candidate) {
+				op = candidate
+				field = strings.TrimPrefix(field, candidate)
+				break
+			}
+		}
+		// 比较符与版本号之间有空格，如 ">= 24"
+		if field == "" && op != "" && i+1 < len(fields) {
+			i++
+			field = fields[i]
+		}
+
+		wildcard := false
+		for _, suffix := range []string{".x", ".X", ".*"} {
+			if strings.HasSuffix(field, suffix) {
+				field = strings.TrimSuffix(field, suffix)
+				wildcard = true
+				break
+			}
+		}
+		if wildcard && op != "" && op != "=" {
+			return nil, fmt.Errorf("通配符只能用于前缀匹配，不能与 %s 一起使用: %s", op, fields[i])
+		}
+		if field == "" || toolVersionPattern.FindString(field) != field {
+			return nil, fmt.Errorf("无法识别的版本号: %s", fields[i])
+		}
+		version, _ := ParseToolVersion(field)
+		constraint.terms = append(constraint.terms, versionTerm{op: op, version: version})
+	}
+	if len(constraint.terms) == 0 {
+		return nil, fmt.Errorf("版本约束为空")
+	}
+	return constraint, nil
+}
+
+// Match 版本号是否满足约束
+func (c *VersionConstraint) Match(version ToolVersion) bool {
+	for _, term := range c.terms {
+		compare := version.Compare(term.version)
+		var ok bool
+		switch term.op {
+		case "", "=":
+			ok = version.hasPrefix(term.version)
+		case "!=":
+			ok = !version.hasPrefix(term.version)
+		case ">=":
+			ok = compare >= 0
+		case "<=":
+			ok = compare <= 0
+		case ">":
+			ok = compare > 0
+		case "<":
+			ok = compare < 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// String 配置中的约束文本
+func (c *VersionConstraint) String() string {
+	return c.raw
+}
+
+// validateToolVersions 验证工具版本约束的格式
+func (v *Validator) validateToolVersions(tools *ToolVersionsConfig, result *ValidationResult) {
+	for _, item := range tools.Constraints() {
+		if _, err := ParseVersionConstraint(item.Constraint); err != nil {
+			result.AddError("tools."+item.Tool, fmt.Sprintf("版本约束 %q 无效: %v（示例: \"24.1\"、\">=23.2, <25\"、\"19.x\"）", item.Constraint, err))
+		}
+	}
+	if tools.Enforce && len(tools.Constraints()) == 0 {
+		result.AddWarning("tools.enforce", "启用了工具版本强制校验，但没有配置任何工具的期望版本",
+			"在 tools.ora2pg、tools.sqlplus、tools.psql 中配置期望的版本")
+	}
+}
@@ -0,0 +1,253 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// BuiltinTemplates 内置的项目模板
+var BuiltinTemplates = []string{"basic", "advanced", "custom"}
+
+// userTemplateExt 用户模板文件扩展名
+const userTemplateExt = ".yaml"
+
+// IsBuiltinTemplate 检查是否为内置模板
+func IsBuiltinTemplate(name string) bool {
+	for _, t := range BuiltinTemplates {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// UserTemplateDir 获取用户级模板目录（~/.ora2pg-admin/templates）
+func UserTemplateDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户主目录失败: %v", err)
+	}
+	return filepath.Join(home, ".ora2pg-admin", "templates"), nil
+}
+
+// validateTemplateName 验证模板名称
+func validateTemplateName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("模板名称不能为空")
+	}
+	if IsBuiltinTemplate(name) {
+		return fmt.Errorf("模板名称 %s 与内置模板冲突", name)
+	}
+	if strings.ContainsAny(name, `/\:*?"<>|`) || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("模板名称包含非法字符: %s", name)
+	}
+	return nil
+}
+
+// SanitizeForSharing 复制配置并去除连接凭据和主机等环境相关信息
+func SanitizeForSharing(cfg *ProjectConfig) *ProjectConfig {
+	shared := *cfg
+	shared.Migration.Types = append([]string(nil), cfg.Migration.Types...)
+
+	shared.Project.Name = ""
+	shared.Project.Created = time.Time{}
+	shared.Project.Updated = time.Time{}
+
+	shared.Oracle.Host = ""
+	shared.Oracle.Username = ""
+	shared.Oracle.Password = ""
+	shared.Oracle.TestUsername = ""
+	shared.Oracle.TestPassword = ""
+
+	shared.PostgreSQL.Host = ""
+	shared.PostgreSQL.Username = ""
+	shared.PostgreSQL.Password = ""
+	shared.PostgreSQL.TestUsername = ""
+	shared.PostgreSQL.TestPassword = ""
+	// 其他目标只保留名称和模式等结构信息
+	shared.PostgreSQL.Targets = append([]PostgreConfig(nil), cfg.PostgreSQL.Targets...)
+	for i := range shared.PostgreSQL.Targets {
+		target := &shared.PostgreSQL.Targets[i]
+		target.Host, target.Username, target.Password, target.TestUsername, target.TestPassword = "", "", "", "", ""
+	}
+
+	shared.OracleClient.Home = ""
+
+	shared.Notifications.Email.Username = ""
+	shared.Notifications.Email.Password = ""
+	shared.Notifications.Webhook.Token = ""
+	// 机器人地址中含有 access_token，负责人手机号也与团队相关
+	shared.Notifications.Bots = nil
+
+	shared.Migration.RemoteStorage.SFTP.Host = ""
+	shared.Migration.RemoteStorage.SFTP.Username = ""
+	shared.Migration.RemoteStorage.SFTP.IdentityFile = ""
+	shared.Migration.RemoteStorage.S3.AccessKeyID = ""
+	shared.Migration.RemoteStorage.S3.SecretAccessKey = ""
+
+	return &shared
+}
+
+// StripSecrets 复制配置并清除密码，环境变量引用不含密码予以保留
+func StripSecrets(cfg *ProjectConfig) *ProjectConfig {
+	stripped := *cfg
+	stripped.Migration.Types = append([]string(nil), cfg.Migration.Types...)
+	stripped.Notifications.Bots = append([]ChatBotConfig(nil), cfg.Notifications.Bots...)
+	stripped.PostgreSQL.Targets = append([]PostgreConfig(nil), cfg.PostgreSQL.Targets...)
+	for _, field := range secretFields(&stripped) {
+		if !isEnvReference(*field) {
+			*field = ""
+		}
+	}
+	return &stripped
+}
+
+// ReadConfigFile 读取配置文件原始内容，不解密也不展开环境变量；include 的公共配置合并展开，缺失的字段使用默认值
+func ReadConfigFile(path string) (*ProjectConfig, error) {
+	included, err := loadIncludedConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	manager := NewManager()
+	manager.CreateDefaultConfig("")
+	if err := decodeConfigMap(included.merged, manager.config); err != nil {
+		return nil, err
+	}
+	return manager.config, nil
+}
+
+// WriteConfigFile 按原样写入配置文件，按扩展名选择YAML或JSON，不更新时间戳也不加密
+func WriteConfigFile(path string, cfg *ProjectConfig) error {
+	data, err := marshalConfig(path, cfg)
+	if err != nil {
+		return fmt.Errorf("序列化配置失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("写入配置文件失败: %v", err)
+	}
+	return nil
+}
+
+// SaveUserTemplate 将配置另存为用户模板，返回模板文件路径
+func SaveUserTemplate(name string, cfg *ProjectConfig) (string, error) {
+	if err := validateTemplateName(name); err != nil {
+		return "", err
+	}
+
+	dir, err := UserTemplateDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("创建模板目录失败: %v", err)
+	}
+
+	data, err := yaml.Marshal(SanitizeForSharing(cfg))
+	if err != nil {
+		return "", fmt.Errorf("序列化模板失败: %v", err)
+	}
+
+	path := filepath.Join(dir, strings.TrimSpace(name)+userTemplateExt)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("写入模板文件失败: %v", err)
+	}
+
+	logrus.Infof("成功保存用户模板: %s", path)
+	return path, nil
+}
+
+// LoadUserTemplate 加载用户模板
+func LoadUserTemplate(name string) (*ProjectConfig, error) {
+	if err := validateTemplateName(name); err != nil {
+		return nil, err
+	}
+
+	dir, err := UserTemplateDir()
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, strings.TrimSpace(name)+userTemplateExt)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("模板不存在: %s", name)
+		}
+		return nil, fmt.Errorf("读取模板文件失败: %v", err)
+	}
+
+	tmpl := &ProjectConfig{}
+	if err := yaml.Unmarshal(data, tmpl); err != nil {
+		return nil, fmt.Errorf("解析模板文件失败: %v", err)
+	}
+	return tmpl, nil
+}
+
+// ListUserTemplates 列出可用的用户模板名称
+func ListUserTemplates() ([]string, error) {
+	dir, err := UserTemplateDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取模板目录失败: %v", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != userTemplateExt {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), userTemplateExt))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ApplyUserTemplate 将用户模板中的共享设置应用到项目配置
+//
+// 主机、凭据等被脱去的字段保留目标配置中的值。
+func ApplyUserTemplate(cfg *ProjectConfig, tmpl *ProjectConfig) {
+	if tmpl.Project.Version != "" {
+		cfg.Project.Version = tmpl.Project.Version
+	}
+
+	if tmpl.Oracle.Port != 0 {
+		cfg.Oracle.Port = tmpl.Oracle.Port
+	}
+	if tmpl.Oracle.SID != "" || tmpl.Oracle.Service != "" {
+		cfg.Oracle.SID = tmpl.Oracle.SID
+		cfg.Oracle.Service = tmpl.Oracle.Service
+	}
+	cfg.Oracle.Schema = tmpl.Oracle.Schema
+
+	if tmpl.PostgreSQL.Port != 0 {
+		cfg.PostgreSQL.Port = tmpl.PostgreSQL.Port
+	}
+	if tmpl.PostgreSQL.Database != "" {
+		cfg.PostgreSQL.Database = tmpl.PostgreSQL.Database
+	}
+	if tmpl.PostgreSQL.Schema != "" {
+		cfg.PostgreSQL.Schema = tmpl.PostgreSQL.Schema
+	}
+
+	cfg.Migration = tmpl.Migration
+	cfg.Migration.Types = append([]string(nil), tmpl.Migration.Types...)
+	cfg.OracleClient.AutoDetect = tmpl.OracleClient.AutoDetect
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"net"
+	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -20,10 +21,25 @@ func (e ValidationError) Error() string {
 	return fmt.Sprintf("字段 '%s': %s", e.Field, e.Message)
 }
 
+// ValidationWarning 配置一致性警告，不影响验证结果
+type ValidationWarning struct {
+	Field      string
+	Message    string
+	Suggestion string
+}
+
+func (w ValidationWarning) String() string {
+	if w.Suggestion == "" {
+		return fmt.Sprintf("字段 '%s': %s", w.Field, w.Message)
+	}
+	return fmt.Sprintf("字段 '%s': %s（建议: %s）", w.Field, w.Message, w.Suggestion)
+}
+
 // ValidationResult 验证结果
 type ValidationResult struct {
-	Valid  bool
-	Errors []ValidationError
+	Valid    bool
+	Errors   []ValidationError
+	Warnings []ValidationWarning
 }
 
 // AddError 添加验证错误
@@ -35,8 +51,20 @@ func (vr *ValidationResult) AddError(field, message string) {
 	})
 }
 
+// AddWarning 添加一致性警告
+func (vr *ValidationResult) AddWarning(field, message, suggestion string) {
+	vr.Warnings = append(vr.Warnings, ValidationWarning{
+		Field:      field,
+		Message:    message,
+		Suggestion: suggestion,
+	})
+}
+
 // Validator 配置验证器
-type Validator struct{}
+type Validator struct {
+	// customRules 组织自定义的校验规则，在内置规则之后执行
+	customRules []CustomRule
+}
 
 // NewValidator 创建新的验证器
 func NewValidator() *Validator {
@@ -55,6 +83,7 @@ func (v *Validator) ValidateConfig(config *ProjectConfig) *ValidationResult {
 
 	// 验证PostgreSQL配置
 	v.validatePostgreSQL(&config.PostgreSQL, result)
+	v.validatePostgreSQLTargets(config, result)
 
 	// 验证迁移配置
 	v.validateMigration(&config.Migration, result)
@@ -62,11 +91,32 @@ func (v *Validator) ValidateConfig(config *ProjectConfig) *ValidationResult {
 	// 验证Oracle客户端配置
 	v.validateOracleClient(&config.OracleClient, result)
 
+	// 验证通知配置
+	v.validateNotifications(&config.Notifications, result)
+
+	// 验证指标导出配置
+	v.validateMetrics(&config.Metrics, result)
+
+	// 验证自定义环境变量
+	v.validateEnvironment(config.Environment, result)
+
+	// 验证运行环境策略
+	v.validateRunEnvironments(&config.RunEnvironments, result)
+
+	// 验证工具版本约束
+	v.validateToolVersions(&config.Tools, result)
+
+	// 执行自定义校验规则
+	v.validateCustomRules(config, result)
+
 	if result.Valid {
 		logrus.Debug("配置验证通过")
 	} else {
 		logrus.Warnf("配置验证失败，发现 %d 个错误", len(result.Errors))
 	}
+	if len(result.Warnings) > 0 {
+		logrus.Debugf("配置一致性检查发现 %d 个警告", len(result.Warnings))
+	}
 
 	return result
 }
@@ -90,10 +140,15 @@ func (v *Validator) validateProject(project *ProjectInfo, result *ValidationResu
 
 // validateOracle 验证Oracle配置
 func (v *Validator) validateOracle(oracle *OracleConfig, result *ValidationResult) {
+	// 填写了导出文件路径时给出专门提示，不再报告主机格式错误
+	if field, value, found := DumpFileReference(oracle); found {
+		result.AddError(field, fmt.Sprintf("%s 是导出文件，不是在线数据库连接；请先用 impdp 导入Oracle实例，再配置该实例的连接", value))
+	}
+
 	// 验证主机地址
 	if strings.TrimSpace(oracle.Host) == "" {
 		result.AddError("oracle.host", "Oracle主机地址不能为空")
-	} else if !v.isValidHost(oracle.Host) {
+	} else if !IsDumpFilePath(oracle.Host) && !v.isValidHost(oracle.Host) {
 		result.AddError("oracle.host", "Oracle主机地址格式无效")
 	}
 
@@ -101,6 +156,7 @@ func (v *Validator) validateOracle(oracle *OracleConfig, result *ValidationResul
 	if oracle.Port <= 0 || oracle.Port > 65535 {
 		result.AddError("oracle.port", "Oracle端口必须在1-65535范围内")
 	}
+	result.addAdvice(AdviseOraclePort(oracle.Port, oracle.UseTCPS))
 
 	// 验证SID或Service Name
 	if strings.TrimSpace(oracle.SID) == "" && strings.TrimSpace(oracle.Service) == "" {
@@ -116,6 +172,12 @@ func (v *Validator) validateOracle(oracle *OracleConfig, result *ValidationResul
 	if strings.TrimSpace(oracle.Password) == "" {
 		result.AddError("oracle.password", "Oracle密码不能为空")
 	}
+
+	// 验证测试账号（可选，用户名和密码需同时配置）
+	v.validateTestAccount("oracle", oracle.Username, oracle.TestUsername, oracle.TestPassword, result)
+
+	// 验证 TCPS 加密连接配置
+	v.validateOracleTLS(oracle, result)
 }
 
 // validatePostgreSQL 验证PostgreSQL配置
@@ -131,6 +193,7 @@ func (v *Validator) validatePostgreSQL(postgres *PostgreConfig, result *Validati
 	if postgres.Port <= 0 || postgres.Port > 65535 {
 		result.AddError("postgresql.port", "PostgreSQL端口必须在1-65535范围内")
 	}
+	result.addAdvice(AdvisePostgresPort(postgres.Port))
 
 	// 验证数据库名
 	if strings.TrimSpace(postgres.Database) == "" {
@@ -146,6 +209,26 @@ func (v *Validator) validatePostgreSQL(postgres *PostgreConfig, result *Validati
 	if strings.TrimSpace(postgres.Password) == "" {
 		result.AddError("postgresql.password", "PostgreSQL密码不能为空")
 	}
+
+	// 验证测试账号（可选，用户名和密码需同时配置）
+	v.validateTestAccount("postgresql", postgres.Username, postgres.TestUsername, postgres.TestPassword, result)
+}
+
+// validateTestAccount 验证独立测试账号配置
+func (v *Validator) validateTestAccount(prefix, username, testUsername, testPassword string, result *ValidationResult) {
+	testUsername = strings.TrimSpace(testUsername)
+	if testUsername == "" {
+		if strings.TrimSpace(testPassword) != "" {
+			result.AddError(prefix+".test_username", "配置了测试账号密码时必须指定测试账号用户名")
+		}
+		return
+	}
+	if strings.TrimSpace(testPassword) == "" {
+		result.AddError(prefix+".test_password", "测试账号密码不能为空")
+	}
+	if strings.EqualFold(testUsername, strings.TrimSpace(username)) {
+		logrus.Warnf("%s 测试账号与迁移账号相同: %s", prefix, testUsername)
+	}
 }
 
 // validateMigration 验证迁移配置
@@ -155,10 +238,11 @@ func (v *Validator) validateMigration(migration *MigrationConfig, result *Valida
 		result.AddError("migration.types", "至少需要指定一种迁移类型")
 	} else {
 		validTypes := map[string]bool{
-			"TABLE": true, "VIEW": true, "SEQUENCE": true, "INDEX": true,
+			"TABLE": true, "VIEW": true, "MVIEW": true, "SEQUENCE": true, "INDEX": true,
 			"TRIGGER": true, "FUNCTION": true, "PROCEDURE": true, "PACKAGE": true,
 			"TYPE": true, "GRANT": true, "TABLESPACE": true, "PARTITION": true,
 			"COPY": true, "INSERT": true, "FDW": true, "QUERY": true,
+			"DBLINK": true, "SYNONYM": true,
 		}
 		for _, t := range migration.Types {
 			if !validTypes[strings.ToUpper(t)] {
@@ -167,23 +251,68 @@ func (v *Validator) validateMigration(migration *MigrationConfig, result *Valida
 		}
 	}
 
-	// 验证并行作业数
+	// 验证并行作业数，超过建议上限只警告
 	if migration.ParallelJobs <= 0 {
 		result.AddError("migration.parallel_jobs", "并行作业数必须大于0")
-	} else if migration.ParallelJobs > 32 {
-		result.AddError("migration.parallel_jobs", "并行作业数不建议超过32")
 	}
+	result.addAdvice(AdviseParallelJobs(migration.ParallelJobs, 0))
 
 	// 验证批处理大小
 	if migration.BatchSize <= 0 {
 		result.AddError("migration.batch_size", "批处理大小必须大于0")
 	}
+	result.addAdvice(AdviseBatchSize(migration.BatchSize))
 
 	// 验证输出目录
 	if strings.TrimSpace(migration.OutputDir) == "" {
 		result.AddError("migration.output_dir", "输出目录不能为空")
 	}
 
+	// 验证SQL后处理规则
+	for i, r := range migration.SQLReplacements {
+		field := fmt.Sprintf("migration.sql_replacements[%d]", i)
+		if r.Pattern == "" {
+			result.AddError(field, "替换规则的pattern不能为空")
+		} else if _, err := regexp.Compile(r.Pattern); err != nil {
+			result.AddError(field, fmt.Sprintf("无效的正则表达式 %s: %v", r.Pattern, err))
+		}
+	}
+	if script := strings.TrimSpace(migration.PostProcessScript); script != "" {
+		if _, err := os.Stat(script); err != nil {
+			result.AddError("migration.post_process_script", fmt.Sprintf("后处理脚本不存在: %s", script))
+		}
+	}
+
+	// 验证大表分片并行配置
+	v.validateLargeTables(migration, result)
+	v.validateMaxConnections(migration, result)
+	v.validateLogSplit(migration, result)
+	v.validateLogFilter(migration, result)
+	v.validateNamingConvention(migration, result)
+	v.validateTimeZone(migration, result)
+	v.validateIncremental(migration, result)
+	v.validateBatching(migration, result)
+	v.validateFragments(migration, result)
+	v.validateAllowTables(migration, result)
+	v.validateTablePatterns(migration, result)
+	v.validateGroups(migration, result)
+	v.validateProgressRules(migration, result)
+	v.validateMigrationScripts(migration, result)
+	v.validateRemoteStorage(&migration.RemoteStorage, result)
+	v.validateAssertions(migration, result)
+	v.validateSQLCheck(migration, result)
+	v.validateConsistency(migration, result)
+	if processes := migration.ExportProcesses(); migration.UsesParallelExport() && processes > 64 {
+		logrus.Warnf("数据导出将启动约 %d 个ora2pg进程（并行表数 × 分片数 × 并行作业数），可能压垮源库或本机", processes)
+	}
+
+	// 验证ora2pg开关名称
+	for name := range migration.Options {
+		if _, ok := LookupOra2pgSwitch(name); !ok {
+			result.AddError("migration.options", fmt.Sprintf("不支持的ora2pg开关: %s", name))
+		}
+	}
+
 	// 验证日志级别
 	validLogLevels := map[string]bool{
 		"DEBUG": true, "INFO": true, "WARN": true, "ERROR": true,
@@ -245,14 +374,21 @@ func (v *Validator) ValidateConnectionString(connStr string) bool {
 
 // GetValidationSummary 获取验证结果摘要
 func (v *Validator) GetValidationSummary(result *ValidationResult) string {
-	if result.Valid {
-		return "✅ 配置验证通过"
+	summary := "✅ 配置验证通过"
+	if !result.Valid {
+		summary = fmt.Sprintf("❌ 配置验证失败，发现 %d 个错误:\n", len(result.Errors))
+		for i, err := range result.Errors {
+			summary += fmt.Sprintf("  %d. %s\n", i+1, err.Error())
+		}
+	} else if len(result.Warnings) > 0 {
+		summary += "\n"
 	}
 
-	summary := fmt.Sprintf("❌ 配置验证失败，发现 %d 个错误:\n", len(result.Errors))
-	for i, err := range result.Errors {
-		summary += fmt.Sprintf("  %d. %s\n", i+1, err.Error())
+	if len(result.Warnings) > 0 {
+		summary += fmt.Sprintf("⚠️  发现 %d 个警告:\n", len(result.Warnings))
+		for i, warning := range result.Warnings {
+			summary += fmt.Sprintf("  %d. %s\n", i+1, warning.String())
+		}
 	}
-
 	return summary
 }
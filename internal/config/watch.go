@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+// ConfigReloadDebounce 配置文件变更后等待的时间，编辑器保存时可能连续触发多次写入、重命名
+const ConfigReloadDebounce = 300 * time.Millisecond
+
+// ReloadEvent 配置文件变更后的重载结果
+type ReloadEvent struct {
+	Path string
+	// Config 重载后使用的配置，重载失败时为原配置
+	Config *ProjectConfig
+	// Validation 新配置的校验结果，加载失败时为空
+	Validation *ValidationResult
+	// Err 加载或校验失败的原因，失败时保留原配置
+	Err error
+}
+
+// Reloaded 是否已切换到新配置
+func (e *ReloadEvent) Reloaded() bool {
+	return e.Err == nil
+}
+
+// ConfigWatcher 配置文件监听器
+type ConfigWatcher struct {
+	manager  *Manager
+	watcher  *fsnotify.Watcher
+	files    map[string]bool
+	callback func(*ReloadEvent)
+	done     chan struct{}
+	wg       sync.WaitGroup
+	once     sync.Once
+}
+
+// Watch 监听配置文件及其 include 的文件，变更后重新加载并校验，通过后替换当前配置并调用 callback
+//
+// 替换配置不影响已通过 GetConfig 取得旧配置的调用方，正在执行的迁移继续使用旧配置，下次获取配置时生效；
+// 加载或校验失败时保留原配置。callback 在监听协程中依次调用，可为空。
+func (m *Manager) Watch(callback func(*ReloadEvent)) (*ConfigWatcher, error) {
+	if m.configPath == "" {
+		return nil, fmt.Errorf("未加载配置文件，无法监听")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("创建配置文件监听失败: %v", err)
+	}
+	w := &ConfigWatcher{
+		manager:  m,
+		watcher:  watcher,
+		files:    make(map[string]bool),
+		callback: callback,
+		done:     make(chan struct{}),
+	}
+
+	// 监听所在目录而不是文件本身，编辑器先写临时文件再重命名覆盖时仍能收到事件
+	paths := []string{m.configPath}
+	if m.included != nil {
+		for _, include := range m.included.includes {
+			paths = append(paths, resolveIncludePath(m.configPath, include))
+		}
+	}
+	dirs := make(map[string]bool)
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			absPath = path
+		}
+		w.files[absPath] = true
+		dir := filepath.Dir(absPath)
+		if dirs[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("监听配置目录 %s 失败: %v", dir, err)
+		}
+		dirs[dir] = true
+	}
+
+	w.wg.Add(1)
+	go w.run()
+	logrus.Infof("开始监听配置文件: %s", m.configPath)
+	return w, nil
+}
+
+// Close 停止监听
+func (w *ConfigWatcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		err = w.watcher.Close()
+		w.wg.Wait()
+	})
+	return err
+}
+
+// run 接收文件事件，防抖后重载配置
+func (w *ConfigWatcher) run() {
+	defer w.wg.Done()
+
+	var timer *time.Timer
+	var fire <-chan time.Time
+	for {
+		select {
+		case <-w.done:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if !w.relevant(event) {
+				continue
+			}
+			// 连续的事件只在最后一次之后重载一次
+			if timer == nil {
+				timer = time.NewTimer(ConfigReloadDebounce)
+			} else {
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				timer.Reset(ConfigReloadDebounce)
+			}
+			fire = timer.C
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			logrus.Warnf("监听配置文件出错: %v", err)
+		case <-fire:
+			fire = nil
+			event := w.manager.reload()
+			if w.callback != nil {
+				w.callback(event)
+			}
+		}
+	}
+}
+
+// relevant 事件是否涉及监听的配置文件
+func (w *ConfigWatcher) relevant(event fsnotify.Event) bool {
+	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) {
+		return false
+	}
+	absPath, err := filepath.Abs(event.Name)
+	if err != nil {
+		absPath = event.Name
+	}
+	return w.files[absPath]
+}
+
+// reload 从配置文件加载新配置并校验，通过后替换当前配置
+func (m *Manager) reload() *ReloadEvent {
+	event := &ReloadEvent{Path: m.configPath, Config: m.GetConfig()}
+
+	// 在新的管理器中加载，失败时不影响当前配置
+	loaded := NewManager()
+	loaded.masterPassword = m.masterPassword
+	if err := loaded.LoadConfig(m.configPath); err != nil {
+		event.Err = fmt.Errorf("重新加载配置失败: %v", err)
+		logrus.Warnf("%v，继续使用原配置", event.Err)
+		return event
+	}
+
+	validator := NewValidator()
+	rules, err := LoadDefaultCustomRules(filepath.Dir(filepath.Dir(m.configPath)))
+	if err != nil {
+		event.Err = err
+		logrus.Warnf("加载自定义校验规则失败，继续使用原配置: %v", err)
+		return event
+	}
+	validator.AddCustomRules(rules...)
+	event.Validation = validator.ValidateConfig(loaded.config)
+	if !event.Validation.Valid {
+		event.Err = fmt.Errorf("新配置校验失败: %d 个错误", len(event.Validation.Errors))
+		logrus.Warnf("%v，继续使用原配置", event.Err)
+		return event
+	}
+
+	m.mu.Lock()
+	m.config = loaded.config
+	m.included = loaded.included
+	m.encryptSecrets = loaded.encryptSecrets
+	if loaded.masterPassword != "" {
+		m.masterPassword = loaded.masterPassword
+	}
+	m.mu.Unlock()
+
+	event.Config = loaded.config
+	logrus.Infof("配置文件已重新加载: %s", m.configPath)
+	return event
+}
@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
 )
 
 // ClientInfo Oracle客户端信息
@@ -21,6 +23,7 @@ type ClientInfo struct {
 	InstantClient bool   `json:"instant_client"`
 	Architecture string `json:"architecture"`
 	Path         string `json:"path"`
+	Configured   bool   `json:"configured"` // 是否为配置文件显式指定的客户端
 }
 
 // InstallationGuide 安装指导信息
@@ -32,7 +35,8 @@ type InstallationGuide struct {
 
 // ClientDetector Oracle客户端检测器
 type ClientDetector struct {
-	clientInfo *ClientInfo
+	clientInfo     *ClientInfo
+	configuredHome string
 }
 
 // NewClientDetector 创建新的客户端检测器
@@ -42,6 +46,19 @@ func NewClientDetector() *ClientDetector {
 	}
 }
 
+// UseConfig 应用客户端配置，AutoDetect=false 时只使用配置指定的 Home
+func (cd *ClientDetector) UseConfig(clientConfig *config.OracleClientConfig) {
+	cd.configuredHome = ""
+	if clientConfig != nil && !clientConfig.AutoDetect {
+		cd.configuredHome = strings.TrimSpace(clientConfig.Home)
+	}
+}
+
+// ConfiguredHome 获取配置指定的客户端路径，未指定时返回空
+func (cd *ClientDetector) ConfiguredHome() string {
+	return cd.configuredHome
+}
+
 // DetectClient 检测Oracle客户端
 func (cd *ClientDetector) DetectClient() (*ClientInfo, error) {
 	logrus.Debug("开始检测Oracle客户端...")
@@ -51,6 +68,21 @@ func (cd *ClientDetector) DetectClient() (*ClientInfo, error) {
 		Architecture: runtime.GOARCH,
 	}
 
+	// 0. 配置显式指定了客户端时跳过自动检测
+	if cd.configuredHome != "" {
+		instantClient, err := ValidateClientHome(cd.configuredHome)
+		if err != nil {
+			return cd.clientInfo, err
+		}
+		logrus.Debugf("使用配置指定的Oracle客户端: %s", cd.configuredHome)
+		cd.clientInfo.Home = cd.configuredHome
+		cd.clientInfo.Installed = true
+		cd.clientInfo.InstantClient = instantClient
+		cd.clientInfo.Configured = true
+		cd.detectVersion()
+		return cd.clientInfo, nil
+	}
+
 	// 1. 检查ORACLE_HOME环境变量
 	if oracleHome := os.Getenv("ORACLE_HOME"); oracleHome != "" {
 		logrus.Debugf("发现ORACLE_HOME环境变量: %s", oracleHome)
@@ -63,7 +95,19 @@ func (cd *ClientDetector) DetectClient() (*ClientInfo, error) {
 		}
 	}
 
-	// 2. 检查常见的Oracle客户端安装路径
+	// 2. Windows下检查注册表登记的Oracle Home，比扫描目录更准确
+	for _, path := range uniqueHomes(registryOracleHomes()) {
+		if cd.validateOracleHome(path) {
+			logrus.Debugf("通过注册表发现Oracle客户端: %s", path)
+			cd.clientInfo.Home = path
+			cd.clientInfo.Installed = true
+			cd.clientInfo.InstantClient = strings.Contains(strings.ToLower(path), "instantclient")
+			cd.detectVersion()
+			return cd.clientInfo, nil
+		}
+	}
+
+	// 3. 检查常见的Oracle客户端安装路径
 	commonPaths := cd.getCommonOraclePaths()
 	for _, path := range commonPaths {
 		if cd.validateOracleHome(path) {
@@ -76,14 +120,14 @@ func (cd *ClientDetector) DetectClient() (*ClientInfo, error) {
 		}
 	}
 
-	// 3. 检查PATH中的Oracle工具
+	// 4. 检查PATH中的Oracle工具
 	if cd.checkOracleInPath() {
 		cd.clientInfo.Installed = true
 		cd.detectVersion()
 		return cd.clientInfo, nil
 	}
 
-	// 4. 未找到Oracle客户端
+	// 5. 未找到Oracle客户端
 	logrus.Warn("未检测到Oracle客户端")
 	cd.clientInfo.Installed = false
 	return cd.clientInfo, nil
@@ -124,6 +168,73 @@ func (cd *ClientDetector) validateOracleHome(oracleHome string) bool {
 	return true
 }
 
+// ValidateClientHome 校验指定的Oracle客户端目录，返回是否为Instant Client
+//
+// Instant Client 的 sqlplus 位于根目录，完整客户端位于 bin 目录下。
+func ValidateClientHome(home string) (bool, error) {
+	info, err := os.Stat(home)
+	if err != nil || !info.IsDir() {
+		return false, utils.NewError(utils.ErrorTypeOracle, "ORACLE_CLIENT_HOME_INVALID").
+			Message("配置指定的Oracle客户端路径不存在").
+			Details(home).
+			Suggestion("检查配置文件中的 oracle_client.home").
+			Suggestion("或设置 oracle_client.auto_detect: true 使用自动检测").
+			Build()
+	}
+
+	sqlplus := "sqlplus"
+	if runtime.GOOS == "windows" {
+		sqlplus += ".exe"
+	}
+	if _, err := os.Stat(filepath.Join(home, sqlplus)); err == nil {
+		return true, nil
+	}
+	if _, err := os.Stat(filepath.Join(home, "bin", sqlplus)); err == nil {
+		return false, nil
+	}
+
+	return false, utils.NewError(utils.ErrorTypeOracle, "ORACLE_CLIENT_TOOL_MISSING").
+		Message("配置指定的Oracle客户端路径下未找到sqlplus").
+		Details(home).
+		Suggestion("确认该路径为Oracle客户端安装目录（Instant Client 根目录或 ORACLE_HOME）").
+		Build()
+}
+
+// ClientEnvironment 生成使用指定客户端所需的环境变量
+//
+// 返回 ORACLE_HOME、动态库路径和 PATH，路径会加在现有值之前。
+func ClientEnvironment(home string) map[string]string {
+	env := map[string]string{"ORACLE_HOME": home}
+
+	binDir, libDir := home, home
+	if info, err := os.Stat(filepath.Join(home, "bin")); err == nil && info.IsDir() {
+		binDir = filepath.Join(home, "bin")
+	}
+	if info, err := os.Stat(filepath.Join(home, "lib")); err == nil && info.IsDir() {
+		libDir = filepath.Join(home, "lib")
+	}
+
+	prepend := func(key, dir string) {
+		if current := os.Getenv(key); current != "" {
+			env[key] = dir + string(os.PathListSeparator) + current
+		} else {
+			env[key] = dir
+		}
+	}
+
+	prepend("PATH", binDir)
+	switch runtime.GOOS {
+	case "windows":
+		// Windows 通过 PATH 查找DLL
+	case "darwin":
+		prepend("DYLD_LIBRARY_PATH", libDir)
+	default:
+		prepend("LD_LIBRARY_PATH", libDir)
+	}
+
+	return env
+}
+
 // getCommonOraclePaths 获取常见的Oracle安装路径
 func (cd *ClientDetector) getCommonOraclePaths() []string {
 	var paths []string
@@ -186,6 +297,27 @@ func (cd *ClientDetector) getCommonOraclePaths() []string {
 	return append(paths, expandedPaths...)
 }
 
+// uniqueHomes 去除重复的Oracle Home，Windows下不区分大小写
+func uniqueHomes(homes []string) []string {
+	seen := make(map[string]bool, len(homes))
+	var result []string
+	for _, home := range homes {
+		if home == "" {
+			continue
+		}
+		key := filepath.Clean(home)
+		if runtime.GOOS == "windows" {
+			key = strings.ToLower(key)
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, home)
+	}
+	return result
+}
+
 // checkOracleInPath 检查PATH中的Oracle工具
 func (cd *ClientDetector) checkOracleInPath() bool {
 	tools := []string{"sqlplus", "tnsping", "lsnrctl"}
@@ -228,6 +360,12 @@ func (cd *ClientDetector) detectVersion() {
 
 	// 执行sqlplus -version命令
 	cmd := exec.Command(sqlplusPath, "-version")
+	if cd.clientInfo.Configured {
+		cmd.Env = os.Environ()
+		for key, value := range ClientEnvironment(cd.clientInfo.Home) {
+			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
 	output, err := cmd.Output()
 	if err != nil {
 		logrus.Debugf("执行sqlplus -version失败: %v", err)
@@ -362,6 +500,11 @@ func (cd *ClientDetector) CheckClientStatus() *ClientStatusReport {
 	}
 
 	report.ClientInfo = *clientInfo
+	defer func() {
+		if clientInfo.Configured {
+			report.Message += "（使用配置指定的客户端）"
+		}
+	}()
 
 	if !clientInfo.Installed {
 		report.Status = "NOT_INSTALLED"
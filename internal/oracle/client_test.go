@@ -2,10 +2,15 @@ package oracle
 
 import (
 	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
 )
 
 func TestNewClientDetector(t *testing.T) {
@@ -146,3 +151,59 @@ func containsSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestConfiguredClientHome(t *testing.T) {
+	sqlplus := "sqlplus"
+	if runtime.GOOS == "windows" {
+		sqlplus += ".exe"
+	}
+
+	// 完整客户端：bin/sqlplus + lib
+	fullHome := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(fullHome, "bin"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(fullHome, "lib"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(fullHome, "bin", sqlplus), []byte(""), 0755))
+
+	instant, err := ValidateClientHome(fullHome)
+	require.NoError(t, err)
+	assert.False(t, instant)
+
+	detector := NewClientDetector()
+	detector.UseConfig(&config.OracleClientConfig{Home: fullHome, AutoDetect: false})
+	info, err := detector.DetectClient()
+	require.NoError(t, err)
+	assert.True(t, info.Installed)
+	assert.True(t, info.Configured)
+	assert.Equal(t, fullHome, info.Home)
+
+	env := ClientEnvironment(fullHome)
+	assert.Equal(t, fullHome, env["ORACLE_HOME"])
+	assert.True(t, strings.HasPrefix(env["PATH"], filepath.Join(fullHome, "bin")))
+	if runtime.GOOS == "linux" {
+		assert.True(t, strings.HasPrefix(env["LD_LIBRARY_PATH"], filepath.Join(fullHome, "lib")))
+	}
+
+	// 自动检测开启时忽略配置路径
+	detector.UseConfig(&config.OracleClientConfig{Home: fullHome, AutoDetect: true})
+	assert.Empty(t, detector.ConfiguredHome())
+
+	// 路径下缺少工具
+	emptyHome := t.TempDir()
+	_, err = ValidateClientHome(emptyHome)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "ORACLE_CLIENT_TOOL_MISSING")
+
+	detector.UseConfig(&config.OracleClientConfig{Home: filepath.Join(emptyHome, "missing")})
+	_, err = detector.DetectClient()
+	assert.Error(t, err)
+}
+
+func TestUniqueHomes(t *testing.T) {
+	homes := uniqueHomes([]string{"/opt/oracle/19c", "", "/opt/oracle/19c/", "/opt/oracle/21c"})
+	assert.Equal(t, []string{"/opt/oracle/19c", "/opt/oracle/21c"}, homes)
+
+	// 非Windows平台注册表检测为空实现
+	if runtime.GOOS != "windows" {
+		assert.Empty(t, registryOracleHomes())
+	}
+}
@@ -2,6 +2,7 @@ package oracle
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
@@ -20,6 +21,8 @@ type ConnectionResult struct {
 	ResponseTime time.Duration `json:"response_time"`
 	Error        string        `json:"error,omitempty"`
 	Details      string        `json:"details,omitempty"`
+	// Network 指定 --network 时测量的网络质量，ResponseTime 包含客户端启动和认证，Network 只反映网络本身
+	Network      *NetworkQuality `json:"network,omitempty"`
 }
 
 // ConnectionTester 数据库连接测试器
@@ -34,11 +37,49 @@ func NewConnectionTester() *ConnectionTester {
 	}
 }
 
+// SetClientConfig 应用Oracle客户端配置，AutoDetect=false 时使用配置指定的客户端
+func (ct *ConnectionTester) SetClientConfig(clientConfig *config.OracleClientConfig) {
+	ct.clientDetector.UseConfig(clientConfig)
+}
+
+// toolEnvironment 执行Oracle工具时的环境变量，使用配置指定的客户端时设置对应路径
+func (ct *ConnectionTester) toolEnvironment() []string {
+	home := ct.clientDetector.ConfiguredHome()
+	if home == "" {
+		return nil
+	}
+
+	env := os.Environ()
+	for key, value := range ClientEnvironment(home) {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+	return env
+}
+
+// connectionEnvironment 连接源库时执行Oracle工具的环境变量，TCPS 连接时额外设置 TNS_ADMIN
+func (ct *ConnectionTester) connectionEnvironment(oracleConfig *config.OracleConfig) []string {
+	extra := oracleConfig.ConnectionEnvironment()
+	env := ct.toolEnvironment()
+	if len(extra) == 0 {
+		return env
+	}
+	if env == nil {
+		env = os.Environ()
+	}
+	for key, value := range extra {
+		env = append(env, fmt.Sprintf("%s=%s", key, value))
+	}
+	return env
+}
+
 // TestOracleConnection 测试Oracle数据库连接
 func (ct *ConnectionTester) TestOracleConnection(oracleConfig *config.OracleConfig) *ConnectionResult {
 	startTime := time.Now()
 	result := &ConnectionResult{}
 
+	// 配置了只读测试账号时使用测试账号
+	oracleConfig = oracleConfig.ForConnectionTest()
+
 	logrus.Debugf("开始测试Oracle连接: %s:%d", oracleConfig.Host, oracleConfig.Port)
 
 	// 1. 检查Oracle客户端是否可用
@@ -78,8 +119,11 @@ func (ct *ConnectionTester) TestOracleConnection(oracleConfig *config.OracleConf
 	result.Success = true
 	result.Message = "✅ Oracle数据库连接成功"
 	result.ResponseTime = time.Since(startTime)
-	result.Details = fmt.Sprintf("连接到 %s:%d，响应时间: %v", 
-		oracleConfig.Host, oracleConfig.Port, result.ResponseTime)
+	result.Details = fmt.Sprintf("连接到 %s:%d（账号: %s），响应时间: %v",
+		oracleConfig.Host, oracleConfig.Port, oracleConfig.Username, result.ResponseTime)
+	if oracleConfig.UseTCPS {
+		result.Details += "，TCPS 加密连接"
+	}
 
 	logrus.Infof("Oracle连接测试成功，响应时间: %v", result.ResponseTime)
 	return result
@@ -89,13 +133,8 @@ func (ct *ConnectionTester) TestOracleConnection(oracleConfig *config.OracleConf
 func (ct *ConnectionTester) testTNSPing(oracleConfig *config.OracleConfig) *ConnectionResult {
 	result := &ConnectionResult{}
 
-	// 构建连接字符串
-	var connectString string
-	if oracleConfig.Service != "" {
-		connectString = fmt.Sprintf("%s:%d/%s", oracleConfig.Host, oracleConfig.Port, oracleConfig.Service)
-	} else {
-		connectString = fmt.Sprintf("%s:%d/%s", oracleConfig.Host, oracleConfig.Port, oracleConfig.SID)
-	}
+	// 构建连接字符串，TCPS 连接使用完整的连接描述符
+	connectString := oracleConfig.ConnectDescriptor()
 
 	// 查找tnsping工具
 	tnspingPath, err := ct.findOracleTool("tnsping")
@@ -107,6 +146,7 @@ func (ct *ConnectionTester) testTNSPing(oracleConfig *config.OracleConfig) *Conn
 
 	// 执行tnsping命令
 	cmd := exec.Command(tnspingPath, connectString)
+	cmd.Env = ct.connectionEnvironment(oracleConfig)
 	output, err := cmd.Output()
 	if err != nil {
 		result.Error = fmt.Sprintf("tnsping执行失败: %v", err)
@@ -139,22 +179,15 @@ func (ct *ConnectionTester) testSQLPlusConnection(oracleConfig *config.OracleCon
 	}
 
 	// 构建连接字符串
-	var connectString string
-	if oracleConfig.Service != "" {
-		connectString = fmt.Sprintf("%s/%s@%s:%d/%s",
-			oracleConfig.Username, oracleConfig.Password,
-			oracleConfig.Host, oracleConfig.Port, oracleConfig.Service)
-	} else {
-		connectString = fmt.Sprintf("%s/%s@%s:%d/%s",
-			oracleConfig.Username, oracleConfig.Password,
-			oracleConfig.Host, oracleConfig.Port, oracleConfig.SID)
-	}
+	connectString := fmt.Sprintf("%s/%s@%s",
+		oracleConfig.Username, oracleConfig.Password, oracleConfig.ConnectDescriptor())
 
 	// 创建测试SQL脚本
 	testSQL := "SELECT 'CONNECTION_TEST_OK' FROM DUAL; EXIT;"
 
 	// 执行sqlplus命令
 	cmd := exec.Command(sqlplusPath, "-S", connectString)
+	cmd.Env = ct.connectionEnvironment(oracleConfig)
 	cmd.Stdin = strings.NewReader(testSQL)
 	
 	output, err := cmd.Output()
@@ -186,6 +219,9 @@ func (ct *ConnectionTester) TestPostgreSQLConnection(pgConfig *config.PostgreCon
 	startTime := time.Now()
 	result := &ConnectionResult{}
 
+	// 配置了只读测试账号时使用测试账号
+	pgConfig = pgConfig.ForConnectionTest()
+
 	logrus.Debugf("开始测试PostgreSQL连接: %s:%d", pgConfig.Host, pgConfig.Port)
 
 	// 查找psql工具
@@ -197,9 +233,7 @@ func (ct *ConnectionTester) TestPostgreSQLConnection(pgConfig *config.PostgreCon
 	}
 
 	// 构建连接字符串
-	connectString := fmt.Sprintf("postgresql://%s:%s@%s:%d/%s",
-		pgConfig.Username, pgConfig.Password,
-		pgConfig.Host, pgConfig.Port, pgConfig.Database)
+	connectString := pgConfig.ConnectionURI()
 
 	// 创建测试SQL
 	testSQL := "SELECT 'CONNECTION_TEST_OK';"
@@ -221,8 +255,8 @@ func (ct *ConnectionTester) TestPostgreSQLConnection(pgConfig *config.PostgreCon
 		result.Success = true
 		result.Message = "✅ PostgreSQL数据库连接成功"
 		result.ResponseTime = time.Since(startTime)
-		result.Details = fmt.Sprintf("连接到 %s:%d，响应时间: %v",
-			pgConfig.Host, pgConfig.Port, result.ResponseTime)
+		result.Details = fmt.Sprintf("连接到 %s:%d（账号: %s），响应时间: %v",
+			pgConfig.Host, pgConfig.Port, pgConfig.Username, result.ResponseTime)
 		logrus.Infof("PostgreSQL连接测试成功，响应时间: %v", result.ResponseTime)
 	} else {
 		result.Error = "PostgreSQL连接测试失败"
@@ -241,6 +275,19 @@ func (ct *ConnectionTester) findOracleTool(toolName string) (string, error) {
 		toolName += ".exe"
 	}
 
+	// 配置指定了客户端时只在该目录中查找，不回退到PATH
+	if home := ct.clientDetector.ConfiguredHome(); home != "" {
+		for _, toolPath := range []string{
+			filepath.Join(home, toolName),
+			filepath.Join(home, "bin", toolName),
+		} {
+			if _, err := exec.LookPath(toolPath); err == nil {
+				return toolPath, nil
+			}
+		}
+		return "", fmt.Errorf("配置指定的Oracle客户端 %s 中未找到工具: %s", home, toolName)
+	}
+
 	// 1. 首先在PATH中查找
 	if path, err := exec.LookPath(toolName); err == nil {
 		return path, nil
@@ -305,8 +352,8 @@ func (ct *ConnectionTester) extractOracleError(output string) string {
 	return "数据库连接失败"
 }
 
-// GetConnectionDiagnostics 获取连接诊断信息
-func (ct *ConnectionTester) GetConnectionDiagnostics(oracleConfig *config.OracleConfig) []string {
+// GetConnectionDiagnostics 获取连接诊断信息，能识别失败原因时给出根因和对应修复步骤
+func (ct *ConnectionTester) GetConnectionDiagnostics(oracleConfig *config.OracleConfig, result *ConnectionResult) []string {
 	var diagnostics []string
 
 	// 检查Oracle客户端
@@ -326,7 +373,22 @@ func (ct *ConnectionTester) GetConnectionDiagnostics(oracleConfig *config.Oracle
 		diagnostics = append(diagnostics, fmt.Sprintf("📁 路径: %s", clientInfo.Home))
 	}
 
-	// 检查网络连通性
+	// 根据实际错误分析根因
+	if diagnosis := ct.DiagnoseConnectionFailure(result, oracleConfig); diagnosis != nil {
+		diagnostics = append(diagnostics, "")
+		if diagnosis.Code != "" {
+			diagnostics = append(diagnostics, fmt.Sprintf("🎯 根因（%s）: %s", diagnosis.Code, diagnosis.Cause))
+		} else {
+			diagnostics = append(diagnostics, fmt.Sprintf("🎯 根因: %s", diagnosis.Cause))
+		}
+		diagnostics = append(diagnostics, "🔧 修复步骤:")
+		for i, fix := range diagnosis.Fixes {
+			diagnostics = append(diagnostics, fmt.Sprintf("%d. %s", i+1, fix))
+		}
+		return diagnostics
+	}
+
+	// 无法识别时给出通用建议
 	diagnostics = append(diagnostics, "")
 	diagnostics = append(diagnostics, "🔍 连接诊断建议:")
 	diagnostics = append(diagnostics, "1. 检查数据库服务器是否运行")
@@ -0,0 +1,214 @@
+package oracle
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"ora2pg-admin/internal/utils"
+)
+
+// DefaultConnectionHistoryPath 默认连接测试历史文件路径（相对于项目根目录），每行一条JSON记录
+var DefaultConnectionHistoryPath = filepath.Join(".ora2pg-admin", "connection_history.jsonl")
+
+// 连接测试的数据库
+const (
+	ConnectionTargetOracle     = "oracle"
+	ConnectionTargetPostgreSQL = "postgresql"
+)
+
+// 异常响应时间的判定：超过中位数的倍数，且比中位数至少慢 anomalyMinDelta，避免毫秒级抖动被标记
+const (
+	anomalyFactor   = 2.0
+	anomalyMinDelta = 50 * time.Millisecond
+	// anomalyMinSamples 成功样本少于该数量时不判定异常
+	anomalyMinSamples = 3
+)
+
+// sparkBlocks 文本趋势图使用的字符，从低到高
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// ConnectionSample 一次连接测试的记录
+type ConnectionSample struct {
+	Time         time.Time     `json:"time"`
+	Target       string        `json:"target"`
+	Endpoint     string        `json:"endpoint"`
+	Success      bool          `json:"success"`
+	ResponseTime time.Duration `json:"response_time"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// NewConnectionSample 根据连接测试结果生成记录
+func NewConnectionSample(target, endpoint string, result *ConnectionResult) ConnectionSample {
+	return ConnectionSample{
+		Time:         time.Now(),
+		Target:       target,
+		Endpoint:     endpoint,
+		Success:      result.Success,
+		ResponseTime: result.ResponseTime,
+		Error:        result.Error,
+	}
+}
+
+// AppendConnectionSamples 追加连接测试记录
+func AppendConnectionSamples(path string, samples ...ConnectionSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	var data []byte
+	for _, sample := range samples {
+		line, err := json.Marshal(sample)
+		if err != nil {
+			return fmt.Errorf("序列化连接测试记录失败: %v", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return utils.FileErrors.CreateFailed(filepath.Dir(path), err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return utils.FileErrors.WriteFailed(path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		return utils.FileErrors.WriteFailed(path, err)
+	}
+	return nil
+}
+
+// LoadConnectionSamples 加载指定数据库最近 limit 次的连接测试记录，按时间先后排列；limit 为0表示全部，文件不存在时返回空
+func LoadConnectionSamples(path, target string, limit int) ([]ConnectionSample, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, utils.FileErrors.ReadFailed(path, err)
+	}
+	defer file.Close()
+
+	var samples []ConnectionSample
+	scanner := bufio.NewScanner(file)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var sample ConnectionSample
+		if err := json.Unmarshal([]byte(line), &sample); err != nil {
+			// 单条记录损坏不影响其他记录
+			logrus.Warnf("跳过损坏的连接测试记录 %s:%d: %v", path, lineNo, err)
+			continue
+		}
+		if sample.Target == target {
+			samples = append(samples, sample)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, utils.FileErrors.ReadFailed(path, err)
+	}
+
+	if limit > 0 && len(samples) > limit {
+		samples = samples[len(samples)-limit:]
+	}
+	return samples, nil
+}
+
+// ConnectionTrend 连接响应时间的趋势统计，只统计成功的连接
+type ConnectionTrend struct {
+	Samples []ConnectionSample `json:"samples"`
+	// Anomalies 与 Samples 对应，响应时间异常或连接失败时为 true
+	Anomalies []bool        `json:"anomalies"`
+	Failures  int           `json:"failures"`
+	Min       time.Duration `json:"min"`
+	Max       time.Duration `json:"max"`
+	Median    time.Duration `json:"median"`
+	Average   time.Duration `json:"average"`
+	// Threshold 判定异常的响应时间阈值，样本不足时为0
+	Threshold time.Duration `json:"threshold"`
+}
+
+// AnalyzeConnectionTrend 统计响应时间并标记异常：连接失败，或响应时间超过中位数的2倍且至少慢50ms
+func AnalyzeConnectionTrend(samples []ConnectionSample) *ConnectionTrend {
+	trend := &ConnectionTrend{Samples: samples, Anomalies: make([]bool, len(samples))}
+
+	var durations []time.Duration
+	var total time.Duration
+	for i, sample := range samples {
+		if !sample.Success {
+			trend.Failures++
+			trend.Anomalies[i] = true
+			continue
+		}
+		durations = append(durations, sample.ResponseTime)
+		total += sample.ResponseTime
+	}
+	if len(durations) == 0 {
+		return trend
+	}
+
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	trend.Min = sorted[0]
+	trend.Max = sorted[len(sorted)-1]
+	trend.Average = total / time.Duration(len(sorted))
+	if n := len(sorted); n%2 == 1 {
+		trend.Median = sorted[n/2]
+	} else {
+		trend.Median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+
+	if len(sorted) < anomalyMinSamples {
+		return trend
+	}
+	trend.Threshold = time.Duration(float64(trend.Median) * anomalyFactor)
+	if trend.Threshold < trend.Median+anomalyMinDelta {
+		trend.Threshold = trend.Median + anomalyMinDelta
+	}
+	for i, sample := range samples {
+		if sample.Success && sample.ResponseTime > trend.Threshold {
+			trend.Anomalies[i] = true
+		}
+	}
+	return trend
+}
+
+// AnomalyCount 异常的记录数，含连接失败
+func (t *ConnectionTrend) AnomalyCount() int {
+	count := 0
+	for _, anomaly := range t.Anomalies {
+		if anomaly {
+			count++
+		}
+	}
+	return count
+}
+
+// Sparkline 按响应时间绘制的文本趋势图，失败的连接显示为 ✗
+func (t *ConnectionTrend) Sparkline() string {
+	var builder strings.Builder
+	span := t.Max - t.Min
+	for _, sample := range t.Samples {
+		if !sample.Success {
+			builder.WriteRune('✗')
+			continue
+		}
+		level := 0
+		if span > 0 {
+			level = int(float64(sample.ResponseTime-t.Min) / float64(span) * float64(len(sparkBlocks)-1))
+		}
+		builder.WriteRune(sparkBlocks[level])
+	}
+	return builder.String()
+}
@@ -0,0 +1,75 @@
+package oracle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionHistoryAppendAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "connection_history.jsonl")
+
+	// 文件不存在时返回空
+	samples, err := LoadConnectionSamples(path, ConnectionTargetOracle, 0)
+	require.NoError(t, err)
+	assert.Empty(t, samples)
+
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, AppendConnectionSamples(path,
+			NewConnectionSample(ConnectionTargetOracle, "db:1521/ORCL", &ConnectionResult{Success: true, ResponseTime: time.Duration(i) * time.Millisecond}),
+			NewConnectionSample(ConnectionTargetPostgreSQL, "pg:5432/app", &ConnectionResult{Success: false, Error: "timeout"}),
+		))
+	}
+	// 损坏的记录被跳过
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = file.WriteString("{broken\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	samples, err = LoadConnectionSamples(path, ConnectionTargetOracle, 2)
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.Equal(t, 2*time.Millisecond, samples[0].ResponseTime)
+	assert.Equal(t, 3*time.Millisecond, samples[1].ResponseTime)
+	assert.Equal(t, "db:1521/ORCL", samples[1].Endpoint)
+
+	samples, err = LoadConnectionSamples(path, ConnectionTargetPostgreSQL, 0)
+	require.NoError(t, err)
+	require.Len(t, samples, 3)
+	assert.False(t, samples[0].Success)
+	assert.Equal(t, "timeout", samples[0].Error)
+}
+
+func TestAnalyzeConnectionTrend(t *testing.T) {
+	sample := func(ms int) ConnectionSample {
+		return ConnectionSample{Success: true, ResponseTime: time.Duration(ms) * time.Millisecond}
+	}
+	samples := []ConnectionSample{sample(100), sample(110), sample(90), {Success: false, Error: "ORA-12170"}, sample(400), sample(120)}
+
+	trend := AnalyzeConnectionTrend(samples)
+	assert.Equal(t, 1, trend.Failures)
+	assert.Equal(t, 90*time.Millisecond, trend.Min)
+	assert.Equal(t, 400*time.Millisecond, trend.Max)
+	assert.Equal(t, 110*time.Millisecond, trend.Median)
+	assert.Equal(t, 164*time.Millisecond, trend.Average)
+	assert.Equal(t, 220*time.Millisecond, trend.Threshold)
+	assert.Equal(t, []bool{false, false, false, true, true, false}, trend.Anomalies)
+	assert.Equal(t, 2, trend.AnomalyCount())
+	assert.Equal(t, "▁▁▁✗█▁", trend.Sparkline())
+
+	// 毫秒级的响应时间波动不算异常
+	trend = AnalyzeConnectionTrend([]ConnectionSample{sample(2), sample(3), sample(2), sample(9)})
+	assert.Equal(t, 52500*time.Microsecond, trend.Threshold)
+	assert.Zero(t, trend.AnomalyCount())
+	assert.Equal(t, "▁▂▁█", trend.Sparkline())
+
+	// 样本不足时不判定异常
+	trend = AnalyzeConnectionTrend([]ConnectionSample{sample(10), sample(500)})
+	assert.Zero(t, trend.Threshold)
+	assert.Zero(t, trend.AnomalyCount())
+}
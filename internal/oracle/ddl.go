@@ -0,0 +1,282 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// DDL查询输出行的标记
+const (
+	ddlObjectMarker = "OBJ|"
+	ddlMarker       = "@@DDL|"
+	ddlDoneMarker   = ddlMarker + "DONE"
+)
+
+// ddlChunkSize 每个输出行最多包含的DDL字符数，超长的行分段输出，避免超过 DBMS_OUTPUT 单行 32767 字节的限制
+const ddlChunkSize = 4000
+
+// DDLObjectTypes 支持导出DDL的对象类型，与 all_objects 中的 object_type 一致
+//
+// 包和类型的DDL包含规范和主体；表的DDL包含约束，由约束自动创建的索引不单独导出。
+var DDLObjectTypes = []string{
+	"TABLE", "VIEW", "MATERIALIZED VIEW", "SEQUENCE", "INDEX", "TRIGGER",
+	"FUNCTION", "PROCEDURE", "PACKAGE", "TYPE", "SYNONYM",
+}
+
+// ParseDDLObjectTypes 解析并校验对象类型，不区分大小写，下划线等同于空格（如 materialized_view）
+func ParseDDLObjectTypes(values []string) ([]string, error) {
+	var types []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		objectType := strings.ToUpper(strings.Join(strings.Fields(strings.ReplaceAll(value, "_", " ")), " "))
+		if objectType == "" || seen[objectType] {
+			continue
+		}
+		supported := false
+		for _, t := range DDLObjectTypes {
+			supported = supported || t == objectType
+		}
+		if !supported {
+			return nil, utils.NewError(utils.ErrorTypeValidation, "INVALID_DDL_OBJECT_TYPE").
+				Message(fmt.Sprintf("不支持导出 %s 的DDL", value)).
+				Suggestion("可选类型: " + strings.Join(DDLObjectTypes, ", ")).
+				Build()
+		}
+		seen[objectType] = true
+		types = append(types, objectType)
+	}
+	return types, nil
+}
+
+// DDLObject 源库中的一个对象
+type DDLObject struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+// DDLResult 一个对象的DDL导出结果，失败时 Error 为Oracle返回的错误
+type DDLResult struct {
+	DDLObject
+	DDL   string `json:"-"`
+	Error string `json:"error,omitempty"`
+}
+
+// DDLOptions DDL导出选项
+type DDLOptions struct {
+	// SegmentAttributes 保留表空间、存储参数等段属性，默认去掉以便对比不同环境的快照
+	SegmentAttributes bool
+}
+
+// DDLExporter 通过 DBMS_METADATA.GET_DDL 导出Schema中对象的DDL
+type DDLExporter struct {
+	runner  *SQLPlusRunner
+	schema  string
+	options DDLOptions
+}
+
+// NewDDLExporter 创建DDL导出器
+func NewDDLExporter(runner *SQLPlusRunner, schema string, options DDLOptions) *DDLExporter {
+	return &DDLExporter{
+		runner:  runner,
+		schema:  strings.ToUpper(strings.TrimSpace(schema)),
+		options: options,
+	}
+}
+
+// Schema 导出的Schema名称
+func (e *DDLExporter) Schema() string {
+	return e.schema
+}
+
+// ListObjects 按对象类型和名称顺序列出要导出的对象，不包含系统生成的对象和回收站中的对象
+func (e *DDLExporter) ListObjects(ctx context.Context, types []string) ([]DDLObject, error) {
+	if len(types) == 0 {
+		types = DDLObjectTypes
+	}
+	quoted := make([]string, len(types))
+	for i, objectType := range types {
+		quoted[i] = quoteLiteral(objectType)
+	}
+	// 物化视图的容器表与物化视图同名，只导出物化视图
+	query := fmt.Sprintf(`SELECT '%s' || o.object_type || '|' || o.object_name FROM all_objects o
+WHERE o.owner = %s AND o.object_type IN (%s)
+  AND o.generated = 'N' AND o.object_name NOT LIKE 'BIN$%%'
+  AND NOT (o.object_type = 'TABLE' AND EXISTS (
+    SELECT 1 FROM all_mviews m WHERE m.owner = o.owner AND m.mview_name = o.object_name))
+ORDER BY o.object_type, o.object_name;`, ddlObjectMarker, quoteLiteral(e.schema), strings.Join(quoted, ", "))
+
+	output, err := e.runner.Run(ctx, query)
+	if err != nil {
+		return nil, e.exportError(fmt.Sprintf("列出 %s 的对象失败", e.schema), err)
+	}
+	return parseDDLObjects(output), nil
+}
+
+// parseDDLObjects 解析对象列表查询的输出
+func parseDDLObjects(output string) []DDLObject {
+	var objects []DDLObject
+	for _, line := range strings.Split(output, "\n") {
+		fields, found := strings.CutPrefix(strings.TrimSpace(line), ddlObjectMarker)
+		if !found {
+			continue
+		}
+		if objectType, name, ok := strings.Cut(fields, "|"); ok && name != "" {
+			objects = append(objects, DDLObject{Type: objectType, Name: name})
+		}
+	}
+	return objects
+}
+
+// Export 在一个sqlplus会话中导出一批对象的DDL，按传入顺序返回结果
+//
+// 单个对象导出失败（如权限不足）只记录在该对象的结果中，不影响同一批的其他对象。
+func (e *DDLExporter) Export(ctx context.Context, objects []DDLObject) ([]DDLResult, error) {
+	if len(objects) == 0 {
+		return nil, nil
+	}
+	output, err := e.runner.Run(ctx, e.exportScript(objects))
+	// DDL源码中出现的 ORA- 字样也会被识别为错误，输出了结束标记说明脚本已完整执行
+	if err != nil && !hasDDLDoneMarker(output) {
+		return nil, e.exportError(fmt.Sprintf("导出 %s 的DDL失败", e.schema), err)
+	}
+
+	parsed := parseDDLOutput(output)
+	results := make([]DDLResult, len(objects))
+	for i, object := range objects {
+		result, found := parsed[object]
+		if !found {
+			result = DDLResult{DDLObject: object, Error: "未返回DDL"}
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// exportScript 生成逐个调用 GET_DDL 并按行输出的PL/SQL块
+func (e *DDLExporter) exportScript(objects []DDLObject) string {
+	var script strings.Builder
+	script.WriteString("SET SERVEROUTPUT ON SIZE UNLIMITED FORMAT WRAPPED\n")
+	fmt.Fprintf(&script, `DECLARE
+  PROCEDURE emit(p_type IN VARCHAR2, p_name IN VARCHAR2) IS
+    l_ddl   CLOB;
+    l_len   PLS_INTEGER;
+    l_pos   PLS_INTEGER := 1;
+    l_eol   PLS_INTEGER;
+    l_size  PLS_INTEGER;
+    l_first BOOLEAN;
+  BEGIN
+    l_ddl := DBMS_METADATA.GET_DDL(REPLACE(p_type, ' ', '_'), p_name, %[1]s);
+    DBMS_OUTPUT.PUT_LINE('%[2]sB|' || p_type || '|' || p_name);
+    l_len := DBMS_LOB.GETLENGTH(l_ddl);
+    WHILE l_pos <= l_len LOOP
+      l_eol := DBMS_LOB.INSTR(l_ddl, CHR(10), l_pos);
+      IF l_eol = 0 THEN
+        l_eol := l_len + 1;
+      END IF;
+      l_first := TRUE;
+      LOOP
+        l_size := LEAST(l_eol - l_pos, %[3]d);
+        DBMS_OUTPUT.PUT_LINE(CASE WHEN l_first THEN '%[2]sL|' ELSE '%[2]sC|' END ||
+          CASE WHEN l_size > 0 THEN DBMS_LOB.SUBSTR(l_ddl, l_size, l_pos) END);
+        l_pos := l_pos + l_size;
+        l_first := FALSE;
+        EXIT WHEN l_pos >= l_eol;
+      END LOOP;
+      l_pos := l_eol + 1;
+    END LOOP;
+    DBMS_OUTPUT.PUT_LINE('%[2]sZ');
+  EXCEPTION
+    WHEN OTHERS THEN
+      DBMS_OUTPUT.PUT_LINE('%[2]sE|' || p_type || '|' || p_name || '|' || REPLACE(SQLERRM, CHR(10), ' '));
+  END;
+BEGIN
+  DBMS_METADATA.SET_TRANSFORM_PARAM(DBMS_METADATA.SESSION_TRANSFORM, 'PRETTY', TRUE);
+  DBMS_METADATA.SET_TRANSFORM_PARAM(DBMS_METADATA.SESSION_TRANSFORM, 'SQLTERMINATOR', TRUE);
+`, quoteLiteral(e.schema), ddlMarker, ddlChunkSize)
+	if !e.options.SegmentAttributes {
+		script.WriteString("  DBMS_METADATA.SET_TRANSFORM_PARAM(DBMS_METADATA.SESSION_TRANSFORM, 'SEGMENT_ATTRIBUTES', FALSE);\n")
+	}
+	for _, object := range objects {
+		fmt.Fprintf(&script, "  emit(%s, %s);\n", quoteLiteral(object.Type), quoteLiteral(object.Name))
+	}
+	script.WriteString("END;\n/\n")
+	fmt.Fprintf(&script, "PROMPT %s\n", ddlDoneMarker)
+	return script.String()
+}
+
+// hasDDLDoneMarker 输出中是否有单独一行的结束标记
+func hasDDLDoneMarker(output string) bool {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == ddlDoneMarker {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDDLOutput 解析导出脚本的输出，分段输出的超长行重新拼接
+func parseDDLOutput(output string) map[DDLObject]DDLResult {
+	results := make(map[DDLObject]DDLResult)
+	var (
+		current *DDLObject
+		lines   []string
+	)
+	for _, line := range strings.Split(output, "\n") {
+		rest, found := strings.CutPrefix(strings.TrimRight(line, "\r"), ddlMarker)
+		if !found || len(rest) < 1 {
+			continue
+		}
+		kind, text, _ := strings.Cut(rest, "|")
+		switch kind {
+		case "B":
+			if objectType, name, ok := strings.Cut(text, "|"); ok {
+				current, lines = &DDLObject{Type: objectType, Name: name}, nil
+			}
+		case "L":
+			lines = append(lines, text)
+		case "C":
+			if len(lines) > 0 {
+				lines[len(lines)-1] += text
+			}
+		case "Z":
+			if current != nil {
+				results[*current] = DDLResult{DDLObject: *current, DDL: strings.TrimSpace(strings.Join(lines, "\n"))}
+			}
+			current, lines = nil, nil
+		case "E":
+			fields := strings.SplitN(text, "|", 3)
+			if len(fields) == 3 {
+				object := DDLObject{Type: fields[0], Name: fields[1]}
+				results[object] = DDLResult{DDLObject: object, Error: strings.TrimSpace(fields[2])}
+			}
+			current, lines = nil, nil
+		}
+	}
+	return results
+}
+
+// exportError 构建导出失败的错误，区分没有 DBMS_METADATA 执行权限的情况
+func (e *DDLExporter) exportError(message string, err error) error {
+	var sqlErr *SQLPlusError
+	if errors.As(err, &sqlErr) && strings.Contains(sqlErr.Output, "PLS-00201") && strings.Contains(sqlErr.Output, "DBMS_METADATA") {
+		return utils.NewError(utils.ErrorTypeOracle, "ORACLE_DDL_PERMISSION").
+			Message(message + "：无法调用 DBMS_METADATA").
+			Details(sqlErr.Error()).
+			Cause(err).
+			Suggestion("请DBA授予 EXECUTE ON DBMS_METADATA 权限").
+			Build()
+	}
+	if utils.GetErrorCode(err) != "UNKNOWN" {
+		return err
+	}
+	return utils.NewError(utils.ErrorTypeOracle, "ORACLE_DDL_FAILED").
+		Message(message).
+		Details(err.Error()).
+		Cause(err).
+		Suggestion("运行 'ora2pg-admin 检查 连接' 确认源库连接和字典视图访问权限").
+		Build()
+}
@@ -0,0 +1,118 @@
+package oracle
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+func TestParseDDLObjectTypes(t *testing.T) {
+	types, err := ParseDDLObjectTypes([]string{"table", " materialized_view ", "TABLE", ""})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TABLE", "MATERIALIZED VIEW"}, types)
+
+	types, err = ParseDDLObjectTypes(nil)
+	require.NoError(t, err)
+	assert.Empty(t, types)
+
+	_, err = ParseDDLObjectTypes([]string{"TABLE", "JOB"})
+	assert.Equal(t, "INVALID_DDL_OBJECT_TYPE", utils.GetErrorCode(err))
+}
+
+func TestParseDDLOutput(t *testing.T) {
+	output := strings.Join([]string{
+		"Connected.",
+		ddlMarker + "B|TABLE|EMP",
+		ddlMarker + "L|",
+		ddlMarker + "L|  CREATE TABLE \"SCOTT\".\"EMP\"",
+		ddlMarker + "L|   (\"ID\" NUMBER, ",
+		ddlMarker + "C|\"NAME\" VARCHAR2(10));",
+		ddlMarker + "Z",
+		ddlMarker + "E|VIEW|V_EMP|ORA-31603: object \"V_EMP\" of type VIEW not found",
+		// 输出了一半后出错的对象以错误为准
+		ddlMarker + "B|PACKAGE|PKG",
+		ddlMarker + "L|CREATE PACKAGE",
+		ddlMarker + "E|PACKAGE|PKG|ORA-01031: insufficient privileges",
+		ddlDoneMarker,
+	}, "\n")
+
+	results := parseDDLOutput(output)
+	require.Len(t, results, 3)
+	emp := results[DDLObject{Type: "TABLE", Name: "EMP"}]
+	assert.Empty(t, emp.Error)
+	assert.Equal(t, "CREATE TABLE \"SCOTT\".\"EMP\"\n   (\"ID\" NUMBER, \"NAME\" VARCHAR2(10));", emp.DDL)
+	assert.Contains(t, results[DDLObject{Type: "VIEW", Name: "V_EMP"}].Error, "ORA-31603")
+	pkg := results[DDLObject{Type: "PACKAGE", Name: "PKG"}]
+	assert.Contains(t, pkg.Error, "ORA-01031")
+	assert.Empty(t, pkg.DDL)
+
+	assert.True(t, hasDDLDoneMarker(output))
+	assert.False(t, hasDDLDoneMarker(ddlMarker+"L|"+ddlDoneMarker))
+}
+
+func TestDDLExportScript(t *testing.T) {
+	exporter := NewDDLExporter(nil, "scott", DDLOptions{})
+	assert.Equal(t, "SCOTT", exporter.Schema())
+	script := exporter.exportScript([]DDLObject{{Type: "TABLE", Name: "EMP"}, {Type: "VIEW", Name: "O'REILLY"}})
+	assert.Contains(t, script, "GET_DDL(REPLACE(p_type, ' ', '_'), p_name, 'SCOTT')")
+	assert.Contains(t, script, "'SEGMENT_ATTRIBUTES', FALSE")
+	assert.Contains(t, script, "emit('TABLE', 'EMP');")
+	assert.Contains(t, script, "emit('VIEW', 'O''REILLY');")
+	assert.True(t, strings.HasSuffix(script, "PROMPT "+ddlDoneMarker+"\n"))
+
+	script = NewDDLExporter(nil, "scott", DDLOptions{SegmentAttributes: true}).exportScript([]DDLObject{{Type: "TABLE", Name: "EMP"}})
+	assert.NotContains(t, script, "SEGMENT_ATTRIBUTES")
+}
+
+func TestDDLExporterWithFakeSQLPlus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟sqlplus依赖 /bin/sh")
+	}
+
+	home := t.TempDir()
+	// 存储过程源码中的 ORA- 字样不应导致整批失败
+	script := `#!/bin/sh
+input=$(cat)
+case "$input" in
+  *"FROM all_objects"*) printf 'OBJ|PROCEDURE|P_EMP\nOBJ|TABLE|EMP\n' ;;
+  *"emit("*)
+    printf '@@DDL|B|PROCEDURE|P_EMP\n@@DDL|L|CREATE PROCEDURE P_EMP AS\n@@DDL|L|BEGIN -- raises ORA-20001\n@@DDL|L|NULL; END;\n@@DDL|Z\n'
+    echo '@@DDL|DONE' ;;
+  *) echo "ORA-00942: table or view does not exist"; exit 1 ;;
+esac
+`
+	require.NoError(t, os.WriteFile(filepath.Join(home, "sqlplus"), []byte(script), 0755))
+	runner := NewSQLPlusRunner(&config.OracleConfig{
+		Host:     "localhost",
+		Port:     1521,
+		Service:  "ORCL",
+		Username: "scott",
+		Password: "tiger",
+	}, &config.OracleClientConfig{Home: home, AutoDetect: false})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	exporter := NewDDLExporter(runner, "scott", DDLOptions{})
+	objects, err := exporter.ListObjects(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []DDLObject{{Type: "PROCEDURE", Name: "P_EMP"}, {Type: "TABLE", Name: "EMP"}}, objects)
+
+	results, err := exporter.Export(ctx, objects)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Contains(t, results[0].DDL, "ORA-20001")
+	assert.Empty(t, results[0].Error)
+	// 没有返回DDL的对象记为失败
+	assert.Equal(t, "未返回DDL", results[1].Error)
+}
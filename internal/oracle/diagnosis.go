@@ -0,0 +1,315 @@
+package oracle
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"ora2pg-admin/internal/config"
+)
+
+// ConnectionDiagnosis 连接失败的根因分析结果
+type ConnectionDiagnosis struct {
+	Code  string   `json:"code,omitempty"` // 匹配到的错误码，如 ORA-12154
+	Cause string   `json:"cause"`          // 根因
+	Fixes []string `json:"fixes"`          // 修复步骤
+}
+
+// diagnosisRule 知识库条目：错误码或输出模式 → 根因 → 修复步骤
+type diagnosisRule struct {
+	codes   []string       // 错误号，ORA-/TNS- 前缀通用
+	pattern *regexp.Regexp // 无错误码时按输出内容匹配
+	cause   string
+	fixes   func(cfg *config.OracleConfig) []string
+}
+
+// oracleErrorCodePattern 匹配 ORA-/TNS- 错误码
+var oracleErrorCodePattern = regexp.MustCompile(`\b(?:ORA|TNS)-(\d{5})\b`)
+
+// diagnosisRules 连接错误知识库，按顺序匹配，先匹配更具体的错误
+var diagnosisRules = []diagnosisRule{
+	{
+		codes: []string{"01017"},
+		cause: "用户名或密码错误",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{
+				fmt.Sprintf("确认账号 %s 的密码，Oracle 11g 及以上版本密码区分大小写", cfg.Username),
+				"运行 'ora2pg-admin 配置 数据库' 重新输入密码",
+				"如配置了只读测试账号，同时检查 test_username/test_password",
+			}
+		},
+	},
+	{
+		codes: []string{"28000"},
+		cause: "账号已被锁定（通常是多次密码错误导致）",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{
+				fmt.Sprintf("请DBA执行: ALTER USER %s ACCOUNT UNLOCK;", strings.ToUpper(cfg.Username)),
+				"解锁前先确认配置中的密码正确，避免再次锁定",
+			}
+		},
+	},
+	{
+		codes: []string{"28001"},
+		cause: "账号密码已过期",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{
+				fmt.Sprintf("请DBA执行: ALTER USER %s IDENTIFIED BY <新密码>;", strings.ToUpper(cfg.Username)),
+				"修改后运行 'ora2pg-admin 配置 数据库' 更新密码",
+			}
+		},
+	},
+	{
+		codes: []string{"01045"},
+		cause: "账号缺少 CREATE SESSION 权限，无法登录",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{
+				fmt.Sprintf("请DBA执行: GRANT CREATE SESSION TO %s;", strings.ToUpper(cfg.Username)),
+			}
+		},
+	},
+	{
+		codes: []string{"28040"},
+		cause: "客户端与服务器没有匹配的认证协议，通常是客户端版本过旧或服务器限制了登录版本",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{
+				"升级Oracle客户端到与数据库相近的版本",
+				"或请DBA在服务器 sqlnet.ora 中调整 SQLNET.ALLOWED_LOGON_VERSION_SERVER",
+			}
+		},
+	},
+	{
+		codes: []string{"28759", "28778"},
+		cause: "TCPS 连接无法打开 wallet 文件",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{
+				fmt.Sprintf("确认 oracle.wallet_dir（当前 %q）存在且当前用户可读，其中包含 cwallet.sso", cfg.WalletDir),
+				"使用 orapki wallet display -wallet <目录> 检查 wallet 内容",
+				"使用 tns_admin 时确认其中 sqlnet.ora 的 WALLET_LOCATION 指向正确的目录",
+			}
+		},
+	},
+	{
+		codes: []string{"29024", "29039"},
+		cause: "TCPS 连接校验服务器证书失败，wallet 中没有签发服务器证书的CA",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{
+				"向DBA获取服务器证书的CA（含中间CA），执行 orapki wallet add -wallet <目录> -trusted_cert -cert <CA证书> -auto_login_only 导入",
+				fmt.Sprintf("确认连接的是 TCPS 端口（当前 %d，通常为 %d）", cfg.Port, config.DefaultOracleTCPSPort),
+			}
+		},
+	},
+	{
+		codes: []string{"29003"},
+		cause: "TCPS 连接的服务器证书DN与期望不一致",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{
+				"向DBA确认服务器证书的DN，填写到 oracle.ssl_server_cert_dn",
+				fmt.Sprintf("证书签发给其他主机名时，确认 oracle.host（当前 %s）使用证书中的主机名", cfg.Host),
+				"确认证书可信、只是DN不匹配时，可设置 ssl_verify: ca 只校验证书链",
+			}
+		},
+	},
+	{
+		codes: []string{"28860", "28862", "29019"},
+		cause: "加密连接握手失败，可能连接了明文端口或客户端与服务器没有共同支持的TLS版本",
+		fixes: func(cfg *config.OracleConfig) []string {
+			if !cfg.UseTCPS {
+				return []string{
+					"服务器可能只接受 TCPS 加密连接，设置 oracle.use_tcps: true 并配置 wallet_dir",
+					fmt.Sprintf("确认监听端口是否为 %d", cfg.Port),
+				}
+			}
+			return []string{
+				fmt.Sprintf("确认端口 %d 是 TCPS 监听端口（通常为 %d），明文端口不能建立加密连接", cfg.Port, config.DefaultOracleTCPSPort),
+				"检查 TNS_ADMIN 中 sqlnet.ora 的 SSL_VERSION、SSL_CIPHER_SUITES 是否与服务器一致",
+				"较旧的客户端可能不支持服务器要求的 TLS 1.2/1.3，升级Oracle客户端",
+			}
+		},
+	},
+	{
+		codes: []string{"12154"},
+		cause: "无法解析连接标识符，客户端不认识配置的服务名",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{
+				fmt.Sprintf("确认服务名/SID %q 拼写正确", connectTarget(cfg)),
+				"检查客户端 sqlnet.ora 的 NAMES.DIRECTORY_PATH 是否包含 EZCONNECT",
+				"如果使用了 TNS_ADMIN，确认其中的 tnsnames.ora 可读且条目正确",
+			}
+		},
+	},
+	{
+		codes: []string{"12514"},
+		cause: "监听器可达，但没有注册配置的服务名",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{
+				fmt.Sprintf("在数据库服务器执行 lsnrctl services，确认是否存在服务 %q", connectTarget(cfg)),
+				"如果配置的其实是SID，请改为填写 sid 字段并清空 service",
+				"数据库刚启动时服务注册可能有延迟，稍后重试或执行 ALTER SYSTEM REGISTER",
+			}
+		},
+	},
+	{
+		codes: []string{"12505"},
+		cause: "监听器可达，但不认识配置的SID",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{
+				fmt.Sprintf("在数据库服务器执行 lsnrctl status，确认实例 %q 是否已注册", connectTarget(cfg)),
+				"12c 及以上版本的可插拔数据库(PDB)需要使用 service 而不是 sid",
+			}
+		},
+	},
+	{
+		codes: []string{"12541"},
+		cause: "目标主机可达，但端口上没有监听器",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{
+				fmt.Sprintf("确认监听端口是否为 %d（默认1521，TCPS 通常为2484）", cfg.Port),
+				"在数据库服务器执行 lsnrctl status，未启动时执行 lsnrctl start",
+			}
+		},
+	},
+	{
+		codes:   []string{"12170", "12535"},
+		pattern: regexp.MustCompile(`(?i)timed? ?out|超时`),
+		cause:   "连接超时，网络不通或防火墙丢弃了连接请求",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{
+				fmt.Sprintf("在本机测试端口连通性: nc -vz %s %d（或 telnet）", cfg.Host, cfg.Port),
+				fmt.Sprintf("检查防火墙/安全组是否放行 %d 端口", cfg.Port),
+				"经过VPN或跳板机时确认路由可达",
+			}
+		},
+	},
+	{
+		codes: []string{"12545"},
+		cause: "目标主机名无法解析或主机不存在",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{
+				fmt.Sprintf("确认主机名 %q 拼写正确，可用 nslookup 或 ping 测试解析", cfg.Host),
+				"必要时在 /etc/hosts 中添加解析，或直接使用IP地址",
+			}
+		},
+	},
+	{
+		codes:   []string{"12543"},
+		pattern: regexp.MustCompile(`(?i)no route to host|host unreachable`),
+		cause:   "目标主机不可达",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{
+				fmt.Sprintf("确认主机 %s 已开机且网络可达", cfg.Host),
+				"检查本机路由和网络配置",
+			}
+		},
+	},
+	{
+		codes: []string{"12516", "12519", "12520"},
+		cause: "监听器找不到可用的服务处理程序，数据库连接数可能已满",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{
+				"请DBA检查 v$resource_limit 中 processes、sessions 的使用情况",
+				"关闭空闲连接后重试，或调大 PROCESSES 参数",
+			}
+		},
+	},
+	{
+		codes: []string{"12537", "12547"},
+		cause: "连接被服务端关闭，可能受监听器访问控制限制",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{
+				"请DBA检查服务器 sqlnet.ora 中的 TCP.VALIDNODE_CHECKING 和 TCP.INVITED_NODES 是否包含本机",
+				"查看服务器的监听日志（listener.log）确认拒绝原因",
+			}
+		},
+	},
+	{
+		codes: []string{"01034", "27101"},
+		cause: "数据库实例未启动",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{
+				"请DBA确认实例状态并执行 STARTUP",
+				"RAC 或 Data Guard 环境确认连接的是可用的主库服务",
+			}
+		},
+	},
+	{
+		codes: []string{"01033"},
+		cause: "数据库正在启动或关闭中",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{"等待数据库完成启动或关闭后重试"}
+		},
+	},
+	{
+		pattern: regexp.MustCompile(`(?i)error while loading shared libraries|libclntsh|libnnz|SP2-0667`),
+		cause:   "Oracle客户端库无法加载",
+		fixes: func(cfg *config.OracleConfig) []string {
+			return []string{
+				"确认 LD_LIBRARY_PATH（macOS 为 DYLD_LIBRARY_PATH）包含Oracle客户端目录",
+				"Linux 上 Instant Client 依赖 libaio，请先安装（如 yum install libaio）",
+				"运行 'ora2pg-admin 检查 环境' 确认客户端配置",
+			}
+		},
+	},
+}
+
+// DiagnoseConnectionFailure 根据连接测试的错误和输出分析根因，无法识别时返回nil
+func (ct *ConnectionTester) DiagnoseConnectionFailure(result *ConnectionResult, oracleConfig *config.OracleConfig) *ConnectionDiagnosis {
+	if result == nil || result.Success {
+		return nil
+	}
+	output := strings.TrimSpace(result.Error + "\n" + result.Details)
+	if output == "" {
+		return nil
+	}
+	// 连接测试使用的可能是只读测试账号
+	oracleConfig = oracleConfig.ForConnectionTest()
+
+	// 优先使用 extractOracleError 提取的主要错误，再看输出中的其他错误码
+	codes := errorCodes(ct.extractOracleError(output))
+	codes = append(codes, errorCodes(output)...)
+
+	for _, code := range codes {
+		for _, rule := range diagnosisRules {
+			for _, ruleCode := range rule.codes {
+				if code == ruleCode {
+					return rule.diagnosis(oracleConfig, code, output)
+				}
+			}
+		}
+	}
+	for _, rule := range diagnosisRules {
+		if rule.pattern != nil && rule.pattern.MatchString(output) {
+			return rule.diagnosis(oracleConfig, "", output)
+		}
+	}
+	return nil
+}
+
+// diagnosis 生成诊断结果，错误码使用输出中的原始前缀
+func (r diagnosisRule) diagnosis(cfg *config.OracleConfig, code, output string) *ConnectionDiagnosis {
+	diagnosis := &ConnectionDiagnosis{Cause: r.cause, Fixes: r.fixes(cfg)}
+	if code != "" {
+		diagnosis.Code = "ORA-" + code
+		if !strings.Contains(output, diagnosis.Code) {
+			diagnosis.Code = "TNS-" + code
+		}
+	}
+	return diagnosis
+}
+
+// errorCodes 提取输出中的错误号（去掉 ORA-/TNS- 前缀）
+func errorCodes(output string) []string {
+	var codes []string
+	for _, match := range oracleErrorCodePattern.FindAllStringSubmatch(output, -1) {
+		codes = append(codes, match[1])
+	}
+	return codes
+}
+
+// connectTarget 配置中的服务名或SID
+func connectTarget(cfg *config.OracleConfig) string {
+	if cfg.Service != "" {
+		return cfg.Service
+	}
+	return cfg.SID
+}
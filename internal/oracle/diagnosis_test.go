@@ -0,0 +1,118 @@
+package oracle
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+)
+
+func TestDiagnoseConnectionFailure(t *testing.T) {
+	cfg := &config.OracleConfig{Host: "db.example.com", Port: 1522, Service: "ORCLPDB", Username: "scott"}
+	tester := NewConnectionTester()
+
+	cases := []struct {
+		name    string
+		result  *ConnectionResult
+		code    string
+		cause   string
+		fixHint string
+	}{
+		{
+			name:    "服务名无法解析",
+			result:  &ConnectionResult{Error: "ORA-12154: TNS:could not resolve the connect identifier specified"},
+			code:    "ORA-12154",
+			cause:   "无法解析连接标识符",
+			fixHint: `"ORCLPDB"`,
+		},
+		{
+			name: "密码错误（错误在sqlplus输出中）",
+			result: &ConnectionResult{
+				Error:   "sqlplus执行失败: exit status 1",
+				Details: "ERROR:\nORA-01017: invalid username/password; logon denied\n\nSP2-0751: Unable to connect to Oracle.",
+			},
+			code:    "ORA-01017",
+			cause:   "用户名或密码错误",
+			fixHint: "scott",
+		},
+		{
+			name:    "无监听（tnsping输出）",
+			result:  &ConnectionResult{Error: "tnsping执行失败: exit status 1", Details: "TNS-12541: TNS:no listener"},
+			code:    "TNS-12541",
+			cause:   "端口上没有监听器",
+			fixHint: "1522",
+		},
+		{
+			name:    "服务未注册",
+			result:  &ConnectionResult{Error: "ORA-12514: TNS:listener does not currently know of service requested in connect descriptor"},
+			code:    "ORA-12514",
+			cause:   "没有注册配置的服务名",
+			fixHint: "lsnrctl services",
+		},
+		{
+			name:    "账号锁定",
+			result:  &ConnectionResult{Error: "ORA-28000: the account is locked"},
+			code:    "ORA-28000",
+			cause:   "账号已被锁定",
+			fixHint: "ALTER USER SCOTT ACCOUNT UNLOCK",
+		},
+		{
+			name:    "TCP超时错误码",
+			result:  &ConnectionResult{Error: "ORA-12170: TNS:Connect timeout occurred"},
+			code:    "ORA-12170",
+			cause:   "连接超时",
+			fixHint: "nc -vz db.example.com 1522",
+		},
+		{
+			name:    "TCP超时无错误码",
+			result:  &ConnectionResult{Error: "数据库连接测试失败", Details: "dial tcp 10.0.0.1:1522: i/o timeout"},
+			cause:   "连接超时",
+			fixHint: "防火墙",
+		},
+		{
+			name:    "主机名无法解析",
+			result:  &ConnectionResult{Error: "ORA-12545: Connect failed because target host or object does not exist"},
+			code:    "ORA-12545",
+			cause:   "主机名无法解析",
+			fixHint: `"db.example.com"`,
+		},
+		{
+			name:    "实例未启动",
+			result:  &ConnectionResult{Error: "ORA-01034: ORACLE not available\nORA-27101: shared memory realm does not exist"},
+			code:    "ORA-01034",
+			cause:   "实例未启动",
+			fixHint: "STARTUP",
+		},
+		{
+			name:    "客户端库缺失",
+			result:  &ConnectionResult{Error: "sqlplus执行失败: exit status 127", Details: "sqlplus: error while loading shared libraries: libclntsh.so.19.1"},
+			cause:   "客户端库无法加载",
+			fixHint: "LD_LIBRARY_PATH",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			diagnosis := tester.DiagnoseConnectionFailure(tc.result, cfg)
+			require.NotNil(t, diagnosis)
+			assert.Equal(t, tc.code, diagnosis.Code)
+			assert.Contains(t, diagnosis.Cause, tc.cause)
+			assert.Contains(t, strings.Join(diagnosis.Fixes, "\n"), tc.fixHint)
+		})
+	}
+
+	// 无法识别的错误和成功结果不给出根因
+	assert.Nil(t, tester.DiagnoseConnectionFailure(&ConnectionResult{Error: "数据库连接失败"}, cfg))
+	assert.Nil(t, tester.DiagnoseConnectionFailure(&ConnectionResult{Success: true}, cfg))
+}
+
+func TestDiagnoseConnectionFailureUsesTestAccount(t *testing.T) {
+	cfg := &config.OracleConfig{Username: "app_owner", TestUsername: "readonly", TestPassword: "secret"}
+	diagnosis := NewConnectionTester().DiagnoseConnectionFailure(
+		&ConnectionResult{Error: "ORA-01017: invalid username/password; logon denied"}, cfg)
+	require.NotNil(t, diagnosis)
+	assert.Contains(t, diagnosis.Fixes[0], "readonly")
+}
@@ -0,0 +1,158 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// 查询结果行的前缀
+const (
+	inventoryMarker = "INV|"
+	tableMarker     = "TAB|"
+)
+
+// InventoryObjectTypes 参与统计的Oracle对象类型
+var InventoryObjectTypes = []string{
+	"TABLE", "VIEW", "MATERIALIZED VIEW", "SEQUENCE", "INDEX", "TRIGGER",
+	"FUNCTION", "PROCEDURE", "PACKAGE", "TYPE", "DATABASE LINK", "SYNONYM",
+}
+
+// ObjectInventory 源库Schema中各类对象的数量，键为Oracle对象类型，未出现的类型数量为0
+type ObjectInventory map[string]int
+
+// Total 所有对象的数量
+func (inv ObjectInventory) Total() int {
+	total := 0
+	for _, count := range inv {
+		total += count
+	}
+	return total
+}
+
+// Inspector 查询源库Schema的对象信息
+type Inspector struct {
+	runner *SQLPlusRunner
+	schema string
+}
+
+// NewInspector 创建源库检查器
+func NewInspector(runner *SQLPlusRunner, schema string) *Inspector {
+	return &Inspector{
+		runner: runner,
+		schema: strings.ToUpper(strings.TrimSpace(schema)),
+	}
+}
+
+// Schema 获取检查的Schema名称
+func (i *Inspector) Schema() string {
+	return i.schema
+}
+
+// SchemaInspection 一次检查得到的源库信息
+type SchemaInspection struct {
+	Schema  string          `json:"schema"`
+	Charset string          `json:"charset"`
+	Objects ObjectInventory `json:"objects"`
+}
+
+// ObjectCounts 按对象类型统计Schema中的对象数量，不包含回收站中的对象
+func (i *Inspector) ObjectCounts(ctx context.Context) (ObjectInventory, error) {
+	output, err := i.runner.Run(ctx, i.objectCountsQuery())
+	if err != nil {
+		return nil, i.inventoryError(err)
+	}
+	return parseObjectInventory(output)
+}
+
+// TableNames 查询Schema下的表名，不包含回收站中的表
+func (i *Inspector) TableNames(ctx context.Context) ([]string, error) {
+	output, err := i.runner.Run(ctx, i.tableNamesQuery())
+	if err != nil {
+		return nil, i.inventoryError(err)
+	}
+	return parseTableNames(output), nil
+}
+
+// tableNamesQuery 构建表名查询
+func (i *Inspector) tableNamesQuery() string {
+	return fmt.Sprintf(`SELECT '%s' || table_name FROM all_tables
+WHERE owner = %s AND table_name NOT LIKE 'BIN$%%'
+ORDER BY table_name;`, tableMarker, quoteLiteral(i.schema))
+}
+
+// parseTableNames 解析表名查询的输出
+func parseTableNames(output string) []string {
+	var tables []string
+	for _, line := range strings.Split(output, "\n") {
+		if name, found := strings.CutPrefix(strings.TrimSpace(line), tableMarker); found && name != "" {
+			tables = append(tables, name)
+		}
+	}
+	return tables
+}
+
+// Inspect 在同一个sqlplus会话中查询对象数量和数据库字符集
+func (i *Inspector) Inspect(ctx context.Context) (*SchemaInspection, error) {
+	outputs, err := i.runner.RunBatch(ctx, i.objectCountsQuery(), databaseCharsetQuery)
+	if err != nil {
+		return nil, i.inventoryError(err)
+	}
+
+	objects, err := parseObjectInventory(outputs[0])
+	if err != nil {
+		return nil, err
+	}
+	charset, err := parseDatabaseCharset(outputs[1])
+	if err != nil {
+		return nil, err
+	}
+	return &SchemaInspection{Schema: i.schema, Charset: charset, Objects: objects}, nil
+}
+
+// objectCountsQuery 构建对象数量统计查询
+func (i *Inspector) objectCountsQuery() string {
+	types := make([]string, len(InventoryObjectTypes))
+	for idx, objectType := range InventoryObjectTypes {
+		types[idx] = quoteLiteral(objectType)
+	}
+	return fmt.Sprintf(`SELECT '%s' || object_type || '|' || COUNT(*)
+FROM all_objects
+WHERE owner = %s AND object_type IN (%s) AND object_name NOT LIKE 'BIN$%%'
+GROUP BY object_type;`, inventoryMarker, quoteLiteral(i.schema), strings.Join(types, ", "))
+}
+
+// inventoryError 构建检查源库失败的错误
+func (i *Inspector) inventoryError(err error) error {
+	return utils.NewError(utils.ErrorTypeOracle, "ORACLE_INVENTORY_FAILED").
+		Message(fmt.Sprintf("统计 %s 的对象数量失败", i.schema)).
+		Details(err.Error()).
+		Cause(err).
+		Suggestion("运行 'ora2pg-admin 检查 连接' 确认源库连接和字典视图访问权限").
+		Build()
+}
+
+// parseObjectInventory 解析对象统计查询的输出
+func parseObjectInventory(output string) (ObjectInventory, error) {
+	inventory := make(ObjectInventory, len(InventoryObjectTypes))
+	for _, objectType := range InventoryObjectTypes {
+		inventory[objectType] = 0
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		fields, found := strings.CutPrefix(strings.TrimSpace(line), inventoryMarker)
+		if !found {
+			continue
+		}
+		objectType, countText, ok := strings.Cut(fields, "|")
+		count, err := strconv.Atoi(strings.TrimSpace(countText))
+		if !ok || err != nil {
+			return nil, fmt.Errorf("解析对象统计结果失败: %s", line)
+		}
+		inventory[strings.TrimSpace(objectType)] = count
+	}
+	return inventory, nil
+}
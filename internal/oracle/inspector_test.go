@@ -0,0 +1,80 @@
+package oracle
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+func TestParseObjectInventory(t *testing.T) {
+	inventory, err := parseObjectInventory("\nINV|TABLE|12\nINV|VIEW|3\n\n")
+	require.NoError(t, err)
+	assert.Equal(t, 12, inventory["TABLE"])
+	assert.Equal(t, 3, inventory["VIEW"])
+	// 查询结果中没有的类型数量为0
+	count, exists := inventory["PACKAGE"]
+	assert.True(t, exists)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, 15, inventory.Total())
+
+	_, err = parseObjectInventory("INV|TABLE|abc")
+	assert.Error(t, err)
+}
+
+func TestObjectCountsQueryIncludesLinksAndSynonyms(t *testing.T) {
+	query := (&Inspector{schema: "SCOTT"}).objectCountsQuery()
+	assert.Contains(t, query, "'DATABASE LINK'")
+	assert.Contains(t, query, "'SYNONYM'")
+
+	// 对象类型名中的空格不影响解析
+	inventory, err := parseObjectInventory("INV|DATABASE LINK|2\nINV|SYNONYM|5\n")
+	require.NoError(t, err)
+	assert.Equal(t, 2, inventory["DATABASE LINK"])
+	assert.Equal(t, 5, inventory["SYNONYM"])
+}
+
+func TestInspectorObjectCountsWithFakeSQLPlus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟sqlplus依赖 /bin/sh")
+	}
+
+	home := t.TempDir()
+	script := `#!/bin/sh
+input=$(cat)
+case "$input" in
+  *"owner = 'SCOTT'"*) printf 'INV|TABLE|4\nINV|INDEX|6\n' ;;
+  *) echo "ORA-00942: table or view does not exist"; exit 1 ;;
+esac
+`
+	require.NoError(t, os.WriteFile(filepath.Join(home, "sqlplus"), []byte(script), 0755))
+
+	runner := NewSQLPlusRunner(&config.OracleConfig{
+		Host:     "localhost",
+		Port:     1521,
+		Service:  "ORCL",
+		Username: "scott",
+		Password: "tiger",
+	}, &config.OracleClientConfig{Home: home, AutoDetect: false})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	inspector := NewInspector(runner, "scott")
+	assert.Equal(t, "SCOTT", inspector.Schema())
+	inventory, err := inspector.ObjectCounts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, inventory["TABLE"])
+	assert.Equal(t, 6, inventory["INDEX"])
+
+	_, err = NewInspector(runner, "hr").ObjectCounts(ctx)
+	assert.Equal(t, "ORACLE_INVENTORY_FAILED", utils.GetErrorCode(err))
+}
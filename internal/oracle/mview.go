@@ -0,0 +1,108 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// 物化视图查询结果行的前缀
+const (
+	mviewMarker           = "MV|"
+	mviewDependencyMarker = "MVD|"
+)
+
+// MaterializedView 源库物化视图的刷新设置
+type MaterializedView struct {
+	Name string `json:"name"`
+	// RefreshMode 刷新时机：DEMAND（手动或定时）、COMMIT（基表提交时）、NEVER
+	RefreshMode string `json:"refresh_mode"`
+	// RefreshMethod 刷新方式：COMPLETE、FAST（增量）、FORCE（能增量则增量）、NEVER
+	RefreshMethod string `json:"refresh_method"`
+	// BuildMode 创建方式：IMMEDIATE、DEFERRED（创建时不填充数据）、PREBUILT
+	BuildMode string `json:"build_mode"`
+	// Interval 定时刷新的间隔表达式，如 "SYSDATE + 1/24"，未设置定时刷新时为空
+	Interval string `json:"interval,omitempty"`
+	// DependsOn 查询中引用的同一Schema下的其他物化视图
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// MaterializedViews 查询Schema下的物化视图及其刷新设置和相互依赖，按名称排列
+func (i *Inspector) MaterializedViews(ctx context.Context) ([]*MaterializedView, error) {
+	outputs, err := i.runner.RunBatch(ctx, i.mviewsQuery(), i.mviewDependenciesQuery())
+	if err != nil {
+		return nil, utils.NewError(utils.ErrorTypeOracle, "ORACLE_MVIEW_QUERY_FAILED").
+			Message(fmt.Sprintf("查询 %s 的物化视图失败", i.schema)).
+			Details(err.Error()).
+			Cause(err).
+			Suggestion("确认迁移账号可以访问 ALL_MVIEWS、ALL_REFRESH_CHILDREN 和 ALL_DEPENDENCIES").
+			Build()
+	}
+	return parseMaterializedViews(outputs[0], outputs[1])
+}
+
+// mviewsQuery 构建物化视图刷新设置查询，定时刷新的间隔来自隐式刷新组
+func (i *Inspector) mviewsQuery() string {
+	return fmt.Sprintf(`SELECT '%s' || m.mview_name || '|' || m.refresh_mode || '|' || m.refresh_method || '|' || m.build_mode || '|'
+  || (SELECT MAX(rc.interval) FROM all_refresh_children rc WHERE rc.owner = m.owner AND rc.name = m.mview_name)
+FROM all_mviews m
+WHERE m.owner = %s
+ORDER BY m.mview_name;`, mviewMarker, quoteLiteral(i.schema))
+}
+
+// mviewDependenciesQuery 构建物化视图之间的依赖查询；引用其他物化视图时依赖的可能是其容器表
+func (i *Inspector) mviewDependenciesQuery() string {
+	schema := quoteLiteral(i.schema)
+	return fmt.Sprintf(`SELECT DISTINCT '%s' || d.name || '|' || d.referenced_name
+FROM all_dependencies d
+WHERE d.owner = %s AND d.type = 'MATERIALIZED VIEW'
+  AND d.referenced_owner = %s AND d.referenced_type IN ('TABLE', 'MATERIALIZED VIEW')
+  AND d.referenced_name <> d.name
+  AND d.referenced_name IN (SELECT mview_name FROM all_mviews WHERE owner = %s);`,
+		mviewDependencyMarker, schema, schema, schema)
+}
+
+// parseMaterializedViews 解析物化视图和依赖查询的输出
+func parseMaterializedViews(mviewOutput, dependencyOutput string) ([]*MaterializedView, error) {
+	var views []*MaterializedView
+	byName := make(map[string]*MaterializedView)
+	for _, line := range strings.Split(mviewOutput, "\n") {
+		fields, found := strings.CutPrefix(strings.TrimSpace(line), mviewMarker)
+		if !found {
+			continue
+		}
+		// 间隔表达式放在最后，其中可能包含分隔符
+		parts := strings.SplitN(fields, "|", 5)
+		if len(parts) < 4 {
+			return nil, fmt.Errorf("解析物化视图查询结果失败: %s", line)
+		}
+		view := &MaterializedView{
+			Name:          strings.TrimSpace(parts[0]),
+			RefreshMode:   strings.TrimSpace(parts[1]),
+			RefreshMethod: strings.TrimSpace(parts[2]),
+			BuildMode:     strings.TrimSpace(parts[3]),
+		}
+		if len(parts) == 5 {
+			view.Interval = strings.TrimSpace(parts[4])
+		}
+		views = append(views, view)
+		byName[view.Name] = view
+	}
+
+	for _, line := range strings.Split(dependencyOutput, "\n") {
+		fields, found := strings.CutPrefix(strings.TrimSpace(line), mviewDependencyMarker)
+		if !found {
+			continue
+		}
+		name, referenced, ok := strings.Cut(fields, "|")
+		if !ok {
+			return nil, fmt.Errorf("解析物化视图依赖查询结果失败: %s", line)
+		}
+		if view := byName[strings.TrimSpace(name)]; view != nil {
+			view.DependsOn = append(view.DependsOn, strings.TrimSpace(referenced))
+		}
+	}
+	return views, nil
+}
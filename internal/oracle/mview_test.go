@@ -0,0 +1,34 @@
+package oracle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMaterializedViews(t *testing.T) {
+	mviews := "\nMV|MV_DAILY|DEMAND|FAST|IMMEDIATE|SYSDATE + 1/24\nMV_REPORT_NOISE\nMV|MV_SUMMARY|COMMIT|COMPLETE|DEFERRED|\n"
+	dependencies := "MVD|MV_SUMMARY|MV_DAILY\nMVD|MV_UNKNOWN|MV_DAILY\n"
+
+	views, err := parseMaterializedViews(mviews, dependencies)
+	require.NoError(t, err)
+	require.Len(t, views, 2)
+	assert.Equal(t, &MaterializedView{
+		Name: "MV_DAILY", RefreshMode: "DEMAND", RefreshMethod: "FAST", BuildMode: "IMMEDIATE", Interval: "SYSDATE + 1/24",
+	}, views[0])
+	assert.Equal(t, "DEFERRED", views[1].BuildMode)
+	assert.Empty(t, views[1].Interval)
+	// 只记录查询到的物化视图之间的依赖
+	assert.Equal(t, []string{"MV_DAILY"}, views[1].DependsOn)
+
+	_, err = parseMaterializedViews("MV|MV_BROKEN|DEMAND", "")
+	assert.Error(t, err)
+}
+
+func TestMaterializedViewQueries(t *testing.T) {
+	inspector := NewInspector(nil, "scott")
+	assert.Contains(t, inspector.mviewsQuery(), "m.owner = 'SCOTT'")
+	assert.Contains(t, inspector.mviewsQuery(), "all_refresh_children")
+	assert.Contains(t, inspector.mviewDependenciesQuery(), "d.referenced_type IN ('TABLE', 'MATERIALIZED VIEW')")
+}
@@ -0,0 +1,212 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"ora2pg-admin/internal/config"
+)
+
+// 网络质量测量的默认参数和分级阈值
+const (
+	// DefaultLatencySamples 默认建立TCP连接测量延迟的次数
+	DefaultLatencySamples = 10
+	// localLatency、remoteLatency 平均延迟低于前者视为同机房，不低于后者视为跨地域
+	localLatency  = 5 * time.Millisecond
+	remoteLatency = 30 * time.Millisecond
+	// highJitter 抖动达到该值时提示迁移耗时波动
+	highJitter = 10 * time.Millisecond
+	// lowBandwidth 带宽低于该值（字节/秒）时视为数据迁移的瓶颈
+	lowBandwidth = 10 << 20
+	// bandwidthRowSize Oracle带宽测试每行返回的字节数（VARCHAR2 的上限）
+	bandwidthRowSize = 4000
+)
+
+// NetworkQuality 到数据库的网络质量：多次建立TCP连接的耗时统计，以及可选的带宽估算
+type NetworkQuality struct {
+	Address string `json:"address"`
+	// Samples 每次建立TCP连接的耗时（约为一次往返），失败的为0
+	Samples  []time.Duration `json:"samples"`
+	Failures int             `json:"failures"`
+	Min      time.Duration   `json:"min"`
+	Average  time.Duration   `json:"average"`
+	Max      time.Duration   `json:"max"`
+	// Jitter 相邻两次成功测量的耗时差的平均值
+	Jitter time.Duration `json:"jitter"`
+	// Bandwidth 通过数据库查询传输测试数据估算的带宽（字节/秒），未测量时为0
+	Bandwidth      float64 `json:"bandwidth,omitempty"`
+	BandwidthBytes int64   `json:"bandwidth_bytes,omitempty"`
+	BandwidthError string  `json:"bandwidth_error,omitempty"`
+}
+
+// latencySampleInterval 两次测量之间的间隔，测试时可替换
+var latencySampleInterval = 200 * time.Millisecond
+
+// latencyDial 建立一次TCP连接并返回耗时，测试时可替换
+var latencyDial = func(ctx context.Context, address string) (time.Duration, error) {
+	dialer := net.Dialer{Timeout: probeDialTimeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return 0, err
+	}
+	elapsed := time.Since(start)
+	conn.Close()
+	return elapsed, nil
+}
+
+// MeasureLatency 多次建立TCP连接测量到数据库的延迟
+//
+// 主机名只解析一次，测量的是TCP握手的往返耗时，不含DNS解析和数据库认证；无法解析主机名时返回错误。
+func MeasureLatency(ctx context.Context, host string, port, samples int) (*NetworkQuality, error) {
+	if samples <= 0 {
+		samples = DefaultLatencySamples
+	}
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("解析主机名 %s 失败: %v", host, err)
+	}
+	address := net.JoinHostPort(addrs[0], strconv.Itoa(port))
+
+	quality := &NetworkQuality{Address: net.JoinHostPort(host, strconv.Itoa(port))}
+	for i := 0; i < samples; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(latencySampleInterval):
+			}
+		}
+		elapsed, err := latencyDial(ctx, address)
+		if err != nil {
+			quality.Failures++
+		}
+		quality.Samples = append(quality.Samples, elapsed)
+	}
+	quality.summarize()
+	return quality, nil
+}
+
+// summarize 统计成功测量的最小、平均、最大耗时和抖动
+func (q *NetworkQuality) summarize() {
+	var total, jitter time.Duration
+	var previous time.Duration
+	succeeded, pairs := 0, 0
+	for _, sample := range q.Samples {
+		if sample <= 0 {
+			continue
+		}
+		if succeeded == 0 || sample < q.Min {
+			q.Min = sample
+		}
+		if sample > q.Max {
+			q.Max = sample
+		}
+		if succeeded > 0 {
+			diff := sample - previous
+			if diff < 0 {
+				diff = -diff
+			}
+			jitter += diff
+			pairs++
+		}
+		total += sample
+		previous = sample
+		succeeded++
+	}
+	if succeeded > 0 {
+		q.Average = total / time.Duration(succeeded)
+	}
+	if pairs > 0 {
+		q.Jitter = jitter / time.Duration(pairs)
+	}
+}
+
+// Reachable 是否至少有一次连接成功
+func (q *NetworkQuality) Reachable() bool {
+	return q.Failures < len(q.Samples)
+}
+
+// Level 按平均延迟划分的网络距离：同机房、同城或跨机房、跨地域
+func (q *NetworkQuality) Level() string {
+	switch {
+	case q.Average < localLatency:
+		return "同机房"
+	case q.Average < remoteLatency:
+		return "同城或跨机房"
+	default:
+		return "跨地域"
+	}
+}
+
+// Degraded 是否存在连接失败、跨地域延迟或明显抖动
+func (q *NetworkQuality) Degraded() bool {
+	return q.Failures > 0 || q.Average >= remoteLatency || q.Jitter >= highJitter
+}
+
+// MeasureBandwidth 估算带宽：分别执行不传输数据和传输 size 字节的查询，按耗时差计算
+//
+// 两次查询都包含启动客户端和登录的耗时，相减后只剩传输数据的耗时。
+func MeasureBandwidth(ctx context.Context, size int64, transfer func(ctx context.Context, size int64) error) (float64, error) {
+	start := time.Now()
+	if err := transfer(ctx, 0); err != nil {
+		return 0, err
+	}
+	baseline := time.Since(start)
+
+	start = time.Now()
+	if err := transfer(ctx, size); err != nil {
+		return 0, err
+	}
+	elapsed := time.Since(start) - baseline
+	if elapsed <= 0 {
+		return 0, fmt.Errorf("传输 %d 字节的耗时小于登录耗时的波动，无法估算带宽，请增大测试数据量", size)
+	}
+	return float64(size) / elapsed.Seconds(), nil
+}
+
+// TransferTest 在源库执行返回约 size 字节数据的查询，size 为0时只执行最简单的查询，用于测量带宽
+func (r *SQLPlusRunner) TransferTest(ctx context.Context, size int64) error {
+	script := "SELECT 1 FROM dual;"
+	if size > 0 {
+		rows := (size + bandwidthRowSize - 1) / bandwidthRowSize
+		script = fmt.Sprintf("SELECT RPAD('x', %d, 'x') FROM dual CONNECT BY LEVEL <= %d;", bandwidthRowSize, rows)
+	}
+	_, err := r.Run(ctx, script)
+	return err
+}
+
+// Advice 根据网络质量给出迁移并行度、批大小和超时的建议
+func (q *NetworkQuality) Advice(migration *config.MigrationConfig) []string {
+	var advice []string
+	if q.Failures > 0 {
+		advice = append(advice, fmt.Sprintf("%d/%d 次连接失败，网络不稳定：迁移窗口前排查网络，迁移中断后使用 --resume 续传", q.Failures, len(q.Samples)))
+	}
+	if q.Average >= remoteLatency {
+		// 每张表的数据按批往返传输，延迟越高越需要多张表同时传输来填满带宽
+		parallel := int(q.Average / (10 * time.Millisecond))
+		parallel = max(4, min(parallel, config.MaxRecommendedParallelJobs))
+		if migration.ParallelTables < parallel {
+			advice = append(advice, fmt.Sprintf("往返延迟 %v，单张表的传输会被延迟拖慢：建议把 migration.parallel_tables 提高到 %d，让多张表同时传输",
+				q.Average.Round(100*time.Microsecond), parallel))
+		}
+		if migration.BatchSize < 20000 {
+			advice = append(advice, "建议把 migration.batch_size 提高到 20000 以上，减少往返次数")
+		}
+	}
+	if q.Jitter >= highJitter {
+		advice = append(advice, fmt.Sprintf("延迟抖动 %v，迁移耗时波动较大：设置 --timeout 时在预估耗时上留出 50%% 以上的余量",
+			q.Jitter.Round(100*time.Microsecond)))
+	}
+	if q.Bandwidth > 0 {
+		perGB := time.Duration(float64(1<<30) / q.Bandwidth * float64(time.Second)).Round(time.Second)
+		advice = append(advice, fmt.Sprintf("按测得的带宽，每 GB 数据约需 %v 传输，可据此估算数据迁移耗时和 --timeout", perGB))
+		if q.Bandwidth < lowBandwidth {
+			advice = append(advice, "带宽是瓶颈，提高并行度不会加快数据迁移；可把迁移机部署在靠近数据量大的一端，或按表分批在多个窗口迁移")
+		}
+	}
+	return advice
+}
@@ -0,0 +1,109 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+)
+
+// fakeLatency 按顺序返回模拟的连接耗时，0 表示连接失败
+func fakeLatency(t *testing.T, samples ...time.Duration) {
+	originalDial, originalInterval := latencyDial, latencySampleInterval
+	t.Cleanup(func() { latencyDial, latencySampleInterval = originalDial, originalInterval })
+	latencySampleInterval = 0
+	i := 0
+	latencyDial = func(ctx context.Context, address string) (time.Duration, error) {
+		sample := samples[i%len(samples)]
+		i++
+		if sample == 0 {
+			return 0, errors.New("connection refused")
+		}
+		return sample, nil
+	}
+}
+
+func TestMeasureLatency(t *testing.T) {
+	ms := time.Millisecond
+	fakeLatency(t, 40*ms, 0, 50*ms, 30*ms)
+
+	quality, err := MeasureLatency(context.Background(), "localhost", 1521, 4)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:1521", quality.Address)
+	assert.Len(t, quality.Samples, 4)
+	assert.Equal(t, 1, quality.Failures)
+	assert.True(t, quality.Reachable())
+	assert.Equal(t, 30*ms, quality.Min)
+	assert.Equal(t, 40*ms, quality.Average)
+	assert.Equal(t, 50*ms, quality.Max)
+	// 相邻成功测量的差：|50-40|、|30-50|
+	assert.Equal(t, 15*ms, quality.Jitter)
+	assert.Equal(t, "跨地域", quality.Level())
+	assert.True(t, quality.Degraded())
+
+	_, err = MeasureLatency(context.Background(), "no-such-host.invalid", 1521, 1)
+	assert.Error(t, err)
+}
+
+func TestNetworkQualityLevel(t *testing.T) {
+	ms := time.Millisecond
+	fakeLatency(t, 2*ms)
+	quality, err := MeasureLatency(context.Background(), "localhost", 5432, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "同机房", quality.Level())
+	assert.False(t, quality.Degraded())
+	assert.Empty(t, quality.Advice(&config.MigrationConfig{}))
+
+	fakeLatency(t, 0)
+	quality, err = MeasureLatency(context.Background(), "localhost", 5432, 2)
+	require.NoError(t, err)
+	assert.False(t, quality.Reachable())
+}
+
+func TestNetworkQualityAdvice(t *testing.T) {
+	quality := &NetworkQuality{
+		Samples:   []time.Duration{80 * time.Millisecond, 0},
+		Failures:  1,
+		Average:   80 * time.Millisecond,
+		Jitter:    12 * time.Millisecond,
+		Bandwidth: 4 << 20,
+	}
+	advice := quality.Advice(&config.MigrationConfig{ParallelJobs: 4, BatchSize: 10000})
+	require.Len(t, advice, 6)
+	assert.Contains(t, advice[0], "1/2 次连接失败")
+	assert.Contains(t, advice[1], "parallel_tables 提高到 8")
+	assert.Contains(t, advice[2], "batch_size")
+	assert.Contains(t, advice[3], "--timeout")
+	assert.Contains(t, advice[4], "每 GB 数据约需 4m16s")
+	assert.Contains(t, advice[5], "带宽是瓶颈")
+
+	// 已按建议配置时不再重复建议
+	advice = quality.Advice(&config.MigrationConfig{ParallelTables: 8, BatchSize: 50000})
+	assert.Len(t, advice, 4)
+}
+
+func TestMeasureBandwidth(t *testing.T) {
+	var sizes []int64
+	bandwidth, err := MeasureBandwidth(context.Background(), 1<<20, func(ctx context.Context, size int64) error {
+		sizes = append(sizes, size)
+		if size > 0 {
+			time.Sleep(50 * time.Millisecond)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{0, 1 << 20}, sizes)
+	// 1MB 约 50ms，带宽不超过 20MB/s
+	assert.Greater(t, bandwidth, float64(1<<20))
+	assert.LessOrEqual(t, bandwidth, float64(20<<20))
+
+	_, err = MeasureBandwidth(context.Background(), 1<<20, func(ctx context.Context, size int64) error {
+		return errors.New("ORA-01017")
+	})
+	assert.Error(t, err)
+}
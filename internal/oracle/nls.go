@@ -0,0 +1,40 @@
+package oracle
+
+import (
+	"context"
+	"strings"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// nlsMarker 字符集查询结果行的前缀
+const nlsMarker = "NLS|"
+
+// databaseCharsetQuery 查询数据库字符集
+const databaseCharsetQuery = `SELECT '` + nlsMarker + `' || value FROM nls_database_parameters WHERE parameter = 'NLS_CHARACTERSET';`
+
+// DatabaseCharset 查询源库的数据库字符集（NLS_CHARACTERSET），如 AL32UTF8、ZHS16GBK
+func DatabaseCharset(ctx context.Context, runner *SQLPlusRunner) (string, error) {
+	output, err := runner.Run(ctx, databaseCharsetQuery)
+	if err != nil {
+		return "", utils.NewError(utils.ErrorTypeOracle, "ORACLE_CHARSET_QUERY_FAILED").
+			Message("查询Oracle数据库字符集失败").
+			Cause(err).
+			Build()
+	}
+	return parseDatabaseCharset(output)
+}
+
+// parseDatabaseCharset 解析字符集查询的输出
+func parseDatabaseCharset(output string) (string, error) {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if charset, found := strings.CutPrefix(line, nlsMarker); found && charset != "" {
+			return strings.ToUpper(charset), nil
+		}
+	}
+	return "", utils.NewError(utils.ErrorTypeOracle, "ORACLE_CHARSET_QUERY_FAILED").
+		Message("查询Oracle数据库字符集失败").
+		Details("sqlplus输出中没有字符集").
+		Build()
+}
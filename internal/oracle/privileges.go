@@ -0,0 +1,87 @@
+package oracle
+
+import (
+	"context"
+	"strings"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// 权限查询结果行的前缀
+const (
+	privilegeMarker = "PRIV|"
+	roleMarker      = "ROLE|"
+)
+
+// sessionPrivilegesQuery 查询当前会话生效的系统权限和角色
+const sessionPrivilegesQuery = `SELECT '` + privilegeMarker + `' || privilege FROM session_privs
+UNION ALL
+SELECT '` + roleMarker + `' || role FROM session_roles;`
+
+// dictionaryAccess 可以读取完整数据字典（ora2pg导出对象定义和注释时需要）的权限或角色
+var dictionaryAccess = []string{"SELECT ANY DICTIONARY", "SELECT_CATALOG_ROLE", "DBA"}
+
+// MigrationPrivileges 迁移账号的权限检查结果
+type MigrationPrivileges struct {
+	Privileges []string `json:"privileges"`
+	Roles      []string `json:"roles"`
+	// Missing 缺少时无法导出数据的权限
+	Missing []string `json:"missing,omitempty"`
+	// Recommended 缺少时部分对象定义可能导出不完整的权限
+	Recommended []string `json:"recommended,omitempty"`
+}
+
+// Sufficient 是否具备迁移所需的全部权限
+func (p *MigrationPrivileges) Sufficient() bool {
+	return len(p.Missing) == 0
+}
+
+// CheckMigrationPrivileges 检查连接账号导出 schema 所需的权限
+//
+// 导出其他用户的 schema 需要 SELECT ANY TABLE（或 DBA 角色），读取完整数据字典建议授予 SELECT ANY DICTIONARY。
+// 逐表授予的对象权限无法通过会话权限判断，此时结果可能偏保守。
+func CheckMigrationPrivileges(ctx context.Context, runner *SQLPlusRunner, username, schema string) (*MigrationPrivileges, error) {
+	output, err := runner.Run(ctx, sessionPrivilegesQuery)
+	if err != nil {
+		return nil, utils.NewError(utils.ErrorTypeOracle, "ORACLE_PRIVILEGE_QUERY_FAILED").
+			Message("查询Oracle会话权限失败").
+			Details(err.Error()).
+			Cause(err).
+			Build()
+	}
+
+	privileges := parseSessionPrivileges(output)
+	granted := make(map[string]bool, len(privileges.Privileges)+len(privileges.Roles))
+	for _, name := range append(privileges.Privileges, privileges.Roles...) {
+		granted[name] = true
+	}
+
+	if !strings.EqualFold(strings.TrimSpace(username), strings.TrimSpace(schema)) && !granted["SELECT ANY TABLE"] && !granted["DBA"] {
+		privileges.Missing = append(privileges.Missing, "SELECT ANY TABLE")
+	}
+	hasDictionary := false
+	for _, name := range dictionaryAccess {
+		if granted[name] {
+			hasDictionary = true
+			break
+		}
+	}
+	if !hasDictionary {
+		privileges.Recommended = append(privileges.Recommended, "SELECT ANY DICTIONARY")
+	}
+	return privileges, nil
+}
+
+// parseSessionPrivileges 解析权限查询的输出
+func parseSessionPrivileges(output string) *MigrationPrivileges {
+	privileges := &MigrationPrivileges{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if name, found := strings.CutPrefix(line, privilegeMarker); found && name != "" {
+			privileges.Privileges = append(privileges.Privileges, strings.ToUpper(name))
+		} else if name, found := strings.CutPrefix(line, roleMarker); found && name != "" {
+			privileges.Roles = append(privileges.Roles, strings.ToUpper(name))
+		}
+	}
+	return privileges
+}
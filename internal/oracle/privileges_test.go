@@ -0,0 +1,14 @@
+package oracle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSessionPrivileges(t *testing.T) {
+	privileges := parseSessionPrivileges("\nPRIV|CREATE SESSION\nPRIV|select any table\nROLE|CONNECT\nno rows\n")
+	assert.Equal(t, []string{"CREATE SESSION", "SELECT ANY TABLE"}, privileges.Privileges)
+	assert.Equal(t, []string{"CONNECT"}, privileges.Roles)
+	assert.True(t, privileges.Sufficient())
+}
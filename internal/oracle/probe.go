@@ -0,0 +1,316 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/config"
+)
+
+// commonListenerPorts 探测时尝试的常见Oracle监听端口，按常见程度排列
+var commonListenerPorts = []int{1521, 1522, 1526, 1523, 1525, 2483}
+
+// 连接自诊断的探测范围限制
+const (
+	// maxPortProbes 最多尝试的其他端口数
+	maxPortProbes = 4
+	// probeDialTimeout 单个端口建立TCP连接的超时时间
+	probeDialTimeout = 2 * time.Second
+	// probeCommandTimeout 单次 tnsping 或 sqlplus 探测的超时时间
+	probeCommandTimeout = 15 * time.Second
+	// probeTotalTimeout 全部探测的总时长上限
+	probeTotalTimeout = 30 * time.Second
+)
+
+// probeOutputMarker 探测登录成功后查询结果行的前缀
+const probeOutputMarker = "PROBE|"
+
+// portProbeCodes 网络层失败、值得探测其他端口的错误码
+var portProbeCodes = []string{"12541", "12170", "12535"}
+
+// nameProbeCodes 监听器不认识服务名或SID、值得切换连接方式的错误码
+var nameProbeCodes = []string{"12514", "12505"}
+
+// nameAcceptedCodes 监听器已接受连接、在认证阶段失败的错误码，说明服务名或SID本身正确
+var nameAcceptedCodes = []string{"01017", "28000", "28001", "01045", "28040"}
+
+// networkFailurePattern 没有错误码时判断网络层失败的输出
+var networkFailurePattern = regexp.MustCompile(`(?i)timed? ?out|超时|connection refused|拒绝连接|no listener`)
+
+// errTNSPingUnavailable 未找到tnsping工具
+var errTNSPingUnavailable = errors.New("未找到tnsping工具")
+
+// ProbeSuggestion 自动探测得到的一条纠错建议
+type ProbeSuggestion struct {
+	Field   string `json:"field"` // 建议修改的配置项，如 oracle.port
+	Value   string `json:"value"` // 建议的值
+	Message string `json:"message"`
+}
+
+// ProbeReport 连接自诊断的结果，只是辅助建议，不会修改配置
+type ProbeReport struct {
+	Attempts    []string          `json:"attempts"`
+	Suggestions []ProbeSuggestion `json:"suggestions,omitempty"`
+}
+
+// ConnectionProber 连接失败时探测常见端口、切换SID与服务名，推测正确的连接参数
+type ConnectionProber struct {
+	tester *ConnectionTester
+	// probing 正在探测的连接配置，执行tnsping时按其设置 TNS_ADMIN
+	probing *config.OracleConfig
+	dial    func(ctx context.Context, address string) error
+	tnsping func(ctx context.Context, connectString string) error
+	login   func(ctx context.Context, cfg *config.OracleConfig, descriptor string) (string, error)
+}
+
+// NewConnectionProber 创建连接探测器，复用连接测试器的客户端配置
+func NewConnectionProber(tester *ConnectionTester) *ConnectionProber {
+	prober := &ConnectionProber{tester: tester, dial: dialTCP}
+	prober.tnsping = prober.runTNSPing
+	prober.login = prober.sqlplusLogin
+	return prober
+}
+
+// Probe 根据连接失败的原因选择探测方式，失败原因与端口、服务名无关时返回nil
+//
+// 网络层失败时探测少量常见端口；监听器不认识服务名或SID时按SID重新登录一次。
+func (p *ConnectionProber) Probe(ctx context.Context, oracleConfig *config.OracleConfig, result *ConnectionResult) *ProbeReport {
+	if result == nil || result.Success {
+		return nil
+	}
+	cfg := oracleConfig.ForConnectionTest()
+	p.probing = cfg
+	output := result.Error + "\n" + result.Details
+	codes := errorCodes(output)
+
+	ctx, cancel := context.WithTimeout(ctx, probeTotalTimeout)
+	defer cancel()
+
+	report := &ProbeReport{}
+	switch {
+	case containsAny(codes, nameProbeCodes):
+		p.probeSID(ctx, cfg, report)
+	case containsAny(codes, portProbeCodes) || (len(codes) == 0 && networkFailurePattern.MatchString(output)):
+		p.probePorts(ctx, cfg, report)
+	default:
+		return nil
+	}
+	return report
+}
+
+// probePorts 配置的端口不通时依次尝试常见端口，找到第一个有监听器响应的端口为止
+func (p *ConnectionProber) probePorts(ctx context.Context, cfg *config.OracleConfig, report *ProbeReport) {
+	if err := p.dial(ctx, net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))); err == nil {
+		report.Attempts = append(report.Attempts, fmt.Sprintf("配置的端口 %d 可以建立TCP连接，未探测其他端口", cfg.Port))
+		return
+	} else if isHostError(err) {
+		report.Attempts = append(report.Attempts, fmt.Sprintf("无法解析或访问主机 %s，未探测其他端口: %v", cfg.Host, err))
+		return
+	}
+	report.Attempts = append(report.Attempts, fmt.Sprintf("配置的端口 %d 无法建立TCP连接", cfg.Port))
+
+	probed := 0
+	for _, port := range listenerPorts(cfg) {
+		if port == cfg.Port {
+			continue
+		}
+		if probed >= maxPortProbes || ctx.Err() != nil {
+			break
+		}
+		probed++
+
+		address := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+		if err := p.dial(ctx, address); err != nil {
+			report.Attempts = append(report.Attempts, fmt.Sprintf("端口 %d: 无法建立TCP连接", port))
+			continue
+		}
+
+		candidate := *cfg
+		candidate.Port = port
+		err := p.tnsping(ctx, candidate.ConnectDescriptor())
+		switch {
+		case err == nil:
+			report.Attempts = append(report.Attempts, fmt.Sprintf("端口 %d: tnsping 成功", port))
+			report.Suggestions = append(report.Suggestions, ProbeSuggestion{
+				Field:   "oracle.port",
+				Value:   strconv.Itoa(port),
+				Message: fmt.Sprintf("端口 %d 上有Oracle监听器响应，而配置的端口 %d 不通，是否应把端口改为 %d？", port, cfg.Port, port),
+			})
+			return
+		case errors.Is(err, errTNSPingUnavailable):
+			report.Attempts = append(report.Attempts, fmt.Sprintf("端口 %d: 可以建立TCP连接（未找到tnsping，无法确认）", port))
+			report.Suggestions = append(report.Suggestions, ProbeSuggestion{
+				Field:   "oracle.port",
+				Value:   strconv.Itoa(port),
+				Message: fmt.Sprintf("端口 %d 可以建立TCP连接，但无法确认是否为Oracle监听器，请与DBA确认监听端口", port),
+			})
+			return
+		default:
+			report.Attempts = append(report.Attempts, fmt.Sprintf("端口 %d: 可以建立TCP连接，但 tnsping 失败", port))
+		}
+	}
+}
+
+// probeSID 把配置的服务名或SID按SID连接，成功时查询实例的服务名和实例名
+//
+// 连接测试和sqlplus都使用 host:port/名称 的EZConnect方式，只能连接服务名；
+// 按SID能登录（或在认证阶段失败）说明该名称是SID而不是服务名。
+func (p *ConnectionProber) probeSID(ctx context.Context, cfg *config.OracleConfig, report *ProbeReport) {
+	name := connectTarget(cfg)
+	if name == "" {
+		return
+	}
+	descriptor := fmt.Sprintf("(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=%s)(PORT=%d))(CONNECT_DATA=(SID=%s)))", cfg.Host, cfg.Port, name)
+	if cfg.UseTCPS {
+		sidConfig := *cfg
+		sidConfig.SID, sidConfig.Service = name, ""
+		descriptor = sidConfig.ConnectDescriptor()
+	}
+	output, err := p.login(ctx, cfg, descriptor)
+
+	var sqlErr *SQLPlusError
+	authCode := ""
+	if errors.As(err, &sqlErr) {
+		if codes := errorCodes(sqlErr.Code); len(codes) > 0 && containsAny(codes, nameAcceptedCodes) {
+			authCode = sqlErr.Code
+		}
+	}
+	if err != nil && authCode == "" {
+		report.Attempts = append(report.Attempts, fmt.Sprintf("按SID %s 连接: 失败（%v）", name, err))
+		return
+	}
+
+	service, instance := parseProbeOutput(output)
+	if authCode != "" {
+		report.Attempts = append(report.Attempts, fmt.Sprintf("按SID %s 连接: 监听器接受了连接，认证失败（%s）", name, authCode))
+	} else {
+		report.Attempts = append(report.Attempts, fmt.Sprintf("按SID %s 连接: 成功", name))
+	}
+
+	if cfg.Service != "" {
+		report.Suggestions = append(report.Suggestions, ProbeSuggestion{
+			Field:   "oracle.sid",
+			Value:   name,
+			Message: fmt.Sprintf("%s 是实例的SID而不是服务名，应填写 sid: %s 并清空 service", name, name),
+		})
+	}
+	if service != "" && !strings.EqualFold(service, name) {
+		report.Suggestions = append(report.Suggestions, ProbeSuggestion{
+			Field:   "oracle.service",
+			Value:   service,
+			Message: fmt.Sprintf("实例 %s 的服务名为 %s，应填写 service: %s 并清空 sid", firstNonEmpty(instance, name), service, service),
+		})
+	} else if cfg.Service == "" {
+		report.Suggestions = append(report.Suggestions, ProbeSuggestion{
+			Field:   "oracle.service",
+			Message: fmt.Sprintf("SID %s 存在但没有同名的服务，请向DBA确认该实例的服务名并填写 service（12c 及以上的PDB只能通过服务名连接）", name),
+		})
+	}
+	if authCode != "" {
+		report.Suggestions = append(report.Suggestions, ProbeSuggestion{
+			Field:   "oracle.password",
+			Message: fmt.Sprintf("按SID连接时返回 %s，修正连接方式后请同时确认账号和密码", authCode),
+		})
+	}
+}
+
+// sqlplusLogin 使用指定的连接描述符登录，并查询当前会话的服务名和实例名
+func (p *ConnectionProber) sqlplusLogin(ctx context.Context, cfg *config.OracleConfig, descriptor string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeCommandTimeout)
+	defer cancel()
+	runner := &SQLPlusRunner{oracleConfig: cfg, tester: p.tester, descriptor: descriptor}
+	return runner.Run(ctx, fmt.Sprintf(
+		"SELECT '%s' || SYS_CONTEXT('USERENV', 'SERVICE_NAME') || '|' || SYS_CONTEXT('USERENV', 'INSTANCE_NAME') FROM DUAL;",
+		probeOutputMarker))
+}
+
+// runTNSPing 使用tnsping探测连接串，未找到tnsping时返回 errTNSPingUnavailable
+func (p *ConnectionProber) runTNSPing(ctx context.Context, connectString string) error {
+	tnspingPath, err := p.tester.findOracleTool("tnsping")
+	if err != nil {
+		return errTNSPingUnavailable
+	}
+	ctx, cancel := context.WithTimeout(ctx, probeCommandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, tnspingPath, connectString)
+	cmd.Env = p.tester.toolEnvironment()
+	if p.probing != nil {
+		cmd.Env = p.tester.connectionEnvironment(p.probing)
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("tnsping执行失败: %v", err)
+	}
+	if !strings.Contains(string(output), "OK") {
+		return fmt.Errorf("tnsping未返回OK")
+	}
+	return nil
+}
+
+// listenerPorts 探测的端口，TCPS 连接优先尝试 2484
+func listenerPorts(cfg *config.OracleConfig) []int {
+	if !cfg.UseTCPS {
+		return commonListenerPorts
+	}
+	return append([]int{config.DefaultOracleTCPSPort}, commonListenerPorts...)
+}
+
+// dialTCP 尝试建立TCP连接
+func dialTCP(ctx context.Context, address string) error {
+	dialer := net.Dialer{Timeout: probeDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// isHostError 是否为主机名无法解析或主机不可达，这时探测其他端口没有意义
+func isHostError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "no route to host") || strings.Contains(message, "network is unreachable")
+}
+
+// parseProbeOutput 解析探测查询返回的服务名和实例名
+func parseProbeOutput(output string) (service, instance string) {
+	for _, line := range strings.Split(output, "\n") {
+		if rest, found := strings.CutPrefix(strings.TrimSpace(line), probeOutputMarker); found {
+			service, instance, _ = strings.Cut(rest, "|")
+			return strings.TrimSpace(service), strings.TrimSpace(instance)
+		}
+	}
+	return "", ""
+}
+
+// containsAny codes 中是否有 candidates 中的错误码
+func containsAny(codes, candidates []string) bool {
+	for _, code := range codes {
+		for _, candidate := range candidates {
+			if code == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
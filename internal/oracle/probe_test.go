@@ -0,0 +1,129 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+)
+
+// fakeProber 使用模拟的端口、tnsping和登录结果的探测器
+func fakeProber(openPorts map[string]bool, listeners map[string]bool, login func(descriptor string) (string, error)) (*ConnectionProber, *[]string) {
+	var calls []string
+	prober := NewConnectionProber(NewConnectionTester())
+	prober.dial = func(ctx context.Context, address string) error {
+		calls = append(calls, "dial "+address)
+		if openPorts[address] {
+			return nil
+		}
+		return errors.New("connection refused")
+	}
+	prober.tnsping = func(ctx context.Context, connectString string) error {
+		calls = append(calls, "tnsping "+connectString)
+		if listeners[connectString] {
+			return nil
+		}
+		return errors.New("tnsping未返回OK")
+	}
+	prober.login = func(ctx context.Context, cfg *config.OracleConfig, descriptor string) (string, error) {
+		calls = append(calls, "login "+descriptor)
+		if login == nil {
+			return "", errors.New("unexpected login")
+		}
+		return login(descriptor)
+	}
+	return prober, &calls
+}
+
+func TestProbePorts(t *testing.T) {
+	cfg := &config.OracleConfig{Host: "db", Port: 1521, Service: "ORCLPDB", Username: "scott"}
+	failure := &ConnectionResult{Error: "ORA-12541: TNS:no listener"}
+
+	prober, calls := fakeProber(map[string]bool{"db:1526": true}, map[string]bool{"db:1526/ORCLPDB": true}, nil)
+	report := prober.Probe(context.Background(), cfg, failure)
+	require.NotNil(t, report)
+	require.Len(t, report.Suggestions, 1)
+	assert.Equal(t, ProbeSuggestion{Field: "oracle.port", Value: "1526",
+		Message: "端口 1526 上有Oracle监听器响应，而配置的端口 1521 不通，是否应把端口改为 1526？"}, report.Suggestions[0])
+	assert.Equal(t, []string{"dial db:1521", "dial db:1522", "dial db:1526", "tnsping db:1526/ORCLPDB"}, *calls)
+
+	// 探测的端口数有上限，配置的端口可以连接时不探测其他端口
+	prober, calls = fakeProber(nil, nil, nil)
+	report = prober.Probe(context.Background(), cfg, failure)
+	assert.Empty(t, report.Suggestions)
+	assert.Len(t, *calls, 1+maxPortProbes)
+
+	prober, calls = fakeProber(map[string]bool{"db:1521": true}, nil, nil)
+	report = prober.Probe(context.Background(), cfg, failure)
+	assert.Empty(t, report.Suggestions)
+	assert.Equal(t, []string{"dial db:1521"}, *calls)
+
+	// 连接成功或失败原因与端口、服务名无关时不探测
+	assert.Nil(t, prober.Probe(context.Background(), cfg, &ConnectionResult{Success: true}))
+	assert.Nil(t, prober.Probe(context.Background(), cfg, &ConnectionResult{Error: "ORA-01017: invalid username/password"}))
+}
+
+func TestProbeSID(t *testing.T) {
+	descriptor := "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCP)(HOST=db)(PORT=1521))(CONNECT_DATA=(SID=ORCL)))"
+	failure := &ConnectionResult{Error: "ORA-12514: TNS:listener does not currently know of service requested"}
+
+	// 把SID误填为服务名
+	prober, calls := fakeProber(nil, nil, func(d string) (string, error) {
+		return "\nPROBE|orcl.example.com|ORCL\n", nil
+	})
+	report := prober.Probe(context.Background(), &config.OracleConfig{Host: "db", Port: 1521, Service: "ORCL"}, failure)
+	require.NotNil(t, report)
+	assert.Equal(t, []string{"login " + descriptor}, *calls)
+	require.Len(t, report.Suggestions, 2)
+	assert.Equal(t, "oracle.sid", report.Suggestions[0].Field)
+	assert.Equal(t, "ORCL", report.Suggestions[0].Value)
+	assert.Equal(t, "oracle.service", report.Suggestions[1].Field)
+	assert.Equal(t, "orcl.example.com", report.Suggestions[1].Value)
+
+	// 填写了SID，按SID连接时在认证阶段失败，说明SID存在但不是服务名
+	prober, _ = fakeProber(nil, nil, func(d string) (string, error) {
+		return "", &SQLPlusError{Code: "ORA-01017", Message: "invalid username/password"}
+	})
+	report = prober.Probe(context.Background(), &config.OracleConfig{Host: "db", Port: 1521, SID: "ORCL"}, failure)
+	require.Len(t, report.Suggestions, 2)
+	assert.Equal(t, "oracle.service", report.Suggestions[0].Field)
+	assert.True(t, strings.Contains(report.Suggestions[1].Message, "ORA-01017"))
+
+	// 按SID也无法连接时只记录尝试
+	prober, _ = fakeProber(nil, nil, func(d string) (string, error) {
+		return "", &SQLPlusError{Code: "ORA-12505", Message: "TNS:listener does not currently know of SID"}
+	})
+	report = prober.Probe(context.Background(), &config.OracleConfig{Host: "db", Port: 1521, Service: "ORCL"}, failure)
+	assert.Empty(t, report.Suggestions)
+	assert.Len(t, report.Attempts, 1)
+}
+
+func TestProbeTCPS(t *testing.T) {
+	cfg := &config.OracleConfig{Host: "db", Port: 1521, Service: "ORCLPDB", Username: "scott", UseTCPS: true, WalletDir: "/opt/wallet"}
+	failure := &ConnectionResult{Error: "ORA-12541: TNS:no listener"}
+
+	// TCPS 优先探测 2484，tnsping 使用 TCPS 连接描述符
+	tcpsDescriptor := "(DESCRIPTION=(ADDRESS=(PROTOCOL=TCPS)(HOST=db)(PORT=2484))(CONNECT_DATA=(SERVICE_NAME=ORCLPDB))" +
+		"(SECURITY=(SSL_SERVER_DN_MATCH=ON)(MY_WALLET_DIRECTORY=/opt/wallet)))"
+	prober, calls := fakeProber(map[string]bool{"db:2484": true}, map[string]bool{tcpsDescriptor: true}, nil)
+	report := prober.Probe(context.Background(), cfg, failure)
+	require.NotNil(t, report)
+	require.Len(t, report.Suggestions, 1)
+	assert.Equal(t, "2484", report.Suggestions[0].Value)
+	assert.Equal(t, []string{"dial db:1521", "dial db:2484", "tnsping " + tcpsDescriptor}, *calls)
+
+	// 按SID重新登录时同样使用 TCPS
+	prober, calls = fakeProber(nil, nil, func(d string) (string, error) {
+		return "\nPROBE|orclpdb.example.com|ORCL\n", nil
+	})
+	cfg.Port = 2484
+	prober.Probe(context.Background(), cfg, &ConnectionResult{Error: "ORA-12514: TNS:listener does not currently know of service requested"})
+	require.Len(t, *calls, 1)
+	assert.Contains(t, (*calls)[0], "(PROTOCOL=TCPS)")
+	assert.Contains(t, (*calls)[0], "(CONNECT_DATA=(SID=ORCLPDB))")
+}
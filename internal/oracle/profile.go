@@ -0,0 +1,241 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 迁移就绪度查询结果行的前缀
+const (
+	difficultTypeMarker  = "DIFF|"
+	difficultTableMarker = "DIFFTAB|"
+	nestedTableMarker    = "NESTED|"
+	largeTableMarker     = "BIG|"
+	dataSizeMarker       = "SIZE|"
+)
+
+// DefaultLargeTableRows 行数达到该值的表视为大表
+const DefaultLargeTableRows = 10000000
+
+// UserDefinedType 列类型为Schema中自定义的对象类型时，在 DifficultColumns 中使用的键
+const UserDefinedType = "USER_TYPE"
+
+// DifficultDataTypes ora2pg无法直接转换、通常需要人工处理的列类型
+var DifficultDataTypes = []string{"LONG", "LONG RAW", "BFILE", "XMLTYPE", "ANYDATA", "SDO_GEOMETRY"}
+
+// TableSize 表名和统计信息中的行数
+type TableSize struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+}
+
+// SchemaProfile 评估迁移就绪度所需的源库信息
+type SchemaProfile struct {
+	Schema  string          `json:"schema"`
+	Objects ObjectInventory `json:"objects"`
+	Tables  []string        `json:"tables"`
+	// DifficultColumns 难迁移类型的列数，键为数据类型或 USER_TYPE
+	DifficultColumns map[string]int `json:"difficult_columns"`
+	// DifficultTables 含难迁移类型列的表数量
+	DifficultTables int `json:"difficult_tables"`
+	// NestedTables 嵌套表数量
+	NestedTables int `json:"nested_tables"`
+	// LargeTables 统计信息中行数达到阈值的表，按行数从大到小排列
+	LargeTables []TableSize `json:"large_tables,omitempty"`
+}
+
+// Profile 在同一个sqlplus会话中查询对象数量、表名、难迁移类型和大表，largeRows 为大表的行数阈值
+//
+// 大表按统计信息中的 num_rows 判断，从未收集统计的表不会被识别为大表。
+func (i *Inspector) Profile(ctx context.Context, largeRows int64) (*SchemaProfile, error) {
+	if largeRows <= 0 {
+		largeRows = DefaultLargeTableRows
+	}
+	outputs, err := i.runner.RunBatch(ctx, i.objectCountsQuery(), i.tableNamesQuery(),
+		i.difficultTypesQuery(), i.largeTablesQuery(largeRows))
+	if err != nil {
+		return nil, i.inventoryError(err)
+	}
+
+	objects, err := parseObjectInventory(outputs[0])
+	if err != nil {
+		return nil, err
+	}
+	profile := &SchemaProfile{
+		Schema:  i.schema,
+		Objects: objects,
+		Tables:  parseTableNames(outputs[1]),
+	}
+	if err := parseDifficultTypes(outputs[2], profile); err != nil {
+		return nil, err
+	}
+	if profile.LargeTables, err = parseLargeTables(outputs[3]); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// difficultTypesQuery 构建难迁移类型统计查询：按类型统计列数，以及涉及的表和嵌套表数量
+func (i *Inspector) difficultTypesQuery() string {
+	types := make([]string, len(DifficultDataTypes))
+	for idx, dataType := range DifficultDataTypes {
+		types[idx] = quoteLiteral(dataType)
+	}
+	filter := fmt.Sprintf(`FROM all_tab_columns c
+JOIN all_tables t ON t.owner = c.owner AND t.table_name = c.table_name
+WHERE c.owner = %s AND c.table_name NOT LIKE 'BIN$%%'
+  AND (c.data_type IN (%s) OR c.data_type_owner = c.owner)`, quoteLiteral(i.schema), strings.Join(types, ", "))
+	kind := fmt.Sprintf("CASE WHEN c.data_type_owner = c.owner THEN '%s' ELSE c.data_type END", UserDefinedType)
+
+	return fmt.Sprintf(`SELECT '%[1]s' || %[2]s || '|' || COUNT(*)
+%[3]s
+GROUP BY %[2]s;
+SELECT '%[4]s' || COUNT(DISTINCT c.table_name)
+%[3]s;
+SELECT '%[5]s' || COUNT(*) FROM all_nested_tables WHERE owner = %[6]s;`,
+		difficultTypeMarker, kind, filter, difficultTableMarker, nestedTableMarker, quoteLiteral(i.schema))
+}
+
+// largeTablesQuery 构建大表查询
+func (i *Inspector) largeTablesQuery(largeRows int64) string {
+	return fmt.Sprintf(`SELECT '%s' || table_name || '|' || num_rows FROM all_tables
+WHERE owner = %s AND table_name NOT LIKE 'BIN$%%' AND num_rows >= %d
+ORDER BY num_rows DESC;`, largeTableMarker, quoteLiteral(i.schema), largeRows)
+}
+
+// TableSizes 查询Schema中全部表在统计信息中的行数，按行数从大到小排列；从未收集统计的表行数为0
+func (i *Inspector) TableSizes(ctx context.Context) ([]TableSize, error) {
+	output, err := i.runner.Run(ctx, fmt.Sprintf(`SELECT '%s' || table_name || '|' || NVL(num_rows, 0) FROM all_tables
+WHERE owner = %s AND table_name NOT LIKE 'BIN$%%';`, largeTableMarker, quoteLiteral(i.schema)))
+	if err != nil {
+		return nil, i.inventoryError(err)
+	}
+	return parseLargeTables(output)
+}
+
+// parseDifficultTypes 解析难迁移类型统计查询的输出
+func parseDifficultTypes(output string, profile *SchemaProfile) error {
+	profile.DifficultColumns = make(map[string]int)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, difficultTypeMarker):
+			dataType, countText, _ := strings.Cut(strings.TrimPrefix(line, difficultTypeMarker), "|")
+			count, err := strconv.Atoi(strings.TrimSpace(countText))
+			if err != nil {
+				return fmt.Errorf("解析难迁移类型统计结果失败: %s", line)
+			}
+			profile.DifficultColumns[strings.TrimSpace(dataType)] = count
+		case strings.HasPrefix(line, difficultTableMarker):
+			count, err := strconv.Atoi(strings.TrimPrefix(line, difficultTableMarker))
+			if err != nil {
+				return fmt.Errorf("解析难迁移类型统计结果失败: %s", line)
+			}
+			profile.DifficultTables = count
+		case strings.HasPrefix(line, nestedTableMarker):
+			count, err := strconv.Atoi(strings.TrimPrefix(line, nestedTableMarker))
+			if err != nil {
+				return fmt.Errorf("解析嵌套表统计结果失败: %s", line)
+			}
+			profile.NestedTables = count
+		}
+	}
+	return nil
+}
+
+// parseLargeTables 解析大表查询的输出
+func parseLargeTables(output string) ([]TableSize, error) {
+	var tables []TableSize
+	for _, line := range strings.Split(output, "\n") {
+		fields, found := strings.CutPrefix(strings.TrimSpace(line), largeTableMarker)
+		if !found {
+			continue
+		}
+		name, rowsText, _ := strings.Cut(fields, "|")
+		rows, err := strconv.ParseInt(strings.TrimSpace(rowsText), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("解析大表查询结果失败: %s", line)
+		}
+		tables = append(tables, TableSize{Name: name, Rows: rows})
+	}
+	sort.SliceStable(tables, func(a, b int) bool { return tables[a].Rows > tables[b].Rows })
+	return tables, nil
+}
+
+// DataSize 按统计信息估算的Schema数据量
+type DataSize struct {
+	Tables int   `json:"tables"`
+	Rows   int64 `json:"rows"`
+	// Bytes 各表 num_rows × avg_row_len 之和，不含索引和LOB段
+	Bytes int64 `json:"bytes"`
+	// Unanalyzed 从未收集统计信息、未计入估算的表数量
+	Unanalyzed int `json:"unanalyzed"`
+}
+
+// DataSize 按统计信息估算Schema中表的行数和数据量
+func (i *Inspector) DataSize(ctx context.Context) (*DataSize, error) {
+	output, err := i.runner.Run(ctx, i.dataSizeQuery())
+	if err != nil {
+		return nil, i.inventoryError(err)
+	}
+	return parseDataSize(output)
+}
+
+// dataSizeQuery 构建数据量估算查询
+func (i *Inspector) dataSizeQuery() string {
+	return fmt.Sprintf(`SELECT '%s' || COUNT(*) || '|' || NVL(SUM(num_rows), 0) || '|' ||
+  NVL(SUM(num_rows * avg_row_len), 0) || '|' || NVL(SUM(CASE WHEN num_rows IS NULL THEN 1 ELSE 0 END), 0)
+FROM all_tables
+WHERE owner = %s AND table_name NOT LIKE 'BIN$%%';`, dataSizeMarker, quoteLiteral(i.schema))
+}
+
+// parseDataSize 解析数据量估算查询的输出
+func parseDataSize(output string) (*DataSize, error) {
+	for _, line := range strings.Split(output, "\n") {
+		fields, found := strings.CutPrefix(strings.TrimSpace(line), dataSizeMarker)
+		if !found {
+			continue
+		}
+		values := strings.Split(fields, "|")
+		if len(values) != 4 {
+			return nil, fmt.Errorf("解析数据量估算结果失败: %s", line)
+		}
+		numbers := make([]int64, len(values))
+		for idx, value := range values {
+			number, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("解析数据量估算结果失败: %s", line)
+			}
+			numbers[idx] = number
+		}
+		return &DataSize{Tables: int(numbers[0]), Rows: numbers[1], Bytes: numbers[2], Unanalyzed: int(numbers[3])}, nil
+	}
+	return nil, fmt.Errorf("未获取到数据量估算结果")
+}
+
+// SchemaScale 迁移开始前估算的Schema规模
+type SchemaScale struct {
+	Objects ObjectInventory `json:"objects"`
+	Data    *DataSize       `json:"data"`
+}
+
+// Scale 在同一个sqlplus会话中统计对象数量并估算数据量
+func (i *Inspector) Scale(ctx context.Context) (*SchemaScale, error) {
+	outputs, err := i.runner.RunBatch(ctx, i.objectCountsQuery(), i.dataSizeQuery())
+	if err != nil {
+		return nil, i.inventoryError(err)
+	}
+
+	objects, err := parseObjectInventory(outputs[0])
+	if err != nil {
+		return nil, err
+	}
+	data, err := parseDataSize(outputs[1])
+	if err != nil {
+		return nil, err
+	}
+	return &SchemaScale{Objects: objects, Data: data}, nil
+}
@@ -0,0 +1,56 @@
+package oracle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDifficultTypes(t *testing.T) {
+	profile := &SchemaProfile{}
+	require.NoError(t, parseDifficultTypes("DIFF|LONG|3\nDIFF|USER_TYPE|2\nDIFFTAB|4\nNESTED|1\n", profile))
+	assert.Equal(t, map[string]int{"LONG": 3, UserDefinedType: 2}, profile.DifficultColumns)
+	assert.Equal(t, 4, profile.DifficultTables)
+	assert.Equal(t, 1, profile.NestedTables)
+
+	// 没有难迁移类型时只有汇总行
+	profile = &SchemaProfile{}
+	require.NoError(t, parseDifficultTypes("DIFFTAB|0\nNESTED|0\n", profile))
+	assert.Empty(t, profile.DifficultColumns)
+
+	assert.Error(t, parseDifficultTypes("DIFF|LONG|x\n", &SchemaProfile{}))
+}
+
+func TestParseLargeTables(t *testing.T) {
+	tables, err := parseLargeTables("BIG|ORDERS|25000000\nBIG|AUDIT_LOG|120000000\n")
+	require.NoError(t, err)
+	assert.Equal(t, []TableSize{{Name: "AUDIT_LOG", Rows: 120000000}, {Name: "ORDERS", Rows: 25000000}}, tables)
+
+	tables, err = parseLargeTables("")
+	require.NoError(t, err)
+	assert.Empty(t, tables)
+
+	_, err = parseLargeTables("BIG|ORDERS|many\n")
+	assert.Error(t, err)
+}
+
+func TestDifficultTypesQuery(t *testing.T) {
+	query := NewInspector(nil, "hr").difficultTypesQuery()
+	assert.Contains(t, query, "c.owner = 'HR'")
+	assert.Contains(t, query, "'LONG RAW'")
+	assert.Contains(t, query, "all_nested_tables WHERE owner = 'HR'")
+}
+
+func TestParseDataSize(t *testing.T) {
+	size, err := parseDataSize("SIZE|12|3500000|1073741824|2\n")
+	require.NoError(t, err)
+	assert.Equal(t, &DataSize{Tables: 12, Rows: 3500000, Bytes: 1073741824, Unanalyzed: 2}, size)
+
+	_, err = parseDataSize("SIZE|12|x|0|0\n")
+	assert.Error(t, err)
+	_, err = parseDataSize("")
+	assert.Error(t, err)
+
+	assert.Contains(t, NewInspector(nil, "hr").dataSizeQuery(), "owner = 'HR'")
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package oracle
+
+// registryOracleHomes 非Windows平台没有注册表，返回空
+func registryOracleHomes() []string {
+	return nil
+}
@@ -0,0 +1,104 @@
+//go:build windows
+
+package oracle
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// oracleRegistryRoot Oracle安装信息在注册表中的位置
+const oracleRegistryRoot = `SOFTWARE\ORACLE`
+
+// registryOracleHomes 从注册表 HKLM\SOFTWARE\ORACLE 读取已安装的 ORACLE_HOME
+//
+// 依次读取64位和32位视图（32位客户端登记在 WOW6432Node 下），
+// 每个视图中包括 KEY_<HomeName> 形式的新版注册项和 HOME<n> 形式的旧版注册项。
+func registryOracleHomes() []string {
+	var homes []string
+	for _, view := range []struct {
+		name   string
+		access uint32
+	}{
+		{"64位", registry.WOW64_64KEY},
+		{"32位", registry.WOW64_32KEY},
+	} {
+		homes = append(homes, readRegistryView(view.name, view.access)...)
+	}
+	return homes
+}
+
+// readRegistryView 读取指定注册表视图下的 ORACLE_HOME
+func readRegistryView(viewName string, access uint32) []string {
+	root, err := registry.OpenKey(registry.LOCAL_MACHINE, oracleRegistryRoot,
+		registry.ENUMERATE_SUB_KEYS|registry.QUERY_VALUE|access)
+	if err != nil {
+		logRegistryError(viewName, oracleRegistryRoot, err)
+		return nil
+	}
+	defer root.Close()
+
+	var homes []string
+
+	// 很早的版本直接把 ORACLE_HOME 写在根键下
+	if home := readOracleHomeValue(root); home != "" {
+		homes = append(homes, home)
+	}
+
+	subKeys, err := root.ReadSubKeyNames(-1)
+	if err != nil {
+		logRegistryError(viewName, oracleRegistryRoot, err)
+		return homes
+	}
+
+	for _, name := range subKeys {
+		upper := strings.ToUpper(name)
+		if !strings.HasPrefix(upper, "KEY_") && !strings.HasPrefix(upper, "HOME") {
+			continue
+		}
+
+		keyPath := oracleRegistryRoot + `\` + name
+		key, err := registry.OpenKey(registry.LOCAL_MACHINE, keyPath, registry.QUERY_VALUE|access)
+		if err != nil {
+			logRegistryError(viewName, keyPath, err)
+			continue
+		}
+		if home := readOracleHomeValue(key); home != "" {
+			logrus.Debugf("注册表(%s) %s 记录的ORACLE_HOME: %s", viewName, keyPath, home)
+			homes = append(homes, home)
+		}
+		key.Close()
+	}
+
+	return homes
+}
+
+// readOracleHomeValue 读取注册项中的 ORACLE_HOME 值，支持环境变量展开
+func readOracleHomeValue(key registry.Key) string {
+	value, valueType, err := key.GetStringValue("ORACLE_HOME")
+	if err != nil {
+		return ""
+	}
+	if valueType == registry.EXPAND_SZ {
+		if expanded, err := registry.ExpandString(value); err == nil {
+			value = expanded
+		}
+	}
+	return strings.TrimSpace(value)
+}
+
+// logRegistryError 记录注册表读取失败，未安装和权限不足都不影响后续检测
+func logRegistryError(viewName, keyPath string, err error) {
+	switch {
+	case errors.Is(err, registry.ErrNotExist):
+		logrus.Debugf("注册表(%s)中不存在 HKLM\\%s", viewName, keyPath)
+	case errors.Is(err, windows.ERROR_ACCESS_DENIED):
+		logrus.Warnf("无权读取注册表(%s) HKLM\\%s，跳过注册表检测", viewName, keyPath)
+	default:
+		logrus.Debugf("读取注册表(%s) HKLM\\%s 失败: %v", viewName, keyPath, err)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build windows
+
+package oracle
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryOracleHomes(t *testing.T) {
+	homes := registryOracleHomes()
+	if len(homes) == 0 {
+		t.Skip("注册表中未登记Oracle安装，跳过")
+	}
+
+	seen := make(map[string]bool)
+	for _, home := range homes {
+		assert.True(t, filepath.IsAbs(home), "注册表中的ORACLE_HOME应为绝对路径: %s", home)
+		seen[strings.ToLower(filepath.Clean(home))] = true
+	}
+
+	// 去重后的结果不应包含重复路径
+	assert.Len(t, uniqueHomes(homes), len(seen))
+}
@@ -0,0 +1,191 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// oracleErrorPattern 匹配sqlplus输出中的错误代码
+var oracleErrorPattern = regexp.MustCompile(`\b((?:ORA|TNS|SP2)-\d+):?\s*(.*)`)
+
+// SQLPlusError sqlplus执行返回的Oracle错误
+type SQLPlusError struct {
+	Code    string
+	Message string
+	Output  string
+}
+
+// Error 实现error接口
+func (e *SQLPlusError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// SQLPlusRunner 通过sqlplus在源库执行SQL脚本
+type SQLPlusRunner struct {
+	oracleConfig *config.OracleConfig
+	tester       *ConnectionTester
+	// descriptor 替代默认EZConnect连接串的连接描述符，连接探测时使用
+	descriptor string
+}
+
+// NewSQLPlusRunner 创建sqlplus执行器，clientConfig 为nil时自动检测客户端
+func NewSQLPlusRunner(oracleConfig *config.OracleConfig, clientConfig *config.OracleClientConfig) *SQLPlusRunner {
+	tester := NewConnectionTester()
+	if clientConfig != nil {
+		tester.SetClientConfig(clientConfig)
+	}
+	return &SQLPlusRunner{
+		oracleConfig: oracleConfig,
+		tester:       tester,
+	}
+}
+
+// batchSectionMarker 批量执行时每段脚本输出前的分隔行前缀
+const batchSectionMarker = "@@ORA2PG_ADMIN_BATCH|"
+
+// Run 执行SQL脚本并返回输出
+//
+// 连接信息通过标准输入传递，避免密码出现在进程参数中；脚本中任一SQL出错即退出。
+func (r *SQLPlusRunner) Run(ctx context.Context, script string) (string, error) {
+	sqlplusPath, err := r.tester.findOracleTool("sqlplus")
+	if err != nil {
+		return "", utils.NewError(utils.ErrorTypeOracle, "SQLPLUS_NOT_FOUND").
+			Message("未找到sqlplus工具").
+			Cause(err).
+			Suggestion("运行 'ora2pg-admin 检查 环境' 确认Oracle客户端安装").
+			Build()
+	}
+
+	var input strings.Builder
+	input.WriteString("WHENEVER SQLERROR EXIT SQL.SQLCODE\n")
+	input.WriteString("WHENEVER OSERROR EXIT FAILURE\n")
+	descriptor := r.descriptor
+	if descriptor == "" {
+		descriptor = r.oracleConfig.ConnectDescriptor()
+	}
+	fmt.Fprintf(&input, "CONNECT %s/\"%s\"@%s\n", r.oracleConfig.Username, r.oracleConfig.Password, descriptor)
+	input.WriteString("SET HEADING OFF\nSET FEEDBACK OFF\nSET PAGESIZE 0\nSET LINESIZE 32767\nSET TRIMOUT ON\n")
+	input.WriteString(script)
+	input.WriteString("\nEXIT\n")
+
+	cmd := exec.CommandContext(ctx, sqlplusPath, "-S", "-L", "/nolog")
+	cmd.Env = r.tester.connectionEnvironment(r.oracleConfig)
+	cmd.Stdin = strings.NewReader(input.String())
+
+	output, err := cmd.CombinedOutput()
+	outputStr := string(output)
+	if ctx.Err() != nil {
+		return outputStr, fmt.Errorf("sqlplus执行被中断: %v", ctx.Err())
+	}
+	if matches := oracleErrorPattern.FindStringSubmatch(outputStr); matches != nil {
+		return outputStr, &SQLPlusError{
+			Code:    matches[1],
+			Message: strings.TrimSpace(matches[2]),
+			Output:  outputStr,
+		}
+	}
+	if err != nil {
+		return outputStr, fmt.Errorf("sqlplus执行失败: %v", err)
+	}
+	return outputStr, nil
+}
+
+// Version 执行 sqlplus -V，返回版本行，如 "Version 19.3.0.0.0"
+//
+// 基础版本的 Release 行固定为 x.0.0.0.0，有 Version 行时返回 Version 行，以区分补丁版本。
+func (r *SQLPlusRunner) Version(ctx context.Context) (string, error) {
+	sqlplusPath, err := r.tester.findOracleTool("sqlplus")
+	if err != nil {
+		return "", utils.NewError(utils.ErrorTypeOracle, "SQLPLUS_NOT_FOUND").
+			Message("未找到sqlplus工具").
+			Cause(err).
+			Suggestion("运行 'ora2pg-admin 检查 环境' 确认Oracle客户端安装").
+			Build()
+	}
+	cmd := exec.CommandContext(ctx, sqlplusPath, "-V")
+	cmd.Env = r.tester.toolEnvironment()
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("执行sqlplus -V失败: %v", err)
+	}
+	return sqlplusVersionLine(string(output)), nil
+}
+
+// sqlplusVersionLine 从 sqlplus -V 的输出中选出版本行
+func sqlplusVersionLine(output string) string {
+	var release string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "Version "):
+			return line
+		case release == "" && strings.Contains(line, "Release "):
+			release = line
+		}
+	}
+	if release == "" {
+		return strings.TrimSpace(output)
+	}
+	return release
+}
+
+// RunFile 通过 @ 命令执行SQL脚本文件，脚本中的PL/SQL块需要以 / 结尾
+func (r *SQLPlusRunner) RunFile(ctx context.Context, path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+	return r.Run(ctx, fmt.Sprintf("@\"%s\"", absPath))
+}
+
+// RunBatch 在同一个sqlplus会话中依次执行多段脚本，按顺序返回每段的输出
+//
+// 每次启动sqlplus都要重新建立连接和认证，多个短查询合并执行可省去重复的进程启动和登录开销。
+// 任一段出错时sqlplus立即退出，返回已执行各段的输出和错误。
+func (r *SQLPlusRunner) RunBatch(ctx context.Context, scripts ...string) ([]string, error) {
+	var batch strings.Builder
+	for i, script := range scripts {
+		fmt.Fprintf(&batch, "PROMPT %s%d\n", batchSectionMarker, i)
+		batch.WriteString(strings.TrimRight(script, "\n"))
+		batch.WriteString("\n")
+	}
+	output, err := r.Run(ctx, batch.String())
+	return splitBatchOutput(output, len(scripts)), err
+}
+
+// splitBatchOutput 按分隔行拆分批量执行的输出，分隔行之前的内容（如连接信息）被忽略
+func splitBatchOutput(output string, count int) []string {
+	sections := make([]strings.Builder, count)
+	current := -1
+	for _, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		if rest, found := strings.CutPrefix(strings.TrimSpace(line), batchSectionMarker); found {
+			if index, err := strconv.Atoi(rest); err == nil && index >= 0 && index < count {
+				current = index
+				continue
+			}
+		}
+		if current >= 0 {
+			sections[current].WriteString(line)
+			sections[current].WriteString("\n")
+		}
+	}
+
+	outputs := make([]string, count)
+	for i := range sections {
+		outputs[i] = sections[i].String()
+	}
+	return outputs
+}
+
+// quoteLiteral 转义SQL字符串字面量
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,135 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+)
+
+// fakeBatchSQLPlus 模拟sqlplus：回显PROMPT行，对 SELECT 'Q<n>' 返回 Q<n>；delay 模拟建立连接的耗时
+func fakeBatchSQLPlus(t testing.TB, delay string) *SQLPlusRunner {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟sqlplus依赖 /bin/sh")
+	}
+
+	home := t.TempDir()
+	script := `#!/bin/sh
+sleep ` + delay + `
+while IFS= read -r line; do
+  case "$line" in
+    PROMPT*) echo "${line#PROMPT }" ;;
+    *BROKEN*) echo "ORA-00942: table or view does not exist"; exit 1 ;;
+    "SELECT '"*) value=${line#SELECT \'}; echo "${value%%\'*}" ;;
+  esac
+done
+`
+	require.NoError(t, os.WriteFile(filepath.Join(home, "sqlplus"), []byte(script), 0755))
+	return NewSQLPlusRunner(&config.OracleConfig{
+		Host:     "localhost",
+		Port:     1521,
+		Service:  "ORCL",
+		Username: "scott",
+		Password: "tiger",
+	}, &config.OracleClientConfig{Home: home, AutoDetect: false})
+}
+
+func TestSplitBatchOutput(t *testing.T) {
+	output := "Connected.\n" + batchSectionMarker + "0\nA|1\nA|2\n" + batchSectionMarker + "1\n" + batchSectionMarker + "2\nC|1\n"
+	sections := splitBatchOutput(output, 3)
+	require.Len(t, sections, 3)
+	assert.Equal(t, "A|1\nA|2\n", sections[0])
+	assert.Empty(t, sections[1])
+	assert.Equal(t, "C|1\n", sections[2])
+
+	// 出错退出时后续段落为空
+	sections = splitBatchOutput(batchSectionMarker+"0\nORA-00942\n", 2)
+	assert.Equal(t, "ORA-00942\n", sections[0])
+	assert.Empty(t, sections[1])
+}
+
+func TestSQLPlusRunBatch(t *testing.T) {
+	runner := fakeBatchSQLPlus(t, "0")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	outputs, err := runner.RunBatch(ctx, "SELECT 'Q0' FROM dual;", "SELECT 'Q1' FROM dual;\n")
+	require.NoError(t, err)
+	require.Len(t, outputs, 2)
+	assert.Equal(t, "Q0", strings.TrimSpace(outputs[0]))
+	assert.Equal(t, "Q1", strings.TrimSpace(outputs[1]))
+
+	outputs, err = runner.RunBatch(ctx, "SELECT 'Q0' FROM dual;", "SELECT BROKEN FROM dual;", "SELECT 'Q2' FROM dual;")
+	require.Error(t, err)
+	var sqlErr *SQLPlusError
+	require.ErrorAs(t, err, &sqlErr)
+	assert.Equal(t, "ORA-00942", sqlErr.Code)
+	assert.Equal(t, "Q0", strings.TrimSpace(outputs[0]))
+	assert.Empty(t, outputs[2])
+}
+
+func TestSQLPlusRunTCPS(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟sqlplus依赖 /bin/sh")
+	}
+
+	// 模拟sqlplus：输出 TNS_ADMIN 和 CONNECT 行
+	home := t.TempDir()
+	script := `#!/bin/sh
+echo "TNS_ADMIN=$TNS_ADMIN"
+grep '^CONNECT'
+`
+	require.NoError(t, os.WriteFile(filepath.Join(home, "sqlplus"), []byte(script), 0755))
+	oracleConfig := &config.OracleConfig{Host: "db", Port: 2484, Service: "ORCL", Username: "scott", Password: "tiger",
+		UseTCPS: true, WalletDir: "/opt/wallet"}
+	runner := NewSQLPlusRunner(oracleConfig, &config.OracleClientConfig{Home: home, AutoDetect: false})
+
+	output, err := runner.Run(context.Background(), "SELECT 1 FROM dual;")
+	require.NoError(t, err)
+	assert.Contains(t, output, "TNS_ADMIN=/opt/wallet")
+	assert.Contains(t, output, "@"+oracleConfig.ConnectDescriptor())
+}
+
+// BenchmarkSQLPlusRunBatch 对比逐个启动sqlplus与单会话批量执行10个查询，
+// 模拟每次登录耗时20ms
+func BenchmarkSQLPlusRunBatch(b *testing.B) {
+	runner := fakeBatchSQLPlus(b, "0.02")
+	ctx := context.Background()
+	queries := make([]string, 10)
+	for i := range queries {
+		queries[i] = fmt.Sprintf("SELECT 'Q%d' FROM dual;", i)
+	}
+
+	b.Run("逐个执行", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			for _, query := range queries {
+				if _, err := runner.Run(ctx, query); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("批量执行", func(b *testing.B) {
+		for n := 0; n < b.N; n++ {
+			if _, err := runner.RunBatch(ctx, queries...); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestSQLPlusVersionLine(t *testing.T) {
+	// 有 Version 行时优先使用，以区分补丁版本
+	assert.Equal(t, "Version 19.3.0.0.0", sqlplusVersionLine("\nSQL*Plus: Release 19.0.0.0.0 - Production\nVersion 19.3.0.0.0\n\n"))
+	assert.Equal(t, "SQL*Plus: Release 12.2.0.1.0 Production", sqlplusVersionLine("\nSQL*Plus: Release 12.2.0.1.0 Production\n"))
+	assert.Equal(t, "unexpected", sqlplusVersionLine(" unexpected\n"))
+}
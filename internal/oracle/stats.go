@@ -0,0 +1,153 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// statsMarker 统计结果行的前缀，用于从sqlplus输出中定位结果
+const statsMarker = "STATS|"
+
+// DefaultStatsMaxAge 统计信息超过该时间视为过期
+const DefaultStatsMaxAge = 7 * 24 * time.Hour
+
+// 权限不足时DBMS_STATS返回的错误代码
+var statsPermissionCodes = map[string]bool{
+	"ORA-01031": true, // insufficient privileges
+	"ORA-20000": true, // DBMS_STATS: insufficient privileges to analyze
+	"ORA-00942": true, // 无法访问字典视图
+}
+
+// StatsFreshness Schema表统计信息的新鲜度
+type StatsFreshness struct {
+	Schema        string `json:"schema"`
+	Tables        int    `json:"tables"`
+	NeverAnalyzed int    `json:"never_analyzed"`
+	Stale         int    `json:"stale"`
+}
+
+// NeedsGather 是否存在缺失或过期的统计信息
+func (f *StatsFreshness) NeedsGather() bool {
+	return f.NeverAnalyzed > 0 || f.Stale > 0
+}
+
+// Summary 获取新鲜度摘要
+func (f *StatsFreshness) Summary() string {
+	return fmt.Sprintf("%s 共 %d 张表，%d 张从未收集统计，%d 张统计已过期",
+		f.Schema, f.Tables, f.NeverAnalyzed, f.Stale)
+}
+
+// StatsCollector Oracle统计信息检测和收集
+type StatsCollector struct {
+	runner *SQLPlusRunner
+	schema string
+}
+
+// NewStatsCollector 创建统计信息收集器
+func NewStatsCollector(runner *SQLPlusRunner, schema string) *StatsCollector {
+	return &StatsCollector{
+		runner: runner,
+		schema: strings.ToUpper(strings.TrimSpace(schema)),
+	}
+}
+
+// CheckFreshness 检测Schema下表统计信息的新鲜度
+func (c *StatsCollector) CheckFreshness(ctx context.Context, maxAge time.Duration) (*StatsFreshness, error) {
+	days := int(maxAge.Hours() / 24)
+	if days < 1 {
+		days = 1
+	}
+
+	query := fmt.Sprintf(`SELECT '%s' || COUNT(*) || '|'
+  || NVL(SUM(CASE WHEN last_analyzed IS NULL THEN 1 ELSE 0 END), 0) || '|'
+  || NVL(SUM(CASE WHEN last_analyzed IS NOT NULL AND (stale_stats = 'YES' OR last_analyzed < SYSDATE - %d) THEN 1 ELSE 0 END), 0)
+FROM all_tab_statistics
+WHERE owner = %s AND object_type = 'TABLE';`, statsMarker, days, quoteLiteral(c.schema))
+
+	output, err := c.runner.Run(ctx, query)
+	if err != nil {
+		return nil, c.wrapError("检测统计信息失败", err)
+	}
+
+	freshness, err := parseStatsFreshness(output)
+	if err != nil {
+		return nil, err
+	}
+	freshness.Schema = c.schema
+	return freshness, nil
+}
+
+// Gather 执行 DBMS_STATS.GATHER_SCHEMA_STATS 收集Schema统计信息
+//
+// 大库收集可能持续很长时间并在源库产生明显负载，超时由ctx控制。
+func (c *StatsCollector) Gather(ctx context.Context) error {
+	script := fmt.Sprintf(`BEGIN
+  DBMS_STATS.GATHER_SCHEMA_STATS(
+    ownname => %s,
+    options => 'GATHER AUTO',
+    cascade => TRUE,
+    degree  => DBMS_STATS.AUTO_DEGREE);
+END;
+/`, quoteLiteral(c.schema))
+
+	if _, err := c.runner.Run(ctx, script); err != nil {
+		return c.wrapError("收集统计信息失败", err)
+	}
+	return nil
+}
+
+// wrapError 转换sqlplus错误，区分权限不足的情况
+func (c *StatsCollector) wrapError(message string, err error) error {
+	var sqlErr *SQLPlusError
+	if errors.As(err, &sqlErr) && statsPermissionCodes[sqlErr.Code] {
+		return utils.NewError(utils.ErrorTypeOracle, "ORACLE_STATS_PERMISSION").
+			Message(fmt.Sprintf("%s：权限不足", message)).
+			Details(sqlErr.Error()).
+			Cause(err).
+			Suggestion(fmt.Sprintf("请DBA授予 ANALYZE ANY 权限，或由DBA手动收集 %s 的统计信息", c.schema)).
+			Build()
+	}
+	if utils.GetErrorCode(err) != "UNKNOWN" {
+		return err
+	}
+	return utils.NewError(utils.ErrorTypeOracle, "ORACLE_STATS_FAILED").
+		Message(message).
+		Details(err.Error()).
+		Cause(err).
+		Build()
+}
+
+// parseStatsFreshness 解析统计检测查询的输出
+func parseStatsFreshness(output string) (*StatsFreshness, error) {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, statsMarker) {
+			continue
+		}
+
+		fields := strings.Split(strings.TrimPrefix(line, statsMarker), "|")
+		if len(fields) != 3 {
+			break
+		}
+		values := make([]int, len(fields))
+		for i, field := range fields {
+			value, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil {
+				return nil, fmt.Errorf("解析统计信息结果失败: %s", line)
+			}
+			values[i] = value
+		}
+		return &StatsFreshness{
+			Tables:        values[0],
+			NeverAnalyzed: values[1],
+			Stale:         values[2],
+		}, nil
+	}
+	return nil, fmt.Errorf("未获取到统计信息结果")
+}
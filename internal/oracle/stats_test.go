@@ -0,0 +1,76 @@
+package oracle
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+func TestParseStatsFreshness(t *testing.T) {
+	freshness, err := parseStatsFreshness("\nSTATS|12|3|4\n\n")
+	require.NoError(t, err)
+	assert.Equal(t, 12, freshness.Tables)
+	assert.Equal(t, 3, freshness.NeverAnalyzed)
+	assert.Equal(t, 4, freshness.Stale)
+	assert.True(t, freshness.NeedsGather())
+
+	freshness, err = parseStatsFreshness("STATS|5|0|0")
+	require.NoError(t, err)
+	assert.False(t, freshness.NeedsGather())
+
+	_, err = parseStatsFreshness("no rows selected")
+	assert.Error(t, err)
+}
+
+func TestStatsCollectorWithFakeSQLPlus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟sqlplus依赖 /bin/sh")
+	}
+
+	// 模拟sqlplus：收集统计时返回权限错误，检测新鲜度时返回结果
+	home := t.TempDir()
+	script := `#!/bin/sh
+input=$(cat)
+case "$input" in
+  *CONNECT*) ;;
+  *) echo "SP2-0640: Not connected"; exit 1 ;;
+esac
+case "$input" in
+  *GATHER_SCHEMA_STATS*) echo "ORA-20000: Unable to analyze TABLE \"SCOTT\".\"EMP\", insufficient privileges"; exit 1 ;;
+  *all_tab_statistics*) echo "STATS|8|1|2" ;;
+esac
+`
+	require.NoError(t, os.WriteFile(filepath.Join(home, "sqlplus"), []byte(script), 0755))
+
+	runner := NewSQLPlusRunner(&config.OracleConfig{
+		Host:     "localhost",
+		Port:     1521,
+		Service:  "ORCL",
+		Username: "scott",
+		Password: "tiger",
+	}, &config.OracleClientConfig{Home: home, AutoDetect: false})
+	collector := NewStatsCollector(runner, "scott")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := collector.Gather(ctx)
+	require.Error(t, err)
+	assert.Equal(t, "ORACLE_STATS_PERMISSION", utils.GetErrorCode(err))
+
+	freshness, err := collector.CheckFreshness(ctx, DefaultStatsMaxAge)
+	require.NoError(t, err)
+	assert.Equal(t, "SCOTT", freshness.Schema)
+	assert.Equal(t, 8, freshness.Tables)
+	assert.Equal(t, 1, freshness.NeverAnalyzed)
+	assert.Equal(t, 2, freshness.Stale)
+}
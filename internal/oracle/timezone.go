@@ -0,0 +1,98 @@
+package oracle
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// timeZoneMarker 时区查询结果行的前缀
+const timeZoneMarker = "TZ|"
+
+// timeZoneQuery 查询数据库时区、会话时区、SYSDATE使用的服务器时区偏移和默认日期格式
+const timeZoneQuery = `SELECT '` + timeZoneMarker + `' || DBTIMEZONE || '|' || SESSIONTIMEZONE
+  || '|' || TO_CHAR(SYSTIMESTAMP, 'TZH:TZM') || '|' || TZ_OFFSET(SESSIONTIMEZONE)
+  || '|' || (SELECT value FROM nls_database_parameters WHERE parameter = 'NLS_DATE_FORMAT')
+  || '|' || (SELECT value FROM nls_database_parameters WHERE parameter = 'NLS_TIMESTAMP_FORMAT')
+FROM dual;`
+
+// TimeZoneSettings 源库的时区和日期格式设置
+type TimeZoneSettings struct {
+	// DBTimeZone 数据库时区（DBTIMEZONE），用于 TIMESTAMP WITH LOCAL TIME ZONE 的存储
+	DBTimeZone string `json:"db_time_zone"`
+	// SessionTimeZone 会话时区（SESSIONTIMEZONE），由客户端的 ORA_SDTZ 或操作系统时区决定
+	SessionTimeZone string `json:"session_time_zone"`
+	// SessionOffset 会话时区当前的偏移量，如 +08:00
+	SessionOffset string `json:"session_offset"`
+	// SystemOffset 数据库服务器操作系统的时区偏移量，SYSDATE 和 SYSTIMESTAMP 按此时区取值
+	SystemOffset string `json:"system_offset"`
+	// DateFormat 数据库默认的 NLS_DATE_FORMAT
+	DateFormat string `json:"date_format"`
+	// TimestampFormat 数据库默认的 NLS_TIMESTAMP_FORMAT
+	TimestampFormat string `json:"timestamp_format"`
+}
+
+// QueryTimeZoneSettings 查询源库的时区和日期格式设置
+func QueryTimeZoneSettings(ctx context.Context, runner *SQLPlusRunner) (*TimeZoneSettings, error) {
+	output, err := runner.Run(ctx, timeZoneQuery)
+	if err != nil {
+		return nil, utils.NewError(utils.ErrorTypeOracle, "ORACLE_TIMEZONE_QUERY_FAILED").
+			Message("查询Oracle时区设置失败").
+			Details(err.Error()).
+			Cause(err).
+			Build()
+	}
+	return parseTimeZoneSettings(output)
+}
+
+// parseTimeZoneSettings 解析时区查询的输出
+func parseTimeZoneSettings(output string) (*TimeZoneSettings, error) {
+	for _, line := range strings.Split(output, "\n") {
+		fields, found := strings.CutPrefix(strings.TrimSpace(line), timeZoneMarker)
+		if !found {
+			continue
+		}
+		values := strings.Split(fields, "|")
+		if len(values) < 6 {
+			break
+		}
+		return &TimeZoneSettings{
+			DBTimeZone:      strings.TrimSpace(values[0]),
+			SessionTimeZone: strings.TrimSpace(values[1]),
+			SystemOffset:    strings.TrimSpace(values[2]),
+			SessionOffset:   strings.TrimSpace(values[3]),
+			DateFormat:      strings.TrimSpace(values[4]),
+			TimestampFormat: strings.TrimSpace(values[5]),
+		}, nil
+	}
+	return nil, utils.NewError(utils.ErrorTypeOracle, "ORACLE_TIMEZONE_QUERY_FAILED").
+		Message("查询Oracle时区设置失败").
+		Details("sqlplus输出中没有时区设置").
+		Build()
+}
+
+// OffsetSeconds 把 +08:00、-05:30 形式的时区偏移转换为秒数，格式无效时返回 false
+func OffsetSeconds(offset string) (int, bool) {
+	offset = strings.TrimSpace(offset)
+	if len(offset) < 2 || (offset[0] != '+' && offset[0] != '-') {
+		return 0, false
+	}
+	hours, minutes, _ := strings.Cut(offset[1:], ":")
+	h, err := strconv.Atoi(hours)
+	if err != nil || h > 14 {
+		return 0, false
+	}
+	m := 0
+	if minutes != "" {
+		if m, err = strconv.Atoi(minutes); err != nil || m >= 60 {
+			return 0, false
+		}
+	}
+	seconds := h*3600 + m*60
+	if offset[0] == '-' {
+		seconds = -seconds
+	}
+	return seconds, true
+}
@@ -0,0 +1,37 @@
+package oracle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeZoneSettings(t *testing.T) {
+	settings, err := parseTimeZoneSettings("\nTZ|+00:00|Asia/Shanghai|+08:00|+08:00|DD-MON-RR|DD-MON-RR HH.MI.SSXFF AM\n")
+	require.NoError(t, err)
+	assert.Equal(t, "+00:00", settings.DBTimeZone)
+	assert.Equal(t, "Asia/Shanghai", settings.SessionTimeZone)
+	assert.Equal(t, "+08:00", settings.SystemOffset)
+	assert.Equal(t, "+08:00", settings.SessionOffset)
+	assert.Equal(t, "DD-MON-RR", settings.DateFormat)
+	assert.Equal(t, "DD-MON-RR HH.MI.SSXFF AM", settings.TimestampFormat)
+
+	_, err = parseTimeZoneSettings("ORA-00942: table or view does not exist\n")
+	assert.Error(t, err)
+	_, err = parseTimeZoneSettings("TZ|+00:00|UTC\n")
+	assert.Error(t, err)
+}
+
+func TestOffsetSeconds(t *testing.T) {
+	tests := map[string]int{"+08:00": 28800, "-05:30": -19800, "+00:00": 0, " +9 ": 32400}
+	for offset, expected := range tests {
+		seconds, ok := OffsetSeconds(offset)
+		assert.True(t, ok, offset)
+		assert.Equal(t, expected, seconds, offset)
+	}
+	for _, offset := range []string{"", "UTC", "Asia/Shanghai", "+08:75", "+15:00"} {
+		_, ok := OffsetSeconds(offset)
+		assert.False(t, ok, offset)
+	}
+}
@@ -0,0 +1,177 @@
+package oracle
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// 增量列查询结果行的前缀
+const (
+	watermarkColumnMarker = "WMCOL|"
+	watermarkValueMarker  = "WM|"
+)
+
+// WatermarkTimestampFormat 时间戳水位的格式，与 TO_TIMESTAMP 比较时使用同一格式
+const WatermarkTimestampFormat = "YYYY-MM-DD HH24:MI:SS.FF6"
+
+// WatermarkColumn 需要查询水位的增量列
+type WatermarkColumn struct {
+	Table  string
+	Column string
+	// Timestamp 为 true 时按时间戳列取值，否则按数值列取值
+	Timestamp bool
+}
+
+// Watermark 增量列当前的最大值
+type Watermark struct {
+	Table    string `json:"table"`
+	Column   string `json:"column"`
+	DataType string `json:"data_type"`
+	Nullable bool   `json:"nullable"`
+	// Value 当前最大值，时间戳按 WatermarkTimestampFormat 格式化；表中没有非空值时为空
+	Value string `json:"value"`
+	// Unusable 列不存在或类型不适合增量同步的原因，为空表示可用
+	Unusable string `json:"unusable,omitempty"`
+}
+
+// Watermarks 查询增量列的类型和当前最大值
+//
+// 先确认列存在且类型与配置相符，只对可用的列计算最大值，避免一张表配置错误导致整个查询失败。
+func (i *Inspector) Watermarks(ctx context.Context, columns []WatermarkColumn) ([]Watermark, error) {
+	if len(columns) == 0 {
+		return nil, nil
+	}
+	output, err := i.runner.Run(ctx, i.watermarkColumnsQuery(columns))
+	if err != nil {
+		return nil, i.watermarkError(err)
+	}
+	types := parseWatermarkColumns(output)
+
+	watermarks := make([]Watermark, len(columns))
+	var usable []WatermarkColumn
+	for idx, column := range columns {
+		watermark := Watermark{Table: column.Table, Column: column.Column}
+		if info, exists := types[column.Table+"."+column.Column]; exists {
+			watermark.DataType = info.dataType
+			watermark.Nullable = info.nullable
+			watermark.Unusable = watermarkTypeProblem(info.dataType, column.Timestamp)
+		} else {
+			watermark.Unusable = fmt.Sprintf("表 %s 中没有列 %s", column.Table, column.Column)
+		}
+		if watermark.Unusable == "" {
+			usable = append(usable, column)
+		}
+		watermarks[idx] = watermark
+	}
+	if len(usable) == 0 {
+		return watermarks, nil
+	}
+
+	output, err = i.runner.Run(ctx, i.watermarkValuesQuery(usable))
+	if err != nil {
+		return nil, i.watermarkError(err)
+	}
+	values := parseWatermarkValues(output)
+	for idx := range watermarks {
+		watermarks[idx].Value = values[watermarks[idx].Table]
+	}
+	return watermarks, nil
+}
+
+// watermarkColumnsQuery 构建增量列类型查询
+func (i *Inspector) watermarkColumnsQuery(columns []WatermarkColumn) string {
+	pairs := make([]string, len(columns))
+	for idx, column := range columns {
+		pairs[idx] = fmt.Sprintf("(%s, %s)", quoteLiteral(column.Table), quoteLiteral(column.Column))
+	}
+	return fmt.Sprintf(`SELECT '%s' || table_name || '.' || column_name || '|' || data_type || '|' || nullable
+FROM all_tab_columns
+WHERE owner = %s AND (table_name, column_name) IN (%s);`,
+		watermarkColumnMarker, quoteLiteral(i.schema), strings.Join(pairs, ", "))
+}
+
+// watermarkValuesQuery 构建增量列最大值查询，每张表一条语句
+func (i *Inspector) watermarkValuesQuery(columns []WatermarkColumn) string {
+	var builder strings.Builder
+	for _, column := range columns {
+		value := fmt.Sprintf(`TO_CHAR(MAX("%s"))`, column.Column)
+		if column.Timestamp {
+			// DATE 转为 TIMESTAMP 后与 TIMESTAMP 列使用同一格式
+			value = fmt.Sprintf(`TO_CHAR(CAST(MAX("%s") AS TIMESTAMP), '%s')`, column.Column, WatermarkTimestampFormat)
+		}
+		fmt.Fprintf(&builder, "SELECT '%s%s|' || %s FROM \"%s\".\"%s\";\n",
+			watermarkValueMarker, column.Table, value, i.schema, column.Table)
+	}
+	return builder.String()
+}
+
+// watermarkError 构建查询水位失败的错误
+func (i *Inspector) watermarkError(err error) error {
+	return utils.NewError(utils.ErrorTypeOracle, "ORACLE_WATERMARK_QUERY_FAILED").
+		Message(fmt.Sprintf("查询 %s 的增量列水位失败", i.schema)).
+		Details(err.Error()).
+		Cause(err).
+		Suggestion("运行 'ora2pg-admin 检查 连接' 确认源库连接和增量表的查询权限").
+		Build()
+}
+
+// watermarkColumnInfo 增量列的类型信息
+type watermarkColumnInfo struct {
+	dataType string
+	nullable bool
+}
+
+// parseWatermarkColumns 解析增量列类型查询的输出，键为 TABLE.COLUMN
+func parseWatermarkColumns(output string) map[string]watermarkColumnInfo {
+	columns := make(map[string]watermarkColumnInfo)
+	for _, line := range strings.Split(output, "\n") {
+		fields, found := strings.CutPrefix(strings.TrimSpace(line), watermarkColumnMarker)
+		if !found {
+			continue
+		}
+		values := strings.Split(fields, "|")
+		if len(values) < 3 {
+			continue
+		}
+		columns[strings.TrimSpace(values[0])] = watermarkColumnInfo{
+			dataType: strings.TrimSpace(values[1]),
+			nullable: strings.TrimSpace(values[2]) == "Y",
+		}
+	}
+	return columns
+}
+
+// parseWatermarkValues 解析增量列最大值查询的输出，键为表名
+func parseWatermarkValues(output string) map[string]string {
+	values := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		fields, found := strings.CutPrefix(strings.TrimSpace(line), watermarkValueMarker)
+		if !found {
+			continue
+		}
+		table, value, _ := strings.Cut(fields, "|")
+		values[strings.TrimSpace(table)] = strings.TrimSpace(value)
+	}
+	return values
+}
+
+// watermarkTypeProblem 列类型不适合作为增量列的原因，适合时返回空
+//
+// 带时区的时间戳按会话时区比较，sqlplus和ora2pg的会话时区不一致时会漏数据，暂不支持。
+func watermarkTypeProblem(dataType string, timestamp bool) string {
+	switch {
+	case timestamp && (dataType == "DATE" || (strings.HasPrefix(dataType, "TIMESTAMP") && !strings.Contains(dataType, "TIME ZONE"))):
+		return ""
+	case timestamp && strings.Contains(dataType, "TIME ZONE"):
+		return fmt.Sprintf("带时区的时间戳列（%s）暂不支持增量同步", dataType)
+	case timestamp:
+		return fmt.Sprintf("列类型 %s 不是 DATE 或 TIMESTAMP", dataType)
+	case dataType == "NUMBER" || dataType == "FLOAT" || dataType == "BINARY_FLOAT" || dataType == "BINARY_DOUBLE":
+		return ""
+	default:
+		return fmt.Sprintf("列类型 %s 不是数值类型", dataType)
+	}
+}
@@ -0,0 +1,44 @@
+package oracle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWatermarkOutput(t *testing.T) {
+	columns := parseWatermarkColumns("WMCOL|ORDERS.UPDATED_AT|TIMESTAMP(6)|Y\nWMCOL|EVENTS.ID|NUMBER|N\nORA-00000\n")
+	assert.Equal(t, watermarkColumnInfo{dataType: "TIMESTAMP(6)", nullable: true}, columns["ORDERS.UPDATED_AT"])
+	assert.Equal(t, watermarkColumnInfo{dataType: "NUMBER", nullable: false}, columns["EVENTS.ID"])
+
+	// 空表的最大值为空
+	values := parseWatermarkValues("WM|ORDERS|2024-05-01 10:00:00.123456\nWM|EVENTS|\n")
+	assert.Equal(t, "2024-05-01 10:00:00.123456", values["ORDERS"])
+	value, exists := values["EVENTS"]
+	assert.True(t, exists)
+	assert.Empty(t, value)
+}
+
+func TestWatermarkTypeProblem(t *testing.T) {
+	for _, dataType := range []string{"DATE", "TIMESTAMP(6)", "TIMESTAMP(3)"} {
+		assert.Empty(t, watermarkTypeProblem(dataType, true), dataType)
+	}
+	assert.Contains(t, watermarkTypeProblem("TIMESTAMP(6) WITH TIME ZONE", true), "带时区")
+	assert.Contains(t, watermarkTypeProblem("VARCHAR2", true), "不是 DATE 或 TIMESTAMP")
+
+	assert.Empty(t, watermarkTypeProblem("NUMBER", false))
+	assert.Contains(t, watermarkTypeProblem("DATE", false), "不是数值类型")
+}
+
+func TestWatermarkValuesQuery(t *testing.T) {
+	inspector := NewInspector(nil, "app")
+	query := inspector.watermarkValuesQuery([]WatermarkColumn{
+		{Table: "ORDERS", Column: "UPDATED_AT", Timestamp: true},
+		{Table: "EVENTS", Column: "ID"},
+	})
+	assert.Contains(t, query, `SELECT 'WM|ORDERS|' || TO_CHAR(CAST(MAX("UPDATED_AT") AS TIMESTAMP), 'YYYY-MM-DD HH24:MI:SS.FF6') FROM "APP"."ORDERS";`)
+	assert.Contains(t, query, `SELECT 'WM|EVENTS|' || TO_CHAR(MAX("ID")) FROM "APP"."EVENTS";`)
+
+	assert.Contains(t, inspector.watermarkColumnsQuery([]WatermarkColumn{{Table: "ORDERS", Column: "UPDATED_AT"}}),
+		"(table_name, column_name) IN (('ORDERS', 'UPDATED_AT'))")
+}
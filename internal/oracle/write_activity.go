@@ -0,0 +1,157 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// 源库写入状态查询结果行的前缀
+const (
+	writeDatabaseMarker    = "WDB|"
+	writeTransactionMarker = "WTX|"
+)
+
+// writeActivityLimit 最多列出的活跃事务数
+const writeActivityLimit = 20
+
+// writeActivityQuery 查询数据库打开模式和活跃事务，排除本会话
+//
+// V$TRANSACTION 中的每一行都是已开始但未提交的事务，事务所在会话通过 V$SESSION.TADDR 关联。
+var writeActivityQuery = fmt.Sprintf(`SELECT '%s' || open_mode || '|' || database_role FROM v$database;
+SELECT '%s' || s.sid || '|' || s.serial# || '|' || NVL(s.username, '-') || '|'
+  || NVL(REPLACE(s.machine, '|', '/'), '-') || '|' || NVL(REPLACE(s.program, '|', '/'), '-') || '|'
+  || t.start_time || '|' || t.used_urec
+FROM v$transaction t, v$session s
+WHERE s.taddr = t.addr AND s.sid <> SYS_CONTEXT('USERENV', 'SID') AND ROWNUM <= %d;`,
+	writeDatabaseMarker, writeTransactionMarker, writeActivityLimit)
+
+// ActiveTransaction 源库中未提交的写事务
+type ActiveTransaction struct {
+	SID      int    `json:"sid"`
+	Serial   int    `json:"serial"`
+	Username string `json:"username"`
+	Machine  string `json:"machine"`
+	Program  string `json:"program"`
+	// StartTime 事务开始时间，格式为 MM/DD/YY HH24:MI:SS
+	StartTime string `json:"start_time"`
+	// UndoRecords 事务已产生的undo记录数，反映修改的行数
+	UndoRecords int64 `json:"undo_records"`
+}
+
+// WriteActivity 源库是否仍在接受写入
+type WriteActivity struct {
+	OpenMode     string              `json:"open_mode"`
+	DatabaseRole string              `json:"database_role"`
+	Transactions []ActiveTransaction `json:"transactions"`
+}
+
+// ReadOnly 数据库是否以只读模式打开（包括 READ ONLY WITH APPLY 的备库）
+func (a *WriteActivity) ReadOnly() bool {
+	return strings.HasPrefix(a.OpenMode, "READ ONLY")
+}
+
+// Writing 是否有其他会话的未提交写事务
+func (a *WriteActivity) Writing() bool {
+	return len(a.Transactions) > 0
+}
+
+// Summary 获取写入状态摘要
+func (a *WriteActivity) Summary() string {
+	switch {
+	case a.ReadOnly():
+		return fmt.Sprintf("源库以只读模式打开（%s）", a.OpenMode)
+	case a.Writing():
+		return fmt.Sprintf("源库仍在接受写入：%d 个会话有未提交的写事务", len(a.Transactions))
+	default:
+		return "源库以读写模式打开，当前没有未提交的写事务"
+	}
+}
+
+// Sessions 有写事务的会话描述，用于排查写入来源
+func (a *WriteActivity) Sessions() []string {
+	sessions := make([]string, len(a.Transactions))
+	for i, tx := range a.Transactions {
+		sessions[i] = fmt.Sprintf("SID %d,%d %s@%s（%s），%s 开始，%d 条undo记录",
+			tx.SID, tx.Serial, tx.Username, tx.Machine, tx.Program, tx.StartTime, tx.UndoRecords)
+	}
+	return sessions
+}
+
+// ReadOnlySuggestions 将源库置为只读的建议SQL
+//
+// 整库只读需要重启实例；不能停机时把迁移Schema的表设为只读，迁移完成后再恢复为 READ WRITE。
+func ReadOnlySuggestions(schema string) []string {
+	schema = strings.ToUpper(strings.TrimSpace(schema))
+	return []string{
+		"整库只读（需要重启实例，由DBA以SYSDBA执行）: SHUTDOWN IMMEDIATE; STARTUP MOUNT; ALTER DATABASE OPEN READ ONLY;",
+		fmt.Sprintf("只读迁移的表（不停机，11g及以上）: 执行 SELECT 'ALTER TABLE \"' || owner || '\".\"' || table_name || '\" READ ONLY;' "+
+			"FROM all_tables WHERE owner = %s; 生成的语句，迁移完成后改为 READ WRITE 恢复", quoteLiteral(schema)),
+		"停止应用写入后，等待或确认结束仍未提交的事务（ALTER SYSTEM KILL SESSION '<SID>,<SERIAL#>'）",
+	}
+}
+
+// WriteActivity 查询源库的打开模式和其他会话的未提交写事务，需要 V$DATABASE、V$TRANSACTION、V$SESSION 的查询权限
+func (i *Inspector) WriteActivity(ctx context.Context) (*WriteActivity, error) {
+	output, err := i.runner.Run(ctx, writeActivityQuery)
+	if err != nil {
+		var sqlErr *SQLPlusError
+		if errors.As(err, &sqlErr) && statsPermissionCodes[sqlErr.Code] {
+			return nil, utils.NewError(utils.ErrorTypeOracle, "ORACLE_WRITE_CHECK_DENIED").
+				Message("无权限查询源库的事务状态").
+				Details(err.Error()).
+				Cause(err).
+				Suggestion("由DBA授予 SELECT_CATALOG_ROLE，或 GRANT SELECT ON V_$DATABASE、V_$TRANSACTION、V_$SESSION 给迁移用户").
+				Build()
+		}
+		return nil, utils.NewError(utils.ErrorTypeOracle, "ORACLE_WRITE_CHECK_FAILED").
+			Message("查询源库的事务状态失败").
+			Details(err.Error()).
+			Cause(err).
+			Suggestion("运行 'ora2pg-admin 检查 连接' 确认源库连接").
+			Build()
+	}
+	return parseWriteActivity(output)
+}
+
+// parseWriteActivity 解析写入状态查询的输出
+func parseWriteActivity(output string) (*WriteActivity, error) {
+	activity := &WriteActivity{}
+	found := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if fields, ok := strings.CutPrefix(line, writeDatabaseMarker); ok {
+			mode, role, _ := strings.Cut(fields, "|")
+			activity.OpenMode = strings.TrimSpace(mode)
+			activity.DatabaseRole = strings.TrimSpace(role)
+			found = true
+			continue
+		}
+		fields, ok := strings.CutPrefix(line, writeTransactionMarker)
+		if !ok {
+			continue
+		}
+		parts := strings.Split(fields, "|")
+		if len(parts) != 7 {
+			return nil, fmt.Errorf("解析事务查询结果失败: %s", line)
+		}
+		sid, err1 := strconv.Atoi(parts[0])
+		serial, err2 := strconv.Atoi(parts[1])
+		undo, err3 := strconv.ParseInt(parts[6], 10, 64)
+		if err1 != nil || err2 != nil || err3 != nil {
+			return nil, fmt.Errorf("解析事务查询结果失败: %s", line)
+		}
+		activity.Transactions = append(activity.Transactions, ActiveTransaction{
+			SID: sid, Serial: serial, Username: parts[2], Machine: parts[3], Program: parts[4],
+			StartTime: parts[5], UndoRecords: undo,
+		})
+	}
+	if !found {
+		return nil, fmt.Errorf("未找到数据库打开模式的查询结果")
+	}
+	return activity, nil
+}
@@ -0,0 +1,43 @@
+package oracle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWriteActivity(t *testing.T) {
+	activity, err := parseWriteActivity("WDB|READ WRITE|PRIMARY\n" +
+		"WTX|123|4567|APP|app-server-01|JDBC Thin Client|05/06/24 10:01:02|42\n")
+	require.NoError(t, err)
+	assert.False(t, activity.ReadOnly())
+	assert.True(t, activity.Writing())
+	assert.Equal(t, "PRIMARY", activity.DatabaseRole)
+	assert.Equal(t, ActiveTransaction{SID: 123, Serial: 4567, Username: "APP", Machine: "app-server-01",
+		Program: "JDBC Thin Client", StartTime: "05/06/24 10:01:02", UndoRecords: 42}, activity.Transactions[0])
+	assert.Equal(t, "源库仍在接受写入：1 个会话有未提交的写事务", activity.Summary())
+	assert.Equal(t, []string{"SID 123,4567 APP@app-server-01（JDBC Thin Client），05/06/24 10:01:02 开始，42 条undo记录"}, activity.Sessions())
+
+	activity, err = parseWriteActivity("WDB|READ ONLY WITH APPLY|PHYSICAL STANDBY\n")
+	require.NoError(t, err)
+	assert.True(t, activity.ReadOnly())
+	assert.False(t, activity.Writing())
+
+	activity, err = parseWriteActivity("WDB|READ WRITE|PRIMARY\n")
+	require.NoError(t, err)
+	assert.Equal(t, "源库以读写模式打开，当前没有未提交的写事务", activity.Summary())
+
+	_, err = parseWriteActivity("")
+	assert.Error(t, err)
+	_, err = parseWriteActivity("WDB|READ WRITE|PRIMARY\nWTX|x|1|APP|m|p|t|1\n")
+	assert.Error(t, err)
+}
+
+func TestReadOnlySuggestions(t *testing.T) {
+	suggestions := ReadOnlySuggestions(" app ")
+	require.Len(t, suggestions, 3)
+	assert.Contains(t, suggestions[0], "ALTER DATABASE OPEN READ ONLY")
+	assert.Contains(t, suggestions[1], "WHERE owner = 'APP'")
+	assert.Contains(t, writeActivityQuery, "FROM v$transaction t, v$session s")
+}
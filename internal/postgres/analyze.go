@@ -0,0 +1,159 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// AnalyzeScope ANALYZE 的范围
+type AnalyzeScope string
+
+const (
+	// AnalyzeScopeMigrated 只分析本次迁移了数据的表
+	AnalyzeScopeMigrated AnalyzeScope = "migrated"
+	// AnalyzeScopeSchema 分析目标模式下的全部表
+	AnalyzeScopeSchema AnalyzeScope = "schema"
+	// AnalyzeScopeDatabase 对整个数据库执行 ANALYZE
+	AnalyzeScopeDatabase AnalyzeScope = "database"
+)
+
+// ParseAnalyzeScope 解析 ANALYZE 范围
+func ParseAnalyzeScope(value string) (AnalyzeScope, error) {
+	switch scope := AnalyzeScope(strings.ToLower(strings.TrimSpace(value))); scope {
+	case AnalyzeScopeMigrated, AnalyzeScopeSchema, AnalyzeScopeDatabase:
+		return scope, nil
+	}
+	return "", utils.NewError(utils.ErrorTypeValidation, "INVALID_ANALYZE_SCOPE").
+		Message("无效的ANALYZE范围").
+		Details(value).
+		Suggestion("可选值: migrated（本次迁移的表）、schema（目标模式）、database（整库）").
+		Build()
+}
+
+// AnalyzeSkip 未能分析的表及原因
+type AnalyzeSkip struct {
+	Table  string
+	Reason string
+}
+
+// AnalyzeResult ANALYZE 执行结果
+type AnalyzeResult struct {
+	Analyzed []string
+	Skipped  []AnalyzeSkip
+	Duration time.Duration
+}
+
+// Analyzer 在目标库执行 ANALYZE 更新统计信息
+type Analyzer struct {
+	runner *PSQLRunner
+	schema string
+}
+
+// NewAnalyzer 创建统计信息分析器，schema 为目标模式
+func NewAnalyzer(runner *PSQLRunner, schema string) *Analyzer {
+	if schema == "" {
+		schema = "public"
+	}
+	return &Analyzer{runner: runner, schema: schema}
+}
+
+// SchemaTables 获取目标模式下的全部普通表和分区表
+func (a *Analyzer) SchemaTables(ctx context.Context) ([]string, error) {
+	query := fmt.Sprintf(`SELECT c.relname FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = %s AND c.relkind IN ('r', 'p')
+ORDER BY c.relname;`, QuoteLiteral(a.schema))
+
+	output, err := a.runner.Run(ctx, query)
+	if err != nil {
+		return nil, analyzeError(err)
+	}
+
+	var tables []string
+	for _, line := range strings.Split(output, "\n") {
+		if table := strings.TrimSpace(line); table != "" {
+			tables = append(tables, table)
+		}
+	}
+	return tables, nil
+}
+
+// AnalyzeTables 逐表执行 ANALYZE，权限不足或表不存在时跳过并继续
+//
+// onTable 在每张表开始前调用，用于显示进度；中断时返回已完成的部分结果。
+func (a *Analyzer) AnalyzeTables(ctx context.Context, tables []string, onTable func(index, total int, table string)) (*AnalyzeResult, error) {
+	startTime := time.Now()
+	result := &AnalyzeResult{}
+	defer func() { result.Duration = time.Since(startTime) }()
+
+	for i, table := range tables {
+		if onTable != nil {
+			onTable(i+1, len(tables), table)
+		}
+
+		statement := fmt.Sprintf("ANALYZE %s.%s;", QuoteIdentifier(a.schema), QuoteIdentifier(table))
+		if _, err := a.runner.Run(ctx, statement); err != nil {
+			if ctx.Err() != nil {
+				return result, err
+			}
+
+			var psqlErr *PSQLError
+			switch {
+			case errors.As(err, &psqlErr) && psqlErr.IsPermissionDenied():
+				result.Skipped = append(result.Skipped, AnalyzeSkip{Table: table, Reason: "权限不足（需要表所有者或超级用户）"})
+			case errors.As(err, &psqlErr) && psqlErr.Code == "42P01":
+				result.Skipped = append(result.Skipped, AnalyzeSkip{Table: table, Reason: "目标库中不存在"})
+			case errors.As(err, &psqlErr) && psqlErr.Code != "":
+				result.Skipped = append(result.Skipped, AnalyzeSkip{Table: table, Reason: psqlErr.Error()})
+			default:
+				// psql缺失或连接失败时后续表同样会失败，直接返回
+				return result, analyzeError(err)
+			}
+			continue
+		}
+		result.Analyzed = append(result.Analyzed, table)
+	}
+	return result, nil
+}
+
+// AnalyzeDatabase 对整个数据库执行 ANALYZE，无权限的表由PostgreSQL自动跳过
+func (a *Analyzer) AnalyzeDatabase(ctx context.Context) (*AnalyzeResult, error) {
+	startTime := time.Now()
+	if _, err := a.runner.Run(ctx, "ANALYZE;"); err != nil {
+		return nil, analyzeError(err)
+	}
+	return &AnalyzeResult{Duration: time.Since(startTime)}, nil
+}
+
+// TargetTableName 把ora2pg输出中的Oracle表名转换为目标库表名
+//
+// 去掉模式前缀；未开启 PRESERVE_CASE 时ora2pg会把名称转为小写。
+func TargetTableName(oracleName string, preserveCase bool) string {
+	name := strings.TrimSpace(oracleName)
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		name = name[idx+1:]
+	}
+	if !preserveCase {
+		name = strings.ToLower(name)
+	}
+	return name
+}
+
+// analyzeError 把psql错误转换为带建议的错误
+func analyzeError(err error) error {
+	var psqlErr *PSQLError
+	if !errors.As(err, &psqlErr) {
+		return err
+	}
+	return utils.NewError(utils.ErrorTypePostgres, "PG_ANALYZE_FAILED").
+		Message("执行ANALYZE失败").
+		Details(psqlErr.Error()).
+		Cause(err).
+		Suggestion("运行 'ora2pg-admin 检查 连接' 确认目标库连接").
+		Build()
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+func TestParseAnalyzeScope(t *testing.T) {
+	scope, err := ParseAnalyzeScope(" Schema ")
+	require.NoError(t, err)
+	assert.Equal(t, AnalyzeScopeSchema, scope)
+
+	_, err = ParseAnalyzeScope("all")
+	assert.Equal(t, "INVALID_ANALYZE_SCOPE", utils.GetErrorCode(err))
+}
+
+func TestTargetTableName(t *testing.T) {
+	assert.Equal(t, "emp", TargetTableName("SCOTT.EMP", false))
+	assert.Equal(t, "EMP", TargetTableName("SCOTT.EMP", true))
+	assert.Equal(t, "dept", TargetTableName(" DEPT ", false))
+	assert.Equal(t, `"a""b"`, QuoteIdentifier(`a"b`))
+	assert.Equal(t, `'it''s'`, QuoteLiteral("it's"))
+}
+
+func TestAnalyzerWithFakePSQL(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟psql依赖 /bin/sh")
+	}
+
+	// 模拟psql：secret表无权限，missing表不存在，其他表成功
+	bin := t.TempDir()
+	script := `#!/bin/sh
+input=$(cat)
+case "$input" in
+  *pg_class*) printf 'emp\nsecret\n' ;;
+  *'"secret"'*) echo 'psql:<stdin>:2: ERROR:  42501: permission denied for table secret'; exit 3 ;;
+  *'"missing"'*) echo 'psql:<stdin>:2: ERROR:  42P01: relation "public.missing" does not exist'; exit 3 ;;
+esac
+`
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "psql"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	analyzer := NewAnalyzer(NewPSQLRunner(&config.PostgreConfig{
+		Host:     "localhost",
+		Port:     5432,
+		Database: "app",
+		Username: "app",
+	}), "")
+
+	tables, err := analyzer.SchemaTables(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"emp", "secret"}, tables)
+
+	var progress []string
+	result, err := analyzer.AnalyzeTables(context.Background(), []string{"emp", "secret", "missing"},
+		func(index, total int, table string) { progress = append(progress, table) })
+	require.NoError(t, err)
+	assert.Equal(t, []string{"emp", "secret", "missing"}, progress)
+	assert.Equal(t, []string{"emp"}, result.Analyzed)
+	require.Len(t, result.Skipped, 2)
+	assert.Equal(t, "secret", result.Skipped[0].Table)
+	assert.Contains(t, result.Skipped[0].Reason, "权限不足")
+	assert.Equal(t, "missing", result.Skipped[1].Table)
+}
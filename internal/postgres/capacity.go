@@ -0,0 +1,219 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// 容量查询结果行的前缀
+const (
+	capacitySettingMarker  = "SET|"
+	capacityActiveMarker   = "ACTIVE|"
+	capacityRoleMarker     = "ROLE|"
+	capacityDatabaseMarker = "DB|"
+)
+
+// capacitySettings 容量预检查询的 pg_settings 参数
+var capacitySettings = []string{
+	"max_connections",
+	"superuser_reserved_connections",
+	"shared_buffers",
+	"work_mem",
+	"maintenance_work_mem",
+	"max_wal_size",
+	"temp_file_limit",
+	"statement_timeout",
+}
+
+// Capacity 目标库承载迁移相关的参数和当前使用情况
+type Capacity struct {
+	MaxConnections      int `json:"max_connections"`
+	ReservedConnections int `json:"superuser_reserved_connections"`
+	// ActiveConnections 当前实例上的连接数
+	ActiveConnections int `json:"active_connections"`
+	// RoleConnLimit、DatabaseConnLimit 当前用户和数据库的连接数限制，-1 表示不限制
+	RoleConnLimit     int `json:"role_conn_limit"`
+	DatabaseConnLimit int `json:"database_conn_limit"`
+	// Superuser 当前用户是否为超级用户，超级用户可以使用保留连接
+	Superuser bool `json:"superuser"`
+
+	SharedBuffers      int64 `json:"shared_buffers"`
+	WorkMem            int64 `json:"work_mem"`
+	MaintenanceWorkMem int64 `json:"maintenance_work_mem"`
+	MaxWalSize         int64 `json:"max_wal_size"`
+	// TempFileLimit 单个会话临时文件的上限（字节），-1 表示不限制
+	TempFileLimit int64 `json:"temp_file_limit"`
+	// StatementTimeout 语句超时（毫秒），0 表示不限制
+	StatementTimeout int64 `json:"statement_timeout"`
+
+	DatabaseSize int64  `json:"database_size"`
+	Tablespace   string `json:"tablespace"`
+}
+
+// AvailableConnections 当前用户还能建立的连接数，同时受实例、数据库和用户的限制
+func (c *Capacity) AvailableConnections() int {
+	available := c.MaxConnections - c.ActiveConnections
+	if !c.Superuser {
+		available -= c.ReservedConnections
+	}
+	if c.DatabaseConnLimit >= 0 && c.DatabaseConnLimit < available {
+		available = c.DatabaseConnLimit
+	}
+	if c.RoleConnLimit >= 0 && c.RoleConnLimit < available {
+		available = c.RoleConnLimit
+	}
+	if available < 0 {
+		return 0
+	}
+	return available
+}
+
+// InspectCapacity 查询目标库的连接数、内存、WAL 和超时参数，以及当前连接数和数据库大小
+func InspectCapacity(ctx context.Context, runner *PSQLRunner) (*Capacity, error) {
+	names := make([]string, len(capacitySettings))
+	for i, name := range capacitySettings {
+		names[i] = QuoteLiteral(name)
+	}
+
+	query := fmt.Sprintf(`SELECT %[2]s || name || '|' || setting || '|' || COALESCE(unit, '')
+FROM pg_settings WHERE name IN (%[1]s);
+SELECT %[3]s || COUNT(*) FROM pg_stat_activity WHERE backend_type = 'client backend';
+SELECT %[4]s || rolconnlimit || '|' || rolsuper::text FROM pg_roles WHERE rolname = current_user;
+SELECT %[5]s || d.datconnlimit || '|' || pg_database_size(d.datname) || '|' || t.spcname
+FROM pg_database d JOIN pg_tablespace t ON t.oid = d.dattablespace
+WHERE d.datname = current_database();`,
+		strings.Join(names, ", "), QuoteLiteral(capacitySettingMarker), QuoteLiteral(capacityActiveMarker),
+		QuoteLiteral(capacityRoleMarker), QuoteLiteral(capacityDatabaseMarker))
+
+	output, err := runner.Run(ctx, query)
+	if err != nil {
+		return nil, utils.NewError(utils.ErrorTypePostgres, "PG_CAPACITY_QUERY_FAILED").
+			Message("查询目标库容量参数失败").
+			Details(err.Error()).
+			Cause(err).
+			Suggestion("运行 'ora2pg-admin 检查 连接' 确认目标库连接").
+			Build()
+	}
+	return parseCapacity(output)
+}
+
+// parseCapacity 解析容量查询的输出
+func parseCapacity(output string) (*Capacity, error) {
+	capacity := &Capacity{RoleConnLimit: -1, DatabaseConnLimit: -1, TempFileLimit: -1}
+	settings := 0
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, capacitySettingMarker):
+			fields := strings.Split(strings.TrimPrefix(line, capacitySettingMarker), "|")
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("解析目标库参数失败: %s", line)
+			}
+			if err := capacity.setSetting(fields[0], fields[1], fields[2]); err != nil {
+				return nil, err
+			}
+			settings++
+		case strings.HasPrefix(line, capacityActiveMarker):
+			active, err := strconv.Atoi(strings.TrimPrefix(line, capacityActiveMarker))
+			if err != nil {
+				return nil, fmt.Errorf("解析目标库连接数失败: %s", line)
+			}
+			capacity.ActiveConnections = active
+		case strings.HasPrefix(line, capacityRoleMarker):
+			limit, super, _ := strings.Cut(strings.TrimPrefix(line, capacityRoleMarker), "|")
+			value, err := strconv.Atoi(limit)
+			if err != nil {
+				return nil, fmt.Errorf("解析用户连接数限制失败: %s", line)
+			}
+			capacity.RoleConnLimit = value
+			capacity.Superuser = super == "true" || super == "t"
+		case strings.HasPrefix(line, capacityDatabaseMarker):
+			fields := strings.SplitN(strings.TrimPrefix(line, capacityDatabaseMarker), "|", 3)
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("解析数据库信息失败: %s", line)
+			}
+			limit, err := strconv.Atoi(fields[0])
+			size, sizeErr := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil || sizeErr != nil {
+				return nil, fmt.Errorf("解析数据库信息失败: %s", line)
+			}
+			capacity.DatabaseConnLimit = limit
+			capacity.DatabaseSize = size
+			capacity.Tablespace = fields[2]
+		}
+	}
+	if settings == 0 {
+		return nil, fmt.Errorf("未获取到目标库参数")
+	}
+	return capacity, nil
+}
+
+// setSetting 按参数名设置容量字段，内存类参数换算为字节
+func (c *Capacity) setSetting(name, setting, unit string) error {
+	value, err := strconv.ParseInt(strings.TrimSpace(setting), 10, 64)
+	if err != nil {
+		return fmt.Errorf("解析目标库参数 %s 失败: %s", name, setting)
+	}
+	bytes := value
+	if value > 0 {
+		multiplier, err := settingUnitBytes(unit)
+		if err != nil {
+			return fmt.Errorf("解析目标库参数 %s 失败: %v", name, err)
+		}
+		bytes = value * multiplier
+	}
+
+	switch name {
+	case "max_connections":
+		c.MaxConnections = int(value)
+	case "superuser_reserved_connections":
+		c.ReservedConnections = int(value)
+	case "shared_buffers":
+		c.SharedBuffers = bytes
+	case "work_mem":
+		c.WorkMem = bytes
+	case "maintenance_work_mem":
+		c.MaintenanceWorkMem = bytes
+	case "max_wal_size":
+		c.MaxWalSize = bytes
+	case "temp_file_limit":
+		c.TempFileLimit = bytes
+	case "statement_timeout":
+		c.StatementTimeout = value
+	}
+	return nil
+}
+
+// settingUnitBytes pg_settings 中 unit 对应的字节数，如 8kB、MB；没有单位时为1
+func settingUnitBytes(unit string) (int64, error) {
+	unit = strings.TrimSpace(unit)
+	if unit == "" || unit == "ms" || unit == "s" || unit == "min" {
+		return 1, nil
+	}
+	digits := strings.TrimRight(unit, "kMGTB")
+	factor := int64(1)
+	if digits != "" {
+		n, err := strconv.ParseInt(digits, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("未知的单位 %s", unit)
+		}
+		factor = n
+	}
+	switch strings.TrimPrefix(unit, digits) {
+	case "B":
+		return factor, nil
+	case "kB":
+		return factor << 10, nil
+	case "MB":
+		return factor << 20, nil
+	case "GB":
+		return factor << 30, nil
+	case "TB":
+		return factor << 40, nil
+	}
+	return 0, fmt.Errorf("未知的单位 %s", unit)
+}
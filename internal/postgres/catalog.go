@@ -0,0 +1,139 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// 目标库对象查询结果行的前缀
+const (
+	catalogColumnMarker = "COL|"
+	catalogObjectMarker = "OBJ|"
+)
+
+// 目标库对象类型，与 CREATE 语句中的对象类型一致
+const (
+	CatalogTable            = "TABLE"
+	CatalogView             = "VIEW"
+	CatalogMaterializedView = "MATERIALIZED VIEW"
+	CatalogSequence         = "SEQUENCE"
+	CatalogIndex            = "INDEX"
+	CatalogFunction         = "FUNCTION"
+	CatalogProcedure        = "PROCEDURE"
+	CatalogTrigger          = "TRIGGER"
+	CatalogType             = "TYPE"
+)
+
+// CatalogColumn 表的列
+type CatalogColumn struct {
+	Name string `json:"name"`
+	// Type format_type 格式的类型，如 character varying(100)、numeric(10,2)
+	Type string `json:"type"`
+}
+
+// Catalog 目标模式中已有的对象
+type Catalog struct {
+	Schema string `json:"schema"`
+	// Tables 表名到按列序排列的列
+	Tables map[string][]CatalogColumn `json:"tables"`
+	// Objects 表以外的对象类型到对象名集合，函数重载时同名只记一次
+	Objects map[string]map[string]bool `json:"objects"`
+}
+
+// HasTable 模式中是否有该表
+func (c *Catalog) HasTable(name string) bool {
+	_, ok := c.Tables[name]
+	return ok
+}
+
+// Has 模式中是否有该类型的对象
+func (c *Catalog) Has(kind, name string) bool {
+	if kind == CatalogTable {
+		return c.HasTable(name)
+	}
+	return c.Objects[kind][name]
+}
+
+// InspectCatalog 查询目标模式中的表（含列定义）、视图、序列、索引、函数、存储过程、触发器和类型
+//
+// 函数和存储过程按 prokind 区分，需要 PostgreSQL 11 及以上版本。
+func InspectCatalog(ctx context.Context, runner *PSQLRunner, schema string) (*Catalog, error) {
+	if schema == "" {
+		schema = "public"
+	}
+
+	query := fmt.Sprintf(`SELECT %[2]s || c.relname || '|' || a.attname || '|' || format_type(a.atttypid, a.atttypmod)
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+JOIN pg_attribute a ON a.attrelid = c.oid AND a.attnum > 0 AND NOT a.attisdropped
+WHERE n.nspname = %[1]s AND c.relkind IN ('r', 'p', 'f')
+ORDER BY c.relname, a.attnum;
+SELECT %[3]s || CASE c.relkind WHEN 'r' THEN 'TABLE' WHEN 'p' THEN 'TABLE' WHEN 'f' THEN 'TABLE'
+    WHEN 'v' THEN 'VIEW' WHEN 'm' THEN 'MATERIALIZED VIEW' WHEN 'S' THEN 'SEQUENCE' ELSE 'INDEX' END || '|' || c.relname
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = %[1]s AND c.relkind IN ('r', 'p', 'f', 'v', 'm', 'S', 'i', 'I')
+UNION ALL
+SELECT DISTINCT %[3]s || CASE p.prokind WHEN 'p' THEN 'PROCEDURE' ELSE 'FUNCTION' END || '|' || p.proname
+FROM pg_proc p
+JOIN pg_namespace n ON n.oid = p.pronamespace
+WHERE n.nspname = %[1]s AND p.prokind IN ('f', 'p')
+UNION ALL
+SELECT DISTINCT %[3]s || 'TRIGGER|' || t.tgname
+FROM pg_trigger t
+JOIN pg_class c ON c.oid = t.tgrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = %[1]s AND NOT t.tgisinternal
+UNION ALL
+SELECT %[3]s || 'TYPE|' || t.typname
+FROM pg_type t
+JOIN pg_namespace n ON n.oid = t.typnamespace
+LEFT JOIN pg_class c ON c.oid = t.typrelid
+WHERE n.nspname = %[1]s AND (t.typtype = 'e' OR (t.typtype = 'c' AND c.relkind = 'c'));`,
+		QuoteLiteral(schema), QuoteLiteral(catalogColumnMarker), QuoteLiteral(catalogObjectMarker))
+
+	output, err := runner.Run(ctx, query)
+	if err != nil {
+		return nil, utils.NewError(utils.ErrorTypePostgres, "PG_CATALOG_QUERY_FAILED").
+			Message(fmt.Sprintf("查询目标模式 %s 中的对象失败", schema)).
+			Details(err.Error()).
+			Cause(err).
+			Suggestion("运行 'ora2pg-admin 检查 连接' 确认目标库连接，目标库需要 PostgreSQL 11 及以上版本").
+			Build()
+	}
+	return parseCatalog(schema, output), nil
+}
+
+// parseCatalog 解析目标库对象查询的输出
+func parseCatalog(schema, output string) *Catalog {
+	catalog := &Catalog{Schema: schema, Tables: make(map[string][]CatalogColumn), Objects: make(map[string]map[string]bool)}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if fields, ok := strings.CutPrefix(line, catalogColumnMarker); ok {
+			parts := strings.SplitN(fields, "|", 3)
+			if len(parts) == 3 {
+				catalog.Tables[parts[0]] = append(catalog.Tables[parts[0]], CatalogColumn{Name: parts[1], Type: parts[2]})
+			}
+		} else if fields, ok := strings.CutPrefix(line, catalogObjectMarker); ok {
+			kind, name, found := strings.Cut(fields, "|")
+			if !found || name == "" {
+				continue
+			}
+			if kind == CatalogTable {
+				// 没有列的表不会出现在列查询中
+				if _, ok := catalog.Tables[name]; !ok {
+					catalog.Tables[name] = nil
+				}
+				continue
+			}
+			if catalog.Objects[kind] == nil {
+				catalog.Objects[kind] = make(map[string]bool)
+			}
+			catalog.Objects[kind][name] = true
+		}
+	}
+	return catalog
+}
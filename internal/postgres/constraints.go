@@ -0,0 +1,247 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// constraintFieldSeparator 查询结果中的字段分隔符，约束定义中不会出现
+const constraintFieldSeparator = "\x1f"
+
+// psqlDetailPattern 匹配psql错误输出中的 DETAIL 行
+var psqlDetailPattern = regexp.MustCompile(`(?m)^DETAIL:\s+(.*)$`)
+
+// ForeignKey 目标库中的外键定义
+type ForeignKey struct {
+	Table      string `json:"table"`
+	Name       string `json:"name"`
+	Definition string `json:"definition"` // pg_get_constraintdef 的输出
+}
+
+// DeferredConstraints 数据导入前删除的外键和禁用了用户触发器的表，用于导入后恢复
+type DeferredConstraints struct {
+	Schema        string       `json:"schema"`
+	ForeignKeys   []ForeignKey `json:"foreign_keys"`
+	TriggerTables []string     `json:"trigger_tables"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+// Empty 没有需要处理的约束和触发器
+func (d *DeferredConstraints) Empty() bool {
+	return len(d.ForeignKeys) == 0 && len(d.TriggerTables) == 0
+}
+
+// Merge 合并另一份记录（如上次未恢复的记录），按表和约束名去重
+func (d *DeferredConstraints) Merge(other *DeferredConstraints) {
+	seenKeys := make(map[string]bool, len(d.ForeignKeys))
+	for _, fk := range d.ForeignKeys {
+		seenKeys[fk.Table+"."+fk.Name] = true
+	}
+	for _, fk := range other.ForeignKeys {
+		if !seenKeys[fk.Table+"."+fk.Name] {
+			seenKeys[fk.Table+"."+fk.Name] = true
+			d.ForeignKeys = append(d.ForeignKeys, fk)
+		}
+	}
+
+	seenTables := make(map[string]bool, len(d.TriggerTables))
+	for _, table := range d.TriggerTables {
+		seenTables[table] = true
+	}
+	for _, table := range other.TriggerTables {
+		if !seenTables[table] {
+			seenTables[table] = true
+			d.TriggerTables = append(d.TriggerTables, table)
+		}
+	}
+}
+
+// ConstraintFailure 重建或验证失败的外键
+type ConstraintFailure struct {
+	ForeignKey
+	Reason string `json:"reason"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ConstraintRestoreResult 恢复约束的结果
+type ConstraintRestoreResult struct {
+	// Validated 重建并验证通过的外键
+	Validated []ForeignKey
+	// Violations 已重建但现有数据违反约束，约束保持 NOT VALID 状态（仍约束新数据）
+	Violations []ConstraintFailure
+	// Failed 无法重建的外键，如被引用的表不存在
+	Failed []ConstraintFailure
+	// TriggerTables 重新启用了用户触发器的表
+	TriggerTables []string
+	Duration      time.Duration
+}
+
+// HasProblems 是否存在验证失败或无法重建的外键
+func (r *ConstraintRestoreResult) HasProblems() bool {
+	return len(r.Violations) > 0 || len(r.Failed) > 0
+}
+
+// ConstraintManager 在目标库数据导入前后管理外键和触发器
+type ConstraintManager struct {
+	runner *PSQLRunner
+	schema string
+}
+
+// NewConstraintManager 创建约束管理器，schema 为目标模式
+func NewConstraintManager(runner *PSQLRunner, schema string) *ConstraintManager {
+	if schema == "" {
+		schema = "public"
+	}
+	return &ConstraintManager{runner: runner, schema: schema}
+}
+
+// Collect 查询目标模式中的外键和启用了用户触发器的表
+func (m *ConstraintManager) Collect(ctx context.Context) (*DeferredConstraints, error) {
+	deferred := &DeferredConstraints{Schema: m.schema, CreatedAt: time.Now()}
+
+	fkQuery := fmt.Sprintf(`SELECT c.relname || %[2]s || con.conname || %[2]s || pg_get_constraintdef(con.oid)
+FROM pg_constraint con
+JOIN pg_class c ON c.oid = con.conrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = %[1]s AND con.contype = 'f' AND con.conparentid = 0
+ORDER BY c.relname, con.conname;`, QuoteLiteral(m.schema), QuoteLiteral(constraintFieldSeparator))
+	output, err := m.runner.Run(ctx, fkQuery)
+	if err != nil {
+		return nil, constraintError("查询目标库外键失败", err)
+	}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), constraintFieldSeparator, 3)
+		if len(fields) != 3 {
+			continue
+		}
+		deferred.ForeignKeys = append(deferred.ForeignKeys, ForeignKey{Table: fields[0], Name: fields[1], Definition: fields[2]})
+	}
+
+	triggerQuery := fmt.Sprintf(`SELECT DISTINCT c.relname
+FROM pg_trigger t
+JOIN pg_class c ON c.oid = t.tgrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = %s AND NOT t.tgisinternal AND t.tgenabled <> 'D'
+ORDER BY c.relname;`, QuoteLiteral(m.schema))
+	output, err = m.runner.Run(ctx, triggerQuery)
+	if err != nil {
+		return nil, constraintError("查询目标库触发器失败", err)
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if table := strings.TrimSpace(line); table != "" {
+			deferred.TriggerTables = append(deferred.TriggerTables, table)
+		}
+	}
+	return deferred, nil
+}
+
+// Disable 在一个事务中删除外键并禁用用户触发器，任一语句失败时全部回滚
+func (m *ConstraintManager) Disable(ctx context.Context, deferred *DeferredConstraints) error {
+	if deferred.Empty() {
+		return nil
+	}
+
+	var script strings.Builder
+	script.WriteString("BEGIN;\n")
+	for _, fk := range deferred.ForeignKeys {
+		fmt.Fprintf(&script, "ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;\n", m.qualified(fk.Table), QuoteIdentifier(fk.Name))
+	}
+	for _, table := range deferred.TriggerTables {
+		fmt.Fprintf(&script, "ALTER TABLE %s DISABLE TRIGGER USER;\n", m.qualified(table))
+	}
+	script.WriteString("COMMIT;")
+
+	if _, err := m.runner.Run(ctx, script.String()); err != nil {
+		return constraintError("禁用目标库约束失败", err)
+	}
+	return nil
+}
+
+// Restore 重新启用触发器，逐个重建外键并验证现有数据
+//
+// 外键先以 NOT VALID 方式重建，再单独 VALIDATE；数据违反约束时约束保持 NOT VALID 并记录违规详情，
+// 不影响其他外键的恢复。psql缺失或连接失败时返回错误。
+func (m *ConstraintManager) Restore(ctx context.Context, deferred *DeferredConstraints) (*ConstraintRestoreResult, error) {
+	startTime := time.Now()
+	result := &ConstraintRestoreResult{}
+	defer func() { result.Duration = time.Since(startTime) }()
+
+	if len(deferred.TriggerTables) > 0 {
+		var script strings.Builder
+		for _, table := range deferred.TriggerTables {
+			fmt.Fprintf(&script, "ALTER TABLE %s ENABLE TRIGGER USER;\n", m.qualified(table))
+		}
+		if _, err := m.runner.Run(ctx, script.String()); err != nil {
+			return result, constraintError("重新启用目标库触发器失败", err)
+		}
+		result.TriggerTables = append(result.TriggerTables, deferred.TriggerTables...)
+	}
+
+	for _, fk := range deferred.ForeignKeys {
+		table, name := m.qualified(fk.Table), QuoteIdentifier(fk.Name)
+
+		// 上次恢复中断时约束可能已经存在，先删除再重建
+		add := fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;\nALTER TABLE %s ADD CONSTRAINT %s %s NOT VALID;",
+			table, name, table, name, fk.Definition)
+		if _, err := m.runner.Run(ctx, add); err != nil {
+			if !isSQLError(err) {
+				return result, constraintError("重建目标库外键失败", err)
+			}
+			result.Failed = append(result.Failed, constraintFailure(fk, err))
+			continue
+		}
+
+		validate := fmt.Sprintf("ALTER TABLE %s VALIDATE CONSTRAINT %s;", table, name)
+		if _, err := m.runner.Run(ctx, validate); err != nil {
+			if !isSQLError(err) {
+				return result, constraintError("验证目标库外键失败", err)
+			}
+			result.Violations = append(result.Violations, constraintFailure(fk, err))
+			continue
+		}
+		result.Validated = append(result.Validated, fk)
+	}
+	return result, nil
+}
+
+// qualified 带模式名的表名
+func (m *ConstraintManager) qualified(table string) string {
+	return QuoteIdentifier(m.schema) + "." + QuoteIdentifier(table)
+}
+
+// isSQLError 是否为SQL语句本身的错误，而不是psql缺失、连接失败或被中断
+func isSQLError(err error) bool {
+	var psqlErr *PSQLError
+	return errors.As(err, &psqlErr) && psqlErr.Code != "" && !strings.HasPrefix(psqlErr.Code, "08")
+}
+
+// constraintFailure 根据psql错误构建失败记录
+func constraintFailure(fk ForeignKey, err error) ConstraintFailure {
+	failure := ConstraintFailure{ForeignKey: fk, Reason: err.Error()}
+	var psqlErr *PSQLError
+	if errors.As(err, &psqlErr) {
+		if matches := psqlDetailPattern.FindStringSubmatch(psqlErr.Output); matches != nil {
+			failure.Detail = strings.TrimSpace(matches[1])
+		}
+	}
+	return failure
+}
+
+// constraintError 把psql错误转换为带建议的错误
+func constraintError(message string, err error) error {
+	builder := utils.NewError(utils.ErrorTypePostgres, "PG_CONSTRAINT_FAILED").
+		Message(message).
+		Details(err.Error()).
+		Cause(err)
+	var psqlErr *PSQLError
+	if errors.As(err, &psqlErr) && psqlErr.IsPermissionDenied() {
+		builder = builder.Suggestion("删除外键和禁用触发器需要表的所有者权限")
+	}
+	return builder.Suggestion("运行 'ora2pg-admin 检查 连接' 确认目标库连接").Build()
+}
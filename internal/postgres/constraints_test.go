@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// installFakeConstraintPSQL 模拟psql：emp 的外键有违规数据，bonus 引用的表不存在，脚本记录到 log 文件
+func installFakeConstraintPSQL(t *testing.T) string {
+	t.Helper()
+	bin := t.TempDir()
+	logPath := filepath.Join(bin, "psql.log")
+	script := `#!/bin/sh
+input=$(cat)
+echo "$input" >> ` + logPath + `
+case "$input" in
+  *pg_constraint*) printf 'emp\037fk_emp_dept\037FOREIGN KEY (deptno) REFERENCES dept(deptno)\nbonus\037fk_bonus_emp\037FOREIGN KEY (empno) REFERENCES missing(empno)\n' ;;
+  *pg_trigger*) echo 'emp' ;;
+  *'REFERENCES missing'*) echo 'psql:<stdin>:3: ERROR:  42P01: relation "missing" does not exist'; exit 3 ;;
+  *'VALIDATE CONSTRAINT "fk_emp_dept"'*)
+    echo 'psql:<stdin>:2: ERROR:  23503: insert or update on table "emp" violates foreign key constraint "fk_emp_dept"'
+    echo 'DETAIL:  Key (deptno)=(99) is not present in table "dept".'
+    exit 3 ;;
+esac
+`
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "psql"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return logPath
+}
+
+func TestConstraintManagerWithFakePSQL(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟psql依赖 /bin/sh")
+	}
+	logPath := installFakeConstraintPSQL(t)
+
+	manager := NewConstraintManager(NewPSQLRunner(&config.PostgreConfig{
+		Host:     "localhost",
+		Port:     5432,
+		Database: "app",
+		Username: "app",
+	}), "hr")
+
+	deferred, err := manager.Collect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "hr", deferred.Schema)
+	require.Len(t, deferred.ForeignKeys, 2)
+	assert.Equal(t, ForeignKey{Table: "emp", Name: "fk_emp_dept", Definition: "FOREIGN KEY (deptno) REFERENCES dept(deptno)"}, deferred.ForeignKeys[0])
+	assert.Equal(t, []string{"emp"}, deferred.TriggerTables)
+
+	require.NoError(t, manager.Disable(context.Background(), deferred))
+	log, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(log), `BEGIN;
+ALTER TABLE "hr"."emp" DROP CONSTRAINT IF EXISTS "fk_emp_dept";
+ALTER TABLE "hr"."bonus" DROP CONSTRAINT IF EXISTS "fk_bonus_emp";
+ALTER TABLE "hr"."emp" DISABLE TRIGGER USER;
+COMMIT;`)
+
+	result, err := manager.Restore(context.Background(), deferred)
+	require.NoError(t, err)
+	assert.True(t, result.HasProblems())
+	assert.Equal(t, []string{"emp"}, result.TriggerTables)
+	assert.Empty(t, result.Validated)
+	require.Len(t, result.Violations, 1)
+	assert.Equal(t, "fk_emp_dept", result.Violations[0].Name)
+	assert.Contains(t, result.Violations[0].Reason, "23503")
+	assert.Equal(t, `Key (deptno)=(99) is not present in table "dept".`, result.Violations[0].Detail)
+	require.Len(t, result.Failed, 1)
+	assert.Equal(t, "fk_bonus_emp", result.Failed[0].Name)
+	assert.Contains(t, result.Failed[0].Reason, "42P01")
+
+	log, err = os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(log), `ALTER TABLE "hr"."emp" ADD CONSTRAINT "fk_emp_dept" FOREIGN KEY (deptno) REFERENCES dept(deptno) NOT VALID;`)
+	assert.Contains(t, string(log), `ALTER TABLE "hr"."emp" ENABLE TRIGGER USER;`)
+}
+
+func TestConstraintManagerPSQLMissing(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	manager := NewConstraintManager(NewPSQLRunner(&config.PostgreConfig{Host: "localhost", Port: 5432}), "")
+	_, err := manager.Collect(context.Background())
+	assert.Equal(t, "PG_CONSTRAINT_FAILED", utils.GetErrorCode(err))
+
+	// psql缺失时不会把外键误记为无法重建
+	result, err := manager.Restore(context.Background(), &DeferredConstraints{
+		ForeignKeys: []ForeignKey{{Table: "emp", Name: "fk", Definition: "FOREIGN KEY (a) REFERENCES b(a)"}},
+	})
+	require.Error(t, err)
+	assert.Empty(t, result.Failed)
+}
+
+func TestDeferredConstraintsMerge(t *testing.T) {
+	current := &DeferredConstraints{
+		ForeignKeys:   []ForeignKey{{Table: "emp", Name: "fk_a"}},
+		TriggerTables: []string{"emp"},
+	}
+	current.Merge(&DeferredConstraints{
+		ForeignKeys:   []ForeignKey{{Table: "emp", Name: "fk_a"}, {Table: "dept", Name: "fk_b"}},
+		TriggerTables: []string{"emp", "dept"},
+	})
+	assert.Len(t, current.ForeignKeys, 2)
+	assert.Equal(t, []string{"emp", "dept"}, current.TriggerTables)
+	assert.False(t, current.Empty())
+	assert.True(t, (&DeferredConstraints{}).Empty())
+}
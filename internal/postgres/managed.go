@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// 权限查询结果行的前缀
+const (
+	privilegeRoleMarker      = "PRIV|"
+	privilegeAdminMarker     = "ADMIN|"
+	privilegeExtensionMarker = "EXT|"
+)
+
+// ManagedProvider 常见托管PostgreSQL服务及其授予用户的管理角色
+type ManagedProvider struct {
+	Name string
+	// AdminRole 托管服务代替超级用户的管理角色
+	AdminRole string
+	// ExtensionSetting 托管服务允许安装的扩展列表参数，为空表示没有该参数
+	ExtensionSetting string
+}
+
+// ManagedProviders 按管理角色识别的托管PostgreSQL服务
+var ManagedProviders = []ManagedProvider{
+	{Name: "AWS RDS/Aurora", AdminRole: "rds_superuser", ExtensionSetting: "rds.extensions"},
+	{Name: "Google Cloud SQL", AdminRole: "cloudsqlsuperuser"},
+	{Name: "Azure Database for PostgreSQL", AdminRole: "azure_pg_admin", ExtensionSetting: "azure.extensions"},
+}
+
+// TargetPrivileges 迁移账号在目标库中的权限，用于识别托管数据库的限制
+type TargetPrivileges struct {
+	Superuser  bool `json:"superuser"`
+	CreateRole bool `json:"create_role"`
+	// CreateSchema 是否可以在当前数据库中创建Schema
+	CreateSchema bool `json:"create_schema"`
+	// Provider 根据管理角色识别出的托管服务，未识别时为空
+	Provider string `json:"provider,omitempty"`
+	// AdminRole 托管服务的管理角色，AdminMember 为当前用户是否是其成员
+	AdminRole   string `json:"admin_role,omitempty"`
+	AdminMember bool   `json:"admin_member"`
+	// AllowedExtensions 托管服务允许安装的扩展，无法获取时为空
+	AllowedExtensions []string `json:"allowed_extensions,omitempty"`
+}
+
+// Managed 目标库是否为识别出的托管服务
+func (p *TargetPrivileges) Managed() bool {
+	return p.Provider != ""
+}
+
+// Restrictions 没有超级用户权限时受限的迁移操作
+func (p *TargetPrivileges) Restrictions() []string {
+	if p.Superuser {
+		return nil
+	}
+	restrictions := []string{
+		"不能禁用外键等系统触发器（DISABLE_TRIGGERS=ALL），只能禁用用户触发器",
+	}
+	if !p.CreateRole {
+		restrictions = append(restrictions, "没有 CREATEROLE 权限，GRANT 类型中的创建角色和授权语句会失败")
+	}
+	if !p.CreateSchema {
+		restrictions = append(restrictions, "没有当前数据库的 CREATE 权限，无法创建Schema")
+	}
+	if p.AdminRole != "" && !p.AdminMember {
+		restrictions = append(restrictions, fmt.Sprintf("不是 %s 的成员，无法安装扩展、修改其他用户的对象", p.AdminRole))
+	}
+	if len(p.AllowedExtensions) > 0 {
+		restrictions = append(restrictions, "只能安装托管服务允许的扩展: "+strings.Join(p.AllowedExtensions, ", "))
+	}
+	return restrictions
+}
+
+// InspectPrivileges 查询迁移账号的权限和托管服务的管理角色
+func InspectPrivileges(ctx context.Context, runner *PSQLRunner) (*TargetPrivileges, error) {
+	roles := make([]string, 0, len(ManagedProviders))
+	var settings []string
+	for _, provider := range ManagedProviders {
+		roles = append(roles, QuoteLiteral(provider.AdminRole))
+		if provider.ExtensionSetting != "" {
+			settings = append(settings, QuoteLiteral(provider.ExtensionSetting))
+		}
+	}
+
+	query := fmt.Sprintf(`SELECT %[1]s || rolsuper::text || '|' || rolcreaterole::text || '|' ||
+  has_database_privilege(current_database(), 'CREATE')::text FROM pg_roles WHERE rolname = current_user;
+SELECT %[2]s || r.rolname || '|' || pg_has_role(current_user, r.oid, 'MEMBER')::text
+FROM pg_roles r WHERE r.rolname IN (%[3]s);
+SELECT %[4]s || setting FROM pg_settings WHERE name IN (%[5]s);`,
+		QuoteLiteral(privilegeRoleMarker), QuoteLiteral(privilegeAdminMarker), strings.Join(roles, ", "),
+		QuoteLiteral(privilegeExtensionMarker), strings.Join(settings, ", "))
+
+	output, err := runner.Run(ctx, query)
+	if err != nil {
+		return nil, utils.NewError(utils.ErrorTypePostgres, "PG_PRIVILEGE_QUERY_FAILED").
+			Message("查询目标库账号权限失败").
+			Details(err.Error()).
+			Cause(err).
+			Suggestion("运行 'ora2pg-admin 检查 连接' 确认目标库连接").
+			Build()
+	}
+	return parseTargetPrivileges(output)
+}
+
+// parseTargetPrivileges 解析权限查询的输出
+func parseTargetPrivileges(output string) (*TargetPrivileges, error) {
+	privileges := &TargetPrivileges{}
+	found := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, privilegeRoleMarker):
+			fields := strings.Split(strings.TrimPrefix(line, privilegeRoleMarker), "|")
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("解析账号权限失败: %s", line)
+			}
+			privileges.Superuser = isTrue(fields[0])
+			privileges.CreateRole = isTrue(fields[1])
+			privileges.CreateSchema = isTrue(fields[2])
+			found = true
+		case strings.HasPrefix(line, privilegeAdminMarker):
+			role, member, ok := strings.Cut(strings.TrimPrefix(line, privilegeAdminMarker), "|")
+			if !ok {
+				return nil, fmt.Errorf("解析管理角色失败: %s", line)
+			}
+			// 多个管理角色同时存在时，优先记录当前用户所属的角色
+			if privileges.AdminRole != "" && (privileges.AdminMember || !isTrue(member)) {
+				continue
+			}
+			for _, provider := range ManagedProviders {
+				if provider.AdminRole == role {
+					privileges.Provider = provider.Name
+					privileges.AdminRole = role
+					privileges.AdminMember = isTrue(member)
+				}
+			}
+		case strings.HasPrefix(line, privilegeExtensionMarker):
+			for _, name := range strings.Split(strings.TrimPrefix(line, privilegeExtensionMarker), ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					privileges.AllowedExtensions = append(privileges.AllowedExtensions, name)
+				}
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("未获取到账号权限")
+	}
+	return privileges, nil
+}
+
+// isTrue psql 输出的布尔值是否为真
+func isTrue(value string) bool {
+	value = strings.TrimSpace(value)
+	return value == "true" || value == "t"
+}
@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTargetPrivileges(t *testing.T) {
+	privileges, err := parseTargetPrivileges("PRIV|false|true|true\nADMIN|rds_superuser|false\nEXT|pg_trgm, postgis,uuid-ossp\n")
+	require.NoError(t, err)
+	assert.Equal(t, &TargetPrivileges{
+		CreateRole:        true,
+		CreateSchema:      true,
+		Provider:          "AWS RDS/Aurora",
+		AdminRole:         "rds_superuser",
+		AllowedExtensions: []string{"pg_trgm", "postgis", "uuid-ossp"},
+	}, privileges)
+	assert.True(t, privileges.Managed())
+	restrictions := privileges.Restrictions()
+	assert.Len(t, restrictions, 3)
+	assert.Contains(t, restrictions[1], "不是 rds_superuser 的成员")
+
+	// 多个管理角色存在时记录当前用户所属的角色
+	privileges, err = parseTargetPrivileges("PRIV|f|f|f\nADMIN|azure_pg_admin|false\nADMIN|cloudsqlsuperuser|true\n")
+	require.NoError(t, err)
+	assert.Equal(t, "Google Cloud SQL", privileges.Provider)
+	assert.True(t, privileges.AdminMember)
+	assert.Len(t, privileges.Restrictions(), 3)
+
+	// 自建库的超级用户没有限制
+	privileges, err = parseTargetPrivileges("PRIV|true|true|true\n")
+	require.NoError(t, err)
+	assert.False(t, privileges.Managed())
+	assert.Empty(t, privileges.Restrictions())
+
+	_, err = parseTargetPrivileges("")
+	assert.Error(t, err)
+	_, err = parseTargetPrivileges("PRIV|true\n")
+	assert.Error(t, err)
+}
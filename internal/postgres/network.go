@@ -0,0 +1,20 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+)
+
+// bandwidthRowSize 带宽测试每行返回的字节数
+const bandwidthRowSize = 1 << 20
+
+// TransferTest 在目标库执行返回约 size 字节数据的查询，size 为0时只执行最简单的查询，用于测量带宽
+func (r *PSQLRunner) TransferTest(ctx context.Context, size int64) error {
+	script := "SELECT 1;"
+	if size > 0 {
+		rows := (size + bandwidthRowSize - 1) / bandwidthRowSize
+		script = fmt.Sprintf("SELECT repeat('x', %d) FROM generate_series(1, %d);", bandwidthRowSize, rows)
+	}
+	_, err := r.Run(ctx, script)
+	return err
+}
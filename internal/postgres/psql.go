@@ -0,0 +1,170 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// psqlErrorPattern 匹配 VERBOSITY verbose 下psql输出的错误，如 "ERROR:  42501: permission denied"
+var psqlErrorPattern = regexp.MustCompile(`(?m)^(?:psql:[^\n]*?)?(?:ERROR|FATAL):\s+(?:([0-9A-Z]{5}):\s+)?(.*)$`)
+
+// PSQLError psql执行返回的PostgreSQL错误
+type PSQLError struct {
+	// Code SQLSTATE错误码，连接失败等情况下可能为空
+	Code    string
+	Message string
+	Output  string
+}
+
+// Error 实现error接口
+func (e *PSQLError) Error() string {
+	if e.Code == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// IsPermissionDenied 是否为权限不足（SQLSTATE 42501）
+func (e *PSQLError) IsPermissionDenied() bool {
+	return e.Code == "42501"
+}
+
+// PSQLRunner 通过psql在目标库执行SQL脚本
+type PSQLRunner struct {
+	pgConfig *config.PostgreConfig
+}
+
+// NewPSQLRunner 创建psql执行器
+func NewPSQLRunner(pgConfig *config.PostgreConfig) *PSQLRunner {
+	return &PSQLRunner{pgConfig: pgConfig}
+}
+
+// Run 执行SQL脚本并返回输出（无表头、不对齐）
+//
+// 密码通过 PGPASSWORD 环境变量传递，避免出现在进程参数中；脚本中任一SQL出错即退出。
+func (r *PSQLRunner) Run(ctx context.Context, script string) (string, error) {
+	cmd, err := r.command(ctx, script, true)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := cmd.CombinedOutput()
+	outputStr := string(output)
+	if ctx.Err() != nil {
+		return outputStr, fmt.Errorf("psql执行被中断: %v", ctx.Err())
+	}
+	if matches := psqlErrorPattern.FindStringSubmatch(outputStr); matches != nil {
+		return outputStr, &PSQLError{
+			Code:    matches[1],
+			Message: strings.TrimSpace(matches[2]),
+			Output:  outputStr,
+		}
+	}
+	if err != nil {
+		return outputStr, fmt.Errorf("psql执行失败: %v", err)
+	}
+	return outputStr, nil
+}
+
+// RunAll 执行SQL脚本，出错后继续执行后续语句，返回包含全部错误信息的输出
+//
+// SQL错误只出现在输出中，返回的错误表示psql无法执行（未安装、无法连接等）。
+func (r *PSQLRunner) RunAll(ctx context.Context, script string) (string, error) {
+	cmd, err := r.command(ctx, script, false)
+	if err != nil {
+		return "", err
+	}
+	output, err := cmd.CombinedOutput()
+	outputStr := string(output)
+	if ctx.Err() != nil {
+		return outputStr, fmt.Errorf("psql执行被中断: %v", ctx.Err())
+	}
+	if err != nil {
+		if matches := psqlErrorPattern.FindStringSubmatch(outputStr); matches != nil {
+			return outputStr, &PSQLError{Code: matches[1], Message: strings.TrimSpace(matches[2]), Output: outputStr}
+		}
+		return outputStr, fmt.Errorf("psql执行失败: %v", err)
+	}
+	return outputStr, nil
+}
+
+// ScriptLineOffset 执行时在脚本前插入的行数，psql报告的行号减去该值为脚本中的行号
+const ScriptLineOffset = 1
+
+// command 构建从标准输入读取脚本的psql命令，onErrorStop 为 true 时任一SQL出错即退出
+func (r *PSQLRunner) command(ctx context.Context, script string, onErrorStop bool) (*exec.Cmd, error) {
+	psqlPath, err := exec.LookPath("psql")
+	if err != nil {
+		return nil, utils.NewError(utils.ErrorTypePostgres, "PSQL_NOT_FOUND").
+			Message("未找到psql工具").
+			Cause(err).
+			Suggestion("请安装PostgreSQL客户端并确认psql在PATH中").
+			Build()
+	}
+
+	onErrorStopValue := "ON_ERROR_STOP=1"
+	if !onErrorStop {
+		onErrorStopValue = "ON_ERROR_STOP=0"
+	}
+	args := []string{
+		"-X", "-q", "-A", "-t",
+		"-v", onErrorStopValue,
+		"-h", r.pgConfig.Host,
+		"-p", strconv.Itoa(r.pgConfig.Port),
+		"-U", r.pgConfig.Username,
+		"-d", r.pgConfig.Database,
+		"-f", "-",
+	}
+	cmd := exec.CommandContext(ctx, psqlPath, args...)
+	cmd.Env = append(os.Environ(),
+		"PGPASSWORD="+r.pgConfig.Password,
+		"PGAPPNAME="+config.ApplicationName(),
+	)
+	cmd.Stdin = strings.NewReader("\\set VERBOSITY verbose\n" + script + "\n")
+	return cmd, nil
+}
+
+// PSQLVersion 执行 psql --version，返回版本行，如 "psql (PostgreSQL) 16.2"
+func PSQLVersion(ctx context.Context) (string, error) {
+	psqlPath, err := exec.LookPath("psql")
+	if err != nil {
+		return "", utils.NewError(utils.ErrorTypePostgres, "PSQL_NOT_FOUND").
+			Message("未找到psql工具").
+			Cause(err).
+			Suggestion("请安装PostgreSQL客户端并确认psql在PATH中").
+			Build()
+	}
+	output, err := exec.CommandContext(ctx, psqlPath, "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("执行psql --version失败: %v", err)
+	}
+	return strings.TrimSpace(strings.SplitN(string(output), "\n", 2)[0]), nil
+}
+
+// RunFile 执行SQL脚本文件，错误信息中的行号对应脚本文件
+func (r *PSQLRunner) RunFile(ctx context.Context, path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+	return r.Run(ctx, `\i `+QuoteLiteral(absPath))
+}
+
+// QuoteIdentifier 转义PostgreSQL标识符
+func QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// QuoteLiteral 转义SQL字符串字面量
+func QuoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// 目标模式查询结果行的前缀
+const (
+	schemaStateMarker    = "SCHEMA|"
+	schemaRelationMarker = "REL|"
+)
+
+// SchemaState 目标模式的当前状态
+type SchemaState struct {
+	Schema string `json:"schema"`
+	Exists bool   `json:"exists"`
+	// CanCreate 模式存在时为模式的 CREATE 权限，不存在时为数据库的 CREATE 权限（用于创建模式）
+	CanCreate bool `json:"can_create"`
+	// Relations 模式中已有的表、视图和序列名
+	Relations []string `json:"relations,omitempty"`
+}
+
+// Empty 模式不存在或没有任何表、视图和序列
+func (s *SchemaState) Empty() bool {
+	return len(s.Relations) == 0
+}
+
+// InspectSchema 查询目标模式是否存在、当前用户能否在其中建表，以及已有的对象
+func InspectSchema(ctx context.Context, runner *PSQLRunner, schema string) (*SchemaState, error) {
+	if schema == "" {
+		schema = "public"
+	}
+
+	query := fmt.Sprintf(`SELECT %[2]s || CASE WHEN n.oid IS NULL THEN 'f' ELSE 't' END || '|'
+  || CASE WHEN n.oid IS NULL THEN has_database_privilege(current_database(), 'CREATE')
+          ELSE has_schema_privilege(n.oid, 'CREATE') END::text
+FROM (SELECT 1) dummy
+LEFT JOIN pg_namespace n ON n.nspname = %[1]s;
+SELECT %[3]s || c.relname
+FROM pg_class c
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = %[1]s AND c.relkind IN ('r', 'p', 'v', 'm', 'S', 'f')
+ORDER BY c.relname;`, QuoteLiteral(schema), QuoteLiteral(schemaStateMarker), QuoteLiteral(schemaRelationMarker))
+
+	output, err := runner.Run(ctx, query)
+	if err != nil {
+		return nil, utils.NewError(utils.ErrorTypePostgres, "PG_SCHEMA_QUERY_FAILED").
+			Message(fmt.Sprintf("查询目标模式 %s 的状态失败", schema)).
+			Details(err.Error()).
+			Cause(err).
+			Suggestion("运行 'ora2pg-admin 检查 连接' 确认目标库连接").
+			Build()
+	}
+	return parseSchemaState(schema, output)
+}
+
+// parseSchemaState 解析目标模式查询的输出
+func parseSchemaState(schema, output string) (*SchemaState, error) {
+	state := &SchemaState{Schema: schema}
+	found := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if fields, ok := strings.CutPrefix(line, schemaStateMarker); ok {
+			exists, canCreate, _ := strings.Cut(fields, "|")
+			state.Exists = exists == "t"
+			state.CanCreate = canCreate == "t" || canCreate == "true"
+			found = true
+		} else if name, ok := strings.CutPrefix(line, schemaRelationMarker); ok && name != "" {
+			state.Relations = append(state.Relations, name)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("未获取到目标模式 %s 的状态", schema)
+	}
+	return state, nil
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchemaState(t *testing.T) {
+	state, err := parseSchemaState("hr", "SCHEMA|t|true\nREL|emp\nREL|dept\n")
+	require.NoError(t, err)
+	assert.True(t, state.Exists)
+	assert.True(t, state.CanCreate)
+	assert.Equal(t, []string{"emp", "dept"}, state.Relations)
+	assert.False(t, state.Empty())
+
+	state, err = parseSchemaState("hr", "SCHEMA|f|false\n")
+	require.NoError(t, err)
+	assert.False(t, state.Exists)
+	assert.False(t, state.CanCreate)
+	assert.True(t, state.Empty())
+
+	_, err = parseSchemaState("hr", "")
+	assert.Error(t, err)
+}
+
+func TestParseCapacity(t *testing.T) {
+	capacity, err := parseCapacity(`SET|max_connections|100|
+SET|superuser_reserved_connections|3|
+SET|shared_buffers|16384|8kB
+SET|work_mem|4096|kB
+SET|maintenance_work_mem|65536|kB
+SET|max_wal_size|1024|MB
+SET|temp_file_limit|-1|kB
+SET|statement_timeout|30000|ms
+ACTIVE|12
+ROLE|20|false
+DB|-1|8421123|pg_default
+`)
+	require.NoError(t, err)
+	assert.Equal(t, 100, capacity.MaxConnections)
+	assert.Equal(t, int64(128<<20), capacity.SharedBuffers)
+	assert.Equal(t, int64(4<<20), capacity.WorkMem)
+	assert.Equal(t, int64(64<<20), capacity.MaintenanceWorkMem)
+	assert.Equal(t, int64(1<<30), capacity.MaxWalSize)
+	assert.Equal(t, int64(-1), capacity.TempFileLimit)
+	assert.Equal(t, int64(30000), capacity.StatementTimeout)
+	assert.Equal(t, int64(8421123), capacity.DatabaseSize)
+	assert.Equal(t, "pg_default", capacity.Tablespace)
+	assert.False(t, capacity.Superuser)
+	// 用户连接数限制小于实例剩余连接数
+	assert.Equal(t, 20, capacity.AvailableConnections())
+
+	capacity.RoleConnLimit = -1
+	assert.Equal(t, 85, capacity.AvailableConnections())
+	capacity.Superuser = true
+	assert.Equal(t, 88, capacity.AvailableConnections())
+
+	_, err = parseCapacity("ACTIVE|1\n")
+	assert.Error(t, err)
+	_, err = parseCapacity("SET|work_mem|4096|parsecs\n")
+	assert.Error(t, err)
+}
+
+func TestParseCatalog(t *testing.T) {
+	catalog := parseCatalog("hr", `COL|emp|id|integer
+COL|emp|name|character varying(100)
+COL|Dept|id|bigint
+OBJ|TABLE|emp
+OBJ|TABLE|Dept
+OBJ|TABLE|empty
+OBJ|INDEX|emp_pkey
+OBJ|FUNCTION|raise_salary
+OBJ|VIEW|emp_v
+`)
+	assert.Equal(t, "hr", catalog.Schema)
+	assert.Equal(t, []CatalogColumn{{Name: "id", Type: "integer"}, {Name: "name", Type: "character varying(100)"}}, catalog.Tables["emp"])
+	assert.True(t, catalog.HasTable("Dept"))
+	assert.False(t, catalog.HasTable("dept"))
+	// 没有列的表也记录
+	assert.True(t, catalog.Has(CatalogTable, "empty"))
+	assert.True(t, catalog.Has(CatalogIndex, "emp_pkey"))
+	assert.True(t, catalog.Has(CatalogFunction, "raise_salary"))
+	assert.False(t, catalog.Has(CatalogProcedure, "raise_salary"))
+	assert.False(t, catalog.Has(CatalogView, "missing"))
+}
@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// timeZoneMarker 时区查询结果行的前缀
+const timeZoneMarker = "TZ|"
+
+// TimeZoneSettings 目标库会话的时区和日期格式设置
+type TimeZoneSettings struct {
+	// TimeZone 会话时区（TimeZone 参数），如 Asia/Shanghai、UTC
+	TimeZone string `json:"time_zone"`
+	// OffsetSeconds 会话时区当前相对UTC的偏移秒数
+	OffsetSeconds int `json:"offset_seconds"`
+	// DateStyle 日期的输出格式和输入解析顺序，如 ISO, MDY
+	DateStyle string `json:"date_style"`
+}
+
+// ISODateStyle 日期输出格式是否为ISO
+func (s *TimeZoneSettings) ISODateStyle() bool {
+	style, _, _ := strings.Cut(s.DateStyle, ",")
+	return strings.EqualFold(strings.TrimSpace(style), "ISO")
+}
+
+// QueryTimeZoneSettings 查询目标库会话的时区和日期格式设置
+func QueryTimeZoneSettings(ctx context.Context, runner *PSQLRunner) (*TimeZoneSettings, error) {
+	query := fmt.Sprintf(`SELECT %s || current_setting('TimeZone') || '|' || EXTRACT(TIMEZONE FROM now())::int
+  || '|' || current_setting('DateStyle');`, QuoteLiteral(timeZoneMarker))
+
+	output, err := runner.Run(ctx, query)
+	if err != nil {
+		return nil, utils.NewError(utils.ErrorTypePostgres, "PG_TIMEZONE_QUERY_FAILED").
+			Message("查询PostgreSQL时区设置失败").
+			Details(err.Error()).
+			Cause(err).
+			Build()
+	}
+	return parseTimeZoneSettings(output)
+}
+
+// parseTimeZoneSettings 解析时区查询的输出
+func parseTimeZoneSettings(output string) (*TimeZoneSettings, error) {
+	for _, line := range strings.Split(output, "\n") {
+		fields, ok := strings.CutPrefix(strings.TrimSpace(line), timeZoneMarker)
+		if !ok {
+			continue
+		}
+		values := strings.SplitN(fields, "|", 3)
+		if len(values) < 3 {
+			break
+		}
+		offset, err := strconv.Atoi(strings.TrimSpace(values[1]))
+		if err != nil {
+			break
+		}
+		return &TimeZoneSettings{
+			TimeZone:      strings.TrimSpace(values[0]),
+			OffsetSeconds: offset,
+			DateStyle:     strings.TrimSpace(values[2]),
+		}, nil
+	}
+	return nil, fmt.Errorf("未获取到PostgreSQL时区设置")
+}
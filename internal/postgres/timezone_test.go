@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeZoneSettings(t *testing.T) {
+	settings, err := parseTimeZoneSettings("TZ|Asia/Shanghai|28800|ISO, MDY\n")
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Shanghai", settings.TimeZone)
+	assert.Equal(t, 28800, settings.OffsetSeconds)
+	assert.True(t, settings.ISODateStyle())
+
+	settings, err = parseTimeZoneSettings("TZ|America/New_York|-14400|SQL, DMY\n")
+	require.NoError(t, err)
+	assert.Equal(t, -14400, settings.OffsetSeconds)
+	assert.False(t, settings.ISODateStyle())
+
+	_, err = parseTimeZoneSettings("")
+	assert.Error(t, err)
+	_, err = parseTimeZoneSettings("TZ|UTC|x|ISO, MDY\n")
+	assert.Error(t, err)
+}
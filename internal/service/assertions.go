@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/postgres"
+	"ora2pg-admin/internal/utils"
+)
+
+// assertionMarker 断言查询结果行的前缀，用于从psql输出中找出断言的值
+const assertionMarker = "ASSERT|"
+
+// 断言的检查结果
+const (
+	AssertionPassed = "PASSED"
+	AssertionFailed = "FAILED"
+	// AssertionError 断言查询执行失败，如表不存在
+	AssertionError = "ERROR"
+)
+
+// AssertionResult 一条断言的检查结果
+type AssertionResult struct {
+	Name   string `json:"name"`
+	Query  string `json:"query"`
+	Expect string `json:"expect"`
+	// Value 断言的值，查询失败时为空
+	Value     *float64      `json:"value,omitempty"`
+	Status    string        `json:"status"`
+	OnFailure string        `json:"on_failure"`
+	Duration  time.Duration `json:"duration"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// Passed 断言是否满足
+func (r *AssertionResult) Passed() bool {
+	return r.Status == AssertionPassed
+}
+
+// Fatal 断言未满足且配置为使迁移失败
+func (r *AssertionResult) Fatal() bool {
+	return !r.Passed() && r.OnFailure == config.AssertionOnFailureFail
+}
+
+// AssertionRunner 在目标库执行迁移后断言
+type AssertionRunner struct {
+	runner       *postgres.PSQLRunner
+	schema       string
+	preserveCase bool
+}
+
+// NewAssertionRunner 创建断言执行器
+func NewAssertionRunner(cfg *config.ProjectConfig) *AssertionRunner {
+	schema := cfg.PostgreSQL.Schema
+	if schema == "" {
+		schema = "public"
+	}
+	return &AssertionRunner{
+		runner:       postgres.NewPSQLRunner(&cfg.PostgreSQL),
+		schema:       schema,
+		preserveCase: cfg.Migration.PreserveCase(),
+	}
+}
+
+// Run 依次执行断言，查询失败的断言记为 ERROR，不影响其他断言
+func (r *AssertionRunner) Run(ctx context.Context, assertions []config.AssertionConfig) []*AssertionResult {
+	results := make([]*AssertionResult, 0, len(assertions))
+	for _, assertion := range assertions {
+		results = append(results, r.check(ctx, assertion))
+	}
+	return results
+}
+
+// check 执行一条断言
+func (r *AssertionRunner) check(ctx context.Context, assertion config.AssertionConfig) *AssertionResult {
+	onFailure := assertion.OnFailure
+	if onFailure == "" {
+		onFailure = config.AssertionOnFailureWarn
+	}
+	result := &AssertionResult{
+		Name:      assertion.DisplayName(),
+		Query:     r.query(assertion),
+		Expect:    assertion.Expect,
+		Status:    AssertionError,
+		OnFailure: onFailure,
+	}
+	expectation, err := assertion.Expectation()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Expect = expectation.String()
+
+	start := time.Now()
+	output, err := r.runner.Run(ctx, fmt.Sprintf("SET search_path TO %s, public;\nSELECT %s || (%s)::text;",
+		postgres.QuoteIdentifier(r.schema), postgres.QuoteLiteral(assertionMarker), result.Query))
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	value, err := parseAssertionValue(output)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Value = &value
+	result.Status = AssertionFailed
+	if expectation.Matches(value) {
+		result.Status = AssertionPassed
+	}
+	return result
+}
+
+// query 断言的值对应的查询：自定义SQL，或统计表中满足条件的行数
+func (r *AssertionRunner) query(assertion config.AssertionConfig) string {
+	if sql := strings.TrimSpace(assertion.SQL); sql != "" {
+		return strings.TrimSpace(strings.TrimSuffix(sql, ";"))
+	}
+
+	query := "SELECT count(*) FROM " + r.table(assertion.Table)
+	condition := strings.TrimSpace(assertion.Condition)
+	switch {
+	case condition == "":
+	case assertion.Column != "":
+		column := postgres.TargetTableName(assertion.Column, r.preserveCase)
+		query += " WHERE " + postgres.QuoteIdentifier(column) + " " + condition
+	default:
+		query += " WHERE " + condition
+	}
+	return query
+}
+
+// table 按命名规则转换后的表名，不带模式名时使用配置的模式
+func (r *AssertionRunner) table(name string) string {
+	schema := r.schema
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		schema = postgres.TargetTableName(name[:idx], r.preserveCase)
+	}
+	return postgres.QuoteIdentifier(schema) + "." + postgres.QuoteIdentifier(postgres.TargetTableName(name, r.preserveCase))
+}
+
+// parseAssertionValue 从psql输出中解析断言的值
+func parseAssertionValue(output string) (float64, error) {
+	for _, line := range strings.Split(output, "\n") {
+		text, ok := strings.CutPrefix(strings.TrimSpace(line), assertionMarker)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return 0, fmt.Errorf("查询结果 %q 不是数值", text)
+		}
+		return value, nil
+	}
+	return 0, fmt.Errorf("查询结果为 NULL 或没有返回行")
+}
+
+// runAssertions 数据迁移后执行配置的断言，配置为 fail 的断言未满足时返回错误
+//
+// 本次没有迁移数据或只导出到文件时不执行。
+func (ms *MigrationService) runAssertions(ctx context.Context, migrationTypes []MigrationType) error {
+	assertions := ms.config.Migration.Assertions
+	if len(assertions) == 0 || ms.fileOnly || ctx.Err() != nil {
+		return nil
+	}
+	hasData := false
+	for _, migrationType := range migrationTypes {
+		hasData = hasData || isDataMigrationType(migrationType)
+	}
+	if !hasData {
+		return nil
+	}
+
+	ms.logger.Infof("执行迁移后断言，共 %d 条", len(assertions))
+	ms.assertionResults = NewAssertionRunner(ms.config).Run(ctx, assertions)
+	var fatal []string
+	for _, result := range ms.assertionResults {
+		switch {
+		case result.Passed():
+			ms.logger.Infof("断言 %s 通过", result.Name)
+		case result.Error != "":
+			ms.logger.Warnf("断言 %s 执行失败: %s", result.Name, result.Error)
+		default:
+			ms.logger.Warnf("断言 %s 未满足: 值为 %s，期望 %s", result.Name, FormatAssertionValue(*result.Value), result.Expect)
+		}
+		if result.Fatal() {
+			fatal = append(fatal, result.Name)
+		}
+	}
+	if len(fatal) == 0 {
+		return nil
+	}
+	return utils.NewError(utils.ErrorTypeMigration, "MIGRATION_ASSERTION_FAILED").
+		Message(fmt.Sprintf("%d 条迁移后断言未满足", len(fatal))).
+		Details(strings.Join(fatal, "; ")).
+		Suggestion("运行 'ora2pg-admin 校验 断言' 查看各断言的查询和值，修复数据后重新检查").
+		Suggestion("只希望警告时，把断言的 on_failure 改为 warn").
+		Build()
+}
+
+// FormatAssertionValue 断言值的文本形式，整数不带小数
+func FormatAssertionValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// AssertionResults 本次迁移的断言检查结果
+func (ms *MigrationService) AssertionResults() []*AssertionResult {
+	return ms.assertionResults
+}
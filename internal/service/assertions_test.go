@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+func TestAssertionQuery(t *testing.T) {
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("断言")
+	cfg := manager.GetConfig()
+	cfg.PostgreSQL.Schema = "sales"
+	runner := NewAssertionRunner(cfg)
+
+	// 表名按命名规则转为小写，不带模式名时使用配置的模式
+	assert.Equal(t, `SELECT count(*) FROM "sales"."orders"`, runner.query(config.AssertionConfig{Table: "ORDERS"}))
+	assert.Equal(t, `SELECT count(*) FROM "hr"."users" WHERE "email" IS NULL`,
+		runner.query(config.AssertionConfig{Table: "HR.USERS", Column: "EMAIL", Condition: " IS NULL "}))
+	assert.Equal(t, `SELECT count(*) FROM "sales"."orders" WHERE amount < 0`,
+		runner.query(config.AssertionConfig{Table: "orders", Condition: "amount < 0"}))
+	assert.Equal(t, "SELECT max(id) FROM orders", runner.query(config.AssertionConfig{SQL: " SELECT max(id) FROM orders; "}))
+}
+
+func TestRunAssertions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟psql依赖 /bin/sh")
+	}
+
+	// 模拟psql：按查询中的表名返回断言的值
+	bin := t.TempDir()
+	psql := `#!/bin/sh
+input=$(cat)
+case "$input" in
+  *missing*) echo 'psql:<stdin>:3: ERROR:  42P01: relation "sales.missing" does not exist'; exit 3 ;;
+  *email*) echo "ASSERT|3" ;;
+  *max*) echo "" ;;
+  *orders*) echo "ASSERT|42" ;;
+esac
+`
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "psql"), []byte(psql), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("断言")
+	cfg := manager.GetConfig()
+	cfg.Migration.Assertions = []config.AssertionConfig{
+		{Name: "订单表有数据", Table: "orders"},
+		{Table: "users", Column: "email", Condition: "IS NULL", Expect: "= 0"},
+		{Table: "missing", Expect: ">= 1"},
+		{SQL: "SELECT max(id) FROM orders", Expect: "< 10"},
+	}
+
+	results := NewAssertionRunner(cfg).Run(context.Background(), cfg.Migration.Assertions)
+	require.Len(t, results, 4)
+	assert.True(t, results[0].Passed())
+	assert.Equal(t, "> 0", results[0].Expect)
+	require.NotNil(t, results[0].Value)
+	assert.Equal(t, 42.0, *results[0].Value)
+
+	assert.Equal(t, "users.email IS NULL", results[1].Name)
+	assert.Equal(t, AssertionFailed, results[1].Status)
+	assert.Equal(t, 3.0, *results[1].Value)
+	assert.False(t, results[1].Fatal())
+
+	// 查询失败的断言不影响其他断言
+	assert.Equal(t, AssertionError, results[2].Status)
+	assert.Contains(t, results[2].Error, "does not exist")
+	assert.Nil(t, results[2].Value)
+	assert.Equal(t, AssertionError, results[3].Status)
+	assert.Contains(t, results[3].Error, "NULL")
+
+	// 默认只警告；配置为 fail 的断言未满足时迁移失败
+	ms := NewMigrationService(cfg)
+	require.NoError(t, ms.runAssertions(context.Background(), []MigrationType{MigrationTypeTable, MigrationTypeCopy}))
+	assert.Len(t, ms.AssertionResults(), 4)
+
+	cfg.Migration.Assertions[1].OnFailure = config.AssertionOnFailureFail
+	err := ms.runAssertions(context.Background(), []MigrationType{MigrationTypeCopy})
+	require.Error(t, err)
+	assert.Equal(t, "MIGRATION_ASSERTION_FAILED", utils.GetErrorCode(err))
+	assert.Contains(t, err.Error(), "users.email IS NULL")
+
+	// 没有迁移数据或只导出到文件时不检查
+	ms = NewMigrationService(cfg)
+	require.NoError(t, ms.runAssertions(context.Background(), []MigrationType{MigrationTypeTable}))
+	ms.SetFileOnly(true)
+	require.NoError(t, ms.runAssertions(context.Background(), []MigrationType{MigrationTypeCopy}))
+	assert.Empty(t, ms.AssertionResults())
+}
@@ -0,0 +1,513 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/postgres"
+	"ora2pg-admin/internal/utils"
+)
+
+// DefaultBatchPlanPath 默认分批计划文件路径（相对于项目根目录）
+var DefaultBatchPlanPath = filepath.Join(".ora2pg-admin", "batch_plan.json")
+
+// 分批迁移时批次和计划在迁移历史元数据中的键
+const (
+	BatchMetadataKey     = "batch"
+	BatchPlanMetadataKey = "batch_plan"
+)
+
+// 批次的类型
+const (
+	BatchKindTables = "tables" // 一组完整的表
+	BatchKindSlice  = "slice"  // 大表的一个范围
+)
+
+// batchIdentifierPattern 批次中的表名和列名，防止被篡改的计划文件把任意内容写入ora2pg配置
+var batchIdentifierPattern = regexp.MustCompile(`^[A-Za-z0-9_$#]+(\.[A-Za-z0-9_$#]+)?$`)
+
+// MigrationBatch 分批迁移中的一个批次
+type MigrationBatch struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Tables 批次导出的表，为空表示配置选中的全部表（排除 Exclude）
+	Tables []string `json:"tables,omitempty"`
+	// Exclude 需要排除的表，即按范围切片的大表
+	Exclude []string `json:"exclude,omitempty"`
+	// Rows 统计信息中的行数，切片批次为整张表的行数，未查询时为0
+	Rows int64 `json:"rows,omitempty"`
+
+	// Table、Column、Type 切片批次的表、切片列和列类型
+	Table  string `json:"table,omitempty"`
+	Column string `json:"column,omitempty"`
+	Type   string `json:"type,omitempty"`
+	// Lower 范围下界（含），为空表示不限，此时包含切片列为空的行
+	Lower string `json:"lower,omitempty"`
+	// Upper 范围上界（不含），为空表示不限
+	Upper string `json:"upper,omitempty"`
+}
+
+// BatchPlan 分批迁移的计划，生成后保存下来，统计信息变化不会改变已有的批次划分
+type BatchPlan struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	// ConfigHash 生成计划时分批规则和表筛选配置的指纹
+	ConfigHash string            `json:"config_hash"`
+	Batches    []*MigrationBatch `json:"batches"`
+}
+
+// BatchConfigFingerprint 影响批次划分的配置的指纹：分批规则和表筛选配置
+func BatchConfigFingerprint(migration *config.MigrationConfig) string {
+	data, err := json.Marshal(struct {
+		Batching        config.BatchingConfig `json:"batching"`
+		AllowTables     []string              `json:"allow_tables,omitempty"`
+		AllowPatterns   []string              `json:"allow_patterns,omitempty"`
+		ExcludePatterns []string              `json:"exclude_patterns,omitempty"`
+	}{migration.Batching, migration.AllowTables, migration.AllowPatterns, migration.ExcludePatterns})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// PlanBatches 按分批规则生成计划，需要按规模分桶时从源库统计信息查询各表行数
+func PlanBatches(ctx context.Context, cfg *config.ProjectConfig) (*BatchPlan, error) {
+	batching := &cfg.Migration.Batching
+	if !batching.Enabled() {
+		return nil, utils.NewError(utils.ErrorTypeConfig, "BATCHING_NOT_CONFIGURED").
+			Message("未配置分批规则").
+			Suggestion("在配置文件的 migration.batching 中设置 table_buckets 或 slices").
+			Build()
+	}
+
+	schema := cfg.Oracle.Schema
+	if schema == "" {
+		schema = cfg.Oracle.Username
+	}
+	var sizes []oracle.TableSize
+	if batching.TableBuckets > 1 {
+		runner := oracle.NewSQLPlusRunner(&cfg.Oracle, &cfg.OracleClient)
+		var err error
+		if sizes, err = oracle.NewInspector(runner, schema).TableSizes(ctx); err != nil {
+			return nil, utils.NewError(utils.ErrorTypeOracle, "BATCH_TABLE_SIZES_FAILED").
+				Message("按规模分桶时无法查询源库各表的行数").
+				Details(err.Error()).
+				Cause(err).
+				Suggestion("运行 'ora2pg-admin 检查 连接' 确认源库连接，或将 table_buckets 设为0不按规模分桶").
+				Build()
+		}
+	}
+	return BuildBatchPlan(&cfg.Migration, schema, sizes, time.Now())
+}
+
+// BuildBatchPlan 根据各表行数生成分批计划
+//
+// 未切片的表按行数从小到大排列，按累计行数均分为 table_buckets 个批次（没有统计信息时按表数量均分）；
+// 每张切片的表按切分点生成范围批次，排在最后。
+func BuildBatchPlan(migration *config.MigrationConfig, schema string, sizes []oracle.TableSize, now time.Time) (*BatchPlan, error) {
+	batching := &migration.Batching
+	plan := &BatchPlan{
+		ID:         now.Format("20060102-150405"),
+		CreatedAt:  now,
+		ConfigHash: BatchConfigFingerprint(migration),
+	}
+
+	sliced := make(map[string]bool, len(batching.Slices))
+	for _, table := range batching.SlicedTables() {
+		sliced[table] = true
+	}
+	rows := make(map[string]int64, len(sizes))
+	for _, size := range sizes {
+		rows[strings.ToUpper(size.Name)] = size.Rows
+	}
+
+	if batching.TableBuckets > 1 {
+		filter, err := migration.TableFilter()
+		if err != nil {
+			return nil, utils.NewError(utils.ErrorTypeConfig, "TABLE_PATTERN_INVALID").
+				Message("表筛选的正则表达式无效").
+				Details(err.Error()).
+				Cause(err).
+				Build()
+		}
+		names := make([]string, 0, len(sizes))
+		for _, size := range sizes {
+			names = append(names, size.Name)
+		}
+		var tables []oracle.TableSize
+		for _, name := range filter.Filter(schema, names).Selected {
+			if !sliced[name] {
+				tables = append(tables, oracle.TableSize{Name: name, Rows: rows[name]})
+			}
+		}
+		sort.SliceStable(tables, func(a, b int) bool {
+			if tables[a].Rows != tables[b].Rows {
+				return tables[a].Rows < tables[b].Rows
+			}
+			return tables[a].Name < tables[b].Name
+		})
+
+		groups := partitionTablesBySize(tables, batching.TableBuckets)
+		for i, group := range groups {
+			batch := &MigrationBatch{
+				ID:   fmt.Sprintf("tables-%d", i+1),
+				Kind: BatchKindTables,
+				Name: fmt.Sprintf("表批次 %d/%d（%d 张表）", i+1, len(groups), len(group)),
+			}
+			for _, table := range group {
+				batch.Tables = append(batch.Tables, table.Name)
+				batch.Rows += table.Rows
+			}
+			plan.Batches = append(plan.Batches, batch)
+		}
+	} else {
+		batch := &MigrationBatch{ID: "tables", Kind: BatchKindTables, Name: "其余表"}
+		for _, table := range migration.AllowTables {
+			if !sliced[strings.ToUpper(strings.TrimSpace(table))] {
+				batch.Tables = append(batch.Tables, table)
+			}
+		}
+		// 只迁移指定的表且都已切片时没有其余表
+		if len(migration.AllowTables) == 0 || len(batch.Tables) > 0 {
+			if len(migration.AllowTables) == 0 {
+				batch.Exclude = batching.SlicedTables()
+			}
+			plan.Batches = append(plan.Batches, batch)
+		}
+	}
+
+	for _, slice := range batching.Slices {
+		boundaries, err := slice.NormalizedBoundaries()
+		if err != nil {
+			return nil, utils.ConfigErrors.InvalidValue(fmt.Sprintf("migration.batching.slices(%s).boundaries", slice.Name), err.Error())
+		}
+		table := slice.TableName()
+		for i := 0; i <= len(boundaries); i++ {
+			batch := &MigrationBatch{
+				ID:     fmt.Sprintf("%s-%d", table, i+1),
+				Kind:   BatchKindSlice,
+				Name:   fmt.Sprintf("%s 切片 %d/%d", table, i+1, len(boundaries)+1),
+				Tables: []string{table},
+				Rows:   rows[table],
+				Table:  table,
+				Column: slice.ColumnName(),
+				Type:   slice.ColumnType(),
+			}
+			if i > 0 {
+				batch.Lower = boundaries[i-1]
+			}
+			if i < len(boundaries) {
+				batch.Upper = boundaries[i]
+			}
+			plan.Batches = append(plan.Batches, batch)
+		}
+	}
+	return plan, nil
+}
+
+// partitionTablesBySize 把按行数升序排列的表按顺序分成最多 buckets 组，各组累计行数尽量接近
+func partitionTablesBySize(tables []oracle.TableSize, buckets int) [][]oracle.TableSize {
+	if len(tables) == 0 {
+		return nil
+	}
+	if buckets > len(tables) {
+		buckets = len(tables)
+	}
+
+	var total int64
+	for _, table := range tables {
+		total += table.Rows
+	}
+	// 没有统计信息时按表数量均分
+	byCount := total == 0
+	if byCount {
+		total = int64(len(tables))
+	}
+	weight := func(table oracle.TableSize) int64 {
+		if byCount {
+			return 1
+		}
+		return table.Rows
+	}
+
+	groups := make([][]oracle.TableSize, 0, buckets)
+	var current []oracle.TableSize
+	var accumulated int64
+	for i, table := range tables {
+		current = append(current, table)
+		accumulated += weight(table)
+		remainingGroups := buckets - len(groups) - 1
+		if remainingGroups == 0 {
+			continue
+		}
+		// 累计行数达到下一个均分点，或剩余的表只够每组一张时结束当前组
+		if accumulated*int64(buckets) >= total*int64(len(groups)+1) || len(tables)-i-1 == remainingGroups {
+			groups = append(groups, current)
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+	return groups
+}
+
+// Validate 检查计划中的表名、列名和切分点，防止被篡改的计划文件把任意内容写入ora2pg配置
+func (p *BatchPlan) Validate() error {
+	invalid := func(batch *MigrationBatch, detail string) error {
+		return utils.NewError(utils.ErrorTypeConfig, "BATCH_PLAN_INVALID").
+			Message(fmt.Sprintf("分批计划中的批次 %s 无效", batch.ID)).
+			Details(detail).
+			Suggestion("指定 --replan 重新生成分批计划").
+			Build()
+	}
+	for _, batch := range p.Batches {
+		for _, table := range append(append([]string(nil), batch.Tables...), batch.Exclude...) {
+			if !batchIdentifierPattern.MatchString(table) {
+				return invalid(batch, fmt.Sprintf("无效的表名: %s", table))
+			}
+		}
+		if batch.Kind != BatchKindSlice {
+			continue
+		}
+		if !batchIdentifierPattern.MatchString(batch.Table) || !batchIdentifierPattern.MatchString(batch.Column) {
+			return invalid(batch, fmt.Sprintf("无效的切片表或列: %s.%s", batch.Table, batch.Column))
+		}
+		if batch.Lower == "" && batch.Upper == "" {
+			return invalid(batch, "切片没有范围")
+		}
+		for _, value := range []string{batch.Lower, batch.Upper} {
+			if value == "" {
+				continue
+			}
+			slice := config.TableSliceConfig{Type: batch.Type, Boundaries: []string{value}}
+			normalized, err := slice.NormalizedBoundaries()
+			if err != nil || normalized[0] != value {
+				return invalid(batch, fmt.Sprintf("无效的切分点: %s", value))
+			}
+		}
+	}
+	return nil
+}
+
+// LoadBatchPlan 加载分批计划，文件不存在时返回nil
+func LoadBatchPlan(path string) (*BatchPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, utils.FileErrors.ReadFailed(path, err)
+	}
+	plan := &BatchPlan{}
+	if err := json.Unmarshal(data, plan); err != nil {
+		return nil, utils.NewError(utils.ErrorTypeFile, "BATCH_PLAN_CORRUPTED").
+			Message("分批计划文件已损坏").
+			Details(path).
+			Cause(err).
+			Suggestion("指定 --replan 重新生成分批计划").
+			Build()
+	}
+	if err := plan.Validate(); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// SaveBatchPlan 保存分批计划
+func SaveBatchPlan(path string, plan *BatchPlan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化分批计划失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return utils.FileErrors.CreateFailed(filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return utils.FileErrors.WriteFailed(path, err)
+	}
+	return nil
+}
+
+// BatchCheckpointPath 分批迁移时的检查点文件路径，每个批次单独续传
+func BatchCheckpointPath(batchID string) string {
+	return filepath.Join(filepath.Dir(DefaultCheckpointPath), "batches", batchID+".json")
+}
+
+// SourceCondition 切片批次在源库导出时的 WHERE 条件，其他批次为空
+func (b *MigrationBatch) SourceCondition() string {
+	if b.Kind != BatchKindSlice {
+		return ""
+	}
+	return b.rangeCondition(b.Column)
+}
+
+// rangeCondition 切片范围的条件，column 为已按目标数据库规则处理的列名；时间字面量的写法Oracle和PostgreSQL通用
+func (b *MigrationBatch) rangeCondition(column string) string {
+	literal := func(value string) string {
+		if b.Type == config.SliceTimestamp {
+			return fmt.Sprintf("TO_TIMESTAMP('%s', 'YYYY-MM-DD HH24:MI:SS')", value)
+		}
+		return value
+	}
+	var parts []string
+	if b.Lower != "" {
+		parts = append(parts, fmt.Sprintf("%s >= %s", column, literal(b.Lower)))
+	}
+	if b.Upper != "" {
+		parts = append(parts, fmt.Sprintf("%s < %s", column, literal(b.Upper)))
+	}
+	condition := strings.Join(parts, " AND ")
+	if b.Lower == "" {
+		condition = fmt.Sprintf("(%s OR %s IS NULL)", condition, column)
+	}
+	return condition
+}
+
+// Describe 批次内容的简短描述
+func (b *MigrationBatch) Describe() string {
+	if b.Kind == BatchKindSlice {
+		return fmt.Sprintf("%s WHERE %s", b.Table, b.SourceCondition())
+	}
+	if len(b.Tables) == 0 {
+		if len(b.Exclude) == 0 {
+			return "配置选中的全部表"
+		}
+		return fmt.Sprintf("配置选中的全部表，排除 %s", strings.Join(b.Exclude, ", "))
+	}
+	return strings.Join(b.Tables, ", ")
+}
+
+// WriteBatchConfig 基于原始ora2pg配置生成只导出批次内容的配置
+//
+// 整表批次打开 TRUNCATE_TABLE，重新执行失败的批次时先清空这些表；切片批次关闭 TRUNCATE_TABLE，
+// 避免清空其他切片已导入的数据，重新执行前由 ClearSliceRange 删除该范围的数据。
+func WriteBatchConfig(baseConfigPath, batchConfigPath string, batch *MigrationBatch) error {
+	lines := []string{"TRUNCATE_TABLE 1"}
+	if batch.Kind == BatchKindSlice {
+		lines = []string{fmt.Sprintf("WHERE %s[%s]", batch.Table, batch.SourceCondition()), "TRUNCATE_TABLE 0"}
+	}
+	drop := map[string]bool{"WHERE": true, "TRUNCATE_TABLE": true}
+	return writeDerivedConfig(baseConfigPath, batchConfigPath, fmt.Sprintf("由 ora2pg-admin 分批迁移生成：%s", batch.Name),
+		batch.Exclude, drop, lines)
+}
+
+// SetBatch 只迁移计划中指定批次的数据，检查点按批次单独保存，运行记录带有批次标签
+//
+// 分批迁移不使用增量水位，批次中的表按完整范围导出。
+func (ms *MigrationService) SetBatch(plan *BatchPlan, batch *MigrationBatch) {
+	scoped := *ms.config
+	scoped.Migration.Incremental = config.IncrementalConfig{}
+	if len(batch.Tables) > 0 {
+		scoped.Migration.AllowTables = append([]string(nil), batch.Tables...)
+		scoped.Migration.AllowPatterns = nil
+		scoped.Migration.ExcludePatterns = nil
+	}
+	ms.config = &scoped
+	ms.batch = batch
+	ms.filteredTables = nil
+	ms.checkpointPath = BatchCheckpointPath(batch.ID)
+	if ms.state.Metadata == nil {
+		ms.state.Metadata = make(map[string]string)
+	}
+	ms.state.Metadata[BatchMetadataKey] = batch.ID
+	ms.state.Metadata[BatchPlanMetadataKey] = plan.ID
+}
+
+// ClearSliceRange 删除目标表中切片范围内已有的数据，使重新执行的切片不会重复导入
+func (ms *MigrationService) ClearSliceRange(ctx context.Context) (string, error) {
+	batch := ms.batch
+	if batch == nil || batch.Kind != BatchKindSlice {
+		return "", nil
+	}
+	schema := ms.config.PostgreSQL.Schema
+	if schema == "" {
+		schema = "public"
+	}
+	column := batch.Column
+	if !ms.config.Migration.PreserveCase() {
+		column = strings.ToLower(column)
+	}
+	table := postgres.QuoteIdentifier(schema) + "." + postgres.QuoteIdentifier(ms.config.Migration.TargetTableName(batch.Table))
+	statement := fmt.Sprintf("DELETE FROM %s WHERE %s;", table, batch.rangeCondition(postgres.QuoteIdentifier(column)))
+
+	if _, err := postgres.NewPSQLRunner(&ms.config.PostgreSQL).Run(ctx, statement); err != nil {
+		return statement, utils.NewError(utils.ErrorTypePostgres, "BATCH_CLEAR_FAILED").
+			Message(fmt.Sprintf("清理目标表中切片 %s 的数据失败", batch.Name)).
+			Details(err.Error()).
+			Cause(err).
+			Suggestion("确认目标表已通过 '迁移 结构' 创建，迁移账号有 DELETE 权限").
+			Build()
+	}
+	return statement, nil
+}
+
+// sliceResumed 续传时切片的表是否已经导入完成，此时不再清理也不再导出
+func (ms *MigrationService) sliceResumed(migrationType MigrationType) bool {
+	if !ms.resume {
+		return false
+	}
+	for _, table := range ms.getCompletedTables(migrationType) {
+		if strings.EqualFold(table, ms.batch.Table) {
+			return true
+		}
+	}
+	return false
+}
+
+// BatchProgress 批次的迁移进度，来自迁移历史中该计划下该批次的运行记录
+type BatchProgress struct {
+	Batch *MigrationBatch `json:"batch"`
+	// Runs 该批次的运行次数
+	Runs int `json:"runs"`
+	// Last 最近一次运行，从未运行时为空
+	Last *HistoryRecord `json:"last,omitempty"`
+	// LastCompleted 最近一次成功的运行
+	LastCompleted *HistoryRecord `json:"last_completed,omitempty"`
+}
+
+// Completed 批次是否已成功迁移过
+func (p *BatchProgress) Completed() bool {
+	return p.LastCompleted != nil
+}
+
+// LoadBatchProgress 汇总计划中各批次在迁移历史中的运行情况，按计划中的顺序排列
+func LoadBatchProgress(historyPath string, plan *BatchPlan) ([]*BatchProgress, error) {
+	records, err := LoadHistory(historyPath, map[string]string{BatchPlanMetadataKey: plan.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make(map[string]*BatchProgress, len(plan.Batches))
+	batches := make([]*BatchProgress, 0, len(plan.Batches))
+	for _, batch := range plan.Batches {
+		item := &BatchProgress{Batch: batch}
+		progress[batch.ID] = item
+		batches = append(batches, item)
+	}
+	for _, record := range records {
+		item, exists := progress[record.Metadata[BatchMetadataKey]]
+		if !exists {
+			continue
+		}
+		item.Runs++
+		item.Last = record
+		if record.Status == StatusCompleted {
+			item.LastCompleted = record
+		}
+	}
+	return batches, nil
+}
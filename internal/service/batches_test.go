@@ -0,0 +1,220 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/utils"
+)
+
+func TestBuildBatchPlanBuckets(t *testing.T) {
+	migration := &config.MigrationConfig{
+		ExcludePatterns: []string{"^TMP_"},
+		Batching: config.BatchingConfig{
+			TableBuckets: 3,
+			Slices:       []config.TableSliceConfig{{Name: "orders", Column: "id", Boundaries: []string{"1000", " 2000 "}}},
+		},
+	}
+	sizes := []oracle.TableSize{
+		{Name: "ORDERS", Rows: 5000}, {Name: "ITEMS", Rows: 100}, {Name: "USERS", Rows: 50},
+		{Name: "TMP_LOAD", Rows: 10}, {Name: "ROLES", Rows: 10}, {Name: "DEPTS", Rows: 10}, {Name: "REGIONS", Rows: 30},
+	}
+	now := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	plan, err := BuildBatchPlan(migration, "APP", sizes, now)
+	require.NoError(t, err)
+	assert.Equal(t, "20240301-080000", plan.ID)
+	assert.Equal(t, BatchConfigFingerprint(migration), plan.ConfigHash)
+	require.Len(t, plan.Batches, 6)
+
+	// 小表在前，按累计行数分组，切片的表和被排除的表不在分桶中
+	assert.Equal(t, []string{"DEPTS", "ROLES", "REGIONS"}, plan.Batches[0].Tables)
+	assert.Equal(t, int64(50), plan.Batches[0].Rows)
+	assert.Equal(t, "表批次 1/3（3 张表）", plan.Batches[0].Name)
+	assert.Equal(t, []string{"USERS"}, plan.Batches[1].Tables)
+	assert.Equal(t, []string{"ITEMS"}, plan.Batches[2].Tables)
+
+	slices := plan.Batches[3:]
+	assert.Equal(t, "ORDERS-1", slices[0].ID)
+	assert.Equal(t, "ORDERS 切片 1/3", slices[0].Name)
+	assert.Equal(t, int64(5000), slices[0].Rows)
+	assert.Equal(t, "(ID < 1000 OR ID IS NULL)", slices[0].SourceCondition())
+	assert.Equal(t, "ID >= 1000 AND ID < 2000", slices[1].SourceCondition())
+	assert.Equal(t, "ID >= 2000", slices[2].SourceCondition())
+	assert.Equal(t, []string{"ORDERS"}, slices[2].Tables)
+	assert.NoError(t, plan.Validate())
+
+	// 配置变化后指纹不同
+	migration.Batching.TableBuckets = 2
+	assert.NotEqual(t, plan.ConfigHash, BatchConfigFingerprint(migration))
+}
+
+func TestBuildBatchPlanSlicesOnly(t *testing.T) {
+	migration := &config.MigrationConfig{
+		Batching: config.BatchingConfig{
+			Slices: []config.TableSliceConfig{{Name: "LOGS", Column: "CREATED_AT", Type: "timestamp", Boundaries: []string{"2024-01-01"}}},
+		},
+	}
+	plan, err := BuildBatchPlan(migration, "APP", nil, time.Now())
+	require.NoError(t, err)
+	require.Len(t, plan.Batches, 3)
+
+	rest := plan.Batches[0]
+	assert.Equal(t, "tables", rest.ID)
+	assert.Empty(t, rest.Tables)
+	assert.Equal(t, []string{"LOGS"}, rest.Exclude)
+	assert.Equal(t, "配置选中的全部表，排除 LOGS", rest.Describe())
+	assert.Equal(t, "(CREATED_AT < TO_TIMESTAMP('2024-01-01 00:00:00', 'YYYY-MM-DD HH24:MI:SS') OR CREATED_AT IS NULL)",
+		plan.Batches[1].SourceCondition())
+	assert.Equal(t, "CREATED_AT >= TO_TIMESTAMP('2024-01-01 00:00:00', 'YYYY-MM-DD HH24:MI:SS')", plan.Batches[2].SourceCondition())
+
+	// 只迁移指定的表时，其余表批次去掉切片的表
+	migration.AllowTables = []string{"USERS", "logs"}
+	plan, err = BuildBatchPlan(migration, "APP", nil, time.Now())
+	require.NoError(t, err)
+	require.Len(t, plan.Batches, 3)
+	assert.Equal(t, []string{"USERS"}, plan.Batches[0].Tables)
+	assert.Empty(t, plan.Batches[0].Exclude)
+
+	// 指定的表都已切片时没有其余表批次
+	migration.AllowTables = []string{"LOGS"}
+	plan, err = BuildBatchPlan(migration, "APP", nil, time.Now())
+	require.NoError(t, err)
+	require.Len(t, plan.Batches, 2)
+	assert.Equal(t, BatchKindSlice, plan.Batches[0].Kind)
+}
+
+func TestPartitionTablesBySize(t *testing.T) {
+	names := func(groups [][]oracle.TableSize) [][]string {
+		result := make([][]string, 0, len(groups))
+		for _, group := range groups {
+			var tables []string
+			for _, table := range group {
+				tables = append(tables, table.Name)
+			}
+			result = append(result, tables)
+		}
+		return result
+	}
+
+	// 没有统计信息时按表数量均分
+	tables := []oracle.TableSize{{Name: "A"}, {Name: "B"}, {Name: "C"}, {Name: "D"}}
+	assert.Equal(t, [][]string{{"A", "B"}, {"C", "D"}}, names(partitionTablesBySize(tables, 2)))
+	// 批次数多于表数时每张表一批
+	assert.Equal(t, [][]string{{"A"}, {"B"}, {"C"}, {"D"}}, names(partitionTablesBySize(tables, 10)))
+	assert.Nil(t, partitionTablesBySize(nil, 3))
+}
+
+func TestWriteBatchConfig(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "ora2pg.conf")
+	require.NoError(t, os.WriteFile(base, []byte("TYPE COPY\nEXCLUDE AUDIT\nWHERE OLD[1=1]\nTRUNCATE_TABLE 0\n"), 0644))
+
+	slice := &MigrationBatch{ID: "ORDERS-2", Kind: BatchKindSlice, Name: "ORDERS 切片 2/3",
+		Table: "ORDERS", Column: "ID", Type: config.SliceNumber, Lower: "1000", Upper: "2000"}
+	path := filepath.Join(dir, "ora2pg.COPY.batch.conf")
+	require.NoError(t, WriteBatchConfig(base, path, slice))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	content := string(data)
+	assert.Contains(t, content, "TYPE COPY\n")
+	assert.Contains(t, content, "EXCLUDE AUDIT\n")
+	assert.Contains(t, content, "WHERE ORDERS[ID >= 1000 AND ID < 2000]\nTRUNCATE_TABLE 0\n")
+	assert.NotContains(t, content, "OLD[1=1]")
+
+	tables := &MigrationBatch{ID: "tables", Kind: BatchKindTables, Name: "其余表", Exclude: []string{"ORDERS"}}
+	require.NoError(t, WriteBatchConfig(base, path, tables))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	content = string(data)
+	assert.Contains(t, content, "EXCLUDE AUDIT ORDERS\n")
+	assert.Contains(t, content, "TRUNCATE_TABLE 1\n")
+	assert.NotContains(t, content, "WHERE")
+}
+
+func TestBatchPlanSaveLoadAndValidate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "batch_plan.json")
+	plan, err := LoadBatchPlan(path)
+	require.NoError(t, err)
+	assert.Nil(t, plan)
+
+	plan = &BatchPlan{ID: "p1", Batches: []*MigrationBatch{
+		{ID: "tables-1", Kind: BatchKindTables, Tables: []string{"USERS"}},
+		{ID: "LOGS-1", Kind: BatchKindSlice, Tables: []string{"LOGS"}, Table: "LOGS", Column: "CREATED_AT",
+			Type: config.SliceTimestamp, Upper: "2024-01-01 00:00:00"},
+	}}
+	require.NoError(t, SaveBatchPlan(path, plan))
+	loaded, err := LoadBatchPlan(path)
+	require.NoError(t, err)
+	assert.Equal(t, plan.Batches, loaded.Batches)
+
+	// 被修改的切分点和表名不会写入ora2pg配置
+	plan.Batches[1].Upper = "2024-01-01'); DROP TABLE X; --"
+	assert.Equal(t, "BATCH_PLAN_INVALID", utils.GetErrorCode(plan.Validate()))
+	plan.Batches[1].Upper = "2024-01-01 00:00:00"
+	plan.Batches[0].Tables = []string{"USERS;"}
+	require.NoError(t, SaveBatchPlan(path, plan))
+	_, err = LoadBatchPlan(path)
+	assert.Equal(t, "BATCH_PLAN_INVALID", utils.GetErrorCode(err))
+
+	require.NoError(t, os.WriteFile(path, []byte("{broken"), 0644))
+	_, err = LoadBatchPlan(path)
+	assert.Equal(t, "BATCH_PLAN_CORRUPTED", utils.GetErrorCode(err))
+}
+
+func TestMigrationServiceSetBatch(t *testing.T) {
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("分批")
+	cfg := manager.GetConfig()
+	cfg.Migration.AllowPatterns = []string{"^APP_"}
+	cfg.Migration.Incremental = config.IncrementalConfig{Tables: []config.IncrementalTableConfig{{Name: "ORDERS", Column: "UPDATED_AT"}}}
+
+	plan := &BatchPlan{ID: "p1"}
+	batch := &MigrationBatch{ID: "tables-1", Kind: BatchKindTables, Tables: []string{"APP_USERS", "APP_ROLES"}}
+	ms := NewMigrationService(cfg)
+	ms.SetBatch(plan, batch)
+	assert.Equal(t, "APP_USERS APP_ROLES", ms.filteredConfig().Migration.AllowDirective())
+	assert.Empty(t, ms.config.Migration.AllowPatterns)
+	assert.False(t, ms.config.Migration.Incremental.Enabled())
+	assert.Equal(t, BatchCheckpointPath("tables-1"), ms.checkpointPath)
+	assert.Equal(t, map[string]string{BatchMetadataKey: "tables-1", BatchPlanMetadataKey: "p1"}, ms.GetState().Metadata)
+	// 项目配置不变
+	assert.Empty(t, cfg.Migration.AllowTables)
+	assert.True(t, cfg.Migration.Incremental.Enabled())
+}
+
+func TestLoadBatchProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	plan := &BatchPlan{ID: "p2", Batches: []*MigrationBatch{{ID: "tables-1"}, {ID: "tables-2"}, {ID: "tables-3"}}}
+
+	start := time.Date(2024, 1, 2, 1, 0, 0, 0, time.UTC)
+	batchRecord := func(runID, planID, batch string, status ExecutionStatus, offset time.Duration) *HistoryRecord {
+		return &HistoryRecord{RunID: runID, Status: status, StartTime: start.Add(offset),
+			Metadata: map[string]string{BatchPlanMetadataKey: planID, BatchMetadataKey: batch}}
+	}
+	records := []*HistoryRecord{
+		batchRecord("r1", "p1", "tables-2", StatusCompleted, 0),
+		batchRecord("r2", "p2", "tables-1", StatusCompleted, time.Hour),
+		batchRecord("r3", "p2", "tables-2", StatusFailed, 2*time.Hour),
+		{RunID: "r4", Status: StatusCompleted, StartTime: start.Add(3 * time.Hour)},
+	}
+	for _, record := range records {
+		require.NoError(t, AppendHistory(path, record))
+	}
+
+	progress, err := LoadBatchProgress(path, plan)
+	require.NoError(t, err)
+	require.Len(t, progress, 3)
+	assert.True(t, progress[0].Completed())
+	assert.Equal(t, 1, progress[0].Runs)
+	// 其他计划的记录不计入
+	assert.False(t, progress[1].Completed())
+	assert.Equal(t, "r3", progress[1].Last.RunID)
+	assert.Nil(t, progress[2].Last)
+}
@@ -0,0 +1,392 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/utils"
+)
+
+// BenchmarkStartupOverhead 每个迁移类型的固定开销（启动ora2pg、连接源库、读取元数据），外推时不随数据量放大
+const BenchmarkStartupOverhead = 3 * time.Second
+
+// 外推结果的置信度
+const (
+	ConfidenceHigh   = "高"
+	ConfidenceMedium = "中"
+	ConfidenceLow    = "低"
+)
+
+// confidenceUncertainty 各置信度下预计耗时的相对误差范围
+var confidenceUncertainty = map[string]float64{
+	ConfidenceHigh:   0.2,
+	ConfidenceMedium: 0.35,
+	ConfidenceLow:    0.5,
+}
+
+// benchmarkAssumptions 线性外推的前提，随外推结果一起输出
+var benchmarkAssumptions = []string{
+	"数据类型的耗时按导出行数线性外推，未考虑大表、LOB字段、分区表带来的非线性开销",
+	"结构类型假设生产库的对象数量与测试库相同，耗时不变",
+	"基准测试只导出到文件，不包含目标库导入、创建索引和验证约束的耗时",
+	"假设生产环境的硬件、网络、并行度与基准测试环境相同",
+}
+
+// targetConnectionDirectives 基准测试时注释掉的目标库连接指令，ora2pg没有目标库连接时只把结果写入输出文件
+var targetConnectionDirectives = []string{"PG_DSN", "PG_USER", "PG_PWD"}
+
+// BenchmarkStage 单个迁移类型的基准测试结果
+type BenchmarkStage struct {
+	Type     MigrationType   `json:"type"`
+	Status   ExecutionStatus `json:"status"`
+	Duration time.Duration   `json:"duration"`
+	// Rows 从ora2pg输出中统计的导出行数
+	Rows int64 `json:"rows,omitempty"`
+	// OutputBytes 本类型新增的输出文件大小
+	OutputBytes    int64   `json:"output_bytes"`
+	RowsPerSecond  float64 `json:"rows_per_second,omitempty"`
+	BytesPerSecond float64 `json:"bytes_per_second,omitempty"`
+	Error          string  `json:"error,omitempty"`
+}
+
+// BenchmarkReport 迁移性能基准报告
+type BenchmarkReport struct {
+	Project   string        `json:"project"`
+	Schema    string        `json:"schema"`
+	StartTime time.Time     `json:"start_time"`
+	EndTime   time.Time     `json:"end_time"`
+	Duration  time.Duration `json:"duration"`
+	// OutputDir 基准测试的临时输出目录
+	OutputDir    string              `json:"output_dir"`
+	ParallelJobs int                 `json:"parallel_jobs"`
+	Scale        *oracle.SchemaScale `json:"scale,omitempty"`
+	Stages       []*BenchmarkStage   `json:"stages"`
+	Estimate     *BenchmarkEstimate  `json:"estimate,omitempty"`
+}
+
+// StageEstimate 单个迁移类型的外推耗时
+type StageEstimate struct {
+	Type     MigrationType `json:"type"`
+	Measured time.Duration `json:"measured"`
+	Estimate time.Duration `json:"estimate"`
+	// Scaled 是否按数据量放大，结构类型为 false
+	Scaled bool `json:"scaled"`
+}
+
+// BenchmarkEstimate 按数据量线性外推的生产库预计耗时
+type BenchmarkEstimate struct {
+	// Factor 生产库数据量相对基准测试样本的倍数
+	Factor         float64          `json:"factor"`
+	SampleRows     int64            `json:"sample_rows,omitempty"`
+	ProductionRows int64            `json:"production_rows,omitempty"`
+	Stages         []*StageEstimate `json:"stages"`
+	Estimate       time.Duration    `json:"estimate"`
+	Lower          time.Duration    `json:"lower"`
+	Upper          time.Duration    `json:"upper"`
+	// Uncertainty 预计耗时的相对误差范围，如 0.35 表示 ±35%
+	Uncertainty float64 `json:"uncertainty"`
+	Confidence  string  `json:"confidence"`
+	// Reasons 置信度降低的原因
+	Reasons     []string `json:"reasons,omitempty"`
+	Assumptions []string `json:"assumptions"`
+}
+
+// DisableTargetConnection 注释掉ora2pg配置中的目标库连接，返回调整后的内容和被注释的指令
+func DisableTargetConnection(content []byte) ([]byte, []string) {
+	lines := strings.Split(string(content), "\n")
+	var disabled []string
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		end := strings.IndexAny(trimmed, "= \t")
+		if end < 0 {
+			continue
+		}
+		name := strings.ToUpper(trimmed[:end])
+		for _, directive := range targetConnectionDirectives {
+			if directive == name {
+				lines[i] = "# 基准测试只导出到文件，不连接目标库: " + name
+				disabled = append(disabled, name)
+			}
+		}
+	}
+	return []byte(strings.Join(lines, "\n")), disabled
+}
+
+// disableTargetConnectionFile 只导出到文件时调整生成的ora2pg配置文件
+func (ms *MigrationService) disableTargetConnectionFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return utils.FileErrors.ReadFailed(path, err)
+	}
+	adapted, disabled := DisableTargetConnection(content)
+	if len(disabled) == 0 {
+		return nil
+	}
+	if err := os.WriteFile(path, adapted, 0644); err != nil {
+		return utils.FileErrors.WriteFailed(path, err)
+	}
+	ms.logger.Infof("只导出到文件，已注释目标库连接: %s", strings.Join(disabled, ", "))
+	return nil
+}
+
+// SetFileOnly 设置只导出到文件：不连接目标库、不处理目标库约束、不执行迁移前后脚本，用于基准测试和预演
+func (ms *MigrationService) SetFileOnly(fileOnly bool) {
+	ms.fileOnly = fileOnly
+}
+
+// RunBenchmark 逐个执行迁移类型并记录各类型的耗时、导出行数和输出大小
+//
+// 每个类型单独执行，耗时包含生成配置、启动ora2pg等固定开销。onStage 在每个类型完成后调用，可为空。
+func RunBenchmark(ctx context.Context, ms *MigrationService, migrationTypes []MigrationType,
+	onStage func(*BenchmarkStage)) (*BenchmarkReport, error) {
+	report := &BenchmarkReport{
+		Project:      ms.config.Project.Name,
+		Schema:       strings.ToUpper(ms.config.Oracle.Schema),
+		StartTime:    time.Now(),
+		OutputDir:    ms.config.Migration.OutputDir,
+		ParallelJobs: ms.parallelJobs,
+		Scale:        ms.scale,
+	}
+	if report.Schema == "" {
+		report.Schema = strings.ToUpper(ms.config.Oracle.Username)
+	}
+
+	for _, migrationType := range migrationTypes {
+		before := directorySize(ms.config.Migration.OutputDir)
+		start := time.Now()
+		results, err := ms.ExecuteWithProgress(ctx, []MigrationType{migrationType}, NewProgressTracker())
+		stage := &BenchmarkStage{
+			Type:        migrationType,
+			Status:      StatusFailed,
+			Duration:    time.Since(start),
+			OutputBytes: directorySize(ms.config.Migration.OutputDir) - before,
+		}
+		if stage.OutputBytes < 0 {
+			stage.OutputBytes = 0
+		}
+		if len(results) > 0 && results[0] != nil {
+			stage.Status = results[0].Status
+			stage.Rows = results[0].MigratedRows
+			if results[0].Error != nil {
+				stage.Error = results[0].Error.Error()
+			}
+		}
+		if err != nil && stage.Error == "" {
+			stage.Error = err.Error()
+		}
+		if seconds := stage.Duration.Seconds(); seconds > 0 {
+			stage.RowsPerSecond = float64(stage.Rows) / seconds
+			stage.BytesPerSecond = float64(stage.OutputBytes) / seconds
+		}
+		report.Stages = append(report.Stages, stage)
+		if onStage != nil {
+			onStage(stage)
+		}
+
+		if ctx.Err() != nil {
+			report.finish()
+			return report, ctx.Err()
+		}
+		// 准备环境、生成配置失败时后续类型同样会失败
+		if len(results) == 0 && err != nil {
+			report.finish()
+			return report, err
+		}
+	}
+	report.finish()
+	return report, nil
+}
+
+// finish 记录基准测试的结束时间
+func (r *BenchmarkReport) finish() {
+	r.EndTime = time.Now()
+	r.Duration = r.EndTime.Sub(r.StartTime)
+}
+
+// SampleRows 基准测试样本的数据行数：优先使用导出行数，ora2pg未输出行数时使用源库统计信息
+func (r *BenchmarkReport) SampleRows() int64 {
+	var rows int64
+	for _, stage := range r.Stages {
+		if isDataMigrationType(stage.Type) {
+			rows += stage.Rows
+		}
+	}
+	if rows == 0 && r.Scale != nil && r.Scale.Data != nil {
+		rows = r.Scale.Data.Rows
+	}
+	return rows
+}
+
+// FactorForRows 按生产库的行数计算相对样本的外推倍数
+func (r *BenchmarkReport) FactorForRows(productionRows int64) (float64, error) {
+	sampleRows := r.SampleRows()
+	if sampleRows <= 0 {
+		return 0, utils.NewError(utils.ErrorTypeValidation, "BENCHMARK_NO_SAMPLE_ROWS").
+			Message("基准测试没有统计到导出行数，无法按行数外推").
+			Details("ora2pg输出中没有行数，源库也没有统计信息").
+			Suggestion("使用 --prod-factor 直接指定生产库数据量的倍数").
+			Suggestion("收集源库统计信息后重新执行（--gather-stats）").
+			Build()
+	}
+	return float64(productionRows) / float64(sampleRows), nil
+}
+
+// Extrapolate 按数据量倍数线性外推生产库的预计耗时
+//
+// 数据类型扣除固定开销后按倍数放大，结构类型耗时不变；预计耗时按置信度给出误差范围。
+func (r *BenchmarkReport) Extrapolate(factor float64) *BenchmarkEstimate {
+	estimate := &BenchmarkEstimate{
+		Factor:      factor,
+		SampleRows:  r.SampleRows(),
+		Assumptions: append([]string(nil), benchmarkAssumptions...),
+	}
+	if estimate.SampleRows > 0 {
+		estimate.ProductionRows = int64(float64(estimate.SampleRows) * factor)
+	}
+
+	var dataDuration time.Duration
+	failed := false
+	dataStages := 0
+	for _, stage := range r.Stages {
+		stageEstimate := &StageEstimate{Type: stage.Type, Measured: stage.Duration, Estimate: stage.Duration}
+		if isDataMigrationType(stage.Type) {
+			dataStages++
+			dataDuration += stage.Duration
+			stageEstimate.Scaled = true
+			stageEstimate.Estimate = scaleDuration(stage.Duration, factor)
+		}
+		if stage.Status != StatusCompleted {
+			failed = true
+		}
+		estimate.Stages = append(estimate.Stages, stageEstimate)
+		estimate.Estimate += stageEstimate.Estimate
+	}
+
+	// 置信度从高开始，每个不确定因素降低一级或两级
+	penalty := 0
+	switch {
+	case factor > 10:
+		penalty += 2
+		estimate.Reasons = append(estimate.Reasons, fmt.Sprintf("外推倍数 %.1f 超过10倍，线性外推误差会被放大", factor))
+	case factor > 3:
+		penalty++
+		estimate.Reasons = append(estimate.Reasons, fmt.Sprintf("外推倍数 %.1f 超过3倍", factor))
+	}
+	if dataStages == 0 {
+		penalty += 2
+		estimate.Reasons = append(estimate.Reasons, "基准测试没有执行数据类型（COPY/INSERT），无法测量数据吞吐")
+	} else if dataDuration < time.Minute {
+		penalty++
+		estimate.Reasons = append(estimate.Reasons, "数据类型的样本耗时不足1分钟，固定开销占比高")
+	}
+	if r.Scale != nil && r.Scale.Data != nil && r.Scale.Data.Unanalyzed > 0 {
+		penalty++
+		estimate.Reasons = append(estimate.Reasons, fmt.Sprintf("%d 张表没有统计信息，样本数据量可能不准确", r.Scale.Data.Unanalyzed))
+	}
+	if failed {
+		penalty += 2
+		estimate.Reasons = append(estimate.Reasons, "有迁移类型执行失败，测得的耗时不完整")
+	}
+	switch {
+	case penalty == 0:
+		estimate.Confidence = ConfidenceHigh
+	case penalty <= 2:
+		estimate.Confidence = ConfidenceMedium
+	default:
+		estimate.Confidence = ConfidenceLow
+	}
+
+	estimate.Uncertainty = confidenceUncertainty[estimate.Confidence]
+	estimate.Lower = time.Duration(float64(estimate.Estimate) * (1 - estimate.Uncertainty))
+	estimate.Upper = time.Duration(float64(estimate.Estimate) * (1 + estimate.Uncertainty))
+	return estimate
+}
+
+// scaleDuration 扣除固定开销后按倍数放大耗时，样本耗时很短时固定开销按一半计算
+func scaleDuration(measured time.Duration, factor float64) time.Duration {
+	overhead := BenchmarkStartupOverhead
+	if overhead > measured/2 {
+		overhead = measured / 2
+	}
+	return overhead + time.Duration(float64(measured-overhead)*factor)
+}
+
+// FormatText 以文本格式输出基准报告
+func (r *BenchmarkReport) FormatText() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "项目: %s  Schema: %s\n", r.Project, r.Schema)
+	fmt.Fprintf(&b, "开始时间: %s  总耗时: %v  并行作业数: %d\n",
+		r.StartTime.Format("2006-01-02 15:04:05"), r.Duration.Round(time.Millisecond), r.ParallelJobs)
+	if r.Scale != nil && r.Scale.Data != nil {
+		fmt.Fprintf(&b, "源库规模: %d 个对象，%d 张表，约 %d 行，约 %s\n", r.Scale.Objects.Total(),
+			r.Scale.Data.Tables, r.Scale.Data.Rows, formatBytes(uint64(r.Scale.Data.Bytes)))
+	}
+
+	b.WriteString("\n各类型耗时:\n")
+	for _, stage := range r.Stages {
+		fmt.Fprintf(&b, "  %-10s %-10s %10v  输出 %s", stage.Type, stage.Status,
+			stage.Duration.Round(time.Millisecond), formatBytes(uint64(stage.OutputBytes)))
+		if stage.Rows > 0 {
+			fmt.Fprintf(&b, "  %d 行（%.0f 行/秒）", stage.Rows, stage.RowsPerSecond)
+		}
+		if stage.BytesPerSecond > 0 {
+			fmt.Fprintf(&b, "  %s/秒", formatBytes(uint64(stage.BytesPerSecond)))
+		}
+		b.WriteString("\n")
+		if stage.Error != "" {
+			fmt.Fprintf(&b, "             错误: %s\n", stage.Error)
+		}
+	}
+
+	if e := r.Estimate; e != nil {
+		b.WriteString("\n生产库预计耗时（线性外推）:\n")
+		if e.ProductionRows > 0 {
+			fmt.Fprintf(&b, "  数据量倍数: %.2f（样本 %d 行 → 生产 %d 行）\n", e.Factor, e.SampleRows, e.ProductionRows)
+		} else {
+			fmt.Fprintf(&b, "  数据量倍数: %.2f\n", e.Factor)
+		}
+		for _, stage := range e.Stages {
+			note := "（对象数量不变，按实测）"
+			if stage.Scaled {
+				note = "（按数据量放大）"
+			}
+			fmt.Fprintf(&b, "  %-10s %10v → %v %s\n", stage.Type,
+				stage.Measured.Round(time.Millisecond), stage.Estimate.Round(time.Second), note)
+		}
+		fmt.Fprintf(&b, "  预计总耗时: %v（范围 %v ~ %v，±%.0f%%）\n", e.Estimate.Round(time.Second),
+			e.Lower.Round(time.Second), e.Upper.Round(time.Second), e.Uncertainty*100)
+		fmt.Fprintf(&b, "  置信度: %s\n", e.Confidence)
+		for _, reason := range e.Reasons {
+			fmt.Fprintf(&b, "    • %s\n", reason)
+		}
+		b.WriteString("  外推前提:\n")
+		for _, assumption := range e.Assumptions {
+			fmt.Fprintf(&b, "    • %s\n", assumption)
+		}
+	}
+	return b.String()
+}
+
+// directorySize 统计目录下所有文件的大小，目录不存在时返回0
+func directorySize(dir string) int64 {
+	var total int64
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
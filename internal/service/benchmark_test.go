@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+)
+
+func TestDisableTargetConnection(t *testing.T) {
+	content := "ORACLE_DSN dbi:Oracle:host=db\nPG_DSN=dbi:Pg:dbname=app\npg_user\tmigrator\n# PG_PWD 已注释\nPG_PWD=secret\nPG_SCHEMA=app\n"
+
+	adapted, disabled := DisableTargetConnection([]byte(content))
+	assert.Equal(t, []string{"PG_DSN", "PG_USER", "PG_PWD"}, disabled)
+	text := string(adapted)
+	assert.NotContains(t, text, "dbname=app")
+	assert.NotContains(t, text, "secret")
+	assert.Contains(t, text, "ORACLE_DSN dbi:Oracle:host=db\n")
+	assert.Contains(t, text, "PG_SCHEMA=app\n")
+
+	_, disabled = DisableTargetConnection(adapted)
+	assert.Empty(t, disabled)
+}
+
+func TestBenchmarkExtrapolate(t *testing.T) {
+	report := &BenchmarkReport{
+		Stages: []*BenchmarkStage{
+			{Type: MigrationTypeTable, Status: StatusCompleted, Duration: 10 * time.Second},
+			{Type: MigrationTypeCopy, Status: StatusCompleted, Duration: 103 * time.Second, Rows: 1000000},
+		},
+	}
+
+	factor, err := report.FactorForRows(2000000)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, factor)
+
+	// 数据类型扣除固定开销后放大，结构类型不变
+	estimate := report.Extrapolate(factor)
+	require.Len(t, estimate.Stages, 2)
+	assert.False(t, estimate.Stages[0].Scaled)
+	assert.Equal(t, 10*time.Second, estimate.Stages[0].Estimate)
+	assert.True(t, estimate.Stages[1].Scaled)
+	assert.Equal(t, BenchmarkStartupOverhead+200*time.Second, estimate.Stages[1].Estimate)
+	assert.Equal(t, 213*time.Second, estimate.Estimate)
+	assert.Equal(t, int64(2000000), estimate.ProductionRows)
+	assert.Equal(t, ConfidenceHigh, estimate.Confidence)
+	assert.Empty(t, estimate.Reasons)
+	assert.Less(t, estimate.Lower, estimate.Estimate)
+	assert.Greater(t, estimate.Upper, estimate.Estimate)
+	assert.NotEmpty(t, estimate.Assumptions)
+
+	// 外推倍数大、统计信息不完整时置信度降低，误差范围变大
+	report.Scale = &oracle.SchemaScale{Data: &oracle.DataSize{Rows: 900000, Unanalyzed: 3}}
+	estimate = report.Extrapolate(50)
+	assert.Equal(t, ConfidenceLow, estimate.Confidence)
+	assert.Len(t, estimate.Reasons, 2)
+	assert.Equal(t, confidenceUncertainty[ConfidenceLow], estimate.Uncertainty)
+
+	text := (&BenchmarkReport{Stages: report.Stages, Estimate: estimate}).FormatText()
+	assert.Contains(t, text, "置信度: 低")
+	assert.Contains(t, text, "外推前提")
+}
+
+func TestBenchmarkSampleRows(t *testing.T) {
+	// ora2pg没有输出行数时使用源库统计信息
+	report := &BenchmarkReport{
+		Stages: []*BenchmarkStage{{Type: MigrationTypeCopy, Status: StatusCompleted, Duration: time.Second}},
+		Scale:  &oracle.SchemaScale{Data: &oracle.DataSize{Rows: 500}},
+	}
+	factor, err := report.FactorForRows(5000)
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, factor)
+
+	report.Scale = nil
+	_, err = report.FactorForRows(5000)
+	require.Error(t, err)
+
+	// 样本很短时固定开销按一半计算
+	assert.Equal(t, time.Second/2+5*time.Second, scaleDuration(time.Second, 10))
+}
+
+func TestRunBenchmarkFileOnly(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟ora2pg依赖 /bin/sh")
+	}
+
+	// 模拟ora2pg：记录使用的配置并生成输出文件
+	bin := t.TempDir()
+	script := `#!/bin/sh
+conf=""
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-c" ]; then conf="$2"; fi
+  shift
+done
+if [ -n "$conf" ]; then
+  cp "$conf" used.conf
+  echo "INSERT INTO t VALUES (1);" >> output/data.sql
+fi
+`
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ora2pg"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Chdir(t.TempDir())
+
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("基准测试")
+	cfg := manager.GetConfig()
+	cfg.Migration.OutputDir = "output"
+	cfg.PostgreSQL.Managed = true
+	require.NoError(t, os.MkdirAll("output", 0755))
+	require.NoError(t, os.WriteFile(filepath.Join("output", "ora2pg.conf"),
+		[]byte("ORACLE_DSN dbi:Oracle:host=db\nPG_DSN=dbi:Pg:dbname=app\n"), 0644))
+
+	ms := NewMigrationService(cfg)
+	ms.SetFileOnly(true)
+	ms.SetCheckpointPath(filepath.Join(t.TempDir(), "checkpoint.json"))
+	assert.False(t, ms.deferConstraintsEnabled())
+
+	var stages []*BenchmarkStage
+	report, err := RunBenchmark(context.Background(), ms, []MigrationType{MigrationTypeTable, MigrationTypeCopy},
+		func(stage *BenchmarkStage) { stages = append(stages, stage) })
+	require.NoError(t, err)
+	require.Len(t, report.Stages, 2)
+	assert.Equal(t, report.Stages, stages)
+	for _, stage := range report.Stages {
+		assert.Equal(t, StatusCompleted, stage.Status, stage.Error)
+		assert.Positive(t, stage.OutputBytes)
+	}
+	assert.Equal(t, "基准测试", report.Project)
+	assert.False(t, report.EndTime.Before(report.StartTime))
+
+	used, err := os.ReadFile("used.conf")
+	require.NoError(t, err)
+	assert.True(t, strings.Contains(string(used), "# 基准测试只导出到文件"))
+	assert.NotContains(t, string(used), "dbname=app")
+}
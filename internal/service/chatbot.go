@@ -0,0 +1,509 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// 群机器人消息中错误摘要的长度限制
+const (
+	chatBotMaxFailures   = 5
+	chatBotMaxErrorRunes = 200
+)
+
+// ChatBotField 群机器人消息中的一个字段
+type ChatBotField struct {
+	Label string
+	Value string
+}
+
+// ChatBotMessage 群机器人消息内容，发送时按各平台的格式渲染
+type ChatBotMessage struct {
+	Event  string // start、success、failure
+	Title  string
+	Fields []ChatBotField
+	// Failures 未成功的迁移类型及错误摘要
+	Failures []string
+}
+
+// chatBotRequest 发送给机器人的一次请求
+type chatBotRequest struct {
+	url  string
+	body []byte
+}
+
+// NewChatBotStartMessage 生成迁移开始时的群机器人消息
+func NewChatBotStartMessage(cfg *config.ProjectConfig, task string, types []MigrationType) *ChatBotMessage {
+	host, _ := os.Hostname()
+	return &ChatBotMessage{
+		Event: config.NotificationEventStart,
+		Title: fmt.Sprintf("%s %s开始", cfg.Project.Name, task),
+		Fields: []ChatBotField{
+			{Label: "运行ID", Value: utils.RunID()},
+			{Label: "主机", Value: host},
+			{Label: "开始时间", Value: time.Now().Format("2006-01-02 15:04:05")},
+			{Label: "进度", Value: fmt.Sprintf("0/%d", len(types))},
+			{Label: "迁移类型", Value: FormatMigrationOrder(types)},
+		},
+	}
+}
+
+// NewChatBotFinishMessage 根据通知数据生成迁移结束时的群机器人消息
+func NewChatBotFinishMessage(data *NotificationData) *ChatBotMessage {
+	message := &ChatBotMessage{
+		Event: config.NotificationEventFailure,
+		Title: fmt.Sprintf("%s %s%s", data.Project, data.Task, data.StatusText),
+		Fields: []ChatBotField{
+			{Label: "运行ID", Value: data.RunID},
+			{Label: "主机", Value: data.Host},
+			{Label: "开始时间", Value: data.StartTime.Format("2006-01-02 15:04:05")},
+			{Label: "耗时", Value: data.Duration},
+			{Label: "进度", Value: fmt.Sprintf("%d/%d 成功", data.Succeeded, data.Total)},
+		},
+	}
+	if data.Success {
+		message.Event = config.NotificationEventSuccess
+	}
+	if data.Failed > 0 {
+		message.Fields[len(message.Fields)-1].Value += fmt.Sprintf("，%d 失败", data.Failed)
+	}
+	if len(data.Tags) > 0 {
+		message.Fields = append(message.Fields, ChatBotField{Label: "标签", Value: strings.Join(data.Tags, ", ")})
+	}
+	if data.Note != "" {
+		message.Fields = append(message.Fields, ChatBotField{Label: "备注", Value: data.Note})
+	}
+	if data.Link != "" {
+		message.Fields = append(message.Fields, ChatBotField{Label: "详情", Value: data.Link})
+	}
+
+	for i, failure := range data.Failures {
+		if i == chatBotMaxFailures {
+			message.Failures = append(message.Failures, fmt.Sprintf("另有 %d 项未成功", len(data.Failures)-i))
+			break
+		}
+		summary := fmt.Sprintf("%s %s", failure.Type, failure.Status)
+		if failure.Error != "" {
+			summary += ": " + errorSummary(failure.Error)
+		}
+		message.Failures = append(message.Failures, summary)
+	}
+	return message
+}
+
+// errorSummary 取错误的第一行并截断，避免消息过长
+func errorSummary(message string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	runes := []rune(strings.TrimSpace(line))
+	if len(runes) <= chatBotMaxErrorRunes {
+		return string(runes)
+	}
+	return string(runes[:chatBotMaxErrorRunes]) + "..."
+}
+
+// Sanitized 按日志脱敏规则处理消息内容后的副本，消息会发送到外部平台，不受日志脱敏开关影响
+func (m *ChatBotMessage) Sanitized(logger *utils.Logger) *ChatBotMessage {
+	return m.mapText(logger.Sanitize)
+}
+
+// mapText 对标题、字段和错误摘要逐项转换后的副本
+func (m *ChatBotMessage) mapText(convert func(string) string) *ChatBotMessage {
+	converted := &ChatBotMessage{Event: m.Event, Title: convert(m.Title)}
+	for _, field := range m.Fields {
+		converted.Fields = append(converted.Fields, ChatBotField{Label: convert(field.Label), Value: convert(field.Value)})
+	}
+	for _, failure := range m.Failures {
+		converted.Failures = append(converted.Failures, convert(failure))
+	}
+	return converted
+}
+
+// Markdown 渲染为钉钉、飞书通用的Markdown正文，不含标题
+func (m *ChatBotMessage) Markdown() string {
+	return m.markdown(func(text string) string { return "**" + text + "**" })
+}
+
+// markdown 按平台的加粗语法渲染正文
+func (m *ChatBotMessage) markdown(bold func(string) string) string {
+	var lines []string
+	for _, field := range m.Fields {
+		lines = append(lines, fmt.Sprintf("- %s: %s", bold(field.Label), field.Value))
+	}
+	if len(m.Failures) > 0 {
+		lines = append(lines, "", bold("错误摘要"))
+		for _, failure := range m.Failures {
+			lines = append(lines, "- "+failure)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mentions 需要@的负责人，只在迁移失败时@
+func (m *ChatBotMessage) mentions(bot *config.ChatBotConfig) []string {
+	if m.Event != config.NotificationEventFailure {
+		return nil
+	}
+	var mentions []string
+	for _, mention := range bot.Mentions {
+		if mention = strings.TrimSpace(mention); mention != "" {
+			mentions = append(mentions, mention)
+		}
+	}
+	return mentions
+}
+
+// splitMentionAll 拆分出@所有人和具体的负责人
+func splitMentionAll(mentions []string) (bool, []string) {
+	all := false
+	var users []string
+	for _, mention := range mentions {
+		if strings.EqualFold(mention, config.ChatBotMentionAll) {
+			all = true
+		} else {
+			users = append(users, mention)
+		}
+	}
+	return all, users
+}
+
+// dingTalkPayload 钉钉机器人的 markdown 消息，@的手机号需要同时出现在正文中
+func dingTalkPayload(message *ChatBotMessage, mentions []string) map[string]any {
+	all, mobiles := splitMentionAll(mentions)
+	text := fmt.Sprintf("### %s\n\n%s", message.Title, message.Markdown())
+	if all {
+		text += "\n\n@所有人"
+	}
+	if len(mobiles) > 0 {
+		text += "\n\n@" + strings.Join(mobiles, " @")
+	}
+	return map[string]any{
+		"msgtype":  "markdown",
+		"markdown": map[string]any{"title": message.Title, "text": text},
+		"at":       map[string]any{"atMobiles": mobiles, "isAtAll": all},
+	}
+}
+
+// weComPayloads 企业微信机器人的消息
+//
+// markdown 消息不支持@手机号和@所有人，有负责人时额外发送一条带 mentioned_list 的文本消息。
+func weComPayloads(message *ChatBotMessage, mentions []string) []map[string]any {
+	color := "info"
+	switch message.Event {
+	case config.NotificationEventFailure:
+		color = "warning"
+	case config.NotificationEventStart:
+		color = "comment"
+	}
+	content := fmt.Sprintf("### <font color=\"%s\">%s</font>\n%s", color, message.Title, message.Markdown())
+	payloads := []map[string]any{{
+		"msgtype":  "markdown",
+		"markdown": map[string]any{"content": content},
+	}}
+	if len(mentions) == 0 {
+		return payloads
+	}
+
+	userIDs, mobiles := []string{}, []string{}
+	for _, mention := range mentions {
+		switch {
+		case strings.EqualFold(mention, config.ChatBotMentionAll):
+			userIDs = append(userIDs, "@all")
+		case isPhoneNumber(mention):
+			mobiles = append(mobiles, mention)
+		default:
+			userIDs = append(userIDs, mention)
+		}
+	}
+	return append(payloads, map[string]any{
+		"msgtype": "text",
+		"text": map[string]any{
+			"content":               fmt.Sprintf("%s，请负责人关注", message.Title),
+			"mentioned_list":        userIDs,
+			"mentioned_mobile_list": mobiles,
+		},
+	})
+}
+
+// isPhoneNumber 是否为手机号（可带 + 前缀的纯数字）
+func isPhoneNumber(value string) bool {
+	digits := strings.TrimPrefix(value, "+")
+	if len(digits) < 5 {
+		return false
+	}
+	_, err := strconv.ParseUint(digits, 10, 64)
+	return err == nil
+}
+
+// feishuPayload 飞书机器人的消息卡片，按结果显示不同颜色的标题
+func feishuPayload(message *ChatBotMessage, mentions []string) map[string]any {
+	template := "green"
+	switch message.Event {
+	case config.NotificationEventFailure:
+		template = "red"
+	case config.NotificationEventStart:
+		template = "blue"
+	}
+	content := message.Markdown()
+	if len(mentions) > 0 {
+		var ats []string
+		for _, mention := range mentions {
+			if strings.EqualFold(mention, config.ChatBotMentionAll) {
+				mention = "all"
+			}
+			ats = append(ats, fmt.Sprintf("<at id=%s></at>", mention))
+		}
+		content += "\n\n" + strings.Join(ats, " ")
+	}
+	return map[string]any{
+		"msg_type": "interactive",
+		"card": map[string]any{
+			"config": map[string]any{"wide_screen_mode": true},
+			"header": map[string]any{
+				"title":    map[string]any{"tag": "plain_text", "content": message.Title},
+				"template": template,
+			},
+			"elements": []any{
+				map[string]any{"tag": "div", "text": map[string]any{"tag": "lark_md", "content": content}},
+			},
+		},
+	}
+}
+
+// slackPayload Slack incoming webhook 的 Block Kit 消息，mrkdwn 用单个星号加粗
+func slackPayload(message *ChatBotMessage, mentions []string) map[string]any {
+	text := message.mapText(slackEscape).markdown(func(text string) string { return "*" + text + "*" })
+	if len(mentions) > 0 {
+		var ats []string
+		for _, mention := range mentions {
+			if strings.EqualFold(mention, config.ChatBotMentionAll) {
+				ats = append(ats, "<!channel>")
+			} else {
+				ats = append(ats, fmt.Sprintf("<@%s>", mention))
+			}
+		}
+		text += "\n\n" + strings.Join(ats, " ")
+	}
+	return map[string]any{
+		"text": message.Title,
+		"blocks": []any{
+			map[string]any{"type": "header", "text": map[string]any{"type": "plain_text", "text": message.Title}},
+			map[string]any{"type": "section", "text": map[string]any{"type": "mrkdwn", "text": text}},
+		},
+	}
+}
+
+// slackEscape 转义 Slack mrkdwn 中的控制字符
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// dingTalkSignedURL 钉钉加签：以密钥对"毫秒时间戳\n密钥"做 HMAC-SHA256，base64 后与时间戳一起附加到地址参数
+func dingTalkSignedURL(rawURL, secret string, now time.Time) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	timestamp := strconv.FormatInt(now.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + secret))
+
+	query := parsed.Query()
+	query.Set("timestamp", timestamp)
+	query.Set("sign", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
+// feishuSign 飞书加签：以"秒级时间戳\n密钥"为密钥对空内容做 HMAC-SHA256，再 base64
+func feishuSign(secret string, now time.Time) (string, string) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(timestamp+"\n"+secret))
+	return timestamp, base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// buildChatBotRequests 按平台构造请求，需要加签时在发送时刻签名
+func buildChatBotRequests(bot *config.ChatBotConfig, message *ChatBotMessage, now time.Time) ([]chatBotRequest, error) {
+	target := strings.TrimSpace(bot.URL)
+	mentions := message.mentions(bot)
+
+	var payloads []map[string]any
+	switch bot.PlatformName() {
+	case config.ChatBotDingTalk:
+		if bot.Secret != "" {
+			signed, err := dingTalkSignedURL(target, bot.Secret, now)
+			if err != nil {
+				return nil, err
+			}
+			target = signed
+		}
+		payloads = append(payloads, dingTalkPayload(message, mentions))
+	case config.ChatBotWeCom:
+		payloads = weComPayloads(message, mentions)
+	case config.ChatBotFeishu:
+		payload := feishuPayload(message, mentions)
+		if bot.Secret != "" {
+			payload["timestamp"], payload["sign"] = feishuSign(bot.Secret, now)
+		}
+		payloads = append(payloads, payload)
+	case config.ChatBotSlack:
+		payloads = append(payloads, slackPayload(message, mentions))
+	default:
+		return nil, fmt.Errorf("不支持的机器人平台: %s", bot.Platform)
+	}
+
+	requests := make([]chatBotRequest, 0, len(payloads))
+	for _, payload := range payloads {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("序列化机器人消息失败: %v", err)
+		}
+		requests = append(requests, chatBotRequest{url: target, body: body})
+	}
+	return requests, nil
+}
+
+// chatBotResponse 钉钉、企业微信（errcode）和飞书（code）的响应
+type chatBotResponse struct {
+	ErrCode *int   `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+	Code    *int   `json:"code"`
+	Msg     string `json:"msg"`
+}
+
+// checkChatBotResponse 检查机器人的响应，平台在HTTP 200中返回业务错误码
+func checkChatBotResponse(platform string, response *http.Response, body []byte) error {
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("机器人返回 %s: %s", response.Status, strings.TrimSpace(string(body)))
+	}
+	if platform == config.ChatBotSlack {
+		return nil
+	}
+	var result chatBotResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("无法解析机器人响应: %s", strings.TrimSpace(string(body)))
+	}
+	if result.ErrCode != nil && *result.ErrCode != 0 {
+		return fmt.Errorf("机器人返回错误 %d: %s", *result.ErrCode, result.ErrMsg)
+	}
+	if result.Code != nil && *result.Code != 0 {
+		return fmt.Errorf("机器人返回错误 %d: %s", *result.Code, result.Msg)
+	}
+	return nil
+}
+
+// NotifyChatBotsStarted 迁移开始时向群机器人发送消息
+func (n *Notifier) NotifyChatBotsStarted(task string, types []MigrationType) []error {
+	if len(n.cfg.Notifications.Bots) == 0 {
+		return nil
+	}
+	return n.SendChatBots(NewChatBotStartMessage(n.cfg, task, types))
+}
+
+// NotifyChatBotsFinished 迁移结束后向群机器人发送结果，没有迁移记录时不发送
+func (n *Notifier) NotifyChatBotsFinished(record *HistoryRecord) []error {
+	if len(n.cfg.Notifications.Bots) == 0 || record == nil {
+		return nil
+	}
+	return n.SendChatBots(NewChatBotFinishMessage(NewNotificationData(n.cfg, record)))
+}
+
+// SendChatBots 向订阅了消息事件的群机器人发送消息，返回各个发送失败的机器人的错误
+func (n *Notifier) SendChatBots(message *ChatBotMessage) []error {
+	var errs []error
+	sanitized := message.Sanitized(n.logger)
+	for i := range n.cfg.Notifications.Bots {
+		bot := &n.cfg.Notifications.Bots[i]
+		if !bot.NotifyOn(message.Event) {
+			continue
+		}
+		if err := n.sendChatBot(bot, sanitized); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// sendChatBot 发送一条消息，失败时按配置重试；每次重试重新签名，避免时间戳过期
+func (n *Notifier) sendChatBot(bot *config.ChatBotConfig, message *ChatBotMessage) error {
+	var lastErr error
+	retries := bot.RetryCount()
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(n.botBackoff * time.Duration(attempt))
+		}
+		if lastErr = n.postChatBot(bot, message); lastErr == nil {
+			n.logger.Infof("已发送群机器人消息: %s %s", bot.DisplayName(), message.Title)
+			return nil
+		}
+	}
+	return utils.NewError(utils.ErrorTypeConnection, "CHATBOT_SEND_FAILED").
+		Message(fmt.Sprintf("发送群机器人消息失败: %s", bot.DisplayName())).
+		Details(fmt.Sprintf("已重试 %d 次: %v", retries, lastErr)).
+		Cause(lastErr).
+		Suggestion("检查 notifications.bots 中该机器人的 url 是否正确、能否从本机访问").
+		Suggestion("钉钉、飞书机器人开启加签时需要配置 secret；开启关键词校验时，关键词需要出现在消息标题中").
+		Build()
+}
+
+// postChatBot 发送一次消息，企业微信@负责人时依次发送两条
+func (n *Notifier) postChatBot(bot *config.ChatBotConfig, message *ChatBotMessage) error {
+	requests, err := buildChatBotRequests(bot, message, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, item := range requests {
+		if err := n.postChatBotRequest(bot, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postChatBotRequest 发送一次请求并检查响应
+func (n *Notifier) postChatBotRequest(bot *config.ChatBotConfig, item chatBotRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), bot.RequestTimeout())
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, item.url, bytes.NewReader(item.body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	request.Header.Set("User-Agent", "ora2pg-admin")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		// 错误中的地址含有 access_token，只保留主机名
+		if urlErr, ok := err.(*url.Error); ok {
+			return fmt.Errorf("请求 %s 失败: %v", requestHost(item.url), urlErr.Err)
+		}
+		return err
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+	return checkChatBotResponse(bot.PlatformName(), response, body)
+}
+
+// requestHost 地址的主机名，解析失败时返回空
+func requestHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Host
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// chatBotTestMessage 失败结果的机器人消息
+func chatBotTestMessage() *ChatBotMessage {
+	cfg := notificationTestConfig()
+	record := notificationTestRecord(StatusFailed)
+	record.Results[1].Error = "ERROR: duplicate key\nDETAIL: Key (id)=(1) already exists"
+	return NewChatBotFinishMessage(NewNotificationData(cfg, record))
+}
+
+// decodePayload 把消息序列化再解析，检查实际发送的JSON
+func decodePayload(t *testing.T, payload any) map[string]any {
+	data, err := json.Marshal(payload)
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	return decoded
+}
+
+func TestNewChatBotFinishMessage(t *testing.T) {
+	message := chatBotTestMessage()
+	assert.Equal(t, config.NotificationEventFailure, message.Event)
+	assert.Equal(t, "通知项目 完整迁移失败", message.Title)
+	assert.Contains(t, message.Fields, ChatBotField{Label: "进度", Value: "1/2 成功，1 失败"})
+	assert.Contains(t, message.Fields, ChatBotField{Label: "耗时", Value: "1m30s"})
+	assert.Contains(t, message.Fields, ChatBotField{Label: "备注", Value: "夜间窗口"})
+	// 错误摘要只保留第一行
+	assert.Equal(t, []string{"COPY 失败: ERROR: duplicate key"}, message.Failures)
+
+	data := NewNotificationData(notificationTestConfig(), notificationTestRecord(StatusCompleted))
+	message = NewChatBotFinishMessage(data)
+	assert.Equal(t, config.NotificationEventSuccess, message.Event)
+	assert.Empty(t, message.Failures)
+
+	// 失败项过多时合并，过长的错误截断
+	data.Failures = nil
+	for i := 0; i < chatBotMaxFailures+2; i++ {
+		data.Failures = append(data.Failures, NotificationResult{Type: "TABLE", Status: "失败", Error: strings.Repeat("错", 300)})
+	}
+	message = NewChatBotFinishMessage(data)
+	require.Len(t, message.Failures, chatBotMaxFailures+1)
+	assert.Equal(t, "另有 2 项未成功", message.Failures[chatBotMaxFailures])
+	assert.True(t, strings.HasSuffix(message.Failures[0], strings.Repeat("错", chatBotMaxErrorRunes)+"..."))
+}
+
+func TestChatBotMessageSanitized(t *testing.T) {
+	message := chatBotTestMessage()
+	message.Failures = []string{"COPY 失败: connect failed password=tiger host=db"}
+	logger := utils.NewLogger(&utils.LogConfig{Level: utils.LogLevelError, Output: "stderr"})
+
+	// 日志关闭脱敏时，发送到外部的消息仍然脱敏
+	sanitized := message.Sanitized(logger)
+	assert.Equal(t, "COPY 失败: connect failed password=***** host=db", sanitized.Failures[0])
+	assert.Contains(t, message.Failures[0], "tiger")
+}
+
+func TestDingTalkPayload(t *testing.T) {
+	message := chatBotTestMessage()
+	payload := decodePayload(t, dingTalkPayload(message, []string{"13800000000", "all"}))
+	assert.Equal(t, "markdown", payload["msgtype"])
+
+	markdown := payload["markdown"].(map[string]any)
+	assert.Equal(t, "通知项目 完整迁移失败", markdown["title"])
+	text := markdown["text"].(string)
+	assert.True(t, strings.HasPrefix(text, "### 通知项目 完整迁移失败\n\n- **运行ID**: run-42"))
+	assert.Contains(t, text, "**错误摘要**\n- COPY 失败: ERROR: duplicate key")
+	assert.Contains(t, text, "@所有人")
+	assert.Contains(t, text, "@13800000000")
+
+	at := payload["at"].(map[string]any)
+	assert.Equal(t, []any{"13800000000"}, at["atMobiles"])
+	assert.Equal(t, true, at["isAtAll"])
+}
+
+func TestDingTalkSignedURL(t *testing.T) {
+	now := time.UnixMilli(1700000000123)
+	signed, err := dingTalkSignedURL("https://oapi.dingtalk.com/robot/send?access_token=abc", "SECxyz", now)
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(signed)
+	require.NoError(t, err)
+	query := parsed.Query()
+	assert.Equal(t, "abc", query.Get("access_token"))
+	assert.Equal(t, "1700000000123", query.Get("timestamp"))
+
+	mac := hmac.New(sha256.New, []byte("SECxyz"))
+	mac.Write([]byte("1700000000123\nSECxyz"))
+	assert.Equal(t, base64.StdEncoding.EncodeToString(mac.Sum(nil)), query.Get("sign"))
+}
+
+func TestWeComPayloads(t *testing.T) {
+	message := chatBotTestMessage()
+	payloads := weComPayloads(message, nil)
+	require.Len(t, payloads, 1)
+	content := decodePayload(t, payloads[0])["markdown"].(map[string]any)["content"].(string)
+	assert.True(t, strings.HasPrefix(content, "### <font color=\"warning\">通知项目 完整迁移失败</font>\n- **运行ID**"))
+
+	// markdown 不支持@手机号，负责人通过额外的文本消息@
+	payloads = weComPayloads(message, []string{"13800000000", "zhangsan", "all"})
+	require.Len(t, payloads, 2)
+	text := decodePayload(t, payloads[1])
+	assert.Equal(t, "text", text["msgtype"])
+	body := text["text"].(map[string]any)
+	assert.Equal(t, []any{"zhangsan", "@all"}, body["mentioned_list"])
+	assert.Equal(t, []any{"13800000000"}, body["mentioned_mobile_list"])
+
+	message.Event = config.NotificationEventSuccess
+	content = decodePayload(t, weComPayloads(message, nil)[0])["markdown"].(map[string]any)["content"].(string)
+	assert.Contains(t, content, `<font color="info">`)
+}
+
+func TestFeishuPayload(t *testing.T) {
+	message := chatBotTestMessage()
+	payload := decodePayload(t, feishuPayload(message, []string{"ou_123", "all"}))
+	assert.Equal(t, "interactive", payload["msg_type"])
+
+	card := payload["card"].(map[string]any)
+	header := card["header"].(map[string]any)
+	assert.Equal(t, "red", header["template"])
+	assert.Equal(t, "通知项目 完整迁移失败", header["title"].(map[string]any)["content"])
+	element := card["elements"].([]any)[0].(map[string]any)
+	content := element["text"].(map[string]any)["content"].(string)
+	assert.Contains(t, content, "- **进度**: 1/2 成功，1 失败")
+	assert.Contains(t, content, "<at id=ou_123></at> <at id=all></at>")
+
+	// 加签：以"时间戳\n密钥"为密钥对空内容签名
+	timestamp, sign := feishuSign("secret", time.Unix(1700000000, 0))
+	assert.Equal(t, "1700000000", timestamp)
+	mac := hmac.New(sha256.New, []byte("1700000000\nsecret"))
+	assert.Equal(t, base64.StdEncoding.EncodeToString(mac.Sum(nil)), sign)
+
+	bot := &config.ChatBotConfig{Platform: "Feishu", URL: "https://open.feishu.cn/open-apis/bot/v2/hook/x", Secret: "secret"}
+	requests, err := buildChatBotRequests(bot, message, time.Unix(1700000000, 0))
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(requests[0].body, &body))
+	assert.Equal(t, "1700000000", body["timestamp"])
+	assert.Equal(t, sign, body["sign"])
+	assert.Equal(t, bot.URL, requests[0].url)
+}
+
+func TestSlackPayload(t *testing.T) {
+	message := chatBotTestMessage()
+	message.Failures = []string{"INDEX 失败: <relation> & more"}
+	payload := decodePayload(t, slackPayload(message, []string{"U123", "all"}))
+	assert.Equal(t, "通知项目 完整迁移失败", payload["text"])
+
+	blocks := payload["blocks"].([]any)
+	require.Len(t, blocks, 2)
+	text := blocks[1].(map[string]any)["text"].(map[string]any)["text"].(string)
+	assert.Contains(t, text, "- *运行ID*: run-42")
+	assert.Contains(t, text, "INDEX 失败: &lt;relation&gt; &amp; more")
+	assert.Contains(t, text, "<@U123> <!channel>")
+}
+
+func TestChatBotMentionsOnlyOnFailure(t *testing.T) {
+	bot := &config.ChatBotConfig{Platform: config.ChatBotDingTalk, Mentions: []string{" 13800000000 ", ""}}
+	message := chatBotTestMessage()
+	assert.Equal(t, []string{"13800000000"}, message.mentions(bot))
+
+	message.Event = config.NotificationEventSuccess
+	assert.Empty(t, message.mentions(bot))
+}
+
+// chatBotRecorder 记录收到的机器人消息，前 failures 次请求返回业务错误
+type chatBotRecorder struct {
+	mu       sync.Mutex
+	failures int
+	requests int
+	bodies   []string
+	queries  []url.Values
+}
+
+func (r *chatBotRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests++
+	if r.requests <= r.failures {
+		w.Write([]byte(`{"errcode":310000,"errmsg":"sign not match"}`))
+		return
+	}
+	body, _ := io.ReadAll(req.Body)
+	r.bodies = append(r.bodies, string(body))
+	r.queries = append(r.queries, req.URL.Query())
+	w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+}
+
+func TestNotifierSendChatBots(t *testing.T) {
+	recorder := &chatBotRecorder{failures: 1}
+	server := httptest.NewServer(recorder)
+	t.Cleanup(server.Close)
+
+	noRetry := 0
+	cfg := notificationTestConfig()
+	cfg.Notifications.Bots = []config.ChatBotConfig{
+		{Name: "DBA群", Platform: config.ChatBotDingTalk, URL: server.URL + "/robot/send?access_token=abc", Secret: "SEC1"},
+		{Platform: config.ChatBotWeCom, URL: server.URL, Events: []string{config.NotificationEventSuccess}},
+		{Platform: config.ChatBotSlack, URL: "http://127.0.0.1:1/hook", Retries: &noRetry},
+	}
+	notifier := NewNotifier(cfg)
+	notifier.botBackoff = time.Millisecond
+
+	// 钉钉失败一次后重试成功，企业微信只订阅成功事件，Slack 无法连接
+	errs := notifier.SendChatBots(chatBotTestMessage())
+	require.Len(t, errs, 1)
+	assert.Contains(t, utils.FormatError(errs[0]), "slack")
+	assert.NotContains(t, utils.FormatError(errs[0]), "/hook")
+
+	assert.Equal(t, 2, recorder.requests)
+	require.Len(t, recorder.bodies, 1)
+	assert.Contains(t, recorder.bodies[0], `"msgtype":"markdown"`)
+	assert.Equal(t, "abc", recorder.queries[0].Get("access_token"))
+	assert.NotEmpty(t, recorder.queries[0].Get("sign"))
+
+	// 开始事件发送给未限制事件的机器人
+	start := NewChatBotStartMessage(cfg, "数据迁移", []MigrationType{MigrationTypeTable, MigrationTypeCopy})
+	assert.Contains(t, start.Fields, ChatBotField{Label: "进度", Value: "0/2"})
+	cfg.Notifications.Bots = cfg.Notifications.Bots[:2]
+	assert.Empty(t, notifier.SendChatBots(start))
+	assert.Equal(t, 3, recorder.requests)
+	assert.Contains(t, recorder.bodies[1], "数据迁移开始")
+}
+
+func TestCheckChatBotResponse(t *testing.T) {
+	ok := &http.Response{StatusCode: http.StatusOK, Status: "200 OK"}
+	assert.NoError(t, checkChatBotResponse(config.ChatBotDingTalk, ok, []byte(`{"errcode":0,"errmsg":"ok"}`)))
+	assert.NoError(t, checkChatBotResponse(config.ChatBotFeishu, ok, []byte(`{"code":0,"msg":"success"}`)))
+	assert.NoError(t, checkChatBotResponse(config.ChatBotSlack, ok, []byte("ok")))
+
+	err := checkChatBotResponse(config.ChatBotFeishu, ok, []byte(`{"code":19021,"msg":"sign match fail"}`))
+	assert.ErrorContains(t, err, "19021")
+	err = checkChatBotResponse(config.ChatBotWeCom, ok, []byte(`{"errcode":93000,"errmsg":"invalid webhook url"}`))
+	assert.ErrorContains(t, err, "invalid webhook url")
+	err = checkChatBotResponse(config.ChatBotSlack, &http.Response{StatusCode: 404, Status: "404 Not Found"}, []byte("no_service"))
+	assert.ErrorContains(t, err, "no_service")
+}
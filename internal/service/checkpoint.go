@@ -0,0 +1,236 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// DefaultCheckpointPath 默认检查点文件路径（相对于项目根目录）
+var DefaultCheckpointPath = filepath.Join(".ora2pg-admin", "checkpoint.json")
+
+// TypeCheckpoint 单个迁移类型的检查点
+type TypeCheckpoint struct {
+	Status          ExecutionStatus `json:"status"`
+	CompletedTables []string        `json:"completed_tables"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+// Checkpoint 迁移检查点，记录类型级和表级的完成情况
+type Checkpoint struct {
+	StartTime time.Time                         `json:"start_time"`
+	UpdatedAt time.Time                         `json:"updated_at"`
+	Types     map[MigrationType]*TypeCheckpoint `json:"types"`
+}
+
+// NewCheckpoint 创建新的检查点
+func NewCheckpoint() *Checkpoint {
+	return &Checkpoint{
+		StartTime: time.Now(),
+		UpdatedAt: time.Now(),
+		Types:     make(map[MigrationType]*TypeCheckpoint),
+	}
+}
+
+// GetType 获取指定类型的检查点，不存在时创建
+func (c *Checkpoint) GetType(migrationType MigrationType) *TypeCheckpoint {
+	tc, exists := c.Types[migrationType]
+	if !exists {
+		tc = &TypeCheckpoint{Status: StatusPending}
+		c.Types[migrationType] = tc
+	}
+	return tc
+}
+
+// IsTypeCompleted 检查类型是否已完成
+func (c *Checkpoint) IsTypeCompleted(migrationType MigrationType) bool {
+	tc, exists := c.Types[migrationType]
+	return exists && tc.Status.Succeeded()
+}
+
+// MarkTableCompleted 记录表已完成，返回是否为新记录
+func (c *Checkpoint) MarkTableCompleted(migrationType MigrationType, table string) bool {
+	tc := c.GetType(migrationType)
+	for _, t := range tc.CompletedTables {
+		if t == table {
+			return false
+		}
+	}
+	tc.CompletedTables = append(tc.CompletedTables, table)
+	tc.UpdatedAt = time.Now()
+	c.UpdatedAt = tc.UpdatedAt
+	return true
+}
+
+// SetTypeStatus 设置类型状态
+func (c *Checkpoint) SetTypeStatus(migrationType MigrationType, status ExecutionStatus) {
+	tc := c.GetType(migrationType)
+	tc.Status = status
+	tc.UpdatedAt = time.Now()
+	c.UpdatedAt = tc.UpdatedAt
+}
+
+// LoadCheckpoint 从文件加载检查点
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, utils.NewError(utils.ErrorTypeMigration, "CHECKPOINT_NOT_FOUND").
+				Message("未找到迁移检查点，无法恢复").
+				Details(path).
+				Suggestion("去掉 --resume 参数重新执行完整迁移").
+				Build()
+		}
+		return nil, utils.FileErrors.ReadFailed(path, err)
+	}
+
+	checkpoint := NewCheckpoint()
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, utils.NewError(utils.ErrorTypeMigration, "CHECKPOINT_CORRUPTED").
+			Message("迁移检查点文件已损坏").
+			Details(path).
+			Cause(err).
+			Suggestion("删除检查点文件后重新执行迁移").
+			Build()
+	}
+	if checkpoint.Types == nil {
+		checkpoint.Types = make(map[MigrationType]*TypeCheckpoint)
+	}
+
+	return checkpoint, nil
+}
+
+// SaveCheckpoint 保存检查点到文件（先写临时文件再重命名，避免中断时写坏）
+func SaveCheckpoint(path string, checkpoint *Checkpoint) error {
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化检查点失败: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return utils.FileErrors.CreateFailed(filepath.Dir(path), err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return utils.FileErrors.WriteFailed(tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return utils.FileErrors.WriteFailed(path, err)
+	}
+
+	return nil
+}
+
+// TableCompletionDetector 从ora2pg输出中识别已完成的表
+//
+// 只认明确的完成行，如 "[====>] 14/14 rows (100.0%) Table COUNTRIES (14 recs/sec)"。
+// 下一张表开始并不代表上一张表已导完（并行导出时输出交错），不据此判定完成。
+// Feed 由 stdout 和 stderr 的读取协程并发调用，需加锁。
+type TableCompletionDetector struct {
+	mu        sync.Mutex
+	completed map[string]bool
+}
+
+var (
+	tableDonePattern       = regexp.MustCompile(`\(100(?:\.0+)?%\)\s+Table\s+([\w$#.]+)`)
+	tableProcessingPattern = regexp.MustCompile(`Processing\s+table:\s+([\w$#.]+)\s+\(\d+/\d+\)`)
+)
+
+// NewTableCompletionDetector 创建表完成检测器，每个迁移类型使用一个
+func NewTableCompletionDetector() *TableCompletionDetector {
+	return &TableCompletionDetector{completed: make(map[string]bool)}
+}
+
+// Feed 处理一行输出，返回本行确认完成的表，同一张表只返回一次
+func (d *TableCompletionDetector) Feed(line string) []string {
+	matches := tableDonePattern.FindStringSubmatch(line)
+	if matches == nil {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.completed[matches[1]] {
+		return nil
+	}
+	d.completed[matches[1]] = true
+	return []string{matches[1]}
+}
+
+// WriteResumeConfig 基于原始ora2pg配置生成续传配置，排除已完成的表
+func WriteResumeConfig(baseConfigPath, resumeConfigPath string, completedTables []string) error {
+	return writeDerivedConfig(baseConfigPath, resumeConfigPath, "由 ora2pg-admin 续传生成：排除已完成的表",
+		completedTables, nil, nil)
+}
+
+// writeDerivedConfig 基于原始ora2pg配置生成派生配置
+//
+// 原有的 EXCLUDE 指令与 exclude 合并为一行，drop 中的指令被去掉，extra 追加在文件末尾。
+func writeDerivedConfig(baseConfigPath, configPath, comment string, exclude []string, drop map[string]bool, extra []string) error {
+	baseFile, err := os.Open(baseConfigPath)
+	if err != nil {
+		return utils.FileErrors.ReadFailed(baseConfigPath, err)
+	}
+	defer baseFile.Close()
+
+	var builder strings.Builder
+	var existingExclude []string
+	scanner := bufio.NewScanner(baseFile)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		// 合并原有的EXCLUDE指令，避免被覆盖
+		if len(fields) > 0 && strings.EqualFold(fields[0], "EXCLUDE") {
+			existingExclude = append(existingExclude, fields[1:]...)
+			continue
+		}
+		if len(fields) > 0 && drop[ora2pgDirectiveName(fields[0])] {
+			continue
+		}
+		builder.WriteString(line)
+		builder.WriteString("\n")
+	}
+	if err := scanner.Err(); err != nil {
+		return utils.FileErrors.ReadFailed(baseConfigPath, err)
+	}
+
+	seen := make(map[string]bool)
+	var excluded []string
+	for _, table := range append(existingExclude, exclude...) {
+		if !seen[table] {
+			seen[table] = true
+			excluded = append(excluded, table)
+		}
+	}
+	sort.Strings(excluded)
+
+	builder.WriteString(fmt.Sprintf("\n# %s\n", comment))
+	if len(excluded) > 0 {
+		builder.WriteString(fmt.Sprintf("EXCLUDE %s\n", strings.Join(excluded, " ")))
+	}
+	for _, line := range extra {
+		builder.WriteString(line)
+		builder.WriteString("\n")
+	}
+
+	if err := os.WriteFile(configPath, []byte(builder.String()), 0644); err != nil {
+		return utils.FileErrors.WriteFailed(configPath, err)
+	}
+	return nil
+}
+
+// ora2pgDirectiveName 配置行第一个字段中的指令名（大写），兼容 NAME=VALUE 写法
+func ora2pgDirectiveName(field string) string {
+	name, _, _ := strings.Cut(field, "=")
+	return strings.ToUpper(name)
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableCompletionDetector(t *testing.T) {
+	detector := NewTableCompletionDetector()
+
+	// 明确的完成行
+	assert.Equal(t, []string{"COUNTRIES"},
+		detector.Feed("[========================>] 14/14 rows (100.0%) Table COUNTRIES (14 recs/sec)"))
+	// 同一张表只返回一次
+	assert.Empty(t, detector.Feed("[========================>] 14/14 rows (100.0%) Table COUNTRIES (14 recs/sec)"))
+
+	// 未完成的进度行不应判定为完成
+	assert.Empty(t, detector.Feed("[=====>     ] 500/1000 rows (50.0%) Table EMPLOYEES (250 recs/sec)"))
+
+	// 下一张表开始不代表上一张表已完成
+	assert.Empty(t, detector.Feed("Processing table: USERS (1/3)"))
+	assert.Empty(t, detector.Feed("Processing table: ORDERS (2/3)"))
+	assert.Equal(t, []string{"USERS"},
+		detector.Feed("[========================>] 10/10 rows (100.0%) Table USERS (10 recs/sec)"))
+}
+
+func TestTableCompletionDetectorConcurrentFeed(t *testing.T) {
+	detector := NewTableCompletionDetector()
+
+	// stdout 和 stderr 的读取协程并发输入同一张表的完成行，只返回一次
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var completed []string
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				tables := detector.Feed(fmt.Sprintf("[====>] 10/10 rows (100.0%%) Table T%d (10 recs/sec)", j))
+				mu.Lock()
+				completed = append(completed, tables...)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, completed, 100)
+}
+
+func TestCheckpointSaveAndLoad(t *testing.T) {
+	tempDir := t.TempDir()
+	path := filepath.Join(tempDir, ".ora2pg-admin", "checkpoint.json")
+
+	checkpoint := NewCheckpoint()
+	checkpoint.SetTypeStatus(MigrationTypeTable, StatusCompleted)
+	assert.True(t, checkpoint.MarkTableCompleted(MigrationTypeCopy, "USERS"))
+	assert.False(t, checkpoint.MarkTableCompleted(MigrationTypeCopy, "USERS"))
+	checkpoint.MarkTableCompleted(MigrationTypeCopy, "ORDERS")
+	checkpoint.SetTypeStatus(MigrationTypeCopy, StatusFailed)
+
+	require.NoError(t, SaveCheckpoint(path, checkpoint))
+
+	loaded, err := LoadCheckpoint(path)
+	require.NoError(t, err)
+	assert.True(t, loaded.IsTypeCompleted(MigrationTypeTable))
+	assert.False(t, loaded.IsTypeCompleted(MigrationTypeCopy))
+	assert.Equal(t, []string{"USERS", "ORDERS"}, loaded.GetType(MigrationTypeCopy).CompletedTables)
+}
+
+func TestLoadCheckpointMissing(t *testing.T) {
+	_, err := LoadCheckpoint(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "CHECKPOINT_NOT_FOUND")
+}
+
+func TestWriteResumeConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	basePath := filepath.Join(tempDir, "ora2pg.conf")
+	resumePath := filepath.Join(tempDir, "ora2pg.COPY.resume.conf")
+
+	base := "ORACLE_DSN=dbi:Oracle:host=localhost;sid=ORCL;port=1521\nEXCLUDE TMP_DATA\nTYPE=COPY\n"
+	require.NoError(t, os.WriteFile(basePath, []byte(base), 0644))
+
+	require.NoError(t, WriteResumeConfig(basePath, resumePath, []string{"USERS", "ORDERS", "USERS"}))
+
+	content, err := os.ReadFile(resumePath)
+	require.NoError(t, err)
+	text := string(content)
+	assert.Contains(t, text, "TYPE=COPY")
+	assert.Contains(t, text, "EXCLUDE ORDERS TMP_DATA USERS")
+	assert.Equal(t, 1, strings.Count(text, "EXCLUDE "))
+}
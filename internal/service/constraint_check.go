@@ -0,0 +1,701 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/postgres"
+)
+
+// 约束查询输出行前缀
+const (
+	constraintDefMarker     = "CON|"
+	constraintNotNullMarker = "NN|"
+	constraintPGColMarker   = "COL|"
+	constraintViolMarker    = "VIO|"
+)
+
+// ConstraintKind 约束类型
+type ConstraintKind string
+
+const (
+	ConstraintPrimaryKey ConstraintKind = "PRIMARY KEY"
+	ConstraintUnique     ConstraintKind = "UNIQUE"
+	ConstraintForeignKey ConstraintKind = "FOREIGN KEY"
+	ConstraintCheck      ConstraintKind = "CHECK"
+	ConstraintNotNull    ConstraintKind = "NOT NULL"
+)
+
+// oracleConstraintKinds Oracle constraint_type 与约束类型的对应
+var oracleConstraintKinds = map[string]ConstraintKind{
+	"P": ConstraintPrimaryKey,
+	"U": ConstraintUnique,
+	"R": ConstraintForeignKey,
+	"C": ConstraintCheck,
+}
+
+// postgresConstraintKinds pg_constraint.contype 与约束类型的对应
+var postgresConstraintKinds = map[string]ConstraintKind{
+	"p": ConstraintPrimaryKey,
+	"u": ConstraintUnique,
+	"f": ConstraintForeignKey,
+	"c": ConstraintCheck,
+}
+
+// postgresDeleteRules pg_constraint.confdeltype 与 Oracle delete_rule 的对应
+var postgresDeleteRules = map[string]string{
+	"a": "NO ACTION",
+	"r": "NO ACTION",
+	"c": "CASCADE",
+	"n": "SET NULL",
+	"d": "SET DEFAULT",
+}
+
+// ConstraintDef 一端的约束定义，名称均为该端数据字典中的名称
+type ConstraintDef struct {
+	Table      string         `json:"table"`
+	Name       string         `json:"name,omitempty"`
+	Kind       ConstraintKind `json:"kind"`
+	Columns    []string       `json:"columns"`
+	RefTable   string         `json:"ref_table,omitempty"`
+	RefColumns []string       `json:"ref_columns,omitempty"`
+	DeleteRule string         `json:"delete_rule,omitempty"`
+	Condition  string         `json:"condition,omitempty"`
+	// Validated PostgreSQL中约束已对现有数据验证（不是 NOT VALID）
+	Validated bool `json:"validated"`
+}
+
+// ConstraintDiffKind 约束差异类型
+type ConstraintDiffKind string
+
+const (
+	// ConstraintDiffMissing Oracle中的约束在PostgreSQL中不存在
+	ConstraintDiffMissing ConstraintDiffKind = "missing"
+	// ConstraintDiffMismatch 两端都有同一组列上的约束，但定义不同
+	ConstraintDiffMismatch ConstraintDiffKind = "mismatch"
+	// ConstraintDiffNotValidated PostgreSQL中的约束为 NOT VALID，现有数据未经验证
+	ConstraintDiffNotValidated ConstraintDiffKind = "not_validated"
+	// ConstraintDiffExtra 只存在于PostgreSQL中的约束
+	ConstraintDiffExtra ConstraintDiffKind = "extra"
+)
+
+// ConstraintDiff 一处约束差异
+type ConstraintDiff struct {
+	Kind     ConstraintDiffKind `json:"kind"`
+	Oracle   *ConstraintDef     `json:"oracle,omitempty"`
+	Postgres *ConstraintDef     `json:"postgres,omitempty"`
+	Detail   string             `json:"detail,omitempty"`
+	// Violations PostgreSQL中违反该约束的行数（唯一约束为重复的键数），-1 表示未检查
+	Violations int `json:"violations"`
+}
+
+// Problem 差异是否需要处理：缺失、不一致或存在违反约束的数据
+func (d *ConstraintDiff) Problem() bool {
+	return d.Kind == ConstraintDiffMissing || d.Kind == ConstraintDiffMismatch || d.Violations > 0
+}
+
+// ConstraintTableResult 单表约束对比结果
+type ConstraintTableResult struct {
+	Table  string `json:"table"`
+	Target string `json:"target"`
+	// TableMissing PostgreSQL中没有对应的表
+	TableMissing bool             `json:"table_missing,omitempty"`
+	Matched      int              `json:"matched"`
+	Diffs        []ConstraintDiff `json:"diffs,omitempty"`
+	// CheckError 数据检查失败的原因
+	CheckError string `json:"check_error,omitempty"`
+}
+
+// Problems 需要处理的差异数
+func (r *ConstraintTableResult) Problems() int {
+	count := 0
+	for i := range r.Diffs {
+		if r.Diffs[i].Problem() {
+			count++
+		}
+	}
+	return count
+}
+
+// ConstraintReport 约束对比报告
+type ConstraintReport struct {
+	OracleSchema   string                   `json:"oracle_schema"`
+	PostgresSchema string                   `json:"postgres_schema"`
+	DataChecked    bool                     `json:"data_checked"`
+	Tables         []*ConstraintTableResult `json:"tables"`
+}
+
+// HasProblems 是否存在缺失、不一致的约束、缺失的表或违反约束的数据
+func (r *ConstraintReport) HasProblems() bool {
+	for _, table := range r.Tables {
+		if table.TableMissing || table.Problems() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Count 指定类型的差异数
+func (r *ConstraintReport) Count(kind ConstraintDiffKind) int {
+	count := 0
+	for _, table := range r.Tables {
+		for _, diff := range table.Diffs {
+			if diff.Kind == kind {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// postgresTable PostgreSQL表的列和约束，键均为目标库中的名称
+type postgresTable struct {
+	notNull     map[string]bool
+	constraints []ConstraintDef
+}
+
+// ConstraintValidator 对比Oracle和PostgreSQL两端数据字典中的约束定义
+//
+// 约束名在迁移中通常会变化（如 SYS_C 开头的系统命名），两端按表、类型和列匹配，不比较约束名；
+// Oracle中的表名、列名按命名规则转换为目标库名称后再比较。
+type ConstraintValidator struct {
+	oracleRunner   *oracle.SQLPlusRunner
+	postgresRunner *postgres.PSQLRunner
+	oracleSchema   string
+	postgresSchema string
+	preserveCase   bool
+	// targetTable 按命名规则把Oracle表名转换为目标库表名
+	targetTable func(string) string
+}
+
+// NewConstraintValidator 创建约束校验器
+func NewConstraintValidator(cfg *config.ProjectConfig) *ConstraintValidator {
+	oracleSchema := cfg.Oracle.Schema
+	if oracleSchema == "" {
+		oracleSchema = cfg.Oracle.Username
+	}
+	postgresSchema := cfg.PostgreSQL.Schema
+	if postgresSchema == "" {
+		postgresSchema = "public"
+	}
+
+	return &ConstraintValidator{
+		oracleRunner:   oracle.NewSQLPlusRunner(&cfg.Oracle, &cfg.OracleClient),
+		postgresRunner: postgres.NewPSQLRunner(&cfg.PostgreSQL),
+		oracleSchema:   strings.ToUpper(strings.TrimSpace(oracleSchema)),
+		postgresSchema: postgresSchema,
+		preserveCase:   cfg.Migration.PreserveCase(),
+		targetTable:    cfg.Migration.TargetTableName,
+	}
+}
+
+// Validate 对比 tables 中各表的约束（为空时为源模式下全部表），checkData 为 true 时
+// 在PostgreSQL中检查现有数据是否满足缺失、不一致或未验证的约束
+func (v *ConstraintValidator) Validate(ctx context.Context, tables []string, checkData bool) (*ConstraintReport, error) {
+	oracleTables, err := v.oracleConstraints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	postgresTables, err := v.postgresConstraints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tables) == 0 {
+		for table := range oracleTables {
+			tables = append(tables, table)
+		}
+		sort.Strings(tables)
+	}
+
+	report := &ConstraintReport{OracleSchema: v.oracleSchema, PostgresSchema: v.postgresSchema, DataChecked: checkData}
+	for _, table := range tables {
+		table = strings.ToUpper(strings.TrimSpace(table))
+		result := v.compareTable(table, oracleTables[table], postgresTables)
+		if checkData && !result.TableMissing {
+			if err := v.checkTableData(ctx, result, postgresTables); err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				result.CheckError = err.Error()
+			}
+		}
+		report.Tables = append(report.Tables, result)
+	}
+	return report, nil
+}
+
+// compareTable 对比单表两端的约束
+func (v *ConstraintValidator) compareTable(table string, oracleDefs []ConstraintDef, postgresTables map[string]*postgresTable) *ConstraintTableResult {
+	target := v.targetTable(table)
+	result := &ConstraintTableResult{Table: table, Target: target}
+	pgTable, exists := postgresTables[target]
+	if !exists {
+		result.TableMissing = true
+		return result
+	}
+
+	used := make([]bool, len(pgTable.constraints))
+	for i := range oracleDefs {
+		oracleDef := &oracleDefs[i]
+		if oracleDef.Kind == ConstraintNotNull {
+			v.compareNotNull(result, oracleDef, pgTable)
+			continue
+		}
+
+		expected := v.targetDef(oracleDef)
+		match, exact := -1, false
+		for j := range pgTable.constraints {
+			pgDef := &pgTable.constraints[j]
+			if used[j] || !sameConstraintColumns(expected, pgDef) {
+				continue
+			}
+			if constraintDefinitionDiff(expected, pgDef) == "" {
+				match, exact = j, true
+				break
+			}
+			if match < 0 {
+				match = j
+			}
+		}
+
+		switch {
+		case match < 0:
+			result.Diffs = append(result.Diffs, ConstraintDiff{Kind: ConstraintDiffMissing, Oracle: oracleDef, Violations: -1})
+		case !exact:
+			used[match] = true
+			result.Diffs = append(result.Diffs, ConstraintDiff{
+				Kind:       ConstraintDiffMismatch,
+				Oracle:     oracleDef,
+				Postgres:   &pgTable.constraints[match],
+				Detail:     constraintDefinitionDiff(expected, &pgTable.constraints[match]),
+				Violations: -1,
+			})
+		case !pgTable.constraints[match].Validated:
+			used[match] = true
+			result.Diffs = append(result.Diffs, ConstraintDiff{Kind: ConstraintDiffNotValidated, Oracle: oracleDef, Postgres: &pgTable.constraints[match], Violations: -1})
+		default:
+			used[match] = true
+			result.Matched++
+		}
+	}
+
+	for j := range pgTable.constraints {
+		if !used[j] {
+			result.Diffs = append(result.Diffs, ConstraintDiff{Kind: ConstraintDiffExtra, Postgres: &pgTable.constraints[j], Violations: -1})
+		}
+	}
+	return result
+}
+
+// compareNotNull 对比非空约束，PostgreSQL中非空是列属性而不是单独的约束
+func (v *ConstraintValidator) compareNotNull(result *ConstraintTableResult, oracleDef *ConstraintDef, pgTable *postgresTable) {
+	column := v.targetColumn(oracleDef.Columns[0])
+	notNull, exists := pgTable.notNull[column]
+	switch {
+	case !exists:
+		result.Diffs = append(result.Diffs, ConstraintDiff{Kind: ConstraintDiffMissing, Oracle: oracleDef, Detail: fmt.Sprintf("PostgreSQL中没有列 %s", column), Violations: -1})
+	case !notNull:
+		result.Diffs = append(result.Diffs, ConstraintDiff{Kind: ConstraintDiffMissing, Oracle: oracleDef, Violations: -1})
+	default:
+		result.Matched++
+	}
+}
+
+// targetDef 把Oracle约束定义中的表名、列名转换为目标库名称
+func (v *ConstraintValidator) targetDef(def *ConstraintDef) *ConstraintDef {
+	target := *def
+	target.Table = v.targetTable(def.Table)
+	target.Columns = v.targetColumns(def.Columns)
+	target.RefColumns = v.targetColumns(def.RefColumns)
+	if def.RefTable != "" {
+		target.RefTable = v.targetTable(def.RefTable)
+	}
+	return &target
+}
+
+// targetColumn 目标库中的列名，列只做大小写转换
+func (v *ConstraintValidator) targetColumn(column string) string {
+	return postgres.TargetTableName(column, v.preserveCase)
+}
+
+// targetColumns 批量转换列名
+func (v *ConstraintValidator) targetColumns(columns []string) []string {
+	if len(columns) == 0 {
+		return nil
+	}
+	converted := make([]string, len(columns))
+	for i, column := range columns {
+		converted[i] = v.targetColumn(column)
+	}
+	return converted
+}
+
+// sameConstraintColumns 两个约束是否为同一类型且作用于同一组列
+func sameConstraintColumns(expected, actual *ConstraintDef) bool {
+	if expected.Kind != actual.Kind {
+		return false
+	}
+	if expected.Kind == ConstraintForeignKey {
+		// 外键列与被引用列一一对应，顺序有意义
+		return strings.Join(expected.Columns, ",") == strings.Join(actual.Columns, ",")
+	}
+	return columnSetKey(expected.Columns) == columnSetKey(actual.Columns)
+}
+
+// columnSetKey 不考虑顺序的列集合键
+func columnSetKey(columns []string) string {
+	sorted := append([]string(nil), columns...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// constraintDefinitionDiff 同一组列上的两个约束定义的差异，一致时返回空串
+func constraintDefinitionDiff(expected, actual *ConstraintDef) string {
+	var diffs []string
+	switch expected.Kind {
+	case ConstraintForeignKey:
+		if expected.RefTable != actual.RefTable || strings.Join(expected.RefColumns, ",") != strings.Join(actual.RefColumns, ",") {
+			diffs = append(diffs, fmt.Sprintf("引用 %s(%s)，PostgreSQL中为 %s(%s)",
+				expected.RefTable, strings.Join(expected.RefColumns, ", "), actual.RefTable, strings.Join(actual.RefColumns, ", ")))
+		}
+		if expected.DeleteRule != actual.DeleteRule {
+			diffs = append(diffs, fmt.Sprintf("ON DELETE %s，PostgreSQL中为 %s", expected.DeleteRule, actual.DeleteRule))
+		}
+	case ConstraintCheck:
+		if normalizeCheckCondition(expected.Condition) != normalizeCheckCondition(actual.Condition) {
+			diffs = append(diffs, fmt.Sprintf("检查条件不同: %s，PostgreSQL中为 %s", expected.Condition, actual.Condition))
+		}
+	}
+	return strings.Join(diffs, "；")
+}
+
+var (
+	// checkCastPattern PostgreSQL在检查条件中补充的类型转换
+	checkCastPattern = regexp.MustCompile(`::(character varying|double precision|timestamp without time zone|timestamp with time zone|[a-z_]+)(\[\])?`)
+	// checkAnyArrayPattern PostgreSQL把 IN 列表改写为 = ANY (ARRAY[...])
+	checkAnyArrayPattern = regexp.MustCompile(`=anyarray\[([^\]]*)\]`)
+	// checkSpacePattern 空白
+	checkSpacePattern = regexp.MustCompile(`\s+`)
+	// checkDecorationPattern 两端格式化方式不同的引号和括号
+	checkDecorationPattern = regexp.MustCompile(`["()]`)
+)
+
+// normalizeCheckCondition 规范化检查条件，消除两端数据字典格式化方式的差异
+func normalizeCheckCondition(condition string) string {
+	normalized := strings.ToLower(strings.TrimSpace(condition))
+	normalized = strings.TrimPrefix(normalized, "check ")
+	normalized = strings.TrimSuffix(normalized, " not valid")
+	normalized = checkCastPattern.ReplaceAllString(normalized, "")
+	normalized = checkSpacePattern.ReplaceAllString(normalized, "")
+	normalized = strings.ReplaceAll(normalized, "!=", "<>")
+	normalized = checkDecorationPattern.ReplaceAllString(normalized, "")
+	return checkAnyArrayPattern.ReplaceAllString(normalized, "in$1")
+}
+
+// oracleConstraints 查询Oracle模式下启用的约束和非空列，键为大写表名
+func (v *ConstraintValidator) oracleConstraints(ctx context.Context) (map[string][]ConstraintDef, error) {
+	output, err := v.oracleRunner.Run(ctx, v.oracleConstraintsQuery())
+	if err != nil {
+		return nil, sampleError("查询Oracle约束失败", err)
+	}
+	return parseOracleConstraints(output), nil
+}
+
+// oracleConstraintsQuery 构建查询Oracle约束的PL/SQL块
+//
+// search_condition 是 LONG 类型，只能在PL/SQL中读取；系统生成的 "列" IS NOT NULL 检查约束
+// 不单独输出，非空约束统一从 all_tab_columns.nullable 获取。
+func (v *ConstraintValidator) oracleConstraintsQuery() string {
+	owner := oracleLiteral(v.oracleSchema)
+	return fmt.Sprintf(`SET SERVEROUTPUT ON SIZE UNLIMITED FORMAT WRAPPED
+DECLARE
+  l_condition VARCHAR2(32767);
+BEGIN
+  FOR c IN (
+    SELECT k.table_name, k.constraint_name, k.constraint_type, k.delete_rule, k.search_condition,
+      (SELECT LISTAGG(cc.column_name, ',') WITHIN GROUP (ORDER BY cc.position) FROM all_cons_columns cc
+        WHERE cc.owner = k.owner AND cc.constraint_name = k.constraint_name) AS column_names,
+      r.table_name AS ref_table,
+      (SELECT LISTAGG(rc.column_name, ',') WITHIN GROUP (ORDER BY rc.position) FROM all_cons_columns rc
+        WHERE rc.owner = k.r_owner AND rc.constraint_name = k.r_constraint_name) AS ref_columns
+    FROM all_constraints k, all_constraints r
+    WHERE k.owner = %[1]s AND k.constraint_type IN ('P', 'U', 'R', 'C') AND k.status = 'ENABLED'
+      AND k.table_name NOT LIKE 'BIN$%%'
+      AND r.owner (+) = k.r_owner AND r.constraint_name (+) = k.r_constraint_name
+    ORDER BY k.table_name, k.constraint_name
+  ) LOOP
+    l_condition := REPLACE(REPLACE(c.search_condition, CHR(13), ' '), CHR(10), ' ');
+    IF c.constraint_type <> 'C' OR NOT REGEXP_LIKE(l_condition, '^\s*"[^"]+"\s+IS\s+NOT\s+NULL\s*$', 'i') THEN
+      DBMS_OUTPUT.PUT_LINE('%[2]s' || c.table_name || '|' || c.constraint_name || '|' || c.constraint_type || '|' ||
+        c.column_names || '|' || c.ref_table || '|' || c.ref_columns || '|' || c.delete_rule || '|' || l_condition);
+    END IF;
+  END LOOP;
+  FOR c IN (
+    SELECT t.table_name, t.column_name FROM all_tab_columns t
+    WHERE t.owner = %[1]s AND t.nullable = 'N'
+      AND t.table_name IN (SELECT table_name FROM all_tables WHERE owner = %[1]s)
+    ORDER BY t.table_name, t.column_id
+  ) LOOP
+    DBMS_OUTPUT.PUT_LINE('%[3]s' || c.table_name || '|' || c.column_name);
+  END LOOP;
+END;
+/`, owner, constraintDefMarker, constraintNotNullMarker)
+}
+
+// parseOracleConstraints 解析Oracle约束查询的输出
+func parseOracleConstraints(output string) map[string][]ConstraintDef {
+	tables := make(map[string][]ConstraintDef)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if rest, found := strings.CutPrefix(line, constraintNotNullMarker); found {
+			if parts := strings.Split(rest, "|"); len(parts) == 2 {
+				tables[parts[0]] = append(tables[parts[0]], ConstraintDef{Table: parts[0], Kind: ConstraintNotNull, Columns: []string{parts[1]}})
+			}
+			continue
+		}
+		rest, found := strings.CutPrefix(line, constraintDefMarker)
+		if !found {
+			continue
+		}
+		parts := strings.SplitN(rest, "|", 8)
+		if len(parts) != 8 {
+			continue
+		}
+		kind, known := oracleConstraintKinds[parts[2]]
+		if !known {
+			continue
+		}
+		def := ConstraintDef{
+			Table:      parts[0],
+			Name:       parts[1],
+			Kind:       kind,
+			Columns:    splitConstraintColumns(parts[3]),
+			RefTable:   parts[4],
+			RefColumns: splitConstraintColumns(parts[5]),
+			Condition:  strings.TrimSpace(parts[7]),
+			Validated:  true,
+		}
+		if kind == ConstraintForeignKey {
+			def.DeleteRule = parts[6]
+		}
+		tables[def.Table] = append(tables[def.Table], def)
+	}
+	return tables
+}
+
+// postgresConstraints 查询PostgreSQL目标模式下的表、非空列和约束，键为目标库表名
+func (v *ConstraintValidator) postgresConstraints(ctx context.Context) (map[string]*postgresTable, error) {
+	schema := postgres.QuoteLiteral(v.postgresSchema)
+	query := fmt.Sprintf(`SELECT '%[2]s' || c.relname || '|' || a.attname || '|' || a.attnotnull
+FROM pg_attribute a
+JOIN pg_class c ON c.oid = a.attrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = %[1]s AND c.relkind IN ('r', 'p') AND a.attnum > 0 AND NOT a.attisdropped
+ORDER BY c.relname, a.attnum;
+SELECT '%[3]s' || c.relname || '|' || con.conname || '|' || con.contype || '|' ||
+  coalesce((SELECT string_agg(a.attname, ',' ORDER BY k.ord) FROM unnest(con.conkey) WITH ORDINALITY k(attnum, ord)
+    JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum), '') || '|' ||
+  coalesce(rc.relname, '') || '|' ||
+  coalesce((SELECT string_agg(a.attname, ',' ORDER BY k.ord) FROM unnest(con.confkey) WITH ORDINALITY k(attnum, ord)
+    JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum), '') || '|' ||
+  con.confdeltype || '|' || con.convalidated || '|' ||
+  replace(replace(pg_get_constraintdef(con.oid), chr(13), ' '), chr(10), ' ')
+FROM pg_constraint con
+JOIN pg_class c ON c.oid = con.conrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+LEFT JOIN pg_class rc ON rc.oid = con.confrelid
+WHERE n.nspname = %[1]s AND con.contype IN ('p', 'u', 'f', 'c') AND con.conparentid = 0
+ORDER BY c.relname, con.conname;`, schema, constraintPGColMarker, constraintDefMarker)
+
+	output, err := v.postgresRunner.Run(ctx, query)
+	if err != nil {
+		return nil, sampleError("查询PostgreSQL约束失败", err)
+	}
+	return parsePostgresConstraints(output), nil
+}
+
+// parsePostgresConstraints 解析PostgreSQL约束查询的输出
+func parsePostgresConstraints(output string) map[string]*postgresTable {
+	tables := make(map[string]*postgresTable)
+	table := func(name string) *postgresTable {
+		if tables[name] == nil {
+			tables[name] = &postgresTable{notNull: make(map[string]bool)}
+		}
+		return tables[name]
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if rest, found := strings.CutPrefix(line, constraintPGColMarker); found {
+			if parts := strings.Split(rest, "|"); len(parts) == 3 {
+				table(parts[0]).notNull[parts[1]] = parts[2] == "t"
+			}
+			continue
+		}
+		rest, found := strings.CutPrefix(line, constraintDefMarker)
+		if !found {
+			continue
+		}
+		parts := strings.SplitN(rest, "|", 9)
+		if len(parts) != 9 {
+			continue
+		}
+		kind, known := postgresConstraintKinds[parts[2]]
+		if !known {
+			continue
+		}
+		def := ConstraintDef{
+			Table:      parts[0],
+			Name:       parts[1],
+			Kind:       kind,
+			Columns:    splitConstraintColumns(parts[3]),
+			RefTable:   parts[4],
+			RefColumns: splitConstraintColumns(parts[5]),
+			Validated:  parts[7] == "t",
+		}
+		switch kind {
+		case ConstraintForeignKey:
+			def.DeleteRule = postgresDeleteRules[parts[6]]
+		case ConstraintCheck:
+			def.Condition = strings.TrimSpace(parts[8])
+		}
+		table(def.Table).constraints = append(table(def.Table).constraints, def)
+	}
+	return tables
+}
+
+// splitConstraintColumns 拆分逗号分隔的列名
+func splitConstraintColumns(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// checkTableData 在PostgreSQL中统计违反缺失、不一致或未验证约束的数据，一张表的检查在同一个psql会话中完成
+//
+// 检查条件无法可靠地从Oracle语法转换，只检查PostgreSQL中已存在但未验证的检查约束。
+func (v *ConstraintValidator) checkTableData(ctx context.Context, result *ConstraintTableResult, postgresTables map[string]*postgresTable) error {
+	var queries []string
+	var indexes []int
+	for i := range result.Diffs {
+		diff := &result.Diffs[i]
+		// 已验证的约束由PostgreSQL保证数据满足
+		if diff.Kind == ConstraintDiffExtra && diff.Postgres.Validated {
+			continue
+		}
+		if query := v.violationQuery(result.Target, diff, postgresTables); query != "" {
+			queries = append(queries, fmt.Sprintf("SELECT '%s%d|' || (%s);", constraintViolMarker, i, query))
+			indexes = append(indexes, i)
+		}
+	}
+	if len(queries) == 0 {
+		return nil
+	}
+
+	output, err := v.postgresRunner.Run(ctx, strings.Join(queries, "\n"))
+	if err != nil {
+		return sampleError(fmt.Sprintf("检查 %s 的数据失败", result.Target), err)
+	}
+	for _, line := range strings.Split(output, "\n") {
+		rest, found := strings.CutPrefix(strings.TrimSpace(line), constraintViolMarker)
+		if !found {
+			continue
+		}
+		parts := strings.Split(rest, "|")
+		if len(parts) != 2 {
+			continue
+		}
+		index, err1 := strconv.Atoi(parts[0])
+		count, err2 := strconv.Atoi(parts[1])
+		if err1 != nil || err2 != nil || index < 0 || index >= len(result.Diffs) {
+			continue
+		}
+		result.Diffs[index].Violations = count
+	}
+	return nil
+}
+
+// violationQuery 统计违反约束的数据的查询，约束涉及的表或列在PostgreSQL中不存在时返回空串
+func (v *ConstraintValidator) violationQuery(target string, diff *ConstraintDiff, postgresTables map[string]*postgresTable) string {
+	pgTable := postgresTables[target]
+	table := postgres.QuoteIdentifier(v.postgresSchema) + "." + postgres.QuoteIdentifier(target)
+
+	if diff.Oracle == nil || (diff.Kind == ConstraintDiffNotValidated && diff.Oracle.Kind == ConstraintCheck) {
+		if diff.Postgres == nil || diff.Postgres.Kind != ConstraintCheck {
+			return ""
+		}
+		condition := strings.TrimSuffix(strings.TrimPrefix(diff.Postgres.Condition, "CHECK "), " NOT VALID")
+		return fmt.Sprintf("SELECT count(*) FROM %s WHERE NOT %s", table, condition)
+	}
+
+	expected := v.targetDef(diff.Oracle)
+	if !hasColumns(pgTable, expected.Columns) {
+		return ""
+	}
+	quoted := make([]string, len(expected.Columns))
+	notNull := make([]string, len(expected.Columns))
+	for i, column := range expected.Columns {
+		quoted[i] = postgres.QuoteIdentifier(column)
+		notNull[i] = quoted[i] + " IS NOT NULL"
+	}
+
+	switch expected.Kind {
+	case ConstraintNotNull:
+		return fmt.Sprintf("SELECT count(*) FROM %s WHERE %s IS NULL", table, quoted[0])
+	case ConstraintPrimaryKey, ConstraintUnique:
+		return fmt.Sprintf("SELECT count(*) FROM (SELECT 1 FROM %s WHERE %s GROUP BY %s HAVING count(*) > 1) d",
+			table, strings.Join(notNull, " AND "), strings.Join(quoted, ", "))
+	case ConstraintForeignKey:
+		if !hasColumns(postgresTables[expected.RefTable], expected.RefColumns) || len(expected.RefColumns) != len(quoted) {
+			return ""
+		}
+		joins := make([]string, len(quoted))
+		for i, column := range expected.RefColumns {
+			joins[i] = fmt.Sprintf("p.%s = c.%s", postgres.QuoteIdentifier(column), quoted[i])
+		}
+		return fmt.Sprintf("SELECT count(*) FROM %s c WHERE %s AND NOT EXISTS (SELECT 1 FROM %s.%s p WHERE %s)",
+			table, "c."+strings.Join(notNull, " AND c."),
+			postgres.QuoteIdentifier(v.postgresSchema), postgres.QuoteIdentifier(expected.RefTable), strings.Join(joins, " AND "))
+	}
+	return ""
+}
+
+// hasColumns 表是否存在且包含全部列
+func hasColumns(table *postgresTable, columns []string) bool {
+	if table == nil || len(columns) == 0 {
+		return false
+	}
+	for _, column := range columns {
+		if _, exists := table.notNull[column]; !exists {
+			return false
+		}
+	}
+	return true
+}
+
+// DescribeConstraint 用于显示的约束描述
+func DescribeConstraint(def *ConstraintDef) string {
+	var desc strings.Builder
+	desc.WriteString(string(def.Kind))
+	if len(def.Columns) > 0 {
+		fmt.Fprintf(&desc, " (%s)", strings.Join(def.Columns, ", "))
+	}
+	if def.Kind == ConstraintForeignKey {
+		fmt.Fprintf(&desc, " -> %s(%s)", def.RefTable, strings.Join(def.RefColumns, ", "))
+	}
+	if def.Kind == ConstraintCheck && def.Condition != "" {
+		fmt.Fprintf(&desc, " %s", def.Condition)
+	}
+	if def.Name != "" {
+		fmt.Fprintf(&desc, " [%s]", def.Name)
+	}
+	return desc.String()
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+)
+
+func TestNormalizeCheckCondition(t *testing.T) {
+	// PostgreSQL补充的类型转换、括号和 IN 列表改写不影响比对
+	assert.Equal(t, normalizeCheckCondition(`"AMOUNT" > 0`), normalizeCheckCondition("CHECK ((amount > (0)::numeric))"))
+	assert.Equal(t, normalizeCheckCondition(`STATUS IN ('A','B')`),
+		normalizeCheckCondition("CHECK (((status)::text = ANY ((ARRAY['A'::character varying, 'B'::character varying])::text[])))"))
+	assert.Equal(t, normalizeCheckCondition("qty != 0"), normalizeCheckCondition("CHECK ((qty <> 0)) NOT VALID"))
+	assert.NotEqual(t, normalizeCheckCondition("amount > 0"), normalizeCheckCondition("CHECK ((amount >= 0))"))
+}
+
+func TestConstraintValidatorWithFakeClients(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟sqlplus和psql依赖 /bin/sh")
+	}
+
+	oracleHome := t.TempDir()
+	sqlplus := `#!/bin/sh
+cat > /dev/null
+printf '%s\n' \
+  'CON|ORDERS|SYS_C001|P|ID||||' \
+  'CON|ORDERS|FK_CUST|R|CUSTOMER_ID|CUSTOMERS|ID|CASCADE|' \
+  'CON|ORDERS|CK_AMOUNT|C|AMOUNT||||"AMOUNT" > 0' \
+  'CON|ORDERS|UK_ORDER_NO|U|ORDER_NO||||' \
+  'CON|CUSTOMERS|SYS_C002|P|ID||||' \
+  'CON|ITEMS|SYS_C003|P|ID||||' \
+  'NN|ORDERS|ID' 'NN|ORDERS|CUSTOMER_ID' 'NN|ORDERS|ORDER_NO' 'NN|CUSTOMERS|ID'
+`
+	require.NoError(t, os.WriteFile(filepath.Join(oracleHome, "sqlplus"), []byte(sqlplus), 0755))
+
+	// 模拟psql：数据字典查询返回目标库结构，数据检查查询对每项返回2处违反
+	pgBin := t.TempDir()
+	psql := `#!/bin/sh
+input=$(cat)
+case "$input" in
+  *VIO*) echo "$input" | grep -o 'VIO|[0-9]*|' | sed 's/$/2/' ;;
+  *) printf '%s\n' \
+    'COL|orders|id|t' 'COL|orders|customer_id|t' 'COL|orders|order_no|f' 'COL|orders|amount|f' 'COL|customers|id|t' \
+    'CON|orders|orders_pkey|p|id||||t|PRIMARY KEY (id)' \
+    'CON|orders|orders_customer_id_fkey|f|customer_id|customers|id|a|t|FOREIGN KEY (customer_id) REFERENCES customers(id)' \
+    'CON|orders|orders_amount_check|c|amount|||a|t|CHECK ((amount > (0)::numeric))' \
+    'CON|orders|orders_note_check|c|amount|||a|t|CHECK ((amount < 1000000))' \
+    'CON|customers|customers_pkey|p|id||||t|PRIMARY KEY (id)' ;;
+esac
+`
+	require.NoError(t, os.WriteFile(filepath.Join(pgBin, "psql"), []byte(psql), 0755))
+	t.Setenv("PATH", pgBin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("约束校验")
+	cfg := manager.GetConfig()
+	cfg.Oracle.Username = "scott"
+	cfg.OracleClient = config.OracleClientConfig{Home: oracleHome, AutoDetect: false}
+
+	validator := NewConstraintValidator(cfg)
+	report, err := validator.Validate(context.Background(), nil, false)
+	require.NoError(t, err)
+	require.Len(t, report.Tables, 3)
+	assert.True(t, report.HasProblems())
+
+	customers, items, orders := report.Tables[0], report.Tables[1], report.Tables[2]
+	assert.Equal(t, 2, customers.Matched)
+	assert.Empty(t, customers.Diffs)
+	assert.True(t, items.TableMissing)
+
+	// 主键、检查约束（规范化后一致）和两个非空约束匹配，约束名不同不影响
+	assert.Equal(t, "orders", orders.Target)
+	assert.Equal(t, 4, orders.Matched)
+	require.Len(t, orders.Diffs, 4)
+	assert.Equal(t, ConstraintDiffMismatch, orders.Diffs[0].Kind)
+	assert.Equal(t, "FK_CUST", orders.Diffs[0].Oracle.Name)
+	assert.Contains(t, orders.Diffs[0].Detail, "ON DELETE CASCADE")
+	assert.Equal(t, ConstraintDiffMissing, orders.Diffs[1].Kind)
+	assert.Equal(t, ConstraintUnique, orders.Diffs[1].Oracle.Kind)
+	assert.Equal(t, ConstraintDiffMissing, orders.Diffs[2].Kind)
+	assert.Equal(t, ConstraintNotNull, orders.Diffs[2].Oracle.Kind)
+	assert.Equal(t, ConstraintDiffExtra, orders.Diffs[3].Kind)
+	assert.Equal(t, "orders_note_check", orders.Diffs[3].Postgres.Name)
+	assert.Equal(t, 3, orders.Problems())
+	assert.Equal(t, -1, orders.Diffs[1].Violations)
+
+	// 检查数据：缺失和不一致的约束统计违反数据，已验证的仅目标库约束不检查
+	report, err = validator.Validate(context.Background(), []string{"orders"}, true)
+	require.NoError(t, err)
+	require.Len(t, report.Tables, 1)
+	orders = report.Tables[0]
+	assert.Empty(t, orders.CheckError)
+	assert.Equal(t, 2, orders.Diffs[0].Violations)
+	assert.Equal(t, 2, orders.Diffs[1].Violations)
+	assert.Equal(t, 2, orders.Diffs[2].Violations)
+	assert.Equal(t, -1, orders.Diffs[3].Violations)
+}
+
+func TestConstraintViolationQuery(t *testing.T) {
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("约束校验")
+	cfg := manager.GetConfig()
+	validator := NewConstraintValidator(cfg)
+
+	tables := map[string]*postgresTable{
+		"orders":    {notNull: map[string]bool{"id": true, "customer_id": false}},
+		"customers": {notNull: map[string]bool{"id": true}},
+	}
+	fk := &ConstraintDiff{Kind: ConstraintDiffMissing, Oracle: &ConstraintDef{
+		Table: "ORDERS", Kind: ConstraintForeignKey, Columns: []string{"CUSTOMER_ID"}, RefTable: "CUSTOMERS", RefColumns: []string{"ID"},
+	}}
+	assert.Equal(t, `SELECT count(*) FROM "public"."orders" c WHERE c."customer_id" IS NOT NULL AND NOT EXISTS (SELECT 1 FROM "public"."customers" p WHERE p."id" = c."customer_id")`,
+		validator.violationQuery("orders", fk, tables))
+
+	unique := &ConstraintDiff{Kind: ConstraintDiffMissing, Oracle: &ConstraintDef{Table: "ORDERS", Kind: ConstraintUnique, Columns: []string{"ID", "CUSTOMER_ID"}}}
+	assert.Contains(t, validator.violationQuery("orders", unique, tables), `GROUP BY "id", "customer_id" HAVING count(*) > 1`)
+
+	// 列在目标库中不存在时无法检查
+	missing := &ConstraintDiff{Kind: ConstraintDiffMissing, Oracle: &ConstraintDef{Table: "ORDERS", Kind: ConstraintNotNull, Columns: []string{"NOTE"}}}
+	assert.Empty(t, validator.violationQuery("orders", missing, tables))
+
+	// NOT VALID 的检查约束使用目标库中的条件
+	check := &ConstraintDiff{Kind: ConstraintDiffNotValidated,
+		Oracle:   &ConstraintDef{Table: "ORDERS", Kind: ConstraintCheck, Columns: []string{"ID"}},
+		Postgres: &ConstraintDef{Table: "orders", Kind: ConstraintCheck, Condition: "CHECK ((id > 0)) NOT VALID"},
+	}
+	assert.Equal(t, `SELECT count(*) FROM "public"."orders" WHERE NOT ((id > 0))`, validator.violationQuery("orders", check, tables))
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ora2pg-admin/internal/postgres"
+	"ora2pg-admin/internal/utils"
+)
+
+// DefaultDeferredConstraintsPath 数据迁移期间删除的外键记录（相对于项目根目录），恢复完成后删除
+var DefaultDeferredConstraintsPath = filepath.Join(".ora2pg-admin", "deferred_constraints.json")
+
+// constraintRestoreTimeout 恢复约束的超时时间，大表验证外键需要全表扫描
+const constraintRestoreTimeout = 2 * time.Hour
+
+// LoadDeferredConstraints 加载上次未恢复的约束记录，文件不存在时返回 nil
+func LoadDeferredConstraints(path string) (*postgres.DeferredConstraints, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, utils.FileErrors.ReadFailed(path, err)
+	}
+
+	deferred := &postgres.DeferredConstraints{}
+	if err := json.Unmarshal(data, deferred); err != nil {
+		return nil, fmt.Errorf("解析约束记录失败: %v", err)
+	}
+	return deferred, nil
+}
+
+// SaveDeferredConstraints 保存约束记录（先写临时文件再重命名，避免中断时写坏）
+func SaveDeferredConstraints(path string, deferred *postgres.DeferredConstraints) error {
+	data, err := json.MarshalIndent(deferred, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化约束记录失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return utils.FileErrors.CreateFailed(filepath.Dir(path), err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return utils.FileErrors.WriteFailed(tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return utils.FileErrors.WriteFailed(path, err)
+	}
+	return nil
+}
+
+// constraintManager 目标库约束管理器
+func (ms *MigrationService) constraintManager() *postgres.ConstraintManager {
+	return postgres.NewConstraintManager(postgres.NewPSQLRunner(&ms.config.PostgreSQL), ms.config.PostgreSQL.Schema)
+}
+
+// deferConstraints 数据迁移前删除目标库外键并禁用用户触发器，失败时保留约束继续导入
+//
+// 删除前先把外键定义写入记录文件，迁移中断后下次运行或恢复时仍能重建。
+func (ms *MigrationService) deferConstraints(ctx context.Context) {
+	ms.constraintsAttempted = true
+	manager := ms.constraintManager()
+
+	deferred, err := manager.Collect(ctx)
+	if err != nil {
+		ms.logger.Warnf("查询目标库约束失败，将保留约束导入数据: %v", err)
+		return
+	}
+
+	// 上次未恢复的外键已被删除，查询不到，沿用记录一并恢复
+	leftover, err := LoadDeferredConstraints(ms.constraintsPath)
+	if err != nil {
+		ms.logger.Warnf("读取上次的约束记录失败: %v", err)
+	} else if leftover != nil {
+		ms.logger.Warnf("发现上次迁移未恢复的约束记录（%d 个外键），本次数据迁移后一并恢复", len(leftover.ForeignKeys))
+		deferred.Merge(leftover)
+	}
+	if deferred.Empty() {
+		ms.logger.Info("目标库没有需要禁用的外键和触发器")
+		return
+	}
+
+	if err := SaveDeferredConstraints(ms.constraintsPath, deferred); err != nil {
+		ms.logger.Warnf("保存约束记录失败，为避免约束丢失不再禁用约束: %v", err)
+		return
+	}
+	if err := manager.Disable(ctx, deferred); err != nil {
+		ms.logger.Warnf("禁用目标库约束失败，将保留约束导入数据: %v", err)
+		if leftover == nil {
+			os.Remove(ms.constraintsPath)
+			return
+		}
+	}
+
+	ms.deferredConstraints = deferred
+	ms.logger.Infof("数据迁移前已删除 %d 个外键，禁用 %d 张表的用户触发器",
+		len(deferred.ForeignKeys), len(deferred.TriggerTables))
+}
+
+// restoreConstraints 数据迁移后重新启用触发器、重建外键并验证现有数据
+//
+// 迁移被取消时同样需要恢复，因此不使用已取消的上下文。无法重建的外键保留在记录文件中。
+func (ms *MigrationService) restoreConstraints(ctx context.Context) {
+	deferred := ms.deferredConstraints
+	if deferred == nil {
+		return
+	}
+	ms.deferredConstraints = nil
+
+	restoreCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), constraintRestoreTimeout)
+	defer cancel()
+
+	ms.logger.Infof("数据迁移完成，开始重建 %d 个外键", len(deferred.ForeignKeys))
+	result, err := ms.constraintManager().Restore(restoreCtx, deferred)
+	ms.constraintResult, ms.constraintErr = result, err
+	if err != nil {
+		ms.logger.Errorf("恢复目标库约束失败，约束记录保留在 %s: %v", ms.constraintsPath, err)
+		return
+	}
+
+	for _, violation := range result.Violations {
+		ms.logger.Warnf("外键 %s.%s 验证失败: %s %s", violation.Table, violation.Name, violation.Reason, violation.Detail)
+	}
+	if len(result.Failed) == 0 {
+		if err := os.Remove(ms.constraintsPath); err != nil && !os.IsNotExist(err) {
+			ms.logger.Warnf("删除约束记录失败: %v", err)
+		}
+		return
+	}
+
+	remaining := &postgres.DeferredConstraints{Schema: deferred.Schema, CreatedAt: deferred.CreatedAt}
+	for _, failure := range result.Failed {
+		ms.logger.Errorf("外键 %s.%s 重建失败: %s", failure.Table, failure.Name, failure.Reason)
+		remaining.ForeignKeys = append(remaining.ForeignKeys, failure.ForeignKey)
+	}
+	if err := SaveDeferredConstraints(ms.constraintsPath, remaining); err != nil {
+		ms.logger.Warnf("保存未恢复的约束记录失败: %v", err)
+	}
+}
+
+// ConstraintResult 获取本次迁移恢复约束的结果，未禁用约束时返回 nil
+func (ms *MigrationService) ConstraintResult() (*postgres.ConstraintRestoreResult, error) {
+	return ms.constraintResult, ms.constraintErr
+}
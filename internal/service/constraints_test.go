@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+)
+
+func TestMigrationServiceDeferConstraints(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟psql依赖 /bin/sh")
+	}
+
+	// 模拟psql：只有一个外键，引用的表不存在导致无法重建
+	bin := t.TempDir()
+	script := `#!/bin/sh
+input=$(cat)
+case "$input" in
+  *pg_constraint*) printf 'emp\037fk_emp_dept\037FOREIGN KEY (deptno) REFERENCES dept(deptno)\n' ;;
+  *pg_trigger*) ;;
+  *'ADD CONSTRAINT'*) echo 'psql:<stdin>:3: ERROR:  42P01: relation "dept" does not exist'; exit 3 ;;
+esac
+`
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "psql"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("约束项目")
+	cfg := manager.GetConfig()
+	cfg.Migration.DeferConstraints = true
+
+	ms := NewMigrationService(cfg)
+	ms.constraintsPath = filepath.Join(t.TempDir(), "deferred_constraints.json")
+
+	// 未禁用约束时恢复不做任何事
+	ms.restoreConstraints(context.Background())
+	result, err := ms.ConstraintResult()
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+
+	// 禁用前记录外键定义
+	ms.deferConstraints(context.Background())
+	saved, err := LoadDeferredConstraints(ms.constraintsPath)
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	require.Len(t, saved.ForeignKeys, 1)
+
+	// 迁移已取消也要恢复；无法重建的外键保留在记录中
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ms.restoreConstraints(ctx)
+	result, err = ms.ConstraintResult()
+	require.NoError(t, err)
+	require.Len(t, result.Failed, 1)
+
+	saved, err = LoadDeferredConstraints(ms.constraintsPath)
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.Equal(t, "fk_emp_dept", saved.ForeignKeys[0].Name)
+	assert.Empty(t, saved.TriggerTables)
+
+	// 下次运行时上次未恢复的外键一并处理，全部恢复后删除记录
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "psql"), []byte("#!/bin/sh\ncat >/dev/null\n"), 0755))
+	next := NewMigrationService(cfg)
+	next.constraintsPath = ms.constraintsPath
+	next.deferConstraints(context.Background())
+	next.restoreConstraints(context.Background())
+	result, err = next.ConstraintResult()
+	require.NoError(t, err)
+	assert.Len(t, result.Validated, 1)
+	assert.NoFileExists(t, ms.constraintsPath)
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/utils"
+)
+
+// DefaultDDLSnapshotDir 默认DDL快照目录（相对于项目根目录）
+const DefaultDDLSnapshotDir = "snapshots"
+
+// DefaultDDLBatchSize 默认每个sqlplus会话导出的对象数
+const DefaultDDLBatchSize = 100
+
+// DDLSnapshotManifestFile 快照目录中的清单文件名
+const DDLSnapshotManifestFile = "manifest.json"
+
+// DDLSnapshotOptions DDL快照选项
+type DDLSnapshotOptions struct {
+	// Types 导出的对象类型，为空时导出全部支持的类型
+	Types []string
+	// BatchSize 每个sqlplus会话导出的对象数，大Schema分批导出以控制单次输出的大小
+	BatchSize int
+	// OnProgress 每导出一批后调用
+	OnProgress func(exported, total int)
+}
+
+// DDLSnapshotFailure 导出失败的对象
+type DDLSnapshotFailure struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// DDLSnapshot 一次DDL快照的清单，保存为快照目录中的 manifest.json
+type DDLSnapshot struct {
+	Schema    string               `json:"schema"`
+	CreatedAt time.Time            `json:"created_at"`
+	RunID     string               `json:"run_id"`
+	Objects   map[string]int       `json:"objects"`
+	Files     []string             `json:"files"`
+	Failures  []DDLSnapshotFailure `json:"failures,omitempty"`
+
+	// Dir 快照目录
+	Dir string `json:"-"`
+}
+
+// Total 成功导出的对象总数
+func (s *DDLSnapshot) Total() int {
+	total := 0
+	for _, count := range s.Objects {
+		total += count
+	}
+	return total
+}
+
+// DDLSnapshotDirName 快照目录名：ddl-<SCHEMA>-<时间>
+func DDLSnapshotDirName(schema string, t time.Time) string {
+	return fmt.Sprintf("ddl-%s-%s", strings.ToUpper(schema), t.Format("20060102-150405"))
+}
+
+// ddlSnapshotFileName 对象类型对应的快照文件名，如 MATERIALIZED_VIEW.sql
+func ddlSnapshotFileName(objectType string) string {
+	return strings.ReplaceAll(objectType, " ", "_") + ".sql"
+}
+
+// CreateDDLSnapshot 导出Schema中对象的DDL，按对象类型分文件保存到 dir
+//
+// 对象按名称排序，文件中不含导出时间，两次快照可以直接用 diff -r 对比；导出时间等信息记录在 manifest.json。
+// 导出过程中先写入临时目录，完成后再改名，中断时不会留下不完整的快照。
+func CreateDDLSnapshot(ctx context.Context, exporter *oracle.DDLExporter, dir string, options DDLSnapshotOptions) (*DDLSnapshot, error) {
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultDDLBatchSize
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return nil, utils.NewError(utils.ErrorTypeFile, "DDL_SNAPSHOT_EXISTS").
+			Message(fmt.Sprintf("快照目录已存在: %s", dir)).
+			Suggestion("指定其他输出目录，或删除已有的快照").
+			Build()
+	}
+
+	objects, err := exporter.ListObjects(ctx, options.Types)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, utils.NewError(utils.ErrorTypeOracle, "DDL_NO_OBJECTS").
+			Message(fmt.Sprintf("%s 中没有可导出的对象", exporter.Schema())).
+			Suggestion("确认 oracle.schema 配置的Schema名称正确").
+			Suggestion("导出其他用户的Schema时，需要 SELECT ANY DICTIONARY 或 SELECT_CATALOG_ROLE 权限才能看到其对象").
+			Build()
+	}
+
+	snapshot := &DDLSnapshot{
+		Schema:    exporter.Schema(),
+		CreatedAt: time.Now(),
+		RunID:     utils.RunID(),
+		Objects:   make(map[string]int),
+		Dir:       dir,
+	}
+	tmpDir := dir + ".partial"
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return nil, utils.FileErrors.WriteFailed(tmpDir, err)
+	}
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return nil, utils.FileErrors.CreateFailed(tmpDir, err)
+	}
+	writer := &ddlSnapshotWriter{dir: tmpDir, files: make(map[string]*os.File)}
+	if err := snapshot.export(ctx, exporter, objects, writer, options); err != nil {
+		writer.close()
+		os.RemoveAll(tmpDir)
+		return nil, err
+	}
+	if err := writer.close(); err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, utils.FileErrors.WriteFailed(tmpDir, err)
+	}
+	snapshot.Files = writer.names
+
+	if len(snapshot.Failures) == len(objects) {
+		os.RemoveAll(tmpDir)
+		return nil, snapshot.allFailedError()
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, utils.FileErrors.WriteFailed(dir, err)
+	}
+	manifestPath := filepath.Join(tmpDir, DDLSnapshotManifestFile)
+	if err := os.WriteFile(manifestPath, data, 0644); err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, utils.FileErrors.WriteFailed(manifestPath, err)
+	}
+	if err := os.Rename(tmpDir, dir); err != nil {
+		os.RemoveAll(tmpDir)
+		return nil, utils.FileErrors.WriteFailed(dir, err)
+	}
+	return snapshot, nil
+}
+
+// export 分批导出对象的DDL并写入文件
+func (s *DDLSnapshot) export(ctx context.Context, exporter *oracle.DDLExporter, objects []oracle.DDLObject, writer *ddlSnapshotWriter, options DDLSnapshotOptions) error {
+	for start := 0; start < len(objects); start += options.BatchSize {
+		if ctx.Err() != nil {
+			return utils.NewError(utils.ErrorTypeOracle, "DDL_EXPORT_CANCELLED").
+				Message("DDL导出被中断").
+				Cause(ctx.Err()).
+				Suggestion("大Schema导出耗时较长时可增大 --timeout，或用 --types 分类型导出").
+				Build()
+		}
+		end := start + options.BatchSize
+		if end > len(objects) {
+			end = len(objects)
+		}
+		results, err := exporter.Export(ctx, objects[start:end])
+		if err != nil {
+			return err
+		}
+		for _, result := range results {
+			if result.Error != "" {
+				s.Failures = append(s.Failures, DDLSnapshotFailure{Type: result.Type, Name: result.Name, Error: result.Error})
+				continue
+			}
+			if err := writer.write(result); err != nil {
+				return err
+			}
+			s.Objects[result.Type]++
+		}
+		if options.OnProgress != nil {
+			options.OnProgress(end, len(objects))
+		}
+	}
+	return nil
+}
+
+// allFailedError 全部对象导出失败的错误，通常是缺少访问其他Schema元数据的权限
+func (s *DDLSnapshot) allFailedError() error {
+	first := s.Failures[0]
+	builder := utils.NewError(utils.ErrorTypeOracle, "DDL_EXPORT_FAILED").
+		Message(fmt.Sprintf("%s 的 %d 个对象全部导出失败", s.Schema, len(s.Failures))).
+		Details(fmt.Sprintf("%s %s: %s", first.Type, first.Name, first.Error))
+	if strings.Contains(first.Error, "ORA-31603") {
+		builder = builder.Suggestion("导出其他用户的Schema需要 SELECT_CATALOG_ROLE 角色，请DBA授予后重试")
+	}
+	return builder.Suggestion("运行 'ora2pg-admin 检查 连接' 确认源库连接和权限").Build()
+}
+
+// ddlSnapshotWriter 按对象类型追加写入快照文件
+type ddlSnapshotWriter struct {
+	dir   string
+	files map[string]*os.File
+	names []string
+}
+
+// write 将一个对象的DDL追加到其类型的文件
+func (w *ddlSnapshotWriter) write(result oracle.DDLResult) error {
+	file, exists := w.files[result.Type]
+	if !exists {
+		name := ddlSnapshotFileName(result.Type)
+		var err error
+		file, err = os.Create(filepath.Join(w.dir, name))
+		if err != nil {
+			return utils.FileErrors.CreateFailed(filepath.Join(w.dir, name), err)
+		}
+		w.files[result.Type] = file
+		w.names = append(w.names, name)
+	}
+	if _, err := fmt.Fprintf(file, "-- %s %s\n%s\n\n", result.Type, result.Name, result.DDL); err != nil {
+		return utils.FileErrors.WriteFailed(file.Name(), err)
+	}
+	return nil
+}
+
+// close 关闭所有快照文件，返回第一个关闭错误
+func (w *ddlSnapshotWriter) close() error {
+	var firstErr error
+	for objectType, file := range w.files {
+		if err := file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(w.files, objectType)
+	}
+	return firstErr
+}
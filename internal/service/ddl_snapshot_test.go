@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/utils"
+)
+
+// fakeDDLExporter 模拟sqlplus的DDL导出器：列出 DEPT、EMP 两张表和视图 V_EMP，名称匹配 failing 的对象导出失败
+func fakeDDLExporter(t *testing.T, failing string) *oracle.DDLExporter {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟sqlplus依赖 /bin/sh")
+	}
+
+	home := t.TempDir()
+	script := `#!/bin/sh
+input=$(cat)
+case "$input" in
+  *"FROM all_objects"*) printf 'OBJ|TABLE|DEPT\nOBJ|TABLE|EMP\nOBJ|VIEW|V_EMP\n'; exit 0 ;;
+esac
+echo "$input" | sed -n "s/^  emit('\([^']*\)', '\([^']*\)');$/\1 \2/p" | while read type name; do
+  case "$name" in
+    ` + failing + `) echo "@@DDL|E|$type|$name|ORA-31603: object \"$name\" of type $type not found"; continue ;;
+  esac
+  echo "@@DDL|B|$type|$name"
+  echo "@@DDL|L|"
+  echo "@@DDL|L|  CREATE $type \"SCOTT\".\"$name\" (\"ID\" NUMBER);"
+  echo "@@DDL|Z"
+done
+echo "@@DDL|DONE"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(home, "sqlplus"), []byte(script), 0755))
+	runner := oracle.NewSQLPlusRunner(&config.OracleConfig{
+		Host:     "localhost",
+		Port:     1521,
+		Service:  "ORCL",
+		Username: "scott",
+		Password: "tiger",
+	}, &config.OracleClientConfig{Home: home, AutoDetect: false})
+	return oracle.NewDDLExporter(runner, "scott", oracle.DDLOptions{})
+}
+
+func TestDDLSnapshotDirName(t *testing.T) {
+	assert.Equal(t, "ddl-SCOTT-20240102-150405", DDLSnapshotDirName("scott", time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local)))
+	assert.Equal(t, "MATERIALIZED_VIEW.sql", ddlSnapshotFileName("MATERIALIZED VIEW"))
+}
+
+func TestCreateDDLSnapshot(t *testing.T) {
+	exporter := fakeDDLExporter(t, "V_EMP")
+	dir := filepath.Join(t.TempDir(), "snapshots", "ddl-SCOTT")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var progress []int
+	snapshot, err := CreateDDLSnapshot(ctx, exporter, dir, DDLSnapshotOptions{
+		BatchSize:  2,
+		OnProgress: func(exported, total int) { progress = append(progress, exported, total) },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 3, 3}, progress)
+	assert.Equal(t, "SCOTT", snapshot.Schema)
+	assert.Equal(t, map[string]int{"TABLE": 2}, snapshot.Objects)
+	assert.Equal(t, 2, snapshot.Total())
+	assert.Equal(t, []string{"TABLE.sql"}, snapshot.Files)
+	require.Len(t, snapshot.Failures, 1)
+	assert.Equal(t, "V_EMP", snapshot.Failures[0].Name)
+
+	// 按类型分文件，对象按名称排序
+	data, err := os.ReadFile(filepath.Join(dir, "TABLE.sql"))
+	require.NoError(t, err)
+	assert.Equal(t, "-- TABLE DEPT\nCREATE TABLE \"SCOTT\".\"DEPT\" (\"ID\" NUMBER);\n\n"+
+		"-- TABLE EMP\nCREATE TABLE \"SCOTT\".\"EMP\" (\"ID\" NUMBER);\n\n", string(data))
+	assert.NoFileExists(t, filepath.Join(dir, "VIEW.sql"))
+	assert.NoDirExists(t, dir+".partial")
+
+	var manifest DDLSnapshot
+	data, err = os.ReadFile(filepath.Join(dir, DDLSnapshotManifestFile))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	assert.Equal(t, snapshot.Objects, manifest.Objects)
+	assert.Equal(t, utils.RunID(), manifest.RunID)
+	require.Len(t, manifest.Failures, 1)
+
+	// 已存在的快照不会被覆盖
+	_, err = CreateDDLSnapshot(ctx, exporter, dir, DDLSnapshotOptions{})
+	assert.Equal(t, "DDL_SNAPSHOT_EXISTS", utils.GetErrorCode(err))
+}
+
+func TestCreateDDLSnapshotAllFailed(t *testing.T) {
+	exporter := fakeDDLExporter(t, "*")
+	dir := filepath.Join(t.TempDir(), "ddl-SCOTT")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := CreateDDLSnapshot(ctx, exporter, dir, DDLSnapshotOptions{})
+	require.Error(t, err)
+	assert.Equal(t, "DDL_EXPORT_FAILED", utils.GetErrorCode(err))
+	assert.Contains(t, utils.FormatError(err), "SELECT_CATALOG_ROLE")
+	assert.NoDirExists(t, dir)
+	assert.NoDirExists(t, dir+".partial")
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// DefaultRunsDir 后台运行记录目录（相对于项目根目录），每次运行一个状态文件和一个输出日志
+var DefaultRunsDir = filepath.Join(".ora2pg-admin", "runs")
+
+// DetachedEnv 标记进程是后台运行的迁移，值为运行记录目录
+const DetachedEnv = "ORA2PG_ADMIN_DETACHED"
+
+// 后台运行的状态
+const (
+	RunStateRunning   = "running"
+	RunStateCompleted = "completed"
+	RunStateFailed    = "failed"
+	// RunStateLost 状态仍为运行中但进程已不存在，如被强制杀死、机器重启
+	RunStateLost = "lost"
+)
+
+// RunProgress 后台运行的最近一次进度
+type RunProgress struct {
+	Task       string    `json:"task"`
+	Step       int       `json:"step"`
+	TotalSteps int       `json:"total_steps"`
+	Message    string    `json:"message"`
+	Percentage float64   `json:"percentage"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// DetachedRun 后台运行的迁移
+type DetachedRun struct {
+	RunID   string `json:"run_id"`
+	PID     int    `json:"pid"`
+	Host    string `json:"host"`
+	Command string `json:"command"`
+	// Args 命令行参数，敏感参数已脱敏
+	Args      []string   `json:"args"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	Status    string     `json:"status"`
+	ExitCode  *int       `json:"exit_code,omitempty"`
+	// StopRequested 已通过停止命令请求终止
+	StopRequested bool         `json:"stop_requested,omitempty"`
+	LogFile       string       `json:"log_file"`
+	Progress      *RunProgress `json:"progress,omitempty"`
+
+	dir string
+}
+
+// runFilePath 运行记录文件路径
+func runFilePath(dir, runID string) string {
+	return filepath.Join(dir, runID+".json")
+}
+
+// StartDetached 在后台启动 executable args，输出写入运行日志，立即返回运行记录
+//
+// 子进程脱离当前终端和进程组（Unix 下新建会话，Windows 下不关联控制台），当前命令退出后继续运行；
+// 子进程通过环境变量沿用运行ID，并在结束时更新运行记录。
+func StartDetached(dir, executable string, args []string, command string) (*DetachedRun, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, utils.FileErrors.CreateFailed(dir, err)
+	}
+	host, _ := os.Hostname()
+	run := &DetachedRun{
+		RunID:     utils.RunID(),
+		Host:      host,
+		Command:   command,
+		Args:      utils.SanitizeAuditArgs(args),
+		StartedAt: time.Now(),
+		Status:    RunStateRunning,
+		LogFile:   filepath.Join(dir, utils.RunID()+".log"),
+		dir:       dir,
+	}
+
+	logFile, err := os.OpenFile(run.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, utils.FileErrors.CreateFailed(run.LogFile, err)
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(executable, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.Env = append(os.Environ(), utils.RunIDEnv+"="+run.RunID, DetachedEnv+"="+dir)
+	cmd.SysProcAttr = detachedProcAttr()
+
+	// 先写入运行记录，子进程启动后即可读取并更新
+	if err := run.Save(); err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		os.Remove(runFilePath(dir, run.RunID))
+		return nil, utils.NewError(utils.ErrorTypeSystem, "DETACH_START_FAILED").
+			Message("无法在后台启动迁移").
+			Details(err.Error()).
+			Cause(err).
+			Build()
+	}
+	run.PID = cmd.Process.Pid
+	if err := run.Save(); err != nil {
+		return nil, err
+	}
+	// 不等待子进程，释放句柄
+	cmd.Process.Release()
+	return run, nil
+}
+
+// LoadDetachedRun 读取后台运行记录，runID 可以是唯一的前缀
+func LoadDetachedRun(dir, runID string) (*DetachedRun, error) {
+	runID = strings.TrimSpace(runID)
+	path := runFilePath(dir, runID)
+	if _, err := os.Stat(path); err != nil {
+		matches, _ := filepath.Glob(filepath.Join(dir, runID+"*.json"))
+		switch {
+		case runID == "" || len(matches) == 0:
+			return nil, utils.NewError(utils.ErrorTypeUser, "RUN_NOT_FOUND").
+				Message(fmt.Sprintf("没有运行ID为 %s 的后台迁移", runID)).
+				Suggestion("运行 'ora2pg-admin 迁移 状态' 列出后台运行的迁移").
+				Build()
+		case len(matches) > 1:
+			return nil, utils.NewError(utils.ErrorTypeUser, "RUN_ID_AMBIGUOUS").
+				Message(fmt.Sprintf("运行ID前缀 %s 匹配了 %d 个后台迁移", runID, len(matches))).
+				Suggestion("输入更完整的运行ID").
+				Build()
+		}
+		path = matches[0]
+	}
+	return readDetachedRun(path, dir)
+}
+
+// readDetachedRun 读取运行记录文件
+func readDetachedRun(path, dir string) (*DetachedRun, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, utils.FileErrors.ReadFailed(path, err)
+	}
+	run := &DetachedRun{}
+	if err := json.Unmarshal(data, run); err != nil {
+		return nil, utils.NewError(utils.ErrorTypeFile, "RUN_RECORD_INVALID").
+			Message("后台运行记录已损坏").
+			Details(fmt.Sprintf("%s: %v", path, err)).
+			Cause(err).
+			Build()
+	}
+	run.dir = dir
+	return run, nil
+}
+
+// ListDetachedRuns 列出后台运行记录，按开始时间倒序
+func ListDetachedRuns(dir string) ([]*DetachedRun, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]*DetachedRun, 0, len(paths))
+	for _, path := range paths {
+		run, err := readDetachedRun(path, dir)
+		if err != nil {
+			utils.GetGlobalLogger().Warnf("跳过无法读取的后台运行记录 %s: %v", path, err)
+			continue
+		}
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+	return runs, nil
+}
+
+// Save 写入运行记录，先写临时文件再重命名，读取方不会读到写了一半的内容
+func (r *DetachedRun) Save() error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化后台运行记录失败: %v", err)
+	}
+	path := runFilePath(r.dir, r.RunID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return utils.FileErrors.WriteFailed(tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return utils.FileErrors.WriteFailed(path, err)
+	}
+	return nil
+}
+
+// State 运行状态，记录为运行中但本机进程已不存在时为 RunStateLost
+func (r *DetachedRun) State() string {
+	if r.Status != RunStateRunning {
+		return r.Status
+	}
+	host, _ := os.Hostname()
+	if r.PID > 0 && r.Host == host && !processAlive(r.PID) {
+		return RunStateLost
+	}
+	return RunStateRunning
+}
+
+// Alive 后台进程是否仍在运行
+func (r *DetachedRun) Alive() bool {
+	return r.State() == RunStateRunning
+}
+
+// UpdateProgress 记录最近一次进度，写入失败时仅记录警告
+func (r *DetachedRun) UpdateProgress(progress RunProgress) {
+	r.Progress = &progress
+	r.mergeStopRequested()
+	if err := r.Save(); err != nil {
+		utils.GetGlobalLogger().Warnf("更新后台运行进度失败: %v", err)
+	}
+}
+
+// DetachedProgressInterval 记录后台运行进度的最小间隔，步骤完成时立即记录
+const DetachedProgressInterval = time.Second
+
+// ProgressHandler 把进度跟踪器的更新记录到运行记录，供 'ora2pg-admin 迁移 状态' 查看
+//
+// 回调在进度跟踪器持有锁时调用，按 DetachedProgressInterval 限制写入频率。
+func (r *DetachedRun) ProgressHandler(task string, totalSteps int) func(ProgressUpdate) {
+	var mu sync.Mutex
+	var last time.Time
+	return func(update ProgressUpdate) {
+		mu.Lock()
+		defer mu.Unlock()
+		now := time.Now()
+		if !update.Completed && now.Sub(last) < DetachedProgressInterval {
+			return
+		}
+		last = now
+		r.UpdateProgress(RunProgress{
+			Task:       task,
+			Step:       update.Step,
+			TotalSteps: totalSteps,
+			Message:    update.Message,
+			Percentage: update.Percentage,
+			UpdatedAt:  now,
+		})
+	}
+}
+
+// Finish 后台进程结束时记录退出码
+func (r *DetachedRun) Finish(exitCode int) error {
+	r.mergeStopRequested()
+	now := time.Now()
+	r.EndedAt = &now
+	r.ExitCode = &exitCode
+	r.Status = RunStateCompleted
+	if exitCode != 0 {
+		r.Status = RunStateFailed
+	}
+	return r.Save()
+}
+
+// mergeStopRequested 后台进程写入记录前重新读取，保留停止命令写入的标记
+func (r *DetachedRun) mergeStopRequested() {
+	if latest, err := readDetachedRun(runFilePath(r.dir, r.RunID), r.dir); err == nil && latest.StopRequested {
+		r.StopRequested = true
+	}
+}
+
+// Stop 请求后台进程终止，kill 为 true 时强制杀死；Unix 下先发送 SIGTERM，迁移会像按 Ctrl+C 一样停止
+func (r *DetachedRun) Stop(kill bool) error {
+	if !r.Alive() {
+		return utils.NewError(utils.ErrorTypeUser, "RUN_NOT_RUNNING").
+			Message(fmt.Sprintf("后台迁移 %s 已不在运行", r.RunID)).
+			Details("状态: " + r.State()).
+			Build()
+	}
+	host, _ := os.Hostname()
+	if r.Host != host {
+		return utils.NewError(utils.ErrorTypeUser, "RUN_ON_OTHER_HOST").
+			Message(fmt.Sprintf("后台迁移 %s 运行在主机 %s 上", r.RunID, r.Host)).
+			Suggestion("请在该主机上执行停止命令").
+			Build()
+	}
+	if err := stopProcess(r.PID, kill); err != nil {
+		return utils.NewError(utils.ErrorTypeSystem, "RUN_STOP_FAILED").
+			Message(fmt.Sprintf("无法终止后台迁移进程 %d", r.PID)).
+			Details(err.Error()).
+			Cause(err).
+			Build()
+	}
+	r.StopRequested = true
+	return r.Save()
+}
+
+// DetachedRunFromEnv 当前进程是后台运行的迁移时返回其运行记录
+func DetachedRunFromEnv() (*DetachedRun, bool) {
+	dir := os.Getenv(DetachedEnv)
+	if dir == "" {
+		return nil, false
+	}
+	run, err := readDetachedRun(runFilePath(dir, utils.RunID()), dir)
+	if err != nil {
+		utils.GetGlobalLogger().Warnf("读取后台运行记录失败: %v", err)
+		return nil, false
+	}
+	return run, true
+}
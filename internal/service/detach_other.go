@@ -0,0 +1,18 @@
+//go:build plan9
+
+package service
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// detachedProcAttr 不支持脱离进程组，后台进程仍随当前会话结束
+func detachedProcAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+// stopProcess 不支持终止后台进程
+func stopProcess(pid int, kill bool) error {
+	return fmt.Errorf("当前平台不支持终止后台进程")
+}
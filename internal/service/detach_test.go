@@ -0,0 +1,131 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/utils"
+)
+
+func TestDetachedRunRecords(t *testing.T) {
+	dir := t.TempDir()
+	host, _ := os.Hostname()
+	now := time.Now()
+
+	older := &DetachedRun{RunID: "20240102-020000-aaaaaa", PID: exitedPID(t), Host: host, StartedAt: now.Add(-time.Hour), Status: RunStateRunning, dir: dir}
+	newer := &DetachedRun{RunID: "20240102-030000-bbbbbb", PID: os.Getpid(), Host: host, StartedAt: now, Status: RunStateRunning, dir: dir}
+	require.NoError(t, older.Save())
+	require.NoError(t, newer.Save())
+
+	runs, err := ListDetachedRuns(dir)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, newer.RunID, runs[0].RunID)
+
+	// 进程已不存在但记录为运行中
+	assert.Equal(t, RunStateLost, runs[1].State())
+	assert.False(t, runs[1].Alive())
+	assert.Equal(t, RunStateRunning, runs[0].State())
+
+	// 按唯一前缀查找
+	run, err := LoadDetachedRun(dir, "20240102-03")
+	require.NoError(t, err)
+	assert.Equal(t, newer.RunID, run.RunID)
+
+	_, err = LoadDetachedRun(dir, "20240102")
+	assert.Equal(t, "RUN_ID_AMBIGUOUS", utils.GetErrorCode(err))
+	_, err = LoadDetachedRun(dir, "nothing")
+	assert.Equal(t, "RUN_NOT_FOUND", utils.GetErrorCode(err))
+	_, err = LoadDetachedRun(dir, "")
+	assert.Equal(t, "RUN_NOT_FOUND", utils.GetErrorCode(err))
+
+	// 已不在运行的迁移不能停止
+	err = runs[1].Stop(false)
+	assert.Equal(t, "RUN_NOT_RUNNING", utils.GetErrorCode(err))
+}
+
+func TestDetachedRunFinish(t *testing.T) {
+	dir := t.TempDir()
+	run := &DetachedRun{RunID: "20240102-020000-aaaaaa", PID: os.Getpid(), StartedAt: time.Now(), Status: RunStateRunning, dir: dir}
+	require.NoError(t, run.Save())
+
+	// 停止命令在另一进程中写入标记，后台进程结束时保留
+	stopper, err := LoadDetachedRun(dir, run.RunID)
+	require.NoError(t, err)
+	stopper.StopRequested = true
+	require.NoError(t, stopper.Save())
+
+	require.NoError(t, run.Finish(1))
+	finished, err := LoadDetachedRun(dir, run.RunID)
+	require.NoError(t, err)
+	assert.Equal(t, RunStateFailed, finished.State())
+	assert.True(t, finished.StopRequested)
+	require.NotNil(t, finished.ExitCode)
+	assert.Equal(t, 1, *finished.ExitCode)
+	assert.NotNil(t, finished.EndedAt)
+
+	run = &DetachedRun{RunID: "20240102-030000-bbbbbb", Status: RunStateRunning, dir: dir}
+	require.NoError(t, run.Save())
+	require.NoError(t, run.Finish(0))
+	assert.Equal(t, RunStateCompleted, run.State())
+}
+
+func TestDetachedRunProgressHandler(t *testing.T) {
+	dir := t.TempDir()
+	run := &DetachedRun{RunID: "20240102-020000-aaaaaa", Status: RunStateRunning, dir: dir}
+	require.NoError(t, run.Save())
+
+	handler := run.ProgressHandler("完整迁移", 3)
+	handler(ProgressUpdate{Step: 1, Message: "TABLE", Percentage: 10})
+	// 间隔内的更新不写入，步骤完成时立即写入
+	handler(ProgressUpdate{Step: 1, Message: "TABLE", Percentage: 20})
+	loaded, err := LoadDetachedRun(dir, run.RunID)
+	require.NoError(t, err)
+	require.NotNil(t, loaded.Progress)
+	assert.Equal(t, 10.0, loaded.Progress.Percentage)
+
+	handler(ProgressUpdate{Step: 1, Message: "TABLE 完成", Percentage: 33.3, Completed: true})
+	loaded, err = LoadDetachedRun(dir, run.RunID)
+	require.NoError(t, err)
+	assert.Equal(t, "完整迁移", loaded.Progress.Task)
+	assert.Equal(t, 3, loaded.Progress.TotalSteps)
+	assert.Equal(t, "TABLE 完成", loaded.Progress.Message)
+}
+
+func TestStartDetached(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟后台进程依赖 /bin/sh")
+	}
+
+	dir := filepath.Join(t.TempDir(), "runs")
+	script := `echo "run $` + utils.RunIDEnv + `"; test -n "$` + DetachedEnv + `" && echo detached; sleep 30`
+	run, err := StartDetached(dir, "/bin/sh", []string{"-c", script}, "测试")
+	require.NoError(t, err)
+	assert.Equal(t, utils.RunID(), run.RunID)
+	assert.Greater(t, run.PID, 0)
+
+	loaded, err := LoadDetachedRun(dir, run.RunID)
+	require.NoError(t, err)
+	assert.Equal(t, run.PID, loaded.PID)
+	assert.Equal(t, RunStateRunning, loaded.State())
+
+	// 输出写入运行日志，子进程沿用运行ID
+	require.Eventually(t, func() bool {
+		data, _ := os.ReadFile(run.LogFile)
+		return strings.Contains(string(data), "detached")
+	}, 5*time.Second, 20*time.Millisecond)
+	data, err := os.ReadFile(run.LogFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "run "+run.RunID)
+
+	require.NoError(t, loaded.Stop(true))
+	stopped, err := LoadDetachedRun(dir, run.RunID)
+	require.NoError(t, err)
+	assert.True(t, stopped.StopRequested)
+}
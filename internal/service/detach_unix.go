@@ -0,0 +1,19 @@
+//go:build !windows && !plan9
+
+package service
+
+import "syscall"
+
+// detachedProcAttr 后台进程新建会话，脱离当前终端，关闭终端时不会收到 SIGHUP
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+// stopProcess 发送 SIGTERM 让迁移自行停止，kill 为 true 时发送 SIGKILL
+func stopProcess(pid int, kill bool) error {
+	signal := syscall.SIGTERM
+	if kill {
+		signal = syscall.SIGKILL
+	}
+	return syscall.Kill(pid, signal)
+}
@@ -0,0 +1,27 @@
+//go:build windows
+
+package service
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// detachedProcAttr 后台进程不关联控制台并使用新的进程组，关闭命令行窗口时继续运行
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		CreationFlags: windows.DETACHED_PROCESS | windows.CREATE_NEW_PROCESS_GROUP,
+		HideWindow:    true,
+	}
+}
+
+// stopProcess 终止进程；Windows 无法向不关联控制台的进程发送中断信号，总是强制终止
+func stopProcess(pid int, kill bool) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return process.Kill()
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/utils"
+)
+
+// outputEncodings 支持转换的输出文件编码，键为规范化后的名称
+var outputEncodings = map[string]encoding.Encoding{
+	"utf8":        nil,
+	"gbk":         simplifiedchinese.GBK,
+	"gb2312":      simplifiedchinese.GBK,
+	"gb18030":     simplifiedchinese.GB18030,
+	"big5":        traditionalchinese.Big5,
+	"shiftjis":    japanese.ShiftJIS,
+	"eucjp":       japanese.EUCJP,
+	"euckr":       korean.EUCKR,
+	"latin1":      charmap.ISO8859_1,
+	"iso88591":    charmap.ISO8859_1,
+	"windows1252": charmap.Windows1252,
+}
+
+// oracleCharsetEncodings Oracle数据库字符集对应的输出编码
+var oracleCharsetEncodings = map[string]string{
+	"AL32UTF8":       "utf-8",
+	"UTF8":           "utf-8",
+	"ZHS16GBK":       "gbk",
+	"ZHS16CGB231280": "gb2312",
+	"ZHS32GB18030":   "gb18030",
+	"ZHT16BIG5":      "big5",
+	"ZHT16MSWIN950":  "big5",
+	"JA16SJIS":       "shift_jis",
+	"JA16EUC":        "euc-jp",
+	"KO16KSC5601":    "euc-kr",
+	"KO16MSWIN949":   "euc-kr",
+	"WE8ISO8859P1":   "latin1",
+	"WE8MSWIN1252":   "windows-1252",
+}
+
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+// maxReportedEncodingFiles 警告中最多列出的文件数
+const maxReportedEncodingFiles = 5
+
+// normalizeEncodingName 规范化编码名称，忽略大小写、"-" 和 "_"
+func normalizeEncodingName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer("-", "", "_", "").Replace(name)
+}
+
+// LookupOutputEncoding 查找输出编码，空字符串表示只检测不转换
+func LookupOutputEncoding(name string) (encoding.Encoding, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, nil
+	}
+	enc, ok := outputEncodings[normalizeEncodingName(name)]
+	if !ok {
+		return nil, utils.NewError(utils.ErrorTypeConfig, "UNSUPPORTED_OUTPUT_ENCODING").
+			Message(fmt.Sprintf("不支持的输出文件编码: %s", name)).
+			Suggestion("migration.output_encoding 可选: utf-8、gbk、gb18030、big5、shift_jis、euc-jp、euc-kr、latin1、windows-1252").
+			Build()
+	}
+	return enc, nil
+}
+
+// OracleCharsetEncoding 获取Oracle数据库字符集对应的输出编码，未知时返回空字符串
+func OracleCharsetEncoding(charset string) string {
+	return oracleCharsetEncodings[strings.ToUpper(strings.TrimSpace(charset))]
+}
+
+// EncodingConversion 单个文件的编码检测和转换结果
+type EncodingConversion struct {
+	File      string
+	Converted bool   // 需要转换为UTF-8（包括去除BOM）
+	FromBOM   string // 按BOM识别的编码
+	NotUTF8   bool   // 未指定源编码且内容不是UTF-8
+}
+
+// DecodeToUTF8 把内容转换为UTF-8
+//
+// 带BOM的内容按BOM识别（UTF-8 BOM 直接去除，UTF-16 解码）；已是UTF-8的内容原样返回；
+// 其他内容按 source 解码，source 为nil时只检测。存在无法按 source 解码的字节时返回错误，
+// 避免把乱码写入目标库。
+func DecodeToUTF8(path string, data []byte, source encoding.Encoding) ([]byte, *EncodingConversion, error) {
+	result := &EncodingConversion{File: path}
+
+	switch {
+	case bytes.HasPrefix(data, utf8BOM):
+		result.FromBOM = "UTF-8 BOM"
+		result.Converted = true
+		return data[len(utf8BOM):], result, nil
+	case bytes.HasPrefix(data, utf16LEBOM), bytes.HasPrefix(data, utf16BEBOM):
+		// ExpectBOM 按BOM确定字节序
+		result.FromBOM = "UTF-16"
+		source = unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)
+	case utf8.Valid(data):
+		return data, result, nil
+	case source == nil:
+		result.NotUTF8 = true
+		return data, result, nil
+	}
+
+	converted, err := source.NewDecoder().Bytes(data)
+	if err != nil {
+		return nil, nil, encodingConversionError(path, err.Error())
+	}
+	// 解码器把无法识别的字节替换为U+FFFD，原文不是合法UTF-8，不会本来就包含该字符
+	if index := bytes.IndexRune(converted, utf8.RuneError); index >= 0 {
+		line := bytes.Count(converted[:index], []byte("\n")) + 1
+		return nil, nil, encodingConversionError(path, fmt.Sprintf("第%d行存在无法转换的字符", line))
+	}
+	result.Converted = true
+	return converted, result, nil
+}
+
+// ConvertFileToUTF8 把文件转换为UTF-8，无需转换或转换失败时不修改文件
+func ConvertFileToUTF8(path string, source encoding.Encoding) (*EncodingConversion, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, utils.FileErrors.ReadFailed(path, err)
+	}
+	converted, result, err := DecodeToUTF8(path, data, source)
+	if err != nil {
+		return nil, err
+	}
+	if result.Converted {
+		if err := writeFileAtomic(path, converted); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// encodingConversionError 构建编码转换失败的错误
+func encodingConversionError(path, details string) error {
+	return utils.NewError(utils.ErrorTypeMigration, "ENCODING_CONVERSION_FAILED").
+		Message(fmt.Sprintf("输出文件编码转换失败: %s", path)).
+		Details(details).
+		Suggestion("确认 migration.output_encoding 与ora2pg实际输出的编码一致，原文件未修改").
+		Build()
+}
+
+// writeFileAtomic 先写临时文件再替换，避免中断时留下不完整的文件
+func writeFileAtomic(path string, data []byte) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return utils.FileErrors.ReadFailed(path, err)
+	}
+	tmp := path + ".encoding.tmp"
+	if err := os.WriteFile(tmp, data, info.Mode().Perm()); err != nil {
+		return utils.FileErrors.WriteFailed(tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return utils.FileErrors.WriteFailed(path, err)
+	}
+	return nil
+}
+
+// OutputEncodingConverter 把ora2pg生成的SQL统一转换为UTF-8
+type OutputEncodingConverter struct {
+	source    encoding.Encoding
+	backupDir string
+	cfg       *config.ProjectConfig
+	logger    *utils.Logger
+	fileUtils *utils.FileUtils
+}
+
+// NewOutputEncodingConverter 创建输出编码转换器，编码名称无效时返回错误
+func NewOutputEncodingConverter(cfg *config.ProjectConfig, backupDir string) (*OutputEncodingConverter, error) {
+	source, err := LookupOutputEncoding(cfg.Migration.OutputEncoding)
+	if err != nil {
+		return nil, err
+	}
+	return &OutputEncodingConverter{
+		source:    source,
+		backupDir: backupDir,
+		cfg:       cfg,
+		logger:    utils.GetGlobalLogger(),
+		fileUtils: utils.NewFileUtils(),
+	}, nil
+}
+
+// Process 检测并转换本次生成的SQL文件，转换前把原文件备份到 backupDir/encoding/<类型>-<时间>/
+func (c *OutputEncodingConverter) Process(ctx context.Context, migrationType MigrationType, files []string) error {
+	backupDir := filepath.Join(c.backupDir, "encoding",
+		fmt.Sprintf("%s-%s", migrationType, time.Now().Format("20060102-150405")))
+
+	var converted, notUTF8 []string
+	for i, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return utils.FileErrors.ReadFailed(file, err)
+		}
+		content, result, err := DecodeToUTF8(file, data, c.source)
+		if err != nil {
+			return err
+		}
+		if result.NotUTF8 {
+			notUTF8 = append(notUTF8, file)
+		}
+		if !result.Converted {
+			continue
+		}
+
+		// 改写前备份原文件，加序号避免不同子目录下的同名文件互相覆盖
+		if err := c.fileUtils.EnsureDir(backupDir); err != nil {
+			return utils.FileErrors.CreateFailed(backupDir, err)
+		}
+		backup := filepath.Join(backupDir, fmt.Sprintf("%03d_%s", i+1, filepath.Base(file)))
+		if err := c.fileUtils.CopyFile(file, backup); err != nil {
+			return utils.FileErrors.CreateFailed(backup, err)
+		}
+		if err := writeFileAtomic(file, content); err != nil {
+			return err
+		}
+		converted = append(converted, file)
+	}
+
+	if len(converted) > 0 {
+		c.logger.Infof("已将 %d 个%s输出文件转换为UTF-8，原文件备份在: %s", len(converted), migrationType, backupDir)
+	}
+	if len(notUTF8) > 0 {
+		c.warnNotUTF8(ctx, migrationType, notUTF8)
+	}
+	return nil
+}
+
+// warnNotUTF8 提示存在非UTF-8文件，并根据源库字符集建议 output_encoding
+func (c *OutputEncodingConverter) warnNotUTF8(ctx context.Context, migrationType MigrationType, files []string) {
+	sort.Strings(files)
+	shown := files
+	if len(shown) > maxReportedEncodingFiles {
+		shown = shown[:maxReportedEncodingFiles]
+	}
+	c.logger.Warnf("%s 生成的 %d 个SQL文件不是UTF-8编码，导入PostgreSQL可能乱码: %s",
+		migrationType, len(files), strings.Join(shown, ", "))
+
+	suggestion := "请在 migration.output_encoding 中指定ora2pg输出的编码（如 gbk），迁移时自动转换为UTF-8"
+	charsetCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	runner := oracle.NewSQLPlusRunner(&c.cfg.Oracle, &c.cfg.OracleClient)
+	if charset, err := oracle.DatabaseCharset(charsetCtx, runner); err == nil {
+		if name := OracleCharsetEncoding(charset); name != "" && name != "utf-8" {
+			suggestion = fmt.Sprintf("源库字符集为 %s，请设置 migration.output_encoding: %s", charset, name)
+		} else {
+			suggestion = fmt.Sprintf("源库字符集为 %s，请检查ora2pg的 NLS_LANG 设置（当前为 AMERICAN_AMERICA.UTF8）和 FILE_ENCODING", charset)
+		}
+	} else {
+		c.logger.Debugf("查询源库字符集失败: %v", err)
+	}
+	c.logger.Warnf("💡 %s", suggestion)
+}
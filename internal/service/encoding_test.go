@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+const encodingSampleSQL = "INSERT INTO emp (name) VALUES ('张三');\n"
+
+func TestLookupOutputEncoding(t *testing.T) {
+	enc, err := LookupOutputEncoding("")
+	assert.NoError(t, err)
+	assert.Nil(t, enc)
+
+	enc, err = LookupOutputEncoding("GBK")
+	require.NoError(t, err)
+	assert.Equal(t, simplifiedchinese.GBK, enc)
+
+	enc, err = LookupOutputEncoding("Shift_JIS")
+	require.NoError(t, err)
+	assert.NotNil(t, enc)
+
+	_, err = LookupOutputEncoding("ebcdic")
+	assert.Equal(t, "UNSUPPORTED_OUTPUT_ENCODING", utils.GetErrorCode(err))
+
+	assert.Equal(t, "gbk", OracleCharsetEncoding("zhs16gbk"))
+	assert.Equal(t, "utf-8", OracleCharsetEncoding("AL32UTF8"))
+	assert.Empty(t, OracleCharsetEncoding("US7ASCII"))
+}
+
+func TestDecodeToUTF8(t *testing.T) {
+	gbk, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(encodingSampleSQL))
+	require.NoError(t, err)
+
+	// GBK转为UTF-8
+	converted, result, err := DecodeToUTF8("a.sql", gbk, simplifiedchinese.GBK)
+	require.NoError(t, err)
+	assert.True(t, result.Converted)
+	assert.Equal(t, encodingSampleSQL, string(converted))
+
+	// 未指定编码时只检测
+	converted, result, err = DecodeToUTF8("a.sql", gbk, nil)
+	require.NoError(t, err)
+	assert.True(t, result.NotUTF8)
+	assert.False(t, result.Converted)
+	assert.Equal(t, gbk, converted)
+
+	// 已是UTF-8时不修改
+	_, result, err = DecodeToUTF8("a.sql", []byte(encodingSampleSQL), simplifiedchinese.GBK)
+	require.NoError(t, err)
+	assert.False(t, result.Converted)
+
+	// UTF-8 BOM 去除
+	converted, result, err = DecodeToUTF8("a.sql", append([]byte{0xEF, 0xBB, 0xBF}, encodingSampleSQL...), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "UTF-8 BOM", result.FromBOM)
+	assert.Equal(t, encodingSampleSQL, string(converted))
+
+	// UTF-16 按BOM识别，无需配置编码
+	utf16, err := unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewEncoder().Bytes([]byte(encodingSampleSQL))
+	require.NoError(t, err)
+	converted, result, err = DecodeToUTF8("a.sql", utf16, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "UTF-16", result.FromBOM)
+	assert.Equal(t, encodingSampleSQL, string(converted))
+
+	// 无法按GBK解码的字节
+	invalid := append([]byte("SELECT 1;\n-- "), 0x81, 0x20)
+	_, _, err = DecodeToUTF8("bad.sql", invalid, simplifiedchinese.GBK)
+	assert.Equal(t, "ENCODING_CONVERSION_FAILED", utils.GetErrorCode(err))
+	assert.Contains(t, err.Error(), "第2行")
+}
+
+func TestOutputEncodingConverterProcess(t *testing.T) {
+	dir := t.TempDir()
+	outputDir := filepath.Join(dir, "output")
+	require.NoError(t, os.MkdirAll(outputDir, 0755))
+
+	gbk, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(encodingSampleSQL))
+	require.NoError(t, err)
+	gbkFile := filepath.Join(outputDir, "data.sql")
+	utf8File := filepath.Join(outputDir, "table.sql")
+	require.NoError(t, os.WriteFile(gbkFile, gbk, 0644))
+	require.NoError(t, os.WriteFile(utf8File, []byte("CREATE TABLE emp (name text);\n"), 0644))
+
+	cfg := &config.ProjectConfig{}
+	cfg.Migration.OutputEncoding = "gbk"
+	converter, err := NewOutputEncodingConverter(cfg, filepath.Join(dir, "backup"))
+	require.NoError(t, err)
+	require.NoError(t, converter.Process(context.Background(), MigrationTypeCopy, []string{gbkFile, utf8File}))
+
+	data, err := os.ReadFile(gbkFile)
+	require.NoError(t, err)
+	assert.Equal(t, encodingSampleSQL, string(data))
+
+	// 只备份被转换的文件
+	backups, err := filepath.Glob(filepath.Join(dir, "backup", "encoding", "COPY-*", "*"))
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	original, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, gbk, original)
+
+	// 转换失败时原文件不变
+	invalid := []byte{'-', '-', ' ', 0x81, 0x20, '\n'}
+	require.NoError(t, os.WriteFile(gbkFile, invalid, 0644))
+	err = converter.Process(context.Background(), MigrationTypeCopy, []string{gbkFile})
+	assert.Equal(t, "ENCODING_CONVERSION_FAILED", utils.GetErrorCode(err))
+	data, err = os.ReadFile(gbkFile)
+	require.NoError(t, err)
+	assert.Equal(t, invalid, data)
+
+	cfg.Migration.OutputEncoding = "klingon"
+	_, err = NewOutputEncodingConverter(cfg, dir)
+	assert.Error(t, err)
+}
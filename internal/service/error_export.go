@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// ErrorReportSchemaVersion 错误导出文件的结构版本，字段含义变化或删除字段时递增，新增字段不递增
+const ErrorReportSchemaVersion = 1
+
+// 导出错误的来源
+const (
+	ErrorSourceResult = "result" // 迁移类型或整个迁移的执行错误
+	ErrorSourceLog    = "log"    // ora2pg输出中的错误行
+)
+
+// 导出错误的类别，供工单系统分派
+const (
+	ErrorCategoryPermission    = "permission"     // 权限不足
+	ErrorCategoryMissingObject = "missing_object" // 对象不存在
+	ErrorCategorySyntax        = "syntax"         // 语法或标识符错误
+	ErrorCategoryData          = "data"           // 数据内容或编码问题
+	ErrorCategoryConstraint    = "constraint"     // 违反约束
+	ErrorCategoryConnection    = "connection"     // 连接中断或无法连接
+	ErrorCategoryResource      = "resource"       // 表空间、UNDO等资源不足
+	ErrorCategoryOther         = "other"
+)
+
+// maxExportedMessageLength 导出消息的最大长度（字符），过长的输出行截断
+const maxExportedMessageLength = 500
+
+// ErrorObject 错误关联的数据库对象
+type ErrorObject struct {
+	// Type 对象类型，如 TABLE、CONSTRAINT，无法判断时为空
+	Type   string `json:"type"`
+	Schema string `json:"schema"`
+	Name   string `json:"name"`
+}
+
+// ExportedError 导出的一个错误，相同的错误合并为一条并记录出现次数
+type ExportedError struct {
+	// Fingerprint 按类型、错误码、迁移类型、对象和消息（忽略数字）计算，工单系统可用来去重
+	Fingerprint   string          `json:"fingerprint"`
+	Type          utils.ErrorType `json:"type"`
+	Code          string          `json:"code"`
+	Category      string          `json:"category"`
+	Source        string          `json:"source"`
+	MigrationType MigrationType   `json:"migration_type"`
+	Target        string          `json:"target"`
+	Message       string          `json:"message"`
+	Details       string          `json:"details"`
+	Suggestions   []string        `json:"suggestions"`
+	Object        *ErrorObject    `json:"object"`
+	Occurrences   int             `json:"occurrences"`
+	LogFiles      []string        `json:"log_files"`
+}
+
+// ErrorReport 一次迁移的结构化错误报告
+type ErrorReport struct {
+	SchemaVersion int             `json:"schema_version"`
+	RunID         string          `json:"run_id"`
+	Project       string          `json:"project"`
+	Task          string          `json:"task"`
+	Status        ExecutionStatus `json:"status"`
+	GeneratedAt   time.Time       `json:"generated_at"`
+	Errors        []ExportedError `json:"errors"`
+}
+
+// errorRule 错误分类规则：Oracle错误按错误码匹配，PostgreSQL错误按消息匹配
+type errorRule struct {
+	code       string
+	pattern    *regexp.Regexp
+	category   string
+	suggestion string
+}
+
+// oracleErrorRules 常见Oracle错误码的类别和建议
+var oracleErrorRules = []errorRule{
+	{code: "ORA-00942", category: ErrorCategoryMissingObject, suggestion: "确认对象存在、名称大小写正确，且迁移账号有 SELECT 权限（或 SELECT ANY TABLE）"},
+	{code: "ORA-01031", category: ErrorCategoryPermission, suggestion: "请DBA为迁移账号授予所需权限，可运行 'ora2pg-admin 检查 权限' 查看缺少的权限"},
+	{code: "ORA-00904", category: ErrorCategorySyntax, suggestion: "列名无效，检查配置中 MODIFY_TYPE、REPLACE_COLS 等选项引用的列名"},
+	{code: "ORA-01555", category: ErrorCategoryResource, suggestion: "快照过旧，请DBA增大 UNDO_RETENTION，或减小 data_limit、在业务低峰期导出"},
+	{code: "ORA-01652", category: ErrorCategoryResource, suggestion: "临时表空间不足，请DBA扩展临时表空间或减小并行度"},
+	{code: "ORA-29275", category: ErrorCategoryData, suggestion: "数据中存在不完整的多字节字符，检查 NLS_LANG 与源库字符集是否一致"},
+	{code: "ORA-03113", category: ErrorCategoryConnection, suggestion: "连接被中断，运行 'ora2pg-admin 检查 连接' 诊断后使用 --resume 续传"},
+	{code: "ORA-03135", category: ErrorCategoryConnection, suggestion: "连接被中断，运行 'ora2pg-admin 检查 连接' 诊断后使用 --resume 续传"},
+	{code: "ORA-12154", category: ErrorCategoryConnection, suggestion: "无法解析连接标识符，运行 'ora2pg-admin 检查 连接' 诊断"},
+	{code: "ORA-12514", category: ErrorCategoryConnection, suggestion: "监听器不识别服务名，运行 'ora2pg-admin 检查 连接' 诊断"},
+	{code: "ORA-12541", category: ErrorCategoryConnection, suggestion: "目标地址没有监听器，运行 'ora2pg-admin 检查 连接' 诊断"},
+}
+
+// postgresErrorRules 常见PostgreSQL错误消息对应的SQLSTATE、类别和建议
+var postgresErrorRules = []errorRule{
+	{code: "42P01", pattern: regexp.MustCompile(`relation ".*" does not exist`), category: ErrorCategoryMissingObject, suggestion: "目标库中缺少该表，确认结构迁移已完成且 Schema 与 search_path 正确"},
+	{code: "42501", pattern: regexp.MustCompile(`permission denied`), category: ErrorCategoryPermission, suggestion: "为目标库用户授予该对象的权限，或使用对象属主执行迁移"},
+	{code: "42601", pattern: regexp.MustCompile(`syntax error`), category: ErrorCategorySyntax, suggestion: "生成的SQL有语法错误，使用 --check-sql 预校验后在输出文件中修正对应语句"},
+	{code: "23505", pattern: regexp.MustCompile(`duplicate key value`), category: ErrorCategoryConstraint, suggestion: "目标表已有相同主键的数据，使用 --idempotent 先清空再导入，或清理重复数据"},
+	{code: "23503", pattern: regexp.MustCompile(`violates foreign key constraint`), category: ErrorCategoryConstraint, suggestion: "父表数据尚未导入，调整导入顺序或开启延迟约束（migration.defer_constraints）"},
+	{code: "23502", pattern: regexp.MustCompile(`violates not-null constraint`), category: ErrorCategoryConstraint, suggestion: "源数据中存在空值（Oracle 会把空字符串视为 NULL），检查该列的数据"},
+	{code: "22001", pattern: regexp.MustCompile(`value too long`), category: ErrorCategoryData, suggestion: "目标列长度不足，检查类型映射，多字节字符集下按字符计算长度"},
+	{code: "22021", pattern: regexp.MustCompile(`invalid byte sequence`), category: ErrorCategoryData, suggestion: "数据编码与目标库不一致，检查 NLS_LANG 和 CLIENT_ENCODING 设置"},
+}
+
+var (
+	// logMessagePrefixPattern 输出行的时间戳前缀
+	logMessagePrefixPattern = regexp.MustCompile(`^\s*\[[^\]]*\]\s*`)
+	// oracleErrorCodeInLine 输出行中的Oracle错误码
+	oracleErrorCodeInLine = regexp.MustCompile(`\bORA-\d{5}\b`)
+	// postgresErrorLinePattern DBD::Pg 或 psql 输出的错误
+	postgresErrorLinePattern = regexp.MustCompile(`DBD::Pg|^psql:`)
+	// sqlstateInLine psql 在 VERBOSITY=verbose 时输出的 SQLSTATE，如 "ERROR:  42P01: ..."
+	sqlstateInLine = regexp.MustCompile(`ERROR:\s+([0-9A-Z]{5}):`)
+	// fingerprintDigits 计算指纹时忽略的数字（行号、行数等）
+	fingerprintDigits = regexp.MustCompile(`\d+`)
+)
+
+// objectName 匹配可带 Schema 和引号的对象名，如 HR.EMPLOYEES、"hr"."employees"
+const objectName = `((?:"[^"]+"|[\w$#]+)(?:\.(?:"[^"]+"|[\w$#]+))?)`
+
+// errorObjectPatterns 从错误消息中提取关联对象，按顺序匹配，先匹配更明确的写法
+var errorObjectPatterns = []struct {
+	objectType string
+	pattern    *regexp.Regexp
+}{
+	{"TABLE", regexp.MustCompile(`relation ` + objectName)},
+	{"CONSTRAINT", regexp.MustCompile(`constraint ` + objectName)},
+	{"", regexp.MustCompile(`(?i)\b(MATERIALIZED VIEW|TABLE|VIEW|SEQUENCE|INDEX|TRIGGER|FUNCTION|PROCEDURE|PACKAGE BODY|PACKAGE|TYPE|SYNONYM)\s+` + objectName)},
+	{"TABLE", regexp.MustCompile(`(?i)\b(?:FROM|INTO)\s+` + objectName)},
+}
+
+// objectNameStopWords 紧跟在对象类型后但不是对象名的单词，如 "table or view does not exist"
+var objectNameStopWords = map[string]bool{
+	"OR": true, "AND": true, "IS": true, "DOES": true, "NOT": true, "ALREADY": true,
+	"WITH": true, "FOR": true, "IF": true, "ON": true, "AS": true, "TO": true,
+}
+
+// BuildErrorReport 根据迁移结果生成错误报告，results 与 migrationTypes 按顺序对应
+//
+// 每个结果的执行错误导出为一条，输出中的错误行按 classifyLogLine 识别后分类；runErr 为迁移脚本、断言等
+// 不属于某个迁移类型的错误，和结果中的错误相同时不重复导出。
+func BuildErrorReport(task string, migrationTypes []MigrationType, results []*ExecutionResult, runErr error) *ErrorReport {
+	report := &ErrorReport{
+		SchemaVersion: ErrorReportSchemaVersion,
+		RunID:         utils.RunID(),
+		Task:          task,
+		GeneratedAt:   time.Now(),
+		Errors:        []ExportedError{},
+	}
+
+	index := make(map[string]int)
+	add := func(item ExportedError) {
+		item.Fingerprint = errorFingerprint(item)
+		if i, ok := index[item.Fingerprint]; ok {
+			report.Errors[i].Occurrences++
+			return
+		}
+		item.Occurrences = 1
+		if item.Suggestions == nil {
+			item.Suggestions = []string{}
+		}
+		if item.LogFiles == nil {
+			item.LogFiles = []string{}
+		}
+		index[item.Fingerprint] = len(report.Errors)
+		report.Errors = append(report.Errors, item)
+	}
+
+	reported := false
+	for i, result := range results {
+		var migrationType MigrationType
+		if i < len(migrationTypes) {
+			migrationType = migrationTypes[i]
+		}
+		if result.Error != nil {
+			item := exportedAppError(result.Error)
+			item.MigrationType = migrationType
+			item.LogFiles = result.LogFiles
+			if item.Details == "" && result.ExitCode != 0 {
+				item.Details = fmt.Sprintf("ora2pg退出码: %d", result.ExitCode)
+			}
+			add(item)
+			reported = reported || errors.Is(runErr, result.Error)
+		}
+		for _, line := range strings.Split(result.Output+"\n"+result.ErrorOutput, "\n") {
+			if classifyLogLine(line) != logLevelError {
+				continue
+			}
+			item := classifyErrorLine(line)
+			item.MigrationType = migrationType
+			item.LogFiles = result.LogFiles
+			add(item)
+		}
+	}
+	// 用户取消不是需要跟踪的错误
+	if runErr != nil && !reported && !errors.Is(runErr, context.Canceled) {
+		add(exportedAppError(runErr))
+	}
+	return report
+}
+
+// exportedAppError 把执行错误转换为导出格式，非 AppError 按迁移错误处理
+func exportedAppError(err error) ExportedError {
+	item := ExportedError{
+		Type:     utils.ErrorTypeMigration,
+		Code:     "MIGRATION_FAILED",
+		Category: ErrorCategoryOther,
+		Source:   ErrorSourceResult,
+		Message:  truncateMessage(err.Error()),
+	}
+	var appErr *utils.AppError
+	if errors.As(err, &appErr) {
+		item.Type = appErr.Type
+		item.Code = appErr.Code
+		item.Message = truncateMessage(appErr.Message)
+		item.Details = truncateMessage(appErr.Details)
+		item.Suggestions = appErr.Suggestions
+	}
+	text := item.Message + " " + item.Details
+	if code := oracleErrorCodeInLine.FindString(text); code != "" {
+		if rule := findOracleRule(code); rule != nil {
+			item.Category = rule.category
+			item.Suggestions = append(item.Suggestions, rule.suggestion)
+		}
+	}
+	item.Object = extractErrorObject(text)
+	return item
+}
+
+// classifyErrorLine 对输出中的错误行分类：Oracle 错误按错误码，PostgreSQL 错误按消息，其余为 ora2pg 错误
+func classifyErrorLine(line string) ExportedError {
+	message := strings.TrimSpace(logMessagePrefixPattern.ReplaceAllString(line, ""))
+	item := ExportedError{
+		Type:     utils.ErrorTypeMigration,
+		Code:     "ORA2PG_ERROR",
+		Category: ErrorCategoryOther,
+		Source:   ErrorSourceLog,
+		Message:  truncateMessage(message),
+		Object:   extractErrorObject(message),
+	}
+	if strings.HasPrefix(message, "FATAL") {
+		item.Code = "ORA2PG_FATAL"
+	}
+
+	switch {
+	case oracleErrorCodeInLine.MatchString(message):
+		item.Type = utils.ErrorTypeOracle
+		item.Code = oracleErrorCodeInLine.FindString(message)
+		if rule := findOracleRule(item.Code); rule != nil {
+			item.Category = rule.category
+			item.Suggestions = []string{rule.suggestion}
+		} else {
+			item.Suggestions = []string{"查看Oracle错误说明: oerr ora " + strings.TrimPrefix(item.Code, "ORA-")}
+		}
+	case postgresErrorLinePattern.MatchString(message):
+		item.Type = utils.ErrorTypePostgres
+		item.Code = "POSTGRES_ERROR"
+		if matches := sqlstateInLine.FindStringSubmatch(message); matches != nil {
+			item.Code = matches[1]
+		}
+		for _, rule := range postgresErrorRules {
+			if rule.pattern.MatchString(message) {
+				item.Code = rule.code
+				item.Category = rule.category
+				item.Suggestions = []string{rule.suggestion}
+				break
+			}
+		}
+		if item.Suggestions == nil {
+			item.Suggestions = []string{"查看目标库日志中同一时间的错误详情"}
+		}
+	default:
+		item.Suggestions = []string{"查看日志文件中该行前后的输出，或使用 'ora2pg-admin 日志' 按迁移类型过滤"}
+	}
+	return item
+}
+
+// findOracleRule 查找Oracle错误码对应的规则
+func findOracleRule(code string) *errorRule {
+	for i := range oracleErrorRules {
+		if oracleErrorRules[i].code == code {
+			return &oracleErrorRules[i]
+		}
+	}
+	return nil
+}
+
+// extractErrorObject 从错误消息中提取关联的对象，找不到时返回 nil
+func extractErrorObject(message string) *ErrorObject {
+	for _, candidate := range errorObjectPatterns {
+		for _, matches := range candidate.pattern.FindAllStringSubmatch(message, -1) {
+			objectType := candidate.objectType
+			name := matches[len(matches)-1]
+			if objectType == "" {
+				objectType = strings.ToUpper(matches[1])
+			}
+			if objectNameStopWords[strings.ToUpper(name)] {
+				continue
+			}
+			object := &ErrorObject{Type: objectType}
+			object.Schema, object.Name = splitObjectName(name)
+			return object
+		}
+	}
+	return nil
+}
+
+// splitObjectName 拆分 schema.name 并去掉引号，错误消息中的限定名也可能整体加引号，如 "hr.employees"
+func splitObjectName(name string) (schema, object string) {
+	if before, after, found := strings.Cut(name, `"."`); found {
+		return strings.TrimPrefix(before, `"`), strings.TrimSuffix(after, `"`)
+	}
+	name = strings.Trim(name, `"`)
+	if schema, object, found := strings.Cut(name, "."); found {
+		return strings.Trim(schema, `"`), strings.Trim(object, `"`)
+	}
+	return "", name
+}
+
+// truncateMessage 截断过长的消息
+func truncateMessage(message string) string {
+	runes := []rune(message)
+	if len(runes) <= maxExportedMessageLength {
+		return message
+	}
+	return string(runes[:maxExportedMessageLength]) + "..."
+}
+
+// errorFingerprint 计算错误指纹，消息中的数字不参与计算，同一问题在不同行号、行数下指纹相同
+func errorFingerprint(item ExportedError) string {
+	parts := []string{string(item.Type), item.Code, string(item.MigrationType), item.Target,
+		fingerprintDigits.ReplaceAllString(item.Message, "#")}
+	if item.Object != nil {
+		parts = append(parts, item.Object.Type, item.Object.Schema, item.Object.Name)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// AddTarget 把另一个目标库的错误合并到报告中，各错误标记目标名称
+func (r *ErrorReport) AddTarget(target string, other *ErrorReport) {
+	for _, item := range other.Errors {
+		item.Target = target
+		item.Fingerprint = errorFingerprint(item)
+		r.Errors = append(r.Errors, item)
+	}
+	// 任一目标失败时整体失败
+	if r.Status == "" || (r.Status != StatusFailed && other.Status != StatusCompleted) {
+		r.Status = other.Status
+	}
+}
+
+// WriteErrorReport 把错误报告写入JSON文件
+func WriteErrorReport(path string, report *ErrorReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return utils.FileErrors.WriteFailed(path, err)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return utils.FileErrors.CreateFailed(dir, err)
+		}
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return utils.FileErrors.WriteFailed(path, err)
+	}
+	return nil
+}
+
+// ErrorReport 本次迁移的结构化错误报告，runErr 为 ExecuteWithProgress 返回的错误
+func (ms *MigrationService) ErrorReport(task string, migrationTypes []MigrationType, runErr error) *ErrorReport {
+	report := BuildErrorReport(task, migrationTypes, ms.state.Results, runErr)
+	report.Project = ms.config.Project.Name
+	report.Status = runStatus(ms.state, runErr)
+	return report
+}
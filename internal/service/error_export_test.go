@@ -0,0 +1,143 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/utils"
+)
+
+func TestBuildErrorReport(t *testing.T) {
+	results := []*ExecutionResult{
+		{Status: StatusCompleted, Output: "[2024-01-01 10:00:00] Exported 12 tables\n"},
+		{
+			Status:   StatusFailed,
+			ExitCode: 1,
+			Error:    errors.New("exit status 1"),
+			Output: "[====>] 14/14 rows (100.0%) Table COUNTRIES (14 recs/sec)\n" +
+				`DBD::Oracle::st execute failed: ORA-00942: table or view does not exist (DBD ERROR: error possibly near <*> indicator at char 14 in 'SELECT * FROM "HR"."EMPLOYEES"')` + "\n" +
+				`DBD::Oracle::st execute failed: ORA-00942: table or view does not exist (DBD ERROR: error possibly near <*> indicator at char 15 in 'SELECT * FROM "HR"."EMPLOYEES"')` + "\n",
+			ErrorOutput: `ERROR:  DBD::Pg::db do failed: ERROR:  duplicate key value violates unique constraint "countries_pkey"` + "\n" +
+				"WARNING: column COMMENTS has no comment\n",
+			LogFiles: []string{"logs/COPY.log"},
+		},
+	}
+	runErr := utils.NewError(utils.ErrorTypeMigration, "ASSERTION_FAILED").
+		Message("迁移断言未满足").
+		Details("ORA-01031: insufficient privileges").
+		Suggestion("检查断言配置").
+		Build()
+
+	report := BuildErrorReport("数据迁移", []MigrationType{MigrationTypeTable, MigrationTypeCopy}, results, runErr)
+	assert.Equal(t, ErrorReportSchemaVersion, report.SchemaVersion)
+	assert.Equal(t, "数据迁移", report.Task)
+	require.Len(t, report.Errors, 4)
+
+	exit := report.Errors[0]
+	assert.Equal(t, ErrorSourceResult, exit.Source)
+	assert.Equal(t, utils.ErrorTypeMigration, exit.Type)
+	assert.Equal(t, "MIGRATION_FAILED", exit.Code)
+	assert.Equal(t, MigrationTypeCopy, exit.MigrationType)
+	assert.Equal(t, "ora2pg退出码: 1", exit.Details)
+	assert.Equal(t, []string{"logs/COPY.log"}, exit.LogFiles)
+
+	// 只有数字不同的错误行合并为一条
+	oracle := report.Errors[1]
+	assert.Equal(t, ErrorSourceLog, oracle.Source)
+	assert.Equal(t, utils.ErrorTypeOracle, oracle.Type)
+	assert.Equal(t, "ORA-00942", oracle.Code)
+	assert.Equal(t, ErrorCategoryMissingObject, oracle.Category)
+	assert.Equal(t, 2, oracle.Occurrences)
+	assert.Equal(t, &ErrorObject{Type: "TABLE", Schema: "HR", Name: "EMPLOYEES"}, oracle.Object)
+	assert.NotEmpty(t, oracle.Suggestions)
+
+	postgres := report.Errors[2]
+	assert.Equal(t, utils.ErrorTypePostgres, postgres.Type)
+	assert.Equal(t, "23505", postgres.Code)
+	assert.Equal(t, ErrorCategoryConstraint, postgres.Category)
+	assert.Equal(t, &ErrorObject{Type: "CONSTRAINT", Name: "countries_pkey"}, postgres.Object)
+
+	// 不属于迁移类型的错误保留 AppError 的错误码和建议
+	assertion := report.Errors[3]
+	assert.Equal(t, MigrationType(""), assertion.MigrationType)
+	assert.Equal(t, "ASSERTION_FAILED", assertion.Code)
+	assert.Equal(t, ErrorCategoryPermission, assertion.Category)
+	assert.Equal(t, "检查断言配置", assertion.Suggestions[0])
+	assert.Nil(t, assertion.Object)
+
+	// 指纹稳定，不同的错误指纹不同
+	again := BuildErrorReport("数据迁移", []MigrationType{MigrationTypeTable, MigrationTypeCopy}, results, runErr)
+	assert.Equal(t, report.Errors[1].Fingerprint, again.Errors[1].Fingerprint)
+	assert.NotEqual(t, report.Errors[1].Fingerprint, report.Errors[2].Fingerprint)
+}
+
+func TestBuildErrorReportRunErr(t *testing.T) {
+	typeErr := errors.New("exit status 2")
+	results := []*ExecutionResult{{Status: StatusFailed, Error: typeErr}}
+
+	// 与结果中相同的错误不重复导出
+	report := BuildErrorReport("结构迁移", []MigrationType{MigrationTypeTable}, results, typeErr)
+	assert.Len(t, report.Errors, 1)
+
+	// 没有错误时导出空列表而不是 null
+	report = BuildErrorReport("结构迁移", nil, nil, nil)
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"errors":[]`)
+}
+
+func TestExtractErrorObject(t *testing.T) {
+	tests := []struct {
+		message string
+		want    *ErrorObject
+	}{
+		{`ERROR:  relation "hr.employees" does not exist`, &ErrorObject{Type: "TABLE", Schema: "hr", Name: "employees"}},
+		{`FATAL: cannot export VIEW EMP_DETAILS_VIEW`, &ErrorObject{Type: "VIEW", Name: "EMP_DETAILS_VIEW"}},
+		{`ORA-04063: package body "HR.PKG_SALARY" has errors`, &ErrorObject{Type: "PACKAGE BODY", Schema: "HR", Name: "PKG_SALARY"}},
+		{`ORA-00942: table or view does not exist`, nil},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, extractErrorObject(tt.message), tt.message)
+	}
+}
+
+func TestErrorReportAddTarget(t *testing.T) {
+	report := BuildErrorReport("结构迁移", nil, nil, nil)
+	primary := &ErrorReport{Status: StatusCompleted, Errors: []ExportedError{}}
+	replica := BuildErrorReport("结构迁移", []MigrationType{MigrationTypeTable},
+		[]*ExecutionResult{{Status: StatusFailed, Error: errors.New("exit status 1")}}, nil)
+	replica.Status = StatusFailed
+
+	report.AddTarget("primary", primary)
+	report.AddTarget("replica", replica)
+	assert.Equal(t, StatusFailed, report.Status)
+	require.Len(t, report.Errors, 1)
+	assert.Equal(t, "replica", report.Errors[0].Target)
+	assert.NotEqual(t, replica.Errors[0].Fingerprint, report.Errors[0].Fingerprint)
+}
+
+func TestWriteErrorReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports", "errors.json")
+	report := BuildErrorReport("数据迁移", []MigrationType{MigrationTypeCopy},
+		[]*ExecutionResult{{Status: StatusFailed, Output: "ERROR: ORA-01555: snapshot too old\n"}}, nil)
+	require.NoError(t, WriteErrorReport(path, report))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, float64(ErrorReportSchemaVersion), decoded["schema_version"])
+	item := decoded["errors"].([]interface{})[0].(map[string]interface{})
+	for _, key := range []string{"fingerprint", "type", "code", "category", "source", "migration_type", "target",
+		"message", "details", "suggestions", "object", "occurrences", "log_files"} {
+		assert.Contains(t, item, key)
+	}
+	assert.Equal(t, "ORA-01555", item["code"])
+	assert.Equal(t, ErrorCategoryResource, item["category"])
+}
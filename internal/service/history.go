@@ -0,0 +1,218 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// DefaultHistoryPath 默认迁移历史文件路径（相对于项目根目录），每行一条JSON记录
+var DefaultHistoryPath = filepath.Join(".ora2pg-admin", "history.jsonl")
+
+// MetadataNoteKey 备注在元数据中使用的键名
+const MetadataNoteKey = "note"
+
+// HistoryTypeResult 单个迁移类型的历史结果
+type HistoryTypeResult struct {
+	Type         MigrationType   `json:"type"`
+	Status       ExecutionStatus `json:"status"`
+	Duration     time.Duration   `json:"duration"`
+	Error        string          `json:"error,omitempty"`
+	ErrorCount   int             `json:"error_count,omitempty"`
+	WarningCount int             `json:"warning_count,omitempty"`
+	Rows         int64           `json:"rows,omitempty"`
+	LogFiles     []string        `json:"log_files,omitempty"`
+	// Tables 耗时最长的表，用于性能分析
+	Tables []TableTiming `json:"tables,omitempty"`
+}
+
+// HistoryRecord 一次迁移运行的历史记录
+type HistoryRecord struct {
+	RunID     string              `json:"run_id,omitempty"`
+	Task      string              `json:"task"`
+	Status    ExecutionStatus     `json:"status"`
+	StartTime time.Time           `json:"start_time"`
+	EndTime   time.Time           `json:"end_time"`
+	Duration  time.Duration       `json:"duration"`
+	Metadata  map[string]string   `json:"metadata,omitempty"`
+	Results   []HistoryTypeResult `json:"results"`
+	// ConfigHash 迁移时的配置指纹，重试时用来确认配置未变
+	ConfigHash string `json:"config_hash,omitempty"`
+	// RetryOf 重试失败类型时，被重试的那次迁移的运行ID
+	RetryOf string `json:"retry_of,omitempty"`
+}
+
+// Note 获取记录的备注
+func (r *HistoryRecord) Note() string {
+	return r.Metadata[MetadataNoteKey]
+}
+
+// Tags 获取除备注外的标签，按键名排序，格式为 key=value
+func (r *HistoryRecord) Tags() []string {
+	return FormatMetadataTags(r.Metadata)
+}
+
+// MatchesTags 记录是否包含全部指定标签
+func (r *HistoryRecord) MatchesTags(tags map[string]string) bool {
+	for key, value := range tags {
+		if actual, exists := r.Metadata[key]; !exists || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseMetadataTags 解析 key=value 形式的标签列表
+func ParseMetadataTags(values []string) (map[string]string, error) {
+	tags := make(map[string]string, len(values))
+	for _, value := range values {
+		key, tagValue, found := strings.Cut(value, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, utils.NewError(utils.ErrorTypeValidation, "INVALID_TAG").
+				Message("标签格式错误").
+				Details(value).
+				Suggestion("请使用 key=value 格式，如 --tag ticket=JIRA-123").
+				Build()
+		}
+		tags[key] = strings.TrimSpace(tagValue)
+	}
+	return tags, nil
+}
+
+// FormatMetadataTags 把元数据格式化为排序后的 key=value 列表，不包含备注
+func FormatMetadataTags(metadata map[string]string) []string {
+	tags := make([]string, 0, len(metadata))
+	for key, value := range metadata {
+		if key == MetadataNoteKey {
+			continue
+		}
+		tags = append(tags, key+"="+value)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// AppendHistory 追加一条历史记录
+func AppendHistory(path string, record *HistoryRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("序列化迁移历史失败: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return utils.FileErrors.CreateFailed(filepath.Dir(path), err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return utils.FileErrors.WriteFailed(path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return utils.FileErrors.WriteFailed(path, err)
+	}
+	return nil
+}
+
+// LoadHistory 加载历史记录，按时间先后排列；只返回包含全部指定标签的记录
+func LoadHistory(path string, tags map[string]string) ([]*HistoryRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, utils.FileErrors.ReadFailed(path, err)
+	}
+	defer file.Close()
+
+	var records []*HistoryRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		record := &HistoryRecord{}
+		if err := json.Unmarshal([]byte(line), record); err != nil {
+			// 单条记录损坏不影响其他记录
+			utils.GetGlobalLogger().Warnf("跳过损坏的迁移历史记录 %s:%d: %v", path, lineNo, err)
+			continue
+		}
+		if record.MatchesTags(tags) {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, utils.FileErrors.ReadFailed(path, err)
+	}
+
+	return records, nil
+}
+
+// newHistoryRecord 根据迁移状态生成历史记录，results 与 migrationTypes 按顺序对应
+func newHistoryRecord(task string, migrationTypes []MigrationType, state *MigrationState, runErr error) *HistoryRecord {
+	record := &HistoryRecord{
+		RunID:     utils.RunID(),
+		Task:      task,
+		StartTime: state.StartTime,
+		EndTime:   time.Now(),
+		Metadata:  state.Metadata,
+		Results:   make([]HistoryTypeResult, 0, len(state.Results)),
+	}
+	record.Duration = record.EndTime.Sub(record.StartTime)
+
+	for i, result := range state.Results {
+		item := HistoryTypeResult{
+			Status:       result.Status,
+			Duration:     result.Duration,
+			ErrorCount:   result.ErrorCount,
+			WarningCount: result.WarningCount,
+			Rows:         result.MigratedRows,
+			LogFiles:     result.LogFiles,
+			Tables:       slowestTables(result.Tables, historyProfileTables),
+		}
+		if i < len(migrationTypes) {
+			item.Type = migrationTypes[i]
+		}
+		if result.Error != nil {
+			item.Error = result.Error.Error()
+		}
+		record.Results = append(record.Results, item)
+	}
+	record.Status = runStatus(state, runErr)
+	return record
+}
+
+// runStatus 整个迁移的结果：被取消、执行出错或任一类型失败时为失败
+func runStatus(state *MigrationState, runErr error) ExecutionStatus {
+	switch {
+	case state.IsCancelled:
+		return StatusCancelled
+	case runErr != nil:
+		return StatusFailed
+	}
+	for _, result := range state.Results {
+		if result.Status == StatusFailed {
+			return StatusFailed
+		}
+	}
+	return StatusCompleted
+}
+
+// RunStatus 本次迁移的整体结果，runErr 为 ExecuteWithProgress 返回的错误
+func (ms *MigrationService) RunStatus(runErr error) ExecutionStatus {
+	return runStatus(ms.state, runErr)
+}
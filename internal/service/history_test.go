@@ -0,0 +1,94 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+func TestParseMetadataTags(t *testing.T) {
+	tags, err := ParseMetadataTags([]string{"ticket=JIRA-123", " owner = zhang ", "env="})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"ticket": "JIRA-123", "owner": "zhang", "env": ""}, tags)
+
+	_, err = ParseMetadataTags([]string{"invalid"})
+	assert.Error(t, err)
+	_, err = ParseMetadataTags([]string{"=value"})
+	assert.Error(t, err)
+}
+
+func TestMigrationHistory(t *testing.T) {
+	historyPath := filepath.Join(t.TempDir(), "history.jsonl")
+
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("历史项目")
+
+	// 第一次运行：带标签和备注，其中一个类型失败
+	first := NewMigrationService(manager.GetConfig())
+	first.SetHistoryPath(historyPath)
+	first.SetMetadata(map[string]string{"ticket": "JIRA-123", "owner": "zhang", MetadataNoteKey: "生产迁移窗口"})
+	first.state.Results = []*ExecutionResult{
+		{Status: StatusCompleted},
+		{Status: StatusFailed, Error: errors.New("ora2pg退出码 1")},
+	}
+	_, err := first.RecordHistory("结构迁移", []MigrationType{MigrationTypeTable, MigrationTypeView}, nil)
+	require.NoError(t, err)
+
+	// 第二次运行：不同负责人
+	second := NewMigrationService(manager.GetConfig())
+	second.SetHistoryPath(historyPath)
+	second.SetMetadata(map[string]string{"ticket": "JIRA-123", "owner": "li"})
+	second.state.Results = []*ExecutionResult{{Status: StatusCompleted}}
+	_, err = second.RecordHistory("数据迁移", []MigrationType{MigrationTypeCopy}, nil)
+	require.NoError(t, err)
+
+	// 全部记录按时间先后排列
+	records, err := LoadHistory(historyPath, nil)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	record := records[0]
+	assert.Equal(t, "结构迁移", record.Task)
+	assert.Equal(t, utils.RunID(), record.RunID)
+	assert.Equal(t, StatusFailed, record.Status)
+	assert.Equal(t, "生产迁移窗口", record.Note())
+	assert.Equal(t, []string{"owner=zhang", "ticket=JIRA-123"}, record.Tags())
+	require.Len(t, record.Results, 2)
+	assert.Equal(t, MigrationTypeView, record.Results[1].Type)
+	assert.Equal(t, "ora2pg退出码 1", record.Results[1].Error)
+	assert.Equal(t, StatusCompleted, records[1].Status)
+
+	// 按标签过滤，多个标签需同时匹配
+	records, err = LoadHistory(historyPath, map[string]string{"ticket": "JIRA-123"})
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	records, err = LoadHistory(historyPath, map[string]string{"ticket": "JIRA-123", "owner": "li"})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "数据迁移", records[0].Task)
+
+	records, err = LoadHistory(historyPath, map[string]string{"owner": "wang"})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	// 损坏的行被跳过，不存在的文件返回空
+	file, err := os.OpenFile(historyPath, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = file.WriteString("{broken\n")
+	require.NoError(t, err)
+	file.Close()
+	records, err = LoadHistory(historyPath, nil)
+	require.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	records, err = LoadHistory(filepath.Join(t.TempDir(), "missing.jsonl"), nil)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// 幂等模式下重复执行同一迁移得到一致的结果：
+//   - 结构幂等：结构类型的脚本在创建对象前先 DROP ... IF EXISTS，对象已存在时重建而不是报错
+//   - 数据幂等：数据类型覆盖而不是追加，COPY/INSERT 前先 TRUNCATE 目标表，重跑不会产生重复行
+//   - 输出幂等：迁移前归档并清理上次遗留的输出文件，输出目录只包含本次运行的结果
+
+// SetIdempotent 设置是否以幂等模式执行迁移
+func (ms *MigrationService) SetIdempotent(idempotent bool) {
+	ms.idempotent = idempotent
+}
+
+// IncompleteTypes 返回检查点中未完成的迁移类型，按名称排序
+func (c *Checkpoint) IncompleteTypes() []MigrationType {
+	var types []MigrationType
+	for migrationType, tc := range c.Types {
+		if !tc.Status.Succeeded() {
+			types = append(types, migrationType)
+		}
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// prepareIdempotentRun 幂等模式下归档并清理上次遗留的输出
+//
+// 重试失败类型时保留其他类型的输出，不做清理；主ora2pg配置随后会重新生成，保留在原处。
+func (ms *MigrationService) prepareIdempotentRun() error {
+	if !ms.idempotent {
+		return nil
+	}
+	if checkpoint, err := LoadCheckpoint(ms.checkpointPath); err == nil {
+		if incomplete := checkpoint.IncompleteTypes(); len(incomplete) > 0 {
+			ms.logger.Warnf("上次迁移未完成的类型: %s，幂等模式下将重新执行", FormatMigrationOrder(incomplete))
+		}
+	}
+	if ms.retry {
+		ms.logger.Infof("重试失败类型时保留输出目录中其他类型的输出")
+		return nil
+	}
+
+	outputDir := ms.config.Migration.OutputDir
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		return utils.FileErrors.ReadFailed(outputDir, err)
+	}
+	stale := 0
+	for _, entry := range entries {
+		if entry.Name() != "ora2pg.conf" {
+			stale++
+		}
+	}
+	if stale == 0 {
+		return nil
+	}
+
+	archivePath, _, err := ms.ArchiveOutput(false)
+	if err == nil {
+		err = ms.cleanOutputDir("ora2pg.conf")
+	}
+	if err != nil {
+		return utils.NewError(utils.ErrorTypeFile, "IDEMPOTENT_CLEANUP_FAILED").
+			Message("无法清理上次迁移的输出").
+			Details(outputDir).
+			Cause(err).
+			Suggestion("检查输出目录和 backup 目录的权限，或手动清理输出目录后重新执行").
+			Build()
+	}
+	ms.logger.Infof("幂等模式：已将上次的 %d 个输出归档到 %s 并清理", stale, archivePath)
+	return nil
+}
+
+// WriteIdempotentConfig 生成幂等执行的ora2pg配置
+//
+// 结构类型开启 DROP_IF_EXISTS，数据类型开启 TRUNCATE_TABLE，覆盖原配置中的同名指令。
+func WriteIdempotentConfig(baseConfigPath, idempotentConfigPath string, migrationType MigrationType) error {
+	directive := "DROP_IF_EXISTS"
+	comment := "由 ora2pg-admin 幂等模式生成：先删除已存在的对象再创建"
+	if isDataMigrationType(migrationType) {
+		directive = "TRUNCATE_TABLE"
+		comment = "由 ora2pg-admin 幂等模式生成：导入数据前先清空目标表"
+	}
+	return writeDerivedConfig(baseConfigPath, idempotentConfigPath, comment, nil,
+		map[string]bool{directive: true}, []string{directive + " 1"})
+}
+
+// idempotentConfigPath 幂等配置的路径
+func (ms *MigrationService) idempotentConfigPath(migrationType MigrationType) string {
+	return filepath.Join(ms.config.Migration.OutputDir, fmt.Sprintf("ora2pg.%s.idempotent.conf", migrationType))
+}
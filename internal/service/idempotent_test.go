@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+func TestWriteIdempotentConfig(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "ora2pg.conf")
+	require.NoError(t, os.WriteFile(base, []byte("TYPE TABLE\nDROP_IF_EXISTS 0\nTRUNCATE_TABLE 0\n"), 0644))
+	path := filepath.Join(dir, "ora2pg.idempotent.conf")
+
+	require.NoError(t, WriteIdempotentConfig(base, path, MigrationTypeTable))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	content := string(data)
+	assert.Contains(t, content, "DROP_IF_EXISTS 1\n")
+	assert.NotContains(t, content, "DROP_IF_EXISTS 0")
+	assert.Contains(t, content, "TRUNCATE_TABLE 0\n")
+
+	require.NoError(t, WriteIdempotentConfig(base, path, MigrationTypeCopy))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	content = string(data)
+	assert.Contains(t, content, "TRUNCATE_TABLE 1\n")
+	assert.NotContains(t, content, "TRUNCATE_TABLE 0")
+	assert.Contains(t, content, "DROP_IF_EXISTS 0\n")
+}
+
+func TestCheckpointIncompleteTypes(t *testing.T) {
+	checkpoint := NewCheckpoint()
+	checkpoint.SetTypeStatus(MigrationTypeTable, StatusCompleted)
+	checkpoint.SetTypeStatus(MigrationTypeView, StatusFailed)
+	checkpoint.SetTypeStatus(MigrationTypeCopy, StatusRunning)
+	assert.Equal(t, []MigrationType{MigrationTypeCopy, MigrationTypeView}, checkpoint.IncompleteTypes())
+}
+
+func TestIdempotentRepeatedRuns(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟ora2pg依赖 /bin/sh")
+	}
+
+	// 模拟ora2pg和目标库：表已存在且未开启 DROP_IF_EXISTS 时失败，未开启 TRUNCATE_TABLE 时数据追加
+	bin := t.TempDir()
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+  case "$1" in
+    -t) type=$2 ;;
+    -c) conf=$2 ;;
+  esac
+  shift
+done
+mkdir -p db
+case "$type" in
+  TABLE)
+    if [ -f db/orders.table ] && ! grep -q "^DROP_IF_EXISTS 1" "$conf"; then
+      echo "FATAL: relation \"orders\" already exists"; exit 1
+    fi
+    echo "CREATE TABLE orders" > db/orders.table ;;
+  COPY)
+    if grep -q "^TRUNCATE_TABLE 1" "$conf"; then : > db/orders.rows; fi
+    echo "1" >> db/orders.rows
+    echo "2" >> db/orders.rows ;;
+esac
+echo "-- $type" > "output/$type.sql"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ora2pg"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Chdir(t.TempDir())
+
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("幂等")
+	cfg := manager.GetConfig()
+	cfg.Migration.OutputDir = "output"
+	require.NoError(t, os.MkdirAll("output", 0755))
+	require.NoError(t, os.WriteFile(filepath.Join("output", "ora2pg.conf"), []byte("ORACLE_DSN dbi:Oracle:host=db\n"), 0644))
+	types := []MigrationType{MigrationTypeTable, MigrationTypeCopy}
+
+	run := func(idempotent bool) []*ExecutionResult {
+		ms := NewMigrationService(cfg)
+		ms.SetIdempotent(idempotent)
+		results, err := ms.ExecuteWithProgress(context.Background(), types, NewProgressTracker())
+		require.NoError(t, err)
+		return results
+	}
+	snapshot := func() map[string]string {
+		files := make(map[string]string)
+		for _, dir := range []string{"db", "output"} {
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			for _, entry := range entries {
+				data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+				require.NoError(t, err)
+				files[filepath.Join(dir, entry.Name())] = string(data)
+			}
+		}
+		return files
+	}
+
+	// 上次中断的迁移留下了不完整的输出
+	require.NoError(t, os.WriteFile(filepath.Join("output", "COPY.partial.sql"), []byte("-- partial"), 0644))
+
+	for _, result := range run(true) {
+		assert.Equal(t, StatusCompleted, result.Status)
+	}
+	first := snapshot()
+	assert.Equal(t, "1\n2\n", first[filepath.Join("db", "orders.rows")])
+	assert.NotContains(t, first, filepath.Join("output", "COPY.partial.sql"))
+	assert.Contains(t, first[filepath.Join("output", "ora2pg.TABLE.idempotent.conf")], "DROP_IF_EXISTS 1")
+	assert.Contains(t, first[filepath.Join("output", "ora2pg.COPY.idempotent.conf")], "TRUNCATE_TABLE 1")
+	archives, err := filepath.Glob(filepath.Join("backup", "output-*.tar.gz"))
+	require.NoError(t, err)
+	assert.Len(t, archives, 1)
+
+	// 重复执行结果一致
+	for _, result := range run(true) {
+		assert.Equal(t, StatusCompleted, result.Status)
+	}
+	assert.Equal(t, first, snapshot())
+
+	// 非幂等模式下重跑时表已存在
+	results := run(false)
+	require.Len(t, results, 2)
+	assert.Equal(t, StatusFailed, results[0].Status)
+}
+
+func TestPrepareIdempotentRunKeepsOutputOnRetry(t *testing.T) {
+	t.Chdir(t.TempDir())
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("幂等")
+	cfg := manager.GetConfig()
+	cfg.Migration.OutputDir = "output"
+	require.NoError(t, os.MkdirAll("output", 0755))
+	require.NoError(t, os.WriteFile(filepath.Join("output", "TABLE.sql"), nil, 0644))
+
+	ms := NewMigrationService(cfg)
+	ms.SetIdempotent(true)
+	ms.SetRetry(&HistoryRecord{RunID: "r1"})
+	require.NoError(t, ms.prepareIdempotentRun())
+	assert.True(t, utils.NewFileUtils().FileExists(filepath.Join("output", "TABLE.sql")))
+
+	// 只有主配置时不需要归档
+	ms = NewMigrationService(cfg)
+	ms.SetIdempotent(true)
+	require.NoError(t, os.Remove(filepath.Join("output", "TABLE.sql")))
+	require.NoError(t, os.WriteFile(filepath.Join("output", "ora2pg.conf"), nil, 0644))
+	require.NoError(t, ms.prepareIdempotentRun())
+	assert.NoDirExists(t, "backup")
+}
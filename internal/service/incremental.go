@@ -0,0 +1,396 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/utils"
+)
+
+// DefaultWatermarkPath 默认增量水位文件路径（相对于项目根目录）
+var DefaultWatermarkPath = filepath.Join(".ora2pg-admin", "watermarks.json")
+
+// watermarkQueryTimeout 查询增量列水位的超时时间
+const watermarkQueryTimeout = 5 * time.Minute
+
+// SyncAction 一张表在本次数据迁移中的导出方式
+type SyncAction string
+
+const (
+	SyncInitial     SyncAction = "initial"     // 全量导出至当前水位，建立基线
+	SyncIncremental SyncAction = "incremental" // 只导出上次水位之后的数据
+	SyncFull        SyncAction = "full"        // 不按水位过滤，全量导出
+	SyncSkip        SyncAction = "skip"        // 不导出
+)
+
+// TableWatermark 一张表上次同步到的水位
+type TableWatermark struct {
+	Column string `json:"column"`
+	Type   string `json:"type"`
+	// Value 上次同步时增量列的最大值，表中当时没有非空值时为空
+	Value    string    `json:"value"`
+	SyncedAt time.Time `json:"synced_at"`
+}
+
+// WatermarkState 各表的增量水位，键为大写表名
+type WatermarkState struct {
+	UpdatedAt time.Time                  `json:"updated_at"`
+	Tables    map[string]*TableWatermark `json:"tables"`
+}
+
+// LoadWatermarks 加载增量水位文件，文件不存在时返回空的水位
+func LoadWatermarks(path string) (*WatermarkState, error) {
+	state := &WatermarkState{Tables: make(map[string]*TableWatermark)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return nil, utils.FileErrors.ReadFailed(path, err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, utils.NewError(utils.ErrorTypeMigration, "WATERMARK_CORRUPTED").
+			Message("增量水位文件已损坏").
+			Details(path).
+			Cause(err).
+			Suggestion("删除水位文件后执行一次完整的数据迁移重新建立基线").
+			Build()
+	}
+	if state.Tables == nil {
+		state.Tables = make(map[string]*TableWatermark)
+	}
+	return state, nil
+}
+
+// SaveWatermarks 保存增量水位文件（先写临时文件再重命名，避免中断时写坏）
+func SaveWatermarks(path string, state *WatermarkState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化增量水位失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return utils.FileErrors.CreateFailed(filepath.Dir(path), err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return utils.FileErrors.WriteFailed(tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return utils.FileErrors.WriteFailed(path, err)
+	}
+	return nil
+}
+
+// TableSyncPlan 一张增量表在本次数据迁移中的导出计划
+type TableSyncPlan struct {
+	Table  string
+	Column string
+	Type   string
+	Action SyncAction
+	// Condition 导出时的 WHERE 条件，为空表示不过滤
+	Condition string
+	// Low 上次同步的水位，High 本次导出的上限，成功后成为新的水位
+	Low  string
+	High string
+	// Reason 跳过、全量导出或需要注意的原因
+	Reason string
+}
+
+// SyncPlan 数据迁移的增量导出计划
+type SyncPlan struct {
+	// Incremental 是否为增量同步，否则为建立基线的全量迁移
+	Incremental bool
+	// OthersFull 增量同步时是否全量导出未配置或不可用增量列的表
+	OthersFull bool
+	Tables     []TableSyncPlan
+}
+
+// BuildSyncPlan 根据上次的水位和源库当前的水位生成导出计划
+//
+// 全量迁移时所有表都导出至当前水位（包括增量列为空的行），成功后记录水位作为基线；
+// 增量同步时只导出 (上次水位, 当前水位] 之间的行，没有基线的表不导出，避免与已迁移的数据重复。
+// 导出上限固定为查询时的水位，导出期间新写入的行留到下一次同步。
+func BuildSyncPlan(incremental *config.IncrementalConfig, incrementalMode bool, stored *WatermarkState, current []oracle.Watermark) *SyncPlan {
+	plan := &SyncPlan{Incremental: incrementalMode, OthersFull: incremental.OthersFull()}
+	watermarks := make(map[string]oracle.Watermark, len(current))
+	for _, watermark := range current {
+		watermarks[watermark.Table] = watermark
+	}
+
+	for _, table := range incremental.Tables {
+		item := TableSyncPlan{Table: table.TableName(), Column: table.ColumnName(), Type: table.ColumnType()}
+		watermark := watermarks[item.Table]
+		item.High = watermark.Value
+
+		var prior *TableWatermark
+		if stored != nil {
+			prior = stored.Tables[item.Table]
+		}
+		if prior != nil && (prior.Column != item.Column || prior.Type != item.Type) {
+			prior = nil
+		}
+
+		switch {
+		case watermark.Unusable != "":
+			item.Action = SyncFull
+			if incrementalMode && !plan.OthersFull {
+				item.Action = SyncSkip
+			}
+			item.Reason = watermark.Unusable
+		case !incrementalMode:
+			item.Action = SyncInitial
+			item.Condition = initialCondition(&item)
+			if watermark.Nullable {
+				item.Reason = "增量列允许为空，之后写入的空值行不会被增量同步"
+			}
+		case prior == nil:
+			item.Action = SyncSkip
+			item.Reason = "没有上次同步的水位，请先执行一次完整的数据迁移建立基线"
+		case item.High == "" || compareWatermarks(item.Type, item.High, prior.Value) <= 0:
+			item.Action = SyncSkip
+			item.Low = prior.Value
+			item.High = prior.Value
+			item.Reason = "没有新数据"
+		default:
+			item.Action = SyncIncremental
+			item.Low = prior.Value
+			item.Condition = incrementalCondition(&item)
+		}
+		plan.Tables = append(plan.Tables, item)
+	}
+	return plan
+}
+
+// initialCondition 建立基线时的导出条件：当前水位及之前的行，以及增量列为空的行
+func initialCondition(item *TableSyncPlan) string {
+	if item.High == "" {
+		return item.Column + " IS NULL"
+	}
+	return fmt.Sprintf("(%s <= %s OR %s IS NULL)", item.Column, watermarkLiteral(item.Type, item.High), item.Column)
+}
+
+// incrementalCondition 增量同步时的导出条件：上次水位之后、当前水位及之前的行
+func incrementalCondition(item *TableSyncPlan) string {
+	upper := fmt.Sprintf("%s <= %s", item.Column, watermarkLiteral(item.Type, item.High))
+	if item.Low == "" {
+		return upper
+	}
+	return fmt.Sprintf("%s > %s AND %s", item.Column, watermarkLiteral(item.Type, item.Low), upper)
+}
+
+// watermarkLiteral 水位在Oracle中的字面量
+func watermarkLiteral(kind, value string) string {
+	if kind == config.IncrementalTimestamp {
+		return fmt.Sprintf("TO_TIMESTAMP('%s', '%s')", strings.ReplaceAll(value, "'", "''"), oracle.WatermarkTimestampFormat)
+	}
+	return value
+}
+
+// compareWatermarks 比较两个水位，时间戳格式固定可按字符串比较，数值按数值比较
+func compareWatermarks(kind, a, b string) int {
+	if kind != config.IncrementalTimestamp {
+		x, okA := new(big.Float).SetString(a)
+		y, okB := new(big.Float).SetString(b)
+		if okA && okB {
+			return x.Cmp(y)
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// Validate 检查水位是否为合法的值，防止被篡改的水位文件把任意内容写入ora2pg配置
+func (p *SyncPlan) Validate() error {
+	for _, item := range p.Tables {
+		for _, value := range []string{item.Low, item.High} {
+			if value == "" {
+				continue
+			}
+			valid := false
+			if item.Type == config.IncrementalTimestamp {
+				_, err := time.Parse("2006-01-02 15:04:05.000000", value)
+				valid = err == nil
+			} else {
+				_, valid = new(big.Float).SetString(value)
+			}
+			if !valid {
+				return utils.NewError(utils.ErrorTypeMigration, "WATERMARK_INVALID").
+					Message(fmt.Sprintf("表 %s 的水位无效: %s", item.Table, value)).
+					Suggestion("删除水位文件后执行一次完整的数据迁移重新建立基线").
+					Build()
+			}
+		}
+	}
+	return nil
+}
+
+// Exported 本次导出的增量表
+func (p *SyncPlan) Exported() []TableSyncPlan {
+	var tables []TableSyncPlan
+	for _, item := range p.Tables {
+		if item.Action != SyncSkip {
+			tables = append(tables, item)
+		}
+	}
+	return tables
+}
+
+// NothingToExport 增量同步且不导出其他表时，是否没有任何表需要导出
+func (p *SyncPlan) NothingToExport() bool {
+	return p.Incremental && !p.OthersFull && len(p.Exported()) == 0
+}
+
+// Directives 生成ora2pg的 WHERE、ALLOW 指令，以及需要排除的表
+//
+// 增量同步且不导出其他表时，用 ALLOW 只导出有新数据的增量表；否则用 EXCLUDE 排除跳过的增量表。
+// 增量同步时关闭 TRUNCATE_TABLE，避免清空目标库中已同步的数据。
+func (p *SyncPlan) Directives() (lines []string, exclude []string) {
+	var conditions, allow []string
+	for _, item := range p.Tables {
+		if item.Condition != "" {
+			conditions = append(conditions, fmt.Sprintf("%s[%s]", item.Table, item.Condition))
+		}
+		if item.Action == SyncSkip {
+			exclude = append(exclude, item.Table)
+		} else {
+			allow = append(allow, item.Table)
+		}
+	}
+	if len(conditions) > 0 {
+		lines = append(lines, "WHERE "+strings.Join(conditions, " "))
+	}
+	if p.Incremental {
+		if !p.OthersFull {
+			sort.Strings(allow)
+			lines = append(lines, "ALLOW "+strings.Join(allow, " "))
+			exclude = nil
+		}
+		lines = append(lines, "TRUNCATE_TABLE 0")
+	}
+	return lines, exclude
+}
+
+// WriteSyncConfig 基于原始ora2pg配置生成带增量导出条件的配置
+func WriteSyncConfig(baseConfigPath, syncConfigPath string, plan *SyncPlan) error {
+	lines, exclude := plan.Directives()
+	comment := "由 ora2pg-admin 生成：按增量列水位导出（migration.incremental）"
+	drop := map[string]bool{"WHERE": true, "ALLOW": plan.Incremental && !plan.OthersFull}
+	if plan.Incremental {
+		comment = "由 ora2pg-admin 增量同步生成：只导出上次水位之后的数据（migration.incremental）"
+		drop["TRUNCATE_TABLE"] = true
+	}
+	return writeDerivedConfig(baseConfigPath, syncConfigPath, comment, exclude, drop, lines)
+}
+
+// isDataMigrationType 是否为导出数据的迁移类型
+func isDataMigrationType(migrationType MigrationType) bool {
+	return migrationType == MigrationTypeCopy || migrationType == MigrationTypeInsert
+}
+
+// prepareSyncPlan 查询源库水位并生成本次运行的导出计划，同一次运行中的数据类型共用一个计划
+func (ms *MigrationService) prepareSyncPlan(ctx context.Context) (*SyncPlan, error) {
+	if ms.syncPlan != nil {
+		return ms.syncPlan, nil
+	}
+
+	stored, err := LoadWatermarks(ms.watermarkPath)
+	if err != nil {
+		return nil, err
+	}
+
+	incremental := &ms.config.Migration.Incremental
+	columns := make([]oracle.WatermarkColumn, 0, len(incremental.Tables))
+	for _, table := range incremental.Tables {
+		columns = append(columns, oracle.WatermarkColumn{
+			Table:     table.TableName(),
+			Column:    table.ColumnName(),
+			Timestamp: table.ColumnType() == config.IncrementalTimestamp,
+		})
+	}
+	schema := ms.config.Oracle.Schema
+	if schema == "" {
+		schema = ms.config.Oracle.Username
+	}
+	queryCtx, cancel := context.WithTimeout(ctx, watermarkQueryTimeout)
+	defer cancel()
+	runner := oracle.NewSQLPlusRunner(&ms.config.Oracle, &ms.config.OracleClient)
+	current, err := oracle.NewInspector(runner, schema).Watermarks(queryCtx, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := BuildSyncPlan(incremental, ms.incremental, stored, current)
+	if err := plan.Validate(); err != nil {
+		return nil, err
+	}
+	for _, item := range plan.Tables {
+		switch {
+		case item.Action == SyncIncremental:
+			ms.logger.Infof("增量同步 %s: %s", item.Table, item.Condition)
+		case item.Reason != "":
+			ms.logger.Warnf("表 %s %s: %s", item.Table, syncActionText(item.Action), item.Reason)
+		}
+	}
+	ms.syncPlan = plan
+	return plan, nil
+}
+
+// commitWatermarks 数据类型成功完成后保存本次导出的上限作为新的水位，state 中同时保留一份
+//
+// skipped 为续传时上次已完成、本次未导出的表，这些表只导出到上次运行时的水位，不推进。
+func (ms *MigrationService) commitWatermarks(plan *SyncPlan, skipped []string) error {
+	stored, err := LoadWatermarks(ms.watermarkPath)
+	if err != nil {
+		return err
+	}
+	resumed := make(map[string]bool, len(skipped))
+	for _, table := range skipped {
+		resumed[strings.ToUpper(table)] = true
+	}
+	now := time.Now()
+	for _, item := range plan.Tables {
+		if item.Action != SyncInitial && item.Action != SyncIncremental {
+			continue
+		}
+		if resumed[item.Table] {
+			ms.logger.Warnf("表 %s 在上次运行中已完成，水位未记录，请重新执行完整的数据迁移建立基线", item.Table)
+			continue
+		}
+		stored.Tables[item.Table] = &TableWatermark{Column: item.Column, Type: item.Type, Value: item.High, SyncedAt: now}
+	}
+	stored.UpdatedAt = now
+	ms.state.Watermarks = stored.Tables
+	return SaveWatermarks(ms.watermarkPath, stored)
+}
+
+// syncActionText 导出方式的中文说明
+func syncActionText(action SyncAction) string {
+	switch action {
+	case SyncInitial:
+		return "全量导出并建立水位"
+	case SyncIncremental:
+		return "增量导出"
+	case SyncFull:
+		return "全量导出"
+	default:
+		return "不导出"
+	}
+}
+
+// SetIncremental 设置本次数据迁移是否为增量同步
+func (ms *MigrationService) SetIncremental(incremental bool) {
+	ms.incremental = incremental
+}
+
+// SetWatermarkPath 设置增量水位文件路径
+func (ms *MigrationService) SetWatermarkPath(path string) {
+	ms.watermarkPath = path
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+)
+
+// testIncrementalConfig 一张时间戳增量表和一张序列增量表
+func testIncrementalConfig() *config.IncrementalConfig {
+	return &config.IncrementalConfig{Tables: []config.IncrementalTableConfig{
+		{Name: "orders", Column: "updated_at"},
+		{Name: "events", Column: "id", Type: "sequence"},
+	}}
+}
+
+func TestBuildSyncPlanInitial(t *testing.T) {
+	current := []oracle.Watermark{
+		{Table: "ORDERS", Column: "UPDATED_AT", Value: "2024-05-01 10:00:00.000000", Nullable: true},
+		{Table: "EVENTS", Column: "ID"},
+	}
+	plan := BuildSyncPlan(testIncrementalConfig(), false, nil, current)
+	require.Len(t, plan.Tables, 2)
+
+	// 全量迁移导出至当前水位，包括增量列为空的行
+	orders := plan.Tables[0]
+	assert.Equal(t, SyncInitial, orders.Action)
+	assert.Equal(t, "(UPDATED_AT <= TO_TIMESTAMP('2024-05-01 10:00:00.000000', 'YYYY-MM-DD HH24:MI:SS.FF6') OR UPDATED_AT IS NULL)", orders.Condition)
+	assert.Contains(t, orders.Reason, "允许为空")
+
+	// 增量列没有值的表只导出空值行，之后写入的行由增量同步导出
+	assert.Equal(t, "ID IS NULL", plan.Tables[1].Condition)
+
+	lines, exclude := plan.Directives()
+	assert.Empty(t, exclude)
+	require.Len(t, lines, 1)
+	assert.True(t, strings.HasPrefix(lines[0], "WHERE ORDERS[(UPDATED_AT <= "))
+	assert.True(t, strings.HasSuffix(lines[0], " EVENTS[ID IS NULL]"))
+	assert.False(t, plan.NothingToExport())
+}
+
+func TestBuildSyncPlanIncremental(t *testing.T) {
+	stored := &WatermarkState{Tables: map[string]*TableWatermark{
+		"ORDERS": {Column: "UPDATED_AT", Type: "timestamp", Value: "2024-05-01 10:00:00.000000"},
+		"EVENTS": {Column: "ID", Type: "sequence", Value: "100"},
+	}}
+	current := []oracle.Watermark{
+		{Table: "ORDERS", Column: "UPDATED_AT", Value: "2024-05-02 08:30:00.500000"},
+		{Table: "EVENTS", Column: "ID", Value: "99"},
+	}
+	plan := BuildSyncPlan(testIncrementalConfig(), true, stored, current)
+	require.NoError(t, plan.Validate())
+
+	orders := plan.Tables[0]
+	assert.Equal(t, SyncIncremental, orders.Action)
+	assert.Equal(t, "UPDATED_AT > TO_TIMESTAMP('2024-05-01 10:00:00.000000', 'YYYY-MM-DD HH24:MI:SS.FF6') AND "+
+		"UPDATED_AT <= TO_TIMESTAMP('2024-05-02 08:30:00.500000', 'YYYY-MM-DD HH24:MI:SS.FF6')", orders.Condition)
+
+	// 当前最大值不大于上次的水位时没有新数据，水位保持不变
+	events := plan.Tables[1]
+	assert.Equal(t, SyncSkip, events.Action)
+	assert.Equal(t, "100", events.High)
+	assert.Equal(t, "没有新数据", events.Reason)
+
+	// 默认只导出有新数据的增量表，并关闭 TRUNCATE_TABLE
+	lines, exclude := plan.Directives()
+	assert.Empty(t, exclude)
+	assert.Equal(t, []string{"WHERE ORDERS[" + orders.Condition + "]", "ALLOW ORDERS", "TRUNCATE_TABLE 0"}, lines)
+
+	// 其他表全量导出时改为排除没有新数据的表
+	incremental := testIncrementalConfig()
+	incremental.Others = "full"
+	plan = BuildSyncPlan(incremental, true, stored, current)
+	lines, exclude = plan.Directives()
+	assert.Equal(t, []string{"EVENTS"}, exclude)
+	assert.Equal(t, []string{"WHERE ORDERS[" + orders.Condition + "]", "TRUNCATE_TABLE 0"}, lines)
+}
+
+func TestBuildSyncPlanUnusableAndMissingBaseline(t *testing.T) {
+	current := []oracle.Watermark{
+		{Table: "ORDERS", Column: "UPDATED_AT", Unusable: "表 ORDERS 中没有列 UPDATED_AT"},
+		{Table: "EVENTS", Column: "ID", Value: "10"},
+	}
+
+	// 增量同步时：增量列不可用的表按 others 处理，没有基线的表不导出
+	plan := BuildSyncPlan(testIncrementalConfig(), true, &WatermarkState{}, current)
+	assert.Equal(t, SyncSkip, plan.Tables[0].Action)
+	assert.Contains(t, plan.Tables[0].Reason, "没有列")
+	assert.Equal(t, SyncSkip, plan.Tables[1].Action)
+	assert.Contains(t, plan.Tables[1].Reason, "建立基线")
+	assert.True(t, plan.NothingToExport())
+
+	// 全量迁移时增量列不可用的表照常全量导出，不记录水位
+	plan = BuildSyncPlan(testIncrementalConfig(), false, nil, current)
+	assert.Equal(t, SyncFull, plan.Tables[0].Action)
+	assert.Empty(t, plan.Tables[0].Condition)
+
+	// 增量列变更后原水位失效
+	stored := &WatermarkState{Tables: map[string]*TableWatermark{"EVENTS": {Column: "SEQ_NO", Type: "sequence", Value: "5"}}}
+	plan = BuildSyncPlan(testIncrementalConfig(), true, stored, current)
+	assert.Equal(t, SyncSkip, plan.Tables[1].Action)
+	assert.Contains(t, plan.Tables[1].Reason, "建立基线")
+}
+
+func TestSyncPlanValidate(t *testing.T) {
+	plan := &SyncPlan{Tables: []TableSyncPlan{{Table: "EVENTS", Type: "sequence", Low: "1 OR 1=1", High: "5"}}}
+	assert.Error(t, plan.Validate())
+
+	plan = &SyncPlan{Tables: []TableSyncPlan{{Table: "ORDERS", Type: "timestamp", High: "2024-05-01') OR ('1"}}}
+	assert.Error(t, plan.Validate())
+
+	plan = &SyncPlan{Tables: []TableSyncPlan{{Table: "EVENTS", Type: "sequence", Low: "1.5", High: "1E+3"}}}
+	assert.NoError(t, plan.Validate())
+	assert.Equal(t, 1, compareWatermarks("sequence", "1000", "999"))
+	assert.Equal(t, -1, compareWatermarks("timestamp", "2024-05-01 09:00:00.000000", "2024-05-01 10:00:00.000000"))
+}
+
+func TestWriteSyncConfig(t *testing.T) {
+	dir := t.TempDir()
+	basePath := filepath.Join(dir, "ora2pg.conf")
+	syncPath := filepath.Join(dir, "ora2pg.COPY.incremental.conf")
+	base := "ORACLE_DSN=dbi:Oracle:host=db\nTRUNCATE_TABLE=1\nEXCLUDE TMP_DATA\nTYPE=COPY\n"
+	require.NoError(t, os.WriteFile(basePath, []byte(base), 0644))
+
+	plan := &SyncPlan{Incremental: true, Tables: []TableSyncPlan{
+		{Table: "ORDERS", Action: SyncIncremental, Condition: "ID > 5 AND ID <= 10"},
+	}}
+	require.NoError(t, WriteSyncConfig(basePath, syncPath, plan))
+
+	content, err := os.ReadFile(syncPath)
+	require.NoError(t, err)
+	text := string(content)
+	assert.Contains(t, text, "TYPE=COPY\n")
+	assert.NotContains(t, text, "TRUNCATE_TABLE=1")
+	assert.Contains(t, text, "EXCLUDE TMP_DATA\n")
+	assert.True(t, strings.HasSuffix(text, "WHERE ORDERS[ID > 5 AND ID <= 10]\nALLOW ORDERS\nTRUNCATE_TABLE 0\n"))
+}
+
+func TestCommitWatermarks(t *testing.T) {
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("增量同步")
+	ms := NewMigrationService(manager.GetConfig())
+	path := filepath.Join(t.TempDir(), "watermarks.json")
+	ms.SetWatermarkPath(path)
+
+	// 没有水位文件时为空
+	state, err := LoadWatermarks(path)
+	require.NoError(t, err)
+	assert.Empty(t, state.Tables)
+
+	plan := &SyncPlan{Tables: []TableSyncPlan{
+		{Table: "ORDERS", Column: "UPDATED_AT", Type: "timestamp", Action: SyncInitial, High: "2024-05-01 10:00:00.000000"},
+		{Table: "EVENTS", Column: "ID", Type: "sequence", Action: SyncSkip, High: "7"},
+		{Table: "LOGS", Column: "ID", Type: "sequence", Action: SyncFull},
+	}}
+	require.NoError(t, ms.commitWatermarks(plan, nil))
+
+	state, err = LoadWatermarks(path)
+	require.NoError(t, err)
+	require.Len(t, state.Tables, 1)
+	assert.Equal(t, "2024-05-01 10:00:00.000000", state.Tables["ORDERS"].Value)
+	assert.WithinDuration(t, time.Now(), state.Tables["ORDERS"].SyncedAt, time.Minute)
+	assert.Equal(t, state.Tables["ORDERS"].Value, ms.GetState().Watermarks["ORDERS"].Value)
+
+	// 续传时上次已完成的表不推进水位
+	ms.SetWatermarkPath(filepath.Join(t.TempDir(), "resumed.json"))
+	require.NoError(t, ms.commitWatermarks(plan, []string{"orders"}))
+	assert.Empty(t, ms.GetState().Watermarks)
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+	_, err = LoadWatermarks(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// DefaultLockPath 默认迁移锁文件路径（相对于项目根目录）
+var DefaultLockPath = filepath.Join(".ora2pg-admin", "migration.lock")
+
+// LockInfo 迁移锁文件的内容，记录持有锁的进程
+type LockInfo struct {
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	User      string    `json:"user,omitempty"`
+	RunID     string    `json:"run_id"`
+	Command   string    `json:"command,omitempty"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// String 持有锁的进程的描述
+func (info *LockInfo) String() string {
+	return fmt.Sprintf("PID %d（%s@%s），开始于 %s，运行ID %s",
+		info.PID, info.User, info.Host, info.StartedAt.Format("2006-01-02 15:04:05"), info.RunID)
+}
+
+// MigrationLock 当前进程持有的迁移锁，同一项目同时只允许一个迁移运行
+type MigrationLock struct {
+	path string
+	info LockInfo
+
+	// Replaced 获取锁时清理或强制替换的旧锁，没有时为 nil
+	Replaced *LockInfo
+	// Forced 旧锁的进程仍在运行（或无法确认），由 force 强制替换
+	Forced bool
+}
+
+// AcquireMigrationLock 创建迁移锁文件，已有迁移在运行时返回 MIGRATION_LOCKED 错误
+//
+// 锁的进程在本机已退出时视为陈旧锁，清理后重新获取；其他主机上的锁无法确认进程状态，视为仍在运行。
+// force 为 true 时无论旧锁进程是否存活都强制替换。
+func AcquireMigrationLock(path, command string, force bool) (*MigrationLock, error) {
+	host, _ := os.Hostname()
+	lock := &MigrationLock{
+		path: path,
+		info: LockInfo{
+			PID:       os.Getpid(),
+			Host:      host,
+			User:      utils.CurrentUsername(),
+			RunID:     utils.RunID(),
+			Command:   command,
+			StartedAt: time.Now(),
+		},
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, utils.FileErrors.CreateFailed(filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(&lock.info, "", "  ")
+	if err != nil {
+		return nil, utils.FileErrors.WriteFailed(path, err)
+	}
+
+	// 清理陈旧锁后与其他进程竞争时可能再次失败，最多重试一次
+	for attempt := 0; attempt < 2; attempt++ {
+		created, err := createLockFile(path, data)
+		if err != nil {
+			return nil, utils.FileErrors.CreateFailed(path, err)
+		}
+		if created {
+			return lock, nil
+		}
+
+		holder, readErr := ReadMigrationLock(path)
+		if os.IsNotExist(readErr) {
+			continue
+		}
+		alive := readErr == nil && holder.alive(lock.info)
+		if alive && !force {
+			return nil, migrationLockedError(path, holder)
+		}
+		// 锁文件损坏、进程已退出或强制获取：删除旧锁，删除前确认没有被其他进程替换
+		if current, err := ReadMigrationLock(path); err == nil && holder != nil && *current != *holder {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, utils.NewError(utils.ErrorTypeFile, "LOCK_REMOVE_FAILED").
+				Message("无法删除旧的迁移锁").
+				Details(path).
+				Cause(err).
+				Suggestion("检查 .ora2pg-admin 目录的权限").
+				Build()
+		}
+		lock.Replaced, lock.Forced = holder, alive
+	}
+
+	holder, _ := ReadMigrationLock(path)
+	return nil, migrationLockedError(path, holder)
+}
+
+// createLockFile 原子地创建带内容的锁文件，已存在时返回 false
+//
+// 先写入临时文件再硬链接到锁路径，其他进程不会读到写了一半的锁；文件系统不支持硬链接时退回到独占创建。
+func createLockFile(path string, data []byte) (bool, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return false, err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return false, err
+	}
+
+	err = os.Link(tmpPath, path)
+	if err == nil {
+		return true, nil
+	}
+	if os.IsExist(err) {
+		return false, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err == nil, err
+}
+
+// ReadMigrationLock 读取迁移锁文件，不存在时返回的错误满足 os.IsNotExist
+func ReadMigrationLock(path string) (*LockInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var info LockInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("迁移锁文件 %s 格式错误: %v", path, err)
+	}
+	return &info, nil
+}
+
+// alive 持有锁的进程是否可能仍在运行
+//
+// 同一进程的不同运行ID说明PID被复用（如容器重启后），视为已退出。
+func (info *LockInfo) alive(self LockInfo) bool {
+	if info.Host != self.Host {
+		return true
+	}
+	if info.PID == self.PID {
+		return info.RunID == self.RunID
+	}
+	return processAlive(info.PID)
+}
+
+// migrationLockedError 已有迁移在运行的错误
+func migrationLockedError(path string, holder *LockInfo) *utils.AppError {
+	details := "锁文件: " + path
+	if holder != nil {
+		details = holder.String()
+		if holder.Command != "" {
+			details += "，命令: " + holder.Command
+		}
+	}
+	return utils.NewError(utils.ErrorTypeMigration, "MIGRATION_LOCKED").
+		Message("已有迁移在运行").
+		Details(details).
+		Suggestion("等待该迁移结束，可使用 'ora2pg-admin 日志 跟踪 <类型>' 查看其进度").
+		Suggestion(fmt.Sprintf("确认没有迁移在运行（如其他主机上的进程已退出）时，加 --force 强制获取锁，或删除 %s", path)).
+		Build()
+}
+
+// Info 锁的内容
+func (l *MigrationLock) Info() LockInfo {
+	return l.info
+}
+
+// Release 释放迁移锁，锁已被其他进程强制替换时保留其锁文件
+func (l *MigrationLock) Release() error {
+	current, err := ReadMigrationLock(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return utils.FileErrors.ReadFailed(l.path, err)
+	}
+	if current.PID != l.info.PID || current.RunID != l.info.RunID {
+		return nil
+	}
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return utils.NewError(utils.ErrorTypeFile, "LOCK_REMOVE_FAILED").
+			Message("无法释放迁移锁").
+			Details(l.path).
+			Cause(err).
+			Suggestion(fmt.Sprintf("手动删除 %s", l.path)).
+			Build()
+	}
+	return nil
+}
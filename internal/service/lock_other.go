@@ -0,0 +1,8 @@
+//go:build plan9
+
+package service
+
+// processAlive 无法检查进程状态，视为仍在运行，陈旧锁需要 --force 替换
+func processAlive(pid int) bool {
+	return pid > 0
+}
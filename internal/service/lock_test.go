@@ -0,0 +1,204 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// lockHelperEnv 子进程中尝试获取的锁文件路径
+const lockHelperEnv = "ORA2PG_ADMIN_LOCK_HELPER"
+
+// writeLockFile 写入指定进程持有的锁文件
+func writeLockFile(t *testing.T, path string, info LockInfo) {
+	t.Helper()
+	data, err := json.Marshal(info)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, data, 0644))
+}
+
+// exitedPID 已退出的子进程的PID
+func exitedPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	require.NoError(t, cmd.Run())
+	return cmd.Process.Pid
+}
+
+func assertLockedError(t *testing.T, err error) {
+	t.Helper()
+	require.Error(t, err)
+	appErr, ok := err.(*utils.AppError)
+	require.True(t, ok)
+	assert.Equal(t, "MIGRATION_LOCKED", appErr.Code)
+	assert.Equal(t, "已有迁移在运行", appErr.Message)
+}
+
+func TestAcquireAndReleaseMigrationLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".ora2pg-admin", "migration.lock")
+
+	lock, err := AcquireMigrationLock(path, "ora2pg-admin 迁移 数据", false)
+	require.NoError(t, err)
+	assert.Nil(t, lock.Replaced)
+
+	info, err := ReadMigrationLock(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), info.PID)
+	assert.Equal(t, utils.RunID(), info.RunID)
+	assert.Equal(t, "ora2pg-admin 迁移 数据", info.Command)
+	assert.WithinDuration(t, time.Now(), info.StartedAt, time.Minute)
+
+	// 同一次运行再次获取被拒绝
+	_, err = AcquireMigrationLock(path, "ora2pg-admin 迁移 结构", false)
+	assertLockedError(t, err)
+
+	require.NoError(t, lock.Release())
+	assert.NoFileExists(t, path)
+	require.NoError(t, lock.Release())
+
+	// 释放后可以重新获取，临时文件不残留
+	lock, err = AcquireMigrationLock(path, "", false)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestMigrationLockRejectsConcurrentAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migration.lock")
+
+	const workers = 8
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		acquired []*MigrationLock
+		rejected int
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lock, err := AcquireMigrationLock(path, "", false)
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				acquired = append(acquired, lock)
+				return
+			}
+			if appErr, ok := err.(*utils.AppError); ok && appErr.Code == "MIGRATION_LOCKED" {
+				rejected++
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, acquired, 1)
+	assert.Equal(t, workers-1, rejected)
+	require.NoError(t, acquired[0].Release())
+}
+
+func TestMigrationLockRejectsOtherProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migration.lock")
+	lock, err := AcquireMigrationLock(path, "", false)
+	require.NoError(t, err)
+	defer lock.Release()
+
+	// 另一个进程启动迁移时被拒绝，且不影响已有的锁
+	cmd := exec.Command(os.Args[0], "-test.run=^TestMigrationLockHelperProcess$")
+	cmd.Env = append(os.Environ(), lockHelperEnv+"="+path)
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	assert.Contains(t, string(output), "result=MIGRATION_LOCKED")
+
+	info, err := ReadMigrationLock(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.Getpid(), info.PID)
+}
+
+// TestMigrationLockHelperProcess 作为子进程运行时尝试获取锁并输出结果
+func TestMigrationLockHelperProcess(t *testing.T) {
+	path := os.Getenv(lockHelperEnv)
+	if path == "" {
+		t.Skip("仅在 TestMigrationLockRejectsOtherProcess 的子进程中运行")
+	}
+	lock, err := AcquireMigrationLock(path, "", false)
+	if err != nil {
+		if appErr, ok := err.(*utils.AppError); ok {
+			fmt.Printf("result=%s\n", appErr.Code)
+			return
+		}
+		fmt.Printf("result=%v\n", err)
+		return
+	}
+	lock.Release()
+	fmt.Println("result=ACQUIRED")
+}
+
+func TestMigrationLockStaleAndForce(t *testing.T) {
+	host, _ := os.Hostname()
+	path := filepath.Join(t.TempDir(), "migration.lock")
+
+	// 进程已退出的陈旧锁自动清理
+	stale := LockInfo{PID: exitedPID(t), Host: host, RunID: "20240101-000000-aaaaaa", StartedAt: time.Now().Add(-time.Hour).Truncate(time.Second)}
+	writeLockFile(t, path, stale)
+	lock, err := AcquireMigrationLock(path, "", false)
+	require.NoError(t, err)
+	require.NotNil(t, lock.Replaced)
+	assert.Equal(t, stale.PID, lock.Replaced.PID)
+	assert.False(t, lock.Forced)
+	require.NoError(t, lock.Release())
+
+	// 同一PID但运行ID不同说明PID被复用，同样视为陈旧锁
+	writeLockFile(t, path, LockInfo{PID: os.Getpid(), Host: host, RunID: "20240101-000000-bbbbbb"})
+	lock, err = AcquireMigrationLock(path, "", false)
+	require.NoError(t, err)
+	assert.NotNil(t, lock.Replaced)
+	require.NoError(t, lock.Release())
+
+	// 损坏的锁文件直接替换
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+	lock, err = AcquireMigrationLock(path, "", false)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+
+	// 进程仍在运行或在其他主机上的锁被拒绝，--force 时强制替换
+	for _, holder := range []LockInfo{
+		{PID: os.Getppid(), Host: host, RunID: "20240101-000000-cccccc"},
+		{PID: exitedPID(t), Host: host + "-other", RunID: "20240101-000000-dddddd"},
+	} {
+		writeLockFile(t, path, holder)
+		_, err = AcquireMigrationLock(path, "", false)
+		assertLockedError(t, err)
+
+		lock, err = AcquireMigrationLock(path, "", true)
+		require.NoError(t, err)
+		require.NotNil(t, lock.Replaced)
+		assert.Equal(t, holder.PID, lock.Replaced.PID)
+		assert.True(t, lock.Forced)
+		require.NoError(t, lock.Release())
+	}
+}
+
+func TestMigrationLockReleaseKeepsReplacedLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "migration.lock")
+	lock, err := AcquireMigrationLock(path, "", false)
+	require.NoError(t, err)
+
+	// 锁被其他进程强制替换后，释放时不删除其他进程的锁
+	host, _ := os.Hostname()
+	writeLockFile(t, path, LockInfo{PID: os.Getppid(), Host: host, RunID: "20240101-000000-eeeeee"})
+	require.NoError(t, lock.Release())
+	assert.FileExists(t, path)
+}
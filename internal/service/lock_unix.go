@@ -0,0 +1,14 @@
+//go:build !windows && !plan9
+
+package service
+
+import "syscall"
+
+// processAlive 通过发送0号信号检查进程是否存在，无权限发送信号时进程也存在
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
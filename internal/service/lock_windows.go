@@ -0,0 +1,26 @@
+//go:build windows
+
+package service
+
+import "golang.org/x/sys/windows"
+
+// stillActive GetExitCodeProcess 对仍在运行的进程返回的退出码
+const stillActive = 259
+
+// processAlive 打开进程并检查其退出码，无权限打开时进程也存在
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return err == windows.ERROR_ACCESS_DENIED
+	}
+	defer windows.CloseHandle(handle)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(handle, &code); err != nil {
+		return true
+	}
+	return code == stillActive
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"ora2pg-admin/internal/config"
+)
+
+// maskedValue 脱敏后替换数据的占位内容
+const maskedValue = "***"
+
+var (
+	// copyStartPattern COPY ... FROM STDIN 语句，其后直到 \. 的行都是数据行
+	copyStartPattern = regexp.MustCompile(`(?i)^\s*COPY\s+\S+.*\bFROM\s+STDIN\b`)
+	// insertValuesPattern INSERT语句的 VALUES 部分，语句可能出现在错误信息中间
+	insertValuesPattern = regexp.MustCompile(`(?i)(\bINSERT\s+INTO\s+[^\s(]+(?:\s*\([^)]*\))?\s*VALUES\s*)\(.*$`)
+	// insertStartPattern 行首的INSERT语句，多行VALUES时直到 ; 结尾的行都属于该语句
+	insertStartPattern = regexp.MustCompile(`(?i)^\s*INSERT\s+INTO\s`)
+	// errorDetailPatterns PostgreSQL错误详情中带出的数据，如 "Key (id)=(42) already exists"
+	errorDetailPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(\bKey\s+\([^)]*\)=\().*?(\)\s+(?:already exists|is not present|is still referenced)|\)\s*$)`),
+		regexp.MustCompile(`(\bFailing row contains \().*(\))`),
+	}
+	// progressBarPattern ora2pg的进度条行，如 "[====>] 14/14 rows (100.0%) Table COUNTRIES"
+	progressBarPattern = regexp.MustCompile(`^\s*\[[=>\s]*\]`)
+)
+
+// LogDataFilter 识别ora2pg输出中的实际数据行并按级别脱敏或丢弃，只保留进度和状态信息
+//
+// 数据行包括 COPY ... FROM STDIN 到 \. 之间的行、INSERT 语句（含多行的 VALUES）和匹配自定义正则的行；
+// 进度条、带级别标记的错误和警告始终保留，其中的 VALUES 和错误详情中的键值替换为 ***。
+type LogDataFilter struct {
+	drop     bool
+	patterns []*regexp.Regexp
+	filtered atomic.Int64
+}
+
+// NewLogDataFilter 按配置创建过滤器，未启用时返回nil；无效的自定义正则已在配置验证中报告，这里跳过
+func NewLogDataFilter(cfg *config.LogFilterConfig) *LogDataFilter {
+	if cfg == nil || !cfg.Enabled() {
+		return nil
+	}
+	filter := &LogDataFilter{drop: cfg.EffectiveLevel() == config.LogFilterDrop}
+	for _, pattern := range cfg.DataPatterns {
+		if re, err := regexp.Compile(pattern); err == nil {
+			filter.patterns = append(filter.patterns, re)
+		}
+	}
+	return filter
+}
+
+// Filtered 已脱敏或丢弃的数据行数
+func (f *LogDataFilter) Filtered() int64 {
+	if f == nil {
+		return 0
+	}
+	return f.filtered.Load()
+}
+
+// Stream 为一个输出流创建过滤状态，stdout和stderr各自跟踪所在的COPY块和INSERT语句
+func (f *LogDataFilter) Stream() *LogDataStream {
+	if f == nil {
+		return nil
+	}
+	return &LogDataStream{filter: f}
+}
+
+// LogDataStream 单个输出流的过滤状态，不能并发使用
+type LogDataStream struct {
+	filter   *LogDataFilter
+	inCopy   bool
+	inInsert bool
+}
+
+// Filter 过滤一行输出，返回写入日志的内容和是否保留该行；nil 时原样保留
+func (s *LogDataStream) Filter(line string) (string, bool) {
+	if s == nil {
+		return line, true
+	}
+	trimmed := strings.TrimSpace(line)
+
+	switch {
+	case s.inCopy:
+		if trimmed == `\.` {
+			s.inCopy = false
+			return line, true
+		}
+		if isStatusLine(line) {
+			return s.maskDiagnostic(line), true
+		}
+		return s.dataRow(fmt.Sprintf("[已过滤数据行，%d 列]", strings.Count(line, "\t")+1))
+	case s.inInsert:
+		s.inInsert = !strings.HasSuffix(trimmed, ";")
+		return s.dataRow("[已过滤INSERT数据]")
+	case copyStartPattern.MatchString(line):
+		s.inCopy = true
+		return line, true
+	case insertStartPattern.MatchString(line):
+		s.inInsert = !strings.HasSuffix(trimmed, ";")
+		if s.filter.drop {
+			return s.dataRow("")
+		}
+		s.filter.filtered.Add(1)
+		return insertValuesPattern.ReplaceAllString(line, "${1}("+maskedValue+")"), true
+	}
+
+	for _, pattern := range s.filter.patterns {
+		if pattern.MatchString(line) {
+			return s.dataRow("[已过滤数据行]")
+		}
+	}
+	return s.maskDiagnostic(line), true
+}
+
+// dataRow 记录一行被过滤的数据，drop 时丢弃，否则替换为占位行
+func (s *LogDataStream) dataRow(placeholder string) (string, bool) {
+	s.filter.filtered.Add(1)
+	if s.filter.drop {
+		return "", false
+	}
+	return placeholder, true
+}
+
+// maskDiagnostic 脱敏状态和错误行中带出的数据，保留其余诊断信息
+func (s *LogDataStream) maskDiagnostic(line string) string {
+	masked := insertValuesPattern.ReplaceAllString(line, "${1}("+maskedValue+")")
+	for _, pattern := range errorDetailPatterns {
+		masked = pattern.ReplaceAllString(masked, "${1}"+maskedValue+"${2}")
+	}
+	if masked != line {
+		s.filter.filtered.Add(1)
+	}
+	return masked
+}
+
+// isStatusLine 是否为进度条或带级别标记的状态行，即使出现在COPY块中也不是数据
+func isStatusLine(line string) bool {
+	return progressBarPattern.MatchString(line) || logLevelPattern.MatchString(line)
+}
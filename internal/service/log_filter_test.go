@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+)
+
+// filterLines 依次过滤多行输出，返回保留的行
+func filterLines(stream *LogDataStream, lines ...string) []string {
+	var kept []string
+	for _, line := range lines {
+		if filtered, keep := stream.Filter(line); keep {
+			kept = append(kept, filtered)
+		}
+	}
+	return kept
+}
+
+func TestLogDataFilterMask(t *testing.T) {
+	filter := NewLogDataFilter(&config.LogFilterConfig{Level: config.LogFilterMask, DataPatterns: []string{`^ROW:`}})
+	require.NotNil(t, filter)
+
+	kept := filterLines(filter.Stream(),
+		"[2024-01-01 10:00:00] Dumping data from table CUSTOMERS",
+		"COPY customers (id,name,phone) FROM STDIN;",
+		"1\t张三\t13800000000",
+		"[====>] 1/2 rows (50.0%) Table CUSTOMERS",
+		"2\t李四\t13900000000",
+		`\.`,
+		"INSERT INTO orders (id,note) VALUES (1,'张三的订单');",
+		"INSERT INTO orders (id,note) VALUES",
+		"(2,'李四'),",
+		"(3,'王五');",
+		"DBD::Pg::db do failed: ERROR:  duplicate key value violates unique constraint \"customers_pkey\"",
+		"DETAIL:  Key (id)=(42) already exists.",
+		"ERROR: null value in column \"name\" violates not-null constraint at ... INSERT INTO customers VALUES (7, NULL, '138');",
+		"DETAIL:  Failing row contains (7, null, 138).",
+		"ROW: secret",
+		"[========================>] 2/2 rows (100.0%) Table CUSTOMERS (2 recs/sec)",
+	)
+	assert.Equal(t, []string{
+		"[2024-01-01 10:00:00] Dumping data from table CUSTOMERS",
+		"COPY customers (id,name,phone) FROM STDIN;",
+		"[已过滤数据行，3 列]",
+		"[====>] 1/2 rows (50.0%) Table CUSTOMERS",
+		"[已过滤数据行，3 列]",
+		`\.`,
+		"INSERT INTO orders (id,note) VALUES (***)",
+		"INSERT INTO orders (id,note) VALUES",
+		"[已过滤INSERT数据]",
+		"[已过滤INSERT数据]",
+		"DBD::Pg::db do failed: ERROR:  duplicate key value violates unique constraint \"customers_pkey\"",
+		"DETAIL:  Key (id)=(***) already exists.",
+		"ERROR: null value in column \"name\" violates not-null constraint at ... INSERT INTO customers VALUES (***)",
+		"DETAIL:  Failing row contains (***).",
+		"[已过滤数据行]",
+		"[========================>] 2/2 rows (100.0%) Table CUSTOMERS (2 recs/sec)",
+	}, kept)
+	assert.Equal(t, int64(10), filter.Filtered())
+}
+
+func TestLogDataFilterDrop(t *testing.T) {
+	filter := NewLogDataFilter(&config.LogFilterConfig{Level: "DROP"})
+	kept := filterLines(filter.Stream(),
+		"COPY customers (id,name) FROM STDIN;",
+		"1\t张三",
+		"WARNING: invalid byte sequence",
+		`\.`,
+		"INSERT INTO orders VALUES (1,'张三');",
+		"DETAIL:  Key (id)=(42) is not present in table \"customers\".",
+		"Total time to export data: 1 sec",
+	)
+	// 数据行丢弃，警告和错误详情脱敏后保留
+	assert.Equal(t, []string{
+		"COPY customers (id,name) FROM STDIN;",
+		"WARNING: invalid byte sequence",
+		`\.`,
+		"DETAIL:  Key (id)=(***) is not present in table \"customers\".",
+		"Total time to export data: 1 sec",
+	}, kept)
+	assert.Equal(t, int64(3), filter.Filtered())
+
+	// 未启用时不创建过滤器，nil 原样保留
+	assert.Nil(t, NewLogDataFilter(&config.LogFilterConfig{}))
+	line, keep := NewLogDataFilter(&config.LogFilterConfig{Level: config.LogFilterOff}).Stream().Filter("1\t张三")
+	assert.True(t, keep)
+	assert.Equal(t, "1\t张三", line)
+}
+
+func TestExecuteWithLogFilter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟ora2pg依赖 /bin/sh")
+	}
+
+	// 模拟ora2pg：stdout输出COPY块，stderr输出带数据的错误详情
+	bin := t.TempDir()
+	script := `#!/bin/sh
+echo 'COPY customers (id,name) FROM STDIN;'
+printf '1\t张三\n2\t李四\n'
+echo '\.'
+echo '[========================>] 2/2 rows (100.0%) Table CUSTOMERS (2 recs/sec)'
+echo 'DETAIL:  Key (id)=(42) already exists.' >&2
+exit 0
+`
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ora2pg"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	var mu sync.Mutex
+	var handled []string
+	service := NewOra2pgService()
+	result, err := service.Execute(context.Background(), MigrationTypeCopy, &ExecutionOptions{
+		LogFilter: NewLogDataFilter(&config.LogFilterConfig{Level: config.LogFilterDrop}),
+		LineHandler: func(line string) {
+			mu.Lock()
+			defer mu.Unlock()
+			handled = append(handled, line)
+		},
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, result.Output, "张三")
+	assert.NotContains(t, handled, "2\t李四")
+	assert.Contains(t, result.Output, "2/2 rows (100.0%) Table CUSTOMERS")
+	assert.Equal(t, "DETAIL:  Key (id)=(***) already exists.\n", result.ErrorOutput)
+	assert.Equal(t, int64(2), result.MigratedRows)
+}
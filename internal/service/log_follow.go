@@ -0,0 +1,315 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// DefaultLogDir ora2pg日志所在目录（相对于项目根目录）
+const DefaultLogDir = "logs"
+
+// defaultFollowInterval 跟踪日志时检查文件变化的间隔
+const defaultFollowInterval = 500 * time.Millisecond
+
+// typeLogPattern 迁移类型日志文件名：ora2pg-<类型>-<时间>.log，切割时为 .partN.log
+var typeLogPattern = regexp.MustCompile(`^ora2pg-([A-Z]+)-(\d{8}-\d{6})(?:\.part(\d+))?\.log$`)
+
+// typeLogFile 一个迁移类型日志文件及其排序依据
+type typeLogFile struct {
+	path      string
+	timestamp string
+	part      int
+}
+
+// LatestTypeLog 日志目录中指定迁移类型最新的ora2pg日志，按运行时间和分段号排序，没有时返回空字符串
+func LatestTypeLog(logDir string, migrationType MigrationType) (string, error) {
+	entries, err := os.ReadDir(logDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", utils.FileErrors.ReadFailed(logDir, err)
+	}
+
+	var files []typeLogFile
+	for _, entry := range entries {
+		matches := typeLogPattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil || MigrationType(matches[1]) != migrationType {
+			continue
+		}
+		part, _ := strconv.Atoi(matches[3])
+		files = append(files, typeLogFile{path: filepath.Join(logDir, entry.Name()), timestamp: matches[2], part: part})
+	}
+	if len(files) == 0 {
+		return "", nil
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].timestamp != files[j].timestamp {
+			return files[i].timestamp < files[j].timestamp
+		}
+		return files[i].part < files[j].part
+	})
+	return files[len(files)-1].path, nil
+}
+
+// LogFollowOptions 跟踪日志的选项
+type LogFollowOptions struct {
+	// Lines 开始跟踪时先显示已有日志的最后几行
+	Lines int
+	// Filter 只显示包含该关键字的行（不区分大小写），为空时显示全部
+	Filter string
+	// Interval 检查文件变化的间隔，默认500毫秒
+	Interval time.Duration
+	// OnWaiting 日志文件还不存在、开始等待时调用
+	OnWaiting func()
+	// OnFile 开始读取一个日志文件时调用，包括切换到新的分段或新的运行
+	OnFile func(path string)
+	// LineHandler 处理每一行通过过滤的日志
+	LineHandler func(line string)
+}
+
+// LogFollower 类似 tail -f 持续读取某迁移类型正在写入的ora2pg日志
+//
+// 出现新的分段或新一次运行的日志时，读完当前文件后切换过去；文件被截断时从头读取，
+// 被改名轮转后读完旧文件再打开同名的新文件。
+type LogFollower struct {
+	logDir        string
+	migrationType MigrationType
+	options       LogFollowOptions
+	filter        string
+
+	file    *os.File
+	path    string
+	offset  int64
+	partial []byte
+}
+
+// NewLogFollower 创建日志跟踪器
+func NewLogFollower(logDir string, migrationType MigrationType, options LogFollowOptions) *LogFollower {
+	if options.Interval <= 0 {
+		options.Interval = defaultFollowInterval
+	}
+	return &LogFollower{
+		logDir:        logDir,
+		migrationType: migrationType,
+		options:       options,
+		filter:        strings.ToLower(options.Filter),
+	}
+}
+
+// Follow 持续跟踪日志直到 ctx 取消，取消时返回 nil
+func (f *LogFollower) Follow(ctx context.Context) error {
+	defer f.close()
+
+	first, waiting := true, false
+	for {
+		if f.file == nil {
+			path, err := LatestTypeLog(f.logDir, f.migrationType)
+			if err != nil {
+				return err
+			}
+			if path != "" {
+				// 开始时已存在的日志先显示最后几行；等待期间才出现的日志从头读取
+				if err := f.open(path, first && !waiting); err != nil {
+					return err
+				}
+			} else if !waiting {
+				waiting = true
+				if f.options.OnWaiting != nil {
+					f.options.OnWaiting()
+				}
+			}
+			first = false
+		}
+
+		if f.file != nil {
+			read, err := f.poll()
+			if err != nil {
+				return err
+			}
+			if !read {
+				if err := f.switchIfNewer(); err != nil {
+					return err
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			f.flushPartial()
+			return nil
+		case <-time.After(f.options.Interval):
+		}
+	}
+}
+
+// open 打开日志文件，tail 为true时先显示最后 Lines 行并从文件末尾开始跟踪
+func (f *LogFollower) open(path string, tail bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return utils.FileErrors.ReadFailed(path, err)
+	}
+	f.file, f.path, f.offset, f.partial = file, path, 0, nil
+	if f.options.OnFile != nil {
+		f.options.OnFile(path)
+	}
+
+	if tail {
+		lines, offset, err := lastLines(file, f.options.Lines)
+		if err != nil {
+			return utils.FileErrors.ReadFailed(path, err)
+		}
+		for _, line := range lines {
+			f.emit(line)
+		}
+		f.offset = offset
+	}
+	return nil
+}
+
+// poll 读取当前文件新写入的内容，返回是否读到了数据
+func (f *LogFollower) poll() (bool, error) {
+	info, err := f.file.Stat()
+	if err != nil {
+		return false, utils.FileErrors.ReadFailed(f.path, err)
+	}
+	if info.Size() < f.offset {
+		// 文件被截断（如 copytruncate 方式轮转），从头读取
+		f.offset, f.partial = 0, nil
+	}
+
+	read, err := f.readFrom()
+	if err != nil || read {
+		return read, err
+	}
+
+	// 文件被改名轮转后，同名路径上出现新文件时切换过去
+	current, err := os.Stat(f.path)
+	if err == nil && !os.SameFile(info, current) {
+		f.flushPartial()
+		f.close()
+		return true, f.open(f.path, false)
+	}
+	return false, nil
+}
+
+// readFrom 从上次的位置读到文件末尾，按行输出，末尾不完整的行留到下次
+func (f *LogFollower) readFrom() (bool, error) {
+	if _, err := f.file.Seek(f.offset, io.SeekStart); err != nil {
+		return false, utils.FileErrors.ReadFailed(f.path, err)
+	}
+	data, err := io.ReadAll(f.file)
+	if err != nil {
+		return false, utils.FileErrors.ReadFailed(f.path, err)
+	}
+	if len(data) == 0 {
+		return false, nil
+	}
+	f.offset += int64(len(data))
+
+	data = append(f.partial, data...)
+	lines := bytes.Split(data, []byte("\n"))
+	f.partial = append([]byte(nil), lines[len(lines)-1]...)
+	for _, line := range lines[:len(lines)-1] {
+		f.emit(strings.TrimRight(string(line), "\r"))
+	}
+	return true, nil
+}
+
+// switchIfNewer 出现更新的分段或运行日志时，读完当前文件后切换过去
+func (f *LogFollower) switchIfNewer() error {
+	latest, err := LatestTypeLog(f.logDir, f.migrationType)
+	if err != nil || latest == "" || latest == f.path {
+		return err
+	}
+	if _, err := f.readFrom(); err != nil {
+		return err
+	}
+	f.flushPartial()
+	f.close()
+	return f.open(latest, false)
+}
+
+// emit 输出通过过滤的一行
+func (f *LogFollower) emit(line string) {
+	if f.filter != "" && !strings.Contains(strings.ToLower(line), f.filter) {
+		return
+	}
+	if f.options.LineHandler != nil {
+		f.options.LineHandler(line)
+	}
+}
+
+// flushPartial 输出末尾没有换行的内容
+func (f *LogFollower) flushPartial() {
+	if len(f.partial) > 0 {
+		f.emit(strings.TrimRight(string(f.partial), "\r"))
+		f.partial = nil
+	}
+}
+
+// close 关闭当前文件
+func (f *LogFollower) close() {
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+}
+
+// lastLines 读取文件最后 n 个完整行，返回这些行和文件末尾的偏移量
+//
+// 末尾没有换行的内容不计入，之后跟踪时与新写入的内容一起输出。
+func lastLines(file *os.File, n int) ([]string, int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	size := info.Size()
+	if n <= 0 || size == 0 {
+		return nil, size, nil
+	}
+
+	// 从末尾按块向前读取，直到包含 n 个完整行
+	const blockSize = 64 << 10
+	var data []byte
+	start := size
+	for start > 0 && bytes.Count(data, []byte("\n")) <= n {
+		read := int64(blockSize)
+		if start < read {
+			read = start
+		}
+		start -= read
+		block := make([]byte, read)
+		if _, err := file.ReadAt(block, start); err != nil && err != io.EOF {
+			return nil, 0, err
+		}
+		data = append(block, data...)
+	}
+
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil, start, nil
+	}
+	// 从中间开始读取时第一行可能不完整，只保留最后 n 行
+	lines := strings.Split(string(data[:end]), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], "\r")
+	}
+	return lines, start + int64(end) + 1, nil
+}
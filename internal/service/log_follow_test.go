@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestTypeLog(t *testing.T) {
+	dir := t.TempDir()
+	path, err := LatestTypeLog(filepath.Join(dir, "missing"), MigrationTypeCopy)
+	require.NoError(t, err)
+	assert.Empty(t, path)
+
+	for _, name := range []string{
+		"ora2pg-COPY-20240501-100000.log",
+		"ora2pg-COPY-20240502-090000.part2.log",
+		"ora2pg-COPY-20240502-090000.part10.log",
+		"ora2pg-TABLE-20240503-080000.log",
+		"ora2pg-COPY-20240504-080000.log.1",
+		"migration.log",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0644))
+	}
+	path, err = LatestTypeLog(dir, MigrationTypeCopy)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "ora2pg-COPY-20240502-090000.part10.log"), path)
+}
+
+// followRecorder 记录跟踪到的文件和行
+type followRecorder struct {
+	mu    sync.Mutex
+	files []string
+	lines []string
+}
+
+func (r *followRecorder) snapshot() ([]string, []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.files...), append([]string(nil), r.lines...)
+}
+
+func TestLogFollowerFollowsSegments(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "ora2pg-COPY-20240501-100000.part1.log")
+	require.NoError(t, os.WriteFile(first, []byte("line 1\nline 2\nline 3\npartial"), 0644))
+
+	recorder := &followRecorder{}
+	follower := NewLogFollower(dir, MigrationTypeCopy, LogFollowOptions{
+		Lines:    2,
+		Interval: 10 * time.Millisecond,
+		OnFile: func(path string) {
+			recorder.mu.Lock()
+			recorder.files = append(recorder.files, filepath.Base(path))
+			recorder.mu.Unlock()
+		},
+		LineHandler: func(line string) {
+			recorder.mu.Lock()
+			recorder.lines = append(recorder.lines, line)
+			recorder.mu.Unlock()
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- follower.Follow(ctx) }()
+
+	waitLines := func(expected ...string) {
+		t.Helper()
+		require.Eventually(t, func() bool {
+			_, lines := recorder.snapshot()
+			return assert.ObjectsAreEqual(expected, lines)
+		}, 2*time.Second, 10*time.Millisecond)
+	}
+	waitLines("line 2", "line 3")
+
+	// 追加的内容与之前不完整的行拼接
+	appendFile(t, first, " line 4\nline 5\n")
+	waitLines("line 2", "line 3", "partial line 4", "line 5")
+
+	// 出现下一个分段时切换过去，并从头读取
+	second := filepath.Join(dir, "ora2pg-COPY-20240501-100000.part2.log")
+	require.NoError(t, os.WriteFile(second, []byte("segment 2\n"), 0644))
+	waitLines("line 2", "line 3", "partial line 4", "line 5", "segment 2")
+
+	// 文件被截断后从头读取
+	require.NoError(t, os.WriteFile(second, []byte("new\n"), 0644))
+	waitLines("line 2", "line 3", "partial line 4", "line 5", "segment 2", "new")
+
+	cancel()
+	require.NoError(t, <-done)
+	files, _ := recorder.snapshot()
+	assert.Equal(t, []string{filepath.Base(first), filepath.Base(second)}, files)
+}
+
+func TestLogFollowerWaitsAndFilters(t *testing.T) {
+	dir := t.TempDir()
+	waiting := make(chan struct{})
+	var mu sync.Mutex
+	var lines []string
+	follower := NewLogFollower(dir, MigrationTypeTable, LogFollowOptions{
+		Lines:     10,
+		Filter:    "error",
+		Interval:  10 * time.Millisecond,
+		OnWaiting: func() { close(waiting) },
+		LineHandler: func(line string) {
+			mu.Lock()
+			lines = append(lines, line)
+			mu.Unlock()
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- follower.Follow(ctx) }()
+
+	<-waiting
+	// 等待期间出现的日志从头读取
+	path := filepath.Join(dir, "ora2pg-TABLE-20240501-100000.log")
+	require.NoError(t, os.WriteFile(path, []byte("[INFO] start\nERROR: relation exists\n[INFO] done\nfatal Error\n"), 0644))
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(lines) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, []string{"ERROR: relation exists", "fatal Error"}, lines)
+}
+
+// appendFile 向文件追加内容
+func appendFile(t *testing.T, path, content string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = file.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+}
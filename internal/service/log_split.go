@@ -0,0 +1,137 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// SegmentedLog 把ora2pg输出写入按大小或时间切割的日志分段
+//
+// 分段命名为 <日志名>.part1.log、<日志名>.part2.log……，每段首行记录运行ID、迁移类型和段号，
+// 便于按运行聚合查看。分段在第一次写入时才创建；写入失败只记录一次警告，不影响迁移。
+type SegmentedLog struct {
+	mu       sync.Mutex
+	basePath string
+	header   string
+	maxSize  int64
+	interval time.Duration
+	now      func() time.Time
+
+	file     *os.File
+	part     int
+	size     int64
+	lines    int
+	openedAt time.Time
+	files    []string
+	failed   bool
+}
+
+// NewSegmentedLog 创建分段日志，maxSize 或 interval 为0表示不按该条件切割
+func NewSegmentedLog(basePath string, migrationType MigrationType, maxSize int64, interval time.Duration) *SegmentedLog {
+	return &SegmentedLog{
+		basePath: basePath,
+		header:   fmt.Sprintf("# ora2pg-admin run_id=%s type=%s", utils.RunID(), migrationType),
+		maxSize:  maxSize,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// WriteLine 写入一行输出，达到切割条件时先切换到下一段（stdout和stderr可能并发调用）
+func (l *SegmentedLog) WriteLine(line string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.failed {
+		return
+	}
+
+	data := line + "\n"
+	if l.file == nil || l.shouldRotate(int64(len(data))) {
+		if err := l.rotate(); err != nil {
+			l.fail(err)
+			return
+		}
+	}
+	n, err := l.file.WriteString(data)
+	l.size += int64(n)
+	l.lines++
+	if err != nil {
+		l.fail(err)
+	}
+}
+
+// shouldRotate 当前分段写入 next 字节后是否超过大小，或已达到时间间隔；空分段不切割
+func (l *SegmentedLog) shouldRotate(next int64) bool {
+	if l.lines == 0 {
+		return false
+	}
+	if l.maxSize > 0 && l.size+next > l.maxSize {
+		return true
+	}
+	return l.interval > 0 && l.now().Sub(l.openedAt) >= l.interval
+}
+
+// rotate 关闭当前分段并打开下一段
+func (l *SegmentedLog) rotate() error {
+	if l.file != nil {
+		if err := l.file.Close(); err != nil {
+			return err
+		}
+		l.file = nil
+	}
+
+	l.part++
+	path := SegmentPath(l.basePath, l.part)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	l.file = file
+	l.files = append(l.files, path)
+	l.openedAt = l.now()
+
+	n, err := fmt.Fprintf(file, "%s part=%d started=%s\n", l.header, l.part, l.openedAt.Format(time.RFC3339))
+	l.size = int64(n)
+	l.lines = 0
+	return err
+}
+
+// fail 停止写入并记录警告
+func (l *SegmentedLog) fail(err error) {
+	l.failed = true
+	utils.GetGlobalLogger().Warnf("写入ora2pg日志分段 %s 失败，后续输出不再写入分段: %v", SegmentPath(l.basePath, l.part), err)
+}
+
+// Close 关闭当前分段
+func (l *SegmentedLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// Files 已创建的分段，按段号排列
+func (l *SegmentedLog) Files() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.files...)
+}
+
+// SegmentPath 日志分段的路径，如 logs/ora2pg-COPY-20240501-100000.part2.log
+func SegmentPath(basePath string, part int) string {
+	ext := filepath.Ext(basePath)
+	return fmt.Sprintf("%s.part%d%s", strings.TrimSuffix(basePath, ext), part, ext)
+}
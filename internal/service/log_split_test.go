@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+func TestSegmentPath(t *testing.T) {
+	assert.Equal(t, filepath.Join("logs", "ora2pg-COPY-20240501-100000.part2.log"),
+		SegmentPath(filepath.Join("logs", "ora2pg-COPY-20240501-100000.log"), 2))
+	assert.Equal(t, "ora2pg.part1", SegmentPath("ora2pg", 1))
+}
+
+func TestSegmentedLogRotatesBySize(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "logs", "ora2pg-COPY.log")
+	log := NewSegmentedLog(base, MigrationTypeCopy, 200, 0)
+
+	// 每段首行约100字节，每段能容纳几行输出后切换
+	line := strings.Repeat("x", 39)
+	for i := 0; i < 10; i++ {
+		log.WriteLine(fmt.Sprintf("%s%d", line, i))
+	}
+	require.NoError(t, log.Close())
+
+	files := log.Files()
+	require.Greater(t, len(files), 1)
+	var lines []string
+	for i, file := range files {
+		assert.Equal(t, SegmentPath(base, i+1), file)
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+
+		// 每段都带运行ID和段号，可按运行聚合
+		content := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		assert.Contains(t, content[0], "run_id="+utils.RunID())
+		assert.Contains(t, content[0], fmt.Sprintf("type=COPY part=%d", i+1))
+		lines = append(lines, content[1:]...)
+	}
+
+	// 按段号拼接后内容完整且顺序不变
+	require.Len(t, lines, 10)
+	for i, content := range lines {
+		assert.Equal(t, fmt.Sprintf("%s%d", line, i), content)
+	}
+}
+
+func TestSegmentedLogRotatesByInterval(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "ora2pg-TABLE.log")
+	log := NewSegmentedLog(base, MigrationTypeTable, 0, time.Hour)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	log.now = func() time.Time { return now }
+
+	log.WriteLine("first")
+	now = now.Add(30 * time.Minute)
+	log.WriteLine("second")
+	now = now.Add(31 * time.Minute)
+	log.WriteLine("third")
+	require.NoError(t, log.Close())
+
+	files := log.Files()
+	require.Len(t, files, 2)
+	first, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(first), "first\nsecond\n")
+	second, err := os.ReadFile(files[1])
+	require.NoError(t, err)
+	assert.Contains(t, string(second), "started=2024-05-01T11:01:00Z")
+	assert.True(t, strings.HasSuffix(string(second), "\nthird\n"))
+}
+
+func TestSegmentedLogWithoutOutput(t *testing.T) {
+	log := NewSegmentedLog(filepath.Join(t.TempDir(), "ora2pg.log"), MigrationTypeView, 1024, 0)
+	require.NoError(t, log.Close())
+	assert.Empty(t, log.Files())
+}
+
+func TestExecuteSingleMigrationSplitsLog(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟ora2pg依赖 /bin/sh")
+	}
+
+	// 模拟ora2pg：传入 -l 时失败，确认切割时由本工具写日志
+	bin := t.TempDir()
+	script := `#!/bin/sh
+for arg in "$@"; do [ "$arg" = "-l" ] && exit 3; done
+i=0
+while [ $i -lt 50 ]; do echo "[====>] $i/50 rows (2.0%) Table ORDERS"; i=$((i+1)); done
+`
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ora2pg"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Chdir(t.TempDir())
+
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("日志分段")
+	cfg := manager.GetConfig()
+	cfg.Migration.OutputDir = "output"
+	cfg.Migration.LogSplit = config.LogSplitConfig{MaxSizeMB: 1}
+	require.NoError(t, os.MkdirAll("output", 0755))
+	require.NoError(t, os.WriteFile(filepath.Join("output", "ora2pg.conf"), []byte("ORACLE_DSN dbi:Oracle:host=db\n"), 0644))
+
+	ms := NewMigrationService(cfg)
+	result, err := ms.executeSingleMigration(context.Background(), MigrationTypeCopy)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, result.Status)
+	require.Len(t, result.LogFiles, 1)
+	assert.Contains(t, result.LogFiles[0], ".part1.log")
+
+	data, err := os.ReadFile(result.LogFiles[0])
+	require.NoError(t, err)
+	assert.Equal(t, 51, strings.Count(string(data), "\n"))
+}
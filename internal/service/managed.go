@@ -0,0 +1,81 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// managedDirective 托管数据库模式下改写的ora2pg指令
+type managedDirective struct {
+	Name string
+	// Enabled 需要改写的取值
+	Enabled []string
+	Value   string
+	Reason  string
+}
+
+// managedDirectives 需要超级用户权限的ora2pg指令及托管数据库中的替代取值
+var managedDirectives = []managedDirective{
+	{Name: "DISABLE_TRIGGERS", Enabled: []string{"1", "ALL"}, Value: "USER",
+		Reason: "禁用外键等系统触发器需要超级用户，只禁用用户触发器"},
+	{Name: "DISABLE_FKEY", Enabled: []string{"1"}, Value: "0",
+		Reason: "禁用外键检查需要超级用户，改为数据导入前删除外键、导入后重建（同 migration.defer_constraints）"},
+}
+
+// matches 指令取值是否需要改写
+func (d managedDirective) matches(value string) bool {
+	for _, enabled := range d.Enabled {
+		if strings.EqualFold(enabled, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// AdaptManagedConf 把ora2pg配置中需要超级用户权限的指令改为托管数据库可用的取值，返回调整后的内容和调整说明
+func AdaptManagedConf(content []byte) ([]byte, []string) {
+	lines := strings.Split(string(content), "\n")
+	var notes []string
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		end := strings.IndexAny(trimmed, "= \t")
+		if end < 0 {
+			continue
+		}
+		name := strings.ToUpper(trimmed[:end])
+		value := strings.TrimSpace(strings.TrimLeft(trimmed[end:], "= \t"))
+		for _, directive := range managedDirectives {
+			if directive.Name != name || !directive.matches(value) {
+				continue
+			}
+			lines[i] = fmt.Sprintf("# 托管数据库模式（postgresql.managed）: %s\n%s=%s", directive.Reason, trimmed[:end], directive.Value)
+			notes = append(notes, fmt.Sprintf("%s 由 %s 改为 %s: %s", directive.Name, value, directive.Value, directive.Reason))
+		}
+	}
+	return []byte(strings.Join(lines, "\n")), notes
+}
+
+// adaptManagedConfigFile 托管数据库模式下调整生成的ora2pg配置文件
+func (s *Ora2pgService) adaptManagedConfigFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return utils.FileErrors.ReadFailed(path, err)
+	}
+	adapted, notes := AdaptManagedConf(content)
+	if len(notes) == 0 {
+		return nil
+	}
+	if err := os.WriteFile(path, adapted, 0644); err != nil {
+		return utils.FileErrors.WriteFailed(path, err)
+	}
+	for _, note := range notes {
+		s.logger.Infof("托管数据库模式: %s", note)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"ora2pg-admin/internal/config"
+)
+
+func TestAdaptManagedConf(t *testing.T) {
+	content := "# 导入数据期间禁用目标表触发器\nDISABLE_TRIGGERS=1\nDISABLE_FKEY 1\nTRUNCATE_TABLE=1\n# DISABLE_FKEY=1\n"
+	adapted, notes := AdaptManagedConf([]byte(content))
+	assert.Len(t, notes, 2)
+	lines := string(adapted)
+	assert.Contains(t, lines, "\nDISABLE_TRIGGERS=USER\n")
+	assert.Contains(t, lines, "\nDISABLE_FKEY=0\n")
+	assert.Contains(t, lines, "TRUNCATE_TABLE=1")
+	assert.True(t, strings.HasSuffix(lines, "# DISABLE_FKEY=1\n"), "注释行保持不变")
+
+	// 已是托管数据库可用的取值时不调整
+	_, notes = AdaptManagedConf([]byte("DISABLE_TRIGGERS=USER\nDISABLE_FKEY=0\n"))
+	assert.Empty(t, notes)
+}
+
+func TestManagedDefersConstraints(t *testing.T) {
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("managed")
+	cfg := manager.GetConfig()
+
+	assert.False(t, NewMigrationService(cfg).deferConstraintsEnabled())
+	cfg.PostgreSQL.Managed = true
+	assert.True(t, NewMigrationService(cfg).deferConstraintsEnabled())
+}
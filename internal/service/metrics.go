@@ -0,0 +1,167 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// metricsNamespace 指标名称前缀
+const metricsNamespace = "ora2pg_admin"
+
+// metricFamily 同名指标的一组样本，键为已格式化的标签
+type metricFamily struct {
+	name    string
+	help    string
+	kind    string
+	samples map[string]float64
+}
+
+// add 累加样本值
+func (f *metricFamily) add(labels string, value float64) {
+	f.samples[labels] += value
+}
+
+// set 设置样本值
+func (f *metricFamily) set(labels string, value float64) {
+	f.samples[labels] = value
+}
+
+// newMetricFamily 创建指标
+func newMetricFamily(name, kind, help string) *metricFamily {
+	return &metricFamily{
+		name:    metricsNamespace + "_" + name,
+		help:    help,
+		kind:    kind,
+		samples: make(map[string]float64),
+	}
+}
+
+// RenderMetrics 根据迁移历史生成 Prometheus 文本格式的指标
+//
+// 计数器按全部历史累计，textfile collector 每次读取的都是完整的累计值；
+// “最后一次”类指标取最近一次运行（或某迁移类型最近一次执行）的结果。
+func RenderMetrics(project string, records []*HistoryRecord) string {
+	runs := newMetricFamily("migration_runs_total", "counter", "迁移运行次数")
+	typeRuns := newMetricFamily("migration_type_runs_total", "counter", "各迁移类型的执行次数")
+	typeDuration := newMetricFamily("migration_type_duration_seconds_total", "counter", "各迁移类型累计耗时（秒）")
+	typeLastDuration := newMetricFamily("migration_type_last_duration_seconds", "gauge", "各迁移类型最近一次执行的耗时（秒）")
+	rows := newMetricFamily("migrated_rows_total", "counter", "累计迁移的数据行数")
+	lastTimestamp := newMetricFamily("last_migration_timestamp_seconds", "gauge", "各状态最近一次迁移结束的Unix时间戳")
+	lastSuccess := newMetricFamily("last_migration_success", "gauge", "最近一次迁移是否成功（1成功，0失败或取消）")
+
+	lastTypes := make(map[MigrationType]HistoryTypeResult)
+	for _, record := range records {
+		status := metricStatus(record.Status)
+		runs.add(metricLabels("project", project, "status", status), 1)
+		lastTimestamp.set(metricLabels("project", project, "status", status), float64(record.EndTime.Unix()))
+
+		for _, result := range record.Results {
+			labels := metricLabels("project", project, "type", string(result.Type), "status", metricStatus(result.Status))
+			typeRuns.add(labels, 1)
+			typeDuration.add(labels, result.Duration.Seconds())
+			rows.add(metricLabels("project", project, "type", string(result.Type)), float64(result.Rows))
+			lastTypes[result.Type] = result
+		}
+	}
+	for migrationType, result := range lastTypes {
+		labels := metricLabels("project", project, "type", string(migrationType), "status", metricStatus(result.Status))
+		typeLastDuration.set(labels, result.Duration.Seconds())
+	}
+	if len(records) > 0 {
+		success := 0.0
+		if records[len(records)-1].Status == StatusCompleted {
+			success = 1
+		}
+		lastSuccess.set(metricLabels("project", project), success)
+	}
+
+	var builder strings.Builder
+	for _, family := range []*metricFamily{runs, typeRuns, typeDuration, typeLastDuration, rows, lastTimestamp, lastSuccess} {
+		writeMetricFamily(&builder, family)
+	}
+	return builder.String()
+}
+
+// writeMetricFamily 输出一个指标的 HELP、TYPE 和按标签排序的样本，没有样本时不输出
+func writeMetricFamily(builder *strings.Builder, family *metricFamily) {
+	if len(family.samples) == 0 {
+		return
+	}
+	fmt.Fprintf(builder, "# HELP %s %s\n", family.name, escapeMetricHelp(family.help))
+	fmt.Fprintf(builder, "# TYPE %s %s\n", family.name, family.kind)
+
+	labels := make([]string, 0, len(family.samples))
+	for label := range family.samples {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		fmt.Fprintf(builder, "%s%s %s\n", family.name, label, strconv.FormatFloat(family.samples[label], 'f', -1, 64))
+	}
+}
+
+// metricLabels 把成对的标签名和值格式化为 {name="value",...}
+func metricLabels(pairs ...string) string {
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, pairs[i], escapeMetricLabel(pairs[i+1])))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// metricStatus 状态标签值，使用小写
+func metricStatus(status ExecutionStatus) string {
+	return strings.ToLower(string(status))
+}
+
+// escapeMetricLabel 按文本格式转义标签值中的反斜杠、双引号和换行
+func escapeMetricLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// escapeMetricHelp 按文本格式转义 HELP 中的反斜杠和换行
+func escapeMetricHelp(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(value)
+}
+
+// WriteMetricsFile 写入指标文件，先写临时文件再重命名，避免 textfile collector 读到不完整的内容
+func WriteMetricsFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return utils.FileErrors.CreateFailed(filepath.Dir(path), err)
+	}
+
+	// 临时文件不以 .prom 结尾，collector 不会读取
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(content), 0644); err != nil {
+		return utils.FileErrors.WriteFailed(tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return utils.FileErrors.WriteFailed(path, err)
+	}
+	return nil
+}
+
+// ExportMetrics 根据迁移历史更新配置的指标文件，未配置时不做任何事，返回写入的文件路径
+func (ms *MigrationService) ExportMetrics() (string, error) {
+	metrics := &ms.config.Metrics
+	if !metrics.Enabled() {
+		return "", nil
+	}
+
+	records, err := LoadHistory(ms.historyPath, nil)
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSpace(metrics.Textfile)
+	if err := WriteMetricsFile(path, RenderMetrics(ms.config.Project.Name, records)); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+)
+
+// 文本格式的样本行：指标名、可选的标签集合、数值
+var metricSamplePattern = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)(\{(?:[a-zA-Z_][a-zA-Z0-9_]*="(?:[^"\\\n]|\\.)*"(?:,[a-zA-Z_][a-zA-Z0-9_]*="(?:[^"\\\n]|\\.)*")*)?\})? (-?[0-9.eE+]+|NaN|[+-]Inf)$`)
+
+// assertPrometheusFormat 检查输出符合 Prometheus 文本格式：每个指标先有 HELP 和 TYPE，样本紧随其后且不重复
+func assertPrometheusFormat(t *testing.T, text string) map[string]string {
+	t.Helper()
+	require.True(t, strings.HasSuffix(text, "\n"), "文本格式必须以换行结尾")
+
+	samples := make(map[string]string)
+	types := make(map[string]string)
+	current := ""
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "# HELP "):
+			fields := strings.SplitN(line, " ", 4)
+			require.Len(t, fields, 4, line)
+			_, seen := types[fields[2]]
+			require.False(t, seen, "指标 %s 重复出现", fields[2])
+			current = fields[2]
+		case strings.HasPrefix(line, "# TYPE "):
+			fields := strings.Fields(line)
+			require.Len(t, fields, 4, line)
+			require.Equal(t, current, fields[2], "TYPE 必须紧跟同名 HELP")
+			require.Contains(t, []string{"counter", "gauge"}, fields[3])
+			types[fields[2]] = fields[3]
+		default:
+			matches := metricSamplePattern.FindStringSubmatch(line)
+			require.NotNil(t, matches, "无效的样本行: %q", line)
+			require.Equal(t, current, matches[1], "样本必须紧跟所属指标的 TYPE")
+			if types[current] == "counter" {
+				require.True(t, strings.HasSuffix(current, "_total"), "计数器 %s 应以 _total 结尾", current)
+			}
+			key := matches[1] + matches[2]
+			_, duplicated := samples[key]
+			require.False(t, duplicated, "样本重复: %s", key)
+			samples[key] = matches[3]
+		}
+	}
+	return samples
+}
+
+func TestRenderMetrics(t *testing.T) {
+	end := time.Unix(1714550400, 0)
+	records := []*HistoryRecord{
+		{
+			Status:  StatusFailed,
+			EndTime: end.Add(-2 * time.Hour),
+			Results: []HistoryTypeResult{
+				{Type: MigrationTypeTable, Status: StatusCompleted, Duration: 30 * time.Second},
+				{Type: MigrationTypeCopy, Status: StatusFailed, Duration: 90 * time.Second, Rows: 1000},
+			},
+		},
+		{
+			Status:  StatusCompleted,
+			EndTime: end,
+			Results: []HistoryTypeResult{
+				{Type: MigrationTypeCopy, Status: StatusCompleted, Duration: 1500 * time.Millisecond, Rows: 2014},
+			},
+		},
+	}
+
+	text := RenderMetrics(`订单"库`, records)
+	samples := assertPrometheusFormat(t, text)
+
+	project := `project="订单\"库"`
+	assert.Equal(t, "1", samples[`ora2pg_admin_migration_runs_total{`+project+`,status="completed"}`])
+	assert.Equal(t, "1", samples[`ora2pg_admin_migration_runs_total{`+project+`,status="failed"}`])
+	assert.Equal(t, "1", samples[`ora2pg_admin_migration_type_runs_total{`+project+`,type="COPY",status="failed"}`])
+	assert.Equal(t, "1.5", samples[`ora2pg_admin_migration_type_duration_seconds_total{`+project+`,type="COPY",status="completed"}`])
+	assert.Equal(t, "90", samples[`ora2pg_admin_migration_type_duration_seconds_total{`+project+`,type="COPY",status="failed"}`])
+	assert.Equal(t, "3014", samples[`ora2pg_admin_migrated_rows_total{`+project+`,type="COPY"}`])
+	assert.Equal(t, "0", samples[`ora2pg_admin_migrated_rows_total{`+project+`,type="TABLE"}`])
+	assert.Equal(t, "1714550400", samples[`ora2pg_admin_last_migration_timestamp_seconds{`+project+`,status="completed"}`])
+	assert.Equal(t, "1714543200", samples[`ora2pg_admin_last_migration_timestamp_seconds{`+project+`,status="failed"}`])
+	assert.Equal(t, "1", samples[`ora2pg_admin_last_migration_success{`+project+`}`])
+
+	// 最近一次执行的耗时只保留每个类型最后的结果
+	assert.Equal(t, "1.5", samples[`ora2pg_admin_migration_type_last_duration_seconds{`+project+`,type="COPY",status="completed"}`])
+	assert.NotContains(t, samples, `ora2pg_admin_migration_type_last_duration_seconds{`+project+`,type="COPY",status="failed"}`)
+	assert.Equal(t, "30", samples[`ora2pg_admin_migration_type_last_duration_seconds{`+project+`,type="TABLE",status="completed"}`])
+
+	// 输出稳定，便于 collector 比较
+	assert.Equal(t, text, RenderMetrics(`订单"库`, records))
+
+	// 没有历史时不输出任何指标
+	assert.Empty(t, RenderMetrics("空项目", nil))
+}
+
+func TestMetricLabelEscaping(t *testing.T) {
+	assert.Equal(t, `{project="a\\b\"c\nd"}`, metricLabels("project", "a\\b\"c\nd"))
+	assert.Equal(t, `多行\n帮助\\`, escapeMetricHelp("多行\n帮助\\"))
+}
+
+func TestExportMetrics(t *testing.T) {
+	dir := t.TempDir()
+	historyPath := filepath.Join(dir, "history.jsonl")
+	metricsPath := filepath.Join(dir, "textfile", "ora2pg.prom")
+
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("指标项目")
+	cfg := manager.GetConfig()
+
+	ms := NewMigrationService(cfg)
+	ms.SetHistoryPath(historyPath)
+	ms.state.Results = []*ExecutionResult{{Status: StatusCompleted, Duration: time.Second, MigratedRows: 42}}
+	_, err := ms.RecordHistory("数据迁移", []MigrationType{MigrationTypeCopy}, nil)
+	require.NoError(t, err)
+
+	// 未配置时不写文件
+	path, err := ms.ExportMetrics()
+	require.NoError(t, err)
+	assert.Empty(t, path)
+	assert.NoFileExists(t, metricsPath)
+
+	cfg.Metrics.Textfile = metricsPath
+	path, err = ms.ExportMetrics()
+	require.NoError(t, err)
+	assert.Equal(t, metricsPath, path)
+	assert.NoFileExists(t, metricsPath+".tmp")
+
+	data, err := os.ReadFile(metricsPath)
+	require.NoError(t, err)
+	samples := assertPrometheusFormat(t, string(data))
+	assert.Equal(t, "42", samples[`ora2pg_admin_migrated_rows_total{project="指标项目",type="COPY"}`])
+	assert.Equal(t, "1", samples[`ora2pg_admin_migration_runs_total{project="指标项目",status="completed"}`])
+}
@@ -3,10 +3,17 @@ package service
 import (
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/postgres"
 	"ora2pg-admin/internal/utils"
 )
 
@@ -21,6 +28,12 @@ const (
 	PhaseGrant     MigrationPhase = "GRANT"
 )
 
+// StructurePhases 结构迁移包含的阶段：数据以外的全部阶段
+var StructurePhases = []MigrationPhase{PhaseStructure, PhaseIndex, PhaseFunction, PhaseGrant}
+
+// DataPhases 数据迁移包含的阶段
+var DataPhases = []MigrationPhase{PhaseData}
+
 // MigrationState 迁移状态
 type MigrationState struct {
 	CurrentPhase    MigrationPhase    `json:"current_phase"`
@@ -32,6 +45,10 @@ type MigrationState struct {
 	Results         []*ExecutionResult `json:"results"`
 	IsCompleted     bool              `json:"is_completed"`
 	IsCancelled     bool              `json:"is_cancelled"`
+	CompletedTables map[MigrationType][]string `json:"completed_tables,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	// Watermarks 数据迁移成功后各增量表的水位
+	Watermarks      map[string]*TableWatermark `json:"watermarks,omitempty"`
 }
 
 // MigrationService 迁移管理服务
@@ -42,6 +59,80 @@ type MigrationService struct {
 	fileUtils    *utils.FileUtils
 	state        *MigrationState
 	parallelJobs int
+	resume         bool
+	checkpoint     *Checkpoint
+	checkpointPath string
+	checkpointMu   sync.Mutex
+	outputSinks    []OutputSink
+	postProcessor  *SQLPostProcessor
+	encoder        *OutputEncodingConverter
+	monitor        *ResourceMonitor
+	historyPath    string
+	validateConf   bool
+	timeoutWarning TimeoutWarningHandler
+
+	// 按增量列水位导出数据
+	incremental   bool
+	watermarkPath string
+	syncPlan      *SyncPlan
+
+	// 数据迁移前后的目标库约束管理
+	constraintsPath      string
+	constraintsAttempted bool
+	deferredConstraints  *postgres.DeferredConstraints
+	constraintResult     *postgres.ConstraintRestoreResult
+	constraintErr        error
+
+	// 迁移前后脚本的执行结果
+	scriptResults []*ScriptResult
+
+	// 迁移后断言的检查结果
+	assertionResults []*AssertionResult
+
+	// 重试上次迁移中失败的类型，retryOf 为被重试的运行ID
+	retry   bool
+	retryOf string
+
+	// 迁移开始前估算的源库规模，用于按各类型的工作量加权计算进度
+	scale *oracle.SchemaScale
+
+	// 只导出到文件，不连接目标库（基准测试）
+	fileOnly bool
+
+	// 按正则表达式从源库筛选出的表，为空切片时表示选中全部表
+	filteredTables []string
+
+	// 按模块迁移时的模块名，配置已替换为只包含该模块对象的配置
+	module string
+
+	// 分批迁移时正在执行的批次，配置已替换为只包含该批次表的配置
+	batch *MigrationBatch
+
+	// 幂等模式：清理上次的输出，结构先删后建，数据先清空再导入
+	idempotent bool
+
+	// 跳过源库中没有对应对象的迁移类型，inventory 为首次需要时查询的源库对象数量
+	skipEmptyTypes  bool
+	inventory       oracle.ObjectInventory
+	inventoryLoaded bool
+
+	// 执行中的进度跟踪器，数据迁移时向其报告已迁移的行数以采集吞吐率
+	progressTracker *ProgressTracker
+
+	// 多目标迁移时的目标名称，用于区分各目标的日志文件
+	target string
+
+	// 导入前校验本次生成的结构SQL
+	sqlCheck       bool
+	sqlCheckFiles  []string
+	sqlCheckReport *SQLCheckReport
+
+	// 物化视图刷新计划
+	mviewRefreshPlan *MViewRefreshPlan
+
+	// 迁移前校验外部工具版本，同一服务只校验一次
+	skipVersionCheck    bool
+	toolVersionsChecked bool
 }
 
 // NewMigrationService 创建新的迁移服务
@@ -52,9 +143,14 @@ func NewMigrationService(cfg *config.ProjectConfig) *MigrationService {
 		logger:        utils.GetGlobalLogger(),
 		fileUtils:     utils.NewFileUtils(),
 		state: &MigrationState{
-			Results: make([]*ExecutionResult, 0),
+			Results:         make([]*ExecutionResult, 0),
+			CompletedTables: make(map[MigrationType][]string),
 		},
-		parallelJobs: cfg.Migration.ParallelJobs,
+		parallelJobs:   cfg.Migration.ParallelJobs,
+		checkpointPath: DefaultCheckpointPath,
+		historyPath:    DefaultHistoryPath,
+		constraintsPath: DefaultDeferredConstraintsPath,
+		watermarkPath:   DefaultWatermarkPath,
 	}
 }
 
@@ -63,6 +159,7 @@ func (ms *MigrationService) ExecuteWithProgress(ctx context.Context, migrationTy
 	progressTracker *ProgressTracker) ([]*ExecutionResult, error) {
 	
 	ms.logger.Infof("开始执行迁移，类型数量: %d", len(migrationTypes))
+	ms.progressTracker = progressTracker
 	
 	// 初始化状态
 	ms.state.TotalSteps = len(migrationTypes)
@@ -77,13 +174,96 @@ func (ms *MigrationService) ExecuteWithProgress(ctx context.Context, migrationTy
 		return nil, err
 	}
 
+	// 校验外部工具版本，启用 tools.enforce 时版本不一致直接失败
+	if err := ms.verifyToolVersions(ctx); err != nil {
+		return nil, err
+	}
+
+	// 按表名正则表达式筛选表，无法列出源库的表时直接失败，避免迁移不该迁移的表
+	if err := ms.resolveTableFilter(ctx); err != nil {
+		return nil, err
+	}
+
+	// 幂等模式下清理上次遗留的输出
+	if err := ms.prepareIdempotentRun(); err != nil {
+		return nil, err
+	}
+
 	// 生成ora2pg配置文件
 	if err := ms.generateOra2pgConfig(); err != nil {
+		// 配置文件校验失败时ora2pg必然无法执行，命名规则无法应用时表名会与预期不符，直接中止
+		if code := utils.GetErrorCode(err); code == "ORA2PG_CONFIG_INVALID" || strings.HasPrefix(code, "NAMING_") {
+			return nil, err
+		}
 		ms.logger.Warnf("生成ora2pg配置文件失败: %v", err)
 	}
+	// 只导出到文件时必须去掉目标库连接，失败时中止，避免把数据写入目标库
+	if ms.fileOnly {
+		if err := ms.disableTargetConnectionFile(ms.getConfigFilePath()); err != nil {
+			return nil, err
+		}
+	}
+	if plan := ms.config.Migration.ConnectionPlan(); plan.Adjusted {
+		ms.logger.Warnf("按连接数上限 %d 降低并行度: %s", plan.MaxConnections, plan.Summary())
+	} else if plan.MaxConnections > 0 {
+		ms.logger.Infof("连接数上限 %d: %s", plan.MaxConnections, plan.Summary())
+	}
+
+	// 准备SQL后处理（规则无效时直接失败，避免生成未处理的SQL）
+	postProcessor, err := NewSQLPostProcessor(&ms.config.Migration, "backup")
+	if err != nil {
+		return nil, err
+	}
+	ms.postProcessor = postProcessor
+
+	// 准备输出编码转换（编码名称无效时直接失败）
+	encoder, err := NewOutputEncodingConverter(ms.config, "backup")
+	if err != nil {
+		return nil, err
+	}
+	ms.encoder = encoder
+
+	// 注册自定义的ora2pg输出解析规则（规则无效时直接失败）
+	if err := ms.ora2pgService.registerConfiguredProgressRules(ms.config.Migration.ProgressRules); err != nil {
+		return nil, err
+	}
+
+	// 初始化检查点（续传时加载上次的记录）
+	if err := ms.initCheckpoint(migrationTypes); err != nil {
+		return nil, err
+	}
+
+	// 执行迁移前脚本（失败策略为 abort 时脚本失败即中止迁移）
+	ms.scriptResults = nil
+	ms.assertionResults = nil
+	ms.sqlCheckFiles = nil
+	ms.sqlCheckReport = nil
+	ms.mviewRefreshPlan = nil
+	if err := ms.runMigrationScripts(ctx, ScriptPhasePre); err != nil {
+		return nil, err
+	}
+
+	// 启动资源监控，采样结果显示在进度条之后
+	if ms.monitor != nil {
+		ms.monitor.SetSampleHandler(func(sample ResourceSample) {
+			progressTracker.UpdateResources(sample.String())
+			progressTracker.RecordResource(sample)
+		})
+		ms.monitor.Start()
+		defer ms.monitor.Stop()
+	}
 
 	results := make([]*ExecutionResult, 0, len(migrationTypes))
 
+	// 按各类型的规模加权计算总进度，无法估算时等权
+	if weights := ProgressWeights(migrationTypes, ms.scale); weights != nil {
+		progressTracker.SetStepWeights(weights)
+		ms.logger.Debugf("进度权重: %v", weights)
+	}
+
+	// 数据阶段后恢复目标库约束，迁移被取消或数据类型后没有其他类型时在返回前恢复
+	defer ms.restoreConstraints(ctx)
+
 	// 按阶段执行迁移
 	for i, migrationType := range migrationTypes {
 		select {
@@ -97,13 +277,52 @@ func (ms *MigrationService) ExecuteWithProgress(ctx context.Context, migrationTy
 		ms.state.CurrentType = migrationType
 		ms.state.CurrentPhase = ms.getPhaseForType(migrationType)
 		
-		// 更新进度
+		// 更新进度，迁移状态随进度一起写入进度状态文件
+		ms.syncProgressState(progressTracker, migrationTypes, results)
 		progressTracker.UpdateStep(i+1, fmt.Sprintf("执行 %s 迁移", migrationType))
 
+		// 续传时跳过上次已完成的类型
+		if ms.resume && ms.checkpoint.IsTypeCompleted(migrationType) {
+			ms.logger.Infof("迁移类型 %s 在上次运行中已完成，跳过", migrationType)
+			result := &ExecutionResult{
+				Status:    StatusCompleted,
+				StartTime: time.Now(),
+				EndTime:   time.Now(),
+				Progress:  &ProgressInfo{Message: "检查点显示已完成，已跳过"},
+			}
+			results = append(results, result)
+			ms.state.Results = append(ms.state.Results, result)
+			ms.state.CompletedSteps++
+			ms.syncProgressState(progressTracker, migrationTypes, results)
+			progressTracker.CompleteStep(i+1, fmt.Sprintf("%s 迁移已完成（检查点）", migrationType))
+			continue
+		}
+
+		// 源库中没有该类对象时跳过，避免ora2pg白白报错
+		if result := ms.skipEmptyType(ctx, migrationType); result != nil {
+			results = append(results, result)
+			ms.state.Results = append(ms.state.Results, result)
+			ms.recordTypeResult(migrationType, result)
+			ms.state.CompletedSteps++
+			ms.syncProgressState(progressTracker, migrationTypes, results)
+			progressTracker.CompleteStep(i+1, fmt.Sprintf("%s 迁移%s", migrationType, executionStatusText(result.Status)))
+			continue
+		}
+
+		// 数据阶段前禁用目标库约束，离开数据阶段时恢复
+		if ms.deferConstraintsEnabled() {
+			if ms.state.CurrentPhase == PhaseData && !ms.constraintsAttempted {
+				ms.deferConstraints(ctx)
+			} else if ms.state.CurrentPhase != PhaseData {
+				ms.restoreConstraints(ctx)
+			}
+		}
+
 		// 执行单个迁移类型
 		result, err := ms.executeSingleMigration(ctx, migrationType)
 		results = append(results, result)
 		ms.state.Results = append(ms.state.Results, result)
+		ms.recordTypeResult(migrationType, result)
 
 		ms.state.CompletedSteps++
 		ms.state.LastUpdateTime = time.Now()
@@ -119,6 +338,20 @@ func (ms *MigrationService) ExecuteWithProgress(ctx context.Context, migrationTy
 		if result.Progress != nil {
 			progressTracker.UpdateProgress(result.Progress.Percentage, result.Progress.Message)
 		}
+		ms.syncProgressState(progressTracker, migrationTypes, results)
+		progressTracker.CompleteStep(i+1, fmt.Sprintf("%s 迁移%s", migrationType, executionStatusText(result.Status)))
+	}
+
+	// 恢复约束后再执行迁移后脚本
+	ms.restoreConstraints(ctx)
+	// 迁移后脚本可能导入生成的SQL，在此之前校验其语法
+	ms.runSQLCheck(ctx)
+	if err := ms.runMigrationScripts(ctx, ScriptPhasePost); err != nil {
+		return results, err
+	}
+	// 迁移后脚本之后检查断言，配置为 fail 的断言未满足时迁移失败
+	if err := ms.runAssertions(ctx, migrationTypes); err != nil {
+		return results, err
 	}
 
 	ms.state.IsCompleted = true
@@ -139,10 +372,255 @@ func (ms *MigrationService) executeSingleMigration(ctx context.Context, migratio
 		Timeout:     30 * time.Minute, // 默认超时时间
 		WorkingDir:  ".",
 		Environment: ms.buildEnvironment(),
+		OutputSinks: ms.outputSinks,
+		LogFilter:   NewLogDataFilter(&ms.config.Migration.LogFilter),
+		OnTimeoutWarning: ms.timeoutWarning,
+	}
+
+	// 幂等模式下结构先删后建、数据先清空再导入，之后的续传、分批等派生配置在此基础上生成
+	if ms.idempotent {
+		idempotentConfig := ms.idempotentConfigPath(migrationType)
+		if err := WriteIdempotentConfig(options.ConfigFile, idempotentConfig, migrationType); err != nil {
+			now := time.Now()
+			return &ExecutionResult{Status: StatusFailed, StartTime: now, EndTime: now, Error: err}, err
+		}
+		options.ConfigFile = idempotentConfig
+	}
+
+	// 已有部分表完成时，生成排除这些表的续传配置
+	if ms.resume {
+		if completed := ms.getCompletedTables(migrationType); len(completed) > 0 {
+			resumeConfig := filepath.Join(ms.config.Migration.OutputDir,
+				fmt.Sprintf("ora2pg.%s.resume.conf", migrationType))
+			if err := WriteResumeConfig(options.ConfigFile, resumeConfig, completed); err != nil {
+				ms.logger.Warnf("生成续传配置失败，将重新执行全部表: %v", err)
+			} else {
+				ms.logger.Infof("迁移类型 %s 续传：跳过已完成的 %d 张表", migrationType, len(completed))
+				options.ConfigFile = resumeConfig
+			}
+		}
+	}
+
+	// 分批迁移时只导出批次内容，重新执行切片批次前先删除目标表中该范围的数据
+	if ms.batch != nil && isDataMigrationType(migrationType) {
+		batchConfig := filepath.Join(ms.config.Migration.OutputDir,
+			fmt.Sprintf("ora2pg.%s.batch.conf", migrationType))
+		if err := WriteBatchConfig(options.ConfigFile, batchConfig, ms.batch); err != nil {
+			now := time.Now()
+			return &ExecutionResult{Status: StatusFailed, StartTime: now, EndTime: now, Error: err}, err
+		}
+		options.ConfigFile = batchConfig
+		if ms.batch.Kind == BatchKindSlice && !ms.sliceResumed(migrationType) && !ms.fileOnly {
+			statement, err := ms.ClearSliceRange(ctx)
+			if err != nil {
+				now := time.Now()
+				return &ExecutionResult{Status: StatusFailed, StartTime: now, EndTime: now, Error: err}, err
+			}
+			ms.logger.Infof("已清理目标表中切片范围内的数据: %s", statement)
+		}
+	}
+
+	// 配置了增量列时按水位生成导出条件，增量同步没有需要导出的表时不执行ora2pg
+	var syncPlan *SyncPlan
+	var syncResumed []string
+	if isDataMigrationType(migrationType) && ms.config.Migration.Incremental.Enabled() {
+		plan, err := ms.prepareSyncPlan(ctx)
+		if err != nil {
+			now := time.Now()
+			return &ExecutionResult{Status: StatusFailed, StartTime: now, EndTime: now, Error: err}, err
+		}
+		if plan.NothingToExport() {
+			ms.logger.Infof("迁移类型 %s 增量同步：没有新数据需要导出", migrationType)
+			now := time.Now()
+			return &ExecutionResult{
+				Status:    StatusCompleted,
+				StartTime: now,
+				EndTime:   now,
+				Progress:  &ProgressInfo{Percentage: 100, Message: "增量同步：没有新数据"},
+			}, nil
+		}
+		syncConfig := filepath.Join(ms.config.Migration.OutputDir,
+			fmt.Sprintf("ora2pg.%s.incremental.conf", migrationType))
+		if err := WriteSyncConfig(options.ConfigFile, syncConfig, plan); err != nil {
+			now := time.Now()
+			return &ExecutionResult{Status: StatusFailed, StartTime: now, EndTime: now, Error: err}, err
+		}
+		options.ConfigFile = syncConfig
+		syncPlan = plan
+		if ms.resume {
+			syncResumed = ms.getCompletedTables(migrationType)
+		}
+	}
+
+	// 跟踪表级完成情况，只按明确的完成行记录
+	detector := NewTableCompletionDetector()
+	rows := newRowProgressCounter()
+	timings := newTableTimingCollector()
+	options.LineHandler = func(line string) {
+		for _, table := range detector.Feed(line) {
+			ms.markTableCompleted(migrationType, table)
+		}
+		timings.Feed(line)
+		if added := rows.Feed(line); added > 0 && ms.progressTracker != nil {
+			ms.progressTracker.AddRows(added)
+		}
+	}
+
+	// 配置了日志切割时由本工具写入分段日志，不再让ora2pg写单个 -l 文件；
+	// 旧版本ora2pg不支持 -l 时同样由本工具记录（不切割）；过滤数据行时ora2pg自己写的日志无法过滤，同样由本工具记录
+	var segmented *SegmentedLog
+	if split := &ms.config.Migration.LogSplit; split.Enabled() || options.LogFilter != nil || !ms.ora2pgService.Compat().SupportsFlag("-l") {
+		segmented = NewSegmentedLog(options.LogFile, migrationType, split.MaxSize(), split.SplitInterval())
+		options.LogFile = ""
+		handleLine := options.LineHandler
+		options.LineHandler = func(line string) {
+			segmented.WriteLine(line)
+			handleLine(line)
+		}
+	}
+
+	// 记录执行前的SQL文件，用于识别本次生成的输出
+	var before map[string]sqlFileStamp
+	if ms.encoder != nil || ms.postProcessor.Enabled() || ms.sqlCheckEnabled() {
+		before = snapshotSQLFiles(ms.config.Migration.OutputDir)
+	}
+
+	if ms.monitor != nil {
+		options.OnProcessStart = ms.monitor.Attach
 	}
 
 	// 执行ora2pg命令
-	return ms.ora2pgService.Execute(ctx, migrationType, options)
+	result, err := ms.ora2pgService.Execute(ctx, migrationType, options)
+	if result != nil {
+		result.LogFiles = ms.executionLogFiles(options.LogFile, segmented)
+		result.Tables = timings.Timings()
+	}
+	if ms.monitor != nil && result != nil {
+		if stats := ms.monitor.Detach(); stats.Samples > 0 {
+			result.Resources = &stats
+		}
+	}
+	if err == nil && result.Status == StatusCompleted {
+		files := changedSQLFiles(ms.config.Migration.OutputDir, before)
+
+		// 统一转换为UTF-8，后处理规则基于UTF-8文本
+		if ms.encoder != nil {
+			if err := ms.encoder.Process(ctx, migrationType, files); err != nil {
+				result.Status = StatusFailed
+				result.Error = err
+				return result, err
+			}
+		}
+
+		// 对生成的SQL执行后处理
+		if ms.postProcessor.Enabled() {
+			if err := ms.postProcessor.Process(ctx, migrationType, files); err != nil {
+				result.Status = StatusFailed
+				result.Error = err
+				return result, err
+			}
+		}
+
+		ms.collectSQLCheckFiles(migrationType, files)
+		ms.planMViewRefresh(ctx, migrationType)
+
+		// 导出成功后才推进水位，失败时下次仍从上次的水位开始
+		if syncPlan != nil {
+			if err := ms.commitWatermarks(syncPlan, syncResumed); err != nil {
+				ms.logger.Warnf("保存增量水位失败，下次同步会重复导出本次的数据: %v", err)
+			}
+		}
+	}
+	return result, err
+}
+
+// initCheckpoint 初始化检查点
+//
+// 重试失败类型时保留其他类型的检查点，只重置本次执行的类型，之后仍可 --resume 续传整个迁移。
+func (ms *MigrationService) initCheckpoint(migrationTypes []MigrationType) error {
+	ms.checkpointMu.Lock()
+	defer ms.checkpointMu.Unlock()
+
+	if ms.resume {
+		checkpoint, err := LoadCheckpoint(ms.checkpointPath)
+		if err != nil {
+			return err
+		}
+		ms.checkpoint = checkpoint
+		for migrationType, tc := range checkpoint.Types {
+			ms.state.CompletedTables[migrationType] = append([]string(nil), tc.CompletedTables...)
+		}
+		ms.logger.Infof("已加载迁移检查点: %s", ms.checkpointPath)
+		return nil
+	}
+
+	ms.checkpoint = NewCheckpoint()
+	if ms.retry {
+		if checkpoint, err := LoadCheckpoint(ms.checkpointPath); err == nil {
+			for _, migrationType := range migrationTypes {
+				delete(checkpoint.Types, migrationType)
+			}
+			ms.checkpoint = checkpoint
+		}
+	}
+	if err := SaveCheckpoint(ms.checkpointPath, ms.checkpoint); err != nil {
+		ms.logger.Warnf("保存迁移检查点失败: %v", err)
+	}
+	return nil
+}
+
+// markTableCompleted 记录表完成并持久化检查点
+func (ms *MigrationService) markTableCompleted(migrationType MigrationType, table string) {
+	ms.checkpointMu.Lock()
+	defer ms.checkpointMu.Unlock()
+
+	if ms.checkpoint == nil || !ms.checkpoint.MarkTableCompleted(migrationType, table) {
+		return
+	}
+	ms.state.CompletedTables[migrationType] = append(ms.state.CompletedTables[migrationType], table)
+	ms.logger.Debugf("表 %s 已完成 (%s)", table, migrationType)
+
+	if err := SaveCheckpoint(ms.checkpointPath, ms.checkpoint); err != nil {
+		ms.logger.Warnf("保存迁移检查点失败: %v", err)
+	}
+}
+
+// recordTypeResult 记录类型执行结果到检查点
+func (ms *MigrationService) recordTypeResult(migrationType MigrationType, result *ExecutionResult) {
+	ms.checkpointMu.Lock()
+	defer ms.checkpointMu.Unlock()
+
+	if ms.checkpoint == nil || result == nil {
+		return
+	}
+	ms.checkpoint.SetTypeStatus(migrationType, result.Status)
+	if err := SaveCheckpoint(ms.checkpointPath, ms.checkpoint); err != nil {
+		ms.logger.Warnf("保存迁移检查点失败: %v", err)
+	}
+}
+
+// getCompletedTables 获取类型已完成的表
+func (ms *MigrationService) getCompletedTables(migrationType MigrationType) []string {
+	ms.checkpointMu.Lock()
+	defer ms.checkpointMu.Unlock()
+
+	return append([]string(nil), ms.state.CompletedTables[migrationType]...)
+}
+
+// DataTables 获取已完成数据迁移（COPY/INSERT）的表，按名称排序
+func (ms *MigrationService) DataTables() []string {
+	seen := make(map[string]bool)
+	var tables []string
+	for _, migrationType := range []MigrationType{MigrationTypeCopy, MigrationTypeInsert} {
+		for _, table := range ms.getCompletedTables(migrationType) {
+			if !seen[table] {
+				seen[table] = true
+				tables = append(tables, table)
+			}
+		}
+	}
+	sort.Strings(tables)
+	return tables
 }
 
 // prepareEnvironment 准备执行环境
@@ -153,11 +631,18 @@ func (ms *MigrationService) prepareEnvironment() error {
 	}
 
 	// 确保日志目录存在
-	logDir := "logs"
+	logDir := DefaultLogDir
 	if err := ms.fileUtils.EnsureDir(logDir); err != nil {
 		return utils.FileErrors.CreateFailed(logDir, err)
 	}
 
+	// 校验配置指定的Oracle客户端
+	if !ms.config.OracleClient.AutoDetect && ms.config.OracleClient.Home != "" {
+		if _, err := oracle.ValidateClientHome(ms.config.OracleClient.Home); err != nil {
+			return err
+		}
+	}
+
 	// 确保备份目录存在
 	backupDir := "backup"
 	if err := ms.fileUtils.EnsureDir(backupDir); err != nil {
@@ -170,7 +655,7 @@ func (ms *MigrationService) prepareEnvironment() error {
 // generateOra2pgConfig 生成ora2pg配置文件
 func (ms *MigrationService) generateOra2pgConfig() error {
 	configPath := filepath.Join(ms.config.Migration.OutputDir, "ora2pg.conf")
-	return ms.ora2pgService.GenerateConfigFile(ms.config, configPath)
+	return ms.ora2pgService.GenerateConfigFile(ms.filteredConfig(), configPath, ms.validateConf)
 }
 
 // getConfigFilePath 获取配置文件路径
@@ -182,28 +667,37 @@ func (ms *MigrationService) getConfigFilePath() string {
 func (ms *MigrationService) getLogFilePath(migrationType MigrationType) string {
 	timestamp := time.Now().Format("20060102-150405")
 	filename := fmt.Sprintf("ora2pg-%s-%s.log", migrationType, timestamp)
-	return filepath.Join("logs", filename)
+	if ms.target != "" {
+		// 多个目标并行执行同一类型，日志文件名中加上目标名称避免冲突
+		filename = fmt.Sprintf("ora2pg-%s-%s-%s.log", ms.target, migrationType, timestamp)
+	}
+	return filepath.Join(DefaultLogDir, filename)
+}
+
+// executionLogFiles 本次执行产生的ora2pg日志文件，切割时为各分段
+func (ms *MigrationService) executionLogFiles(logFile string, segmented *SegmentedLog) []string {
+	if segmented != nil {
+		if err := segmented.Close(); err != nil {
+			ms.logger.Warnf("关闭ora2pg日志分段失败: %v", err)
+		}
+		return segmented.Files()
+	}
+	if logFile == "" || !ms.fileUtils.FileExists(logFile) {
+		return nil
+	}
+	return []string{logFile}
 }
 
 // buildEnvironment 构建环境变量
 func (ms *MigrationService) buildEnvironment() map[string]string {
-	env := make(map[string]string)
-	
-	// 设置Oracle相关环境变量
-	if ms.config.OracleClient.Home != "" {
-		env["ORACLE_HOME"] = ms.config.OracleClient.Home
-	}
-	
-	// 设置其他必要的环境变量
-	env["NLS_LANG"] = "AMERICAN_AMERICA.UTF8"
-	
-	return env
+	return ora2pgEnvironment(ms.config)
 }
 
 // getPhaseForType 根据迁移类型获取阶段
 func (ms *MigrationService) getPhaseForType(migrationType MigrationType) MigrationPhase {
 	switch migrationType {
-	case MigrationTypeTable, MigrationTypeView, MigrationTypeSequence:
+	case MigrationTypeTable, MigrationTypeView, MigrationTypeMView, MigrationTypeSequence,
+		MigrationTypeDBLink, MigrationTypeSynonym:
 		return PhaseStructure
 	case MigrationTypeCopy, MigrationTypeInsert:
 		return PhaseData
@@ -218,6 +712,84 @@ func (ms *MigrationService) getPhaseForType(migrationType MigrationType) Migrati
 	}
 }
 
+// ConfiguredTypesForPhases 从配置的迁移类型中选出属于指定阶段的类型，按建议顺序返回
+//
+// 重复的类型只保留一次；不支持单独执行的类型不会被选中，以原始名称在第二个返回值中列出。
+func (ms *MigrationService) ConfiguredTypesForPhases(phases ...MigrationPhase) ([]MigrationType, []string) {
+	wanted := make(map[MigrationPhase]bool, len(phases))
+	for _, phase := range phases {
+		wanted[phase] = true
+	}
+
+	var selected []MigrationType
+	var unsupported []string
+	seen := make(map[MigrationType]bool)
+	for _, name := range ms.config.Migration.Types {
+		migrationType := MigrationType(strings.ToUpper(strings.TrimSpace(name)))
+		if seen[migrationType] {
+			continue
+		}
+		seen[migrationType] = true
+
+		if err := ms.ora2pgService.ValidateMigrationType(migrationType); err != nil {
+			unsupported = append(unsupported, name)
+			continue
+		}
+		if wanted[ms.getPhaseForType(migrationType)] {
+			selected = append(selected, migrationType)
+		}
+	}
+	return OrderMigrationTypes(selected), unsupported
+}
+
+// ArchiveOutput 将输出目录打包为带时间戳的 .tar.gz 归档到 backup 目录
+//
+// clean 为true时在归档成功后清理输出目录中的原始文件，保留目录本身。
+func (ms *MigrationService) ArchiveOutput(clean bool) (string, int, error) {
+	outputDir := ms.config.Migration.OutputDir
+	archivePath := filepath.Join("backup",
+		fmt.Sprintf("%s-%s.tar.gz", filepath.Base(filepath.Clean(outputDir)), time.Now().Format("20060102-150405")))
+
+	count, err := ms.fileUtils.ArchiveDir(outputDir, archivePath)
+	if err != nil {
+		return "", 0, utils.FileErrors.CreateFailed(archivePath, err)
+	}
+	ms.logger.Infof("已归档输出目录 %s: %s (%d 个文件)", outputDir, archivePath, count)
+
+	if clean {
+		if err := ms.cleanOutputDir(); err != nil {
+			return archivePath, count, err
+		}
+	}
+
+	return archivePath, count, nil
+}
+
+// cleanOutputDir 清理输出目录中的文件，保留目录本身和 keep 中的文件
+func (ms *MigrationService) cleanOutputDir(keep ...string) error {
+	outputDir := ms.config.Migration.OutputDir
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		return utils.FileErrors.ReadFailed(outputDir, err)
+	}
+	for _, entry := range entries {
+		if slices.Contains(keep, entry.Name()) {
+			continue
+		}
+		path := filepath.Join(outputDir, entry.Name())
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("清理输出文件失败 %s: %v", path, err)
+		}
+	}
+	ms.logger.Infof("已清理输出目录: %s", outputDir)
+	return nil
+}
+
+// GetConfig 获取迁移使用的项目配置
+func (ms *MigrationService) GetConfig() *config.ProjectConfig {
+	return ms.config
+}
+
 // GetState 获取当前迁移状态
 func (ms *MigrationService) GetState() *MigrationState {
 	return ms.state
@@ -231,6 +803,109 @@ func (ms *MigrationService) SetParallelJobs(jobs int) {
 	}
 }
 
+// SetRetry 设置为重试指定迁移记录中失败的类型
+func (ms *MigrationService) SetRetry(record *HistoryRecord) {
+	ms.retry = true
+	ms.retryOf = record.RunID
+}
+
+// deferConstraintsEnabled 数据迁移前是否删除外键、禁用用户触发器
+//
+// 托管数据库没有超级用户权限，ora2pg 的 DISABLE_FKEY 无法使用，改由本工具删除并重建外键。
+func (ms *MigrationService) deferConstraintsEnabled() bool {
+	if ms.fileOnly {
+		return false
+	}
+	return ms.config.Migration.DeferConstraints || ms.config.PostgreSQL.Managed
+}
+
+// SetProgressScale 设置估算的源库规模，进度按各类型的对象数量和数据量加权计算；为空时各类型等权
+func (ms *MigrationService) SetProgressScale(scale *oracle.SchemaScale) {
+	ms.scale = scale
+}
+
+// SetResume 设置是否从检查点恢复
+func (ms *MigrationService) SetResume(resume bool) {
+	ms.resume = resume
+}
+
+// SetValidateConfig 设置生成ora2pg配置文件后是否调用ora2pg校验
+func (ms *MigrationService) SetValidateConfig(validate bool) {
+	ms.validateConf = validate
+}
+
+// SetCheckpointPath 设置检查点文件路径
+func (ms *MigrationService) SetCheckpointPath(path string) {
+	ms.checkpointPath = path
+}
+
+// SetMetadata 设置本次迁移的标签和备注，随运行记录保存到历史
+func (ms *MigrationService) SetMetadata(metadata map[string]string) {
+	if len(metadata) == 0 {
+		ms.state.Metadata = nil
+		return
+	}
+	ms.state.Metadata = make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		ms.state.Metadata[key] = value
+	}
+}
+
+// SetTimeoutWarningHandler 设置迁移类型接近超时时的处理，可返回延长的时间；未设置时只记录预警日志
+func (ms *MigrationService) SetTimeoutWarningHandler(handler TimeoutWarningHandler) {
+	ms.timeoutWarning = handler
+}
+
+// SetHistoryPath 设置迁移历史文件路径
+func (ms *MigrationService) SetHistoryPath(path string) {
+	ms.historyPath = path
+}
+
+// RecordHistory 把本次运行追加到迁移历史
+func (ms *MigrationService) RecordHistory(task string, migrationTypes []MigrationType, runErr error) (*HistoryRecord, error) {
+	record := newHistoryRecord(task, migrationTypes, ms.state, runErr)
+	record.ConfigHash = ConfigFingerprint(ms.config)
+	record.RetryOf = ms.retryOf
+	if err := AppendHistory(ms.historyPath, record); err != nil {
+		return record, err
+	}
+	return record, nil
+}
+
+// AddOutputSink 注册ora2pg输出转发目标
+func (ms *MigrationService) AddOutputSink(sink OutputSink) {
+	ms.outputSinks = append(ms.outputSinks, sink)
+}
+
+// CloseOutputSinks 关闭所有输出转发目标
+func (ms *MigrationService) CloseOutputSinks() {
+	for _, sink := range ms.outputSinks {
+		if err := sink.Close(); err != nil {
+			ms.logger.Warnf("关闭输出转发 %s 失败: %v", sink.Name(), err)
+		}
+	}
+	ms.outputSinks = nil
+}
+
+// EnableResourceMonitor 启用ora2pg进程的资源使用监控
+func (ms *MigrationService) EnableResourceMonitor(interval time.Duration) {
+	ms.monitor = NewResourceMonitor(interval)
+}
+
+// GetResourceStats 获取整个迁移期间的资源统计，未启用监控时返回nil
+func (ms *MigrationService) GetResourceStats() *ResourceStats {
+	if ms.monitor == nil {
+		return nil
+	}
+	stats := ms.monitor.Stats()
+	return &stats
+}
+
+// ResourceMonitorSupported 当前平台是否支持资源监控
+func (ms *MigrationService) ResourceMonitorSupported() bool {
+	return ms.monitor == nil || ms.monitor.Supported()
+}
+
 // GetProgress 获取迁移进度
 func (ms *MigrationService) GetProgress() float64 {
 	if ms.state.TotalSteps == 0 {
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+)
+
+func TestConfiguredTypesForPhases(t *testing.T) {
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("阶段项目")
+	cfg := manager.GetConfig()
+	cfg.Migration.Types = []string{"copy", "GRANT", "INDEX", "TABLE", "FDW", "MVIEW", " view ", "TABLE", "INSERT", "PACKAGE"}
+
+	ms := NewMigrationService(cfg)
+
+	// 结构类型：按建议顺序，去重，排除数据类型和不支持的类型
+	structure, unsupported := ms.ConfiguredTypesForPhases(StructurePhases...)
+	assert.Equal(t, []MigrationType{
+		MigrationTypeTable, MigrationTypeView, MigrationTypeMView, MigrationTypeIndex, MigrationTypePackage, MigrationTypeGrant,
+	}, structure)
+	assert.Equal(t, []string{"FDW"}, unsupported)
+
+	data, _ := ms.ConfiguredTypesForPhases(DataPhases...)
+	assert.Equal(t, []MigrationType{MigrationTypeCopy, MigrationTypeInsert}, data)
+
+	// 结构和数据阶段互不重叠，合起来覆盖全部支持的类型
+	for _, migrationType := range ms.ora2pgService.GetSupportedTypes() {
+		phase := ms.getPhaseForType(migrationType)
+		assert.NotEqual(t, containsPhase(StructurePhases, phase), containsPhase(DataPhases, phase), migrationType)
+	}
+
+	// 只配置了结构类型时，数据阶段为空
+	cfg.Migration.Types = []string{"TABLE", "VIEW", "SEQUENCE", "INDEX"}
+	data, unsupported = ms.ConfiguredTypesForPhases(DataPhases...)
+	assert.Empty(t, data)
+	assert.Empty(t, unsupported)
+}
+
+func containsPhase(phases []MigrationPhase, phase MigrationPhase) bool {
+	for _, candidate := range phases {
+		if candidate == phase {
+			return true
+		}
+	}
+	return false
+}
+
+func TestExecuteSingleMigrationPassesEnvironment(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟ora2pg依赖 /bin/sh")
+	}
+
+	// 模拟ora2pg：把收到的环境变量写入文件
+	bin := t.TempDir()
+	script := `#!/bin/sh
+{
+echo "NLS_LANG=$NLS_LANG"
+echo "TNS_ADMIN=$TNS_ADMIN"
+echo "NLS_DATE_FORMAT=$NLS_DATE_FORMAT"
+echo "LD_LIBRARY_PATH=$LD_LIBRARY_PATH"
+echo "SYSTEM_ONLY=$SYSTEM_ONLY"
+} > env.txt
+`
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ora2pg"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("SYSTEM_ONLY", "kept")
+	t.Setenv("NLS_DATE_FORMAT", "DD-MON-RR")
+	t.Setenv("LD_LIBRARY_PATH", "/usr/lib")
+	t.Chdir(t.TempDir())
+
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("环境变量")
+	cfg := manager.GetConfig()
+	cfg.Migration.OutputDir = "output"
+	cfg.Environment = map[string]string{
+		"NLS_LANG":        "SIMPLIFIED CHINESE_CHINA.AL32UTF8",
+		"TNS_ADMIN":       "/etc/oracle",
+		"NLS_DATE_FORMAT": "YYYY-MM-DD",
+		"LD_LIBRARY_PATH": "/opt/lib:$LD_LIBRARY_PATH",
+	}
+	require.NoError(t, os.MkdirAll("output", 0755))
+	require.NoError(t, os.WriteFile(filepath.Join("output", "ora2pg.conf"), []byte("ORACLE_DSN dbi:Oracle:host=db\n"), 0644))
+
+	ms := NewMigrationService(cfg)
+	result, err := ms.executeSingleMigration(context.Background(), MigrationTypeTable)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, result.Status)
+
+	data, err := os.ReadFile("env.txt")
+	require.NoError(t, err)
+	env := string(data)
+	// 配置优先于工具默认值和系统环境，未配置的系统变量原样继承
+	assert.Contains(t, env, "NLS_LANG=SIMPLIFIED CHINESE_CHINA.AL32UTF8\n")
+	assert.Contains(t, env, "TNS_ADMIN=/etc/oracle\n")
+	assert.Contains(t, env, "NLS_DATE_FORMAT=YYYY-MM-DD\n")
+	assert.Contains(t, env, "LD_LIBRARY_PATH=/opt/lib:/usr/lib\n")
+	assert.Contains(t, env, "SYSTEM_ONLY=kept\n")
+}
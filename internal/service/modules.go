@@ -0,0 +1,103 @@
+package service
+
+import (
+	"path/filepath"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// ModuleMetadataKey 按模块迁移时模块名在迁移历史元数据中的键
+const ModuleMetadataKey = "module"
+
+// ModuleCheckpointPath 按模块迁移时的检查点文件路径，每个模块单独续传
+func ModuleCheckpointPath(module string) string {
+	return filepath.Join(filepath.Dir(DefaultCheckpointPath), "modules", module+".json")
+}
+
+// moduleConfig 只迁移指定模块对象的项目配置
+func moduleConfig(cfg *config.ProjectConfig, module string) (*config.ProjectConfig, error) {
+	migration, err := cfg.Migration.ForGroup(module)
+	if err != nil {
+		return nil, utils.NewError(utils.ErrorTypeConfig, "MODULE_NOT_FOUND").
+			Message("模块不存在").
+			Details(err.Error()).
+			Suggestion("在配置文件的 migration.groups 中定义模块及其对象").
+			Build()
+	}
+	scoped := *cfg
+	scoped.Migration = migration
+	return &scoped, nil
+}
+
+// SetModule 只迁移指定模块的对象，检查点按模块单独保存，运行记录带有模块标签
+func (ms *MigrationService) SetModule(module string) error {
+	scoped, err := moduleConfig(ms.config, module)
+	if err != nil {
+		return err
+	}
+	ms.config = scoped
+	ms.module = module
+	ms.filteredTables = nil
+	ms.checkpointPath = ModuleCheckpointPath(module)
+	if ms.state.Metadata == nil {
+		ms.state.Metadata = make(map[string]string)
+	}
+	ms.state.Metadata[ModuleMetadataKey] = module
+	return nil
+}
+
+// Module 正在迁移的模块，不是按模块迁移时为空
+func (ms *MigrationService) Module() string {
+	return ms.module
+}
+
+// ModuleProgress 模块的迁移进度，来自迁移历史中该模块的运行记录
+type ModuleProgress struct {
+	Name         string   `json:"name"`
+	Objects      int      `json:"objects"`
+	Dependencies []string `json:"dependencies,omitempty"`
+	// Runs 该模块的运行次数
+	Runs int `json:"runs"`
+	// Last 最近一次运行，从未运行时为空
+	Last *HistoryRecord `json:"last,omitempty"`
+	// LastCompleted 最近一次成功的运行
+	LastCompleted *HistoryRecord `json:"last_completed,omitempty"`
+}
+
+// Completed 模块是否已成功迁移过
+func (p *ModuleProgress) Completed() bool {
+	return p.LastCompleted != nil
+}
+
+// LoadModuleProgress 汇总各模块在迁移历史中的运行情况，按模块名排序
+func LoadModuleProgress(historyPath string, migration *config.MigrationConfig) ([]*ModuleProgress, error) {
+	records, err := LoadHistory(historyPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make(map[string]*ModuleProgress, len(migration.Groups))
+	modules := make([]*ModuleProgress, 0, len(migration.Groups))
+	for _, name := range migration.GroupNames() {
+		module := &ModuleProgress{
+			Name:         name,
+			Objects:      len(migration.Groups[name]),
+			Dependencies: migration.GroupDependencies[name],
+		}
+		progress[name] = module
+		modules = append(modules, module)
+	}
+	for _, record := range records {
+		module, exists := progress[record.Metadata[ModuleMetadataKey]]
+		if !exists {
+			continue
+		}
+		module.Runs++
+		module.Last = record
+		if record.Status == StatusCompleted {
+			module.LastCompleted = record
+		}
+	}
+	return modules, nil
+}
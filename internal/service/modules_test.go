@@ -0,0 +1,97 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+func TestMigrationServiceSetModule(t *testing.T) {
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("模块")
+	cfg := manager.GetConfig()
+	cfg.Migration.Groups = map[string][]string{"订单": {"ORDERS", "ORDER_ITEMS"}}
+
+	ms := NewMigrationService(cfg)
+	ms.SetMetadata(map[string]string{"ticket": "JIRA-1"})
+	require.NoError(t, ms.SetModule("订单"))
+	assert.Equal(t, "订单", ms.Module())
+	assert.Equal(t, "ORDERS ORDER_ITEMS", ms.filteredConfig().Migration.AllowDirective())
+	assert.Equal(t, ModuleCheckpointPath("订单"), ms.checkpointPath)
+	assert.Equal(t, map[string]string{"ticket": "JIRA-1", ModuleMetadataKey: "订单"}, ms.GetState().Metadata)
+	// 项目配置不变
+	assert.Empty(t, cfg.Migration.AllowTables)
+
+	err := NewMigrationService(cfg).SetModule("财务")
+	assert.Equal(t, "MODULE_NOT_FOUND", utils.GetErrorCode(err))
+}
+
+func TestLoadModuleProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	migration := &config.MigrationConfig{
+		Groups:            map[string][]string{"用户": {"USERS"}, "订单": {"ORDERS", "ORDER_ITEMS"}, "库存": {"STOCK"}},
+		GroupDependencies: map[string][]string{"订单": {"用户"}},
+	}
+
+	start := time.Date(2024, 1, 2, 1, 0, 0, 0, time.UTC)
+	records := []*HistoryRecord{
+		{RunID: "r1", Task: "模块 用户 完整迁移", Status: StatusCompleted, StartTime: start, Metadata: map[string]string{ModuleMetadataKey: "用户"}},
+		{RunID: "r2", Task: "完整迁移", Status: StatusCompleted, StartTime: start.Add(time.Hour)},
+		{RunID: "r3", Task: "模块 订单 完整迁移", Status: StatusFailed, StartTime: start.Add(2 * time.Hour), Metadata: map[string]string{ModuleMetadataKey: "订单"}},
+	}
+	for _, record := range records {
+		require.NoError(t, AppendHistory(path, record))
+	}
+
+	progress, err := LoadModuleProgress(path, migration)
+	require.NoError(t, err)
+	require.Len(t, progress, 3)
+
+	inventory, users, orders := progress[0], progress[1], progress[2]
+	assert.Equal(t, "库存", inventory.Name)
+	assert.Nil(t, inventory.Last)
+	assert.False(t, inventory.Completed())
+
+	assert.Equal(t, "订单", orders.Name)
+	assert.Equal(t, 2, orders.Objects)
+	assert.Equal(t, []string{"用户"}, orders.Dependencies)
+	assert.Equal(t, "r3", orders.Last.RunID)
+	assert.False(t, orders.Completed())
+
+	assert.Equal(t, 1, users.Runs)
+	assert.True(t, users.Completed())
+}
+
+func TestPlanRetryModule(t *testing.T) {
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("模块")
+	cfg := manager.GetConfig()
+	cfg.Migration.Groups = map[string][]string{"订单": {"ORDERS"}}
+
+	// 按模块迁移时记录的是模块配置的指纹
+	scoped, err := moduleConfig(cfg, "订单")
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	require.NoError(t, AppendHistory(path, &HistoryRecord{
+		RunID:      "r1",
+		Status:     StatusFailed,
+		Metadata:   map[string]string{ModuleMetadataKey: "订单"},
+		Results:    []HistoryTypeResult{{Type: MigrationTypeCopy, Status: StatusFailed}},
+		ConfigHash: ConfigFingerprint(scoped),
+	}))
+
+	plan, err := PlanRetry(path, cfg)
+	require.NoError(t, err)
+	assert.False(t, plan.ConfigChanged)
+
+	// 模块的对象变化后视为配置变化
+	cfg.Migration.Groups["订单"] = []string{"ORDERS", "ORDER_ITEMS"}
+	plan, err = PlanRetry(path, cfg)
+	require.NoError(t, err)
+	assert.True(t, plan.ConfigChanged)
+}
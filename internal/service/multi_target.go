@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/utils"
+)
+
+// MetadataTargetKey 多目标迁移时目标名称在迁移元数据中的键
+const MetadataTargetKey = "target"
+
+// TargetRun 向一个目标库执行的迁移
+type TargetRun struct {
+	Name string
+	// Endpoint 目标库地址，如 10.0.0.2:5432/appdb
+	Endpoint string
+	Service  *MigrationService
+	Results  []*ExecutionResult
+	// Err 连接测试失败或迁移无法继续时的错误
+	Err      error
+	Duration time.Duration
+}
+
+// Succeeded 目标的全部迁移类型是否都成功
+func (r *TargetRun) Succeeded() bool {
+	if r.Err != nil {
+		return false
+	}
+	for _, result := range r.Results {
+		if !result.Status.Succeeded() {
+			return false
+		}
+	}
+	return true
+}
+
+// Failed 未成功的迁移类型数量
+func (r *TargetRun) Failed() int {
+	failed := 0
+	for _, result := range r.Results {
+		if !result.Status.Succeeded() {
+			failed++
+		}
+	}
+	return failed
+}
+
+// NewTargetRuns 为每个目标库创建独立的迁移服务，沿用当前服务的执行设置
+//
+// 各目标的输出目录为原输出目录下以目标名称命名的子目录，检查点、约束记录和增量水位按目标分别保存，
+// 迁移历史仍写入同一文件，运行记录的元数据中带有目标名称。
+func (ms *MigrationService) NewTargetRuns(targets []config.PostgreConfig) []*TargetRun {
+	runs := make([]*TargetRun, 0, len(targets))
+	for _, target := range targets {
+		runs = append(runs, &TargetRun{
+			Name:     target.Name,
+			Endpoint: net.JoinHostPort(target.Host, strconv.Itoa(target.Port)) + "/" + target.Database,
+			Service:  ms.forTarget(target),
+		})
+	}
+	return runs
+}
+
+// forTarget 复制迁移服务并替换为指定的目标库
+func (ms *MigrationService) forTarget(target config.PostgreConfig) *MigrationService {
+	clone := NewMigrationService(ms.config.ForTarget(target))
+	clone.target = target.Name
+	clone.parallelJobs = ms.parallelJobs
+	clone.resume = ms.resume
+	clone.validateConf = ms.validateConf
+	clone.timeoutWarning = ms.timeoutWarning
+	clone.outputSinks = ms.outputSinks
+	clone.incremental = ms.incremental
+	clone.retry = ms.retry
+	clone.retryOf = ms.retryOf
+	clone.scale = ms.scale
+	clone.fileOnly = ms.fileOnly
+	clone.module = ms.module
+	clone.batch = ms.batch
+	clone.idempotent = ms.idempotent
+	clone.skipEmptyTypes = ms.skipEmptyTypes
+	clone.skipVersionCheck = ms.skipVersionCheck
+	clone.sqlCheck = ms.sqlCheck
+	clone.historyPath = ms.historyPath
+	clone.checkpointPath = targetStatePath(ms.checkpointPath, target.Name)
+	clone.constraintsPath = targetStatePath(ms.constraintsPath, target.Name)
+	clone.watermarkPath = targetStatePath(ms.watermarkPath, target.Name)
+	if ms.monitor != nil {
+		clone.monitor = NewResourceMonitor(ms.monitor.interval)
+	}
+
+	metadata := make(map[string]string, len(ms.state.Metadata)+1)
+	for key, value := range ms.state.Metadata {
+		metadata[key] = value
+	}
+	metadata[MetadataTargetKey] = target.Name
+	clone.SetMetadata(metadata)
+	return clone
+}
+
+// targetStatePath 按目标区分的状态文件，如 checkpoint.json 对应 checkpoint.staging.json
+func targetStatePath(path, target string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + target + ext
+}
+
+// CheckConnection 迁移前测试目标库连接，失败时记录错误，该目标不再执行
+func (r *TargetRun) CheckConnection(tester *oracle.ConnectionTester) bool {
+	cfg := &r.Service.GetConfig().PostgreSQL
+	result := tester.TestPostgreSQLConnection(cfg)
+	if result.Success {
+		return true
+	}
+	details := result.Message
+	if result.Error != "" {
+		details += ": " + result.Error
+	}
+	r.Err = utils.NewError(utils.ErrorTypeConnection, "TARGET_CONNECTION_FAILED").
+		Message(fmt.Sprintf("目标 %s（%s）连接失败", r.Name, r.Endpoint)).
+		Details(details).
+		Suggestion("运行 'ora2pg-admin 检查 连接' 查看各目标的连接详情，或用 --targets 只迁移可连接的目标").
+		Build()
+	return false
+}
+
+// ExecuteTargets 并行向各目标库执行迁移，每个目标使用独立的ora2pg进程和输出目录，部分目标失败不影响其他目标
+//
+// 已有错误（如连接测试失败）的目标不执行。各目标的进度合并显示在 progressTracker 中，
+// 总进度为各目标进度的平均值，每个目标结束时完成一个步骤，progressTracker 的总步骤数应为目标数。
+func ExecuteTargets(ctx context.Context, runs []*TargetRun, migrationTypes []MigrationType, progressTracker *ProgressTracker) {
+	progress := newTargetProgress(runs, progressTracker)
+
+	var wg sync.WaitGroup
+	for i, run := range runs {
+		if run.Err != nil {
+			progress.finish(i)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, run *TargetRun) {
+			defer wg.Done()
+			tracker := NewProgressTracker()
+			tracker.SetQuiet(true)
+			tracker.SetUpdateHandler(func(update ProgressUpdate) {
+				progress.update(i, update.Percentage)
+			})
+			tracker.Start(run.Name, len(migrationTypes))
+
+			start := time.Now()
+			run.Results, run.Err = run.Service.ExecuteWithProgress(ctx, migrationTypes, tracker)
+			run.Duration = time.Since(start)
+			tracker.Stop()
+			progress.finish(i)
+		}(i, run)
+	}
+	wg.Wait()
+}
+
+// targetProgress 合并各目标的进度
+type targetProgress struct {
+	mu          sync.Mutex
+	runs        []*TargetRun
+	percentages []float64
+	finished    int
+	tracker     *ProgressTracker
+}
+
+// newTargetProgress 创建多目标进度合并器
+func newTargetProgress(runs []*TargetRun, tracker *ProgressTracker) *targetProgress {
+	return &targetProgress{runs: runs, percentages: make([]float64, len(runs)), tracker: tracker}
+}
+
+// update 更新一个目标的进度（在该目标的进度跟踪器持有锁时调用）
+func (p *targetProgress) update(i int, percentage float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.percentages[i] = percentage
+	p.tracker.UpdateProgress(p.average(), p.details())
+}
+
+// finish 一个目标执行结束
+func (p *targetProgress) finish(i int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.percentages[i] = 100
+	p.finished++
+	status := "完成"
+	if !p.runs[i].Succeeded() {
+		status = "未全部成功"
+	}
+	p.tracker.CompleteStep(p.finished, fmt.Sprintf("目标 %s %s", p.runs[i].Name, status))
+	p.tracker.UpdateProgress(p.average(), p.details())
+}
+
+// average 各目标进度的平均值（调用方需持有锁）
+func (p *targetProgress) average() float64 {
+	var sum float64
+	for _, percentage := range p.percentages {
+		sum += percentage
+	}
+	return sum / float64(len(p.percentages))
+}
+
+// details 各目标的进度，如 "primary 40% · staging 60%"（调用方需持有锁）
+func (p *targetProgress) details() string {
+	parts := make([]string, len(p.runs))
+	for i, run := range p.runs {
+		parts[i] = fmt.Sprintf("%s %.0f%%", run.Name, p.percentages[i])
+	}
+	return strings.Join(parts, " · ")
+}
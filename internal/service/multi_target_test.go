@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+)
+
+func TestExecuteTargets(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟ora2pg依赖 /bin/sh")
+	}
+
+	// 模拟ora2pg：目标为 pg-bad 时失败，否则在配置文件所在目录生成输出
+	bin := t.TempDir()
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+  case "$1" in
+    -c) conf=$2 ;;
+    -t) type=$2 ;;
+  esac
+  shift
+done
+if grep -q "pg-bad" "$conf"; then
+  echo "FATAL: could not connect to pg-bad"; exit 1
+fi
+echo "-- $type" > "$(dirname "$conf")/$type.sql"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ora2pg"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	templates, err := filepath.Abs(filepath.Join("..", "..", "templates"))
+	require.NoError(t, err)
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.Symlink(templates, "templates"))
+
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("多目标")
+	cfg := manager.GetConfig()
+	cfg.Migration.OutputDir = "output"
+	cfg.PostgreSQL.Host = "pg-dev"
+	cfg.PostgreSQL.Targets = []config.PostgreConfig{{Name: "staging", Host: "pg-bad"}, {Name: "perf", Host: "pg-perf"}}
+	types := []MigrationType{MigrationTypeTable, MigrationTypeView}
+
+	ms := NewMigrationService(cfg)
+	ms.SetMetadata(map[string]string{"ticket": "OPS-1"})
+	targets, err := cfg.SelectTargets(nil)
+	require.NoError(t, err)
+	runs := ms.NewTargetRuns(targets)
+	require.Len(t, runs, 3)
+	assert.Equal(t, "primary", runs[0].Name)
+	assert.Equal(t, "pg-perf:5432/"+cfg.PostgreSQL.Database, runs[2].Endpoint)
+	assert.Equal(t, "OPS-1", runs[1].Service.state.Metadata["ticket"])
+	assert.Equal(t, "staging", runs[1].Service.state.Metadata[MetadataTargetKey])
+	assert.Equal(t, targetStatePath(ms.checkpointPath, "staging"), runs[1].Service.checkpointPath)
+	assert.NotEqual(t, runs[1].Service.checkpointPath, runs[2].Service.checkpointPath)
+
+	// 已有错误的目标不执行
+	runs[0].Err = assert.AnError
+	progressTracker := NewProgressTracker()
+	progressTracker.SetQuiet(true)
+	progressTracker.Start("迁移", len(runs))
+	ExecuteTargets(context.Background(), runs, types, progressTracker)
+	progressTracker.Stop()
+
+	assert.Empty(t, runs[0].Results)
+	assert.NoDirExists(t, filepath.Join("output", "primary"))
+	assert.False(t, runs[0].Succeeded())
+
+	// 部分目标失败不影响其他目标，各目标的输出在各自的子目录
+	require.Len(t, runs[1].Results, 2)
+	assert.Equal(t, StatusFailed, runs[1].Results[0].Status)
+	assert.Equal(t, 2, runs[1].Failed())
+	require.Len(t, runs[2].Results, 2)
+	assert.True(t, runs[2].Succeeded())
+	assert.FileExists(t, filepath.Join("output", "perf", "TABLE.sql"))
+	assert.FileExists(t, filepath.Join("output", "perf", "VIEW.sql"))
+	assert.NoFileExists(t, filepath.Join("output", "staging", "TABLE.sql"))
+	assert.NoFileExists(t, filepath.Join("output", "TABLE.sql"))
+
+	assert.Equal(t, 100.0, progressTracker.GetProgress())
+	assert.Equal(t, 3, progressTracker.GetCurrentStep())
+}
+
+func TestForTargetCopiesRunOptions(t *testing.T) {
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("多目标")
+	ms := NewMigrationService(manager.GetConfig())
+	ms.SetSkipVersionCheck(true)
+	ms.SetSQLCheck(true)
+
+	runs := ms.NewTargetRuns([]config.PostgreConfig{{Name: "staging", Host: "pg-staging", Port: 5432, Database: "app"}})
+	require.Len(t, runs, 1)
+	assert.True(t, runs[0].Service.skipVersionCheck)
+	// --check-sql 未在配置中启用时同样对每个目标生效
+	assert.True(t, runs[0].Service.sqlCheckEnabled())
+}
+
+func TestTargetStatePath(t *testing.T) {
+	assert.Equal(t, filepath.Join(".ora2pg-admin", "checkpoint.staging.json"),
+		targetStatePath(filepath.Join(".ora2pg-admin", "checkpoint.json"), "staging"))
+	assert.Equal(t, "watermark.perf", targetStatePath("watermark", "perf"))
+}
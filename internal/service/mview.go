@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/postgres"
+)
+
+// MViewRefreshScriptName 物化视图刷新脚本的文件名，生成在输出目录中
+const MViewRefreshScriptName = "MVIEW_refresh.sql"
+
+// MViewRefreshItem 一个物化视图的刷新策略转换结果
+type MViewRefreshItem struct {
+	Name string `json:"name"`
+	// Target 目标库中的限定名，已转义
+	Target string `json:"target"`
+	// Source 源库的刷新设置，如 "FAST ON DEMAND，间隔 SYSDATE + 1/24"
+	Source string `json:"source"`
+	// Refresh 是否写入刷新脚本，源库设置为 NEVER REFRESH 时为 false
+	Refresh bool     `json:"refresh"`
+	Notes   []string `json:"notes,omitempty"`
+}
+
+// MViewRefreshPlan 物化视图的刷新计划，按依赖排列：被引用的物化视图先刷新
+type MViewRefreshPlan struct {
+	Views      []*MViewRefreshItem `json:"views"`
+	ScriptPath string              `json:"script_path,omitempty"`
+}
+
+// OrderMaterializedViews 按依赖排序，引用其他物化视图的排在后面；
+// 同一层保持原有顺序，存在循环依赖的按原有顺序排在最后
+func OrderMaterializedViews(views []*oracle.MaterializedView) []*oracle.MaterializedView {
+	remaining := make(map[string]bool, len(views))
+	for _, view := range views {
+		remaining[view.Name] = true
+	}
+
+	ordered := make([]*oracle.MaterializedView, 0, len(views))
+	for len(ordered) < len(views) {
+		var ready []*oracle.MaterializedView
+		for _, view := range views {
+			if !remaining[view.Name] {
+				continue
+			}
+			blocked := false
+			for _, dependency := range view.DependsOn {
+				if remaining[dependency] {
+					blocked = true
+					break
+				}
+			}
+			if !blocked {
+				ready = append(ready, view)
+			}
+		}
+		if len(ready) == 0 {
+			for _, view := range views {
+				if remaining[view.Name] {
+					ready = append(ready, view)
+				}
+			}
+		}
+		for _, view := range ready {
+			delete(remaining, view.Name)
+			ordered = append(ordered, view)
+		}
+	}
+	return ordered
+}
+
+// BuildMViewRefreshPlan 根据源库的刷新设置生成刷新计划
+//
+// PostgreSQL 的物化视图只能通过 REFRESH MATERIALIZED VIEW 完全刷新，ON COMMIT、增量刷新和定时刷新都需要另行处理，
+// 在 Notes 中给出对应的建议。
+func BuildMViewRefreshPlan(views []*oracle.MaterializedView, schema string, preserveCase bool) *MViewRefreshPlan {
+	plan := &MViewRefreshPlan{}
+	for _, view := range OrderMaterializedViews(views) {
+		target := postgres.QuoteIdentifier(postgres.TargetTableName(view.Name, preserveCase))
+		if schema != "" {
+			target = postgres.QuoteIdentifier(postgres.TargetTableName(schema, preserveCase)) + "." + target
+		}
+		item := &MViewRefreshItem{
+			Name:    view.Name,
+			Target:  target,
+			Source:  fmt.Sprintf("%s ON %s", view.RefreshMethod, view.RefreshMode),
+			Refresh: view.RefreshMode != "NEVER" && view.RefreshMethod != "NEVER",
+		}
+		if view.Interval != "" {
+			item.Source += "，间隔 " + view.Interval
+		}
+
+		if !item.Refresh {
+			item.Notes = append(item.Notes, "源库设置为 NEVER REFRESH，不写入刷新脚本")
+		}
+		if view.RefreshMode == "COMMIT" {
+			item.Notes = append(item.Notes, "PostgreSQL 不支持 ON COMMIT 刷新，需在基表上创建语句级触发器执行刷新，或改为定时刷新")
+		}
+		if item.Refresh && (view.RefreshMethod == "FAST" || view.RefreshMethod == "FORCE") {
+			item.Notes = append(item.Notes, "PostgreSQL 只能完全刷新；创建唯一索引后可用 REFRESH MATERIALIZED VIEW CONCURRENTLY 避免阻塞查询，或使用 pg_ivm 扩展增量维护")
+		}
+		if item.Refresh && view.Interval != "" {
+			item.Notes = append(item.Notes, fmt.Sprintf("源库每隔 %s 定时刷新，可使用 pg_cron 定时执行: SELECT cron.schedule('refresh_%s', '<cron表达式>', 'REFRESH MATERIALIZED VIEW %s')",
+				view.Interval, strings.ToLower(view.Name), strings.ReplaceAll(target, "'", "''")))
+		}
+		switch view.BuildMode {
+		case "DEFERRED":
+			item.Notes = append(item.Notes, "源库创建时不填充数据（BUILD DEFERRED），目标库使用前需执行刷新脚本")
+		case "PREBUILT":
+			item.Notes = append(item.Notes, "源库基于已有的表创建（ON PREBUILT TABLE），确认 TABLE 迁移没有生成同名的表")
+		}
+		if len(view.DependsOn) > 0 {
+			item.Notes = append(item.Notes, fmt.Sprintf("引用物化视图 %s，需在其后刷新", strings.Join(view.DependsOn, "、")))
+		}
+		plan.Views = append(plan.Views, item)
+	}
+	return plan
+}
+
+// Script 刷新脚本内容，数据迁移完成后按依赖顺序刷新全部物化视图
+func (p *MViewRefreshPlan) Script() string {
+	var script strings.Builder
+	script.WriteString("-- 物化视图刷新脚本，由 ora2pg-admin 根据源库的刷新设置生成\n")
+	script.WriteString("-- 按依赖顺序排列，在数据迁移完成后执行\n")
+	for _, item := range p.Views {
+		if !item.Refresh {
+			fmt.Fprintf(&script, "-- %s: NEVER REFRESH，已跳过\n", item.Target)
+			continue
+		}
+		fmt.Fprintf(&script, "REFRESH MATERIALIZED VIEW %s;\n", item.Target)
+	}
+	return script.String()
+}
+
+// planMViewRefresh MVIEW 迁移后查询源库物化视图的刷新设置，在输出目录生成刷新脚本
+//
+// 物化视图在迁移结构时创建，此时数据可能尚未导入，需在数据迁移后执行刷新脚本；查询失败不影响迁移结果。
+func (ms *MigrationService) planMViewRefresh(ctx context.Context, migrationType MigrationType) {
+	if migrationType != MigrationTypeMView {
+		return
+	}
+	if _, _, found := config.DumpFileReference(&ms.config.Oracle); found {
+		ms.logger.Info("源数据是dump文件，不生成物化视图刷新脚本")
+		return
+	}
+
+	schema := ms.config.Oracle.Schema
+	if schema == "" {
+		schema = ms.config.Oracle.Username
+	}
+	queryCtx, cancel := context.WithTimeout(ctx, inventoryCheckTimeout)
+	defer cancel()
+	runner := oracle.NewSQLPlusRunner(&ms.config.Oracle, &ms.config.OracleClient)
+	views, err := oracle.NewInspector(runner, schema).MaterializedViews(queryCtx)
+	if err != nil {
+		ms.logger.Warnf("查询源库物化视图的刷新设置失败，未生成刷新脚本: %v", err)
+		return
+	}
+	if len(views) == 0 {
+		return
+	}
+
+	plan := BuildMViewRefreshPlan(views, ms.config.PostgreSQL.Schema, ms.config.Migration.PreserveCase())
+	path := filepath.Join(ms.config.Migration.OutputDir, MViewRefreshScriptName)
+	if err := os.WriteFile(path, []byte(plan.Script()), 0644); err != nil {
+		ms.logger.Warnf("写入物化视图刷新脚本失败: %v", err)
+	} else {
+		plan.ScriptPath = path
+		ms.logger.Infof("已生成物化视图刷新脚本: %s（%d 个物化视图）", path, len(plan.Views))
+	}
+	for _, item := range plan.Views {
+		for _, note := range item.Notes {
+			ms.logger.Warnf("物化视图 %s（%s）: %s", item.Name, item.Source, note)
+		}
+	}
+	ms.mviewRefreshPlan = plan
+}
+
+// MViewRefreshPlan 本次迁移生成的物化视图刷新计划，未迁移物化视图时为 nil
+func (ms *MigrationService) MViewRefreshPlan() *MViewRefreshPlan {
+	return ms.mviewRefreshPlan
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/oracle"
+)
+
+func TestOrderMaterializedViews(t *testing.T) {
+	views := []*oracle.MaterializedView{
+		{Name: "MV_C", DependsOn: []string{"MV_B"}},
+		{Name: "MV_B", DependsOn: []string{"MV_A", "MV_OTHER"}},
+		{Name: "MV_A"},
+		{Name: "MV_D"},
+		{Name: "MV_X", DependsOn: []string{"MV_Y"}},
+		{Name: "MV_Y", DependsOn: []string{"MV_X"}},
+	}
+
+	var names []string
+	for _, view := range OrderMaterializedViews(views) {
+		names = append(names, view.Name)
+	}
+	// 不在列表中的依赖忽略，循环依赖按原有顺序排在最后
+	assert.Equal(t, []string{"MV_A", "MV_D", "MV_B", "MV_C", "MV_X", "MV_Y"}, names)
+}
+
+func TestBuildMViewRefreshPlan(t *testing.T) {
+	views := []*oracle.MaterializedView{
+		{Name: "MV_SUMMARY", RefreshMode: "COMMIT", RefreshMethod: "FAST", BuildMode: "IMMEDIATE", DependsOn: []string{"MV_DAILY"}},
+		{Name: "MV_DAILY", RefreshMode: "DEMAND", RefreshMethod: "COMPLETE", BuildMode: "DEFERRED", Interval: "SYSDATE + 1"},
+		{Name: "MV_ARCHIVE", RefreshMode: "NEVER", RefreshMethod: "NEVER", BuildMode: "PREBUILT"},
+	}
+
+	plan := BuildMViewRefreshPlan(views, "app", false)
+	require.Len(t, plan.Views, 3)
+	assert.Equal(t, "MV_DAILY", plan.Views[0].Name)
+	assert.Equal(t, `"app"."mv_daily"`, plan.Views[0].Target)
+	assert.Equal(t, "COMPLETE ON DEMAND，间隔 SYSDATE + 1", plan.Views[0].Source)
+	require.Len(t, plan.Views[0].Notes, 2)
+	assert.Contains(t, plan.Views[0].Notes[0], "pg_cron")
+	assert.Contains(t, plan.Views[0].Notes[1], "BUILD DEFERRED")
+
+	summary := plan.Views[2]
+	assert.Equal(t, "MV_SUMMARY", summary.Name)
+	require.Len(t, summary.Notes, 3)
+	assert.Contains(t, summary.Notes[0], "ON COMMIT")
+	assert.Contains(t, summary.Notes[1], "CONCURRENTLY")
+	assert.Contains(t, summary.Notes[2], "MV_DAILY")
+
+	archive := plan.Views[1]
+	assert.False(t, archive.Refresh)
+
+	assert.Equal(t, `-- 物化视图刷新脚本，由 ora2pg-admin 根据源库的刷新设置生成
+-- 按依赖顺序排列，在数据迁移完成后执行
+REFRESH MATERIALIZED VIEW "app"."mv_daily";
+-- "app"."mv_archive": NEVER REFRESH，已跳过
+REFRESH MATERIALIZED VIEW "app"."mv_summary";
+`, plan.Script())
+
+	// 未配置目标Schema时使用 search_path
+	plan = BuildMViewRefreshPlan(views[1:2], "", true)
+	assert.Equal(t, `"MV_DAILY"`, plan.Views[0].Target)
+}
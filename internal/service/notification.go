@@ -0,0 +1,366 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// notificationSubjectTemplate 内置邮件主题模板
+const notificationSubjectTemplate = `[ora2pg-admin] {{.Project}} {{.Task}}{{.StatusText}}`
+
+// notificationTextTemplate 内置纯文本正文模板
+const notificationTextTemplate = `{{.Project}} {{.Task}}{{.StatusText}}
+
+运行ID: {{.RunID}}
+主机: {{.Host}}
+开始时间: {{.StartTime.Format "2006-01-02 15:04:05"}}
+耗时: {{.Duration}}
+结果: {{.Succeeded}}/{{.Total}} 成功{{if .Failed}}，{{.Failed}} 失败{{end}}
+{{- if .Tags}}
+标签: {{range $i, $tag := .Tags}}{{if $i}}, {{end}}{{$tag}}{{end}}
+{{- end}}
+{{- if .Note}}
+备注: {{.Note}}
+{{- end}}
+
+{{range .Results -}}
+- {{.Type}}: {{.Status}}（{{.Duration}}）
+{{- if .Error}}
+  错误: {{.Error}}
+{{- end}}
+{{end}}
+{{- if .Link}}
+详情: {{.Link}}
+{{- end}}
+`
+
+// notificationHTMLTemplate 内置HTML正文模板
+const notificationHTMLTemplate = `<html>
+<body style="font-family: sans-serif;">
+<h2 style="color: {{if .Success}}#2e7d32{{else}}#c62828{{end}};">{{.Project}} {{.Task}}{{.StatusText}}</h2>
+<table cellpadding="4">
+<tr><td>运行ID</td><td>{{.RunID}}</td></tr>
+<tr><td>主机</td><td>{{.Host}}</td></tr>
+<tr><td>开始时间</td><td>{{.StartTime.Format "2006-01-02 15:04:05"}}</td></tr>
+<tr><td>耗时</td><td>{{.Duration}}</td></tr>
+<tr><td>结果</td><td>{{.Succeeded}}/{{.Total}} 成功{{if .Failed}}，{{.Failed}} 失败{{end}}</td></tr>
+{{- if .Tags}}
+<tr><td>标签</td><td>{{range $i, $tag := .Tags}}{{if $i}}, {{end}}{{$tag}}{{end}}</td></tr>
+{{- end}}
+{{- if .Note}}
+<tr><td>备注</td><td>{{.Note}}</td></tr>
+{{- end}}
+</table>
+<table border="1" cellpadding="4" style="border-collapse: collapse; margin-top: 12px;">
+<tr><th>类型</th><th>状态</th><th>耗时</th><th>错误</th></tr>
+{{- range .Results}}
+<tr><td>{{.Type}}</td><td>{{.Status}}</td><td>{{.Duration}}</td><td>{{.Error}}</td></tr>
+{{- end}}
+</table>
+{{- if .Link}}
+<p><a href="{{.Link}}">查看详情</a></p>
+{{- end}}
+</body>
+</html>
+`
+
+// NotificationResult 通知中单个迁移类型的结果
+type NotificationResult struct {
+	Type         string
+	Status       string // 成功、失败、已取消等
+	Duration     string
+	Error        string
+	ErrorCount   int
+	WarningCount int
+}
+
+// NotificationData 通知模板可用的数据
+type NotificationData struct {
+	Project    string
+	Task       string
+	RunID      string
+	Success    bool
+	StatusText string // 成功、失败、已取消
+	Host       string
+	StartTime  time.Time
+	EndTime    time.Time
+	Duration   string
+	Total      int
+	Succeeded  int
+	Failed     int
+	Results    []NotificationResult
+	Failures   []NotificationResult // Results 中未成功的部分
+	Tags       []string             // key=value 形式
+	Note       string
+	Link       string // 由 link 模板生成的报告链接
+}
+
+// NotificationMessage 渲染后的通知邮件
+type NotificationMessage struct {
+	Subject string
+	Body    string
+	HTML    bool
+	// FallbackReason 自定义模板渲染失败、改用内置模板的原因
+	FallbackReason string
+}
+
+// executionStatusTexts 执行状态的中文名称
+var executionStatusTexts = map[ExecutionStatus]string{
+	StatusPending:   "未执行",
+	StatusRunning:   "执行中",
+	StatusCompleted: "成功",
+	StatusFailed:    "失败",
+	StatusCancelled: "已取消",
+	StatusSkipped:   "跳过（无对象）",
+}
+
+// NewNotificationData 根据迁移历史记录生成通知数据
+func NewNotificationData(cfg *config.ProjectConfig, record *HistoryRecord) *NotificationData {
+	host, _ := os.Hostname()
+	data := &NotificationData{
+		Project:    cfg.Project.Name,
+		Task:       record.Task,
+		RunID:      record.RunID,
+		Success:    record.Status == StatusCompleted,
+		StatusText: executionStatusText(record.Status),
+		Host:       host,
+		StartTime:  record.StartTime,
+		EndTime:    record.EndTime,
+		Duration:   record.Duration.Truncate(time.Second).String(),
+		Total:      len(record.Results),
+		Tags:       record.Tags(),
+		Note:       record.Note(),
+	}
+	for _, result := range record.Results {
+		item := NotificationResult{
+			Type:         string(result.Type),
+			Status:       executionStatusText(result.Status),
+			Duration:     result.Duration.Truncate(time.Second).String(),
+			Error:        result.Error,
+			ErrorCount:   result.ErrorCount,
+			WarningCount: result.WarningCount,
+		}
+		data.Results = append(data.Results, item)
+		if result.Status.Succeeded() {
+			data.Succeeded++
+		} else {
+			data.Failed++
+			data.Failures = append(data.Failures, item)
+		}
+	}
+	return data
+}
+
+// executionStatusText 获取执行状态的中文名称
+func executionStatusText(status ExecutionStatus) string {
+	if text, ok := executionStatusTexts[status]; ok {
+		return text
+	}
+	return string(status)
+}
+
+// RenderNotification 渲染通知邮件；自定义模板读取或渲染失败时改用内置模板，并记录原因
+func RenderNotification(emailConfig *config.EmailNotificationConfig, data *NotificationData) (*NotificationMessage, error) {
+	engine := config.NewTemplateEngine(".")
+	render := engine.RenderText
+	if emailConfig.IsHTML() {
+		render = engine.RenderHTML
+	}
+	message := &NotificationMessage{HTML: emailConfig.IsHTML()}
+
+	// 链接和主题在正文之前生成，正文模板中可以引用 .Link
+	if emailConfig.Link != "" {
+		link, err := engine.RenderText("link", emailConfig.Link, data)
+		if err != nil {
+			message.FallbackReason = fmt.Sprintf("链接模板: %v", err)
+		} else {
+			data.Link = strings.TrimSpace(link)
+		}
+	}
+
+	subject, err := engine.RenderText("subject", notificationSubjectTemplate, data)
+	if emailConfig.Subject != "" {
+		custom, customErr := engine.RenderText("subject", emailConfig.Subject, data)
+		if customErr == nil {
+			subject = custom
+		} else {
+			message.FallbackReason = appendFallbackReason(message.FallbackReason, fmt.Sprintf("主题模板: %v", customErr))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	// 主题不能换行
+	message.Subject = strings.Join(strings.Fields(subject), " ")
+
+	builtin := notificationTextTemplate
+	if message.HTML {
+		builtin = notificationHTMLTemplate
+	}
+	if emailConfig.Template != "" {
+		body, customErr := renderNotificationTemplate(engine, emailConfig.Template, data, render)
+		if customErr == nil {
+			message.Body = body
+			return message, nil
+		}
+		message.FallbackReason = appendFallbackReason(message.FallbackReason,
+			fmt.Sprintf("正文模板 %s: %v", emailConfig.Template, customErr))
+	}
+
+	body, err := render("body", builtin, data)
+	if err != nil {
+		return nil, err
+	}
+	message.Body = body
+	return message, nil
+}
+
+// renderNotificationTemplate 读取并渲染自定义正文模板
+func renderNotificationTemplate(engine *config.TemplateEngine, path string, data *NotificationData,
+	render func(name, content string, data interface{}) (string, error)) (string, error) {
+	engine.SetTemplateDir(filepath.Dir(path))
+	content, err := engine.ReadTemplate(filepath.Base(path))
+	if err != nil {
+		return "", err
+	}
+	return render(filepath.Base(path), content, data)
+}
+
+// appendFallbackReason 追加回退原因
+func appendFallbackReason(reasons, reason string) string {
+	if reasons == "" {
+		return reason
+	}
+	return reasons + "; " + reason
+}
+
+// mailSender 发送邮件的函数，测试时替换
+type mailSender func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+
+// Notifier 迁移结果通知
+type Notifier struct {
+	cfg        *config.ProjectConfig
+	logger     *utils.Logger
+	sendMail   mailSender
+	botBackoff time.Duration // 群机器人重试的退避间隔
+}
+
+// NewNotifier 创建迁移结果通知器
+func NewNotifier(cfg *config.ProjectConfig) *Notifier {
+	return &Notifier{
+		cfg:        cfg,
+		logger:     utils.GetGlobalLogger(),
+		sendMail:   smtp.SendMail,
+		botBackoff: time.Second,
+	}
+}
+
+// Enabled 是否配置了通知
+func (n *Notifier) Enabled() bool {
+	return n.cfg.Notifications.Email.Enabled || len(n.cfg.Notifications.Bots) > 0
+}
+
+// NotifyMigrationFinished 迁移结束后按配置的事件发送通知，未启用或事件不匹配时不发送
+func (n *Notifier) NotifyMigrationFinished(record *HistoryRecord) error {
+	emailConfig := &n.cfg.Notifications.Email
+	if !emailConfig.Enabled || record == nil {
+		return nil
+	}
+	event := config.NotificationEventFailure
+	if record.Status == StatusCompleted {
+		event = config.NotificationEventSuccess
+	}
+	if !emailConfig.NotifyOn(event) {
+		return nil
+	}
+
+	message, err := RenderNotification(emailConfig, NewNotificationData(n.cfg, record))
+	if err != nil {
+		return err
+	}
+	return n.SendEmail(message)
+}
+
+// SendEmail 通过SMTP发送通知邮件
+func (n *Notifier) SendEmail(message *NotificationMessage) error {
+	emailConfig := &n.cfg.Notifications.Email
+	if message.FallbackReason != "" {
+		n.logger.Warnf("自定义通知模板渲染失败，已使用内置模板: %s", message.FallbackReason)
+	}
+
+	from, err := parseMailAddress(emailConfig.From)
+	if err != nil {
+		return err
+	}
+	to := make([]string, 0, len(emailConfig.To))
+	for _, address := range emailConfig.To {
+		parsed, err := parseMailAddress(address)
+		if err != nil {
+			return err
+		}
+		to = append(to, parsed)
+	}
+
+	var auth smtp.Auth
+	if emailConfig.Username != "" {
+		auth = smtp.PlainAuth("", emailConfig.Username, emailConfig.Password, emailConfig.SMTPHost)
+	}
+	addr := net.JoinHostPort(emailConfig.SMTPHost, strconv.Itoa(emailConfig.SMTPPort))
+	if err := n.sendMail(addr, auth, from, to, buildMailMessage(emailConfig, message)); err != nil {
+		return utils.NewError(utils.ErrorTypeSystem, "NOTIFICATION_SEND_FAILED").
+			Message("发送通知邮件失败").
+			Details(err.Error()).
+			Cause(err).
+			Suggestion(fmt.Sprintf("检查 notifications.email 中的SMTP服务器 %s 和账号配置", addr)).
+			Build()
+	}
+	n.logger.Infof("已发送通知邮件: %s", message.Subject)
+	return nil
+}
+
+// parseMailAddress 解析邮件地址，返回用于SMTP信封的纯地址
+func parseMailAddress(address string) (string, error) {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil {
+		return "", utils.ValidationErrors.InvalidFormat("email", address)
+	}
+	return parsed.Address, nil
+}
+
+// buildMailMessage 构建MIME邮件，正文使用base64编码以支持中文
+func buildMailMessage(emailConfig *config.EmailNotificationConfig, message *NotificationMessage) []byte {
+	contentType := "text/plain"
+	if message.HTML {
+		contentType = "text/html"
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", emailConfig.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(emailConfig.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: %s; charset=UTF-8\r\n", contentType)
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(message.Body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes()
+}
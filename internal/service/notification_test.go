@@ -0,0 +1,155 @@
+package service
+
+import (
+	"encoding/base64"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+)
+
+func notificationTestRecord(status ExecutionStatus) *HistoryRecord {
+	start := time.Date(2024, 3, 1, 22, 0, 0, 0, time.Local)
+	return &HistoryRecord{
+		RunID:     "run-42",
+		Task:      "完整迁移",
+		Status:    status,
+		StartTime: start,
+		EndTime:   start.Add(90 * time.Second),
+		Duration:  90*time.Second + 300*time.Millisecond,
+		Metadata:  map[string]string{"ticket": "JIRA-1", MetadataNoteKey: "夜间窗口"},
+		Results: []HistoryTypeResult{
+			{Type: MigrationTypeTable, Status: StatusCompleted, Duration: 30 * time.Second},
+			{Type: MigrationTypeCopy, Status: status, Duration: time.Minute, Error: "<ERROR> duplicate key"},
+		},
+	}
+}
+
+func notificationTestConfig() *config.ProjectConfig {
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("通知项目")
+	cfg := manager.GetConfig()
+	cfg.Notifications.Email = config.EmailNotificationConfig{
+		Enabled:  true,
+		SMTPHost: "smtp.example.com",
+		SMTPPort: 25,
+		From:     "机器人 <bot@example.com>",
+		To:       []string{"dba@example.com"},
+	}
+	return cfg
+}
+
+func TestNewNotificationData(t *testing.T) {
+	data := NewNotificationData(notificationTestConfig(), notificationTestRecord(StatusFailed))
+	assert.Equal(t, "通知项目", data.Project)
+	assert.False(t, data.Success)
+	assert.Equal(t, "失败", data.StatusText)
+	assert.Equal(t, "1m30s", data.Duration)
+	assert.Equal(t, 2, data.Total)
+	assert.Equal(t, 1, data.Succeeded)
+	assert.Equal(t, 1, data.Failed)
+	require.Len(t, data.Failures, 1)
+	assert.Equal(t, "COPY", data.Failures[0].Type)
+	assert.Equal(t, []string{"ticket=JIRA-1"}, data.Tags)
+	assert.Equal(t, "夜间窗口", data.Note)
+}
+
+func TestRenderNotification(t *testing.T) {
+	cfg := notificationTestConfig()
+	emailConfig := cfg.Notifications.Email
+	emailConfig.Link = "https://ci.example.com/runs/{{.RunID}}"
+
+	// 内置纯文本模板
+	message, err := RenderNotification(&emailConfig, NewNotificationData(cfg, notificationTestRecord(StatusFailed)))
+	require.NoError(t, err)
+	assert.Empty(t, message.FallbackReason)
+	assert.Equal(t, "[ora2pg-admin] 通知项目 完整迁移失败", message.Subject)
+	assert.Contains(t, message.Body, "结果: 1/2 成功，1 失败")
+	assert.Contains(t, message.Body, "标签: ticket=JIRA-1")
+	assert.Contains(t, message.Body, "- COPY: 失败（1m0s）\n  错误: <ERROR> duplicate key")
+	assert.Contains(t, message.Body, "详情: https://ci.example.com/runs/run-42")
+
+	// 内置HTML模板转义错误信息
+	emailConfig.Format = config.NotificationFormatHTML
+	message, err = RenderNotification(&emailConfig, NewNotificationData(cfg, notificationTestRecord(StatusFailed)))
+	require.NoError(t, err)
+	assert.True(t, message.HTML)
+	assert.Contains(t, message.Body, "&lt;ERROR&gt; duplicate key")
+	assert.Contains(t, message.Body, `<a href="https://ci.example.com/runs/run-42">`)
+
+	// 自定义模板和主题
+	dir := t.TempDir()
+	custom := filepath.Join(dir, "notify.tmpl")
+	require.NoError(t, os.WriteFile(custom, []byte("{{.Task}}: {{.Succeeded}}/{{.Total}}{{range .Failures}} {{.Type}}{{end}}"), 0644))
+	emailConfig.Format = config.NotificationFormatText
+	emailConfig.Template = custom
+	emailConfig.Subject = "{{.Project}} {{.StatusText}}\n{{.RunID}}"
+	message, err = RenderNotification(&emailConfig, NewNotificationData(cfg, notificationTestRecord(StatusFailed)))
+	require.NoError(t, err)
+	assert.Empty(t, message.FallbackReason)
+	assert.Equal(t, "通知项目 失败 run-42", message.Subject)
+	assert.Equal(t, "完整迁移: 1/2 COPY", message.Body)
+
+	// 自定义模板引用不存在的字段时回退到内置模板
+	require.NoError(t, os.WriteFile(custom, []byte("{{.Unknown}}"), 0644))
+	emailConfig.Subject = "{{.Project"
+	message, err = RenderNotification(&emailConfig, NewNotificationData(cfg, notificationTestRecord(StatusCompleted)))
+	require.NoError(t, err)
+	assert.Contains(t, message.FallbackReason, "主题模板")
+	assert.Contains(t, message.FallbackReason, "正文模板")
+	assert.Equal(t, "[ora2pg-admin] 通知项目 完整迁移成功", message.Subject)
+	assert.Contains(t, message.Body, "结果: 2/2 成功")
+
+	// 模板文件不存在同样回退
+	emailConfig.Subject = ""
+	emailConfig.Template = filepath.Join(dir, "missing.tmpl")
+	message, err = RenderNotification(&emailConfig, NewNotificationData(cfg, notificationTestRecord(StatusCompleted)))
+	require.NoError(t, err)
+	assert.Contains(t, message.FallbackReason, "missing.tmpl")
+}
+
+func TestNotifierNotifyMigrationFinished(t *testing.T) {
+	cfg := notificationTestConfig()
+	cfg.Notifications.Email.Format = config.NotificationFormatHTML
+
+	var sentAddr string
+	var sentTo []string
+	var sentMsg []byte
+	notifier := NewNotifier(cfg)
+	notifier.sendMail = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		sentAddr, sentTo, sentMsg = addr, to, msg
+		assert.Equal(t, "bot@example.com", from)
+		assert.Nil(t, auth)
+		return nil
+	}
+
+	require.NoError(t, notifier.NotifyMigrationFinished(notificationTestRecord(StatusFailed)))
+	assert.Equal(t, "smtp.example.com:25", sentAddr)
+	assert.Equal(t, []string{"dba@example.com"}, sentTo)
+
+	header, body, found := strings.Cut(string(sentMsg), "\r\n\r\n")
+	require.True(t, found)
+	assert.Contains(t, header, "Content-Type: text/html; charset=UTF-8")
+	assert.Contains(t, header, "Subject: =?UTF-8?b?")
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(body, "\r\n", ""))
+	require.NoError(t, err)
+	assert.Contains(t, string(decoded), "完整迁移失败")
+
+	// 只通知失败时，成功的运行不发送
+	sentMsg = nil
+	cfg.Notifications.Email.Events = []string{config.NotificationEventFailure}
+	require.NoError(t, notifier.NotifyMigrationFinished(notificationTestRecord(StatusCompleted)))
+	assert.Nil(t, sentMsg)
+
+	// 未启用时不发送
+	cfg.Notifications.Email.Enabled = false
+	require.NoError(t, notifier.NotifyMigrationFinished(notificationTestRecord(StatusFailed)))
+	assert.Nil(t, sentMsg)
+}
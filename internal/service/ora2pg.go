@@ -23,6 +23,7 @@ type MigrationType string
 const (
 	MigrationTypeTable     MigrationType = "TABLE"
 	MigrationTypeView      MigrationType = "VIEW"
+	MigrationTypeMView     MigrationType = "MVIEW"
 	MigrationTypeSequence  MigrationType = "SEQUENCE"
 	MigrationTypeIndex     MigrationType = "INDEX"
 	MigrationTypeTrigger   MigrationType = "TRIGGER"
@@ -33,6 +34,8 @@ const (
 	MigrationTypeGrant     MigrationType = "GRANT"
 	MigrationTypeCopy      MigrationType = "COPY"
 	MigrationTypeInsert    MigrationType = "INSERT"
+	MigrationTypeDBLink    MigrationType = "DBLINK"
+	MigrationTypeSynonym   MigrationType = "SYNONYM"
 )
 
 // ExecutionStatus 执行状态
@@ -44,8 +47,15 @@ const (
 	StatusCompleted  ExecutionStatus = "COMPLETED"
 	StatusFailed     ExecutionStatus = "FAILED"
 	StatusCancelled  ExecutionStatus = "CANCELLED"
+	// StatusSkipped 源库中没有该类型的对象，未执行ora2pg
+	StatusSkipped    ExecutionStatus = "SKIPPED"
 )
 
+// Succeeded 执行成功或因没有对象而跳过，都不需要重新执行
+func (s ExecutionStatus) Succeeded() bool {
+	return s == StatusCompleted || s == StatusSkipped
+}
+
 // ExecutionResult 执行结果
 type ExecutionResult struct {
 	Status       ExecutionStatus `json:"status"`
@@ -57,6 +67,20 @@ type ExecutionResult struct {
 	ErrorOutput  string          `json:"error_output"`
 	Progress     *ProgressInfo   `json:"progress,omitempty"`
 	Error        error           `json:"error,omitempty"`
+	Resources    *ResourceStats  `json:"resources,omitempty"`
+	ErrorCount   int             `json:"error_count"`
+	WarningCount int             `json:"warning_count"`
+	// MigratedRows 从ora2pg输出中统计的导出行数
+	MigratedRows int64           `json:"migrated_rows,omitempty"`
+	// LogFiles ora2pg日志文件，切割时为按顺序排列的各分段
+	LogFiles     []string        `json:"log_files,omitempty"`
+	// Tables 从ora2pg输出中采集的表级耗时，按首次出现的顺序排列
+	Tables       []TableTiming   `json:"tables,omitempty"`
+}
+
+// HasWarnings 退出码为0但输出中有警告或错误，即"成功（有警告）"
+func (r *ExecutionResult) HasWarnings() bool {
+	return r.Status == StatusCompleted && (r.WarningCount > 0 || r.ErrorCount > 0)
 }
 
 // ProgressInfo 进度信息
@@ -80,19 +104,35 @@ type ExecutionOptions struct {
 	Timeout       time.Duration     `json:"timeout"`
 	Environment   map[string]string `json:"environment"`
 	WorkingDir    string            `json:"working_dir"`
+	// LineHandler 每读取一行输出时回调（stdout和stderr可能并发调用）
+	LineHandler   func(line string) `json:"-"`
+	// OutputSinks 输出实时转发目标，异步发送，失败不影响迁移
+	OutputSinks   []OutputSink      `json:"-"`
+	// LogFilter 数据行过滤，在输出写入结果、日志、LineHandler 和 OutputSinks 之前应用，nil 表示不过滤
+	LogFilter     *LogDataFilter    `json:"-"`
+	// OnProcessStart ora2pg进程启动后回调，用于资源监控
+	OnProcessStart func(pid int)    `json:"-"`
+	// OnTimeoutWarning 接近超时时回调，可返回延长的时间；在独立goroutine中调用
+	OnTimeoutWarning TimeoutWarningHandler `json:"-"`
 }
 
 // Ora2pgService ora2pg包装服务
 type Ora2pgService struct {
 	logger    *utils.Logger
 	fileUtils *utils.FileUtils
+	// version 检测到的ora2pg版本，用于适配命令行参数和配置指令
+	version         Ora2pgVersion
+	versionDetected bool
+	// progressRules 解析ora2pg输出的规则，创建时复制全局规则集
+	progressRules *ProgressRuleSet
 }
 
 // NewOra2pgService 创建新的ora2pg服务
 func NewOra2pgService() *Ora2pgService {
 	return &Ora2pgService{
-		logger:    utils.GetGlobalLogger(),
-		fileUtils: utils.NewFileUtils(),
+		logger:        utils.GetGlobalLogger(),
+		fileUtils:     utils.NewFileUtils(),
+		progressRules: defaultProgressRules.Clone(),
 	}
 }
 
@@ -130,7 +170,7 @@ func (s *Ora2pgService) Execute(ctx context.Context, migrationType MigrationType
 
 	// 4. 执行命令
 	result.Status = StatusRunning
-	if err := s.executeCommand(ctx, args, options, result); err != nil {
+	if err := s.executeCommand(ctx, migrationType, args, options, result); err != nil {
 		result.Status = StatusFailed
 		result.Error = err
 		result.EndTime = time.Now()
@@ -217,9 +257,13 @@ func (s *Ora2pgService) buildCommandArgs(migrationType MigrationType, options *E
 		args = append(args, "-n")
 	}
 
-	// 添加日志文件参数
+	// 添加日志文件参数，旧版本不支持时由调用方自行记录输出
 	if options.LogFile != "" {
-		args = append(args, "-l", options.LogFile)
+		if s.Compat().SupportsFlag("-l") {
+			args = append(args, "-l", options.LogFile)
+		} else {
+			s.logger.Warnf("ora2pg %s 不支持 -l 参数，不生成ora2pg日志文件", s.version)
+		}
 	}
 
 	s.logger.Debugf("构建的命令参数: %v", args)
@@ -247,7 +291,7 @@ func (s *Ora2pgService) prepareExecutionEnvironment(options *ExecutionOptions) e
 }
 
 // executeCommand 执行命令
-func (s *Ora2pgService) executeCommand(ctx context.Context, args []string, options *ExecutionOptions, result *ExecutionResult) error {
+func (s *Ora2pgService) executeCommand(ctx context.Context, migrationType MigrationType, args []string, options *ExecutionOptions, result *ExecutionResult) error {
 	// 创建命令
 	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
 
@@ -277,60 +321,92 @@ func (s *Ora2pgService) executeCommand(ctx context.Context, args []string, optio
 	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("启动ora2pg命令失败: %v", err)
 	}
+	if options.OnProcessStart != nil {
+		options.OnProcessStart(cmd.Process.Pid)
+	}
 
 	// 处理输出
-	outputChan := make(chan string, 100)
-	errorChan := make(chan string, 100)
 	doneChan := make(chan bool, 2)
 
+	dispatcher := newSinkDispatcher(options.OutputSinks, s.logger)
+	defer dispatcher.Close()
+	forward := func(stream string) func(string) {
+		return func(line string) {
+			if options.LineHandler != nil {
+				options.LineHandler(line)
+			}
+			dispatcher.Dispatch(OutputLine{
+				MigrationType: migrationType,
+				Stream:        stream,
+				Line:          line,
+				Time:          time.Now(),
+			})
+		}
+	}
+
+	// 收集输出，与读取并发进行
+	outputCollector := newOutputCollector(100)
+	errorCollector := newOutputCollector(100)
+
 	// 读取标准输出
-	go s.readOutput(stdout, outputChan, doneChan, result)
+	go s.readOutput(stdout, outputCollector.lines, doneChan, result, options.LogFilter.Stream(), forward(StreamStdout))
 	// 读取错误输出
-	go s.readOutput(stderr, errorChan, doneChan, result)
+	go s.readOutput(stderr, errorCollector.lines, doneChan, result, options.LogFilter.Stream(), forward(StreamStderr))
 
 	// 等待命令完成或超时
+	waitChan := waitAfterOutput(cmd, doneChan, 2)
+
 	var waitErr error
+	var timeout *commandTimeout
 	if options.Timeout > 0 {
-		timer := time.NewTimer(options.Timeout)
-		defer timer.Stop()
+		timeout = newCommandTimeout(options.Timeout)
+		defer timeout.Stop()
+	}
 
+	// 接近超时时分级预警；询问是否延长在独立goroutine中进行，不影响等待命令结束
+	extendChan := make(chan time.Duration, 1)
+	asking := false
+	for waiting := true; waiting; {
 		select {
 		case <-ctx.Done():
 			cmd.Process.Kill()
+			<-waitChan
 			waitErr = ctx.Err()
-		case <-timer.C:
+			waiting = false
+		case <-timeout.Expired():
 			cmd.Process.Kill()
-			waitErr = fmt.Errorf("命令执行超时")
-		case waitErr = <-func() chan error {
-			errChan := make(chan error, 1)
-			go func() {
-				errChan <- cmd.Wait()
-			}()
-			return errChan
-		}():
+			<-waitChan
+			waitErr = fmt.Errorf("命令执行超时（%v）", timeout.Timeout())
+			waiting = false
+		case <-timeout.Warning():
+			elapsed, limit := timeout.Elapsed(), timeout.Timeout()
+			s.logger.Warnf("迁移类型 %s 已运行 %v，接近超时阈值 %v", migrationType, elapsed.Round(time.Second), limit)
+			timeout.NextWarning()
+			if options.OnTimeoutWarning != nil && !asking {
+				asking = true
+				go func() {
+					extendChan <- options.OnTimeoutWarning(migrationType, elapsed, limit)
+				}()
+			}
+		case extension := <-extendChan:
+			asking = false
+			if extension > 0 {
+				timeout.Extend(extension)
+				s.logger.Infof("迁移类型 %s 的超时已延长至 %v", migrationType, timeout.Timeout())
+			}
+		case waitErr = <-waitChan:
+			waiting = false
 		}
-	} else {
-		waitErr = cmd.Wait()
 	}
 
-	// 等待输出读取完成
-	<-doneChan
-	<-doneChan
-	close(outputChan)
-	close(errorChan)
-
-	// 收集输出
-	var outputBuilder, errorBuilder strings.Builder
-	for output := range outputChan {
-		outputBuilder.WriteString(output)
-	}
-	for errorOutput := range errorChan {
-		errorBuilder.WriteString(errorOutput)
+	result.Output = outputCollector.Close()
+	result.ErrorOutput = errorCollector.Close()
+	result.ErrorCount, result.WarningCount = countLogLevels(result.Output + result.ErrorOutput)
+	result.MigratedRows = countMigratedRows(result.Output + result.ErrorOutput)
+	if filtered := options.LogFilter.Filtered(); filtered > 0 {
+		s.logger.Infof("迁移类型 %s 的输出中已过滤 %d 行数据", migrationType, filtered)
 	}
 
-	result.Output = outputBuilder.String()
-	result.ErrorOutput = errorBuilder.String()
-
 	// 获取退出码
 	if waitErr != nil {
 		if exitError, ok := waitErr.(*exec.ExitError); ok {
@@ -345,18 +421,27 @@ func (s *Ora2pgService) executeCommand(ctx context.Context, args []string, optio
 	return nil
 }
 
-// readOutput 读取命令输出
-func (s *Ora2pgService) readOutput(reader io.Reader, outputChan chan<- string, doneChan chan<- bool, result *ExecutionResult) {
+// readOutput 读取命令输出，filter 为nil时不过滤数据行
+func (s *Ora2pgService) readOutput(reader io.Reader, outputChan chan<- string, doneChan chan<- bool, result *ExecutionResult, filter *LogDataStream, lineHandler func(string)) {
 	defer func() { doneChan <- true }()
 
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
 		line := scanner.Text()
-		outputChan <- line + "\n"
 
-		// 解析进度信息
+		// 解析进度信息，使用过滤前的原始输出，只保存在内存中
 		s.parseProgress(line, result.Progress)
 
+		line, keep := filter.Filter(line)
+		if !keep {
+			continue
+		}
+		outputChan <- line + "\n"
+
+		if lineHandler != nil {
+			lineHandler(line)
+		}
+
 		// 记录重要日志
 		if s.isImportantLogLine(line) {
 			s.logger.Info(line)
@@ -368,67 +453,86 @@ func (s *Ora2pgService) readOutput(reader io.Reader, outputChan chan<- string, d
 	}
 }
 
-// parseProgress 解析进度信息
+// parseProgress 按注册的解析规则更新进度信息
 func (s *Ora2pgService) parseProgress(line string, progress *ProgressInfo) {
 	if progress == nil {
 		return
 	}
+	s.progressRules.Parse(line, progress)
+}
 
-	// 解析不同类型的进度信息
-	patterns := []struct {
-		regex   *regexp.Regexp
-		handler func(matches []string, progress *ProgressInfo)
-	}{
-		{
-			// 匹配 "Processing table: TABLE_NAME (1/10)"
-			regexp.MustCompile(`Processing\s+(\w+):\s+(\w+)\s+\((\d+)/(\d+)\)`),
-			func(matches []string, progress *ProgressInfo) {
-				progress.CurrentStep = fmt.Sprintf("处理%s: %s", matches[1], matches[2])
-				if completed, err := strconv.Atoi(matches[3]); err == nil {
-					progress.CompletedSteps = completed
-				}
-				if total, err := strconv.Atoi(matches[4]); err == nil {
-					progress.TotalSteps = total
-					if total > 0 {
-						progress.Percentage = float64(progress.CompletedSteps) / float64(total) * 100
-					}
-				}
-			},
-		},
-		{
-			// 匹配 "Exported 1000 rows"
-			regexp.MustCompile(`Exported\s+(\d+)\s+rows`),
-			func(matches []string, progress *ProgressInfo) {
-				if rows, err := strconv.ParseInt(matches[1], 10, 64); err == nil {
-					progress.ProcessedRows = rows
-				}
-				progress.Message = fmt.Sprintf("已导出 %s 行数据", matches[1])
-			},
-		},
-		{
-			// 匹配 "Total rows: 10000"
-			regexp.MustCompile(`Total\s+rows:\s+(\d+)`),
-			func(matches []string, progress *ProgressInfo) {
-				if rows, err := strconv.ParseInt(matches[1], 10, 64); err == nil {
-					progress.TotalRows = rows
-				}
-			},
-		},
-		{
-			// 匹配一般的状态信息
-			regexp.MustCompile(`^(INFO|WARNING|ERROR):\s+(.+)`),
-			func(matches []string, progress *ProgressInfo) {
-				progress.Message = matches[2]
-			},
-		},
-	}
-
-	for _, pattern := range patterns {
-		if matches := pattern.regex.FindStringSubmatch(line); matches != nil {
-			pattern.handler(matches, progress)
-			break
+// logLevel ora2pg输出行的级别
+type logLevel int
+
+const (
+	logLevelNone logLevel = iota
+	logLevelWarning
+	logLevelError
+)
+
+// logLevelPattern 匹配行首（可带时间戳前缀）的级别标记，如 "[2024-01-01 10:00:00] WARNING: ..."
+var logLevelPattern = regexp.MustCompile(`^\s*(?:\[[^\]]*\]\s*)?(FATAL|ERROR|WARNING|WARN)\b`)
+
+// oracleErrorPattern 匹配DBD::Oracle等输出中的Oracle错误码
+var oracleErrorPattern = regexp.MustCompile(`\bORA-\d{5}\b`)
+
+// classifyLogLine 判断输出行是错误、警告还是普通信息
+func classifyLogLine(line string) logLevel {
+	if matches := logLevelPattern.FindStringSubmatch(line); matches != nil {
+		if strings.HasPrefix(matches[1], "WARN") {
+			return logLevelWarning
+		}
+		return logLevelError
+	}
+	if oracleErrorPattern.MatchString(line) {
+		return logLevelError
+	}
+	return logLevelNone
+}
+
+// countLogLevels 统计输出中的错误行数和警告行数
+func countLogLevels(output string) (errors, warnings int) {
+	for _, line := range strings.Split(output, "\n") {
+		switch classifyLogLine(line) {
+		case logLevelError:
+			errors++
+		case logLevelWarning:
+			warnings++
 		}
 	}
+	return errors, warnings
+}
+
+var (
+	tableRowsDonePattern = regexp.MustCompile(`(\d+)/\d+\s+rows\s+\(100(?:\.0+)?%\)\s+Table\s+[\w$#.]+`)
+	totalRowsPattern     = regexp.MustCompile(`(\d+)\s+total\s+rows`)
+)
+
+// countMigratedRows 统计输出中导出的行数
+//
+// 优先使用ora2pg结束时输出的 "... and N total rows in ..." 汇总行，没有汇总行时累加各表的完成行，
+// 如 "[====>] 14/14 rows (100.0%) Table COUNTRIES (14 recs/sec)"。
+func countMigratedRows(output string) int64 {
+	var tableRows, totalRows int64
+	hasTotal := false
+	for _, line := range strings.Split(output, "\n") {
+		if matches := totalRowsPattern.FindStringSubmatch(line); matches != nil {
+			if rows, err := strconv.ParseInt(matches[1], 10, 64); err == nil {
+				totalRows += rows
+				hasTotal = true
+			}
+			continue
+		}
+		if matches := tableRowsDonePattern.FindStringSubmatch(line); matches != nil {
+			if rows, err := strconv.ParseInt(matches[1], 10, 64); err == nil {
+				tableRows += rows
+			}
+		}
+	}
+	if hasTotal {
+		return totalRows
+	}
+	return tableRows
 }
 
 // isImportantLogLine 判断是否为重要日志行
@@ -459,6 +563,7 @@ func (s *Ora2pgService) GetSupportedTypes() []MigrationType {
 	return []MigrationType{
 		MigrationTypeTable,
 		MigrationTypeView,
+		MigrationTypeMView,
 		MigrationTypeSequence,
 		MigrationTypeIndex,
 		MigrationTypeTrigger,
@@ -469,6 +574,8 @@ func (s *Ora2pgService) GetSupportedTypes() []MigrationType {
 		MigrationTypeGrant,
 		MigrationTypeCopy,
 		MigrationTypeInsert,
+		MigrationTypeDBLink,
+		MigrationTypeSynonym,
 	}
 }
 
@@ -484,8 +591,8 @@ func (s *Ora2pgService) ValidateMigrationType(migrationType MigrationType) error
 	return utils.ValidationErrors.InvalidFormat("migration_type", string(migrationType))
 }
 
-// GenerateConfigFile 生成ora2pg配置文件
-func (s *Ora2pgService) GenerateConfigFile(cfg *config.ProjectConfig, outputPath string) error {
+// GenerateConfigFile 生成ora2pg配置文件，validate 为true时生成后校验ora2pg能否解析
+func (s *Ora2pgService) GenerateConfigFile(cfg *config.ProjectConfig, outputPath string, validate bool) error {
 	templateEngine := config.NewTemplateEngine("templates")
 
 	// 检查模板目录
@@ -504,7 +611,35 @@ func (s *Ora2pgService) GenerateConfigFile(cfg *config.ProjectConfig, outputPath
 		}
 	}
 
-	return templateEngine.GenerateOra2pgConfig(cfg, outputPath)
+	// 命名规则需要逐表改名时，先查询源库表名
+	if cfg.Migration.NamingConvention.RenamesTables() {
+		tables, err := namingSourceTables(cfg)
+		if err != nil {
+			return err
+		}
+		templateEngine.SetSourceTables(tables)
+	}
+
+	if err := templateEngine.GenerateOra2pgConfig(cfg, outputPath); err != nil {
+		return err
+	}
+
+	// 托管数据库没有超级用户权限，改写需要超级用户的指令
+	if cfg.PostgreSQL.Managed {
+		if err := s.adaptManagedConfigFile(outputPath); err != nil {
+			return err
+		}
+	}
+
+	// 按ora2pg版本改用旧指令名或注释掉不支持的指令
+	s.ensureVersion(context.Background(), ora2pgEnvironment(cfg))
+	if err := s.adaptConfigFile(outputPath); err != nil {
+		return err
+	}
+	if !validate {
+		return nil
+	}
+	return s.ValidateConfigFile(context.Background(), outputPath, ora2pgEnvironment(cfg))
 }
 
 // GetExecutionSummary 获取执行摘要
@@ -514,6 +649,7 @@ func (s *Ora2pgService) GetExecutionSummary(results []*ExecutionResult) map[stri
 		"successful":       0,
 		"failed":          0,
 		"cancelled":       0,
+		"skipped":         0,
 		"total_duration":  time.Duration(0),
 		"details":         []map[string]interface{}{},
 	}
@@ -526,6 +662,8 @@ func (s *Ora2pgService) GetExecutionSummary(results []*ExecutionResult) map[stri
 			summary["failed"] = summary["failed"].(int) + 1
 		case StatusCancelled:
 			summary["cancelled"] = summary["cancelled"].(int) + 1
+		case StatusSkipped:
+			summary["skipped"] = summary["skipped"].(int) + 1
 		}
 
 		summary["total_duration"] = summary["total_duration"].(time.Duration) + result.Duration
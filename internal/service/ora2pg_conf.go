@@ -0,0 +1,192 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/utils"
+)
+
+// ora2pgConfCheckTimeout ora2pg解析配置文件的超时时间，SHOW_VERSION 会尝试连接Oracle
+const ora2pgConfCheckTimeout = 30 * time.Second
+
+// requiredOra2pgDirectives 生成的配置文件中必须有值的指令
+var requiredOra2pgDirectives = []string{"ORACLE_DSN", "ORACLE_USER"}
+
+var (
+	// ora2pgConfProblemPattern ora2pg输出中表示配置问题的行
+	ora2pgConfProblemPattern = regexp.MustCompile(`(?i)\b(FATAL|ERROR|invalid|unknown|not supported|syntax error)\b`)
+	// ora2pgConnectPattern 连接Oracle失败，说明配置已被解析
+	ora2pgConnectPattern = regexp.MustCompile(`(?i)ORA-\d{5}|DBI connect|can(?:'t|not| not) connect`)
+)
+
+// Ora2pgConfIssue ora2pg配置文件中的一个问题
+type Ora2pgConfIssue struct {
+	Line    int    // 行号，0表示来自ora2pg输出
+	Message string // 问题描述
+}
+
+// ValidateConfigFile 校验生成的ora2pg配置文件，先检查必填指令和模板渲染残留，
+// 再调用 ora2pg -t SHOW_VERSION 确认ora2pg能解析该文件；ora2pg不可用或无法连接Oracle时跳过后一步
+func (s *Ora2pgService) ValidateConfigFile(ctx context.Context, configPath string, env map[string]string) error {
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return utils.FileErrors.ReadFailed(configPath, err)
+	}
+	if issues := inspectOra2pgConf(content); len(issues) > 0 {
+		return ora2pgConfError(configPath, issues)
+	}
+
+	if _, err := exec.LookPath("ora2pg"); err != nil {
+		s.logger.Infof("未找到ora2pg，跳过配置文件校验: %s", configPath)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ora2pgConfCheckTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "ora2pg", "-c", configPath, "-t", "SHOW_VERSION")
+	cmd.Env = os.Environ()
+	for key, value := range env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+	}
+	output, runErr := cmd.CombinedOutput()
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		s.logger.Warnf("ora2pg校验配置文件超时（%v），未完成校验", ora2pgConfCheckTimeout)
+		return nil
+	}
+	if ora2pgConnectPattern.Match(output) {
+		s.logger.Warnf("ora2pg已解析配置文件，但无法连接Oracle，未完成完整校验")
+		return nil
+	}
+	issues := parseOra2pgConfOutput(output)
+	if runErr == nil && len(issues) == 0 {
+		s.logger.Infof("ora2pg配置文件校验通过: %s", configPath)
+		return nil
+	}
+	if len(issues) == 0 {
+		issues = []Ora2pgConfIssue{{Message: runErr.Error()}}
+	}
+	return ora2pgConfError(configPath, issues)
+}
+
+// inspectOra2pgConf 检查配置文件中的必填指令和未渲染的模板值
+func inspectOra2pgConf(content []byte) []Ora2pgConfIssue {
+	var issues []Ora2pgConfIssue
+	values := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value := line, ""
+		if i := strings.IndexAny(line, "= \t"); i >= 0 {
+			name, value = line[:i], strings.TrimSpace(line[i+1:])
+		}
+		name = strings.ToUpper(name)
+		values[name] = value
+
+		if strings.Contains(value, "<no value>") || strings.Contains(value, "{{") {
+			issues = append(issues, Ora2pgConfIssue{Line: lineNo, Message: fmt.Sprintf("%s 的值未正确渲染: %s", name, value)})
+		}
+	}
+
+	for _, name := range requiredOra2pgDirectives {
+		if values[name] == "" {
+			issues = append(issues, Ora2pgConfIssue{Message: fmt.Sprintf("%s 为空", name)})
+		}
+	}
+	return issues
+}
+
+// parseOra2pgConfOutput 从ora2pg输出中提取配置问题
+func parseOra2pgConfOutput(output []byte) []Ora2pgConfIssue {
+	var issues []Ora2pgConfIssue
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && ora2pgConfProblemPattern.MatchString(line) {
+			issues = append(issues, Ora2pgConfIssue{Message: line})
+		}
+	}
+	return issues
+}
+
+// ora2pgConfError 构建配置文件校验失败的错误
+func ora2pgConfError(configPath string, issues []Ora2pgConfIssue) error {
+	details := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		if issue.Line > 0 {
+			details = append(details, fmt.Sprintf("第%d行: %s", issue.Line, issue.Message))
+		} else {
+			details = append(details, issue.Message)
+		}
+	}
+	return utils.NewError(utils.ErrorTypeConfig, "ORA2PG_CONFIG_INVALID").
+		Message(fmt.Sprintf("ora2pg配置文件校验失败: %s", configPath)).
+		Details(strings.Join(details, "\n")).
+		Suggestion("运行 'ora2pg-admin 配置 数据库' 检查Oracle连接信息").
+		Suggestion(fmt.Sprintf("手动执行 ora2pg -c %s -t SHOW_VERSION 查看完整输出", configPath)).
+		Build()
+}
+
+// ora2pgEnvironment 构建执行ora2pg所需的环境变量，配置文件 environment 段中的变量优先于默认值
+func ora2pgEnvironment(cfg *config.ProjectConfig) map[string]string {
+	env := make(map[string]string)
+
+	// 设置Oracle相关环境变量，未启用自动检测时使用配置指定的客户端
+	if home := cfg.OracleClient.Home; home != "" {
+		if cfg.OracleClient.AutoDetect {
+			env["ORACLE_HOME"] = home
+		} else {
+			for key, value := range oracle.ClientEnvironment(home) {
+				env[key] = value
+			}
+		}
+	}
+
+	// 设置其他必要的环境变量
+	env["NLS_LANG"] = "AMERICAN_AMERICA.UTF8"
+
+	// TCPS 连接需要从 TNS_ADMIN 读取 sqlnet.ora 和 wallet
+	for key, value := range cfg.Oracle.ConnectionEnvironment() {
+		env[key] = value
+	}
+
+	return config.MergeEnvironment(env, cfg.Environment)
+}
+
+// namingSourceTables 查询命名规则需要转换的源库表名
+func namingSourceTables(cfg *config.ProjectConfig) ([]string, error) {
+	schema := cfg.Oracle.Schema
+	if schema == "" {
+		schema = cfg.Oracle.Username
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ora2pgConfCheckTimeout)
+	defer cancel()
+
+	runner := oracle.NewSQLPlusRunner(&cfg.Oracle, &cfg.OracleClient)
+	tables, err := oracle.NewInspector(runner, schema).TableNames(ctx)
+	if err != nil {
+		return nil, utils.NewError(utils.ErrorTypeConfig, "NAMING_TABLES_QUERY_FAILED").
+			Message("命名规则需要源库表名，查询失败").
+			Details(err.Error()).
+			Cause(err).
+			Suggestion("运行 'ora2pg-admin 检查 连接' 确认源库连接，或去掉 migration.naming_convention 中的前缀、后缀和驼峰转换").
+			Build()
+	}
+	return tables, nil
+}
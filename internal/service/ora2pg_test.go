@@ -2,12 +2,19 @@ package service
 
 import (
 	"context"
+	"errors"
+	"net"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/utils"
 )
 
 func TestNewOra2pgService(t *testing.T) {
@@ -24,6 +31,9 @@ func TestGetSupportedTypes(t *testing.T) {
 	assert.NotEmpty(t, types)
 	assert.Contains(t, types, MigrationTypeTable)
 	assert.Contains(t, types, MigrationTypeView)
+	assert.Contains(t, types, MigrationTypeMView)
+	assert.Contains(t, types, MigrationTypeDBLink)
+	assert.Contains(t, types, MigrationTypeSynonym)
 	assert.Contains(t, types, MigrationTypeSequence)
 	assert.Contains(t, types, MigrationTypeIndex)
 	assert.Contains(t, types, MigrationTypeTrigger)
@@ -53,6 +63,9 @@ func TestValidateMigrationType(t *testing.T) {
 		MigrationTypeGrant,
 		MigrationTypeCopy,
 		MigrationTypeInsert,
+		MigrationTypeMView,
+		MigrationTypeDBLink,
+		MigrationTypeSynonym,
 	}
 	
 	for _, validType := range validTypes {
@@ -349,6 +362,71 @@ func TestIsImportantLogLine(t *testing.T) {
 	}
 }
 
+func TestCountLogLevels(t *testing.T) {
+	output := strings.Join([]string{
+		"[2024-01-01 10:00:00] WARNING: column EMP.NOTE has unsupported type",
+		"WARNING: table DEPT has no primary key",
+		"ERROR: relation \"emp\" does not exist",
+		"DBD::Oracle::st execute failed: ORA-00942: table or view does not exist",
+		"FATAL: could not connect",
+		"Processing table EMP (1/2)",
+		"Exported 10 rows, no ERROR here",
+		"",
+	}, "\n")
+
+	errorCount, warnings := countLogLevels(output)
+	assert.Equal(t, 3, errorCount)
+	assert.Equal(t, 2, warnings)
+}
+
+func TestCountMigratedRows(t *testing.T) {
+	// 没有汇总行时累加各表的完成行，未完成的进度行不计入
+	output := strings.Join([]string{
+		"[========================>] 14/14 rows (100.0%) Table COUNTRIES (14 recs/sec)",
+		"[=====>                   ] 500/2000 rows (25.0%) Table ORDERS (500 recs/sec)",
+		"[========================>] 2000/2000 rows (100.0%) Table ORDERS (1000 recs/sec)",
+	}, "\n")
+	assert.Equal(t, int64(2014), countMigratedRows(output))
+
+	// 有汇总行时以汇总为准
+	output += "\n[2024-05-01 10:00:00] Total time to export data from 2 tables (0 partitions, 0 sparse) and 2014 total rows in 2 secs (1007 recs/sec)"
+	assert.Equal(t, int64(2014), countMigratedRows(output))
+
+	assert.Zero(t, countMigratedRows("ERROR: ORA-12541: TNS:no listener"))
+}
+
+func TestExecuteCompletedWithWarnings(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟ora2pg依赖 /bin/sh")
+	}
+
+	// 模拟ora2pg：输出警告但退出码为0，输出行数超过缓冲区大小
+	bin := t.TempDir()
+	script := `#!/bin/sh
+echo 'WARNING: table DEPT has no primary key'
+i=0
+while [ $i -lt 300 ]; do echo "Exported $i rows"; i=$((i+1)); done
+echo 'WARNING: unsupported type' >&2
+exit 0
+`
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ora2pg"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	service := NewOra2pgService()
+	result, err := service.Execute(context.Background(), MigrationTypeCopy, &ExecutionOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, result.Status)
+	assert.Equal(t, 0, result.ErrorCount)
+	assert.Equal(t, 2, result.WarningCount)
+	assert.True(t, result.HasWarnings())
+	assert.Contains(t, result.Output, "Exported 299 rows")
+
+	clean := &ExecutionResult{Status: StatusCompleted}
+	assert.False(t, clean.HasWarnings())
+	failed := &ExecutionResult{Status: StatusFailed, WarningCount: 1}
+	assert.False(t, failed.HasWarnings())
+}
+
 func TestExecuteWithInvalidTool(t *testing.T) {
 	service := NewOra2pgService()
 	
@@ -368,3 +446,205 @@ func TestExecuteWithInvalidTool(t *testing.T) {
 	assert.Equal(t, StatusFailed, result.Status)
 	assert.NotNil(t, result.Error)
 }
+
+// blockingSink 模拟阻塞且总是失败的sink
+type blockingSink struct {
+	release chan struct{}
+	mu      sync.Mutex
+	lines   []string
+}
+
+func (b *blockingSink) Name() string { return "blocking" }
+
+func (b *blockingSink) Write(line OutputLine) error {
+	<-b.release
+	b.mu.Lock()
+	b.lines = append(b.lines, line.Line)
+	b.mu.Unlock()
+	return errors.New("sink unavailable")
+}
+
+func (b *blockingSink) Close() error { return nil }
+
+func TestSinkDispatcherDoesNotBlock(t *testing.T) {
+	sink := &blockingSink{release: make(chan struct{})}
+	dispatcher := newSinkDispatcher([]OutputSink{sink}, NewOra2pgService().logger)
+
+	done := make(chan struct{})
+	go func() {
+		// 超过缓冲容量的输出也不应阻塞
+		for i := 0; i < defaultSinkBufferSize*2; i++ {
+			dispatcher.Dispatch(OutputLine{Stream: StreamStdout, Line: "line"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Dispatch 被阻塞")
+	}
+
+	close(sink.release)
+	dispatcher.Close()
+	assert.NotEmpty(t, sink.lines)
+	assert.LessOrEqual(t, len(sink.lines), defaultSinkBufferSize+1)
+}
+
+func TestSyslogSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	sink, err := NewSyslogSink("udp", conn.LocalAddr().String(), "ora2pg-test")
+	require.NoError(t, err)
+	defer sink.Close()
+
+	require.NoError(t, sink.Write(OutputLine{
+		MigrationType: MigrationTypeCopy,
+		Stream:        StreamStderr,
+		Line:          "ORA-00942: table or view does not exist",
+	}))
+
+	buf := make([]byte, 1024)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+
+	msg := string(buf[:n])
+	assert.True(t, strings.HasPrefix(msg, "<131>"), msg) // local0.err
+	assert.Contains(t, msg, "ora2pg-test[")
+	assert.Contains(t, msg, "[COPY] ORA-00942")
+
+	_, err = NewSyslogSink("http", "localhost:514", "")
+	assert.Error(t, err)
+}
+
+func TestParseSyslogTarget(t *testing.T) {
+	network, address, err := ParseSyslogTarget("tcp://log.example.com:601")
+	require.NoError(t, err)
+	assert.Equal(t, "tcp", network)
+	assert.Equal(t, "log.example.com:601", address)
+
+	_, _, err = ParseSyslogTarget("log.example.com:514")
+	assert.Error(t, err)
+}
+
+func TestValidateConfigFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟ora2pg依赖 /bin/sh")
+	}
+
+	dir := t.TempDir()
+	confPath := filepath.Join(dir, "ora2pg.conf")
+	validConf := "# 注释\nORACLE_DSN=dbi:Oracle:host=db;sid=ORCL;port=1521\nORACLE_USER=scott\nPG_DSN=dbi:Pg:dbname=app\n"
+	service := NewOra2pgService()
+
+	// 空DSN和未渲染的模板值无需ora2pg即可发现
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	require.NoError(t, os.WriteFile(confPath, []byte("ORACLE_DSN=\nORACLE_USER=scott\nPG_SCHEMA=<no value>\n"), 0644))
+	err := service.ValidateConfigFile(context.Background(), confPath, nil)
+	assert.Equal(t, "ORA2PG_CONFIG_INVALID", utils.GetErrorCode(err))
+	assert.Contains(t, err.Error(), "第3行: PG_SCHEMA")
+	assert.Contains(t, err.Error(), "ORACLE_DSN 为空")
+
+	// ora2pg不可用时跳过
+	require.NoError(t, os.WriteFile(confPath, []byte(validConf), 0644))
+	assert.NoError(t, service.ValidateConfigFile(context.Background(), confPath, nil))
+
+	// ora2pg报告配置错误
+	fatal := "#!/bin/sh\necho 'FATAL: Unknown export type: TABLES' >&2\nexit 1\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ora2pg"), []byte(fatal), 0755))
+	err = service.ValidateConfigFile(context.Background(), confPath, nil)
+	assert.Equal(t, "ORA2PG_CONFIG_INVALID", utils.GetErrorCode(err))
+	assert.Contains(t, err.Error(), "FATAL: Unknown export type: TABLES")
+
+	// 无法连接Oracle说明配置已被解析
+	connect := "#!/bin/sh\necho 'DBI connect failed: ORA-12541: TNS:no listener' >&2\nexit 1\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ora2pg"), []byte(connect), 0755))
+	assert.NoError(t, service.ValidateConfigFile(context.Background(), confPath, nil))
+
+	// 解析成功，环境变量传给ora2pg
+	ok := "#!/bin/sh\n[ \"$NLS_LANG\" = AMERICAN_AMERICA.UTF8 ] || exit 1\necho 'Oracle Database 19c Enterprise Edition'\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ora2pg"), []byte(ok), 0755))
+	assert.NoError(t, service.ValidateConfigFile(context.Background(), confPath, map[string]string{"NLS_LANG": "AMERICAN_AMERICA.UTF8"}))
+}
+
+func TestCommandTimeoutWarningLevels(t *testing.T) {
+	var nilTimeout *commandTimeout
+	assert.Nil(t, nilTimeout.Expired())
+	assert.Nil(t, nilTimeout.Warning())
+	nilTimeout.Stop()
+
+	timeout := newCommandTimeout(200 * time.Millisecond)
+	defer timeout.Stop()
+
+	// 80%、95% 两级预警依次触发，之后不再预警
+	for level := 0; level < len(timeoutWarningRatios); level++ {
+		select {
+		case <-timeout.Warning():
+			timeout.NextWarning()
+		case <-timeout.Expired():
+			t.Fatalf("第 %d 级预警之前已超时", level+1)
+		}
+	}
+	assert.Nil(t, timeout.Warning())
+	assert.GreaterOrEqual(t, timeout.Elapsed(), 190*time.Millisecond)
+
+	// 延长后按新的超时时间重新预警
+	timeout.Extend(time.Second)
+	assert.Equal(t, 1200*time.Millisecond, timeout.Timeout())
+	require.NotNil(t, timeout.Warning())
+	select {
+	case <-timeout.Expired():
+		t.Fatal("延长后不应立即超时")
+	case <-timeout.Warning():
+	}
+}
+
+func TestExecuteTimeoutWarningAndExtension(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟ora2pg依赖 /bin/sh")
+	}
+
+	bin := t.TempDir()
+	script := "#!/bin/sh\nsleep 1\necho done\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ora2pg"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	service := NewOra2pgService()
+
+	// 不延长时到超时被终止
+	result, err := service.Execute(context.Background(), MigrationTypeCopy, &ExecutionOptions{Timeout: 300 * time.Millisecond})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "命令执行超时")
+	assert.Equal(t, StatusFailed, result.Status)
+
+	// 第一次预警时延长，命令正常完成
+	var mu sync.Mutex
+	var warnings []time.Duration
+	options := &ExecutionOptions{
+		Timeout: 500 * time.Millisecond,
+		OnTimeoutWarning: func(migrationType MigrationType, elapsed, timeout time.Duration) time.Duration {
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Equal(t, MigrationTypeCopy, migrationType)
+			assert.GreaterOrEqual(t, elapsed, 400*time.Millisecond)
+			warnings = append(warnings, timeout)
+			if len(warnings) == 1 {
+				return 2 * time.Second
+			}
+			return 0
+		},
+	}
+	result, err = service.Execute(context.Background(), MigrationTypeCopy, options)
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, result.Status)
+	assert.Contains(t, result.Output, "done")
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.NotEmpty(t, warnings)
+	assert.Equal(t, 500*time.Millisecond, warnings[0])
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// ora2pgVersionTimeout 执行 ora2pg --version 的超时时间
+const ora2pgVersionTimeout = 10 * time.Second
+
+var (
+	// ora2pgVersionPattern ora2pg --version 的输出，如 "Ora2Pg v24.1"
+	ora2pgVersionPattern = regexp.MustCompile(`(?i)ora2pg\s+v?(\d+)(?:\.(\d+))?`)
+	// bareVersionPattern 只有版本号的输出，如 "v23.2"
+	bareVersionPattern = regexp.MustCompile(`\bv?(\d+)\.(\d+)\b`)
+)
+
+// Ora2pgVersion ora2pg版本号，零值表示版本未知
+type Ora2pgVersion struct {
+	Major int
+	Minor int
+}
+
+// ParseOra2pgVersion 从 ora2pg --version 的输出中解析版本号
+func ParseOra2pgVersion(output string) (Ora2pgVersion, bool) {
+	matches := ora2pgVersionPattern.FindStringSubmatch(output)
+	if matches == nil {
+		matches = bareVersionPattern.FindStringSubmatch(output)
+	}
+	if matches == nil {
+		return Ora2pgVersion{}, false
+	}
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+	if major == 0 {
+		return Ora2pgVersion{}, false
+	}
+	return Ora2pgVersion{Major: major, Minor: minor}, true
+}
+
+// Known 是否已识别版本
+func (v Ora2pgVersion) Known() bool {
+	return v.Major > 0
+}
+
+// Before 是否早于 other
+func (v Ora2pgVersion) Before(other Ora2pgVersion) bool {
+	if v.Major != other.Major {
+		return v.Major < other.Major
+	}
+	return v.Minor < other.Minor
+}
+
+// String 版本号文本，如 24.1
+func (v Ora2pgVersion) String() string {
+	if !v.Known() {
+		return "未知"
+	}
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// ora2pgDirectiveChange 配置指令的版本差异
+type ora2pgDirectiveChange struct {
+	// Name 新版本中的指令名
+	Name string
+	// Since 开始使用该名称的版本
+	Since Ora2pgVersion
+	// OldName 之前的版本使用的名称，为空表示之前的版本不支持该指令
+	OldName string
+}
+
+// ora2pgDirectiveChanges 生成的配置中会用到的指令的版本差异表
+var ora2pgDirectiveChanges = []ora2pgDirectiveChange{
+	{Name: "DISABLE_TRIGGERS", Since: Ora2pgVersion{10, 0}, OldName: "DISABLE_TABLE_TRIGGERS"},
+	{Name: "JOBS", Since: Ora2pgVersion{11, 0}},
+	{Name: "ORACLE_COPIES", Since: Ora2pgVersion{11, 0}},
+	{Name: "DEFINED_PK", Since: Ora2pgVersion{11, 0}},
+	{Name: "PARALLEL_TABLES", Since: Ora2pgVersion{13, 0}},
+	{Name: "USE_RESERVED_WORDS", Since: Ora2pgVersion{14, 0}},
+	{Name: "PRESERVE_CASE", Since: Ora2pgVersion{15, 0}, OldName: "CASE_SENSITIVE"},
+	{Name: "NO_LOB_LOCATOR", Since: Ora2pgVersion{16, 0}},
+	{Name: "FILE_PER_FKEYS", Since: Ora2pgVersion{17, 0}},
+	{Name: "DISABLE_UNLOGGED", Since: Ora2pgVersion{18, 0}},
+	{Name: "PG_INITIAL_COMMAND", Since: Ora2pgVersion{19, 0}},
+}
+
+// ora2pgFlagChange 命令行参数的版本差异
+type ora2pgFlagChange struct {
+	Flag  string
+	Since Ora2pgVersion
+}
+
+// ora2pgFlagChanges 命令行参数及其引入的版本，之前的版本不传该参数
+var ora2pgFlagChanges = []ora2pgFlagChange{
+	{Flag: "-l", Since: Ora2pgVersion{10, 0}},
+}
+
+// Ora2pgCompat 按ora2pg版本调整命令行参数和配置指令
+//
+// 版本未知时按最新版本处理，不做任何调整。
+type Ora2pgCompat struct {
+	version Ora2pgVersion
+}
+
+// NewOra2pgCompat 创建指定版本的兼容性适配
+func NewOra2pgCompat(version Ora2pgVersion) *Ora2pgCompat {
+	return &Ora2pgCompat{version: version}
+}
+
+// Version 适配的ora2pg版本
+func (c *Ora2pgCompat) Version() Ora2pgVersion {
+	return c.version
+}
+
+// Directive 当前版本中指令使用的名称，当前版本不支持该指令时返回 false
+func (c *Ora2pgCompat) Directive(name string) (string, bool) {
+	name = strings.ToUpper(name)
+	change, ok := c.directiveChange(name)
+	if !ok {
+		return name, true
+	}
+	if change.OldName == "" {
+		return "", false
+	}
+	return change.OldName, true
+}
+
+// directiveChange 当前版本需要调整的指令
+func (c *Ora2pgCompat) directiveChange(name string) (ora2pgDirectiveChange, bool) {
+	if !c.version.Known() {
+		return ora2pgDirectiveChange{}, false
+	}
+	for _, change := range ora2pgDirectiveChanges {
+		if change.Name == name && c.version.Before(change.Since) {
+			return change, true
+		}
+	}
+	return ora2pgDirectiveChange{}, false
+}
+
+// SupportsFlag 当前版本是否支持命令行参数
+func (c *Ora2pgCompat) SupportsFlag(flag string) bool {
+	if !c.version.Known() {
+		return true
+	}
+	for _, change := range ora2pgFlagChanges {
+		if change.Flag == flag && c.version.Before(change.Since) {
+			return false
+		}
+	}
+	return true
+}
+
+// AdaptConf 调整配置文件内容：改用旧版本的指令名，注释掉当前版本不支持的指令，返回调整后的内容和调整说明
+func (c *Ora2pgCompat) AdaptConf(content []byte) ([]byte, []string) {
+	if !c.version.Known() {
+		return content, nil
+	}
+
+	lines := strings.Split(string(content), "\n")
+	var notes []string
+	noted := make(map[string]bool)
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		name := trimmed
+		if end := strings.IndexAny(trimmed, "= \t"); end >= 0 {
+			name = trimmed[:end]
+		}
+		change, ok := c.directiveChange(strings.ToUpper(name))
+		if !ok {
+			continue
+		}
+
+		var note string
+		if change.OldName != "" {
+			start := strings.Index(line, name)
+			lines[i] = line[:start] + change.OldName + line[start+len(name):]
+			note = fmt.Sprintf("%s 改用旧名称 %s", change.Name, change.OldName)
+		} else {
+			lines[i] = fmt.Sprintf("# ora2pg %s 不支持该指令（%s 起支持）: %s", c.version, change.Since, trimmed)
+			note = fmt.Sprintf("注释掉不支持的 %s（%s 起支持）", change.Name, change.Since)
+		}
+		if !noted[note] {
+			noted[note] = true
+			notes = append(notes, note)
+		}
+	}
+	return []byte(strings.Join(lines, "\n")), notes
+}
+
+// Adjustments 当前版本与最新版本的全部差异，用于环境检查展示
+func (c *Ora2pgCompat) Adjustments() []string {
+	if !c.version.Known() {
+		return nil
+	}
+	var adjustments []string
+	for _, change := range ora2pgDirectiveChanges {
+		if !c.version.Before(change.Since) {
+			continue
+		}
+		if change.OldName != "" {
+			adjustments = append(adjustments, fmt.Sprintf("%s → %s", change.Name, change.OldName))
+		} else {
+			adjustments = append(adjustments, fmt.Sprintf("不支持 %s", change.Name))
+		}
+	}
+	for _, change := range ora2pgFlagChanges {
+		if c.version.Before(change.Since) {
+			adjustments = append(adjustments, fmt.Sprintf("不支持命令行参数 %s", change.Flag))
+		}
+	}
+	return adjustments
+}
+
+// DetectOra2pgVersion 执行 ora2pg --version，返回输出的版本行和解析出的版本号
+func DetectOra2pgVersion(ctx context.Context, env map[string]string) (string, Ora2pgVersion, error) {
+	ctx, cancel := context.WithTimeout(ctx, ora2pgVersionTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "ora2pg", "--version")
+	cmd.Env = os.Environ()
+	for key, value := range env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+	}
+	output, err := cmd.Output()
+	if err != nil {
+		return "", Ora2pgVersion{}, utils.NewError(utils.ErrorTypeSystem, "ORA2PG_VERSION_UNKNOWN").
+			Message("无法获取ora2pg版本").
+			Cause(err).
+			Build()
+	}
+
+	raw := strings.TrimSpace(string(output))
+	for _, line := range strings.Split(raw, "\n") {
+		if line = strings.TrimSpace(line); strings.Contains(strings.ToLower(line), "ora2pg") {
+			raw = line
+			break
+		}
+	}
+	version, ok := ParseOra2pgVersion(raw)
+	if !ok {
+		return raw, Ora2pgVersion{}, utils.NewError(utils.ErrorTypeSystem, "ORA2PG_VERSION_UNKNOWN").
+			Message("无法识别ora2pg版本").
+			Details(raw).
+			Build()
+	}
+	return raw, version, nil
+}
+
+// SetVersion 设置ora2pg版本，之后生成的命令参数和配置文件按该版本适配
+func (s *Ora2pgService) SetVersion(version Ora2pgVersion) {
+	s.version = version
+	s.versionDetected = true
+}
+
+// Compat 当前ora2pg版本的兼容性适配
+func (s *Ora2pgService) Compat() *Ora2pgCompat {
+	return NewOra2pgCompat(s.version)
+}
+
+// ensureVersion 首次使用时检测ora2pg版本，无法识别时按最新版本处理
+func (s *Ora2pgService) ensureVersion(ctx context.Context, env map[string]string) {
+	if s.versionDetected {
+		return
+	}
+	s.versionDetected = true
+	if _, err := exec.LookPath("ora2pg"); err != nil {
+		return
+	}
+	raw, version, err := DetectOra2pgVersion(ctx, env)
+	if err != nil {
+		s.logger.Warnf("无法识别ora2pg版本，按最新版本生成参数和配置: %v", err)
+		return
+	}
+	s.version = version
+	s.logger.Infof("ora2pg版本: %s", raw)
+	if adjustments := s.Compat().Adjustments(); len(adjustments) > 0 {
+		s.logger.Warnf("ora2pg %s 与最新版本存在差异，将自动适配: %s", version, strings.Join(adjustments, "；"))
+	}
+}
+
+// adaptConfigFile 按ora2pg版本调整生成的配置文件
+func (s *Ora2pgService) adaptConfigFile(path string) error {
+	compat := s.Compat()
+	if !compat.Version().Known() {
+		return nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return utils.FileErrors.ReadFailed(path, err)
+	}
+	adapted, notes := compat.AdaptConf(content)
+	if len(notes) == 0 {
+		return nil
+	}
+	if err := os.WriteFile(path, adapted, 0644); err != nil {
+		return utils.FileErrors.WriteFailed(path, err)
+	}
+	for _, note := range notes {
+		s.logger.Warnf("ora2pg %s 兼容: %s", compat.Version(), note)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+func TestParseOra2pgVersion(t *testing.T) {
+	cases := map[string]Ora2pgVersion{
+		"Ora2Pg v24.1":            {24, 1},
+		"ora2pg 15.3\n":           {15, 3},
+		"Ora2Pg v8":               {8, 0},
+		"version: v23.2 (perl 5)": {23, 2},
+	}
+	for output, expected := range cases {
+		version, ok := ParseOra2pgVersion(output)
+		assert.True(t, ok, output)
+		assert.Equal(t, expected, version, output)
+	}
+
+	_, ok := ParseOra2pgVersion("command not found")
+	assert.False(t, ok)
+	assert.Equal(t, "未知", Ora2pgVersion{}.String())
+	assert.True(t, Ora2pgVersion{14, 9}.Before(Ora2pgVersion{15, 0}))
+	assert.False(t, Ora2pgVersion{15, 0}.Before(Ora2pgVersion{15, 0}))
+}
+
+func TestOra2pgCompatDirectives(t *testing.T) {
+	old := NewOra2pgCompat(Ora2pgVersion{14, 2})
+	name, ok := old.Directive("preserve_case")
+	assert.True(t, ok)
+	assert.Equal(t, "CASE_SENSITIVE", name)
+	_, ok = old.Directive("NO_LOB_LOCATOR")
+	assert.False(t, ok)
+	name, ok = old.Directive("PARALLEL_TABLES")
+	assert.True(t, ok)
+	assert.Equal(t, "PARALLEL_TABLES", name)
+
+	// 版本未知时按最新版本处理
+	name, ok = NewOra2pgCompat(Ora2pgVersion{}).Directive("PRESERVE_CASE")
+	assert.True(t, ok)
+	assert.Equal(t, "PRESERVE_CASE", name)
+	assert.Empty(t, NewOra2pgCompat(Ora2pgVersion{}).Adjustments())
+	assert.Empty(t, NewOra2pgCompat(Ora2pgVersion{24, 1}).Adjustments())
+	assert.Equal(t, []string{"不支持 FILE_PER_FKEYS", "不支持 DISABLE_UNLOGGED", "不支持 PG_INITIAL_COMMAND"},
+		NewOra2pgCompat(Ora2pgVersion{16, 1}).Adjustments())
+
+	content := strings.Join([]string{
+		"# PRESERVE_CASE 说明",
+		"PRESERVE_CASE=1",
+		"PARALLEL_TABLES=4",
+		"NO_LOB_LOCATOR=0",
+		"PG_INITIAL_COMMAND SET TIME ZONE 'UTC'",
+		"JOBS=8",
+		"",
+	}, "\n")
+	adapted, notes := old.AdaptConf([]byte(content))
+	assert.Equal(t, strings.Join([]string{
+		"# PRESERVE_CASE 说明",
+		"CASE_SENSITIVE=1",
+		"PARALLEL_TABLES=4",
+		"# ora2pg 14.2 不支持该指令（16.0 起支持）: NO_LOB_LOCATOR=0",
+		"# ora2pg 14.2 不支持该指令（19.0 起支持）: PG_INITIAL_COMMAND SET TIME ZONE 'UTC'",
+		"JOBS=8",
+		"",
+	}, "\n"), string(adapted))
+	assert.Len(t, notes, 3)
+
+	same, notes := NewOra2pgCompat(Ora2pgVersion{24, 1}).AdaptConf([]byte(content))
+	assert.Equal(t, content, string(same))
+	assert.Empty(t, notes)
+}
+
+func TestBuildCommandArgsByVersion(t *testing.T) {
+	options := &ExecutionOptions{OutputDir: "output", LogFile: "ora2pg.log"}
+
+	service := NewOra2pgService()
+	service.SetVersion(Ora2pgVersion{24, 1})
+	args, err := service.buildCommandArgs(MigrationTypeCopy, options)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ora2pg", "-t", "COPY", "-o", "output", "-l", "ora2pg.log"}, args)
+
+	// 旧版本不支持 -l
+	service.SetVersion(Ora2pgVersion{9, 5})
+	args, err = service.buildCommandArgs(MigrationTypeCopy, options)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ora2pg", "-t", "COPY", "-o", "output"}, args)
+}
+
+func TestGenerateConfigFileByVersion(t *testing.T) {
+	templates, err := filepath.Abs(filepath.Join("..", "..", "templates"))
+	require.NoError(t, err)
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.Symlink(templates, "templates"))
+
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("版本适配")
+	cfg := manager.GetConfig()
+	cfg.Migration.Options = map[string]bool{"PRESERVE_CASE": true}
+	cfg.Migration.TimeZone = "UTC"
+
+	generate := func(version Ora2pgVersion) string {
+		service := NewOra2pgService()
+		service.SetVersion(version)
+		path := filepath.Join("output", "ora2pg-"+version.String()+".conf")
+		require.NoError(t, service.GenerateConfigFile(cfg, path, false))
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	latest := generate(Ora2pgVersion{24, 1})
+	assert.Contains(t, latest, "\nPRESERVE_CASE=1\n")
+	assert.Contains(t, latest, "\nPG_INITIAL_COMMAND ")
+
+	old := generate(Ora2pgVersion{14, 0})
+	assert.Contains(t, old, "\nCASE_SENSITIVE=1\n")
+	assert.NotContains(t, old, "\nPRESERVE_CASE=")
+	assert.NotContains(t, old, "\nPG_INITIAL_COMMAND ")
+	assert.Contains(t, old, "# ora2pg 14.0 不支持该指令（19.0 起支持）: PG_INITIAL_COMMAND ")
+	assert.Contains(t, old, "\nORA_INITIAL_COMMAND ")
+}
+
+func TestDetectOra2pgVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟ora2pg依赖 /bin/sh")
+	}
+
+	bin := t.TempDir()
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	write := func(script string) {
+		require.NoError(t, os.WriteFile(filepath.Join(bin, "ora2pg"), []byte("#!/bin/sh\n"+script), 0755))
+	}
+
+	write("echo 'Ora2Pg v15.3'\n")
+	raw, version, err := DetectOra2pgVersion(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Ora2Pg v15.3", raw)
+	assert.Equal(t, Ora2pgVersion{15, 3}, version)
+
+	write("echo 'unexpected'\n")
+	_, _, err = DetectOra2pgVersion(context.Background(), nil)
+	assert.Equal(t, "ORA2PG_VERSION_UNKNOWN", utils.GetErrorCode(err))
+
+	// 首次生成配置时检测一次版本，之后复用
+	write("echo 'Ora2Pg v12.0'\n")
+	service := NewOra2pgService()
+	service.ensureVersion(context.Background(), nil)
+	assert.Equal(t, Ora2pgVersion{12, 0}, service.Compat().Version())
+	write("echo 'Ora2Pg v24.1'\n")
+	service.ensureVersion(context.Background(), nil)
+	assert.Equal(t, Ora2pgVersion{12, 0}, service.Compat().Version())
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// migrationTypeRank 建议的执行顺序，数值越小越先执行；
+// 物化视图创建时即执行查询填充数据，放在数据迁移之后、基表建索引之前；
+// 数据库链接转换的外部服务器不依赖其他对象，最先创建；同义词转换的视图在所有被引用的对象之后创建
+var migrationTypeRank = map[MigrationType]int{
+	MigrationTypeDBLink:    0,
+	MigrationTypeType:      1,
+	MigrationTypeTable:     2,
+	MigrationTypeView:      3,
+	MigrationTypeSequence:  4,
+	MigrationTypeCopy:      5,
+	MigrationTypeInsert:    6,
+	MigrationTypeMView:     7,
+	MigrationTypeIndex:     8,
+	MigrationTypeTrigger:   9,
+	MigrationTypeFunction:  10,
+	MigrationTypeProcedure: 11,
+	MigrationTypePackage:   12,
+	MigrationTypeSynonym:   13,
+	MigrationTypeGrant:     14,
+}
+
+// migrationTypeDependencies 类型执行前必须已完成的类型（仅在同时执行时约束）
+var migrationTypeDependencies = map[MigrationType][]MigrationType{
+	MigrationTypeTable:     {MigrationTypeType},
+	MigrationTypeView:      {MigrationTypeTable},
+	MigrationTypeMView:     {MigrationTypeTable, MigrationTypeView},
+	MigrationTypeCopy:      {MigrationTypeTable},
+	MigrationTypeInsert:    {MigrationTypeTable},
+	MigrationTypeIndex:     {MigrationTypeTable},
+	MigrationTypeTrigger:   {MigrationTypeTable},
+	MigrationTypeFunction:  {MigrationTypeType},
+	MigrationTypeProcedure: {MigrationTypeType},
+	MigrationTypePackage:   {MigrationTypeType},
+	MigrationTypeSynonym:   {MigrationTypeTable, MigrationTypeView, MigrationTypeMView},
+	MigrationTypeGrant: {
+		MigrationTypeTable, MigrationTypeView, MigrationTypeMView, MigrationTypeSequence,
+		MigrationTypeFunction, MigrationTypeProcedure, MigrationTypePackage, MigrationTypeSynonym,
+	},
+}
+
+// OrderMigrationTypes 返回建议的执行顺序，未知类型保持原有相对顺序排在最后
+func OrderMigrationTypes(types []MigrationType) []MigrationType {
+	ordered := make([]MigrationType, len(types))
+	copy(ordered, types)
+
+	rank := func(t MigrationType) int {
+		if r, exists := migrationTypeRank[t]; exists {
+			return r
+		}
+		return len(migrationTypeRank)
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return rank(ordered[i]) < rank(ordered[j])
+	})
+	return ordered
+}
+
+// MigrationTypeDependencies 获取类型依赖的前置类型
+func MigrationTypeDependencies(migrationType MigrationType) []MigrationType {
+	return migrationTypeDependencies[migrationType]
+}
+
+// ValidateMigrationOrder 校验执行顺序是否满足依赖关系，前置类型未参与执行时不做要求
+func ValidateMigrationOrder(types []MigrationType) error {
+	position := make(map[MigrationType]int, len(types))
+	for i, t := range types {
+		if _, exists := position[t]; exists {
+			return utils.NewError(utils.ErrorTypeValidation, "INVALID_MIGRATION_ORDER").
+				Message("执行顺序中存在重复的迁移类型").
+				Details(string(t)).
+				Build()
+		}
+		position[t] = i
+	}
+
+	var violations []string
+	for i, t := range types {
+		for _, dependency := range migrationTypeDependencies[t] {
+			if pos, exists := position[dependency]; exists && pos > i {
+				violations = append(violations, fmt.Sprintf("%s 必须在 %s 之后执行", t, dependency))
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return utils.NewError(utils.ErrorTypeValidation, "INVALID_MIGRATION_ORDER").
+			Message("执行顺序不满足依赖关系").
+			Details(strings.Join(violations, "; ")).
+			Suggestion(fmt.Sprintf("建议顺序: %s", FormatMigrationOrder(OrderMigrationTypes(types)))).
+			Build()
+	}
+	return nil
+}
+
+// ParseMigrationOrder 解析逗号分隔的类型列表，如 "TABLE,COPY,INDEX"
+func ParseMigrationOrder(value string) ([]MigrationType, error) {
+	var types []MigrationType
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToUpper(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		types = append(types, MigrationType(item))
+	}
+	if len(types) == 0 {
+		return nil, utils.ValidationErrors.Required("执行顺序")
+	}
+	return types, nil
+}
+
+// ApplyMigrationOrder 用手动顺序覆盖建议顺序，手动顺序只能包含候选类型且需满足依赖关系
+func ApplyMigrationOrder(candidates, order []MigrationType) ([]MigrationType, error) {
+	allowed := make(map[MigrationType]bool, len(candidates))
+	for _, t := range candidates {
+		allowed[t] = true
+	}
+	for _, t := range order {
+		if !allowed[t] {
+			return nil, utils.NewError(utils.ErrorTypeValidation, "INVALID_MIGRATION_ORDER").
+				Message("执行顺序包含当前任务不支持的迁移类型").
+				Details(string(t)).
+				Suggestion(fmt.Sprintf("可用类型: %s", FormatMigrationOrder(candidates))).
+				Build()
+		}
+	}
+	if err := ValidateMigrationOrder(order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// FormatMigrationOrder 格式化为逗号分隔的类型列表，可直接用于 --order 参数
+func FormatMigrationOrder(types []MigrationType) string {
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = string(t)
+	}
+	return strings.Join(names, ",")
+}
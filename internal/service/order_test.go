@@ -0,0 +1,69 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/utils"
+)
+
+func TestOrderMigrationTypes(t *testing.T) {
+	ordered := OrderMigrationTypes([]MigrationType{
+		MigrationTypeGrant, MigrationTypeCopy, "FDW", MigrationTypeIndex, MigrationTypeTable, MigrationTypeType,
+	})
+	assert.Equal(t, []MigrationType{
+		MigrationTypeType, MigrationTypeTable, MigrationTypeCopy, MigrationTypeIndex, MigrationTypeGrant, "FDW",
+	}, ordered)
+
+	// 建议顺序总是满足依赖关系
+	assert.NoError(t, ValidateMigrationOrder(OrderMigrationTypes(NewOra2pgService().GetSupportedTypes())))
+}
+
+func TestValidateMigrationOrder(t *testing.T) {
+	// 前置类型未参与执行时不做要求
+	assert.NoError(t, ValidateMigrationOrder([]MigrationType{MigrationTypeCopy, MigrationTypeIndex}))
+	assert.NoError(t, ValidateMigrationOrder([]MigrationType{MigrationTypeTable, MigrationTypeSequence, MigrationTypeCopy}))
+
+	err := ValidateMigrationOrder([]MigrationType{MigrationTypeCopy, MigrationTypeTable})
+	require.Error(t, err)
+	assert.Equal(t, "INVALID_MIGRATION_ORDER", utils.GetErrorCode(err))
+	assert.Contains(t, utils.FormatError(err), "COPY 必须在 TABLE 之后执行")
+	assert.Contains(t, utils.FormatError(err), "TABLE,COPY")
+
+	err = ValidateMigrationOrder([]MigrationType{MigrationTypeTable, MigrationTypeTable})
+	assert.Error(t, err)
+
+	// 物化视图依赖基表和视图，建议在数据迁移之后创建
+	err = ValidateMigrationOrder([]MigrationType{MigrationTypeTable, MigrationTypeMView, MigrationTypeView})
+	assert.Contains(t, utils.FormatError(err), "MVIEW 必须在 VIEW 之后执行")
+	assert.Equal(t, []MigrationType{MigrationTypeTable, MigrationTypeCopy, MigrationTypeMView, MigrationTypeIndex},
+		OrderMigrationTypes([]MigrationType{MigrationTypeIndex, MigrationTypeMView, MigrationTypeCopy, MigrationTypeTable}))
+
+	// 外部服务器最先创建，同义词转换的视图在被引用的对象之后、授权之前
+	assert.Equal(t, []MigrationType{MigrationTypeDBLink, MigrationTypeTable, MigrationTypePackage, MigrationTypeSynonym, MigrationTypeGrant},
+		OrderMigrationTypes([]MigrationType{MigrationTypeGrant, MigrationTypeSynonym, MigrationTypeTable, MigrationTypePackage, MigrationTypeDBLink}))
+	err = ValidateMigrationOrder([]MigrationType{MigrationTypeSynonym, MigrationTypeView})
+	assert.Contains(t, utils.FormatError(err), "SYNONYM 必须在 VIEW 之后执行")
+}
+
+func TestApplyMigrationOrder(t *testing.T) {
+	candidates := []MigrationType{MigrationTypeTable, MigrationTypeView, MigrationTypeSequence, MigrationTypeCopy}
+
+	order, err := ParseMigrationOrder(" table, sequence ,COPY,, view")
+	require.NoError(t, err)
+	result, err := ApplyMigrationOrder(candidates, order)
+	require.NoError(t, err)
+	assert.Equal(t, "TABLE,SEQUENCE,COPY,VIEW", FormatMigrationOrder(result))
+
+	// 不属于当前任务的类型
+	_, err = ApplyMigrationOrder(candidates, []MigrationType{MigrationTypeTable, MigrationTypeGrant})
+	assert.Error(t, err)
+
+	// 违反依赖关系
+	_, err = ApplyMigrationOrder(candidates, []MigrationType{MigrationTypeCopy, MigrationTypeTable})
+	assert.Error(t, err)
+
+	_, err = ParseMigrationOrder(" , ")
+	assert.Error(t, err)
+}
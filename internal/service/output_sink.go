@@ -0,0 +1,261 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// 输出流名称
+const (
+	StreamStdout = "stdout"
+	StreamStderr = "stderr"
+)
+
+// defaultSinkBufferSize 每个sink的默认缓冲行数
+const defaultSinkBufferSize = 1024
+
+// OutputLine ora2pg输出的一行
+type OutputLine struct {
+	MigrationType MigrationType `json:"migration_type"`
+	Stream        string        `json:"stream"`
+	Line          string        `json:"line"`
+	Time          time.Time     `json:"time"`
+}
+
+// OutputSink ora2pg输出转发目标（syslog、HTTP、文件等）
+//
+// Write 在独立的goroutine中被顺序调用，实现无需考虑并发；
+// 返回的错误只会被记录，不会影响迁移。sink 可在多次执行间复用，
+// 由注册方在全部迁移结束后调用 Close。
+type OutputSink interface {
+	Name() string
+	Write(line OutputLine) error
+	Close() error
+}
+
+// sinkDispatcher 将输出行异步分发给各个sink
+type sinkDispatcher struct {
+	logger *utils.Logger
+	queues []*sinkQueue
+	wg     sync.WaitGroup
+}
+
+// sinkQueue 单个sink的缓冲队列
+type sinkQueue struct {
+	sink    OutputSink
+	lines   chan OutputLine
+	mu      sync.Mutex
+	dropped int
+	failed  int
+}
+
+// newSinkDispatcher 创建分发器，没有sink时返回nil
+func newSinkDispatcher(sinks []OutputSink, logger *utils.Logger) *sinkDispatcher {
+	if len(sinks) == 0 {
+		return nil
+	}
+
+	d := &sinkDispatcher{logger: logger}
+	for _, sink := range sinks {
+		if sink == nil {
+			continue
+		}
+		q := &sinkQueue{
+			sink:  sink,
+			lines: make(chan OutputLine, defaultSinkBufferSize),
+		}
+		d.queues = append(d.queues, q)
+		d.wg.Add(1)
+		go d.run(q)
+	}
+	return d
+}
+
+// run 消费队列并写入sink
+func (d *sinkDispatcher) run(q *sinkQueue) {
+	defer d.wg.Done()
+	for line := range q.lines {
+		if err := q.sink.Write(line); err != nil {
+			q.mu.Lock()
+			q.failed++
+			first := q.failed == 1
+			q.mu.Unlock()
+			// 只记录首次失败，避免日志刷屏
+			if first {
+				d.logger.Warnf("输出转发 %s 写入失败: %v", q.sink.Name(), err)
+			}
+		}
+	}
+}
+
+// Dispatch 分发一行输出，缓冲已满时丢弃而不阻塞读取
+func (d *sinkDispatcher) Dispatch(line OutputLine) {
+	if d == nil {
+		return
+	}
+	for _, q := range d.queues {
+		select {
+		case q.lines <- line:
+		default:
+			q.mu.Lock()
+			q.dropped++
+			q.mu.Unlock()
+		}
+	}
+}
+
+// Close 等待缓冲的输出发送完毕
+func (d *sinkDispatcher) Close() {
+	if d == nil {
+		return
+	}
+	for _, q := range d.queues {
+		close(q.lines)
+	}
+	d.wg.Wait()
+
+	for _, q := range d.queues {
+		if q.dropped > 0 || q.failed > 0 {
+			d.logger.Warnf("输出转发 %s: 丢弃 %d 行，写入失败 %d 行", q.sink.Name(), q.dropped, q.failed)
+		}
+	}
+}
+
+// syslog facility 和 severity（RFC 3164）
+const (
+	syslogFacilityLocal0 = 16
+	syslogSeverityError  = 3
+	syslogSeverityInfo   = 6
+)
+
+// SyslogSink 将输出以RFC 3164格式发送到syslog服务器
+//
+// 直接通过网络连接发送，不依赖 log/syslog，因此在Windows上同样可用。
+type SyslogSink struct {
+	network  string
+	address  string
+	tag      string
+	hostname string
+	conn     net.Conn
+}
+
+// NewSyslogSink 创建syslog sink，network 为 udp、tcp 或 unix
+func NewSyslogSink(network, address, tag string) (*SyslogSink, error) {
+	switch network {
+	case "udp", "tcp", "unix", "unixgram":
+	default:
+		return nil, utils.ConfigErrors.InvalidValue("syslog network", network)
+	}
+	if tag == "" {
+		tag = "ora2pg-admin"
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+
+	sink := &SyslogSink{
+		network:  network,
+		address:  address,
+		tag:      tag,
+		hostname: hostname,
+	}
+	if err := sink.connect(); err != nil {
+		return nil, utils.NewError(utils.ErrorTypeConnection, "SYSLOG_CONNECT_FAILED").
+			Message("连接syslog服务器失败").
+			Details(fmt.Sprintf("%s://%s", network, address)).
+			Cause(err).
+			Suggestion("检查syslog服务器地址和端口是否正确").
+			Build()
+	}
+	return sink, nil
+}
+
+// ParseSyslogTarget 解析 "udp://host:514" 形式的syslog地址
+func ParseSyslogTarget(target string) (network, address string, err error) {
+	parts := strings.SplitN(target, "://", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", utils.ConfigErrors.InvalidValue("syslog", target)
+	}
+	return parts[0], parts[1], nil
+}
+
+// Name 返回sink名称
+func (s *SyslogSink) Name() string {
+	return fmt.Sprintf("syslog(%s://%s)", s.network, s.address)
+}
+
+// connect 建立连接
+func (s *SyslogSink) connect() error {
+	conn, err := net.DialTimeout(s.network, s.address, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	return nil
+}
+
+// Write 发送一行输出，连接断开时重连一次
+func (s *SyslogSink) Write(line OutputLine) error {
+	msg := s.format(line)
+
+	if s.conn == nil {
+		if err := s.connect(); err != nil {
+			return err
+		}
+	}
+	if _, err := s.conn.Write([]byte(msg)); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		if err := s.connect(); err != nil {
+			return err
+		}
+		_, err = s.conn.Write([]byte(msg))
+		return err
+	}
+	return nil
+}
+
+// format 按RFC 3164格式化消息
+func (s *SyslogSink) format(line OutputLine) string {
+	severity := syslogSeverityInfo
+	if line.Stream == StreamStderr {
+		severity = syslogSeverityError
+	}
+	priority := syslogFacilityLocal0*8 + severity
+
+	timestamp := line.Time
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	content := line.Line
+	if line.MigrationType != "" {
+		content = fmt.Sprintf("[%s] %s", line.MigrationType, content)
+	}
+
+	msg := fmt.Sprintf("<%d>%s %s %s[%d]: %s",
+		priority, timestamp.Format(time.Stamp), s.hostname, s.tag, os.Getpid(), content)
+	// 流式传输需要换行分帧
+	if s.network == "tcp" || s.network == "unix" {
+		msg += "\n"
+	}
+	return msg
+}
+
+// Close 关闭连接
+func (s *SyslogSink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// sqlFileStamp SQL文件的修改时间和大小，用于识别本次新生成的文件
+type sqlFileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// compiledReplacement 已编译的替换规则
+type compiledReplacement struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// SQLPostProcessor 对ora2pg生成的SQL进行后处理（正则替换和自定义脚本）
+type SQLPostProcessor struct {
+	replacements []compiledReplacement
+	script       string
+	backupDir    string
+	logger       *utils.Logger
+	fileUtils    *utils.FileUtils
+}
+
+// NewSQLPostProcessor 创建SQL后处理器，正则表达式无效时返回错误
+func NewSQLPostProcessor(cfg *config.MigrationConfig, backupDir string) (*SQLPostProcessor, error) {
+	p := &SQLPostProcessor{
+		script:    strings.TrimSpace(cfg.PostProcessScript),
+		backupDir: backupDir,
+		logger:    utils.GetGlobalLogger(),
+		fileUtils: utils.NewFileUtils(),
+	}
+
+	for i, r := range cfg.SQLReplacements {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil || r.Pattern == "" {
+			return nil, utils.NewError(utils.ErrorTypeConfig, "INVALID_SQL_REPLACEMENT").
+				Message(fmt.Sprintf("第 %d 条SQL替换规则无效", i+1)).
+				Details(r.Pattern).
+				Cause(err).
+				Suggestion("检查 migration.sql_replacements 中的正则表达式语法").
+				Build()
+		}
+		p.replacements = append(p.replacements, compiledReplacement{pattern: re, replacement: r.Replacement})
+	}
+
+	return p, nil
+}
+
+// Enabled 是否配置了后处理
+func (p *SQLPostProcessor) Enabled() bool {
+	return p != nil && (len(p.replacements) > 0 || p.script != "")
+}
+
+// snapshotSQLFiles 记录目录下SQL文件的当前状态
+func snapshotSQLFiles(dir string) map[string]sqlFileStamp {
+	stamps := make(map[string]sqlFileStamp)
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.EqualFold(filepath.Ext(path), ".sql") {
+			return nil
+		}
+		stamps[path] = sqlFileStamp{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return stamps
+}
+
+// changedSQLFiles 返回与快照相比新增或被修改的SQL文件
+func changedSQLFiles(dir string, before map[string]sqlFileStamp) []string {
+	var files []string
+	for path, stamp := range snapshotSQLFiles(dir) {
+		if old, exists := before[path]; !exists || !old.modTime.Equal(stamp.modTime) || old.size != stamp.size {
+			files = append(files, path)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Process 对指定SQL文件执行后处理
+//
+// 处理前先把原始文件备份到 backupDir/postprocess/<类型>-<时间>/，
+// 任一步骤失败时从备份恢复，避免留下处理了一半的文件。
+func (p *SQLPostProcessor) Process(ctx context.Context, migrationType MigrationType, files []string) error {
+	if !p.Enabled() || len(files) == 0 {
+		return nil
+	}
+
+	backupDir := filepath.Join(p.backupDir, "postprocess",
+		fmt.Sprintf("%s-%s", migrationType, time.Now().Format("20060102-150405")))
+	if err := p.fileUtils.EnsureDir(backupDir); err != nil {
+		return utils.FileErrors.CreateFailed(backupDir, err)
+	}
+
+	backups := make(map[string]string, len(files))
+	for i, file := range files {
+		// 加序号避免不同子目录下的同名文件互相覆盖
+		backup := filepath.Join(backupDir, fmt.Sprintf("%03d_%s", i+1, filepath.Base(file)))
+		if err := p.fileUtils.CopyFile(file, backup); err != nil {
+			return utils.FileErrors.CreateFailed(backup, err)
+		}
+		backups[file] = backup
+	}
+	p.logger.Infof("已备份 %d 个SQL文件到: %s", len(files), backupDir)
+
+	if err := p.apply(ctx, migrationType, files); err != nil {
+		for file, backup := range backups {
+			if restoreErr := p.fileUtils.CopyFile(backup, file); restoreErr != nil {
+				p.logger.Errorf("恢复原始文件失败 %s: %v", file, restoreErr)
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// apply 依次执行正则替换和后处理脚本
+func (p *SQLPostProcessor) apply(ctx context.Context, migrationType MigrationType, files []string) error {
+	if len(p.replacements) > 0 {
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return utils.FileErrors.ReadFailed(file, err)
+			}
+
+			content := string(data)
+			for _, r := range p.replacements {
+				content = r.pattern.ReplaceAllString(content, r.replacement)
+			}
+			if content == string(data) {
+				continue
+			}
+
+			if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+				return utils.FileErrors.WriteFailed(file, err)
+			}
+			p.logger.Debugf("已应用SQL替换规则: %s", file)
+		}
+	}
+
+	if p.script != "" {
+		cmd := exec.CommandContext(ctx, p.script, files...)
+		cmd.Env = append(os.Environ(), fmt.Sprintf("ORA2PG_ADMIN_TYPE=%s", migrationType))
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return utils.NewError(utils.ErrorTypeMigration, "POST_PROCESS_FAILED").
+				Message(fmt.Sprintf("SQL后处理脚本执行失败: %s", p.script)).
+				Details(strings.TrimSpace(string(output))).
+				Cause(err).
+				Suggestion("检查脚本是否可执行，原始SQL文件已恢复").
+				Build()
+		}
+		p.logger.Infof("SQL后处理脚本执行完成: %s", p.script)
+	}
+
+	return nil
+}
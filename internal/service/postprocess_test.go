@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+)
+
+func TestSQLPostProcessorReplacements(t *testing.T) {
+	tempDir := t.TempDir()
+	outputDir := filepath.Join(tempDir, "output")
+	require.NoError(t, os.MkdirAll(outputDir, 0755))
+
+	oldFile := filepath.Join(outputDir, "old.sql")
+	require.NoError(t, os.WriteFile(oldFile, []byte("CREATE TABLE old (id int);\n"), 0644))
+	before := snapshotSQLFiles(outputDir)
+
+	newFile := filepath.Join(outputDir, "TABLE_output.sql")
+	original := "CREATE TABLE users (id int);\nALTER TABLE users OWNER TO scott;\n"
+	require.NoError(t, os.WriteFile(newFile, []byte(original), 0644))
+
+	files := changedSQLFiles(outputDir, before)
+	assert.Equal(t, []string{newFile}, files)
+
+	processor, err := NewSQLPostProcessor(&config.MigrationConfig{
+		SQLReplacements: []config.SQLReplacement{
+			{Pattern: `OWNER TO \w+`, Replacement: "OWNER TO app_owner"},
+			{Pattern: `\);\n`, Replacement: ") TABLESPACE data_ts;\n"},
+		},
+	}, filepath.Join(tempDir, "backup"))
+	require.NoError(t, err)
+	require.True(t, processor.Enabled())
+
+	require.NoError(t, processor.Process(context.Background(), MigrationTypeTable, files))
+
+	content, err := os.ReadFile(newFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "CREATE TABLE users (id int) TABLESPACE data_ts;")
+	assert.Contains(t, string(content), "OWNER TO app_owner")
+
+	// 原始文件应保留备份
+	backups, err := filepath.Glob(filepath.Join(tempDir, "backup", "postprocess", "TABLE-*", "*TABLE_output.sql"))
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	backup, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, original, string(backup))
+}
+
+func TestSQLPostProcessorInvalidPattern(t *testing.T) {
+	_, err := NewSQLPostProcessor(&config.MigrationConfig{
+		SQLReplacements: []config.SQLReplacement{{Pattern: `(unclosed`}},
+	}, t.TempDir())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "INVALID_SQL_REPLACEMENT")
+}
+
+func TestSQLPostProcessorScriptFailureRestores(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("脚本测试依赖 /bin/sh")
+	}
+
+	tempDir := t.TempDir()
+	script := filepath.Join(tempDir, "fail.sh")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\necho broken >&2\nexit 3\n"), 0755))
+
+	file := filepath.Join(tempDir, "VIEW_output.sql")
+	require.NoError(t, os.WriteFile(file, []byte("CREATE VIEW v AS SELECT 1;\n"), 0644))
+
+	processor, err := NewSQLPostProcessor(&config.MigrationConfig{
+		PostProcessScript: script,
+		SQLReplacements:   []config.SQLReplacement{{Pattern: "VIEW", Replacement: "OR REPLACE VIEW"}},
+	}, filepath.Join(tempDir, "backup"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = processor.Process(ctx, MigrationTypeView, []string{file})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "POST_PROCESS_FAILED")
+
+	// 失败后应恢复原始内容
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, "CREATE VIEW v AS SELECT 1;\n", string(content))
+}
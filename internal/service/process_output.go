@@ -0,0 +1,48 @@
+package service
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// outputCollector 在独立协程中收集一个输出流的所有行
+//
+// 收集与读取并发进行，输出超过通道缓冲区时读取协程不会阻塞，进程也就不会因管道写满而挂起。
+type outputCollector struct {
+	lines   chan string
+	done    chan struct{}
+	builder strings.Builder
+}
+
+// newOutputCollector 创建输出收集器并开始收集，buffer 为通道缓冲区大小
+func newOutputCollector(buffer int) *outputCollector {
+	collector := &outputCollector{lines: make(chan string, buffer), done: make(chan struct{})}
+	go func() {
+		defer close(collector.done)
+		for line := range collector.lines {
+			collector.builder.WriteString(line)
+		}
+	}()
+	return collector
+}
+
+// Close 写入该输出流的读取协程结束后调用，等待收集完成并返回收集到的输出
+func (c *outputCollector) Close() string {
+	close(c.lines)
+	<-c.done
+	return c.builder.String()
+}
+
+// waitAfterOutput 等待 readers 个输出读取协程结束后再调用 cmd.Wait，返回接收其结果的通道
+//
+// 输出读取完成前调用 Wait 会关闭管道导致输出丢失；在独立协程中等待，调用方可同时处理超时和取消。
+func waitAfterOutput(cmd *exec.Cmd, readersDone <-chan bool, readers int) <-chan error {
+	waitChan := make(chan error, 1)
+	go func() {
+		for i := 0; i < readers; i++ {
+			<-readersDone
+		}
+		waitChan <- cmd.Wait()
+	}()
+	return waitChan
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputCollectorDoesNotBlockReaders(t *testing.T) {
+	collector := newOutputCollector(1)
+
+	// 写入远超缓冲区的行数，收集协程及时消费时写入不会阻塞
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		for i := 0; i < 1000; i++ {
+			collector.lines <- fmt.Sprintf("line %d\n", i)
+		}
+	}()
+	select {
+	case <-written:
+	case <-time.After(5 * time.Second):
+		t.Fatal("写入输出被阻塞")
+	}
+
+	output := collector.Close()
+	assert.Equal(t, 1000, strings.Count(output, "\n"))
+	assert.True(t, strings.HasPrefix(output, "line 0\n"))
+	assert.True(t, strings.HasSuffix(output, "line 999\n"))
+}
+
+// writeFakeOra2pg 在临时目录写入模拟的ora2pg脚本并加入PATH
+func writeFakeOra2pg(t *testing.T, script string) {
+	t.Helper()
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ora2pg"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestExecuteLargeOutputWithTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟ora2pg依赖 /bin/sh")
+	}
+
+	// 两个输出流各写入约200KB，超过通道缓冲区和管道缓冲区；设置超时时走等待循环而不是直接 Wait
+	writeFakeOra2pg(t, `#!/bin/sh
+i=0
+while [ $i -lt 5000 ]; do
+  echo "Exported $i rows from table EMPLOYEES_WITH_A_LONG_NAME"
+  echo "fetched batch $i of table EMPLOYEES_WITH_A_LONG_NAME" >&2
+  i=$((i+1))
+done
+`)
+
+	done := make(chan struct{})
+	var result *ExecutionResult
+	var err error
+	go func() {
+		defer close(done)
+		result, err = NewOra2pgService().Execute(context.Background(), MigrationTypeCopy, &ExecutionOptions{Timeout: time.Minute})
+	}()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("大量输出时等待命令结束发生死锁")
+	}
+
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, result.Status)
+	// 输出读取完成后才调用 Wait，输出没有丢失
+	assert.Equal(t, 5000, strings.Count(result.Output, "\n"))
+	assert.Equal(t, 5000, strings.Count(result.ErrorOutput, "\n"))
+	assert.Contains(t, result.Output, "Exported 4999 rows")
+	assert.Contains(t, result.ErrorOutput, "fetched batch 4999 of table")
+}
+
+func TestExecuteCanceledKeepsPartialOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟ora2pg依赖 /bin/sh")
+	}
+
+	writeFakeOra2pg(t, "#!/bin/sh\necho 'Exported 10 rows'\nexec sleep 30\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(300*time.Millisecond, cancel)
+
+	start := time.Now()
+	result, err := NewOra2pgService().Execute(ctx, MigrationTypeCopy, &ExecutionOptions{})
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 10*time.Second)
+	assert.Equal(t, StatusFailed, result.Status)
+	assert.Contains(t, result.Output, "Exported 10 rows")
+}
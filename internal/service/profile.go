@@ -0,0 +1,274 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultProfileTopTables 性能分析默认显示的最慢表数量
+const DefaultProfileTopTables = 10
+
+// historyProfileTables 每个迁移类型写入迁移历史的最慢表数量，避免历史文件过大
+const historyProfileTables = 20
+
+// 优化建议的判断阈值
+const (
+	// profileIndexShare INDEX 阶段的耗时占比不低于该值时建议迁移后再建索引
+	profileIndexShare = 0.3
+	// profileInsertShare INSERT 的耗时占比不低于该值时建议改用 COPY
+	profileInsertShare = 0.2
+	// profileHotTableShare 单张表占数据迁移耗时不低于该比例时建议分片并行导出
+	profileHotTableShare = 0.5
+	// profileSlowTableRatio 表的迁移速度低于平均速度的该比例时视为明显偏慢
+	profileSlowTableRatio = 0.2
+	// profileMinSlowTable 耗时不到该时长的表不判断是否偏慢
+	profileMinSlowTable = 10 * time.Second
+)
+
+// tableRatePattern ora2pg进度条末尾的表级速度，如 "Table EMPLOYEES (250 recs/sec)"
+var tableRatePattern = regexp.MustCompile(`Table\s+([\w$#.]+)\s+\((\d+(?:\.\d+)?)\s+recs/sec\)`)
+
+// TableTiming 一张表的数据迁移耗时
+type TableTiming struct {
+	Table string `json:"table"`
+	Rows  int64  `json:"rows"`
+	// Duration 按ora2pg报告的行数和速度计算的表处理时间，没有速度时为首末两次进度输出的间隔
+	Duration      time.Duration `json:"duration"`
+	RowsPerSecond float64       `json:"rows_per_second,omitempty"`
+}
+
+// tableTimingCollector 从ora2pg输出中采集表级耗时
+type tableTimingCollector struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	order  []string
+	tables map[string]*tableTimingState
+}
+
+// tableTimingState 采集中的表状态
+type tableTimingState struct {
+	start, end time.Time
+	rows       int64
+	rate       float64
+}
+
+// newTableTimingCollector 创建表级耗时采集器，每个迁移类型使用一个
+func newTableTimingCollector() *tableTimingCollector {
+	return &tableTimingCollector{now: time.Now, tables: make(map[string]*tableTimingState)}
+}
+
+// Feed 处理一行输出（stdout和stderr可能并发调用）
+func (c *tableTimingCollector) Feed(line string) {
+	processing := tableProcessingPattern.FindStringSubmatch(line)
+	progress := tableProgressPattern.FindAllStringSubmatch(line, -1)
+	if processing == nil && progress == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if processing != nil {
+		c.table(processing[1], now)
+	}
+	for _, match := range progress {
+		state := c.table(match[2], now)
+		state.end = now
+		if rows, err := strconv.ParseInt(match[1], 10, 64); err == nil && rows > state.rows {
+			state.rows = rows
+		}
+	}
+	for _, match := range tableRatePattern.FindAllStringSubmatch(line, -1) {
+		if rate, err := strconv.ParseFloat(match[2], 64); err == nil {
+			c.table(match[1], now).rate = rate
+		}
+	}
+}
+
+// table 获取表的采集状态，第一次出现时记录开始时间（调用方需持有锁）
+func (c *tableTimingCollector) table(name string, now time.Time) *tableTimingState {
+	state := c.tables[name]
+	if state == nil {
+		state = &tableTimingState{start: now, end: now}
+		c.tables[name] = state
+		c.order = append(c.order, name)
+	}
+	return state
+}
+
+// Timings 各表的耗时，按首次出现的顺序排列
+func (c *tableTimingCollector) Timings() []TableTiming {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timings := make([]TableTiming, 0, len(c.order))
+	for _, name := range c.order {
+		state := c.tables[name]
+		timing := TableTiming{Table: name, Rows: state.rows, Duration: state.end.Sub(state.start), RowsPerSecond: state.rate}
+		// ora2pg的速度是行数除以该表的处理时间，比按输出间隔测量更准确（整表只输出一次进度时间隔为0）
+		if state.rate > 0 && state.rows > 0 {
+			timing.Duration = time.Duration(float64(state.rows) / state.rate * float64(time.Second))
+		} else if timing.Duration > 0 {
+			timing.RowsPerSecond = float64(state.rows) / timing.Duration.Seconds()
+		}
+		timings = append(timings, timing)
+	}
+	return timings
+}
+
+// TypeProfile 一个迁移类型的耗时
+type TypeProfile struct {
+	Type     MigrationType `json:"type"`
+	Duration time.Duration `json:"duration"`
+	// Share 占各迁移类型总耗时的比例（0-1）
+	Share float64 `json:"share"`
+	Rows  int64   `json:"rows,omitempty"`
+}
+
+// TableProfile 一张表的耗时及其所属的迁移类型
+type TableProfile struct {
+	Type MigrationType `json:"type"`
+	TableTiming
+	// Share 占所有表耗时之和的比例（0-1）
+	Share float64 `json:"share"`
+}
+
+// MigrationProfile 迁移耗时的性能分析报告
+type MigrationProfile struct {
+	// Duration 各迁移类型耗时之和
+	Duration time.Duration `json:"duration"`
+	// Types 各迁移类型的耗时，从高到低排列
+	Types []TypeProfile `json:"types"`
+	// SlowestTables 耗时最长的表，从高到低排列
+	SlowestTables []TableProfile `json:"slowest_tables,omitempty"`
+	// TableCount 采集到耗时的表数量
+	TableCount int   `json:"table_count"`
+	TotalRows  int64 `json:"total_rows"`
+	// TimePerRow 数据迁移类型的平均每行耗时，没有迁移数据时为0
+	TimePerRow  time.Duration `json:"time_per_row,omitempty"`
+	Suggestions []string      `json:"suggestions,omitempty"`
+}
+
+// BuildMigrationProfile 根据迁移历史中的类型结果分析耗时，topTables 为显示的最慢表数量
+func BuildMigrationProfile(results []HistoryTypeResult, topTables int) *MigrationProfile {
+	profile := &MigrationProfile{}
+	var dataDuration time.Duration
+	var tables []TableProfile
+	var tableDuration time.Duration
+	for _, result := range results {
+		if result.Status == StatusSkipped {
+			continue
+		}
+		profile.Duration += result.Duration
+		profile.TotalRows += result.Rows
+		profile.Types = append(profile.Types, TypeProfile{Type: result.Type, Duration: result.Duration, Rows: result.Rows})
+		if isDataMigrationType(result.Type) {
+			dataDuration += result.Duration
+		}
+		for _, timing := range result.Tables {
+			tables = append(tables, TableProfile{Type: result.Type, TableTiming: timing})
+			tableDuration += timing.Duration
+		}
+	}
+	for i := range profile.Types {
+		if profile.Duration > 0 {
+			profile.Types[i].Share = float64(profile.Types[i].Duration) / float64(profile.Duration)
+		}
+	}
+	sort.SliceStable(profile.Types, func(i, j int) bool { return profile.Types[i].Duration > profile.Types[j].Duration })
+
+	for i := range tables {
+		if tableDuration > 0 {
+			tables[i].Share = float64(tables[i].Duration) / float64(tableDuration)
+		}
+	}
+	sort.SliceStable(tables, func(i, j int) bool { return tables[i].Duration > tables[j].Duration })
+	profile.TableCount = len(tables)
+	if profile.TotalRows > 0 && dataDuration > 0 {
+		profile.TimePerRow = dataDuration / time.Duration(profile.TotalRows)
+	}
+
+	profile.Suggestions = profileSuggestions(profile, tables)
+	if topTables > 0 && len(tables) > topTables {
+		tables = tables[:topTables]
+	}
+	profile.SlowestTables = tables
+	return profile
+}
+
+// profileSuggestions 根据耗时分布给出优化建议，tables 为按耗时从高到低排列的全部表
+func profileSuggestions(profile *MigrationProfile, tables []TableProfile) []string {
+	var suggestions []string
+	for _, item := range profile.Types {
+		switch {
+		case item.Type == MigrationTypeIndex && item.Share >= profileIndexShare:
+			suggestions = append(suggestions, fmt.Sprintf(
+				"INDEX 阶段占 %s，考虑迁移数据后再建索引：使用 structure-first 或 data-first 配置片段（migration.fragments）", FormatShare(item.Share)))
+		case item.Type == MigrationTypeInsert && item.Share >= profileInsertShare:
+			suggestions = append(suggestions, fmt.Sprintf(
+				"INSERT 阶段占 %s，INSERT 语句逐行写入，数据量大时改用 COPY 迁移数据", FormatShare(item.Share)))
+		}
+	}
+
+	if len(tables) > 1 && tables[0].Share >= profileHotTableShare {
+		suggestions = append(suggestions, fmt.Sprintf(
+			"表 %s 占表迁移耗时的 %s，可在 migration.large_tables 中为其配置分片并行导出", tables[0].Table, FormatShare(tables[0].Share)))
+	}
+
+	var rows int64
+	var duration time.Duration
+	for _, table := range tables {
+		rows += table.Rows
+		duration += table.Duration
+	}
+	if rows == 0 || duration <= 0 {
+		return suggestions
+	}
+	average := float64(rows) / duration.Seconds()
+	var slow []string
+	for _, table := range tables {
+		if table.Duration >= profileMinSlowTable && table.RowsPerSecond < average*profileSlowTableRatio {
+			slow = append(slow, table.Table)
+		}
+	}
+	if len(slow) > 0 {
+		suggestions = append(suggestions, fmt.Sprintf(
+			"表 %s 的迁移速度明显低于平均的 %s 行/秒，可能包含LOB等大字段，可使用 lob 配置片段减少每批读取的行数",
+			strings.Join(slow, "、"), formatRate(average)))
+	}
+	return suggestions
+}
+
+// slowestTables 耗时最长的 limit 张表，从高到低排列
+func slowestTables(timings []TableTiming, limit int) []TableTiming {
+	sorted := append([]TableTiming(nil), timings...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Duration > sorted[j].Duration })
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	return sorted
+}
+
+// FormatShare 格式化耗时占比，如 "60%"，不足1%时保留一位小数
+func FormatShare(share float64) string {
+	percent := share * 100
+	if percent > 0 && percent < 1 {
+		return strconv.FormatFloat(percent, 'f', 1, 64) + "%"
+	}
+	return strconv.FormatFloat(percent, 'f', 0, 64) + "%"
+}
+
+// FormatTimePerRow 格式化平均每行耗时，如 "12.5µs"
+func FormatTimePerRow(duration time.Duration) string {
+	switch {
+	case duration >= time.Millisecond:
+		return duration.Round(10 * time.Microsecond).String()
+	case duration >= time.Microsecond:
+		return duration.Round(10 * time.Nanosecond).String()
+	}
+	return duration.String()
+}
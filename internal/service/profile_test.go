@@ -0,0 +1,106 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableTimingCollector(t *testing.T) {
+	collector := newTableTimingCollector()
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	collector.now = func() time.Time { return now }
+
+	collector.Feed("Processing table: EMPLOYEES (1/3)")
+	now = now.Add(2 * time.Second)
+	collector.Feed("[=====>     ] 500/1000 rows (50.0%) Table EMPLOYEES (250 recs/sec)")
+	now = now.Add(2 * time.Second)
+	// 同一行中以 \r 分隔的多个进度条分别计入各表
+	collector.Feed("[========================>] 1000/1000 rows (100.0%) Table EMPLOYEES (250 recs/sec)\r" +
+		"[========================>] 14/14 rows (100.0%) Table COUNTRIES (14 recs/sec)")
+	collector.Feed("Processing table: REGIONS (3/3)")
+	now = now.Add(3 * time.Second)
+	collector.Feed("[==>        ] 30/100 rows (30.0%) Table REGIONS")
+	collector.Feed("[========================>] 1014/1014 total rows (100.0%) - (7 sec., avg: 144 recs/sec).")
+
+	timings := collector.Timings()
+	require.Len(t, timings, 3)
+	// 有速度时按 行数/速度 计算处理时间
+	assert.Equal(t, TableTiming{Table: "EMPLOYEES", Rows: 1000, Duration: 4 * time.Second, RowsPerSecond: 250}, timings[0])
+	assert.Equal(t, TableTiming{Table: "COUNTRIES", Rows: 14, Duration: time.Second, RowsPerSecond: 14}, timings[1])
+	// 没有速度时按首末两次输出的间隔计算
+	assert.Equal(t, TableTiming{Table: "REGIONS", Rows: 30, Duration: 3 * time.Second, RowsPerSecond: 10}, timings[2])
+}
+
+func TestBuildMigrationProfile(t *testing.T) {
+	results := []HistoryTypeResult{
+		{Type: MigrationTypeTable, Status: StatusCompleted, Duration: 10 * time.Second},
+		{Type: MigrationTypeCopy, Status: StatusCompleted, Duration: 30 * time.Second, Rows: 10000, Tables: []TableTiming{
+			{Table: "ORDERS", Rows: 9000, Duration: 18 * time.Second, RowsPerSecond: 500},
+			{Table: "DOCUMENTS", Rows: 100, Duration: 10 * time.Second, RowsPerSecond: 10},
+			{Table: "COUNTRIES", Rows: 900, Duration: 2 * time.Second, RowsPerSecond: 450},
+		}},
+		{Type: MigrationTypeIndex, Status: StatusCompleted, Duration: 60 * time.Second},
+		{Type: MigrationTypeView, Status: StatusSkipped},
+	}
+
+	profile := BuildMigrationProfile(results, 2)
+	assert.Equal(t, 100*time.Second, profile.Duration)
+	assert.Equal(t, int64(10000), profile.TotalRows)
+	assert.Equal(t, 3*time.Millisecond, profile.TimePerRow)
+
+	// 跳过的类型不计入，按耗时从高到低排列
+	require.Len(t, profile.Types, 3)
+	assert.Equal(t, MigrationTypeIndex, profile.Types[0].Type)
+	assert.InDelta(t, 0.6, profile.Types[0].Share, 1e-9)
+	assert.Equal(t, MigrationTypeCopy, profile.Types[1].Type)
+	assert.Equal(t, MigrationTypeTable, profile.Types[2].Type)
+
+	assert.Equal(t, 3, profile.TableCount)
+	require.Len(t, profile.SlowestTables, 2)
+	assert.Equal(t, "ORDERS", profile.SlowestTables[0].Table)
+	assert.Equal(t, MigrationTypeCopy, profile.SlowestTables[0].Type)
+	assert.InDelta(t, 0.6, profile.SlowestTables[0].Share, 1e-9)
+	assert.Equal(t, "DOCUMENTS", profile.SlowestTables[1].Table)
+
+	require.Len(t, profile.Suggestions, 3)
+	assert.Contains(t, profile.Suggestions[0], "INDEX 阶段占 60%，考虑迁移数据后再建索引")
+	assert.Contains(t, profile.Suggestions[1], "表 ORDERS 占表迁移耗时的 60%")
+	assert.Contains(t, profile.Suggestions[2], "表 DOCUMENTS 的迁移速度明显低于平均的 333 行/秒")
+}
+
+func TestBuildMigrationProfileWithoutData(t *testing.T) {
+	profile := BuildMigrationProfile([]HistoryTypeResult{
+		{Type: MigrationTypeTable, Status: StatusCompleted, Duration: 5 * time.Second},
+		{Type: MigrationTypeInsert, Status: StatusFailed, Duration: 5 * time.Second},
+	}, DefaultProfileTopTables)
+
+	assert.Equal(t, time.Duration(0), profile.TimePerRow)
+	assert.Empty(t, profile.SlowestTables)
+	require.Len(t, profile.Suggestions, 1)
+	assert.Contains(t, profile.Suggestions[0], "INSERT 阶段占 50%")
+}
+
+func TestSlowestTables(t *testing.T) {
+	timings := []TableTiming{
+		{Table: "A", Duration: time.Second},
+		{Table: "B", Duration: 3 * time.Second},
+		{Table: "C", Duration: 2 * time.Second},
+	}
+	slowest := slowestTables(timings, 2)
+	require.Len(t, slowest, 2)
+	assert.Equal(t, "B", slowest[0].Table)
+	assert.Equal(t, "C", slowest[1].Table)
+	assert.Equal(t, "A", timings[0].Table, "不修改原有顺序")
+	assert.Nil(t, slowestTables(nil, 2))
+}
+
+func TestFormatShare(t *testing.T) {
+	assert.Equal(t, "60%", FormatShare(0.6))
+	assert.Equal(t, "0.5%", FormatShare(0.005))
+	assert.Equal(t, "0%", FormatShare(0))
+	assert.Equal(t, "12.5µs", FormatTimePerRow(12500*time.Nanosecond))
+	assert.Equal(t, "3ms", FormatTimePerRow(3*time.Millisecond))
+}
@@ -13,9 +13,13 @@ import (
 type ProgressTracker struct {
 	taskName       string
 	totalSteps     int
+	// stepWeights 各步骤的权重，为空时每个步骤等权
+	stepWeights    []float64
 	currentStep    int
 	currentMessage string
+	currentDetails string
 	percentage     float64
+	resourceInfo   string
 	startTime      time.Time
 	lastUpdateTime time.Time
 	isRunning      bool
@@ -23,6 +27,20 @@ type ProgressTracker struct {
 	logger         *utils.Logger
 	stopChan       chan bool
 	updateChan     chan ProgressUpdate
+	updateHandler  func(ProgressUpdate)
+	// quiet 不在终端显示进度，仍然记录进度并调用更新回调
+	quiet          bool
+
+	// 吞吐率采集：已迁移的行数、上次采样时的行数和时间、最近一次资源采样
+	processedRows  int64
+	sampledRows    int64
+	sampledTime    time.Time
+	latestResource *ResourceSample
+	throughput     []ThroughputSample
+
+	// 进度状态文件，为空时不写入
+	stateWriter *progressStateWriter
+	migration   *MigrationProgress
 }
 
 // ProgressUpdate 进度更新信息
@@ -31,6 +49,8 @@ type ProgressUpdate struct {
 	Message    string
 	Percentage float64
 	Details    string
+	// Completed 当前步骤已执行完成
+	Completed bool
 }
 
 // NewProgressTracker 创建新的进度跟踪器
@@ -55,11 +75,16 @@ func (pt *ProgressTracker) Start(taskName string, totalSteps int) {
 	pt.startTime = time.Now()
 	pt.lastUpdateTime = time.Now()
 	pt.isRunning = true
+	pt.resetThroughput()
+	pt.persistState(true)
 
 	// 启动进度显示协程
 	go pt.displayProgress()
 
 	pt.logger.Infof("开始进度跟踪: %s (总步骤: %d)", taskName, totalSteps)
+	if pt.quiet {
+		return
+	}
 	fmt.Printf("🚀 开始%s\n", taskName)
 	pt.printProgressBar()
 }
@@ -73,12 +98,17 @@ func (pt *ProgressTracker) Stop() {
 		return
 	}
 
+	pt.finishThroughput(time.Now())
 	pt.isRunning = false
+	pt.persistState(true)
 	pt.stopChan <- true
 	close(pt.updateChan)
 
 	duration := time.Since(pt.startTime)
 	pt.logger.Infof("进度跟踪结束: %s (耗时: %v)", pt.taskName, duration)
+	if pt.quiet {
+		return
+	}
 	
 	fmt.Printf("\n✅ %s完成，总耗时: %v\n", pt.taskName, duration)
 }
@@ -94,22 +124,19 @@ func (pt *ProgressTracker) UpdateStep(step int, message string) {
 
 	pt.currentStep = step
 	pt.currentMessage = message
+	pt.currentDetails = ""
 	pt.lastUpdateTime = time.Now()
 
-	if pt.totalSteps > 0 {
-		pt.percentage = float64(step) / float64(pt.totalSteps) * 100
-	}
+	// 步骤开始执行时，进度为之前各步骤完成的进度
+	pt.percentage = pt.completedPercentage(step - 1)
+	pt.persistState(true)
 
 	// 发送更新信息
-	select {
-	case pt.updateChan <- ProgressUpdate{
+	pt.publish(ProgressUpdate{
 		Step:       step,
 		Message:    message,
 		Percentage: pt.percentage,
-	}:
-	default:
-		// 如果通道满了，跳过这次更新
-	}
+	})
 
 	pt.logger.Debugf("进度更新: 步骤 %d/%d - %s", step, pt.totalSteps, message)
 }
@@ -124,25 +151,124 @@ func (pt *ProgressTracker) UpdateProgress(percentage float64, details string) {
 	}
 
 	pt.percentage = percentage
+	pt.currentDetails = details
 	pt.lastUpdateTime = time.Now()
+	pt.persistState(false)
 
 	// 发送更新信息
-	select {
-	case pt.updateChan <- ProgressUpdate{
+	pt.publish(ProgressUpdate{
 		Step:       pt.currentStep,
 		Message:    pt.currentMessage,
 		Percentage: percentage,
 		Details:    details,
-	}:
+	})
+}
+
+// CompleteStep 标记步骤执行完成，进度按已完成步骤的权重计算
+func (pt *ProgressTracker) CompleteStep(step int, message string) {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+
+	if !pt.isRunning {
+		return
+	}
+
+	pt.currentStep = step
+	pt.currentMessage = message
+	pt.currentDetails = ""
+	pt.lastUpdateTime = time.Now()
+	pt.percentage = pt.completedPercentage(step)
+	pt.persistState(true)
+
+	pt.publish(ProgressUpdate{
+		Step:       step,
+		Message:    message,
+		Percentage: pt.percentage,
+		Completed:  true,
+	})
+}
+
+// SetStepWeights 设置各步骤的权重（如各迁移类型的对象数量），总进度按已完成步骤的权重之和计算
+//
+// 权重个数与总步骤数不一致或权重无效时仍按等权计算。
+func (pt *ProgressTracker) SetStepWeights(weights []float64) {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	pt.stepWeights = append([]float64(nil), weights...)
+}
+
+// completedPercentage 前 completed 个步骤完成时的总进度（调用方需持有锁）
+func (pt *ProgressTracker) completedPercentage(completed int) float64 {
+	if pt.totalSteps <= 0 || completed <= 0 {
+		return 0
+	}
+	if completed > pt.totalSteps {
+		completed = pt.totalSteps
+	}
+
+	if len(pt.stepWeights) == pt.totalSteps {
+		var done, total float64
+		valid := true
+		for i, weight := range pt.stepWeights {
+			if weight < 0 {
+				valid = false
+				break
+			}
+			total += weight
+			if i < completed {
+				done += weight
+			}
+		}
+		if valid && total > 0 {
+			return done / total * 100
+		}
+	}
+	return float64(completed) / float64(pt.totalSteps) * 100
+}
+
+// SetQuiet 设置是否不在终端显示进度（如多个目标并行迁移时由调用方合并显示），需在 Start 之前调用
+func (pt *ProgressTracker) SetQuiet(quiet bool) {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	pt.quiet = quiet
+}
+
+// SetUpdateHandler 设置进度更新回调，用于向外部推送进度；回调在持有锁时调用，不能阻塞
+func (pt *ProgressTracker) SetUpdateHandler(handler func(ProgressUpdate)) {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	pt.updateHandler = handler
+}
+
+// publish 把更新发送给显示协程和更新回调（调用方需持有锁）
+func (pt *ProgressTracker) publish(update ProgressUpdate) {
+	select {
+	case pt.updateChan <- update:
 	default:
 		// 如果通道满了，跳过这次更新
 	}
+	if pt.updateHandler != nil {
+		pt.updateHandler(update)
+	}
+}
+
+// UpdateResources 更新资源使用信息，显示在进度条之后
+func (pt *ProgressTracker) UpdateResources(info string) {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+
+	if !pt.isRunning {
+		return
+	}
+	pt.resourceInfo = info
 }
 
 // displayProgress 显示进度（在单独的协程中运行）
 func (pt *ProgressTracker) displayProgress() {
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
+	sampleTicker := time.NewTicker(throughputInterval)
+	defer sampleTicker.Stop()
 
 	for {
 		select {
@@ -151,14 +277,20 @@ func (pt *ProgressTracker) displayProgress() {
 		case update := <-pt.updateChan:
 			pt.handleProgressUpdate(update)
 		case <-ticker.C:
-			// 定期刷新显示
+			// 定期刷新显示，补写被节流跳过的进度状态
 			pt.refreshDisplay()
+			pt.flushState()
+		case <-sampleTicker.C:
+			pt.recordThroughput()
 		}
 	}
 }
 
 // handleProgressUpdate 处理进度更新
 func (pt *ProgressTracker) handleProgressUpdate(update ProgressUpdate) {
+	if pt.quiet {
+		return
+	}
 	fmt.Printf("\r🔄 [%d/%d] %s", update.Step, pt.totalSteps, update.Message)
 	
 	if update.Details != "" {
@@ -173,7 +305,7 @@ func (pt *ProgressTracker) refreshDisplay() {
 	pt.mutex.RLock()
 	defer pt.mutex.RUnlock()
 
-	if !pt.isRunning {
+	if !pt.isRunning || pt.quiet {
 		return
 	}
 
@@ -191,6 +323,9 @@ func (pt *ProgressTracker) printProgressBar() {
 	
 	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
 	fmt.Printf(" [%s] %.1f%%", bar, pt.percentage)
+	if pt.resourceInfo != "" {
+		fmt.Printf(" | %s", pt.resourceInfo)
+	}
 }
 
 // GetCurrentStatus 获取当前状态
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// ProgressParser 解析一行ora2pg输出并更新进度，识别该行时返回 true
+type ProgressParser interface {
+	Parse(line string, progress *ProgressInfo) bool
+}
+
+// ProgressHandler 规则匹配后根据捕获组更新进度，matches[0] 为整个匹配
+type ProgressHandler func(matches []string, progress *ProgressInfo)
+
+// ProgressRule 一条输出解析规则：正则表达式匹配时调用处理函数
+type ProgressRule struct {
+	// Name 规则名称，注册同名规则时替换原有规则
+	Name string
+	// Priority 优先级，越大越先匹配，相同时按注册顺序
+	Priority int
+	Pattern  *regexp.Regexp
+	Handler  ProgressHandler
+}
+
+// Parse 匹配时调用处理函数
+func (r ProgressRule) Parse(line string, progress *ProgressInfo) bool {
+	matches := r.Pattern.FindStringSubmatch(line)
+	if matches == nil {
+		return false
+	}
+	r.Handler(matches, progress)
+	return true
+}
+
+// 内置规则的优先级，自定义规则默认为0，排在具体格式之后、兜底的状态行之前
+const (
+	progressPriorityProcessing = 300
+	progressPriorityExported   = 200
+	progressPriorityTotalRows  = 100
+	progressPriorityStatus     = -100
+)
+
+// builtinProgressRules 内置的ora2pg输出解析规则
+func builtinProgressRules() []ProgressRule {
+	return []ProgressRule{
+		{
+			// 匹配 "Processing table: TABLE_NAME (1/10)"
+			Name:     "processing",
+			Priority: progressPriorityProcessing,
+			Pattern:  regexp.MustCompile(`Processing\s+(\w+):\s+(\w+)\s+\((\d+)/(\d+)\)`),
+			Handler: func(matches []string, progress *ProgressInfo) {
+				progress.CurrentStep = fmt.Sprintf("处理%s: %s", matches[1], matches[2])
+				if completed, err := strconv.Atoi(matches[3]); err == nil {
+					progress.CompletedSteps = completed
+				}
+				if total, err := strconv.Atoi(matches[4]); err == nil {
+					setProgressTotal(progress, total)
+				}
+			},
+		},
+		{
+			// 匹配 "Exported 1000 rows"
+			Name:     "exported-rows",
+			Priority: progressPriorityExported,
+			Pattern:  regexp.MustCompile(`Exported\s+(\d+)\s+rows`),
+			Handler: func(matches []string, progress *ProgressInfo) {
+				if rows, err := strconv.ParseInt(matches[1], 10, 64); err == nil {
+					progress.ProcessedRows = rows
+				}
+				progress.Message = fmt.Sprintf("已导出 %s 行数据", matches[1])
+			},
+		},
+		{
+			// 匹配 "Total rows: 10000"
+			Name:     "total-rows",
+			Priority: progressPriorityTotalRows,
+			Pattern:  regexp.MustCompile(`Total\s+rows:\s+(\d+)`),
+			Handler: func(matches []string, progress *ProgressInfo) {
+				if rows, err := strconv.ParseInt(matches[1], 10, 64); err == nil {
+					progress.TotalRows = rows
+				}
+			},
+		},
+		{
+			// 匹配一般的状态信息
+			Name:     "status",
+			Priority: progressPriorityStatus,
+			Pattern:  regexp.MustCompile(`^(INFO|WARNING|ERROR):\s+(.+)`),
+			Handler: func(matches []string, progress *ProgressInfo) {
+				progress.Message = matches[2]
+			},
+		},
+	}
+}
+
+// setProgressTotal 设置总步骤数并按已完成步骤计算百分比
+func setProgressTotal(progress *ProgressInfo, total int) {
+	progress.TotalSteps = total
+	if total > 0 {
+		progress.Percentage = float64(progress.CompletedSteps) / float64(total) * 100
+	}
+}
+
+// ProgressRuleSet 按优先级排列的解析规则，每行只应用第一条匹配的规则
+type ProgressRuleSet struct {
+	mu    sync.RWMutex
+	rules []ProgressRule
+}
+
+// NewProgressRuleSet 创建规则集
+func NewProgressRuleSet(rules ...ProgressRule) *ProgressRuleSet {
+	set := &ProgressRuleSet{}
+	for _, rule := range rules {
+		set.Register(rule)
+	}
+	return set
+}
+
+// Register 注册规则，同名规则替换原有规则
+func (s *ProgressRuleSet) Register(rule ProgressRule) error {
+	if strings.TrimSpace(rule.Name) == "" || rule.Pattern == nil || rule.Handler == nil {
+		return utils.NewError(utils.ErrorTypeConfig, "INVALID_PROGRESS_RULE").
+			Message("输出解析规则无效").
+			Details(fmt.Sprintf("规则 %q 缺少名称、正则表达式或处理函数", rule.Name)).
+			Build()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.rules {
+		if s.rules[i].Name == rule.Name {
+			s.rules = append(s.rules[:i], s.rules[i+1:]...)
+			break
+		}
+	}
+	s.rules = append(s.rules, rule)
+	sort.SliceStable(s.rules, func(i, j int) bool {
+		return s.rules[i].Priority > s.rules[j].Priority
+	})
+	return nil
+}
+
+// Rules 按匹配顺序排列的规则
+func (s *ProgressRuleSet) Rules() []ProgressRule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]ProgressRule(nil), s.rules...)
+}
+
+// Clone 复制规则集，之后注册的规则互不影响
+func (s *ProgressRuleSet) Clone() *ProgressRuleSet {
+	return &ProgressRuleSet{rules: s.Rules()}
+}
+
+// Parse 按优先级应用第一条匹配的规则
+func (s *ProgressRuleSet) Parse(line string, progress *ProgressInfo) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, rule := range s.rules {
+		if rule.Parse(line, progress) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultProgressRules 新建的ora2pg服务使用的规则集
+var defaultProgressRules = NewProgressRuleSet(builtinProgressRules()...)
+
+// RegisterProgressRule 注册全局解析规则，对之后创建的ora2pg服务生效
+func RegisterProgressRule(rule ProgressRule) error {
+	return defaultProgressRules.Register(rule)
+}
+
+// ProgressRuleFromConfig 将配置中的规则转换为解析规则
+func ProgressRuleFromConfig(rc config.ProgressRuleConfig) (ProgressRule, error) {
+	re, err := regexp.Compile(rc.Pattern)
+	if err != nil || rc.Pattern == "" {
+		return ProgressRule{}, utils.NewError(utils.ErrorTypeConfig, "INVALID_PROGRESS_RULE").
+			Message(fmt.Sprintf("输出解析规则 %s 的正则表达式无效", rc.Name)).
+			Details(rc.Pattern).
+			Cause(err).
+			Suggestion("检查 migration.progress_rules 中的正则表达式语法").
+			Build()
+	}
+	for key, group := range rc.Groups() {
+		if group < 0 || group > re.NumSubexp() {
+			return ProgressRule{}, utils.NewError(utils.ErrorTypeConfig, "INVALID_PROGRESS_RULE").
+				Message(fmt.Sprintf("输出解析规则 %s 引用了不存在的捕获组", rc.Name)).
+				Details(fmt.Sprintf("%s: %d，正则表达式共有 %d 个捕获组", key, group, re.NumSubexp())).
+				Build()
+		}
+	}
+
+	handler := func(matches []string, progress *ProgressInfo) {
+		if rc.Step != "" {
+			progress.CurrentStep = expandProgressGroups(rc.Step, re, matches)
+		}
+		if rc.Message != "" {
+			progress.Message = expandProgressGroups(rc.Message, re, matches)
+		}
+		if value, ok := progressGroupNumber(matches, rc.Completed); ok {
+			progress.CompletedSteps = int(value)
+		}
+		if value, ok := progressGroupNumber(matches, rc.Rows); ok {
+			progress.ProcessedRows = value
+		}
+		if value, ok := progressGroupNumber(matches, rc.TotalRows); ok {
+			progress.TotalRows = value
+		}
+		if value, ok := progressGroupNumber(matches, rc.Total); ok {
+			setProgressTotal(progress, int(value))
+		} else if rc.Completed > 0 && progress.TotalSteps > 0 {
+			setProgressTotal(progress, progress.TotalSteps)
+		}
+	}
+	return ProgressRule{Name: rc.Name, Priority: rc.Priority, Pattern: re, Handler: handler}, nil
+}
+
+// expandProgressGroups 展开模板中的 $1、${1}、${name} 捕获组引用
+func expandProgressGroups(template string, re *regexp.Regexp, matches []string) string {
+	return os.Expand(template, func(key string) string {
+		if n, err := strconv.Atoi(key); err == nil {
+			if n >= 0 && n < len(matches) {
+				return matches[n]
+			}
+			return ""
+		}
+		if i := re.SubexpIndex(key); i >= 0 {
+			return matches[i]
+		}
+		return ""
+	})
+}
+
+// progressGroupNumber 读取捕获组中的数字，允许千分位逗号；序号为0或不是数字时返回 false
+func progressGroupNumber(matches []string, group int) (int64, bool) {
+	if group <= 0 || group >= len(matches) {
+		return 0, false
+	}
+	value, err := strconv.ParseInt(strings.ReplaceAll(strings.TrimSpace(matches[group]), ",", ""), 10, 64)
+	return value, err == nil
+}
+
+// RegisterProgressRule 为当前服务注册解析规则，不影响其他服务
+func (s *Ora2pgService) RegisterProgressRule(rule ProgressRule) error {
+	return s.progressRules.Register(rule)
+}
+
+// registerConfiguredProgressRules 注册配置中的自定义解析规则
+func (s *Ora2pgService) registerConfiguredProgressRules(rules []config.ProgressRuleConfig) error {
+	for _, rc := range rules {
+		rule, err := ProgressRuleFromConfig(rc)
+		if err != nil {
+			return err
+		}
+		if err := s.RegisterProgressRule(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
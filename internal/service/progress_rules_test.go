@@ -0,0 +1,107 @@
+package service
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+func TestProgressRuleSetPriority(t *testing.T) {
+	set := NewProgressRuleSet(builtinProgressRules()...)
+	names := func() []string {
+		var result []string
+		for _, rule := range set.Rules() {
+			result = append(result, rule.Name)
+		}
+		return result
+	}
+	assert.Equal(t, []string{"processing", "exported-rows", "total-rows", "status"}, names())
+
+	// 默认优先级的自定义规则排在兜底的状态行规则之前，相同优先级按注册顺序
+	custom := func(name string, priority int) ProgressRule {
+		return ProgressRule{
+			Name:     name,
+			Priority: priority,
+			Pattern:  regexp.MustCompile(`^INFO: (.+)`),
+			Handler:  func(matches []string, progress *ProgressInfo) { progress.Message = name + ":" + matches[1] },
+		}
+	}
+	require.NoError(t, set.Register(custom("first", 0)))
+	require.NoError(t, set.Register(custom("second", 0)))
+	assert.Equal(t, []string{"processing", "exported-rows", "total-rows", "first", "second", "status"}, names())
+
+	progress := &ProgressInfo{}
+	assert.True(t, set.Parse("INFO: loading", progress))
+	assert.Equal(t, "first:loading", progress.Message)
+	assert.False(t, set.Parse("unrelated output", progress))
+
+	// 同名规则替换原有规则
+	require.NoError(t, set.Register(custom("status", 500)))
+	assert.Equal(t, []string{"status", "processing", "exported-rows", "total-rows", "first", "second"}, names())
+	set.Parse("INFO: loading", progress)
+	assert.Equal(t, "status:loading", progress.Message)
+
+	err := set.Register(ProgressRule{Name: "broken"})
+	assert.Equal(t, "INVALID_PROGRESS_RULE", utils.GetErrorCode(err))
+}
+
+func TestProgressRuleFromConfig(t *testing.T) {
+	rule, err := ProgressRuleFromConfig(config.ProgressRuleConfig{
+		Name:      "dump",
+		Pattern:   `Dumping (?P<table>\w+) \((\d+)/(\d+)\), ([\d,]+) rows`,
+		Step:      "导出表 ${table}",
+		Message:   "第 $2 张，已导出 $4 行",
+		Completed: 2,
+		Total:     3,
+		Rows:      4,
+	})
+	require.NoError(t, err)
+
+	progress := &ProgressInfo{}
+	assert.True(t, rule.Parse("Dumping ORDERS (2/8), 12,500 rows", progress))
+	assert.Equal(t, "导出表 ORDERS", progress.CurrentStep)
+	assert.Equal(t, "第 2 张，已导出 12,500 行", progress.Message)
+	assert.Equal(t, 2, progress.CompletedSteps)
+	assert.Equal(t, 8, progress.TotalSteps)
+	assert.Equal(t, 25.0, progress.Percentage)
+	assert.Equal(t, int64(12500), progress.ProcessedRows)
+
+	_, err = ProgressRuleFromConfig(config.ProgressRuleConfig{Name: "bad", Pattern: `(`})
+	assert.Equal(t, "INVALID_PROGRESS_RULE", utils.GetErrorCode(err))
+	_, err = ProgressRuleFromConfig(config.ProgressRuleConfig{Name: "bad", Pattern: `(\d+)`, Rows: 2})
+	assert.Equal(t, "INVALID_PROGRESS_RULE", utils.GetErrorCode(err))
+}
+
+func TestOra2pgServiceProgressRules(t *testing.T) {
+	service := NewOra2pgService()
+	require.NoError(t, service.registerConfiguredProgressRules([]config.ProgressRuleConfig{
+		{Name: "copy", Pattern: `^COPY (\w+): (\d+) rows`, Step: "复制 $1", Rows: 2},
+	}))
+
+	progress := &ProgressInfo{}
+	service.parseProgress("COPY ORDERS: 300 rows", progress)
+	assert.Equal(t, "复制 ORDERS", progress.CurrentStep)
+	assert.Equal(t, int64(300), progress.ProcessedRows)
+
+	// 服务的规则互不影响
+	other := &ProgressInfo{}
+	NewOra2pgService().parseProgress("COPY ORDERS: 300 rows", other)
+	assert.Empty(t, other.CurrentStep)
+
+	// 全局注册的规则对之后创建的服务生效
+	original := defaultProgressRules
+	defer func() { defaultProgressRules = original }()
+	defaultProgressRules = original.Clone()
+	require.NoError(t, RegisterProgressRule(ProgressRule{
+		Name:    "finished",
+		Pattern: regexp.MustCompile(`^Finished (\w+)`),
+		Handler: func(matches []string, progress *ProgressInfo) { progress.Message = matches[1] + " 完成" },
+	}))
+	progress = &ProgressInfo{}
+	NewOra2pgService().parseProgress("Finished TABLE", progress)
+	assert.Equal(t, "TABLE 完成", progress.Message)
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/oracle"
+)
+
+// 迁移类型在进度快照中的状态
+const (
+	ProgressStatusCompleted  = "completed"   // 至少有一次运行成功
+	ProgressStatusPartial    = "partial"     // 检查点中记录了部分已完成的表
+	ProgressStatusFailed     = "failed"      // 执行过但从未成功
+	ProgressStatusNotStarted = "not_started" // 没有运行记录
+)
+
+// migrationTypeObjectTypes 迁移类型对应的源库对象类型，数据类型按表统计；
+// 不在其中的类型（如 GRANT）无法统计对象总数
+var migrationTypeObjectTypes = map[MigrationType]string{
+	MigrationTypeTable:     "TABLE",
+	MigrationTypeView:      "VIEW",
+	MigrationTypeMView:     "MATERIALIZED VIEW",
+	MigrationTypeSequence:  "SEQUENCE",
+	MigrationTypeIndex:     "INDEX",
+	MigrationTypeTrigger:   "TRIGGER",
+	MigrationTypeFunction:  "FUNCTION",
+	MigrationTypeProcedure: "PROCEDURE",
+	MigrationTypePackage:   "PACKAGE",
+	MigrationTypeType:      "TYPE",
+	MigrationTypeDBLink:    "DATABASE LINK",
+	MigrationTypeSynonym:   "SYNONYM",
+	MigrationTypeCopy:      "TABLE",
+	MigrationTypeInsert:    "TABLE",
+}
+
+// TypeProgress 单个迁移类型的累计完成情况
+type TypeProgress struct {
+	Type     MigrationType `json:"type"`
+	Status   string        `json:"status"`
+	Counted  bool          `json:"counted"` // 是否统计到源库对象总数
+	Total    int           `json:"total"`
+	Migrated int           `json:"migrated"`
+	Percent  float64       `json:"percent"`
+	Runs     int           `json:"runs"` // 执行过该类型的运行次数
+
+	LastSuccess *time.Time      `json:"last_success,omitempty"`
+	LastRunID   string          `json:"last_run_id,omitempty"`
+	LastStatus  ExecutionStatus `json:"last_status,omitempty"`
+	LastError   string          `json:"last_error,omitempty"`
+}
+
+// ProgressSnapshot 项目整体迁移完成度快照
+type ProgressSnapshot struct {
+	Project     string    `json:"project,omitempty"`
+	Schema      string    `json:"schema,omitempty"`
+	Charset     string    `json:"charset,omitempty"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Runs        int       `json:"runs"`
+
+	FirstRun *time.Time `json:"first_run,omitempty"`
+	LastRun  *time.Time `json:"last_run,omitempty"`
+
+	// 只统计能获取到对象总数的类型
+	TotalObjects    int     `json:"total_objects"`
+	MigratedObjects int     `json:"migrated_objects"`
+	Percent         float64 `json:"percent"`
+
+	CompletedTypes int            `json:"completed_types"`
+	Types          []TypeProgress `json:"types"`
+
+	// InventoryError 源库对象统计失败的原因，此时只能按类型统计完成度
+	InventoryError string `json:"inventory_error,omitempty"`
+}
+
+// BuildProgressSnapshot 汇总历史记录生成完成度快照
+//
+// 迁移类型在任意一次运行中成功即视为该类型的对象全部迁移；从未成功的数据类型按检查点中
+// 已完成的表计算部分进度。inventory 为nil时不统计对象数量，checkpoint 可以为nil。
+func BuildProgressSnapshot(types []MigrationType, records []*HistoryRecord, inventory oracle.ObjectInventory, checkpoint *Checkpoint) *ProgressSnapshot {
+	snapshot := &ProgressSnapshot{
+		GeneratedAt: time.Now(),
+		Runs:        len(records),
+	}
+	if len(records) > 0 {
+		first, last := records[0].StartTime, records[len(records)-1].StartTime
+		snapshot.FirstRun, snapshot.LastRun = &first, &last
+	}
+
+	// 配置中的类型和历史中出现过的类型都参与统计
+	progress := make(map[MigrationType]*TypeProgress)
+	var ordered []MigrationType
+	get := func(migrationType MigrationType) *TypeProgress {
+		item, exists := progress[migrationType]
+		if !exists {
+			item = &TypeProgress{Type: migrationType, Status: ProgressStatusNotStarted}
+			progress[migrationType] = item
+			ordered = append(ordered, migrationType)
+		}
+		return item
+	}
+	for _, migrationType := range types {
+		get(migrationType)
+	}
+
+	// 历史按时间先后排列，后面的记录覆盖最近一次的状态
+	for _, record := range records {
+		for _, result := range record.Results {
+			if result.Type == "" {
+				continue
+			}
+			item := get(result.Type)
+			item.Runs++
+			item.LastRunID = record.RunID
+			item.LastStatus = result.Status
+			item.LastError = result.Error
+			if result.Status.Succeeded() {
+				end := record.EndTime
+				item.LastSuccess = &end
+			}
+		}
+	}
+
+	for _, migrationType := range OrderMigrationTypes(ordered) {
+		item := progress[migrationType]
+		if objectType, ok := migrationTypeObjectTypes[migrationType]; ok && inventory != nil {
+			item.Counted = true
+			item.Total = inventory[objectType]
+		}
+
+		switch {
+		case item.LastSuccess != nil:
+			item.Status = ProgressStatusCompleted
+			item.Migrated = item.Total
+		case checkpoint != nil && checkpoint.Types[migrationType] != nil && len(checkpoint.Types[migrationType].CompletedTables) > 0:
+			item.Status = ProgressStatusPartial
+			item.Migrated = len(checkpoint.Types[migrationType].CompletedTables)
+			if item.Counted && item.Migrated > item.Total {
+				item.Migrated = item.Total
+			}
+		case item.Runs > 0:
+			item.Status = ProgressStatusFailed
+		}
+
+		item.Percent = progressPercent(item.Status == ProgressStatusCompleted, item.Migrated, item.Total, item.Counted)
+		if item.Status == ProgressStatusCompleted {
+			snapshot.CompletedTypes++
+		}
+		if item.Counted {
+			snapshot.TotalObjects += item.Total
+			snapshot.MigratedObjects += item.Migrated
+		}
+		snapshot.Types = append(snapshot.Types, *item)
+	}
+
+	if inventory != nil {
+		snapshot.Percent = progressPercent(false, snapshot.MigratedObjects, snapshot.TotalObjects, true)
+	} else if len(snapshot.Types) > 0 {
+		// 没有对象总数时按类型计算
+		snapshot.Percent = progressPercent(false, snapshot.CompletedTypes, len(snapshot.Types), true)
+	}
+	return snapshot
+}
+
+// progressPercent 计算百分比，保留一位小数；没有对象的类型成功后视为100%
+func progressPercent(completed bool, done, total int, counted bool) float64 {
+	if !counted || total == 0 {
+		if completed {
+			return 100
+		}
+		return 0
+	}
+	return float64(done*1000/total) / 10
+}
+
+// FormatText 以文本形式输出快照，用于汇报
+func (s *ProgressSnapshot) FormatText() string {
+	var b strings.Builder
+	if s.Project != "" {
+		fmt.Fprintf(&b, "项目: %s\n", s.Project)
+	}
+	if s.Schema != "" {
+		fmt.Fprintf(&b, "源库Schema: %s\n", s.Schema)
+	}
+	if s.Charset != "" {
+		fmt.Fprintf(&b, "源库字符集: %s\n", s.Charset)
+	}
+	fmt.Fprintf(&b, "生成时间: %s\n", s.GeneratedAt.Format("2006-01-02 15:04:05"))
+	if s.FirstRun != nil {
+		fmt.Fprintf(&b, "运行记录: %d 次（%s 至 %s）\n", s.Runs,
+			s.FirstRun.Format("2006-01-02"), s.LastRun.Format("2006-01-02"))
+	} else {
+		b.WriteString("运行记录: 暂无\n")
+	}
+	b.WriteString("\n")
+
+	for _, item := range s.Types {
+		fmt.Fprintf(&b, "%s %-10s %s", progressStatusIcon(item.Status), item.Type, progressBar(item.Percent))
+		if item.Counted {
+			fmt.Fprintf(&b, " %5.1f%% (%d/%d)", item.Percent, item.Migrated, item.Total)
+		} else {
+			fmt.Fprintf(&b, " %5.1f%%", item.Percent)
+		}
+		switch {
+		case item.LastSuccess != nil:
+			fmt.Fprintf(&b, "  最近成功: %s", item.LastSuccess.Format("2006-01-02 15:04"))
+		case item.Status == ProgressStatusPartial:
+			b.WriteString("  部分完成（检查点）")
+		case item.Status == ProgressStatusFailed:
+			fmt.Fprintf(&b, "  尚未成功（执行 %d 次）", item.Runs)
+		default:
+			b.WriteString("  未执行")
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if s.counted() {
+		fmt.Fprintf(&b, "整体完成度: %.1f%%（%d/%d 个对象，%d/%d 个类型已完成）\n",
+			s.Percent, s.MigratedObjects, s.TotalObjects, s.CompletedTypes, len(s.Types))
+	} else {
+		fmt.Fprintf(&b, "整体完成度: %.1f%%（%d/%d 个类型已完成，未统计源库对象数量）\n",
+			s.Percent, s.CompletedTypes, len(s.Types))
+	}
+	if s.InventoryError != "" {
+		fmt.Fprintf(&b, "⚠️ %s\n", s.InventoryError)
+	}
+	return b.String()
+}
+
+// counted 是否统计了源库对象数量
+func (s *ProgressSnapshot) counted() bool {
+	for _, item := range s.Types {
+		if item.Counted {
+			return true
+		}
+	}
+	return false
+}
+
+// progressStatusIcon 获取进度状态图标
+func progressStatusIcon(status string) string {
+	switch status {
+	case ProgressStatusCompleted:
+		return "✅"
+	case ProgressStatusPartial:
+		return "🔄"
+	case ProgressStatusFailed:
+		return "❌"
+	default:
+		return "⏸️"
+	}
+}
+
+// progressBar 生成20格的文本进度条
+func progressBar(percent float64) string {
+	filled := int(percent / 5)
+	if filled > 20 {
+		filled = 20
+	}
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", 20-filled) + "]"
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/oracle"
+)
+
+func progressRecord(start time.Time, results ...HistoryTypeResult) *HistoryRecord {
+	return &HistoryRecord{
+		RunID:     start.Format("150405"),
+		StartTime: start,
+		EndTime:   start.Add(time.Hour),
+		Results:   results,
+	}
+}
+
+func TestBuildProgressSnapshot(t *testing.T) {
+	day1 := time.Date(2024, 3, 1, 22, 0, 0, 0, time.Local)
+	day2 := day1.AddDate(0, 0, 1)
+	records := []*HistoryRecord{
+		progressRecord(day1,
+			HistoryTypeResult{Type: MigrationTypeTable, Status: StatusCompleted},
+			HistoryTypeResult{Type: MigrationTypeView, Status: StatusFailed, Error: "boom"}),
+		// 第二次运行TABLE失败，但之前已成功过
+		progressRecord(day2,
+			HistoryTypeResult{Type: MigrationTypeTable, Status: StatusFailed},
+			HistoryTypeResult{Type: MigrationTypeCopy, Status: StatusFailed}),
+	}
+	inventory := oracle.ObjectInventory{"TABLE": 10, "VIEW": 2, "SEQUENCE": 0, "INDEX": 8}
+	checkpoint := NewCheckpoint()
+	checkpoint.MarkTableCompleted(MigrationTypeCopy, "EMP")
+	checkpoint.MarkTableCompleted(MigrationTypeCopy, "DEPT")
+
+	snapshot := BuildProgressSnapshot(
+		[]MigrationType{MigrationTypeIndex, MigrationTypeTable, MigrationTypeView, MigrationTypeSequence},
+		records, inventory, checkpoint)
+
+	require.Len(t, snapshot.Types, 5)
+	byType := make(map[MigrationType]TypeProgress)
+	for _, item := range snapshot.Types {
+		byType[item.Type] = item
+	}
+	// 按建议的执行顺序排列
+	assert.Equal(t, MigrationTypeTable, snapshot.Types[0].Type)
+
+	table := byType[MigrationTypeTable]
+	assert.Equal(t, ProgressStatusCompleted, table.Status)
+	assert.Equal(t, 10, table.Migrated)
+	assert.Equal(t, float64(100), table.Percent)
+	assert.Equal(t, 2, table.Runs)
+	assert.Equal(t, StatusFailed, table.LastStatus)
+	require.NotNil(t, table.LastSuccess)
+	assert.Equal(t, day1.Add(time.Hour), *table.LastSuccess)
+
+	view := byType[MigrationTypeView]
+	assert.Equal(t, ProgressStatusFailed, view.Status)
+	assert.Equal(t, "boom", view.LastError)
+	assert.Zero(t, view.Percent)
+
+	copyProgress := byType[MigrationTypeCopy]
+	assert.Equal(t, ProgressStatusPartial, copyProgress.Status)
+	assert.Equal(t, 2, copyProgress.Migrated)
+	assert.Equal(t, float64(20), copyProgress.Percent)
+
+	assert.Equal(t, ProgressStatusNotStarted, byType[MigrationTypeIndex].Status)
+	assert.Equal(t, ProgressStatusNotStarted, byType[MigrationTypeSequence].Status)
+
+	// TABLE 10 + VIEW 2 + SEQUENCE 0 + INDEX 8 + COPY 10
+	assert.Equal(t, 30, snapshot.TotalObjects)
+	assert.Equal(t, 12, snapshot.MigratedObjects)
+	assert.Equal(t, float64(40), snapshot.Percent)
+	assert.Equal(t, 1, snapshot.CompletedTypes)
+	assert.Equal(t, 2, snapshot.Runs)
+
+	text := snapshot.FormatText()
+	assert.Contains(t, text, "整体完成度: 40.0%（12/30 个对象，1/5 个类型已完成）")
+	assert.Contains(t, text, "部分完成（检查点）")
+}
+
+func TestBuildProgressSnapshotLinksAndSynonyms(t *testing.T) {
+	start := time.Date(2024, 3, 1, 22, 0, 0, 0, time.Local)
+	records := []*HistoryRecord{progressRecord(start,
+		HistoryTypeResult{Type: MigrationTypeDBLink, Status: StatusCompleted})}
+	inventory := oracle.ObjectInventory{"DATABASE LINK": 3, "SYNONYM": 7}
+
+	snapshot := BuildProgressSnapshot([]MigrationType{MigrationTypeSynonym, MigrationTypeDBLink}, records, inventory, nil)
+	byType := make(map[MigrationType]TypeProgress)
+	for _, item := range snapshot.Types {
+		byType[item.Type] = item
+	}
+	assert.True(t, byType[MigrationTypeDBLink].Counted)
+	assert.Equal(t, 3, byType[MigrationTypeDBLink].Migrated)
+	assert.True(t, byType[MigrationTypeSynonym].Counted)
+	assert.Equal(t, 7, byType[MigrationTypeSynonym].Total)
+	assert.Equal(t, 10, snapshot.TotalObjects)
+	assert.Equal(t, 3, snapshot.MigratedObjects)
+}
+
+func TestBuildProgressSnapshotWithoutInventory(t *testing.T) {
+	start := time.Date(2024, 3, 1, 22, 0, 0, 0, time.Local)
+	records := []*HistoryRecord{progressRecord(start,
+		HistoryTypeResult{Type: MigrationTypeTable, Status: StatusCompleted},
+		HistoryTypeResult{Type: MigrationTypeGrant, Status: StatusCompleted})}
+
+	snapshot := BuildProgressSnapshot([]MigrationType{MigrationTypeTable, MigrationTypeView}, records, nil, nil)
+	assert.Zero(t, snapshot.TotalObjects)
+	// 3个类型中2个已完成
+	assert.Equal(t, 66.6, snapshot.Percent)
+	for _, item := range snapshot.Types {
+		assert.False(t, item.Counted)
+	}
+	assert.Contains(t, snapshot.FormatText(), "未统计源库对象数量")
+
+	empty := BuildProgressSnapshot(nil, nil, nil, nil)
+	assert.Empty(t, empty.Types)
+	assert.Zero(t, empty.Percent)
+	assert.Contains(t, empty.FormatText(), "运行记录: 暂无")
+}
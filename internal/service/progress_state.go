@@ -0,0 +1,263 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// DefaultProgressStatePath 迁移进度状态文件（相对于项目根目录），监控端从中恢复进度显示
+var DefaultProgressStatePath = filepath.Join(".ora2pg-admin", "progress.json")
+
+// ProgressStateInterval 写入进度状态文件的最小间隔，步骤开始、完成和结束时立即写入
+const ProgressStateInterval = time.Second
+
+// progressStateVersion 进度状态文件的格式版本
+const progressStateVersion = 1
+
+// MigrationTypeProgress 一个迁移类型的执行状态
+type MigrationTypeProgress struct {
+	Type   MigrationType   `json:"type"`
+	Status ExecutionStatus `json:"status"`
+}
+
+// MigrationProgress 迁移服务的执行状态，与进度一起写入状态文件
+type MigrationProgress struct {
+	CurrentType    MigrationType           `json:"current_type,omitempty"`
+	CurrentPhase   MigrationPhase          `json:"current_phase,omitempty"`
+	CompletedSteps int                     `json:"completed_steps"`
+	Types          []MigrationTypeProgress `json:"types,omitempty"`
+}
+
+// ProgressState 写入状态文件的进度，与内存中的进度跟踪器一致（最多滞后 ProgressStateInterval）
+type ProgressState struct {
+	Version    int     `json:"version"`
+	RunID      string  `json:"run_id"`
+	PID        int     `json:"pid"`
+	Host       string  `json:"host"`
+	Task       string  `json:"task"`
+	Step       int     `json:"step"`
+	TotalSteps int     `json:"total_steps"`
+	Message    string  `json:"message"`
+	Details    string  `json:"details,omitempty"`
+	Percentage float64 `json:"percentage"`
+	Resources  string  `json:"resources,omitempty"`
+	// Running 为 false 时迁移已结束
+	Running   bool               `json:"running"`
+	StartedAt time.Time          `json:"started_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+	EndedAt   *time.Time         `json:"ended_at,omitempty"`
+	Migration *MigrationProgress `json:"migration,omitempty"`
+	// Seq 每次写入递增，监控端据此判断进度是否有更新
+	Seq uint64 `json:"seq"`
+}
+
+// State 迁移状态：running；已结束时有未成功的迁移类型为 failed，否则为 completed；
+// 记录为运行中但本机进程已不存在时为 lost
+func (s *ProgressState) State() string {
+	if !s.Running {
+		if s.Migration != nil {
+			for _, progress := range s.Migration.Types {
+				if !progress.Status.Succeeded() {
+					return RunStateFailed
+				}
+			}
+		}
+		return RunStateCompleted
+	}
+	host, _ := os.Hostname()
+	if s.PID > 0 && s.Host == host && !processAlive(s.PID) {
+		return RunStateLost
+	}
+	return RunStateRunning
+}
+
+// Elapsed 已运行时间，结束后为总耗时
+func (s *ProgressState) Elapsed() time.Duration {
+	end := time.Now()
+	if s.EndedAt != nil {
+		end = *s.EndedAt
+	}
+	return end.Sub(s.StartedAt)
+}
+
+// EstimatedRemaining 按当前进度估算的剩余时间，无法估算时为0
+func (s *ProgressState) EstimatedRemaining() time.Duration {
+	if !s.Running || s.Percentage <= 0 || s.Percentage >= 100 {
+		return 0
+	}
+	elapsed := s.UpdatedAt.Sub(s.StartedAt)
+	remaining := time.Duration(float64(elapsed)/s.Percentage*100) - time.Since(s.StartedAt)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// LoadProgressState 读取进度状态文件，文件损坏时改为读取上一次写入的备份
+func LoadProgressState(path string) (*ProgressState, error) {
+	state, err := readProgressState(path)
+	if err == nil {
+		return state, nil
+	}
+	if backup, backupErr := readProgressState(path + ".bak"); backupErr == nil {
+		utils.GetGlobalLogger().Warnf("进度状态文件 %s 无法读取（%v），使用上一次写入的备份", path, err)
+		return backup, nil
+	}
+	if os.IsNotExist(err) {
+		return nil, utils.NewError(utils.ErrorTypeUser, "PROGRESS_STATE_NOT_FOUND").
+			Message("没有正在执行或最近执行的迁移").
+			Details(fmt.Sprintf("进度状态文件 %s 不存在", path)).
+			Suggestion("迁移开始后会自动写入进度状态文件，如: ora2pg-admin 迁移 数据").
+			Build()
+	}
+	return nil, utils.NewError(utils.ErrorTypeFile, "PROGRESS_STATE_INVALID").
+		Message("进度状态文件已损坏").
+		Details(fmt.Sprintf("%s: %v", path, err)).
+		Cause(err).
+		Suggestion("迁移进程下次写入时会覆盖该文件，稍后重试；迁移已结束时可删除该文件").
+		Build()
+}
+
+// readProgressState 读取并校验一个进度状态文件
+func readProgressState(path string) (*ProgressState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	state := &ProgressState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	if state.Version != progressStateVersion || state.StartedAt.IsZero() {
+		return nil, fmt.Errorf("不是有效的进度状态（版本 %d）", state.Version)
+	}
+	return state, nil
+}
+
+// progressStateWriter 节流写入进度状态文件
+type progressStateWriter struct {
+	path string
+	seq  uint64
+	last time.Time
+	// dirty 有被节流跳过的更新，由显示协程定期补写
+	dirty bool
+}
+
+// write 写入进度状态，force 为 false 且距上次写入不足 ProgressStateInterval 时跳过
+//
+// 先写临时文件再重命名，原文件保留为 .bak，读取方不会读到写了一半的内容。写入失败只记录警告。
+func (w *progressStateWriter) write(state ProgressState, force bool) {
+	now := time.Now()
+	if !force && now.Sub(w.last) < ProgressStateInterval {
+		w.dirty = true
+		return
+	}
+	w.seq++
+	w.last = now
+	w.dirty = false
+	state.Seq = w.seq
+	state.UpdatedAt = now
+
+	if err := writeProgressState(w.path, &state); err != nil {
+		utils.GetGlobalLogger().Warnf("写入进度状态文件失败: %v", err)
+	}
+}
+
+// writeProgressState 原子地写入进度状态文件
+func writeProgressState(path string, state *ProgressState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	os.Remove(path + ".bak")
+	os.Rename(path, path+".bak")
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// SetStatePath 设置进度状态文件，进度定期写入该文件，需在 Start 之前调用
+func (pt *ProgressTracker) SetStatePath(path string) {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	pt.stateWriter = &progressStateWriter{path: path}
+}
+
+// SetMigrationProgress 更新迁移服务的执行状态，随下一次进度更新写入状态文件
+func (pt *ProgressTracker) SetMigrationProgress(progress MigrationProgress) {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	pt.migration = &progress
+}
+
+// persistState 写入当前进度（调用方需持有锁）
+func (pt *ProgressTracker) persistState(force bool) {
+	if pt.stateWriter == nil {
+		return
+	}
+	host, _ := os.Hostname()
+	state := ProgressState{
+		Version:    progressStateVersion,
+		RunID:      utils.RunID(),
+		PID:        os.Getpid(),
+		Host:       host,
+		Task:       pt.taskName,
+		Step:       pt.currentStep,
+		TotalSteps: pt.totalSteps,
+		Message:    pt.currentMessage,
+		Details:    pt.currentDetails,
+		Percentage: pt.percentage,
+		Resources:  pt.resourceInfo,
+		Running:    pt.isRunning,
+		StartedAt:  pt.startTime,
+	}
+	if pt.migration != nil {
+		migration := *pt.migration
+		migration.Types = append([]MigrationTypeProgress(nil), pt.migration.Types...)
+		state.Migration = &migration
+	}
+	if !pt.isRunning {
+		now := time.Now()
+		state.EndedAt = &now
+	}
+	pt.stateWriter.write(state, force)
+}
+
+// flushState 补写被节流跳过的更新，保证状态文件最终与内存一致
+func (pt *ProgressTracker) flushState() {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	if pt.isRunning && pt.stateWriter != nil && pt.stateWriter.dirty {
+		pt.persistState(true)
+	}
+}
+
+// syncProgressState 把迁移服务的执行状态同步到进度跟踪器，在迁移类型开始和结束时调用
+func (ms *MigrationService) syncProgressState(progressTracker *ProgressTracker, migrationTypes []MigrationType, results []*ExecutionResult) {
+	progress := MigrationProgress{
+		CurrentType:    ms.state.CurrentType,
+		CurrentPhase:   ms.state.CurrentPhase,
+		CompletedSteps: ms.state.CompletedSteps,
+	}
+	for i, result := range results {
+		if i >= len(migrationTypes) {
+			break
+		}
+		progress.Types = append(progress.Types, MigrationTypeProgress{Type: migrationTypes[i], Status: result.Status})
+	}
+	progressTracker.SetMigrationProgress(progress)
+}
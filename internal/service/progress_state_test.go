@@ -0,0 +1,97 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/utils"
+)
+
+func TestProgressStatePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".ora2pg-admin", "progress.json")
+	pt := NewProgressTracker()
+	pt.SetQuiet(true)
+	pt.SetStatePath(path)
+
+	pt.Start("数据迁移", 2)
+	state, err := LoadProgressState(path)
+	require.NoError(t, err)
+	assert.True(t, state.Running)
+	assert.Equal(t, "数据迁移", state.Task)
+	assert.Equal(t, os.Getpid(), state.PID)
+	assert.Equal(t, utils.RunID(), state.RunID)
+	assert.Equal(t, RunStateRunning, state.State())
+
+	// 步骤开始时立即写入，同一秒内的百分比更新被节流，随后补写
+	pt.SetMigrationProgress(MigrationProgress{CurrentType: MigrationTypeCopy, CurrentPhase: PhaseData})
+	pt.UpdateStep(1, "执行 COPY 迁移")
+	pt.UpdateProgress(30, "EMPLOYEES 3000/10000")
+	state, err = LoadProgressState(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, state.Step)
+	assert.Equal(t, 0.0, state.Percentage)
+	require.NotNil(t, state.Migration)
+	assert.Equal(t, MigrationTypeCopy, state.Migration.CurrentType)
+	seq := state.Seq
+
+	pt.flushState()
+	state, err = LoadProgressState(path)
+	require.NoError(t, err)
+	assert.Equal(t, 30.0, state.Percentage)
+	assert.Equal(t, "EMPLOYEES 3000/10000", state.Details)
+	assert.Greater(t, state.Seq, seq)
+
+	// 没有被跳过的更新时不重复写入
+	seq = state.Seq
+	pt.flushState()
+	state, err = LoadProgressState(path)
+	require.NoError(t, err)
+	assert.Equal(t, seq, state.Seq)
+
+	pt.SetMigrationProgress(MigrationProgress{CompletedSteps: 1, Types: []MigrationTypeProgress{{Type: MigrationTypeCopy, Status: StatusFailed}}})
+	pt.CompleteStep(1, "COPY 迁移失败")
+	pt.Stop()
+	state, err = LoadProgressState(path)
+	require.NoError(t, err)
+	assert.False(t, state.Running)
+	require.NotNil(t, state.EndedAt)
+	assert.Equal(t, 50.0, state.Percentage)
+	assert.Empty(t, state.Details)
+	assert.Equal(t, RunStateFailed, state.State())
+	assert.Zero(t, state.EstimatedRemaining())
+	assert.NoFileExists(t, path+".tmp")
+}
+
+func TestLoadProgressStateCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.json")
+	_, err := LoadProgressState(path)
+	assert.Equal(t, "PROGRESS_STATE_NOT_FOUND", utils.GetErrorCode(err))
+
+	host, _ := os.Hostname()
+	state := &ProgressState{Version: progressStateVersion, PID: os.Getpid(), Host: host, Task: "迁移", Running: true, StartedAt: time.Now()}
+	require.NoError(t, writeProgressState(path, state))
+	state.Percentage = 40
+	require.NoError(t, writeProgressState(path, state))
+
+	// 文件损坏时使用上一次写入的备份
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 1, "task": "迁`), 0644))
+	loaded, err := LoadProgressState(path)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, loaded.Percentage)
+
+	require.NoError(t, os.WriteFile(path+".bak", []byte(`{}`), 0644))
+	_, err = LoadProgressState(path)
+	assert.Equal(t, "PROGRESS_STATE_INVALID", utils.GetErrorCode(err))
+
+	// 记录为运行中但进程已不存在
+	state.PID = exitedPID(t)
+	require.NoError(t, writeProgressState(path, state))
+	loaded, err = LoadProgressState(path)
+	require.NoError(t, err)
+	assert.Equal(t, RunStateLost, loaded.State())
+}
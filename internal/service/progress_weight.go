@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/utils"
+)
+
+// DataRowsPerWeight 数据迁移类型中折算为一个对象权重的行数
+const DataRowsPerWeight = 10000
+
+// progressObjectTypes 迁移类型对应的源库对象类型，用对象数量作为权重
+var progressObjectTypes = map[MigrationType]string{
+	MigrationTypeTable:     "TABLE",
+	MigrationTypeView:      "VIEW",
+	MigrationTypeMView:     "MATERIALIZED VIEW",
+	MigrationTypeSequence:  "SEQUENCE",
+	MigrationTypeIndex:     "INDEX",
+	MigrationTypeTrigger:   "TRIGGER",
+	MigrationTypeFunction:  "FUNCTION",
+	MigrationTypeProcedure: "PROCEDURE",
+	MigrationTypePackage:   "PACKAGE",
+	MigrationTypeType:      "TYPE",
+	MigrationTypeDBLink:    "DATABASE LINK",
+	MigrationTypeSynonym:   "SYNONYM",
+}
+
+// EstimateMigrationScale 从源库的对象统计和表统计信息估算迁移规模，用于按工作量加权计算进度
+func EstimateMigrationScale(ctx context.Context, cfg *config.ProjectConfig) (*oracle.SchemaScale, error) {
+	if field, value, found := config.DumpFileReference(&cfg.Oracle); found {
+		return nil, utils.NewError(utils.ErrorTypeConfig, "SCALE_UNAVAILABLE").
+			Message("源数据是dump文件，无法从源库估算迁移规模").
+			Details(field + ": " + value).
+			Build()
+	}
+
+	schema := cfg.Oracle.Schema
+	if schema == "" {
+		schema = cfg.Oracle.Username
+	}
+	runner := oracle.NewSQLPlusRunner(&cfg.Oracle, &cfg.OracleClient)
+	return oracle.NewInspector(runner, schema).Scale(ctx)
+}
+
+// ProgressWeights 按迁移规模计算各迁移类型的进度权重
+//
+// 对象类型按源库中的对象数量计算，COPY/INSERT 按表数量加上每 DataRowsPerWeight 行折算一个对象计算，
+// 至少为1；无法估算的类型（如 GRANT）取可估算类型的平均权重。scale 为空或没有可估算的类型时返回 nil，按等权计算。
+func ProgressWeights(migrationTypes []MigrationType, scale *oracle.SchemaScale) []float64 {
+	if scale == nil || len(migrationTypes) == 0 {
+		return nil
+	}
+
+	weights := make([]float64, len(migrationTypes))
+	known := make([]bool, len(migrationTypes))
+	var knownTotal float64
+	knownCount := 0
+	for i, migrationType := range migrationTypes {
+		weight, ok := typeWeight(migrationType, scale)
+		if !ok {
+			continue
+		}
+		if weight < 1 {
+			weight = 1
+		}
+		weights[i], known[i] = weight, true
+		knownTotal += weight
+		knownCount++
+	}
+	if knownCount == 0 {
+		return nil
+	}
+
+	average := knownTotal / float64(knownCount)
+	for i := range weights {
+		if !known[i] {
+			weights[i] = average
+		}
+	}
+	return weights
+}
+
+// typeWeight 单个迁移类型的规模，无法估算时返回 false
+func typeWeight(migrationType MigrationType, scale *oracle.SchemaScale) (float64, bool) {
+	if isDataMigrationType(migrationType) {
+		if scale.Data == nil {
+			return 0, false
+		}
+		return float64(scale.Data.Tables) + float64(scale.Data.Rows)/DataRowsPerWeight, true
+	}
+	objectType, ok := progressObjectTypes[migrationType]
+	if !ok || scale.Objects == nil {
+		return 0, false
+	}
+	count, ok := scale.Objects[objectType]
+	return float64(count), ok
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/utils"
+)
+
+func TestProgressWeights(t *testing.T) {
+	scale := &oracle.SchemaScale{
+		Objects: oracle.ObjectInventory{"TABLE": 40, "VIEW": 0, "SEQUENCE": 10},
+		Data:    &oracle.DataSize{Tables: 40, Rows: 1600000},
+	}
+	types := []MigrationType{MigrationTypeTable, MigrationTypeView, MigrationTypeSequence, MigrationTypeCopy, MigrationTypeGrant}
+	// 没有对象的类型权重为1，GRANT 无法估算，取其他类型的平均值
+	assert.Equal(t, []float64{40, 1, 10, 200, 62.75}, ProgressWeights(types, scale))
+
+	// 没有数据量估算时，数据类型同样按平均值计算
+	assert.Equal(t, []float64{40, 40}, ProgressWeights([]MigrationType{MigrationTypeTable, MigrationTypeCopy},
+		&oracle.SchemaScale{Objects: scale.Objects}))
+
+	// 无法估算时按等权计算
+	assert.Nil(t, ProgressWeights(types, nil))
+	assert.Nil(t, ProgressWeights([]MigrationType{MigrationTypeGrant}, scale))
+}
+
+func TestProgressWeightsLinksAndSynonyms(t *testing.T) {
+	scale := &oracle.SchemaScale{Objects: oracle.ObjectInventory{"TABLE": 40, "DATABASE LINK": 2, "SYNONYM": 30}}
+	types := []MigrationType{MigrationTypeDBLink, MigrationTypeTable, MigrationTypeSynonym}
+	assert.Equal(t, []float64{2, 40, 30}, ProgressWeights(types, scale))
+}
+
+func TestProgressTrackerStepWeights(t *testing.T) {
+	var updates []ProgressUpdate
+	tracker := NewProgressTracker()
+	tracker.SetUpdateHandler(func(update ProgressUpdate) { updates = append(updates, update) })
+	tracker.Start("测试", 3)
+	tracker.SetStepWeights([]float64{1, 1, 8})
+	for step := 1; step <= 3; step++ {
+		tracker.UpdateStep(step, "执行")
+		tracker.CompleteStep(step, "完成")
+	}
+	tracker.Stop()
+
+	var percentages []float64
+	for _, update := range updates {
+		percentages = append(percentages, update.Percentage)
+	}
+	// 步骤开始时显示之前步骤完成的进度，小步骤完成后不会显示接近完成
+	assert.Equal(t, []float64{0, 10, 10, 20, 20, 100}, percentages)
+
+	// 权重个数与步骤数不符时按等权计算
+	updates = nil
+	tracker = NewProgressTracker()
+	tracker.SetUpdateHandler(func(update ProgressUpdate) { updates = append(updates, update) })
+	tracker.Start("测试", 4)
+	tracker.SetStepWeights([]float64{1, 9})
+	tracker.CompleteStep(1, "完成")
+	tracker.Stop()
+	require.Len(t, updates, 1)
+	assert.Equal(t, 25.0, updates[0].Percentage)
+}
+
+func TestEstimateMigrationScaleDumpFile(t *testing.T) {
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("dump")
+	cfg := manager.GetConfig()
+	cfg.Oracle.Host = "/data/export/full.dmp"
+
+	_, err := EstimateMigrationScale(context.Background(), cfg)
+	assert.Equal(t, "SCALE_UNAVAILABLE", utils.GetErrorCode(err))
+}
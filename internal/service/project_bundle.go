@@ -0,0 +1,277 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// ProjectBundleFormatVersion 项目包格式版本，格式不兼容时递增
+const ProjectBundleFormatVersion = 1
+
+// projectBundleRoot 项目包内的根目录名
+const projectBundleRoot = "ora2pg-project"
+
+// projectManifestFile 项目包清单文件名
+const projectManifestFile = "manifest.json"
+
+// projectConfigPath 项目包中配置文件的相对路径，项目使用JSON配置时导出后也统一为YAML
+var projectConfigPath = filepath.Join(config.ProjectConfigDir, config.FormatYAML.FileName())
+
+// projectBundleDirs 随项目导出的目录，logs/output/backup 及运行状态不导出
+var projectBundleDirs = []string{"scripts", "templates", "docs"}
+
+// projectBundleFiles 随项目导出的单个文件
+var projectBundleFiles = []string{"README.md", ".gitignore"}
+
+// projectRuntimeDirs 导入后需要创建的空目录
+var projectRuntimeDirs = []string{"logs", "output", "backup"}
+
+// ProjectManifest 项目包清单
+type ProjectManifest struct {
+	FormatVersion int       `json:"format_version"`
+	ToolVersion   string    `json:"tool_version"`
+	ProjectName   string    `json:"project_name"`
+	ExportedAt    time.Time `json:"exported_at"`
+	Files         []string  `json:"files"`
+}
+
+// ExportProject 把项目配置和自定义脚本打包为 .tar.gz，配置中的密码会被清除
+func ExportProject(projectDir, archivePath, toolVersion string) (*ProjectManifest, error) {
+	fileUtils := utils.NewFileUtils()
+
+	configPath := config.ProjectConfigPath(projectDir)
+	if !fileUtils.FileExists(configPath) {
+		return nil, utils.ConfigErrors.FileNotFound(configPath)
+	}
+	cfg, err := config.ReadConfigFile(configPath)
+	if err != nil {
+		return nil, utils.ConfigErrors.ParseFailed(err)
+	}
+
+	stagingParent, err := os.MkdirTemp("", "ora2pg-export-")
+	if err != nil {
+		return nil, utils.FileErrors.CreateFailed(os.TempDir(), err)
+	}
+	defer os.RemoveAll(stagingParent)
+	staging := filepath.Join(stagingParent, projectBundleRoot)
+
+	manifest := &ProjectManifest{
+		FormatVersion: ProjectBundleFormatVersion,
+		ToolVersion:   toolVersion,
+		ProjectName:   cfg.Project.Name,
+		ExportedAt:    time.Now(),
+	}
+
+	// 配置脱敏后写入
+	if err := config.WriteConfigFile(filepath.Join(staging, projectConfigPath), config.StripSecrets(cfg)); err != nil {
+		return nil, err
+	}
+	manifest.Files = append(manifest.Files, filepath.ToSlash(projectConfigPath))
+
+	for _, dir := range projectBundleDirs {
+		files, err := copyProjectTree(filepath.Join(projectDir, dir), filepath.Join(staging, dir), dir)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, files...)
+	}
+	for _, name := range projectBundleFiles {
+		src := filepath.Join(projectDir, name)
+		if !fileUtils.FileExists(src) {
+			continue
+		}
+		if err := fileUtils.CopyFile(src, filepath.Join(staging, name)); err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, name)
+	}
+	sort.Strings(manifest.Files)
+
+	if err := writeProjectManifest(filepath.Join(staging, projectManifestFile), manifest); err != nil {
+		return nil, err
+	}
+	if _, err := fileUtils.ArchiveDir(staging, archivePath); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// ImportProject 把项目包恢复到目标目录
+//
+// 目标目录非空时需要 force 才会覆盖；覆盖已有项目时保留其本地配置的密码。
+func ImportProject(archivePath, targetDir string, force bool) (*ProjectManifest, error) {
+	fileUtils := utils.NewFileUtils()
+
+	extractDir, err := os.MkdirTemp("", "ora2pg-import-")
+	if err != nil {
+		return nil, utils.FileErrors.CreateFailed(os.TempDir(), err)
+	}
+	defer os.RemoveAll(extractDir)
+
+	if err := fileUtils.ExtractArchive(archivePath, extractDir); err != nil {
+		return nil, err
+	}
+	root := filepath.Join(extractDir, projectBundleRoot)
+
+	manifest, err := readProjectManifest(filepath.Join(root, projectManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	if manifest.FormatVersion > ProjectBundleFormatVersion {
+		return nil, utils.NewError(utils.ErrorTypeConfig, "PROJECT_BUNDLE_UNSUPPORTED").
+			Message("项目包由更新版本的 ora2pg-admin 导出，当前版本无法导入").
+			Details(fmt.Sprintf("项目包格式: %d，当前支持: %d（导出工具版本: %s）",
+				manifest.FormatVersion, ProjectBundleFormatVersion, manifest.ToolVersion)).
+			Suggestion("请升级 ora2pg-admin 后再导入").
+			Build()
+	}
+
+	// 旧版本导出的配置缺失的字段由默认值补齐
+	bundleConfigPath := filepath.Join(root, projectConfigPath)
+	cfg, err := config.ReadConfigFile(bundleConfigPath)
+	if err != nil {
+		return nil, utils.ConfigErrors.ParseFailed(err)
+	}
+
+	targetConfigPath := config.ProjectConfigPath(targetDir)
+	if !isEmptyDir(targetDir) {
+		if !force {
+			return nil, utils.NewError(utils.ErrorTypeFile, "PROJECT_DIR_EXISTS").
+				Message("目标目录已存在且不为空").
+				Details(targetDir).
+				Suggestion("指定新的目标目录").
+				Suggestion("使用 --force 覆盖目标目录中的同名文件").
+				Build()
+		}
+		if existing, err := config.ReadConfigFile(targetConfigPath); err == nil {
+			restoreSecrets(cfg, existing)
+		}
+	}
+
+	if err := fileUtils.EnsureDir(targetDir); err != nil {
+		return nil, utils.FileErrors.CreateFailed(targetDir, err)
+	}
+	for _, dir := range projectBundleDirs {
+		if _, err := copyProjectTree(filepath.Join(root, dir), filepath.Join(targetDir, dir), dir); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range projectBundleFiles {
+		src := filepath.Join(root, name)
+		if !fileUtils.FileExists(src) {
+			continue
+		}
+		if err := fileUtils.CopyFile(src, filepath.Join(targetDir, name)); err != nil {
+			return nil, err
+		}
+	}
+	for _, dir := range projectRuntimeDirs {
+		if err := fileUtils.EnsureDir(filepath.Join(targetDir, dir)); err != nil {
+			return nil, utils.FileErrors.CreateFailed(dir, err)
+		}
+	}
+
+	if err := config.WriteConfigFile(targetConfigPath, cfg); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// restoreSecrets 用目标目录已有配置中的密码补齐导入的配置
+func restoreSecrets(imported, existing *config.ProjectConfig) {
+	pairs := [][2]*string{
+		{&imported.Oracle.Password, &existing.Oracle.Password},
+		{&imported.Oracle.TestPassword, &existing.Oracle.TestPassword},
+		{&imported.PostgreSQL.Password, &existing.PostgreSQL.Password},
+		{&imported.PostgreSQL.TestPassword, &existing.PostgreSQL.TestPassword},
+		{&imported.Notifications.Email.Password, &existing.Notifications.Email.Password},
+	}
+	for _, pair := range pairs {
+		if *pair[0] == "" {
+			*pair[0] = *pair[1]
+		}
+	}
+}
+
+// copyProjectTree 复制目录下的全部文件，返回以 prefix 开头的相对路径列表；源目录不存在时跳过
+func copyProjectTree(srcDir, dstDir, prefix string) ([]string, error) {
+	fileUtils := utils.NewFileUtils()
+	if !fileUtils.DirExists(srcDir) {
+		return nil, nil
+	}
+
+	var files []string
+	err := filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dstDir, rel)
+		if info.IsDir() {
+			return fileUtils.EnsureDir(target)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		if err := fileUtils.CopyFile(path, target); err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(filepath.Join(prefix, rel)))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// isEmptyDir 目录不存在或为空
+func isEmptyDir(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	return err != nil || len(entries) == 0
+}
+
+// writeProjectManifest 写入项目包清单
+func writeProjectManifest(path string, manifest *ProjectManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化项目清单失败: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return utils.FileErrors.WriteFailed(path, err)
+	}
+	return nil
+}
+
+// readProjectManifest 读取项目包清单
+func readProjectManifest(path string) (*ProjectManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, utils.NewError(utils.ErrorTypeFile, "PROJECT_BUNDLE_INVALID").
+			Message("不是有效的 ora2pg-admin 项目包").
+			Details("缺少 " + projectManifestFile).
+			Cause(err).
+			Suggestion("请使用 'ora2pg-admin 项目 导出' 生成的项目包").
+			Build()
+	}
+
+	manifest := &ProjectManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, utils.NewError(utils.ErrorTypeFile, "PROJECT_BUNDLE_INVALID").
+			Message("项目包清单已损坏").
+			Details(path).
+			Cause(err).
+			Build()
+	}
+	return manifest, nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// createTestProject 创建包含配置、脚本和运行产物的测试项目
+func createTestProject(t *testing.T) (string, *config.ProjectConfig) {
+	projectDir := t.TempDir()
+
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("共享项目")
+	cfg := manager.GetConfig()
+	cfg.Oracle.Host = "ora.example.com"
+	cfg.Oracle.Username = "migrator"
+	cfg.Oracle.Password = "tiger"
+	cfg.PostgreSQL.Password = "${PG_PASSWORD}"
+	cfg.Migration.Types = []string{"TABLE", "COPY"}
+	cfg.Migration.Options = map[string]bool{"TRUNCATE_TABLE": true}
+	require.NoError(t, manager.SaveConfig(filepath.Join(projectDir, ".ora2pg-admin", "config.yaml")))
+
+	files := map[string]string{
+		"scripts/example.sql":           "SELECT 1;",
+		"scripts/post/fix.sql":          "UPDATE t SET a = 1;",
+		"README.md":                     "# 共享项目",
+		"logs/migration.log":            "log",
+		"output/TABLE.sql":              "CREATE TABLE t();",
+		".ora2pg-admin/checkpoint.json": "{}",
+	}
+	for name, content := range files {
+		path := filepath.Join(projectDir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return projectDir, cfg
+}
+
+func TestExportImportProject(t *testing.T) {
+	projectDir, original := createTestProject(t)
+	archivePath := filepath.Join(t.TempDir(), "project.tar.gz")
+
+	manifest, err := ExportProject(projectDir, archivePath, "1.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, ProjectBundleFormatVersion, manifest.FormatVersion)
+	assert.Equal(t, "共享项目", manifest.ProjectName)
+	assert.Equal(t, []string{".ora2pg-admin/config.yaml", "README.md", "scripts/example.sql", "scripts/post/fix.sql"}, manifest.Files)
+
+	// 导入到新目录
+	targetDir := filepath.Join(t.TempDir(), "imported")
+	imported, err := ImportProject(archivePath, targetDir, false)
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.0", imported.ToolVersion)
+
+	cfg, err := config.ReadConfigFile(filepath.Join(targetDir, ".ora2pg-admin", "config.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, original.Project.Name, cfg.Project.Name)
+	assert.Equal(t, original.Oracle.Host, cfg.Oracle.Host)
+	assert.Equal(t, original.Oracle.Username, cfg.Oracle.Username)
+	assert.Equal(t, original.Migration.Types, cfg.Migration.Types)
+	assert.Equal(t, original.Migration.Options, cfg.Migration.Options)
+
+	// 密码被清除，环境变量引用保留
+	assert.Empty(t, cfg.Oracle.Password)
+	assert.Equal(t, "${PG_PASSWORD}", cfg.PostgreSQL.Password)
+
+	content, err := os.ReadFile(filepath.Join(targetDir, "scripts", "post", "fix.sql"))
+	require.NoError(t, err)
+	assert.Equal(t, "UPDATE t SET a = 1;", string(content))
+
+	// 运行产物不导出，但会创建空目录
+	fileUtils := utils.NewFileUtils()
+	assert.False(t, fileUtils.FileExists(filepath.Join(targetDir, "logs", "migration.log")))
+	assert.False(t, fileUtils.FileExists(filepath.Join(targetDir, "output", "TABLE.sql")))
+	assert.False(t, fileUtils.FileExists(filepath.Join(targetDir, ".ora2pg-admin", "checkpoint.json")))
+	assert.True(t, fileUtils.DirExists(filepath.Join(targetDir, "output")))
+
+	// 目标目录不为空时需要 --force，覆盖时保留本地密码
+	_, err = ImportProject(archivePath, projectDir, false)
+	assert.Equal(t, "PROJECT_DIR_EXISTS", utils.GetErrorCode(err))
+
+	_, err = ImportProject(archivePath, projectDir, true)
+	require.NoError(t, err)
+	cfg, err = config.ReadConfigFile(filepath.Join(projectDir, ".ora2pg-admin", "config.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "tiger", cfg.Oracle.Password)
+}
+
+func TestImportProjectIncompatible(t *testing.T) {
+	// 更新版本的项目包格式
+	bundleDir := filepath.Join(t.TempDir(), projectBundleRoot)
+	require.NoError(t, os.MkdirAll(bundleDir, 0755))
+	require.NoError(t, writeProjectManifest(filepath.Join(bundleDir, projectManifestFile),
+		&ProjectManifest{FormatVersion: ProjectBundleFormatVersion + 1, ToolVersion: "9.0.0"}))
+	archivePath := filepath.Join(t.TempDir(), "future.tar.gz")
+	_, err := utils.NewFileUtils().ArchiveDir(bundleDir, archivePath)
+	require.NoError(t, err)
+
+	_, err = ImportProject(archivePath, filepath.Join(t.TempDir(), "target"), false)
+	assert.Equal(t, "PROJECT_BUNDLE_UNSUPPORTED", utils.GetErrorCode(err))
+
+	// 不是项目包
+	otherDir := filepath.Join(t.TempDir(), projectBundleRoot)
+	require.NoError(t, os.MkdirAll(otherDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(otherDir, "a.txt"), []byte("x"), 0644))
+	_, err = utils.NewFileUtils().ArchiveDir(otherDir, archivePath)
+	require.NoError(t, err)
+	_, err = ImportProject(archivePath, filepath.Join(t.TempDir(), "target"), false)
+	assert.Equal(t, "PROJECT_BUNDLE_INVALID", utils.GetErrorCode(err))
+}
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+)
+
+// 就绪度评分的维度
+const (
+	ReadinessObjectScale    = "object_scale"
+	ReadinessDifficultTypes = "difficult_types"
+	ReadinessPLSQL          = "plsql"
+	ReadinessStatistics     = "statistics"
+	ReadinessNaming         = "naming"
+	ReadinessLargeTables    = "large_tables"
+)
+
+// readinessDimension 评分模型中的一个维度：权重之和为100，各维度得分0-100
+type readinessDimension struct {
+	key    string
+	name   string
+	weight int
+}
+
+// readinessModel 评分模型，维度按权重从高到低排列
+var readinessModel = []readinessDimension{
+	{ReadinessDifficultTypes, "难迁移类型", 25},
+	{ReadinessPLSQL, "PL/SQL代码", 20},
+	{ReadinessObjectScale, "对象规模", 15},
+	{ReadinessStatistics, "统计信息", 15},
+	{ReadinessLargeTables, "大表数量", 15},
+	{ReadinessNaming, "命名合规", 10},
+}
+
+// 评分模型的阈值
+const (
+	readinessSmallSchema      = 500   // 对象数不超过该值时规模得满分
+	readinessLargeSchema      = 20000 // 对象数达到该值时规模得0分
+	readinessDifficultRatio   = 0.2   // 含难迁移类型的表占比达到该值时得0分
+	readinessPLSQLRatio       = 0.4   // PL/SQL对象占比达到该值时得0分
+	readinessNamingRatio      = 0.1   // 命名不合规的表占比达到该值时得0分
+	readinessLargeTablePoints = 10    // 每张大表扣除的分数
+)
+
+// plsqlObjectTypes 需要人工复核改写的PL/SQL对象类型
+var plsqlObjectTypes = []string{"PACKAGE", "PROCEDURE", "FUNCTION", "TRIGGER", "TYPE"}
+
+// unquotedNamePattern 不加引号即可在PostgreSQL中使用的Oracle表名（Oracle默认大写）
+var unquotedNamePattern = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// ReadinessScore 单个维度的评分
+type ReadinessScore struct {
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+	// Score 维度得分（0-100），Available 为 false 时不参与总分
+	Score       int      `json:"score"`
+	Available   bool     `json:"available"`
+	Summary     string   `json:"summary"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// ReadinessReport 源库迁移就绪度报告
+type ReadinessReport struct {
+	Schema      string    `json:"schema"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// Score 加权总分（0-100），越高越容易迁移
+	Score int    `json:"score"`
+	Level string `json:"level"`
+	// Complexity 迁移复杂度说明
+	Complexity string `json:"complexity"`
+	// ManualReview 预计需要人工复核的对象数：PL/SQL对象、含难迁移类型的表和嵌套表
+	ManualReview int              `json:"manual_review"`
+	Dimensions   []ReadinessScore `json:"dimensions"`
+}
+
+// readinessLevels 总分对应的就绪度等级，按分数从高到低排列
+var readinessLevels = []struct {
+	min        int
+	level      string
+	complexity string
+}{
+	{85, "就绪", "低复杂度：大部分对象可由ora2pg自动转换"},
+	{70, "基本就绪", "中等复杂度：少量对象需要人工处理"},
+	{50, "需要准备", "较高复杂度：建议先处理下列问题并预留改写时间"},
+	{0, "复杂", "高复杂度：需要较多人工改写，建议分阶段迁移"},
+}
+
+// ScoreReadiness 按评分模型计算迁移就绪度，stats 为nil表示无法获取统计信息，该维度不参与总分
+func ScoreReadiness(profile *oracle.SchemaProfile, stats *oracle.StatsFreshness) *ReadinessReport {
+	report := &ReadinessReport{
+		Schema:      profile.Schema,
+		GeneratedAt: time.Now(),
+	}
+
+	scorers := map[string]func(*ReadinessScore){
+		ReadinessObjectScale:    func(s *ReadinessScore) { scoreObjectScale(s, profile) },
+		ReadinessDifficultTypes: func(s *ReadinessScore) { scoreDifficultTypes(s, profile) },
+		ReadinessPLSQL:          func(s *ReadinessScore) { scorePLSQL(s, profile) },
+		ReadinessStatistics:     func(s *ReadinessScore) { scoreStatistics(s, stats) },
+		ReadinessNaming:         func(s *ReadinessScore) { scoreNaming(s, profile) },
+		ReadinessLargeTables:    func(s *ReadinessScore) { scoreLargeTables(s, profile) },
+	}
+
+	weighted, weights := 0.0, 0
+	for _, dimension := range readinessModel {
+		score := ReadinessScore{Key: dimension.key, Name: dimension.name, Weight: dimension.weight, Available: true}
+		scorers[dimension.key](&score)
+		if score.Available {
+			weighted += float64(score.Score * dimension.weight)
+			weights += dimension.weight
+		}
+		report.Dimensions = append(report.Dimensions, score)
+	}
+	// 部分维度无法评估时按其余维度的权重折算
+	if weights > 0 {
+		report.Score = int(math.Round(weighted / float64(weights)))
+	}
+	for _, level := range readinessLevels {
+		if report.Score >= level.min {
+			report.Level = level.level
+			report.Complexity = level.complexity
+			break
+		}
+	}
+	report.ManualReview = plsqlObjectCount(profile.Objects) + profile.DifficultTables + profile.NestedTables
+	return report
+}
+
+// linearScore 值不超过 good 时得100分，达到 bad 时得0分，之间线性递减
+func linearScore(value, good, bad float64) int {
+	switch {
+	case value <= good:
+		return 100
+	case value >= bad:
+		return 0
+	}
+	return int(math.Round(100 * (bad - value) / (bad - good)))
+}
+
+// ratio 安全计算占比
+func ratio(part, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
+
+// scoreObjectScale 对象越多，迁移和验证的工作量越大
+func scoreObjectScale(score *ReadinessScore, profile *oracle.SchemaProfile) {
+	total := profile.Objects.Total()
+	score.Score = linearScore(float64(total), readinessSmallSchema, readinessLargeSchema)
+	score.Summary = fmt.Sprintf("共 %d 个对象（表 %d、视图 %d、索引 %d）",
+		total, profile.Objects["TABLE"], profile.Objects["VIEW"], profile.Objects["INDEX"])
+	if total > readinessSmallSchema {
+		score.Suggestions = append(score.Suggestions, "对象较多，建议按业务模块拆分为多次迁移，或使用 迁移 队列 分批执行")
+	}
+}
+
+// scoreDifficultTypes LONG、嵌套表等类型需要在源库转换或手工指定目标类型
+func scoreDifficultTypes(score *ReadinessScore, profile *oracle.SchemaProfile) {
+	tables := len(profile.Tables)
+	affected := profile.DifficultTables + profile.NestedTables
+	score.Score = linearScore(ratio(affected, tables), 0, readinessDifficultRatio)
+
+	if len(profile.DifficultColumns) == 0 && profile.NestedTables == 0 {
+		score.Summary = "未发现难迁移的列类型"
+		return
+	}
+	types := make([]string, 0, len(profile.DifficultColumns))
+	for dataType := range profile.DifficultColumns {
+		types = append(types, dataType)
+	}
+	sort.Strings(types)
+	parts := make([]string, 0, len(types)+1)
+	for _, dataType := range types {
+		name := dataType
+		if dataType == oracle.UserDefinedType {
+			name = "自定义对象类型"
+		}
+		parts = append(parts, fmt.Sprintf("%s %d 列", name, profile.DifficultColumns[dataType]))
+	}
+	if profile.NestedTables > 0 {
+		parts = append(parts, fmt.Sprintf("嵌套表 %d 张", profile.NestedTables))
+	}
+	score.Summary = fmt.Sprintf("%s，涉及 %d 张表（占 %.1f%%）", strings.Join(parts, "、"), affected, ratio(affected, tables)*100)
+
+	if profile.DifficultColumns["LONG"] > 0 || profile.DifficultColumns["LONG RAW"] > 0 {
+		score.Suggestions = append(score.Suggestions, "LONG/LONG RAW 建议先在源库转换为 CLOB/BLOB，或在 MODIFY_TYPE 中指定目标类型")
+	}
+	if profile.NestedTables > 0 || profile.DifficultColumns[oracle.UserDefinedType] > 0 {
+		score.Suggestions = append(score.Suggestions, "嵌套表和对象类型列在PostgreSQL中需要改为数组、复合类型或子表，请提前设计目标结构")
+	}
+	if profile.DifficultColumns["BFILE"] > 0 {
+		score.Suggestions = append(score.Suggestions, "BFILE 指向数据库服务器上的文件，需要单独迁移文件内容")
+	}
+}
+
+// scorePLSQL 包、存储过程、触发器等需要人工改写为PL/pgSQL
+func scorePLSQL(score *ReadinessScore, profile *oracle.SchemaProfile) {
+	plsql := plsqlObjectCount(profile.Objects)
+	total := profile.Objects.Total()
+	score.Score = linearScore(ratio(plsql, total), 0, readinessPLSQLRatio)
+	score.Summary = fmt.Sprintf("PL/SQL对象 %d 个（包 %d、存储过程 %d、函数 %d、触发器 %d、类型 %d），占 %.1f%%",
+		plsql, profile.Objects["PACKAGE"], profile.Objects["PROCEDURE"], profile.Objects["FUNCTION"],
+		profile.Objects["TRIGGER"], profile.Objects["TYPE"], ratio(plsql, total)*100)
+	if plsql > 0 {
+		score.Suggestions = append(score.Suggestions, "PL/SQL 转换后需要逐个复核，可运行 ora2pg -t SHOW_REPORT --estimate_cost 估算改写工作量")
+	}
+}
+
+// plsqlObjectCount PL/SQL对象数量
+func plsqlObjectCount(objects oracle.ObjectInventory) int {
+	count := 0
+	for _, objectType := range plsqlObjectTypes {
+		count += objects[objectType]
+	}
+	return count
+}
+
+// scoreStatistics 统计信息缺失时无法准确评估数据量和大表
+func scoreStatistics(score *ReadinessScore, stats *oracle.StatsFreshness) {
+	if stats == nil {
+		score.Available = false
+		score.Summary = "无法查询统计信息，该维度不参与评分"
+		return
+	}
+	fresh := stats.Tables - stats.NeverAnalyzed - stats.Stale
+	score.Score = 100
+	if stats.Tables > 0 {
+		score.Score = int(math.Round(ratio(fresh, stats.Tables) * 100))
+	}
+	score.Summary = fmt.Sprintf("%d 张表中 %d 张从未收集统计，%d 张统计已过期", stats.Tables, stats.NeverAnalyzed, stats.Stale)
+	if stats.NeedsGather() {
+		score.Suggestions = append(score.Suggestions, "迁移前收集统计信息: ora2pg-admin 迁移 数据 --gather-stats，使大表识别和进度估算更准确")
+	}
+}
+
+// scoreNaming 保留字、超长或需要加引号的表名在PostgreSQL中容易出错
+func scoreNaming(score *ReadinessScore, profile *oracle.SchemaProfile) {
+	var reserved, tooLong, quoted []string
+	for _, table := range profile.Tables {
+		switch {
+		case config.IsPostgresReservedWord(table):
+			reserved = append(reserved, table)
+		case len(table) > 63:
+			tooLong = append(tooLong, table)
+		case !unquotedNamePattern.MatchString(table):
+			quoted = append(quoted, table)
+		}
+	}
+	invalid := len(reserved) + len(tooLong) + len(quoted)
+	score.Score = linearScore(ratio(invalid, len(profile.Tables)), 0, readinessNamingRatio)
+	if invalid == 0 {
+		score.Summary = "表名均可直接在PostgreSQL中使用"
+		return
+	}
+
+	var parts []string
+	if len(reserved) > 0 {
+		parts = append(parts, fmt.Sprintf("保留字 %d 张（%s）", len(reserved), namingExamples(reserved)))
+		score.Suggestions = append(score.Suggestions, "保留字表名需要加引号，可开启 USE_RESERVED_WORDS 或配置 migration.naming_convention 的前缀")
+	}
+	if len(tooLong) > 0 {
+		parts = append(parts, fmt.Sprintf("超过63字节 %d 张（%s）", len(tooLong), namingExamples(tooLong)))
+		score.Suggestions = append(score.Suggestions, "超过63字节的表名会被PostgreSQL截断，请用 REPLACE_TABLES 指定新名称")
+	}
+	if len(quoted) > 0 {
+		parts = append(parts, fmt.Sprintf("含小写或特殊字符 %d 张（%s）", len(quoted), namingExamples(quoted)))
+		score.Suggestions = append(score.Suggestions, "含小写或特殊字符的表名需要确认大小写规则，参考 migration.naming_convention")
+	}
+	score.Summary = strings.Join(parts, "，")
+}
+
+// namingExamples 最多列出3个示例名称
+func namingExamples(names []string) string {
+	if len(names) > 3 {
+		return strings.Join(names[:3], ", ") + " 等"
+	}
+	return strings.Join(names, ", ")
+}
+
+// scoreLargeTables 大表决定数据迁移的耗时，需要分片并行导出
+func scoreLargeTables(score *ReadinessScore, profile *oracle.SchemaProfile) {
+	count := len(profile.LargeTables)
+	score.Score = max(0, 100-count*readinessLargeTablePoints)
+	if count == 0 {
+		score.Summary = fmt.Sprintf("没有行数超过 %d 的表", oracle.DefaultLargeTableRows)
+		return
+	}
+	names := make([]string, 0, count)
+	for _, table := range profile.LargeTables {
+		names = append(names, fmt.Sprintf("%s(%d行)", table.Name, table.Rows))
+	}
+	score.Summary = fmt.Sprintf("%d 张大表: %s", count, namingExamples(names))
+	score.Suggestions = append(score.Suggestions, "在 migration.large_tables 中为大表配置分片并行导出，并预留足够的迁移窗口")
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/oracle"
+)
+
+// readinessDimensionByKey 按维度查找评分
+func readinessDimensionByKey(t *testing.T, report *ReadinessReport, key string) ReadinessScore {
+	t.Helper()
+	for _, dimension := range report.Dimensions {
+		if dimension.Key == key {
+			return dimension
+		}
+	}
+	require.Failf(t, "缺少维度", key)
+	return ReadinessScore{}
+}
+
+func TestReadinessModelWeights(t *testing.T) {
+	total := 0
+	for _, dimension := range readinessModel {
+		total += dimension.weight
+	}
+	assert.Equal(t, 100, total)
+}
+
+func TestScoreReadinessSimpleSchema(t *testing.T) {
+	profile := &oracle.SchemaProfile{
+		Schema:           "HR",
+		Objects:          oracle.ObjectInventory{"TABLE": 10, "INDEX": 20, "VIEW": 2},
+		Tables:           []string{"EMPLOYEES", "DEPARTMENTS"},
+		DifficultColumns: map[string]int{},
+	}
+	report := ScoreReadiness(profile, &oracle.StatsFreshness{Tables: 10})
+	assert.Equal(t, 100, report.Score)
+	assert.Equal(t, "就绪", report.Level)
+	assert.Equal(t, 0, report.ManualReview)
+	for _, dimension := range report.Dimensions {
+		assert.True(t, dimension.Available)
+		assert.Empty(t, dimension.Suggestions, dimension.Key)
+	}
+}
+
+func TestScoreReadinessComplexSchema(t *testing.T) {
+	tables := []string{"USER", "OrderItems", "ORDERS", "AUDIT_LOG", "CUSTOMERS", "ITEMS", "PRICES", "STOCK", "SHIPMENTS", "INVOICES"}
+	profile := &oracle.SchemaProfile{
+		Schema:           "SALES",
+		Objects:          oracle.ObjectInventory{"TABLE": 10, "PACKAGE": 20, "TRIGGER": 10, "INDEX": 10},
+		Tables:           tables,
+		DifficultColumns: map[string]int{"LONG": 2, oracle.UserDefinedType: 1},
+		DifficultTables:  2,
+		NestedTables:     1,
+		LargeTables:      []oracle.TableSize{{Name: "AUDIT_LOG", Rows: 200000000}, {Name: "ORDERS", Rows: 30000000}},
+	}
+	report := ScoreReadiness(profile, &oracle.StatsFreshness{Tables: 10, NeverAnalyzed: 4, Stale: 1})
+
+	// 3/10 的表含难迁移类型，超过 20% 得0分
+	difficult := readinessDimensionByKey(t, report, ReadinessDifficultTypes)
+	assert.Equal(t, 0, difficult.Score)
+	assert.Contains(t, difficult.Summary, "LONG 2 列")
+	assert.Contains(t, difficult.Summary, "嵌套表 1 张")
+	assert.Len(t, difficult.Suggestions, 2)
+
+	// PL/SQL 占 60%，超过 40% 得0分
+	assert.Equal(t, 0, readinessDimensionByKey(t, report, ReadinessPLSQL).Score)
+	assert.Equal(t, 100, readinessDimensionByKey(t, report, ReadinessObjectScale).Score)
+	assert.Equal(t, 50, readinessDimensionByKey(t, report, ReadinessStatistics).Score)
+	assert.Equal(t, 80, readinessDimensionByKey(t, report, ReadinessLargeTables).Score)
+
+	// 保留字和驼峰表名各一张，占 20%
+	naming := readinessDimensionByKey(t, report, ReadinessNaming)
+	assert.Equal(t, 0, naming.Score)
+	assert.Contains(t, naming.Summary, "保留字 1 张（USER）")
+	assert.Contains(t, naming.Summary, "OrderItems")
+
+	// (0*25 + 0*20 + 100*15 + 50*15 + 80*15 + 0*10) / 100
+	assert.Equal(t, 35, report.Score)
+	assert.Equal(t, "复杂", report.Level)
+	assert.Equal(t, 30+2+1, report.ManualReview)
+}
+
+func TestScoreReadinessWithoutStatistics(t *testing.T) {
+	profile := &oracle.SchemaProfile{
+		Objects: oracle.ObjectInventory{"TABLE": 10, "PROCEDURE": 10},
+		Tables:  []string{"A", "B"},
+	}
+	report := ScoreReadiness(profile, nil)
+	stats := readinessDimensionByKey(t, report, ReadinessStatistics)
+	assert.False(t, stats.Available)
+
+	// 统计信息维度不参与评分，按其余 85 的权重折算：PL/SQL 占 50% 得0分，
+	// (25*100 + 15*100 + 15*100 + 10*100) / 85 ≈ 76
+	assert.Equal(t, 76, report.Score)
+}
+
+func TestLinearScore(t *testing.T) {
+	assert.Equal(t, 100, linearScore(0, 0, 0.2))
+	assert.Equal(t, 50, linearScore(0.1, 0, 0.2))
+	assert.Equal(t, 0, linearScore(0.3, 0, 0.2))
+	assert.Equal(t, 50, linearScore(10250, 500, 20000))
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// s3UnsignedPayload 不对请求体计算摘要，上传大文件时无需预先读一遍
+const s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// s3Uploader 通过 PUT Object 上传到 S3 兼容的对象存储
+type s3Uploader struct {
+	config   *config.S3StorageConfig
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// newS3Uploader 创建 S3 上传实现
+func newS3Uploader(s3 *config.S3StorageConfig) (*s3Uploader, error) {
+	endpoint, err := url.Parse(strings.TrimRight(strings.TrimSpace(s3.Endpoint), "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, utils.NewError(utils.ErrorTypeConfig, "REMOTE_STORAGE_INVALID").
+			Message(fmt.Sprintf("无效的 S3 服务地址: %s", s3.Endpoint)).
+			Suggestion("migration.remote_storage.s3.endpoint 需要 http:// 或 https:// 开头").
+			Build()
+	}
+	// 超时由每次上传的 context 控制
+	return &s3Uploader{config: s3, endpoint: endpoint, client: &http.Client{}, now: time.Now}, nil
+}
+
+// Target 显示用的目标地址
+func (u *s3Uploader) Target(prefix string) string {
+	return fmt.Sprintf("s3://%s/%s", u.config.Bucket, strings.TrimPrefix(prefix, "/"))
+}
+
+// BatchSize 每个文件单独上传、单独重试
+func (u *s3Uploader) BatchSize() int {
+	return 1
+}
+
+// Upload 逐个上传文件
+func (u *s3Uploader) Upload(ctx context.Context, files []remoteFile) error {
+	for _, file := range files {
+		if err := u.putObject(ctx, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// objectURL 对象地址，path_style 时为 <endpoint>/<bucket>/<key>，否则为 <bucket>.<endpoint>/<key>
+func (u *s3Uploader) objectURL(key string) (*url.URL, error) {
+	key = strings.TrimPrefix(key, "/")
+	base := *u.endpoint
+	escapedPath := strings.TrimRight(base.EscapedPath(), "/")
+	if u.config.PathStyle {
+		escapedPath += "/" + s3EscapePath(u.config.Bucket)
+	} else {
+		base.Host = u.config.Bucket + "." + base.Host
+	}
+	return url.Parse(base.Scheme + "://" + base.Host + escapedPath + "/" + s3EscapePath(key))
+}
+
+// putObject 上传单个文件
+func (u *s3Uploader) putObject(ctx context.Context, file remoteFile) error {
+	target, err := u.objectURL(file.Remote)
+	if err != nil {
+		return err
+	}
+	body, err := os.Open(file.Local)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = file.Size
+	signS3Request(req, s3UnsignedPayload, u.config.AccessKeyID, u.config.SecretAccessKey, u.config.SigningRegion(), u.now())
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	return s3ResponseError(file.Remote, resp)
+}
+
+// s3ResponseError 从 S3 的 XML 错误响应中取出错误码和描述
+func s3ResponseError(key string, resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var s3Err struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if xml.Unmarshal(data, &s3Err) == nil && s3Err.Code != "" {
+		return fmt.Errorf("上传 %s 失败: HTTP %d %s: %s", key, resp.StatusCode, s3Err.Code, s3Err.Message)
+	}
+	return fmt.Errorf("上传 %s 失败: HTTP %d", key, resp.StatusCode)
+}
+
+// signS3Request 使用 AWS Signature V4 签名请求，签名 host、content-type、range 和全部 x-amz-* 头
+func signS3Request(req *http.Request, payloadHash, accessKey, secretKey, region string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" || lower == "range" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	for _, part := range []string{region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+// hmacSHA256 计算 HMAC-SHA256
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3CanonicalQuery 按参数名排序并编码的查询字符串
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, s3Escape(key)+"="+s3Escape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3EscapePath 按 RFC 3986 编码对象键，保留路径分隔符 /
+func s3EscapePath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// s3Escape 按 RFC 3986 编码，只保留字母、数字和 -._~
+func s3Escape(value string) string {
+	var builder strings.Builder
+	for _, b := range []byte(value) {
+		if ('A' <= b && b <= 'Z') || ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') || strings.IndexByte("-._~", b) >= 0 {
+			builder.WriteByte(b)
+		} else {
+			fmt.Fprintf(&builder, "%%%02X", b)
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+
+	"ora2pg-admin/internal/config"
+)
+
+// sftpMaxBatch 一个 sftp 会话最多上传的文件数，文件很多时分多次会话，失败时重试的范围也更小
+const sftpMaxBatch = 200
+
+// sftpUploader 调用系统的 sftp 命令以批处理模式上传，认证使用密钥或 ssh-agent
+type sftpUploader struct {
+	config  *config.SFTPStorageConfig
+	command string
+}
+
+// Target 显示用的目标地址
+func (u *sftpUploader) Target(prefix string) string {
+	return fmt.Sprintf("sftp://%s@%s:%d/%s", u.config.Username, u.config.Host, u.config.SFTPPort(), strings.TrimPrefix(prefix, "/"))
+}
+
+// BatchSize 按并发数平均分组，每组不超过 sftpMaxBatch 个文件
+func (u *sftpUploader) BatchSize() int {
+	return 0
+}
+
+// Upload 在一个 sftp 会话中创建远程目录并上传一批文件
+func (u *sftpUploader) Upload(ctx context.Context, files []remoteFile) error {
+	for start := 0; start < len(files); start += sftpMaxBatch {
+		if err := u.uploadSession(ctx, files[start:min(start+sftpMaxBatch, len(files))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uploadSession 写出批处理文件并执行 sftp
+func (u *sftpUploader) uploadSession(ctx context.Context, files []remoteFile) error {
+	batchFile, err := os.CreateTemp("", "ora2pg-admin-sftp-*.txt")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(batchFile.Name())
+	_, err = batchFile.WriteString(sftpBatchScript(files))
+	if closeErr := batchFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	output, err := exec.CommandContext(ctx, u.command, u.args(batchFile.Name())...).CombinedOutput()
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("sftp 上传超时或被取消: %v", ctx.Err())
+		}
+		return fmt.Errorf("sftp 上传失败: %v: %s", err, outputTail(string(output), 5))
+	}
+	return nil
+}
+
+// args sftp 命令参数，BatchMode 禁止交互式输入密码
+func (u *sftpUploader) args(batchFile string) []string {
+	args := []string{"-b", batchFile, "-P", strconv.Itoa(u.config.SFTPPort()), "-o", "BatchMode=yes"}
+	if u.config.IdentityFile != "" {
+		args = append(args, "-i", u.config.IdentityFile)
+	}
+	if u.config.KnownHostsFile != "" {
+		args = append(args, "-o", "UserKnownHostsFile="+u.config.KnownHostsFile)
+	}
+	return append(args, u.config.Username+"@"+u.config.Host)
+}
+
+// sftpBatchScript 批处理命令：先逐级创建远程目录（已存在时忽略错误），再上传文件
+func sftpBatchScript(files []remoteFile) string {
+	var script strings.Builder
+	created := make(map[string]bool)
+	for _, file := range files {
+		var parents []string
+		for dir := path.Dir(file.Remote); dir != "." && dir != "/" && !created[dir]; dir = path.Dir(dir) {
+			created[dir] = true
+			parents = append(parents, dir)
+		}
+		for i := len(parents) - 1; i >= 0; i-- {
+			fmt.Fprintf(&script, "-mkdir %s\n", sftpQuote(parents[i]))
+		}
+	}
+	for _, file := range files {
+		fmt.Fprintf(&script, "put %s %s\n", sftpQuote(file.Local), sftpQuote(file.Remote))
+	}
+	return script.String()
+}
+
+// sftpQuote 为批处理命令的参数加引号
+func sftpQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// outputTail 命令输出的最后几行，用于错误信息
+func outputTail(output string, n int) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// remoteUploadBackoff 上传失败后第一次重试前的等待时间，之后按次数递增
+const remoteUploadBackoff = 2 * time.Second
+
+// remoteFile 待上传的文件
+type remoteFile struct {
+	Local  string // 本地路径
+	Remote string // 远程路径，使用 / 分隔
+	Size   int64
+}
+
+// remoteUploader 远程存储的上传实现
+type remoteUploader interface {
+	// Target 显示用的目标地址
+	Target(prefix string) string
+	// BatchSize 每次调用 Upload 上传的文件数，0 表示按并发数平均分配
+	BatchSize() int
+	// Upload 上传一批文件，任一文件失败时返回错误，整批重试
+	Upload(ctx context.Context, files []remoteFile) error
+}
+
+// RemoteUploadProgress 上传进度
+type RemoteUploadProgress struct {
+	Files      int
+	TotalFiles int
+	Bytes      int64
+	TotalBytes int64
+}
+
+// String 进度描述，如 "12/30 个文件（1.2MB/3.5MB）"
+func (p RemoteUploadProgress) String() string {
+	return fmt.Sprintf("%d/%d 个文件（%s/%s）", p.Files, p.TotalFiles, formatBytes(uint64(p.Bytes)), formatBytes(uint64(p.TotalBytes)))
+}
+
+// RemoteUploadReport 上传结果
+type RemoteUploadReport struct {
+	Target   string
+	Files    int
+	Bytes    int64
+	Failed   []string // 重试后仍上传失败的文件（相对于输出目录）
+	Deleted  int      // 上传后删除的本地文件数
+	Duration time.Duration
+}
+
+// Size 上传的总大小，如 "3.5MB"
+func (r *RemoteUploadReport) Size() string {
+	return formatBytes(uint64(r.Bytes))
+}
+
+// UploadOutput 把输出目录上传到配置的远程存储，文件放在 <path>/<运行ID>/ 下
+//
+// 含数据库密码的 ora2pg*.conf 和 exclude 匹配的文件不上传。cleanLocal 为 true 且
+// local_files 为 delete 时，全部文件上传成功后删除已上传的本地文件。
+func (ms *MigrationService) UploadOutput(ctx context.Context, cleanLocal bool, onProgress func(RemoteUploadProgress)) (*RemoteUploadReport, error) {
+	storage := &ms.config.Migration.RemoteStorage
+	uploader, err := newRemoteUploader(storage)
+	if err != nil {
+		return nil, err
+	}
+	prefix := path.Join(strings.Trim(filepath.ToSlash(storage.Path), "/"), utils.RunID())
+	if strings.HasPrefix(filepath.ToSlash(storage.Path), "/") {
+		prefix = "/" + prefix
+	}
+
+	outputDir := ms.config.Migration.OutputDir
+	files, err := collectRemoteFiles(outputDir, prefix, storage.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	upload := &remoteUpload{
+		uploader: uploader,
+		workers:  storage.Workers(),
+		retries:  storage.RetryCount(),
+		backoff:  remoteUploadBackoff,
+		timeout:  storage.UploadTimeout(),
+		logger:   ms.logger,
+	}
+	start := time.Now()
+	report := &RemoteUploadReport{Target: uploader.Target(prefix), Files: len(files)}
+	for _, file := range files {
+		report.Bytes += file.Size
+	}
+	ms.logger.Infof("开始上传输出目录 %s 到 %s，共 %d 个文件", outputDir, report.Target, len(files))
+
+	failed, lastErr := upload.run(ctx, files, onProgress)
+	report.Duration = time.Since(start)
+	for _, file := range failed {
+		rel, _ := filepath.Rel(outputDir, file.Local)
+		report.Failed = append(report.Failed, filepath.ToSlash(rel))
+	}
+	if len(failed) > 0 {
+		details := report.Failed
+		if len(details) > 10 {
+			details = append(details[:10:10], fmt.Sprintf("... 等 %d 个文件", len(failed)))
+		}
+		return report, utils.NewError(utils.ErrorTypeConnection, "REMOTE_UPLOAD_FAILED").
+			Message(fmt.Sprintf("%d 个文件上传到 %s 失败", len(failed), report.Target)).
+			Details(fmt.Sprintf("%s\n最后一次错误: %v", strings.Join(details, "\n"), lastErr)).
+			Cause(lastErr).
+			Suggestion("检查远程存储的地址、凭据和网络；本地输出已保留，修复后可手动复制失败的文件").
+			Build()
+	}
+	ms.logger.Infof("已上传 %d 个文件到 %s，耗时 %v", len(files), report.Target, report.Duration)
+
+	if cleanLocal && storage.DeleteLocal() {
+		for _, file := range files {
+			if err := os.Remove(file.Local); err != nil && !os.IsNotExist(err) {
+				return report, utils.FileErrors.WriteFailed(file.Local, err)
+			}
+			report.Deleted++
+		}
+		ms.logger.Infof("已删除上传的本地文件 %d 个", report.Deleted)
+	}
+	return report, nil
+}
+
+// newRemoteUploader 按存储类型创建上传实现
+func newRemoteUploader(storage *config.RemoteStorageConfig) (remoteUploader, error) {
+	switch storage.StorageType() {
+	case config.RemoteStorageS3:
+		return newS3Uploader(&storage.S3)
+	case config.RemoteStorageSFTP:
+		return &sftpUploader{config: &storage.SFTP, command: "sftp"}, nil
+	default:
+		return nil, utils.NewError(utils.ErrorTypeConfig, "REMOTE_STORAGE_INVALID").
+			Message(fmt.Sprintf("不支持的远程存储类型: %s", storage.Type)).
+			Suggestion("migration.remote_storage.type 支持 sftp、s3").
+			Build()
+	}
+}
+
+// isProtectedOutputFile 含数据库密码、不能上传的文件
+func isProtectedOutputFile(name string) bool {
+	matched, _ := path.Match("ora2pg*.conf", strings.ToLower(name))
+	return matched
+}
+
+// collectRemoteFiles 列出输出目录中要上传的文件，exclude 同时匹配相对路径和文件名
+func collectRemoteFiles(outputDir, prefix string, exclude []string) ([]remoteFile, error) {
+	var files []remoteFile
+	err := filepath.WalkDir(outputDir, func(localPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(outputDir, localPath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if isProtectedOutputFile(entry.Name()) {
+			return nil
+		}
+		for _, pattern := range exclude {
+			if ok, _ := path.Match(pattern, rel); ok {
+				return nil
+			}
+			if ok, _ := path.Match(pattern, entry.Name()); ok {
+				return nil
+			}
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, remoteFile{Local: localPath, Remote: path.Join(prefix, rel), Size: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, utils.FileErrors.ReadFailed(outputDir, err)
+	}
+	return files, nil
+}
+
+// remoteUpload 并发上传文件，失败的批次按配置重试
+type remoteUpload struct {
+	uploader remoteUploader
+	workers  int
+	retries  int
+	backoff  time.Duration
+	timeout  time.Duration
+	logger   *utils.Logger
+}
+
+// run 上传全部文件，返回重试后仍失败的文件和最后一个错误
+func (u *remoteUpload) run(ctx context.Context, files []remoteFile, onProgress func(RemoteUploadProgress)) ([]remoteFile, error) {
+	batches := u.batches(files)
+	progress := RemoteUploadProgress{TotalFiles: len(files)}
+	for _, file := range files {
+		progress.TotalBytes += file.Size
+	}
+
+	var (
+		mu      sync.Mutex
+		failed  []remoteFile
+		lastErr error
+		wg      sync.WaitGroup
+	)
+	queue := make(chan []remoteFile)
+	for range min(u.workers, len(batches)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range queue {
+				err := u.uploadBatch(ctx, batch)
+				mu.Lock()
+				if err != nil {
+					failed = append(failed, batch...)
+					lastErr = err
+				} else {
+					progress.Files += len(batch)
+					for _, file := range batch {
+						progress.Bytes += file.Size
+					}
+					if onProgress != nil {
+						onProgress(progress)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, batch := range batches {
+		queue <- batch
+	}
+	close(queue)
+	wg.Wait()
+	return failed, lastErr
+}
+
+// batches 按上传实现的批大小分组
+func (u *remoteUpload) batches(files []remoteFile) [][]remoteFile {
+	size := u.uploader.BatchSize()
+	if size <= 0 {
+		size = max(1, (len(files)+u.workers-1)/max(u.workers, 1))
+	}
+	var batches [][]remoteFile
+	for start := 0; start < len(files); start += size {
+		batches = append(batches, files[start:min(start+size, len(files))])
+	}
+	return batches
+}
+
+// uploadBatch 上传一批文件，失败时等待后重试
+func (u *remoteUpload) uploadBatch(ctx context.Context, batch []remoteFile) error {
+	var err error
+	for attempt := 0; attempt <= u.retries; attempt++ {
+		if attempt > 0 {
+			u.logger.Warnf("上传 %s 等 %d 个文件失败，第 %d 次重试: %v", batch[0].Remote, len(batch), attempt, err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(u.backoff * time.Duration(attempt)):
+			}
+		}
+		uploadCtx, cancel := context.WithTimeout(ctx, u.timeout)
+		err = u.uploader.Upload(uploadCtx, batch)
+		cancel()
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// errResourceUnsupported 当前平台无法读取进程资源信息
+var errResourceUnsupported = errors.New("当前平台不支持进程资源监控")
+
+// defaultResourceInterval 默认采样间隔
+const defaultResourceInterval = 2 * time.Second
+
+// processUsage 进程树的累计资源使用
+type processUsage struct {
+	cpuTime time.Duration
+	rss     uint64
+	count   int
+}
+
+// ResourceSample 资源使用采样
+type ResourceSample struct {
+	Time       time.Time `json:"time"`
+	CPUPercent float64   `json:"cpu_percent"`
+	MemoryRSS  uint64    `json:"memory_rss"`
+	Processes  int       `json:"processes"`
+}
+
+// String 格式化采样，用于进度展示
+func (s ResourceSample) String() string {
+	return fmt.Sprintf("CPU %.0f%% 内存 %s 进程 %d", s.CPUPercent, formatBytes(s.MemoryRSS), s.Processes)
+}
+
+// ResourceStats 资源使用统计
+type ResourceStats struct {
+	Samples        int     `json:"samples"`
+	PeakCPUPercent float64 `json:"peak_cpu_percent"`
+	AvgCPUPercent  float64 `json:"avg_cpu_percent"`
+	PeakMemoryRSS  uint64  `json:"peak_memory_rss"`
+	PeakProcesses  int     `json:"peak_processes"`
+	cpuSamples     int
+	cpuSum         float64
+}
+
+// add 累加一次采样，cpuValid 表示本次采样是否包含有效的CPU数据
+func (s *ResourceStats) add(sample ResourceSample, cpuValid bool) {
+	s.Samples++
+	if cpuValid {
+		s.cpuSamples++
+		s.cpuSum += sample.CPUPercent
+		s.AvgCPUPercent = s.cpuSum / float64(s.cpuSamples)
+		if sample.CPUPercent > s.PeakCPUPercent {
+			s.PeakCPUPercent = sample.CPUPercent
+		}
+	}
+	if sample.MemoryRSS > s.PeakMemoryRSS {
+		s.PeakMemoryRSS = sample.MemoryRSS
+	}
+	if sample.Processes > s.PeakProcesses {
+		s.PeakProcesses = sample.Processes
+	}
+}
+
+// Summary 获取统计摘要
+func (s ResourceStats) Summary() string {
+	if s.Samples == 0 {
+		return "无资源采样数据"
+	}
+	return fmt.Sprintf("CPU峰值 %.0f%%（平均 %.0f%%），内存峰值 %s，进程数峰值 %d",
+		s.PeakCPUPercent, s.AvgCPUPercent, formatBytes(s.PeakMemoryRSS), s.PeakProcesses)
+}
+
+// ResourceMonitor 采样ora2pg子进程树的CPU和内存使用
+//
+// 采样协程由 Start/Stop 控制，随迁移生命周期启停；每个ora2pg进程启动时
+// 通过 Attach 关联，结束时 Detach 取得该进程的统计。平台不支持时静默降级。
+type ResourceMonitor struct {
+	interval  time.Duration
+	logger    *utils.Logger
+	onSample  func(ResourceSample)
+	mutex     sync.Mutex
+	rootPID   int
+	lastCPU   time.Duration
+	lastTime  time.Time
+	latest    *ResourceSample
+	segment   ResourceStats
+	total     ResourceStats
+	supported bool
+	stopChan  chan struct{}
+	doneChan  chan struct{}
+}
+
+// NewResourceMonitor 创建资源监控器，interval<=0 时使用默认间隔
+func NewResourceMonitor(interval time.Duration) *ResourceMonitor {
+	if interval <= 0 {
+		interval = defaultResourceInterval
+	}
+	return &ResourceMonitor{
+		interval:  interval,
+		logger:    utils.GetGlobalLogger(),
+		supported: true,
+	}
+}
+
+// SetSampleHandler 设置每次采样后的回调
+func (rm *ResourceMonitor) SetSampleHandler(handler func(ResourceSample)) {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+	rm.onSample = handler
+}
+
+// Start 启动采样协程
+func (rm *ResourceMonitor) Start() {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	if rm.stopChan != nil {
+		return
+	}
+	rm.stopChan = make(chan struct{})
+	rm.doneChan = make(chan struct{})
+	go rm.run(rm.stopChan, rm.doneChan)
+}
+
+// Stop 停止采样协程并等待其退出
+func (rm *ResourceMonitor) Stop() {
+	rm.mutex.Lock()
+	stopChan, doneChan := rm.stopChan, rm.doneChan
+	rm.stopChan, rm.doneChan = nil, nil
+	rm.mutex.Unlock()
+
+	if stopChan == nil {
+		return
+	}
+	close(stopChan)
+	<-doneChan
+}
+
+// Attach 关联新启动的进程，开始新一段统计
+func (rm *ResourceMonitor) Attach(pid int) {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	rm.rootPID = pid
+	rm.lastCPU = 0
+	rm.lastTime = time.Time{}
+	rm.latest = nil
+	rm.segment = ResourceStats{}
+}
+
+// Detach 取消关联当前进程，返回该进程的资源统计
+func (rm *ResourceMonitor) Detach() ResourceStats {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	rm.rootPID = 0
+	rm.latest = nil
+	return rm.segment
+}
+
+// Stats 获取整个监控期间的资源统计
+func (rm *ResourceMonitor) Stats() ResourceStats {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+	return rm.total
+}
+
+// Latest 获取最近一次采样
+func (rm *ResourceMonitor) Latest() *ResourceSample {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+	if rm.latest == nil {
+		return nil
+	}
+	sample := *rm.latest
+	return &sample
+}
+
+// Supported 当前平台是否支持资源监控
+func (rm *ResourceMonitor) Supported() bool {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+	return rm.supported
+}
+
+// run 采样循环
+func (rm *ResourceMonitor) run(stopChan, doneChan chan struct{}) {
+	defer close(doneChan)
+
+	ticker := time.NewTicker(rm.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopChan:
+			return
+		case <-ticker.C:
+			if !rm.sample() {
+				return
+			}
+		}
+	}
+}
+
+// sample 执行一次采样，平台不支持时返回false以结束采样
+func (rm *ResourceMonitor) sample() bool {
+	rm.mutex.Lock()
+	pid := rm.rootPID
+	rm.mutex.Unlock()
+
+	if pid <= 0 {
+		return true
+	}
+
+	usage, err := processTreeUsage(pid)
+	if errors.Is(err, errResourceUnsupported) {
+		rm.mutex.Lock()
+		rm.supported = false
+		rm.mutex.Unlock()
+		rm.logger.Warnf("资源监控已停用: %v", err)
+		return false
+	}
+	if err != nil {
+		// 进程可能已退出，等待下一个进程关联
+		rm.logger.Debugf("资源采样失败: %v", err)
+		return true
+	}
+
+	now := time.Now()
+	rm.mutex.Lock()
+	if rm.rootPID != pid {
+		// 采样期间已切换到其他进程
+		rm.mutex.Unlock()
+		return true
+	}
+
+	sample := ResourceSample{
+		Time:      now,
+		MemoryRSS: usage.rss,
+		Processes: usage.count,
+	}
+	cpuValid := false
+	if !rm.lastTime.IsZero() {
+		if elapsed := now.Sub(rm.lastTime); elapsed > 0 && usage.cpuTime >= rm.lastCPU {
+			sample.CPUPercent = float64(usage.cpuTime-rm.lastCPU) / float64(elapsed) * 100
+			cpuValid = true
+		}
+	}
+	rm.lastCPU = usage.cpuTime
+	rm.lastTime = now
+
+	rm.segment.add(sample, cpuValid)
+	rm.total.add(sample, cpuValid)
+	rm.latest = &sample
+	handler := rm.onSample
+	rm.mutex.Unlock()
+
+	if handler != nil && cpuValid {
+		handler(sample)
+	}
+	return true
+}
+
+// descendantPIDs 根据父子关系计算以root为根的进程树
+func descendantPIDs(root int, parents map[int]int) []int {
+	children := make(map[int][]int)
+	for pid, ppid := range parents {
+		children[ppid] = append(children[ppid], pid)
+	}
+
+	// 记录已访问的进程，防止异常的父子关系形成环
+	visited := map[int]bool{root: true}
+	pids := []int{root}
+	for i := 0; i < len(pids); i++ {
+		for _, child := range children[pids[i]] {
+			if !visited[child] {
+				visited[child] = true
+				pids = append(pids, child)
+			}
+		}
+	}
+	return pids
+}
+
+// formatBytes 格式化字节数
+func formatBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
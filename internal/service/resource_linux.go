@@ -0,0 +1,90 @@
+//go:build linux
+
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicks Linux用户态时钟频率（USER_HZ），主流内核均为100
+const clockTicks = 100
+
+// procStat /proc/<pid>/stat 中需要的字段
+type procStat struct {
+	ppid int
+	cpu  time.Duration
+	rss  uint64
+}
+
+// readProcStat 读取单个进程的 /proc/<pid>/stat
+func readProcStat(pid int) (*procStat, error) {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return nil, err
+	}
+
+	// 进程名可能包含空格和括号，从最后一个')'之后开始解析
+	content := string(data)
+	end := strings.LastIndex(content, ")")
+	if end < 0 {
+		return nil, fmt.Errorf("无法解析进程状态: %d", pid)
+	}
+	fields := strings.Fields(content[end+1:])
+	if len(fields) < 22 {
+		return nil, fmt.Errorf("进程状态字段不足: %d", pid)
+	}
+
+	// 字段编号参见 proc(5)，此处下标从 state(3) 开始
+	ppid, _ := strconv.Atoi(fields[1])
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	rssPages, _ := strconv.ParseUint(fields[21], 10, 64)
+
+	return &procStat{
+		ppid: ppid,
+		cpu:  time.Duration(utime+stime) * time.Second / clockTicks,
+		rss:  rssPages * uint64(os.Getpagesize()),
+	}, nil
+}
+
+// processTreeUsage 通过 /proc 统计进程树的资源使用
+func processTreeUsage(root int) (*processUsage, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, errResourceUnsupported
+	}
+
+	stats := make(map[int]*procStat)
+	parents := make(map[int]int)
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+		stat, err := readProcStat(pid)
+		if err != nil {
+			continue
+		}
+		stats[pid] = stat
+		parents[pid] = stat.ppid
+	}
+
+	if _, exists := stats[root]; !exists {
+		return nil, fmt.Errorf("进程不存在: %d", root)
+	}
+
+	usage := &processUsage{}
+	for _, pid := range descendantPIDs(root, parents) {
+		if stat, exists := stats[pid]; exists {
+			usage.cpuTime += stat.cpu
+			usage.rss += stat.rss
+			usage.count++
+		}
+	}
+	return usage, nil
+}
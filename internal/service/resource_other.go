@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !windows
+
+package service
+
+// processTreeUsage 当前平台不支持资源监控
+func processTreeUsage(root int) (*processUsage, error) {
+	return nil, errResourceUnsupported
+}
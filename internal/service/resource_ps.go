@@ -0,0 +1,85 @@
+//go:build darwin || freebsd || netbsd || openbsd
+
+package service
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// psProcess ps 输出的一行
+type psProcess struct {
+	ppid int
+	rss  uint64
+	cpu  time.Duration
+}
+
+// processTreeUsage 通过 ps 统计进程树的资源使用
+func processTreeUsage(root int) (*processUsage, error) {
+	output, err := exec.Command("ps", "-A", "-o", "pid=", "-o", "ppid=", "-o", "rss=", "-o", "time=").Output()
+	if err != nil {
+		if _, lookErr := exec.LookPath("ps"); lookErr != nil {
+			return nil, errResourceUnsupported
+		}
+		return nil, fmt.Errorf("执行ps失败: %v", err)
+	}
+
+	processes := make(map[int]*psProcess)
+	parents := make(map[int]int)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		ppid, _ := strconv.Atoi(fields[1])
+		rssKB, _ := strconv.ParseUint(fields[2], 10, 64)
+		processes[pid] = &psProcess{
+			ppid: ppid,
+			rss:  rssKB * 1024,
+			cpu:  parsePsTime(fields[3]),
+		}
+		parents[pid] = ppid
+	}
+
+	if _, exists := processes[root]; !exists {
+		return nil, fmt.Errorf("进程不存在: %d", root)
+	}
+
+	usage := &processUsage{}
+	for _, pid := range descendantPIDs(root, parents) {
+		if p, exists := processes[pid]; exists {
+			usage.cpuTime += p.cpu
+			usage.rss += p.rss
+			usage.count++
+		}
+	}
+	return usage, nil
+}
+
+// parsePsTime 解析 ps 的CPU时间，格式为 [[dd-]hh:]mm:ss[.cc]
+func parsePsTime(value string) time.Duration {
+	var days int
+	if idx := strings.Index(value, "-"); idx >= 0 {
+		days, _ = strconv.Atoi(value[:idx])
+		value = value[idx+1:]
+	}
+
+	parts := strings.Split(value, ":")
+	seconds, _ := strconv.ParseFloat(parts[len(parts)-1], 64)
+	total := time.Duration(seconds * float64(time.Second))
+
+	multiplier := time.Minute
+	for i := len(parts) - 2; i >= 0; i-- {
+		n, _ := strconv.Atoi(parts[i])
+		total += time.Duration(n) * multiplier
+		multiplier *= 60
+	}
+	return total + time.Duration(days)*24*time.Hour
+}
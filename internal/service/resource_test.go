@@ -0,0 +1,96 @@
+package service
+
+import (
+	"os"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceStatsAdd(t *testing.T) {
+	var stats ResourceStats
+	assert.Equal(t, "无资源采样数据", stats.Summary())
+
+	// 首次采样没有CPU基准，只统计内存
+	stats.add(ResourceSample{MemoryRSS: 100 << 20, Processes: 1}, false)
+	stats.add(ResourceSample{CPUPercent: 50, MemoryRSS: 300 << 20, Processes: 3}, true)
+	stats.add(ResourceSample{CPUPercent: 150, MemoryRSS: 200 << 20, Processes: 2}, true)
+
+	assert.Equal(t, 3, stats.Samples)
+	assert.Equal(t, 150.0, stats.PeakCPUPercent)
+	assert.Equal(t, 100.0, stats.AvgCPUPercent)
+	assert.Equal(t, uint64(300<<20), stats.PeakMemoryRSS)
+	assert.Equal(t, 3, stats.PeakProcesses)
+	assert.Contains(t, stats.Summary(), "300.0MB")
+}
+
+func TestDescendantPIDs(t *testing.T) {
+	parents := map[int]int{
+		10: 1,
+		11: 10,
+		12: 10,
+		13: 11,
+		20: 1,
+		// 异常的环形关系不应导致死循环
+		30: 31,
+		31: 30,
+	}
+
+	pids := descendantPIDs(10, parents)
+	sort.Ints(pids)
+	assert.Equal(t, []int{10, 11, 12, 13}, pids)
+	assert.Len(t, descendantPIDs(30, parents), 2)
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512B", formatBytes(512))
+	assert.Equal(t, "1.5KB", formatBytes(1536))
+	assert.Equal(t, "2.0GB", formatBytes(2<<30))
+}
+
+func TestProcessTreeUsage(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("仅在Linux上验证 /proc 采样")
+	}
+
+	usage, err := processTreeUsage(os.Getpid())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, usage.count, 1)
+	assert.Greater(t, usage.rss, uint64(0))
+
+	_, err = processTreeUsage(-1)
+	assert.Error(t, err)
+}
+
+func TestResourceMonitorSamples(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("仅在Linux上验证 /proc 采样")
+	}
+
+	monitor := NewResourceMonitor(20 * time.Millisecond)
+	samples := make(chan ResourceSample, 10)
+	monitor.SetSampleHandler(func(sample ResourceSample) {
+		select {
+		case samples <- sample:
+		default:
+		}
+	})
+	monitor.Start()
+	defer monitor.Stop()
+	monitor.Attach(os.Getpid())
+
+	select {
+	case sample := <-samples:
+		assert.Greater(t, sample.MemoryRSS, uint64(0))
+	case <-time.After(2 * time.Second):
+		t.Fatal("未收到资源采样")
+	}
+
+	stats := monitor.Detach()
+	assert.GreaterOrEqual(t, stats.Samples, 2)
+	assert.True(t, monitor.Supported())
+}
@@ -0,0 +1,92 @@
+//go:build windows
+
+package service
+
+import (
+	"fmt"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var procGetProcessMemoryInfo = windows.NewLazySystemDLL("psapi.dll").NewProc("GetProcessMemoryInfo")
+
+// processMemoryCounters 对应 PROCESS_MEMORY_COUNTERS 结构
+type processMemoryCounters struct {
+	cb                         uint32
+	pageFaultCount             uint32
+	peakWorkingSetSize         uintptr
+	workingSetSize             uintptr
+	quotaPeakPagedPoolUsage    uintptr
+	quotaPagedPoolUsage        uintptr
+	quotaPeakNonPagedPoolUsage uintptr
+	quotaNonPagedPoolUsage     uintptr
+	pagefileUsage              uintptr
+	peakPagefileUsage          uintptr
+}
+
+// processTreeUsage 通过 Toolhelp 快照和进程API统计进程树的资源使用
+func processTreeUsage(root int) (*processUsage, error) {
+	if procGetProcessMemoryInfo.Find() != nil {
+		return nil, errResourceUnsupported
+	}
+
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, fmt.Errorf("创建进程快照失败: %v", err)
+	}
+	defer windows.CloseHandle(snapshot)
+
+	parents := make(map[int]int)
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	for err = windows.Process32First(snapshot, &entry); err == nil; err = windows.Process32Next(snapshot, &entry) {
+		parents[int(entry.ProcessID)] = int(entry.ParentProcessID)
+	}
+
+	if _, exists := parents[root]; !exists {
+		return nil, fmt.Errorf("进程不存在: %d", root)
+	}
+
+	usage := &processUsage{}
+	for _, pid := range descendantPIDs(root, parents) {
+		cpu, rss, err := windowsProcessUsage(uint32(pid))
+		if err != nil {
+			continue
+		}
+		usage.cpuTime += cpu
+		usage.rss += rss
+		usage.count++
+	}
+	return usage, nil
+}
+
+// windowsProcessUsage 获取单个进程的CPU时间和工作集大小
+func windowsProcessUsage(pid uint32) (time.Duration, uint64, error) {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, pid)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer windows.CloseHandle(handle)
+
+	var creation, exit, kernel, user windows.Filetime
+	if err := windows.GetProcessTimes(handle, &creation, &exit, &kernel, &user); err != nil {
+		return 0, 0, err
+	}
+	// FILETIME 以100纳秒为单位
+	cpu := time.Duration(filetimeTicks(kernel)+filetimeTicks(user)) * 100
+
+	var counters processMemoryCounters
+	counters.cb = uint32(unsafe.Sizeof(counters))
+	ret, _, callErr := procGetProcessMemoryInfo.Call(uintptr(handle), uintptr(unsafe.Pointer(&counters)), uintptr(counters.cb))
+	if ret == 0 {
+		return 0, 0, callErr
+	}
+	return cpu, uint64(counters.workingSetSize), nil
+}
+
+// filetimeTicks 将 FILETIME 转换为100纳秒计数
+func filetimeTicks(ft windows.Filetime) int64 {
+	return int64(ft.HighDateTime)<<32 | int64(ft.LowDateTime)
+}
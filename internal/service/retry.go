@@ -0,0 +1,93 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// ConfigFingerprint 影响迁移结果的配置（源库、目标库、迁移选项和环境变量）的指纹，不包含密码和测试账号
+//
+// 记录在迁移历史中，重试失败的类型前用来确认配置没有变化。
+func ConfigFingerprint(cfg *config.ProjectConfig) string {
+	fingerprint := struct {
+		Oracle      config.OracleConfig    `json:"oracle"`
+		PostgreSQL  config.PostgreConfig   `json:"postgresql"`
+		Migration   config.MigrationConfig `json:"migration"`
+		Environment map[string]string      `json:"environment,omitempty"`
+	}{cfg.Oracle, cfg.PostgreSQL, cfg.Migration, cfg.Environment}
+	fingerprint.Oracle.Password, fingerprint.Oracle.TestUsername, fingerprint.Oracle.TestPassword = "", "", ""
+	fingerprint.PostgreSQL.Password, fingerprint.PostgreSQL.TestUsername, fingerprint.PostgreSQL.TestPassword = "", "", ""
+
+	data, err := json.Marshal(fingerprint)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// RetryPlan 重试上次迁移中失败类型的计划
+type RetryPlan struct {
+	// Record 上次迁移的历史记录
+	Record *HistoryRecord
+	// Failed 上次迁移中失败的类型结果，按当时的执行顺序
+	Failed []HistoryTypeResult
+	// ConfigChanged 配置与上次迁移时不同
+	ConfigChanged bool
+	// FingerprintMissing 上次的记录中没有配置指纹（早期版本生成），无法确认配置是否变化
+	FingerprintMissing bool
+}
+
+// Types 需要重试的迁移类型
+func (p *RetryPlan) Types() []MigrationType {
+	types := make([]MigrationType, 0, len(p.Failed))
+	for _, result := range p.Failed {
+		types = append(types, result.Type)
+	}
+	return types
+}
+
+// PlanRetry 根据最后一条迁移历史找出失败的类型，并与当前配置的指纹比较
+//
+// 只重试状态为 FAILED 的类型；因取消等原因没有执行到的类型由 --resume 续传，不在重试范围内。
+func PlanRetry(historyPath string, cfg *config.ProjectConfig) (*RetryPlan, error) {
+	records, err := LoadHistory(historyPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, utils.NewError(utils.ErrorTypeMigration, "RETRY_NO_HISTORY").
+			Message("没有迁移历史，无法确定要重试的类型").
+			Details(historyPath).
+			Suggestion("先执行 'ora2pg-admin 迁移 全部' 等迁移命令").
+			Build()
+	}
+
+	last := records[len(records)-1]
+	plan := &RetryPlan{Record: last}
+	seen := make(map[MigrationType]bool)
+	for _, result := range last.Results {
+		if result.Status != StatusFailed || result.Type == "" || seen[result.Type] {
+			continue
+		}
+		seen[result.Type] = true
+		plan.Failed = append(plan.Failed, result)
+	}
+
+	// 按模块迁移的记录使用该模块的配置计算指纹，模块已不存在时视为配置变化
+	if module := last.Metadata[ModuleMetadataKey]; module != "" {
+		if scoped, err := moduleConfig(cfg, module); err == nil {
+			cfg = scoped
+		}
+	}
+	if last.ConfigHash == "" {
+		plan.FingerprintMissing = true
+	} else {
+		plan.ConfigChanged = last.ConfigHash != ConfigFingerprint(cfg)
+	}
+	return plan, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+func TestConfigFingerprint(t *testing.T) {
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("指纹")
+	cfg := manager.GetConfig()
+	fingerprint := ConfigFingerprint(cfg)
+	assert.Len(t, fingerprint, 64)
+
+	// 密码和项目信息不影响指纹
+	cfg.Oracle.Password = "changed"
+	cfg.PostgreSQL.Password = "changed"
+	cfg.Project.Description = "changed"
+	assert.Equal(t, fingerprint, ConfigFingerprint(cfg))
+
+	cfg.Migration.ParallelJobs++
+	assert.NotEqual(t, fingerprint, ConfigFingerprint(cfg))
+}
+
+func TestPlanRetryWithoutHistory(t *testing.T) {
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("重试")
+
+	_, err := PlanRetry(filepath.Join(t.TempDir(), "history.jsonl"), manager.GetConfig())
+	assert.Equal(t, "RETRY_NO_HISTORY", utils.GetErrorCode(err))
+}
+
+func TestRetryOnlyFailedTypes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟ora2pg依赖 /bin/sh")
+	}
+
+	// 模拟ora2pg：记录每次执行的类型，存在 fail-<类型> 文件时该类型失败
+	bin := t.TempDir()
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+  if [ "$1" = "-t" ]; then type=$2; fi
+  shift
+done
+echo "$type" >> calls.txt
+if [ -f "fail-$type" ]; then echo "FATAL: $type failed"; exit 1; fi
+`
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ora2pg"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Chdir(t.TempDir())
+
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("重试")
+	cfg := manager.GetConfig()
+	cfg.Migration.OutputDir = "output"
+	require.NoError(t, os.MkdirAll("output", 0755))
+	require.NoError(t, os.WriteFile(filepath.Join("output", "ora2pg.conf"), []byte("ORACLE_DSN dbi:Oracle:host=db\n"), 0644))
+	historyPath := filepath.Join(".ora2pg-admin", "history.jsonl")
+	types := []MigrationType{MigrationTypeTable, MigrationTypeView, MigrationTypeSequence, MigrationTypeTrigger}
+
+	run := func(ms *MigrationService, migrationTypes []MigrationType) *HistoryRecord {
+		ms.SetHistoryPath(historyPath)
+		_, err := ms.ExecuteWithProgress(context.Background(), migrationTypes, NewProgressTracker())
+		require.NoError(t, err)
+		record, err := ms.RecordHistory("完整迁移", migrationTypes, nil)
+		require.NoError(t, err)
+		return record
+	}
+	readCalls := func() []string {
+		data, err := os.ReadFile("calls.txt")
+		require.NoError(t, err)
+		require.NoError(t, os.Remove("calls.txt"))
+		return strings.Fields(string(data))
+	}
+
+	// 第一次迁移：VIEW 和 TRIGGER 失败
+	require.NoError(t, os.WriteFile("fail-VIEW", nil, 0644))
+	require.NoError(t, os.WriteFile("fail-TRIGGER", nil, 0644))
+	first := run(NewMigrationService(cfg), types)
+	assert.Equal(t, StatusFailed, first.Status)
+	assert.Equal(t, []string{"TABLE", "VIEW", "SEQUENCE", "TRIGGER"}, readCalls())
+
+	plan, err := PlanRetry(historyPath, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []MigrationType{MigrationTypeView, MigrationTypeTrigger}, plan.Types())
+	assert.False(t, plan.ConfigChanged)
+	assert.False(t, plan.FingerprintMissing)
+
+	// 重试：只执行失败的类型，TRIGGER 仍然失败
+	require.NoError(t, os.Remove("fail-VIEW"))
+	retryService := NewMigrationService(cfg)
+	retryService.SetRetry(plan.Record)
+	retry := run(retryService, plan.Types())
+	assert.Equal(t, []string{"VIEW", "TRIGGER"}, readCalls())
+	assert.Equal(t, first.RunID, retry.RetryOf)
+
+	// 检查点保留了第一次成功的类型，并更新了重试的结果
+	checkpoint, err := LoadCheckpoint(DefaultCheckpointPath)
+	require.NoError(t, err)
+	assert.True(t, checkpoint.IsTypeCompleted(MigrationTypeTable))
+	assert.True(t, checkpoint.IsTypeCompleted(MigrationTypeSequence))
+	assert.True(t, checkpoint.IsTypeCompleted(MigrationTypeView))
+	assert.False(t, checkpoint.IsTypeCompleted(MigrationTypeTrigger))
+
+	// 再次重试只剩 TRIGGER；配置变化后能被识别
+	plan, err = PlanRetry(historyPath, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []MigrationType{MigrationTypeTrigger}, plan.Types())
+	cfg.Migration.ParallelJobs++
+	plan, err = PlanRetry(historyPath, cfg)
+	require.NoError(t, err)
+	assert.True(t, plan.ConfigChanged)
+
+	// 全部成功后没有需要重试的类型
+	require.NoError(t, os.Remove("fail-TRIGGER"))
+	run(NewMigrationService(cfg), plan.Types())
+	readCalls()
+	plan, err = PlanRetry(historyPath, cfg)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Types())
+}
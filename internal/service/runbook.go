@@ -0,0 +1,479 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/config"
+)
+
+// DefaultRunbookPath 默认的迁移操作手册路径
+const DefaultRunbookPath = "docs/migration-runbook.md"
+
+// RunbookOptions 生成迁移操作手册的选项
+type RunbookOptions struct {
+	// ConfigFile 项目配置文件路径
+	ConfigFile string
+	// GeneratedAt 生成时间
+	GeneratedAt time.Time
+	// ShowHosts 显示完整的主机名和用户名，默认脱敏
+	ShowHosts bool
+}
+
+// runbookPhases 手册中迁移范围的阶段顺序和名称
+var runbookPhases = []struct {
+	phase MigrationPhase
+	name  string
+}{
+	{PhaseStructure, "结构"},
+	{PhaseData, "数据"},
+	{PhaseIndex, "索引和约束"},
+	{PhaseFunction, "程序对象"},
+	{PhaseGrant, "权限"},
+}
+
+// runbook 操作手册的生成状态
+type runbook struct {
+	cfg     *config.ProjectConfig
+	options RunbookOptions
+	sb      strings.Builder
+	step    int
+}
+
+// GenerateRunbook 根据项目配置生成Markdown格式的迁移操作手册
+//
+// 手册内容随配置变化：只列出配置了的迁移类型、表范围、分批和增量规则，执行步骤和回滚方案按实际使用的功能生成。
+// 密码不会出现在手册中，主机名和用户名默认脱敏。
+func GenerateRunbook(cfg *config.ProjectConfig, options RunbookOptions) string {
+	r := &runbook{cfg: cfg, options: options}
+	r.writeHeader()
+	r.writeProject()
+	r.writeConnections()
+	r.writeScope()
+	r.writeSteps()
+	r.writeRollback()
+	r.writeChecklist()
+	return r.sb.String()
+}
+
+// line 写入一行
+func (r *runbook) line(format string, args ...interface{}) {
+	fmt.Fprintf(&r.sb, format, args...)
+	r.sb.WriteString("\n")
+}
+
+// command 写入命令代码块
+func (r *runbook) command(commands ...string) {
+	r.line("```bash")
+	for _, command := range commands {
+		r.line("%s", command)
+	}
+	r.line("```")
+	r.line("")
+}
+
+// stepTitle 写入带编号的步骤标题
+func (r *runbook) stepTitle(title string) {
+	r.step++
+	r.line("### 4.%d %s", r.step, title)
+	r.line("")
+}
+
+// writeHeader 写入标题
+func (r *runbook) writeHeader() {
+	name := r.cfg.Project.Name
+	if name == "" {
+		name = "Oracle到PostgreSQL"
+	}
+	r.line("# %s 迁移操作手册", name)
+	r.line("")
+	r.line("> 由 `ora2pg-admin 文档 生成` 于 %s 根据项目配置生成，配置变化后请重新生成。", r.options.GeneratedAt.Format("2006-01-02 15:04"))
+	r.line("")
+}
+
+// writeProject 写入项目信息
+func (r *runbook) writeProject() {
+	project := r.cfg.Project
+	r.line("## 1. 项目信息")
+	r.line("")
+	r.line("| 项目 | 内容 |")
+	r.line("| --- | --- |")
+	r.line("| 项目名称 | %s |", orNotSet(project.Name))
+	r.line("| 项目描述 | %s |", orNotSet(project.Description))
+	r.line("| 配置版本 | %s |", orNotSet(project.Version))
+	if !project.Created.IsZero() {
+		r.line("| 创建时间 | %s |", project.Created.Format("2006-01-02"))
+	}
+	r.line("| 配置文件 | `%s` |", r.options.ConfigFile)
+	r.line("| 输出目录 | `%s` |", r.cfg.Migration.OutputDir)
+	r.line("")
+}
+
+// writeConnections 写入脱敏的数据库连接概况
+func (r *runbook) writeConnections() {
+	oracleCfg := &r.cfg.Oracle
+	pg := &r.cfg.PostgreSQL
+
+	oracleTarget := "SID " + oracleCfg.SID
+	if oracleCfg.Service != "" {
+		oracleTarget = "服务名 " + oracleCfg.Service
+	}
+	oracleSecurity := "TCP"
+	if oracleCfg.UseTCPS {
+		oracleSecurity = fmt.Sprintf("TCPS（证书校验 %s）", oracleCfg.SSLVerifyLevel())
+	}
+	pgSecurity := "自建"
+	if pg.Managed {
+		pgSecurity = "云托管（无超级用户权限）"
+	}
+
+	r.line("## 2. 数据库连接概况")
+	r.line("")
+	r.line("| 项目 | 源库 Oracle | 目标库 PostgreSQL |")
+	r.line("| --- | --- | --- |")
+	r.line("| 地址 | %s | %s |", r.address(oracleCfg.Host, oracleCfg.Port), r.address(pg.Host, pg.Port))
+	r.line("| 数据库 | %s | %s |", oracleTarget, orNotSet(pg.Database))
+	r.line("| 用户 | %s | %s |", r.identity(oracleCfg.Username), r.identity(pg.Username))
+	r.line("| 密码 | %s | %s |", passwordState(oracleCfg.Password), passwordState(pg.Password))
+	r.line("| Schema | %s | %s |", orNotSet(oracleCfg.Schema), orNotSet(pg.Schema))
+	r.line("| 连接方式 | %s | %s |", oracleSecurity, pgSecurity)
+	if oracleCfg.HasTestAccount() || pg.HasTestAccount() {
+		r.line("| 测试账号 | %s | %s |", r.identity(oracleCfg.TestUsername), r.identity(pg.TestUsername))
+	}
+	r.line("")
+	if !r.options.ShowHosts {
+		r.line("主机名和用户名已脱敏，密码不出现在手册中，执行人员从配置文件或密码管理系统获取。")
+		r.line("")
+	}
+}
+
+// writeScope 写入迁移范围
+func (r *runbook) writeScope() {
+	migration := &r.cfg.Migration
+	ms := NewMigrationService(r.cfg)
+
+	r.line("## 3. 迁移范围")
+	r.line("")
+	r.line("### 3.1 迁移类型")
+	r.line("")
+	r.line("| 阶段 | 类型 |")
+	r.line("| --- | --- |")
+	for _, phase := range runbookPhases {
+		types, _ := ms.ConfiguredTypesForPhases(phase.phase)
+		if len(types) > 0 {
+			r.line("| %s | %s |", phase.name, typeList(types))
+		}
+	}
+	if _, unsupported := ms.ConfiguredTypesForPhases(); len(unsupported) > 0 {
+		r.line("| 不支持 | %s（不会执行） |", strings.Join(unsupported, ", "))
+	}
+	r.line("")
+
+	r.line("### 3.2 迁移对象")
+	r.line("")
+	switch {
+	case len(migration.AllowTables) > 0:
+		r.line("- 只迁移指定的 %d 张表：%s", len(migration.AllowTables), strings.Join(migration.AllowTables, ", "))
+	case len(migration.AllowPatterns) > 0:
+		r.line("- 迁移名称匹配以下正则表达式的表：%s", codeList(migration.AllowPatterns))
+	case r.cfg.Oracle.Schema != "":
+		r.line("- 源库 Schema %s 中的全部表", r.cfg.Oracle.Schema)
+	default:
+		r.line("- 源库连接用户 Schema 中的全部表")
+	}
+	if len(migration.ExcludePatterns) > 0 {
+		r.line("- 排除名称匹配以下正则表达式的表：%s", codeList(migration.ExcludePatterns))
+	}
+	for _, table := range migration.LargeTables {
+		r.line("- 大表 %s 分 %d 片并行导出", table.Name, table.Shards)
+	}
+	if migration.Batching.Enabled() {
+		r.line("- 数据分批迁移：%s", batchingSummary(&migration.Batching))
+	}
+	if migration.Incremental.Enabled() {
+		var tables []string
+		for _, table := range migration.Incremental.Tables {
+			tables = append(tables, fmt.Sprintf("%s（%s）", table.Name, table.Column))
+		}
+		r.line("- 增量同步的表：%s", strings.Join(tables, ", "))
+	}
+	if len(migration.Fragments) > 0 {
+		r.line("- ora2pg配置片段：%s", strings.Join(migration.Fragments, " → "))
+	}
+	r.line("- 并行作业数 %d", migration.ParallelJobs)
+	r.line("")
+
+	if names := migration.GroupNames(); len(names) > 0 {
+		plan, _, err := migration.GroupPlan(names, true)
+		if err != nil {
+			plan = names
+		}
+		r.line("### 3.3 迁移模块")
+		r.line("")
+		r.line("| 顺序 | 模块 | 依赖 | 表 |")
+		r.line("| --- | --- | --- | --- |")
+		for i, name := range plan {
+			r.line("| %d | %s | %s | %s |", i+1, name, orNone(strings.Join(migration.GroupDependencies[name], ", ")),
+				strings.Join(migration.Groups[name], ", "))
+		}
+		r.line("")
+	}
+}
+
+// writeSteps 写入执行步骤
+func (r *runbook) writeSteps() {
+	migration := &r.cfg.Migration
+	ms := NewMigrationService(r.cfg)
+	structureTypes, _ := ms.ConfiguredTypesForPhases(StructurePhases...)
+	dataTypes, _ := ms.ConfiguredTypesForPhases(DataPhases...)
+
+	r.line("## 4. 执行步骤")
+	r.line("")
+
+	r.stepTitle("检查环境和连接")
+	r.command("ora2pg-admin 检查 环境", "ora2pg-admin 检查 连接", "ora2pg-admin 检查 就绪度")
+
+	r.stepTitle("迁移前预检")
+	r.line("确认磁盘空间、连接数、目标库权限等满足要求，查看本次执行的类型和顺序。")
+	r.line("")
+	r.command("ora2pg-admin 迁移 预检", "ora2pg-admin 迁移 计划")
+
+	if !migration.Scripts.Disabled {
+		r.stepTitle("准备迁移前后脚本")
+		r.line("`%s` 中的脚本在迁移前执行，`%s` 中的脚本在迁移后执行，按文件名排序；脚本失败时%s。",
+			migration.Scripts.PreScriptsDir(), migration.Scripts.PostScriptsDir(), scriptsOnError(&migration.Scripts))
+		r.line("")
+	}
+
+	switch names := migration.GroupNames(); {
+	case len(names) > 0:
+		if plan, _, err := migration.GroupPlan(names, true); err == nil {
+			names = plan
+		}
+		r.stepTitle("按模块迁移")
+		r.line("按上面的模块顺序迁移，某个模块失败时不再执行后续模块；不指定模块名时查看各模块的进度。")
+		r.line("")
+		r.command("ora2pg-admin 迁移 模块 "+strings.Join(names, " "), "ora2pg-admin 迁移 模块")
+	case migration.Batching.Enabled():
+		if len(structureTypes) > 0 {
+			r.stepTitle("迁移结构")
+			r.line("执行类型：%s", typeList(structureTypes))
+			r.line("")
+			r.command("ora2pg-admin 迁移 结构")
+		}
+		r.stepTitle("分批迁移数据")
+		r.line("每个批次完成后暂停，核对该批次的数据后再继续。")
+		r.line("")
+		r.command("ora2pg-admin 迁移 分批 --plan", "ora2pg-admin 迁移 分批")
+	default:
+		if len(structureTypes) > 0 {
+			r.stepTitle("迁移结构")
+			r.line("执行类型：%s", typeList(structureTypes))
+			r.line("")
+			r.command("ora2pg-admin 迁移 结构")
+		}
+		if len(dataTypes) > 0 {
+			r.stepTitle("迁移数据")
+			r.line("执行类型：%s", typeList(dataTypes))
+			r.line("")
+			r.command("ora2pg-admin 迁移 数据")
+		}
+	}
+	r.line("执行中可用 `ora2pg-admin 进度` 查看进度；中断后在同一命令后加 `--resume` 续传，失败的类型用 `ora2pg-admin 迁移 重试` 重新执行。")
+	r.line("")
+
+	if len(dataTypes) > 0 {
+		r.stepTitle("验证迁移结果")
+		r.command("ora2pg-admin 校验", "ora2pg-admin 校验 约束")
+	}
+
+	if migration.Incremental.Enabled() {
+		r.stepTitle("增量同步")
+		r.line("全量迁移完成后到切换前，按需多次执行增量同步，切换前停止源库写入再执行最后一次。")
+		r.line("")
+		r.command("ora2pg-admin 迁移 数据 --incremental")
+	}
+
+	r.stepTitle("归档")
+	r.command("ora2pg-admin 历史", "ora2pg-admin 项目 导出 migration-"+r.options.GeneratedAt.Format("20060102")+".tar.gz")
+}
+
+// writeRollback 写入回滚方案
+func (r *runbook) writeRollback() {
+	migration := &r.cfg.Migration
+	schema := r.cfg.PostgreSQL.Schema
+	if schema == "" {
+		schema = "public"
+	}
+
+	r.line("## 5. 回滚方案")
+	r.line("")
+	r.line("ora2pg 只读取源库，迁移不会修改 Oracle 中的数据，回滚只需要处理目标库。")
+	r.line("")
+	r.line("1. 停止正在运行的迁移：`ora2pg-admin 迁移 状态` 查看运行ID，`ora2pg-admin 迁移 停止 <运行ID>`")
+	if schema == "public" {
+		r.line("2. 清理目标库：目标 Schema 为 public，请按迁移的表逐个删除，不要删除整个 Schema")
+	} else {
+		r.line("2. 清理目标库：确认 Schema 中没有其他业务对象后执行 `DROP SCHEMA %s CASCADE;` 并重新创建", schema)
+	}
+	n := 3
+	if migration.DeferConstraints {
+		r.line("%d. 数据迁移中断时外键可能处于删除状态，执行 `ora2pg-admin 校验 约束` 确认，外键定义保存在 `.ora2pg-admin/deferred_constraints.json`", n)
+		n++
+	}
+	if migration.Incremental.Enabled() {
+		r.line("%d. 增量同步失败时不推进水位（`.ora2pg-admin/watermarks.json`），修复后重新执行即可；回滚到全量状态时删除水位文件", n)
+		n++
+	}
+	if migration.Batching.Enabled() {
+		r.line("%d. 单个批次有问题时用 `ora2pg-admin 迁移 分批 --batch <序号>` 重新执行该批次，整表批次先清空表，切片批次先删除该范围的数据", n)
+		n++
+	}
+	r.line("%d. 修复问题后以幂等模式重新执行，结构先删后建、数据先清空再导入：`ora2pg-admin 迁移 全部 --idempotent`", n)
+	r.line("%d. 业务已切换到目标库后需要回退时，把应用连接改回 Oracle；切换后在目标库写入的数据需要另行同步回源库", n+1)
+	r.line("")
+}
+
+// writeChecklist 写入检查清单
+func (r *runbook) writeChecklist() {
+	migration := &r.cfg.Migration
+
+	r.line("## 6. 检查清单")
+	r.line("")
+	r.line("### 迁移前")
+	r.line("")
+	r.line("- [ ] 手册已经团队评审，迁移窗口和回滚负责人已确认")
+	r.line("- [ ] `检查 环境`、`检查 连接`、`迁移 预检` 全部通过")
+	r.line("- [ ] 目标库已备份或确认可以清空")
+	if migration.Incremental.Enabled() {
+		r.line("- [ ] 最后一次增量同步前源库已停止写入")
+	} else {
+		r.line("- [ ] 源库已置为只读或停止写入（`迁移 预检` 的源库写入状态没有警告）")
+	}
+	r.line("- [ ] 输出目录 `%s` 和 `backup/` 所在磁盘空间充足", migration.OutputDir)
+	if r.cfg.Oracle.UseTCPS {
+		r.line("- [ ] Oracle 钱包目录 `%s` 在执行机器上可用", orNotSet(r.cfg.Oracle.WalletDir))
+	}
+	if r.cfg.PostgreSQL.Managed {
+		r.line("- [ ] 云托管目标库的迁移账号具有目标 Schema 的建表权限")
+	}
+	if migration.TimeZone != "" {
+		r.line("- [ ] 源库和目标库会话时区统一为 %s", migration.TimeZone)
+	}
+	if len(migration.LargeTables) > 0 || migration.Batching.Enabled() {
+		r.line("- [ ] 大表的统计信息是最新的，分片或分批规则已按当前数据量确认")
+	}
+	r.line("")
+	r.line("### 迁移中")
+	r.line("")
+	r.line("- [ ] 每个阶段完成后记录耗时和结果（`ora2pg-admin 历史`）")
+	r.line("- [ ] 出现失败类型时先查看 `logs/` 中的 ora2pg 日志，再决定重试或回滚")
+	r.line("")
+	r.line("### 迁移后")
+	r.line("")
+	r.line("- [ ] `校验` 抽样比对数据一致，`校验 约束` 没有缺失的约束")
+	r.line("- [ ] 序列当前值已大于表中的最大值")
+	r.line("- [ ] 应用在目标库上完成冒烟测试")
+	r.line("- [ ] 输出和运行记录已归档")
+}
+
+// address 返回主机和端口，默认脱敏
+func (r *runbook) address(host string, port int) string {
+	if host == "" {
+		return "未配置"
+	}
+	if !r.options.ShowHosts {
+		host = maskHost(host)
+	}
+	return fmt.Sprintf("%s:%d", host, port)
+}
+
+// identity 返回用户名，默认脱敏
+func (r *runbook) identity(name string) string {
+	if name == "" {
+		return "未配置"
+	}
+	if r.options.ShowHosts {
+		return name
+	}
+	return maskText(name)
+}
+
+// maskHost 脱敏主机名：IP保留前两段，域名只保留首字母和顶级域
+func maskHost(host string) string {
+	if ip := net.ParseIP(host); ip != nil && ip.To4() != nil {
+		parts := strings.Split(host, ".")
+		return parts[0] + "." + parts[1] + ".*.*"
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) == 1 {
+		return maskText(host)
+	}
+	return maskText(labels[0]) + ".***." + labels[len(labels)-1]
+}
+
+// maskText 只保留第一个字符
+func maskText(value string) string {
+	runes := []rune(value)
+	return string(runes[:1]) + "***"
+}
+
+// passwordState 密码是否已配置
+func passwordState(password string) string {
+	if password == "" {
+		return "未配置"
+	}
+	return "已配置（不显示）"
+}
+
+// batchingSummary 分批规则的概要
+func batchingSummary(batching *config.BatchingConfig) string {
+	var parts []string
+	if batching.TableBuckets > 1 {
+		parts = append(parts, fmt.Sprintf("其余表按大小分为 %d 批", batching.TableBuckets))
+	}
+	for _, slice := range batching.Slices {
+		parts = append(parts, fmt.Sprintf("%s 按 %s 切为 %d 片", slice.Name, slice.Column, len(slice.Boundaries)+1))
+	}
+	return strings.Join(parts, "，")
+}
+
+// scriptsOnError 脚本失败策略的描述
+func scriptsOnError(scripts *config.MigrationScriptsConfig) string {
+	if scripts.ContinueOnError() {
+		return "记录失败后继续迁移"
+	}
+	return "中止迁移"
+}
+
+// typeList 以逗号分隔列出迁移类型
+func typeList(types []MigrationType) string {
+	return strings.ReplaceAll(FormatMigrationOrder(types), ",", ", ")
+}
+
+// codeList 以代码格式列出
+func codeList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = "`" + value + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// orNotSet 为空时返回"未配置"
+func orNotSet(value string) string {
+	if value == "" {
+		return "未配置"
+	}
+	return value
+}
+
+// orNone 为空时返回"无"
+func orNone(value string) string {
+	if value == "" {
+		return "无"
+	}
+	return value
+}
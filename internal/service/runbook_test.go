@@ -0,0 +1,100 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"ora2pg-admin/internal/config"
+)
+
+func runbookConfig() *config.ProjectConfig {
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("订单系统")
+	cfg := manager.GetConfig()
+	cfg.Oracle.Host = "ora-prod.corp.example.com"
+	cfg.Oracle.Username = "scott"
+	cfg.Oracle.Password = "tiger"
+	cfg.Oracle.Service = "ORCLPDB"
+	cfg.Oracle.Schema = "APP"
+	cfg.PostgreSQL.Host = "10.20.30.40"
+	cfg.PostgreSQL.Username = "migrator"
+	cfg.PostgreSQL.Password = "secret"
+	cfg.PostgreSQL.Schema = "app"
+	cfg.Migration.Types = []string{"COPY", "TABLE", "INDEX", "BOGUS"}
+	return cfg
+}
+
+func TestGenerateRunbook(t *testing.T) {
+	cfg := runbookConfig()
+	cfg.Migration.ExcludePatterns = []string{"^TMP_"}
+	options := RunbookOptions{ConfigFile: ".ora2pg-admin/config.yaml", GeneratedAt: time.Date(2024, 5, 6, 7, 8, 0, 0, time.UTC)}
+
+	content := GenerateRunbook(cfg, options)
+	assert.Contains(t, content, "# 订单系统 迁移操作手册\n")
+	assert.Contains(t, content, "2024-05-06 07:08")
+	assert.Contains(t, content, "| 配置文件 | `.ora2pg-admin/config.yaml` |")
+
+	// 连接信息脱敏，不包含密码
+	assert.Contains(t, content, "| 地址 | o***.***.com:1521 | 10.20.*.*:5432 |")
+	assert.Contains(t, content, "| 用户 | s*** | m*** |")
+	assert.Contains(t, content, "| 数据库 | 服务名 ORCLPDB |")
+	for _, secret := range []string{"tiger", "secret", "scott", "migrator", "ora-prod", "30.40"} {
+		assert.NotContains(t, content, secret)
+	}
+
+	// 迁移范围来自配置
+	assert.Contains(t, content, "| 结构 | TABLE |")
+	assert.Contains(t, content, "| 数据 | COPY |")
+	assert.Contains(t, content, "| 索引和约束 | INDEX |")
+	assert.Contains(t, content, "| 不支持 | BOGUS（不会执行） |")
+	assert.NotContains(t, content, "| 权限 |")
+	assert.Contains(t, content, "`^TMP_`")
+
+	// 执行步骤和回滚方案按使用的功能生成
+	assert.Contains(t, content, "ora2pg-admin 迁移 结构\n")
+	assert.Contains(t, content, "ora2pg-admin 迁移 数据\n")
+	assert.NotContains(t, content, "迁移 分批")
+	assert.NotContains(t, content, "--incremental")
+	assert.Contains(t, content, "DROP SCHEMA app CASCADE")
+	assert.Contains(t, content, "## 6. 检查清单")
+
+	options.ShowHosts = true
+	content = GenerateRunbook(cfg, options)
+	assert.Contains(t, content, "ora-prod.corp.example.com:1521")
+	assert.Contains(t, content, "| 用户 | scott | migrator |")
+	assert.NotContains(t, content, "tiger")
+}
+
+func TestGenerateRunbookFeatures(t *testing.T) {
+	cfg := runbookConfig()
+	cfg.PostgreSQL.Schema = ""
+	cfg.Migration.Batching = config.BatchingConfig{TableBuckets: 3,
+		Slices: []config.TableSliceConfig{{Name: "ORDERS", Column: "ID", Boundaries: []string{"1000"}}}}
+	cfg.Migration.Incremental = config.IncrementalConfig{Tables: []config.IncrementalTableConfig{{Name: "ORDERS", Column: "UPDATED_AT"}}}
+	cfg.Migration.DeferConstraints = true
+
+	content := GenerateRunbook(cfg, RunbookOptions{GeneratedAt: time.Now()})
+	assert.Contains(t, content, "其余表按大小分为 3 批，ORDERS 按 ID 切为 2 片")
+	assert.Contains(t, content, "ora2pg-admin 迁移 分批\n")
+	assert.Contains(t, content, "ora2pg-admin 迁移 数据 --incremental")
+	assert.Contains(t, content, "deferred_constraints.json")
+	assert.Contains(t, content, "watermarks.json")
+	assert.Contains(t, content, "目标 Schema 为 public")
+
+	// 配置了模块时按依赖顺序迁移
+	cfg.Migration.Groups = map[string][]string{"订单": {"ORDERS"}, "用户": {"USERS"}}
+	cfg.Migration.GroupDependencies = map[string][]string{"订单": {"用户"}}
+	content = GenerateRunbook(cfg, RunbookOptions{GeneratedAt: time.Now()})
+	assert.Contains(t, content, "| 1 | 用户 | 无 | USERS |")
+	assert.Contains(t, content, "| 2 | 订单 | 用户 | ORDERS |")
+	assert.Contains(t, content, "ora2pg-admin 迁移 模块 用户 订单\n")
+}
+
+func TestMaskHost(t *testing.T) {
+	assert.Equal(t, "192.168.*.*", maskHost("192.168.1.10"))
+	assert.Equal(t, "d***.***.local", maskHost("db01.prod.local"))
+	assert.Equal(t, "l***", maskHost("localhost"))
+	assert.Equal(t, "数***", maskText("数据库"))
+}
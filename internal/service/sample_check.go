@@ -0,0 +1,547 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/postgres"
+	"ora2pg-admin/internal/utils"
+)
+
+// DefaultSampleTolerance 数值比对的默认相对容差，用于吸收浮点表示差异
+const DefaultSampleTolerance = 1e-9
+
+// sampleValueLength 每个值参与比对的最大字符数，避免单行输出过长
+const sampleValueLength = 100
+
+// sampleColumnBatchSize 预取表结构时每个sqlplus会话查询的表数
+const sampleColumnBatchSize = 50
+
+// sampleMaxColumns 每张表最多比对的列数（主键列始终参与）
+const sampleMaxColumns = 30
+
+// sampleNullMarker 空值标记，不是合法的十六进制串因此不会与数据混淆
+const sampleNullMarker = "N"
+
+// 输出行前缀，用于从sqlplus/psql输出中定位结果
+const (
+	sampleColumnMarker = "COL|"
+	sampleTableMarker  = "TAB|"
+	sampleRowMarker    = "ROW|"
+)
+
+// SampleColumnKind 列值的比对方式
+type SampleColumnKind string
+
+const (
+	SampleKindNumber      SampleColumnKind = "number"
+	SampleKindDate        SampleColumnKind = "date"
+	SampleKindTimestamp   SampleColumnKind = "timestamp"
+	SampleKindTimestampTZ SampleColumnKind = "timestamptz"
+	SampleKindChar        SampleColumnKind = "char"
+	SampleKindText        SampleColumnKind = "text"
+	// SampleKindUnsupported LOB、RAW等不参与比对
+	SampleKindUnsupported SampleColumnKind = ""
+)
+
+// SampleColumn 参与采样比对的列
+type SampleColumn struct {
+	Name        string
+	DataType    string
+	Kind        SampleColumnKind
+	KeyPosition int // 主键中的位置，从1开始，0表示非主键列
+}
+
+// SampleMismatch 不一致的行或列
+type SampleMismatch struct {
+	Key      string
+	Column   string
+	Oracle   string
+	Postgres string
+}
+
+// SampleTableResult 单表采样比对结果
+type SampleTableResult struct {
+	Table          string
+	Sampled        int
+	Matched        int
+	Columns        []string
+	SkippedColumns []string
+	Mismatches     []SampleMismatch
+}
+
+// SampleValidator 从Oracle随机抽样，再按主键到PostgreSQL查询同一行比对列值
+type SampleValidator struct {
+	oracleRunner   *oracle.SQLPlusRunner
+	postgresRunner *postgres.PSQLRunner
+	oracleSchema   string
+	postgresSchema string
+	preserveCase   bool
+	// targetTable 按命名规则把Oracle表名转换为目标库表名
+	targetTable func(string) string
+	tolerance   float64
+
+	// columns 预取的表结构，键为大写表名
+	columns map[string][]SampleColumn
+}
+
+// NewSampleValidator 创建采样校验器
+func NewSampleValidator(cfg *config.ProjectConfig) *SampleValidator {
+	oracleSchema := cfg.Oracle.Schema
+	if oracleSchema == "" {
+		oracleSchema = cfg.Oracle.Username
+	}
+	postgresSchema := cfg.PostgreSQL.Schema
+	if postgresSchema == "" {
+		postgresSchema = "public"
+	}
+
+	return &SampleValidator{
+		oracleRunner:   oracle.NewSQLPlusRunner(&cfg.Oracle, &cfg.OracleClient),
+		postgresRunner: postgres.NewPSQLRunner(&cfg.PostgreSQL),
+		oracleSchema:   strings.ToUpper(strings.TrimSpace(oracleSchema)),
+		postgresSchema: postgresSchema,
+		preserveCase:   cfg.Migration.PreserveCase(),
+		targetTable:    cfg.Migration.TargetTableName,
+		tolerance:      DefaultSampleTolerance,
+	}
+}
+
+// SetTolerance 设置数值比对的相对容差
+func (v *SampleValidator) SetTolerance(tolerance float64) {
+	v.tolerance = tolerance
+}
+
+// SchemaTables 获取Oracle模式下的全部表
+func (v *SampleValidator) SchemaTables(ctx context.Context) ([]string, error) {
+	query := fmt.Sprintf(`SELECT '%s' || table_name FROM all_tables
+WHERE owner = %s AND nested = 'NO' AND secondary = 'N'
+ORDER BY table_name;`, sampleTableMarker, oracleLiteral(v.oracleSchema))
+
+	output, err := v.oracleRunner.Run(ctx, query)
+	if err != nil {
+		return nil, sampleError("获取Oracle表清单失败", err)
+	}
+
+	var tables []string
+	for _, line := range strings.Split(output, "\n") {
+		if table, found := strings.CutPrefix(strings.TrimSpace(line), sampleTableMarker); found && table != "" {
+			tables = append(tables, table)
+		}
+	}
+	return tables, nil
+}
+
+// ValidateTable 对单表随机抽取 sampleSize 行进行比对
+func (v *SampleValidator) ValidateTable(ctx context.Context, table string, sampleSize int) (*SampleTableResult, error) {
+	table = strings.ToUpper(strings.TrimSpace(table))
+	result := &SampleTableResult{Table: table}
+
+	columns, err := v.tableColumns(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, utils.NewError(utils.ErrorTypeValidation, "SAMPLE_TABLE_NOT_FOUND").
+			Message("Oracle中未找到表").
+			Details(fmt.Sprintf("%s.%s", v.oracleSchema, table)).
+			Build()
+	}
+
+	columns, skipped := selectSampleColumns(columns)
+	result.SkippedColumns = skipped
+	for _, column := range columns {
+		result.Columns = append(result.Columns, column.Name)
+	}
+	keyCount := 0
+	for _, column := range columns {
+		if column.KeyPosition > 0 {
+			keyCount++
+		}
+	}
+	if keyCount == 0 {
+		return nil, utils.NewError(utils.ErrorTypeValidation, "SAMPLE_NO_PRIMARY_KEY").
+			Message("表没有可用于定位行的主键").
+			Details(table).
+			Suggestion("采样比对需要主键（且主键列不能是LOB等不支持比对的类型），请改用行数对比").
+			Build()
+	}
+
+	// 1. 从Oracle随机抽样
+	output, err := v.oracleRunner.Run(ctx, v.oracleSampleQuery(table, columns, sampleSize))
+	if err != nil {
+		return nil, sampleError(fmt.Sprintf("从Oracle抽样 %s 失败", table), err)
+	}
+	oracleRows, err := parseSampleRows(output, len(columns))
+	if err != nil {
+		return nil, err
+	}
+	result.Sampled = len(oracleRows)
+	if len(oracleRows) == 0 {
+		return result, nil
+	}
+
+	// 2. 按主键到PostgreSQL查询同一批行
+	output, err = v.postgresRunner.Run(ctx, v.postgresSampleQuery(table, columns, oracleRows))
+	if err != nil {
+		return nil, sampleError(fmt.Sprintf("从PostgreSQL查询 %s 失败", table), err)
+	}
+	postgresRows, err := parseSampleRows(output, len(columns))
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 逐行逐列比对
+	postgresByKey := make(map[string][]string, len(postgresRows))
+	for _, row := range postgresRows {
+		postgresByKey[sampleRowKey(columns, row)] = row
+	}
+	for _, oracleRow := range oracleRows {
+		key := sampleRowKey(columns, oracleRow)
+		postgresRow, exists := postgresByKey[key]
+		if !exists {
+			result.Mismatches = append(result.Mismatches, SampleMismatch{Key: key, Oracle: "存在", Postgres: "行不存在"})
+			continue
+		}
+
+		matched := true
+		for i, column := range columns {
+			if !compareSampleValue(column.Kind, oracleRow[i], postgresRow[i], v.tolerance) {
+				matched = false
+				result.Mismatches = append(result.Mismatches, SampleMismatch{
+					Key:      key,
+					Column:   column.Name,
+					Oracle:   displaySampleValue(oracleRow[i]),
+					Postgres: displaySampleValue(postgresRow[i]),
+				})
+			}
+		}
+		if matched {
+			result.Matched++
+		}
+	}
+	return result, nil
+}
+
+// PrefetchColumns 批量查询多张表的结构，每个sqlplus会话查询一批表，
+// 避免逐表校验时为每张表单独启动sqlplus和登录
+func (v *SampleValidator) PrefetchColumns(ctx context.Context, tables []string) error {
+	if v.columns == nil {
+		v.columns = make(map[string][]SampleColumn, len(tables))
+	}
+	for start := 0; start < len(tables); start += sampleColumnBatchSize {
+		end := start + sampleColumnBatchSize
+		if end > len(tables) {
+			end = len(tables)
+		}
+		batch := make([]string, 0, end-start)
+		queries := make([]string, 0, end-start)
+		for _, table := range tables[start:end] {
+			table = strings.ToUpper(strings.TrimSpace(table))
+			batch = append(batch, table)
+			queries = append(queries, v.tableColumnsQuery(table))
+		}
+
+		outputs, err := v.oracleRunner.RunBatch(ctx, queries...)
+		if err != nil {
+			return sampleError("查询Oracle表结构失败", err)
+		}
+		for i, table := range batch {
+			columns, err := parseSampleColumns(outputs[i])
+			if err != nil {
+				return err
+			}
+			v.columns[table] = columns
+		}
+	}
+	return nil
+}
+
+// tableColumns 查询Oracle表的列、类型和主键位置，已预取时直接使用预取结果
+func (v *SampleValidator) tableColumns(ctx context.Context, table string) ([]SampleColumn, error) {
+	if columns, exists := v.columns[table]; exists {
+		return columns, nil
+	}
+	output, err := v.oracleRunner.Run(ctx, v.tableColumnsQuery(table))
+	if err != nil {
+		return nil, sampleError("查询Oracle表结构失败", err)
+	}
+	return parseSampleColumns(output)
+}
+
+// tableColumnsQuery 构建查询表结构的SQL
+func (v *SampleValidator) tableColumnsQuery(table string) string {
+	return fmt.Sprintf(`SELECT '%s' || c.column_name || '|' || c.data_type || '|' || NVL((
+    SELECT cc.position FROM all_constraints k
+    JOIN all_cons_columns cc ON cc.owner = k.owner AND cc.constraint_name = k.constraint_name
+    WHERE k.owner = c.owner AND k.table_name = c.table_name
+      AND k.constraint_type = 'P' AND cc.column_name = c.column_name), 0)
+FROM all_tab_columns c
+WHERE c.owner = %s AND c.table_name = %s
+ORDER BY c.column_id;`, sampleColumnMarker, oracleLiteral(v.oracleSchema), oracleLiteral(table))
+}
+
+// oracleSampleQuery 构建Oracle随机抽样查询，输出规范化后的十六进制值
+func (v *SampleValidator) oracleSampleQuery(table string, columns []SampleColumn, sampleSize int) string {
+	fields := make([]string, len(columns))
+	for i, column := range columns {
+		fields[i] = fmt.Sprintf("NVL(RAWTOHEX(UTL_I18N.STRING_TO_RAW(SUBSTR(%s, 1, %d), 'AL32UTF8')), '%s')",
+			oracleValueExpr(column), sampleValueLength, sampleNullMarker)
+	}
+	return fmt.Sprintf(`SET LONG 1000000
+SET LONGCHUNKSIZE 32767
+SELECT TO_CLOB('%s') || %s FROM (
+  SELECT * FROM %s.%s ORDER BY DBMS_RANDOM.VALUE
+) WHERE ROWNUM <= %d;`, sampleRowMarker, strings.Join(fields, " || '|' || "),
+		oracleIdentifier(v.oracleSchema), oracleIdentifier(table), sampleSize)
+}
+
+// postgresSampleQuery 构建按主键查询同一批行的PostgreSQL查询
+func (v *SampleValidator) postgresSampleQuery(table string, columns []SampleColumn, oracleRows [][]string) string {
+	fields := make([]string, len(columns))
+	var keyNames []string
+	var keyIndexes []int
+	for i, column := range columns {
+		name := postgres.QuoteIdentifier(postgres.TargetTableName(column.Name, v.preserveCase))
+		fields[i] = fmt.Sprintf("coalesce(encode(convert_to(substr(%s, 1, %d), 'UTF8'), 'hex'), '%s')",
+			postgresValueExpr(column, name), sampleValueLength, sampleNullMarker)
+		if column.KeyPosition > 0 {
+			keyNames = append(keyNames, name)
+			keyIndexes = append(keyIndexes, i)
+		}
+	}
+
+	keys := make([]string, 0, len(oracleRows))
+	for _, row := range oracleRows {
+		values := make([]string, len(keyIndexes))
+		for j, index := range keyIndexes {
+			value := decodeSampleValue(row[index])
+			if columns[index].Kind == SampleKindTimestampTZ {
+				value += "+00"
+			}
+			values[j] = postgres.QuoteLiteral(value)
+		}
+		keys = append(keys, "("+strings.Join(values, ", ")+")")
+	}
+
+	return fmt.Sprintf("SELECT '%s' || %s FROM %s.%s WHERE (%s) IN (%s);",
+		sampleRowMarker, strings.Join(fields, " || '|' || "),
+		postgres.QuoteIdentifier(v.postgresSchema),
+		postgres.QuoteIdentifier(v.targetTable(table)),
+		strings.Join(keyNames, ", "), strings.Join(keys, ", "))
+}
+
+// classifySampleColumn 根据Oracle数据类型确定比对方式
+func classifySampleColumn(dataType string) SampleColumnKind {
+	dataType = strings.ToUpper(strings.TrimSpace(dataType))
+	switch {
+	case dataType == "NUMBER", dataType == "FLOAT", dataType == "INTEGER",
+		dataType == "BINARY_FLOAT", dataType == "BINARY_DOUBLE":
+		return SampleKindNumber
+	case dataType == "DATE":
+		return SampleKindDate
+	case strings.HasPrefix(dataType, "TIMESTAMP") && strings.Contains(dataType, "TIME ZONE"):
+		return SampleKindTimestampTZ
+	case strings.HasPrefix(dataType, "TIMESTAMP"):
+		return SampleKindTimestamp
+	case dataType == "CHAR", dataType == "NCHAR":
+		return SampleKindChar
+	case dataType == "VARCHAR2", dataType == "NVARCHAR2", dataType == "VARCHAR":
+		return SampleKindText
+	}
+	return SampleKindUnsupported
+}
+
+// selectSampleColumns 选出参与比对的列：主键列始终保留，其余按顺序最多保留到列数上限
+func selectSampleColumns(columns []SampleColumn) ([]SampleColumn, []string) {
+	var selected []SampleColumn
+	var skipped []string
+	for _, column := range columns {
+		if column.Kind == SampleKindUnsupported {
+			skipped = append(skipped, fmt.Sprintf("%s(%s)", column.Name, column.DataType))
+			continue
+		}
+		selected = append(selected, column)
+	}
+
+	// 主键列排在前面，按主键位置排序，便于组装主键
+	sort.SliceStable(selected, func(i, j int) bool {
+		pi, pj := selected[i].KeyPosition, selected[j].KeyPosition
+		if pi == 0 || pj == 0 {
+			return pi != 0 && pj == 0
+		}
+		return pi < pj
+	})
+	if len(selected) > sampleMaxColumns {
+		for _, column := range selected[sampleMaxColumns:] {
+			skipped = append(skipped, column.Name+"(超出列数上限)")
+		}
+		selected = selected[:sampleMaxColumns]
+	}
+	return selected, skipped
+}
+
+// oracleValueExpr Oracle端的规范化表达式
+func oracleValueExpr(column SampleColumn) string {
+	name := oracleIdentifier(column.Name)
+	switch column.Kind {
+	case SampleKindNumber:
+		return fmt.Sprintf("TO_CHAR(%s, 'TM9', 'NLS_NUMERIC_CHARACTERS=''.,''')", name)
+	case SampleKindDate:
+		return fmt.Sprintf("TO_CHAR(%s, 'YYYY-MM-DD HH24:MI:SS')", name)
+	case SampleKindTimestamp:
+		return fmt.Sprintf("TO_CHAR(%s, 'YYYY-MM-DD HH24:MI:SS.FF6')", name)
+	case SampleKindTimestampTZ:
+		return fmt.Sprintf("TO_CHAR(SYS_EXTRACT_UTC(CAST(%s AS TIMESTAMP WITH TIME ZONE)), 'YYYY-MM-DD HH24:MI:SS.FF6')", name)
+	case SampleKindChar:
+		return fmt.Sprintf("RTRIM(%s)", name)
+	}
+	return name
+}
+
+// postgresValueExpr PostgreSQL端与 oracleValueExpr 对应的规范化表达式
+func postgresValueExpr(column SampleColumn, name string) string {
+	switch column.Kind {
+	case SampleKindNumber:
+		return name + "::text"
+	case SampleKindDate:
+		return fmt.Sprintf("to_char(%s, 'YYYY-MM-DD HH24:MI:SS')", name)
+	case SampleKindTimestamp:
+		return fmt.Sprintf("to_char(%s, 'YYYY-MM-DD HH24:MI:SS.US')", name)
+	case SampleKindTimestampTZ:
+		return fmt.Sprintf("to_char(%s AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:MI:SS.US')", name)
+	case SampleKindChar:
+		return fmt.Sprintf("nullif(rtrim(%s::text), '')", name)
+	}
+	// Oracle中空字符串即NULL
+	return fmt.Sprintf("nullif(%s::text, '')", name)
+}
+
+// parseSampleColumns 解析列信息查询的输出
+func parseSampleColumns(output string) ([]SampleColumn, error) {
+	var columns []SampleColumn
+	for _, line := range strings.Split(output, "\n") {
+		rest, found := strings.CutPrefix(strings.TrimSpace(line), sampleColumnMarker)
+		if !found {
+			continue
+		}
+		parts := strings.Split(rest, "|")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("无法解析列信息: %s", line)
+		}
+		position, err := strconv.Atoi(strings.TrimSpace(parts[2]))
+		if err != nil {
+			return nil, fmt.Errorf("无法解析主键位置: %s", line)
+		}
+		columns = append(columns, SampleColumn{
+			Name:        parts[0],
+			DataType:    parts[1],
+			Kind:        classifySampleColumn(parts[1]),
+			KeyPosition: position,
+		})
+	}
+	return columns, nil
+}
+
+// parseSampleRows 解析抽样查询输出的行，每行为 columnCount 个十六进制值或空值标记
+func parseSampleRows(output string, columnCount int) ([][]string, error) {
+	var rows [][]string
+	for _, line := range strings.Split(output, "\n") {
+		rest, found := strings.CutPrefix(strings.TrimSpace(line), sampleRowMarker)
+		if !found {
+			continue
+		}
+		values := strings.Split(rest, "|")
+		if len(values) != columnCount {
+			return nil, fmt.Errorf("抽样结果列数不匹配: 期望 %d 列，实际 %d 列", columnCount, len(values))
+		}
+		rows = append(rows, values)
+	}
+	return rows, nil
+}
+
+// sampleRowKey 用主键列的规范化值组成行键
+func sampleRowKey(columns []SampleColumn, row []string) string {
+	var parts []string
+	for i, column := range columns {
+		if column.KeyPosition > 0 {
+			parts = append(parts, column.Name+"="+displaySampleValue(row[i]))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// compareSampleValue 比较两端规范化后的值，数值按十进制精确比较，不相等时再按相对容差比较
+func compareSampleValue(kind SampleColumnKind, oracleValue, postgresValue string, tolerance float64) bool {
+	if oracleValue == postgresValue {
+		return true
+	}
+	if kind != SampleKindNumber || oracleValue == sampleNullMarker || postgresValue == sampleNullMarker {
+		return false
+	}
+
+	left, leftOK := new(big.Rat).SetString(decodeSampleValue(oracleValue))
+	right, rightOK := new(big.Rat).SetString(decodeSampleValue(postgresValue))
+	if !leftOK || !rightOK {
+		return false
+	}
+	if left.Cmp(right) == 0 {
+		return true
+	}
+
+	a, _ := left.Float64()
+	b, _ := right.Float64()
+	scale := math.Max(math.Abs(a), math.Abs(b))
+	return math.Abs(a-b) <= tolerance*scale
+}
+
+// decodeSampleValue 把十六进制值解码为原文，空值标记返回空串
+func decodeSampleValue(value string) string {
+	if value == sampleNullMarker {
+		return ""
+	}
+	decoded, err := hex.DecodeString(value)
+	if err != nil {
+		return value
+	}
+	return string(decoded)
+}
+
+// displaySampleValue 用于显示的值
+func displaySampleValue(value string) string {
+	if value == sampleNullMarker {
+		return "NULL"
+	}
+	return strconv.Quote(decodeSampleValue(value))
+}
+
+// oracleIdentifier 转义Oracle标识符
+func oracleIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// oracleLiteral 转义Oracle字符串字面量
+func oracleLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// sampleError 包装抽样查询的错误
+func sampleError(message string, err error) error {
+	if code := utils.GetErrorCode(err); code != "UNKNOWN" {
+		return err
+	}
+	return utils.NewError(utils.ErrorTypeValidation, "SAMPLE_QUERY_FAILED").
+		Message(message).
+		Details(err.Error()).
+		Cause(err).
+		Suggestion("运行 'ora2pg-admin 检查 连接' 确认源库和目标库连接").
+		Build()
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// sampleHex 把原文编码为抽样输出的十六进制值
+func sampleHex(value string) string {
+	return hex.EncodeToString([]byte(value))
+}
+
+func TestClassifySampleColumn(t *testing.T) {
+	assert.Equal(t, SampleKindNumber, classifySampleColumn("NUMBER"))
+	assert.Equal(t, SampleKindNumber, classifySampleColumn("BINARY_DOUBLE"))
+	assert.Equal(t, SampleKindDate, classifySampleColumn("DATE"))
+	assert.Equal(t, SampleKindTimestamp, classifySampleColumn("TIMESTAMP(6)"))
+	assert.Equal(t, SampleKindTimestampTZ, classifySampleColumn("TIMESTAMP(6) WITH TIME ZONE"))
+	assert.Equal(t, SampleKindTimestampTZ, classifySampleColumn("TIMESTAMP(6) WITH LOCAL TIME ZONE"))
+	assert.Equal(t, SampleKindChar, classifySampleColumn("CHAR"))
+	assert.Equal(t, SampleKindText, classifySampleColumn("VARCHAR2"))
+	assert.Equal(t, SampleKindUnsupported, classifySampleColumn("CLOB"))
+	assert.Equal(t, SampleKindUnsupported, classifySampleColumn("RAW"))
+}
+
+func TestCompareSampleValue(t *testing.T) {
+	// NUMBER精度：Oracle TM9格式省略前导0，PostgreSQL numeric保留尾部0
+	assert.True(t, compareSampleValue(SampleKindNumber, sampleHex(".5"), sampleHex("0.50"), 0))
+	assert.True(t, compareSampleValue(SampleKindNumber, sampleHex("1.5E+000"), sampleHex("1.5"), 0))
+	assert.False(t, compareSampleValue(SampleKindNumber, sampleHex("100"), sampleHex("100.01"), DefaultSampleTolerance))
+
+	// 浮点容差
+	assert.True(t, compareSampleValue(SampleKindNumber, sampleHex("0.3"), sampleHex("0.30000000000000004"), DefaultSampleTolerance))
+	assert.False(t, compareSampleValue(SampleKindNumber, sampleHex("0.3"), sampleHex("0.30000000000000004"), 0))
+
+	// 空值和文本
+	assert.True(t, compareSampleValue(SampleKindText, sampleNullMarker, sampleNullMarker, 0))
+	assert.False(t, compareSampleValue(SampleKindNumber, sampleNullMarker, sampleHex("0"), DefaultSampleTolerance))
+	assert.False(t, compareSampleValue(SampleKindText, sampleHex("abc"), sampleHex("abd"), DefaultSampleTolerance))
+}
+
+func TestSelectSampleColumns(t *testing.T) {
+	columns := []SampleColumn{
+		{Name: "NOTE", DataType: "CLOB", Kind: SampleKindUnsupported},
+		{Name: "NAME", DataType: "VARCHAR2", Kind: SampleKindText},
+		{Name: "LINE_NO", DataType: "NUMBER", Kind: SampleKindNumber, KeyPosition: 2},
+		{Name: "ORDER_ID", DataType: "NUMBER", Kind: SampleKindNumber, KeyPosition: 1},
+	}
+
+	selected, skipped := selectSampleColumns(columns)
+	require.Len(t, selected, 3)
+	assert.Equal(t, "ORDER_ID", selected[0].Name)
+	assert.Equal(t, "LINE_NO", selected[1].Name)
+	assert.Equal(t, "NAME", selected[2].Name)
+	assert.Equal(t, []string{"NOTE(CLOB)"}, skipped)
+}
+
+func TestSampleValidatorWithFakeClients(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟sqlplus和psql依赖 /bin/sh")
+	}
+
+	// Oracle: 3行样本；PostgreSQL: 第2行金额不同，第3行缺失
+	oracleRows := []string{
+		strings.Join([]string{sampleHex("1"), sampleHex("Alice"), sampleHex("2024-01-01 10:00:00"), sampleHex(".5")}, "|"),
+		strings.Join([]string{sampleHex("2"), sampleHex("Bob"), sampleNullMarker, sampleHex("10")}, "|"),
+		strings.Join([]string{sampleHex("3"), sampleHex("Carol"), sampleNullMarker, sampleHex("7")}, "|"),
+	}
+	postgresRows := []string{
+		strings.Join([]string{sampleHex("1"), sampleHex("Alice"), sampleHex("2024-01-01 10:00:00"), sampleHex("0.50")}, "|"),
+		strings.Join([]string{sampleHex("2"), sampleHex("Bob"), sampleNullMarker, sampleHex("10.01")}, "|"),
+	}
+
+	oracleHome := t.TempDir()
+	sqlplus := `#!/bin/sh
+input=$(cat)
+case "$input" in
+  *all_tab_columns*) printf 'COL|ID|NUMBER|1\nCOL|NAME|VARCHAR2|0\nCOL|HIRED|DATE|0\nCOL|PHOTO|BLOB|0\nCOL|SALARY|NUMBER|0\n' ;;
+  *DBMS_RANDOM*) printf 'ROW|%s\nROW|%s\nROW|%s\n' '` + oracleRows[0] + `' '` + oracleRows[1] + `' '` + oracleRows[2] + `' ;;
+esac
+`
+	require.NoError(t, os.WriteFile(filepath.Join(oracleHome, "sqlplus"), []byte(sqlplus), 0755))
+
+	pgBin := t.TempDir()
+	psql := `#!/bin/sh
+input=$(cat)
+case "$input" in
+  *'"emp"'*) printf 'ROW|%s\nROW|%s\n' '` + postgresRows[0] + `' '` + postgresRows[1] + `' ;;
+esac
+`
+	require.NoError(t, os.WriteFile(filepath.Join(pgBin, "psql"), []byte(psql), 0755))
+	t.Setenv("PATH", pgBin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("校验项目")
+	cfg := manager.GetConfig()
+	cfg.Oracle.Username = "scott"
+	cfg.OracleClient = config.OracleClientConfig{Home: oracleHome, AutoDetect: false}
+
+	validator := NewSampleValidator(cfg)
+	result, err := validator.ValidateTable(context.Background(), "emp", 3)
+	require.NoError(t, err)
+
+	assert.Equal(t, "EMP", result.Table)
+	assert.Equal(t, 3, result.Sampled)
+	assert.Equal(t, 1, result.Matched)
+	assert.Equal(t, []string{"ID", "NAME", "HIRED", "SALARY"}, result.Columns)
+	assert.Equal(t, []string{"PHOTO(BLOB)"}, result.SkippedColumns)
+	require.Len(t, result.Mismatches, 2)
+	assert.Equal(t, SampleMismatch{Key: `ID="2"`, Column: "SALARY", Oracle: `"10"`, Postgres: `"10.01"`}, result.Mismatches[0])
+	assert.Equal(t, `ID="3"`, result.Mismatches[1].Key)
+	assert.Empty(t, result.Mismatches[1].Column)
+
+	// 主键列生成PostgreSQL查询条件
+	query := validator.postgresSampleQuery("EMP", []SampleColumn{
+		{Name: "ID", DataType: "NUMBER", Kind: SampleKindNumber, KeyPosition: 1},
+		{Name: "NAME", DataType: "VARCHAR2", Kind: SampleKindText},
+	}, [][]string{{sampleHex("1"), sampleHex("O'Brien")}})
+	assert.Contains(t, query, `FROM "public"."emp" WHERE ("id") IN (('1'))`)
+	assert.Contains(t, query, `nullif("name"::text, '')`)
+
+	// 没有主键的表无法定位行
+	noKey := `#!/bin/sh
+cat > /dev/null
+printf 'COL|NAME|VARCHAR2|0\n'
+`
+	require.NoError(t, os.WriteFile(filepath.Join(oracleHome, "sqlplus"), []byte(noKey), 0755))
+	_, err = validator.ValidateTable(context.Background(), "LOGS", 3)
+	assert.Equal(t, "SAMPLE_NO_PRIMARY_KEY", utils.GetErrorCode(err))
+}
+
+func TestSampleValidatorPrefetchColumns(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟sqlplus依赖 /bin/sh")
+	}
+
+	// 模拟sqlplus：回显PROMPT分隔行，按表名返回列，并记录启动次数
+	oracleHome := t.TempDir()
+	calls := filepath.Join(oracleHome, "calls")
+	sqlplus := `#!/bin/sh
+echo run >> '` + calls + `'
+while IFS= read -r line; do
+  case "$line" in
+    PROMPT*) echo "${line#PROMPT }" ;;
+    *"c.table_name = 'EMP'"*) printf 'COL|ID|NUMBER|1\nCOL|NAME|VARCHAR2|0\n' ;;
+    *"c.table_name = 'DEPT'"*) printf 'COL|DEPTNO|NUMBER|1\n' ;;
+  esac
+done
+`
+	require.NoError(t, os.WriteFile(filepath.Join(oracleHome, "sqlplus"), []byte(sqlplus), 0755))
+
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("校验项目")
+	cfg := manager.GetConfig()
+	cfg.Oracle.Username = "scott"
+	cfg.OracleClient = config.OracleClientConfig{Home: oracleHome, AutoDetect: false}
+
+	validator := NewSampleValidator(cfg)
+	require.NoError(t, validator.PrefetchColumns(context.Background(), []string{"emp", "DEPT", "MISSING"}))
+
+	data, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "run"), "多张表应在同一个sqlplus会话中查询")
+
+	columns, err := validator.tableColumns(context.Background(), "EMP")
+	require.NoError(t, err)
+	require.Len(t, columns, 2)
+	assert.Equal(t, 1, columns[0].KeyPosition)
+	columns, err = validator.tableColumns(context.Background(), "DEPT")
+	require.NoError(t, err)
+	assert.Equal(t, "DEPTNO", columns[0].Name)
+	// 不存在的表同样缓存为空结果
+	columns, err = validator.tableColumns(context.Background(), "MISSING")
+	require.NoError(t, err)
+	assert.Empty(t, columns)
+
+	data, err = os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "run"))
+}
@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"ora2pg-admin/internal/utils"
+)
+
+// 队列任务类型，与 迁移 子命令对应
+const (
+	TaskTypeStructure = "结构"
+	TaskTypeData      = "数据"
+	TaskTypeAll       = "全部"
+)
+
+// taskTypeAliases 任务文件中可使用的英文类型名
+var taskTypeAliases = map[string]string{
+	"structure": TaskTypeStructure,
+	"data":      TaskTypeData,
+	"all":       TaskTypeAll,
+}
+
+// 任务优先级，等待中的任务按优先级从高到低执行，同一优先级按任务文件中的顺序
+const (
+	TaskPriorityUrgent = "紧急"
+	TaskPriorityHigh   = "高"
+	TaskPriorityNormal = "普通"
+	TaskPriorityLow    = "低"
+)
+
+// taskPriorityRanks 各优先级的排序值，越大越先执行
+var taskPriorityRanks = map[string]int{
+	TaskPriorityUrgent: 3,
+	TaskPriorityHigh:   2,
+	TaskPriorityNormal: 1,
+	TaskPriorityLow:    0,
+}
+
+// taskPriorityAliases 任务文件中可使用的英文优先级
+var taskPriorityAliases = map[string]string{
+	"urgent": TaskPriorityUrgent,
+	"high":   TaskPriorityHigh,
+	"normal": TaskPriorityNormal,
+	"low":    TaskPriorityLow,
+}
+
+// scheduleLayouts 支持的调度时间格式，只有时分时表示下一次到达该时间
+var scheduleLayouts = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	time.RFC3339,
+}
+
+// ScheduledTask 队列中的一个迁移任务
+type ScheduledTask struct {
+	Name string            `yaml:"name"`
+	Type string            `yaml:"type"`
+	At   string            `yaml:"at,omitempty"`
+	Tags map[string]string `yaml:"tags,omitempty"`
+	Note string            `yaml:"note,omitempty"`
+	// Priority 紧急、高、普通、低（或 urgent、high、normal、low），默认普通
+	Priority string `yaml:"priority,omitempty"`
+
+	// StartAt 解析后的最早开始时间，零值表示上一个任务结束后立即执行
+	StartAt time.Time `yaml:"-"`
+}
+
+// TaskQueue 任务文件内容
+type TaskQueue struct {
+	ContinueOnError bool             `yaml:"continue_on_error"`
+	Tasks           []*ScheduledTask `yaml:"tasks"`
+}
+
+// TaskResult 队列任务的执行结果
+type TaskResult struct {
+	Task      *ScheduledTask
+	StartTime time.Time
+	EndTime   time.Time
+	Err       error
+	Skipped   bool
+}
+
+// TaskRunner 执行单个任务，每次调用应自行创建和释放迁移所需资源
+type TaskRunner func(ctx context.Context, task *ScheduledTask) error
+
+// TaskQueueLoader 重新读取任务文件，用于在任务之间加入新任务或调整优先级
+type TaskQueueLoader func() (*TaskQueue, error)
+
+// Scheduler 迁移任务调度器，按优先级和顺序执行任务，到达开始时间前等待
+type Scheduler struct {
+	logger *utils.Logger
+	now    func() time.Time
+	reload TaskQueueLoader
+}
+
+// NewScheduler 创建任务调度器
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		logger: utils.GetGlobalLogger(),
+		now:    time.Now,
+	}
+}
+
+// SetReloader 设置任务文件的重新读取方式，每个任务结束后用最新的任务文件刷新等待队列
+func (s *Scheduler) SetReloader(reload TaskQueueLoader) {
+	s.reload = reload
+}
+
+// ParseScheduleTime 解析调度时间，支持 "02:00"、"2024-01-02 02:00" 和 RFC3339，
+// 只有时分时取 after 之后最近一次到达该时间（已过则为次日）
+func ParseScheduleTime(spec string, after time.Time) (time.Time, error) {
+	spec = strings.TrimSpace(spec)
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if clock, err := time.ParseInLocation(layout, spec, after.Location()); err == nil {
+			at := time.Date(after.Year(), after.Month(), after.Day(),
+				clock.Hour(), clock.Minute(), clock.Second(), 0, after.Location())
+			if !at.After(after) {
+				at = at.AddDate(0, 0, 1)
+			}
+			return at, nil
+		}
+	}
+	for _, layout := range scheduleLayouts {
+		if at, err := time.ParseInLocation(layout, spec, after.Location()); err == nil {
+			return at, nil
+		}
+	}
+	return time.Time{}, utils.NewError(utils.ErrorTypeValidation, "INVALID_SCHEDULE").
+		Message(fmt.Sprintf("无法解析调度时间: %s", spec)).
+		Suggestion("请使用 HH:MM（如 02:00）或 YYYY-MM-DD HH:MM 格式").
+		Build()
+}
+
+// NormalizeTaskType 规范化任务类型，支持中文子命令名和 structure/data/all
+func NormalizeTaskType(taskType string) (string, error) {
+	taskType = strings.TrimSpace(taskType)
+	switch taskType {
+	case TaskTypeStructure, TaskTypeData, TaskTypeAll:
+		return taskType, nil
+	}
+	if alias, ok := taskTypeAliases[strings.ToLower(taskType)]; ok {
+		return alias, nil
+	}
+	return "", utils.NewError(utils.ErrorTypeValidation, "INVALID_TASK_TYPE").
+		Message(fmt.Sprintf("未知的任务类型: %s", taskType)).
+		Suggestion("任务类型可选: 结构、数据、全部（或 structure、data、all）").
+		Build()
+}
+
+// NormalizeTaskPriority 规范化任务优先级，为空时为普通
+func NormalizeTaskPriority(priority string) (string, error) {
+	priority = strings.TrimSpace(priority)
+	if priority == "" {
+		return TaskPriorityNormal, nil
+	}
+	if _, ok := taskPriorityRanks[priority]; ok {
+		return priority, nil
+	}
+	if alias, ok := taskPriorityAliases[strings.ToLower(priority)]; ok {
+		return alias, nil
+	}
+	return "", utils.NewError(utils.ErrorTypeValidation, "INVALID_TASK_PRIORITY").
+		Message(fmt.Sprintf("未知的任务优先级: %s", priority)).
+		Suggestion("任务优先级可选: 紧急、高、普通、低（或 urgent、high、normal、low）").
+		Build()
+}
+
+// LoadTaskQueue 读取任务文件并校验任务类型、优先级和开始时间
+func LoadTaskQueue(path string, start time.Time) (*TaskQueue, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, utils.FileErrors.NotFound(path)
+		}
+		return nil, utils.FileErrors.ReadFailed(path, err)
+	}
+
+	var queue TaskQueue
+	if err := yaml.Unmarshal(data, &queue); err != nil {
+		return nil, utils.ConfigErrors.ParseFailed(err)
+	}
+	if len(queue.Tasks) == 0 {
+		return nil, utils.ValidationErrors.Required("tasks")
+	}
+	if err := queue.resolve(start); err != nil {
+		return nil, err
+	}
+	return &queue, nil
+}
+
+// resolve 校验任务并解析开始时间；只有时分的时间按队列顺序递增，
+// 例如 23:00 之后的 01:00 表示次日凌晨
+func (q *TaskQueue) resolve(start time.Time) error {
+	previous := start
+	names := make(map[string]bool, len(q.Tasks))
+	for i, task := range q.Tasks {
+		if task == nil {
+			return utils.ValidationErrors.Required(fmt.Sprintf("tasks[%d]", i))
+		}
+		if task.Name == "" {
+			task.Name = fmt.Sprintf("任务%d", i+1)
+		}
+		// 重新读取任务文件时按名称对应已执行和等待中的任务
+		if names[task.Name] {
+			return utils.NewError(utils.ErrorTypeValidation, "DUPLICATE_TASK_NAME").
+				Message(fmt.Sprintf("任务名称重复: %s", task.Name)).
+				Suggestion("为每个任务指定不同的 name").
+				Build()
+		}
+		names[task.Name] = true
+
+		taskType, err := NormalizeTaskType(task.Type)
+		if err != nil {
+			return err
+		}
+		task.Type = taskType
+		priority, err := NormalizeTaskPriority(task.Priority)
+		if err != nil {
+			return err
+		}
+		task.Priority = priority
+
+		if task.At == "" {
+			continue
+		}
+		at, err := ParseScheduleTime(task.At, previous)
+		if err != nil {
+			return err
+		}
+		task.StartAt = at
+		previous = at
+	}
+	return nil
+}
+
+// WaitUntil 等待到指定时间，期间可通过 ctx 取消
+func (s *Scheduler) WaitUntil(ctx context.Context, at time.Time) error {
+	wait := at.Sub(s.now())
+	if wait <= 0 {
+		return nil
+	}
+	s.logger.Infof("等待到 %s 执行（%v 后）", at.Format("2006-01-02 15:04:05"), wait.Truncate(time.Second))
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		s.logger.Warnf("等待期间已取消调度: %v", ctx.Err())
+		return ctx.Err()
+	}
+}
+
+// ExecutionOrder 任务的执行顺序：按优先级从高到低，同一优先级保持任务文件中的顺序
+func (q *TaskQueue) ExecutionOrder() []*ScheduledTask {
+	tasks := append([]*ScheduledTask(nil), q.Tasks...)
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return taskPriorityRanks[tasks[i].Priority] > taskPriorityRanks[tasks[j].Priority]
+	})
+	return tasks
+}
+
+// RunQueue 按优先级依次执行队列中的任务；任务失败时，未设置 continue_on_error 则跳过剩余任务
+//
+// 正在执行的任务不会被抢占。设置了 SetReloader 时每个任务结束后重新读取任务文件，
+// 新增的任务和优先级的调整只影响等待中的任务，已开始的任务按名称排除。结果按实际执行顺序排列。
+func (s *Scheduler) RunQueue(ctx context.Context, queue *TaskQueue, runner TaskRunner) []*TaskResult {
+	waiting := queue.ExecutionOrder()
+	results := make([]*TaskResult, 0, len(waiting))
+	started := make(map[string]bool, len(waiting))
+	stopped := false
+
+	for len(waiting) > 0 {
+		if len(results) > 0 && !stopped && ctx.Err() == nil {
+			waiting = s.refreshWaiting(waiting, started)
+			if len(waiting) == 0 {
+				break
+			}
+		}
+		task := waiting[0]
+		waiting = waiting[1:]
+		started[task.Name] = true
+		position, total := len(results)+1, len(results)+1+len(waiting)
+
+		result := &TaskResult{Task: task}
+		results = append(results, result)
+
+		if stopped || ctx.Err() != nil {
+			result.Skipped = true
+			s.logger.Infof("跳过任务 %d/%d: %s", position, total, task.Name)
+			continue
+		}
+
+		if !task.StartAt.IsZero() {
+			if err := s.WaitUntil(ctx, task.StartAt); err != nil {
+				result.Skipped = true
+				continue
+			}
+		}
+
+		result.StartTime = s.now()
+		s.logger.Infof("开始任务 %d/%d: %s（%s，优先级%s）", position, total, task.Name, task.Type, task.Priority)
+		result.Err = runner(ctx, task)
+		result.EndTime = s.now()
+
+		if result.Err != nil {
+			s.logger.Errorf("任务 %s 失败，耗时 %v: %v", task.Name, result.EndTime.Sub(result.StartTime), result.Err)
+			stopped = !queue.ContinueOnError
+			continue
+		}
+		s.logger.Infof("任务 %s 完成，耗时 %v", task.Name, result.EndTime.Sub(result.StartTime))
+	}
+	return results
+}
+
+// refreshWaiting 重新读取任务文件，返回其中尚未开始的任务；读取或校验失败时保留原等待队列
+func (s *Scheduler) refreshWaiting(waiting []*ScheduledTask, started map[string]bool) []*ScheduledTask {
+	if s.reload == nil {
+		return waiting
+	}
+	latest, err := s.reload()
+	if err != nil {
+		s.logger.Warnf("重新读取任务文件失败，继续使用原等待队列: %v", err)
+		return waiting
+	}
+
+	var refreshed []*ScheduledTask
+	for _, task := range latest.ExecutionOrder() {
+		if !started[task.Name] {
+			refreshed = append(refreshed, task)
+		}
+	}
+	if taskNames(refreshed) != taskNames(waiting) {
+		s.logger.Infof("任务文件已更新，等待中的任务: %s", taskNames(refreshed))
+	}
+	return refreshed
+}
+
+// taskNames 按顺序连接任务名称，用于比较和显示等待队列
+func taskNames(tasks []*ScheduledTask) string {
+	names := make([]string, len(tasks))
+	for i, task := range tasks {
+		names[i] = task.Name
+	}
+	return strings.Join(names, "、")
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/utils"
+)
+
+func TestParseScheduleTime(t *testing.T) {
+	now := time.Date(2024, 3, 1, 15, 30, 0, 0, time.Local)
+
+	at, err := ParseScheduleTime("18:00", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 18, 0, 0, 0, time.Local), at)
+
+	// 已过的时间为次日
+	at, err = ParseScheduleTime("02:00", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 2, 2, 0, 0, 0, time.Local), at)
+
+	at, err = ParseScheduleTime("2024-03-05 01:30", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 5, 1, 30, 0, 0, time.Local), at)
+
+	_, err = ParseScheduleTime("明天凌晨", now)
+	assert.Equal(t, "INVALID_SCHEDULE", utils.GetErrorCode(err))
+}
+
+func TestLoadTaskQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.yaml")
+	content := `continue_on_error: true
+tasks:
+  - name: 结构
+    type: structure
+    at: "23:00"
+  - type: 数据
+    at: "01:00"
+  - name: 收尾
+    type: 全部
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	start := time.Date(2024, 3, 1, 15, 0, 0, 0, time.Local)
+	queue, err := LoadTaskQueue(path, start)
+	require.NoError(t, err)
+	require.Len(t, queue.Tasks, 3)
+	assert.True(t, queue.ContinueOnError)
+
+	assert.Equal(t, TaskTypeStructure, queue.Tasks[0].Type)
+	assert.Equal(t, time.Date(2024, 3, 1, 23, 0, 0, 0, time.Local), queue.Tasks[0].StartAt)
+	// 23:00 之后的 01:00 为次日凌晨
+	assert.Equal(t, "任务2", queue.Tasks[1].Name)
+	assert.Equal(t, time.Date(2024, 3, 2, 1, 0, 0, 0, time.Local), queue.Tasks[1].StartAt)
+	assert.True(t, queue.Tasks[2].StartAt.IsZero())
+
+	require.NoError(t, os.WriteFile(path, []byte("tasks:\n  - type: 回滚\n"), 0644))
+	_, err = LoadTaskQueue(path, start)
+	assert.Equal(t, "INVALID_TASK_TYPE", utils.GetErrorCode(err))
+
+	_, err = LoadTaskQueue(filepath.Join(t.TempDir(), "missing.yaml"), start)
+	assert.Error(t, err)
+}
+
+func TestLoadTaskQueuePriority(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.yaml")
+	content := `tasks:
+  - name: a
+    type: 结构
+  - name: b
+    type: 数据
+    priority: low
+  - name: c
+    type: 数据
+    priority: 紧急
+  - name: d
+    type: 全部
+`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	queue, err := LoadTaskQueue(path, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, TaskPriorityNormal, queue.Tasks[0].Priority)
+	assert.Equal(t, TaskPriorityLow, queue.Tasks[1].Priority)
+	// 同一优先级保持文件中的顺序
+	assert.Equal(t, "c、a、d、b", taskNames(queue.ExecutionOrder()))
+	assert.Equal(t, "a、b、c、d", taskNames(queue.Tasks), "不修改任务文件中的顺序")
+
+	require.NoError(t, os.WriteFile(path, []byte("tasks:\n  - type: 数据\n    priority: 最高\n"), 0644))
+	_, err = LoadTaskQueue(path, time.Now())
+	assert.Equal(t, "INVALID_TASK_PRIORITY", utils.GetErrorCode(err))
+
+	require.NoError(t, os.WriteFile(path, []byte("tasks:\n  - name: a\n    type: 数据\n  - name: a\n    type: 结构\n"), 0644))
+	_, err = LoadTaskQueue(path, time.Now())
+	assert.Equal(t, "DUPLICATE_TASK_NAME", utils.GetErrorCode(err))
+}
+
+func TestSchedulerWaitUntilCancel(t *testing.T) {
+	scheduler := NewScheduler()
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	started := time.Now()
+	err := scheduler.WaitUntil(ctx, started.Add(time.Hour))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(started), time.Second)
+
+	// 已过的时间不等待
+	assert.NoError(t, scheduler.WaitUntil(context.Background(), started.Add(-time.Minute)))
+}
+
+func TestSchedulerRunQueue(t *testing.T) {
+	tasks := func() []*ScheduledTask {
+		return []*ScheduledTask{
+			{Name: "a", Type: TaskTypeStructure},
+			{Name: "b", Type: TaskTypeData, StartAt: time.Now().Add(-time.Minute)},
+			{Name: "c", Type: TaskTypeAll},
+		}
+	}
+	var executed []string
+	runner := func(ctx context.Context, task *ScheduledTask) error {
+		executed = append(executed, task.Name)
+		if task.Name == "b" {
+			return errors.New("迁移失败")
+		}
+		return nil
+	}
+
+	// 默认失败后跳过剩余任务
+	results := NewScheduler().RunQueue(context.Background(), &TaskQueue{Tasks: tasks()}, runner)
+	assert.Equal(t, []string{"a", "b"}, executed)
+	require.Len(t, results, 3)
+	assert.NoError(t, results[0].Err)
+	assert.Error(t, results[1].Err)
+	assert.True(t, results[2].Skipped)
+
+	executed = nil
+	results = NewScheduler().RunQueue(context.Background(), &TaskQueue{ContinueOnError: true, Tasks: tasks()}, runner)
+	assert.Equal(t, []string{"a", "b", "c"}, executed)
+	assert.False(t, results[2].Skipped)
+}
+
+func TestSchedulerRunQueueReload(t *testing.T) {
+	queue := &TaskQueue{Tasks: []*ScheduledTask{
+		{Name: "a", Type: TaskTypeStructure, Priority: TaskPriorityNormal},
+		{Name: "b", Type: TaskTypeData, Priority: TaskPriorityNormal},
+		{Name: "c", Type: TaskTypeData, Priority: TaskPriorityLow},
+	}}
+
+	var executed []string
+	scheduler := NewScheduler()
+	scheduler.SetReloader(func() (*TaskQueue, error) {
+		if len(executed) != 1 {
+			return nil, errors.New("任务文件格式错误")
+		}
+		// 第一个任务执行期间加入紧急任务、调低 b 的优先级并删除 c
+		return &TaskQueue{Tasks: []*ScheduledTask{
+			{Name: "a", Type: TaskTypeStructure, Priority: TaskPriorityLow},
+			{Name: "b", Type: TaskTypeData, Priority: TaskPriorityLow},
+			{Name: "d", Type: TaskTypeData, Priority: TaskPriorityNormal},
+			{Name: "urgent", Type: TaskTypeData, Priority: TaskPriorityUrgent},
+		}}, nil
+	})
+	results := scheduler.RunQueue(context.Background(), queue, func(ctx context.Context, task *ScheduledTask) error {
+		executed = append(executed, task.Name)
+		return nil
+	})
+
+	// 已执行的 a 不再执行；之后读取失败时保留原等待队列
+	assert.Equal(t, []string{"a", "urgent", "d", "b"}, executed)
+	require.Len(t, results, 4)
+	assert.Equal(t, "urgent", results[1].Task.Name)
+}
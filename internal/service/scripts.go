@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/postgres"
+	"ora2pg-admin/internal/utils"
+)
+
+// ScriptPhase 脚本执行的时机
+type ScriptPhase string
+
+const (
+	ScriptPhasePre  ScriptPhase = "pre"
+	ScriptPhasePost ScriptPhase = "post"
+)
+
+// scriptPhaseTexts 脚本时机的中文名称
+var scriptPhaseTexts = map[ScriptPhase]string{
+	ScriptPhasePre:  "迁移前",
+	ScriptPhasePost: "迁移后",
+}
+
+// MigrationScript 迁移前后执行的一个SQL脚本
+type MigrationScript struct {
+	Phase  ScriptPhase `json:"phase"`
+	Path   string      `json:"path"`
+	Target string      `json:"target"`
+}
+
+// ScriptResult 脚本的执行结果，中止后未执行的脚本状态为 PENDING
+type ScriptResult struct {
+	MigrationScript
+	Status   ExecutionStatus `json:"status"`
+	Duration time.Duration   `json:"duration"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// ListMigrationScripts 列出目录下的 *.sql 脚本并按文件名排序，目录不存在时返回空列表
+//
+// 文件名以 .oracle.sql 或 .postgresql.sql 结尾时在对应的库执行，否则在 defaultTarget 执行。
+func ListMigrationScripts(dir string, phase ScriptPhase, defaultTarget string) ([]MigrationScript, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, utils.FileErrors.ReadFailed(dir, err)
+	}
+
+	var scripts []MigrationScript
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(name), ".sql") {
+			continue
+		}
+		scripts = append(scripts, MigrationScript{
+			Phase:  phase,
+			Path:   filepath.Join(dir, name),
+			Target: scriptTarget(name, defaultTarget),
+		})
+	}
+	sort.Slice(scripts, func(i, j int) bool {
+		return filepath.Base(scripts[i].Path) < filepath.Base(scripts[j].Path)
+	})
+	return scripts, nil
+}
+
+// scriptTarget 根据文件名确定脚本的目标库
+func scriptTarget(name, defaultTarget string) string {
+	base := strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+	for _, target := range []string{config.ScriptTargetOracle, config.ScriptTargetPostgreSQL} {
+		if strings.HasSuffix(base, "."+target) {
+			return target
+		}
+	}
+	return defaultTarget
+}
+
+// runMigrationScripts 按文件名顺序执行迁移前或迁移后的脚本
+//
+// 失败策略为 abort 时第一个失败的脚本中止执行并返回错误，其余脚本不再执行；为 continue 时记录失败后继续。
+func (ms *MigrationService) runMigrationScripts(ctx context.Context, phase ScriptPhase) error {
+	scriptsConfig := &ms.config.Migration.Scripts
+	if scriptsConfig.Disabled {
+		return nil
+	}
+	// 只导出到文件时不连接目标库
+	if ms.fileOnly {
+		ms.logger.Infof("只导出到文件，跳过%s脚本", scriptPhaseTexts[phase])
+		return nil
+	}
+	dir := scriptsConfig.PreScriptsDir()
+	if phase == ScriptPhasePost {
+		dir = scriptsConfig.PostScriptsDir()
+	}
+	scripts, err := ListMigrationScripts(dir, phase, scriptsConfig.DefaultTarget())
+	if err != nil || len(scripts) == 0 {
+		return err
+	}
+
+	ms.logger.Infof("执行%s脚本: %s 下共 %d 个", scriptPhaseTexts[phase], dir, len(scripts))
+	var abortErr error
+	for _, script := range scripts {
+		result := &ScriptResult{MigrationScript: script, Status: StatusPending}
+		ms.scriptResults = append(ms.scriptResults, result)
+		if abortErr != nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			result.Status = StatusCancelled
+			abortErr = ctx.Err()
+			continue
+		}
+
+		start := time.Now()
+		output, err := ms.runScript(ctx, script)
+		result.Duration = time.Since(start)
+		if err == nil {
+			result.Status = StatusCompleted
+			ms.logger.Infof("脚本 %s 在 %s 执行成功（%v）", script.Path, script.Target, result.Duration.Round(time.Millisecond))
+			continue
+		}
+
+		result.Status = StatusFailed
+		result.Error = err.Error()
+		ms.logger.Errorf("脚本 %s 在 %s 执行失败: %v", script.Path, script.Target, err)
+		if output != "" {
+			ms.logger.Debugf("脚本 %s 的输出:\n%s", script.Path, output)
+		}
+		if !scriptsConfig.ContinueOnError() {
+			abortErr = utils.NewError(utils.ErrorTypeMigration, "MIGRATION_SCRIPT_FAILED").
+				Message(fmt.Sprintf("%s脚本 %s 执行失败", scriptPhaseTexts[phase], script.Path)).
+				Details(err.Error()).
+				Cause(err).
+				Suggestion("修复脚本后重新执行迁移，脚本应可重复执行").
+				Suggestion("希望脚本失败后继续迁移时，设置 migration.scripts.on_error: continue").
+				Build()
+		}
+	}
+	return abortErr
+}
+
+// runScript 通过sqlplus或psql执行脚本文件
+func (ms *MigrationService) runScript(ctx context.Context, script MigrationScript) (string, error) {
+	if script.Target == config.ScriptTargetOracle {
+		return oracle.NewSQLPlusRunner(&ms.config.Oracle, &ms.config.OracleClient).RunFile(ctx, script.Path)
+	}
+	return postgres.NewPSQLRunner(&ms.config.PostgreSQL).RunFile(ctx, script.Path)
+}
+
+// ScriptResults 本次迁移执行的迁移前后脚本及结果
+func (ms *MigrationService) ScriptResults() []*ScriptResult {
+	return ms.scriptResults
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// writeScripts 在目录下创建脚本文件
+func writeScripts(t *testing.T, dir string, contents map[string]string) {
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	for name, content := range contents {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+}
+
+func TestListMigrationScripts(t *testing.T) {
+	dir := t.TempDir()
+	writeScripts(t, dir, map[string]string{
+		"20_grants.sql":                "GRANT ...;",
+		"10_extensions.postgresql.sql": "CREATE EXTENSION ...;",
+		"05_stats.oracle.sql":          "BEGIN ... END;\n/",
+		"README.md":                    "说明",
+	})
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "archive.sql"), 0755))
+
+	scripts, err := ListMigrationScripts(dir, ScriptPhasePre, config.ScriptTargetPostgreSQL)
+	require.NoError(t, err)
+	assert.Equal(t, []MigrationScript{
+		{Phase: ScriptPhasePre, Path: filepath.Join(dir, "05_stats.oracle.sql"), Target: config.ScriptTargetOracle},
+		{Phase: ScriptPhasePre, Path: filepath.Join(dir, "10_extensions.postgresql.sql"), Target: config.ScriptTargetPostgreSQL},
+		{Phase: ScriptPhasePre, Path: filepath.Join(dir, "20_grants.sql"), Target: config.ScriptTargetPostgreSQL},
+	}, scripts)
+
+	// 目录不存在时没有脚本
+	scripts, err = ListMigrationScripts(filepath.Join(dir, "missing"), ScriptPhasePost, config.ScriptTargetOracle)
+	require.NoError(t, err)
+	assert.Empty(t, scripts)
+}
+
+func TestRunMigrationScripts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟psql依赖 /bin/sh")
+	}
+
+	// 模拟psql：脚本内容包含 FAIL 时报错
+	bin := t.TempDir()
+	psql := `#!/bin/sh
+input=$(cat)
+file=$(echo "$input" | sed -n "s/^\\\\i '\(.*\)'$/\1/p")
+if grep -q FAIL "$file"; then
+  echo "psql:$file:1: ERROR:  42704: extension \"missing\" is not available"
+  exit 3
+fi
+`
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "psql"), []byte(psql), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	dir := t.TempDir()
+	writeScripts(t, filepath.Join(dir, "pre"), map[string]string{
+		"01_ok.sql":   "SELECT 1;",
+		"02_fail.sql": "FAIL",
+		"03_next.sql": "SELECT 3;",
+	})
+
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("脚本项目")
+	cfg := manager.GetConfig()
+	cfg.Migration.Scripts.PreDir = filepath.Join(dir, "pre")
+
+	// 默认中止：失败后的脚本不再执行
+	ms := NewMigrationService(cfg)
+	err := ms.runMigrationScripts(context.Background(), ScriptPhasePre)
+	assert.Equal(t, "MIGRATION_SCRIPT_FAILED", utils.GetErrorCode(err))
+	results := ms.ScriptResults()
+	require.Len(t, results, 3)
+	assert.Equal(t, []ExecutionStatus{StatusCompleted, StatusFailed, StatusPending},
+		[]ExecutionStatus{results[0].Status, results[1].Status, results[2].Status})
+	assert.Contains(t, results[1].Error, "42704")
+
+	// 继续策略：记录失败后执行后续脚本
+	cfg.Migration.Scripts.OnError = config.ScriptOnErrorContinue
+	ms = NewMigrationService(cfg)
+	require.NoError(t, ms.runMigrationScripts(context.Background(), ScriptPhasePre))
+	results = ms.ScriptResults()
+	require.Len(t, results, 3)
+	assert.Equal(t, StatusFailed, results[1].Status)
+	assert.Equal(t, StatusCompleted, results[2].Status)
+
+	// 禁用后不执行
+	cfg.Migration.Scripts.Disabled = true
+	ms = NewMigrationService(cfg)
+	require.NoError(t, ms.runMigrationScripts(context.Background(), ScriptPhasePre))
+	assert.Empty(t, ms.ScriptResults())
+}
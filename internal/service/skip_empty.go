@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+)
+
+// inventoryCheckTimeout 检查源库各类对象是否存在的超时时间
+const inventoryCheckTimeout = 30 * time.Second
+
+// alwaysRunTypes 源库Schema中没有对象时也执行的类型：公共数据库链接和公共同义词的属主是 PUBLIC，不在Schema的对象统计中
+var alwaysRunTypes = map[MigrationType]bool{
+	MigrationTypeDBLink:  true,
+	MigrationTypeSynonym: true,
+}
+
+// SetSkipEmptyTypes 设置是否跳过源库中没有对应对象的迁移类型，如源库没有触发器时跳过 TRIGGER
+func (ms *MigrationService) SetSkipEmptyTypes(skip bool) {
+	ms.skipEmptyTypes = skip
+}
+
+// skipEmptyType 源库中没有该类型的对象时返回跳过的结果，否则返回nil
+//
+// 只检查与源库对象类型一一对应的迁移类型，COPY、INSERT 按表判断；GRANT、DBLINK、SYNONYM 等类型总是执行。
+func (ms *MigrationService) skipEmptyType(ctx context.Context, migrationType MigrationType) *ExecutionResult {
+	if !ms.skipEmptyTypes || alwaysRunTypes[migrationType] {
+		return nil
+	}
+	objectType, ok := migrationTypeObjectTypes[migrationType]
+	if !ok {
+		return nil
+	}
+	inventory := ms.sourceInventory(ctx)
+	if count, found := inventory[objectType]; inventory == nil || !found || count > 0 {
+		return nil
+	}
+
+	ms.logger.Infof("源库中没有 %s 对象，跳过迁移类型 %s", objectType, migrationType)
+	now := time.Now()
+	return &ExecutionResult{
+		Status:    StatusSkipped,
+		StartTime: now,
+		EndTime:   now,
+		Progress:  &ProgressInfo{Percentage: 100, Message: fmt.Sprintf("源库中没有 %s 对象", objectType)},
+	}
+}
+
+// sourceInventory 源库各类对象的数量，整个迁移只查询一次
+//
+// 估算进度时已统计过的直接复用；源数据为dump文件或查询失败时返回nil，不跳过任何类型。
+func (ms *MigrationService) sourceInventory(ctx context.Context) oracle.ObjectInventory {
+	if ms.inventoryLoaded {
+		return ms.inventory
+	}
+	ms.inventoryLoaded = true
+
+	if ms.scale != nil && ms.scale.Objects != nil {
+		ms.inventory = ms.scale.Objects
+		return ms.inventory
+	}
+	if _, _, found := config.DumpFileReference(&ms.config.Oracle); found {
+		ms.logger.Info("源数据是dump文件，不检查源库对象是否存在")
+		return nil
+	}
+
+	schema := ms.config.Oracle.Schema
+	if schema == "" {
+		schema = ms.config.Oracle.Username
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, inventoryCheckTimeout)
+	defer cancel()
+	runner := oracle.NewSQLPlusRunner(&ms.config.Oracle, &ms.config.OracleClient)
+	inventory, err := oracle.NewInspector(runner, schema).ObjectCounts(checkCtx)
+	if err != nil {
+		ms.logger.Warnf("检查源库对象失败，按配置执行全部迁移类型: %v", err)
+		return nil
+	}
+	ms.inventory = inventory
+	return inventory
+}
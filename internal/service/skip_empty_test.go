@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+)
+
+func TestSkipEmptyTypes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟ora2pg依赖 /bin/sh")
+	}
+
+	// 模拟ora2pg：源库没有触发器时 TRIGGER 类型报错
+	bin := t.TempDir()
+	script := `#!/bin/sh
+while [ $# -gt 0 ]; do
+  case "$1" in
+    -t) type=$2 ;;
+  esac
+  shift
+done
+if [ "$type" = "TRIGGER" ]; then
+  echo "FATAL: no trigger found"; exit 1
+fi
+echo "-- $type" > "output/$type.sql"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "ora2pg"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Chdir(t.TempDir())
+
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("跳过")
+	cfg := manager.GetConfig()
+	cfg.Migration.OutputDir = "output"
+	require.NoError(t, os.MkdirAll("output", 0755))
+	require.NoError(t, os.WriteFile(filepath.Join("output", "ora2pg.conf"), []byte("ORACLE_DSN dbi:Oracle:host=db\n"), 0644))
+	types := []MigrationType{MigrationTypeTable, MigrationTypeTrigger, MigrationTypeGrant}
+	scale := &oracle.SchemaScale{Objects: oracle.ObjectInventory{"TABLE": 2, "TRIGGER": 0}}
+
+	// 复用估算进度时的对象统计，没有对象的类型跳过，GRANT 没有对应的对象类型总是执行
+	ms := NewMigrationService(cfg)
+	ms.SetSkipEmptyTypes(true)
+	ms.SetProgressScale(scale)
+	results, err := ms.ExecuteWithProgress(context.Background(), types, NewProgressTracker())
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, StatusCompleted, results[0].Status)
+	assert.Equal(t, StatusSkipped, results[1].Status)
+	assert.Equal(t, "源库中没有 TRIGGER 对象", results[1].Progress.Message)
+	assert.Equal(t, StatusCompleted, results[2].Status)
+	assert.NoFileExists(t, filepath.Join("output", "TRIGGER.sql"))
+
+	// 跳过的类型不算失败：整体成功，检查点记为已完成，通知中计入成功
+	assert.Equal(t, StatusCompleted, runStatus(ms.state, nil))
+	assert.True(t, ms.checkpoint.IsTypeCompleted(MigrationTypeTrigger))
+	summary := ms.ora2pgService.GetExecutionSummary(results)
+	assert.Equal(t, 2, summary["successful"])
+	assert.Equal(t, 1, summary["skipped"])
+	data := NewNotificationData(cfg, newHistoryRecord("迁移", types, ms.state, nil))
+	assert.Equal(t, 3, data.Succeeded)
+	assert.Equal(t, "跳过（无对象）", data.Results[1].Status)
+
+	// 未开启时照常执行
+	ms = NewMigrationService(cfg)
+	ms.SetProgressScale(scale)
+	results, err = ms.ExecuteWithProgress(context.Background(), types, NewProgressTracker())
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, results[1].Status)
+
+	// 源数据是dump文件时无法检查源库，不跳过
+	dumpConfig := *cfg
+	dumpConfig.Oracle.Host = "/data/export.dmp"
+	ms = NewMigrationService(&dumpConfig)
+	ms.SetSkipEmptyTypes(true)
+	assert.Nil(t, ms.skipEmptyType(context.Background(), MigrationTypeTrigger))
+	assert.True(t, ms.inventoryLoaded)
+}
+
+func TestSkipEmptyTypesKeepsLinksAndSynonyms(t *testing.T) {
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("跳过")
+	ms := NewMigrationService(manager.GetConfig())
+	ms.SetSkipEmptyTypes(true)
+	ms.SetProgressScale(&oracle.SchemaScale{Objects: oracle.ObjectInventory{"DATABASE LINK": 0, "SYNONYM": 0, "VIEW": 0}})
+
+	// Schema中没有私有链接和同义词时仍可能有公共的，总是执行
+	assert.Nil(t, ms.skipEmptyType(context.Background(), MigrationTypeDBLink))
+	assert.Nil(t, ms.skipEmptyType(context.Background(), MigrationTypeSynonym))
+	assert.NotNil(t, ms.skipEmptyType(context.Background(), MigrationTypeView))
+}
@@ -0,0 +1,405 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/postgres"
+	"ora2pg-admin/internal/utils"
+)
+
+// 校验发现的问题类别
+const (
+	// SQLCheckSyntax 语法错误，语句本身无法解析，通常是转换问题
+	SQLCheckSyntax = "syntax"
+	// SQLCheckRuntime 运行时错误，语法正确但执行失败，如依赖的对象不存在，与校验库的状态有关
+	SQLCheckRuntime = "runtime"
+)
+
+const (
+	// sqlCheckMaxBytes 校验的单条语句上限，超过的语句无法完整执行，计为跳过
+	sqlCheckMaxBytes = 16 * 1024 * 1024
+	// sqlCheckStatementTimeout 单条语句的执行超时
+	sqlCheckStatementTimeout = "60s"
+	// sqlCheckLockTimeout 等待锁的超时，避免校验阻塞在目标库正在使用的对象上
+	sqlCheckLockTimeout = "5s"
+)
+
+// sqlCheckHeader 校验脚本的开头：出错的语句回滚到该语句之前继续执行，最后回滚整个事务
+var sqlCheckHeader = []string{
+	`\set ON_ERROR_ROLLBACK on`,
+	"BEGIN;",
+	"SET LOCAL check_function_bodies = on;",
+	fmt.Sprintf("SET LOCAL statement_timeout = '%s';", sqlCheckStatementTimeout),
+	fmt.Sprintf("SET LOCAL lock_timeout = '%s';", sqlCheckLockTimeout),
+}
+
+// sqlSyntaxErrorCodes 视为语法错误的SQLSTATE（42601 syntax_error 等），函数体的PL/pgSQL语法错误同样为 42601
+var sqlSyntaxErrorCodes = map[string]bool{
+	"42601": true, // syntax_error
+	"42602": true, // invalid_name
+	"42622": true, // name_too_long
+	"42939": true, // reserved_name
+}
+
+// sqlCheckSkippedKinds 不执行的语句：psql元命令、事务控制（会结束校验事务）和数据语句（数据量大，且不是转换问题的常见来源）
+var sqlCheckSkippedKinds = map[string]bool{
+	SQLKindPSQL: true,
+	"BEGIN":     true, "START": true, "COMMIT": true, "END": true, "ROLLBACK": true, "ABORT": true,
+	"SAVEPOINT": true, "RELEASE": true, "PREPARE": true,
+	"INSERT": true, "COPY": true,
+}
+
+var (
+	// psqlCheckErrorPattern psql输出的错误，如 "psql:<stdin>:12: ERROR:  42601: syntax error at or near "TABL""
+	psqlCheckErrorPattern = regexp.MustCompile(`^psql:.*?:(\d+):\s+(?:ERROR|FATAL):\s+(?:([0-9A-Z]{5}):\s+)?(.*)$`)
+	// psqlErrorLinePattern 错误位置所在的行（相对于语句），如 "LINE 3: ..."
+	psqlErrorLinePattern = regexp.MustCompile(`^LINE (\d+):`)
+)
+
+// SQLCheckIssue 校验发现的一个问题
+type SQLCheckIssue struct {
+	File string `json:"file"`
+	// Line 错误在文件中的行号，psql没有给出位置时为语句的起始行
+	Line     int    `json:"line"`
+	Kind     string `json:"kind"`
+	Category string `json:"category"`
+	Code     string `json:"code,omitempty"`
+	Message  string `json:"message"`
+	// Statement 语句的第一行
+	Statement string `json:"statement"`
+}
+
+// SQLCheckFileResult 一个SQL文件的校验结果
+type SQLCheckFileResult struct {
+	File       string          `json:"file"`
+	Statements int             `json:"statements"`
+	Skipped    int             `json:"skipped"`
+	Issues     []SQLCheckIssue `json:"issues,omitempty"`
+	// Error 文件无法读取或校验没有完成
+	Error string `json:"error,omitempty"`
+}
+
+// SQLCheckReport SQL语法预校验报告
+type SQLCheckReport struct {
+	Database string                `json:"database"`
+	Files    []*SQLCheckFileResult `json:"files"`
+	// Statements 执行校验的语句数，Skipped 为跳过的元命令、事务控制和数据语句
+	Statements    int           `json:"statements"`
+	Skipped       int           `json:"skipped"`
+	SyntaxErrors  int           `json:"syntax_errors"`
+	RuntimeErrors int           `json:"runtime_errors"`
+	FailedFiles   int           `json:"failed_files"`
+	Duration      time.Duration `json:"duration"`
+}
+
+// Issues 指定类别的全部问题，按文件和行号排列
+func (r *SQLCheckReport) Issues(category string) []SQLCheckIssue {
+	var issues []SQLCheckIssue
+	for _, file := range r.Files {
+		for _, issue := range file.Issues {
+			if issue.Category == category {
+				issues = append(issues, issue)
+			}
+		}
+	}
+	return issues
+}
+
+// RuntimeErrorCodes 运行时错误按SQLSTATE统计的数量，按数量从多到少排列，如 ["42P01 ×12"]
+func (r *SQLCheckReport) RuntimeErrorCodes() []string {
+	counts := make(map[string]int)
+	for _, issue := range r.Issues(SQLCheckRuntime) {
+		code := issue.Code
+		if code == "" {
+			code = "UNKNOWN"
+		}
+		counts[code]++
+	}
+	codes := make([]string, 0, len(counts))
+	for code := range counts {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		if counts[codes[i]] != counts[codes[j]] {
+			return counts[codes[i]] > counts[codes[j]]
+		}
+		return codes[i] < codes[j]
+	})
+	for i, code := range codes {
+		codes[i] = fmt.Sprintf("%s ×%d", code, counts[code])
+	}
+	return codes
+}
+
+// SQLSyntaxChecker 在PostgreSQL中试执行生成的SQL，报告有问题的文件和语句
+//
+// 每个文件在一个psql会话的事务中执行，出错的语句单独回滚后继续执行后续语句，最后回滚整个事务。
+type SQLSyntaxChecker struct {
+	runner   *postgres.PSQLRunner
+	database string
+	jobs     int
+}
+
+// NewSQLSyntaxChecker 创建SQL语法预校验器，使用 migration.sql_check 指定的校验库和并行度
+func NewSQLSyntaxChecker(cfg *config.ProjectConfig) *SQLSyntaxChecker {
+	pgConfig := cfg.PostgreSQL
+	if database := cfg.Migration.SQLCheck.Database; database != "" {
+		pgConfig.Database = database
+	}
+	return &SQLSyntaxChecker{
+		runner:   postgres.NewPSQLRunner(&pgConfig),
+		database: pgConfig.Database,
+		jobs:     cfg.Migration.SQLCheck.ParallelJobs(),
+	}
+}
+
+// SetJobs 设置并行校验的文件数
+func (c *SQLSyntaxChecker) SetJobs(jobs int) {
+	if jobs > 0 {
+		c.jobs = jobs
+	}
+}
+
+// Check 校验SQL文件，校验库无法连接时返回错误
+func (c *SQLSyntaxChecker) Check(ctx context.Context, files []string) (*SQLCheckReport, error) {
+	start := time.Now()
+	if _, err := c.runner.Run(ctx, "SELECT 1;"); err != nil {
+		return nil, utils.NewError(utils.ErrorTypePostgres, "SQL_CHECK_UNAVAILABLE").
+			Message("无法连接SQL校验库").
+			Details(fmt.Sprintf("数据库 %s: %v", c.database, err)).
+			Cause(err).
+			Suggestion("运行 'ora2pg-admin 检查 连接' 确认目标库可以连接").
+			Suggestion("使用单独的校验库时，先创建该库（如 createdb " + c.database + "）并确认迁移账号有建表权限").
+			Build()
+	}
+
+	report := &SQLCheckReport{Database: c.database, Files: make([]*SQLCheckFileResult, len(files))}
+	var wg sync.WaitGroup
+	queue := make(chan int)
+	for range min(c.jobs, len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				report.Files[i] = c.checkFile(ctx, files[i])
+			}
+		}()
+	}
+	for i := range files {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+
+	for _, file := range report.Files {
+		report.Statements += file.Statements
+		report.Skipped += file.Skipped
+		if file.Error != "" {
+			report.FailedFiles++
+		}
+		for _, issue := range file.Issues {
+			if issue.Category == SQLCheckSyntax {
+				report.SyntaxErrors++
+			} else {
+				report.RuntimeErrors++
+			}
+		}
+	}
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+// checkFile 校验一个SQL文件
+func (c *SQLSyntaxChecker) checkFile(ctx context.Context, path string) *SQLCheckFileResult {
+	result := &SQLCheckFileResult{File: path}
+	script, err := buildSQLCheckScript(path)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Statements = len(script.statements)
+	result.Skipped = script.skipped
+	if len(script.statements) == 0 {
+		return result
+	}
+
+	output, err := c.runner.RunAll(ctx, script.text)
+	if err != nil {
+		result.Error = err.Error()
+	}
+	result.Issues = script.issues(output)
+	return result
+}
+
+// sqlCheckStatement 校验脚本中的一条语句，line 为语句在脚本中的起始行
+type sqlCheckStatement struct {
+	line int
+	stmt SQLStatement
+}
+
+// sqlCheckScript 由SQL文件生成的校验脚本
+type sqlCheckScript struct {
+	text       string
+	statements []sqlCheckStatement
+	skipped    int
+}
+
+// buildSQLCheckScript 读取SQL文件生成校验脚本，跳过不能在校验事务中执行的语句
+func buildSQLCheckScript(path string) (*sqlCheckScript, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, utils.FileErrors.ReadFailed(path, err)
+	}
+	defer file.Close()
+
+	script := &sqlCheckScript{}
+	var text strings.Builder
+	line := 1
+	for _, header := range sqlCheckHeader {
+		text.WriteString(header + "\n")
+		line++
+	}
+	scanner := NewSQLStatementScanner(file, path, sqlCheckMaxBytes)
+	for scanner.Scan() {
+		stmt := scanner.Statement()
+		if sqlCheckSkippedKinds[stmt.Kind] || stmt.Truncated {
+			script.skipped++
+			continue
+		}
+		body := stmt.Text
+		if !strings.HasSuffix(body, ";") {
+			body += ";"
+		}
+		script.statements = append(script.statements, sqlCheckStatement{line: line, stmt: stmt})
+		text.WriteString(body + "\n")
+		line += strings.Count(body, "\n") + 1
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, sqlPreviewReadError(path, err)
+	}
+	text.WriteString("ROLLBACK;\n")
+	script.text = text.String()
+	return script, nil
+}
+
+// issues 从psql输出中解析错误，按行号对应到出错的语句
+func (s *sqlCheckScript) issues(output string) []SQLCheckIssue {
+	var issues []SQLCheckIssue
+	var current *SQLCheckIssue
+	var currentStmt *sqlCheckStatement
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if matches := psqlCheckErrorPattern.FindStringSubmatch(line); matches != nil {
+			scriptLine, _ := strconv.Atoi(matches[1])
+			currentStmt = s.statementAt(scriptLine - postgres.ScriptLineOffset)
+			if currentStmt == nil {
+				current = nil
+				continue
+			}
+			issue := SQLCheckIssue{
+				File:      currentStmt.stmt.File,
+				Line:      currentStmt.stmt.Line,
+				Kind:      currentStmt.stmt.Kind,
+				Category:  SQLCheckRuntime,
+				Code:      matches[2],
+				Message:   strings.TrimSpace(matches[3]),
+				Statement: firstSQLLine(currentStmt.stmt.Text),
+			}
+			if sqlSyntaxErrorCodes[issue.Code] {
+				issue.Category = SQLCheckSyntax
+			}
+			issues = append(issues, issue)
+			current = &issues[len(issues)-1]
+			continue
+		}
+		// 错误之后的第一个 LINE 给出错误在语句中的行
+		if matches := psqlErrorLinePattern.FindStringSubmatch(line); matches != nil && current != nil {
+			if offset, err := strconv.Atoi(matches[1]); err == nil && offset > 0 {
+				current.Line = currentStmt.stmt.Line + offset - 1
+			}
+			current = nil
+		}
+	}
+	return issues
+}
+
+// statementAt 脚本中包含指定行的语句，行号在第一条语句之前时返回 nil
+func (s *sqlCheckScript) statementAt(line int) *sqlCheckStatement {
+	i := sort.Search(len(s.statements), func(i int) bool { return s.statements[i].line > line })
+	if i == 0 {
+		return nil
+	}
+	return &s.statements[i-1]
+}
+
+// firstSQLLine 语句的第一行，过长时截断
+func firstSQLLine(text string) string {
+	const maxRunes = 120
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	line = strings.TrimSpace(line)
+	if utf8.RuneCountInString(line) > maxRunes {
+		line = string([]rune(line)[:maxRunes]) + "..."
+	}
+	return line
+}
+
+// SetSQLCheck 设置迁移结构后是否校验生成的SQL，未设置时按 migration.sql_check.enabled
+func (ms *MigrationService) SetSQLCheck(enabled bool) {
+	ms.sqlCheck = enabled
+}
+
+// sqlCheckEnabled 本次迁移是否校验生成的SQL
+func (ms *MigrationService) sqlCheckEnabled() bool {
+	return ms.sqlCheck || ms.config.Migration.SQLCheck.Enabled
+}
+
+// collectSQLCheckFiles 记录结构类型本次生成的SQL文件，迁移结束后统一校验
+func (ms *MigrationService) collectSQLCheckFiles(migrationType MigrationType, files []string) {
+	if !ms.sqlCheckEnabled() || isDataMigrationType(migrationType) {
+		return
+	}
+	ms.sqlCheckFiles = append(ms.sqlCheckFiles, files...)
+}
+
+// runSQLCheck 校验本次生成的结构SQL，只记录结果不影响迁移状态
+func (ms *MigrationService) runSQLCheck(ctx context.Context) {
+	if len(ms.sqlCheckFiles) == 0 || ctx.Err() != nil {
+		return
+	}
+	files := make([]string, 0, len(ms.sqlCheckFiles))
+	seen := make(map[string]bool)
+	for _, file := range ms.sqlCheckFiles {
+		if !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+
+	ms.logger.Infof("校验生成的SQL语法，共 %d 个文件", len(files))
+	report, err := NewSQLSyntaxChecker(ms.config).Check(ctx, files)
+	if err != nil {
+		ms.logger.Warnf("SQL语法预校验未执行: %v", err)
+		return
+	}
+	ms.sqlCheckReport = report
+	for _, issue := range report.Issues(SQLCheckSyntax) {
+		ms.logger.Warnf("SQL语法错误 %s:%d: %s", issue.File, issue.Line, issue.Message)
+	}
+	ms.logger.Infof("SQL语法预校验完成: %d 条语句，语法错误 %d 个，运行时错误 %d 个", report.Statements, report.SyntaxErrors, report.RuntimeErrors)
+}
+
+// SQLCheckReport 本次迁移的SQL语法预校验报告，未校验时为 nil
+func (ms *MigrationService) SQLCheckReport() *SQLCheckReport {
+	return ms.sqlCheckReport
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// sqlCheckSample 模拟ora2pg生成的结构SQL
+const sqlCheckSample = `\set ON_ERROR_STOP ON
+SET client_encoding TO 'UTF8';
+BEGIN;
+CREATE TABLE emp (
+	id bigint,
+	name varchar(50)
+);
+CREATE OR REPLACE FUNCTION emp_name(p_id bigint) RETURNS varchar AS $$
+BEGIN
+	RETURN (SELECT name FROM emp WHERE id = p_id);
+END;
+$$ LANGUAGE plpgsql;
+CREATE INDEX emp_dept_idx ON missing_dept (id);
+INSERT INTO emp VALUES (1, 'a');
+CREATE TABL broken (
+	id int
+);
+COMMIT;
+`
+
+// fakeCheckPSQL 模拟psql：保存收到的脚本，对含 "CREATE TABL " 的行报语法错误，对 missing_dept 报表不存在
+func fakeCheckPSQL(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟psql依赖 /bin/sh")
+	}
+	bin := t.TempDir()
+	scripts := filepath.Join(t.TempDir(), "scripts")
+	psql := `#!/bin/sh
+input=$(cat)
+printf '%s\n' "$input" >> "` + scripts + `"
+printf '%s\n' "$input" | awk '
+/CREATE TABL / { print "psql:<stdin>:" NR ": ERROR:  42601: syntax error at or near \"TABL\""; print "LINE 1: CREATE TABL broken ("; print "               ^" }
+/missing_dept/ { print "psql:<stdin>:" NR ": ERROR:  42P01: relation \"missing_dept\" does not exist" }
+'
+`
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "psql"), []byte(psql), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return scripts
+}
+
+func TestBuildSQLCheckScript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "TABLE_output.sql")
+	require.NoError(t, os.WriteFile(path, []byte(sqlCheckSample), 0644))
+
+	script, err := buildSQLCheckScript(path)
+	require.NoError(t, err)
+	// 跳过元命令、事务控制和数据语句
+	assert.Equal(t, 4, script.skipped)
+	require.Len(t, script.statements, 5)
+	assert.NotContains(t, script.text, "ON_ERROR_STOP")
+	assert.NotContains(t, script.text, "COMMIT")
+	assert.NotContains(t, script.text, "INSERT")
+	assert.True(t, strings.HasPrefix(script.text, "\\set ON_ERROR_ROLLBACK on\nBEGIN;\n"))
+	assert.True(t, strings.HasSuffix(script.text, "ROLLBACK;\n"))
+
+	// 语句在脚本中的行号与文本一致
+	lines := strings.Split(script.text, "\n")
+	for _, statement := range script.statements {
+		assert.Equal(t, firstSQLLine(statement.stmt.Text), lines[statement.line-1])
+	}
+	assert.Nil(t, script.statementAt(1))
+	assert.Equal(t, "CREATE TABLE", script.statementAt(script.statements[1].line+1).stmt.Kind)
+}
+
+func TestSQLSyntaxChecker(t *testing.T) {
+	scripts := fakeCheckPSQL(t)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "TABLE_output.sql"), []byte(sqlCheckSample), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "VIEW_output.sql"), []byte("CREATE VIEW v AS SELECT 1;\n"), 0644))
+	files, err := FindSQLFiles([]string{dir})
+	require.NoError(t, err)
+
+	cfg := &config.ProjectConfig{}
+	cfg.PostgreSQL.Database = "target"
+	cfg.Migration.SQLCheck.Database = "sqlcheck"
+	checker := NewSQLSyntaxChecker(cfg)
+	checker.SetJobs(2)
+	report, err := checker.Check(context.Background(), files)
+	require.NoError(t, err)
+
+	assert.Equal(t, "sqlcheck", report.Database)
+	require.Len(t, report.Files, 2)
+	assert.Equal(t, 6, report.Statements)
+	assert.Equal(t, 4, report.Skipped)
+	assert.Equal(t, 1, report.SyntaxErrors)
+	assert.Equal(t, 1, report.RuntimeErrors)
+	assert.Empty(t, report.Files[1].Issues)
+
+	syntax := report.Issues(SQLCheckSyntax)
+	require.Len(t, syntax, 1)
+	assert.Equal(t, filepath.Join(dir, "TABLE_output.sql"), syntax[0].File)
+	assert.Equal(t, 15, syntax[0].Line)
+	assert.Equal(t, "42601", syntax[0].Code)
+	assert.Equal(t, "CREATE TABL broken (", syntax[0].Statement)
+
+	runtimeIssues := report.Issues(SQLCheckRuntime)
+	require.Len(t, runtimeIssues, 1)
+	assert.Equal(t, 13, runtimeIssues[0].Line)
+	assert.Equal(t, "CREATE INDEX", runtimeIssues[0].Kind)
+	assert.Equal(t, []string{"42P01 ×1"}, report.RuntimeErrorCodes())
+
+	// 每个文件在事务中执行后回滚
+	data, err := os.ReadFile(scripts)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "SET LOCAL check_function_bodies = on;")
+	assert.Equal(t, 2, strings.Count(string(data), "ROLLBACK;"))
+}
+
+func TestSQLSyntaxCheckerUnavailable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟psql依赖 /bin/sh")
+	}
+	bin := t.TempDir()
+	psql := "#!/bin/sh\necho 'psql: error: FATAL:  3D000: database \"sqlcheck\" does not exist'\nexit 2\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "psql"), []byte(psql), 0755))
+	t.Setenv("PATH", bin)
+
+	cfg := &config.ProjectConfig{}
+	cfg.Migration.SQLCheck.Database = "sqlcheck"
+	_, err := NewSQLSyntaxChecker(cfg).Check(context.Background(), []string{"a.sql"})
+	assert.Equal(t, "SQL_CHECK_UNAVAILABLE", utils.GetErrorCode(err))
+}
+
+func TestMigrationSQLCheckFiles(t *testing.T) {
+	cfg := &config.ProjectConfig{}
+	ms := NewMigrationService(cfg)
+	ms.collectSQLCheckFiles(MigrationTypeTable, []string{"a.sql"})
+	assert.Empty(t, ms.sqlCheckFiles, "未启用时不收集")
+
+	ms.SetSQLCheck(true)
+	ms.collectSQLCheckFiles(MigrationTypeTable, []string{"table.sql"})
+	ms.collectSQLCheckFiles(MigrationTypeCopy, []string{"data.sql"})
+	assert.Equal(t, []string{"table.sql"}, ms.sqlCheckFiles, "数据类型的输出不校验")
+}
@@ -0,0 +1,750 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"ora2pg-admin/internal/utils"
+)
+
+// SQL语句中无法按关键字归类的类型
+const (
+	SQLKindPSQL  = "PSQL"  // psql元命令，如 \set ON_ERROR_STOP ON
+	SQLKindOther = "OTHER" // 无法识别的语句
+)
+
+// DefaultSQLPreviewBytes 预览时每条语句最多保留的字节数，超出部分只计行数不保存
+const DefaultSQLPreviewBytes = 64 * 1024
+
+// sqlObjectModifiers CREATE 与对象类型之间可能出现的修饰词
+var sqlObjectModifiers = map[string]bool{
+	"OR": true, "REPLACE": true, "UNIQUE": true, "GLOBAL": true, "LOCAL": true,
+	"TEMPORARY": true, "TEMP": true, "UNLOGGED": true, "CONSTRAINT": true,
+	"TRUSTED": true, "PROCEDURAL": true, "RECURSIVE": true,
+}
+
+// sqlTwoWordObjects 由两个词组成的对象类型的首词，如 MATERIALIZED VIEW、FOREIGN TABLE
+var sqlTwoWordObjects = map[string]bool{"MATERIALIZED": true, "FOREIGN": true}
+
+// copyFromStdinPattern 数据跟在语句之后的 COPY
+var copyFromStdinPattern = regexp.MustCompile(`(?i)\bFROM\s+STDIN\b`)
+
+// SQLStatement SQL文件中的一条语句
+type SQLStatement struct {
+	File string
+	// Line 语句起始行号，从1开始
+	Line int
+	// Kind 语句类型，如 CREATE TABLE、ALTER TABLE、INSERT、COPY
+	Kind string
+	// Text 语句文本（COPY FROM STDIN 包括其后的数据），超过上限的部分被截断
+	Text string
+	// Lines 语句的总行数
+	Lines int
+	// Rows COPY FROM STDIN 的数据行数
+	Rows      int
+	Truncated bool
+}
+
+// SQLStatementScanner 从SQL文件中逐条读取语句
+//
+// 按字节流式读取，只保留当前语句的前若干字节，大文件和超长的 INSERT、COPY 数据不会整体载入内存。
+// 识别字符串、带引号的标识符、注释和 $tag$ 包围的函数体，其中的分号不会结束语句。
+type SQLStatementScanner struct {
+	reader   *bufio.Reader
+	file     string
+	maxBytes int
+	line     int
+	lastLine int
+	prev     [2]byte
+	stmt     SQLStatement
+	text     []byte
+	err      error
+	eof      bool
+}
+
+// NewSQLStatementScanner 创建语句扫描器，maxBytes 为每条语句保留的最大字节数，0表示使用默认值
+func NewSQLStatementScanner(r io.Reader, file string, maxBytes int) *SQLStatementScanner {
+	if maxBytes <= 0 {
+		maxBytes = DefaultSQLPreviewBytes
+	}
+	return &SQLStatementScanner{
+		reader:   bufio.NewReaderSize(r, 64*1024),
+		file:     file,
+		maxBytes: maxBytes,
+		line:     1,
+	}
+}
+
+// Scan 读取下一条语句，没有更多语句或读取失败时返回 false
+func (s *SQLStatementScanner) Scan() bool {
+	if s.err != nil || !s.skipGap() {
+		return false
+	}
+
+	s.text = s.text[:0]
+	s.prev = [2]byte{}
+	s.stmt = SQLStatement{File: s.file, Line: s.line}
+	if c, ok := s.peek(1); ok && c[0] == '\\' {
+		s.readLine()
+		s.stmt.Kind = SQLKindPSQL
+	} else {
+		s.readStatement()
+		s.stmt.Kind = ClassifySQL(string(s.text))
+		if s.stmt.Kind == "COPY" && copyFromStdinPattern.Match(s.text) {
+			s.readCopyData()
+		}
+	}
+	if s.err != nil {
+		return false
+	}
+
+	s.stmt.Lines = s.lastLine - s.stmt.Line + 1
+	s.stmt.Text = strings.TrimRightFunc(string(trimPartialRune(s.text)), func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\r' || r == '\n'
+	})
+	return true
+}
+
+// Statement 最近一次读取的语句
+func (s *SQLStatementScanner) Statement() SQLStatement {
+	return s.stmt
+}
+
+// Err 读取过程中的错误，读到文件末尾不算错误
+func (s *SQLStatementScanner) Err() error {
+	return s.err
+}
+
+// next 读取一个字节并追加到当前语句
+func (s *SQLStatementScanner) next() (byte, bool) {
+	c, ok := s.skip()
+	if !ok {
+		return 0, false
+	}
+	if len(s.text) < s.maxBytes {
+		s.text = append(s.text, c)
+	} else {
+		s.stmt.Truncated = true
+	}
+	s.prev[0], s.prev[1] = s.prev[1], c
+	return c, true
+}
+
+// skip 读取一个字节但不追加到语句，用于语句之间的空白和注释
+func (s *SQLStatementScanner) skip() (byte, bool) {
+	if s.eof || s.err != nil {
+		return 0, false
+	}
+	c, err := s.reader.ReadByte()
+	if err != nil {
+		s.eof = true
+		if err != io.EOF {
+			s.err = err
+		}
+		return 0, false
+	}
+	s.lastLine = s.line
+	if c == '\n' {
+		s.line++
+	}
+	return c, true
+}
+
+// peek 查看后续 n 个字节，不足 n 个时返回 false
+func (s *SQLStatementScanner) peek(n int) ([]byte, bool) {
+	if s.eof || s.err != nil {
+		return nil, false
+	}
+	data, _ := s.reader.Peek(n)
+	return data, len(data) == n
+}
+
+// peekIs 下一个字节是否为 c
+func (s *SQLStatementScanner) peekIs(c byte) bool {
+	data, ok := s.peek(1)
+	return ok && data[0] == c
+}
+
+// skipGap 跳过语句之间的空白和注释，还有语句时返回 true
+func (s *SQLStatementScanner) skipGap() bool {
+	for {
+		data, ok := s.peek(1)
+		if !ok {
+			return false
+		}
+		switch c := data[0]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == ';':
+			s.skip()
+		case c == '-' || c == '/':
+			pair, ok := s.peek(2)
+			if !ok || (string(pair) != "--" && string(pair) != "/*") {
+				return true
+			}
+			s.skip()
+			s.skip()
+			if pair[0] == '-' {
+				s.consumeLine(s.skip)
+			} else {
+				s.consumeBlockComment(s.skip)
+			}
+		default:
+			return true
+		}
+	}
+}
+
+// readStatement 读取到语句结尾的分号（含）或文件末尾
+func (s *SQLStatementScanner) readStatement() {
+	for {
+		before := s.prev[1]
+		escapes := (before == 'E' || before == 'e') && !isSQLIdentByte(s.prev[0])
+		c, ok := s.next()
+		if !ok {
+			return
+		}
+		switch {
+		case c == ';':
+			return
+		case c == '\'':
+			s.readQuoted('\'', escapes)
+		case c == '"':
+			s.readQuoted('"', false)
+		case c == '-' && s.peekIs('-'):
+			s.consumeLine(s.next)
+		case c == '/' && s.peekIs('*'):
+			s.next()
+			s.consumeBlockComment(s.next)
+		case c == '$' && !isSQLIdentByte(before):
+			if tag, ok := s.dollarTag(); ok {
+				for range len(tag) + 1 {
+					s.next()
+				}
+				s.readDollarQuoted(tag)
+			}
+		}
+	}
+}
+
+// readQuoted 读取到引号结束，连续两个引号表示引号本身；escapes 为 true 时反斜杠转义下一个字符（E'...'）
+func (s *SQLStatementScanner) readQuoted(quote byte, escapes bool) {
+	for {
+		c, ok := s.next()
+		if !ok {
+			return
+		}
+		switch {
+		case escapes && c == '\\':
+			s.next()
+		case c == quote:
+			if !s.peekIs(quote) {
+				return
+			}
+			s.next()
+		}
+	}
+}
+
+// dollarTag 当前 $ 之后是否为 $tag$ 的开始，返回标签（可以为空，即 $$）
+func (s *SQLStatementScanner) dollarTag() (string, bool) {
+	data, _ := s.reader.Peek(64)
+	for i, c := range data {
+		if c == '$' {
+			return string(data[:i]), true
+		}
+		if !isSQLIdentByte(c) || (i == 0 && c >= '0' && c <= '9') {
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// readDollarQuoted 读取到结束的 $tag$
+func (s *SQLStatementScanner) readDollarQuoted(tag string) {
+	closing := tag + "$"
+	for {
+		c, ok := s.next()
+		if !ok {
+			return
+		}
+		if c != '$' {
+			continue
+		}
+		if data, ok := s.peek(len(closing)); ok && string(data) == closing {
+			for range len(closing) {
+				s.next()
+			}
+			return
+		}
+	}
+}
+
+// readLine 读取到行尾（含换行）
+func (s *SQLStatementScanner) readLine() {
+	s.consumeLine(s.next)
+}
+
+// consumeLine 用 read 读取到行尾
+func (s *SQLStatementScanner) consumeLine(read func() (byte, bool)) {
+	for {
+		c, ok := read()
+		if !ok || c == '\n' {
+			return
+		}
+	}
+}
+
+// consumeBlockComment 用 read 读取到块注释结束，PostgreSQL的块注释可以嵌套
+func (s *SQLStatementScanner) consumeBlockComment(read func() (byte, bool)) {
+	depth := 1
+	var prev byte
+	for depth > 0 {
+		c, ok := read()
+		if !ok {
+			return
+		}
+		switch {
+		case prev == '*' && c == '/':
+			depth--
+			c = 0
+		case prev == '/' && c == '*':
+			depth++
+			c = 0
+		}
+		prev = c
+	}
+}
+
+// readCopyData 读取 COPY FROM STDIN 之后的数据，直到单独一行的 \.
+func (s *SQLStatementScanner) readCopyData() {
+	s.readLine()
+	for {
+		var head []byte
+		length := 0
+		for {
+			c, ok := s.next()
+			if !ok {
+				if length > 0 {
+					s.stmt.Rows++
+				}
+				return
+			}
+			if c == '\n' {
+				break
+			}
+			if len(head) < 3 {
+				head = append(head, c)
+			}
+			length++
+		}
+		if line := strings.TrimSuffix(string(head), "\r"); line == `\.` && length <= 3 {
+			return
+		}
+		s.stmt.Rows++
+	}
+}
+
+// isSQLIdentByte 是否为标识符中的字符（非ASCII字节视为标识符的一部分）
+func isSQLIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// trimPartialRune 去掉截断后末尾不完整的UTF-8字符
+func trimPartialRune(data []byte) []byte {
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				return data[:i]
+			}
+			break
+		}
+	}
+	return data
+}
+
+// ClassifySQL 识别语句类型
+//
+// CREATE、ALTER、DROP 带上对象类型（如 CREATE TABLE、CREATE INDEX、ALTER SEQUENCE，
+// 忽略 OR REPLACE、UNIQUE 等修饰词），其他语句使用首个关键字（如 INSERT、COPY、SET）。
+func ClassifySQL(text string) string {
+	words := leadingSQLWords(text, 8)
+	if len(words) == 0 {
+		if strings.HasPrefix(strings.TrimSpace(text), `\`) {
+			return SQLKindPSQL
+		}
+		return SQLKindOther
+	}
+
+	verb := words[0]
+	switch verb {
+	case "CREATE", "ALTER", "DROP":
+		rest := words[1:]
+		if verb == "CREATE" {
+			for len(rest) > 0 && sqlObjectModifiers[rest[0]] {
+				rest = rest[1:]
+			}
+		}
+		if len(rest) == 0 {
+			return verb
+		}
+		if sqlTwoWordObjects[rest[0]] && len(rest) > 1 {
+			return verb + " " + rest[0] + " " + rest[1]
+		}
+		return verb + " " + rest[0]
+	}
+	return verb
+}
+
+// leadingSQLWords 语句开头的关键字（大写），跳过注释，遇到其他符号时停止
+func leadingSQLWords(text string, limit int) []string {
+	var words []string
+	for i := 0; i < len(text) && len(words) < limit; {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case strings.HasPrefix(text[i:], "--"):
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				return words
+			}
+			i += end + 1
+		case strings.HasPrefix(text[i:], "/*"):
+			end := strings.Index(text[i+2:], "*/")
+			if end < 0 {
+				return words
+			}
+			i += end + 4
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_':
+			start := i
+			for i < len(text) && isSQLIdentByte(text[i]) {
+				i++
+			}
+			words = append(words, strings.ToUpper(text[start:i]))
+		default:
+			return words
+		}
+	}
+	return words
+}
+
+// SQLPreviewFilter 预览时的语句过滤条件，条件为空表示不过滤
+type SQLPreviewFilter struct {
+	// Kinds 语句类型，CREATE 匹配所有 CREATE 语句，CREATE TABLE 只匹配建表语句
+	Kinds []string
+	// Keywords 语句包含任一关键字时匹配（ASCII字母不区分大小写），只检查保留的前若干字节
+	Keywords []string
+}
+
+// Match 语句是否满足过滤条件
+func (f SQLPreviewFilter) Match(stmt SQLStatement) bool {
+	if len(f.Kinds) > 0 {
+		matched := false
+		for _, kind := range f.Kinds {
+			if MatchSQLKind(stmt.Kind, kind) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(f.Keywords) == 0 {
+		return true
+	}
+	text := asciiLower(stmt.Text)
+	for _, keyword := range f.Keywords {
+		if keyword = asciiLower(strings.TrimSpace(keyword)); keyword != "" && strings.Contains(text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchSQLKind 语句类型是否匹配过滤条件，条件可以只写前面的词
+func MatchSQLKind(kind, filter string) bool {
+	filter = strings.Join(strings.Fields(strings.ToUpper(filter)), " ")
+	if filter == "" {
+		return false
+	}
+	return kind == filter || strings.HasPrefix(kind, filter+" ")
+}
+
+// asciiLower 只把ASCII字母转为小写，保持字节位置不变
+func asciiLower(text string) string {
+	data := []byte(text)
+	for i, c := range data {
+		if c >= 'A' && c <= 'Z' {
+			data[i] = c + ('a' - 'A')
+		}
+	}
+	return string(data)
+}
+
+// SQLPreviewSummary SQL预览的统计
+type SQLPreviewSummary struct {
+	Files      int
+	Statements int
+	Matched    int
+	// Kinds 匹配的语句按类型计数
+	Kinds map[string]int
+	// Stopped 用户在浏览完之前退出
+	Stopped bool
+}
+
+// KindCounts 按数量从多到少排列的语句类型
+func (s *SQLPreviewSummary) KindCounts() []string {
+	kinds := make([]string, 0, len(s.Kinds))
+	for kind := range s.Kinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		if s.Kinds[kinds[i]] != s.Kinds[kinds[j]] {
+			return s.Kinds[kinds[i]] > s.Kinds[kinds[j]]
+		}
+		return kinds[i] < kinds[j]
+	})
+	return kinds
+}
+
+// FindSQLFiles 展开预览路径：目录下的 .sql 文件按路径排序，文件按给定顺序，重复的只保留一次
+func FindSQLFiles(paths []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, utils.NewError(utils.ErrorTypeFile, "SQL_PREVIEW_PATH_NOT_FOUND").
+				Message("找不到要预览的SQL文件").
+				Details(path).
+				Cause(err).
+				Suggestion("先执行迁移生成SQL文件，或指定SQL文件所在的目录").
+				Build()
+		}
+		if !info.IsDir() {
+			add(path)
+			continue
+		}
+		var found []string
+		filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() && strings.EqualFold(filepath.Ext(file), ".sql") {
+				found = append(found, file)
+			}
+			return nil
+		})
+		sort.Strings(found)
+		for _, file := range found {
+			add(file)
+		}
+	}
+	return files, nil
+}
+
+// PreviewSQLFiles 依次读取SQL文件，把满足过滤条件的语句交给 visit，visit 返回 false 时停止
+func PreviewSQLFiles(files []string, filter SQLPreviewFilter, maxBytes int, visit func(SQLStatement) bool) (*SQLPreviewSummary, error) {
+	summary := &SQLPreviewSummary{Kinds: make(map[string]int)}
+	for _, path := range files {
+		stopped, err := previewSQLFile(path, filter, maxBytes, summary, visit)
+		if err != nil {
+			return summary, err
+		}
+		if stopped {
+			summary.Stopped = true
+			return summary, nil
+		}
+	}
+	return summary, nil
+}
+
+// previewSQLFile 读取单个SQL文件，返回 visit 是否要求停止
+func previewSQLFile(path string, filter SQLPreviewFilter, maxBytes int, summary *SQLPreviewSummary, visit func(SQLStatement) bool) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, sqlPreviewReadError(path, err)
+	}
+	defer file.Close()
+	summary.Files++
+
+	scanner := NewSQLStatementScanner(file, path, maxBytes)
+	for scanner.Scan() {
+		stmt := scanner.Statement()
+		summary.Statements++
+		if !filter.Match(stmt) {
+			continue
+		}
+		summary.Matched++
+		summary.Kinds[stmt.Kind]++
+		if visit != nil && !visit(stmt) {
+			return true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, sqlPreviewReadError(path, err)
+	}
+	return false, nil
+}
+
+// sqlPreviewReadError 读取SQL文件失败
+func sqlPreviewReadError(path string, err error) error {
+	return utils.NewError(utils.ErrorTypeFile, "SQL_PREVIEW_READ_FAILED").
+		Message(fmt.Sprintf("读取SQL文件 %s 失败", path)).
+		Cause(err).
+		Build()
+}
+
+// SQL高亮使用的终端颜色
+const (
+	sqlColorReset   = "\033[0m"
+	sqlColorKeyword = "\033[1;34m"
+	sqlColorString  = "\033[32m"
+	sqlColorComment = "\033[90m"
+	sqlColorMatch   = "\033[30;43m"
+)
+
+// sqlHighlightStyle 高亮时每个字节的样式
+type sqlHighlightStyle uint8
+
+const (
+	sqlStylePlain sqlHighlightStyle = iota
+	sqlStyleKeyword
+	sqlStyleString
+	sqlStyleComment
+	sqlStyleMatch
+)
+
+// sqlStyleColors 各样式的颜色
+var sqlStyleColors = map[sqlHighlightStyle]string{
+	sqlStyleKeyword: sqlColorKeyword,
+	sqlStyleString:  sqlColorString,
+	sqlStyleComment: sqlColorComment,
+	sqlStyleMatch:   sqlColorMatch,
+}
+
+// sqlHighlightKeywords 高亮显示的SQL关键字
+var sqlHighlightKeywords = map[string]bool{}
+
+func init() {
+	for _, word := range strings.Fields(`ADD ALTER AND AS ASC BEGIN BETWEEN BY CASCADE CASE CHECK COLUMN COMMENT
+COMMIT CONSTRAINT COPY CREATE DECLARE DEFAULT DELETE DESC DISTINCT DO DROP ELSE ELSIF END EXCEPTION
+EXISTS EXTENSION FOREIGN FROM FUNCTION GRANT GROUP HAVING IF IN INDEX INSERT INTO IS JOIN KEY LANGUAGE
+LIKE LOOP MATERIALIZED NOT NULL ON OR ORDER PRIMARY PROCEDURE REFERENCES REPLACE RETURN RETURNS REVOKE
+SCHEMA SELECT SEQUENCE SET STDIN TABLE THEN TO TRIGGER TRUNCATE TYPE UNIQUE UPDATE USING VALUES VIEW
+WHEN WHERE WITH`) {
+		sqlHighlightKeywords[word] = true
+	}
+}
+
+// HighlightSQL 用终端颜色高亮SQL：关键字、字符串、注释，以及 keywords 中的关注词（ASCII字母不区分大小写）
+func HighlightSQL(text string, keywords []string) string {
+	styles := make([]sqlHighlightStyle, len(text))
+	mark := func(start, end int, style sqlHighlightStyle) {
+		for i := start; i < end && i < len(text); i++ {
+			styles[i] = style
+		}
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case strings.HasPrefix(text[i:], "--"):
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				end = len(text) - i
+			}
+			mark(i, i+end, sqlStyleComment)
+			i += end
+		case strings.HasPrefix(text[i:], "/*"):
+			end := strings.Index(text[i+2:], "*/")
+			if end < 0 {
+				end = len(text) - i
+			} else {
+				end += 4
+			}
+			mark(i, i+end, sqlStyleComment)
+			i += end
+		case c == '\'':
+			end := i + 1
+			for end < len(text) {
+				if text[end] == '\'' {
+					if end+1 < len(text) && text[end+1] == '\'' {
+						end += 2
+						continue
+					}
+					end++
+					break
+				}
+				end++
+			}
+			mark(i, end, sqlStyleString)
+			i = end
+		case c == '"':
+			end := strings.IndexByte(text[i+1:], '"')
+			if end < 0 {
+				end = len(text)
+			} else {
+				end += i + 2
+			}
+			i = end
+		case isSQLIdentByte(c):
+			start := i
+			for i < len(text) && isSQLIdentByte(text[i]) {
+				i++
+			}
+			if sqlHighlightKeywords[strings.ToUpper(text[start:i])] {
+				mark(start, i, sqlStyleKeyword)
+			}
+		default:
+			i++
+		}
+	}
+
+	lower := asciiLower(text)
+	for _, keyword := range keywords {
+		keyword = asciiLower(strings.TrimSpace(keyword))
+		if keyword == "" {
+			continue
+		}
+		for offset := 0; ; {
+			index := strings.Index(lower[offset:], keyword)
+			if index < 0 {
+				break
+			}
+			mark(offset+index, offset+index+len(keyword), sqlStyleMatch)
+			offset += index + len(keyword)
+		}
+	}
+
+	var builder strings.Builder
+	current := sqlStylePlain
+	for i := 0; i < len(text); i++ {
+		if styles[i] != current {
+			if current != sqlStylePlain {
+				builder.WriteString(sqlColorReset)
+			}
+			if styles[i] != sqlStylePlain {
+				builder.WriteString(sqlStyleColors[styles[i]])
+			}
+			current = styles[i]
+		}
+		builder.WriteByte(text[i])
+	}
+	if current != sqlStylePlain {
+		builder.WriteString(sqlColorReset)
+	}
+	return builder.String()
+}
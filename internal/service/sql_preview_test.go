@@ -0,0 +1,196 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/utils"
+)
+
+// scanSQL 读取文本中的全部语句
+func scanSQL(t *testing.T, text string, maxBytes int) []SQLStatement {
+	t.Helper()
+	scanner := NewSQLStatementScanner(strings.NewReader(text), "test.sql", maxBytes)
+	var statements []SQLStatement
+	for scanner.Scan() {
+		statements = append(statements, scanner.Statement())
+	}
+	require.NoError(t, scanner.Err())
+	return statements
+}
+
+func TestSQLStatementScanner(t *testing.T) {
+	text := `-- Generated by Ora2Pg
+\set ON_ERROR_STOP ON
+
+SET client_encoding TO 'UTF8';
+CREATE TABLE orders (
+	id bigint, -- 主键; 注释中的分号
+	note varchar(20) DEFAULT 'a;b''c'
+);
+/* 块注释 /* 嵌套 */ ; */
+COMMENT ON TABLE orders IS E'it\'s;ok';
+CREATE OR REPLACE FUNCTION f() RETURNS trigger AS $body$
+BEGIN
+	NEW.note := 'x;y';
+	RETURN NEW;
+END;
+$body$ LANGUAGE plpgsql;
+COPY orders (id, note) FROM STDIN;
+1	a;b
+2	\N
+\.
+INSERT INTO "odd;name" VALUES ($1, $$;$$);
+SELECT 1`
+
+	statements := scanSQL(t, text, 0)
+	require.Len(t, statements, 8)
+
+	kinds := make([]string, len(statements))
+	for i, stmt := range statements {
+		kinds[i] = stmt.Kind
+	}
+	assert.Equal(t, []string{SQLKindPSQL, "SET", "CREATE TABLE", "COMMENT", "CREATE FUNCTION", "COPY", "INSERT", "SELECT"}, kinds)
+
+	assert.Equal(t, `\set ON_ERROR_STOP ON`, statements[0].Text)
+	assert.Equal(t, 2, statements[0].Line)
+
+	// 注释、字符串中的分号不结束语句
+	create := statements[2]
+	assert.Equal(t, 5, create.Line)
+	assert.Equal(t, 4, create.Lines)
+	assert.True(t, strings.HasSuffix(create.Text, ");"))
+
+	assert.Equal(t, `COMMENT ON TABLE orders IS E'it\'s;ok';`, statements[3].Text)
+	assert.Equal(t, 6, statements[4].Lines)
+
+	// COPY 的数据属于同一条语句
+	copyStmt := statements[5]
+	assert.Equal(t, 2, copyStmt.Rows)
+	assert.Equal(t, 4, copyStmt.Lines)
+	assert.True(t, strings.HasSuffix(copyStmt.Text, `\.`))
+
+	assert.Equal(t, `INSERT INTO "odd;name" VALUES ($1, $$;$$);`, statements[6].Text)
+	// 文件末尾没有分号的语句
+	assert.Equal(t, "SELECT 1", statements[7].Text)
+	assert.Equal(t, 22, statements[7].Line)
+}
+
+func TestSQLStatementScannerTruncates(t *testing.T) {
+	values := strings.Repeat("(1, '中文'),\n", 1000)
+	text := "INSERT INTO t VALUES\n" + values + "(2, 'end');\nCREATE INDEX i ON t (a);\n"
+
+	statements := scanSQL(t, text, 100)
+	require.Len(t, statements, 2)
+	insert := statements[0]
+	assert.True(t, insert.Truncated)
+	assert.LessOrEqual(t, len(insert.Text), 100)
+	assert.True(t, strings.HasPrefix(insert.Text, "INSERT INTO t VALUES"))
+	assert.Equal(t, 1002, insert.Lines)
+	assert.NotContains(t, insert.Text, "�")
+
+	// 截断后仍能正确找到下一条语句
+	assert.Equal(t, "CREATE INDEX", statements[1].Kind)
+	assert.Equal(t, 1003, statements[1].Line)
+	assert.False(t, statements[1].Truncated)
+}
+
+func TestClassifySQL(t *testing.T) {
+	cases := map[string]string{
+		"create table t (a int)":                      "CREATE TABLE",
+		"CREATE UNIQUE INDEX i ON t (a)":              "CREATE INDEX",
+		"CREATE OR REPLACE VIEW v AS SELECT 1":        "CREATE VIEW",
+		"CREATE GLOBAL TEMPORARY TABLE t (a int)":     "CREATE TABLE",
+		"CREATE MATERIALIZED VIEW mv AS SELECT 1":     "CREATE MATERIALIZED VIEW",
+		"CREATE OR REPLACE PROCEDURE p() AS $$ $$":    "CREATE PROCEDURE",
+		"-- 说明\nALTER TABLE t ADD PRIMARY KEY (a)":    "ALTER TABLE",
+		"DROP FOREIGN TABLE ft":                       "DROP FOREIGN TABLE",
+		"/* x */ insert into t values (1)":            "INSERT",
+		"COPY t FROM STDIN":                           "COPY",
+		"GRANT SELECT ON t TO app":                    "GRANT",
+		"(SELECT 1)":                                  SQLKindOther,
+		`\i other.sql`:                                SQLKindPSQL,
+		"CREATE":                                      "CREATE",
+		"create or replace trigger trg before insert": "CREATE TRIGGER",
+	}
+	for text, kind := range cases {
+		assert.Equal(t, kind, ClassifySQL(text), text)
+	}
+}
+
+func TestSQLPreviewFilter(t *testing.T) {
+	table := SQLStatement{Kind: "CREATE TABLE", Text: "CREATE TABLE orders (id bigint) ON DELETE CASCADE"}
+	index := SQLStatement{Kind: "CREATE INDEX", Text: "CREATE INDEX i ON orders (id)"}
+	insert := SQLStatement{Kind: "INSERT", Text: "INSERT INTO customers VALUES (1)"}
+
+	assert.True(t, SQLPreviewFilter{}.Match(insert))
+
+	create := SQLPreviewFilter{Kinds: []string{"create"}}
+	assert.True(t, create.Match(table))
+	assert.True(t, create.Match(index))
+	assert.False(t, create.Match(insert))
+
+	onlyTables := SQLPreviewFilter{Kinds: []string{" create   table "}}
+	assert.True(t, onlyTables.Match(table))
+	assert.False(t, onlyTables.Match(index))
+	assert.False(t, MatchSQLKind("CREATE TABLESPACE", "CREATE TABLE"))
+
+	keyword := SQLPreviewFilter{Kinds: []string{"CREATE"}, Keywords: []string{"cascade"}}
+	assert.True(t, keyword.Match(table))
+	assert.False(t, keyword.Match(index))
+}
+
+func TestHighlightSQL(t *testing.T) {
+	highlighted := HighlightSQL("SELECT 'from' FROM orders -- select", []string{"ORDERS"})
+	assert.Equal(t, sqlColorKeyword+"SELECT"+sqlColorReset+" "+
+		sqlColorString+"'from'"+sqlColorReset+" "+
+		sqlColorKeyword+"FROM"+sqlColorReset+" "+
+		sqlColorMatch+"orders"+sqlColorReset+" "+
+		sqlColorComment+"-- select"+sqlColorReset, highlighted)
+
+	// 带引号的标识符和函数名不高亮，关注词可以出现在字符串中
+	assert.Equal(t, `"select" now()`, HighlightSQL(`"select" now()`, nil))
+	assert.Equal(t, sqlColorString+"'a"+sqlColorReset+sqlColorMatch+"订单"+sqlColorReset+sqlColorString+"'"+sqlColorReset,
+		HighlightSQL("'a订单'", []string{"订单"}))
+}
+
+func TestPreviewSQLFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "TABLE_output.sql"),
+		[]byte("CREATE TABLE a (id int);\nCREATE TABLE b (id int);\nCREATE INDEX i ON a (id);\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "COPY_output.sql"),
+		[]byte("COPY a FROM STDIN;\n1\n\\.\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("CREATE TABLE x;"), 0644))
+
+	files, err := FindSQLFiles([]string{dir, filepath.Join(dir, "TABLE_output.sql")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "TABLE_output.sql"), filepath.Join(dir, "sub", "COPY_output.sql")}, files)
+
+	_, err = FindSQLFiles([]string{filepath.Join(dir, "missing")})
+	assert.Equal(t, "SQL_PREVIEW_PATH_NOT_FOUND", utils.GetErrorCode(err))
+
+	var visited []string
+	summary, err := PreviewSQLFiles(files, SQLPreviewFilter{Kinds: []string{"CREATE TABLE", "COPY"}}, 0, func(stmt SQLStatement) bool {
+		visited = append(visited, stmt.Text)
+		return true
+	})
+	require.NoError(t, err)
+	assert.Len(t, visited, 3)
+	assert.Equal(t, 2, summary.Files)
+	assert.Equal(t, 4, summary.Statements)
+	assert.Equal(t, 3, summary.Matched)
+	assert.Equal(t, []string{"CREATE TABLE", "COPY"}, summary.KindCounts())
+	assert.False(t, summary.Stopped)
+
+	// visit 返回 false 时停止读取后续文件
+	summary, err = PreviewSQLFiles(files, SQLPreviewFilter{}, 0, func(SQLStatement) bool { return false })
+	require.NoError(t, err)
+	assert.True(t, summary.Stopped)
+	assert.Equal(t, 1, summary.Files)
+	assert.Equal(t, 1, summary.Statements)
+}
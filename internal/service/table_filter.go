@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/utils"
+)
+
+// resolveTableFilter 配置了表名正则表达式时从源库列出表并筛选，生成配置时用筛选出的表作为 ALLOW
+//
+// 同一迁移服务只查询一次源库，之后的执行复用筛选结果。
+func (ms *MigrationService) resolveTableFilter(ctx context.Context) error {
+	migration := &ms.config.Migration
+	if !migration.HasTablePatterns() || ms.filteredTables != nil {
+		return nil
+	}
+
+	filter, err := migration.TableFilter()
+	if err != nil {
+		return utils.NewError(utils.ErrorTypeConfig, "TABLE_PATTERN_INVALID").
+			Message("表筛选的正则表达式无效").
+			Details(err.Error()).
+			Cause(err).
+			Suggestion("检查配置文件中的 migration.allow_patterns 和 migration.exclude_patterns").
+			Build()
+	}
+
+	schema := ms.config.Oracle.Schema
+	if schema == "" {
+		schema = ms.config.Oracle.Username
+	}
+	runner := oracle.NewSQLPlusRunner(&ms.config.Oracle, &ms.config.OracleClient)
+	tables, err := oracle.NewInspector(runner, schema).TableNames(ctx)
+	if err != nil {
+		return utils.NewError(utils.ErrorTypeOracle, "TABLE_FILTER_LIST_FAILED").
+			Message("按正则表达式筛选表时无法列出源库的表").
+			Details(err.Error()).
+			Cause(err).
+			Suggestion("运行 'ora2pg-admin 检查 连接' 确认源库连接").
+			Build()
+	}
+
+	result := filter.Filter(schema, tables)
+	if len(result.Selected) == 0 {
+		return utils.NewError(utils.ErrorTypeConfig, "TABLE_FILTER_EMPTY").
+			Message("按表筛选规则没有选中任何表").
+			Details(fmt.Sprintf("模式 %s 共 %d 张表，排除 %d 张", strings.ToUpper(schema), len(tables), len(result.Excluded))).
+			Suggestion("检查 migration.allow_patterns 是否过窄、migration.exclude_patterns 是否过宽").
+			Build()
+	}
+
+	ms.logger.Infof("按表筛选规则选中 %d 张表，排除 %d 张（共 %d 张）", len(result.Selected), len(result.Excluded), len(tables))
+	if len(result.Excluded) > 0 {
+		ms.logger.Debugf("排除的表: %s", strings.Join(result.Excluded, ", "))
+	}
+	ms.filteredTables = result.Selected
+	if result.All {
+		// 全部表都被选中时不生成 ALLOW，避免表很多时指令过长
+		ms.filteredTables = []string{}
+	}
+	return nil
+}
+
+// filteredConfig 生成ora2pg配置使用的项目配置，按正则表达式筛选过表时用筛选结果替换 allow_tables
+func (ms *MigrationService) filteredConfig() *config.ProjectConfig {
+	if ms.filteredTables == nil {
+		return ms.config
+	}
+	filtered := *ms.config
+	filtered.Migration.AllowTables = ms.filteredTables
+	filtered.Migration.AllowPatterns = nil
+	filtered.Migration.ExcludePatterns = nil
+	return &filtered
+}
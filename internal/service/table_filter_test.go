@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+func TestResolveTableFilter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟sqlplus依赖 /bin/sh")
+	}
+
+	// 模拟sqlplus：返回源库的表并记录调用次数
+	oracleHome := t.TempDir()
+	calls := filepath.Join(t.TempDir(), "calls")
+	sqlplus := `#!/bin/sh
+cat > /dev/null
+echo call >> ` + calls + `
+printf 'TAB|ORDERS\nTAB|ORDERS_BAK\nTAB|TMP_IMPORT\nTAB|CUSTOMERS\n'
+`
+	require.NoError(t, os.WriteFile(filepath.Join(oracleHome, "sqlplus"), []byte(sqlplus), 0755))
+
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("表筛选")
+	cfg := manager.GetConfig()
+	cfg.Oracle.Username = "scott"
+	cfg.OracleClient = config.OracleClientConfig{Home: oracleHome, AutoDetect: false}
+	cfg.Migration.ExcludePatterns = []string{"^TMP_", "_BAK$"}
+
+	ms := NewMigrationService(cfg)
+	require.NoError(t, ms.resolveTableFilter(context.Background()))
+	filtered := ms.filteredConfig()
+	assert.Equal(t, "CUSTOMERS ORDERS", filtered.Migration.AllowDirective())
+	assert.Empty(t, filtered.Migration.ExcludePatterns)
+	// 原配置不变
+	assert.Empty(t, cfg.Migration.AllowTables)
+	assert.Equal(t, []string{"^TMP_", "_BAK$"}, cfg.Migration.ExcludePatterns)
+
+	// 同一迁移服务只查询一次源库
+	require.NoError(t, ms.resolveTableFilter(context.Background()))
+	data, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(data), "call"))
+
+	// 没有选中任何表时报错，不能生成空的 ALLOW 导致迁移全部表
+	cfg.Migration.AllowPatterns = []string{"^NOTHING"}
+	ms = NewMigrationService(cfg)
+	err = ms.resolveTableFilter(context.Background())
+	assert.Equal(t, "TABLE_FILTER_EMPTY", utils.GetErrorCode(err))
+
+	// 没有配置正则表达式时不查询源库
+	cfg.Migration.AllowPatterns = nil
+	cfg.Migration.ExcludePatterns = nil
+	ms = NewMigrationService(cfg)
+	require.NoError(t, ms.resolveTableFilter(context.Background()))
+	assert.Same(t, cfg, ms.filteredConfig())
+}
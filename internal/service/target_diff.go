@@ -0,0 +1,597 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/postgres"
+	"ora2pg-admin/internal/utils"
+)
+
+// 对象相对目标库现状的变化
+const (
+	TargetDiffCreate    = "create"    // 目标库中没有，将新增
+	TargetDiffModify    = "modify"    // 目标库中已有且定义不同，或将被 CREATE OR REPLACE 替换
+	TargetDiffUnchanged = "unchanged" // 目标库中已有且定义相同（表以外的对象只比较名称）
+)
+
+// targetDiffMaxStatementBytes 解析对象定义时每条语句保留的字节数，超出时表只比较名称
+const targetDiffMaxStatementBytes = 1 << 20
+
+// targetDiffKinds CREATE 语句类型到目标库对象类型，按报告中的显示顺序排列
+var targetDiffKinds = []struct {
+	statement string
+	kind      string
+	label     string
+}{
+	{"CREATE TABLE", postgres.CatalogTable, "表"},
+	{"CREATE VIEW", postgres.CatalogView, "视图"},
+	{"CREATE MATERIALIZED VIEW", postgres.CatalogMaterializedView, "物化视图"},
+	{"CREATE SEQUENCE", postgres.CatalogSequence, "序列"},
+	{"CREATE INDEX", postgres.CatalogIndex, "索引"},
+	{"CREATE FUNCTION", postgres.CatalogFunction, "函数"},
+	{"CREATE PROCEDURE", postgres.CatalogProcedure, "存储过程"},
+	{"CREATE TRIGGER", postgres.CatalogTrigger, "触发器"},
+	{"CREATE TYPE", postgres.CatalogType, "类型"},
+}
+
+// TargetKindLabel 对象类型的中文名称
+func TargetKindLabel(kind string) string {
+	for _, item := range targetDiffKinds {
+		if item.kind == kind {
+			return item.label
+		}
+	}
+	return kind
+}
+
+// SQLObjectDef 生成的SQL中定义的对象
+type SQLObjectDef struct {
+	Kind string
+	Name string
+	// Replace CREATE OR REPLACE
+	Replace bool
+	// Columns 表的列，定义过长被截断时为空
+	Columns   []postgres.CatalogColumn
+	Truncated bool
+	File      string
+	Line      int
+}
+
+// TargetObjectDiff 单个对象的变化
+type TargetObjectDiff struct {
+	Kind    string   `json:"kind"`
+	Name    string   `json:"name"`
+	Action  string   `json:"action"`
+	Changes []string `json:"changes,omitempty"`
+	// Recreate 生成的SQL先删除同名对象再创建，表中已有的数据会丢失
+	Recreate bool   `json:"recreate,omitempty"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// TargetDataLoad 生成的SQL向一张表导入的数据
+type TargetDataLoad struct {
+	Table string `json:"table"`
+	// Rows COPY 的数据行数加 INSERT 语句数
+	Rows int64 `json:"rows"`
+	// Truncate 导入前清空表
+	Truncate bool `json:"truncate,omitempty"`
+	// Exists 目标库中已有该表，或生成的SQL会创建该表
+	Exists bool `json:"exists"`
+}
+
+// TargetDiffReport 生成的SQL相对目标库现状的差异，类似数据库迁移工具的 plan
+type TargetDiffReport struct {
+	Schema      string             `json:"schema"`
+	GeneratedAt time.Time          `json:"generated_at"`
+	Files       int                `json:"files"`
+	Statements  int                `json:"statements"`
+	Objects     []TargetObjectDiff `json:"objects"`
+	Data        []TargetDataLoad   `json:"data,omitempty"`
+	// TargetOnly 目标库中已有、生成的SQL中没有定义的表
+	TargetOnly []string `json:"target_only,omitempty"`
+}
+
+// Count 某种变化的对象数，kind 为空时统计全部类型
+func (r *TargetDiffReport) Count(kind, action string) int {
+	count := 0
+	for _, object := range r.Objects {
+		if (kind == "" || object.Kind == kind) && object.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// HasChanges 是否有新增或修改的对象
+func (r *TargetDiffReport) HasChanges() bool {
+	return r.Count("", TargetDiffCreate) > 0 || r.Count("", TargetDiffModify) > 0
+}
+
+// Summary 一句话概括，如 "将新增 3 个对象、修改 1 个、无变化 5 个"
+func (r *TargetDiffReport) Summary() string {
+	return fmt.Sprintf("将新增 %d 个对象、修改 %d 个、无变化 %d 个",
+		r.Count("", TargetDiffCreate), r.Count("", TargetDiffModify), r.Count("", TargetDiffUnchanged))
+}
+
+// KindSummaries 按对象类型的统计，如 "表: 新增 3、修改 1、无变化 5"，没有该类对象时不输出
+func (r *TargetDiffReport) KindSummaries() []string {
+	var lines []string
+	for _, item := range targetDiffKinds {
+		created, modified, unchanged := r.Count(item.kind, TargetDiffCreate), r.Count(item.kind, TargetDiffModify), r.Count(item.kind, TargetDiffUnchanged)
+		if created+modified+unchanged == 0 {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: 新增 %d、修改 %d、无变化 %d", item.label, created, modified, unchanged))
+	}
+	return lines
+}
+
+// PlanTargetChanges 解析生成的SQL文件并与目标库现有对象对比
+func PlanTargetChanges(ctx context.Context, cfg *config.ProjectConfig, files []string) (*TargetDiffReport, error) {
+	definitions, err := ParseSQLDefinitions(files)
+	if err != nil {
+		return nil, err
+	}
+	schema := cfg.PostgreSQL.Schema
+	if schema == "" {
+		schema = "public"
+	}
+	catalog, err := postgres.InspectCatalog(ctx, postgres.NewPSQLRunner(&cfg.PostgreSQL), schema)
+	if err != nil {
+		return nil, err
+	}
+	report := DiffTargetObjects(definitions, catalog)
+	report.Files = len(files)
+	return report, nil
+}
+
+// SQLDefinitions 从生成的SQL中解析出的对象定义、删除的对象和导入的数据
+type SQLDefinitions struct {
+	Statements int
+	Objects    []SQLObjectDef
+	// Dropped DROP 语句删除的对象，键为 "<类型>|<名称>"
+	Dropped map[string]bool
+	// Data 按表统计的数据，键为表名
+	Data      map[string]*TargetDataLoad
+	dataOrder []string
+}
+
+// ParseSQLDefinitions 按顺序读取SQL文件，提取 CREATE 语句定义的对象、DROP 的对象和导入的数据
+func ParseSQLDefinitions(files []string) (*SQLDefinitions, error) {
+	definitions := &SQLDefinitions{Dropped: make(map[string]bool), Data: make(map[string]*TargetDataLoad)}
+	for _, path := range files {
+		if err := definitions.parseFile(path); err != nil {
+			return nil, err
+		}
+	}
+	return definitions, nil
+}
+
+// parseFile 读取单个SQL文件
+func (d *SQLDefinitions) parseFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return utils.FileErrors.ReadFailed(path, err)
+	}
+	defer file.Close()
+
+	scanner := NewSQLStatementScanner(file, path, targetDiffMaxStatementBytes)
+	for scanner.Scan() {
+		d.Statements++
+		d.add(scanner.Statement())
+	}
+	if err := scanner.Err(); err != nil {
+		return sqlPreviewReadError(path, err)
+	}
+	return nil
+}
+
+// add 记录一条语句
+func (d *SQLDefinitions) add(stmt SQLStatement) {
+	tokens := sqlTokens(stmt.Text, 64)
+	switch {
+	case strings.HasPrefix(stmt.Kind, "CREATE "):
+		if object, ok := parseSQLObjectDef(stmt); ok {
+			d.Objects = append(d.Objects, object)
+		}
+	case strings.HasPrefix(stmt.Kind, "DROP "):
+		kind := targetKindOf("CREATE" + strings.TrimPrefix(stmt.Kind, "DROP"))
+		if name, ok := nameAfter(tokens, len(strings.Fields(stmt.Kind)), "IF", "EXISTS", "CONCURRENTLY"); ok && kind != "" {
+			d.Dropped[kind+"|"+name] = true
+		}
+	case stmt.Kind == "COPY":
+		if name, ok := nameAfter(tokens, 1); ok {
+			d.data(name).Rows += int64(stmt.Rows)
+		}
+	case stmt.Kind == "INSERT":
+		if name, ok := nameAfter(tokens, 1, "INTO"); ok {
+			d.data(name).Rows++
+		}
+	case stmt.Kind == "TRUNCATE":
+		if name, ok := nameAfter(tokens, 1, "TABLE", "ONLY"); ok {
+			d.data(name).Truncate = true
+		}
+	}
+}
+
+// data 表的数据统计
+func (d *SQLDefinitions) data(table string) *TargetDataLoad {
+	load, ok := d.Data[table]
+	if !ok {
+		load = &TargetDataLoad{Table: table}
+		d.Data[table] = load
+		d.dataOrder = append(d.dataOrder, table)
+	}
+	return load
+}
+
+// targetKindOf CREATE 语句类型对应的目标库对象类型，不比较的类型返回空
+func targetKindOf(statementKind string) string {
+	for _, item := range targetDiffKinds {
+		if item.statement == statementKind {
+			return item.kind
+		}
+	}
+	return ""
+}
+
+// parseSQLObjectDef 解析 CREATE 语句定义的对象，没有名称（如 CREATE INDEX ON t）或不比较的类型返回 false
+func parseSQLObjectDef(stmt SQLStatement) (SQLObjectDef, bool) {
+	kind := targetKindOf(stmt.Kind)
+	if kind == "" {
+		return SQLObjectDef{}, false
+	}
+	// 只有表需要解析完整的定义，函数体等不必拆分
+	limit := 64
+	if kind == postgres.CatalogTable {
+		limit = -1
+	}
+	tokens := sqlTokens(stmt.Text, limit)
+	object := SQLObjectDef{Kind: kind, File: stmt.File, Line: stmt.Line, Truncated: stmt.Truncated}
+
+	// 跳过 CREATE 和修饰词，定位到对象类型之后
+	i := 1
+	for i < len(tokens) && sqlObjectModifiers[strings.ToUpper(tokens[i])] {
+		if strings.EqualFold(tokens[i], "REPLACE") {
+			object.Replace = true
+		}
+		i++
+	}
+	if sqlTwoWordObjects[strings.ToUpper(tokens[min(i, len(tokens)-1)])] {
+		i++
+	}
+	name, ok := nameAfter(tokens, i+1, "IF", "NOT", "EXISTS", "CONCURRENTLY")
+	if !ok {
+		return SQLObjectDef{}, false
+	}
+	object.Name = name
+
+	if kind == postgres.CatalogTable && !stmt.Truncated {
+		object.Columns = parseTableColumns(tokens)
+	}
+	return object, true
+}
+
+// nameAfter 从第 start 个词开始跳过 skip 中的关键字，返回之后的对象名（去掉模式名）
+func nameAfter(tokens []string, start int, skip ...string) (string, bool) {
+	i := start
+	for i < len(tokens) && containsFold(skip, tokens[i]) {
+		i++
+	}
+	if i >= len(tokens) || !isSQLNameToken(tokens[i]) || strings.EqualFold(tokens[i], "ON") {
+		return "", false
+	}
+	name := tokens[i]
+	for i+2 < len(tokens) && tokens[i+1] == "." && isSQLNameToken(tokens[i+2]) {
+		name = tokens[i+2]
+		i += 2
+	}
+	return normalizeSQLIdentifier(name), true
+}
+
+// containsFold 不区分大小写判断 values 中是否有 value
+func containsFold(values []string, value string) bool {
+	for _, item := range values {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSQLNameToken 是否为标识符（含带双引号的标识符）
+func isSQLNameToken(token string) bool {
+	if token == "" {
+		return false
+	}
+	c := token[0]
+	return c == '"' || c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+// normalizeSQLIdentifier 按PostgreSQL的规则规范化标识符：不带引号的转为小写，带引号的去掉引号
+func normalizeSQLIdentifier(token string) string {
+	if len(token) >= 2 && token[0] == '"' && token[len(token)-1] == '"' {
+		return strings.ReplaceAll(token[1:len(token)-1], `""`, `"`)
+	}
+	return asciiLower(token)
+}
+
+// tableElementKeywords 建表语句中表级约束等非列定义的开头
+var tableElementKeywords = map[string]bool{
+	"CONSTRAINT": true, "PRIMARY": true, "UNIQUE": true, "CHECK": true, "FOREIGN": true, "EXCLUDE": true, "LIKE": true,
+}
+
+// columnTypeEnd 列定义中类型之后的关键字
+var columnTypeEnd = map[string]bool{
+	"NOT": true, "NULL": true, "DEFAULT": true, "CONSTRAINT": true, "PRIMARY": true, "UNIQUE": true, "CHECK": true,
+	"REFERENCES": true, "COLLATE": true, "GENERATED": true,
+}
+
+// parseTableColumns 解析建表语句第一对括号中的列名和类型
+func parseTableColumns(tokens []string) []postgres.CatalogColumn {
+	start := -1
+	for i, token := range tokens {
+		if token == "(" {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+
+	var columns []postgres.CatalogColumn
+	var element []string
+	depth := 0
+	flush := func() {
+		if len(element) >= 2 && !tableElementKeywords[strings.ToUpper(element[0])] {
+			var typeTokens []string
+			nested := 0
+			for _, token := range element[1:] {
+				if nested == 0 && columnTypeEnd[strings.ToUpper(token)] {
+					break
+				}
+				switch token {
+				case "(":
+					nested++
+				case ")":
+					nested--
+				}
+				typeTokens = append(typeTokens, token)
+			}
+			columns = append(columns, postgres.CatalogColumn{Name: normalizeSQLIdentifier(element[0]), Type: joinTypeTokens(typeTokens)})
+		}
+		element = nil
+	}
+	for _, token := range tokens[start+1:] {
+		switch {
+		case token == "(":
+			depth++
+		case token == ")" && depth == 0:
+			flush()
+			return columns
+		case token == ")":
+			depth--
+		case token == "," && depth == 0:
+			flush()
+			continue
+		}
+		element = append(element, token)
+	}
+	return columns
+}
+
+// joinTypeTokens 拼接类型的各个词，括号和逗号两侧不加空格，如 numeric(10,2)
+func joinTypeTokens(tokens []string) string {
+	var builder strings.Builder
+	for i, token := range tokens {
+		if i > 0 && !slices.Contains([]string{"(", ")", ",", "[", "]"}, token) && !slices.Contains([]string{"(", ",", "["}, tokens[i-1]) {
+			builder.WriteByte(' ')
+		}
+		builder.WriteString(token)
+	}
+	return builder.String()
+}
+
+// sqlTokens 把语句拆分为词：标识符、带引号的标识符、字符串、数字和单个符号，跳过空白和注释；limit 小于0时不限数量
+func sqlTokens(text string, limit int) []string {
+	var tokens []string
+	for i := 0; i < len(text) && (limit < 0 || len(tokens) < limit); {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case strings.HasPrefix(text[i:], "--"):
+			end := strings.IndexByte(text[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+		case strings.HasPrefix(text[i:], "/*"):
+			end := strings.Index(text[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(text) {
+				if text[end] == c {
+					if end+1 < len(text) && text[end+1] == c {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			end = min(end+1, len(text))
+			tokens = append(tokens, text[i:end])
+			i = end
+		case isSQLIdentByte(c):
+			start := i
+			for i < len(text) && isSQLIdentByte(text[i]) {
+				i++
+			}
+			tokens = append(tokens, text[start:i])
+		default:
+			tokens = append(tokens, text[i:i+1])
+			i++
+		}
+	}
+	return tokens
+}
+
+// pgTypeAliases 类型别名到 format_type 输出的名称
+var pgTypeAliases = map[string]string{
+	"varchar": "character varying", "char": "character", "bpchar": "character",
+	"int": "integer", "int4": "integer", "int8": "bigint", "int2": "smallint",
+	"serial": "integer", "serial4": "integer", "bigserial": "bigint", "serial8": "bigint", "smallserial": "smallint", "serial2": "smallint",
+	"float8": "double precision", "float4": "real", "decimal": "numeric", "bool": "boolean",
+	"timestamptz": "timestamp with time zone", "timetz": "time with time zone", "varbit": "bit varying",
+}
+
+// pgTypePattern 类型名、括号中的参数、时区和数组后缀
+var pgTypePattern = regexp.MustCompile(`^([a-z_][a-z0-9_ ]*?) ?(?:\(([^)]*)\))? ?((?:with|without) time zone)?((?:\[\])*)$`)
+
+// NormalizePGType 把类型规范化为 format_type 的写法，如 varchar(100) → character varying(100)、timestamp → timestamp without time zone
+func NormalizePGType(value string) string {
+	t := strings.TrimPrefix(strings.Join(strings.Fields(strings.ToLower(value)), " "), "pg_catalog.")
+	match := pgTypePattern.FindStringSubmatch(t)
+	if match == nil {
+		return t
+	}
+	base, modifiers, zone, arrays := match[1], strings.ReplaceAll(match[2], " ", ""), match[3], match[4]
+	if base == "float" {
+		// float(1)~float(24) 为 real，其余为 double precision
+		base = "double precision"
+		if precision, err := strconv.Atoi(modifiers); err == nil && precision <= 24 {
+			base = "real"
+		}
+		modifiers = ""
+	}
+	if alias, ok := pgTypeAliases[base]; ok {
+		base = alias
+	}
+	if rest, ok := strings.CutSuffix(base, " with time zone"); ok {
+		base, zone = rest, "with time zone"
+	}
+	switch base {
+	case "timestamp", "time":
+		if zone == "" {
+			zone = "without time zone"
+		}
+	case "numeric":
+		if modifiers != "" && !strings.Contains(modifiers, ",") {
+			modifiers += ",0"
+		}
+	case "character":
+		if modifiers == "" {
+			modifiers = "1"
+		}
+	}
+
+	result := base
+	if modifiers != "" {
+		result += "(" + modifiers + ")"
+	}
+	if zone != "" {
+		result += " " + zone
+	}
+	return result + arrays
+}
+
+// DiffTargetObjects 对比生成的SQL中的对象与目标库现有对象
+//
+// 表比较列名和规范化后的类型；其他对象只比较名称，CREATE OR REPLACE 已有对象时视为修改。
+// 同一对象定义多次时只取第一次。
+func DiffTargetObjects(definitions *SQLDefinitions, catalog *postgres.Catalog) *TargetDiffReport {
+	report := &TargetDiffReport{Schema: catalog.Schema, GeneratedAt: time.Now(), Statements: definitions.Statements}
+	seen := make(map[string]bool)
+	definedTables := make(map[string]bool)
+	for _, object := range definitions.Objects {
+		key := object.Kind + "|" + object.Name
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if object.Kind == postgres.CatalogTable {
+			definedTables[object.Name] = true
+		}
+
+		diff := TargetObjectDiff{Kind: object.Kind, Name: object.Name, File: object.File, Line: object.Line}
+		switch {
+		case !catalog.Has(object.Kind, object.Name):
+			diff.Action = TargetDiffCreate
+		case object.Kind == postgres.CatalogTable:
+			diff.Recreate = definitions.Dropped[key]
+			diff.Changes = diffTableColumns(object, catalog.Tables[object.Name])
+			diff.Action = TargetDiffUnchanged
+			if len(diff.Changes) > 0 {
+				diff.Action = TargetDiffModify
+			}
+		case object.Replace || definitions.Dropped[key]:
+			diff.Recreate = definitions.Dropped[key]
+			diff.Action = TargetDiffModify
+			diff.Changes = []string{"替换现有定义"}
+		default:
+			diff.Action = TargetDiffUnchanged
+			diff.Changes = []string{"同名对象已存在，未比较定义"}
+		}
+		report.Objects = append(report.Objects, diff)
+	}
+
+	for _, table := range definitions.dataOrder {
+		load := *definitions.Data[table]
+		load.Exists = catalog.HasTable(table) || definedTables[table]
+		report.Data = append(report.Data, load)
+	}
+	if len(definedTables) > 0 {
+		for table := range catalog.Tables {
+			if !definedTables[table] {
+				report.TargetOnly = append(report.TargetOnly, table)
+			}
+		}
+		sort.Strings(report.TargetOnly)
+	}
+	return report
+}
+
+// diffTableColumns 表的列差异：新增、删除和类型变化的列
+func diffTableColumns(object SQLObjectDef, existing []postgres.CatalogColumn) []string {
+	if object.Truncated {
+		return nil
+	}
+	current := make(map[string]string, len(existing))
+	for _, column := range existing {
+		current[column.Name] = column.Type
+	}
+	defined := make(map[string]bool, len(object.Columns))
+	var changes []string
+	for _, column := range object.Columns {
+		defined[column.Name] = true
+		currentType, ok := current[column.Name]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("新增列 %s %s", column.Name, column.Type))
+		case NormalizePGType(currentType) != NormalizePGType(column.Type):
+			changes = append(changes, fmt.Sprintf("列 %s 类型 %s → %s", column.Name, currentType, NormalizePGType(column.Type)))
+		}
+	}
+	for _, column := range existing {
+		if !defined[column.Name] {
+			changes = append(changes, fmt.Sprintf("删除列 %s", column.Name))
+		}
+	}
+	return changes
+}
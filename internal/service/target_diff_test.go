@@ -0,0 +1,131 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"ora2pg-admin/internal/postgres"
+)
+
+func TestNormalizePGType(t *testing.T) {
+	cases := map[string]string{
+		"varchar(100)":              "character varying(100)",
+		"VARCHAR (100)":             "character varying(100)",
+		"char":                      "character(1)",
+		"int":                       "integer",
+		"int8":                      "bigint",
+		"numeric(10)":               "numeric(10,0)",
+		"numeric(10, 2)":            "numeric(10,2)",
+		"decimal":                   "numeric",
+		"timestamp":                 "timestamp without time zone",
+		"timestamp(6)":              "timestamp(6) without time zone",
+		"timestamptz":               "timestamp with time zone",
+		"timestamp with time zone":  "timestamp with time zone",
+		"float(10)":                 "real",
+		"float":                     "double precision",
+		"bool":                      "boolean",
+		"integer[]":                 "integer[]",
+		"pg_catalog.int4":           "integer",
+		"character varying(100)":    "character varying(100)",
+		"double precision":          "double precision",
+		"hr.address_t":              "hr.address_t",
+		"time(3) without time zone": "time(3) without time zone",
+	}
+	for input, expected := range cases {
+		assert.Equal(t, expected, NormalizePGType(input), input)
+	}
+}
+
+func TestDiffTargetObjects(t *testing.T) {
+	dir := t.TempDir()
+	tableSQL := `SET search_path = hr,public;
+
+DROP TABLE IF EXISTS hr.dept CASCADE;
+CREATE TABLE hr.dept (
+	id bigint NOT NULL,
+	name varchar(50),
+	PRIMARY KEY (id)
+) ;
+CREATE TABLE emp (
+	id integer,
+	name varchar(200) DEFAULT 'a, b',
+	salary numeric(10,2) NOT NULL,
+	CONSTRAINT emp_ck CHECK (salary > 0)
+) ;
+CREATE TABLE "Audit" (id int, at timestamp(6)) ;
+CREATE TABLE projects (id int) ;
+CREATE INDEX emp_name_idx ON emp (name);
+CREATE INDEX ON emp (salary);
+CREATE OR REPLACE VIEW emp_v AS SELECT * FROM emp;
+CREATE SEQUENCE emp_seq;
+CREATE OR REPLACE FUNCTION hr.raise_salary(pct numeric) RETURNS void AS $body$
+BEGIN
+  UPDATE emp SET salary = salary * (1 + pct); -- ; 不会结束语句
+END;
+$body$ LANGUAGE plpgsql;
+`
+	dataSQL := "TRUNCATE TABLE emp;\nCOPY emp (id, name, salary) FROM STDIN;\n1\ta\t10\n2\tb\t20\n\\.\nINSERT INTO missing VALUES (1);\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "TABLE_output.sql"), []byte(tableSQL), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "COPY_output.sql"), []byte(dataSQL), 0644))
+
+	definitions, err := ParseSQLDefinitions([]string{filepath.Join(dir, "TABLE_output.sql"), filepath.Join(dir, "COPY_output.sql")})
+	require.NoError(t, err)
+	catalog := &postgres.Catalog{
+		Schema: "hr",
+		Tables: map[string][]postgres.CatalogColumn{
+			"dept":   {{Name: "id", Type: "bigint"}, {Name: "name", Type: "character varying(50)"}},
+			"emp":    {{Name: "id", Type: "integer"}, {Name: "name", Type: "character varying(100)"}, {Name: "note", Type: "text"}},
+			"Audit":  {{Name: "id", Type: "integer"}, {Name: "at", Type: "timestamp(6) without time zone"}},
+			"legacy": {{Name: "id", Type: "integer"}},
+		},
+		Objects: map[string]map[string]bool{
+			postgres.CatalogView:     {"emp_v": true},
+			postgres.CatalogSequence: {"emp_seq": true},
+		},
+	}
+	report := DiffTargetObjects(definitions, catalog)
+
+	diffs := make(map[string]TargetObjectDiff)
+	for _, object := range report.Objects {
+		diffs[object.Kind+" "+object.Name] = object
+	}
+	// 未命名的索引不比较
+	require.Len(t, diffs, 8)
+
+	// 类型写法不同但规范化后相同的表无变化，先删除再重建时提示
+	assert.Equal(t, TargetDiffUnchanged, diffs["TABLE dept"].Action)
+	assert.True(t, diffs["TABLE dept"].Recreate)
+	assert.Equal(t, TargetDiffUnchanged, diffs["TABLE Audit"].Action)
+	assert.Equal(t, TargetDiffModify, diffs["TABLE emp"].Action)
+	assert.Equal(t, []string{
+		"列 name 类型 character varying(100) → character varying(200)",
+		"新增列 salary numeric(10,2)",
+		"删除列 note",
+	}, diffs["TABLE emp"].Changes)
+	assert.Equal(t, TargetDiffCreate, diffs["TABLE projects"].Action)
+	assert.Equal(t, 16, diffs["TABLE projects"].Line)
+
+	// 其他对象只比较名称，CREATE OR REPLACE 已有对象时视为修改
+	assert.Equal(t, TargetDiffCreate, diffs["INDEX emp_name_idx"].Action)
+	assert.Equal(t, TargetDiffModify, diffs["VIEW emp_v"].Action)
+	assert.Equal(t, TargetDiffUnchanged, diffs["SEQUENCE emp_seq"].Action)
+	assert.Equal(t, TargetDiffCreate, diffs["FUNCTION raise_salary"].Action)
+
+	assert.Equal(t, 3, report.Count("", TargetDiffCreate))
+	assert.Equal(t, 2, report.Count("", TargetDiffModify))
+	assert.Equal(t, 3, report.Count(postgres.CatalogTable, TargetDiffUnchanged)+report.Count(postgres.CatalogSequence, TargetDiffUnchanged))
+	assert.Equal(t, "将新增 3 个对象、修改 2 个、无变化 3 个", report.Summary())
+	assert.Equal(t, "表: 新增 1、修改 1、无变化 2", report.KindSummaries()[0])
+	assert.True(t, report.HasChanges())
+
+	// 数据：COPY 按行数统计，导入前清空的表和目标库中没有的表单独标记
+	assert.Equal(t, []TargetDataLoad{
+		{Table: "emp", Rows: 2, Truncate: true, Exists: true},
+		{Table: "missing", Rows: 1, Exists: false},
+	}, report.Data)
+	assert.Equal(t, []string{"legacy"}, report.TargetOnly)
+}
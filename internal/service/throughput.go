@@ -0,0 +1,449 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// throughputInterval 吞吐率采样间隔
+const throughputInterval = 5 * time.Second
+
+// 吞吐率分析参数
+const (
+	// throughputStallRatio 吞吐率低于平均值的该比例时视为卡顿
+	throughputStallRatio = 0.2
+	// throughputMinStall 持续不到该时长的低谷不报告
+	throughputMinStall = 2 * throughputInterval
+	// throughputCPUBoundRatio 卡顿时段的CPU不低于整体平均CPU的该比例时，判断为受CPU限制
+	throughputCPUBoundRatio = 0.5
+)
+
+// 卡顿时段的瓶颈类型
+const (
+	ThroughputBottleneckCPU = "cpu"
+	ThroughputBottleneckIO  = "io"
+)
+
+// tableProgressPattern ora2pg的表级进度条，如 "[====>] 500/1000 rows (50.0%) Table EMPLOYEES (250 recs/sec)"
+var tableProgressPattern = regexp.MustCompile(`(\d+)/\d+\s+rows\s+\([\d.]+%\)\s+Table\s+([\w$#.]+)`)
+
+// rowProgressCounter 根据表级进度条统计已迁移的行数
+//
+// 进度条给出的是各表的累计行数，同一行中可能有多个以 \r 分隔的进度条，取各表的最大值。
+type rowProgressCounter struct {
+	mu     sync.Mutex
+	tables map[string]int64
+}
+
+// newRowProgressCounter 创建行数统计器，每个迁移类型使用一个
+func newRowProgressCounter() *rowProgressCounter {
+	return &rowProgressCounter{tables: make(map[string]int64)}
+}
+
+// Feed 处理一行输出，返回本行新增的已迁移行数（stdout和stderr可能并发调用）
+func (c *rowProgressCounter) Feed(line string) int64 {
+	matches := tableProgressPattern.FindAllStringSubmatch(line, -1)
+	if matches == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var added int64
+	for _, match := range matches {
+		rows, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		if previous := c.tables[match[2]]; rows > previous {
+			added += rows - previous
+			c.tables[match[2]] = rows
+		}
+	}
+	return added
+}
+
+// ThroughputSample 一个采样周期内的吞吐率
+type ThroughputSample struct {
+	Time time.Time `json:"time"`
+	// Elapsed 采样时距迁移开始的时间
+	Elapsed time.Duration `json:"elapsed"`
+	// Rows 截至采样时已迁移的总行数
+	Rows          int64   `json:"rows"`
+	RowsPerSecond float64 `json:"rows_per_second"`
+	// Resource 采样时最近一次的资源使用，未开启资源监控时为空
+	Resource *ResourceSample `json:"resource,omitempty"`
+}
+
+// AddRows 累加已迁移的行数，用于采集吞吐率
+func (pt *ProgressTracker) AddRows(rows int64) {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	pt.processedRows += rows
+}
+
+// RecordResource 记录最近一次资源采样，附加到之后的吞吐率采样中
+func (pt *ProgressTracker) RecordResource(sample ResourceSample) {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	pt.latestResource = &sample
+}
+
+// ThroughputSamples 获取吞吐率时间序列
+func (pt *ProgressTracker) ThroughputSamples() []ThroughputSample {
+	pt.mutex.RLock()
+	defer pt.mutex.RUnlock()
+	return append([]ThroughputSample(nil), pt.throughput...)
+}
+
+// ThroughputReport 分析吞吐率时间序列
+func (pt *ProgressTracker) ThroughputReport() *ThroughputReport {
+	return AnalyzeThroughput(pt.ThroughputSamples())
+}
+
+// resetThroughput 开始跟踪时清空吞吐率数据（调用方需持有锁）
+func (pt *ProgressTracker) resetThroughput() {
+	pt.processedRows = 0
+	pt.sampledRows = 0
+	pt.sampledTime = pt.startTime
+	pt.latestResource = nil
+	pt.throughput = nil
+}
+
+// recordThroughput 定期采样吞吐率
+func (pt *ProgressTracker) recordThroughput() {
+	pt.mutex.Lock()
+	defer pt.mutex.Unlock()
+	if pt.isRunning {
+		pt.sampleThroughput(time.Now())
+	}
+}
+
+// sampleThroughput 计算上次采样以来的吞吐率（调用方需持有锁）
+func (pt *ProgressTracker) sampleThroughput(now time.Time) {
+	period := now.Sub(pt.sampledTime)
+	if period <= 0 {
+		return
+	}
+	sample := ThroughputSample{
+		Time:          now,
+		Elapsed:       now.Sub(pt.startTime),
+		Rows:          pt.processedRows,
+		RowsPerSecond: float64(pt.processedRows-pt.sampledRows) / period.Seconds(),
+		Resource:      pt.latestResource,
+	}
+	pt.throughput = append(pt.throughput, sample)
+	pt.sampledRows = pt.processedRows
+	pt.sampledTime = now
+}
+
+// finishThroughput 跟踪结束时补充最后一个不足采样间隔的采样（调用方需持有锁）
+func (pt *ProgressTracker) finishThroughput(now time.Time) {
+	// 间隔太短时吞吐率波动大，仅在有新增行数时采样
+	if now.Sub(pt.sampledTime) >= time.Second || pt.processedRows > pt.sampledRows {
+		pt.sampleThroughput(now)
+	}
+}
+
+// ThroughputStall 吞吐率明显低于平均值的时段
+type ThroughputStall struct {
+	Start         time.Duration `json:"start"`
+	End           time.Duration `json:"end"`
+	RowsPerSecond float64       `json:"rows_per_second"`
+	// AvgCPUPercent 时段内的平均CPU，没有资源采样时为0
+	AvgCPUPercent float64 `json:"avg_cpu_percent,omitempty"`
+	// Bottleneck 根据CPU判断的瓶颈类型（cpu、io），没有资源采样时为空
+	Bottleneck string `json:"bottleneck,omitempty"`
+}
+
+// Hint 根据瓶颈类型给出的分析
+func (s ThroughputStall) Hint() string {
+	switch s.Bottleneck {
+	case ThroughputBottleneckCPU:
+		return fmt.Sprintf("CPU %.0f%% 仍然较高，可能受ora2pg数据转换的CPU限制", s.AvgCPUPercent)
+	case ThroughputBottleneckIO:
+		return fmt.Sprintf("CPU %.0f%% 明显下降，可能在等待源库读取、目标库写入或磁盘IO", s.AvgCPUPercent)
+	}
+	return "未开启资源监控（--monitor），无法判断瓶颈类型"
+}
+
+// ThroughputReport 吞吐率分析报告
+type ThroughputReport struct {
+	Samples   []ThroughputSample `json:"samples"`
+	TotalRows int64              `json:"total_rows"`
+	// Start、End 有数据导出的时段，平均吞吐率按该时段计算
+	Start                time.Duration     `json:"start"`
+	End                  time.Duration     `json:"end"`
+	AverageRowsPerSecond float64           `json:"average_rows_per_second"`
+	PeakRowsPerSecond    float64           `json:"peak_rows_per_second"`
+	PeakAt               time.Duration     `json:"peak_at"`
+	Stalls               []ThroughputStall `json:"stalls,omitempty"`
+	// hasResource 采样中带有资源使用数据
+	hasResource bool
+}
+
+// AnalyzeThroughput 统计吞吐率并找出卡顿时段，没有迁移数据行时返回nil
+func AnalyzeThroughput(samples []ThroughputSample) *ThroughputReport {
+	first, last := -1, -1
+	for i, sample := range samples {
+		if sample.RowsPerSecond > 0 {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+
+	report := &ThroughputReport{
+		Samples:   samples,
+		TotalRows: samples[last].Rows,
+		Start:     sampleStart(samples, first),
+		End:       samples[last].Elapsed,
+	}
+	var cpuSum float64
+	var cpuSamples int
+	for _, sample := range samples[first : last+1] {
+		if sample.RowsPerSecond > report.PeakRowsPerSecond {
+			report.PeakRowsPerSecond = sample.RowsPerSecond
+			report.PeakAt = sample.Elapsed
+		}
+		if sample.Resource != nil {
+			cpuSum += sample.Resource.CPUPercent
+			cpuSamples++
+		}
+	}
+	report.hasResource = cpuSamples > 0
+	rows := samples[last].Rows
+	if first > 0 {
+		rows -= samples[first-1].Rows
+	}
+	if span := report.End - report.Start; span > 0 {
+		report.AverageRowsPerSecond = float64(rows) / span.Seconds()
+	}
+	var avgCPU float64
+	if cpuSamples > 0 {
+		avgCPU = cpuSum / float64(cpuSamples)
+	}
+
+	// 合并连续低于阈值的采样为卡顿时段
+	threshold := report.AverageRowsPerSecond * throughputStallRatio
+	for i := first; i <= last; i++ {
+		if samples[i].RowsPerSecond >= threshold {
+			continue
+		}
+		j := i
+		for j+1 <= last && samples[j+1].RowsPerSecond < threshold {
+			j++
+		}
+		if stall := newThroughputStall(samples, i, j, avgCPU); stall.End-stall.Start >= throughputMinStall {
+			report.Stalls = append(report.Stalls, stall)
+		}
+		i = j
+	}
+	return report
+}
+
+// sampleStart 采样周期的开始时间
+func sampleStart(samples []ThroughputSample, i int) time.Duration {
+	if i == 0 {
+		return 0
+	}
+	return samples[i-1].Elapsed
+}
+
+// newThroughputStall 统计第 from 到 to 个采样组成的卡顿时段
+func newThroughputStall(samples []ThroughputSample, from, to int, avgCPU float64) ThroughputStall {
+	stall := ThroughputStall{Start: sampleStart(samples, from), End: samples[to].Elapsed}
+	rows := samples[to].Rows
+	if from > 0 {
+		rows -= samples[from-1].Rows
+	}
+	if span := stall.End - stall.Start; span > 0 {
+		stall.RowsPerSecond = float64(rows) / span.Seconds()
+	}
+
+	var cpuSum float64
+	var cpuSamples int
+	for _, sample := range samples[from : to+1] {
+		if sample.Resource != nil {
+			cpuSum += sample.Resource.CPUPercent
+			cpuSamples++
+		}
+	}
+	if cpuSamples > 0 {
+		stall.AvgCPUPercent = cpuSum / float64(cpuSamples)
+		// 吞吐下降而CPU仍然很忙，说明转换本身慢；CPU随吞吐一起下降，说明在等待IO
+		stall.Bottleneck = ThroughputBottleneckIO
+		if stall.AvgCPUPercent >= avgCPU*throughputCPUBoundRatio && stall.AvgCPUPercent > 0 {
+			stall.Bottleneck = ThroughputBottleneckCPU
+		}
+	}
+	return stall
+}
+
+// Summary 获取吞吐率摘要
+func (r *ThroughputReport) Summary() string {
+	return fmt.Sprintf("共 %d 行，平均 %s 行/秒，峰值 %s 行/秒（%s）", r.TotalRows,
+		formatRate(r.AverageRowsPerSecond), formatRate(r.PeakRowsPerSecond), formatElapsed(r.PeakAt))
+}
+
+// throughputBlocks 柱状图中按八分之一高度递增的字符
+var throughputBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Chart 以字符柱状图显示吞吐率随时间的变化，width 为柱数上限，height 为行数
+//
+// 采样多于 width 时相邻采样合并为一根柱，取平均吞吐率。
+func (r *ThroughputReport) Chart(width, height int) []string {
+	if width <= 0 || height <= 0 || len(r.Samples) == 0 {
+		return nil
+	}
+	columns := r.columns(width)
+	maxRate := 0.0
+	for _, rate := range columns {
+		maxRate = math.Max(maxRate, rate)
+	}
+
+	top := formatRate(maxRate)
+	labelWidth := len(top)
+	lines := make([]string, 0, height+2)
+	for row := height - 1; row >= 0; row-- {
+		label := ""
+		switch row {
+		case height - 1:
+			label = top
+		case 0:
+			label = "0"
+		}
+		var bar strings.Builder
+		for _, rate := range columns {
+			units := 0
+			if maxRate > 0 {
+				units = int(math.Round(rate / maxRate * float64(height*8)))
+			}
+			switch cell := units - row*8; {
+			case cell >= 8:
+				bar.WriteRune(throughputBlocks[7])
+			case cell > 0:
+				bar.WriteRune(throughputBlocks[cell-1])
+			default:
+				bar.WriteByte(' ')
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%*s ┤%s", labelWidth, label, bar.String()))
+	}
+	lines = append(lines, fmt.Sprintf("%*s └%s", labelWidth, "", strings.Repeat("─", len(columns))))
+
+	start, end := "0s", formatElapsed(r.Samples[len(r.Samples)-1].Elapsed)
+	gap := len(columns) - len(start) - len(end)
+	if gap < 1 {
+		gap = 1
+	}
+	lines = append(lines, fmt.Sprintf("%*s  %s%s%s", labelWidth, "", start, strings.Repeat(" ", gap), end))
+	return lines
+}
+
+// columns 把采样合并为最多 width 根柱
+func (r *ThroughputReport) columns(width int) []float64 {
+	count := len(r.Samples)
+	if count < width {
+		width = count
+	}
+	columns := make([]float64, width)
+	for i := range columns {
+		from, to := i*count/width, (i+1)*count/width
+		var sum float64
+		for _, sample := range r.Samples[from:to] {
+			sum += sample.RowsPerSecond
+		}
+		columns[i] = sum / float64(to-from)
+	}
+	return columns
+}
+
+// SVG 生成吞吐率随时间变化的SVG图表，有资源采样时叠加CPU曲线，卡顿时段以浅红色标出
+func (r *ThroughputReport) SVG() string {
+	const (
+		width, height = 800, 320
+		left, right   = 70, 60
+		top, bottom   = 30, 40
+	)
+	plotWidth, plotHeight := float64(width-left-right), float64(height-top-bottom)
+	duration := r.Samples[len(r.Samples)-1].Elapsed.Seconds()
+	if duration <= 0 {
+		duration = 1
+	}
+	maxRate := math.Max(r.PeakRowsPerSecond, 1)
+	maxCPU := 100.0
+	for _, sample := range r.Samples {
+		if sample.Resource != nil {
+			maxCPU = math.Max(maxCPU, sample.Resource.CPUPercent)
+		}
+	}
+	x := func(elapsed time.Duration) float64 { return left + elapsed.Seconds()/duration*plotWidth }
+	y := func(value, max float64) float64 { return top + plotHeight - value/max*plotHeight }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n", width, height, width, height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#ffffff"/>`+"\n", width, height)
+	fmt.Fprintf(&b, `<text x="%d" y="18" font-size="14">吞吐率（行/秒）：%s</text>`+"\n", left, svgEscape(r.Summary()))
+	for _, stall := range r.Stalls {
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%d" width="%.1f" height="%.0f" fill="#f8d7da"><title>%s</title></rect>`+"\n",
+			x(stall.Start), top, x(stall.End)-x(stall.Start), plotHeight, svgEscape(stall.Hint()))
+	}
+	fmt.Fprintf(&b, `<polyline fill="none" stroke="#999999" points="%d,%d %d,%d %d,%d"/>`+"\n",
+		left, top, left, top+int(plotHeight), width-right, top+int(plotHeight))
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`+"\n", left-6, top+4, formatRate(maxRate))
+	fmt.Fprintf(&b, `<text x="%d" y="%.0f" text-anchor="end">0</text>`+"\n", left-6, top+plotHeight+4)
+	fmt.Fprintf(&b, `<text x="%d" y="%d">0s</text>`+"\n", left, height-bottom+18)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`+"\n", width-right, height-bottom+18,
+		formatElapsed(r.Samples[len(r.Samples)-1].Elapsed))
+
+	rates := make([]string, 0, len(r.Samples)+1)
+	rates = append(rates, fmt.Sprintf("%d,%.1f", left, y(0, maxRate)))
+	var cpu []string
+	for _, sample := range r.Samples {
+		rates = append(rates, fmt.Sprintf("%.1f,%.1f", x(sample.Elapsed), y(sample.RowsPerSecond, maxRate)))
+		if sample.Resource != nil {
+			cpu = append(cpu, fmt.Sprintf("%.1f,%.1f", x(sample.Elapsed), y(sample.Resource.CPUPercent, maxCPU)))
+		}
+	}
+	fmt.Fprintf(&b, `<polyline fill="none" stroke="#1f77b4" stroke-width="2" points="%s"/>`+"\n", strings.Join(rates, " "))
+	if len(cpu) > 0 {
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="#ff7f0e" stroke-width="1.5" stroke-dasharray="4 3" points="%s"/>`+"\n", strings.Join(cpu, " "))
+		fmt.Fprintf(&b, `<text x="%d" y="%d" fill="#ff7f0e">CPU %.0f%%</text>`+"\n", width-right+6, top+4, maxCPU)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" fill="#ff7f0e">CPU（虚线）</text>`+"\n", width-right-140, 18)
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// HasResource 采样中是否带有资源使用数据
+func (r *ThroughputReport) HasResource() bool {
+	return r.hasResource
+}
+
+// formatRate 格式化吞吐率
+func formatRate(rate float64) string {
+	if rate >= 10 || rate == 0 {
+		return strconv.FormatFloat(math.Round(rate), 'f', 0, 64)
+	}
+	return strconv.FormatFloat(rate, 'f', 1, 64)
+}
+
+// formatElapsed 格式化距迁移开始的时间
+func formatElapsed(elapsed time.Duration) string {
+	return elapsed.Round(time.Second).String()
+}
+
+// svgEscape 转义SVG文本中的特殊字符
+func svgEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(text)
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// throughputSamples 按采样间隔生成吞吐率序列，cpu 为空时不带资源采样
+func throughputSamples(rates []float64, cpu []float64) []ThroughputSample {
+	samples := make([]ThroughputSample, len(rates))
+	var rows int64
+	for i, rate := range rates {
+		rows += int64(rate * throughputInterval.Seconds())
+		samples[i] = ThroughputSample{Elapsed: time.Duration(i+1) * throughputInterval, Rows: rows, RowsPerSecond: rate}
+		if cpu != nil {
+			samples[i].Resource = &ResourceSample{CPUPercent: cpu[i]}
+		}
+	}
+	return samples
+}
+
+func TestRowProgressCounter(t *testing.T) {
+	counter := newRowProgressCounter()
+	assert.Equal(t, int64(500), counter.Feed("[=====>     ] 500/1000 rows (50.0%) Table EMPLOYEES (250 recs/sec)"))
+
+	// 同一行中以 \r 分隔的多个进度条按各表的最大值累计
+	assert.Equal(t, int64(514), counter.Feed("[=======>   ] 600/1000 rows (60.0%) Table EMPLOYEES\r"+
+		"[========================>] 1000/1000 rows (100.0%) Table EMPLOYEES (500 recs/sec)\r"+
+		"[========================>] 14/14 rows (100.0%) Table COUNTRIES (14 recs/sec)"))
+	assert.Equal(t, int64(0), counter.Feed("[========================>] 1000/1000 rows (100.0%) Table EMPLOYEES (500 recs/sec)"))
+
+	// 总进度行不计入，避免与表级进度重复
+	assert.Equal(t, int64(0), counter.Feed("[========================>] 1014/1014 total rows (100.0%) - (2 sec., avg: 507 recs/sec)."))
+	assert.Equal(t, int64(0), counter.Feed("Processing table: EMPLOYEES (1/2)"))
+}
+
+func TestProgressTrackerThroughput(t *testing.T) {
+	pt := NewProgressTracker()
+	start := time.Now()
+	pt.startTime = start
+	pt.resetThroughput()
+
+	pt.AddRows(500)
+	pt.RecordResource(ResourceSample{CPUPercent: 80})
+	pt.sampleThroughput(start.Add(5 * time.Second))
+	pt.AddRows(1500)
+	pt.sampleThroughput(start.Add(10 * time.Second))
+
+	// 结束时间隔太短且没有新增行数时不补充采样
+	pt.finishThroughput(start.Add(10*time.Second + 200*time.Millisecond))
+	samples := pt.ThroughputSamples()
+	require.Len(t, samples, 2)
+	assert.Equal(t, 100.0, samples[0].RowsPerSecond)
+	assert.Equal(t, 300.0, samples[1].RowsPerSecond)
+	assert.Equal(t, int64(2000), samples[1].Rows)
+	assert.Equal(t, 10*time.Second, samples[1].Elapsed)
+	require.NotNil(t, samples[1].Resource)
+	assert.Equal(t, 80.0, samples[1].Resource.CPUPercent)
+
+	pt.AddRows(100)
+	pt.finishThroughput(start.Add(10*time.Second + 500*time.Millisecond))
+	samples = pt.ThroughputSamples()
+	require.Len(t, samples, 3)
+	assert.Equal(t, 200.0, samples[2].RowsPerSecond)
+}
+
+func TestAnalyzeThroughput(t *testing.T) {
+	// 前两个采样是结构迁移，没有数据行；中间 15 秒吞吐下降且CPU同时下降
+	samples := throughputSamples(
+		[]float64{0, 0, 1000, 1000, 1000, 50, 50, 50, 1000, 1000},
+		[]float64{5, 5, 100, 100, 100, 10, 10, 10, 100, 100})
+	report := AnalyzeThroughput(samples)
+	require.NotNil(t, report)
+
+	assert.Equal(t, int64(25750), report.TotalRows)
+	assert.Equal(t, 10*time.Second, report.Start)
+	assert.Equal(t, 50*time.Second, report.End)
+	assert.InDelta(t, 643.75, report.AverageRowsPerSecond, 0.01)
+	assert.Equal(t, 1000.0, report.PeakRowsPerSecond)
+	assert.Equal(t, 15*time.Second, report.PeakAt)
+	assert.True(t, report.HasResource())
+
+	require.Len(t, report.Stalls, 1)
+	stall := report.Stalls[0]
+	assert.Equal(t, 25*time.Second, stall.Start)
+	assert.Equal(t, 40*time.Second, stall.End)
+	assert.Equal(t, 50.0, stall.RowsPerSecond)
+	assert.Equal(t, ThroughputBottleneckIO, stall.Bottleneck)
+	assert.Contains(t, stall.Hint(), "IO")
+
+	// CPU仍然很忙时判断为受CPU限制；不足两个采样间隔的低谷不报告
+	samples = throughputSamples(
+		[]float64{1000, 1000, 50, 50, 1000, 50, 1000},
+		[]float64{100, 100, 90, 90, 100, 20, 100})
+	report = AnalyzeThroughput(samples)
+	require.NotNil(t, report)
+	require.Len(t, report.Stalls, 1)
+	assert.Equal(t, 10*time.Second, report.Stalls[0].Start)
+	assert.Equal(t, ThroughputBottleneckCPU, report.Stalls[0].Bottleneck)
+
+	// 没有资源采样时无法判断瓶颈
+	report = AnalyzeThroughput(throughputSamples([]float64{1000, 10, 10, 1000}, nil))
+	require.NotNil(t, report)
+	require.Len(t, report.Stalls, 1)
+	assert.Empty(t, report.Stalls[0].Bottleneck)
+	assert.False(t, report.HasResource())
+
+	// 没有迁移数据行时没有报告
+	assert.Nil(t, AnalyzeThroughput(throughputSamples([]float64{0, 0}, nil)))
+	assert.Nil(t, AnalyzeThroughput(nil))
+}
+
+func TestThroughputChart(t *testing.T) {
+	report := AnalyzeThroughput(throughputSamples([]float64{100, 100, 400, 400, 250, 250, 0, 0}, nil))
+	require.NotNil(t, report)
+
+	// 采样多于柱数时相邻采样取平均合并
+	lines := report.Chart(4, 4)
+	require.Len(t, lines, 6)
+	assert.Equal(t, "400 ┤ █  ", lines[0])
+	assert.Equal(t, "    ┤ █▄ ", lines[1])
+	assert.Equal(t, "    ┤ ██ ", lines[2])
+	assert.Equal(t, "  0 ┤███ ", lines[3])
+	assert.Equal(t, "    └────", lines[4])
+	assert.Equal(t, "     0s 40s", lines[5])
+
+	assert.Nil(t, report.Chart(0, 4))
+}
+
+func TestThroughputSVG(t *testing.T) {
+	report := AnalyzeThroughput(throughputSamples(
+		[]float64{1000, 1000, 10, 10, 10, 1000},
+		[]float64{100, 100, 5, 5, 5, 100}))
+	require.NotNil(t, report)
+
+	svg := report.SVG()
+	assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg"`))
+	assert.True(t, strings.HasSuffix(svg, "</svg>\n"))
+	assert.Equal(t, 3, strings.Count(svg, "<polyline"))
+	assert.Contains(t, svg, `<rect x="`)
+	assert.Contains(t, svg, "可能在等待源库读取")
+	assert.Contains(t, svg, "峰值 1000 行/秒")
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"time"
+)
+
+// timeoutWarningRatios 超时预警的分级阈值，按已运行时间占超时时间的比例
+var timeoutWarningRatios = []float64{0.8, 0.95}
+
+// TimeoutWarningHandler 命令接近超时时回调，返回需要延长的时间，0表示不延长
+type TimeoutWarningHandler func(migrationType MigrationType, elapsed, timeout time.Duration) time.Duration
+
+// commandTimeout 命令执行的超时计时，到达各级阈值时触发预警，可在运行中延长
+//
+// nil 表示不限制超时，此时 Warning 和 Expired 返回的通道永远不会触发。
+type commandTimeout struct {
+	start   time.Time
+	timeout time.Duration
+	expired *time.Timer
+	warning *time.Timer
+}
+
+// newCommandTimeout 从现在开始计时
+func newCommandTimeout(timeout time.Duration) *commandTimeout {
+	t := &commandTimeout{
+		start:   time.Now(),
+		timeout: timeout,
+		expired: time.NewTimer(timeout),
+	}
+	t.armWarning()
+	return t
+}
+
+// Expired 到达超时时间时触发
+func (t *commandTimeout) Expired() <-chan time.Time {
+	if t == nil {
+		return nil
+	}
+	return t.expired.C
+}
+
+// Warning 到达下一级预警阈值时触发，所有级别都已触发后返回 nil
+func (t *commandTimeout) Warning() <-chan time.Time {
+	if t == nil || t.warning == nil {
+		return nil
+	}
+	return t.warning.C
+}
+
+// Elapsed 已运行时间
+func (t *commandTimeout) Elapsed() time.Duration {
+	return time.Since(t.start)
+}
+
+// Timeout 当前的超时时间（含已延长的部分）
+func (t *commandTimeout) Timeout() time.Duration {
+	return t.timeout
+}
+
+// NextWarning 一级预警处理完后，准备下一级预警
+func (t *commandTimeout) NextWarning() {
+	t.armWarning()
+}
+
+// Extend 延长超时时间，按新的超时时间重新计算尚未到达的预警级别
+func (t *commandTimeout) Extend(extension time.Duration) {
+	t.timeout += extension
+	t.expired.Reset(t.timeout - t.Elapsed())
+	t.armWarning()
+}
+
+// Stop 停止计时
+func (t *commandTimeout) Stop() {
+	if t == nil {
+		return
+	}
+	t.expired.Stop()
+	if t.warning != nil {
+		t.warning.Stop()
+	}
+}
+
+// armWarning 设置下一个尚未到达的预警级别的计时器
+func (t *commandTimeout) armWarning() {
+	if t.warning != nil {
+		t.warning.Stop()
+		t.warning = nil
+	}
+
+	elapsed := t.Elapsed()
+	for _, ratio := range timeoutWarningRatios {
+		threshold := time.Duration(float64(t.timeout) * ratio)
+		if threshold > elapsed {
+			t.warning = time.NewTimer(threshold - elapsed)
+			return
+		}
+	}
+}
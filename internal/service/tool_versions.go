@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/oracle"
+	"ora2pg-admin/internal/postgres"
+	"ora2pg-admin/internal/utils"
+)
+
+// toolVersionTimeout 检测单个工具版本的超时时间
+const toolVersionTimeout = 10 * time.Second
+
+// 工具版本的校验结果
+const (
+	ToolVersionMatched    = "matched"
+	ToolVersionMismatched = "mismatched"
+	// ToolVersionUnknown 工具不存在、版本无法识别或约束无效
+	ToolVersionUnknown = "unknown"
+)
+
+// ToolVersionCheck 一个工具的版本校验结果
+type ToolVersionCheck struct {
+	Tool     string `json:"tool"`
+	Expected string `json:"expected"`
+	// Output 工具输出的版本行，如 "psql (PostgreSQL) 16.2"
+	Output  string `json:"output,omitempty"`
+	Version string `json:"version,omitempty"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+// detectToolVersion 执行工具的版本命令，返回输出的版本行
+func detectToolVersion(ctx context.Context, tool string, cfg *config.ProjectConfig) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, toolVersionTimeout)
+	defer cancel()
+
+	switch tool {
+	case "ora2pg":
+		raw, _, err := DetectOra2pgVersion(ctx, ora2pgEnvironment(cfg))
+		if raw != "" {
+			return raw, nil
+		}
+		return "", err
+	case "sqlplus":
+		return oracle.NewSQLPlusRunner(&cfg.Oracle, &cfg.OracleClient).Version(ctx)
+	case "psql":
+		return postgres.PSQLVersion(ctx)
+	}
+	return "", fmt.Errorf("不支持的工具: %s", tool)
+}
+
+// CheckToolVersions 检测 tools 中配置了期望版本的工具，按配置顺序返回校验结果
+func CheckToolVersions(ctx context.Context, cfg *config.ProjectConfig) []*ToolVersionCheck {
+	var checks []*ToolVersionCheck
+	for _, item := range cfg.Tools.Constraints() {
+		check := &ToolVersionCheck{Tool: item.Tool, Expected: item.Constraint, Status: ToolVersionUnknown}
+		checks = append(checks, check)
+
+		constraint, err := config.ParseVersionConstraint(item.Constraint)
+		if err != nil {
+			check.Error = fmt.Sprintf("版本约束无效: %v", err)
+			continue
+		}
+		output, err := detectToolVersion(ctx, item.Tool, cfg)
+		if err != nil {
+			check.Error = toolVersionError(err)
+			continue
+		}
+		check.Output = output
+		version, ok := config.ParseToolVersion(output)
+		if !ok {
+			check.Error = "无法从版本输出中识别版本号"
+			continue
+		}
+		check.Version = version.String()
+		if constraint.Match(version) {
+			check.Status = ToolVersionMatched
+		} else {
+			check.Status = ToolVersionMismatched
+		}
+	}
+	return checks
+}
+
+// toolVersionError 版本检测失败的简要原因
+func toolVersionError(err error) string {
+	var appErr *utils.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Message
+	}
+	return err.Error()
+}
+
+// Describe 校验结果的说明，如 "ora2pg 期望 24.1，实际 23.2"
+func (c *ToolVersionCheck) Describe() string {
+	switch c.Status {
+	case ToolVersionMatched:
+		return fmt.Sprintf("%s %s 符合期望版本 %s", c.Tool, c.Version, c.Expected)
+	case ToolVersionMismatched:
+		return fmt.Sprintf("%s 期望 %s，实际 %s", c.Tool, c.Expected, c.Version)
+	}
+	return fmt.Sprintf("%s 期望 %s，无法确认实际版本: %s", c.Tool, c.Expected, c.Error)
+}
+
+// ToolVersionMismatchError 存在不匹配或无法确认版本的工具时返回错误
+func ToolVersionMismatchError(checks []*ToolVersionCheck) error {
+	var problems []string
+	for _, check := range checks {
+		if check.Status != ToolVersionMatched {
+			problems = append(problems, check.Describe())
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return utils.NewError(utils.ErrorTypeSystem, "TOOL_VERSION_MISMATCH").
+		Message("外部工具版本与项目配置的期望版本不一致").
+		Details(strings.Join(problems, "；")).
+		Suggestion("安装 tools 中声明的版本（运行 'ora2pg-admin 检查 环境' 查看详情），确认无影响时可使用 --skip-version-check 跳过校验").
+		Build()
+}
+
+// SetSkipVersionCheck 设置是否跳过迁移前的工具版本校验
+func (ms *MigrationService) SetSkipVersionCheck(skip bool) {
+	ms.skipVersionCheck = skip
+}
+
+// verifyToolVersions 迁移开始前校验工具版本，启用 tools.enforce 时不一致即终止迁移，否则只记录警告
+func (ms *MigrationService) verifyToolVersions(ctx context.Context) error {
+	if ms.skipVersionCheck || ms.toolVersionsChecked || len(ms.config.Tools.Constraints()) == 0 {
+		return nil
+	}
+	ms.toolVersionsChecked = true
+
+	checks := CheckToolVersions(ctx, ms.config)
+	err := ToolVersionMismatchError(checks)
+	if err == nil {
+		for _, check := range checks {
+			ms.logger.Infof("工具版本校验通过: %s", check.Describe())
+		}
+		return nil
+	}
+	if ms.config.Tools.Enforce {
+		return err
+	}
+	for _, check := range checks {
+		if check.Status != ToolVersionMatched {
+			ms.logger.Warnf("工具版本与期望不一致: %s", check.Describe())
+		}
+	}
+	return nil
+}
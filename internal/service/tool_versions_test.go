@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// fakeVersionTools 在只包含模拟工具的PATH中创建输出指定版本的 ora2pg 和 sqlplus，不创建 psql
+func fakeVersionTools(t *testing.T, ora2pgOutput, sqlplusOutput string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("模拟工具依赖 /bin/sh")
+	}
+	bin := t.TempDir()
+	t.Setenv("PATH", bin)
+	for name, output := range map[string]string{"ora2pg": ora2pgOutput, "sqlplus": sqlplusOutput} {
+		script := "#!/bin/sh\nprintf '" + output + "'\n"
+		require.NoError(t, os.WriteFile(filepath.Join(bin, name), []byte(script), 0755))
+	}
+}
+
+func TestCheckToolVersions(t *testing.T) {
+	fakeVersionTools(t, "Ora2Pg v23.2\\n", "\\nSQL*Plus: Release 19.0.0.0.0 - Production\\nVersion 19.3.0.0.0\\n\\n")
+	cfg := &config.ProjectConfig{Tools: config.ToolVersionsConfig{Ora2pg: "24.1", SQLPlus: "19.3", PSQL: ">=16"}}
+
+	checks := CheckToolVersions(context.Background(), cfg)
+	require.Len(t, checks, 3)
+	assert.Equal(t, ToolVersionMismatched, checks[0].Status)
+	assert.Equal(t, "Ora2Pg v23.2", checks[0].Output)
+	assert.Equal(t, "ora2pg 期望 24.1，实际 23.2", checks[0].Describe())
+	// sqlplus 按 Version 行识别补丁版本
+	assert.Equal(t, ToolVersionMatched, checks[1].Status)
+	assert.Equal(t, "19.3.0.0.0", checks[1].Version)
+	assert.Equal(t, ToolVersionUnknown, checks[2].Status)
+	assert.Equal(t, "未找到psql工具", checks[2].Error)
+
+	err := ToolVersionMismatchError(checks)
+	require.Error(t, err)
+	assert.Equal(t, "TOOL_VERSION_MISMATCH", utils.GetErrorCode(err))
+	assert.Contains(t, err.Error(), "ora2pg 期望 24.1，实际 23.2")
+	assert.Contains(t, err.Error(), "psql 期望 >=16，无法确认实际版本")
+	assert.NotContains(t, err.Error(), "sqlplus")
+
+	assert.NoError(t, ToolVersionMismatchError(checks[1:2]))
+}
+
+func TestVerifyToolVersions(t *testing.T) {
+	fakeVersionTools(t, "Ora2Pg v23.2\\n", "")
+	cfg := &config.ProjectConfig{Tools: config.ToolVersionsConfig{Ora2pg: "24.x"}}
+
+	// 未启用强制校验时只警告
+	assert.NoError(t, NewMigrationService(cfg).verifyToolVersions(context.Background()))
+
+	cfg.Tools.Enforce = true
+	ms := NewMigrationService(cfg)
+	err := ms.verifyToolVersions(context.Background())
+	assert.Equal(t, "TOOL_VERSION_MISMATCH", utils.GetErrorCode(err))
+	// 同一服务只校验一次
+	assert.NoError(t, ms.verifyToolVersions(context.Background()))
+
+	ms = NewMigrationService(cfg)
+	ms.SetSkipVersionCheck(true)
+	assert.NoError(t, ms.verifyToolVersions(context.Background()))
+
+	cfg.Tools.Ora2pg = ">=23.2"
+	assert.NoError(t, NewMigrationService(cfg).verifyToolVersions(context.Background()))
+}
@@ -0,0 +1,21 @@
+package service
+
+// migrationTypeNotes 无法由 ora2pg 完整转换的迁移类型的转换说明和限制，执行前提示用户
+var migrationTypeNotes = map[MigrationType][]string{
+	MigrationTypeDBLink: {
+		"数据库链接转换为外部服务器（CREATE SERVER ... FOREIGN DATA WRAPPER oracle_fdw）和用户映射，目标库需先安装 oracle_fdw 扩展；远端已迁移到 PostgreSQL 时改用 postgres_fdw 或 dblink 扩展",
+		"Oracle 不导出数据库链接的密码，需在生成的 CREATE USER MAPPING 中补充远端账号的密码",
+		"SQL 和程序中的 表名@链接名 引用不会被转换，需用 IMPORT FOREIGN SCHEMA 为远端表创建外部表后改写引用",
+	},
+	MigrationTypeSynonym: {
+		"指向表和视图的同义词转换为同名视图（SELECT * FROM 目标对象），简单视图可以执行 DML，目标表增加字段后需重建视图",
+		"指向函数、存储过程、包和序列的同义词无法转换为视图，需把对象所在的 Schema 加入 search_path 或修改调用处的对象名",
+		"PUBLIC 同义词建议改为在数据库或角色上设置 search_path（ALTER ROLE ... SET search_path），而不是在每个 Schema 中创建视图",
+		"通过数据库链接指向远端对象的同义词需先迁移 DBLINK 并创建对应的外部表",
+	},
+}
+
+// MigrationTypeNotes 迁移类型的转换说明和限制，没有需要注意的事项时返回 nil
+func MigrationTypeNotes(migrationType MigrationType) []string {
+	return migrationTypeNotes[migrationType]
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrationTypeNotes(t *testing.T) {
+	assert.Contains(t, MigrationTypeNotes(MigrationTypeDBLink)[0], "oracle_fdw")
+	assert.Contains(t, MigrationTypeNotes(MigrationTypeSynonym)[1], "search_path")
+	assert.Nil(t, MigrationTypeNotes(MigrationTypeTable))
+
+	// 有转换说明的类型都是可执行的类型
+	for migrationType := range migrationTypeNotes {
+		assert.NoError(t, NewOra2pgService().ValidateMigrationType(migrationType), migrationType)
+	}
+}
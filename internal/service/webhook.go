@@ -0,0 +1,250 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"ora2pg-admin/internal/config"
+	"ora2pg-admin/internal/utils"
+)
+
+// 进度 webhook 推送的事件
+const (
+	WebhookEventProgress      = "progress"       // 进度达到推送间隔
+	WebhookEventTypeCompleted = "type_completed" // 一个迁移类型执行完成
+	WebhookEventFinished      = "finished"       // 整个迁移结束
+)
+
+// webhookCloseTimeout 迁移结束时等待剩余进度推送完成的最长时间
+const webhookCloseTimeout = 30 * time.Second
+
+// ProgressPayload webhook 推送的进度JSON
+type ProgressPayload struct {
+	Event          string    `json:"event"`
+	Project        string    `json:"project"`
+	Task           string    `json:"task"`
+	RunID          string    `json:"run_id"`
+	Step           int       `json:"step"`
+	TotalSteps     int       `json:"total_steps"`
+	Percentage     float64   `json:"percentage"`
+	Message        string    `json:"message,omitempty"`
+	Details        string    `json:"details,omitempty"`
+	Status         string    `json:"status,omitempty"` // 仅 finished 事件
+	ElapsedSeconds float64   `json:"elapsed_seconds"`
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// ProgressWebhook 迁移过程中把进度异步推送到 webhook
+//
+// 进度更新先按百分点间隔和最小时间间隔节流，再交给后台协程发送；排队中的进度事件只保留最新一条，
+// 因此推送不会阻塞迁移，也不会在请求变慢时堆积。迁移类型完成和迁移结束的事件不受节流限制，逐条发送。
+type ProgressWebhook struct {
+	cfg        *config.ProjectConfig
+	webhook    *config.WebhookNotificationConfig
+	task       string
+	totalSteps int
+	startTime  time.Time
+	logger     *utils.Logger
+	client     *http.Client
+	backoff    time.Duration
+	now        func() time.Time
+
+	mu          sync.Mutex
+	queue       []*ProgressPayload
+	lastPercent float64
+	lastSent    time.Time
+	closed      bool
+	signal      chan struct{}
+	done        chan struct{}
+}
+
+// StartProgressWebhook 启动进度推送，未启用 webhook 时返回nil（nil值的方法均可安全调用）
+func (n *Notifier) StartProgressWebhook(task string, totalSteps int) *ProgressWebhook {
+	webhook := &n.cfg.Notifications.Webhook
+	if !webhook.Enabled {
+		return nil
+	}
+	w := &ProgressWebhook{
+		cfg:        n.cfg,
+		webhook:    webhook,
+		task:       task,
+		totalSteps: totalSteps,
+		startTime:  time.Now(),
+		logger:     n.logger,
+		client:     &http.Client{Timeout: webhook.RequestTimeout()},
+		backoff:    time.Second,
+		now:        time.Now,
+		signal:     make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// HandleUpdate 接收进度跟踪器的更新，满足节流条件时排队推送；不会阻塞
+func (w *ProgressWebhook) HandleUpdate(update ProgressUpdate) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return
+	}
+
+	now := w.now()
+	event := WebhookEventTypeCompleted
+	if !update.Completed {
+		// 第一次更新总会推送，便于看板尽早显示迁移已开始
+		throttled := update.Percentage-w.lastPercent < w.webhook.Step() || now.Sub(w.lastSent) < w.webhook.Interval()
+		if !w.lastSent.IsZero() && throttled {
+			return
+		}
+		event = WebhookEventProgress
+	}
+	w.lastPercent = update.Percentage
+	w.lastSent = now
+	w.enqueue(&ProgressPayload{
+		Event:      event,
+		Step:       update.Step,
+		Percentage: update.Percentage,
+		Message:    update.Message,
+		Details:    update.Details,
+	})
+}
+
+// Finish 推送迁移结束事件，等待排队的推送完成后停止后台协程
+func (w *ProgressWebhook) Finish(status ExecutionStatus) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	if !w.closed {
+		percentage := w.lastPercent
+		if status == StatusCompleted {
+			percentage = 100
+		}
+		w.enqueue(&ProgressPayload{
+			Event:      WebhookEventFinished,
+			Step:       w.totalSteps,
+			Percentage: percentage,
+			Status:     strings.ToLower(string(status)),
+			Message:    fmt.Sprintf("%s%s", w.task, executionStatusText(status)),
+		})
+		w.closed = true
+		close(w.signal)
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+	case <-time.After(webhookCloseTimeout):
+		w.logger.Warnf("等待进度 webhook 推送超时，剩余进度未发送")
+	}
+}
+
+// enqueue 排队推送并唤醒发送协程，新的进度事件替换队尾尚未发送的进度事件（调用方需持有锁）
+func (w *ProgressWebhook) enqueue(payload *ProgressPayload) {
+	payload.Project = w.cfg.Project.Name
+	payload.Task = w.task
+	payload.RunID = utils.RunID()
+	payload.TotalSteps = w.totalSteps
+	payload.Timestamp = w.now()
+	payload.ElapsedSeconds = payload.Timestamp.Sub(w.startTime).Truncate(time.Second).Seconds()
+	if last := len(w.queue) - 1; last >= 0 && payload.Event == WebhookEventProgress && w.queue[last].Event == WebhookEventProgress {
+		w.queue[last] = payload
+	} else {
+		w.queue = append(w.queue, payload)
+	}
+	select {
+	case w.signal <- struct{}{}:
+	default:
+	}
+}
+
+// run 后台发送排队的进度，直到 Finish 后发送完剩余进度
+func (w *ProgressWebhook) run() {
+	defer close(w.done)
+	for range w.signal {
+		w.flush()
+	}
+	w.flush()
+}
+
+// flush 按顺序发送排队的进度
+func (w *ProgressWebhook) flush() {
+	for {
+		w.mu.Lock()
+		if len(w.queue) == 0 {
+			w.mu.Unlock()
+			return
+		}
+		payload := w.queue[0]
+		w.queue = w.queue[1:]
+		w.mu.Unlock()
+
+		if err := w.send(payload); err != nil {
+			w.logger.Warnf("推送迁移进度失败: %v", err)
+		}
+	}
+}
+
+// send 发送一次进度，失败时按配置重试
+func (w *ProgressWebhook) send(payload *ProgressPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("序列化进度失败: %v", err)
+	}
+
+	var lastErr error
+	retries := w.webhook.RetryCount()
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(w.backoff * time.Duration(attempt))
+		}
+		if lastErr = w.post(body); lastErr == nil {
+			w.logger.Debugf("已推送迁移进度: %s %.1f%%", payload.Event, payload.Percentage)
+			return nil
+		}
+	}
+	return utils.NewError(utils.ErrorTypeConnection, "WEBHOOK_SEND_FAILED").
+		Message("推送迁移进度失败").
+		Details(fmt.Sprintf("已重试 %d 次: %v", retries, lastErr)).
+		Cause(lastErr).
+		Suggestion(fmt.Sprintf("检查 notifications.webhook.url 是否可访问: %s", w.webhook.URL)).
+		Build()
+}
+
+// post 发送一次请求，非2xx响应视为失败
+func (w *ProgressWebhook) post(body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.webhook.RequestTimeout())
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSpace(w.webhook.URL), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "ora2pg-admin")
+	if w.webhook.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+w.webhook.Token)
+	}
+
+	response, err := w.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 4096))
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("webhook 返回 %s", response.Status)
+	}
+	return nil
+}
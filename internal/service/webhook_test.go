@@ -0,0 +1,177 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"ora2pg-admin/internal/config"
+)
+
+// webhookRecorder 记录收到的进度推送，前 failures 次请求返回500
+type webhookRecorder struct {
+	mu       sync.Mutex
+	failures int
+	requests int
+	payloads []ProgressPayload
+	headers  []http.Header
+}
+
+func (r *webhookRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests++
+	if r.requests <= r.failures {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	var payload ProgressPayload
+	if err := json.NewDecoder(req.Body).Decode(&payload); err == nil {
+		r.payloads = append(r.payloads, payload)
+		r.headers = append(r.headers, req.Header.Clone())
+	}
+}
+
+func (r *webhookRecorder) events() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := make([]string, 0, len(r.payloads))
+	for _, payload := range r.payloads {
+		events = append(events, payload.Event)
+	}
+	return events
+}
+
+// newTestWebhook 创建指向测试服务器的进度推送
+func newTestWebhook(t *testing.T, handler http.Handler, webhookConfig config.WebhookNotificationConfig) *ProgressWebhook {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("进度项目")
+	cfg := manager.GetConfig()
+	webhookConfig.Enabled = true
+	webhookConfig.URL = server.URL
+	cfg.Notifications.Webhook = webhookConfig
+
+	webhook := NewNotifier(cfg).StartProgressWebhook("数据迁移", 4)
+	require.NotNil(t, webhook)
+	webhook.backoff = time.Millisecond
+	return webhook
+}
+
+func TestProgressWebhookThrottle(t *testing.T) {
+	recorder := &webhookRecorder{}
+	webhook := newTestWebhook(t, recorder, config.WebhookNotificationConfig{Token: "secret", PercentStep: 20, MinInterval: "1m"})
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	webhook.mu.Lock()
+	webhook.now = func() time.Time { return now }
+	webhook.mu.Unlock()
+
+	// 第一次更新立即推送，之后百分点和时间间隔都满足时才推送
+	webhook.HandleUpdate(ProgressUpdate{Step: 1, Percentage: 1})
+	now = now.Add(2 * time.Minute)
+	webhook.HandleUpdate(ProgressUpdate{Step: 1, Percentage: 10})
+	webhook.HandleUpdate(ProgressUpdate{Step: 1, Percentage: 21})
+	webhook.HandleUpdate(ProgressUpdate{Step: 1, Percentage: 30})
+
+	// 迁移类型完成时不受节流限制
+	webhook.HandleUpdate(ProgressUpdate{Step: 1, Percentage: 25, Completed: true, Message: "TABLE 迁移成功"})
+	webhook.Finish(StatusCompleted)
+
+	// 排队中的进度事件只保留最新一条，完成和结束事件逐条发送
+	events := recorder.events()
+	require.GreaterOrEqual(t, len(events), 3)
+	assert.Equal(t, []string{WebhookEventTypeCompleted, WebhookEventFinished}, events[len(events)-2:])
+	var progress []float64
+	for _, payload := range recorder.payloads[:len(events)-2] {
+		assert.Equal(t, WebhookEventProgress, payload.Event)
+		progress = append(progress, payload.Percentage)
+	}
+	assert.Subset(t, []float64{1, 21}, progress)
+	assert.Contains(t, progress, 21.0)
+
+	last := recorder.payloads[len(recorder.payloads)-1]
+	assert.Equal(t, "进度项目", last.Project)
+	assert.Equal(t, "数据迁移", last.Task)
+	assert.Equal(t, 4, last.TotalSteps)
+	assert.Equal(t, 100.0, last.Percentage)
+	assert.Equal(t, "completed", last.Status)
+	assert.NotEmpty(t, last.RunID)
+	assert.Equal(t, "Bearer secret", recorder.headers[0].Get("Authorization"))
+	assert.Equal(t, "application/json", recorder.headers[0].Get("Content-Type"))
+
+	// 结束后不再推送
+	webhook.HandleUpdate(ProgressUpdate{Completed: true})
+	assert.Equal(t, events, recorder.events())
+}
+
+func TestProgressWebhookRetries(t *testing.T) {
+	retries := 2
+	recorder := &webhookRecorder{failures: 2}
+	webhook := newTestWebhook(t, recorder, config.WebhookNotificationConfig{Retries: &retries})
+	webhook.Finish(StatusFailed)
+	assert.Equal(t, 3, recorder.requests)
+	assert.Equal(t, []string{WebhookEventFinished}, recorder.events())
+	assert.Equal(t, "failed", recorder.payloads[0].Status)
+
+	// 重试次数用完后放弃，不影响调用方
+	recorder = &webhookRecorder{failures: 10}
+	webhook = newTestWebhook(t, recorder, config.WebhookNotificationConfig{Retries: &retries})
+	err := webhook.send(&ProgressPayload{Event: WebhookEventProgress})
+	assert.Error(t, err)
+	assert.Equal(t, 3, recorder.requests)
+	webhook.Finish(StatusCompleted)
+}
+
+func TestProgressWebhookDoesNotBlock(t *testing.T) {
+	// webhook 响应很慢时，进度更新仍立即返回
+	release := make(chan struct{})
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { <-release })
+	webhook := newTestWebhook(t, slow, config.WebhookNotificationConfig{Timeout: "5s"})
+
+	start := time.Now()
+	for step := 1; step <= 100; step++ {
+		webhook.HandleUpdate(ProgressUpdate{Step: step, Percentage: float64(step)})
+	}
+	webhook.HandleUpdate(ProgressUpdate{Step: 100, Percentage: 100, Completed: true})
+	assert.Less(t, time.Since(start), time.Second)
+
+	// 慢请求期间的进度事件合并为一条
+	webhook.mu.Lock()
+	assert.LessOrEqual(t, len(webhook.queue), 2)
+	webhook.mu.Unlock()
+	close(release)
+	webhook.Finish(StatusCompleted)
+}
+
+func TestStartProgressWebhookDisabled(t *testing.T) {
+	manager := config.NewManager()
+	manager.CreateDefaultConfig("未启用")
+	webhook := NewNotifier(manager.GetConfig()).StartProgressWebhook("数据迁移", 1)
+	assert.Nil(t, webhook)
+
+	// nil 值可以直接作为进度回调使用
+	webhook.HandleUpdate(ProgressUpdate{Completed: true})
+	webhook.Finish(StatusCompleted)
+}
+
+func TestProgressTrackerCompleteStep(t *testing.T) {
+	var updates []ProgressUpdate
+	tracker := NewProgressTracker()
+	tracker.SetUpdateHandler(func(update ProgressUpdate) { updates = append(updates, update) })
+	tracker.Start("测试", 4)
+	tracker.UpdateStep(1, "执行 TABLE 迁移")
+	tracker.CompleteStep(1, "TABLE 迁移成功")
+	tracker.Stop()
+
+	require.Len(t, updates, 2)
+	assert.False(t, updates[0].Completed)
+	assert.True(t, updates[1].Completed)
+	assert.Equal(t, 25.0, updates[1].Percentage)
+}
@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ArchiveDir 将目录流式打包为 .tar.gz，归档内保留以目录名为根的相对结构
+//
+// 先写入临时文件，完成后再重命名，避免中断时留下不完整的归档。返回归档的文件数。
+func (fu *FileUtils) ArchiveDir(srcDir, archivePath string) (int, error) {
+	srcDir = filepath.Clean(srcDir)
+	if !fu.DirExists(srcDir) {
+		return 0, fmt.Errorf("归档目录不存在: %s", srcDir)
+	}
+	if err := fu.EnsureDir(filepath.Dir(archivePath)); err != nil {
+		return 0, err
+	}
+
+	tmpPath := archivePath + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, fmt.Errorf("创建归档文件失败 %s: %v", archivePath, err)
+	}
+
+	count, err := writeTarGz(file, srcDir, tmpPath)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("写入归档文件失败: %v", closeErr)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+
+	if err := os.Rename(tmpPath, archivePath); err != nil {
+		os.Remove(tmpPath)
+		return 0, fmt.Errorf("保存归档文件失败 %s: %v", archivePath, err)
+	}
+
+	logrus.Debugf("成功归档目录: %s -> %s (%d 个文件)", srcDir, archivePath, count)
+	return count, nil
+}
+
+// writeTarGz 把目录内容写入gzip压缩的tar流，skipPath 为正在写入的归档自身
+func writeTarGz(w io.Writer, srcDir, skipPath string) (int, error) {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	skipAbs, _ := filepath.Abs(skipPath)
+	root := filepath.Base(srcDir)
+	count := 0
+
+	err := filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// 归档文件位于源目录内时跳过自身
+		if abs, _ := filepath.Abs(path); abs == skipAbs {
+			return nil
+		}
+
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+
+		link := ""
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return fmt.Errorf("生成归档条目失败 %s: %v", path, err)
+		}
+		header.Name = filepath.ToSlash(filepath.Join(root, rel))
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("写入归档条目失败 %s: %v", path, err)
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("打开文件失败 %s: %v", path, err)
+		}
+		defer src.Close()
+
+		// 逐块复制，避免大文件整体读入内存
+		if _, err := io.Copy(tarWriter, src); err != nil {
+			return fmt.Errorf("压缩文件失败 %s: %v", path, err)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return 0, fmt.Errorf("写入归档失败: %v", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return 0, fmt.Errorf("写入归档失败: %v", err)
+	}
+	return count, nil
+}
+
+// ExtractArchive 将 .tar.gz 归档解压到目标目录
+func (fu *FileUtils) ExtractArchive(archivePath, destDir string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("打开归档文件失败 %s: %v", archivePath, err)
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("读取归档文件失败 %s: %v", archivePath, err)
+	}
+	defer gzipReader.Close()
+
+	destDir = filepath.Clean(destDir)
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("读取归档条目失败: %v", err)
+		}
+
+		// 防止条目路径逃逸到目标目录之外
+		target := filepath.Join(destDir, filepath.FromSlash(header.Name))
+		if target != destDir && !strings.HasPrefix(target, destDir+string(os.PathSeparator)) {
+			return fmt.Errorf("归档条目路径非法: %s", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.FileMode(header.Mode)|0700); err != nil {
+				return fmt.Errorf("创建目录失败 %s: %v", target, err)
+			}
+		case tar.TypeReg:
+			if err := fu.EnsureDir(filepath.Dir(target)); err != nil {
+				return err
+			}
+			if err := extractFile(tarReader, target, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := fu.EnsureDir(filepath.Dir(target)); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return fmt.Errorf("创建符号链接失败 %s: %v", target, err)
+			}
+		}
+	}
+}
+
+// extractFile 写出归档中的单个文件
+func extractFile(r io.Reader, target string, mode os.FileMode) error {
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("创建文件失败 %s: %v", target, err)
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, r); err != nil {
+		return fmt.Errorf("解压文件失败 %s: %v", target, err)
+	}
+	return nil
+}
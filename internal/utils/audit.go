@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// AuditLogFile 审计日志在项目 .ora2pg-admin 目录中的文件名，每行一条JSON记录
+const AuditLogFile = "audit.log"
+
+// 审计结果
+const (
+	AuditResultSuccess = "success"
+	AuditResultFailure = "failure"
+)
+
+// auditSensitiveNames 参数名包含这些词时值会被脱敏
+var auditSensitiveNames = []string{"password", "passwd", "pwd", "secret", "token", "key"}
+
+// AuditRecord 一次命令执行的审计记录
+type AuditRecord struct {
+	Time     time.Time     `json:"time"`
+	RunID    string        `json:"run_id"`
+	User     string        `json:"user"`
+	Host     string        `json:"host,omitempty"`
+	Project  string        `json:"project"`
+	Command  string        `json:"command"`
+	Args     []string      `json:"args"`
+	Result   string        `json:"result"`
+	ExitCode int           `json:"exit_code"`
+	Duration time.Duration `json:"duration"`
+}
+
+// AuditLogPath 获取项目的审计日志路径
+func AuditLogPath(projectDir string) string {
+	return filepath.Join(projectDir, ".ora2pg-admin", AuditLogFile)
+}
+
+// AppendAuditRecord 追加一条审计记录，直接写文件，不受日志级别和静默模式影响
+func AppendAuditRecord(path string, record *AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return FileErrors.WriteFailed(path, err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return FileErrors.WriteFailed(path, err)
+	}
+	return nil
+}
+
+// SanitizeAuditArgs 脱敏命令行参数，支持 --password=xx、--password xx 和 password=xx 形式
+func SanitizeAuditArgs(args []string) []string {
+	sanitized := make([]string, len(args))
+	maskNext := false
+	for i, arg := range args {
+		if maskNext {
+			sanitized[i] = "******"
+			maskNext = false
+			continue
+		}
+
+		name, _, hasValue := strings.Cut(arg, "=")
+		switch {
+		case hasValue && isSensitiveAuditName(name):
+			sanitized[i] = name + "=******"
+		case !hasValue && strings.HasPrefix(arg, "-") && isSensitiveAuditName(arg):
+			sanitized[i] = arg
+			maskNext = true
+		default:
+			sanitized[i] = arg
+		}
+	}
+	return sanitized
+}
+
+// isSensitiveAuditName 参数名是否为敏感参数
+func isSensitiveAuditName(name string) bool {
+	name = strings.ToLower(strings.TrimLeft(name, "-"))
+	for _, sensitive := range auditSensitiveNames {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// CurrentUsername 获取当前操作系统用户名
+func CurrentUsername() string {
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return current.Username
+	}
+	for _, env := range []string{"USER", "USERNAME"} {
+		if name := os.Getenv(env); name != "" {
+			return name
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeAuditArgs(t *testing.T) {
+	args := []string{
+		"配置", "数据库", "--master-password", "s3cret",
+		"--api-key=abc", "password=tiger", "--verbose", "-c", "app.yaml",
+	}
+
+	assert.Equal(t, []string{
+		"配置", "数据库", "--master-password", "******",
+		"--api-key=******", "password=******", "--verbose", "-c", "app.yaml",
+	}, SanitizeAuditArgs(args))
+}
+
+func TestAppendAuditRecord(t *testing.T) {
+	projectDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(projectDir, ".ora2pg-admin"), 0755))
+	path := AuditLogPath(projectDir)
+
+	for _, result := range []string{AuditResultSuccess, AuditResultFailure} {
+		require.NoError(t, AppendAuditRecord(path, &AuditRecord{
+			Time:     time.Now(),
+			RunID:    RunID(),
+			User:     CurrentUsername(),
+			Project:  projectDir,
+			Command:  "ora2pg-admin 迁移 全部",
+			Args:     []string{"迁移", "全部"},
+			Result:   result,
+			Duration: time.Second,
+		}))
+	}
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var records []AuditRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record AuditRecord
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	require.Len(t, records, 2)
+	assert.Equal(t, AuditResultSuccess, records[0].Result)
+	assert.Equal(t, AuditResultFailure, records[1].Result)
+	assert.Equal(t, RunID(), records[0].RunID)
+	assert.NotEmpty(t, records[0].User)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
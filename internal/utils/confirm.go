@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"io"
+	"os"
+
+	"github.com/manifoldco/promptui"
+)
+
+// assumeYes 为 true 时确认提示自动视为"是"（--yes/--assume-yes）
+var assumeYes bool
+
+// promptInput 提示的输入，nil 表示标准输入
+var promptInput io.ReadCloser
+
+// SetAssumeYes 设置是否自动确认
+func SetAssumeYes(enabled bool) {
+	assumeYes = enabled
+}
+
+// AssumeYes 是否自动确认
+func AssumeYes() bool {
+	return assumeYes
+}
+
+// Confirm 确认提示，回答"是"时返回 true；自动确认时不读取输入直接返回 true
+func Confirm(label string) bool {
+	if assumeYes {
+		GetGlobalLogger().Debugf("已自动确认: %s", label)
+		return true
+	}
+	return AskYesNo(label)
+}
+
+// ConfirmDangerous 确认删除、覆盖等危险操作，无论是否自动确认都记录警告日志
+func ConfirmDangerous(label, action string) bool {
+	if assumeYes {
+		GetGlobalLogger().Warnf("已通过 --yes 自动确认危险操作: %s", action)
+		return true
+	}
+	if !AskYesNo(label) {
+		return false
+	}
+	GetGlobalLogger().Warnf("用户已确认危险操作: %s", action)
+	return true
+}
+
+// IsInteractive 是否可以向用户提问：未自动确认且标准输入是终端
+func IsInteractive() bool {
+	if assumeYes {
+		return false
+	}
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// AskYesNo 是/否提问，不受自动确认影响，用于配置向导中是否进入可选步骤
+func AskYesNo(label string) bool {
+	prompt := promptui.Prompt{
+		Label:     label,
+		IsConfirm: true,
+		Stdin:     promptInput,
+	}
+	_, err := prompt.Run()
+	return err == nil
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirmAssumeYesDoesNotBlock(t *testing.T) {
+	// 输入端永远不写入，读取输入会一直阻塞
+	reader, writer := io.Pipe()
+	defer writer.Close()
+	promptInput = reader
+	defer func() { promptInput = nil }()
+
+	SetAssumeYes(true)
+	defer SetAssumeYes(false)
+
+	done := make(chan bool, 2)
+	go func() {
+		done <- Confirm("确认保存配置")
+		done <- ConfirmDangerous("项目目录已存在，是否覆盖", "删除项目目录 demo")
+	}()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case confirmed := <-done:
+			assert.True(t, confirmed)
+		case <-time.After(2 * time.Second):
+			t.Fatal("自动确认时不应等待输入")
+		}
+	}
+}
+
+func TestConfirmWithoutInput(t *testing.T) {
+	// 无自动确认且输入已结束时视为"否"
+	reader, writer := io.Pipe()
+	writer.Close()
+	promptInput = reader
+	defer func() { promptInput = nil }()
+
+	assert.False(t, AssumeYes())
+	assert.False(t, Confirm("确认保存配置"))
+	assert.False(t, ConfirmDangerous("项目目录已存在，是否覆盖", "删除项目目录 demo"))
+}
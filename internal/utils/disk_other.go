@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package utils
+
+import "errors"
+
+// DiskFreeSpace 当前平台不支持查询磁盘可用空间
+func DiskFreeSpace(path string) (uint64, error) {
+	return 0, errors.New("当前平台不支持查询磁盘可用空间")
+}
@@ -294,3 +294,40 @@ func TestSetFilePermission(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
 }
+
+func TestArchiveAndExtract(t *testing.T) {
+	fu := NewFileUtils()
+	tempDir := t.TempDir()
+
+	// 准备带子目录和较大文件的输出目录
+	outputDir := filepath.Join(tempDir, "output")
+	files := map[string]string{
+		"TABLE_output.sql":      "CREATE TABLE t (id int);\n",
+		"data/COPY_users.sql":   string(make([]byte, 2<<20)),
+		"data/nested/extra.sql": "SELECT 1;\n",
+	}
+	for name, content := range files {
+		require.NoError(t, fu.WriteFile(filepath.Join(outputDir, filepath.FromSlash(name)), []byte(content), 0644))
+	}
+	require.NoError(t, fu.EnsureDir(filepath.Join(outputDir, "empty")))
+
+	archivePath := filepath.Join(tempDir, "backup", "output-test.tar.gz")
+	count, err := fu.ArchiveDir(outputDir, archivePath)
+	require.NoError(t, err)
+	assert.Equal(t, len(files), count)
+	assert.True(t, fu.FileExists(archivePath))
+	assert.False(t, fu.FileExists(archivePath+".tmp"))
+
+	// 解压后应完整还原目录结构和内容
+	restoreDir := filepath.Join(tempDir, "restore")
+	require.NoError(t, fu.ExtractArchive(archivePath, restoreDir))
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(restoreDir, "output", filepath.FromSlash(name)))
+		require.NoError(t, err)
+		assert.Equal(t, content, string(data), name)
+	}
+	assert.True(t, fu.DirExists(filepath.Join(restoreDir, "output", "empty")))
+
+	_, err = fu.ArchiveDir(filepath.Join(tempDir, "missing"), archivePath)
+	assert.Error(t, err)
+}
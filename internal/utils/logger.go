@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -25,18 +26,28 @@ const (
 type LogConfig struct {
 	Level      LogLevel `json:"level"`
 	Format     string   `json:"format"`     // text, json
-	Output     string   `json:"output"`     // stdout, stderr, file
+	Output     string   `json:"output"`     // stdout, stderr, file, syslog
 	FilePath   string   `json:"file_path"`  // 日志文件路径
 	MaxSize    int64    `json:"max_size"`   // 最大文件大小（字节）
 	MaxAge     int      `json:"max_age"`    // 最大保存天数
 	Compress   bool     `json:"compress"`   // 是否压缩旧日志
 	TimeFormat string   `json:"time_format"` // 时间格式
+	// DisableSanitize 关闭对密码等敏感信息的脱敏，零值为脱敏
+	DisableSanitize bool `json:"disable_sanitize"`
+	// SanitizePatterns 额外的脱敏正则，有分组时只替换分组内容，否则替换整个匹配
+	SanitizePatterns []string `json:"sanitize_patterns"`
+	// SyslogFacility 输出到syslog时的facility（如 user、daemon、local0），默认 user，Windows 上忽略
+	SyslogFacility string `json:"syslog_facility"`
+	// SyslogTag 输出到syslog时的标识，Windows 上为事件源名称，默认 ora2pg-admin
+	SyslogTag string `json:"syslog_tag"`
 }
 
 // Logger 日志管理器
 type Logger struct {
-	config *LogConfig
-	logger *logrus.Logger
+	config        *LogConfig
+	logger        *logrus.Logger
+	extraPatterns []*regexp.Regexp
+	systemLog     *systemLogHook
 }
 
 // NewLogger 创建新的日志管理器
@@ -48,9 +59,12 @@ func NewLogger(config *LogConfig) *Logger {
 	logger := logrus.New()
 	
 	l := &Logger{
-		config: config,
-		logger: logger,
+		config:    config,
+		logger:    logger,
+		systemLog: &systemLogHook{config: config},
 	}
+	logger.AddHook(&runIDHook{config: config})
+	logger.AddHook(l.systemLog)
 
 	l.configure()
 	return l
@@ -79,6 +93,30 @@ func (l *Logger) configure() {
 
 	// 设置输出目标
 	l.setOutput()
+
+	// 设置脱敏规则
+	l.setSanitize()
+}
+
+// setSanitize 编译额外的脱敏规则，关闭脱敏时输出警告
+func (l *Logger) setSanitize() {
+	l.extraPatterns = nil
+	for _, pattern := range l.config.SanitizePatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			l.logger.Warnf("忽略无效的脱敏规则 %q: %v", pattern, err)
+			continue
+		}
+		l.extraPatterns = append(l.extraPatterns, re)
+	}
+
+	if l.config.DisableSanitize {
+		warning := "⚠️ 日志脱敏已关闭，密码、连接串等敏感信息将以明文写入日志，仅限在安全环境中调试使用"
+		l.logger.Warn(warning)
+		if l.logger.Out != os.Stdout && l.logger.Out != os.Stderr {
+			fmt.Fprintln(os.Stderr, warning)
+		}
+	}
 }
 
 // setLevel 设置日志级别
@@ -117,7 +155,11 @@ func (l *Logger) setFormatter() {
 
 // setOutput 设置输出目标
 func (l *Logger) setOutput() {
+	l.systemLog.close()
+
 	switch strings.ToLower(l.config.Output) {
+	case LogOutputSyslog:
+		l.setSystemLogOutput()
 	case "stderr":
 		l.logger.SetOutput(os.Stderr)
 	case "file":
@@ -153,6 +195,16 @@ func (l *Logger) setFileOutput() {
 	l.logger.SetOutput(file)
 }
 
+// setSystemLogOutput 设置系统日志输出，连接失败时降级为stderr
+func (l *Logger) setSystemLogOutput() {
+	if err := l.systemLog.open(); err != nil {
+		l.logger.SetOutput(os.Stderr)
+		l.logger.Warnf("系统日志不可用，日志改为输出到stderr: %v", err)
+		return
+	}
+	l.logger.SetOutput(io.Discard)
+}
+
 // isColorSupported 检查是否支持颜色输出
 func (l *Logger) isColorSupported() bool {
 	// 在Windows命令行中通常不支持颜色，除非使用特殊终端
@@ -214,8 +266,16 @@ func (l *Logger) WithFields(fields logrus.Fields) *logrus.Entry {
 	return l.logger.WithFields(sanitizedFields)
 }
 
-// sanitizeMessage 脱敏日志消息
+// sanitizeMessage 脱敏日志消息，关闭脱敏时原样返回
 func (l *Logger) sanitizeMessage(message string) string {
+	if l.config.DisableSanitize {
+		return message
+	}
+	return l.Sanitize(message)
+}
+
+// Sanitize 按内置和额外的脱敏规则处理文本，不受 DisableSanitize 开关影响，用于发送到外部的内容
+func (l *Logger) Sanitize(message string) string {
 	// 脱敏密码相关信息
 	sensitivePatterns := []string{
 		"password=",
@@ -242,9 +302,35 @@ func (l *Logger) sanitizeMessage(message string) string {
 		}
 	}
 
+	for _, re := range l.extraPatterns {
+		result = maskPattern(result, re)
+	}
+
 	return result
 }
 
+// maskPattern 用星号替换正则匹配的内容，有分组时只替换分组
+func maskPattern(message string, re *regexp.Regexp) string {
+	matches := re.FindAllStringSubmatchIndex(message, -1)
+	if len(matches) == 0 {
+		return message
+	}
+
+	masked := []byte(message)
+	for _, match := range matches {
+		ranges := match[2:]
+		if len(ranges) == 0 {
+			ranges = match[:2]
+		}
+		for i := 0; i+1 < len(ranges); i += 2 {
+			for j := ranges[i]; j >= 0 && j < ranges[i+1]; j++ {
+				masked[j] = '*'
+			}
+		}
+	}
+	return string(masked)
+}
+
 // sanitizeArgs 脱敏参数
 func (l *Logger) sanitizeArgs(args ...interface{}) []interface{} {
 	sanitized := make([]interface{}, len(args))
@@ -277,6 +363,11 @@ func (l *Logger) SetOutput(output string, filePath ...string) {
 	l.setOutput()
 }
 
+// WritesToStdout 日志当前是否写入标准输出
+func (l *Logger) WritesToStdout() bool {
+	return l.logger.Out == os.Stdout
+}
+
 // GetLogger 获取底层logrus实例
 func (l *Logger) GetLogger() *logrus.Logger {
 	return l.logger
@@ -284,6 +375,9 @@ func (l *Logger) GetLogger() *logrus.Logger {
 
 // Close 关闭日志器
 func (l *Logger) Close() error {
+	if err := l.systemLog.close(); err != nil {
+		return err
+	}
 	if closer, ok := l.logger.Out.(io.Closer); ok {
 		return closer.Close()
 	}
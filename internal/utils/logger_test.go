@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFileLogger 创建输出到临时文件的日志器，返回读取日志内容的函数
+func newFileLogger(t *testing.T, configure func(config *LogConfig)) (*Logger, func() string) {
+	logFile := filepath.Join(t.TempDir(), "test.log")
+	config := GetDefaultLogConfig()
+	config.Output = "file"
+	config.FilePath = logFile
+	configure(config)
+
+	logger := NewLogger(config)
+	t.Cleanup(func() { logger.Close() })
+	return logger, func() string {
+		content, err := os.ReadFile(logFile)
+		require.NoError(t, err)
+		return string(content)
+	}
+}
+
+func TestLoggerSanitizeEnabledByDefault(t *testing.T) {
+	assert.False(t, GetDefaultLogConfig().DisableSanitize)
+
+	logger, read := newFileLogger(t, func(config *LogConfig) {
+		config.SanitizePatterns = []string{`ORA_PASS:(\S+)`, `tok-[0-9]+`, `(`}
+	})
+	logger.Infof("连接参数: %s", "user=scott password=tiger")
+	logger.Info("ORA_PASS:secret123 已设置")
+	logger.Info("令牌 tok-98765")
+
+	content := read()
+	assert.NotContains(t, content, "tiger")
+	assert.Contains(t, content, "password=*****")
+	assert.NotContains(t, content, "secret123")
+	assert.Contains(t, content, "ORA_PASS:*********")
+	assert.NotContains(t, content, "tok-98765")
+	assert.Contains(t, content, "忽略无效的脱敏规则")
+	assert.NotContains(t, content, "日志脱敏已关闭")
+}
+
+func TestLoggerSanitizeZeroValueConfig(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "test.log")
+	logger := NewLogger(&LogConfig{Output: "file", FilePath: logFile})
+	logger.Infof("连接参数: %s", "user=scott password=tiger")
+	logger.Close()
+
+	content, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "tiger")
+	assert.NotContains(t, string(content), "日志脱敏已关闭")
+}
+
+func TestLoggerSanitizeDisabled(t *testing.T) {
+	logger, read := newFileLogger(t, func(config *LogConfig) {
+		config.DisableSanitize = true
+		config.SanitizePatterns = []string{`tok-[0-9]+`}
+	})
+	logger.Infof("连接参数: %s", "user=scott password=tiger")
+	logger.Info("令牌 tok-98765")
+
+	content := read()
+	assert.Contains(t, content, "日志脱敏已关闭")
+	assert.Contains(t, content, "password=tiger")
+	assert.Contains(t, content, "tok-98765")
+}
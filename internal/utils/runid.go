@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RunIDEnv 指定运行ID的环境变量，后台运行的迁移进程沿用启动它的命令打印的运行ID
+const RunIDEnv = "ORA2PG_ADMIN_RUN_ID"
+
+var (
+	runID     string
+	runIDOnce sync.Once
+)
+
+// RunID 本次运行的ID，进程内不变，用于关联日志、迁移历史和数据库连接
+func RunID() string {
+	runIDOnce.Do(func() {
+		if inherited := os.Getenv(RunIDEnv); inherited != "" {
+			runID = inherited
+			return
+		}
+		suffix := make([]byte, 3)
+		if _, err := rand.Read(suffix); err != nil {
+			suffix = []byte{0, 0, 0}
+		}
+		runID = time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+	})
+	return runID
+}
+
+// runIDHook 为写入文件或JSON格式的日志添加 run_id 字段，控制台文本日志保持简洁
+type runIDHook struct {
+	config *LogConfig
+}
+
+// Levels 作用于全部日志级别
+func (h *runIDHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire 添加 run_id 字段
+func (h *runIDHook) Fire(entry *logrus.Entry) error {
+	if h.config.Output == "file" || h.config.Output == LogOutputSyslog || h.config.Format == "json" {
+		entry.Data["run_id"] = RunID()
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// 系统日志的默认设置
+const (
+	DefaultSyslogFacility = "user"
+	DefaultSyslogTag      = "ora2pg-admin"
+)
+
+// LogOutputSyslog 输出到系统日志：Linux/macOS 为 syslog，Windows 为事件日志
+const LogOutputSyslog = "syslog"
+
+// systemLogWriter 平台相关的系统日志写入器
+type systemLogWriter interface {
+	Write(level logrus.Level, message string) error
+	Close() error
+}
+
+// systemLogHook 把日志条目写入系统日志，未打开系统日志时不做任何事
+//
+// syslog 和事件日志自带时间、主机和级别，消息中只保留正文和字段。
+type systemLogHook struct {
+	mu     sync.Mutex
+	config *LogConfig
+	writer systemLogWriter
+}
+
+// Levels 作用于全部日志级别
+func (h *systemLogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire 写入系统日志，写入失败时改为输出到stderr，避免丢失日志
+func (h *systemLogHook) Fire(entry *logrus.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.writer == nil {
+		return nil
+	}
+
+	message, err := formatSystemLogMessage(entry, h.config.Format)
+	if err != nil {
+		return err
+	}
+	if err := h.writer.Write(entry.Level, message); err != nil {
+		fmt.Fprintf(os.Stderr, "%s [%s] %s\n", entry.Time.Format(h.config.TimeFormat), strings.ToUpper(entry.Level.String()), message)
+	}
+	return nil
+}
+
+// open 打开系统日志，已打开时先关闭
+func (h *systemLogHook) open() error {
+	h.close()
+	writer, err := openSystemLog(systemLogTag(h.config), systemLogFacility(h.config))
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	h.writer = writer
+	h.mu.Unlock()
+	return nil
+}
+
+// close 关闭系统日志
+func (h *systemLogHook) close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.writer == nil {
+		return nil
+	}
+	err := h.writer.Close()
+	h.writer = nil
+	return err
+}
+
+// formatSystemLogMessage 格式化系统日志消息，json 格式时输出不含时间和级别的JSON
+func formatSystemLogMessage(entry *logrus.Entry, format string) (string, error) {
+	if strings.EqualFold(format, "json") {
+		formatter := &logrus.JSONFormatter{DisableTimestamp: true}
+		data, err := formatter.Format(entry)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(string(data), "\n"), nil
+	}
+
+	keys := make([]string, 0, len(entry.Data))
+	for key := range entry.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var message strings.Builder
+	message.WriteString(entry.Message)
+	for _, key := range keys {
+		fmt.Fprintf(&message, " %s=%v", key, entry.Data[key])
+	}
+	return message.String(), nil
+}
+
+// systemLogTag 系统日志标识（Windows 上为事件源名称）
+func systemLogTag(config *LogConfig) string {
+	if tag := strings.TrimSpace(config.SyslogTag); tag != "" {
+		return tag
+	}
+	return DefaultSyslogTag
+}
+
+// systemLogFacility syslog facility 名称
+func systemLogFacility(config *LogConfig) string {
+	if facility := strings.TrimSpace(config.SyslogFacility); facility != "" {
+		return strings.ToLower(facility)
+	}
+	return DefaultSyslogFacility
+}
@@ -0,0 +1,10 @@
+//go:build plan9
+
+package utils
+
+import "errors"
+
+// openSystemLog 当前平台不支持系统日志
+func openSystemLog(tag, facility string) (systemLogWriter, error) {
+	return nil, errors.New("当前平台不支持系统日志")
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSystemLog 记录写入内容的系统日志
+type fakeSystemLog struct {
+	levels   []logrus.Level
+	messages []string
+	closed   bool
+}
+
+func (f *fakeSystemLog) Write(level logrus.Level, message string) error {
+	f.levels = append(f.levels, level)
+	f.messages = append(f.messages, message)
+	return nil
+}
+
+func (f *fakeSystemLog) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestSystemLogHookFormat(t *testing.T) {
+	config := GetDefaultLogConfig()
+	fake := &fakeSystemLog{}
+	hook := &systemLogHook{config: config, writer: fake}
+
+	logger := logrus.New()
+	entry := logrus.NewEntry(logger).WithField("run_id", "r1").WithField("type", "COPY")
+	entry.Level = logrus.WarnLevel
+	entry.Message = "迁移类型接近超时"
+	require.NoError(t, hook.Fire(entry))
+
+	// syslog 自带时间和级别，消息中只保留正文和按名称排序的字段
+	assert.Equal(t, []logrus.Level{logrus.WarnLevel}, fake.levels)
+	assert.Equal(t, "迁移类型接近超时 run_id=r1 type=COPY", fake.messages[0])
+
+	config.Format = "json"
+	require.NoError(t, hook.Fire(entry))
+	assert.JSONEq(t, `{"level":"warning","msg":"迁移类型接近超时","run_id":"r1","type":"COPY"}`, fake.messages[1])
+
+	require.NoError(t, hook.close())
+	assert.True(t, fake.closed)
+	require.NoError(t, hook.Fire(entry))
+	assert.Len(t, fake.messages, 2)
+}
+
+func TestLoggerSyslogFallback(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows 事件日志不使用 facility")
+	}
+
+	config := GetDefaultLogConfig()
+	config.Output = LogOutputSyslog
+	config.SyslogFacility = "invalid"
+	logger := NewLogger(config)
+	defer logger.Close()
+
+	// facility 无效时降级为stderr
+	assert.Equal(t, os.Stderr, logger.GetLogger().Out)
+	assert.False(t, logger.WritesToStdout())
+}
+
+func TestSystemLogDefaults(t *testing.T) {
+	config := GetDefaultLogConfig()
+	assert.Equal(t, DefaultSyslogTag, systemLogTag(config))
+	assert.Equal(t, DefaultSyslogFacility, systemLogFacility(config))
+
+	config.SyslogTag = "migration-prod"
+	config.SyslogFacility = "LOCAL3"
+	assert.Equal(t, "migration-prod", systemLogTag(config))
+	assert.Equal(t, "local3", systemLogFacility(config))
+}
@@ -0,0 +1,73 @@
+//go:build !windows && !plan9
+
+package utils
+
+import (
+	"fmt"
+	"log/syslog"
+
+	"github.com/sirupsen/logrus"
+)
+
+// syslogFacilities 支持的 syslog facility
+var syslogFacilities = map[string]syslog.Priority{
+	"kern":     syslog.LOG_KERN,
+	"user":     syslog.LOG_USER,
+	"mail":     syslog.LOG_MAIL,
+	"daemon":   syslog.LOG_DAEMON,
+	"auth":     syslog.LOG_AUTH,
+	"syslog":   syslog.LOG_SYSLOG,
+	"lpr":      syslog.LOG_LPR,
+	"news":     syslog.LOG_NEWS,
+	"uucp":     syslog.LOG_UUCP,
+	"cron":     syslog.LOG_CRON,
+	"authpriv": syslog.LOG_AUTHPRIV,
+	"ftp":      syslog.LOG_FTP,
+	"local0":   syslog.LOG_LOCAL0,
+	"local1":   syslog.LOG_LOCAL1,
+	"local2":   syslog.LOG_LOCAL2,
+	"local3":   syslog.LOG_LOCAL3,
+	"local4":   syslog.LOG_LOCAL4,
+	"local5":   syslog.LOG_LOCAL5,
+	"local6":   syslog.LOG_LOCAL6,
+	"local7":   syslog.LOG_LOCAL7,
+}
+
+// syslogWriter 通过本机 syslog 守护进程写入日志
+type syslogWriter struct {
+	writer *syslog.Writer
+}
+
+// openSystemLog 连接本机 syslog，facility 无效或守护进程不可用时返回错误
+func openSystemLog(tag, facility string) (systemLogWriter, error) {
+	priority, ok := syslogFacilities[facility]
+	if !ok {
+		return nil, fmt.Errorf("不支持的syslog facility: %s", facility)
+	}
+	writer, err := syslog.New(priority|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, fmt.Errorf("连接syslog失败: %v", err)
+	}
+	return &syslogWriter{writer: writer}, nil
+}
+
+// Write 按日志级别映射 syslog severity 写入
+func (w *syslogWriter) Write(level logrus.Level, message string) error {
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel:
+		return w.writer.Crit(message)
+	case logrus.ErrorLevel:
+		return w.writer.Err(message)
+	case logrus.WarnLevel:
+		return w.writer.Warning(message)
+	case logrus.InfoLevel:
+		return w.writer.Info(message)
+	default:
+		return w.writer.Debug(message)
+	}
+}
+
+// Close 关闭 syslog 连接
+func (w *syslogWriter) Close() error {
+	return w.writer.Close()
+}
@@ -0,0 +1,28 @@
+//go:build !windows && !plan9
+
+package utils
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggerSyslogOutput(t *testing.T) {
+	writer, err := openSystemLog(DefaultSyslogTag, DefaultSyslogFacility)
+	if err != nil {
+		t.Skipf("本机syslog不可用: %v", err)
+	}
+	require.NoError(t, writer.Close())
+
+	config := GetDefaultLogConfig()
+	config.Output = LogOutputSyslog
+	config.SyslogFacility = "local0"
+	logger := NewLogger(config)
+
+	assert.Equal(t, io.Discard, logger.GetLogger().Out)
+	logger.Info("ora2pg-admin syslog 输出测试")
+	require.NoError(t, logger.Close())
+}
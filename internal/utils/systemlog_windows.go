@@ -0,0 +1,49 @@
+//go:build windows
+
+package utils
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/windows/svc/eventlog"
+)
+
+// eventLogID 写入事件日志的事件ID
+const eventLogID = 1
+
+// eventLogWriter 写入Windows应用程序事件日志
+type eventLogWriter struct {
+	log *eventlog.Log
+}
+
+// openSystemLog 打开以 tag 为事件源的事件日志，事件源未注册时尝试注册（需要管理员权限）
+//
+// Windows 事件日志没有 facility 的概念，facility 被忽略。
+func openSystemLog(tag, facility string) (systemLogWriter, error) {
+	// 事件源未注册时写入的事件显示为"找不到描述"；已注册或无权限时注册失败，不影响写入
+	_ = eventlog.InstallAsEventCreate(tag, eventlog.Error|eventlog.Warning|eventlog.Info)
+
+	log, err := eventlog.Open(tag)
+	if err != nil {
+		return nil, fmt.Errorf("打开Windows事件日志失败: %v", err)
+	}
+	return &eventLogWriter{log: log}, nil
+}
+
+// Write 按日志级别映射事件类型写入，调试和信息级别均为信息事件
+func (w *eventLogWriter) Write(level logrus.Level, message string) error {
+	switch level {
+	case logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel:
+		return w.log.Error(eventLogID, message)
+	case logrus.WarnLevel:
+		return w.log.Warning(eventLogID, message)
+	default:
+		return w.log.Info(eventLogID, message)
+	}
+}
+
+// Close 关闭事件日志
+func (w *eventLogWriter) Close() error {
+	return w.log.Close()
+}
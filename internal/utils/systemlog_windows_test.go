@@ -0,0 +1,27 @@
+//go:build windows
+
+package utils
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggerEventLogOutput(t *testing.T) {
+	writer, err := openSystemLog(DefaultSyslogTag, DefaultSyslogFacility)
+	if err != nil {
+		t.Skipf("Windows事件日志不可用: %v", err)
+	}
+	require.NoError(t, writer.Close())
+
+	config := GetDefaultLogConfig()
+	config.Output = LogOutputSyslog
+	logger := NewLogger(config)
+
+	assert.Equal(t, io.Discard, logger.GetLogger().Out)
+	logger.Warn("ora2pg-admin 事件日志输出测试")
+	require.NoError(t, logger.Close())
+}